@@ -25,3 +25,47 @@ func (service *Admin) GetChainAliases(r *http.Request, args *GetChainAliasesArgs
 	reply.Aliases = service.chainManager.Aliases(ID)
 	return nil
 }
+
+// AddChainRouteArgs are the arguments for calling AddChainRoute
+type AddChainRouteArgs struct {
+	Chain string `json:"chain"`
+	Route string `json:"route"`
+}
+
+// AddChainRouteReply are the results from calling AddChainRoute
+type AddChainRouteReply struct {
+	Success bool `json:"success"`
+}
+
+// AddChainRoute serves the API of the chain [args.Chain] under
+// /ext/bc/[args.Route]. The route is kept when the node restarts.
+func (service *Admin) AddChainRoute(_ *http.Request, args *AddChainRouteArgs, reply *AddChainRouteReply) error {
+	service.log.Debug("Admin: AddChainRoute called with Chain: %s, Route: %s", args.Chain, args.Route)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	if err := service.chainRoutes.Add(chainID, args.Route); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// GetChainRoutesArgs are the arguments for calling GetChainRoutes
+type GetChainRoutesArgs struct{}
+
+// GetChainRoutesReply are the results from calling GetChainRoutes
+type GetChainRoutesReply struct {
+	// Chain ID or alias --> routes the chain's API is served under
+	Routes map[string][]string `json:"routes"`
+}
+
+// GetChainRoutes returns the custom routes chains' APIs are served under
+func (service *Admin) GetChainRoutes(_ *http.Request, args *GetChainRoutesArgs, reply *GetChainRoutesReply) error {
+	service.log.Debug("Admin: GetChainRoutes called")
+
+	reply.Routes = service.chainRoutes.Routes()
+	return nil
+}
@@ -0,0 +1,249 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// minCompactionInterval is the minimum time between the end of a compaction
+// and the start of the next one
+const minCompactionInterval = time.Minute
+
+var (
+	// statProperties are the properties of the node's database reported by
+	// DBStats, when the database has them
+	statProperties = []string{
+		"leveldb.stats",
+		"leveldb.compcount",
+		"leveldb.iostats",
+		"leveldb.writedelay",
+		"leveldb.blockpool",
+		"leveldb.cachedblock",
+		"leveldb.openedtables",
+		"leveldb.alivesnaps",
+		"leveldb.aliveiters",
+		"rocksdb.stats",
+		"rocksdb.estimate-num-keys",
+		"rocksdb.estimate-pending-compaction-bytes",
+		"rocksdb.compaction-pending",
+		"rocksdb.num-running-compactions",
+		"rocksdb.total-sst-files-size",
+		"rocksdb.live-sst-files-size",
+		"rocksdb.cur-size-all-mem-tables",
+	}
+
+	errCompactionRunning = errors.New("the database is already being compacted")
+	errCompactionTooSoon = fmt.Errorf("the database can only be compacted once every %s", minCompactionInterval)
+	errNotPersistent     = errors.New("the node's database isn't persistent")
+)
+
+// CompactDatabaseArgs are the arguments for calling CompactDatabase
+type CompactDatabaseArgs struct {
+	// ID or alias of the chain whose data is compacted. If empty, the whole
+	// database is compacted.
+	Chain string `json:"chain"`
+	// Hex encoded prefix of the keys that are compacted, in the chain's data if
+	// [Chain] is set
+	Prefix string `json:"prefix"`
+}
+
+// CompactDatabaseReply are the results from calling CompactDatabase
+type CompactDatabaseReply struct {
+	Duration cjson.Uint64 `json:"duration"`
+	Success  bool         `json:"success"`
+}
+
+// CompactDatabase compacts the node's database, or the part of it selected by
+// [args], which reclaims the space of deleted keys. Only one compaction runs
+// at a time, and compactions are at least minCompactionInterval apart. The
+// duration of the compaction is returned in milliseconds.
+func (service *Admin) CompactDatabase(_ *http.Request, args *CompactDatabaseArgs, reply *CompactDatabaseReply) error {
+	service.log.Debug("Admin: CompactDatabase called with Chain: %s, Prefix: %s", args.Chain, args.Prefix)
+
+	prefix, err := hex.DecodeString(args.Prefix)
+	if err != nil {
+		return fmt.Errorf("couldn't parse prefix: %w", err)
+	}
+	db := service.db
+	if args.Chain != "" {
+		chainID, err := service.chainManager.Lookup(args.Chain)
+		if err != nil {
+			return err
+		}
+		db = prefixdb.New(chainID.Bytes(), db)
+	}
+	start, limit := []byte(nil), []byte(nil)
+	if len(prefix) > 0 {
+		start, limit = prefix, prefixLimit(prefix)
+	}
+
+	if err := service.startCompaction(); err != nil {
+		return err
+	}
+	began := time.Now()
+	err = db.Compact(start, limit)
+	service.endCompaction()
+	if err != nil {
+		return err
+	}
+
+	duration := time.Since(began)
+	service.log.Info("Admin: compacted the database in %s", duration)
+	reply.Duration = cjson.Uint64(duration / time.Millisecond)
+	reply.Success = true
+	return nil
+}
+
+// startCompaction marks a compaction as running, or returns an error if one is
+// running or one ended too recently
+func (service *Admin) startCompaction() error {
+	service.compactionLock.Lock()
+	defer service.compactionLock.Unlock()
+
+	switch {
+	case service.compacting:
+		return errCompactionRunning
+	case time.Since(service.lastCompaction) < minCompactionInterval:
+		return errCompactionTooSoon
+	}
+	service.compacting = true
+	return nil
+}
+
+// endCompaction marks the running compaction as done
+func (service *Admin) endCompaction() {
+	service.compactionLock.Lock()
+	defer service.compactionLock.Unlock()
+
+	service.compacting = false
+	service.lastCompaction = time.Now()
+}
+
+// prefixLimit returns the first key after every key that starts with [prefix],
+// or nil if there is none
+func prefixLimit(prefix []byte) []byte {
+	limit := append([]byte(nil), prefix...)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit[i]++
+			return limit[:i+1]
+		}
+	}
+	return nil
+}
+
+// DBStatsArgs are the arguments for calling DBStats
+type DBStatsArgs struct{}
+
+// LevelStats are the compaction statistics of a level of a LevelDB database
+type LevelStats struct {
+	Level   cjson.Uint64 `json:"level"`
+	Tables  cjson.Uint64 `json:"tables"`
+	SizeMB  float64      `json:"sizeMB"`
+	TimeSec float64      `json:"timeSec"`
+	ReadMB  float64      `json:"readMB"`
+	WriteMB float64      `json:"writeMB"`
+}
+
+// DBStatsReply are the results from calling DBStats
+type DBStatsReply struct {
+	// Property --> its value. Values made of fields, such as
+	// "Read(MB):0.5 Write(MB):1.5", are reported as a map of field name to
+	// field value.
+	Properties map[string]interface{} `json:"properties"`
+	// Compaction statistics of each level, if the database is a LevelDB
+	// database
+	Levels []LevelStats `json:"levels,omitempty"`
+}
+
+// DBStats returns the statistics the node's database reports
+func (service *Admin) DBStats(_ *http.Request, _ *DBStatsArgs, reply *DBStatsReply) error {
+	service.log.Debug("Admin: DBStats called")
+
+	reply.Properties = make(map[string]interface{})
+	for _, property := range statProperties {
+		value, err := service.db.Stat(property)
+		switch {
+		case err == database.ErrNotFound:
+			continue
+		case err != nil:
+			return err
+		}
+
+		if property == "leveldb.stats" {
+			if reply.Levels, err = parseLevelStats(value); err != nil {
+				return err
+			}
+		}
+		if fields, ok := parseFields(value); ok {
+			reply.Properties[property] = fields
+		} else {
+			reply.Properties[property] = value
+		}
+	}
+	return nil
+}
+
+// parseFields parses a value made of space separated name:value fields, as in
+// "DelayN:0 Delay:0s Paused:false". Returns false if [value] isn't made of
+// fields.
+func parseFields(value string) (map[string]string, bool) {
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		return nil, false
+	}
+	fields := make(map[string]string, len(words))
+	for _, word := range words {
+		i := strings.IndexByte(word, ':')
+		if i <= 0 {
+			return nil, false
+		}
+		fields[word[:i]] = word[i+1:]
+	}
+	return fields, true
+}
+
+// parseLevelStats parses the table of compaction statistics that LevelDB
+// reports as its "leveldb.stats" property. The row of totals is skipped.
+func parseLevelStats(value string) ([]LevelStats, error) {
+	levels := []LevelStats(nil)
+	for _, line := range strings.Split(value, "\n") {
+		columns := strings.Split(line, "|")
+		if len(columns) != 6 {
+			continue
+		}
+		level, err := strconv.ParseUint(strings.TrimSpace(columns[0]), 10, 64)
+		if err != nil {
+			// The header and the totals
+			continue
+		}
+		tables, err := strconv.ParseUint(strings.TrimSpace(columns[1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse tables of level %d: %w", level, err)
+		}
+		stats := LevelStats{
+			Level:  cjson.Uint64(level),
+			Tables: cjson.Uint64(tables),
+		}
+		for i, field := range []*float64{&stats.SizeMB, &stats.TimeSec, &stats.ReadMB, &stats.WriteMB} {
+			if *field, err = strconv.ParseFloat(strings.TrimSpace(columns[i+2]), 64); err != nil {
+				return nil, fmt.Errorf("couldn't parse stats of level %d: %w", level, err)
+			}
+		}
+		levels = append(levels, stats)
+	}
+	return levels, nil
+}
@@ -0,0 +1,76 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const levelDBStats = `Compactions
+ Level |   Tables   |    Size(MB)   |    Time(sec)  |    Read(MB)   |   Write(MB)
+-------+------------+---------------+---------------+---------------+---------------
+   0   |          2 |       0.00024 |       0.50000 |       0.00000 |       0.00100
+   2   |         14 |      25.12500 |       3.00000 |      12.00000 |      13.00000
+-------+------------+---------------+---------------+---------------+---------------
+ Total |         16 |      25.12524 |       3.50000 |      12.00000 |      13.00100
+`
+
+func TestParseLevelStats(t *testing.T) {
+	levels, err := parseLevelStats(levelDBStats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 2 {
+		t.Fatalf("Should have parsed 2 levels but parsed %d", len(levels))
+	}
+	if level := levels[1]; level.Level != 2 || level.Tables != 14 || level.SizeMB != 25.125 || level.WriteMB != 13 {
+		t.Fatalf("Parsed the wrong stats: %+v", level)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, ok := parseFields("DelayN:0 Delay:1.5s Paused:false")
+	if !ok {
+		t.Fatalf("Should have parsed the fields")
+	}
+	if len(fields) != 3 || fields["Delay"] != "1.5s" || fields["Paused"] != "false" {
+		t.Fatalf("Parsed the wrong fields: %v", fields)
+	}
+	if _, ok := parseFields("12"); ok {
+		t.Fatalf("Shouldn't have parsed a number as fields")
+	}
+	if _, ok := parseFields(levelDBStats); ok {
+		t.Fatalf("Shouldn't have parsed a table as fields")
+	}
+}
+
+func TestPrefixLimit(t *testing.T) {
+	if limit := prefixLimit([]byte{1, 0xff}); !bytes.Equal(limit, []byte{2}) {
+		t.Fatalf("Wrong limit %x", limit)
+	}
+	if limit := prefixLimit([]byte{0xff, 0xff}); limit != nil {
+		t.Fatalf("Wrong limit %x", limit)
+	}
+}
+
+func TestCompactDatabaseRateLimited(t *testing.T) {
+	service := &Admin{
+		log: logging.NoLog{},
+		db:  memdb.New(),
+	}
+	reply := CompactDatabaseReply{}
+	if err := service.CompactDatabase(nil, &CompactDatabaseArgs{Prefix: "00ff"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Success {
+		t.Fatalf("Should have compacted the database")
+	}
+	if err := service.CompactDatabase(nil, &CompactDatabaseArgs{}, &reply); err != errCompactionTooSoon {
+		t.Fatalf("Should have been rate limited but returned %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var errNoLoggerName = errors.New("no logger name was provided")
+
+// SetLoggerLevelArgs are the arguments for calling SetLoggerLevel. Levels that
+// are empty are left unchanged.
+type SetLoggerLevelArgs struct {
+	// Subsystem, such as network, http, consensus or database, or chain ID or alias
+	LoggerName   string `json:"loggerName"`
+	LogLevel     string `json:"logLevel"`
+	DisplayLevel string `json:"displayLevel"`
+}
+
+// SetLoggerLevelReply are the results from calling SetLoggerLevel
+type SetLoggerLevelReply struct {
+	Success bool `json:"success"`
+}
+
+// SetLoggerLevel sets the log and display levels of the loggers with the
+// provided name
+func (service *Admin) SetLoggerLevel(_ *http.Request, args *SetLoggerLevelArgs, reply *SetLoggerLevelReply) error {
+	service.log.Debug("Admin: SetLoggerLevel called with LoggerName: %s, LogLevel: %s, DisplayLevel: %s", args.LoggerName, args.LogLevel, args.DisplayLevel)
+
+	name, err := service.loggerName(args.LoggerName)
+	if err != nil {
+		return err
+	}
+
+	// Parse both levels before changing either of them
+	logLevel, displayLevel := logging.Off, logging.Off
+	if args.LogLevel != "" {
+		if logLevel, err = logging.ToLevel(args.LogLevel); err != nil {
+			return err
+		}
+	}
+	if args.DisplayLevel != "" {
+		if displayLevel, err = logging.ToLevel(args.DisplayLevel); err != nil {
+			return err
+		}
+	}
+
+	if args.LogLevel != "" {
+		service.logFactory.SetLogLevel(name, logLevel)
+	}
+	if args.DisplayLevel != "" {
+		service.logFactory.SetDisplayLevel(name, displayLevel)
+	}
+	reply.Success = true
+	return nil
+}
+
+// GetLoggerLevelArgs are the arguments for calling GetLoggerLevel
+type GetLoggerLevelArgs struct {
+	LoggerName string `json:"loggerName"`
+}
+
+// GetLoggerLevelReply are the results from calling GetLoggerLevel
+type GetLoggerLevelReply struct {
+	LogLevel     string `json:"logLevel"`
+	DisplayLevel string `json:"displayLevel"`
+}
+
+// GetLoggerLevel returns the log and display levels of the loggers with the
+// provided name
+func (service *Admin) GetLoggerLevel(_ *http.Request, args *GetLoggerLevelArgs, reply *GetLoggerLevelReply) error {
+	service.log.Debug("Admin: GetLoggerLevel called with LoggerName: %s", args.LoggerName)
+
+	name, err := service.loggerName(args.LoggerName)
+	if err != nil {
+		return err
+	}
+	logLevel, displayLevel := service.logFactory.GetLevels(name)
+	reply.LogLevel = strings.ToLower(strings.TrimSpace(logLevel.String()))
+	reply.DisplayLevel = strings.ToLower(strings.TrimSpace(displayLevel.String()))
+	return nil
+}
+
+// loggerName returns the name of the loggers named [name]. Chain aliases are
+// replaced by the chain's ID.
+func (service *Admin) loggerName(name string) (string, error) {
+	if name == "" {
+		return "", errNoLoggerName
+	}
+	if chainID, err := service.chainManager.Lookup(name); err == nil {
+		return chainID.String(), nil
+	}
+	return name, nil
+}
@@ -5,12 +5,17 @@ package admin
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/rpc/v2"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/backup"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/logging"
 
@@ -22,14 +27,23 @@ type Admin struct {
 	nodeID       ids.ShortID
 	networkID    uint32
 	log          logging.Logger
+	logFactory   logging.Factory
 	networking   Networking
 	performance  Performance
 	chainManager chains.Manager
 	httpServer   *api.Server
+	chainRoutes  *api.ChainRoutes
+	advisor      Advisor
+	db           database.Database
+	dbDir        string
+
+	compactionLock sync.Mutex
+	compacting     bool
+	lastCompaction time.Time
 }
 
 // NewService returns a new admin API service
-func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainManager chains.Manager, peers Peerable, httpServer *api.Server) *common.HTTPHandler {
+func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, logFactory logging.Factory, chainManager chains.Manager, peers Peerable, httpServer *api.Server, chainRoutes *api.ChainRoutes, advisor Advisor, db database.Database, dbDir string) *common.HTTPHandler {
 	newServer := rpc.NewServer()
 	codec := cjson.NewCodec()
 	newServer.RegisterCodec(codec, "application/json")
@@ -38,11 +52,16 @@ func NewService(nodeID ids.ShortID, networkID uint32, log logging.Logger, chainM
 		nodeID:       nodeID,
 		networkID:    networkID,
 		log:          log,
+		logFactory:   logFactory,
 		chainManager: chainManager,
 		networking: Networking{
 			peers: peers,
 		},
-		httpServer: httpServer,
+		httpServer:  httpServer,
+		chainRoutes: chainRoutes,
+		advisor:     advisor,
+		db:          db,
+		dbDir:       dbDir,
 	}, "admin")
 	return &common.HTTPHandler{Handler: newServer}
 }
@@ -98,6 +117,30 @@ func (service *Admin) GetBlockchainID(r *http.Request, args *GetBlockchainIDArgs
 	return err
 }
 
+// GetChainVersionArgs are the arguments for calling GetChainVersion
+type GetChainVersionArgs struct {
+	Chain string `json:"chain"`
+}
+
+// GetChainVersionReply are the results from calling GetChainVersion
+type GetChainVersionReply struct {
+	Version  string        `json:"version"`
+	Upgrades snow.Upgrades `json:"upgrades"`
+}
+
+// GetChainVersion returns the version of the VM running the chain with the
+// supplied ID or alias, and the upgrades scheduled for the chain
+func (service *Admin) GetChainVersion(r *http.Request, args *GetChainVersionArgs, reply *GetChainVersionReply) error {
+	service.log.Debug("Admin: GetChainVersion called with %s", args.Chain)
+
+	chainID, err := service.chainManager.Lookup(args.Chain)
+	if err != nil {
+		return err
+	}
+	reply.Version, reply.Upgrades, err = service.chainManager.Version(chainID)
+	return err
+}
+
 // PeersArgs are the arguments for calling Peers
 type PeersArgs struct{}
 
@@ -226,3 +269,55 @@ func (service *Admin) AliasChain(_ *http.Request, args *AliasChainArgs, reply *A
 	reply.Success = true
 	return service.httpServer.AddAliasesWithReadLock("bc/"+chainID.String(), "bc/"+args.Alias)
 }
+
+// BackupDatabaseArgs are the arguments for calling BackupDatabase
+type BackupDatabaseArgs struct {
+	Filename string `json:"filename"`
+}
+
+// BackupDatabaseReply are the results from calling BackupDatabase
+type BackupDatabaseReply struct {
+	Pairs   cjson.Uint64 `json:"pairs"`
+	Success bool         `json:"success"`
+}
+
+// BackupDatabase writes a backup of the node's database to the specified file
+func (service *Admin) BackupDatabase(_ *http.Request, args *BackupDatabaseArgs, reply *BackupDatabaseReply) error {
+	service.log.Debug("Admin: BackupDatabase called with %s", args.Filename)
+
+	pairs, err := backup.BackupFile(service.db, args.Filename)
+	if err != nil {
+		return err
+	}
+	reply.Pairs = cjson.Uint64(pairs)
+	reply.Success = true
+	return nil
+}
+
+// RestoreDatabaseArgs are the arguments for calling RestoreDatabase
+type RestoreDatabaseArgs struct {
+	Filename string `json:"filename"`
+}
+
+// RestoreDatabaseReply are the results from calling RestoreDatabase
+type RestoreDatabaseReply struct {
+	Success bool `json:"success"`
+}
+
+// RestoreDatabase schedules the backup in the specified file to be restored
+// the next time the node starts. The running node's database isn't changed.
+// On restart, the database is moved aside and the backup is restored into an
+// empty one before any chain runs.
+func (service *Admin) RestoreDatabase(_ *http.Request, args *RestoreDatabaseArgs, reply *RestoreDatabaseReply) error {
+	service.log.Debug("Admin: RestoreDatabase called with %s", args.Filename)
+
+	if service.dbDir == "" {
+		return errNotPersistent
+	}
+	if err := backup.ScheduleRestore(service.dbDir, args.Filename); err != nil {
+		return err
+	}
+	service.log.Info("Admin: scheduled %s to be restored. The node should be restarted.", args.Filename)
+	reply.Success = true
+	return nil
+}
@@ -0,0 +1,28 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/utils/version"
+)
+
+// Advisor can tell whether this node should be upgraded
+type Advisor interface{ Advisory() version.Advisory }
+
+// GetVersionAdvisoryArgs are the arguments for Admin.GetVersionAdvisory API call
+type GetVersionAdvisoryArgs struct{}
+
+// GetVersionAdvisoryReply are the results from Admin.GetVersionAdvisory API call
+type GetVersionAdvisoryReply struct{ version.Advisory }
+
+// GetVersionAdvisory returns whether this node's version is still compatible
+// with the network, and the versions run by the connected validators
+func (service *Admin) GetVersionAdvisory(_ *http.Request, args *GetVersionAdvisoryArgs, reply *GetVersionAdvisoryReply) error {
+	service.log.Debug("Admin: GetVersionAdvisory called")
+
+	reply.Advisory = service.advisor.Advisory()
+	return nil
+}
@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package auth authorizes calls to the node's privileged APIs, such as the
+// Admin, Keystore and IPC APIs. Whoever knows the node's API password can
+// issue bearer tokens, each of which grants access to a set of endpoints until
+// it expires or is revoked.
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/timer"
+
+	zxcvbn "github.com/nbutton23/zxcvbn-go"
+)
+
+const (
+	// AllEndpoints is the endpoint that grants access to every privileged
+	// endpoint
+	AllEndpoints = "*"
+
+	// TokenLifespan is how long a token is valid after it's issued
+	TokenLifespan = 12 * time.Hour
+
+	// maxPasswordLen is the maximum length of the password allowed
+	maxPasswordLen = 1024
+
+	// requiredPassScore is the score, as defined by the zxcvbn package, that a
+	// password must achieve to be accepted. See the keystore's password
+	// requirements.
+	requiredPassScore = 2
+
+	// maxEndpoints is the maximum number of endpoints a token can grant
+	// access to
+	maxEndpoints = 128
+
+	// tokenLen is the number of random bytes in a token
+	tokenLen = 32
+
+	headerKey      = "Authorization"
+	headerValStart = "Bearer "
+)
+
+var (
+	errWrongPassword    = errors.New("incorrect password")
+	errWeakPassword     = errors.New("password is too weak. A stronger password is one of 8 or more characters containing attributes of upper and lowercase letters, numbers, and/or special characters")
+	errPasswordTooLong  = fmt.Errorf("password exceeds the maximum length of %d chars", maxPasswordLen)
+	errNoEndpoints      = errors.New("a token must grant access to at least one endpoint")
+	errTooManyEndpoints = fmt.Errorf("a token can grant access to at most %d endpoints", maxEndpoints)
+	errUnknownToken     = errors.New("unknown or expired token")
+	errNoToken          = errors.New("auth token not provided")
+	errUnauthorized     = errors.New("the token doesn't grant access to this endpoint")
+)
+
+// token is an issued token that hasn't been revoked
+type token struct {
+	endpoints map[string]bool
+	expiry    time.Time
+}
+
+// Auth issues tokens and checks that requests to privileged endpoints carry a
+// valid token that grants access to them
+type Auth struct {
+	lock     sync.Mutex
+	clock    timer.Clock
+	password password
+	tokens   map[string]*token
+}
+
+// New returns an Auth whose tokens are issued to those who know [pw]
+func New(pw string) (*Auth, error) {
+	a := &Auth{tokens: make(map[string]*token)}
+	if err := checkPasswordStrength(pw); err != nil {
+		return nil, err
+	}
+	return a, a.password.set(pw)
+}
+
+// NewToken returns a new token that grants access to [endpoints] for
+// TokenLifespan, if [pw] is the password
+func (a *Auth) NewToken(pw string, endpoints []string) (string, error) {
+	switch {
+	case len(endpoints) == 0:
+		return "", errNoEndpoints
+	case len(endpoints) > maxEndpoints:
+		return "", errTooManyEndpoints
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.password.check(pw) {
+		return "", errWrongPassword
+	}
+	a.pruneExpired()
+
+	tokenBytes := make([]byte, tokenLen)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	tokenStr := base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	t := &token{
+		endpoints: make(map[string]bool, len(endpoints)),
+		expiry:    a.clock.Time().Add(TokenLifespan),
+	}
+	for _, endpoint := range endpoints {
+		t.endpoints[endpointName(endpoint)] = true
+	}
+	a.tokens[tokenStr] = t
+	return tokenStr, nil
+}
+
+// RevokeToken revokes [tokenStr], if [pw] is the password. Revoking a token
+// that doesn't exist, or has expired, succeeds.
+func (a *Auth) RevokeToken(pw, tokenStr string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.password.check(pw) {
+		return errWrongPassword
+	}
+	delete(a.tokens, tokenStr)
+	return nil
+}
+
+// ChangePassword changes the password from [oldPW] to [newPW], and revokes
+// every token issued with the old password
+func (a *Auth) ChangePassword(oldPW, newPW string) error {
+	if err := checkPasswordStrength(newPW); err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.password.check(oldPW) {
+		return errWrongPassword
+	}
+	if err := a.password.set(newPW); err != nil {
+		return err
+	}
+	a.tokens = make(map[string]*token)
+	return nil
+}
+
+// authorize returns nil if [tokenStr] grants access to [endpoint]
+func (a *Auth) authorize(tokenStr, endpoint string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	t, exists := a.tokens[tokenStr]
+	if !exists {
+		return errUnknownToken
+	}
+	if !a.clock.Time().Before(t.expiry) {
+		delete(a.tokens, tokenStr)
+		return errUnknownToken
+	}
+	if !t.endpoints[AllEndpoints] && !t.endpoints[endpointName(endpoint)] {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// WrapHandler returns a handler that passes the requests to [endpoint] that
+// carry a token granting access to it to [h], and rejects the others
+func (a *Auth) WrapHandler(endpoint string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get(headerKey)
+		if !strings.HasPrefix(header, headerValStart) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, errNoToken.Error(), http.StatusUnauthorized)
+			return
+		}
+		tokenStr := strings.TrimSpace(strings.TrimPrefix(header, headerValStart))
+		switch err := a.authorize(tokenStr, endpoint); err {
+		case nil:
+			h.ServeHTTP(w, r)
+		case errUnauthorized:
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		}
+	})
+}
+
+// pruneExpired removes the expired tokens. Assumes [a.lock] is held.
+func (a *Auth) pruneExpired() {
+	now := a.clock.Time()
+	for tokenStr, t := range a.tokens {
+		if !now.Before(t.expiry) {
+			delete(a.tokens, tokenStr)
+		}
+	}
+}
+
+// endpointName returns the name of [endpoint] without its leading /ext/, so
+// that "admin" and "/ext/admin" grant access to the same endpoint
+func endpointName(endpoint string) string {
+	endpoint = strings.TrimPrefix(endpoint, "/")
+	endpoint = strings.TrimPrefix(endpoint, "ext/")
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+func checkPasswordStrength(pw string) error {
+	switch {
+	case len(pw) > maxPasswordLen:
+		return errPasswordTooLong
+	case zxcvbn.PasswordStrength(pw, nil).Score < requiredPassScore:
+		return errWeakPassword
+	default:
+		return nil
+	}
+}
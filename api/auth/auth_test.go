@@ -0,0 +1,146 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testPassword = "jNqV7#9b!kx2Lm"
+
+func newTestAuth(t *testing.T) *Auth {
+	a, err := New(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestNewWeakPassword(t *testing.T) {
+	if _, err := New("password"); err == nil {
+		t.Fatalf("Should have rejected a weak password")
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	a := newTestAuth(t)
+
+	if _, err := a.NewToken("wrong", []string{"admin"}); err == nil {
+		t.Fatalf("Should have rejected the wrong password")
+	}
+	if _, err := a.NewToken(testPassword, nil); err == nil {
+		t.Fatalf("Should have rejected a token without endpoints")
+	}
+
+	token, err := a.NewToken(testPassword, []string{"/ext/admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.authorize(token, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.authorize(token, "keystore"); err != errUnauthorized {
+		t.Fatalf("Token shouldn't grant access to the keystore")
+	}
+
+	all, err := a.NewToken(testPassword, []string{AllEndpoints})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.authorize(all, "ipcs"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTokenRevocation(t *testing.T) {
+	a := newTestAuth(t)
+
+	token, err := a.NewToken(testPassword, []string{"admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RevokeToken("wrong", token); err == nil {
+		t.Fatalf("Should have rejected the wrong password")
+	}
+	if err := a.RevokeToken(testPassword, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.authorize(token, "admin"); err != errUnknownToken {
+		t.Fatalf("Revoked token shouldn't grant access")
+	}
+
+	token, err = a.NewToken(testPassword, []string{"admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPassword := "Wq8$zP1!rT6yHc"
+	if err := a.ChangePassword(testPassword, newPassword); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.authorize(token, "admin"); err != errUnknownToken {
+		t.Fatalf("Changing the password should have revoked the token")
+	}
+	if _, err := a.NewToken(testPassword, []string{"admin"}); err == nil {
+		t.Fatalf("Should have rejected the old password")
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	a := newTestAuth(t)
+	now := time.Now()
+	a.clock.Set(now)
+
+	token, err := a.NewToken(testPassword, []string{"admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.clock.Set(now.Add(TokenLifespan - time.Second))
+	if err := a.authorize(token, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	a.clock.Set(now.Add(TokenLifespan))
+	if err := a.authorize(token, "admin"); err != errUnknownToken {
+		t.Fatalf("Expired token shouldn't grant access")
+	}
+}
+
+func TestWrapHandler(t *testing.T) {
+	a := newTestAuth(t)
+	token, err := a.NewToken(testPassword, []string{"admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin := a.WrapHandler("admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	keystore := a.WrapHandler("keystore", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		handler http.Handler
+		header  string
+		code    int
+	}{
+		{admin, "", http.StatusUnauthorized},
+		{admin, "Bearer unknown", http.StatusUnauthorized},
+		{admin, "Bearer " + token, http.StatusTeapot},
+		{keystore, "Bearer " + token, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", "/ext/admin", nil)
+		if test.header != "" {
+			req.Header.Set(headerKey, test.header)
+		}
+		w := httptest.NewRecorder()
+		test.handler.ServeHTTP(w, req)
+		if w.Code != test.code {
+			t.Fatalf("Request with header %q should have returned %d but returned %d", test.header, test.code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// password is a salted, hashed password
+type password struct {
+	hash [32]byte
+	salt [16]byte
+}
+
+func (p *password) set(pw string) error {
+	if _, err := rand.Read(p.salt[:]); err != nil {
+		return err
+	}
+	copy(p.hash[:], argon2.IDKey([]byte(pw), p.salt[:], 1, 64*1024, 4, 32))
+	return nil
+}
+
+func (p *password) check(pw string) bool {
+	hash := argon2.IDKey([]byte(pw), p.salt[:], 1, 64*1024, 4, 32)
+	return subtle.ConstantTimeCompare(hash, p.hash[:]) == 1
+}
@@ -0,0 +1,102 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Service is the API service that issues and revokes tokens
+type Service struct {
+	log  logging.Logger
+	auth *Auth
+}
+
+// NewService returns a new auth API service for [auth]
+func NewService(log logging.Logger, auth *Auth) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Service{
+		log:  log,
+		auth: auth,
+	}, "auth")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// NewTokenArgs are the arguments for calling NewToken
+type NewTokenArgs struct {
+	Password string `json:"password"`
+	// Endpoints the token grants access to, such as admin or /ext/keystore.
+	// "*" grants access to every privileged endpoint.
+	Endpoints []string `json:"endpoints"`
+}
+
+// NewTokenReply are the results from calling NewToken
+type NewTokenReply struct {
+	Token string `json:"token"`
+}
+
+// NewToken returns a token that grants access to the given endpoints. The
+// token is passed in the Authorization header of requests, as "Bearer <token>".
+func (s *Service) NewToken(_ *http.Request, args *NewTokenArgs, reply *NewTokenReply) error {
+	s.log.Debug("Auth: NewToken called with endpoints %v", args.Endpoints)
+
+	token, err := s.auth.NewToken(args.Password, args.Endpoints)
+	reply.Token = token
+	return err
+}
+
+// RevokeTokenArgs are the arguments for calling RevokeToken
+type RevokeTokenArgs struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// RevokeTokenReply are the results from calling RevokeToken
+type RevokeTokenReply struct {
+	Success bool `json:"success"`
+}
+
+// RevokeToken revokes a token, so it no longer grants access to any endpoint
+func (s *Service) RevokeToken(_ *http.Request, args *RevokeTokenArgs, reply *RevokeTokenReply) error {
+	s.log.Debug("Auth: RevokeToken called")
+
+	if err := s.auth.RevokeToken(args.Password, args.Token); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
+
+// ChangePasswordArgs are the arguments for calling ChangePassword
+type ChangePasswordArgs struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+// ChangePasswordReply are the results from calling ChangePassword
+type ChangePasswordReply struct {
+	Success bool `json:"success"`
+}
+
+// ChangePassword changes the password tokens are issued with, and revokes
+// every token issued so far
+func (s *Service) ChangePassword(_ *http.Request, args *ChangePasswordArgs, reply *ChangePasswordReply) error {
+	s.log.Debug("Auth: ChangePassword called")
+
+	if err := s.auth.ChangePassword(args.OldPassword, args.NewPassword); err != nil {
+		return err
+	}
+	reply.Success = true
+	return nil
+}
@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// ChainRoutes serves the APIs of chains under custom routes, besides
+// bc/<chain ID>. A chain's routes are added each time the chain is created.
+// Routes added at runtime are persisted, so they're restored when the node
+// restarts.
+type ChainRoutes struct {
+	log    logging.Logger
+	db     database.Database
+	server *Server
+
+	lock sync.Mutex
+	// Route --> ID or alias of the chain served under bc/<route>
+	routes map[string]string
+}
+
+// NewChainRoutes returns the routes of [configured], which maps routes to the
+// IDs or aliases of chains, and the routes persisted in [db]
+func NewChainRoutes(log logging.Logger, db database.Database, server *Server, configured map[string]string) (*ChainRoutes, error) {
+	r := &ChainRoutes{
+		log:    log,
+		db:     db,
+		server: server,
+		routes: make(map[string]string),
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		r.routes[string(it.Key())] = string(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	for route, chain := range configured {
+		if err := CheckChainRoute(route); err != nil {
+			return nil, err
+		}
+		r.routes[route] = chain
+	}
+	return r, nil
+}
+
+// CheckChainRoute returns an error if [route] can't be used as a chain's route
+func CheckChainRoute(route string) error {
+	if route == "" || strings.HasPrefix(route, "/") || strings.HasSuffix(route, "/") {
+		return fmt.Errorf("chain route %q should be non-empty and not start or end with /", route)
+	}
+	for _, segment := range strings.Split(route, "/") {
+		if segment == "" || url.PathEscape(segment) != segment {
+			return fmt.Errorf("chain route %q isn't a valid URL path", route)
+		}
+	}
+	return nil
+}
+
+// RegisterChain implements the chains.Registrant interface. The routes of the
+// new chain are added to the server.
+func (r *ChainRoutes) RegisterChain(ctx *snow.Context, _ interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for route, chain := range r.routes {
+		if !r.routesTo(ctx, chain) {
+			continue
+		}
+		r.log.Info("serving the API of chain %s under bc/%s", ctx.ChainID, route)
+		if err := r.server.AddAliases("bc/"+ctx.ChainID.String(), "bc/"+route); err != nil {
+			r.log.Error("couldn't serve the API of chain %s under bc/%s: %s", ctx.ChainID, route, err)
+		}
+	}
+}
+
+// routesTo returns true if [chain] is the ID or an alias of the chain of [ctx]
+func (r *ChainRoutes) routesTo(ctx *snow.Context, chain string) bool {
+	if chain == ctx.ChainID.String() {
+		return true
+	}
+	if ctx.BCLookup == nil {
+		return false
+	}
+	chainID, err := ctx.BCLookup.Lookup(chain)
+	return err == nil && chainID.Equals(ctx.ChainID)
+}
+
+// Add serves the API of [chainID] under bc/[route], and persists the route.
+// Assumes the server's read lock is held, as it is while an API call is
+// handled.
+func (r *ChainRoutes) Add(chainID ids.ID, route string) error {
+	if err := CheckChainRoute(route); err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if chain, exists := r.routes[route]; exists {
+		return fmt.Errorf("chain route %q is already used by chain %s", route, chain)
+	}
+	if err := r.server.AddAliasesWithReadLock("bc/"+chainID.String(), "bc/"+route); err != nil {
+		return err
+	}
+	if err := r.db.Put([]byte(route), []byte(chainID.String())); err != nil {
+		return err
+	}
+	r.routes[route] = chainID.String()
+	return nil
+}
+
+// Routes returns the routes of each chain, keyed by the chain's ID or alias
+func (r *ChainRoutes) Routes() map[string][]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	routes := make(map[string][]string)
+	for route, chain := range r.routes {
+		routes[chain] = append(routes[chain], route)
+	}
+	return routes
+}
@@ -0,0 +1,79 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestChainRoutes(t *testing.T) {
+	s := &Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 8080)
+
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = ids.NewID([32]byte{1})
+	aliaser := &ids.Aliaser{}
+	aliaser.Initialize()
+	if err := aliaser.Alias(ctx.ChainID, "X"); err != nil {
+		t.Fatal(err)
+	}
+	ctx.BCLookup = aliaser
+
+	db := memdb.New()
+	routes, err := NewChainRoutes(logging.NoLog{}, db, s, map[string]string{
+		"mychain/v1": "X",
+		"other":      ids.NewID([32]byte{2}).String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain is created
+	chainBase := "bc/" + ctx.ChainID.String()
+	if err := s.AddRoute(&common.HTTPHandler{Handler: &testHandler{}}, new(sync.RWMutex), chainBase, "/rpc", logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+	routes.RegisterChain(ctx, nil)
+
+	if _, err := s.router.GetHandler(baseURL+"/bc/mychain/v1", "/rpc"); err != nil {
+		t.Fatalf("Chain should have been served under its configured route: %s", err)
+	}
+	if _, err := s.router.GetHandler(baseURL+"/bc/other", "/rpc"); err == nil {
+		t.Fatalf("Chain shouldn't have been served under the route of another chain")
+	}
+
+	// Routes are added while an API call is handled
+	s.router.lock.RLock()
+	err = routes.Add(ctx.ChainID, "stable")
+	s.router.lock.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.router.GetHandler(baseURL+"/bc/stable", "/rpc"); err != nil {
+		t.Fatalf("Chain should have been served under the added route: %s", err)
+	}
+	if err := routes.Add(ctx.ChainID, "mychain/v1"); err == nil {
+		t.Fatalf("Should have failed to add a route that's already used")
+	}
+	if err := routes.Add(ctx.ChainID, "/bad"); err == nil {
+		t.Fatalf("Should have failed to add a malformed route")
+	}
+
+	// Only the added route is persisted
+	restored, err := NewChainRoutes(logging.NoLog{}, db, s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainRoutes := restored.Routes()[ctx.ChainID.String()]
+	if len(chainRoutes) != 1 || chainRoutes[0] != "stable" {
+		t.Fatalf("Should have restored the added route but restored %v", chainRoutes)
+	}
+}
@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encoding
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Encoding is the API service for converting IDs, addresses and serialized
+// bytes between encodings
+type Encoding struct {
+	log logging.Logger
+}
+
+// NewService returns a new encoding API service
+func NewService(log logging.Logger) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Encoding{log: log}, "encoding")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// ConvertArgs are the arguments for calling Convert
+type ConvertArgs struct {
+	// The ID, address or bytes to convert. Addresses keep their chain alias
+	// prefix, and only the rest of the address is converted.
+	Data string `json:"data"`
+
+	// The encodings to convert from and to. CB58 is used if empty.
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ConvertReply are the results from calling Convert
+type ConvertReply struct {
+	Data string `json:"data"`
+}
+
+// Convert [args.Data] from one encoding to another
+func (service *Encoding) Convert(_ *http.Request, args *ConvertArgs, reply *ConvertReply) error {
+	service.log.Debug("Encoding: Convert called from %s to %s", args.From, args.To)
+
+	from, err := formatting.GetEncoding(args.From)
+	if err != nil {
+		return err
+	}
+	to, err := formatting.GetEncoding(args.To)
+	if err != nil {
+		return err
+	}
+
+	prefix, data := "", args.Data
+	if i := strings.LastIndex(data, formatting.AddressSep); i >= 0 {
+		prefix, data = data[:i+1], data[i+1:]
+	}
+	b, err := from.Decode(data)
+	if err != nil {
+		return err
+	}
+	reply.Data = prefix + to.Encode(b)
+	return nil
+}
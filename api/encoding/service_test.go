@@ -0,0 +1,60 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestConvert(t *testing.T) {
+	service := Encoding{log: logging.NoLog{}}
+
+	tests := []struct {
+		args     ConvertArgs
+		expected string
+	}{
+		{
+			args:     ConvertArgs{Data: "1NVSVezva3bAtJesnUj", To: formatting.HexEncoding},
+			expected: "0x00010203040506070809ff",
+		},
+		{
+			args:     ConvertArgs{Data: "00010203040506070809ff", From: formatting.HexEncoding, To: formatting.Base64Encoding},
+			expected: "AAECAwQFBgcICf8=",
+		},
+		{
+			args:     ConvertArgs{Data: "AAECAwQFBgcICf8=", From: "BASE64"},
+			expected: "1NVSVezva3bAtJesnUj",
+		},
+		{
+			args:     ConvertArgs{Data: "X-1NVSVezva3bAtJesnUj", To: formatting.HexEncoding},
+			expected: "X-0x00010203040506070809ff",
+		},
+	}
+	for _, test := range tests {
+		reply := ConvertReply{}
+		if err := service.Convert(nil, &test.args, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Data != test.expected {
+			t.Fatalf("Expected %s, got %s", test.expected, reply.Data)
+		}
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	service := Encoding{log: logging.NoLog{}}
+
+	if err := service.Convert(nil, &ConvertArgs{Data: "1NVSVezva3bAtJesnUj", To: "base32"}, &ConvertReply{}); err == nil {
+		t.Fatalf("Should have errored due to an unknown encoding")
+	}
+	if err := service.Convert(nil, &ConvertArgs{Data: "1NVSVezva3bAtJesnUk"}, &ConvertReply{}); err == nil {
+		t.Fatalf("Should have errored due to an invalid checksum")
+	}
+	if err := service.Convert(nil, &ConvertArgs{Data: "0xzz", From: formatting.HexEncoding}, &ConvertReply{}); err == nil {
+		t.Fatalf("Should have errored due to invalid hex")
+	}
+}
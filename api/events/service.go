@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"sync"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+const eventIdentifier = "events"
+
+// Event is published when a container is accepted
+type Event struct {
+	ChainID     ids.ID `json:"chainID"`
+	ContainerID ids.ID `json:"containerID"`
+}
+
+// ChainChannel returns the channel the containers accepted by [chainID] are
+// published to
+func ChainChannel(chainID ids.ID) string { return "chain:" + chainID.String() }
+
+// AddressChannel returns the channel the accepted containers with an output to
+// [addr] are published to
+func AddressChannel(addr ids.ShortID) string { return "address:" + addr.String() }
+
+// AssetChannel returns the channel the accepted containers with an output of
+// [assetID] are published to
+func AssetChannel(assetID ids.ID) string { return "asset:" + assetID.String() }
+
+// Events is the API service that pushes the containers accepted by each chain
+// to the clients subscribed to them over a WebSocket. Clients subscribe to a
+// chain, or to an address or an asset of the chains whose VM is a
+// common.SubscribableVM, by sending {"channel": <channel>}, and unsubscribe by
+// sending {"channel": <channel>, "unsubscribe": true}.
+//
+// Events are delivered on a best effort basis: if a client falls behind, the
+// events it hasn't received yet may be dropped.
+type Events struct {
+	log    logging.Logger
+	events *triggers.EventDispatcher
+	server *cjson.PubSubServer
+
+	lock   sync.Mutex
+	chains ids.Set
+}
+
+// NewService returns an events service that receives accepted containers from
+// [events]
+func NewService(log logging.Logger, events *triggers.EventDispatcher) *Events {
+	return &Events{
+		log:    log,
+		events: events,
+		server: cjson.NewDynamicPubSubServer(log),
+	}
+}
+
+// CreateHandler returns the WebSocket handler of this API
+func (e *Events) CreateHandler() *common.HTTPHandler {
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: e.server}
+}
+
+// RegisterChain implements the chains.Registrant interface
+func (e *Events) RegisterChain(ctx *snow.Context, vm interface{}) {
+	a := &acceptor{
+		log:    e.log,
+		server: e.server,
+	}
+	a.vm, _ = vm.(common.SubscribableVM)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// Publishing must not block consensus, so the oldest unpublished
+	// containers are dropped if the publisher falls too far behind
+	config := triggers.AsyncConfig{
+		QueueSize: triggers.DefaultQueueSize,
+		Policy:    triggers.DropOldest,
+	}
+	if err := e.events.RegisterChainAsync(ctx.ChainID, eventIdentifier, a, config); err != nil {
+		e.log.Error("couldn't publish the events of chain %s due to %s", ctx.ChainID, err)
+		return
+	}
+	e.chains.Add(ctx.ChainID)
+}
+
+// DeregisterChain implements the chains.Deregistrant interface
+func (e *Events) DeregisterChain(ctx *snow.Context) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if !e.chains.Contains(ctx.ChainID) {
+		return
+	}
+	if err := e.events.DeregisterChain(ctx.ChainID, eventIdentifier); err != nil {
+		e.log.Warn("couldn't stop publishing the events of chain %s due to %s", ctx.ChainID, err)
+	}
+	e.chains.Remove(ctx.ChainID)
+}
+
+// acceptor publishes the containers accepted by a chain
+type acceptor struct {
+	log    logging.Logger
+	server *cjson.PubSubServer
+	vm     common.SubscribableVM // nil if the chain's VM isn't subscribable
+}
+
+// Accept implements the triggers.Acceptor interface
+func (a *acceptor) Accept(chainID, containerID ids.ID, container []byte) error {
+	event := Event{
+		ChainID:     chainID,
+		ContainerID: containerID,
+	}
+	a.server.Publish(ChainChannel(chainID), event)
+	if a.vm == nil {
+		return nil
+	}
+
+	addrs, assets, err := a.vm.ContainerSubjects(container)
+	if err != nil {
+		// Containers that aren't transactions, such as blocks, have no
+		// subjects
+		a.log.Verbo("couldn't parse the subjects of %s on chain %s due to %s", containerID, chainID, err)
+		return nil
+	}
+	for _, addr := range addrs {
+		a.server.Publish(AddressChannel(addr), event)
+	}
+	for _, assetID := range assets {
+		a.server.Publish(AssetChannel(assetID), event)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package events
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type testVM struct {
+	addrs  []ids.ShortID
+	assets []ids.ID
+}
+
+func (vm *testVM) ContainerSubjects(container []byte) ([]ids.ShortID, []ids.ID, error) {
+	if len(container) == 0 {
+		return nil, nil, errors.New("not a transaction")
+	}
+	return vm.addrs, vm.assets, nil
+}
+
+type message struct {
+	Channel string `json:"channel"`
+	Value   Event  `json:"value"`
+}
+
+func TestEventsPublishSubjects(t *testing.T) {
+	dispatcher := &triggers.EventDispatcher{}
+	dispatcher.Initialize(logging.NoLog{})
+
+	service := NewService(logging.NoLog{}, dispatcher)
+	server := httptest.NewServer(service.CreateHandler().Handler)
+	defer server.Close()
+
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = ids.NewID([32]byte{1})
+	addr := ids.NewShortID([20]byte{2})
+	assetID := ids.NewID([32]byte{3})
+	service.RegisterChain(ctx, &testVM{
+		addrs:  []ids.ShortID{addr},
+		assets: []ids.ID{assetID},
+	})
+	defer service.DeregisterChain(ctx)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(map[string]string{"channel": AddressChannel(addr)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscription is handled asynchronously, so accept containers until
+	// one is received
+	containerID := ids.NewID([32]byte{4})
+	received := make(chan message, 1)
+	go func() {
+		msg := message{}
+		if err := conn.ReadJSON(&msg); err == nil {
+			received <- msg
+		}
+	}()
+	for timeout := time.After(5 * time.Second); ; {
+		dispatcher.Accept(ctx.ChainID, containerID, []byte{1})
+		select {
+		case msg := <-received:
+			if msg.Channel != AddressChannel(addr) {
+				t.Fatalf("Received a message on the wrong channel %s", msg.Channel)
+			}
+			if !msg.Value.ChainID.Equals(ctx.ChainID) || !msg.Value.ContainerID.Equals(containerID) {
+				t.Fatalf("Received the wrong event %+v", msg.Value)
+			}
+			return
+		case <-timeout:
+			t.Fatalf("Should have received the accepted container")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
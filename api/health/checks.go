@@ -0,0 +1,100 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/version"
+	"github.com/ava-labs/gecko/utils/wrappers"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	canaryKey = []byte("canary")
+
+	errCanaryMismatch = errors.New("read a different canary value than was written")
+	errLowDiskSpace   = errors.New("the database's disk is running out of space")
+	errIncompatible   = errors.New("this node's version is older than the network's minimum compatible version")
+)
+
+// Check returns details about what it checked, or an error if what it checked
+// is unhealthy
+type Check func() (interface{}, error)
+
+// NewDatabaseCheck returns a check that writes a canary value to [db], reads it
+// back and deletes it. The canary value is written under a fixed key, so [db]
+// should hold no other data.
+func NewDatabaseCheck(db database.Database) Check {
+	return func() (interface{}, error) {
+		p := wrappers.Packer{MaxSize: wrappers.LongLen}
+		p.PackLong(uint64(time.Now().UnixNano()))
+		if p.Errored() {
+			return nil, p.Err
+		}
+
+		if err := db.Put(canaryKey, p.Bytes); err != nil {
+			return nil, fmt.Errorf("couldn't write canary: %w", err)
+		}
+		value, err := db.Get(canaryKey)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read canary: %w", err)
+		}
+		if !bytes.Equal(value, p.Bytes) {
+			return nil, errCanaryMismatch
+		}
+		if err := db.Delete(canaryKey); err != nil {
+			return nil, fmt.Errorf("couldn't delete canary: %w", err)
+		}
+		return nil, nil
+	}
+}
+
+// DiskSpace is the space of the disk a directory is on
+type DiskSpace struct {
+	// Number of bytes available to the node
+	Free cjson.Uint64 `json:"free"`
+	// Number of bytes of the disk
+	Total cjson.Uint64 `json:"total"`
+}
+
+// NewDiskCheck returns a check that fails if the disk [dir] is on has less
+// than [minFree] bytes, or less than [minFreeFraction] of its space, available.
+// Either threshold is ignored if it's 0.
+func NewDiskCheck(dir string, minFree uint64, minFreeFraction float64) Check {
+	return func() (interface{}, error) {
+		free, total, err := diskSpace(dir)
+		if err != nil {
+			return nil, err
+		}
+		space := DiskSpace{
+			Free:  cjson.Uint64(free),
+			Total: cjson.Uint64(total),
+		}
+		if free < minFree || (total > 0 && float64(free)/float64(total) < minFreeFraction) {
+			return space, fmt.Errorf("%w: %d of %d bytes are available", errLowDiskSpace, free, total)
+		}
+		return space, nil
+	}
+}
+
+// Advisor can tell whether this node should be upgraded
+type Advisor interface{ Advisory() version.Advisory }
+
+// NewVersionCheck returns a check that reports [advisor]'s advisory, and fails
+// if this node is older than the network's minimum compatible version
+func NewVersionCheck(advisor Advisor) Check {
+	return func() (interface{}, error) {
+		advisory := advisor.Advisory()
+		if !advisory.Compatible {
+			return advisory, fmt.Errorf("%w: running %s but the minimum is %s", errIncompatible, advisory.Version, advisory.MinimumVersion)
+		}
+		return advisory, nil
+	}
+}
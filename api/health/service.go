@@ -0,0 +1,191 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// healthPrefix is the prefix, in a chain's database, of the keys its canary
+// check writes
+var healthPrefix = []byte("health")
+
+// Result is the outcome of the last run of a check
+type Result struct {
+	// Details the check reported, if any
+	Details interface{} `json:"details,omitempty"`
+	// Error the check failed with, if it failed
+	Error string `json:"error,omitempty"`
+	// When the check was last run
+	Timestamp time.Time `json:"timestamp"`
+	// How long the check took
+	Duration time.Duration `json:"duration"`
+	// Number of times in a row the check has failed
+	ContiguousFailures cjson.Uint64 `json:"contiguousFailures"`
+}
+
+// Health is the API service that reports the health of the node. Its checks
+// are run every [frequency], and the results of their last runs are reported.
+type Health struct {
+	log logging.Logger
+	db  database.Database
+
+	lock    sync.Mutex
+	checks  map[string]Check
+	results map[string]Result
+
+	runner *timer.Repeater
+}
+
+// NewService returns a health service that runs its checks every [frequency]
+// until it's stopped. A canary check is registered for the database of each
+// chain registered in [db].
+func NewService(log logging.Logger, db database.Database, frequency time.Duration) *Health {
+	h := &Health{
+		log:     log,
+		db:      db,
+		checks:  make(map[string]Check),
+		results: make(map[string]Result),
+	}
+	h.runner = timer.NewRepeater(h.runChecks, frequency)
+	go log.RecoverAndPanic(h.runner.Dispatch)
+	return h
+}
+
+// CreateHandler returns a new service object that can send requests to this API
+func (h *Health) CreateHandler() *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(h, "health")
+	return &common.HTTPHandler{LockOptions: common.NoLock, Handler: newServer}
+}
+
+// RegisterCheck adds [check] to the checks of the node under [name], replacing
+// the check of that name, if any. The check is run before this returns.
+func (h *Health) RegisterCheck(name string, check Check) {
+	h.lock.Lock()
+	h.checks[name] = check
+	h.lock.Unlock()
+
+	h.runCheck(name, check)
+}
+
+// DeregisterCheck removes the check registered under [name]
+func (h *Health) DeregisterCheck(name string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.checks, name)
+	delete(h.results, name)
+}
+
+// RegisterChain registers a canary check of the database of the chain of [ctx]
+func (h *Health) RegisterChain(ctx *snow.Context, _ interface{}) {
+	db := prefixdb.New(healthPrefix, prefixdb.New(ctx.ChainID.Bytes(), h.db))
+	h.RegisterCheck(chainCheckName(ctx), NewDatabaseCheck(db))
+}
+
+// DeregisterChain removes the canary check of the database of the chain of
+// [ctx]
+func (h *Health) DeregisterChain(ctx *snow.Context) { h.DeregisterCheck(chainCheckName(ctx)) }
+
+// Stop running the checks
+func (h *Health) Stop() { h.runner.Stop() }
+
+// chainCheckName returns the name of the canary check of the database of the
+// chain of [ctx]
+func chainCheckName(ctx *snow.Context) string { return "database-" + ctx.ChainID.String() }
+
+// runChecks runs every check
+func (h *Health) runChecks() {
+	h.lock.Lock()
+	checks := make(map[string]Check, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.lock.Unlock()
+
+	for name, check := range checks {
+		h.runCheck(name, check)
+	}
+}
+
+// runCheck runs [check] and records its result under [name], unless the check
+// was deregistered while it ran
+func (h *Health) runCheck(name string, check Check) {
+	start := time.Now()
+	details, err := check()
+	result := Result{
+		Details:   details,
+		Timestamp: start,
+		Duration:  time.Since(start),
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, registered := h.checks[name]; !registered {
+		return
+	}
+	if err != nil {
+		h.log.Warn("health check %s failed: %s", name, err)
+		result.Error = err.Error()
+		result.ContiguousFailures = h.results[name].ContiguousFailures + 1
+	}
+	h.results[name] = result
+}
+
+// GetLivenessArgs are the arguments to GetLiveness
+type GetLivenessArgs struct{}
+
+// CheckResult is the result of a check, and the check's name
+type CheckResult struct {
+	Name string `json:"name"`
+	Result
+}
+
+// GetLivenessReply is the reply from GetLiveness
+type GetLivenessReply struct {
+	Checks  []CheckResult `json:"checks"`
+	Healthy bool          `json:"healthy"`
+}
+
+// GetLiveness returns the result of the last run of each check. The node is
+// healthy if none of them failed.
+func (h *Health) GetLiveness(_ *http.Request, _ *GetLivenessArgs, reply *GetLivenessReply) error {
+	h.log.Verbo("GetLiveness called")
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	reply.Checks = make([]CheckResult, 0, len(h.results))
+	reply.Healthy = true
+	for name, result := range h.results {
+		reply.Checks = append(reply.Checks, CheckResult{
+			Name:   name,
+			Result: result,
+		})
+		if result.Error != "" {
+			reply.Healthy = false
+		}
+	}
+	sort.Slice(reply.Checks, func(i, j int) bool { return reply.Checks[i].Name < reply.Checks[j].Name })
+	return nil
+}
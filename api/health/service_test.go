@@ -0,0 +1,161 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/api/info"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/version"
+)
+
+type testAdvisor struct{ advisory version.Advisory }
+
+func (a *testAdvisor) Advisory() version.Advisory { return a.advisory }
+
+func TestDatabaseCheck(t *testing.T) {
+	db := memdb.New()
+	check := NewDatabaseCheck(db)
+	if _, err := check(); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := db.Has(canaryKey); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("The canary should have been deleted")
+	}
+
+	db.Close()
+	if _, err := check(); err == nil {
+		t.Fatalf("The check of a closed database should have failed")
+	}
+}
+
+func TestDiskCheck(t *testing.T) {
+	dir := os.TempDir()
+	if _, err := NewDiskCheck(dir, 0, 0)(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDiskCheck(dir, math.MaxUint64, 0)(); !errors.Is(err, errLowDiskSpace) {
+		t.Fatalf("Should have reported low disk space but returned %v", err)
+	}
+	if _, err := NewDiskCheck(dir, 0, 1.1)(); !errors.Is(err, errLowDiskSpace) {
+		t.Fatalf("Should have reported low disk space but returned %v", err)
+	}
+}
+
+func TestVersionCheck(t *testing.T) {
+	advisor := &testAdvisor{advisory: version.Advisory{
+		Version:        "avalanche/0.5.7",
+		MinimumVersion: "avalanche/0.5.0",
+		Compatible:     true,
+	}}
+	check := NewVersionCheck(advisor)
+	details, err := check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advisory, ok := details.(version.Advisory); !ok || advisory.Version != advisor.advisory.Version {
+		t.Fatalf("Should have reported the advisory but reported %v", details)
+	}
+
+	advisor.advisory.MinimumVersion = "avalanche/0.6.0"
+	advisor.advisory.Compatible = false
+	if _, err := check(); !errors.Is(err, errIncompatible) {
+		t.Fatalf("Should have reported an incompatible version but returned %v", err)
+	}
+
+	h := NewService(logging.NoLog{}, memdb.New(), time.Hour)
+	defer h.Stop()
+	h.RegisterCheck("version", check)
+	reply := GetLivenessReply{}
+	if err := h.GetLiveness(nil, &GetLivenessArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Healthy {
+		t.Fatalf("Shouldn't have been healthy while running an incompatible version")
+	}
+}
+
+func TestInfoGetVersionAdvisory(t *testing.T) {
+	advisor := &testAdvisor{advisory: version.Advisory{
+		Version:        "avalanche/0.5.7",
+		MinimumVersion: "avalanche/0.6.0",
+		Warnings:       []string{"upgrade"},
+	}}
+	service := info.NewService(logging.NoLog{}, nil, nil, advisor)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/ext/info", bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"info.getVersionAdvisory","params":{}}`))
+	r.Header.Set("Content-Type", "application/json")
+	service.Handler.ServeHTTP(w, r)
+
+	response := struct {
+		Result info.GetVersionAdvisoryReply `json:"result"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if reply := response.Result; reply.Version != advisor.advisory.Version || reply.MinimumVersion != advisor.advisory.MinimumVersion || reply.Compatible || len(reply.Warnings) != 1 {
+		t.Fatalf("Should have returned %+v but returned %+v (%s)", advisor.advisory, reply, w.Body)
+	}
+}
+
+func TestGetLiveness(t *testing.T) {
+	db := memdb.New()
+	h := NewService(logging.NoLog{}, db, time.Hour)
+	defer h.Stop()
+
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = ids.NewID([32]byte{1})
+	h.RegisterChain(ctx, nil)
+	h.RegisterCheck("disk", NewDiskCheck(os.TempDir(), 0, 0))
+
+	reply := GetLivenessReply{}
+	if err := h.GetLiveness(nil, &GetLivenessArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Healthy {
+		t.Fatalf("Should have been healthy")
+	}
+	if len(reply.Checks) != 2 {
+		t.Fatalf("Should have reported 2 checks but reported %d", len(reply.Checks))
+	}
+
+	h.RegisterCheck("failing", func() (interface{}, error) { return nil, errLowDiskSpace })
+	h.runChecks()
+	reply = GetLivenessReply{}
+	if err := h.GetLiveness(nil, &GetLivenessArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Healthy {
+		t.Fatalf("Shouldn't have been healthy")
+	}
+	for _, check := range reply.Checks {
+		if check.Name == "failing" && check.ContiguousFailures != 2 {
+			t.Fatalf("Should have failed twice but failed %d times", check.ContiguousFailures)
+		}
+	}
+
+	h.DeregisterCheck("failing")
+	h.DeregisterChain(ctx)
+	reply = GetLivenessReply{}
+	if err := h.GetLiveness(nil, &GetLivenessArgs{}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Healthy || len(reply.Checks) != 1 {
+		t.Fatalf("Should have been healthy with 1 check")
+	}
+}
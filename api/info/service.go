@@ -0,0 +1,126 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package info
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/peerstore"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/version"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Advisor can tell whether this node should be upgraded
+type Advisor interface{ Advisory() version.Advisory }
+
+// Info is the API service for what this node knows of the network
+type Info struct {
+	log     logging.Logger
+	peers   *peerstore.Store
+	ip      *utils.DynamicIPDesc
+	advisor Advisor
+}
+
+// NewService returns a new info API service
+func NewService(log logging.Logger, peers *peerstore.Store, ip *utils.DynamicIPDesc, advisor Advisor) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Info{
+		log:     log,
+		peers:   peers,
+		ip:      ip,
+		advisor: advisor,
+	}, "info")
+	return &common.HTTPHandler{Handler: newServer}
+}
+
+// KnownPeersArgs are the arguments for calling KnownPeers
+type KnownPeersArgs struct{}
+
+// APIPeer is what this node knows of a peer it has been connected to
+type APIPeer struct {
+	NodeID  ids.ShortID `json:"nodeID"`
+	IP      string      `json:"ip"`
+	Version string      `json:"version"`
+
+	// When this node was last connected to the peer
+	LastSeen time.Time `json:"lastSeen"`
+
+	// Fraction of the time this node was running that the peer was connected
+	// to it, as of when the peer was last seen
+	Uptime cjson.Float64 `json:"uptime"`
+
+	// Average time, in milliseconds, the peer took to respond to a request
+	AverageLatency cjson.Uint64 `json:"averageLatency"`
+}
+
+// KnownPeersReply are the results from calling KnownPeers
+type KnownPeersReply struct {
+	Peers []APIPeer `json:"peers"`
+}
+
+// KnownPeers returns the peers this node has been connected to, most recently
+// seen first. These are the peers it reconnects to when it restarts.
+func (service *Info) KnownPeers(_ *http.Request, _ *KnownPeersArgs, reply *KnownPeersReply) error {
+	service.log.Debug("Info: KnownPeers called")
+
+	peers, err := service.peers.Peers()
+	if err != nil {
+		return err
+	}
+	reply.Peers = make([]APIPeer, len(peers))
+	for i, peer := range peers {
+		reply.Peers[i] = APIPeer{
+			NodeID:         peer.NodeID,
+			IP:             peer.IP.String(),
+			Version:        peer.Version,
+			LastSeen:       peer.LastSeen,
+			Uptime:         cjson.Float64(peer.Uptime),
+			AverageLatency: cjson.Uint64(peer.AverageLatency / time.Millisecond),
+		}
+	}
+	return nil
+}
+
+// GetNodeIPArgs are the arguments for calling GetNodeIP
+type GetNodeIPArgs struct{}
+
+// GetNodeIPReply are the results from calling GetNodeIP
+type GetNodeIPReply struct {
+	IP string `json:"ip"`
+}
+
+// GetNodeIP returns the IP and staking port this node is reachable at. It's
+// kept up to date if the node was started with --dynamic-public-ip.
+func (service *Info) GetNodeIP(_ *http.Request, _ *GetNodeIPArgs, reply *GetNodeIPReply) error {
+	service.log.Debug("Info: GetNodeIP called")
+
+	reply.IP = service.ip.IP().String()
+	return nil
+}
+
+// GetVersionAdvisoryArgs are the arguments for calling GetVersionAdvisory
+type GetVersionAdvisoryArgs struct{}
+
+// GetVersionAdvisoryReply are the results from calling GetVersionAdvisory
+type GetVersionAdvisoryReply struct{ version.Advisory }
+
+// GetVersionAdvisory returns whether this node's version is still compatible
+// with the network, and the versions run by the connected validators
+func (service *Info) GetVersionAdvisory(_ *http.Request, _ *GetVersionAdvisoryArgs, reply *GetVersionAdvisoryReply) error {
+	service.log.Debug("Info: GetVersionAdvisory called")
+
+	reply.Advisory = service.advisor.Advisory()
+	return nil
+}
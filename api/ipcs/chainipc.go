@@ -1,29 +1,26 @@
 // (c) 2019-2020, Ava Labs, Inc. All rights reserved.
 // See the file LICENSE for licensing terms.
 
+// Package ipcs publishes the containers accepted by chains to local sockets.
+// Every accepted container is written to each reader of its chain's socket as
+// a big endian uint64 length followed by the container's bytes.
 package ipcs
 
 import (
-	"nanomsg.org/go/mangos/v2"
-
 	"github.com/ava-labs/gecko/ids"
-	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/logging"
 )
 
-// ChainIPC a struct which holds IPC socket information
+// ChainIPC publishes the containers accepted by a chain to a socket
 type ChainIPC struct {
 	log    logging.Logger
-	socket mangos.Socket
+	socket *socket
 }
 
 // Accept delivers a message to the ChainIPC
 func (cipc *ChainIPC) Accept(chainID, containerID ids.ID, container []byte) error {
-	err := cipc.socket.Send(container)
-	if err != nil {
-		cipc.log.Error("%s while trying to send:\n%s", err, formatting.DumpBytes{Bytes: container})
-	}
-	return err
+	cipc.socket.Send(container)
+	return nil
 }
 
 // Stop halts the ChainIPC event loop
@@ -7,10 +7,6 @@ import (
 	"fmt"
 	"net/http"
 
-	"nanomsg.org/go/mangos/v2/protocol/pub"
-
-	_ "nanomsg.org/go/mangos/v2/transport/ipc" // registers the IPC transport
-
 	"github.com/gorilla/rpc/v2"
 
 	"github.com/ava-labs/gecko/api"
@@ -22,8 +18,6 @@ import (
 	"github.com/ava-labs/gecko/utils/wrappers"
 )
 
-const baseURL = "ipc:///tmp/"
-
 // IPCs maintains the IPCs
 type IPCs struct {
 	log          logging.Logger
@@ -56,6 +50,8 @@ type PublishBlockchainArgs struct {
 
 // PublishBlockchainReply are the results from calling PublishBlockchain
 type PublishBlockchainReply struct {
+	// Path of the Unix domain socket, or on Windows the named pipe, the
+	// accepted containers are written to
 	URL string `json:"url"`
 }
 
@@ -69,24 +65,22 @@ func (ipc *IPCs) PublishBlockchain(r *http.Request, args *PublishBlockchainArgs,
 
 	chainIDKey := chainID.Key()
 	chainIDStr := chainID.String()
-	url := baseURL + chainIDStr + ".ipc"
+	path, err := socketPath(chainIDStr + ".ipc")
+	if err != nil {
+		ipc.log.Error("can't create the directory of the socket: %s", err)
+		return err
+	}
 
-	reply.URL = url
+	reply.URL = path
 
 	if _, ok := ipc.chains[chainIDKey]; ok {
 		ipc.log.Info("returning existing blockchainID %s", chainIDStr)
 		return nil
 	}
 
-	sock, err := pub.NewSocket()
+	sock, err := newSocket(ipc.log, path)
 	if err != nil {
-		ipc.log.Error("can't get new pub socket: %s", err)
-		return err
-	}
-
-	if err = sock.Listen(url); err != nil {
-		ipc.log.Error("can't listen on pub socket: %s", err)
-		sock.Close()
+		ipc.log.Error("can't listen on socket: %s", err)
 		return err
 	}
 
@@ -94,7 +88,13 @@ func (ipc *IPCs) PublishBlockchain(r *http.Request, args *PublishBlockchainArgs,
 		log:    ipc.log,
 		socket: sock,
 	}
-	if err := ipc.events.RegisterChain(chainID, "ipc", chainIPC); err != nil {
+	// Publishing to a slow consumer must not block consensus, so the oldest
+	// unsent containers are dropped if the consumer falls too far behind
+	config := triggers.AsyncConfig{
+		QueueSize: triggers.DefaultQueueSize,
+		Policy:    triggers.DropOldest,
+	}
+	if err := ipc.events.RegisterChainAsync(chainID, "ipc", chainIPC, config); err != nil {
 		ipc.log.Error("couldn't register event: %s", err)
 		sock.Close()
 		return err
@@ -130,9 +130,10 @@ func (ipc *IPCs) UnpublishBlockchain(r *http.Request, args *UnpublishBlockchainA
 	}
 
 	errs := wrappers.Errs{}
+	// Stop dispatching events before the socket is closed
 	errs.Add(
-		chain.Stop(),
 		ipc.events.DeregisterChain(chainID, "ipc"),
+		chain.Stop(),
 	)
 	delete(ipc.chains, chainIDKey)
 
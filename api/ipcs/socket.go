@@ -0,0 +1,149 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ipcs
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// headerLen is the length of the big endian uint64 that precedes every
+	// message written to a socket, and is the length of the message
+	headerLen = 8
+
+	// maxQueuedMsgs is the number of messages that can wait to be written to a
+	// reader. A reader that falls further behind is disconnected.
+	maxQueuedMsgs = 1024
+)
+
+// listener accepts the connections of the readers of a socket
+type listener interface {
+	Accept() (io.WriteCloser, error)
+	Close() error
+}
+
+// reader is a connected reader of a socket, and the messages waiting to be
+// written to it
+type reader struct {
+	conn io.WriteCloser
+	msgs chan []byte
+}
+
+// socket writes messages to every reader connected to it. On Unix it's a Unix
+// domain socket, and on Windows it's a named pipe.
+//
+// Each reader is written to by its own goroutine, so a reader that doesn't
+// read doesn't delay the others, or the sender.
+type socket struct {
+	log      logging.Logger
+	path     string
+	listener listener
+
+	lock    sync.Mutex
+	readers map[*reader]struct{}
+}
+
+// newSocket returns a socket that readers connect to at [path]
+func newSocket(log logging.Logger, path string) (*socket, error) {
+	l, err := listen(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &socket{
+		log:      log,
+		path:     path,
+		listener: l,
+		readers:  make(map[*reader]struct{}),
+	}
+	go log.RecoverAndPanic(s.accept)
+	return s, nil
+}
+
+// accept connections until the listener is closed
+func (s *socket) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.log.Debug("stopped accepting readers of %s: %s", s.path, err)
+			return
+		}
+		s.lock.Lock()
+		if s.readers == nil { // The socket was closed
+			s.lock.Unlock()
+			conn.Close()
+			return
+		}
+		r := &reader{
+			conn: conn,
+			msgs: make(chan []byte, maxQueuedMsgs),
+		}
+		s.readers[r] = struct{}{}
+		s.lock.Unlock()
+
+		go s.log.RecoverAndPanic(func() { s.write(r) })
+	}
+}
+
+// write the messages queued for [r] until it's disconnected
+func (s *socket) write(r *reader) {
+	for msg := range r.msgs {
+		if _, err := r.conn.Write(msg); err != nil {
+			s.log.Debug("disconnecting a reader of %s: %s", s.path, err)
+
+			s.lock.Lock()
+			s.disconnect(r)
+			s.lock.Unlock()
+			return
+		}
+	}
+}
+
+// Send [msg], prefixed with its length, to every connected reader. A reader
+// that has too many messages waiting to be written to it is disconnected.
+func (s *socket) Send(msg []byte) {
+	framed := make([]byte, headerLen+len(msg))
+	binary.BigEndian.PutUint64(framed, uint64(len(msg)))
+	copy(framed[headerLen:], msg)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for r := range s.readers {
+		select {
+		case r.msgs <- framed:
+		default:
+			s.log.Debug("disconnecting a reader of %s that fell %d messages behind", s.path, maxQueuedMsgs)
+			s.disconnect(r)
+		}
+	}
+}
+
+// Close the socket and disconnect its readers
+func (s *socket) Close() error {
+	err := s.listener.Close()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for r := range s.readers {
+		s.disconnect(r)
+	}
+	s.readers = nil
+	return err
+}
+
+// disconnect [r], unless it already was. Closing its connection unblocks the
+// write to it, if any. Assumes the lock is held.
+func (s *socket) disconnect(r *reader) {
+	if _, ok := s.readers[r]; !ok {
+		return
+	}
+	delete(s.readers, r)
+	close(r.msgs)
+	r.conn.Close()
+}
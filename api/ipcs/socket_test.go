@@ -0,0 +1,167 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !windows
+// +build !windows
+
+package ipcs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// awaitReaders waits for [s] to have [n] readers
+func awaitReaders(t *testing.T, s *socket, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s.lock.Lock()
+		numReaders := len(s.readers)
+		s.lock.Unlock()
+		if numReaders == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The socket should have %d readers but has %d", n, numReaders)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSocketSendsLengthPrefixedMessages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := newSocket(logging.NoLog{}, filepath.Join(dir, "chain.ipc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	conn, err := net.Dial("unix", s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	awaitReaders(t, s, 1)
+
+	msgs := [][]byte{{1, 2, 3}, {}, bytes.Repeat([]byte{4}, 1000)}
+	for _, msg := range msgs {
+		s.Send(msg)
+	}
+	for _, expected := range msgs {
+		header := make([]byte, headerLen)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			t.Fatal(err)
+		}
+		msg := make([]byte, binary.BigEndian.Uint64(header))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg, expected) {
+			t.Fatalf("Read %v but should have read %v", msg, expected)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("Closing the socket should have disconnected the reader but read returned %v", err)
+	}
+}
+
+func TestSocketDisconnectsSlowReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := newSocket(logging.NoLog{}, filepath.Join(dir, "chain.ipc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	slow, err := net.Dial("unix", s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	awaitReaders(t, s, 1)
+
+	fast, err := net.Dial("unix", s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fast.Close()
+	awaitReaders(t, s, 2)
+
+	// The slow reader never reads, which mustn't block sending to the fast
+	// reader, which reads each message before the next is sent
+	msg := bytes.Repeat([]byte{1}, 16*1024)
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2*maxQueuedMsgs; i++ {
+			s.Send(msg)
+			if _, err := io.ReadFull(fast, make([]byte, headerLen+len(msg))); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Sending shouldn't have blocked on the slow reader")
+	}
+	awaitReaders(t, s, 1)
+}
+
+func TestSocketPathIsPrivate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "ipcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	os.Setenv("TMPDIR", tmpDir)
+
+	path, err := socketPath("chain.ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := newSocket(logging.NoLog{}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for path, expected := range map[string]os.FileMode{filepath.Dir(path): 0700, path: 0600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != expected {
+			t.Fatalf("%s has mode %o ; Expected %o", path, mode, expected)
+		}
+	}
+}
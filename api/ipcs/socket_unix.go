@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !windows
+// +build !windows
+
+package ipcs
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// socketPath returns the path of the Unix domain socket named [name]. The
+// socket is in a directory of the temporary directory that only the user
+// running the node can access.
+func socketPath(name string) (string, error) {
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("gecko-ipcs-%d", os.Getuid()))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	// The directory may have been created by another user, who could then
+	// replace the socket
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); !info.IsDir() || !ok || int(stat.Uid) != os.Getuid() {
+		return "", fmt.Errorf("%s isn't a directory owned by the user running the node", dir)
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// unixListener accepts the readers of a Unix domain socket
+type unixListener struct{ net.Listener }
+
+func (l unixListener) Accept() (io.WriteCloser, error) { return l.Listener.Accept() }
+
+// listen on the Unix domain socket at [path], replacing the socket left there
+// by a node that didn't shut down cleanly
+func listen(path string) (listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return unixListener{l}, nil
+}
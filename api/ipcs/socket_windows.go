@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ipcs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	pipeAccessOutbound        = 0x00000002
+	fileFlagFirstPipeInstance = 0x00080000
+	pipeUnlimitedInstances    = 255
+	pipeBufferSize            = 1 << 16
+
+	errorPipeConnected = syscall.Errno(535)
+)
+
+var (
+	kernel32          = syscall.NewLazyDLL("kernel32.dll")
+	createNamedPipe   = kernel32.NewProc("CreateNamedPipeW")
+	connectNamedPipe  = kernel32.NewProc("ConnectNamedPipe")
+	errListenerClosed = errors.New("named pipe listener is closed")
+)
+
+// socketPath returns the path of the named pipe named [name]
+func socketPath(name string) (string, error) {
+	return `\\.\pipe\` + name, nil
+}
+
+// pipeListener accepts the readers of a named pipe. Each reader is connected
+// to its own instance of the pipe.
+type pipeListener struct {
+	path string
+
+	lock   sync.Mutex
+	next   syscall.Handle // Instance the next reader connects to
+	closed bool
+}
+
+// listen on the named pipe at [path]. Fails if another process has the pipe.
+func listen(path string) (listener, error) {
+	h, err := newPipeInstance(path, fileFlagFirstPipeInstance)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeListener{path: path, next: h}, nil
+}
+
+func newPipeInstance(path string, flags uint32) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return syscall.InvalidHandle, err
+	}
+	h, _, err := createNamedPipe.Call(
+		uintptr(unsafe.Pointer(name)),
+		uintptr(pipeAccessOutbound|flags),
+		0, // Byte stream, blocking
+		pipeUnlimitedInstances,
+		pipeBufferSize,
+		0,
+		0,
+		0,
+	)
+	if syscall.Handle(h) == syscall.InvalidHandle {
+		return syscall.InvalidHandle, err
+	}
+	return syscall.Handle(h), nil
+}
+
+func (l *pipeListener) Accept() (io.WriteCloser, error) {
+	l.lock.Lock()
+	h := l.next
+	closed := l.closed
+	l.lock.Unlock()
+	if closed {
+		syscall.CloseHandle(h)
+		return nil, errListenerClosed
+	}
+
+	// Blocks until a reader connects, or until Close connects to unblock it
+	if ok, _, err := connectNamedPipe.Call(uintptr(h), 0); ok == 0 && err != errorPipeConnected {
+		syscall.CloseHandle(h)
+		return nil, err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.closed {
+		syscall.CloseHandle(h)
+		return nil, errListenerClosed
+	}
+	next, err := newPipeInstance(l.path, 0)
+	if err != nil {
+		syscall.CloseHandle(h)
+		l.closed = true
+		return nil, err
+	}
+	l.next = next
+	return os.NewFile(uintptr(h), l.path), nil
+}
+
+func (l *pipeListener) Close() error {
+	l.lock.Lock()
+	if l.closed {
+		l.lock.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.lock.Unlock()
+
+	// Connect to the instance Accept is waiting on so that it returns
+	name, err := syscall.UTF16PtrFromString(l.path)
+	if err != nil {
+		return err
+	}
+	if h, err := syscall.CreateFile(name, syscall.GENERIC_READ, 0, nil, syscall.OPEN_EXISTING, 0, 0); err == nil {
+		syscall.CloseHandle(h)
+	}
+	return nil
+}
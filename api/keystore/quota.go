@@ -0,0 +1,185 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+var errQuotaExceeded = errors.New("the user's storage quota would be exceeded")
+
+// usage is the number of bytes of keys and values a user's data uses, across
+// every chain
+type usage struct {
+	lock  sync.Mutex
+	bytes int
+	// If positive, the number of bytes the data may use
+	quota int
+}
+
+// countUsage returns the usage of the data in [db]
+func countUsage(db database.Iteratee, quota int) (*usage, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	u := &usage{quota: quota}
+	for it.Next() {
+		u.bytes += len(it.Key()) + len(it.Value())
+	}
+	return u, it.Error()
+}
+
+// check returns errQuotaExceeded if adding [delta] bytes would grow the usage
+// past the quota. Assumes the lock is held.
+func (u *usage) check(delta int) error {
+	if delta > 0 && u.quota > 0 && u.bytes+delta > u.quota {
+		return errQuotaExceeded
+	}
+	return nil
+}
+
+// quotaDB fails the writes that would make a user's data use more bytes than
+// its quota allows. Writes are serialized so the usage is kept exact.
+type quotaDB struct {
+	database.Database
+	usage *usage
+}
+
+// size returns the number of bytes [key] and its value use. Assumes the usage
+// lock is held.
+func (db *quotaDB) size(key []byte) (int, error) {
+	value, err := db.Database.Get(key)
+	switch err {
+	case nil:
+		return len(key) + len(value), nil
+	case database.ErrNotFound:
+		return 0, nil
+	default:
+		return 0, err
+	}
+}
+
+// Put implements the Database interface
+func (db *quotaDB) Put(key, value []byte) error {
+	db.usage.lock.Lock()
+	defer db.usage.lock.Unlock()
+
+	oldSize, err := db.size(key)
+	if err != nil {
+		return err
+	}
+	delta := len(key) + len(value) - oldSize
+	if err := db.usage.check(delta); err != nil {
+		return err
+	}
+	if err := db.Database.Put(key, value); err != nil {
+		return err
+	}
+	db.usage.bytes += delta
+	return nil
+}
+
+// Delete implements the Database interface
+func (db *quotaDB) Delete(key []byte) error {
+	db.usage.lock.Lock()
+	defer db.usage.lock.Unlock()
+
+	oldSize, err := db.size(key)
+	if err != nil {
+		return err
+	}
+	if err := db.Database.Delete(key); err != nil {
+		return err
+	}
+	db.usage.bytes -= oldSize
+	return nil
+}
+
+// DeleteRange implements the Database interface
+func (db *quotaDB) DeleteRange(start, end []byte) error {
+	db.usage.lock.Lock()
+	defer db.usage.lock.Unlock()
+
+	it := db.Database.NewIteratorWithStart(start)
+	removed := 0
+	for it.Next() && (end == nil || bytes.Compare(it.Key(), end) < 0) {
+		removed += len(it.Key()) + len(it.Value())
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return err
+	}
+
+	if err := db.Database.DeleteRange(start, end); err != nil {
+		return err
+	}
+	db.usage.bytes -= removed
+	return nil
+}
+
+// NewBatch implements the Database interface
+func (db *quotaDB) NewBatch() database.Batch {
+	return &quotaBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+		sizes: make(map[string]int),
+	}
+}
+
+// quotaBatch fails to write if the writes would make the user's data use more
+// bytes than its quota allows
+type quotaBatch struct {
+	database.Batch
+	db *quotaDB
+
+	// Key --> the number of bytes the key and its value will use once the
+	// batch is written
+	sizes map[string]int
+}
+
+// Put implements the Batch interface
+func (b *quotaBatch) Put(key, value []byte) error {
+	b.sizes[string(key)] = len(key) + len(value)
+	return b.Batch.Put(key, value)
+}
+
+// Delete implements the Batch interface
+func (b *quotaBatch) Delete(key []byte) error {
+	b.sizes[string(key)] = 0
+	return b.Batch.Delete(key)
+}
+
+// Write implements the Batch interface
+func (b *quotaBatch) Write() error {
+	b.db.usage.lock.Lock()
+	defer b.db.usage.lock.Unlock()
+
+	delta := 0
+	for key, size := range b.sizes {
+		oldSize, err := b.db.size([]byte(key))
+		if err != nil {
+			return err
+		}
+		delta += size - oldSize
+	}
+	if err := b.db.usage.check(delta); err != nil {
+		return err
+	}
+	if err := b.Batch.Write(); err != nil {
+		return err
+	}
+	b.db.usage.bytes += delta
+	return nil
+}
+
+// Reset implements the Batch interface
+func (b *quotaBatch) Reset() {
+	b.sizes = make(map[string]int)
+	b.Batch.Reset()
+}
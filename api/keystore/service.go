@@ -4,6 +4,7 @@
 package keystore
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,9 +15,11 @@ import (
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/encdb"
 	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/vms/components/codec"
 
@@ -70,7 +73,20 @@ type Keystore struct {
 	// Value: The user with that name
 	users map[string]*User
 
+	// If positive, the maximum number of bytes of data each user may store
+	UserQuota int
+
+	// Key: username
+	// Value: The number of bytes of data the user stores. Counted the first
+	// time the user's data is opened.
+	usage map[string]*usage
+
+	// Key: The hashed prefix a blockchain's data is stored after
+	// Value: The ID of the blockchain
+	chains map[string]ids.ID
+
 	// Used to persist users and their data
+	db     database.Database
 	userDB database.Database
 	bcDB   database.Database
 	//           BaseDB
@@ -87,6 +103,9 @@ func (ks *Keystore) Initialize(log logging.Logger, db database.Database) {
 	ks.log = log
 	ks.codec = codec.NewDefault()
 	ks.users = make(map[string]*User)
+	ks.usage = make(map[string]*usage)
+	ks.chains = make(map[string]ids.ID)
+	ks.db = db
 	ks.userDB = prefixdb.New([]byte("users"), db)
 	ks.bcDB = prefixdb.New([]byte("bcs"), db)
 }
@@ -198,6 +217,8 @@ func (ks *Keystore) ListUsers(_ *http.Request, args *ListUsersArgs, reply *ListU
 type ExportUserArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// If set, the exported user is encrypted with a key derived from it
+	Passphrase string `json:"passphrase"`
 }
 
 // ExportUserReply is the reply from ExportUser
@@ -205,7 +226,8 @@ type ExportUserReply struct {
 	User string `json:"user"`
 }
 
-// ExportUser exports a serialized encoding of a user's information complete with encrypted database values
+// ExportUser exports a serialized encoding of a user's information complete with encrypted database values.
+// If a passphrase is given, the whole encoding is encrypted with it.
 func (ks *Keystore) ExportUser(_ *http.Request, args *ExportUserArgs, reply *ExportUserReply) error {
 	ks.lock.Lock()
 	defer ks.lock.Unlock()
@@ -242,6 +264,11 @@ func (ks *Keystore) ExportUser(_ *http.Request, args *ExportUserArgs, reply *Exp
 	if err != nil {
 		return err
 	}
+	if args.Passphrase != "" {
+		if b, err = encdb.EncryptValue([]byte(args.Passphrase), b, encdb.DefaultConfig); err != nil {
+			return err
+		}
+	}
 	cb58 := formatting.CB58{Bytes: b}
 	reply.User = cb58.String()
 	return nil
@@ -252,6 +279,8 @@ type ImportUserArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	User     string `json:"user"`
+	// The passphrase the user was exported with, if any
+	Passphrase string `json:"passphrase"`
 }
 
 // ImportUserReply is the response for ImportUser
@@ -259,7 +288,8 @@ type ImportUserReply struct {
 	Success bool `json:"success"`
 }
 
-// ImportUser imports a serialized encoding of a user's information complete with encrypted database values, integrity checks the password, and adds it to the database
+// ImportUser imports a serialized encoding of a user's information complete with encrypted database values, integrity checks the password, and adds it to the database.
+// If the user was exported with a passphrase, the same passphrase must be given.
 func (ks *Keystore) ImportUser(r *http.Request, args *ImportUserArgs, reply *ImportUserReply) error {
 	ks.lock.Lock()
 	defer ks.lock.Unlock()
@@ -275,36 +305,130 @@ func (ks *Keystore) ImportUser(r *http.Request, args *ImportUserArgs, reply *Imp
 		return err
 	}
 
+	userBytes := cb58.Bytes
+	if args.Passphrase != "" {
+		var err error
+		if userBytes, err = encdb.DecryptValue([]byte(args.Passphrase), userBytes); err != nil {
+			return fmt.Errorf("couldn't decrypt user with the passphrase: %w", err)
+		}
+	}
+
 	userData := UserDB{}
-	if err := ks.codec.Unmarshal(cb58.Bytes, &userData); err != nil {
+	if err := ks.codec.Unmarshal(userBytes, &userData); err != nil {
 		return err
 	}
+	if !userData.User.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
 
 	usrBytes, err := ks.codec.Marshal(&userData.User)
 	if err != nil {
 		return err
 	}
 
-	// TODO: Should add batching to prevent creating a user without importing
-	// the account
-	if err := ks.userDB.Put([]byte(args.Username), usrBytes); err != nil {
+	// The user and its data are committed together, so a user is never
+	// created without its data
+	vdb := versiondb.New(ks.db)
+	if err := prefixdb.New([]byte("users"), vdb).Put([]byte(args.Username), usrBytes); err != nil {
+		return err
+	}
+	userDB := prefixdb.New([]byte(args.Username), prefixdb.New([]byte("bcs"), vdb))
+	for _, kvp := range userData.Data {
+		if err := userDB.Put(kvp.Key, kvp.Value); err != nil {
+			return err
+		}
+	}
+	if err := vdb.Commit(); err != nil {
+		return err
+	}
+
+	if err := ks.recountUsage(args.Username); err != nil {
 		return err
 	}
 	ks.users[args.Username] = &userData.User
+	reply.Success = true
+	return nil
+}
 
-	userDB := prefixdb.New([]byte(args.Username), ks.bcDB)
-	batch := userDB.NewBatch()
+// ChangePasswordArgs are the arguments to ChangePassword
+type ChangePasswordArgs struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	NewPassword string `json:"newPassword"`
+}
 
-	for _, kvp := range userData.Data {
-		batch.Put(kvp.Key, kvp.Value)
+// ChangePasswordReply is the reply from ChangePassword
+type ChangePasswordReply struct {
+	Success bool `json:"success"`
+}
+
+// ChangePassword changes the password of a user, and re-encrypts the user's
+// data with the new password
+func (ks *Keystore) ChangePassword(_ *http.Request, args *ChangePasswordArgs, reply *ChangePasswordReply) error {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.log.Verbo("ChangePassword called for %s", args.Username)
+
+	if len(args.NewPassword) > maxUserPassLen {
+		return errUserPassMaxLength
+	}
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return err
+	}
+	if !usr.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	if zxcvbn.PasswordStrength(args.NewPassword, nil).Score < requiredPassScore {
+		return errWeakPassword
 	}
 
+	newUsr := &User{}
+	if err := newUsr.Initialize(args.NewPassword); err != nil {
+		return err
+	}
+	usrBytes, err := ks.codec.Marshal(newUsr)
+	if err != nil {
+		return err
+	}
+
+	// The user and the re-encrypted data are committed together, so the data
+	// is always encrypted with the user's password
+	vdb := versiondb.New(ks.db)
+	userDB := prefixdb.New([]byte(args.Username), prefixdb.New([]byte("bcs"), vdb))
+	encDB, err := encdb.New([]byte(args.Password), userDB)
+	if err != nil {
+		return err
+	}
+	// The data is re-encrypted with a key derived with Argon2id, even if it
+	// was encrypted with a key hashed from the old password
+	if err := encDB.RotateKeyWithConfig([]byte(args.NewPassword), encdb.DefaultConfig); err != nil {
+		return err
+	}
+	if err := prefixdb.New([]byte("users"), vdb).Put([]byte(args.Username), usrBytes); err != nil {
+		return err
+	}
+	if err := vdb.Commit(); err != nil {
+		return err
+	}
+
+	if err := ks.recountUsage(args.Username); err != nil {
+		return err
+	}
+	ks.users[args.Username] = newUsr
 	reply.Success = true
-	return batch.Write()
+	return nil
 }
 
 // NewBlockchainKeyStore ...
 func (ks *Keystore) NewBlockchainKeyStore(blockchainID ids.ID) *BlockchainKeystore {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.chains[string(hashing.ComputeHash256(blockchainID.Bytes()))] = blockchainID
 	return &BlockchainKeystore{
 		blockchainID: blockchainID,
 		ks:           ks,
@@ -325,7 +449,12 @@ func (ks *Keystore) GetDatabase(bID ids.ID, username, password string) (database
 	}
 
 	userDB := prefixdb.New([]byte(username), ks.bcDB)
-	bcDB := prefixdb.NewNested(bID.Bytes(), userDB)
+	usage, err := ks.getUsage(username, userDB)
+	if err != nil {
+		return nil, err
+	}
+	quotaDB := &quotaDB{Database: userDB, usage: usage}
+	bcDB := prefixdb.NewNested(bID.Bytes(), quotaDB)
 	encDB, err := encdb.New([]byte(password), bcDB)
 
 	if err != nil {
@@ -334,3 +463,105 @@ func (ks *Keystore) GetDatabase(bID ids.ID, username, password string) (database
 
 	return encDB, nil
 }
+
+// getUsage returns the usage of the data of [username], which is stored in
+// [userDB]. Assumes the lock is held.
+func (ks *Keystore) getUsage(username string, userDB database.Iteratee) (*usage, error) {
+	if u, exists := ks.usage[username]; exists {
+		return u, nil
+	}
+	u, err := countUsage(userDB, ks.UserQuota)
+	if err != nil {
+		return nil, err
+	}
+	ks.usage[username] = u
+	return u, nil
+}
+
+// recountUsage counts the usage of the data of [username] again, if it has been
+// counted, after the data was written around the quota. Assumes the lock is
+// held.
+func (ks *Keystore) recountUsage(username string) error {
+	u, exists := ks.usage[username]
+	if !exists {
+		return nil
+	}
+	counted, err := countUsage(prefixdb.New([]byte(username), ks.bcDB), ks.UserQuota)
+	if err != nil {
+		return err
+	}
+
+	u.lock.Lock()
+	u.bytes = counted.bytes
+	u.lock.Unlock()
+	return nil
+}
+
+// GetUserUsageArgs are the arguments to GetUserUsage
+type GetUserUsageArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// ChainUsage is the data a user stores for a blockchain
+type ChainUsage struct {
+	// Null if the blockchain isn't running on this node
+	ChainID ids.ID           `json:"chainID"`
+	Bytes   jsoncodec.Uint64 `json:"bytes"`
+	Keys    jsoncodec.Uint64 `json:"keys"`
+}
+
+// GetUserUsageReply is the reply from GetUserUsage
+type GetUserUsageReply struct {
+	Bytes jsoncodec.Uint64 `json:"bytes"`
+	Keys  jsoncodec.Uint64 `json:"keys"`
+	// The maximum number of bytes the user may store. If 0, there is no limit.
+	Quota  jsoncodec.Uint64 `json:"quota"`
+	Chains []ChainUsage     `json:"chains"`
+}
+
+// GetUserUsage returns the number of bytes and keys a user stores, in total and
+// for each blockchain
+func (ks *Keystore) GetUserUsage(_ *http.Request, args *GetUserUsageArgs, reply *GetUserUsageReply) error {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.log.Verbo("GetUserUsage called for %s", args.Username)
+
+	usr, err := ks.getUser(args.Username)
+	if err != nil {
+		return err
+	}
+	if !usr.CheckPassword(args.Password) {
+		return fmt.Errorf("incorrect password for %s", args.Username)
+	}
+
+	userDB := prefixdb.New([]byte(args.Username), ks.bcDB)
+	it := userDB.NewIterator()
+	defer it.Release()
+
+	reply.Chains = []ChainUsage{}
+	lastPrefix := []byte(nil)
+	for it.Next() {
+		key := it.Key()
+		size := jsoncodec.Uint64(len(key) + len(it.Value()))
+		reply.Bytes += size
+		reply.Keys++
+
+		// A blockchain's keys are stored after the hash of its ID
+		if len(key) < hashing.HashLen {
+			continue
+		}
+		if prefix := key[:hashing.HashLen]; !bytes.Equal(prefix, lastPrefix) {
+			lastPrefix = append([]byte(nil), prefix...)
+			reply.Chains = append(reply.Chains, ChainUsage{ChainID: ks.chains[string(prefix)]})
+		}
+		chain := &reply.Chains[len(reply.Chains)-1]
+		chain.Bytes += size
+		chain.Keys++
+	}
+	if ks.UserQuota > 0 {
+		reply.Quota = jsoncodec.Uint64(ks.UserQuota)
+	}
+	return it.Error()
+}
@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"testing"
 
+	"github.com/ava-labs/gecko/database/encdb"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -280,3 +281,250 @@ func TestServiceExportImport(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceChangePassword(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	newPassword := strongPassword + "!"
+
+	{
+		reply := CreateUserReply{}
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: "bob",
+			Password: strongPassword,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if !reply.Success {
+			t.Fatalf("User should have been created successfully")
+		}
+	}
+
+	{
+		db, err := ks.GetDatabase(ids.Empty, "bob", strongPassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	{
+		reply := ChangePasswordReply{}
+		if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+			Username:    "bob",
+			Password:    newPassword,
+			NewPassword: strongPassword,
+		}, &reply); err == nil {
+			t.Fatalf("Should have errored due to an incorrect password")
+		}
+	}
+
+	{
+		reply := ChangePasswordReply{}
+		if err := ks.ChangePassword(nil, &ChangePasswordArgs{
+			Username:    "bob",
+			Password:    strongPassword,
+			NewPassword: newPassword,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if !reply.Success {
+			t.Fatalf("Password should have been changed successfully")
+		}
+	}
+
+	if _, err := ks.GetDatabase(ids.Empty, "bob", strongPassword); err == nil {
+		t.Fatalf("Should have errored due to the old password")
+	}
+
+	// The data was re-encrypted with a key derived with Argon2id. A value's
+	// header is its version, its cipher and its KDF.
+	{
+		it := ks.bcDB.NewIterator()
+		for it.Next() {
+			if value := it.Value(); len(value) < 3 || encdb.KDF(value[2]) != encdb.Argon2id {
+				t.Fatalf("Value of key 0x%x should have been encrypted with a key derived with Argon2id", it.Key())
+			}
+		}
+		it.Release()
+	}
+
+	{
+		db, err := ks.GetDatabase(ids.Empty, "bob", newPassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := db.Get([]byte("hello")); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, []byte("world")) {
+			t.Fatalf("Should have read '%s' from the db", "world")
+		}
+	}
+}
+
+func TestServiceExportImportPassphrase(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+
+	passphrase := "export passphrase"
+
+	{
+		reply := CreateUserReply{}
+		if err := ks.CreateUser(nil, &CreateUserArgs{
+			Username: "bob",
+			Password: strongPassword,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	{
+		db, err := ks.GetDatabase(ids.Empty, "bob", strongPassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exportReply := ExportUserReply{}
+	if err := ks.ExportUser(nil, &ExportUserArgs{
+		Username:   "bob",
+		Password:   strongPassword,
+		Passphrase: passphrase,
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+
+	newKS := Keystore{}
+	newKS.Initialize(logging.NoLog{}, memdb.New())
+
+	if err := newKS.ImportUser(nil, &ImportUserArgs{
+		Username:   "bob",
+		Password:   strongPassword,
+		User:       exportReply.User,
+		Passphrase: "wrong passphrase",
+	}, &ImportUserReply{}); err == nil {
+		t.Fatalf("Should have errored due to an incorrect passphrase")
+	}
+
+	if err := newKS.ImportUser(nil, &ImportUserArgs{
+		Username:   "bob",
+		Password:   strongPassword + "!",
+		User:       exportReply.User,
+		Passphrase: passphrase,
+	}, &ImportUserReply{}); err == nil {
+		t.Fatalf("Should have errored due to an incorrect password")
+	}
+
+	{
+		reply := ImportUserReply{}
+		if err := newKS.ImportUser(nil, &ImportUserArgs{
+			Username:   "bob",
+			Password:   strongPassword,
+			User:       exportReply.User,
+			Passphrase: passphrase,
+		}, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if !reply.Success {
+			t.Fatalf("User should have been imported successfully")
+		}
+	}
+
+	{
+		db, err := newKS.GetDatabase(ids.Empty, "bob", strongPassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, err := db.Get([]byte("hello")); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, []byte("world")) {
+			t.Fatalf("Should have read '%s' from the db", "world")
+		}
+	}
+}
+
+func TestServiceUserQuota(t *testing.T) {
+	ks := Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	ks.UserQuota = 1024
+
+	reply := CreateUserReply{}
+	if err := ks.CreateUser(nil, &CreateUserArgs{
+		Username: "bob",
+		Password: strongPassword,
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+
+	chainID := ids.NewID([32]byte{1})
+	ks.NewBlockchainKeyStore(chainID)
+	db, err := ks.GetDatabase(chainID, "bob", strongPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherDB, err := ks.GetDatabase(ids.Empty, "bob", strongPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Put([]byte("small"), make([]byte, 256)); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherDB.Put([]byte("large"), make([]byte, 640)); err != errQuotaExceeded {
+		t.Fatalf("Should have exceeded the quota but returned %v", err)
+	}
+	batch := otherDB.NewBatch()
+	if err := batch.Put([]byte("large"), make([]byte, 640)); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != errQuotaExceeded {
+		t.Fatalf("Should have exceeded the quota but returned %v", err)
+	}
+	if err := otherDB.Put([]byte("medium"), make([]byte, 128)); err != nil {
+		t.Fatal(err)
+	}
+
+	usageReply := GetUserUsageReply{}
+	if err := ks.GetUserUsage(nil, &GetUserUsageArgs{
+		Username: "bob",
+		Password: strongPassword,
+	}, &usageReply); err != nil {
+		t.Fatal(err)
+	}
+	if usageReply.Keys != 2 {
+		t.Fatalf("Should have stored 2 keys but stored %d", usageReply.Keys)
+	}
+	if usageReply.Quota != 1024 {
+		t.Fatalf("Should have reported a quota of 1024 but reported %d", usageReply.Quota)
+	}
+	if len(usageReply.Chains) != 2 {
+		t.Fatalf("Should have stored data for 2 chains but stored data for %d", len(usageReply.Chains))
+	}
+	total := uint64(0)
+	for _, chain := range usageReply.Chains {
+		if chain.Keys != 1 {
+			t.Fatalf("Should have stored 1 key for each chain but stored %d", chain.Keys)
+		}
+		if !chain.ChainID.IsZero() && !chain.ChainID.Equals(chainID) {
+			t.Fatalf("Unexpected chain %s", chain.ChainID)
+		}
+		total += uint64(chain.Bytes)
+	}
+	if total != uint64(usageReply.Bytes) {
+		t.Fatalf("Chains stored %d bytes but the user stored %d", total, usageReply.Bytes)
+	}
+
+	// Deleting data frees space for more
+	if err := db.Delete([]byte("small")); err != nil {
+		t.Fatal(err)
+	}
+	if err := otherDB.Put([]byte("large"), make([]byte, 640)); err != nil {
+		t.Fatal(err)
+	}
+}
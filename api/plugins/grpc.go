@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/rpc/jsoncodec"
+)
+
+const (
+	// BackendService is the name of the gRPC service an external backend
+	// serves. Its messages are encoded as JSON, with the content subtype
+	// "json".
+	BackendService = "gecko.plugins.Backend"
+
+	initializeMethod = "/" + BackendService + "/Initialize"
+	handleMethod     = "/" + BackendService + "/Handle"
+
+	// initializeTimeout is how long a backend has to respond to Initialize
+	initializeTimeout = 10 * time.Second
+
+	// maxRequestSize is the largest request body that's forwarded to a backend
+	maxRequestSize = 1 << 22
+)
+
+// NodeInfo is sent to a backend when it's initialized. Backends query the
+// node's chains through the node service at [NodeAddr], passing [NodeToken]
+// with each call.
+type NodeInfo struct {
+	NodeID    string `json:"nodeID"`
+	NetworkID uint32 `json:"networkID"`
+	Version   string `json:"version"`
+	NodeAddr  string `json:"nodeAddr"`
+	NodeToken string `json:"nodeToken"`
+}
+
+// InitializeReply is a backend's reply to Initialize
+type InitializeReply struct {
+	// Extensions of the plugin's base URL the backend handles
+	Endpoints []string `json:"endpoints"`
+}
+
+// HTTPRequest is an API request that's forwarded to a backend
+type HTTPRequest struct {
+	Endpoint string      `json:"endpoint"`
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// HTTPResponse is a backend's response to an HTTPRequest
+type HTTPResponse struct {
+	Code   int         `json:"code"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// BackendServer is implemented by external backends that are written in Go
+type BackendServer interface {
+	Initialize(context.Context, *NodeInfo) (*InitializeReply, error)
+	Handle(context.Context, *HTTPRequest) (*HTTPResponse, error)
+}
+
+// RegisterBackendServer registers [srv] as the backend served by [s]
+func RegisterBackendServer(s *grpc.Server, srv BackendServer) {
+	s.RegisterService(&backendServiceDesc, srv)
+}
+
+var backendServiceDesc = grpc.ServiceDesc{
+	ServiceName: BackendService,
+	HandlerType: (*BackendServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Initialize",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &NodeInfo{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(BackendServer).Initialize(ctx, req.(*NodeInfo))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: initializeMethod}, handler)
+			},
+		},
+		{
+			MethodName: "Handle",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &HTTPRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(BackendServer).Handle(ctx, req.(*HTTPRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: handleMethod}, handler)
+			},
+		},
+	},
+}
+
+// grpcPlugin forwards the API requests it receives to an external backend
+type grpcPlugin struct {
+	addr string
+	log  logging.Logger
+	conn *grpc.ClientConn
+
+	// nodeServer serves the node to the backend
+	nodeServer *grpc.Server
+}
+
+func (p *grpcPlugin) Initialize(node Node, log logging.Logger) (map[string]*common.HTTPHandler, error) {
+	conn, err := grpc.Dial(
+		p.addr,
+		grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsoncodec.Name)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeServer, nodeAddr, nodeToken, err := serveNode(node, log)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("couldn't serve the node to the backend at %s: %w", p.addr, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), initializeTimeout)
+	defer cancel()
+
+	reply := &InitializeReply{}
+	info := &NodeInfo{
+		NodeID:    node.NodeID().String(),
+		NetworkID: node.NetworkID(),
+		Version:   node.Version(),
+		NodeAddr:  nodeAddr,
+		NodeToken: nodeToken,
+	}
+	if err := conn.Invoke(ctx, initializeMethod, info, reply, grpc.WaitForReady(true)); err != nil {
+		nodeServer.Stop()
+		conn.Close()
+		return nil, fmt.Errorf("couldn't initialize the backend at %s: %w", p.addr, err)
+	}
+
+	p.log = log
+	p.conn = conn
+	p.nodeServer = nodeServer
+	handlers := make(map[string]*common.HTTPHandler, len(reply.Endpoints))
+	for _, endpoint := range reply.Endpoints {
+		handlers[endpoint] = &common.HTTPHandler{
+			LockOptions: common.NoLock,
+			Handler:     &backendHandler{plugin: p, endpoint: endpoint},
+		}
+	}
+	return handlers, nil
+}
+
+func (p *grpcPlugin) Shutdown() {
+	if p.conn == nil {
+		return
+	}
+	p.nodeServer.Stop()
+	if err := p.conn.Close(); err != nil {
+		p.log.Warn("failed to close the connection to the backend at %s: %s", p.addr, err)
+	}
+}
+
+// backendHandler forwards the requests to one endpoint of a backend
+type backendHandler struct {
+	plugin   *grpcPlugin
+	endpoint string
+}
+
+func (h *backendHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &HTTPRequest{
+		Endpoint: h.endpoint,
+		Method:   r.Method,
+		URL:      r.URL.String(),
+		Header:   r.Header,
+		Body:     body,
+	}
+	resp := &HTTPResponse{}
+	if err := h.plugin.conn.Invoke(r.Context(), handleMethod, req, resp); err != nil {
+		h.plugin.log.Debug("backend at %s failed to handle a request to %s: %s", h.plugin.addr, req.URL, err)
+		http.Error(w, "plugin backend failed to handle the request", http.StatusBadGateway)
+		return
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if resp.Code == 0 {
+		resp.Code = http.StatusOK
+	}
+	w.WriteHeader(resp.Code)
+	if _, err := w.Write(resp.Body); err != nil {
+		h.plugin.log.Debug("failed to write the response to a request to %s: %s", req.URL, err)
+	}
+}
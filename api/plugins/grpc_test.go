@@ -0,0 +1,208 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/rpc/jsoncodec"
+)
+
+var errUnknownChain = errors.New("unknown chain")
+
+// testNode runs the single chain [chainID] under the alias [alias]
+type testNode struct {
+	chainID  ids.ID
+	alias    string
+	upgrades snow.Upgrades
+}
+
+func (n *testNode) NodeID() ids.ShortID { return ids.NewShortID([20]byte{1}) }
+
+func (n *testNode) NetworkID() uint32 { return 12345 }
+
+func (n *testNode) Version() string { return "avalanche/0.5.0" }
+
+func (n *testNode) Lookup(alias string) (ids.ID, error) {
+	if alias != n.alias {
+		return ids.ID{}, errUnknownChain
+	}
+	return n.chainID, nil
+}
+
+func (n *testNode) Aliases(chainID ids.ID) []string {
+	if !chainID.Equals(n.chainID) {
+		return nil
+	}
+	return []string{n.alias}
+}
+
+func (n *testNode) ChainVersion(chainID ids.ID) (string, snow.Upgrades, error) {
+	if !chainID.Equals(n.chainID) {
+		return "", nil, errUnknownChain
+	}
+	return "avm/1.0.0", n.upgrades, nil
+}
+
+type testBackend struct {
+	info     *NodeInfo
+	requests []*HTTPRequest
+}
+
+func (b *testBackend) Initialize(_ context.Context, info *NodeInfo) (*InitializeReply, error) {
+	b.info = info
+	return &InitializeReply{Endpoints: []string{"", "/echo"}}, nil
+}
+
+func (b *testBackend) Handle(_ context.Context, req *HTTPRequest) (*HTTPResponse, error) {
+	b.requests = append(b.requests, req)
+	return &HTTPResponse{
+		Code:   http.StatusTeapot,
+		Header: http.Header{"X-Endpoint": []string{req.Endpoint}},
+		Body:   req.Body,
+	}, nil
+}
+
+func TestGRPCPlugin(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &testBackend{}
+	server := grpc.NewServer()
+	RegisterBackendServer(server, backend)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	plugin, err := Load(GRPCScheme + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Shutdown()
+
+	node := &testNode{
+		chainID:  ids.NewID([32]byte{2}),
+		alias:    "X",
+		upgrades: snow.Upgrades{{Name: "apricot", Height: 10}},
+	}
+	handlers, err := plugin.Initialize(node, logging.NoLog{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend.info.NodeID != node.NodeID().String() || backend.info.NetworkID != 12345 || backend.info.Version != "avalanche/0.5.0" {
+		t.Fatalf("Backend was initialized with the wrong node info: %+v", backend.info)
+	}
+	if len(handlers) != 2 {
+		t.Fatalf("Should have returned a handler for each endpoint but returned %d", len(handlers))
+	}
+
+	handler, ok := handlers["/echo"]
+	if !ok {
+		t.Fatalf("Should have returned a handler for /echo")
+	}
+	req := httptest.NewRequest("POST", "/ext/plugin/test/echo", bytes.NewBufferString("hello"))
+	w := httptest.NewRecorder()
+	handler.Handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("Should have returned the backend's status code but returned %d", w.Code)
+	}
+	if body := w.Body.String(); body != "hello" {
+		t.Fatalf("Should have returned the backend's body but returned %q", body)
+	}
+	if endpoint := w.Header().Get("X-Endpoint"); endpoint != "/echo" {
+		t.Fatalf("Request should have been forwarded to /echo but was forwarded to %q", endpoint)
+	}
+	if len(backend.requests) != 1 || backend.requests[0].URL != "/ext/plugin/test/echo" || backend.requests[0].Method != "POST" {
+		t.Fatalf("Backend received the wrong requests")
+	}
+}
+
+func TestGRPCPluginNodeService(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &testBackend{}
+	server := grpc.NewServer()
+	RegisterBackendServer(server, backend)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	plugin, err := Load(GRPCScheme + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plugin.Shutdown()
+
+	node := &testNode{
+		chainID:  ids.NewID([32]byte{2}),
+		alias:    "X",
+		upgrades: snow.Upgrades{{Name: "apricot", Height: 10}},
+	}
+	if _, err := plugin.Initialize(node, logging.NoLog{}); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(
+		backend.info.NodeAddr,
+		grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsoncodec.Name)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	client := NewNodeClient(backend.info, conn, logging.NoLog{})
+	if !client.NodeID().Equals(node.NodeID()) || client.NetworkID() != node.NetworkID() || client.Version() != node.Version() {
+		t.Fatalf("Client returned the wrong node info")
+	}
+	if chainID, err := client.Lookup("X"); err != nil {
+		t.Fatal(err)
+	} else if !chainID.Equals(node.chainID) {
+		t.Fatalf("Lookup returned %s but should have returned %s", chainID, node.chainID)
+	}
+	if _, err := client.Lookup("P"); err == nil {
+		t.Fatalf("Lookup of an unknown alias should have failed")
+	}
+	if aliases := client.Aliases(node.chainID); !reflect.DeepEqual(aliases, []string{"X"}) {
+		t.Fatalf("Aliases returned %v but should have returned [X]", aliases)
+	}
+	version, upgrades, err := client.ChainVersion(node.chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != "avm/1.0.0" || len(upgrades) != 1 || upgrades[0].Name != "apricot" || upgrades[0].Height != 10 {
+		t.Fatalf("ChainVersion returned %s %+v", version, upgrades)
+	}
+
+	reply := &LookupReply{}
+	if err := conn.Invoke(context.Background(), lookupMethod, &LookupRequest{Alias: "X"}, reply); err == nil {
+		t.Fatalf("Call without the token should have been rejected")
+	}
+	wrongToken := *backend.info
+	wrongToken.NodeToken = "wrong"
+	if _, err := NewNodeClient(&wrongToken, conn, logging.NoLog{}).Lookup("X"); err == nil {
+		t.Fatalf("Call with the wrong token should have been rejected")
+	}
+}
+
+func TestLoadMissingGoPlugin(t *testing.T) {
+	if _, err := Load("/nonexistent/plugin.so"); err == nil {
+		t.Fatalf("Should have failed to load a missing plugin")
+	}
+}
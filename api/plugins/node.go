@@ -0,0 +1,64 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package plugins
+
+import (
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+)
+
+// Node is the read-only view of the node that plugins are given
+type Node interface {
+	// NodeID returns the ID of this node
+	NodeID() ids.ShortID
+
+	// NetworkID returns the ID of the network this node is running on
+	NetworkID() uint32
+
+	// Version returns the version of this node
+	Version() string
+
+	// Lookup returns the ID of the chain with [alias]
+	Lookup(alias string) (ids.ID, error)
+
+	// Aliases returns the aliases of the chain [chainID]
+	Aliases(chainID ids.ID) []string
+
+	// ChainVersion returns the version of the VM running the chain [chainID]
+	// and the upgrades scheduled for the chain
+	ChainVersion(chainID ids.ID) (string, snow.Upgrades, error)
+}
+
+type node struct {
+	nodeID       ids.ShortID
+	networkID    uint32
+	version      string
+	chainManager chains.Manager
+}
+
+// NewNode returns a read-only view of the node [nodeID], which runs
+// [version] on the network [networkID] and its chains with [chainManager]
+func NewNode(nodeID ids.ShortID, networkID uint32, version string, chainManager chains.Manager) Node {
+	return &node{
+		nodeID:       nodeID,
+		networkID:    networkID,
+		version:      version,
+		chainManager: chainManager,
+	}
+}
+
+func (n *node) NodeID() ids.ShortID { return n.nodeID }
+
+func (n *node) NetworkID() uint32 { return n.networkID }
+
+func (n *node) Version() string { return n.version }
+
+func (n *node) Lookup(alias string) (ids.ID, error) { return n.chainManager.Lookup(alias) }
+
+func (n *node) Aliases(chainID ids.ID) []string { return n.chainManager.Aliases(chainID) }
+
+func (n *node) ChainVersion(chainID ids.ID) (string, snow.Upgrades, error) {
+	return n.chainManager.Version(chainID)
+}
@@ -0,0 +1,228 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package plugins
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// NodeService is the name of the gRPC service the node serves to an
+	// external backend, so the backend has the view of the node a Go plugin
+	// gets. Its messages are encoded as JSON, with the content subtype "json".
+	//
+	// The service is served on the loopback interface. Each call must carry
+	// the token the backend was initialized with as the metadata [TokenKey].
+	NodeService = "gecko.plugins.Node"
+
+	// TokenKey is the metadata key of the token that authenticates a call to
+	// the node service
+	TokenKey = "gecko-plugin-token"
+
+	lookupMethod       = "/" + NodeService + "/Lookup"
+	aliasesMethod      = "/" + NodeService + "/Aliases"
+	chainVersionMethod = "/" + NodeService + "/ChainVersion"
+
+	// tokenSize is the number of random bytes of a token
+	tokenSize = 32
+)
+
+var errWrongToken = errors.New("missing or wrong plugin token")
+
+// LookupRequest asks for the ID of the chain with [Alias]
+type LookupRequest struct {
+	Alias string `json:"alias"`
+}
+
+// LookupReply is the node's reply to a LookupRequest
+type LookupReply struct {
+	ChainID ids.ID `json:"chainID"`
+}
+
+// ChainRequest asks about the chain [ChainID]
+type ChainRequest struct {
+	ChainID ids.ID `json:"chainID"`
+}
+
+// AliasesReply is the node's reply to an Aliases request
+type AliasesReply struct {
+	Aliases []string `json:"aliases"`
+}
+
+// ChainVersionReply is the node's reply to a ChainVersion request
+type ChainVersionReply struct {
+	Version  string        `json:"version"`
+	Upgrades snow.Upgrades `json:"upgrades"`
+}
+
+// nodeServer serves a Node to an external backend
+type nodeServer struct{ node Node }
+
+func (s *nodeServer) lookup(req *LookupRequest) (*LookupReply, error) {
+	chainID, err := s.node.Lookup(req.Alias)
+	if err != nil {
+		return nil, err
+	}
+	return &LookupReply{ChainID: chainID}, nil
+}
+
+func (s *nodeServer) aliases(req *ChainRequest) (*AliasesReply, error) {
+	return &AliasesReply{Aliases: s.node.Aliases(req.ChainID)}, nil
+}
+
+func (s *nodeServer) chainVersion(req *ChainRequest) (*ChainVersionReply, error) {
+	version, upgrades, err := s.node.ChainVersion(req.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainVersionReply{Version: version, Upgrades: upgrades}, nil
+}
+
+// nodeMethod returns the description of the method [name] of the node service,
+// whose requests are decoded into a new value returned by [newReq] and handled
+// by [handle]
+func nodeMethod(name string, newReq func() interface{}, handle func(s *nodeServer, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newReq()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(_ context.Context, req interface{}) (interface{}, error) {
+				return handle(srv.(*nodeServer), req)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + NodeService + "/" + name}, handler)
+		},
+	}
+}
+
+var nodeServiceDesc = grpc.ServiceDesc{
+	ServiceName: NodeService,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		nodeMethod("Lookup", func() interface{} { return &LookupRequest{} }, func(s *nodeServer, req interface{}) (interface{}, error) {
+			return s.lookup(req.(*LookupRequest))
+		}),
+		nodeMethod("Aliases", func() interface{} { return &ChainRequest{} }, func(s *nodeServer, req interface{}) (interface{}, error) {
+			return s.aliases(req.(*ChainRequest))
+		}),
+		nodeMethod("ChainVersion", func() interface{} { return &ChainRequest{} }, func(s *nodeServer, req interface{}) (interface{}, error) {
+			return s.chainVersion(req.(*ChainRequest))
+		}),
+	},
+}
+
+// serveNode serves [node] on the loopback interface. Returns the server, its
+// address and the token calls to it must carry.
+func serveNode(node Node, log logging.Logger) (*grpc.Server, string, string, error) {
+	tokenBytes := make([]byte, tokenSize)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, "", "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", "", err
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		tokens := md.Get(TokenKey)
+		if len(tokens) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(token)) != 1 {
+			return nil, errWrongToken
+		}
+		return handler(ctx, req)
+	}))
+	server.RegisterService(&nodeServiceDesc, &nodeServer{node: node})
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Debug("stopped serving the node to a plugin backend: %s", err)
+		}
+	}()
+	return server, listener.Addr().String(), token, nil
+}
+
+// NodeClient is the view of the node that an external backend written in Go
+// gets. It implements Node over the node service.
+type NodeClient struct {
+	info *NodeInfo
+	conn *grpc.ClientConn
+	log  logging.Logger
+}
+
+// NewNodeClient returns the view of the node that initialized the backend with
+// [info], whose node service is reached over [conn]. Failures of the methods
+// that can't return an error are logged to [log].
+func NewNodeClient(info *NodeInfo, conn *grpc.ClientConn, log logging.Logger) *NodeClient {
+	return &NodeClient{
+		info: info,
+		conn: conn,
+		log:  log,
+	}
+}
+
+// call the method [method] of the node service
+func (c *NodeClient) call(method string, req, reply interface{}) error {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), TokenKey, c.info.NodeToken)
+	return c.conn.Invoke(ctx, method, req, reply)
+}
+
+// NodeID implements the Node interface
+func (c *NodeClient) NodeID() ids.ShortID {
+	nodeID, err := ids.ShortFromString(c.info.NodeID)
+	if err != nil {
+		c.log.Warn("couldn't parse the node ID %s: %s", c.info.NodeID, err)
+	}
+	return nodeID
+}
+
+// NetworkID implements the Node interface
+func (c *NodeClient) NetworkID() uint32 { return c.info.NetworkID }
+
+// Version implements the Node interface
+func (c *NodeClient) Version() string { return c.info.Version }
+
+// Lookup implements the Node interface
+func (c *NodeClient) Lookup(alias string) (ids.ID, error) {
+	reply := &LookupReply{}
+	if err := c.call(lookupMethod, &LookupRequest{Alias: alias}, reply); err != nil {
+		return ids.ID{}, err
+	}
+	return reply.ChainID, nil
+}
+
+// Aliases implements the Node interface
+func (c *NodeClient) Aliases(chainID ids.ID) []string {
+	reply := &AliasesReply{}
+	if err := c.call(aliasesMethod, &ChainRequest{ChainID: chainID}, reply); err != nil {
+		c.log.Warn("couldn't get the aliases of chain %s: %s", chainID, err)
+		return nil
+	}
+	return reply.Aliases
+}
+
+// ChainVersion implements the Node interface
+func (c *NodeClient) ChainVersion(chainID ids.ID) (string, snow.Upgrades, error) {
+	reply := &ChainVersionReply{}
+	if err := c.call(chainVersionMethod, &ChainRequest{ChainID: chainID}, reply); err != nil {
+		return "", nil, err
+	}
+	return reply.Version, reply.Upgrades, nil
+}
@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package plugins
+
+import (
+	"fmt"
+	"plugin"
+	"strings"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// GRPCScheme prefixes the address of an external gRPC backend
+const GRPCScheme = "grpc://"
+
+// NewSymbol is the name of the function a Go plugin exports to create its
+// Plugin. Its type must be func() Plugin.
+const NewSymbol = "New"
+
+// Plugin is an API service that's loaded when the node starts. Its endpoints
+// are served under /ext/plugin/<name>.
+type Plugin interface {
+	// Initialize the plugin with a read-only view of the node. Returns the
+	// plugin's handlers, keyed by their extension of the plugin's base URL,
+	// like a VM's CreateHandlers.
+	Initialize(node Node, log logging.Logger) (map[string]*common.HTTPHandler, error)
+
+	// Shutdown releases the plugin's resources
+	Shutdown()
+}
+
+// Load the plugin at [target], which is either the path of a Go plugin or the
+// address of an external gRPC backend prefixed with grpc://
+func Load(target string) (Plugin, error) {
+	if strings.HasPrefix(target, GRPCScheme) {
+		return &grpcPlugin{addr: strings.TrimPrefix(target, GRPCScheme)}, nil
+	}
+
+	p, err := plugin.Open(target)
+	if err != nil {
+		return nil, err
+	}
+	sym, err := p.Lookup(NewSymbol)
+	if err != nil {
+		return nil, err
+	}
+	newPlugin, ok := sym.(func() Plugin)
+	if !ok {
+		return nil, fmt.Errorf("%s of %s has type %T but should be func() plugins.Plugin", NewSymbol, target, sym)
+	}
+	return newPlugin(), nil
+}
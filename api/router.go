@@ -130,3 +130,38 @@ func (r *router) AddAlias(base string, aliases ...string) error {
 	}
 	return err
 }
+
+// RemoveRouter removes the endpoints of [base] and of its aliases, and frees
+// the aliases of [base] to be used again
+func (r *router) RemoveRouter(base string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.routeLock.Lock()
+	defer r.routeLock.Unlock()
+
+	if _, exists := r.routes[base]; !exists {
+		return errUnknownBaseURL
+	}
+
+	r.removeRoutes(base)
+
+	// The mux router can't remove routes, so the remaining routes are added to
+	// a new one
+	r.router = mux.NewRouter()
+	for base, endpoints := range r.routes {
+		for endpoint, handler := range endpoints {
+			r.router.Handle(base+endpoint, handler)
+		}
+	}
+	return nil
+}
+
+// removeRoutes removes the endpoints of [base] and, recursively, of its aliases
+func (r *router) removeRoutes(base string) {
+	delete(r.routes, base)
+	for _, alias := range r.aliases[base] {
+		delete(r.reservedRoutes, alias)
+		r.removeRoutes(alias)
+	}
+	delete(r.aliases, base)
+}
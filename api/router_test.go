@@ -67,3 +67,47 @@ func TestBlock(t *testing.T) {
 		t.Fatalf("Permanently locked %s", "1")
 	}
 }
+
+func TestRemoveRouter(t *testing.T) {
+	r := newRouter()
+
+	handler := &testHandler{}
+	if err := r.AddRouter("1", "", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRouter("1", "/rpc", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("1", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddAlias("2", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRouter("4", "", handler); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.RemoveRouter("1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, base := range []string{"1", "2", "3"} {
+		if _, err := r.GetHandler(base, ""); err == nil {
+			t.Fatalf("Should have removed %s", base)
+		}
+	}
+	if _, err := r.GetHandler("4", ""); err != nil {
+		t.Fatalf("Shouldn't have removed %s", "4")
+	}
+	if err := r.RemoveRouter("1"); err == nil {
+		t.Fatalf("Should have errored due to %s already being removed", "1")
+	}
+
+	// The removed aliases can be used again
+	if err := r.AddAlias("4", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetHandler("2", ""); err != nil {
+		t.Fatalf("Should have routed the reused alias %s", "2")
+	}
+}
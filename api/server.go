@@ -4,17 +4,21 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"sync"
 
 	"github.com/gorilla/handlers"
 
 	"github.com/rs/cors"
 
+	"github.com/ava-labs/gecko/api/auth"
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -31,27 +35,93 @@ type Server struct {
 	log     logging.Logger
 	factory logging.Factory
 	router  *router
-	portURL string
+	addrs   []string
+
+	// If non-nil, requests to privileged routes must carry a token issued by
+	// [auth]
+	auth *auth.Auth
+
+	lock sync.Mutex
+	srvs []*http.Server
 }
 
-// Initialize creates the API server at the provided port
-func (s *Server) Initialize(log logging.Logger, factory logging.Factory, port uint16) {
+// Initialize creates the API server at the provided port. The server listens
+// on [port] of each of [hosts], or of every interface if no host is given.
+func (s *Server) Initialize(log logging.Logger, factory logging.Factory, port uint16, hosts ...string) {
 	s.log = log
 	s.factory = factory
-	s.portURL = fmt.Sprintf(":%d", port)
+	s.addrs = nil
+	for _, host := range hosts {
+		s.addrs = append(s.addrs, net.JoinHostPort(host, strconv.Itoa(int(port))))
+	}
+	if len(s.addrs) == 0 {
+		s.addrs = []string{fmt.Sprintf(":%d", port)}
+	}
 	s.router = newRouter()
 }
 
-// Dispatch starts the API server
+// Dispatch starts the API server. Returns nil if the server is shut down.
 func (s *Server) Dispatch() error {
-	handler := cors.Default().Handler(s.router)
-	return http.ListenAndServe(s.portURL, handler)
+	return s.serve(func(srv *http.Server) error { return srv.ListenAndServe() })
 }
 
-// DispatchTLS starts the API server with the provided TLS certificate
+// DispatchTLS starts the API server with the provided TLS certificate. Returns
+// nil if the server is shut down.
 func (s *Server) DispatchTLS(certFile, keyFile string) error {
+	return s.serve(func(srv *http.Server) error { return srv.ListenAndServeTLS(certFile, keyFile) })
+}
+
+// serve runs a server for each address with [listen] until they are all
+// stopped. If a server fails, the others are shut down and the error is
+// returned.
+func (s *Server) serve(listen func(*http.Server) error) error {
+	srvs := s.newHTTPServers()
+	errs := make(chan error, len(srvs))
+	for _, srv := range srvs {
+		go func(srv *http.Server) { errs <- listen(srv) }(srv)
+	}
+
+	var firstErr error
+	for range srvs {
+		err := <-errs
+		if err == http.ErrServerClosed || firstErr != nil {
+			continue
+		}
+		firstErr = err
+		if err := s.Shutdown(context.Background()); err != nil {
+			s.log.Warn("failed to stop API server after an error: %s", err)
+		}
+	}
+	return firstErr
+}
+
+// Shutdown stops accepting API requests and waits for the requests that are
+// being handled to finish, or for [ctx] to be done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, srv := range s.srvs {
+		if err := srv.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) newHTTPServers() []*http.Server {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	handler := cors.Default().Handler(s.router)
-	return http.ListenAndServeTLS(s.portURL, certFile, keyFile, handler)
+	s.srvs = make([]*http.Server, len(s.addrs))
+	for i, addr := range s.addrs {
+		s.srvs[i] = &http.Server{
+			Addr:    addr,
+			Handler: handler,
+		}
+	}
+	return s.srvs
 }
 
 // RegisterChain registers the API endpoints associated with this chain That
@@ -88,6 +158,14 @@ func (s *Server) RegisterChain(ctx *snow.Context, vmIntf interface{}) {
 	}
 }
 
+// DeregisterChain removes the API endpoints of this chain, and of its aliases
+func (s *Server) DeregisterChain(ctx *snow.Context) {
+	url := fmt.Sprintf("%s/bc/%s", baseURL, ctx.ChainID)
+	if err := s.router.RemoveRouter(url); err != nil {
+		s.log.Debug("chain %s had no API endpoints to remove: %s", ctx.ChainID, err)
+	}
+}
+
 // AddRoute registers the appropriate endpoint for the vm given an endpoint
 func (s *Server) AddRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base, endpoint string, log logging.Logger) error {
 	url := fmt.Sprintf("%s/%s", baseURL, base)
@@ -113,6 +191,23 @@ func (s *Server) AddRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base,
 	}
 }
 
+// RequireAuth requires the requests to the privileged routes added after this
+// call to carry a token issued by [a] that grants access to them
+func (s *Server) RequireAuth(a *auth.Auth) { s.auth = a }
+
+// AddPrivilegedRoute is AddRoute for endpoints that can read secrets or change
+// the node, such as the Admin API. If auth is required, requests to the route
+// must carry a token that grants access to [base].
+func (s *Server) AddPrivilegedRoute(handler *common.HTTPHandler, lock *sync.RWMutex, base, endpoint string, log logging.Logger) error {
+	if s.auth != nil {
+		handler = &common.HTTPHandler{
+			LockOptions: handler.LockOptions,
+			Handler:     s.auth.WrapHandler(base, handler.Handler),
+		}
+	}
+	return s.AddRoute(handler, lock, base, endpoint, log)
+}
+
 // AddAliases registers aliases to the server
 func (s *Server) AddAliases(endpoint string, aliases ...string) error {
 	url := fmt.Sprintf("%s/%s", baseURL, endpoint)
@@ -5,6 +5,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -58,3 +59,30 @@ func TestCall(t *testing.T) {
 		t.Fatalf("Should have been called")
 	}
 }
+
+func TestShutdown(t *testing.T) {
+	s := Server{}
+	s.Initialize(logging.NoLog{}, logging.NoFactory{}, 0, "127.0.0.1", "localhost")
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutting down a server that wasn't dispatched should have been a no-op but errored with %s", err)
+	}
+
+	srvs := s.newHTTPServers()
+	if len(srvs) != 2 {
+		t.Fatalf("Should have had a listener per host but had %d", len(srvs))
+	}
+	dispatchErr := make(chan error, len(srvs))
+	for _, srv := range srvs {
+		go func(srv *http.Server) { dispatchErr <- srv.ListenAndServe() }(srv)
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range srvs {
+		if err := <-dispatchErr; err != http.ErrServerClosed {
+			t.Fatalf("Server should have been closed but returned %v", err)
+		}
+	}
+}
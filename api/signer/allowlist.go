@@ -0,0 +1,38 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package signer
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+)
+
+// Allowlist is a signer that only signs the allowed types of operations
+type Allowlist struct {
+	signer snow.Signer
+	ops    map[string]bool
+}
+
+// NewAllowlist returns a signer that passes requests to sign any of [ops] to
+// [signer], and rejects all others
+func NewAllowlist(signer snow.Signer, ops []string) *Allowlist {
+	allowed := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		allowed[op] = true
+	}
+	return &Allowlist{
+		signer: signer,
+		ops:    allowed,
+	}
+}
+
+// Sign implements the snow.Signer interface
+func (a *Allowlist) Sign(op string, addr ids.ShortID, msg []byte) ([]byte, error) {
+	if !a.ops[op] {
+		return nil, fmt.Errorf("operation %q isn't allowed to be signed", op)
+	}
+	return a.signer.Sign(op, addr, msg)
+}
@@ -0,0 +1,116 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	errWrongSigner = errors.New("signature wasn't made by the requested address")
+)
+
+// SignArgs are the arguments of a request to the signing service
+type SignArgs struct {
+	// Type of the operation the signature authorizes
+	Operation string `json:"operation"`
+
+	// Address whose key should sign [Message]
+	Address string `json:"address"`
+
+	// The message to sign. The signature must be over its SHA256 hash.
+	Message formatting.CB58 `json:"message"`
+}
+
+// signRequest is the JSON-RPC request sent to the signing service
+type signRequest struct {
+	Version string    `json:"jsonrpc"`
+	Method  string    `json:"method"`
+	Params  *SignArgs `json:"params"`
+	ID      uint64    `json:"id"`
+}
+
+// SignReply is the reply of the signing service
+type SignReply struct {
+	// 65 byte recoverable secp256k1 signature
+	Signature formatting.CB58 `json:"signature"`
+}
+
+// Client requests signatures from an external signing service by calling its
+// signer.sign JSON-RPC method
+type Client struct {
+	uri    string
+	client http.Client
+	keyFac crypto.FactorySECP256K1R
+}
+
+// NewClient returns a client of the signing service at [uri]. Requests that
+// take longer than [timeout] fail.
+func NewClient(uri string, timeout time.Duration) *Client {
+	return &Client{
+		uri:    uri,
+		client: http.Client{Timeout: timeout},
+	}
+}
+
+// Sign implements the snow.Signer interface. The returned signature is
+// verified to have been made by [addr].
+func (c *Client) Sign(op string, addr ids.ShortID, msg []byte) ([]byte, error) {
+	// The request is canonically encoded so that signing services can
+	// authenticate or log the exact bytes of the request in any language
+	buf, err := cjson.Canonical(&signRequest{
+		Version: "2.0",
+		Method:  "signer.sign",
+		Params: &SignArgs{
+			Operation: op,
+			Address:   addr.String(),
+			Message:   formatting.CB58{Bytes: msg},
+		},
+		ID: uint64(rand.Int63()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Post(c.uri, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't reach the signing service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signing service responded with status %d", resp.StatusCode)
+	}
+
+	reply := SignReply{}
+	if err := json2.DecodeClientResponse(resp.Body, &reply); err != nil {
+		return nil, fmt.Errorf("signing service refused to sign: %w", err)
+	}
+
+	sig := reply.Signature.Bytes
+	if len(sig) != crypto.SECP256K1RSigLen {
+		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	}
+	key, err := c.keyFac.RecoverPublicKey(msg, sig)
+	if err != nil {
+		return nil, err
+	}
+	if !key.Address().Equals(addr) {
+		return nil, errWrongSigner
+	}
+	return sig, nil
+}
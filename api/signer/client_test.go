@@ -0,0 +1,109 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package signer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/json"
+)
+
+var errUnknownKey = errors.New("unknown key")
+
+type Service struct {
+	keys map[[20]byte]*crypto.PrivateKeySECP256K1R
+}
+
+func (s *Service) Sign(_ *http.Request, args *SignArgs, reply *SignReply) error {
+	addr, err := ids.ShortFromString(args.Address)
+	if err != nil {
+		return err
+	}
+	key, ok := s.keys[addr.Key()]
+	if !ok {
+		return errUnknownKey
+	}
+	reply.Signature.Bytes, err = key.Sign(args.Message.Bytes)
+	return err
+}
+
+func newKey(t *testing.T) *crypto.PrivateKeySECP256K1R {
+	factory := crypto.FactorySECP256K1R{}
+	key, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key.(*crypto.PrivateKeySECP256K1R)
+}
+
+// newTestServer returns a signing service that signs requests for the
+// addresses in [keys] with the mapped keys
+func newTestServer(t *testing.T, keys map[[20]byte]*crypto.PrivateKeySECP256K1R) *httptest.Server {
+	service := &Service{keys: keys}
+	server := rpc.NewServer()
+	server.RegisterCodec(json.NewCodec(), "application/json")
+	if err := server.RegisterService(service, "signer"); err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(server)
+}
+
+func TestClientSign(t *testing.T) {
+	key := newKey(t)
+	addr := key.PublicKey().Address()
+
+	server := newTestServer(t, map[[20]byte]*crypto.PrivateKeySECP256K1R{addr.Key(): key})
+	defer server.Close()
+
+	client := NewClient(server.URL, time.Second)
+
+	msg := []byte{1, 2, 3}
+	sig, err := client.Sign("avm.send", addr, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.PublicKey().Verify(msg, sig) {
+		t.Fatalf("Signature should have been made by %s", addr)
+	}
+
+	if _, err := client.Sign("avm.send", ids.ShortEmpty, msg); err == nil {
+		t.Fatalf("Should have failed to sign with an unknown key")
+	}
+}
+
+func TestClientWrongSigner(t *testing.T) {
+	// The signing service signs requests for [addr] with the wrong key
+	addr := ids.NewShortID([20]byte{1})
+	server := newTestServer(t, map[[20]byte]*crypto.PrivateKeySECP256K1R{addr.Key(): newKey(t)})
+	defer server.Close()
+
+	client := NewClient(server.URL, time.Second)
+	if _, err := client.Sign("avm.send", addr, []byte{1}); err != errWrongSigner {
+		t.Fatalf("Should have failed with %q but got %v", errWrongSigner, err)
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	key := newKey(t)
+	addr := key.PublicKey().Address()
+
+	server := newTestServer(t, map[[20]byte]*crypto.PrivateKeySECP256K1R{addr.Key(): key})
+	defer server.Close()
+
+	signer := NewAllowlist(NewClient(server.URL, time.Second), []string{"avm.send"})
+	if _, err := signer.Sign("avm.send", addr, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.Sign("platform.addDefaultSubnetValidator", addr, []byte{1}); err == nil {
+		t.Fatalf("Should have refused to sign an operation that isn't allowed")
+	}
+}
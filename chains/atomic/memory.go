@@ -0,0 +1,61 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// Memory is the memory that the chains running on this node share with each
+// other. Every pair of chains gets its own section of the memory, identified
+// by the pair's shared ID.
+type Memory struct {
+	log  logging.Logger
+	lock sync.Mutex
+	db   database.Database
+}
+
+// Initialize the shared memory. [db] is where the shared memory is persisted.
+func (m *Memory) Initialize(log logging.Logger, db database.Database) {
+	m.log = log
+	m.db = db
+}
+
+// NewSharedMemory returns the view of the shared memory used by the chain
+// [chainID]
+func (m *Memory) NewSharedMemory(chainID ids.ID) SharedMemory {
+	return &sharedMemory{
+		m:           m,
+		thisChainID: chainID,
+	}
+}
+
+// sharedDB returns the section of the memory shared by [chainID1] and
+// [chainID2]. Changes to the returned database are only written to the memory
+// once it's committed. The caller must hold [m.lock].
+func (m *Memory) sharedDB(chainID1, chainID2 ids.ID) *versiondb.Database {
+	sharedID := sharedID(chainID1, chainID2)
+	return versiondb.New(prefixdb.New(sharedID.Bytes(), m.db))
+}
+
+// sharedID returns the ID of the memory shared by [id1] and [id2]. The result
+// doesn't depend on the order of the arguments.
+func sharedID(id1, id2 ids.ID) ids.ID {
+	if bytes.Compare(id1.Bytes(), id2.Bytes()) == 1 {
+		id1, id2 = id2, id1
+	}
+
+	combined := make([]byte, 0, 2*hashing.HashLen)
+	combined = append(combined, id1.Bytes()...)
+	combined = append(combined, id2.Bytes()...)
+	return ids.NewID(hashing.ComputeHash256Array(combined))
+}
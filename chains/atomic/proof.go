@@ -0,0 +1,116 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	leafPrefix byte = iota
+	nodePrefix
+)
+
+// maxDepth is the greatest depth of a leaf, which is reached by the leaves of
+// two elements whose paths differ only in their last bit
+const maxDepth = 8 * hashing.HashLen
+
+var (
+	errNilProof        = errors.New("nil proof")
+	errEmptyTree       = errors.New("proof can't be verified against an empty root")
+	errWrongPathLength = errors.New("proof path has the wrong length")
+	errRootMismatch    = errors.New("proof doesn't match the expected root")
+)
+
+// Proof shows that an element is included in the state committed to by a
+// Merkle root.
+//
+// The state is a sparse Merkle tree in which an element's leaf is on the path
+// given by the hash of its key. The leaf sits at the shallowest depth at which
+// no other element shares its path, so the tree, and its root, only depend on
+// the elements in the state. [Path] holds the hashes of the siblings of the
+// nodes on the way from the leaf up to the root. An empty sibling is all
+// zeros.
+type Proof struct {
+	Path [][hashing.HashLen]byte `serialize:"true" json:"path"`
+}
+
+// Verify returns nil iff [proof] shows that [key] maps to [value] in the state
+// whose Merkle root is [root].
+//
+// Verify doesn't read any local state, so a chain can use it to check an
+// import against a root it learned from the exporting chain's consensus,
+// rather than trusting the contents of shared memory.
+func Verify(root ids.ID, key, value []byte, proof *Proof) error {
+	switch {
+	case proof == nil:
+		return errNilProof
+	case root.IsZero() || root.Equals(ids.Empty):
+		return errEmptyTree
+	case len(proof.Path) > maxDepth:
+		return errWrongPathLength
+	}
+
+	hash := leafHash(key, value)
+	path := keyPath(key)
+	for i, sibling := range proof.Path {
+		if depth := len(proof.Path) - 1 - i; pathBit(path, depth) == 0 {
+			hash = nodeHash(hash, sibling)
+		} else {
+			hash = nodeHash(sibling, hash)
+		}
+	}
+	if !bytes.Equal(hash[:], root.Bytes()) {
+		return errRootMismatch
+	}
+	return nil
+}
+
+// keyPath returns the path from the root of a tree to the leaf of [key]
+func keyPath(key []byte) [hashing.HashLen]byte { return hashing.ComputeHash256Array(key) }
+
+// pathBit returns which child, 0 for left and 1 for right, [path] goes to from
+// its node at [depth]
+func pathBit(path [hashing.HashLen]byte, depth int) byte {
+	return (path[depth/8] >> (7 - uint(depth%8))) & 1
+}
+
+// withBit returns [path] with the bit that is followed from its node at
+// [depth] set to [bit]
+func withBit(path [hashing.HashLen]byte, depth int, bit byte) [hashing.HashLen]byte {
+	mask := byte(0x80) >> uint(depth%8)
+	if bit == 0 {
+		path[depth/8] &^= mask
+	} else {
+		path[depth/8] |= mask
+	}
+	return path
+}
+
+// leafHash returns the hash of the element that maps [key] to [value]. The key
+// is length prefixed so that different splits of the same bytes into a key and
+// a value don't collide.
+func leafHash(key, value []byte) [hashing.HashLen]byte {
+	p := wrappers.Packer{Bytes: make([]byte, 1+wrappers.IntLen+len(key)+hashing.HashLen)}
+	p.PackByte(leafPrefix)
+	p.PackBytes(key)
+	valueHash := hashing.ComputeHash256Array(value)
+	p.PackFixedBytes(valueHash[:])
+	return hashing.ComputeHash256Array(p.Bytes)
+}
+
+// nodeHash returns the hash of the inner node whose children are [left] and
+// [right].
+func nodeHash(left, right [hashing.HashLen]byte) [hashing.HashLen]byte {
+	buf := make([]byte, 1+2*hashing.HashLen)
+	buf[0] = nodePrefix
+	copy(buf[1:], left[:])
+	copy(buf[1+hashing.HashLen:], right[:])
+	return hashing.ComputeHash256Array(buf)
+}
@@ -0,0 +1,168 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// referenceHash returns the hash of the subtree at [depth] that holds
+// [leaves], keyed by their path, computed from scratch
+func referenceHash(leaves map[[hashing.HashLen]byte][hashing.HashLen]byte, depth int) [hashing.HashLen]byte {
+	switch len(leaves) {
+	case 0:
+		return [hashing.HashLen]byte{}
+	case 1:
+		for _, leaf := range leaves {
+			return leaf
+		}
+	}
+	left := map[[hashing.HashLen]byte][hashing.HashLen]byte{}
+	right := map[[hashing.HashLen]byte][hashing.HashLen]byte{}
+	for path, leaf := range leaves {
+		if pathBit(path, depth) == 0 {
+			left[path] = leaf
+		} else {
+			right[path] = leaf
+		}
+	}
+	return nodeHash(referenceHash(left, depth+1), referenceHash(right, depth+1))
+}
+
+// stateRoot returns the root of [s] after committing to it
+func stateRoot(t *testing.T, s *state) ids.ID {
+	if err := s.updateRoot(); err != nil {
+		t.Fatal(err)
+	}
+	root, err := s.root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestMerkleTreeProofs(t *testing.T) {
+	for size := 1; size <= 33; size++ {
+		s := newState(memdb.New(), chainID0)
+		keys := make([][]byte, size)
+		values := make([][]byte, size)
+		leaves := map[[hashing.HashLen]byte][hashing.HashLen]byte{}
+		for i := range keys {
+			keys[i] = []byte{byte(i)}
+			values[i] = []byte{byte(i), byte(size)}
+			leaves[keyPath(keys[i])] = leafHash(keys[i], values[i])
+			if err := s.put(keys[i], values[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		root := stateRoot(t, s)
+		if expected := referenceHash(leaves, 0); !root.Equals(ids.NewID(expected)) {
+			t.Fatalf("Tree of size %d has root %s but should have root %s", size, root, ids.NewID(expected))
+		}
+		for i := range keys {
+			proof, err := s.proof(keys[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(root, keys[i], values[i], proof); err != nil {
+				t.Fatalf("Proof of element %d in tree of size %d failed: %s", i, size, err)
+			}
+			if err := Verify(root, keys[i], []byte{0xff}, proof); err == nil {
+				t.Fatalf("Proof of element %d in tree of size %d verified the wrong value", i, size)
+			}
+			if size > 1 {
+				other := (i + 1) % size
+				if err := Verify(root, keys[other], values[other], proof); err == nil {
+					t.Fatalf("Proof of element %d in tree of size %d verified element %d", i, size, other)
+				}
+			}
+		}
+		if _, err := s.proof([]byte{byte(size)}); err == nil {
+			t.Fatalf("Should have errored due to proving a missing element")
+		}
+	}
+}
+
+func TestMerkleTreeHistoryIndependence(t *testing.T) {
+	const size = 64
+
+	forward := newState(memdb.New(), chainID0)
+	for i := 0; i < size; i++ {
+		if err := forward.put([]byte{byte(i)}, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backward := newState(memdb.New(), chainID0)
+	for i := size - 1; i >= 0; i-- {
+		if err := backward.put([]byte{byte(i)}, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !stateRoot(t, forward).Equals(stateRoot(t, backward)) {
+		t.Fatalf("The root shouldn't depend on the order the elements were added in")
+	}
+
+	// Removing every other element leaves the tree of the remaining elements
+	leaves := map[[hashing.HashLen]byte][hashing.HashLen]byte{}
+	for i := 0; i < size; i++ {
+		key := []byte{byte(i)}
+		if i%2 == 0 {
+			if err := forward.remove(key); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			leaves[keyPath(key)] = leafHash(key, key)
+		}
+	}
+	if root := stateRoot(t, forward); !root.Equals(ids.NewID(referenceHash(leaves, 0))) {
+		t.Fatalf("The root should only depend on the remaining elements")
+	}
+
+	for i := 1; i < size; i += 2 {
+		if err := forward.remove([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if root := stateRoot(t, forward); !root.Equals(ids.Empty) {
+		t.Fatalf("The root of an emptied tree should be empty")
+	}
+	it := forward.nodes.NewIterator()
+	defer it.Release()
+	if it.Next() {
+		t.Fatalf("An emptied tree shouldn't keep any nodes")
+	}
+}
+
+func TestVerifyMalformedProof(t *testing.T) {
+	key := []byte{1}
+	value := []byte{2}
+	root := ids.NewID(leafHash(key, value))
+
+	if err := Verify(root, key, value, nil); err == nil {
+		t.Fatalf("Should have errored due to a nil proof")
+	}
+	if err := Verify(ids.Empty, key, value, &Proof{}); err == nil {
+		t.Fatalf("Should have errored due to an empty root")
+	}
+	if err := Verify(root, key, value, &Proof{Path: make([][hashing.HashLen]byte, maxDepth+1)}); err == nil {
+		t.Fatalf("Should have errored due to a path that is too long")
+	}
+	if err := Verify(root, key, value, &Proof{Path: [][hashing.HashLen]byte{{}}}); err == nil {
+		t.Fatalf("Should have errored due to an extra path element")
+	}
+	if err := Verify(root, key, value, &Proof{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLeafHashKeyValueSplit(t *testing.T) {
+	if leafHash([]byte{1, 2}, []byte{3}) == leafHash([]byte{1}, []byte{2, 3}) {
+		t.Fatalf("Different elements should have different hashes")
+	}
+}
@@ -0,0 +1,508 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	statePrefix = []byte("state")
+	treePrefix  = []byte("tree")
+	rootPrefix  = []byte("root")
+)
+
+var (
+	errElementExists  = errors.New("element already exists")
+	errMissingElement = errors.New("element doesn't exist")
+	errMalformedNode  = errors.New("malformed Merkle tree node")
+)
+
+// Element is a key-value pair that one chain exports to another
+type Element struct {
+	Key   []byte `serialize:"true"`
+	Value []byte `serialize:"true"`
+}
+
+// Requests are the changes a chain makes to the memory it shares with one peer
+// chain
+type Requests struct {
+	// Keys of the elements the peer chain exported to this chain to remove
+	RemoveRequests [][]byte
+
+	// Elements to export to the peer chain
+	PutRequests []*Element
+}
+
+// SharedMemory is the view of the shared memory that a single chain has.
+//
+// The elements a chain exports to a peer chain form the peer's inbound state.
+// Every update to an inbound state commits to a new Merkle root over its
+// elements, so the importing chain can check an element against a root it
+// learned independently, such as from a block accepted by the exporting chain.
+type SharedMemory interface {
+	// Put exports [elems] to [peerChainID]. Returns an error, and doesn't
+	// modify the memory, if any of the keys has already been exported.
+	Put(peerChainID ids.ID, elems []*Element) error
+
+	// Get returns the values that [peerChainID] exported to this chain under
+	// [keys]
+	Get(peerChainID ids.ID, keys [][]byte) ([][]byte, error)
+
+	// GetWithProofs is Get, but also returns proofs that the values are
+	// included in the state whose root is returned
+	GetWithProofs(peerChainID ids.ID, keys [][]byte) (ids.ID, [][]byte, []*Proof, error)
+
+	// GetVerified is Get, but only succeeds if the values are included in the
+	// state whose root is [root]
+	GetVerified(peerChainID, root ids.ID, keys [][]byte) ([][]byte, error)
+
+	// Remove deletes the elements that [peerChainID] exported to this chain
+	// under [keys]. Returns an error, and doesn't modify the memory, if any of
+	// the keys doesn't exist.
+	Remove(peerChainID ids.ID, keys [][]byte) error
+
+	// Apply makes [requests], keyed by the peer chain they're made to, and
+	// writes [batches] in one write to the underlying database. The batches
+	// must be of databases built on the same database as the memory, such as
+	// the batch returned by CommitBatch of the chain's versiondb, so that a
+	// chain's state and the elements it imports and exports stay consistent
+	// after a crash. Returns an error, and neither modifies the memory nor
+	// writes the batches, if any of the requests can't be made.
+	Apply(requests map[[32]byte]*Requests, batches ...database.Batch) error
+
+	// Exported returns whether each of the elements this chain exported to
+	// [peerChainID] under [keys] is still in the memory, that is, whether the
+	// peer chain hasn't removed it yet
+	Exported(peerChainID ids.ID, keys [][]byte) ([]bool, error)
+
+	// ExportRoot returns the root of the elements this chain has exported to
+	// [peerChainID]. Returns ids.Empty if there are no such elements.
+	ExportRoot(peerChainID ids.ID) (ids.ID, error)
+
+	// ImportRoot returns the root of the elements [peerChainID] has exported
+	// to this chain. Returns ids.Empty if there are no such elements.
+	ImportRoot(peerChainID ids.ID) (ids.ID, error)
+}
+
+type sharedMemory struct {
+	m           *Memory
+	thisChainID ids.ID
+}
+
+// Put implements the SharedMemory interface
+func (sm *sharedMemory) Put(peerChainID ids.ID, elems []*Element) error {
+	return sm.Apply(map[[32]byte]*Requests{
+		peerChainID.Key(): {PutRequests: elems},
+	})
+}
+
+// Get implements the SharedMemory interface
+func (sm *sharedMemory) Get(peerChainID ids.ID, keys [][]byte) ([][]byte, error) {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	s := newState(sm.m.sharedDB(sm.thisChainID, peerChainID), sm.thisChainID)
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		value, err := s.values.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// GetWithProofs implements the SharedMemory interface
+func (sm *sharedMemory) GetWithProofs(peerChainID ids.ID, keys [][]byte) (ids.ID, [][]byte, []*Proof, error) {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	s := newState(sm.m.sharedDB(sm.thisChainID, peerChainID), sm.thisChainID)
+	root, err := s.root()
+	if err != nil {
+		return ids.ID{}, nil, nil, err
+	}
+
+	values := make([][]byte, len(keys))
+	proofs := make([]*Proof, len(keys))
+	for i, key := range keys {
+		value, err := s.values.Get(key)
+		if err != nil {
+			return ids.ID{}, nil, nil, err
+		}
+		proof, err := s.proof(key)
+		if err != nil {
+			return ids.ID{}, nil, nil, err
+		}
+		values[i] = value
+		proofs[i] = proof
+	}
+	return root, values, proofs, nil
+}
+
+// GetVerified implements the SharedMemory interface
+func (sm *sharedMemory) GetVerified(peerChainID, root ids.ID, keys [][]byte) ([][]byte, error) {
+	committedRoot, values, proofs, err := sm.GetWithProofs(peerChainID, keys)
+	if err != nil {
+		return nil, err
+	}
+	if !committedRoot.Equals(root) {
+		return nil, errRootMismatch
+	}
+	for i, key := range keys {
+		if err := Verify(root, key, values[i], proofs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Remove implements the SharedMemory interface
+func (sm *sharedMemory) Remove(peerChainID ids.ID, keys [][]byte) error {
+	return sm.Apply(map[[32]byte]*Requests{
+		peerChainID.Key(): {RemoveRequests: keys},
+	})
+}
+
+// Apply implements the SharedMemory interface
+func (sm *sharedMemory) Apply(requests map[[32]byte]*Requests, batches ...database.Batch) error {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	sharedBatches := make([]database.Batch, 0, len(requests))
+	for peerChainKey, req := range requests {
+		peerChainID := ids.NewID(peerChainKey)
+		vdb := sm.m.sharedDB(sm.thisChainID, peerChainID)
+
+		if len(req.RemoveRequests) > 0 {
+			inbound := newState(vdb, sm.thisChainID)
+			for _, key := range req.RemoveRequests {
+				if has, err := inbound.values.Has(key); err != nil {
+					return err
+				} else if !has {
+					return errMissingElement
+				}
+				if err := inbound.remove(key); err != nil {
+					return err
+				}
+			}
+			if err := inbound.updateRoot(); err != nil {
+				return err
+			}
+		}
+
+		if len(req.PutRequests) > 0 {
+			outbound := newState(vdb, peerChainID)
+			for _, elem := range req.PutRequests {
+				if has, err := outbound.values.Has(elem.Key); err != nil {
+					return err
+				} else if has {
+					return errElementExists
+				}
+				if err := outbound.put(elem.Key, elem.Value); err != nil {
+					return err
+				}
+			}
+			if err := outbound.updateRoot(); err != nil {
+				return err
+			}
+		}
+
+		sharedBatch, err := vdb.CommitBatch()
+		if err != nil {
+			return err
+		}
+		sharedBatches = append(sharedBatches, sharedBatch)
+	}
+
+	// Every batch is replayed into one batch of the database the memory is
+	// built on, so that the batches are written together or not at all
+	batch := database.InnerBatch(sm.m.db.NewBatch())
+	for _, other := range append(sharedBatches, batches...) {
+		if err := database.InnerBatch(other).Replay(batch); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// Exported implements the SharedMemory interface
+func (sm *sharedMemory) Exported(peerChainID ids.ID, keys [][]byte) ([]bool, error) {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	s := newState(sm.m.sharedDB(sm.thisChainID, peerChainID), peerChainID)
+	exported := make([]bool, len(keys))
+	for i, key := range keys {
+		has, err := s.values.Has(key)
+		if err != nil {
+			return nil, err
+		}
+		exported[i] = has
+	}
+	return exported, nil
+}
+
+// ExportRoot implements the SharedMemory interface
+func (sm *sharedMemory) ExportRoot(peerChainID ids.ID) (ids.ID, error) {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	return newState(sm.m.sharedDB(sm.thisChainID, peerChainID), peerChainID).root()
+}
+
+// ImportRoot implements the SharedMemory interface
+func (sm *sharedMemory) ImportRoot(peerChainID ids.ID) (ids.ID, error) {
+	sm.m.lock.Lock()
+	defer sm.m.lock.Unlock()
+
+	return newState(sm.m.sharedDB(sm.thisChainID, peerChainID), sm.thisChainID).root()
+}
+
+// state is the inbound state of a chain within the memory it shares with one
+// other chain
+type state struct {
+	chainID ids.ID            // The chain that can import these elements
+	values  database.Database // Key --> Value of the exported elements
+	nodes   database.Database // Depth and path --> Node of the Merkle tree over the elements
+	roots   database.Database // Chain ID --> Root of that chain's inbound state
+}
+
+func newState(db database.Database, chainID ids.ID) *state {
+	return &state{
+		chainID: chainID,
+		values:  prefixdb.New(chainID.Bytes(), prefixdb.New(statePrefix, db)),
+		nodes:   prefixdb.New(chainID.Bytes(), prefixdb.New(treePrefix, db)),
+		roots:   prefixdb.New(rootPrefix, db),
+	}
+}
+
+// put adds the element that maps [key] to [value], which isn't in this state,
+// and updates the nodes of the tree above its leaf
+func (s *state) put(key, value []byte) error {
+	if err := s.values.Put(key, value); err != nil {
+		return err
+	}
+
+	path := keyPath(key)
+	depth := 0
+	for ; ; depth++ {
+		n, err := s.node(depth, path)
+		if err != nil {
+			return err
+		}
+		if n == nil {
+			break
+		}
+		if !n.leaf {
+			continue
+		}
+		if n.path == path {
+			return errElementExists
+		}
+		// The subtree holds a single other element. Both leaves move below
+		// the node at which their paths diverge.
+		for pathBit(n.path, depth) == pathBit(path, depth) {
+			depth++
+		}
+		depth++
+		if err := s.putNode(depth, n.path, n); err != nil {
+			return err
+		}
+		break
+	}
+
+	leaf := &node{
+		hash: leafHash(key, value),
+		leaf: true,
+		path: path,
+	}
+	if err := s.putNode(depth, path, leaf); err != nil {
+		return err
+	}
+	return s.rehash(path, depth)
+}
+
+// remove deletes the element with [key] and updates the nodes of the tree that
+// were above its leaf
+func (s *state) remove(key []byte) error {
+	if err := s.values.Delete(key); err != nil {
+		return err
+	}
+
+	path := keyPath(key)
+	depth, err := s.leafDepth(path)
+	if err != nil {
+		return err
+	}
+	if err := s.nodes.Delete(nodeKey(depth, path)); err != nil {
+		return err
+	}
+	return s.rehash(path, depth)
+}
+
+// proof returns the proof of the element with [key]
+func (s *state) proof(key []byte) (*Proof, error) {
+	path := keyPath(key)
+	depth, err := s.leafDepth(path)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &Proof{Path: make([][hashing.HashLen]byte, depth)}
+	for d := 0; d < depth; d++ {
+		sibling, err := s.node(d+1, withBit(path, d, 1-pathBit(path, d)))
+		if err != nil {
+			return nil, err
+		}
+		proof.Path[depth-1-d] = sibling.subtreeHash()
+	}
+	return proof, nil
+}
+
+// leafDepth returns the depth of the leaf on [path]
+func (s *state) leafDepth(path [hashing.HashLen]byte) (int, error) {
+	for depth := 0; depth <= maxDepth; depth++ {
+		n, err := s.node(depth, path)
+		switch {
+		case err != nil:
+			return 0, err
+		case n == nil || n.leaf && n.path != path:
+			return 0, database.ErrNotFound
+		case n.leaf:
+			return depth, nil
+		}
+	}
+	return 0, database.ErrNotFound
+}
+
+// rehash updates the nodes on [path] above [depth], from the bottom up, after
+// the subtree at [depth] changed. A node whose subtree holds a single element
+// is replaced by the element's leaf.
+func (s *state) rehash(path [hashing.HashLen]byte, depth int) error {
+	for depth--; depth >= 0; depth-- {
+		leftPath := withBit(path, depth, 0)
+		rightPath := withBit(path, depth, 1)
+		left, err := s.node(depth+1, leftPath)
+		if err != nil {
+			return err
+		}
+		right, err := s.node(depth+1, rightPath)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case left == nil && right == nil:
+			err = s.nodes.Delete(nodeKey(depth, path))
+		case left == nil && right.leaf:
+			if err = s.nodes.Delete(nodeKey(depth+1, rightPath)); err == nil {
+				err = s.putNode(depth, path, right)
+			}
+		case right == nil && left.leaf:
+			if err = s.nodes.Delete(nodeKey(depth+1, leftPath)); err == nil {
+				err = s.putNode(depth, path, left)
+			}
+		default:
+			err = s.putNode(depth, path, &node{hash: nodeHash(left.subtreeHash(), right.subtreeHash())})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// node returns the node at [depth] on [path], or nil if the subtree there is
+// empty
+func (s *state) node(depth int, path [hashing.HashLen]byte) (*node, error) {
+	nodeBytes, err := s.nodes.Get(nodeKey(depth, path))
+	if err == database.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	n := &node{}
+	switch len(nodeBytes) {
+	case hashing.HashLen:
+	case 2 * hashing.HashLen:
+		n.leaf = true
+		copy(n.path[:], nodeBytes[hashing.HashLen:])
+	default:
+		return nil, errMalformedNode
+	}
+	copy(n.hash[:], nodeBytes)
+	return n, nil
+}
+
+func (s *state) putNode(depth int, path [hashing.HashLen]byte, n *node) error {
+	nodeBytes := make([]byte, 0, 2*hashing.HashLen)
+	nodeBytes = append(nodeBytes, n.hash[:]...)
+	if n.leaf {
+		nodeBytes = append(nodeBytes, n.path[:]...)
+	}
+	return s.nodes.Put(nodeKey(depth, path), nodeBytes)
+}
+
+// root returns the last committed root of this state
+func (s *state) root() (ids.ID, error) {
+	rootBytes, err := s.roots.Get(s.chainID.Bytes())
+	if err == database.ErrNotFound {
+		return ids.Empty, nil
+	} else if err != nil {
+		return ids.ID{}, err
+	}
+	return ids.ToID(rootBytes)
+}
+
+// updateRoot commits to the root of the current elements of this state
+func (s *state) updateRoot() error {
+	n, err := s.node(0, [hashing.HashLen]byte{})
+	if err != nil {
+		return err
+	}
+	if n == nil {
+		return s.roots.Delete(s.chainID.Bytes())
+	}
+	return s.roots.Put(s.chainID.Bytes(), n.hash[:])
+}
+
+// node is a non-empty subtree of the Merkle tree over the elements of a state.
+// A subtree that holds a single element is that element's leaf.
+type node struct {
+	hash [hashing.HashLen]byte
+	leaf bool
+	path [hashing.HashLen]byte // Path of the leaf's element
+}
+
+// subtreeHash returns the hash of the subtree [n], which is all zeros if the
+// subtree is empty
+func (n *node) subtreeHash() [hashing.HashLen]byte {
+	if n == nil {
+		return [hashing.HashLen]byte{}
+	}
+	return n.hash
+}
+
+// nodeKey returns the key of the node at [depth] on [path]. Only the part of
+// the path above the node is kept, so every path through the node has the
+// same key.
+func nodeKey(depth int, path [hashing.HashLen]byte) []byte {
+	key := make([]byte, 2+hashing.HashLen)
+	binary.BigEndian.PutUint16(key, uint16(depth))
+	copy(key[2:], path[:depth/8])
+	if depth%8 != 0 {
+		key[2+depth/8] = path[depth/8] & (0xff << uint(8-depth%8))
+	}
+	return key
+}
@@ -0,0 +1,302 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/meterdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+var (
+	chainID0 = ids.NewID([32]byte{0})
+	chainID1 = ids.NewID([32]byte{1})
+	chainID2 = ids.NewID([32]byte{2})
+)
+
+func newTestMemory() *Memory {
+	m := &Memory{}
+	m.Initialize(logging.NoLog{}, memdb.New())
+	return m
+}
+
+func TestSharedMemoryPutGetRemove(t *testing.T) {
+	m := newTestMemory()
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+	sm2 := m.NewSharedMemory(chainID2)
+
+	elems := []*Element{
+		{Key: []byte{1}, Value: []byte{10}},
+		{Key: []byte{2}, Value: []byte{20}},
+		{Key: []byte{3}, Value: []byte{30}},
+	}
+	if err := sm0.Put(chainID1, elems); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := sm1.Get(chainID0, [][]byte{{2}, {3}})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(values) != 2:
+		t.Fatalf("Wrong number of values returned")
+	case !bytes.Equal(values[0], []byte{20}), !bytes.Equal(values[1], []byte{30}):
+		t.Fatalf("Wrong values returned")
+	}
+
+	if _, err := sm0.Get(chainID1, [][]byte{{1}}); err == nil {
+		t.Fatalf("The exporting chain shouldn't see its own exports as imports")
+	}
+	if _, err := sm2.Get(chainID0, [][]byte{{1}}); err == nil {
+		t.Fatalf("A third chain shouldn't see the exports")
+	}
+	if err := sm0.Put(chainID1, elems[:1]); err == nil {
+		t.Fatalf("Should have errored due to exporting an existing element")
+	}
+
+	if err := sm1.Remove(chainID0, [][]byte{{1}, {4}}); err == nil {
+		t.Fatalf("Should have errored due to removing a missing element")
+	}
+	if _, err := sm1.Get(chainID0, [][]byte{{1}}); err != nil {
+		t.Fatalf("A failed remove shouldn't modify the memory: %s", err)
+	}
+	if err := sm1.Remove(chainID0, [][]byte{{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm1.Get(chainID0, [][]byte{{1}}); err == nil {
+		t.Fatalf("Should have errored due to getting a removed element")
+	}
+}
+
+func TestSharedMemoryExported(t *testing.T) {
+	m := newTestMemory()
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+
+	if err := sm0.Put(chainID1, []*Element{
+		{Key: []byte{1}, Value: []byte{10}},
+		{Key: []byte{2}, Value: []byte{20}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sm1.Remove(chainID0, [][]byte{{1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	exported, err := sm0.Exported(chainID1, [][]byte{{1}, {2}, {3}})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(exported) != 3:
+		t.Fatalf("Wrong number of results returned")
+	case exported[0]:
+		t.Fatalf("The element the peer removed shouldn't be exported")
+	case !exported[1]:
+		t.Fatalf("The element the peer didn't remove should be exported")
+	case exported[2]:
+		t.Fatalf("The element that was never exported shouldn't be exported")
+	}
+
+	if exported, err := sm1.Exported(chainID0, [][]byte{{2}}); err != nil {
+		t.Fatal(err)
+	} else if exported[0] {
+		t.Fatalf("The importing chain shouldn't see the exports as its own")
+	}
+}
+
+func TestSharedMemoryRoots(t *testing.T) {
+	m := newTestMemory()
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+
+	if root, err := sm1.ImportRoot(chainID0); err != nil {
+		t.Fatal(err)
+	} else if !root.Equals(ids.Empty) {
+		t.Fatalf("The root of an empty state should be empty")
+	}
+
+	if err := sm0.Put(chainID1, []*Element{{Key: []byte{1}, Value: []byte{10}}}); err != nil {
+		t.Fatal(err)
+	}
+	exportRoot, err := sm0.ExportRoot(chainID1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	importRoot, err := sm1.ImportRoot(chainID0)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case exportRoot.Equals(ids.Empty):
+		t.Fatalf("The root of a non-empty state shouldn't be empty")
+	case !exportRoot.Equals(importRoot):
+		t.Fatalf("Both chains should agree on the root")
+	}
+
+	if err := sm0.Put(chainID1, []*Element{{Key: []byte{2}, Value: []byte{20}}}); err != nil {
+		t.Fatal(err)
+	}
+	if newRoot, err := sm0.ExportRoot(chainID1); err != nil {
+		t.Fatal(err)
+	} else if newRoot.Equals(exportRoot) {
+		t.Fatalf("The root should change when the state changes")
+	}
+
+	if err := sm1.Remove(chainID0, [][]byte{{2}}); err != nil {
+		t.Fatal(err)
+	}
+	if newRoot, err := sm1.ImportRoot(chainID0); err != nil {
+		t.Fatal(err)
+	} else if !newRoot.Equals(exportRoot) {
+		t.Fatalf("The root should only depend on the elements in the state")
+	}
+}
+
+func TestSharedMemoryProofs(t *testing.T) {
+	m := newTestMemory()
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+
+	elems := []*Element{
+		{Key: []byte{1}, Value: []byte{10}},
+		{Key: []byte{2}, Value: []byte{20}},
+		{Key: []byte{3}, Value: []byte{30}},
+	}
+	if err := sm0.Put(chainID1, elems); err != nil {
+		t.Fatal(err)
+	}
+	exportRoot, err := sm0.ExportRoot(chainID1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := [][]byte{{3}, {1}}
+	root, values, proofs, err := sm1.GetWithProofs(chainID0, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !root.Equals(exportRoot) {
+		t.Fatalf("Proofs should be against the committed root")
+	}
+	for i, key := range keys {
+		if err := Verify(exportRoot, key, values[i], proofs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := sm1.GetVerified(chainID0, exportRoot, keys); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm1.GetVerified(chainID0, ids.NewID([32]byte{1}), keys); err == nil {
+		t.Fatalf("Should have errored due to verifying against the wrong root")
+	}
+	if _, _, _, err := sm1.GetWithProofs(chainID0, [][]byte{{4}}); err == nil {
+		t.Fatalf("Should have errored due to proving a missing element")
+	}
+
+	// A value changed without updating the tree can't be proven
+	vdb := m.sharedDB(chainID0, chainID1)
+	if err := newState(vdb, chainID1).values.Put([]byte{3}, []byte{31}); err != nil {
+		t.Fatal(err)
+	}
+	if err := vdb.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm1.Get(chainID0, keys); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sm1.GetVerified(chainID0, exportRoot, keys); err == nil {
+		t.Fatalf("Should have errored due to verifying a tampered value")
+	}
+}
+
+func TestSharedMemoryApply(t *testing.T) {
+	baseDB := memdb.New()
+	m := &Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), baseDB))
+	sm0 := m.NewSharedMemory(chainID0)
+	sm1 := m.NewSharedMemory(chainID1)
+	sm2 := m.NewSharedMemory(chainID2)
+
+	if err := sm1.Put(chainID0, []*Element{{Key: []byte{1}, Value: []byte{10}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	chainDB := meterdb.New(&meterdb.Meter{}, prefixdb.New(chainID0.Bytes(), baseDB))
+	vdb := versiondb.New(chainDB)
+	if err := vdb.Put([]byte("block"), []byte("accepted")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing an element that wasn't exported should fail the whole request
+	batch, err := vdb.CommitBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sm0.Apply(map[[32]byte]*Requests{
+		chainID1.Key(): {RemoveRequests: [][]byte{{1}, {2}}},
+		chainID2.Key(): {PutRequests: []*Element{{Key: []byte{3}, Value: []byte{30}}}},
+	}, batch)
+	if err == nil {
+		t.Fatalf("Should have errored due to removing a missing element")
+	}
+	if has, err := chainDB.Has([]byte("block")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("The chain's batch shouldn't have been written")
+	}
+	if _, err := sm0.Get(chainID1, [][]byte{{1}}); err != nil {
+		t.Fatalf("The element shouldn't have been removed")
+	}
+	if _, err := sm2.Get(chainID0, [][]byte{{3}}); err == nil {
+		t.Fatalf("The element shouldn't have been exported")
+	}
+
+	batch, err = vdb.CommitBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sm0.Apply(map[[32]byte]*Requests{
+		chainID1.Key(): {RemoveRequests: [][]byte{{1}}},
+		chainID2.Key(): {PutRequests: []*Element{{Key: []byte{3}, Value: []byte{30}}}},
+	}, batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdb.Abort()
+
+	if value, err := chainDB.Get([]byte("block")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, []byte("accepted")) {
+		t.Fatalf("The chain's batch should have been written")
+	}
+	if _, err := sm0.Get(chainID1, [][]byte{{1}}); err == nil {
+		t.Fatalf("The element should have been removed")
+	}
+	if root, err := sm1.ExportRoot(chainID0); err != nil {
+		t.Fatal(err)
+	} else if !root.Equals(ids.Empty) {
+		t.Fatalf("Removing the only element should have cleared the root")
+	}
+	if values, err := sm2.Get(chainID0, [][]byte{{3}}); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(values[0], []byte{30}) {
+		t.Fatalf("Wrong value exported")
+	}
+}
+
+func TestSharedID(t *testing.T) {
+	if !sharedID(chainID0, chainID1).Equals(sharedID(chainID1, chainID0)) {
+		t.Fatalf("The shared ID shouldn't depend on the order of the chains")
+	}
+	if sharedID(chainID0, chainID1).Equals(sharedID(chainID0, chainID2)) {
+		t.Fatalf("Different pairs of chains should have different shared IDs")
+	}
+}
@@ -0,0 +1,68 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	errNilTransferTx    = errors.New("transfer must name the tx that exported it")
+	errNoTransferAmount = errors.New("transfer amount must be positive")
+	errNoTransferOwner  = errors.New("transfer must be to an address")
+	errWrongTransferID  = errors.New("transfer isn't stored under its ID")
+)
+
+var transferCodec = codec.NewDefault()
+
+// Transfer is an amount of $AVA that one chain exports to an address on
+// another chain. Chains that hold $AVA differently, such as the Platform
+// Chain's accounts and the AVM's UTXOs, export transfers to each other instead
+// of their own types, so neither has to parse the other's. A transfer is put
+// in shared memory under its ID, and is imported by a tx signed by the key of
+// its address.
+type Transfer struct {
+	SourceTx ids.ID      `serialize:"true"` // Tx that exported the transfer
+	Index    uint32      `serialize:"true"` // Index of the transfer among those exported by [SourceTx]
+	Amount   uint64      `serialize:"true"` // Amount of $AVA, in nAVA
+	To       ids.ShortID `serialize:"true"` // Address that can import the transfer
+}
+
+// ID returns the ID of the transfer. It's derived from the tx and index the
+// way the ID of a UTXO is, so a chain can export a transfer under the ID of the
+// output it replaces.
+func (t *Transfer) ID() ids.ID { return t.SourceTx.Prefix(uint64(t.Index)) }
+
+// Verify that the transfer is well-formed
+func (t *Transfer) Verify() error {
+	switch {
+	case t.SourceTx.IsZero():
+		return errNilTransferTx
+	case t.Amount == 0:
+		return errNoTransferAmount
+	case t.To.IsZero():
+		return errNoTransferOwner
+	default:
+		return nil
+	}
+}
+
+// Bytes returns the byte representation of the transfer
+func (t *Transfer) Bytes() ([]byte, error) { return transferCodec.Marshal(t) }
+
+// ParseTransfer parses and verifies the transfer [b] that was stored under
+// [transferID]
+func ParseTransfer(transferID ids.ID, b []byte) (*Transfer, error) {
+	t := &Transfer{}
+	if err := transferCodec.Unmarshal(b, t); err != nil {
+		return nil, err
+	}
+	if !t.ID().Equals(transferID) {
+		return nil, errWrongTransferID
+	}
+	return t, t.Verify()
+}
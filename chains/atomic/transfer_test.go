@@ -0,0 +1,50 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package atomic
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestTransfer(t *testing.T) {
+	transfer := &Transfer{
+		SourceTx: ids.NewID([32]byte{1}),
+		Index:    2,
+		Amount:   3,
+		To:       ids.NewShortID([20]byte{4}),
+	}
+	if err := transfer.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := ids.NewID([32]byte{1}).Prefix(2); !transfer.ID().Equals(expected) {
+		t.Fatalf("Transfer ID should have been %s but was %s", expected, transfer.ID())
+	}
+
+	b, err := transfer.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTransfer(transfer.ID(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.SourceTx.Equals(transfer.SourceTx) || parsed.Index != transfer.Index || parsed.Amount != transfer.Amount || !parsed.To.Equals(transfer.To) {
+		t.Fatalf("Parsed %+v but should have parsed %+v", parsed, transfer)
+	}
+
+	if _, err := ParseTransfer(transfer.SourceTx.Prefix(3), b); err != errWrongTransferID {
+		t.Fatalf("Should have failed to parse a transfer stored under another ID but got %v", err)
+	}
+
+	empty := *transfer
+	empty.Amount = 0
+	if b, err = empty.Bytes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTransfer(empty.ID(), b); err != errNoTransferAmount {
+		t.Fatalf("Should have failed to parse an empty transfer but got %v", err)
+	}
+}
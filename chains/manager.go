@@ -5,11 +5,14 @@ package chains
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/meterdb"
 	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
@@ -23,7 +26,9 @@ import (
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/sender"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
+	"github.com/ava-labs/gecko/snow/pruning"
 	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/vms"
@@ -37,7 +42,7 @@ import (
 
 const (
 	defaultChannelSize = 1000
-	requestTimeout     = 2 * time.Second
+	requestTimeout     = 2 * time.Second // Before a validator's response latency is known
 )
 
 // Manager manages the chains running on this node.
@@ -67,12 +72,27 @@ type Manager interface {
 	// Given an alias, return the ID of the VM associated with that alias
 	LookupVM(string) (ids.ID, error)
 
+	// Check the genesis data of a chain running the given VM and fxs, without
+	// creating the chain. Returns false if the VM can't check genesis data.
+	VerifyGenesis(vmID ids.ID, fxIDs []ids.ID, genesisData []byte) (bool, error)
+
+	// Return the version of the VM running a chain and the upgrades scheduled
+	// for the chain
+	Version(ids.ID) (string, snow.Upgrades, error)
+
 	// Return the aliases associated with a chain
 	Aliases(ids.ID) []string
 
 	// Add an alias to a chain
 	Alias(ids.ID, string) error
 
+	// Shut down a chain and release its resources. The chain's data is kept,
+	// so the chain can be created again later.
+	RemoveChain(ids.ID) error
+
+	// Remove every chain validated by a subnet
+	RemoveSubnet(ids.ID)
+
 	Shutdown()
 }
 
@@ -98,20 +118,64 @@ type manager struct {
 	decisionEvents  *triggers.EventDispatcher
 	consensusEvents *triggers.EventDispatcher
 	db              database.Database
-	chainRouter     router.Router         // Routes incoming messages to the appropriate chain
-	sender          sender.ExternalSender // Sends consensus messages to other validators
-	timeoutManager  *timeout.Manager      // Manages request timeouts when sending messages to other validators
-	consensusParams avacon.Parameters     // The consensus parameters (alpha, beta, etc.) for new chains
-	validators      validators.Manager    // Validators validating on this chain
-	registrants     []Registrant          // Those notified when a chain is created
-	nodeID          ids.ShortID           // The ID of this node
-	networkID       uint32                // ID of the network this node is connected to
-	awaiter         Awaiter               // Waits for required connections before running bootstrapping
-	server          *api.Server           // Handles HTTP API calls
+	chainRouter     router.Router               // Routes incoming messages to the appropriate chain
+	sender          sender.ExternalSender       // Sends consensus messages to other validators
+	timeoutManager  *timeout.Manager            // Manages request timeouts when sending messages to other validators
+	msgFailures     *networking.MessageFailures // Counts messages that weren't delivered. May be nil.
+	consensusParams avacon.Parameters           // The consensus parameters (alpha, beta, etc.) for new chains
+	validators      validators.Manager          // Validators validating on this chain
+	registrants     []Registrant                // Those notified when a chain is created
+	nodeID          ids.ShortID                 // The ID of this node
+	networkID       uint32                      // ID of the network this node is connected to
+	awaiter         Awaiter                     // Waits for required connections before running bootstrapping
+	server          *api.Server                 // Handles HTTP API calls
 	keystore        *keystore.Keystore
+	signer          snow.Signer // Signs with keys held outside of this node. May be nil.
+	sharedMemory    *atomic.Memory
+	replayChains    []string // IDs or aliases of the chains to replay against a fresh VM
+
+	// Chain ID or alias --> upgrades scheduled for the chain
+	upgrades map[string]snow.Upgrades
+
+	// Chain ID or alias --> weight of the chain's queue in the router
+	chainWeights map[string]int
+
+	// How much historical state the chains keep
+	stateMode snow.StateMode
+
+	// True if the state the chains no longer need is deleted from their
+	// databases
+	statePrune bool
+
+	// True if the chains reject issued transactions and never vote
+	readOnly bool
+
+	// IDs of the subnets, besides the default subnet, whose chains this node
+	// runs
+	trackedSubnets ids.Set
+
+	versionsLock sync.RWMutex
+	// Chain ID --> version of the VM running the chain
+	versions map[[32]byte]chainVersion
 
 	unblocked     bool
 	blockedChains []ChainParameters
+
+	chainsLock sync.Mutex
+	// Chain ID --> chain that is running
+	chains map[[32]byte]*runningChain
+
+	// Counts the goroutines of each chain for its metrics
+	goroutines goroutineCounter
+}
+
+// runningChain is a chain that was created and hasn't been removed
+type runningChain struct {
+	params  ChainParameters
+	vmID    ids.ID
+	ctx     *snow.Context
+	dbLog   logging.Logger // Log of the pruning of the chain's databases
+	pruners []*pruning.Pruner
 }
 
 // New returns a new Manager where:
@@ -133,14 +197,31 @@ func New(
 	nodeID ids.ShortID,
 	networkID uint32,
 	awaiter Awaiter,
+	uptimeTracker *uptime.Tracker,
 	server *api.Server,
 	keystore *keystore.Keystore,
+	signer snow.Signer,
+	sharedMemory *atomic.Memory,
+	replayChains []string,
+	upgrades map[string]snow.Upgrades,
+	chainWeights map[string]int,
+	minRequestTimeout, maxRequestTimeout time.Duration,
+	stateMode snow.StateMode,
+	statePrune bool,
+	readOnly bool,
+	trackedSubnets ids.Set,
+	msgFailures *networking.MessageFailures,
 ) Manager {
 	timeoutManager := timeout.Manager{}
 	timeoutManager.Initialize(requestTimeout)
+	timeoutManager.Adapt(minRequestTimeout, maxRequestTimeout)
+	if uptimeTracker != nil {
+		timeoutManager.Track(uptimeTracker)
+	}
 	go log.RecoverAndPanic(timeoutManager.Dispatch)
 
 	router.Initialize(log, &timeoutManager)
+	router.TrackFailures(msgFailures)
 
 	m := &manager{
 		log:             log,
@@ -152,6 +233,7 @@ func New(
 		chainRouter:     router,
 		sender:          sender,
 		timeoutManager:  &timeoutManager,
+		msgFailures:     msgFailures,
 		consensusParams: consensusParams,
 		validators:      validators,
 		nodeID:          nodeID,
@@ -159,6 +241,17 @@ func New(
 		awaiter:         awaiter,
 		server:          server,
 		keystore:        keystore,
+		signer:          signer,
+		sharedMemory:    sharedMemory,
+		replayChains:    replayChains,
+		upgrades:        upgrades,
+		chainWeights:    chainWeights,
+		stateMode:       stateMode,
+		statePrune:      statePrune,
+		readOnly:        readOnly,
+		trackedSubnets:  trackedSubnets,
+		versions:        make(map[[32]byte]chainVersion),
+		chains:          make(map[[32]byte]*runningChain),
 	}
 	m.Initialize()
 	return m
@@ -185,6 +278,11 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 		chain.VMAlias,
 	)
 
+	if !m.tracksSubnet(chain.SubnetID) {
+		m.log.Info("not creating chain %s because its subnet %s isn't tracked", chain.ID, chain.SubnetID)
+		return
+	}
+
 	// Assert that there isn't already a chain with an alias in [chain].Aliases
 	// (Recall that the string repr. of a chain's ID is also an alias for a chain)
 	if alias, isRepeat := m.isChainWithAlias(chain.ID.String()); isRepeat {
@@ -208,26 +306,17 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 	// Create the chain
 	vm := vmFactory.New()
 
-	fxs := make([]*common.Fx, len(chain.FxAliases))
-	for i, fxAlias := range chain.FxAliases {
-		fxID, err := m.vmManager.Lookup(fxAlias)
-		if err != nil {
-			m.log.Error("error while looking up Fx: %s", err)
-			return
-		}
-
-		// Get a factory for the fx we want to use on our chain
-		fxFactory, err := m.vmManager.GetVMFactory(fxID)
-		if err != nil {
-			m.log.Error("error while getting fxFactory: %s", err)
-			return
-		}
+	version, err := m.chainUpgrades(chain.ID, vm)
+	if err != nil {
+		m.log.Error("error while scheduling the chain's upgrades: %s", err)
+		return
+	}
+	m.log.Info("chain %s is running version %s of its VM with %d scheduled upgrades", chain.ID, version.version, len(version.upgrades))
 
-		// Create the fx
-		fxs[i] = &common.Fx{
-			ID: fxID,
-			Fx: fxFactory.New(),
-		}
+	fxs, err := m.newFxs(chain.FxAliases)
+	if err != nil {
+		m.log.Error("error while creating fxs: %s", err)
+		return
 	}
 
 	// Create the log and context of the chain
@@ -236,24 +325,44 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 		m.log.Error("error while creating chain's log %s", err)
 		return
 	}
+	consensusLog, err := m.logFactory.MakeChain(chain.ID, "consensus")
+	if err != nil {
+		m.log.Error("error while creating chain's consensus log %s", err)
+		return
+	}
+	dbLog, err := m.logFactory.MakeChain(chain.ID, "database")
+	if err != nil {
+		m.log.Error("error while creating chain's database log %s", err)
+		return
+	}
 
 	ctx := &snow.Context{
 		NetworkID:           m.networkID,
 		ChainID:             chain.ID,
 		Log:                 chainLog,
+		ConsensusLog:        consensusLog,
 		DecisionDispatcher:  m.decisionEvents,
 		ConsensusDispatcher: m.consensusEvents,
 		NodeID:              m.nodeID,
 		HTTP:                m.server,
 		Keystore:            m.keystore.NewBlockchainKeyStore(chain.ID),
+		Signer:              m.signer,
 		BCLookup:            m,
+		SharedMemory:        m.sharedMemory.NewSharedMemory(chain.ID),
+		Upgrades:            version.upgrades,
+		StateMode:           m.stateMode,
+		ReadOnly:            m.readOnly,
+		VMLookup:            m,
 	}
+	ctx.EnableTracing()
 	consensusParams := m.consensusParams
 	if alias, err := m.PrimaryAlias(ctx.ChainID); err == nil {
 		consensusParams.Namespace = fmt.Sprintf("gecko_%s", alias)
 	} else {
 		consensusParams.Namespace = fmt.Sprintf("gecko_%s", ctx.ChainID)
 	}
+	ctx.Namespace = consensusParams.Namespace
+	ctx.Metrics = consensusParams.Metrics
 
 	// The validators of this blockchain
 	validators, ok := m.validators.GetValidatorSet(ids.Empty) // TODO: Change argument to chain.SubnetID
@@ -267,6 +376,16 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 		beacons = chain.CustomBeacons
 	}
 
+	// The chain is running once its engine can be started
+	m.chainsLock.Lock()
+	m.chains[chain.ID.Key()] = &runningChain{
+		params: chain,
+		vmID:   vmID,
+		ctx:    ctx,
+		dbLog:  dbLog,
+	}
+	m.chainsLock.Unlock()
+
 	switch vm := vm.(type) {
 	case avalanche.DAGVM:
 		err := m.createAvalancheChain(
@@ -277,9 +396,11 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 			vm,
 			fxs,
 			consensusParams,
+			m.chainWeight(chain),
 		)
 		if err != nil {
 			m.log.Error("error while creating new avalanche vm %s", err)
+			m.forget(chain.ID)
 			return
 		}
 	case smeng.ChainVM:
@@ -291,21 +412,78 @@ func (m *manager) ForceCreateChain(chain ChainParameters) {
 			vm,
 			fxs,
 			consensusParams.Parameters,
+			m.chainWeight(chain),
 		)
 		if err != nil {
 			m.log.Error("error while creating new snowman vm %s", err)
+			m.forget(chain.ID)
 			return
 		}
 	default:
 		m.log.Error("the vm should have type avalanche.DAGVM or snowman.ChainVM. Chain not created")
+		m.forget(chain.ID)
 		return
 	}
 
 	// Associate the newly created chain with its default alias
 	m.log.AssertNoError(m.Alias(chain.ID, chain.ID.String()))
 
+	m.versionsLock.Lock()
+	m.versions[chain.ID.Key()] = version
+	m.versionsLock.Unlock()
+
 	// Notify those that registered to be notified when a new chain is created
 	m.notifyRegistrants(ctx, vm)
+
+	if m.isReplayed(chain.ID) {
+		go ctx.Log.RecoverAndPanic(func() { m.replayChain(ctx, chain, vm) })
+	}
+}
+
+// chainWeight returns the weight of [chain]'s queue in the router. Unless it's
+// configured, the chains of the default subnet are weighted more heavily.
+func (m *manager) chainWeight(chain ChainParameters) int {
+	aliases := append(m.Aliases(chain.ID), chain.ID.String())
+	for _, alias := range aliases {
+		if weight, ok := m.chainWeights[alias]; ok {
+			return weight
+		}
+	}
+	if chain.SubnetID.Equals(ids.Empty) {
+		return router.DefaultSubnetWeight
+	}
+	return router.DefaultWeight
+}
+
+// tracksSubnet returns true if this node runs the chains of the subnet
+// [subnetID]. The chains of the default subnet, whose ID is ids.Empty, are
+// always run.
+func (m *manager) tracksSubnet(subnetID ids.ID) bool {
+	return subnetID.Equals(ids.Empty) || m.trackedSubnets.Contains(subnetID)
+}
+
+// newFxs creates new instances of the fxs with the given aliases
+func (m *manager) newFxs(fxAliases []string) ([]*common.Fx, error) {
+	fxs := make([]*common.Fx, len(fxAliases))
+	for i, fxAlias := range fxAliases {
+		fxID, err := m.vmManager.Lookup(fxAlias)
+		if err != nil {
+			return nil, fmt.Errorf("error while looking up Fx: %w", err)
+		}
+
+		// Get a factory for the fx we want to use on our chain
+		fxFactory, err := m.vmManager.GetVMFactory(fxID)
+		if err != nil {
+			return nil, fmt.Errorf("error while getting fxFactory: %w", err)
+		}
+
+		// Create the fx
+		fxs[i] = &common.Fx{
+			ID: fxID,
+			Fx: fxFactory.New(),
+		}
+	}
+	return fxs, nil
 }
 
 // Implements Manager.AddRegistrant
@@ -329,41 +507,58 @@ func (m *manager) createAvalancheChain(
 	vm avalanche.DAGVM,
 	fxs []*common.Fx,
 	consensusParams avacon.Parameters,
+	weight int,
 ) error {
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 
+	meter := &meterdb.Meter{}
 	db := prefixdb.New(ctx.ChainID.Bytes(), m.db)
-	vmDB := prefixdb.New([]byte("vm"), db)
-	vertexDB := prefixdb.New([]byte("vertex"), db)
-	vertexBootstrappingDB := prefixdb.New([]byte("vertex_bootstrapping"), db)
-	txBootstrappingDB := prefixdb.New([]byte("tx_bootstrapping"), db)
+	vmDB := meterdb.New(meter, prefixdb.New(vmPrefix, db))
+	vertexDB := meterdb.New(meter, prefixdb.New([]byte("vertex"), db))
+	vertexBootstrappingDB := meterdb.New(meter, prefixdb.New([]byte("vertex_bootstrapping"), db))
+	txBootstrappingDB := meterdb.New(meter, prefixdb.New([]byte("tx_bootstrapping"), db))
+	registerChainMetrics(ctx, db, meter, &m.goroutines)
 
 	vtxBlocker, err := queue.New(vertexBootstrappingDB)
 	if err != nil {
 		return err
 	}
+	if ctx.Pruner, err = m.newPruner(ctx, db, vmDB, []byte("vm_pruning")); err != nil {
+		return err
+	}
+	vtxPruner, err := m.newPruner(ctx, db, vertexDB, []byte("vertex_pruning"))
+	if err != nil {
+		return err
+	}
 	txBlocker, err := queue.New(txBootstrappingDB)
 	if err != nil {
 		return err
 	}
 
+	if err := m.migrate(ctx, vm); err != nil {
+		return err
+	}
+
 	// The channel through which a VM may send messages to the consensus engine
 	// VM uses this channel to notify engine that a block is ready to be made
 	msgChan := make(chan common.Message, defaultChannelSize)
 
-	if err := vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs); err != nil {
+	// The goroutines the VM starts are attributed to this chain
+	withChainLabel(ctx.ChainID, func() { err = vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs) })
+	if err != nil {
 		return err
 	}
 
 	// Handles serialization/deserialization of vertices and also the
 	// persistence of vertices
-	vtxState := &state.Serializer{}
+	vtxState := &state.Serializer{Pruner: vtxPruner}
 	vtxState.Initialize(ctx, vm, vertexDB)
 
 	// Passes messages from the consensus engine to the network
 	sender := sender.Sender{}
 	sender.Initialize(ctx, m.sender, m.chainRouter, m.timeoutManager)
+	sender.TrackFailures(m.msgFailures)
 
 	// The engine handles consensus
 	engine := avaeng.Transitive{
@@ -399,15 +594,17 @@ func (m *manager) createAvalancheChain(
 	handler.Initialize(&engine, msgChan, defaultChannelSize)
 
 	// Allows messages to be routed to the new chain
-	m.chainRouter.AddChain(handler)
-	go ctx.Log.RecoverAndPanic(handler.Dispatch)
+	m.chainRouter.AddChain(handler, weight)
+	withChainLabel(ctx.ChainID, func() { go ctx.ConsensusLog.RecoverAndPanic(handler.Dispatch) })
 
 	awaiting := &networking.AwaitingConnections{
 		Finish: func() {
 			ctx.Lock.Lock()
 			defer ctx.Lock.Unlock()
 
-			engine.Startup()
+			if m.isRunning(ctx.ChainID) { // The chain may have been removed while it waited
+				withChainLabel(ctx.ChainID, engine.Startup)
+			}
 		},
 	}
 	for _, vdr := range beacons.List() {
@@ -428,31 +625,44 @@ func (m *manager) createSnowmanChain(
 	vm smeng.ChainVM,
 	fxs []*common.Fx,
 	consensusParams snowball.Parameters,
+	weight int,
 ) error {
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 
+	meter := &meterdb.Meter{}
 	db := prefixdb.New(ctx.ChainID.Bytes(), m.db)
-	vmDB := prefixdb.New([]byte("vm"), db)
-	bootstrappingDB := prefixdb.New([]byte("bootstrapping"), db)
+	vmDB := meterdb.New(meter, prefixdb.New(vmPrefix, db))
+	bootstrappingDB := meterdb.New(meter, prefixdb.New([]byte("bootstrapping"), db))
+	registerChainMetrics(ctx, db, meter, &m.goroutines)
 
 	blocked, err := queue.New(bootstrappingDB)
 	if err != nil {
 		return err
 	}
+	if ctx.Pruner, err = m.newPruner(ctx, db, vmDB, []byte("vm_pruning")); err != nil {
+		return err
+	}
+
+	if err := m.migrate(ctx, vm); err != nil {
+		return err
+	}
 
 	// The channel through which a VM may send messages to the consensus engine
 	// VM uses this channel to notify engine that a block is ready to be made
 	msgChan := make(chan common.Message, defaultChannelSize)
 
 	// Initialize the VM
-	if err := vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs); err != nil {
+	// The goroutines the VM starts are attributed to this chain
+	withChainLabel(ctx.ChainID, func() { err = vm.Initialize(ctx, vmDB, genesisData, msgChan, fxs) })
+	if err != nil {
 		return err
 	}
 
 	// Passes messages from the consensus engine to the network
 	sender := sender.Sender{}
 	sender.Initialize(ctx, m.sender, m.chainRouter, m.timeoutManager)
+	sender.TrackFailures(m.msgFailures)
 
 	// The engine handles consensus
 	engine := smeng.Transitive{}
@@ -478,15 +688,17 @@ func (m *manager) createSnowmanChain(
 	handler.Initialize(&engine, msgChan, defaultChannelSize)
 
 	// Allow incoming messages to be routed to the new chain
-	m.chainRouter.AddChain(handler)
-	go ctx.Log.RecoverAndPanic(handler.Dispatch)
+	m.chainRouter.AddChain(handler, weight)
+	withChainLabel(ctx.ChainID, func() { go ctx.ConsensusLog.RecoverAndPanic(handler.Dispatch) })
 
 	awaiting := &networking.AwaitingConnections{
 		Finish: func() {
 			ctx.Lock.Lock()
 			defer ctx.Lock.Unlock()
 
-			engine.Startup()
+			if m.isRunning(ctx.ChainID) { // The chain may have been removed while it waited
+				withChainLabel(ctx.ChainID, engine.Startup)
+			}
 		},
 	}
 	for _, vdr := range beacons.List() {
@@ -497,12 +709,132 @@ func (m *manager) createSnowmanChain(
 	return nil
 }
 
+// RemoveChain shuts down the chain [chainID], removes its API endpoints and
+// aliases, and stops routing messages to it
+func (m *manager) RemoveChain(chainID ids.ID) error {
+	m.chainsLock.Lock()
+	chain, exists := m.chains[chainID.Key()]
+	delete(m.chains, chainID.Key())
+	m.chainsLock.Unlock()
+
+	if !exists {
+		return fmt.Errorf("chain %s isn't running", chainID)
+	}
+
+	m.log.Info("removing chain %s", chainID)
+	stopPruners(chain)
+
+	// Shuts down the chain's engine and VM, which closes the VM's database,
+	// after the messages already routed to the chain are handled
+	m.chainRouter.RemoveChain(chainID)
+
+	for _, registrant := range m.registrants {
+		if deregistrant, ok := registrant.(Deregistrant); ok {
+			deregistrant.DeregisterChain(chain.ctx)
+		}
+	}
+
+	m.RemoveAliases(chainID)
+
+	m.versionsLock.Lock()
+	delete(m.versions, chainID.Key())
+	m.versionsLock.Unlock()
+	return nil
+}
+
+// RemoveSubnet removes the chains validated by [subnetID]
+func (m *manager) RemoveSubnet(subnetID ids.ID) {
+	m.chainsLock.Lock()
+	chainIDs := []ids.ID(nil)
+	for _, chain := range m.chains {
+		if chain.params.SubnetID.Equals(subnetID) {
+			chainIDs = append(chainIDs, chain.params.ID)
+		}
+	}
+	m.chainsLock.Unlock()
+
+	for _, chainID := range chainIDs {
+		if err := m.RemoveChain(chainID); err != nil {
+			m.log.Warn("couldn't remove chain %s of subnet %s: %s", chainID, subnetID, err)
+		}
+	}
+}
+
+// forget a chain that failed to be created
+func (m *manager) forget(chainID ids.ID) {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+
+	if chain, exists := m.chains[chainID.Key()]; exists {
+		stopPruners(chain)
+	}
+	delete(m.chains, chainID.Key())
+}
+
+// ChainVM returns the ID of the VM running the chain [chainID]. Implements
+// snow.VMLookup.
+func (m *manager) ChainVM(chainID ids.ID) (ids.ID, error) {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+
+	chain, running := m.chains[chainID.Key()]
+	if !running {
+		return ids.ID{}, fmt.Errorf("chain %s isn't running", chainID)
+	}
+	return chain.vmID, nil
+}
+
+// isRunning returns true if the chain [chainID] was created and hasn't been
+// removed
+func (m *manager) isRunning(chainID ids.ID) bool {
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+
+	_, running := m.chains[chainID.Key()]
+	return running
+}
+
 // Shutdown stops all the chains
-func (m *manager) Shutdown() { m.chainRouter.Shutdown() }
+func (m *manager) Shutdown() {
+	m.chainsLock.Lock()
+	for _, chain := range m.chains {
+		stopPruners(chain)
+	}
+	m.chainsLock.Unlock()
+
+	m.chainRouter.Shutdown()
+}
 
 // LookupVM returns the ID of the VM associated with an alias
 func (m *manager) LookupVM(alias string) (ids.ID, error) { return m.vmManager.Lookup(alias) }
 
+// VerifyGenesis checks [genesisData] with the static parser of the VM [vmID],
+// if the VM has one
+func (m *manager) VerifyGenesis(vmID ids.ID, fxIDs []ids.ID, genesisData []byte) (bool, error) {
+	vmFactory, err := m.vmManager.GetVMFactory(vmID)
+	if err != nil {
+		return false, err
+	}
+
+	fxs := make([]*common.Fx, len(fxIDs))
+	for i, fxID := range fxIDs {
+		fxFactory, err := m.vmManager.GetVMFactory(fxID)
+		if err != nil {
+			return false, err
+		}
+		fxs[i] = &common.Fx{
+			ID: fxID,
+			Fx: fxFactory.New(),
+		}
+	}
+
+	verifier, ok := vmFactory.(vms.GenesisVerifier)
+	if !ok {
+		return false, nil
+	}
+	return true, verifier.VerifyGenesis(genesisData, fxs)
+}
+
 // Notify registrants [those who want to know about the creation of chains]
 // that the specified chain has been created
 func (m *manager) notifyRegistrants(ctx *snow.Context, vm interface{}) {
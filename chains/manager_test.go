@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms"
+)
+
+var errUnknownVM = errors.New("unknown vm")
+
+// testVMManager records the VMs looked up and doesn't know any of them, so
+// chain creation stops right after the lookup
+type testVMManager struct {
+	vms.Manager
+	lookups []string
+}
+
+func (m *testVMManager) Lookup(alias string) (ids.ID, error) {
+	m.lookups = append(m.lookups, alias)
+	return ids.ID{}, errUnknownVM
+}
+
+func TestForceCreateChainUntrackedSubnet(t *testing.T) {
+	trackedSubnetID := ids.NewID([32]byte{1})
+	untrackedSubnetID := ids.NewID([32]byte{2})
+
+	trackedSubnets := ids.Set{}
+	trackedSubnets.Add(trackedSubnetID)
+
+	vmManager := &testVMManager{}
+	m := &manager{
+		log:            logging.NoLog{},
+		vmManager:      vmManager,
+		trackedSubnets: trackedSubnets,
+	}
+	m.Initialize()
+
+	m.ForceCreateChain(ChainParameters{
+		ID:       ids.NewID([32]byte{3}),
+		SubnetID: untrackedSubnetID,
+		VMAlias:  "untracked",
+	})
+	if len(vmManager.lookups) != 0 {
+		t.Fatalf("Chain on an untracked subnet shouldn't have been created")
+	}
+
+	m.ForceCreateChain(ChainParameters{
+		ID:       ids.NewID([32]byte{4}),
+		SubnetID: trackedSubnetID,
+		VMAlias:  "tracked",
+	})
+	m.ForceCreateChain(ChainParameters{
+		ID:       ids.NewID([32]byte{5}),
+		SubnetID: ids.Empty,
+		VMAlias:  "default",
+	})
+	if len(vmManager.lookups) != 2 || vmManager.lookups[0] != "tracked" || vmManager.lookups[1] != "default" {
+		t.Fatalf("Chains on tracked subnets should have been created but looked up %v", vmManager.lookups)
+	}
+}
@@ -0,0 +1,226 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/meterdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// chainLabel is the profiler label that attributes goroutines, and the CPU
+	// time they use, to the chain that started them
+	chainLabel = "chain"
+
+	// goroutineCountInterval is the minimum time between two goroutine profiles
+	goroutineCountInterval = time.Second
+
+	// keyCountInterval is the minimum time between two counts of the keys of a
+	// chain's database
+	keyCountInterval = 10 * time.Minute
+)
+
+// withChainLabel runs [f] labeled with [chainID]. The goroutines that [f]
+// starts inherit the label.
+func withChainLabel(chainID ids.ID, f func()) {
+	labels := pprof.Labels(chainLabel, chainID.String())
+	pprof.Do(context.Background(), labels, func(context.Context) { f() })
+}
+
+// goroutineCounter counts the goroutines labeled with each chain
+type goroutineCounter struct {
+	lock    sync.Mutex
+	updated time.Time
+	counts  map[string]int
+}
+
+// count returns the number of goroutines labeled with [chainID]
+func (g *goroutineCounter) count(chainID ids.ID) int {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if now := time.Now(); now.Sub(g.updated) >= goroutineCountInterval {
+		g.counts = countGoroutines()
+		g.updated = now
+	}
+	return g.counts[chainID.String()]
+}
+
+// countGoroutines returns the number of running goroutines per chain label
+func countGoroutines() map[string]int {
+	profile := bytes.Buffer{}
+	counts := map[string]int{}
+	if err := pprof.Lookup("goroutine").WriteTo(&profile, 1); err != nil {
+		return counts
+	}
+
+	// Each stack in the profile starts with "<count> @ <pcs>" and, if its
+	// goroutines are labeled, is followed by "# labels: {"<key>":"<value>"}"
+	labelPrefix := strconv.Quote(chainLabel) + `:"`
+	stackCount := 0
+	scanner := bufio.NewScanner(&profile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, " @ "); i > 0 {
+			stackCount, _ = strconv.Atoi(line[:i])
+			continue
+		}
+		if !strings.HasPrefix(line, "# labels:") {
+			continue
+		}
+		i := strings.Index(line, labelPrefix)
+		if i < 0 {
+			continue
+		}
+		value := line[i+len(labelPrefix):]
+		if end := strings.IndexByte(value, '"'); end >= 0 {
+			counts[value[:end]] += stackCount
+		}
+	}
+	return counts
+}
+
+// keyCounter estimates the number of keys of a database. The keys are counted
+// in the background, at most once every keyCountInterval, so the estimate lags
+// behind the database.
+type keyCounter struct {
+	db  database.Iteratee
+	log logging.Logger
+
+	lock     sync.Mutex
+	counting bool
+	counted  time.Time
+	keys     int
+}
+
+// estimate returns the number of keys found by the last count, and starts a
+// new count if the last one is too old
+func (k *keyCounter) estimate() int {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if !k.counting && time.Since(k.counted) >= keyCountInterval {
+		k.counting = true
+		go k.log.RecoverAndPanic(k.count)
+	}
+	return k.keys
+}
+
+// count the keys of the database
+func (k *keyCounter) count() {
+	it := k.db.NewIterator()
+	keys := 0
+	for it.Next() {
+		keys++
+	}
+	err := it.Error()
+	it.Release()
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.counting = false
+	k.counted = time.Now()
+	if err != nil {
+		k.log.Warn("couldn't count the keys of the database due to %s", err)
+		return
+	}
+	k.keys = keys
+}
+
+// registerChainMetrics registers the resources used by the chain of [ctx]: its
+// goroutines, the bytes it reads from and writes to the database, the latency
+// of its database operations, and the size of its database [db]
+func registerChainMetrics(ctx *snow.Context, db database.Database, meter *meterdb.Meter, goroutines *goroutineCounter) {
+	numGoroutines := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: ctx.Namespace,
+			Name:      "goroutines",
+			Help:      "Number of goroutines started by this chain",
+		},
+		func() float64 { return float64(goroutines.count(ctx.ChainID)) })
+	readBytes := prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: ctx.Namespace,
+			Name:      "db_read_bytes",
+			Help:      "Number of bytes read from the database",
+		},
+		func() float64 { return float64(meter.Read()) })
+	writtenBytes := prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Namespace: ctx.Namespace,
+			Name:      "db_written_bytes",
+			Help:      "Number of bytes written to the database",
+		},
+		func() float64 { return float64(meter.Written()) })
+
+	if err := ctx.Metrics.Register(numGoroutines); err != nil {
+		ctx.Log.Error("Failed to register goroutines statistics due to %s", err)
+	}
+	if err := ctx.Metrics.Register(readBytes); err != nil {
+		ctx.Log.Error("Failed to register db_read_bytes statistics due to %s", err)
+	}
+	if err := ctx.Metrics.Register(writtenBytes); err != nil {
+		ctx.Log.Error("Failed to register db_written_bytes statistics due to %s", err)
+	}
+	if err := meter.Register(ctx.Namespace, ctx.Metrics); err != nil {
+		ctx.Log.Error("Failed to register database operation statistics due to %s", err)
+	}
+
+	counter := &keyCounter{
+		db:  db,
+		log: ctx.Log,
+	}
+	numKeys := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: ctx.Namespace,
+			Name:      "db_keys",
+			Help:      "Estimated number of keys in the database, counted every " + keyCountInterval.String(),
+		},
+		func() float64 { return float64(counter.estimate()) })
+	if err := ctx.Metrics.Register(numKeys); err != nil {
+		ctx.Log.Error("Failed to register db_keys statistics due to %s", err)
+	}
+
+	// Prefixed databases are only able to estimate their size if the database
+	// they're in is
+	if sizer, ok := db.(database.DiskSizer); ok && sizerWorks(sizer) {
+		diskSize := prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: ctx.Namespace,
+				Name:      "db_disk_bytes",
+				Help:      "Estimated number of bytes the database uses on disk",
+			},
+			func() float64 {
+				size, err := sizer.DiskSize(nil, nil)
+				if err != nil {
+					return 0
+				}
+				return float64(size)
+			})
+		if err := ctx.Metrics.Register(diskSize); err != nil {
+			ctx.Log.Error("Failed to register db_disk_bytes statistics due to %s", err)
+		}
+	}
+}
+
+// sizerWorks returns true if [sizer] is able to estimate its size on disk
+func sizerWorks(sizer database.DiskSizer) bool {
+	_, err := sizer.DiskSize(nil, nil)
+	return err == nil
+}
@@ -0,0 +1,71 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestGoroutineCounter(t *testing.T) {
+	// Goroutines left over from earlier runs of this test mustn't be counted
+	chainBytes := [32]byte{}
+	rand.Read(chainBytes[:])
+	chainID := ids.NewID(chainBytes)
+	otherID := ids.NewID([32]byte{})
+
+	done := make(chan struct{})
+	defer close(done)
+
+	withChainLabel(chainID, func() {
+		for i := 0; i < 3; i++ {
+			go func() { <-done }()
+		}
+	})
+
+	counter := goroutineCounter{}
+	if count := counter.count(chainID); count != 3 {
+		t.Fatalf("count Returned: %d ; Expected: %d", count, 3)
+	}
+	if count := counter.count(otherID); count != 0 {
+		t.Fatalf("count Returned: %d ; Expected: %d", count, 0)
+	}
+}
+
+func TestKeyCounter(t *testing.T) {
+	db := memdb.New()
+	for i := 0; i < 5; i++ {
+		if err := db.Put([]byte{byte(i)}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counter := &keyCounter{
+		db:  db,
+		log: logging.NoLog{},
+	}
+	if keys := counter.estimate(); keys != 0 {
+		t.Fatalf("The first estimate should be made before the keys are counted but was %d", keys)
+	}
+	for counting := true; counting; {
+		counter.lock.Lock()
+		counting = counter.counting
+		counter.lock.Unlock()
+	}
+	if keys := counter.estimate(); keys != 5 {
+		t.Fatalf("Should have counted 5 keys but counted %d", keys)
+	}
+
+	// The keys aren't counted again until keyCountInterval passes
+	if err := db.Put([]byte{5}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if keys := counter.estimate(); keys != 5 {
+		t.Fatalf("Shouldn't have counted the keys again but counted %d", keys)
+	}
+}
@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"github.com/ava-labs/gecko/database/migration"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+// vmPrefix is the prefix of a VM's database in its chain's database
+var vmPrefix = []byte("vm")
+
+// migrate runs the migrations of the database of [vm], which runs the chain
+// [ctx], if the VM has any
+func (m *manager) migrate(ctx *snow.Context, vm interface{}) error {
+	migratable, ok := vm.(common.MigratableVM)
+	if !ok {
+		return nil
+	}
+	from, to, err := migration.Run(m.db, [][]byte{ctx.ChainID.Bytes(), vmPrefix}, migratable.Migrations())
+	if err != nil {
+		return err
+	}
+	if from != to {
+		ctx.Log.Info("migrated the database of chain %s from schema version %d to %d", ctx.ChainID, from, to)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/pruning"
+)
+
+// pruneFrequency is how often the keys scheduled to be pruned from a chain's
+// databases are deleted
+const pruneFrequency = time.Minute
+
+// newPruner returns the pruner of [db], a database of the chain [ctx] whose
+// database is [chainDB], or nil if the chain's state isn't pruned. State is
+// only deleted if the chain's state mode, which is what the node advertises to
+// its peers, is pruned. The keys scheduled to be pruned are queued in
+// [chainDB] under [queuePrefix]. The pruner is stopped when the chain is
+// removed.
+func (m *manager) newPruner(ctx *snow.Context, chainDB, db database.Database, queuePrefix []byte) (*pruning.Pruner, error) {
+	if !m.statePrune || ctx.StateMode != snow.PrunedState {
+		return nil, nil
+	}
+	m.chainsLock.Lock()
+	defer m.chainsLock.Unlock()
+
+	log := ctx.Log
+	chain, exists := m.chains[ctx.ChainID.Key()]
+	if exists {
+		log = chain.dbLog
+	}
+	pruner, err := pruning.New(log, db, prefixdb.New(queuePrefix, chainDB), pruneFrequency)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		chain.pruners = append(chain.pruners, pruner)
+	}
+	return pruner, nil
+}
+
+// stopPruners stops pruning the databases of [chain]
+func stopPruners(chain *runningChain) {
+	for _, pruner := range chain.pruners {
+		pruner.Stop()
+	}
+}
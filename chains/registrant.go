@@ -11,3 +11,10 @@ import (
 type Registrant interface {
 	RegisterChain(ctx *snow.Context, vm interface{})
 }
+
+// Deregistrant is a Registrant that is notified when a chain is removed
+type Deregistrant interface {
+	Registrant
+
+	DeregisterChain(ctx *snow.Context)
+}
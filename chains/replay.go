@@ -0,0 +1,269 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/engine/avalanche"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/triggers"
+
+	smeng "github.com/ava-labs/gecko/snow/engine/snowman"
+)
+
+var (
+	errNoTxIndex       = errors.New("the vm doesn't index its accepted transactions")
+	errStateMismatch   = errors.New("the state of the replayed vm doesn't match the state of the chain")
+	errReplayMismatch  = errors.New("the replayed vm's last accepted container doesn't match the chain's")
+	errMismatchedTypes = errors.New("the replayed vm isn't of the same type as the chain's vm")
+)
+
+// replaySnowman accepts the blocks accepted by [source], in order of height,
+// on [fresh], which must be a new instance of the same VM initialized with the
+// same genesis. Returns the number of blocks replayed.
+// Returns an error if a block isn't accepted the same way by [fresh], or if
+// the VMs' states differ after the replay.
+func replaySnowman(source, fresh smeng.ChainVM) (int, error) {
+	numReplayed := 0
+	// Blocks the fresh VM already has, such as its genesis, are checked rather
+	// than replayed
+	for height := uint64(0); ; height++ {
+		blkID, err := source.GetBlockIDAtHeight(height)
+		if err != nil {
+			break // [height] is past the last accepted block
+		}
+		if freshID, err := fresh.GetBlockIDAtHeight(height); err == nil {
+			if !freshID.Equals(blkID) {
+				return numReplayed, fmt.Errorf("block %s at height %d was replayed as %s", blkID, height, freshID)
+			}
+			continue
+		}
+
+		blk, err := source.GetBlock(blkID)
+		if err != nil {
+			return numReplayed, fmt.Errorf("couldn't get accepted block %s: %w", blkID, err)
+		}
+		replayedBlk, err := fresh.ParseBlock(blk.Bytes())
+		if err != nil {
+			return numReplayed, fmt.Errorf("couldn't parse block %s at height %d: %w", blkID, height, err)
+		}
+		if replayedID := replayedBlk.ID(); !replayedID.Equals(blkID) {
+			return numReplayed, fmt.Errorf("block %s at height %d was parsed as %s", blkID, height, replayedID)
+		}
+		if err := replayedBlk.Verify(); err != nil {
+			return numReplayed, fmt.Errorf("block %s at height %d failed verification: %w", blkID, height, err)
+		}
+		replayedBlk.Accept()
+		fresh.SetPreference(blkID)
+		numReplayed++
+	}
+
+	if sourceID, freshID := source.LastAccepted(), fresh.LastAccepted(); !sourceID.Equals(freshID) {
+		return numReplayed, fmt.Errorf("%w: %s != %s", errReplayMismatch, freshID, sourceID)
+	}
+	return numReplayed, compareStateHashes(source, fresh)
+}
+
+// replayAvalanche accepts the transactions accepted by [source], in the order
+// they were accepted, on [fresh], which must be a new instance of the same VM
+// initialized with the same genesis. Returns the number of transactions
+// replayed.
+// Returns an error if a transaction isn't accepted the same way by [fresh], or
+// if the VMs' states differ after the replay.
+func replayAvalanche(source, fresh avalanche.DAGVM) (int, error) {
+	sourceIndex, ok := source.(avalanche.TxIndexer)
+	if !ok {
+		return 0, errNoTxIndex
+	}
+	freshIndex, ok := fresh.(avalanche.TxIndexer)
+	if !ok {
+		return 0, errNoTxIndex
+	}
+
+	numAccepted, err := sourceIndex.NumAcceptedTxs()
+	if err != nil {
+		return 0, err
+	}
+	start, err := freshIndex.NumAcceptedTxs() // Skip the genesis transactions
+	if err != nil {
+		return 0, err
+	}
+
+	numReplayed := 0
+	for index := start; index < numAccepted; index++ {
+		txID, err := sourceIndex.GetTxIDAtIndex(index)
+		if err != nil {
+			return numReplayed, fmt.Errorf("couldn't get the tx accepted at index %d: %w", index, err)
+		}
+		tx, err := source.GetTx(txID)
+		if err != nil {
+			return numReplayed, fmt.Errorf("couldn't get accepted tx %s: %w", txID, err)
+		}
+		replayedTx, err := fresh.ParseTx(tx.Bytes())
+		if err != nil {
+			return numReplayed, fmt.Errorf("couldn't parse tx %s at index %d: %w", txID, index, err)
+		}
+		if replayedID := replayedTx.ID(); !replayedID.Equals(txID) {
+			return numReplayed, fmt.Errorf("tx %s at index %d was parsed as %s", txID, index, replayedID)
+		}
+		// Transactions are replayed in the order they were accepted, so their
+		// dependencies have already been accepted
+		if err := replayedTx.Verify(); err != nil {
+			return numReplayed, fmt.Errorf("tx %s at index %d failed verification: %w", txID, index, err)
+		}
+		replayedTx.Accept()
+		if status := replayedTx.Status(); status != choices.Accepted {
+			return numReplayed, fmt.Errorf("tx %s at index %d has status %s after being accepted", txID, index, status)
+		}
+		numReplayed++
+	}
+
+	replayedAccepted, err := freshIndex.NumAcceptedTxs()
+	if err != nil {
+		return numReplayed, err
+	}
+	if replayedAccepted != numAccepted {
+		return numReplayed, fmt.Errorf("%w: %d txs were accepted but %d were replayed", errReplayMismatch, numAccepted, replayedAccepted)
+	}
+	return numReplayed, compareStateHashes(source, fresh)
+}
+
+// compareStateHashes returns an error if [source] and [fresh] report different
+// state hashes. VMs that don't report state hashes are assumed to match.
+func compareStateHashes(source, fresh interface{}) error {
+	sourceHasher, ok := source.(common.StateHasher)
+	if !ok {
+		return nil
+	}
+	freshHasher, ok := fresh.(common.StateHasher)
+	if !ok {
+		return nil
+	}
+	sourceHash, err := sourceHasher.StateHash()
+	if err != nil {
+		return err
+	}
+	freshHash, err := freshHasher.StateHash()
+	if err != nil {
+		return err
+	}
+	if !sourceHash.Equals(freshHash) {
+		return fmt.Errorf("%w: %s != %s", errStateMismatch, freshHash, sourceHash)
+	}
+	return nil
+}
+
+// replayChain replays the accepted containers of the chain [ctx] against a new
+// instance of its VM, and logs the result. The chain is blocked while it is
+// replayed.
+//
+// The new instance gets an in-memory database and shared memory, so the
+// replay doesn't change the state of this node. As a result, transactions that
+// import from other chains fail to replay.
+func (m *manager) replayChain(ctx *snow.Context, chain ChainParameters, vm interface{}) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	numReplayed, err := m.replay(ctx, chain, vm)
+	if err != nil {
+		ctx.Log.Error("replay of chain %s failed after %d containers: %s", chain.ID, numReplayed, err)
+		return
+	}
+	ctx.Log.Info("replayed %d containers of chain %s. The resulting state matches", numReplayed, chain.ID)
+}
+
+func (m *manager) replay(ctx *snow.Context, chain ChainParameters, vm interface{}) (int, error) {
+	vmID, err := m.vmManager.Lookup(chain.VMAlias)
+	if err != nil {
+		return 0, err
+	}
+	vmFactory, err := m.vmManager.GetVMFactory(vmID)
+	if err != nil {
+		return 0, err
+	}
+	fxs, err := m.newFxs(chain.FxAliases)
+	if err != nil {
+		return 0, err
+	}
+
+	replayLog, err := m.logFactory.MakeChain(chain.ID, "replay")
+	if err != nil {
+		return 0, err
+	}
+	sharedMemory := &atomic.Memory{}
+	sharedMemory.Initialize(replayLog, memdb.New())
+	decisionEvents := &triggers.EventDispatcher{}
+	decisionEvents.Initialize(replayLog)
+	consensusEvents := &triggers.EventDispatcher{}
+	consensusEvents.Initialize(replayLog)
+
+	// The lock isn't copied from [ctx], so the context is built field by field
+	replayCtx := &snow.Context{
+		NetworkID:           ctx.NetworkID,
+		ChainID:             ctx.ChainID,
+		NodeID:              ctx.NodeID,
+		Log:                 replayLog,
+		ConsensusLog:        replayLog,
+		DecisionDispatcher:  decisionEvents,
+		ConsensusDispatcher: consensusEvents,
+		HTTP:                ctx.HTTP,
+		BCLookup:            ctx.BCLookup,
+		SharedMemory:        sharedMemory.NewSharedMemory(ctx.ChainID),
+		Namespace:           ctx.Namespace + "_replay",
+		Metrics:             prometheus.NewRegistry(),
+		Upgrades:            ctx.Upgrades,
+	}
+	replayCtx.Lock.Lock()
+	defer replayCtx.Lock.Unlock()
+
+	fresh := vmFactory.New()
+	toEngine := make(chan common.Message, 1)
+	switch vm := vm.(type) {
+	case avalanche.DAGVM:
+		freshVM, ok := fresh.(avalanche.DAGVM)
+		if !ok {
+			return 0, errMismatchedTypes
+		}
+		if err := freshVM.Initialize(replayCtx, memdb.New(), chain.GenesisData, toEngine, fxs); err != nil {
+			return 0, err
+		}
+		defer freshVM.Shutdown()
+		return replayAvalanche(vm, freshVM)
+	case smeng.ChainVM:
+		freshVM, ok := fresh.(smeng.ChainVM)
+		if !ok {
+			return 0, errMismatchedTypes
+		}
+		if err := freshVM.Initialize(replayCtx, memdb.New(), chain.GenesisData, toEngine, fxs); err != nil {
+			return 0, err
+		}
+		defer freshVM.Shutdown()
+		return replaySnowman(vm, freshVM)
+	default:
+		return 0, errMismatchedTypes
+	}
+}
+
+// isReplayed returns true if the chain [chainID] should be replayed when it
+// is created
+func (m *manager) isReplayed(chainID ids.ID) bool {
+	aliases := append(m.Aliases(chainID), chainID.String())
+	for _, replayed := range m.replayChains {
+		for _, alias := range aliases {
+			if replayed == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+
+	smeng "github.com/ava-labs/gecko/snow/engine/snowman"
+)
+
+var errUnknownBlock = errors.New("unknown block")
+
+type testBlock struct {
+	id       ids.ID
+	bytes    []byte
+	status   choices.Status
+	verifyF  func() error
+	onAccept func()
+}
+
+func (b *testBlock) ID() ids.ID             { return b.id }
+func (b *testBlock) Parent() snowman.Block  { return nil }
+func (b *testBlock) Accept()                { b.status = choices.Accepted; b.onAccept() }
+func (b *testBlock) Reject()                { b.status = choices.Rejected }
+func (b *testBlock) Status() choices.Status { return b.status }
+func (b *testBlock) Verify() error          { return b.verifyF() }
+func (b *testBlock) Bytes() []byte          { return b.bytes }
+
+// testChainVM returns a VM that has accepted the blocks [accepted], in order
+func testChainVM(t *testing.T, accepted [][]byte) *smeng.VMTest {
+	vm := &smeng.VMTest{}
+	vm.T = t
+	vm.Default(true)
+
+	acceptedIDs := []ids.ID(nil)
+	newBlock := func(b []byte) *testBlock {
+		blk := &testBlock{
+			id:      ids.NewID([32]byte{b[0]}),
+			bytes:   b,
+			status:  choices.Processing,
+			verifyF: func() error { return nil },
+		}
+		blk.onAccept = func() { acceptedIDs = append(acceptedIDs, blk.id) }
+		return blk
+	}
+	for _, b := range accepted {
+		newBlock(b).Accept()
+	}
+
+	vm.GetBlockIDAtHeightF = func(height uint64) (ids.ID, error) {
+		if height >= uint64(len(acceptedIDs)) {
+			return ids.ID{}, errUnknownBlock
+		}
+		return acceptedIDs[height], nil
+	}
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) {
+		for _, b := range accepted {
+			if blk := newBlock(b); blk.id.Equals(blkID) {
+				return blk, nil
+			}
+		}
+		return nil, errUnknownBlock
+	}
+	vm.ParseBlockF = func(b []byte) (snowman.Block, error) { return newBlock(b), nil }
+	vm.SetPreferenceF = func(ids.ID) {}
+	vm.LastAcceptedF = func() ids.ID { return acceptedIDs[len(acceptedIDs)-1] }
+	return vm
+}
+
+func TestReplaySnowman(t *testing.T) {
+	blocks := [][]byte{{0}, {1}, {2}, {3}}
+
+	source := testChainVM(t, blocks)
+	fresh := testChainVM(t, blocks[:1])
+
+	numReplayed, err := replaySnowman(source, fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numReplayed != 3 {
+		t.Fatalf("Replayed %d blocks, expected 3", numReplayed)
+	}
+	if lastAccepted := fresh.LastAccepted(); !lastAccepted.Equals(ids.NewID([32]byte{3})) {
+		t.Fatalf("Replayed VM accepted %s last", lastAccepted)
+	}
+}
+
+func TestReplaySnowmanDivergentGenesis(t *testing.T) {
+	source := testChainVM(t, [][]byte{{0}, {1}})
+	fresh := testChainVM(t, [][]byte{{2}})
+
+	if _, err := replaySnowman(source, fresh); err == nil {
+		t.Fatalf("Should have failed to replay on a VM with a different genesis")
+	}
+}
+
+func TestReplaySnowmanParseMismatch(t *testing.T) {
+	source := testChainVM(t, [][]byte{{0}, {1}})
+	fresh := testChainVM(t, [][]byte{{0}})
+	fresh.ParseBlockF = func([]byte) (snowman.Block, error) {
+		return &testBlock{
+			id:       ids.NewID([32]byte{5}),
+			status:   choices.Processing,
+			verifyF:  func() error { return nil },
+			onAccept: func() {},
+		}, nil
+	}
+
+	if _, err := replaySnowman(source, fresh); err == nil {
+		t.Fatalf("Should have failed when the block was parsed differently")
+	}
+}
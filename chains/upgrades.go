@@ -0,0 +1,86 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+var (
+	errUnknownChain          = errors.New("unknown chain")
+	errUnsupportedUpgrade    = errors.New("the vm doesn't implement the upgrade")
+	errMultipleUpgradeConfig = errors.New("the upgrades of the chain are scheduled under multiple aliases")
+)
+
+// unversioned is reported as the version of VMs that don't declare one
+const unversioned = "unversioned"
+
+// chainVersion is the version of the VM running a chain, and the upgrades
+// scheduled for the chain
+type chainVersion struct {
+	version  string
+	upgrades snow.Upgrades
+}
+
+// chainUpgrades returns the version of [vm] and the upgrades scheduled for
+// the chain [chainID]. Returns an error if [vm] doesn't implement one of the
+// upgrades, as the chain would diverge from the rest of the network once the
+// upgrade activates.
+func (m *manager) chainUpgrades(chainID ids.ID, vm interface{}) (chainVersion, error) {
+	upgrades := snow.Upgrades(nil)
+	scheduledUnder := ""
+	for _, alias := range append(m.Aliases(chainID), chainID.String()) {
+		scheduled, ok := m.upgrades[alias]
+		if !ok {
+			continue
+		}
+		if scheduledUnder != "" {
+			return chainVersion{}, fmt.Errorf("%w: %s and %s", errMultipleUpgradeConfig, scheduledUnder, alias)
+		}
+		upgrades = scheduled
+		scheduledUnder = alias
+	}
+	if err := upgrades.Verify(); err != nil {
+		return chainVersion{}, err
+	}
+
+	versioned, ok := vm.(common.VersionedVM)
+	if !ok {
+		if len(upgrades) > 0 {
+			return chainVersion{}, fmt.Errorf("%w: %s", errUnsupportedUpgrade, upgrades[0].Name)
+		}
+		return chainVersion{version: unversioned}, nil
+	}
+
+	implemented := make(map[string]bool)
+	for _, name := range versioned.Upgrades() {
+		implemented[name] = true
+	}
+	for _, upgrade := range upgrades {
+		if !implemented[upgrade.Name] {
+			return chainVersion{}, fmt.Errorf("%w: version %s doesn't implement %s", errUnsupportedUpgrade, versioned.Version(), upgrade.Name)
+		}
+	}
+	return chainVersion{
+		version:  versioned.Version(),
+		upgrades: upgrades,
+	}, nil
+}
+
+// Version implements the Manager interface
+func (m *manager) Version(chainID ids.ID) (string, snow.Upgrades, error) {
+	m.versionsLock.RLock()
+	defer m.versionsLock.RUnlock()
+
+	version, ok := m.versions[chainID.Key()]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", errUnknownChain, chainID)
+	}
+	return version.version, version.upgrades, nil
+}
@@ -0,0 +1,64 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+)
+
+type testVersionedVM struct {
+	version  string
+	upgrades []string
+}
+
+func (vm *testVersionedVM) Version() string    { return vm.version }
+func (vm *testVersionedVM) Upgrades() []string { return vm.upgrades }
+
+func TestChainUpgrades(t *testing.T) {
+	chainID := ids.NewID([32]byte{1})
+	m := &manager{
+		upgrades: map[string]snow.Upgrades{
+			"X": {{Name: "a", Height: 5}},
+		},
+	}
+	m.Initialize()
+	if err := m.Alias(chainID, "X"); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := m.chainUpgrades(chainID, &testVersionedVM{version: "v1", upgrades: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.version != "v1" {
+		t.Fatalf("Wrong version %s", version.version)
+	}
+	if !version.upgrades.IsActivated("a", 5, version.upgrades[0].Time) {
+		t.Fatalf("Upgrade should have been scheduled")
+	}
+
+	if _, err := m.chainUpgrades(chainID, &testVersionedVM{version: "v0"}); !errors.Is(err, errUnsupportedUpgrade) {
+		t.Fatalf("Should have errored due to an unimplemented upgrade but returned %v", err)
+	}
+	if _, err := m.chainUpgrades(chainID, struct{}{}); !errors.Is(err, errUnsupportedUpgrade) {
+		t.Fatalf("Should have errored due to an unversioned VM but returned %v", err)
+	}
+
+	version, err = m.chainUpgrades(ids.NewID([32]byte{2}), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.version != unversioned || len(version.upgrades) != 0 {
+		t.Fatalf("Chain without scheduled upgrades should be unversioned without upgrades")
+	}
+
+	m.upgrades[chainID.String()] = snow.Upgrades{}
+	if _, err := m.chainUpgrades(chainID, &testVersionedVM{}); !errors.Is(err, errMultipleUpgradeConfig) {
+		t.Fatalf("Should have errored due to upgrades scheduled under multiple aliases but returned %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package backup streams the key/value pairs of a database to a file, and
+// restores them.
+//
+// A backup is a header followed by frames. Each frame is the length of its
+// body, the CRC-32 checksum of its body, and its body, which is a compressed
+// chunk of key/value pairs. A frame with an empty body ends the backup.
+//
+// A backup is only restored into an empty database, and a running node's
+// database never is. Restoring a node's database is scheduled with
+// ScheduleRestore, and done by the node before it opens its chains the next
+// time it starts.
+package backup
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// maxChunkSize is the largest chunk, before it's compressed, that is
+	// written or restored
+	maxChunkSize = 1 << 26
+
+	// frameHeaderSize is the size, in bytes, of the length and checksum of a
+	// frame
+	frameHeaderSize = 2 * wrappers.IntLen
+
+	// version of the backup format
+	version byte = 0
+
+	// PendingRestoreFile is the name of the file, in a database's directory,
+	// that holds the path of the backup to restore the next time the node
+	// starts
+	PendingRestoreFile = "PENDING_RESTORE"
+)
+
+var (
+	magic = []byte("gecko-backup")
+
+	errNotBackup         = errors.New("file isn't a database backup")
+	errChecksum          = errors.New("frame checksum mismatch")
+	errFrameTooLarge     = errors.New("frame is too large")
+	errChunkTooLarge     = errors.New("chunk is too large")
+	errUnexpectedVersion = errors.New("unexpected backup version")
+	errNotEmpty          = errors.New("a backup can only be restored into an empty database")
+)
+
+// Backup writes the key/value pairs of [db] to [w], and returns how many were
+// written. The backup is consistent if iterating over [db] is, which is the
+// case for LevelDB and the in-memory database.
+func Backup(db database.Iteratee, w io.Writer) (int, error) {
+	header := append(append([]byte{}, magic...), version)
+	if _, err := w.Write(header); err != nil {
+		return 0, err
+	}
+
+	pairs, err := database.WriteChunks(db, maxChunkSize, func(chunk []byte) error {
+		return writeFrame(w, chunk)
+	})
+	if err != nil {
+		return pairs, err
+	}
+	return pairs, writeFrame(w, nil)
+}
+
+// Restore writes the key/value pairs backed up in [r] to [db], which must be
+// empty, and returns how many were written. Each chunk of pairs is written in
+// one batch once its checksum is verified, so if restoring fails, the pairs of
+// the earlier chunks have been written.
+func Restore(db database.Database, r io.Reader) (int, error) {
+	it := db.NewIterator()
+	empty := !it.Next()
+	it.Release()
+	if !empty {
+		return 0, errNotEmpty
+	}
+
+	if err := readHeader(r); err != nil {
+		return 0, err
+	}
+
+	pairs := 0
+	for {
+		chunk, err := readFrame(r)
+		if err != nil {
+			return pairs, err
+		}
+		if chunk == nil {
+			return pairs, nil
+		}
+		restored, err := database.RestoreChunk(db, chunk)
+		pairs += restored
+		if err != nil {
+			return pairs, err
+		}
+	}
+}
+
+// BackupFile backs up [db] to the file [path]. The backup is written to a
+// temporary file that is renamed once the backup is complete, so [path] never
+// holds a partial backup.
+func BackupFile(db database.Iteratee, path string) (int, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+
+	pairs, err := Backup(db, file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, err
+	}
+	return pairs, os.Rename(tmpPath, path)
+}
+
+// RestoreFile restores the backup in the file [path] to [db], which must be
+// empty
+func RestoreFile(db database.Database, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return Restore(db, file)
+}
+
+// ScheduleRestore schedules the backup in the file [path] to be restored to the
+// database in the directory [dir] the next time the node starts. Only the
+// header of the backup is checked now.
+func ScheduleRestore(dir, path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = readHeader(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	pendingPath := filepath.Join(dir, PendingRestoreFile)
+	tmpPath := pendingPath + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, []byte(path), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, pendingPath)
+}
+
+// PendingRestore returns the path of the backup scheduled to be restored to the
+// database in the directory [dir], or the empty string if there's none
+func PendingRestore(dir string) (string, error) {
+	path, err := ioutil.ReadFile(filepath.Join(dir, PendingRestoreFile))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	return string(path), err
+}
+
+// readHeader reads the header of a backup from [r]
+func readHeader(r io.Reader) error {
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, header); err != nil || !bytes.Equal(header[:len(magic)], magic) {
+		return errNotBackup
+	}
+	if header[len(magic)] != version {
+		return errUnexpectedVersion
+	}
+	return nil
+}
+
+// writeFrame writes [chunk], compressed, as a frame to [w]
+func writeFrame(w io.Writer, chunk []byte) error {
+	body := []byte(nil)
+	if len(chunk) > 0 {
+		buf := bytes.Buffer{}
+		compressor, err := flate.NewWriter(&buf, flate.DefaultCompression)
+		if err != nil {
+			return err
+		}
+		if _, err := compressor.Write(chunk); err != nil {
+			return err
+		}
+		if err := compressor.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	header := make([]byte, frameHeaderSize)
+	binary.BigEndian.PutUint32(header, uint32(len(body)))
+	binary.BigEndian.PutUint32(header[wrappers.IntLen:], crc32.ChecksumIEEE(body))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// readFrame reads a frame from [r] and returns its chunk, or nil if the frame
+// ends the backup
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	checksum := binary.BigEndian.Uint32(header[wrappers.IntLen:])
+	if length == 0 {
+		return nil, nil
+	}
+	// A chunk can't be compressed to more than slightly larger than itself
+	if length > 2*maxChunkSize {
+		return nil, errFrameTooLarge
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, errChecksum
+	}
+
+	decompressor := flate.NewReader(bytes.NewReader(body))
+	defer decompressor.Close()
+
+	chunk, err := ioutil.ReadAll(io.LimitReader(decompressor, maxChunkSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(chunk) > maxChunkSize {
+		return nil, errChunkTooLarge
+	}
+	return chunk, nil
+}
@@ -0,0 +1,186 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+// fill puts [n] pairs, with values large enough to span several chunks, in
+// [db]
+func fill(t *testing.T, db database.Database, n int) {
+	for i := 0; i < n; i++ {
+		key := []byte{byte(i >> 8), byte(i)}
+		value := bytes.Repeat([]byte{byte(i)}, database.ChunkSize/100)
+		if err := db.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// assertEqual fails if [db] and [expected] don't hold the same pairs
+func assertEqual(t *testing.T, expected, db database.Database) {
+	expectedIt := expected.NewIterator()
+	defer expectedIt.Release()
+	it := db.NewIterator()
+	defer it.Release()
+
+	for expectedIt.Next() {
+		if !it.Next() {
+			t.Fatalf("Missing key 0x%x", expectedIt.Key())
+		}
+		if !bytes.Equal(it.Key(), expectedIt.Key()) || !bytes.Equal(it.Value(), expectedIt.Value()) {
+			t.Fatalf("Restored key 0x%x ; Expected key 0x%x", it.Key(), expectedIt.Key())
+		}
+	}
+	if it.Next() {
+		t.Fatalf("Unexpected key 0x%x", it.Key())
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	db := memdb.New()
+	fill(t, db, 300)
+
+	buf := bytes.Buffer{}
+	if pairs, err := Backup(db, &buf); err != nil {
+		t.Fatal(err)
+	} else if pairs != 300 {
+		t.Fatalf("Backed up %d pairs ; Expected 300", pairs)
+	}
+
+	restored := memdb.New()
+	if pairs, err := Restore(restored, &buf); err != nil {
+		t.Fatal(err)
+	} else if pairs != 300 {
+		t.Fatalf("Restored %d pairs ; Expected 300", pairs)
+	}
+	assertEqual(t, db, restored)
+}
+
+func TestBackupRestoreEmpty(t *testing.T) {
+	buf := bytes.Buffer{}
+	if _, err := Backup(memdb.New(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if pairs, err := Restore(memdb.New(), &buf); err != nil {
+		t.Fatal(err)
+	} else if pairs != 0 {
+		t.Fatalf("Restored %d pairs ; Expected 0", pairs)
+	}
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	db := memdb.New()
+	fill(t, db, 10)
+
+	buf := bytes.Buffer{}
+	if _, err := Backup(db, &buf); err != nil {
+		t.Fatal(err)
+	}
+	backup := buf.Bytes()
+
+	corrupted := append([]byte{}, backup...)
+	corrupted[len(magic)+1+frameHeaderSize]++
+	if _, err := Restore(memdb.New(), bytes.NewReader(corrupted)); err != errChecksum {
+		t.Fatalf("Restore returned %v ; Expected %s", err, errChecksum)
+	}
+
+	if _, err := Restore(memdb.New(), bytes.NewReader(backup[:len(backup)-1])); err == nil {
+		t.Fatalf("Restoring a truncated backup should have failed")
+	}
+
+	if _, err := Restore(memdb.New(), bytes.NewReader([]byte("not a backup"))); err != errNotBackup {
+		t.Fatalf("Restore returned %v ; Expected %s", err, errNotBackup)
+	}
+}
+
+func TestBackupRestoreFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.backup")
+
+	db := memdb.New()
+	fill(t, db, 10)
+	if _, err := BackupFile(db, path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("Temporary backup file should have been removed")
+	}
+
+	restored := memdb.New()
+	if _, err := RestoreFile(restored, path); err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, db, restored)
+}
+
+func TestRestoreNotEmpty(t *testing.T) {
+	db := memdb.New()
+	fill(t, db, 10)
+
+	buf := bytes.Buffer{}
+	if _, err := Backup(db, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	live := memdb.New()
+	if err := live.Put([]byte{0xff}, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(live, &buf); err != errNotEmpty {
+		t.Fatalf("Restore returned %v ; Expected %s", err, errNotEmpty)
+	}
+	if has, err := live.Has([]byte{0}); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Backup shouldn't have been merged into the database")
+	}
+}
+
+func TestScheduleRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "db.backup")
+
+	if pending, err := PendingRestore(dir); err != nil {
+		t.Fatal(err)
+	} else if pending != "" {
+		t.Fatalf("No restore should be pending, but %s is", pending)
+	}
+
+	notBackup := filepath.Join(dir, "not.backup")
+	if err := ioutil.WriteFile(notBackup, []byte("not a backup"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ScheduleRestore(dir, notBackup); err != errNotBackup {
+		t.Fatalf("ScheduleRestore returned %v ; Expected %s", err, errNotBackup)
+	}
+
+	if _, err := BackupFile(memdb.New(), path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ScheduleRestore(dir, path); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := PendingRestore(dir); err != nil {
+		t.Fatal(err)
+	} else if pending != path {
+		t.Fatalf("Pending restore is %s ; Expected %s", pending, path)
+	}
+}
@@ -0,0 +1,401 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package badgerdb
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/dgraph-io/badger"
+)
+
+const (
+	// gcInterval is how often the space of overwritten and deleted values is
+	// reclaimed from the value log
+	gcInterval = 5 * time.Minute
+
+	// gcDiscardRatio is the fraction of a value log file that must be
+	// reclaimable for the file to be rewritten
+	gcDiscardRatio = .5
+)
+
+// Database is a persistent key-value store on top of BadgerDB. Apart from basic
+// data storage functionality it also supports batch writes and iterating over
+// the keyspace in binary-alphabetical order.
+type Database struct {
+	lock sync.RWMutex
+	db   *badger.DB
+
+	// Closed when the database is closed, to stop garbage collection
+	closed chan struct{}
+}
+
+// New returns a BadgerDB database stored in [dir]
+func New(dir string) (*Database, error) { return open(dir, false) }
+
+// NewStrict returns a BadgerDB database stored in [dir] that verifies the
+// checksum of every value it reads
+func NewStrict(dir string) (*Database, error) { return open(dir, true) }
+
+func open(dir string, strict bool) (*Database, error) {
+	options := badger.DefaultOptions(dir).
+		WithLogger(nil).
+		WithVerifyValueChecksum(strict)
+
+	db, err := badger.Open(options)
+	if err != nil {
+		return nil, err
+	}
+
+	bdb := &Database{
+		db:     db,
+		closed: make(chan struct{}),
+	}
+	go bdb.collectGarbage()
+	return bdb, nil
+}
+
+// collectGarbage periodically reclaims space from the value log until the
+// database is closed
+func (db *Database) collectGarbage() {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-db.closed:
+			return
+		}
+
+		db.lock.RLock()
+		if db.db != nil {
+			// Each call rewrites at most one file
+			for db.db.RunValueLogGC(gcDiscardRatio) == nil {
+			}
+		}
+		db.lock.RUnlock()
+	}
+}
+
+// Has returns if the key is set in the database
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return false, database.ErrClosed
+	}
+	err := db.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	switch err {
+	case nil:
+		return true, nil
+	case badger.ErrKeyNotFound:
+		return false, nil
+	default:
+		return false, updateError(err)
+	}
+}
+
+// Get returns the value the key maps to in the database
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, database.ErrClosed
+	}
+	var value []byte
+	err := db.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	return value, updateError(err)
+}
+
+// Put sets the value of the provided key to the provided value
+func (db *Database) Put(key []byte, value []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return updateError(db.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(copyBytes(key), copyBytes(value))
+	}))
+}
+
+// Delete removes the key from the database
+func (db *Database) Delete(key []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return updateError(db.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(copyBytes(key))
+	}))
+}
+
+// DeleteRange removes the keys in [start, end) from the database. BadgerDB
+// can't delete a range natively, so the keys are deleted in batches.
+func (db *Database) DeleteRange(start, end []byte) error {
+	return database.BatchDeleteRange(db, start, end)
+}
+
+// NewBatch creates a write/delete-only buffer that is atomically committed to
+// the database when write is called
+func (db *Database) NewBatch() database.Batch { return &batch{db: db} }
+
+// NewIterator creates a lexicographically ordered iterator over the database
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart creates a lexicographically ordered iterator over the
+// database starting at the provided key
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix creates a lexicographically ordered iterator over the
+// database ignoring keys that do not start with the provided prefix
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix creates a lexicographically ordered iterator
+// over the database starting at start and ignoring keys that do not start with
+// the provided prefix
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return &nodb.Iterator{Err: database.ErrClosed}
+	}
+
+	options := badger.DefaultIteratorOptions
+	options.Prefix = copyBytes(prefix)
+
+	// The iterator reads from the transaction's snapshot of the database
+	txn := db.db.NewTransaction(false)
+	it := txn.NewIterator(options)
+	if bytes.Compare(start, prefix) == 1 {
+		it.Seek(start)
+	} else {
+		it.Seek(options.Prefix)
+	}
+	return &iterator{
+		txn:    txn,
+		it:     it,
+		prefix: options.Prefix,
+	}
+}
+
+// Stat returns a particular internal stat of the database. BadgerDB doesn't
+// have any stats.
+func (db *Database) Stat(property string) (string, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return "", database.ErrClosed
+	}
+	return "", database.ErrNotFound
+}
+
+// Compact the underlying DB. BadgerDB can't compact a key range, so the whole
+// database is compacted, and space is reclaimed from the value log.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	if err := db.db.Flatten(1); err != nil {
+		return updateError(err)
+	}
+	for {
+		switch err := db.db.RunValueLogGC(gcDiscardRatio); err {
+		case nil:
+		case badger.ErrNoRewrite:
+			return nil
+		default:
+			return updateError(err)
+		}
+	}
+}
+
+// Close implements the Database interface
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	close(db.closed)
+	err := db.db.Close()
+	db.db = nil
+	return updateError(err)
+}
+
+type keyValue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type batch struct {
+	db     *Database
+	writes []keyValue
+	size   int
+}
+
+// Put the value into the batch for later writing
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), copyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+// Delete the key during writing
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), nil, true})
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int { return b.size }
+
+// Write flushes any accumulated data to disk in one transaction. Fails if the
+// batch is larger than BadgerDB allows a transaction to be.
+func (b *batch) Write() error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	if b.db.db == nil {
+		return database.ErrClosed
+	}
+	return updateError(b.db.db.Update(func(txn *badger.Txn) error {
+		for _, kv := range b.writes {
+			if kv.delete {
+				if err := txn.Delete(kv.key); err != nil {
+					return err
+				}
+			} else if err := txn.Set(kv.key, kv.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay the batch contents.
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	for _, kv := range b.writes {
+		if kv.delete {
+			if err := w.Delete(kv.key); err != nil {
+				return err
+			}
+		} else if err := w.Put(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type iterator struct {
+	txn    *badger.Txn
+	it     *badger.Iterator
+	prefix []byte
+
+	initialized bool
+	key, value  []byte
+	err         error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	if it.it == nil || it.err != nil {
+		return false
+	}
+	if it.initialized {
+		it.it.Next()
+	}
+	it.initialized = true
+
+	if !it.it.ValidForPrefix(it.prefix) {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+	item := it.it.Item()
+	it.key = item.KeyCopy(nil)
+	it.value, it.err = item.ValueCopy(nil)
+	if it.err != nil {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+	return true
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error { return updateError(it.err) }
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte { return it.key }
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte { return it.value }
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	if it.it == nil {
+		return
+	}
+	it.it.Close()
+	it.txn.Discard()
+	it.it = nil
+	it.key = nil
+	it.value = nil
+}
+
+func updateError(err error) error {
+	switch err {
+	case badger.ErrKeyNotFound:
+		return database.ErrNotFound
+	default:
+		return err
+	}
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package badgerdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/dbtest"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		folder, err := ioutil.TempDir("", "badgerdb")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(folder)
+
+		db, err := New(folder)
+		if err != nil {
+			t.Fatalf("badgerdb.New(%s) errored with %s", folder, err)
+		}
+		defer db.Close()
+
+		test(t, db)
+	}
+}
@@ -31,3 +31,25 @@ type Batcher interface {
 	// until a final write is called.
 	NewBatch() Batch
 }
+
+// BatchWrapper is implemented by the batches of databases that store their
+// values, unchanged, in another database. Writing the inner batch writes the
+// same changes as writing this batch.
+type BatchWrapper interface {
+	// Inner returns the batch of the wrapped database that holds the changes
+	// of this batch
+	Inner() Batch
+}
+
+// InnerBatch returns the innermost batch that [batch] wraps. Batches of
+// databases built on the same database can be written atomically by replaying
+// their inner batches into one batch of that database.
+func InnerBatch(batch Batch) Batch {
+	for {
+		wrapper, ok := batch.(BatchWrapper)
+		if !ok {
+			return batch
+		}
+		batch = wrapper.Inner()
+	}
+}
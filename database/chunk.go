@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// ChunkSize is the size, in bytes, at which WriteChunks closes a chunk. A
+// chunk always holds at least one key/value pair, so a chunk holding a single
+// large pair may be larger.
+const ChunkSize = 1 << 21
+
+// WriteChunks splits the key/value pairs of [db] into chunks, in key order,
+// passes each chunk to [write], and returns how many pairs were written. A
+// pair that makes a chunk larger than [maxChunkSize] bytes is an error.
+func WriteChunks(db Iteratee, maxChunkSize int, write func(chunk []byte) error) (int, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	pairs := 0
+	p := wrappers.Packer{MaxSize: maxChunkSize}
+	for it.Next() {
+		p.PackBytes(it.Key())
+		p.PackBytes(it.Value())
+		if p.Errored() {
+			return pairs, p.Err
+		}
+		pairs++
+		if p.Offset >= ChunkSize {
+			if err := write(p.Bytes); err != nil {
+				return pairs, err
+			}
+			p = wrappers.Packer{MaxSize: maxChunkSize}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return pairs, err
+	}
+	if p.Offset > 0 {
+		if err := write(p.Bytes); err != nil {
+			return pairs, err
+		}
+	}
+	return pairs, nil
+}
+
+// RestoreChunk writes the key/value pairs of [chunk], which was made by
+// WriteChunks, to [db] in one batch, and returns how many were written
+func RestoreChunk(db Batcher, chunk []byte) (int, error) {
+	batch := db.NewBatch()
+	pairs := 0
+	p := wrappers.Packer{Bytes: chunk}
+	for p.Offset < len(chunk) && !p.Errored() {
+		key := p.UnpackBytes()
+		value := p.UnpackBytes()
+		if p.Errored() {
+			break
+		}
+		if err := batch.Put(key, value); err != nil {
+			return 0, err
+		}
+		pairs++
+	}
+	if p.Errored() {
+		return 0, p.Err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return pairs, nil
+}
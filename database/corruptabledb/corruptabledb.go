@@ -0,0 +1,184 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package corruptabledb
+
+import (
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+// Database fails every operation with database.ErrCorrupted once an operation
+// of the underlying database fails unexpectedly. Errors other than
+// database.ErrNotFound and database.ErrClosed are unexpected. Because the
+// failed operation may have been partially applied, retrying it could leave
+// the database inconsistent.
+type Database struct {
+	db database.Database
+
+	lock sync.RWMutex
+	// The unexpected error that corrupted the database, if any
+	err error
+}
+
+// New returns a database that latches the unexpected errors of [db]
+func New(db database.Database) *Database { return &Database{db: db} }
+
+// Corrupted returns true if an operation failed unexpectedly
+func (db *Database) Corrupted() bool { return db.Err() != nil }
+
+// Err returns the unexpected error that corrupted the database, or nil if the
+// database isn't corrupted
+func (db *Database) Err() error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.err
+}
+
+// check returns database.ErrCorrupted if the database is corrupted
+func (db *Database) check() error {
+	if db.Corrupted() {
+		return database.ErrCorrupted
+	}
+	return nil
+}
+
+// handle [err], returned by the underlying database, and return it
+func (db *Database) handle(err error) error {
+	switch err {
+	case nil, database.ErrNotFound, database.ErrClosed:
+		return err
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.err == nil {
+		db.err = err
+	}
+	return err
+}
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) {
+	if err := db.check(); err != nil {
+		return false, err
+	}
+	has, err := db.db.Has(key)
+	return has, db.handle(err)
+}
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) {
+	if err := db.check(); err != nil {
+		return nil, err
+	}
+	value, err := db.db.Get(key)
+	return value, db.handle(err)
+}
+
+// Put implements the Database interface
+func (db *Database) Put(key, value []byte) error {
+	if err := db.check(); err != nil {
+		return err
+	}
+	return db.handle(db.db.Put(key, value))
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	if err := db.check(); err != nil {
+		return err
+	}
+	return db.handle(db.db.Delete(key))
+}
+
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	if err := db.check(); err != nil {
+		return err
+	}
+	return db.handle(db.db.DeleteRange(start, end))
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return &iterator{
+		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		db:       db,
+	}
+}
+
+// Stat implements the Database interface
+func (db *Database) Stat(stat string) (string, error) {
+	if err := db.check(); err != nil {
+		return "", err
+	}
+	value, err := db.db.Stat(stat)
+	return value, db.handle(err)
+}
+
+// Compact implements the Database interface
+func (db *Database) Compact(start, limit []byte) error {
+	if err := db.check(); err != nil {
+		return err
+	}
+	return db.handle(db.db.Compact(start, limit))
+}
+
+// Close implements the Database interface. A corrupted database can be closed.
+func (db *Database) Close() error { return db.db.Close() }
+
+type batch struct {
+	database.Batch
+	db *Database
+}
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	if err := b.db.check(); err != nil {
+		return err
+	}
+	return b.db.handle(b.Batch.Write())
+}
+
+type iterator struct {
+	database.Iterator
+	db *Database
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool { return !it.db.Corrupted() && it.Iterator.Next() }
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error {
+	if err := it.db.handle(it.Iterator.Error()); err != nil {
+		return err
+	}
+	return it.db.check()
+}
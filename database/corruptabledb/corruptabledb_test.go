@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package corruptabledb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		test(t, New(memdb.New()))
+	}
+}
+
+// failingDB fails its writes with [err]
+type failingDB struct {
+	database.Database
+	err error
+}
+
+func (db *failingDB) Put([]byte, []byte) error { return db.err }
+
+func TestCorruption(t *testing.T) {
+	baseDB := &failingDB{Database: memdb.New()}
+	db := New(baseDB)
+
+	// Expected errors don't corrupt the database
+	if _, err := db.Get([]byte("key")); err != database.ErrNotFound {
+		t.Fatalf("Get should have failed with %s but got %v", database.ErrNotFound, err)
+	}
+	if db.Corrupted() {
+		t.Fatalf("Database shouldn't have been corrupted")
+	}
+
+	errIO := errors.New("i/o error")
+	baseDB.err = errIO
+	if err := db.Put([]byte("key"), []byte("value")); err != errIO {
+		t.Fatalf("Put should have failed with %s but got %v", errIO, err)
+	}
+	if !db.Corrupted() {
+		t.Fatalf("Database should have been corrupted")
+	}
+	if err := db.Err(); err != errIO {
+		t.Fatalf("Err Returned: %v ; Expected: %s", err, errIO)
+	}
+
+	// The database keeps failing after the underlying database recovers
+	baseDB.err = nil
+	if err := db.Put([]byte("key"), []byte("value")); err != database.ErrCorrupted {
+		t.Fatalf("Put should have failed with %s but got %v", database.ErrCorrupted, err)
+	}
+	if _, err := db.Has([]byte("key")); err != database.ErrCorrupted {
+		t.Fatalf("Has should have failed with %s but got %v", database.ErrCorrupted, err)
+	}
+	if err := db.NewBatch().Write(); err != database.ErrCorrupted {
+		t.Fatalf("Batch write should have failed with %s but got %v", database.ErrCorrupted, err)
+	}
+	iterator := db.NewIterator()
+	defer iterator.Release()
+	if iterator.Next() {
+		t.Fatalf("Iterator of a corrupted database shouldn't have iterated")
+	}
+	if err := iterator.Error(); err != database.ErrCorrupted {
+		t.Fatalf("Iterator should have failed with %s but got %v", database.ErrCorrupted, err)
+	}
+}
@@ -29,6 +29,17 @@ type KeyValueWriter interface {
 	Delete(key []byte) error
 }
 
+// RangeDeleter wraps the DeleteRange method of a backing data store.
+type RangeDeleter interface {
+	// DeleteRange removes the keys in [start, end) from the key-value data
+	// store.
+	//
+	// A nil start is treated as a key before all keys in the DB.
+	// And a nil end is treated as a key after all keys in the DB.
+	// Therefore if both are nil then it will remove every key in the DB.
+	DeleteRange(start []byte, end []byte) error
+}
+
 // Stater wraps the Stat method of a backing data store.
 type Stater interface {
 	// Stat returns a particular internal stat of the database.
@@ -49,11 +60,21 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// DiskSizer is a database that can estimate the space its keys use on disk
+type DiskSizer interface {
+	// DiskSize returns the approximate number of bytes the keys in the range
+	// [start, limit), and their values, use on disk. A nil start is treated as
+	// a key before all keys in the DB, and a nil limit is treated as a key
+	// after all keys in the DB.
+	DiskSize(start []byte, limit []byte) (uint64, error)
+}
+
 // Database contains all the methods required to allow handling different
 // key-value data stores backing the database.
 type Database interface {
 	KeyValueReader
 	KeyValueWriter
+	RangeDeleter
 	Batcher
 	Iteratee
 	Stater
@@ -0,0 +1,161 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dbtest
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+// Sizes, in bytes, of the keys and values written by the benchmarks
+const (
+	benchmarkKeySize   = 32
+	benchmarkValueSize = 256
+)
+
+var (
+	// Benchmarks is a list of all database benchmarks. Each benchmark must be
+	// given a new database.
+	Benchmarks = []func(b *testing.B, db database.Database){
+		BenchmarkPut,
+		BenchmarkGet,
+		BenchmarkHas,
+		BenchmarkDelete,
+		BenchmarkBatchPut,
+		BenchmarkIterator,
+	}
+)
+
+// BenchmarkDatabase runs every benchmark in Benchmarks against a database
+// returned by [newDB], which must return a new empty database each time it's
+// called. Each database is closed after its benchmark.
+func BenchmarkDatabase(b *testing.B, newDB func() database.Database) {
+	for _, bench := range Benchmarks {
+		bench := bench
+		b.Run(funcName(bench), func(b *testing.B) {
+			db := newDB()
+			defer db.Close()
+
+			bench(b, db)
+		})
+	}
+}
+
+// BenchmarkPut ...
+func BenchmarkPut(b *testing.B, db database.Database) {
+	keys, values := benchmarkPairs(b, b.N)
+
+	b.SetBytes(benchmarkKeySize + benchmarkValueSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.Put(keys[i], values[i]); err != nil {
+			b.Fatalf("Unexpected error on db.Put: %s", err)
+		}
+	}
+}
+
+// BenchmarkGet ...
+func BenchmarkGet(b *testing.B, db database.Database) {
+	keys := benchmarkFill(b, db, b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.Get(keys[i]); err != nil {
+			b.Fatalf("Unexpected error on db.Get: %s", err)
+		}
+	}
+}
+
+// BenchmarkHas ...
+func BenchmarkHas(b *testing.B, db database.Database) {
+	keys := benchmarkFill(b, db, b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.Has(keys[i]); err != nil {
+			b.Fatalf("Unexpected error on db.Has: %s", err)
+		}
+	}
+}
+
+// BenchmarkDelete ...
+func BenchmarkDelete(b *testing.B, db database.Database) {
+	keys := benchmarkFill(b, db, b.N)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.Delete(keys[i]); err != nil {
+			b.Fatalf("Unexpected error on db.Delete: %s", err)
+		}
+	}
+}
+
+// BenchmarkBatchPut ...
+func BenchmarkBatchPut(b *testing.B, db database.Database) {
+	keys, values := benchmarkPairs(b, b.N)
+
+	b.SetBytes(benchmarkKeySize + benchmarkValueSize)
+	b.ResetTimer()
+	batch := db.NewBatch()
+	for i := 0; i < b.N; i++ {
+		if err := batch.Put(keys[i], values[i]); err != nil {
+			b.Fatalf("Unexpected error on batch.Put: %s", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		b.Fatalf("Unexpected error on batch.Write: %s", err)
+	}
+}
+
+// BenchmarkIterator ...
+func BenchmarkIterator(b *testing.B, db database.Database) {
+	benchmarkFill(b, db, b.N)
+
+	b.ResetTimer()
+	iterator := db.NewIterator()
+	defer iterator.Release()
+
+	for iterator.Next() {
+		_ = iterator.Key()
+		_ = iterator.Value()
+	}
+	if err := iterator.Error(); err != nil {
+		b.Fatalf("Unexpected error on iterator.Error: %s", err)
+	}
+}
+
+// benchmarkFill puts [n] random pairs in [db] and returns their keys
+func benchmarkFill(b *testing.B, db database.Database, n int) [][]byte {
+	keys, values := benchmarkPairs(b, n)
+
+	batch := db.NewBatch()
+	for i := range keys {
+		if err := batch.Put(keys[i], values[i]); err != nil {
+			b.Fatalf("Unexpected error on batch.Put: %s", err)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		b.Fatalf("Unexpected error on batch.Write: %s", err)
+	}
+	return keys
+}
+
+// benchmarkPairs returns [n] random keys and values
+func benchmarkPairs(b *testing.B, n int) ([][]byte, [][]byte) {
+	keys := make([][]byte, n)
+	values := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		keys[i] = make([]byte, benchmarkKeySize)
+		values[i] = make([]byte, benchmarkValueSize)
+		if _, err := rand.Read(keys[i]); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := rand.Read(values[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return keys, values
+}
@@ -1,18 +1,25 @@
 // (c) 2019-2020, Ava Labs, Inc. All rights reserved.
 // See the file LICENSE for licensing terms.
 
-package database
+package dbtest
 
 import (
 	"bytes"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/ava-labs/gecko/database"
 )
 
 var (
-	// Tests is a list of all database tests
-	Tests = []func(t *testing.T, db Database){
+	// Tests is a list of all database tests. Each test must be given a new
+	// database.
+	Tests = []func(t *testing.T, db database.Database){
 		TestSimpleKeyValue,
 		TestSimpleKeyValueClosed,
+		TestMemorySafety,
 		TestBatchPut,
 		TestBatchDelete,
 		TestBatchReset,
@@ -22,13 +29,15 @@ var (
 		TestIteratorPrefix,
 		TestIteratorStartPrefix,
 		TestIteratorClosed,
+		TestDeleteRange,
+		TestClear,
 		TestStatNoPanic,
 		TestCompactNoPanic,
 	}
 )
 
 // TestSimpleKeyValue ...
-func TestSimpleKeyValue(t *testing.T, db Database) {
+func TestSimpleKeyValue(t *testing.T, db database.Database) {
 	key := []byte("hello")
 	value := []byte("world")
 
@@ -36,8 +45,8 @@ func TestSimpleKeyValue(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Has: %s", err)
 	} else if has {
 		t.Fatalf("db.Has unexpectedly returned true on key %s", key)
-	} else if v, err := db.Get(key); err != ErrNotFound {
-		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", ErrNotFound, key, v)
+	} else if v, err := db.Get(key); err != database.ErrNotFound {
+		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", database.ErrNotFound, key, v)
 	} else if err := db.Delete(key); err != nil {
 		t.Fatalf("Unexpected error on db.Delete: %s", err)
 	}
@@ -64,15 +73,15 @@ func TestSimpleKeyValue(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Has: %s", err)
 	} else if has {
 		t.Fatalf("db.Has unexpectedly returned true on key %s", key)
-	} else if v, err := db.Get(key); err != ErrNotFound {
-		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", ErrNotFound, key, v)
+	} else if v, err := db.Get(key); err != database.ErrNotFound {
+		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", database.ErrNotFound, key, v)
 	} else if err := db.Delete(key); err != nil {
 		t.Fatalf("Unexpected error on db.Delete: %s", err)
 	}
 }
 
 // TestSimpleKeyValueClosed ...
-func TestSimpleKeyValueClosed(t *testing.T, db Database) {
+func TestSimpleKeyValueClosed(t *testing.T, db database.Database) {
 	key := []byte("hello")
 	value := []byte("world")
 
@@ -80,8 +89,8 @@ func TestSimpleKeyValueClosed(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Has: %s", err)
 	} else if has {
 		t.Fatalf("db.Has unexpectedly returned true on key %s", key)
-	} else if v, err := db.Get(key); err != ErrNotFound {
-		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", ErrNotFound, key, v)
+	} else if v, err := db.Get(key); err != database.ErrNotFound {
+		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", database.ErrNotFound, key, v)
 	} else if err := db.Delete(key); err != nil {
 		t.Fatalf("Unexpected error on db.Delete: %s", err)
 	}
@@ -104,21 +113,52 @@ func TestSimpleKeyValueClosed(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Close: %s", err)
 	}
 
-	if _, err := db.Has(key); err != ErrClosed {
-		t.Fatalf("Expected %s on db.Has after close", ErrClosed)
-	} else if _, err := db.Get(key); err != ErrClosed {
-		t.Fatalf("Expected %s on db.Get after close", ErrClosed)
-	} else if err := db.Put(key, value); err != ErrClosed {
-		t.Fatalf("Expected %s on db.Put after close", ErrClosed)
-	} else if err := db.Delete(key); err != ErrClosed {
-		t.Fatalf("Expected %s on db.Delete after close", ErrClosed)
-	} else if err := db.Close(); err != ErrClosed {
-		t.Fatalf("Expected %s on db.Close after close", ErrClosed)
+	if _, err := db.Has(key); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Has after close", database.ErrClosed)
+	} else if _, err := db.Get(key); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Get after close", database.ErrClosed)
+	} else if err := db.Put(key, value); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Put after close", database.ErrClosed)
+	} else if err := db.Delete(key); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Delete after close", database.ErrClosed)
+	} else if err := db.Close(); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Close after close", database.ErrClosed)
+	}
+}
+
+// TestMemorySafety ...
+func TestMemorySafety(t *testing.T, db database.Database) {
+	key := []byte("hello")
+	value := []byte("world")
+	putKey := []byte("hello")
+	putValue := []byte("world")
+
+	if err := db.Put(putKey, putValue); err != nil {
+		t.Fatalf("Unexpected error on db.Put: %s", err)
+	}
+
+	// Modifying the slices that were put shouldn't modify the database
+	putKey[0] = 'j'
+	putValue[0] = 'x'
+
+	v, err := db.Get(key)
+	if err != nil {
+		t.Fatalf("Unexpected error on db.Get: %s", err)
+	} else if !bytes.Equal(value, v) {
+		t.Fatalf("db.Get: Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+
+	// Modifying the slice that was returned shouldn't modify the database
+	v[0] = 'x'
+	if v, err := db.Get(key); err != nil {
+		t.Fatalf("Unexpected error on db.Get: %s", err)
+	} else if !bytes.Equal(value, v) {
+		t.Fatalf("db.Get: Returned: 0x%x ; Expected: 0x%x", v, value)
 	}
 }
 
 // TestBatchPut ...
-func TestBatchPut(t *testing.T, db Database) {
+func TestBatchPut(t *testing.T, db database.Database) {
 	key := []byte("hello")
 	value := []byte("world")
 
@@ -162,13 +202,13 @@ func TestBatchPut(t *testing.T, db Database) {
 
 	db.Close()
 
-	if err := batch.Write(); err != ErrClosed {
-		t.Fatalf("Expected %s on batch.Write", ErrClosed)
+	if err := batch.Write(); err != database.ErrClosed {
+		t.Fatalf("Expected %s on batch.Write", database.ErrClosed)
 	}
 }
 
 // TestBatchDelete ...
-func TestBatchDelete(t *testing.T, db Database) {
+func TestBatchDelete(t *testing.T, db database.Database) {
 	key := []byte("hello")
 	value := []byte("world")
 
@@ -193,15 +233,15 @@ func TestBatchDelete(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Has: %s", err)
 	} else if has {
 		t.Fatalf("db.Has unexpectedly returned true on key %s", key)
-	} else if v, err := db.Get(key); err != ErrNotFound {
-		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", ErrNotFound, key, v)
+	} else if v, err := db.Get(key); err != database.ErrNotFound {
+		t.Fatalf("Expected %s on db.Get for missing key %s. Returned 0x%x", database.ErrNotFound, key, v)
 	} else if err := db.Delete(key); err != nil {
 		t.Fatalf("Unexpected error on db.Delete: %s", err)
 	}
 }
 
 // TestBatchReset ...
-func TestBatchReset(t *testing.T, db Database) {
+func TestBatchReset(t *testing.T, db database.Database) {
 	key := []byte("hello")
 	value := []byte("world")
 
@@ -236,7 +276,7 @@ func TestBatchReset(t *testing.T, db Database) {
 }
 
 // TestBatchReplay ...
-func TestBatchReplay(t *testing.T, db Database) {
+func TestBatchReplay(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -292,15 +332,15 @@ func TestBatchReplay(t *testing.T, db Database) {
 		t.Fatalf("Unexpected error on db.Close: %s", err)
 	}
 
-	if err := batch.Replay(db); err != ErrClosed {
-		t.Fatalf("Expected %s on batch.Replay", ErrClosed)
-	} else if err := thirdBatch.Replay(db); err != ErrClosed {
-		t.Fatalf("Expected %s on batch.Replay", ErrClosed)
+	if err := batch.Replay(db); err != database.ErrClosed {
+		t.Fatalf("Expected %s on batch.Replay", database.ErrClosed)
+	} else if err := thirdBatch.Replay(db); err != database.ErrClosed {
+		t.Fatalf("Expected %s on batch.Replay", database.ErrClosed)
 	}
 }
 
 // TestIterator ...
-func TestIterator(t *testing.T, db Database) {
+func TestIterator(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -343,7 +383,7 @@ func TestIterator(t *testing.T, db Database) {
 }
 
 // TestIteratorStart ...
-func TestIteratorStart(t *testing.T, db Database) {
+func TestIteratorStart(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -380,7 +420,7 @@ func TestIteratorStart(t *testing.T, db Database) {
 }
 
 // TestIteratorPrefix ...
-func TestIteratorPrefix(t *testing.T, db Database) {
+func TestIteratorPrefix(t *testing.T, db database.Database) {
 	key1 := []byte("hello")
 	value1 := []byte("world1")
 
@@ -417,7 +457,7 @@ func TestIteratorPrefix(t *testing.T, db Database) {
 }
 
 // TestIteratorStartPrefix ...
-func TestIteratorStartPrefix(t *testing.T, db Database) {
+func TestIteratorStartPrefix(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -465,7 +505,7 @@ func TestIteratorStartPrefix(t *testing.T, db Database) {
 }
 
 // TestIteratorClosed ...
-func TestIteratorClosed(t *testing.T, db Database) {
+func TestIteratorClosed(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -489,13 +529,94 @@ func TestIteratorClosed(t *testing.T, db Database) {
 		t.Fatalf("iterator.Key Returned: 0x%x ; Expected: nil", key)
 	} else if value := iterator.Value(); value != nil {
 		t.Fatalf("iterator.Value Returned: 0x%x ; Expected: nil", value)
-	} else if err := iterator.Error(); err != ErrClosed {
-		t.Fatalf("Expected %s on iterator.Error", ErrClosed)
+	} else if err := iterator.Error(); err != database.ErrClosed {
+		t.Fatalf("Expected %s on iterator.Error", database.ErrClosed)
+	}
+}
+
+// TestDeleteRange ...
+func TestDeleteRange(t *testing.T, db database.Database) {
+	keys := [][]byte{
+		[]byte("a"),
+		[]byte("b"),
+		[]byte("b1"),
+		[]byte("c"),
+		[]byte("d"),
+	}
+	for _, key := range keys {
+		if err := db.Put(key, key); err != nil {
+			t.Fatalf("Unexpected error on db.Put: %s", err)
+		}
+	}
+
+	if err := db.DeleteRange([]byte("b"), []byte("c")); err != nil {
+		t.Fatalf("Unexpected error on db.DeleteRange: %s", err)
+	}
+	assertKeys(t, db, [][]byte{[]byte("a"), []byte("c"), []byte("d")})
+
+	if err := db.DeleteRange(nil, []byte("a")); err != nil {
+		t.Fatalf("Unexpected error on db.DeleteRange: %s", err)
+	}
+	assertKeys(t, db, [][]byte{[]byte("a"), []byte("c"), []byte("d")})
+
+	if err := db.DeleteRange([]byte("c"), nil); err != nil {
+		t.Fatalf("Unexpected error on db.DeleteRange: %s", err)
+	}
+	assertKeys(t, db, [][]byte{[]byte("a")})
+
+	if err := db.DeleteRange(nil, []byte("b")); err != nil {
+		t.Fatalf("Unexpected error on db.DeleteRange: %s", err)
+	}
+	assertKeys(t, db, nil)
+}
+
+// TestClear ...
+func TestClear(t *testing.T, db database.Database) {
+	// More keys than database.BatchDeleteRange deletes in one batch
+	for i := 0; i < 2049; i++ {
+		key := []byte{byte(i >> 8), byte(i)}
+		if err := db.Put(key, key); err != nil {
+			t.Fatalf("Unexpected error on db.Put: %s", err)
+		}
+	}
+
+	if err := database.Clear(db); err != nil {
+		t.Fatalf("Unexpected error on Clear: %s", err)
+	}
+	assertKeys(t, db, nil)
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Unexpected error on db.Close: %s", err)
+	}
+	if err := database.Clear(db); err != database.ErrClosed {
+		t.Fatalf("Clear Returned: %v ; Expected: %s", err, database.ErrClosed)
+	}
+}
+
+// assertKeys fails the test if the keys of [db] aren't [expected]
+func assertKeys(t *testing.T, db database.Database, expected [][]byte) {
+	iterator := db.NewIterator()
+	defer iterator.Release()
+
+	keys := [][]byte(nil)
+	for iterator.Next() {
+		keys = append(keys, append([]byte(nil), iterator.Key()...))
+	}
+	if err := iterator.Error(); err != nil {
+		t.Fatalf("iterator.Error Returned: %s ; Expected: nil", err)
+	}
+	if len(keys) != len(expected) {
+		t.Fatalf("Iterated over %d keys ; Expected: %d", len(keys), len(expected))
+	}
+	for i, key := range keys {
+		if !bytes.Equal(key, expected[i]) {
+			t.Fatalf("Key %d Returned: 0x%x ; Expected: 0x%x", i, key, expected[i])
+		}
 	}
 }
 
 // TestStatNoPanic ...
-func TestStatNoPanic(t *testing.T, db Database) {
+func TestStatNoPanic(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -523,7 +644,7 @@ func TestStatNoPanic(t *testing.T, db Database) {
 }
 
 // TestCompactNoPanic ...
-func TestCompactNoPanic(t *testing.T, db Database) {
+func TestCompactNoPanic(t *testing.T, db database.Database) {
 	key1 := []byte("hello1")
 	value1 := []byte("world1")
 
@@ -549,3 +670,18 @@ func TestCompactNoPanic(t *testing.T, db Database) {
 
 	db.Compact(nil, nil)
 }
+
+// TestDatabase runs every test in Tests against a database returned by
+// [newDB], which must return a new empty database each time it's called
+func TestDatabase(t *testing.T, newDB func() database.Database) {
+	for _, test := range Tests {
+		test := test
+		t.Run(funcName(test), func(t *testing.T) { test(t, newDB()) })
+	}
+}
+
+// funcName returns the name of the function [f], without its package
+func funcName(f interface{}) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
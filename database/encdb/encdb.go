@@ -4,38 +4,334 @@
 package encdb
 
 import (
+	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"errors"
+	"math"
 	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/chacha20poly1305"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/nodb"
 	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
 	"github.com/ava-labs/gecko/vms/components/codec"
 )
 
+const (
+	// keySize is the size, in bytes, of the keys derived from passwords
+	keySize = 32
+	// saltSize is the size, in bytes, of the random Argon2id salts
+	saltSize = 16
+	// headerVersion is the first byte of values that are stored with a
+	// header. Values stored before headers were added start with a zero byte.
+	headerVersion byte = 1
+
+	// MaxArgon2Time, MaxArgon2Memory and MaxArgon2Threads bound the Argon2id
+	// parameters keys are derived with. The parameters of a value are read
+	// from its header, which may come from an untrusted caller, so they're
+	// checked before any memory is allocated for the derivation.
+	MaxArgon2Time    uint32 = 10
+	MaxArgon2Memory  uint32 = 256 * 1024 // KiB
+	MaxArgon2Threads uint8  = 16
+)
+
+var (
+	errUnknownCipher        = errors.New("unknown cipher")
+	errUnknownKDF           = errors.New("unknown key derivation function")
+	errInvalidArgon2Params  = errors.New("argon2id time, memory and threads must be positive")
+	errArgon2ParamsTooLarge = errors.New("argon2id time, memory or threads exceeds its maximum")
+	errUnknownVersion       = errors.New("unknown encrypted value version")
+)
+
+// Cipher is an AEAD that values can be encrypted with
+type Cipher byte
+
+// Ciphers that values can be encrypted with
+const (
+	// ChaCha20Poly1305 is XChaCha20-Poly1305. It's faster than AES-GCM on
+	// CPUs without AES instructions, such as many ARM CPUs.
+	ChaCha20Poly1305 Cipher = iota
+	// AESGCM is AES-256-GCM
+	AESGCM
+)
+
+// KDF is a function that derives encryption keys from passwords
+type KDF byte
+
+// Functions that keys can be derived with
+const (
+	// SHA256 derives keys with a single hash. It's cheap to brute force.
+	SHA256 KDF = iota
+	// Argon2id derives keys with the memory-hard Argon2id function
+	Argon2id
+)
+
+// Config is the encryption scheme of a database
+type Config struct {
+	Cipher Cipher
+	KDF    KDF
+
+	// Argon2id parameters, only used if KDF is Argon2id. Memory is in KiB.
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+	// Argon2Salt is the salt of the Argon2id KDF. If it's empty, a random salt
+	// is used. Each value is stored with its salt, and a key is derived for
+	// each salt read, so databases that are reopened should reuse their salt.
+	Argon2Salt []byte
+}
+
+// DefaultConfig encrypts values with XChaCha20-Poly1305 and derives keys with
+// the parameters RFC 9106 recommends for Argon2id when memory is constrained
+var DefaultConfig = Config{
+	Cipher:        ChaCha20Poly1305,
+	KDF:           Argon2id,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 4,
+}
+
+// legacyScheme is the scheme of values stored before headers were added
+var legacyScheme = scheme{cipher: ChaCha20Poly1305, kdf: SHA256}
+
+// scheme is how a value is encrypted. The salt is a string so schemes can be
+// map keys.
+type scheme struct {
+	cipher        Cipher
+	kdf           KDF
+	argon2Time    uint32
+	argon2Memory  uint32
+	argon2Threads uint8
+	argon2Salt    string
+}
+
 // Database encrypts all values that are provided
 type Database struct {
-	lock   sync.RWMutex
-	codec  codec.Codec
-	cipher cipher.AEAD
+	lock     sync.RWMutex
+	codec    codec.Codec
+	password []byte
+	config   Config
+	// The scheme new values are encrypted with
+	scheme scheme
 	db     database.Database
+
+	// Ciphers of the schemes that have been used, by scheme. Values may have
+	// been encrypted with schemes other than [scheme].
+	cipherLock sync.Mutex
+	ciphers    map[scheme]cipher.AEAD
 }
 
-// New returns a new encrypted database
+// New returns a new encrypted database. Values are encrypted with
+// XChaCha20-Poly1305 and a key hashed from [password].
 func New(password []byte, db database.Database) (*Database, error) {
-	h := hashing.ComputeHash256(password)
-	aead, err := chacha20poly1305.NewX(h)
+	return NewWithConfig(password, db, Config{Cipher: ChaCha20Poly1305, KDF: SHA256})
+}
+
+// NewWithConfig returns a new encrypted database that encrypts values with
+// the scheme in [config]. Values encrypted with other schemes, or stored
+// before schemes were configurable, can still be read.
+func NewWithConfig(password []byte, db database.Database, config Config) (*Database, error) {
+	s, err := newScheme(config)
+	if err != nil {
+		return nil, err
+	}
+	config.Argon2Salt = copyBytes(config.Argon2Salt)
+	encDB := &Database{
+		codec:    codec.NewDefault(),
+		password: copyBytes(password),
+		config:   config,
+		scheme:   s,
+		db:       db,
+		ciphers:  make(map[scheme]cipher.AEAD),
+	}
+	// Derive the key now, so an expensive KDF doesn't slow down the first
+	// write
+	if _, err := encDB.cipher(s); err != nil {
+		return nil, err
+	}
+	return encDB, nil
+}
+
+// EncryptValue returns [value] encrypted with the scheme in [config] and a key
+// derived from [password], in the format a database stores values in
+func EncryptValue(password, value []byte, config Config) ([]byte, error) {
+	db, err := NewWithConfig(password, nil, config)
+	if err != nil {
+		return nil, err
+	}
+	return db.encrypt(value)
+}
+
+// DecryptValue returns the plaintext of [encValue], which was encrypted with a
+// key derived from [password]
+func DecryptValue(password, encValue []byte) ([]byte, error) {
+	db, err := New(password, nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.decrypt(encValue)
+}
+
+// newScheme returns the scheme of [config], choosing a salt if it needs one
+func newScheme(config Config) (scheme, error) {
+	s := scheme{
+		cipher: config.Cipher,
+		kdf:    config.KDF,
+	}
+	switch config.Cipher {
+	case ChaCha20Poly1305, AESGCM:
+	default:
+		return scheme{}, errUnknownCipher
+	}
+	switch config.KDF {
+	case SHA256:
+		return s, nil
+	case Argon2id:
+	default:
+		return scheme{}, errUnknownKDF
+	}
+
+	s.argon2Time = config.Argon2Time
+	s.argon2Memory = config.Argon2Memory
+	s.argon2Threads = config.Argon2Threads
+	if err := s.verifyArgon2(); err != nil {
+		return scheme{}, err
+	}
+	salt := config.Argon2Salt
+	if len(salt) == 0 {
+		salt = make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return scheme{}, err
+		}
+	}
+	s.argon2Salt = string(salt)
+	return s, nil
+}
+
+// verifyArgon2 returns nil iff argon2 can derive a key with the parameters of
+// [s] without panicking or exhausting memory
+func (s scheme) verifyArgon2() error {
+	switch {
+	case s.argon2Time == 0 || s.argon2Memory == 0 || s.argon2Threads == 0:
+		return errInvalidArgon2Params
+	case s.argon2Time > MaxArgon2Time || s.argon2Memory > MaxArgon2Memory || s.argon2Threads > MaxArgon2Threads:
+		return errArgon2ParamsTooLarge
+	default:
+		return nil
+	}
+}
+
+// cipher returns the cipher of [s], deriving its key from the password if it
+// hasn't been used yet
+func (db *Database) cipher(s scheme) (cipher.AEAD, error) {
+	db.cipherLock.Lock()
+	defer db.cipherLock.Unlock()
+
+	if aead, ok := db.ciphers[s]; ok {
+		return aead, nil
+	}
+
+	var key []byte
+	switch s.kdf {
+	case SHA256:
+		key = hashing.ComputeHash256(db.password)
+	case Argon2id:
+		// The parameters of values are read from their headers, which may
+		// not have been written by this database
+		if err := s.verifyArgon2(); err != nil {
+			return nil, err
+		}
+		key = argon2.IDKey(db.password, []byte(s.argon2Salt), s.argon2Time, s.argon2Memory, s.argon2Threads, keySize)
+	default:
+		return nil, errUnknownKDF
+	}
+
+	var (
+		aead cipher.AEAD
+		err  error
+	)
+	switch s.cipher {
+	case ChaCha20Poly1305:
+		aead, err = chacha20poly1305.NewX(key)
+	case AESGCM:
+		var block cipher.Block
+		if block, err = aes.NewCipher(key); err == nil {
+			aead, err = cipher.NewGCM(block)
+		}
+	default:
+		err = errUnknownCipher
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &Database{
-		codec:  codec.NewDefault(),
-		cipher: aead,
-		db:     db,
-	}, nil
+	db.ciphers[s] = aead
+	return aead, nil
+}
+
+// RotateKey re-encrypts every value with the key derived from [newPassword],
+// which is used from then on. The values are re-encrypted in one batch, so
+// either all of them or none of them are. Once they are, databases that
+// encrypt the same values with the old password can't read them.
+func (db *Database) RotateKey(newPassword []byte) error {
+	db.lock.RLock()
+	config := db.config
+	db.lock.RUnlock()
+
+	// A new salt is chosen unless the old one was configured
+	return db.RotateKeyWithConfig(newPassword, config)
+}
+
+// RotateKeyWithConfig is RotateKey, but the values are re-encrypted with the
+// scheme in [config], which is used from then on. It upgrades the values that
+// were encrypted with a weaker scheme.
+func (db *Database) RotateKeyWithConfig(newPassword []byte, config Config) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+
+	rotated, err := NewWithConfig(newPassword, nil, config)
+	if err != nil {
+		return err
+	}
+	rotated.codec = db.codec
+
+	it := db.db.NewIterator()
+	defer it.Release()
+
+	batch := db.db.NewBatch()
+	for it.Next() {
+		value, err := db.decrypt(it.Value())
+		if err != nil {
+			return err
+		}
+		encValue, err := rotated.encrypt(value)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(it.Key(), encValue); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.password = rotated.password
+	db.config = rotated.config
+	db.scheme = rotated.scheme
+	db.cipherLock.Lock()
+	db.ciphers = rotated.ciphers
+	db.cipherLock.Unlock()
+	return nil
 }
 
 // Has implements the Database interface
@@ -91,6 +387,17 @@ func (db *Database) Delete(key []byte) error {
 	return db.db.Delete(key)
 }
 
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return db.db.DeleteRange(start, end)
+}
+
 // NewBatch implements the Database interface
 func (db *Database) NewBatch() database.Batch {
 	return &batch{
@@ -100,7 +407,9 @@ func (db *Database) NewBatch() database.Batch {
 }
 
 // NewIterator implements the Database interface
-func (db *Database) NewIterator() database.Iterator { return db.NewIteratorWithStartAndPrefix(nil, nil) }
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
 
 // NewIteratorWithStart implements the Database interface
 func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
@@ -257,27 +566,76 @@ func copyBytes(bytes []byte) []byte {
 	return copiedBytes
 }
 
+// encryptedValue is a value stored before headers were added
 type encryptedValue struct {
 	Ciphertext []byte `serialize:"true"`
 	Nonce      []byte `serialize:"true"`
 }
 
+// encrypt returns [plaintext] encrypted with the database's scheme, after a
+// header that describes the scheme
 func (db *Database) encrypt(plaintext []byte) ([]byte, error) {
-	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	aead, err := db.cipher(db.scheme)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
 	if _, err := rand.Read(nonce); err != nil {
 		return nil, err
 	}
-	ciphertext := db.cipher.Seal(nil, nonce, plaintext, nil)
-	return db.codec.Marshal(&encryptedValue{
-		Ciphertext: ciphertext,
-		Nonce:      nonce,
-	})
+
+	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	p.PackByte(headerVersion)
+	p.PackByte(byte(db.scheme.cipher))
+	p.PackByte(byte(db.scheme.kdf))
+	if db.scheme.kdf == Argon2id {
+		p.PackInt(db.scheme.argon2Time)
+		p.PackInt(db.scheme.argon2Memory)
+		p.PackByte(db.scheme.argon2Threads)
+		p.PackBytes([]byte(db.scheme.argon2Salt))
+	}
+	p.PackBytes(nonce)
+	p.PackBytes(aead.Seal(nil, nonce, plaintext, nil))
+	return p.Bytes, p.Err
 }
 
+// decrypt returns the plaintext of [ciphertext], which may have been encrypted
+// with any scheme
 func (db *Database) decrypt(ciphertext []byte) ([]byte, error) {
-	val := encryptedValue{}
-	if err := db.codec.Unmarshal(ciphertext, &val); err != nil {
+	if len(ciphertext) > 0 && ciphertext[0] == 0 {
+		val := encryptedValue{}
+		if err := db.codec.Unmarshal(ciphertext, &val); err != nil {
+			return nil, err
+		}
+		aead, err := db.cipher(legacyScheme)
+		if err != nil {
+			return nil, err
+		}
+		return aead.Open(nil, val.Nonce, val.Ciphertext, nil)
+	}
+
+	p := wrappers.Packer{Bytes: ciphertext}
+	if version := p.UnpackByte(); !p.Errored() && version != headerVersion {
+		return nil, errUnknownVersion
+	}
+	s := scheme{
+		cipher: Cipher(p.UnpackByte()),
+		kdf:    KDF(p.UnpackByte()),
+	}
+	if s.kdf == Argon2id {
+		s.argon2Time = p.UnpackInt()
+		s.argon2Memory = p.UnpackInt()
+		s.argon2Threads = p.UnpackByte()
+		s.argon2Salt = string(p.UnpackBytes())
+	}
+	nonce := p.UnpackBytes()
+	sealed := p.UnpackBytes()
+	if p.Errored() {
+		return nil, p.Err
+	}
+	aead, err := db.cipher(s)
+	if err != nil {
 		return nil, err
 	}
-	return db.cipher.Open(nil, val.Nonce, val.Ciphertext, nil)
+	return aead.Open(nil, nonce, sealed, nil)
 }
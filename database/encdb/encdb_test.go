@@ -4,15 +4,30 @@
 package encdb
 
 import (
+	"bytes"
+	"crypto/rand"
 	"testing"
 
-	"github.com/ava-labs/gecko/database"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/ava-labs/gecko/database/dbtest"
 	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/codec"
 )
 
+// testConfigs are cheap to derive keys with
+var testConfigs = []Config{
+	{Cipher: ChaCha20Poly1305, KDF: SHA256},
+	{Cipher: AESGCM, KDF: SHA256},
+	{Cipher: ChaCha20Poly1305, KDF: Argon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1},
+	{Cipher: AESGCM, KDF: Argon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1, Argon2Salt: []byte("salt")},
+}
+
 func TestInterface(t *testing.T) {
 	pw := "lol totally a secure password"
-	for _, test := range database.Tests {
+	for _, test := range dbtest.Tests {
 		unencryptedDB := memdb.New()
 		db, err := New([]byte(pw), unencryptedDB)
 		if err != nil {
@@ -22,3 +37,250 @@ func TestInterface(t *testing.T) {
 		test(t, db)
 	}
 }
+
+func TestInterfaceConfigs(t *testing.T) {
+	pw := "lol totally a secure password"
+	for _, config := range testConfigs {
+		for _, test := range dbtest.Tests {
+			db, err := NewWithConfig([]byte(pw), memdb.New(), config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			test(t, db)
+		}
+	}
+}
+
+func TestConfigsReadable(t *testing.T) {
+	pw := []byte("lol totally a secure password")
+	key := []byte("hello")
+	value := []byte("world")
+
+	for _, config := range testConfigs {
+		unencryptedDB := memdb.New()
+		db, err := NewWithConfig(pw, unencryptedDB, config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+
+		// The scheme is read from the value, so any config can read it
+		otherDB, err := New(pw, unencryptedDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v, err := otherDB.Get(key); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(v, value) {
+			t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+		}
+
+		wrongDB, err := New([]byte("wrong password"), unencryptedDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wrongDB.Get(key); err == nil {
+			t.Fatalf("Value shouldn't have been readable with the wrong password")
+		}
+	}
+}
+
+func TestLegacyValue(t *testing.T) {
+	pw := []byte("lol totally a secure password")
+	key := []byte("hello")
+	value := []byte("world")
+
+	// Store a value the way it was stored before headers were added
+	aead, err := chacha20poly1305.NewX(hashing.ComputeHash256(pw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(nonce); err != nil {
+		t.Fatal(err)
+	}
+	legacyValue, err := codec.NewDefault().Marshal(&encryptedValue{
+		Ciphertext: aead.Seal(nil, nonce, value, nil),
+		Nonce:      nonce,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unencryptedDB := memdb.New()
+	if err := unencryptedDB.Put(key, legacyValue); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := NewWithConfig(pw, unencryptedDB, testConfigs[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	configs := []Config{
+		{Cipher: 2},
+		{KDF: 2},
+		{KDF: Argon2id},
+		{KDF: Argon2id, Argon2Time: 1, Argon2Memory: MaxArgon2Memory + 1, Argon2Threads: 1},
+		{KDF: Argon2id, Argon2Time: MaxArgon2Time + 1, Argon2Memory: 64, Argon2Threads: 1},
+		{KDF: Argon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: MaxArgon2Threads + 1},
+	}
+	for _, config := range configs {
+		if _, err := NewWithConfig(nil, memdb.New(), config); err == nil {
+			t.Fatalf("Should have errored due to an invalid config")
+		}
+	}
+}
+
+func TestHostileHeader(t *testing.T) {
+	pw := []byte("lol totally a secure password")
+	value, err := EncryptValue(pw, []byte("world"), testConfigs[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Claim that the value's key was derived with a GiB of memory. Deriving
+	// it would run the node out of memory, so the value must be refused
+	// before the derivation starts.
+	p := wrappers.Packer{Bytes: value, Offset: 3 + wrappers.IntLen}
+	p.PackInt(1 << 20)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if _, err := DecryptValue(pw, value); err != errArgon2ParamsTooLarge {
+		t.Fatalf("Decrypting a value with hostile Argon2id parameters returned %v ; Expected: %s", err, errArgon2ParamsTooLarge)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	oldPassword := []byte("lol totally a secure password")
+	newPassword := []byte("a more secure password")
+	key := []byte("hello")
+	value := []byte("world")
+
+	unencryptedDB := memdb.New()
+	db, err := New(oldPassword, unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.RotateKey(newPassword); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+
+	// The values can only be read with the new password
+	oldDB, err := New(oldPassword, unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oldDB.Get(key); err == nil {
+		t.Fatalf("Value shouldn't have been readable with the old password")
+	}
+	newDB, err := New(newPassword, unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := newDB.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("newDB.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+}
+
+func TestRotateKeyWithConfig(t *testing.T) {
+	oldPassword := []byte("lol totally a secure password")
+	newPassword := []byte("a more secure password")
+	key := []byte("hello")
+	value := []byte("world")
+
+	unencryptedDB := memdb.New()
+	db, err := New(oldPassword, unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	config := testConfigs[2]
+	if err := db.RotateKeyWithConfig(newPassword, config); err != nil {
+		t.Fatal(err)
+	}
+	encValue, err := unencryptedDB.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kdf := KDF(encValue[2]); kdf != config.KDF {
+		t.Fatalf("Value was re-encrypted with KDF %d ; Expected %d", kdf, config.KDF)
+	}
+
+	// New values are encrypted with the new scheme too
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	if encValue, err = unencryptedDB.Get(key); err != nil {
+		t.Fatal(err)
+	} else if kdf := KDF(encValue[2]); kdf != config.KDF {
+		t.Fatalf("Value was encrypted with KDF %d ; Expected %d", kdf, config.KDF)
+	}
+
+	newDB, err := New(newPassword, unencryptedDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := newDB.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("newDB.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	pw := []byte("lol totally a secure password")
+	config := testConfigs[2]
+	key := []byte("hello")
+	value := []byte("world")
+
+	baseDB := memdb.New()
+	db, err := Open(pw, baseDB, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open([]byte("wrong password"), baseDB, config); err != errWrongPassword {
+		t.Fatalf("Open returned %v ; Expected %s", err, errWrongPassword)
+	}
+
+	reopenedDB, err := Open(pw, baseDB, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := reopenedDB.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+	if db.scheme != reopenedDB.scheme {
+		t.Fatalf("Reopened database should have used the stored salt")
+	}
+}
@@ -0,0 +1,77 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+)
+
+var (
+	errWrongPassword = errors.New("wrong password for the encrypted database")
+
+	// Prefixes of the values and metadata of databases opened with Open
+	valuesPrefix   = []byte("encrypted")
+	metadataPrefix = []byte("encryption")
+
+	saltKey  = []byte("salt")
+	checkKey = []byte("check")
+	// checkValue is encrypted to verify the password
+	checkValue = []byte("gecko")
+)
+
+// Open returns an encrypted database that stores its values in [db], along
+// with what's needed to open it again: the salt of its key, if [config]
+// derives keys with Argon2id and doesn't set a salt, and a value that verifies
+// the password. If [db] was opened with another password, Open fails. The
+// values are kept under a prefix, so [db] should only be used through the
+// returned database. Keys aren't encrypted.
+func Open(password []byte, db database.Database, config Config) (*Database, error) {
+	metadata := prefixdb.New(metadataPrefix, db)
+	if config.KDF == Argon2id && len(config.Argon2Salt) == 0 {
+		salt, err := metadata.Get(saltKey)
+		switch err {
+		case nil:
+		case database.ErrNotFound:
+			salt = make([]byte, saltSize)
+			if _, err := rand.Read(salt); err != nil {
+				return nil, err
+			}
+			if err := metadata.Put(saltKey, salt); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+		config.Argon2Salt = salt
+	}
+
+	encDB, err := NewWithConfig(password, prefixdb.New(valuesPrefix, db), config)
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := metadata.Get(checkKey)
+	switch err {
+	case nil:
+		if value, err := encDB.decrypt(check); err != nil || !bytes.Equal(value, checkValue) {
+			return nil, errWrongPassword
+		}
+	case database.ErrNotFound:
+		encCheck, err := encDB.encrypt(checkValue)
+		if err != nil {
+			return nil, err
+		}
+		if err := metadata.Put(checkKey, encCheck); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	return encDB, nil
+}
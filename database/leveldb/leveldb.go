@@ -5,6 +5,8 @@ package leveldb
 
 import (
 	"bytes"
+	"strconv"
+	"strings"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -29,34 +31,71 @@ const (
 	minHandleCap = 16
 )
 
+// Config of a LevelDB database. Values below the minimums are raised to them.
+type Config struct {
+	// Number of bytes of uncompressed blocks cached in memory
+	BlockCacheSize int
+	// Number of bytes of writes buffered in memory. Two buffers of half this
+	// size are used, and a full buffer is written to a table.
+	WriteBufferSize int
+	// Number of open files cached
+	HandleCap int
+	// Number of bytes of the tables written by compactions. If zero, LevelDB's
+	// default is used. Larger tables mean fewer, longer compactions.
+	CompactionTableSize int
+	// Number of bits per key of the bloom filters of the tables. If zero,
+	// tables don't have bloom filters.
+	BloomFilterBits int
+}
+
+// DefaultConfig is the default configuration of a LevelDB database
+var DefaultConfig = Config{
+	BlockCacheSize:      64 * opt.MiB,
+	WriteBufferSize:     32 * opt.MiB,
+	HandleCap:           512,
+	CompactionTableSize: 8 * opt.MiB,
+	BloomFilterBits:     10,
+}
+
 // Database is a persistent key-value store. Apart from basic data storage
 // functionality it also supports batch writes and iterating over the keyspace
 // in binary-alphabetical order.
 type Database struct{ *leveldb.DB }
 
 // New returns a wrapped LevelDB object.
-func New(file string, blockCacheSize, writeBufferSize, handleCap int) (*Database, error) {
-	// Enforce minimums
-	if blockCacheSize < minBlockCacheSize {
-		blockCacheSize = minBlockCacheSize
-	}
-	if writeBufferSize < minWriteBufferSize {
-		writeBufferSize = minWriteBufferSize
+func New(file string, config Config) (*Database, error) { return open(file, config, false) }
+
+// NewStrict returns a wrapped LevelDB object that verifies the checksum of
+// every block it reads, and fails to open a corrupted database rather than
+// recovering it, which may drop data.
+func NewStrict(file string, config Config) (*Database, error) { return open(file, config, true) }
+
+// NewReadOnly returns a wrapped LevelDB object of the existing database in
+// [file] that can't be written to. The database is opened without taking its
+// exclusive lock and without recovering it, so opening it never modifies its
+// files. Writes fail with leveldb.ErrReadOnly.
+func NewReadOnly(file string, config Config) (*Database, error) {
+	options := newOptions(config)
+	options.ReadOnly = true
+	options.ErrorIfMissing = true
+
+	db, err := leveldb.OpenFile(file, options)
+	if err != nil {
+		return nil, err
 	}
-	if handleCap < minHandleCap {
-		handleCap = minHandleCap
+	return &Database{DB: db}, nil
+}
+
+func open(file string, config Config, strict bool) (*Database, error) {
+	options := newOptions(config)
+	if strict {
+		options.Strict = opt.StrictAll
 	}
 
 	// Open the db and recover any potential corruptions
-	db, err := leveldb.OpenFile(file, &opt.Options{
-		OpenFilesCacheCapacity: handleCap,
-		BlockCacheCapacity:     blockCacheSize,
-		// There are two buffers of size WriteBuffer used.
-		WriteBuffer: writeBufferSize / 2,
-		Filter:      filter.NewBloomFilter(10),
-	})
-	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
-		db, err = leveldb.RecoverFile(file, nil)
+	db, err := leveldb.OpenFile(file, options)
+	if _, corrupted := err.(*errors.ErrCorrupted); corrupted && !strict {
+		db, err = leveldb.RecoverFile(file, options)
 	}
 	if err != nil {
 		return nil, err
@@ -64,6 +103,32 @@ func New(file string, blockCacheSize, writeBufferSize, handleCap int) (*Database
 	return &Database{DB: db}, nil
 }
 
+// newOptions returns the options of a database configured by [config]
+func newOptions(config Config) *opt.Options {
+	// Enforce minimums
+	if config.BlockCacheSize < minBlockCacheSize {
+		config.BlockCacheSize = minBlockCacheSize
+	}
+	if config.WriteBufferSize < minWriteBufferSize {
+		config.WriteBufferSize = minWriteBufferSize
+	}
+	if config.HandleCap < minHandleCap {
+		config.HandleCap = minHandleCap
+	}
+
+	options := &opt.Options{
+		OpenFilesCacheCapacity: config.HandleCap,
+		BlockCacheCapacity:     config.BlockCacheSize,
+		// There are two buffers of size WriteBuffer used.
+		WriteBuffer:         config.WriteBufferSize / 2,
+		CompactionTableSize: config.CompactionTableSize,
+	}
+	if config.BloomFilterBits > 0 {
+		options.Filter = filter.NewBloomFilter(config.BloomFilterBits)
+	}
+	return options
+}
+
 // Has returns if the key is set in the database
 func (db *Database) Has(key []byte) (bool, error) {
 	has, err := db.DB.Has(key, nil)
@@ -84,6 +149,12 @@ func (db *Database) Put(key []byte, value []byte) error {
 // Delete removes the key from the database
 func (db *Database) Delete(key []byte) error { return updateError(db.DB.Delete(key, nil)) }
 
+// DeleteRange removes the keys in [start, end) from the database. LevelDB
+// can't delete a range natively, so the keys are deleted in batches.
+func (db *Database) DeleteRange(start, end []byte) error {
+	return database.BatchDeleteRange(db, start, end)
+}
+
 // NewBatch creates a write/delete-only buffer that is atomically committed to
 // the database when write is called
 func (db *Database) NewBatch() database.Batch { return &batch{db: db.DB} }
@@ -137,6 +208,46 @@ func (db *Database) Compact(start []byte, limit []byte) error {
 	return updateError(db.DB.CompactRange(util.Range{Start: start, Limit: limit}))
 }
 
+// DiskSize implements the DiskSizer interface
+func (db *Database) DiskSize(start []byte, limit []byte) (uint64, error) {
+	if limit != nil {
+		sizes, err := db.DB.SizeOf([]util.Range{{Start: start, Limit: limit}})
+		if err != nil {
+			return 0, updateError(err)
+		}
+		return uint64(sizes.Sum()), nil
+	}
+
+	// LevelDB treats a nil limit as a key before all keys, so the size after
+	// [start] is the size of every table, less the size before [start]
+	tables, err := db.DB.GetProperty("leveldb.sstables")
+	if err != nil {
+		return 0, updateError(err)
+	}
+	total := uint64(0)
+	for _, line := range strings.Split(tables, "\n") {
+		// Each table is described as <number>:<size>[<min key> .. <max key>]
+		sizeStart := strings.IndexByte(line, ':')
+		sizeEnd := strings.IndexByte(line, '[')
+		if sizeStart < 0 || sizeEnd < sizeStart {
+			continue
+		}
+		size, err := strconv.ParseUint(line[sizeStart+1:sizeEnd], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	before, err := db.DB.SizeOf([]util.Range{{Limit: start}})
+	if err != nil {
+		return 0, updateError(err)
+	}
+	if uint64(before.Sum()) > total {
+		return 0, nil
+	}
+	return total - uint64(before.Sum()), nil
+}
+
 // Close implements the Database interface
 func (db *Database) Close() error { return updateError(db.DB.Close()) }
 
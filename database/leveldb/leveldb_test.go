@@ -4,20 +4,21 @@
 package leveldb
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"testing"
 
-	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
 )
 
 func TestInterface(t *testing.T) {
-	for i, test := range database.Tests {
+	for i, test := range dbtest.Tests {
 		folder := fmt.Sprintf("db%d", i)
 
-		db, err := New(folder, 0, 0, 0)
+		db, err := New(folder, Config{})
 		if err != nil {
-			t.Fatalf("leveldb.New(%s, 0, 0) errored with %s", folder, err)
+			t.Fatalf("leveldb.New(%s, Config{}) errored with %s", folder, err)
 		}
 		defer os.RemoveAll(folder)
 		defer db.Close()
@@ -25,3 +26,79 @@ func TestInterface(t *testing.T) {
 		test(t, db)
 	}
 }
+
+func TestReadOnly(t *testing.T) {
+	folder := "db_read_only"
+	defer os.RemoveAll(folder)
+
+	if _, err := NewReadOnly(folder, Config{}); err == nil {
+		t.Fatalf("Opening a missing database read-only should have failed")
+	}
+
+	key := []byte("hello")
+	value := []byte("world")
+
+	db, err := New(folder, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = NewReadOnly(folder, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if v, err := db.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+	if err := db.Put(key, value); err == nil {
+		t.Fatalf("Writing to a read-only database should have failed")
+	}
+}
+
+func TestDiskSize(t *testing.T) {
+	folder := "db_disk_size"
+	defer os.RemoveAll(folder)
+
+	db, err := New(folder, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	value := make([]byte, 1024)
+	for i := 0; i < 1024; i++ {
+		if err := db.Put([]byte{1, byte(i >> 8), byte(i)}, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Flush the keys to tables, which are what is measured
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if size, err := db.DiskSize(nil, nil); err != nil {
+		t.Fatal(err)
+	} else if size == 0 {
+		t.Fatalf("The keys should use space on disk")
+	}
+	if size, err := db.DiskSize([]byte{1}, []byte{2}); err != nil {
+		t.Fatal(err)
+	} else if size == 0 {
+		t.Fatalf("The keys in the range should use space on disk")
+	}
+	if size, err := db.DiskSize([]byte{2}, nil); err != nil {
+		t.Fatal(err)
+	} else if size != 0 {
+		t.Fatalf("No keys should be after the prefix but %d bytes are", size)
+	}
+}
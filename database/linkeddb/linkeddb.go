@@ -0,0 +1,405 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package linkeddb
+
+import (
+	"math"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Prefixes of the keys stored in the underlying database
+const (
+	nodePrefix byte = iota
+	metadataPrefix
+)
+
+var metadataKey = []byte{metadataPrefix}
+
+// LinkedDB is a list of key-value pairs, in the order their keys were first
+// put, that is stored in a database. Each pair is stored as a node linked to
+// the previous and next pairs, so pairs can be put and deleted, and the list
+// iterated over in either direction, without reading or sorting the other
+// pairs.
+type LinkedDB struct {
+	lock sync.RWMutex
+	db   database.Database
+
+	// The first and last keys of the list, once loaded
+	metadata *metadata
+}
+
+type node struct {
+	value            []byte
+	hasPrev, hasNext bool
+	prev, next       []byte
+}
+
+type metadata struct {
+	// False if the list is empty
+	hasHead    bool
+	head, tail []byte
+}
+
+// New returns the list stored in [db]. The list must be the only user of [db].
+func New(db database.Database) *LinkedDB { return &LinkedDB{db: db} }
+
+// Has returns true if [key] is in the list
+func (ldb *LinkedDB) Has(key []byte) (bool, error) {
+	ldb.lock.RLock()
+	defer ldb.lock.RUnlock()
+
+	return ldb.db.Has(nodeKey(key))
+}
+
+// Get returns the value of [key], or database.ErrNotFound if [key] isn't in
+// the list
+func (ldb *LinkedDB) Get(key []byte) ([]byte, error) {
+	ldb.lock.RLock()
+	defer ldb.lock.RUnlock()
+
+	n, err := ldb.getNode(key)
+	return n.value, err
+}
+
+// Put sets the value of [key] to [value]. If [key] isn't in the list, it's
+// appended to the end of the list. Otherwise, it keeps its position.
+func (ldb *LinkedDB) Put(key, value []byte) error {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	n, err := ldb.getNode(key)
+	switch err {
+	case nil:
+		n.value = value
+		return ldb.db.Put(nodeKey(key), n.marshal())
+	case database.ErrNotFound:
+	default:
+		return err
+	}
+
+	meta, err := ldb.getMetadata()
+	if err != nil {
+		return err
+	}
+
+	// The key is cached as the tail of the list
+	key = copyBytes(key)
+	batch := ldb.db.NewBatch()
+	newNode := node{value: value}
+	newMeta := metadata{
+		hasHead: true,
+		head:    key,
+		tail:    key,
+	}
+	if meta.hasHead {
+		tail, err := ldb.getNode(meta.tail)
+		if err != nil {
+			return err
+		}
+		tail.hasNext = true
+		tail.next = key
+		if err := batch.Put(nodeKey(meta.tail), tail.marshal()); err != nil {
+			return err
+		}
+
+		newNode.hasPrev = true
+		newNode.prev = meta.tail
+		newMeta.head = meta.head
+	}
+	if err := batch.Put(nodeKey(key), newNode.marshal()); err != nil {
+		return err
+	}
+	return ldb.writeMetadata(batch, newMeta)
+}
+
+// Delete removes [key] from the list. Deleting a key that isn't in the list is
+// a no-op.
+func (ldb *LinkedDB) Delete(key []byte) error {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	n, err := ldb.getNode(key)
+	switch err {
+	case nil:
+	case database.ErrNotFound:
+		return nil
+	default:
+		return err
+	}
+
+	meta, err := ldb.getMetadata()
+	if err != nil {
+		return err
+	}
+
+	batch := ldb.db.NewBatch()
+	newMeta := metadata{
+		hasHead: n.hasPrev || n.hasNext,
+		head:    meta.head,
+		tail:    meta.tail,
+	}
+	if n.hasPrev {
+		prev, err := ldb.getNode(n.prev)
+		if err != nil {
+			return err
+		}
+		prev.hasNext = n.hasNext
+		prev.next = n.next
+		if err := batch.Put(nodeKey(n.prev), prev.marshal()); err != nil {
+			return err
+		}
+	} else {
+		newMeta.head = n.next
+	}
+	if n.hasNext {
+		next, err := ldb.getNode(n.next)
+		if err != nil {
+			return err
+		}
+		next.hasPrev = n.hasPrev
+		next.prev = n.prev
+		if err := batch.Put(nodeKey(n.next), next.marshal()); err != nil {
+			return err
+		}
+	} else {
+		newMeta.tail = n.prev
+	}
+	if !newMeta.hasHead {
+		newMeta.head = nil
+		newMeta.tail = nil
+	}
+	if err := batch.Delete(nodeKey(key)); err != nil {
+		return err
+	}
+	return ldb.writeMetadata(batch, newMeta)
+}
+
+// IsEmpty returns true if the list has no keys
+func (ldb *LinkedDB) IsEmpty() (bool, error) {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	meta, err := ldb.getMetadata()
+	return !meta.hasHead, err
+}
+
+// Head returns the first key of the list and its value, or
+// database.ErrNotFound if the list is empty
+func (ldb *LinkedDB) Head() ([]byte, []byte, error) { return ldb.end(true) }
+
+// Tail returns the last key of the list and its value, or database.ErrNotFound
+// if the list is empty
+func (ldb *LinkedDB) Tail() ([]byte, []byte, error) { return ldb.end(false) }
+
+func (ldb *LinkedDB) end(head bool) ([]byte, []byte, error) {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	meta, err := ldb.getMetadata()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !meta.hasHead {
+		return nil, nil, database.ErrNotFound
+	}
+	key := meta.tail
+	if head {
+		key = meta.head
+	}
+	n, err := ldb.getNode(key)
+	return key, n.value, err
+}
+
+// NewIterator returns an iterator over the list, from its first key to its
+// last. Modifying the list while the iterator is used may cause the iterator
+// to fail.
+func (ldb *LinkedDB) NewIterator() database.Iterator { return &iterator{ldb: ldb, forward: true} }
+
+// NewReverseIterator returns an iterator over the list, from its last key to
+// its first. Modifying the list while the iterator is used may cause the
+// iterator to fail.
+func (ldb *LinkedDB) NewReverseIterator() database.Iterator { return &iterator{ldb: ldb} }
+
+// getNode returns the node of [key]. Assumes the lock is held.
+func (ldb *LinkedDB) getNode(key []byte) (node, error) {
+	bytes, err := ldb.db.Get(nodeKey(key))
+	if err != nil {
+		return node{}, err
+	}
+	return unmarshalNode(bytes)
+}
+
+// getMetadata returns the metadata of the list, loading it if it isn't loaded
+// yet. Assumes the write lock is held.
+func (ldb *LinkedDB) getMetadata() (metadata, error) {
+	if ldb.metadata != nil {
+		return *ldb.metadata, nil
+	}
+
+	bytes, err := ldb.db.Get(metadataKey)
+	switch err {
+	case nil:
+	case database.ErrNotFound:
+		ldb.metadata = &metadata{}
+		return *ldb.metadata, nil
+	default:
+		return metadata{}, err
+	}
+
+	p := wrappers.Packer{Bytes: bytes}
+	meta := metadata{hasHead: p.UnpackBool()}
+	if meta.hasHead {
+		meta.head = p.UnpackBytes()
+		meta.tail = p.UnpackBytes()
+	}
+	if p.Errored() {
+		return metadata{}, p.Err
+	}
+	ldb.metadata = &meta
+	return meta, nil
+}
+
+// writeMetadata writes [batch] along with [meta], and caches [meta] once it's
+// written. Assumes the write lock is held.
+func (ldb *LinkedDB) writeMetadata(batch database.Batch, meta metadata) error {
+	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	p.PackBool(meta.hasHead)
+	if meta.hasHead {
+		p.PackBytes(meta.head)
+		p.PackBytes(meta.tail)
+	}
+	if err := batch.Put(metadataKey, p.Bytes); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	ldb.metadata = &meta
+	return nil
+}
+
+func (n *node) marshal() []byte {
+	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	p.PackBytes(n.value)
+	p.PackBool(n.hasPrev)
+	if n.hasPrev {
+		p.PackBytes(n.prev)
+	}
+	p.PackBool(n.hasNext)
+	if n.hasNext {
+		p.PackBytes(n.next)
+	}
+	return p.Bytes
+}
+
+func unmarshalNode(bytes []byte) (node, error) {
+	p := wrappers.Packer{Bytes: bytes}
+	n := node{value: p.UnpackBytes()}
+	if n.hasPrev = p.UnpackBool(); n.hasPrev {
+		n.prev = p.UnpackBytes()
+	}
+	if n.hasNext = p.UnpackBool(); n.hasNext {
+		n.next = p.UnpackBytes()
+	}
+	if p.Errored() {
+		return node{}, p.Err
+	}
+	return n, nil
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
+
+func nodeKey(key []byte) []byte {
+	prefixedKey := make([]byte, len(key)+1)
+	prefixedKey[0] = nodePrefix
+	copy(prefixedKey[1:], key)
+	return prefixedKey
+}
+
+type iterator struct {
+	ldb     *LinkedDB
+	forward bool
+
+	initialized bool
+	// The key of the node to read on the next call to Next, if [hasNext]
+	hasNext bool
+	nextKey []byte
+
+	key, value []byte
+	err        error
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.initialized {
+		it.initialized = true
+
+		it.ldb.lock.Lock()
+		meta, err := it.ldb.getMetadata()
+		it.ldb.lock.Unlock()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.hasNext = meta.hasHead
+		it.nextKey = meta.tail
+		if it.forward {
+			it.nextKey = meta.head
+		}
+	}
+	if !it.hasNext {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+
+	it.ldb.lock.RLock()
+	n, err := it.ldb.getNode(it.nextKey)
+	it.ldb.lock.RUnlock()
+	if err != nil {
+		it.err = err
+		it.key = nil
+		it.value = nil
+		return false
+	}
+
+	it.key = it.nextKey
+	it.value = n.value
+	if it.forward {
+		it.hasNext = n.hasNext
+		it.nextKey = n.next
+	} else {
+		it.hasNext = n.hasPrev
+		it.nextKey = n.prev
+	}
+	return true
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error { return it.err }
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte { return it.key }
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte { return it.value }
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	it.initialized = true
+	it.hasNext = false
+	it.nextKey = nil
+	it.key = nil
+	it.value = nil
+}
@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package linkeddb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+// assertOrder fails the test if iterating over [ldb] in either direction
+// doesn't return [keys], in order
+func assertOrder(t *testing.T, ldb *LinkedDB, keys ...string) {
+	for _, forward := range []bool{true, false} {
+		it := ldb.NewReverseIterator()
+		if forward {
+			it = ldb.NewIterator()
+		}
+
+		iterated := []string(nil)
+		for it.Next() {
+			iterated = append(iterated, string(it.Key()))
+			if value := it.Value(); !bytes.Equal(value, it.Key()) {
+				t.Fatalf("Value of %s Returned: %s ; Expected: %s", it.Key(), value, it.Key())
+			}
+		}
+		if err := it.Error(); err != nil {
+			t.Fatal(err)
+		}
+		it.Release()
+
+		if len(iterated) != len(keys) {
+			t.Fatalf("Iterated over %v ; Expected: %v", iterated, keys)
+		}
+		for i, key := range iterated {
+			expected := keys[i]
+			if !forward {
+				expected = keys[len(keys)-1-i]
+			}
+			if key != expected {
+				t.Fatalf("Iterated over %v ; Expected: %v (forward: %v)", iterated, keys, forward)
+			}
+		}
+	}
+}
+
+func TestLinkedDB(t *testing.T) {
+	db := memdb.New()
+	ldb := New(db)
+
+	if empty, err := ldb.IsEmpty(); err != nil {
+		t.Fatal(err)
+	} else if !empty {
+		t.Fatalf("New list should have been empty")
+	}
+	if _, _, err := ldb.Head(); err != database.ErrNotFound {
+		t.Fatalf("Head Returned: %v ; Expected: %s", err, database.ErrNotFound)
+	}
+	assertOrder(t, ldb)
+
+	// Keys are ordered by when they were first put, not by their values
+	for _, key := range []string{"c", "a", "d", "b"} {
+		if err := ldb.Put([]byte(key), []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertOrder(t, ldb, "c", "a", "d", "b")
+
+	// Overwriting a key keeps its position
+	if err := ldb.Put([]byte("a"), []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, ldb, "c", "a", "d", "b")
+
+	if err := ldb.Delete([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, ldb, "c", "d", "b")
+	if has, err := ldb.Has([]byte("a")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Deleted key should have been removed")
+	}
+
+	if err := ldb.Delete([]byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ldb.Delete([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, ldb, "d")
+	if key, value, err := ldb.Tail(); err != nil {
+		t.Fatal(err)
+	} else if string(key) != "d" || string(value) != "d" {
+		t.Fatalf("Tail Returned: %s, %s ; Expected: d, d", key, value)
+	}
+
+	// The list is read back from the database
+	ldb = New(db)
+	if err := ldb.Put([]byte("e"), []byte("e")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, ldb, "d", "e")
+
+	if err := ldb.Delete([]byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ldb.Delete([]byte("e")); err != nil {
+		t.Fatal(err)
+	}
+	assertOrder(t, ldb)
+	if empty, err := ldb.IsEmpty(); err != nil {
+		t.Fatal(err)
+	} else if !empty {
+		t.Fatalf("List should have been empty")
+	}
+}
@@ -4,30 +4,51 @@
 package memdb
 
 import (
-	"sort"
 	"strings"
 	"sync"
 
+	"github.com/google/btree"
+
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/nodb"
 )
 
-// DefaultSize is the default initial size of the memory database
-const DefaultSize = 1 << 10
+const (
+	// DefaultSize is the default initial size of the memory database
+	DefaultSize = 1 << 10
+
+	// treeDegree is the degree of the B-tree the keys are stored in
+	treeDegree = 32
+
+	// iteratorPageSize is the number of keys an iterator reads from the tree
+	// at a time
+	iteratorPageSize = 128
+)
 
 // Database is an ephemeral key-value store that implements the Database
-// interface.
+// interface. The keys are stored in order in a B-tree, so iterators don't have
+// to sort them.
 type Database struct {
 	lock sync.RWMutex
-	db   map[string][]byte
+	db   *btree.BTree
+}
+
+// entry is a key-value pair stored in the tree. The value of an entry is never
+// modified, so it can be shared with iterators.
+type entry struct {
+	key   string
+	value []byte
 }
 
-// New returns a map with the Database interface methods implemented.
+// Less implements the btree.Item interface
+func (e *entry) Less(than btree.Item) bool { return e.key < than.(*entry).key }
+
+// New returns an empty in-memory database
 func New() *Database { return NewWithSize(DefaultSize) }
 
-// NewWithSize returns a map pre-allocated to the provided size with the
-// Database interface methods implemented.
-func NewWithSize(size int) *Database { return &Database{db: make(map[string][]byte, size)} }
+// NewWithSize returns an empty in-memory database. The tree grows as keys are
+// added, so [size] is only kept for compatibility.
+func NewWithSize(size int) *Database { return &Database{db: btree.New(treeDegree)} }
 
 // Close implements the Database interface
 func (db *Database) Close() error {
@@ -49,8 +70,7 @@ func (db *Database) Has(key []byte) (bool, error) {
 	if db.db == nil {
 		return false, database.ErrClosed
 	}
-	_, ok := db.db[string(key)]
-	return ok, nil
+	return db.db.Has(&entry{key: string(key)}), nil
 }
 
 // Get implements the Database interface
@@ -61,8 +81,8 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	if db.db == nil {
 		return nil, database.ErrClosed
 	}
-	if entry, ok := db.db[string(key)]; ok {
-		return copyBytes(entry), nil
+	if item := db.db.Get(&entry{key: string(key)}); item != nil {
+		return copyBytes(item.(*entry).value), nil
 	}
 	return nil, database.ErrNotFound
 }
@@ -75,7 +95,7 @@ func (db *Database) Put(key []byte, value []byte) error {
 	if db.db == nil {
 		return database.ErrClosed
 	}
-	db.db[string(key)] = copyBytes(value)
+	db.db.ReplaceOrInsert(&entry{key: string(key), value: copyBytes(value)})
 	return nil
 }
 
@@ -87,7 +107,33 @@ func (db *Database) Delete(key []byte) error {
 	if db.db == nil {
 		return database.ErrClosed
 	}
-	delete(db.db, string(key))
+	db.db.Delete(&entry{key: string(key)})
+	return nil
+}
+
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+
+	// The tree can't be modified while it's iterated over
+	var deleted []btree.Item
+	collect := func(item btree.Item) bool {
+		deleted = append(deleted, item)
+		return true
+	}
+	if end == nil {
+		db.db.AscendGreaterOrEqual(&entry{key: string(start)}, collect)
+	} else {
+		db.db.AscendRange(&entry{key: string(start)}, &entry{key: string(end)}, collect)
+	}
+	for _, item := range deleted {
+		db.db.Delete(item)
+	}
 	return nil
 }
 
@@ -107,10 +153,13 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
 	return db.NewIteratorWithStartAndPrefix(nil, prefix)
 }
 
-// NewIteratorWithStartAndPrefix implements the Database interface
+// NewIteratorWithStartAndPrefix implements the Database interface. The
+// iterator walks over a copy-on-write clone of the tree, so later writes
+// aren't iterated over, and the keys aren't copied up front.
 func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
-	db.lock.RLock()
-	defer db.lock.RUnlock()
+	// Cloning the tree modifies it
+	db.lock.Lock()
+	defer db.lock.Unlock()
 
 	if db.db == nil {
 		return &nodb.Iterator{Err: database.ErrClosed}
@@ -118,20 +167,13 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 
 	startString := string(start)
 	prefixString := string(prefix)
-	keys := make([]string, 0, len(db.db))
-	for key := range db.db {
-		if strings.HasPrefix(key, prefixString) && key >= startString {
-			keys = append(keys, key)
-		}
-	}
-	sort.Strings(keys) // Keys need to be in sorted order
-	values := make([][]byte, 0, len(keys))
-	for _, key := range keys {
-		values = append(values, db.db[key])
+	if startString < prefixString {
+		startString = prefixString
 	}
 	return &iterator{
-		keys:   keys,
-		values: values,
+		tree:   db.db.Clone(),
+		next:   startString,
+		prefix: prefixString,
 	}
 }
 
@@ -178,11 +220,11 @@ func (b *batch) Write() error {
 	}
 
 	for _, kv := range b.writes {
-		key := string(kv.key)
+		e := &entry{key: string(kv.key), value: kv.value}
 		if kv.delete {
-			delete(b.db.db, key)
+			b.db.db.Delete(e)
 		} else {
-			b.db.db[key] = kv.value
+			b.db.db.ReplaceOrInsert(e)
 		}
 	}
 	return nil
@@ -209,47 +251,75 @@ func (b *batch) Replay(w database.KeyValueWriter) error {
 }
 
 type iterator struct {
-	initialized bool
-	keys        []string
-	values      [][]byte
+	// Not modified, as it's a clone
+	tree *btree.BTree
+
+	// The smallest key that hasn't been read from the tree yet
+	next   string
+	prefix string
+
+	// The entries read from the tree that haven't been iterated over
+	page      []*entry
+	exhausted bool
+
+	key, value []byte
 }
 
 // Next implements the Iterator interface
 func (it *iterator) Next() bool {
-	// If the iterator was not yet initialized, do it now
-	if !it.initialized {
-		it.initialized = true
-		return len(it.keys) > 0
+	if len(it.page) == 0 && !it.exhausted {
+		it.readPage()
+	}
+	if len(it.page) == 0 {
+		it.key = nil
+		it.value = nil
+		return false
 	}
-	// Iterator already initialize, advance it
-	if len(it.keys) > 0 {
-		it.keys = it.keys[1:]
-		it.values = it.values[1:]
+	it.key = []byte(it.page[0].key)
+	it.value = it.page[0].value
+	it.page[0] = nil
+	it.page = it.page[1:]
+	return true
+}
+
+// readPage reads the next page of entries from the tree
+func (it *iterator) readPage() {
+	it.page = make([]*entry, 0, iteratorPageSize)
+	it.tree.AscendGreaterOrEqual(&entry{key: it.next}, func(item btree.Item) bool {
+		// The keys with the prefix are contiguous
+		e := item.(*entry)
+		if !strings.HasPrefix(e.key, it.prefix) {
+			it.exhausted = true
+			return false
+		}
+		it.page = append(it.page, e)
+		return len(it.page) < iteratorPageSize
+	})
+	if len(it.page) < iteratorPageSize {
+		it.exhausted = true
+		return
 	}
-	return len(it.keys) > 0
+	// The smallest key after the last key read
+	it.next = it.page[len(it.page)-1].key + "\x00"
 }
 
 // Error implements the Iterator interface
 func (it *iterator) Error() error { return nil }
 
 // Key implements the Iterator interface
-func (it *iterator) Key() []byte {
-	if len(it.keys) > 0 {
-		return []byte(it.keys[0])
-	}
-	return nil
-}
+func (it *iterator) Key() []byte { return it.key }
 
 // Value implements the Iterator interface
-func (it *iterator) Value() []byte {
-	if len(it.values) > 0 {
-		return it.values[0]
-	}
-	return nil
-}
+func (it *iterator) Value() []byte { return it.value }
 
 // Release implements the Iterator interface
-func (it *iterator) Release() { it.keys = nil; it.values = nil }
+func (it *iterator) Release() {
+	it.tree = nil
+	it.page = nil
+	it.exhausted = true
+	it.key = nil
+	it.value = nil
+}
 
 func copyBytes(bytes []byte) []byte {
 	copiedBytes := make([]byte, len(bytes))
@@ -4,13 +4,72 @@
 package memdb
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
 )
 
 func TestInterface(t *testing.T) {
-	for _, test := range database.Tests {
-		test(t, New())
+	dbtest.TestDatabase(t, func() database.Database { return New() })
+}
+
+func BenchmarkInterface(b *testing.B) {
+	dbtest.BenchmarkDatabase(b, func() database.Database { return New() })
+}
+
+func TestIteratorPages(t *testing.T) {
+	db := New()
+
+	// Enough keys for several pages, both with and without the prefix
+	numKeys := 3*iteratorPageSize + 1
+	for i := 0; i < numKeys; i++ {
+		for _, prefix := range []byte{1, 2} {
+			key := []byte{prefix, byte(i >> 8), byte(i)}
+			if err := db.Put(key, key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	iterator := db.NewIteratorWithPrefix([]byte{1})
+	defer iterator.Release()
+
+	// Writes after the iterator is created aren't iterated over
+	if err := db.Delete([]byte{1, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < numKeys; i++ {
+		expected := []byte{1, byte(i >> 8), byte(i)}
+		if !iterator.Next() {
+			t.Fatalf("Iterator stopped after %d keys ; Expected: %d", i, numKeys)
+		} else if key := iterator.Key(); !bytes.Equal(key, expected) {
+			t.Fatalf("iterator.Key Returned: 0x%x ; Expected: 0x%x", key, expected)
+		} else if value := iterator.Value(); !bytes.Equal(value, expected) {
+			t.Fatalf("iterator.Value Returned: 0x%x ; Expected: 0x%x", value, expected)
+		}
+	}
+	if iterator.Next() {
+		t.Fatalf("Iterator should have been exhausted")
+	}
+}
+
+func BenchmarkIterator(b *testing.B) {
+	db := New()
+	for i := 0; i < 100000; i++ {
+		key := []byte{byte(i >> 16), byte(i >> 8), byte(i)}
+		if err := db.Put(key, key); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iterator := db.NewIteratorWithStart([]byte{0, byte(i >> 8), byte(i)})
+		for j := 0; j < 10 && iterator.Next(); j++ {
+		}
+		iterator.Release()
 	}
 }
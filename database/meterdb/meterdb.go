@@ -0,0 +1,246 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package meterdb
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Operations whose latency and size are observed
+const (
+	opHas          = "has"
+	opGet          = "get"
+	opPut          = "put"
+	opDelete       = "delete"
+	opDeleteRange  = "delete_range"
+	opBatchWrite   = "batch_write"
+	opIteratorNext = "iterator_next"
+)
+
+// Meter counts the bytes read from and written to the databases that report to
+// it. A meter may be shared by many databases.
+type Meter struct {
+	read, written uint64
+
+	// If registered, the latency and the number of key and value bytes of each
+	// operation, by type of operation
+	latency, size *prometheus.HistogramVec
+}
+
+// Register histograms of the latency and the size of the operations of the
+// meter's databases with [registerer]. Should be called before the meter's
+// databases are used.
+func (m *Meter) Register(namespace string, registerer prometheus.Registerer) error {
+	m.latency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_latency",
+			Help:      "Latency of the database operations, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 12), // 1us to 4s
+		},
+		[]string{"op"})
+	m.size = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_size",
+			Help:      "Number of key and value bytes of the database operations",
+			Buckets:   prometheus.ExponentialBuckets(16, 4, 10), // 16B to 4MB
+		},
+		[]string{"op"})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(m.latency),
+		registerer.Register(m.size),
+	)
+	return errs.Err
+}
+
+// observe an operation of type [op] that started at [start] and read or wrote
+// [size] bytes
+func (m *Meter) observe(op string, start time.Time, size int) {
+	if m.latency == nil {
+		return
+	}
+	m.latency.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	m.size.WithLabelValues(op).Observe(float64(size))
+}
+
+// Read returns the number of key and value bytes read so far
+func (m *Meter) Read() uint64 { return atomic.LoadUint64(&m.read) }
+
+// Written returns the number of key and value bytes written so far
+func (m *Meter) Written() uint64 { return atomic.LoadUint64(&m.written) }
+
+func (m *Meter) addRead(n int)    { atomic.AddUint64(&m.read, uint64(n)) }
+func (m *Meter) addWritten(n int) { atomic.AddUint64(&m.written, uint64(n)) }
+
+// Database reports the bytes read from and written to a database to a meter.
+// Batched writes are reported when the batch is written.
+type Database struct {
+	db    database.Database
+	meter *Meter
+}
+
+// New returns a database that reports the bytes read from and written to [db]
+// to [meter]
+func New(meter *Meter, db database.Database) *Database {
+	return &Database{
+		db:    db,
+		meter: meter,
+	}
+}
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) {
+	start := time.Now()
+	has, err := db.db.Has(key)
+	db.meter.addRead(len(key))
+	db.meter.observe(opHas, start, len(key))
+	return has, err
+}
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) {
+	start := time.Now()
+	value, err := db.db.Get(key)
+	db.meter.addRead(len(key) + len(value))
+	db.meter.observe(opGet, start, len(key)+len(value))
+	return value, err
+}
+
+// Put implements the Database interface
+func (db *Database) Put(key, value []byte) error {
+	start := time.Now()
+	err := db.db.Put(key, value)
+	db.meter.addWritten(len(key) + len(value))
+	db.meter.observe(opPut, start, len(key)+len(value))
+	return err
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	start := time.Now()
+	err := db.db.Delete(key)
+	db.meter.addWritten(len(key))
+	db.meter.observe(opDelete, start, len(key))
+	return err
+}
+
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	began := time.Now()
+	err := db.db.DeleteRange(start, end)
+	db.meter.addWritten(len(start) + len(end))
+	db.meter.observe(opDeleteRange, began, len(start)+len(end))
+	return err
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.db.NewBatch(),
+		meter: db.meter,
+	}
+}
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator {
+	return &iterator{
+		Iterator: db.db.NewIterator(),
+		meter:    db.meter,
+	}
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return &iterator{
+		Iterator: db.db.NewIteratorWithStart(start),
+		meter:    db.meter,
+	}
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return &iterator{
+		Iterator: db.db.NewIteratorWithPrefix(prefix),
+		meter:    db.meter,
+	}
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return &iterator{
+		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		meter:    db.meter,
+	}
+}
+
+// Stat implements the Database interface
+func (db *Database) Stat(stat string) (string, error) { return db.db.Stat(stat) }
+
+// Compact implements the Database interface
+func (db *Database) Compact(start, limit []byte) error { return db.db.Compact(start, limit) }
+
+// Close implements the Database interface
+func (db *Database) Close() error { return db.db.Close() }
+
+type batch struct {
+	database.Batch
+	meter *Meter
+	size  int
+}
+
+// Put implements the Batch interface
+func (b *batch) Put(key, value []byte) error {
+	b.size += len(key) + len(value)
+	return b.Batch.Put(key, value)
+}
+
+// Delete implements the Batch interface
+func (b *batch) Delete(key []byte) error {
+	b.size += len(key)
+	return b.Batch.Delete(key)
+}
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	start := time.Now()
+	err := b.Batch.Write()
+	b.meter.addWritten(b.size)
+	b.meter.observe(opBatchWrite, start, b.size)
+	return err
+}
+
+// Reset implements the Batch interface
+func (b *batch) Reset() {
+	b.size = 0
+	b.Batch.Reset()
+}
+
+// Inner implements the BatchWrapper interface
+func (b *batch) Inner() database.Batch { return b.Batch }
+
+type iterator struct {
+	database.Iterator
+	meter *Meter
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	start := time.Now()
+	if !it.Iterator.Next() {
+		return false
+	}
+	size := len(it.Iterator.Key()) + len(it.Iterator.Value())
+	it.meter.addRead(size)
+	it.meter.observe(opIteratorNext, start, size)
+	return true
+}
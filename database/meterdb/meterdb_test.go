@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package meterdb
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		test(t, New(&Meter{}, memdb.New()))
+	}
+}
+
+func TestMeter(t *testing.T) {
+	meter := &Meter{}
+	db := New(meter, memdb.New())
+
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if written := meter.Written(); written != 8 {
+		t.Fatalf("Written Returned: %d ; Expected: %d", written, 8)
+	}
+
+	if _, err := db.Get([]byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if read := meter.Read(); read != 8 {
+		t.Fatalf("Read Returned: %d ; Expected: %d", read, 8)
+	}
+
+	batch := db.NewBatch()
+	if err := batch.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if written := meter.Written(); written != 8 {
+		t.Fatalf("Unwritten batch shouldn't have been counted")
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if written := meter.Written(); written != 10 {
+		t.Fatalf("Written Returned: %d ; Expected: %d", written, 10)
+	}
+
+	// A meter can be shared between databases
+	other := New(meter, memdb.New())
+	if err := other.Put([]byte("a"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if written := meter.Written(); written != 11 {
+		t.Fatalf("Written Returned: %d ; Expected: %d", written, 11)
+	}
+
+	iterator := db.NewIterator()
+	defer iterator.Release()
+	for iterator.Next() {
+	}
+	if read := meter.Read(); read != 18 {
+		t.Fatalf("Read Returned: %d ; Expected: %d", read, 18)
+	}
+}
+
+func TestMeterHistograms(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	meter := &Meter{}
+	if err := meter.Register("chain", registry); err != nil {
+		t.Fatal(err)
+	}
+	db := New(meter, memdb.New())
+
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get([]byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Get([]byte("missing")); err != database.ErrNotFound {
+		t.Fatalf("Get should have failed with %s but got %v", database.ErrNotFound, err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]map[string]uint64{}
+	sums := map[string]map[string]float64{}
+	for _, family := range families {
+		counts[family.GetName()] = map[string]uint64{}
+		sums[family.GetName()] = map[string]float64{}
+		for _, metric := range family.GetMetric() {
+			op := metric.GetLabel()[0].GetValue()
+			counts[family.GetName()][op] = metric.GetHistogram().GetSampleCount()
+			sums[family.GetName()][op] = metric.GetHistogram().GetSampleSum()
+		}
+	}
+
+	if count := counts["chain_db_latency"][opPut]; count != 1 {
+		t.Fatalf("Should have observed 1 put but observed %d", count)
+	}
+	if count := counts["chain_db_latency"][opGet]; count != 2 {
+		t.Fatalf("Should have observed 2 gets but observed %d", count)
+	}
+	if sum := sums["chain_db_size"][opGet]; sum != 15 {
+		t.Fatalf("Gets should have read 15 bytes but read %f", sum)
+	}
+}
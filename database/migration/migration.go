@@ -0,0 +1,144 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	versionPrefix = []byte("schema")
+	versionKey    = []byte("version")
+
+	errNoPrefix            = errors.New("data must be stored under a prefix")
+	errUnorderedMigrations = errors.New("migrations must have increasing, positive versions")
+	errVersionTooNew       = errors.New("database schema is newer than the latest migration")
+)
+
+// Migration changes the format of a database from the schema version of the
+// migration before it to Version
+type Migration struct {
+	// Version of the schema after the migration. The first schema version,
+	// before any migration, is 0.
+	Version uint64
+	// Name describes the migration in errors
+	Name string
+	// Migrate changes the format of the data in [db]
+	Migrate func(db database.Database) error
+}
+
+// Run migrates the data stored in [db] under the nested [prefixes] to the
+// latest schema version of [migrations], which must be sorted by version. The
+// data is opened as prefixdb.New would open it, so it's laid out as it is for
+// the data's users. The schema version of the data is recorded in a sibling of
+// the last prefix. Each migration is written atomically along with its
+// version, so a migration interrupted by a crash is run again from the start.
+// The writes of a migration are kept in memory until it's done.
+//
+// Data without a recorded version is at version 0, unless there is no data, in
+// which case it's at the latest version. Returns the versions of the data
+// before and after it was migrated.
+func Run(db database.Database, prefixes [][]byte, migrations []Migration) (uint64, uint64, error) {
+	if len(prefixes) == 0 {
+		return 0, 0, errNoPrefix
+	}
+	latest := uint64(0)
+	for _, migration := range migrations {
+		if migration.Version <= latest {
+			return 0, 0, errUnorderedMigrations
+		}
+		latest = migration.Version
+	}
+
+	data, versions := open(db, prefixes)
+	version, err := getVersion(versions)
+	switch {
+	case err == database.ErrNotFound:
+		empty, err := isEmpty(data)
+		if err != nil {
+			return 0, 0, err
+		}
+		version = 0
+		if empty {
+			return latest, latest, putVersion(versions, latest)
+		}
+	case err != nil:
+		return 0, 0, err
+	case version > latest:
+		return version, version, fmt.Errorf("%w: version %d > %d", errVersionTooNew, version, latest)
+	}
+
+	from := version
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		vdb := versiondb.New(db)
+		data, versions := open(vdb, prefixes)
+		if err := migration.Migrate(data); err != nil {
+			return from, version, fmt.Errorf("migration to version %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		if err := putVersion(versions, migration.Version); err != nil {
+			return from, version, err
+		}
+		if err := vdb.Commit(); err != nil {
+			return from, version, err
+		}
+		version = migration.Version
+	}
+	return from, version, nil
+}
+
+// open returns the database of the data under the nested [prefixes] in [db],
+// and the database its version is recorded in
+func open(db database.Database, prefixes [][]byte) (database.Database, database.Database) {
+	parent := db
+	for _, prefix := range prefixes[:len(prefixes)-1] {
+		parent = prefixdb.New(prefix, parent)
+	}
+	return prefixdb.New(prefixes[len(prefixes)-1], parent), prefixdb.New(versionPrefix, parent)
+}
+
+// Version returns the recorded schema version of the data stored in [db] under
+// the nested [prefixes], or database.ErrNotFound if there is none
+func Version(db database.Database, prefixes [][]byte) (uint64, error) {
+	if len(prefixes) == 0 {
+		return 0, errNoPrefix
+	}
+	_, versions := open(db, prefixes)
+	return getVersion(versions)
+}
+
+func getVersion(db database.Database) (uint64, error) {
+	versionBytes, err := db.Get(versionKey)
+	if err != nil {
+		return 0, err
+	}
+	p := wrappers.Packer{Bytes: versionBytes}
+	version := p.UnpackLong()
+	return version, p.Err
+}
+
+func putVersion(db database.Database, version uint64) error {
+	p := wrappers.Packer{MaxSize: wrappers.LongLen}
+	p.PackLong(version)
+	if p.Errored() {
+		return p.Err
+	}
+	return db.Put(versionKey, p.Bytes)
+}
+
+func isEmpty(db database.Iteratee) (bool, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	return !it.Next(), it.Error()
+}
@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migration
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+)
+
+var (
+	// The data is stored as a chain's VM stores it
+	prefixes = [][]byte{[]byte("chain"), []byte("vm")}
+	key      = []byte("key")
+)
+
+func dataDB(db database.Database) database.Database {
+	return prefixdb.New(prefixes[1], prefixdb.New(prefixes[0], db))
+}
+
+// appendMigration appends [suffix] to the value of key
+func appendMigration(version uint64, suffix byte) Migration {
+	return Migration{
+		Version: version,
+		Name:    "append",
+		Migrate: func(db database.Database) error {
+			value, err := db.Get(key)
+			if err != nil {
+				return err
+			}
+			return db.Put(key, append(value, suffix))
+		},
+	}
+}
+
+func assertValue(t *testing.T, db database.Database, expected []byte) {
+	value, err := dataDB(db).Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(value, expected) {
+		t.Fatalf("Value is 0x%x ; Expected 0x%x", value, expected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	db := memdb.New()
+	if err := dataDB(db).Put(key, []byte{0}); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations := []Migration{appendMigration(1, 1), appendMigration(3, 3)}
+	if from, to, err := Run(db, prefixes, migrations); err != nil {
+		t.Fatal(err)
+	} else if from != 0 || to != 3 {
+		t.Fatalf("Migrated from %d to %d ; Expected from 0 to 3", from, to)
+	}
+	assertValue(t, db, []byte{0, 1, 3})
+
+	// Only the new migrations are run
+	migrations = append(migrations, appendMigration(4, 4))
+	if from, to, err := Run(db, prefixes, migrations); err != nil {
+		t.Fatal(err)
+	} else if from != 3 || to != 4 {
+		t.Fatalf("Migrated from %d to %d ; Expected from 3 to 4", from, to)
+	}
+	assertValue(t, db, []byte{0, 1, 3, 4})
+
+	if _, _, err := Run(db, prefixes, migrations[:1]); !errors.Is(err, errVersionTooNew) {
+		t.Fatalf("Run returned %v ; Expected %s", err, errVersionTooNew)
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	db := memdb.New()
+	ran := false
+	migrations := []Migration{{
+		Version: 2,
+		Migrate: func(database.Database) error { ran = true; return nil },
+	}}
+	if from, to, err := Run(db, prefixes, migrations); err != nil {
+		t.Fatal(err)
+	} else if from != 2 || to != 2 {
+		t.Fatalf("Migrated from %d to %d ; Expected from 2 to 2", from, to)
+	}
+	if ran {
+		t.Fatalf("Migrations shouldn't run on an empty database")
+	}
+	if version, err := Version(db, prefixes); err != nil {
+		t.Fatal(err)
+	} else if version != 2 {
+		t.Fatalf("Version is %d ; Expected 2", version)
+	}
+}
+
+func TestRunFailed(t *testing.T) {
+	db := memdb.New()
+	if err := dataDB(db).Put(key, []byte{0}); err != nil {
+		t.Fatal(err)
+	}
+
+	errMigration := errors.New("migration failed")
+	migrations := []Migration{
+		appendMigration(1, 1),
+		{
+			Version: 2,
+			Migrate: func(db database.Database) error {
+				if err := db.Put(key, nil); err != nil {
+					return err
+				}
+				return errMigration
+			},
+		},
+	}
+	if _, to, err := Run(db, prefixes, migrations); !errors.Is(err, errMigration) {
+		t.Fatalf("Run returned %v ; Expected %s", err, errMigration)
+	} else if to != 1 {
+		t.Fatalf("Migrated to %d ; Expected 1", to)
+	}
+
+	// The failed migration wasn't written
+	assertValue(t, db, []byte{0, 1})
+	if version, err := Version(db, prefixes); err != nil {
+		t.Fatal(err)
+	} else if version != 1 {
+		t.Fatalf("Version is %d ; Expected 1", version)
+	}
+}
+
+func TestRunUnordered(t *testing.T) {
+	migrations := []Migration{appendMigration(2, 2), appendMigration(1, 1)}
+	if _, _, err := Run(memdb.New(), prefixes, migrations); err != errUnorderedMigrations {
+		t.Fatalf("Run returned %v ; Expected %s", err, errUnorderedMigrations)
+	}
+}
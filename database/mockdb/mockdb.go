@@ -25,6 +25,7 @@ type Database struct {
 	OnGet                           func([]byte) ([]byte, error)
 	OnPut                           func([]byte) error
 	OnDelete                        func([]byte) error
+	OnDeleteRange                   func([]byte, []byte) error
 	OnNewBatch                      func() database.Batch
 	OnNewIterator                   func() database.Iterator
 	OnNewIteratorWithStart          func([]byte) database.Iterator
@@ -67,6 +68,14 @@ func (db *Database) Delete(b []byte) error {
 	return db.OnDelete(b)
 }
 
+// DeleteRange implements the database.Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	if db.OnDeleteRange == nil {
+		return errNoFunction
+	}
+	return db.OnDeleteRange(start, end)
+}
+
 // NewBatch implements the database.Database interface
 func (db *Database) NewBatch() database.Batch {
 	if db.OnNewBatch == nil {
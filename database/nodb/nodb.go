@@ -22,6 +22,9 @@ func (*Database) Put(_ []byte, _ []byte) error { return database.ErrClosed }
 // Delete returns nil
 func (*Database) Delete([]byte) error { return database.ErrClosed }
 
+// DeleteRange returns nil
+func (*Database) DeleteRange(_, _ []byte) error { return database.ErrClosed }
+
 // NewBatch returns a new batch
 func (*Database) NewBatch() database.Batch { return &Batch{} }
 
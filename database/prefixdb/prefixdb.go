@@ -4,6 +4,7 @@
 package prefixdb
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/ava-labs/gecko/database"
@@ -11,6 +12,8 @@ import (
 	"github.com/ava-labs/gecko/utils/hashing"
 )
 
+var errNotDiskSizer = errors.New("the underlying database can't estimate its size on disk")
+
 // Database partitions a database into a sub-database by prefixing all keys with
 // a unique value.
 type Database struct {
@@ -40,6 +43,60 @@ func NewNested(prefix []byte, db database.Database) *Database {
 	}
 }
 
+// NewHashed returns the prefixed database whose keys are stored in [db] after
+// [hashedPrefix]. It can be used to open the databases whose prefixes are
+// returned by Prefixes.
+func NewHashed(hashedPrefix []byte, db database.Database) *Database {
+	return &Database{
+		dbPrefix: copyBytes(hashedPrefix),
+		db:       db,
+	}
+}
+
+// Drop removes every key of the database prefixed by [prefix] in [db]
+func Drop(prefix []byte, db database.Database) error { return New(prefix, db).Drop() }
+
+// Prefixes returns the hashed prefixes that keys are stored after in [db],
+// which are the prefixes of the databases created with NewNested on [db], or
+// with New if [db] isn't a prefixed database. Keys that are too short to have
+// a prefix are ignored. Each prefix is found with one seek, rather than by
+// iterating over its keys.
+func Prefixes(db database.Iteratee) ([][]byte, error) {
+	prefixes := [][]byte(nil)
+	start := []byte(nil)
+	for {
+		it := db.NewIteratorWithStart(start)
+		found := false
+		for it.Next() {
+			if key := it.Key(); len(key) >= hashing.HashLen {
+				prefixes = append(prefixes, copyBytes(key[:hashing.HashLen]))
+				found = true
+				break
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return prefixes, nil
+		}
+
+		// Skip the rest of the keys with this prefix
+		if start = prefixEnd(prefixes[len(prefixes)-1]); start == nil {
+			return prefixes, nil
+		}
+	}
+}
+
+// Prefix returns the hashed prefix the keys of this database are stored after
+func (db *Database) Prefix() []byte { return copyBytes(db.dbPrefix) }
+
+// Drop removes every key of this database. The keys are removed atomically if
+// the underlying database deletes ranges natively, and in batches otherwise.
+func (db *Database) Drop() error { return db.DeleteRange(nil, nil) }
+
 // Has implements the Database interface
 func (db *Database) Has(key []byte) (bool, error) {
 	db.lock.RLock()
@@ -84,6 +141,24 @@ func (db *Database) Delete(key []byte) error {
 	return db.db.Delete(db.prefix(key))
 }
 
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+
+	// A nil end is after every key of this database, which is the first key
+	// after every key with its prefix
+	prefixedEnd := db.prefix(end)
+	if end == nil {
+		prefixedEnd = prefixEnd(db.dbPrefix)
+	}
+	return db.db.DeleteRange(db.prefix(start), prefixedEnd)
+}
+
 // NewBatch implements the Database interface
 func (db *Database) NewBatch() database.Batch {
 	return &batch{
@@ -138,7 +213,23 @@ func (db *Database) Compact(start, limit []byte) error {
 	if db.db == nil {
 		return database.ErrClosed
 	}
-	return db.db.Compact(db.prefix(start), db.prefix(limit))
+	return db.db.Compact(db.prefix(start), db.limit(limit))
+}
+
+// DiskSize implements the database.DiskSizer interface, if the underlying
+// database does
+func (db *Database) DiskSize(start, limit []byte) (uint64, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return 0, database.ErrClosed
+	}
+	sizer, ok := db.db.(database.DiskSizer)
+	if !ok {
+		return 0, errNotDiskSizer
+	}
+	return sizer.DiskSize(db.prefix(start), db.limit(limit))
 }
 
 // Close implements the Database interface
@@ -153,6 +244,28 @@ func (db *Database) Close() error {
 	return nil
 }
 
+// prefixEnd returns the smallest key that is greater than every key that
+// starts with [prefix], or nil if there is no such key
+func prefixEnd(prefix []byte) []byte {
+	end := copyBytes(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// limit returns the key of the underlying database that [limit] is stored at.
+// A nil limit is after every key of this database.
+func (db *Database) limit(limit []byte) []byte {
+	if limit == nil {
+		return prefixEnd(db.dbPrefix)
+	}
+	return db.prefix(limit)
+}
+
 func (db *Database) prefix(key []byte) []byte {
 	prefixedKey := make([]byte, len(db.dbPrefix)+len(key))
 	copy(prefixedKey, db.dbPrefix)
@@ -216,6 +329,9 @@ func (b *batch) Replay(w database.KeyValueWriter) error {
 	return nil
 }
 
+// Inner implements the BatchWrapper interface
+func (b *batch) Inner() database.Batch { return b.Batch }
+
 type iterator struct {
 	database.Iterator
 	db *Database
@@ -4,14 +4,15 @@
 package prefixdb
 
 import (
+	"bytes"
 	"testing"
 
-	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
 	"github.com/ava-labs/gecko/database/memdb"
 )
 
 func TestInterface(t *testing.T) {
-	for _, test := range database.Tests {
+	for _, test := range dbtest.Tests {
 		db := memdb.New()
 		test(t, New([]byte("hello"), db))
 		test(t, New([]byte("world"), db))
@@ -21,3 +22,89 @@ func TestInterface(t *testing.T) {
 		test(t, NewNested([]byte("ld"), New([]byte("wor"), db)))
 	}
 }
+
+func TestDrop(t *testing.T) {
+	db := memdb.New()
+	dropped := New([]byte("hello"), db)
+	kept := New([]byte("world"), db)
+	for _, key := range [][]byte{{}, {0x00}, {0xff, 0xff}} {
+		if err := dropped.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+		if err := kept.Put(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Drop([]byte("hello"), db); err != nil {
+		t.Fatal(err)
+	}
+
+	it := dropped.NewIterator()
+	if it.Next() {
+		t.Fatalf("Dropped database should be empty")
+	}
+	it.Release()
+
+	it = kept.NewIterator()
+	keys := 0
+	for it.Next() {
+		keys++
+	}
+	it.Release()
+	if keys != 3 {
+		t.Fatalf("Other database should have 3 keys, but had %d", keys)
+	}
+}
+
+func TestPrefixes(t *testing.T) {
+	db := memdb.New()
+	hello := New([]byte("hello"), db)
+	world := New([]byte("world"), db)
+	nested := NewNested([]byte("nested"), world)
+	for _, prefixDB := range []*Database{hello, world, nested} {
+		for _, key := range [][]byte{{0x00}, {0x01}} {
+			if err := prefixDB.Put(key, key); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// Keys without a prefix are ignored
+	if err := db.Put([]byte{0x01}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes, err := Prefixes(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefixes) != 2 {
+		t.Fatalf("Should have found 2 prefixes, but found %d", len(prefixes))
+	}
+	for _, prefixDB := range []*Database{hello, world} {
+		found := false
+		for _, prefix := range prefixes {
+			found = found || bytes.Equal(prefix, prefixDB.Prefix())
+		}
+		if !found {
+			t.Fatalf("Should have found prefix 0x%x", prefixDB.Prefix())
+		}
+	}
+
+	nestedPrefixes, err := Prefixes(world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nestedPrefixes) != 1 || !bytes.Equal(nestedPrefixes[0], nested.Prefix()) {
+		t.Fatalf("Should have found the nested prefix")
+	}
+
+	if err := NewHashed(hello.Prefix(), db).Drop(); err != nil {
+		t.Fatal(err)
+	}
+	if prefixes, err := Prefixes(db); err != nil {
+		t.Fatal(err)
+	} else if len(prefixes) != 1 || !bytes.Equal(prefixes[0], world.Prefix()) {
+		t.Fatalf("Only the world prefix should remain")
+	}
+}
@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package database
+
+import (
+	"bytes"
+)
+
+// deleteRangeBatchSize is the number of keys BatchDeleteRange deletes per
+// batch
+const deleteRangeBatchSize = 1024
+
+// Clear removes every key from [db]
+func Clear(db RangeDeleter) error { return db.DeleteRange(nil, nil) }
+
+// BatchDeleteRange removes the keys of [db] in [start, end) by iterating over
+// them and writing their deletions in batches. It implements DeleteRange for
+// data stores that can't delete a range of keys natively. If it fails, some of
+// the keys may have been removed.
+func BatchDeleteRange(db interface {
+	Iteratee
+	Batcher
+}, start, end []byte) error {
+	it := db.NewIteratorWithStart(start)
+	defer it.Release()
+
+	batch := db.NewBatch()
+	for keys := 0; it.Next(); keys++ {
+		key := it.Key()
+		if end != nil && bytes.Compare(key, end) >= 0 {
+			break
+		}
+		if keys == deleteRangeBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+			keys = 0
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
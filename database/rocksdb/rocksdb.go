@@ -0,0 +1,553 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build rocksdb
+// +build rocksdb
+
+// Package rocksdb implements the Database interface on top of RocksDB. It
+// links against the RocksDB C library, so it's only built with the rocksdb
+// build tag.
+package rocksdb
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/tecbot/gorocksdb"
+)
+
+const (
+	// LevelCompaction compacts the database in levels of increasing size. It
+	// has the lowest space and read amplification.
+	LevelCompaction = "level"
+
+	// UniversalCompaction compacts sorted runs of similar sizes together. It
+	// has lower write amplification, so it stalls less under heavy writes, at
+	// the cost of using more space.
+	UniversalCompaction = "universal"
+
+	// defaultColumnFamily is the column family that every database has
+	defaultColumnFamily = "default"
+
+	// minBlockCacheSize is the minimum number of bytes to use for block caching
+	minBlockCacheSize = 8 * 1024 * 1024
+)
+
+// Config of a RocksDB database
+type Config struct {
+	// Number of bytes of uncompressed blocks cached in memory
+	BlockCacheSize int
+	// Number of bits per key of the bloom filters of the tables. If zero,
+	// tables don't have bloom filters.
+	BloomFilterBits int
+	// One of {LevelCompaction, UniversalCompaction}
+	CompactionStyle string
+}
+
+// DefaultConfig is the default configuration of a RocksDB database
+var DefaultConfig = Config{
+	BlockCacheSize:  512 * 1024 * 1024,
+	BloomFilterBits: 10,
+	CompactionStyle: LevelCompaction,
+}
+
+// store is a RocksDB database shared by the column families opened from it
+type store struct {
+	lock sync.RWMutex
+	db   *gorocksdb.DB
+
+	options      *gorocksdb.Options
+	tableOptions *gorocksdb.BlockBasedTableOptions
+	cache        *gorocksdb.Cache
+	readOptions  *gorocksdb.ReadOptions
+	writeOptions *gorocksdb.WriteOptions
+
+	// Column family name --> handle
+	families map[string]*gorocksdb.ColumnFamilyHandle
+	// Number of Databases that haven't been closed. The store is closed when
+	// the last one is.
+	numOpen int
+	// Iterators that haven't been released, which are released when the store
+	// is closed
+	iterators map[*iterator]struct{}
+}
+
+// Database is a column family of a RocksDB database. Apart from basic data
+// storage functionality it also supports batch writes and iterating over the
+// keyspace in binary-alphabetical order.
+type Database struct {
+	store  *store
+	family *gorocksdb.ColumnFamilyHandle
+	closed bool
+}
+
+// New opens the RocksDB database stored in [dir], creating it if it doesn't
+// exist, and returns its default column family
+func New(dir string, config Config) (*Database, error) {
+	options, err := newOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	tableOptions, cache := newTableOptions(config)
+	options.SetBlockBasedTableFactory(tableOptions)
+
+	// Every column family of the database must be opened
+	names, err := gorocksdb.ListColumnFamilies(options, dir)
+	if err != nil {
+		// The database doesn't exist yet
+		names = []string{defaultColumnFamily}
+	}
+	familyOptions := make([]*gorocksdb.Options, len(names))
+	for i := range familyOptions {
+		familyOptions[i] = options
+	}
+	db, handles, err := gorocksdb.OpenDbColumnFamilies(options, dir, names, familyOptions)
+	if err != nil {
+		options.Destroy()
+		tableOptions.Destroy()
+		cache.Destroy()
+		return nil, err
+	}
+
+	s := &store{
+		db:           db,
+		options:      options,
+		tableOptions: tableOptions,
+		cache:        cache,
+		readOptions:  gorocksdb.NewDefaultReadOptions(),
+		writeOptions: gorocksdb.NewDefaultWriteOptions(),
+		families:     make(map[string]*gorocksdb.ColumnFamilyHandle, len(names)),
+		numOpen:      1,
+		iterators:    make(map[*iterator]struct{}),
+	}
+	for i, name := range names {
+		s.families[name] = handles[i]
+	}
+	return &Database{
+		store:  s,
+		family: s.families[defaultColumnFamily],
+	}, nil
+}
+
+func newOptions(config Config) (*gorocksdb.Options, error) {
+	options := gorocksdb.NewDefaultOptions()
+	options.SetCreateIfMissing(true)
+
+	switch config.CompactionStyle {
+	case LevelCompaction, "":
+		options.SetCompactionStyle(gorocksdb.LevelCompactionStyle)
+	case UniversalCompaction:
+		options.SetCompactionStyle(gorocksdb.UniversalCompactionStyle)
+	default:
+		options.Destroy()
+		return nil, fmt.Errorf("unknown compaction style %q. Should be one of {%s, %s}",
+			config.CompactionStyle, LevelCompaction, UniversalCompaction)
+	}
+	return options, nil
+}
+
+func newTableOptions(config Config) (*gorocksdb.BlockBasedTableOptions, *gorocksdb.Cache) {
+	blockCacheSize := config.BlockCacheSize
+	if blockCacheSize < minBlockCacheSize {
+		blockCacheSize = minBlockCacheSize
+	}
+	cache := gorocksdb.NewLRUCache(uint64(blockCacheSize))
+
+	tableOptions := gorocksdb.NewDefaultBlockBasedTableOptions()
+	tableOptions.SetBlockCache(cache)
+	if config.BloomFilterBits > 0 {
+		tableOptions.SetFilterPolicy(gorocksdb.NewBloomFilter(config.BloomFilterBits))
+	}
+	return tableOptions, cache
+}
+
+// ColumnFamily returns the column family [name] of this database, creating it
+// if it doesn't exist. Column families have separate keyspaces. The returned
+// Database must be closed separately, and the underlying database is closed
+// once all of its column families are.
+func (db *Database) ColumnFamily(name string) (*Database, error) {
+	s := db.store
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	family, exists := s.families[name]
+	if !exists {
+		var err error
+		family, err = s.db.CreateColumnFamily(s.options, name)
+		if err != nil {
+			return nil, err
+		}
+		s.families[name] = family
+	}
+	s.numOpen++
+	return &Database{
+		store:  s,
+		family: family,
+	}, nil
+}
+
+// Has returns if the key is set in the database
+func (db *Database) Has(key []byte) (bool, error) {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+	value, err := s.db.GetCF(s.readOptions, db.family, key)
+	if err != nil {
+		return false, err
+	}
+	defer value.Free()
+	return value.Exists(), nil
+}
+
+// Get returns the value the key maps to in the database
+func (db *Database) Get(key []byte) ([]byte, error) {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	value, err := s.db.GetCF(s.readOptions, db.family, key)
+	if err != nil {
+		return nil, err
+	}
+	defer value.Free()
+	if !value.Exists() {
+		return nil, database.ErrNotFound
+	}
+	return copyBytes(value.Data()), nil
+}
+
+// Put sets the value of the provided key to the provided value
+func (db *Database) Put(key []byte, value []byte) error {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return s.db.PutCF(s.writeOptions, db.family, key, value)
+}
+
+// Delete removes the key from the database
+func (db *Database) Delete(key []byte) error {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return s.db.DeleteCF(s.writeOptions, db.family, key)
+}
+
+// DeleteRange removes the keys in [start, end) from the database with a range
+// tombstone, without reading them
+func (db *Database) DeleteRange(start, end []byte) error {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	if start == nil {
+		start = []byte{}
+	}
+	if end == nil {
+		// A range tombstone needs an end, so the last key is deleted on its own
+		it := s.db.NewIteratorCF(s.readOptions, db.family)
+		defer it.Close()
+
+		it.SeekToLast()
+		if !it.Valid() {
+			return it.Err()
+		}
+		last := it.Key()
+		end = copyBytes(last.Data())
+		last.Free()
+		if bytes.Compare(end, start) < 0 {
+			return nil
+		}
+		wb.DeleteCF(db.family, end)
+	}
+	wb.DeleteRangeCF(db.family, start, end)
+	return s.db.Write(s.writeOptions, wb)
+}
+
+// NewBatch creates a write/delete-only buffer that is atomically committed to
+// the database when write is called
+func (db *Database) NewBatch() database.Batch { return &batch{db: db} }
+
+// NewIterator creates a lexicographically ordered iterator over the database
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart creates a lexicographically ordered iterator over the
+// database starting at the provided key
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix creates a lexicographically ordered iterator over the
+// database ignoring keys that do not start with the provided prefix
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix creates a lexicographically ordered iterator
+// over the database starting at start and ignoring keys that do not start with
+// the provided prefix
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	s := db.store
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if db.closed {
+		return &nodb.Iterator{Err: database.ErrClosed}
+	}
+
+	// The iterator reads from an implicit snapshot of the database
+	it := &iterator{
+		store:  s,
+		it:     s.db.NewIteratorCF(s.readOptions, db.family),
+		prefix: copyBytes(prefix),
+	}
+	if bytes.Compare(start, prefix) == 1 {
+		it.it.Seek(start)
+	} else {
+		it.it.Seek(prefix)
+	}
+	s.iterators[it] = struct{}{}
+	return it
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *Database) Stat(property string) (string, error) {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return "", database.ErrClosed
+	}
+	if stat := s.db.GetPropertyCF(property, db.family); stat != "" {
+		return stat, nil
+	}
+	return "", database.ErrNotFound
+}
+
+// Compact the underlying DB for the given key range. A nil start is treated as
+// a key before all keys in the DB, and a nil limit is treated as a key after
+// all keys in the DB.
+func (db *Database) Compact(start []byte, limit []byte) error {
+	s := db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	s.db.CompactRangeCF(db.family, gorocksdb.Range{Start: start, Limit: limit})
+	return nil
+}
+
+// Close implements the Database interface
+func (db *Database) Close() error {
+	s := db.store
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	db.closed = true
+	s.numOpen--
+	if s.numOpen > 0 {
+		return nil
+	}
+
+	for it := range s.iterators {
+		it.release()
+	}
+	for _, family := range s.families {
+		family.Destroy()
+	}
+	s.db.Close()
+	s.readOptions.Destroy()
+	s.writeOptions.Destroy()
+	s.options.Destroy()
+	s.tableOptions.Destroy()
+	s.cache.Destroy()
+	return nil
+}
+
+type keyValue struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type batch struct {
+	db     *Database
+	writes []keyValue
+	size   int
+}
+
+// Put the value into the batch for later writing
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), copyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+// Delete the key during writing
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyValue{copyBytes(key), nil, true})
+	b.size++
+	return nil
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int { return b.size }
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	s := b.db.store
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+
+	wb := gorocksdb.NewWriteBatch()
+	defer wb.Destroy()
+
+	for _, kv := range b.writes {
+		if kv.delete {
+			wb.DeleteCF(b.db.family, kv.key)
+		} else {
+			wb.PutCF(b.db.family, kv.key, kv.value)
+		}
+	}
+	return s.db.Write(s.writeOptions, wb)
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay the batch contents.
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	for _, kv := range b.writes {
+		if kv.delete {
+			if err := w.Delete(kv.key); err != nil {
+				return err
+			}
+		} else if err := w.Put(kv.key, kv.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type iterator struct {
+	store  *store
+	it     *gorocksdb.Iterator
+	prefix []byte
+
+	initialized bool
+	key, value  []byte
+	err         error
+}
+
+// Next moves the iterator to the next key/value pair. It returns whether the
+// iterator is exhausted.
+func (it *iterator) Next() bool {
+	it.store.lock.RLock()
+	defer it.store.lock.RUnlock()
+
+	if it.it == nil {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+	if it.initialized {
+		it.it.Next()
+	}
+	it.initialized = true
+
+	if !it.it.ValidForPrefix(it.prefix) {
+		it.err = it.it.Err()
+		it.key = nil
+		it.value = nil
+		return false
+	}
+	key := it.it.Key()
+	it.key = copyBytes(key.Data())
+	key.Free()
+	value := it.it.Value()
+	it.value = copyBytes(value.Data())
+	value.Free()
+	return true
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error {
+	it.store.lock.RLock()
+	defer it.store.lock.RUnlock()
+
+	return it.err
+}
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte { return it.key }
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte { return it.value }
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	it.store.lock.Lock()
+	defer it.store.lock.Unlock()
+
+	it.release()
+}
+
+// Assumes the store's lock is held
+func (it *iterator) release() {
+	if it.it == nil {
+		return
+	}
+	it.it.Close()
+	it.it = nil
+	it.key = nil
+	it.value = nil
+	delete(it.store.iterators, it)
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
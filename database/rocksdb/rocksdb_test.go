@@ -0,0 +1,92 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build rocksdb
+// +build rocksdb
+
+package rocksdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/dbtest"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		folder, err := ioutil.TempDir("", "rocksdb")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(folder)
+
+		db, err := New(folder, DefaultConfig)
+		if err != nil {
+			t.Fatalf("rocksdb.New(%s) errored with %s", folder, err)
+		}
+		defer db.Close()
+
+		test(t, db)
+	}
+}
+
+func TestColumnFamilies(t *testing.T) {
+	folder, err := ioutil.TempDir("", "rocksdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	db, err := New(folder, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	family, err := db.ColumnFamily("family")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := []byte("hello")
+	value := []byte("world")
+	if err := family.Put(key, value); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := db.Has(key); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Column families should have separate keyspaces")
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The column family is still open
+	if v, err := family.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("family.Get: Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+	if err := family.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Column families are kept when the database is reopened
+	db, err = New(folder, DefaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	family, err = db.ColumnFamily("family")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer family.Close()
+	if v, err := family.Get(key); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("family.Get: Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+}
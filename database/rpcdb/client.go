@@ -0,0 +1,290 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/ava-labs/gecko/utils/retry"
+	"github.com/ava-labs/gecko/utils/rpc/jsoncodec"
+)
+
+// callRetry describes how a call that failed because the server was
+// unavailable, such as while the connection to it is re-established, is
+// retried. Other failures aren't retried.
+var callRetry = retry.Config{
+	MaxAttempts:  8,
+	InitialDelay: 50 * time.Millisecond,
+	MaxDelay:     time.Second,
+	Jitter:       .2,
+}
+
+// DatabaseClient is a database served over gRPC by a DatabaseServer
+type DatabaseClient struct{ conn *grpc.ClientConn }
+
+// NewClient returns the database served over [conn]. Closing the database
+// closes the served database, but not the connection.
+func NewClient(conn *grpc.ClientConn) *DatabaseClient { return &DatabaseClient{conn: conn} }
+
+// call the method [name] of the database service. The call is retried while
+// the server is unavailable.
+func (db *DatabaseClient) call(name string, req, reply interface{}) error {
+	return retry.Do(context.Background(), callRetry, func() error {
+		err := db.conn.Invoke(
+			context.Background(),
+			"/"+DatabaseService+"/"+name,
+			req,
+			reply,
+			grpc.CallContentSubtype(jsoncodec.Name),
+		)
+		if status.Code(err) != codes.Unavailable {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
+
+// Has implements the Database interface
+func (db *DatabaseClient) Has(key []byte) (bool, error) {
+	reply := &hasReply{}
+	if err := db.call("Has", &keyRequest{Key: key}, reply); err != nil {
+		return false, err
+	}
+	return reply.Has, codeError(reply.Err)
+}
+
+// Get implements the Database interface
+func (db *DatabaseClient) Get(key []byte) ([]byte, error) {
+	reply := &getReply{}
+	if err := db.call("Get", &keyRequest{Key: key}, reply); err != nil {
+		return nil, err
+	}
+	if err := codeError(reply.Err); err != nil {
+		return nil, err
+	}
+	if reply.Value == nil {
+		// Empty values are decoded as nil
+		reply.Value = []byte{}
+	}
+	return reply.Value, nil
+}
+
+// Put implements the Database interface
+func (db *DatabaseClient) Put(key, value []byte) error {
+	reply := &errReply{}
+	if err := db.call("Put", &putRequest{Key: key, Value: value}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// Delete implements the Database interface
+func (db *DatabaseClient) Delete(key []byte) error {
+	reply := &errReply{}
+	if err := db.call("Delete", &keyRequest{Key: key}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// DeleteRange implements the Database interface
+func (db *DatabaseClient) DeleteRange(start, end []byte) error {
+	reply := &errReply{}
+	if err := db.call("DeleteRange", &deleteRangeRequest{Start: start, End: end}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// NewBatch implements the Database interface
+func (db *DatabaseClient) NewBatch() database.Batch { return &batch{db: db} }
+
+// NewIterator implements the Database interface
+func (db *DatabaseClient) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *DatabaseClient) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *DatabaseClient) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface. The
+// iterator fetches its keys and values from the server in pages.
+func (db *DatabaseClient) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	reply := &iteratorRequest{}
+	if err := db.call("NewIterator", &newIteratorRequest{Start: start, Prefix: prefix}, reply); err != nil {
+		return &nodb.Iterator{Err: err}
+	}
+	return &iterator{db: db, id: reply.ID}
+}
+
+// Stat implements the Database interface
+func (db *DatabaseClient) Stat(property string) (string, error) {
+	reply := &statReply{}
+	if err := db.call("Stat", &statRequest{Property: property}, reply); err != nil {
+		return "", err
+	}
+	return reply.Stat, codeError(reply.Err)
+}
+
+// Compact implements the Database interface
+func (db *DatabaseClient) Compact(start, limit []byte) error {
+	reply := &errReply{}
+	if err := db.call("Compact", &compactRequest{Start: start, Limit: limit}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// Close implements the Database interface
+func (db *DatabaseClient) Close() error {
+	reply := &errReply{}
+	if err := db.call("Close", &emptyRequest{}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+type batch struct {
+	db     *DatabaseClient
+	writes []keyValue
+	size   int
+}
+
+// Put implements the Batch interface
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, keyValue{Key: copyBytes(key), Value: copyBytes(value)})
+	b.size += len(value)
+	return nil
+}
+
+// Delete implements the Batch interface
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, keyValue{Key: copyBytes(key), Delete: true})
+	b.size++
+	return nil
+}
+
+// ValueSize implements the Batch interface
+func (b *batch) ValueSize() int { return b.size }
+
+// Write implements the Batch interface. The writes are sent in one request,
+// and written to the served database in one batch.
+func (b *batch) Write() error {
+	reply := &errReply{}
+	if err := b.db.call("WriteBatch", &writeBatchRequest{Writes: b.writes}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// Reset implements the Batch interface
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay implements the Batch interface
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	for _, kv := range b.writes {
+		if kv.Delete {
+			if err := w.Delete(kv.Key); err != nil {
+				return err
+			}
+		} else if err := w.Put(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type iterator struct {
+	db *DatabaseClient
+	id uint64
+
+	// The fetched keys and values that haven't been iterated over
+	data       []keyValue
+	key, value []byte
+
+	exhausted, released bool
+	err                 error
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	if len(it.data) == 0 && !it.exhausted && !it.released {
+		reply := &iteratorNextReply{}
+		if err := it.db.call("IteratorNext", &iteratorRequest{ID: it.id}, reply); err != nil {
+			it.err = err
+		}
+		it.data = reply.Data
+		it.exhausted = len(it.data) == 0
+	}
+	if len(it.data) == 0 {
+		it.key = nil
+		it.value = nil
+		return false
+	}
+
+	it.key = it.data[0].Key
+	it.value = it.data[0].Value
+	if it.value == nil {
+		it.value = []byte{}
+	}
+	it.data[0] = keyValue{}
+	it.data = it.data[1:]
+	return true
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error {
+	if it.err != nil || it.released {
+		return it.err
+	}
+	reply := &errReply{}
+	if err := it.db.call("IteratorError", &iteratorRequest{ID: it.id}, reply); err != nil {
+		return err
+	}
+	return codeError(reply.Err)
+}
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte { return it.key }
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte { return it.value }
+
+// Release implements the Iterator interface
+func (it *iterator) Release() {
+	if it.released {
+		return
+	}
+	it.released = true
+	it.data = nil
+	it.key = nil
+	it.value = nil
+
+	// The server releases the iterators of a closed database itself, so a
+	// failure to release it can't leak it for long
+	_ = it.db.call("IteratorRelease", &iteratorRequest{ID: it.id}, &errReply{})
+}
+
+func copyBytes(bytes []byte) []byte {
+	copiedBytes := make([]byte, len(bytes))
+	copy(copiedBytes, bytes)
+	return copiedBytes
+}
@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+// Errors that are sent as codes, so the client can return the same errors as
+// the served database
+const (
+	errorNone uint32 = iota
+	errorClosed
+	errorNotFound
+)
+
+var errUnknownIterator = errors.New("unknown iterator")
+
+type keyRequest struct {
+	Key []byte `json:"key"`
+}
+
+type hasReply struct {
+	Has bool   `json:"has"`
+	Err uint32 `json:"err"`
+}
+
+type getReply struct {
+	Value []byte `json:"value"`
+	Err   uint32 `json:"err"`
+}
+
+type putRequest struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type deleteRangeRequest struct {
+	Start []byte `json:"start"`
+	End   []byte `json:"end"`
+}
+
+type errReply struct {
+	Err uint32 `json:"err"`
+}
+
+type statRequest struct {
+	Property string `json:"property"`
+}
+
+type statReply struct {
+	Stat string `json:"stat"`
+	Err  uint32 `json:"err"`
+}
+
+type compactRequest struct {
+	Start []byte `json:"start"`
+	Limit []byte `json:"limit"`
+}
+
+type emptyRequest struct{}
+
+type keyValue struct {
+	Key    []byte `json:"key"`
+	Value  []byte `json:"value"`
+	Delete bool   `json:"delete"`
+}
+
+type writeBatchRequest struct {
+	Writes []keyValue `json:"writes"`
+}
+
+type newIteratorRequest struct {
+	Start  []byte `json:"start"`
+	Prefix []byte `json:"prefix"`
+}
+
+type iteratorRequest struct {
+	ID uint64 `json:"id"`
+}
+
+type iteratorNextReply struct {
+	// Empty once the iterator is exhausted
+	Data []keyValue `json:"data"`
+}
+
+// errorCode returns the code of [err], if it's sent as a code. Other errors
+// are returned, to be sent as gRPC errors.
+func errorCode(err error) (uint32, error) {
+	switch err {
+	case nil:
+		return errorNone, nil
+	case database.ErrClosed:
+		return errorClosed, nil
+	case database.ErrNotFound:
+		return errorNotFound, nil
+	default:
+		return errorNone, err
+	}
+}
+
+// codeError returns the error that [code] was sent for
+func codeError(code uint32) error {
+	switch code {
+	case errorClosed:
+		return database.ErrClosed
+	case errorNotFound:
+		return database.ErrNotFound
+	default:
+		return nil
+	}
+}
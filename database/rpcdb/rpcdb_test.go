@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/retry"
+)
+
+// serve [db] and return a client of it
+func serve(t *testing.T, db database.Database) (*DatabaseClient, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterDatabaseServer(server, NewServer(db))
+	go server.Serve(listener)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		db, stop := serve(t, memdb.New())
+		test(t, db)
+		stop()
+	}
+}
+
+func TestIteratorPages(t *testing.T) {
+	baseDB := memdb.New()
+	db, stop := serve(t, baseDB)
+	defer stop()
+
+	// The values span several pages
+	value := make([]byte, maxPageSize/4)
+	numKeys := 10
+	for i := 0; i < numKeys; i++ {
+		if err := baseDB.Put([]byte(fmt.Sprintf("key%d", i)), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	iterator := db.NewIterator()
+	defer iterator.Release()
+	for i := 0; i < numKeys; i++ {
+		if !iterator.Next() {
+			t.Fatalf("iterator.Next Returned: %v ; Expected: %v", false, true)
+		} else if key, expected := iterator.Key(), []byte(fmt.Sprintf("key%d", i)); !bytes.Equal(key, expected) {
+			t.Fatalf("iterator.Key Returned: 0x%x ; Expected: 0x%x", key, expected)
+		} else if v := iterator.Value(); !bytes.Equal(v, value) {
+			t.Fatalf("iterator.Value returned a value of length %d ; Expected: %d", len(v), len(value))
+		}
+	}
+	if iterator.Next() {
+		t.Fatalf("iterator.Next Returned: %v ; Expected: %v", true, false)
+	} else if err := iterator.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryUnavailable(t *testing.T) {
+	defer func(config retry.Config) { callRetry = config }(callRetry)
+	callRetry = retry.Config{MaxAttempts: 20, InitialDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}
+
+	// Reserve an address that nothing listens on yet
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithConnectParams(grpc.ConnectParams{
+		Backoff: backoff.Config{BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	db := NewClient(conn)
+
+	// The server is down for every attempt
+	if _, err := db.Has([]byte("key")); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Should have failed with %s but got %v", codes.Unavailable, err)
+	}
+
+	// The server comes up while the call is being retried
+	baseDB := memdb.New()
+	if err := baseDB.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterDatabaseServer(server, NewServer(baseDB))
+	defer server.Stop()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		server.Serve(listener)
+	}()
+	if value, err := db.Get([]byte("key")); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", value, []byte("value"))
+	}
+}
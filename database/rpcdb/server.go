@@ -0,0 +1,242 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcdb
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+const (
+	// DatabaseService is the name of the gRPC service a database is served as.
+	// Its messages are encoded as JSON, with the content subtype "json".
+	DatabaseService = "gecko.rpcdb.Database"
+
+	// maxPageSize is the number of bytes of keys and values after which a
+	// page of an iterator is sent
+	maxPageSize = 512 * 1024
+)
+
+// DatabaseServer serves a database over gRPC
+type DatabaseServer struct {
+	db database.Database
+
+	lock           sync.Mutex
+	nextIteratorID uint64
+	iterators      map[uint64]database.Iterator
+}
+
+// NewServer returns a server of [db]
+func NewServer(db database.Database) *DatabaseServer {
+	return &DatabaseServer{
+		db:        db,
+		iterators: make(map[uint64]database.Iterator),
+	}
+}
+
+// RegisterDatabaseServer registers [srv] as the database served by [s]
+func RegisterDatabaseServer(s *grpc.Server, srv *DatabaseServer) {
+	s.RegisterService(&databaseServiceDesc, srv)
+}
+
+func (s *DatabaseServer) has(req *keyRequest) (*hasReply, error) {
+	has, err := s.db.Has(req.Key)
+	code, err := errorCode(err)
+	return &hasReply{Has: has, Err: code}, err
+}
+
+func (s *DatabaseServer) get(req *keyRequest) (*getReply, error) {
+	value, err := s.db.Get(req.Key)
+	code, err := errorCode(err)
+	return &getReply{Value: value, Err: code}, err
+}
+
+func (s *DatabaseServer) put(req *putRequest) (*errReply, error) {
+	code, err := errorCode(s.db.Put(req.Key, req.Value))
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) delete(req *keyRequest) (*errReply, error) {
+	code, err := errorCode(s.db.Delete(req.Key))
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) deleteRange(req *deleteRangeRequest) (*errReply, error) {
+	code, err := errorCode(s.db.DeleteRange(req.Start, req.End))
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) stat(req *statRequest) (*statReply, error) {
+	stat, err := s.db.Stat(req.Property)
+	code, err := errorCode(err)
+	return &statReply{Stat: stat, Err: code}, err
+}
+
+func (s *DatabaseServer) compact(req *compactRequest) (*errReply, error) {
+	code, err := errorCode(s.db.Compact(req.Start, req.Limit))
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) close(*emptyRequest) (*errReply, error) {
+	s.lock.Lock()
+	for id, it := range s.iterators {
+		it.Release()
+		delete(s.iterators, id)
+	}
+	s.lock.Unlock()
+
+	code, err := errorCode(s.db.Close())
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) writeBatch(req *writeBatchRequest) (*errReply, error) {
+	batch := s.db.NewBatch()
+	for _, kv := range req.Writes {
+		var err error
+		if kv.Delete {
+			err = batch.Delete(kv.Key)
+		} else {
+			err = batch.Put(kv.Key, kv.Value)
+		}
+		if code, err := errorCode(err); code != errorNone || err != nil {
+			return &errReply{Err: code}, err
+		}
+	}
+	code, err := errorCode(batch.Write())
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) newIterator(req *newIteratorRequest) (*iteratorRequest, error) {
+	it := s.db.NewIteratorWithStartAndPrefix(req.Start, req.Prefix)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := s.nextIteratorID
+	s.nextIteratorID++
+	s.iterators[id] = it
+	return &iteratorRequest{ID: id}, nil
+}
+
+func (s *DatabaseServer) iterator(id uint64) (database.Iterator, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	it, exists := s.iterators[id]
+	if !exists {
+		return nil, errUnknownIterator
+	}
+	return it, nil
+}
+
+func (s *DatabaseServer) iteratorNext(req *iteratorRequest) (*iteratorNextReply, error) {
+	it, err := s.iterator(req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &iteratorNextReply{}
+	for size := 0; size < maxPageSize && it.Next(); {
+		// The iterator may reuse the memory of its keys and values
+		key := copyBytes(it.Key())
+		value := copyBytes(it.Value())
+		reply.Data = append(reply.Data, keyValue{Key: key, Value: value})
+		size += len(key) + len(value)
+	}
+	return reply, nil
+}
+
+func (s *DatabaseServer) iteratorError(req *iteratorRequest) (*errReply, error) {
+	it, err := s.iterator(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	code, err := errorCode(it.Error())
+	return &errReply{Err: code}, err
+}
+
+func (s *DatabaseServer) iteratorRelease(req *iteratorRequest) (*errReply, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if it, exists := s.iterators[req.ID]; exists {
+		it.Release()
+		delete(s.iterators, req.ID)
+	}
+	return &errReply{}, nil
+}
+
+// method returns the description of the method [name], which decodes its
+// requests into the values returned by [newRequest] and handles them with
+// [handle]
+func method(name string, newRequest func() interface{}, handle func(*DatabaseServer, interface{}) (interface{}, error)) grpc.MethodDesc {
+	fullMethod := "/" + DatabaseService + "/" + name
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newRequest()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handle(srv.(*DatabaseServer), req)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+		},
+	}
+}
+
+var databaseServiceDesc = grpc.ServiceDesc{
+	ServiceName: DatabaseService,
+	// Only a *DatabaseServer can be registered, by RegisterDatabaseServer
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		method("Has", func() interface{} { return &keyRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.has(req.(*keyRequest))
+		}),
+		method("Get", func() interface{} { return &keyRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.get(req.(*keyRequest))
+		}),
+		method("Put", func() interface{} { return &putRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.put(req.(*putRequest))
+		}),
+		method("Delete", func() interface{} { return &keyRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.delete(req.(*keyRequest))
+		}),
+		method("DeleteRange", func() interface{} { return &deleteRangeRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.deleteRange(req.(*deleteRangeRequest))
+		}),
+		method("Stat", func() interface{} { return &statRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.stat(req.(*statRequest))
+		}),
+		method("Compact", func() interface{} { return &compactRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.compact(req.(*compactRequest))
+		}),
+		method("Close", func() interface{} { return &emptyRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.close(req.(*emptyRequest))
+		}),
+		method("WriteBatch", func() interface{} { return &writeBatchRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.writeBatch(req.(*writeBatchRequest))
+		}),
+		method("NewIterator", func() interface{} { return &newIteratorRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.newIterator(req.(*newIteratorRequest))
+		}),
+		method("IteratorNext", func() interface{} { return &iteratorRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.iteratorNext(req.(*iteratorRequest))
+		}),
+		method("IteratorError", func() interface{} { return &iteratorRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.iteratorError(req.(*iteratorRequest))
+		}),
+		method("IteratorRelease", func() interface{} { return &iteratorRequest{} }, func(s *DatabaseServer, req interface{}) (interface{}, error) {
+			return s.iteratorRelease(req.(*iteratorRequest))
+		}),
+	},
+}
@@ -0,0 +1,347 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ttldb
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/nodb"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// expirySize is the number of bytes of the expiration stored before each value
+const expirySize = 8
+
+var errMalformedValue = errors.New("value is missing its expiration")
+
+// Database expires the keys of another database a duration after they're
+// written. Expired keys are hidden from reads and iteration, and are removed
+// from the underlying database when it's swept. Each value is stored after its
+// expiration, in unix nanoseconds.
+type Database struct {
+	// Clock is used to expire keys. It can be set in tests.
+	Clock timer.Clock
+
+	lock sync.RWMutex
+	db   database.Database
+	ttl  time.Duration
+
+	// If non-nil, sweeps the database periodically
+	sweeper *timer.Repeater
+}
+
+// New returns a database that expires the keys written to [db] [ttl] after
+// they're written. If [sweepInterval] is positive, the expired keys are
+// removed from [db] every [sweepInterval] until the database is closed.
+func New(db database.Database, ttl, sweepInterval time.Duration) *Database {
+	ttlDB := &Database{
+		db:  db,
+		ttl: ttl,
+	}
+	if sweepInterval > 0 {
+		// Failures are retried by the next sweep
+		ttlDB.sweeper = timer.NewRepeater(func() { _, _ = ttlDB.Sweep() }, sweepInterval)
+		go ttlDB.sweeper.Dispatch()
+	}
+	return ttlDB
+}
+
+// Has implements the Database interface
+func (db *Database) Has(key []byte) (bool, error) {
+	_, err := db.Get(key)
+	switch err {
+	case nil:
+		return true, nil
+	case database.ErrNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Get implements the Database interface
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, database.ErrClosed
+	}
+	value, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	expiry, value, err := split(value)
+	if err != nil {
+		return nil, err
+	}
+	if db.expired(expiry) {
+		return nil, database.ErrNotFound
+	}
+	return value, nil
+}
+
+// Put implements the Database interface. The key expires after the default
+// duration of the database.
+func (db *Database) Put(key, value []byte) error { return db.PutWithTTL(key, value, db.ttl) }
+
+// PutWithTTL sets the value of [key] to [value] until [ttl] from now
+func (db *Database) PutWithTTL(key, value []byte, ttl time.Duration) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return db.db.Put(key, join(db.Clock.Time().Add(ttl), value))
+}
+
+// Delete implements the Database interface
+func (db *Database) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return db.db.Delete(key)
+}
+
+// DeleteRange implements the Database interface
+func (db *Database) DeleteRange(start, end []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return db.db.DeleteRange(start, end)
+}
+
+// Sweep removes the expired keys from the underlying database, and returns how
+// many were removed
+func (db *Database) Sweep() (int, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return 0, database.ErrClosed
+	}
+
+	it := db.db.NewIterator()
+	defer it.Release()
+
+	batch := db.db.NewBatch()
+	swept := 0
+	for it.Next() {
+		expiry, _, err := split(it.Value())
+		if err != nil {
+			return 0, err
+		}
+		if !db.expired(expiry) {
+			continue
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return 0, err
+		}
+		swept++
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return swept, nil
+}
+
+// NewBatch implements the Database interface
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		Batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+// NewIterator implements the Database interface
+func (db *Database) NewIterator() database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart implements the Database interface
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix implements the Database interface
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix implements the Database interface. Keys that
+// expire while the iterator is used may still be iterated over.
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return &nodb.Iterator{Err: database.ErrClosed}
+	}
+	return &iterator{
+		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		now:      db.Clock.Time(),
+	}
+}
+
+// Stat implements the Database interface
+func (db *Database) Stat(stat string) (string, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return "", database.ErrClosed
+	}
+	return db.db.Stat(stat)
+}
+
+// Compact implements the Database interface
+func (db *Database) Compact(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	return db.db.Compact(start, limit)
+}
+
+// Close implements the Database interface. The underlying database isn't
+// closed.
+func (db *Database) Close() error {
+	db.lock.Lock()
+	if db.db == nil {
+		db.lock.Unlock()
+		return database.ErrClosed
+	}
+	db.db = nil
+	db.lock.Unlock()
+
+	// The lock isn't held while stopping the sweeper, so a running sweep can
+	// finish
+	if db.sweeper != nil {
+		db.sweeper.Stop()
+	}
+	return nil
+}
+
+// expired returns true if [expiry] has passed
+func (db *Database) expired(expiry time.Time) bool { return !db.Clock.Time().Before(expiry) }
+
+type batch struct {
+	database.Batch
+	db *Database
+}
+
+// Put implements the Batch interface. The key expires after the default
+// duration of the database, measured from when the write is added to the
+// batch.
+func (b *batch) Put(key, value []byte) error {
+	return b.Batch.Put(key, join(b.db.Clock.Time().Add(b.db.ttl), value))
+}
+
+// Write implements the Batch interface
+func (b *batch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	if b.db.db == nil {
+		return database.ErrClosed
+	}
+	return b.Batch.Write()
+}
+
+// Replay implements the Batch interface. The values are replayed without their
+// expirations.
+func (b *batch) Replay(w database.KeyValueWriter) error {
+	return b.Batch.Replay(&replayer{writer: w})
+}
+
+type replayer struct{ writer database.KeyValueWriter }
+
+func (r *replayer) Put(key, value []byte) error {
+	_, value, err := split(value)
+	if err != nil {
+		return err
+	}
+	return r.writer.Put(key, value)
+}
+
+func (r *replayer) Delete(key []byte) error { return r.writer.Delete(key) }
+
+type iterator struct {
+	database.Iterator
+
+	// Keys that expire before [now] are skipped
+	now time.Time
+
+	value []byte
+	err   error
+}
+
+// Next implements the Iterator interface
+func (it *iterator) Next() bool {
+	for it.err == nil && it.Iterator.Next() {
+		expiry, value, err := split(it.Iterator.Value())
+		if err != nil {
+			it.err = err
+			break
+		}
+		if it.now.Before(expiry) {
+			it.value = value
+			return true
+		}
+	}
+	it.value = nil
+	return false
+}
+
+// Error implements the Iterator interface
+func (it *iterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
+
+// Key implements the Iterator interface
+func (it *iterator) Key() []byte {
+	if it.value == nil {
+		return nil
+	}
+	return it.Iterator.Key()
+}
+
+// Value implements the Iterator interface
+func (it *iterator) Value() []byte { return it.value }
+
+// join returns [value] stored after [expiry]
+func join(expiry time.Time, value []byte) []byte {
+	stored := make([]byte, expirySize+len(value))
+	binary.BigEndian.PutUint64(stored, uint64(expiry.UnixNano()))
+	copy(stored[expirySize:], value)
+	return stored
+}
+
+// split returns the expiry and the value stored in [stored]
+func split(stored []byte) (time.Time, []byte, error) {
+	if len(stored) < expirySize {
+		return time.Time{}, nil, errMalformedValue
+	}
+	expiry := time.Unix(0, int64(binary.BigEndian.Uint64(stored)))
+	return expiry, stored[expirySize:], nil
+}
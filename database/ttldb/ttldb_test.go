@@ -0,0 +1,90 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ttldb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+func TestInterface(t *testing.T) {
+	for _, test := range dbtest.Tests {
+		test(t, New(memdb.New(), time.Hour, 0))
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	baseDB := memdb.New()
+	db := New(baseDB, time.Minute, 0)
+	db.Clock.Set(time.Unix(1000, 0))
+
+	key1 := []byte("key1")
+	key2 := []byte("key2")
+	value := []byte("value")
+	if err := db.Put(key1, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.PutWithTTL(key2, value, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := db.Get(key1); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, value) {
+		t.Fatalf("db.Get Returned: 0x%x ; Expected: 0x%x", v, value)
+	}
+
+	db.Clock.Advance(time.Minute)
+
+	if has, err := db.Has(key1); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Expired key should have been hidden")
+	}
+	if _, err := db.Get(key1); err != database.ErrNotFound {
+		t.Fatalf("db.Get Returned: %v ; Expected: %s", err, database.ErrNotFound)
+	}
+	if has, err := db.Has(key2); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Unexpired key should have been found")
+	}
+
+	iterator := db.NewIterator()
+	if !iterator.Next() {
+		t.Fatalf("Iterator should have returned the unexpired key")
+	} else if key := iterator.Key(); !bytes.Equal(key, key2) {
+		t.Fatalf("iterator.Key Returned: 0x%x ; Expected: 0x%x", key, key2)
+	} else if iterator.Next() {
+		t.Fatalf("Iterator shouldn't have returned the expired key")
+	}
+	iterator.Release()
+
+	// The expired key is only removed from the underlying database by a sweep
+	if has, err := baseDB.Has(key1); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Expired key should have been stored until swept")
+	}
+	if swept, err := db.Sweep(); err != nil {
+		t.Fatal(err)
+	} else if swept != 1 {
+		t.Fatalf("Sweep Returned: %d ; Expected: 1", swept)
+	}
+	if has, err := baseDB.Has(key1); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Expired key should have been swept")
+	}
+	if has, err := baseDB.Has(key2); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Unexpired key shouldn't have been swept")
+	}
+}
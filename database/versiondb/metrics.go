@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Register gauges of the size of the uncommitted changes of the database, and
+// a histogram of the latency of its commits, with [registerer]
+func (db *Database) Register(namespace string, registerer prometheus.Registerer) error {
+	pendingBytes := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "versiondb_pending_bytes",
+			Help:      "Number of key and value bytes of the uncommitted changes",
+		},
+		func() float64 { return float64(db.PendingSize()) })
+	pendingKeys := prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "versiondb_pending_keys",
+			Help:      "Number of keys with uncommitted changes",
+		},
+		func() float64 { return float64(db.PendingKeys()) })
+	commitLatency := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "versiondb_commit_latency",
+			Help:      "Latency of writing the uncommitted changes to the underlying database, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(1e-5, 4, 10), // 10us to 2.6s
+		})
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		registerer.Register(pendingBytes),
+		registerer.Register(pendingKeys),
+		registerer.Register(commitLatency),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+
+	db.lock.Lock()
+	db.commitLatency = commitLatency
+	db.lock.Unlock()
+	return nil
+}
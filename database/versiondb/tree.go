@@ -0,0 +1,149 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// node of an immutable treap, ordered by key. Changing the treap copies the
+// nodes on the path to the change, so an iterator can hold onto a root while
+// the database keeps changing.
+type node struct {
+	key         string
+	value       valueDelete
+	priority    uint32
+	left, right *node
+}
+
+func priority(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// insert returns the treap [n] with [key] set to [value]
+func insert(n *node, key string, value valueDelete) *node {
+	if n == nil {
+		return &node{
+			key:      key,
+			value:    value,
+			priority: priority(key),
+		}
+	}
+
+	c := *n
+	switch {
+	case key < n.key:
+		c.left = insert(n.left, key, value)
+		if c.left.priority > c.priority {
+			// Both nodes are copies, so they can be rotated in place
+			l := c.left
+			c.left = l.right
+			l.right = &c
+			return l
+		}
+	case key > n.key:
+		c.right = insert(n.right, key, value)
+		if c.right.priority > c.priority {
+			r := c.right
+			c.right = r.left
+			r.left = &c
+			return r
+		}
+	default:
+		c.value = value
+	}
+	return &c
+}
+
+// remove returns the treap [n] without [key]
+func remove(n *node, key string) *node {
+	if n == nil {
+		return nil
+	}
+
+	switch {
+	case key < n.key:
+		c := *n
+		c.left = remove(n.left, key)
+		return &c
+	case key > n.key:
+		c := *n
+		c.right = remove(n.right, key)
+		return &c
+	default:
+		return merge(n.left, n.right)
+	}
+}
+
+// merge returns the treap with the nodes of [a] and [b]. The keys of [a] must
+// be less than the keys of [b].
+func merge(a, b *node) *node {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.priority > b.priority:
+		c := *a
+		c.right = merge(a.right, b)
+		return &c
+	default:
+		c := *b
+		c.left = merge(a, b.left)
+		return &c
+	}
+}
+
+// cursor walks over the keys of a treap, in order, that are at least [start]
+// and start with [prefix]
+type cursor struct {
+	prefix string
+	// The path to the next node that hasn't been walked over. The next node
+	// is on top, and the nodes below it are the ones with greater keys whose
+	// left subtrees are being walked over.
+	stack []*node
+}
+
+func newCursor(root *node, start, prefix string) *cursor {
+	if start < prefix {
+		start = prefix
+	}
+	c := &cursor{prefix: prefix}
+	for n := root; n != nil; {
+		if n.key >= start {
+			c.stack = append(c.stack, n)
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return c
+}
+
+// peek returns the next node, or nil if the cursor is exhausted
+func (c *cursor) peek() *node {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	n := c.stack[len(c.stack)-1]
+	if !strings.HasPrefix(n.key, c.prefix) {
+		// Keys are in order, so no later key starts with the prefix
+		c.stack = nil
+		return nil
+	}
+	return n
+}
+
+// next moves the cursor past the next node
+func (c *cursor) next() {
+	n := c.stack[len(c.stack)-1]
+	c.stack[len(c.stack)-1] = nil
+	c.stack = c.stack[:len(c.stack)-1]
+	for n = n.right; n != nil; n = n.left {
+		c.stack = append(c.stack, n)
+	}
+}
@@ -0,0 +1,74 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package versiondb
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTreeOrder(t *testing.T) {
+	rand.Seed(0)
+
+	var root *node
+	keys := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("%03d", rand.Intn(300))
+		if rand.Intn(3) == 0 {
+			root = remove(root, key)
+			delete(keys, key)
+		} else {
+			root = insert(root, key, valueDelete{value: []byte(key)})
+			keys[key] = true
+		}
+	}
+
+	expected := []string(nil)
+	for key := range keys {
+		if key >= "150" && key[0] == '1' {
+			expected = append(expected, key)
+		}
+	}
+	sort.Strings(expected)
+
+	c := newCursor(root, "150", "1")
+	for _, key := range expected {
+		n := c.peek()
+		if n == nil {
+			t.Fatalf("Cursor should have walked over %s", key)
+		}
+		if n.key != key || string(n.value.value) != key {
+			t.Fatalf("Cursor should have walked over %s but walked over %s", key, n.key)
+		}
+		c.next()
+	}
+	if n := c.peek(); n != nil {
+		t.Fatalf("Cursor shouldn't have walked over %s", n.key)
+	}
+}
+
+func TestTreeImmutable(t *testing.T) {
+	var root *node
+	for _, key := range []string{"a", "b", "c"} {
+		root = insert(root, key, valueDelete{value: []byte(key)})
+	}
+	c := newCursor(root, "", "")
+
+	root = remove(root, "b")
+	root = insert(root, "c", valueDelete{delete: true})
+	_ = insert(root, "d", valueDelete{value: []byte("d")})
+
+	for _, key := range []string{"a", "b", "c"} {
+		n := c.peek()
+		if n == nil || n.key != key || n.value.delete {
+			t.Fatalf("Cursor should have walked over %s", key)
+		}
+		c.next()
+	}
+	if c.peek() != nil {
+		t.Fatalf("Cursor should have been exhausted")
+	}
+}
@@ -4,22 +4,64 @@
 package versiondb
 
 import (
-	"sort"
-	"strings"
+	"bytes"
+	"errors"
 	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/database/nodb"
 )
 
+var (
+	// ErrOverflow is returned when a write would grow the uncommitted changes
+	// of a bounded database past its bound
+	ErrOverflow = errors.New("uncommitted changes would exceed the bound")
+
+	errUnknownSavepoint = errors.New("unknown savepoint")
+)
+
 // Database implements the Database interface by living on top of another
 // database, writing changes to the underlying database only when commit is
 // called.
+//
+// Savepoints mark the uncommitted changes at a point in time. Rolling back to
+// a savepoint discards the changes made after it, and keeps the changes made
+// before it.
 type Database struct {
 	lock sync.RWMutex
 	mem  map[string]valueDelete
 	db   database.Database
+
+	// The changes in [mem], ordered by key, for iterators
+	sorted *node
+
+	// Total size of the keys and values in [mem]
+	memSize int
+
+	// If positive, the max total size of the keys and values in [mem]
+	maxSize int
+	// If true, the changes are committed once they grow past [maxSize].
+	// Otherwise, writes that would grow them past [maxSize] fail.
+	autoCommit bool
+
+	// Called with the keys changed by each commit
+	hooks []CommitHook
+	// Commits whose hooks haven't been called yet
+	committed []commitChanges
+	// Held while calling the hooks, so they're called in the order of the
+	// commits
+	hookLock sync.Mutex
+
+	// Savepoints that haven't been rolled back to or committed, oldest first
+	savepoints      []savepoint
+	lastSavepointID SavepointID
+
+	// If registered, the latency of each commit
+	commitLatency prometheus.Histogram
 }
 
 type valueDelete struct {
@@ -27,6 +69,29 @@ type valueDelete struct {
 	delete bool
 }
 
+// CommitHook is called after a commit with the keys, in order, that the commit
+// wrote and deleted
+type CommitHook func(written, deleted [][]byte)
+
+type commitChanges struct {
+	written, deleted [][]byte
+}
+
+// SavepointID identifies a savepoint of a Database
+type SavepointID uint64
+
+type savepoint struct {
+	id SavepointID
+	// Key --> the key's uncommitted change when the savepoint was created,
+	// for the keys that changed since
+	undo map[string]undo
+}
+
+type undo struct {
+	valueDelete
+	changed bool // False if the key had no uncommitted change
+}
+
 // New returns a new prefixed database
 func New(db database.Database) *Database {
 	return &Database{
@@ -35,6 +100,18 @@ func New(db database.Database) *Database {
 	}
 }
 
+// NewBounded returns a new versioned database whose uncommitted changes are
+// bounded to [maxBytes] of keys and values. If [autoCommit], the changes are
+// committed once a write grows them past the bound, unless there are
+// savepoints, which a commit would discard. Otherwise, writes that would grow
+// the changes past the bound fail with ErrOverflow and aren't applied.
+func NewBounded(db database.Database, maxBytes int, autoCommit bool) *Database {
+	vdb := New(db)
+	vdb.maxSize = maxBytes
+	vdb.autoCommit = autoCommit
+	return vdb
+}
+
 // Has implements the database.Database interface
 func (db *Database) Has(key []byte) (bool, error) {
 	db.lock.RLock()
@@ -69,24 +146,203 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 // Put implements the database.Database interface
 func (db *Database) Put(key, value []byte) error {
 	db.lock.Lock()
-	defer db.lock.Unlock()
+	defer db.unlock()
 
 	if db.mem == nil {
 		return database.ErrClosed
 	}
-	db.mem[string(key)] = valueDelete{value: value}
-	return nil
+	k := string(key)
+	v := valueDelete{value: copyBytes(value)}
+	if err := db.reserve(db.growth(k, v)); err != nil {
+		return err
+	}
+	db.put(k, v)
+	return db.flush()
 }
 
 // Delete implements the database.Database interface
 func (db *Database) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+	k := string(key)
+	if err := db.reserve(db.growth(k, valueDelete{delete: true})); err != nil {
+		return err
+	}
+	db.put(k, valueDelete{delete: true})
+	return db.flush()
+}
+
+// DeleteRange implements the database.Database interface. The keys in the
+// range, of both the uncommitted changes and the underlying database, are
+// deleted in one change that is bounded like a batch.
+func (db *Database) DeleteRange(start, end []byte) error {
+	db.lock.Lock()
+	defer db.unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+
+	keys := make(map[string]struct{})
+	for c := newCursor(db.sorted, string(start), ""); c.peek() != nil; c.next() {
+		if key := c.peek().key; end == nil || key < string(end) {
+			keys[key] = struct{}{}
+		} else {
+			break
+		}
+	}
+	it := db.db.NewIteratorWithStart(start)
+	for it.Next() && (end == nil || bytes.Compare(it.Key(), end) < 0) {
+		keys[string(it.Key())] = struct{}{}
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return err
+	}
+
+	growth := 0
+	for key := range keys {
+		growth += db.growth(key, valueDelete{delete: true})
+	}
+	if err := db.reserve(growth); err != nil {
+		return err
+	}
+	for key := range keys {
+		db.put(key, valueDelete{delete: true})
+	}
+	return db.flush()
+}
+
+// put records the change of [key] to [value]. Assumes the lock is held.
+func (db *Database) put(key string, value valueDelete) {
+	if len(db.savepoints) > 0 {
+		undos := db.savepoints[len(db.savepoints)-1].undo
+		if _, recorded := undos[key]; !recorded {
+			prev, changed := db.mem[key]
+			undos[key] = undo{valueDelete: prev, changed: changed}
+		}
+	}
+	db.set(key, value)
+}
+
+// set the uncommitted change of [key]. Assumes the lock is held.
+func (db *Database) set(key string, value valueDelete) {
+	db.unset(key)
+	db.mem[key] = value
+	db.memSize += len(key) + len(value.value)
+	db.sorted = insert(db.sorted, key, value)
+}
+
+// unset the uncommitted change of [key]. Assumes the lock is held.
+func (db *Database) unset(key string) {
+	if prev, ok := db.mem[key]; ok {
+		db.memSize -= len(key) + len(prev.value)
+		delete(db.mem, key)
+		db.sorted = remove(db.sorted, key)
+	}
+}
+
+// growth returns how much changing [key] to [value] grows the size of the
+// uncommitted changes. Assumes the lock is held.
+func (db *Database) growth(key string, value valueDelete) int {
+	growth := len(key) + len(value.value)
+	if prev, ok := db.mem[key]; ok {
+		growth -= len(key) + len(prev.value)
+	}
+	return growth
+}
+
+// reserve returns ErrOverflow if the uncommitted changes can't grow by
+// [growth]. Assumes the lock is held.
+func (db *Database) reserve(growth int) error {
+	if db.maxSize > 0 && !db.autoCommit && growth > 0 && db.memSize+growth > db.maxSize {
+		return ErrOverflow
+	}
+	return nil
+}
+
+// flush commits the uncommitted changes if they grew past the bound and the
+// database commits automatically. Assumes the lock is held.
+func (db *Database) flush() error {
+	if db.maxSize <= 0 || !db.autoCommit || db.memSize <= db.maxSize || len(db.savepoints) > 0 {
+		return nil
+	}
+	return db.commit()
+}
+
+// PendingSize returns the total size, in bytes, of the keys and values that
+// haven't been committed
+func (db *Database) PendingSize() int {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.memSize
+}
+
+// PendingKeys returns the number of keys with changes that haven't been
+// committed
+func (db *Database) PendingKeys() int {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return len(db.mem)
+}
+
+// Dirty returns true if there are changes that haven't been committed
+func (db *Database) Dirty() bool { return db.PendingKeys() > 0 }
+
+// Snapshot creates a savepoint of the uncommitted changes made so far, so
+// that the changes made after it can be rolled back. Savepoints can be nested.
+func (db *Database) Snapshot() (SavepointID, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.mem == nil {
+		return 0, database.ErrClosed
+	}
+	db.lastSavepointID++
+	db.savepoints = append(db.savepoints, savepoint{
+		id:   db.lastSavepointID,
+		undo: make(map[string]undo),
+	})
+	return db.lastSavepointID, nil
+}
+
+// RollbackTo discards the changes made since the savepoint [id] was created,
+// including those made after the savepoints nested in it, which are removed
+// along with [id]. The changes made before [id] are kept.
+func (db *Database) RollbackTo(id SavepointID) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
 	if db.mem == nil {
 		return database.ErrClosed
 	}
-	db.mem[string(key)] = valueDelete{delete: true}
+
+	i := len(db.savepoints) - 1
+	for i >= 0 && db.savepoints[i].id != id {
+		i--
+	}
+	if i < 0 {
+		return errUnknownSavepoint
+	}
+
+	// The newest changes are undone first
+	for j := len(db.savepoints) - 1; j >= i; j-- {
+		for key, undo := range db.savepoints[j].undo {
+			if undo.changed {
+				db.set(key, undo.valueDelete)
+			} else {
+				db.unset(key)
+			}
+		}
+	}
+	db.savepoints = db.savepoints[:i]
 	return nil
 }
 
@@ -115,24 +371,11 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 		return &nodb.Iterator{Err: database.ErrClosed}
 	}
 
-	startString := string(start)
-	prefixString := string(prefix)
-	keys := make([]string, 0, len(db.mem))
-	for key := range db.mem {
-		if strings.HasPrefix(key, prefixString) && key >= startString {
-			keys = append(keys, key)
-		}
-	}
-	sort.Strings(keys) // Keys need to be in sorted order
-	values := make([]valueDelete, 0, len(keys))
-	for _, key := range keys {
-		values = append(values, db.mem[key])
-	}
-
+	// The changes are immutable once sorted, so the iterator walks over them
+	// lazily without holding the lock
 	return &iterator{
 		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
-		keys:     keys,
-		values:   values,
+		mem:      newCursor(db.sorted, string(start), string(prefix)),
 	}
 }
 
@@ -182,31 +425,131 @@ func (db *Database) GetDatabase() database.Database {
 // Commit writes all the operations of this database to the underlying database
 func (db *Database) Commit() error {
 	db.lock.Lock()
-	defer db.lock.Unlock()
+	defer db.unlock()
 
-	if db.mem == nil {
-		return database.ErrClosed
+	return db.commit()
+}
+
+// Assumes the lock is held
+func (db *Database) commit() error {
+	batch, err := db.commitBatch()
+	if err != nil {
+		return err
 	}
 	if len(db.mem) == 0 {
 		return nil
 	}
+	start := time.Now()
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if db.commitLatency != nil {
+		db.commitLatency.Observe(time.Since(start).Seconds())
+	}
+	if len(db.hooks) > 0 {
+		db.committed = append(db.committed, db.changes())
+	}
+	db.abort()
+	return nil
+}
+
+// OnCommit registers [hook] to be called after each commit of this database.
+// Hooks are called after the lock is released, so they can read from the
+// database, but they must not commit it. Changes written with CommitBatch
+// aren't passed to the hooks.
+func (db *Database) OnCommit(hook CommitHook) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.hooks = append(db.hooks, hook)
+}
+
+// changes returns the keys that are written and deleted by the uncommitted
+// changes. Assumes the lock is held.
+func (db *Database) changes() commitChanges {
+	changes := commitChanges{}
+	for c := newCursor(db.sorted, "", ""); c.peek() != nil; c.next() {
+		n := c.peek()
+		if n.value.delete {
+			changes.deleted = append(changes.deleted, []byte(n.key))
+		} else {
+			changes.written = append(changes.written, []byte(n.key))
+		}
+	}
+	return changes
+}
+
+// unlock releases the lock, then calls the hooks of the commits made while it
+// was held
+func (db *Database) unlock() {
+	committed := db.committed
+	hooks := db.hooks
+	db.committed = nil
+	if len(committed) == 0 {
+		db.lock.Unlock()
+		return
+	}
+
+	db.hookLock.Lock()
+	defer db.hookLock.Unlock()
+
+	db.lock.Unlock()
+	for _, changes := range committed {
+		for _, hook := range hooks {
+			hook(changes.written, changes.deleted)
+		}
+	}
+}
+
+// CommitBatch returns a batch of the underlying database with all the
+// operations of this database, without writing it. To commit several
+// databases that share an underlying database atomically, their batches can
+// be replayed into one batch, which is written once. Abort should be called
+// once the batch is written.
+func (db *Database) CommitBatch() (database.Batch, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.commitBatch()
+}
+
+// Assumes the lock is held
+func (db *Database) commitBatch() (database.Batch, error) {
+	if db.mem == nil {
+		return nil, database.ErrClosed
+	}
 
 	batch := db.db.NewBatch()
 	for key, value := range db.mem {
 		if value.delete {
 			if err := batch.Delete([]byte(key)); err != nil {
-				return err
+				return nil, err
 			}
 		} else if err := batch.Put([]byte(key), value.value); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	if err := batch.Write(); err != nil {
-		return err
-	}
+	return batch, nil
+}
+
+// Abort drops all the operations of this database that haven't been
+// committed, along with its savepoints
+func (db *Database) Abort() {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.abort()
+}
 
+// Assumes the lock is held
+func (db *Database) abort() {
+	if db.mem == nil {
+		return
+	}
 	db.mem = make(map[string]valueDelete, memdb.DefaultSize)
-	return nil
+	db.memSize = 0
+	db.sorted = nil
+	db.savepoints = nil
 }
 
 // Close implements the database.Database interface
@@ -218,7 +561,11 @@ func (db *Database) Close() error {
 		return database.ErrClosed
 	}
 	db.mem = nil
+	db.memSize = 0
+	db.sorted = nil
 	db.db = nil
+	db.hooks = nil
+	db.savepoints = nil
 	return nil
 }
 
@@ -254,19 +601,36 @@ func (b *batch) ValueSize() int { return b.size }
 // Write implements the Database interface
 func (b *batch) Write() error {
 	b.db.lock.Lock()
-	defer b.db.lock.Unlock()
+	defer b.db.unlock()
 
 	if b.db.mem == nil {
 		return database.ErrClosed
 	}
 
+	// The batch is applied entirely or not at all, so the growth is measured
+	// against the last write of each key
+	last := make(map[string]valueDelete, len(b.writes))
 	for _, kv := range b.writes {
-		b.db.mem[string(kv.key)] = valueDelete{
+		last[string(kv.key)] = valueDelete{
 			value:  kv.value,
 			delete: kv.delete,
 		}
 	}
-	return nil
+	growth := 0
+	for key, value := range last {
+		growth += b.db.growth(key, value)
+	}
+	if err := b.db.reserve(growth); err != nil {
+		return err
+	}
+
+	for _, kv := range b.writes {
+		b.db.put(string(kv.key), valueDelete{
+			value:  kv.value,
+			delete: kv.delete,
+		})
+	}
+	return b.db.flush()
 }
 
 // Reset implements the Database interface
@@ -296,8 +660,7 @@ type iterator struct {
 
 	key, value []byte
 
-	keys   []string
-	values []valueDelete
+	mem *cursor
 
 	initialized, exhausted bool
 }
@@ -312,58 +675,50 @@ func (it *iterator) Next() bool {
 	}
 
 	for {
+		mem := it.mem.peek()
 		switch {
-		case it.exhausted && len(it.keys) == 0:
+		case it.exhausted && mem == nil:
 			it.key = nil
 			it.value = nil
 			return false
 		case it.exhausted:
-			nextKey := it.keys[0]
-			nextValue := it.values[0]
+			it.mem.next()
 
-			it.keys = it.keys[1:]
-			it.values = it.values[1:]
-
-			if !nextValue.delete {
-				it.key = []byte(nextKey)
-				it.value = nextValue.value
+			if !mem.value.delete {
+				it.key = []byte(mem.key)
+				it.value = mem.value.value
 				return true
 			}
-		case len(it.keys) == 0:
+		case mem == nil:
 			it.key = it.Iterator.Key()
 			it.value = it.Iterator.Value()
 			it.exhausted = !it.Iterator.Next()
 			return true
 		default:
-			memKey := it.keys[0]
-			memValue := it.values[0]
-
 			dbKey := it.Iterator.Key()
 
 			dbStringKey := string(dbKey)
 			switch {
-			case memKey < dbStringKey:
-				it.keys = it.keys[1:]
-				it.values = it.values[1:]
+			case mem.key < dbStringKey:
+				it.mem.next()
 
-				if !memValue.delete {
-					it.key = []byte(memKey)
-					it.value = memValue.value
+				if !mem.value.delete {
+					it.key = []byte(mem.key)
+					it.value = mem.value.value
 					return true
 				}
-			case dbStringKey < memKey:
+			case dbStringKey < mem.key:
 				it.key = dbKey
 				it.value = it.Iterator.Value()
 				it.exhausted = !it.Iterator.Next()
 				return true
 			default:
-				it.keys = it.keys[1:]
-				it.values = it.values[1:]
+				it.mem.next()
 				it.exhausted = !it.Iterator.Next()
 
-				if !memValue.delete {
-					it.key = []byte(memKey)
-					it.value = memValue.value
+				if !mem.value.delete {
+					it.key = []byte(mem.key)
+					it.value = mem.value.value
 					return true
 				}
 			}
@@ -381,8 +736,7 @@ func (it *iterator) Value() []byte { return it.value }
 func (it *iterator) Release() {
 	it.key = nil
 	it.value = nil
-	it.keys = nil
-	it.values = nil
+	it.mem = &cursor{}
 	it.Iterator.Release()
 }
 
@@ -7,12 +7,15 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/dbtest"
 	"github.com/ava-labs/gecko/database/memdb"
 )
 
 func TestInterface(t *testing.T) {
-	for _, test := range database.Tests {
+	for _, test := range dbtest.Tests {
 		baseDB := memdb.New()
 		test(t, New(baseDB))
 	}
@@ -297,3 +300,430 @@ func TestSetDatabaseClosed(t *testing.T) {
 		t.Fatalf("Unexpected database from db.GetDatabase")
 	}
 }
+
+func TestRollbackTo(t *testing.T) {
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	key1 := []byte("hello1")
+	key2 := []byte("hello2")
+	key3 := []byte("hello3")
+
+	if err := db.Put(key1, []byte("world1")); err != nil {
+		t.Fatal(err)
+	}
+	first, err := db.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key1, []byte("world1.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(key2, []byte("world2")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(key2); err != nil {
+		t.Fatal(err)
+	}
+	batch := db.NewBatch()
+	if err := batch.Put(key3, []byte("world3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the most recent layer is discarded
+	if err := db.RollbackTo(second); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get(key2); err != nil || !bytes.Equal(value, []byte("world2")) {
+		t.Fatalf("Deletion after the savepoint should have been rolled back")
+	}
+	if has, err := db.Has(key3); err != nil || has {
+		t.Fatalf("Batch written after the savepoint should have been rolled back")
+	}
+	if value, err := db.Get(key1); err != nil || !bytes.Equal(value, []byte("world1.1")) {
+		t.Fatalf("Changes before the savepoint should have been kept")
+	}
+	if err := db.RollbackTo(second); err == nil {
+		t.Fatalf("Should have failed to roll back to a removed savepoint")
+	}
+
+	if err := db.RollbackTo(first); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get(key1); err != nil || !bytes.Equal(value, []byte("world1")) {
+		t.Fatalf("Changes after the first savepoint should have been rolled back")
+	}
+	if has, err := db.Has(key2); err != nil || has {
+		t.Fatalf("Key written after the first savepoint shouldn't exist")
+	}
+
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := baseDB.Get(key1); err != nil || !bytes.Equal(value, []byte("world1")) {
+		t.Fatalf("Changes before the first savepoint should have been committed")
+	}
+	if has, err := baseDB.Has(key2); err != nil || has {
+		t.Fatalf("Rolled back changes shouldn't have been committed")
+	}
+}
+
+func TestRollbackToAfterCommit(t *testing.T) {
+	db := New(memdb.New())
+
+	savepoint, err := db.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RollbackTo(savepoint); err == nil {
+		t.Fatalf("Committed changes shouldn't be rolled back")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Snapshot(); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.Snapshot", database.ErrClosed)
+	}
+}
+
+func TestCommitBatch(t *testing.T) {
+	baseDB := memdb.New()
+	chainDB := New(baseDB)
+	indexDB := New(baseDB)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	if err := chainDB.Put(key1, value1); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexDB.Put(key2, value2); err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := chainDB.CommitBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexBatch, err := indexDB.CommitBatch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has, err := baseDB.Has(key1); err != nil || has {
+		t.Fatalf("CommitBatch shouldn't write to the underlying database")
+	}
+
+	// Both databases are committed in one write
+	if err := indexBatch.Replay(batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	chainDB.Abort()
+	indexDB.Abort()
+
+	if value, err := baseDB.Get(key1); err != nil || !bytes.Equal(value, value1) {
+		t.Fatalf("First database's write should have been committed")
+	}
+	if value, err := baseDB.Get(key2); err != nil || !bytes.Equal(value, value2) {
+		t.Fatalf("Second database's write should have been committed")
+	}
+	if batch, err := chainDB.CommitBatch(); err != nil || batch.ValueSize() != 0 {
+		t.Fatalf("Aborted database should have no pending writes")
+	}
+}
+
+func TestAbort(t *testing.T) {
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	key1 := []byte("hello1")
+	if err := baseDB.Put(key1, []byte("world1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(key1); err != nil {
+		t.Fatal(err)
+	}
+	db.Abort()
+
+	if has, err := db.Has(key1); err != nil || !has {
+		t.Fatalf("Aborted deletion shouldn't be visible")
+	}
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := baseDB.Has(key1); err != nil || !has {
+		t.Fatalf("Aborted deletion shouldn't have been committed")
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CommitBatch(); err != database.ErrClosed {
+		t.Fatalf("Expected %s on db.CommitBatch", database.ErrClosed)
+	}
+}
+
+func TestPending(t *testing.T) {
+	db := New(memdb.New())
+
+	if db.Dirty() || db.PendingKeys() != 0 || db.PendingSize() != 0 {
+		t.Fatalf("New database shouldn't have pending changes")
+	}
+
+	if err := db.Put([]byte("key1"), []byte("value1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key1"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	savepoint, err := db.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete([]byte("key2")); err != nil {
+		t.Fatal(err)
+	}
+	if !db.Dirty() || db.PendingKeys() != 2 || db.PendingSize() != 13 {
+		t.Fatalf("Wrong pending changes: %d keys of size %d", db.PendingKeys(), db.PendingSize())
+	}
+
+	if err := db.RollbackTo(savepoint); err != nil {
+		t.Fatal(err)
+	}
+	if db.PendingKeys() != 1 || db.PendingSize() != 9 {
+		t.Fatalf("Wrong pending changes after rollback: %d keys of size %d", db.PendingKeys(), db.PendingSize())
+	}
+
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if db.Dirty() || db.PendingSize() != 0 {
+		t.Fatalf("Committed database shouldn't have pending changes")
+	}
+}
+
+func TestBoundedOverflow(t *testing.T) {
+	baseDB := memdb.New()
+	db := NewBounded(baseDB, 10, false)
+
+	if err := db.Put([]byte("key1"), []byte("val1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key2"), []byte("val2")); err != ErrOverflow {
+		t.Fatalf("Put past the bound should have failed with %s but got %v", ErrOverflow, err)
+	}
+	if has, err := db.Has([]byte("key2")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Put past the bound shouldn't have been applied")
+	}
+
+	// Overwriting a key with a smaller value doesn't grow the changes
+	if err := db.Put([]byte("key1"), []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := db.NewBatch()
+	if err := batch.Put([]byte("k"), []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Put([]byte("key3"), []byte("val3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := batch.Write(); err != ErrOverflow {
+		t.Fatalf("Batch past the bound should have failed with %s but got %v", ErrOverflow, err)
+	}
+	if has, err := db.Has([]byte("k")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Batch past the bound shouldn't have been partially applied")
+	}
+
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key2"), []byte("val2")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBoundedAutoCommit(t *testing.T) {
+	baseDB := memdb.New()
+	db := NewBounded(baseDB, 10, true)
+
+	if err := db.Put([]byte("key1"), []byte("val1")); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := baseDB.Has([]byte("key1")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Changes within the bound shouldn't have been committed")
+	}
+
+	if err := db.Put([]byte("key2"), []byte("val2")); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := baseDB.Has([]byte("key2")); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Changes past the bound should have been committed")
+	}
+	if db.Dirty() {
+		t.Fatalf("Changes past the bound should have been committed")
+	}
+
+	// Savepoints hold back automatic commits
+	if _, err := db.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key3"), []byte("value3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key4"), []byte("value4")); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := baseDB.Has([]byte("key4")); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Changes after a savepoint shouldn't have been committed")
+	}
+}
+
+func TestIteratorIgnoresLaterChanges(t *testing.T) {
+	db := New(memdb.New())
+
+	if err := db.Put([]byte("key1"), []byte("value1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key2"), []byte("value2")); err != nil {
+		t.Fatal(err)
+	}
+
+	iterator := db.NewIterator()
+	defer iterator.Release()
+
+	if err := db.Delete([]byte("key1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key2"), []byte("changed")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key3"), []byte("value3")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"key1", "key2"} {
+		if !iterator.Next() {
+			t.Fatalf("iterator.Next Returned: %v ; Expected: %v", false, true)
+		} else if key := string(iterator.Key()); key != expected {
+			t.Fatalf("iterator.Key Returned: %s ; Expected: %s", key, expected)
+		} else if value := string(iterator.Value()); value != "value"+expected[3:] {
+			t.Fatalf("iterator.Value Returned: %s ; Expected: %s", value, "value"+expected[3:])
+		}
+	}
+	if iterator.Next() {
+		t.Fatalf("iterator.Next Returned: %v ; Expected: %v", true, false)
+	}
+}
+
+func TestOnCommit(t *testing.T) {
+	db := New(memdb.New())
+
+	commits := 0
+	db.OnCommit(func(written, deleted [][]byte) {
+		commits++
+
+		if len(written) != 2 || string(written[0]) != "key1" || string(written[1]) != "key3" {
+			t.Fatalf("Wrong written keys: %s", written)
+		}
+		if len(deleted) != 1 || string(deleted[0]) != "key2" {
+			t.Fatalf("Wrong deleted keys: %s", deleted)
+		}
+		// Hooks can read from the database
+		if value, err := db.Get([]byte("key3")); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(value, []byte("value3")) {
+			t.Fatalf("Wrong value after commit: %s", value)
+		}
+	})
+
+	if err := db.Put([]byte("key3"), []byte("value3")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete([]byte("key2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("key1"), []byte("value1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if commits != 1 {
+		t.Fatalf("Hook should have been called once but was called %d times", commits)
+	}
+
+	// Empty commits don't call the hooks
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if commits != 1 {
+		t.Fatalf("Hook should have been called once but was called %d times", commits)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	db := New(memdb.New())
+	registry := prometheus.NewRegistry()
+	if err := db.Register("", registry); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte("hello"), []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Name --> value of each gauge, and sample count of each histogram
+	gauges := func() map[string]float64 {
+		metrics, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]float64{}
+		for _, metric := range metrics {
+			if gauge := metric.GetMetric()[0].GetGauge(); gauge != nil {
+				values[metric.GetName()] = gauge.GetValue()
+			}
+			if histogram := metric.GetMetric()[0].GetHistogram(); histogram != nil {
+				values[metric.GetName()] = float64(histogram.GetSampleCount())
+			}
+		}
+		return values
+	}
+	if values := gauges(); values["versiondb_pending_bytes"] != 10 || values["versiondb_pending_keys"] != 1 {
+		t.Fatalf("Wrong pending changes %v", values)
+	}
+
+	if err := db.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if values := gauges(); values["versiondb_pending_bytes"] != 0 || values["versiondb_pending_keys"] != 0 || values["versiondb_commit_latency"] != 1 {
+		t.Fatalf("Wrong pending changes or commits %v", values)
+	}
+}
@@ -0,0 +1,310 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/backup"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// copyBatchSize is the number of bytes copied per batch
+const copyBatchSize = 4 * 1024 * 1024
+
+// parse parses [args] into a flag set named after [command] whose source
+// database flags are [src]. [register] registers the other flags.
+func parse(command string, args []string, src *dbFlags, register func(fs *flag.FlagSet)) error {
+	fs := flag.NewFlagSet("gecko-db "+command, flag.ContinueOnError)
+	src.register(fs, "db", true)
+	if register != nil {
+		register(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	return nil
+}
+
+func runDump(args []string) error {
+	src := dbFlags{}
+	limit := 0
+	err := parse("dump", args, &src, func(fs *flag.FlagSet) {
+		fs.IntVar(&limit, "limit", 0, "Maximum number of keys to print. If 0, every key is printed")
+	})
+	if err != nil {
+		return err
+	}
+
+	db, prefix, closeDB, err := src.open(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := dump(db, prefix, limit, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// dump writes the keys of [db] that start with [prefix], and their values, to
+// [w], one pair per line. At most [limit] pairs are written, unless [limit] is
+// 0.
+func dump(db database.Iteratee, prefix []byte, limit int, w io.Writer) error {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for dumped := 0; (limit == 0 || dumped < limit) && it.Next(); dumped++ {
+		if _, err := fmt.Fprintf(w, "%x %x\n", it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+func runCount(args []string) error {
+	src := dbFlags{}
+	if err := parse("count", args, &src, nil); err != nil {
+		return err
+	}
+
+	db, prefix, closeDB, err := src.open(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	count, err := countKeys(db, prefix)
+	if err != nil {
+		return err
+	}
+	fmt.Println(count)
+	return nil
+}
+
+// countKeys returns the number of keys of [db] that start with [prefix]
+func countKeys(db database.Iteratee, prefix []byte) (int, error) {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count, it.Error()
+}
+
+// prefixStats are the sizes of the keys under a prefix
+type prefixStats struct {
+	prefix     string
+	keys       int
+	keyBytes   int
+	valueBytes int
+}
+
+func runStats(args []string) error {
+	src := dbFlags{}
+	prefixLen := 0
+	err := parse("stats", args, &src, func(fs *flag.FlagSet) {
+		fs.IntVar(&prefixLen, "prefix-len", hashing.HashLen, "Number of bytes, after --db-prefix, of the prefixes keys are grouped by. Prefixed databases prefix their keys with 32 bytes")
+	})
+	if err != nil {
+		return err
+	}
+
+	db, prefix, closeDB, err := src.open(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	stats, err := statPrefixes(db, prefix, prefixLen)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	fmt.Fprintf(w, "%-66s %12s %14s %14s\n", "prefix", "keys", "key bytes", "value bytes")
+	for _, stat := range stats {
+		fmt.Fprintf(w, "%-66s %12d %14d %14d\n", stat.prefix, stat.keys, stat.keyBytes, stat.valueBytes)
+	}
+	return w.Flush()
+}
+
+// statPrefixes groups the keys of [db] that start with [prefix] by their next
+// [prefixLen] bytes, and returns the sizes of each group, largest first. Keys
+// that are too short to have a prefix are grouped under the empty prefix.
+func statPrefixes(db database.Iteratee, prefix []byte, prefixLen int) ([]prefixStats, error) {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	statsByPrefix := make(map[string]*prefixStats)
+	for it.Next() {
+		key := it.Key()[len(prefix):]
+		groupPrefix := ""
+		if len(key) >= prefixLen {
+			groupPrefix = hex.EncodeToString(key[:prefixLen])
+		}
+		stats, ok := statsByPrefix[groupPrefix]
+		if !ok {
+			stats = &prefixStats{prefix: groupPrefix}
+			statsByPrefix[groupPrefix] = stats
+		}
+		stats.keys++
+		stats.keyBytes += len(it.Key())
+		stats.valueBytes += len(it.Value())
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	stats := make([]prefixStats, 0, len(statsByPrefix))
+	for _, stat := range statsByPrefix {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		sizeI := stats[i].keyBytes + stats[i].valueBytes
+		sizeJ := stats[j].keyBytes + stats[j].valueBytes
+		if sizeI != sizeJ {
+			return sizeI > sizeJ
+		}
+		return stats[i].prefix < stats[j].prefix
+	})
+	return stats, nil
+}
+
+func runCompact(args []string) error {
+	src := dbFlags{}
+	if err := parse("compact", args, &src, nil); err != nil {
+		return err
+	}
+
+	db, _, closeDB, err := src.open(false)
+	if err != nil {
+		return err
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		closeDB()
+		return err
+	}
+	return closeDB()
+}
+
+func runCopy(args []string) error {
+	src := dbFlags{}
+	dst := dbFlags{}
+	if err := parse("copy", args, &src, func(fs *flag.FlagSet) { dst.register(fs, "dst", false) }); err != nil {
+		return err
+	}
+
+	srcDB, prefix, closeSrc, err := src.open(true)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+
+	dstDB, _, closeDst, err := dst.open(false)
+	if err != nil {
+		return err
+	}
+	copied, err := copyDB(srcDB, prefix, dstDB)
+	if closeErr := closeDst(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("copied %d keys\n", copied)
+	return nil
+}
+
+// copyDB writes the keys of [src] that start with [prefix], and their values,
+// to [dst], and returns how many were written
+func copyDB(src database.Iteratee, prefix []byte, dst database.Batcher) (int, error) {
+	it := src.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	copied := 0
+	batch := dst.NewBatch()
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return copied, err
+		}
+		copied++
+		if batch.ValueSize() >= copyBatchSize {
+			if err := batch.Write(); err != nil {
+				return copied, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return copied, err
+	}
+	return copied, batch.Write()
+}
+
+func runBackup(args []string) error {
+	src := dbFlags{}
+	path := ""
+	if err := parse("backup", args, &src, func(fs *flag.FlagSet) {
+		fs.StringVar(&path, "file", "", "Path of the file the backup is written to")
+	}); err != nil {
+		return err
+	}
+	if path == "" {
+		return errMissingFile
+	}
+
+	db, _, closeDB, err := src.open(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	pairs, err := backup.BackupFile(db, path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("backed up %d keys\n", pairs)
+	return nil
+}
+
+func runRestore(args []string) error {
+	dst := dbFlags{}
+	path := ""
+	if err := parse("restore", args, &dst, func(fs *flag.FlagSet) {
+		fs.StringVar(&path, "file", "", "Path of the backup to restore")
+	}); err != nil {
+		return err
+	}
+	if path == "" {
+		return errMissingFile
+	}
+
+	db, _, closeDB, err := dst.open(false)
+	if err != nil {
+		return err
+	}
+	pairs, err := backup.RestoreFile(db, path)
+	if closeErr := closeDB(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("restored %d keys\n", pairs)
+	return nil
+}
@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// gecko-db inspects and maintains the database of a node that isn't running,
+// as in `gecko-db count --db-dir=db/mainnet --prefix-db=keystore`
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/badgerdb"
+	"github.com/ava-labs/gecko/database/leveldb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+)
+
+// Types of the databases that can be opened
+const (
+	leveldbType  = "leveldb"
+	badgerdbType = "badgerdb"
+	rocksdbType  = "rocksdb"
+)
+
+var (
+	errMissingDir  = errors.New("the database directory must be set with --db-dir")
+	errMissingFile = errors.New("the backup file must be set with --file")
+)
+
+// commands are the subcommands, by name
+var commands = map[string]struct {
+	usage string
+	run   func(args []string) error
+}{
+	"dump":    {"print the keys and values of the database, in hex", runDump},
+	"count":   {"print the number of keys of the database", runCount},
+	"stats":   {"print the number of keys and bytes under each prefix of the database", runStats},
+	"compact": {"compact the database", runCompact},
+	"copy":    {"copy the keys and values of the database to another database, which may be of another type", runCopy},
+	"backup":  {"write a backup of the database to a file", runBackup},
+	"restore": {"restore a backup into the database, which must be empty", runRestore},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := command.run(os.Args[2:]); err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "gecko-db %s: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gecko-db <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range []string{"dump", "count", "stats", "compact", "copy", "backup", "restore"} {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "run `gecko-db <command> --help` for the flags of a command")
+}
+
+// dbFlags are the flags that select a database
+type dbFlags struct {
+	dbType    string
+	dir       string
+	prefixDBs string
+	prefix    string
+}
+
+// register registers the flags of [f] in [fs]. Their names start with [name].
+// The key prefix flag is only registered if [withPrefix].
+func (f *dbFlags) register(fs *flag.FlagSet, name string, withPrefix bool) {
+	fs.StringVar(&f.dbType, name+"-type", leveldbType, fmt.Sprintf("Type of the database. Should be one of {%s, %s, %s}. %s is only available if gecko-db was built with the %s build tag", leveldbType, badgerdbType, rocksdbType, rocksdbType, rocksdbType))
+	fs.StringVar(&f.dir, name+"-dir", "", "Directory of the database, such as db/mainnet for a LevelDB database, or db/mainnet/badgerdb for a BadgerDB database")
+	fs.StringVar(&f.prefixDBs, name+"-prefix-db", "", "Comma separated list of the names of nested prefixed databases to open in the database, such as keystore")
+	if withPrefix {
+		fs.StringVar(&f.prefix, name+"-prefix", "", "Hex encoded prefix of the keys to use in the database")
+	}
+}
+
+// open opens the database selected by [f]. If [readOnly], a LevelDB database
+// is opened without its lock. The returned function closes the database.
+func (f *dbFlags) open(readOnly bool) (database.Database, []byte, func() error, error) {
+	if f.dir == "" {
+		return nil, nil, nil, errMissingDir
+	}
+	prefix, err := hex.DecodeString(f.prefix)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("couldn't parse prefix: %w", err)
+	}
+
+	var db database.Database
+	switch f.dbType {
+	case leveldbType:
+		if readOnly {
+			db, err = leveldb.NewReadOnly(f.dir, leveldb.DefaultConfig)
+		} else {
+			db, err = leveldb.New(f.dir, leveldb.DefaultConfig)
+		}
+	case badgerdbType:
+		db, err = badgerdb.New(f.dir)
+	case rocksdbType:
+		db, err = openRocksDB(f.dir)
+	default:
+		err = fmt.Errorf("unknown database type %q. Should be one of {%s, %s, %s}", f.dbType, leveldbType, badgerdbType, rocksdbType)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	base := db
+	if f.prefixDBs != "" {
+		for _, name := range strings.Split(f.prefixDBs, ",") {
+			db = prefixdb.New([]byte(name), db)
+		}
+	}
+	return db, prefix, base.Close, nil
+}
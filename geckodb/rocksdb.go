@@ -0,0 +1,17 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build rocksdb
+// +build rocksdb
+
+package main
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/rocksdb"
+)
+
+// openRocksDB opens the RocksDB database stored in [dir]
+func openRocksDB(dir string) (database.Database, error) {
+	return rocksdb.New(dir, rocksdb.DefaultConfig)
+}
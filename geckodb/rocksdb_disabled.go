@@ -0,0 +1,20 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+//go:build !rocksdb
+// +build !rocksdb
+
+package main
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/database"
+)
+
+var errNoRocksDB = errors.New("gecko-db was built without RocksDB. Build it with the rocksdb build tag to use RocksDB")
+
+// openRocksDB fails, as RocksDB isn't linked into gecko-db
+func openRocksDB(string) (database.Database, error) {
+	return nil, errNoRocksDB
+}
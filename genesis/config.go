@@ -0,0 +1,188 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/vms/platformvm"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+var (
+	errNoValidators  = errors.New("genesis must have at least one validator")
+	errNoValidatorID = errors.New("genesis validator must have a node ID")
+	errNoChainVM     = errors.New("genesis chain must specify a VM")
+	errNoChainName   = errors.New("genesis chain must have a name")
+	errNoNetworkID   = errors.New("custom genesis must specify a network ID")
+)
+
+// Spec describes the genesis state of a network: the accounts, default subnet
+// validators and chains that exist at genesis, and the genesis time. It has
+// the same format as the arguments of the Platform Chain's buildGenesis API.
+type Spec = platformvm.BuildGenesisArgs
+
+// Chain is a chain that exists at a network's genesis
+type Chain struct {
+	ID   ids.ID `json:"id"`
+	VMID ids.ID `json:"vmID"`
+	Name string `json:"name"`
+}
+
+// Validator is a default subnet validator at a network's genesis
+type Validator struct {
+	ID      ids.ShortID  `json:"id"`
+	Weight  cjson.Uint64 `json:"weight"`
+	EndTime cjson.Uint64 `json:"endTime"`
+}
+
+// Network is the genesis of a network
+type Network struct {
+	// Bytes is the genesis data of the Platform Chain, and thereby the network
+	Bytes      formatting.CB58 `json:"bytes"`
+	Chains     []Chain         `json:"chains"`
+	Validators []Validator     `json:"validators"`
+}
+
+// Build returns the genesis of the network [networkID] described by [spec].
+// The network ID in [spec], if any, is replaced with [networkID].
+func Build(networkID uint32, spec *Spec) (*Network, error) {
+	args := *spec
+	args.NetworkID = cjson.Uint32(networkID)
+
+	if len(args.Validators) == 0 {
+		return nil, errNoValidators
+	}
+	validatorIDs := ids.ShortSet{}
+	for _, validator := range args.Validators {
+		if validator.ID.IsZero() {
+			return nil, errNoValidatorID
+		}
+		if validatorIDs.Contains(validator.ID) {
+			return nil, fmt.Errorf("genesis validator %s is listed more than once", validator.ID)
+		}
+		validatorIDs.Add(validator.ID)
+	}
+	for _, chain := range args.Chains {
+		switch {
+		case chain.VMID.IsZero():
+			return nil, errNoChainVM
+		case chain.Name == "":
+			return nil, errNoChainName
+		}
+	}
+
+	reply := platformvm.BuildGenesisReply{}
+	if err := (&platformvm.StaticService{}).BuildGenesis(nil, &args, &reply); err != nil {
+		return nil, err
+	}
+
+	genesis := platformvm.Genesis{}
+	if _, err := platformvm.Codec.Unmarshal(reply.Bytes.Bytes, &genesis); err != nil {
+		return nil, err
+	}
+	if err := genesis.Initialize(); err != nil {
+		return nil, err
+	}
+
+	network := &Network{Bytes: reply.Bytes}
+	for _, chain := range genesis.Chains {
+		network.Chains = append(network.Chains, Chain{
+			ID:   chain.ID(),
+			VMID: chain.VMID,
+			Name: chain.ChainName,
+		})
+	}
+	for _, tx := range genesis.Validators.Txs {
+		vdr := tx.Vdr()
+		network.Validators = append(network.Validators, Validator{
+			ID:      vdr.ID(),
+			Weight:  cjson.Uint64(vdr.Weight()),
+			EndTime: cjson.Uint64(tx.EndTime().Unix()),
+		})
+	}
+	return network, nil
+}
+
+// FromFile returns the genesis data of the network [networkID] described by
+// the JSON file at [path]. See FromJSON for the file's format.
+func FromFile(networkID uint32, path string) ([]byte, error) {
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	genesisBytes, err := FromJSON(networkID, jsonBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", path, err)
+	}
+	return genesisBytes, nil
+}
+
+// FromJSON returns the genesis data of the network [networkID] described by
+// [jsonBytes], which is a JSON encoded Spec
+func FromJSON(networkID uint32, jsonBytes []byte) ([]byte, error) {
+	spec := Spec{}
+	if err := json.Unmarshal(jsonBytes, &spec); err != nil {
+		return nil, err
+	}
+	network, err := Build(networkID, &spec)
+	if err != nil {
+		return nil, err
+	}
+	return network.Bytes.Bytes, nil
+}
+
+// CustomFromFile returns the ID and genesis data of the custom network
+// described by the JSON file at [path]. See CustomFromJSON for the file's
+// format.
+func CustomFromFile(path string) (uint32, []byte, error) {
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	networkID, genesisBytes, err := CustomFromJSON(jsonBytes)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid genesis file %s: %w", path, err)
+	}
+	return networkID, genesisBytes, nil
+}
+
+// CustomFromJSON returns the ID and genesis data of the custom network
+// described by [jsonBytes], which is a JSON encoded Spec. The network ID is the
+// one in the Spec, and may not be the ID of a network with a hardcoded
+// genesis.
+func CustomFromJSON(jsonBytes []byte) (uint32, []byte, error) {
+	spec := Spec{}
+	if err := json.Unmarshal(jsonBytes, &spec); err != nil {
+		return 0, nil, err
+	}
+	networkID, err := CustomNetworkID(&spec)
+	if err != nil {
+		return 0, nil, err
+	}
+	network, err := Build(networkID, &spec)
+	if err != nil {
+		return 0, nil, err
+	}
+	return networkID, network.Bytes.Bytes, nil
+}
+
+// CustomNetworkID returns the network ID of the custom network described by
+// [spec]
+func CustomNetworkID(spec *Spec) (uint32, error) {
+	networkID := uint32(spec.NetworkID)
+	if networkID == 0 {
+		return 0, errNoNetworkID
+	}
+	if name, exists := NetworkIDToNetworkName[networkID]; exists {
+		return 0, fmt.Errorf("custom genesis can't use the network ID %d of %s", networkID, name)
+	}
+	return networkID, nil
+}
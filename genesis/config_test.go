@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ava-labs/gecko/vms/platformvm"
+	"github.com/ava-labs/gecko/vms/timestampvm"
+)
+
+func TestFromJSON(t *testing.T) {
+	genesisJSON := fmt.Sprintf(`{
+		"accounts": [{"address": "%s", "balance": "1000"}],
+		"defaultSubnetValidators": [{
+			"id": "%s",
+			"destination": "%s",
+			"endtime": "2000",
+			"weight": "10"
+		}],
+		"chains": [{"vmID": "%s", "name": "Timestamp"}],
+		"time": "1000"
+	}`, Addresses[0], StakerIDs[0], Addresses[0], timestampvm.ID)
+
+	genesisBytes, err := FromJSON(5, []byte(genesisJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	genesis := platformvm.Genesis{}
+	if _, err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
+		t.Fatal(err)
+	}
+	if err := genesis.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case len(genesis.Accounts) != 1:
+		t.Fatalf("Genesis should have 1 account but has %d", len(genesis.Accounts))
+	case genesis.Validators.Len() != 1:
+		t.Fatalf("Genesis should have 1 validator but has %d", genesis.Validators.Len())
+	case len(genesis.Chains) != 1:
+		t.Fatalf("Genesis should have 1 chain but has %d", len(genesis.Chains))
+	case genesis.Chains[0].NetworkID != 5:
+		t.Fatalf("Genesis chain should be on network 5 but is on %d", genesis.Chains[0].NetworkID)
+	}
+
+	_, chainAliases, _ := GenesisAliases(genesisBytes)
+	if aliases := chainAliases[genesis.Chains[0].ID().Key()]; len(aliases) != 1 || aliases[0] != "timestamp" {
+		t.Fatalf("Genesis chain has aliases %v", aliases)
+	}
+}
+
+func TestFromJSONInvalid(t *testing.T) {
+	validator := fmt.Sprintf(`{"id": "%s", "destination": "%s", "endtime": "2000", "weight": "10"}`, StakerIDs[0], Addresses[0])
+	tests := map[string]string{
+		"malformed":           `{`,
+		"no validators":       `{"time": "1000"}`,
+		"duplicate validator": `{"defaultSubnetValidators": [` + validator + `,` + validator + `], "time": "1000"}`,
+		"chain without a VM":  `{"defaultSubnetValidators": [` + validator + `], "chains": [{"name": "chain"}], "time": "1000"}`,
+		"expired validator":   `{"defaultSubnetValidators": [` + validator + `], "time": "3000"}`,
+	}
+	for name, genesisJSON := range tests {
+		if _, err := FromJSON(5, []byte(genesisJSON)); err == nil {
+			t.Fatalf("Should have errored due to the genesis being %s", name)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	spec := Spec{}
+	specJSON := fmt.Sprintf(`{
+		"defaultSubnetValidators": [
+			{"id": "%s", "destination": "%s", "endtime": "2000", "weight": "10"},
+			{"id": "%s", "destination": "%s", "endtime": "3000", "weight": "20"}
+		],
+		"chains": [{"vmID": "%s", "name": "Timestamp"}],
+		"time": "1000"
+	}`, StakerIDs[0], Addresses[0], StakerIDs[1], Addresses[0], timestampvm.ID)
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := Build(5, &spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Validators) != 2 {
+		t.Fatalf("Genesis should have 2 validators but has %d", len(network.Validators))
+	}
+	weight := uint64(0)
+	for _, validator := range network.Validators {
+		weight += uint64(validator.Weight)
+	}
+	if weight != 30 {
+		t.Fatalf("Genesis validators should have weight 30 but have %d", weight)
+	}
+	if len(network.Chains) != 1 || !network.Chains[0].VMID.Equals(timestampvm.ID) || network.Chains[0].Name != "Timestamp" {
+		t.Fatalf("Genesis has the wrong chains %v", network.Chains)
+	}
+
+	// The chain IDs are the IDs of the chains created by the genesis data
+	genesisBytes, err := FromJSON(5, []byte(specJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, chainAliases, _ := GenesisAliases(genesisBytes)
+	if _, exists := chainAliases[network.Chains[0].ID.Key()]; !exists {
+		t.Fatalf("Genesis chain %s isn't created by the genesis data", network.Chains[0].ID)
+	}
+	if string(genesisBytes) != string(network.Bytes.Bytes) {
+		t.Fatalf("Building the same spec twice should have produced the same genesis data")
+	}
+}
+
+func TestCustomFromJSON(t *testing.T) {
+	validator := fmt.Sprintf(`{"id": "%s", "destination": "%s", "endtime": "2000", "weight": "10"}`, StakerIDs[0], Addresses[0])
+
+	networkID, genesisBytes, err := CustomFromJSON([]byte(`{"networkID": "42", "defaultSubnetValidators": [` + validator + `], "time": "1000"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if networkID != 42 {
+		t.Fatalf("Network ID should be 42 but is %d", networkID)
+	}
+	genesis := platformvm.Genesis{}
+	if _, err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"missing a network ID":      `{"defaultSubnetValidators": [` + validator + `], "time": "1000"}`,
+		"using a hardcoded network": fmt.Sprintf(`{"networkID": "%d", "defaultSubnetValidators": [`+validator+`], "time": "1000"}`, MainnetID),
+	}
+	for name, genesisJSON := range tests {
+		if _, _, err := CustomFromJSON([]byte(genesisJSON)); err == nil {
+			t.Fatalf("Should have errored due to the genesis %s", name)
+		}
+	}
+}
@@ -38,6 +38,10 @@ const (
 	TestnetName  = "testnet"
 	BorealisName = "borealis"
 	LocalName    = "local"
+
+	// CustomName is the network name of a network whose ID and genesis are
+	// defined by a genesis file
+	CustomName = "custom"
 )
 
 var (
@@ -128,6 +132,12 @@ func NetworkID(networkName string) (uint32, error) {
 
 // Aliases returns the default aliases based on the network ID
 func Aliases(networkID uint32) (generalAliases map[string][]string, chainAliases map[[32]byte][]string, vmAliases map[[32]byte][]string) {
+	return GenesisAliases(Genesis(networkID))
+}
+
+// GenesisAliases returns the default aliases of the network whose genesis data
+// is [genesisBytes]
+func GenesisAliases(genesisBytes []byte) (generalAliases map[string][]string, chainAliases map[[32]byte][]string, vmAliases map[[32]byte][]string) {
 	generalAliases = map[string][]string{
 		"vm/" + platformvm.ID.String():  []string{"vm/platform"},
 		"vm/" + avm.ID.String():         []string{"vm/avm"},
@@ -149,7 +159,6 @@ func Aliases(networkID uint32) (generalAliases map[string][]string, chainAliases
 		timestampvm.ID.Key(): []string{"timestamp"},
 	}
 
-	genesisBytes := Genesis(networkID)
 	genesis := &platformvm.Genesis{}                  // TODO let's not re-create genesis to do aliasing
 	platformvm.Codec.Unmarshal(genesisBytes, genesis) // TODO check for error
 	genesis.Initialize()
@@ -108,7 +108,7 @@ func TestAliases(t *testing.T) {
 func TestGenesis(t *testing.T) {
 	genesisBytes := Genesis(LocalID)
 	genesis := platformvm.Genesis{}
-	if err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
+	if _, err := platformvm.Codec.Unmarshal(genesisBytes, &genesis); err != nil {
 		t.Fatal(err)
 	}
 }
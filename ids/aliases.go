@@ -52,3 +52,12 @@ func (a Aliaser) Alias(id ID, alias string) error {
 	a.aliases[key] = append(a.aliases[key], alias)
 	return nil
 }
+
+// RemoveAliases of [id]
+func (a Aliaser) RemoveAliases(id ID) {
+	key := id.Key()
+	for _, alias := range a.aliases[key] {
+		delete(a.dealias, alias)
+	}
+	delete(a.aliases, key)
+}
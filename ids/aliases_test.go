@@ -0,0 +1,41 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+func TestAliaserRemoveAliases(t *testing.T) {
+	aliaser := Aliaser{}
+	aliaser.Initialize()
+
+	id0 := NewID([32]byte{0})
+	id1 := NewID([32]byte{1})
+	if err := aliaser.Alias(id0, "X"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aliaser.Alias(id0, "avm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aliaser.Alias(id1, "P"); err != nil {
+		t.Fatal(err)
+	}
+
+	aliaser.RemoveAliases(id0)
+	if _, err := aliaser.Lookup("X"); err == nil {
+		t.Fatalf("Removed alias shouldn't have been found")
+	}
+	if aliases := aliaser.Aliases(id0); len(aliases) != 0 {
+		t.Fatalf("Removed ID still has aliases %v", aliases)
+	}
+	if id, err := aliaser.Lookup("P"); err != nil || !id.Equals(id1) {
+		t.Fatalf("Other IDs should have kept their aliases")
+	}
+
+	// A removed alias can be reused
+	if err := aliaser.Alias(id1, "X"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -79,11 +79,14 @@ func (b *Bag) Len() int { return b.size }
 
 // List returns a list of all ids that have been added.
 func (b *Bag) List() []ID {
-	idList := []ID(nil)
+	if len(b.counts) == 0 {
+		return nil
+	}
+	keys := make([][32]byte, 0, len(b.counts))
 	for id := range b.counts {
-		idList = append(idList, NewID(id))
+		keys = append(keys, id)
 	}
-	return idList
+	return newIDs(keys)
 }
 
 // Mode returns the id that has been seen the most and the number of times it
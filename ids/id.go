@@ -27,6 +27,16 @@ type ID struct {
 // NewID creates an identifer from a 32 byte hash
 func NewID(id [32]byte) ID { return ID{ID: &id} }
 
+// newIDs returns the IDs of [keys]. The IDs point into [keys], so the list is
+// allocated at once rather than one ID at a time.
+func newIDs(keys [][32]byte) []ID {
+	idList := make([]ID, len(keys))
+	for i := range keys {
+		idList[i] = ID{ID: &keys[i]}
+	}
+	return idList
+}
+
 // ToID attempt to convert a byte slice into an id
 func ToID(bytes []byte) (ID, error) {
 	addrHash, err := hashing.ToHash256(bytes)
@@ -0,0 +1,104 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"sync"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// DefaultInternSize is the number of IDs of each kind that the default interner
+// keeps
+const DefaultInternSize = 1 << 16
+
+var defaultInterner = NewInterner(DefaultInternSize)
+
+// Interner returns the same backing array for equal IDs, so that an ID that is
+// parsed many times, such as the ID of a container gossiped by every peer, is
+// only allocated once.
+//
+// At most [maxSize] IDs of each kind are kept. When that limit is reached,
+// the interner is emptied, so memory use is bounded and IDs that are no longer
+// seen are eventually released.
+type Interner struct {
+	lock     sync.Mutex
+	maxSize  int
+	ids      map[[32]byte]*[32]byte
+	shortIDs map[[20]byte]*[20]byte
+}
+
+// NewInterner returns an interner that keeps at most [maxSize] IDs of each kind
+func NewInterner(maxSize int) *Interner {
+	if maxSize <= 0 {
+		maxSize = 1
+	}
+	return &Interner{
+		maxSize:  maxSize,
+		ids:      make(map[[32]byte]*[32]byte),
+		shortIDs: make(map[[20]byte]*[20]byte),
+	}
+}
+
+// ID returns the interned ID of [id]
+func (i *Interner) ID(id [32]byte) ID {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if interned, ok := i.ids[id]; ok {
+		return ID{ID: interned}
+	}
+	if len(i.ids) >= i.maxSize {
+		i.ids = make(map[[32]byte]*[32]byte)
+	}
+	interned := &id
+	i.ids[id] = interned
+	return ID{ID: interned}
+}
+
+// ShortID returns the interned ShortID of [id]
+func (i *Interner) ShortID(id [20]byte) ShortID {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if interned, ok := i.shortIDs[id]; ok {
+		return ShortID{ID: interned}
+	}
+	if len(i.shortIDs) >= i.maxSize {
+		i.shortIDs = make(map[[20]byte]*[20]byte)
+	}
+	interned := &id
+	i.shortIDs[id] = interned
+	return ShortID{ID: interned}
+}
+
+// ToID is the same as the package's ToID, but returns an interned ID
+func (i *Interner) ToID(bytes []byte) (ID, error) {
+	hash, err := hashing.ToHash256(bytes)
+	if err != nil {
+		return ID{}, err
+	}
+	return i.ID(hash), nil
+}
+
+// ToShortID is the same as the package's ToShortID, but returns an interned ID
+func (i *Interner) ToShortID(bytes []byte) (ShortID, error) {
+	hash, err := hashing.ToHash160(bytes)
+	if err != nil {
+		return ShortID{}, err
+	}
+	return i.ShortID(hash), nil
+}
+
+// Intern returns the ID of [id] from the default interner
+func Intern(id [32]byte) ID { return defaultInterner.ID(id) }
+
+// InternShort returns the ShortID of [id] from the default interner
+func InternShort(id [20]byte) ShortID { return defaultInterner.ShortID(id) }
+
+// ToInternedID converts [bytes] into an ID from the default interner
+func ToInternedID(bytes []byte) (ID, error) { return defaultInterner.ToID(bytes) }
+
+// ToInternedShortID converts [bytes] into a ShortID from the default interner
+func ToInternedShortID(bytes []byte) (ShortID, error) { return defaultInterner.ToShortID(bytes) }
@@ -0,0 +1,96 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+func TestInterner(t *testing.T) {
+	interner := NewInterner(2)
+
+	id0 := interner.ID([32]byte{0})
+	id1 := interner.ID([32]byte{1})
+	if !id0.Equals(NewID([32]byte{0})) || !id1.Equals(NewID([32]byte{1})) {
+		t.Fatalf("Interned IDs have the wrong values")
+	}
+	if id := interner.ID([32]byte{0}); id.ID != id0.ID {
+		t.Fatalf("Equal IDs should have shared their backing array")
+	}
+
+	// The interner is full, so it is emptied before adding a new ID
+	interner.ID([32]byte{2})
+	if id := interner.ID([32]byte{0}); id.ID == id0.ID {
+		t.Fatalf("The interner should have been emptied")
+	}
+	if len(interner.ids) > 2 {
+		t.Fatalf("The interner kept %d IDs, more than its limit", len(interner.ids))
+	}
+
+	short0 := interner.ShortID([20]byte{0})
+	if id := interner.ShortID([20]byte{0}); id.ID != short0.ID {
+		t.Fatalf("Equal ShortIDs should have shared their backing array")
+	}
+}
+
+func TestInternerToID(t *testing.T) {
+	interner := NewInterner(DefaultInternSize)
+
+	if _, err := interner.ToID([]byte{1}); err == nil {
+		t.Fatalf("Should have errored due to the wrong length")
+	}
+	bytes := make([]byte, 32)
+	bytes[0] = 1
+	id, err := interner.ToID(bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !id.Equals(NewID([32]byte{1})) {
+		t.Fatalf("Wrong ID %s", id)
+	}
+	if _, err := interner.ToShortID(bytes); err == nil {
+		t.Fatalf("Should have errored due to the wrong length")
+	}
+}
+
+func TestSetListAllocations(t *testing.T) {
+	set := Set{}
+	for i := 0; i < 100; i++ {
+		set.Add(NewID([32]byte{byte(i)}))
+	}
+
+	list := set.List()
+	listed := Set{}
+	listed.Add(list...)
+	if !listed.Equals(set) {
+		t.Fatalf("Listed the wrong IDs")
+	}
+
+	// The IDs and their backing array are each allocated once
+	if allocs := testing.AllocsPerRun(10, func() { set.List() }); allocs > 2 {
+		t.Fatalf("Listing the set took %v allocations", allocs)
+	}
+}
+
+func BenchmarkSetList(b *testing.B) {
+	set := Set{}
+	for i := 0; i < 1000; i++ {
+		set.Add(NewID([32]byte{byte(i), byte(i >> 8)}))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.List()
+	}
+}
+
+func BenchmarkIntern(b *testing.B) {
+	interner := NewInterner(DefaultInternSize)
+	id := [32]byte{1}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interner.ID(id)
+	}
+}
@@ -70,11 +70,14 @@ func (ids *Set) Clear() { *ids = nil }
 
 // List converts this set into a list
 func (ids Set) List() []ID {
-	idList := []ID(nil)
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([][32]byte, 0, len(ids))
 	for id := range ids {
-		idList = append(idList, NewID(id))
+		keys = append(keys, id)
 	}
-	return idList
+	return newIDs(keys)
 }
 
 // Equals returns true if the sets contain the same elements
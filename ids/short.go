@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"encoding/hex"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/formatting"
@@ -24,6 +26,16 @@ type ShortID struct {
 // NewShortID creates an identifer from a 20 byte hash
 func NewShortID(id [20]byte) ShortID { return ShortID{ID: &id} }
 
+// newShortIDs returns the IDs of [keys]. The IDs point into [keys], so the list
+// is allocated at once rather than one ID at a time.
+func newShortIDs(keys [][20]byte) []ShortID {
+	idList := make([]ShortID, len(keys))
+	for i := range keys {
+		idList[i] = ShortID{ID: &keys[i]}
+	}
+	return idList
+}
+
 // ToShortID attempt to convert a byte slice into an id
 func ToShortID(bytes []byte) (ShortID, error) {
 	addrHash, err := hashing.ToHash160(bytes)
@@ -54,11 +66,16 @@ func (id *ShortID) UnmarshalJSON(b []byte) error {
 	if string(b) == "null" {
 		return nil
 	}
+	idBytes := []byte(nil)
 	cb58 := formatting.CB58{}
-	if err := cb58.UnmarshalJSON(b); err != nil {
+	if err := cb58.UnmarshalJSON(b); err == nil {
+		idBytes = cb58.Bytes
+	} else if addr, bech32Err := bech32Bytes(b); bech32Err == nil {
+		idBytes = addr
+	} else {
 		return err
 	}
-	newID, err := ToShortID(cb58.Bytes)
+	newID, err := ToShortID(idBytes)
 	if err != nil {
 		return err
 	}
@@ -66,6 +83,21 @@ func (id *ShortID) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// bech32Bytes returns the bytes of the quoted bech32 address [b], which may be
+// prefixed by a chain alias
+func bech32Bytes(b []byte) ([]byte, error) {
+	str, err := strconv.Unquote(string(b))
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(str, formatting.AddressSep) {
+		_, _, addr, err := formatting.ParseAddress(str)
+		return addr, err
+	}
+	_, addr, err := formatting.ParseBech32(str)
+	return addr, err
+}
+
 // IsZero returns true if the value has not been initialized
 func (id ShortID) IsZero() bool { return id.ID == nil }
 
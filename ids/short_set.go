@@ -52,25 +52,22 @@ func (ids *ShortSet) Clear() { *ids = nil }
 
 // CappedList returns a list of length at most [size]. Size should be >= 0
 func (ids ShortSet) CappedList(size int) []ShortID {
-	idList := make([]ShortID, size)[:0]
+	if len(ids) < size {
+		size = len(ids)
+	}
+	keys := make([][20]byte, 0, size)
 	for id := range ids {
 		if size <= 0 {
 			break
 		}
 		size--
-		idList = append(idList, NewShortID(id))
+		keys = append(keys, id)
 	}
-	return idList
+	return newShortIDs(keys)
 }
 
 // List converts this set into a list
-func (ids ShortSet) List() []ShortID {
-	idList := make([]ShortID, len(ids))[:0]
-	for id := range ids {
-		idList = append(idList, NewShortID(id))
-	}
-	return idList
-}
+func (ids ShortSet) List() []ShortID { return ids.CappedList(len(ids)) }
 
 // Equals returns true if the sets contain the same elements
 func (ids ShortSet) Equals(oIDs ShortSet) bool {
@@ -0,0 +1,45 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ids
+
+import (
+	"testing"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func TestShortFromString(t *testing.T) {
+	id := NewShortID([20]byte{
+		0x3d, 0x0a, 0xd1, 0x2b, 0x8e, 0xe8, 0x92, 0x8e, 0xdf, 0x24,
+		0x8c, 0xa9, 0x1c, 0xa5, 0x56, 0x00, 0xfb, 0x38, 0x3f, 0x07,
+	})
+	parsed, err := ShortFromString(id.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Equals(id) {
+		t.Fatalf("Expected %s, got %s", id, parsed)
+	}
+}
+
+// The string form of a ShortID includes a checksum, so a node ID or address
+// with a mistyped character is rejected rather than parsed as another ID
+func TestShortFromStringRejectsTypos(t *testing.T) {
+	idStr := NewShortID([20]byte{
+		0x3d, 0x0a, 0xd1, 0x2b, 0x8e, 0xe8, 0x92, 0x8e, 0xdf, 0x24,
+		0x8c, 0xa9, 0x1c, 0xa5, 0x56, 0x00, 0xfb, 0x38, 0x3f, 0x07,
+	}).String()
+
+	for i := 0; i < len(idStr); i++ {
+		for _, c := range []byte(base58Alphabet) {
+			if c == idStr[i] {
+				continue
+			}
+			typo := idStr[:i] + string(c) + idStr[i+1:]
+			if _, err := ShortFromString(typo); err == nil {
+				t.Fatalf("Should have rejected %s, which differs from %s at index %d", typo, idStr, i)
+			}
+		}
+	}
+}
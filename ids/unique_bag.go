@@ -64,11 +64,14 @@ func (b *UniqueBag) GetSet(id ID) BitSet { return (*b)[*id.ID] }
 
 // List ...
 func (b *UniqueBag) List() []ID {
-	idList := []ID(nil)
+	if len(*b) == 0 {
+		return nil
+	}
+	keys := make([][32]byte, 0, len(*b))
 	for id := range *b {
-		idList = append(idList, NewID(id))
+		keys = append(keys, id)
 	}
-	return idList
+	return newIDs(keys)
 }
 
 // Bag ...
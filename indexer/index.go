@@ -0,0 +1,207 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	indexToContainerPrefix = []byte("indexToContainer")
+	containerToIndexPrefix = []byte("containerToIndex")
+	metadataPrefix         = []byte("metadata")
+
+	nextIndexKey = []byte("nextIndex")
+
+	errNoContainers = errors.New("no containers have been accepted")
+)
+
+// Container is a container accepted by a chain
+type Container struct {
+	ID        ids.ID
+	Bytes     []byte
+	Timestamp uint64 // Unix time the container was indexed at
+	Index     uint64 // Position of the container in acceptance order
+}
+
+// index stores the containers accepted by a chain, in the order they were
+// accepted
+type index struct {
+	lock  sync.RWMutex
+	clock timer.Clock
+
+	db               *versiondb.Database
+	indexToContainer database.Database
+	containerToIndex database.Database
+	metadata         database.Database
+
+	// Index of the next container to be accepted
+	nextIndex uint64
+}
+
+// newIndex returns the index stored in [db]
+func newIndex(db database.Database) (*index, error) {
+	vdb := versiondb.New(db)
+	i := &index{
+		db:               vdb,
+		indexToContainer: prefixdb.New(indexToContainerPrefix, vdb),
+		containerToIndex: prefixdb.New(containerToIndexPrefix, vdb),
+		metadata:         prefixdb.New(metadataPrefix, vdb),
+	}
+
+	nextIndexBytes, err := i.metadata.Get(nextIndexKey)
+	if err == database.ErrNotFound {
+		return i, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p := wrappers.Packer{Bytes: nextIndexBytes}
+	i.nextIndex = p.UnpackLong()
+	return i, p.Err
+}
+
+// Accept implements the triggers.Acceptor interface
+func (i *index) Accept(chainID, containerID ids.ID, container []byte) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	// Containers are only indexed once, even if their acceptance is reported
+	// again
+	if has, err := i.containerToIndex.Has(containerID.Bytes()); err != nil {
+		return err
+	} else if has {
+		return nil
+	}
+
+	indexBytes := packLong(i.nextIndex)
+	p := wrappers.Packer{MaxSize: hashing.HashLen + wrappers.LongLen + wrappers.IntLen + len(container)}
+	p.PackFixedBytes(containerID.Bytes())
+	p.PackLong(i.clock.Unix())
+	p.PackBytes(container)
+	if p.Errored() {
+		return p.Err
+	}
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		i.indexToContainer.Put(indexBytes, p.Bytes),
+		i.containerToIndex.Put(containerID.Bytes(), indexBytes),
+		i.metadata.Put(nextIndexKey, packLong(i.nextIndex+1)),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	// The container, its index and the new number of containers are written
+	// atomically
+	if err := i.db.Commit(); err != nil {
+		return err
+	}
+	i.nextIndex++
+	return nil
+}
+
+// NumAccepted returns the number of containers that were indexed
+func (i *index) NumAccepted() uint64 {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return i.nextIndex
+}
+
+// LastAccepted returns the most recently indexed container
+func (i *index) LastAccepted() (Container, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if i.nextIndex == 0 {
+		return Container{}, errNoContainers
+	}
+	return i.containerByIndex(i.nextIndex - 1)
+}
+
+// ContainerByIndex returns the container at position [index]
+func (i *index) ContainerByIndex(index uint64) (Container, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return i.containerByIndex(index)
+}
+
+// ContainerRange returns at most [numToFetch] containers, starting at position
+// [startIndex]
+func (i *index) ContainerRange(startIndex, numToFetch uint64) ([]Container, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if startIndex >= i.nextIndex {
+		return nil, fmt.Errorf("start index %d must be less than the number of accepted containers, %d", startIndex, i.nextIndex)
+	}
+	if remaining := i.nextIndex - startIndex; numToFetch > remaining {
+		numToFetch = remaining
+	}
+
+	containers := make([]Container, numToFetch)
+	for j := range containers {
+		container, err := i.containerByIndex(startIndex + uint64(j))
+		if err != nil {
+			return nil, err
+		}
+		containers[j] = container
+	}
+	return containers, nil
+}
+
+// Index returns the position of the container [containerID]
+func (i *index) Index(containerID ids.ID) (uint64, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	indexBytes, err := i.containerToIndex.Get(containerID.Bytes())
+	if err == database.ErrNotFound {
+		return 0, fmt.Errorf("container %s isn't indexed", containerID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	p := wrappers.Packer{Bytes: indexBytes}
+	index := p.UnpackLong()
+	return index, p.Err
+}
+
+func (i *index) containerByIndex(index uint64) (Container, error) {
+	containerBytes, err := i.indexToContainer.Get(packLong(index))
+	if err == database.ErrNotFound {
+		return Container{}, fmt.Errorf("no container at index %d", index)
+	}
+	if err != nil {
+		return Container{}, err
+	}
+
+	p := wrappers.Packer{Bytes: containerBytes}
+	containerID, _ := ids.ToID(p.UnpackFixedBytes(hashing.HashLen))
+	container := Container{
+		ID:        containerID,
+		Timestamp: p.UnpackLong(),
+		Bytes:     p.UnpackBytes(),
+		Index:     index,
+	}
+	return container, p.Err
+}
+
+func packLong(val uint64) []byte {
+	p := wrappers.Packer{Bytes: make([]byte, wrappers.LongLen)}
+	p.PackLong(val)
+	return p.Bytes
+}
@@ -0,0 +1,169 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/avalanche"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestIndexAccept(t *testing.T) {
+	db := memdb.New()
+	chainIndex, err := newIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainIndex.clock.Set(time.Unix(1000, 0))
+
+	if _, err := chainIndex.LastAccepted(); err == nil {
+		t.Fatalf("Should have errored with no accepted containers")
+	}
+
+	chainID := ids.Empty.Prefix(0)
+	containerIDs := []ids.ID{ids.Empty.Prefix(1), ids.Empty.Prefix(2), ids.Empty.Prefix(3)}
+	for i, containerID := range containerIDs {
+		if err := chainIndex.Accept(chainID, containerID, []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Reported acceptances of indexed containers are ignored
+	if err := chainIndex.Accept(chainID, containerIDs[0], []byte{0}); err != nil {
+		t.Fatal(err)
+	}
+
+	if numAccepted := chainIndex.NumAccepted(); numAccepted != 3 {
+		t.Fatalf("Should have indexed %d containers but indexed %d", 3, numAccepted)
+	}
+
+	last, err := chainIndex.LastAccepted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.ID.Equals(containerIDs[2]) || last.Index != 2 || last.Timestamp != 1000 || !bytes.Equal(last.Bytes, []byte{2}) {
+		t.Fatalf("Wrong last accepted container %+v", last)
+	}
+
+	index, err := chainIndex.Index(containerIDs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index != 1 {
+		t.Fatalf("Should have returned index %d but returned %d", 1, index)
+	}
+	if _, err := chainIndex.Index(ids.Empty.Prefix(4)); err == nil {
+		t.Fatalf("Should have errored on an unknown container")
+	}
+
+	containers, err := chainIndex.ContainerRange(1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 2 || !containers[0].ID.Equals(containerIDs[1]) || !containers[1].ID.Equals(containerIDs[2]) {
+		t.Fatalf("Wrong container range %+v", containers)
+	}
+	if _, err := chainIndex.ContainerRange(3, 1); err == nil {
+		t.Fatalf("Should have errored on a start index past the last container")
+	}
+
+	// The index should be restored from the database
+	chainIndex, err = newIndex(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numAccepted := chainIndex.NumAccepted(); numAccepted != 3 {
+		t.Fatalf("Should have restored %d containers but restored %d", 3, numAccepted)
+	}
+	container, err := chainIndex.ContainerByIndex(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !container.ID.Equals(containerIDs[0]) {
+		t.Fatalf("Restored the wrong container %s", container.ID)
+	}
+}
+
+func TestIndexerRegisterChain(t *testing.T) {
+	events := &triggers.EventDispatcher{}
+	events.Initialize(logging.NoLog{})
+	vertexEvents := &triggers.EventDispatcher{}
+	vertexEvents.Initialize(logging.NoLog{})
+
+	indexer := NewIndexer(logging.NoLog{}, memdb.New(), events, vertexEvents)
+
+	ctx := snow.DefaultContextTest()
+	indexer.RegisterChain(ctx, nil)
+
+	containerID := ids.Empty.Prefix(1)
+	events.Accept(ctx.ChainID, containerID, []byte{1})
+	// Decisions of other chains aren't indexed
+	events.Accept(ids.Empty.Prefix(2), ids.Empty.Prefix(3), []byte{3})
+
+	chainIndex, err := indexer.index(ctx.ChainID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numAccepted := chainIndex.NumAccepted(); numAccepted != 1 {
+		t.Fatalf("Should have indexed %d containers but indexed %d", 1, numAccepted)
+	}
+
+	indexer.DeregisterChain(ctx)
+	if _, err := indexer.index(ctx.ChainID, false); err == nil {
+		t.Fatalf("Shouldn't index a deregistered chain")
+	}
+}
+
+func TestIndexerRegisterAvalancheChain(t *testing.T) {
+	events := &triggers.EventDispatcher{}
+	events.Initialize(logging.NoLog{})
+	vertexEvents := &triggers.EventDispatcher{}
+	vertexEvents.Initialize(logging.NoLog{})
+
+	indexer := NewIndexer(logging.NoLog{}, memdb.New(), events, vertexEvents)
+
+	ctx := snow.DefaultContextTest()
+	indexer.RegisterChain(ctx, &avalanche.VMTest{})
+
+	events.Accept(ctx.ChainID, ids.Empty.Prefix(1), []byte{1})
+	vertexEvents.Accept(ctx.ChainID, ids.Empty.Prefix(2), []byte{2})
+	vertexEvents.Accept(ctx.ChainID, ids.Empty.Prefix(3), []byte{3})
+
+	decisionIndex, err := indexer.index(ctx.ChainID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numAccepted := decisionIndex.NumAccepted(); numAccepted != 1 {
+		t.Fatalf("Should have indexed %d decisions but indexed %d", 1, numAccepted)
+	}
+	vertexIndex, err := indexer.index(ctx.ChainID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numAccepted := vertexIndex.NumAccepted(); numAccepted != 2 {
+		t.Fatalf("Should have indexed %d vertices but indexed %d", 2, numAccepted)
+	}
+	if container, err := vertexIndex.LastAccepted(); err != nil {
+		t.Fatal(err)
+	} else if !container.ID.Equals(ids.Empty.Prefix(3)) {
+		t.Fatalf("Indexed the wrong last vertex %s", container.ID)
+	}
+
+	indexer.DeregisterChain(ctx)
+	if _, err := indexer.index(ctx.ChainID, true); err == nil {
+		t.Fatalf("Shouldn't index the vertices of a deregistered chain")
+	}
+
+	// Linear chains have no vertices
+	indexer.RegisterChain(ctx, nil)
+	if _, err := indexer.index(ctx.ChainID, true); err == nil {
+		t.Fatalf("Shouldn't index the vertices of a linear chain")
+	}
+}
@@ -0,0 +1,142 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/avalanche"
+	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const eventIdentifier = "indexer"
+
+// vertexPrefix is the prefix, in a chain's index database, of the index of
+// the chain's vertices
+var vertexPrefix = []byte("vertices")
+
+// Indexer stores the decisions accepted by each chain, in the order they were
+// accepted, and the vertices accepted by each Avalanche chain. Each chain's
+// index is kept under its own prefix of the indexer's database, which is
+// separate from the chains' databases, so the indexer can be enabled or
+// disabled without affecting consensus.
+//
+// Only the containers accepted while the indexer is enabled are indexed.
+type Indexer struct {
+	log          logging.Logger
+	db           database.Database
+	events       *triggers.EventDispatcher
+	vertexEvents *triggers.EventDispatcher
+
+	lock          sync.RWMutex
+	indexes       map[[32]byte]*index // chain ID --> the index of the chain's decisions
+	vertexIndexes map[[32]byte]*index // chain ID --> the index of the chain's vertices
+}
+
+// NewIndexer returns an indexer that stores its indexes in [db], receives
+// accepted decisions from [events] and receives accepted vertices from
+// [vertexEvents]
+func NewIndexer(log logging.Logger, db database.Database, events, vertexEvents *triggers.EventDispatcher) *Indexer {
+	return &Indexer{
+		log:           log,
+		db:            db,
+		events:        events,
+		vertexEvents:  vertexEvents,
+		indexes:       make(map[[32]byte]*index),
+		vertexIndexes: make(map[[32]byte]*index),
+	}
+}
+
+// RegisterChain implements the chains.Registrant interface
+func (i *Indexer) RegisterChain(ctx *snow.Context, vm interface{}) {
+	chainDB := prefixdb.New(ctx.ChainID.Bytes(), i.db)
+	if _, ok := vm.(avalanche.DAGVM); ok {
+		i.registerVertices(ctx, prefixdb.New(vertexPrefix, chainDB))
+	}
+
+	chainIndex, err := newIndex(chainDB)
+	if err != nil {
+		i.log.Error("couldn't load the index of chain %s due to %s", ctx.ChainID, err)
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	// The index is written synchronously, so that no decision is missed and
+	// decisions are stored in the order they were accepted
+	if err := i.events.RegisterChain(ctx.ChainID, eventIdentifier, chainIndex); err != nil {
+		i.log.Error("couldn't start indexing chain %s due to %s", ctx.ChainID, err)
+		return
+	}
+	i.indexes[ctx.ChainID.Key()] = chainIndex
+	i.log.Info("indexing chain %s, which has %d indexed decisions", ctx.ChainID, chainIndex.NumAccepted())
+}
+
+// registerVertices starts indexing the vertices of the Avalanche chain of
+// [ctx] in [db]
+func (i *Indexer) registerVertices(ctx *snow.Context, db database.Database) {
+	vertexIndex, err := newIndex(db)
+	if err != nil {
+		i.log.Error("couldn't load the vertex index of chain %s due to %s", ctx.ChainID, err)
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if err := i.vertexEvents.RegisterChain(ctx.ChainID, eventIdentifier, vertexIndex); err != nil {
+		i.log.Error("couldn't start indexing the vertices of chain %s due to %s", ctx.ChainID, err)
+		return
+	}
+	i.vertexIndexes[ctx.ChainID.Key()] = vertexIndex
+	i.log.Info("indexing the vertices of chain %s, which has %d indexed vertices", ctx.ChainID, vertexIndex.NumAccepted())
+}
+
+// DeregisterChain implements the chains.Deregistrant interface. The chain's
+// indexes are kept, so indexing resumes if the chain is created again.
+func (i *Indexer) DeregisterChain(ctx *snow.Context) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if _, ok := i.vertexIndexes[ctx.ChainID.Key()]; ok {
+		if err := i.vertexEvents.DeregisterChain(ctx.ChainID, eventIdentifier); err != nil {
+			i.log.Warn("couldn't stop indexing the vertices of chain %s due to %s", ctx.ChainID, err)
+		}
+		delete(i.vertexIndexes, ctx.ChainID.Key())
+	}
+	if _, ok := i.indexes[ctx.ChainID.Key()]; !ok {
+		return
+	}
+	if err := i.events.DeregisterChain(ctx.ChainID, eventIdentifier); err != nil {
+		i.log.Warn("couldn't stop indexing chain %s due to %s", ctx.ChainID, err)
+	}
+	delete(i.indexes, ctx.ChainID.Key())
+}
+
+// index returns the index of the decisions of the chain [chainID], or of its
+// vertices if [vertices]
+func (i *Indexer) index(chainID ids.ID, vertices bool) (*index, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	if vertices {
+		vertexIndex, ok := i.vertexIndexes[chainID.Key()]
+		if !ok {
+			return nil, fmt.Errorf("the vertices of chain %s aren't indexed", chainID)
+		}
+		return vertexIndex, nil
+	}
+	chainIndex, ok := i.indexes[chainID.Key()]
+	if !ok {
+		return nil, fmt.Errorf("chain %s isn't indexed", chainID)
+	}
+	return chainIndex, nil
+}
@@ -0,0 +1,214 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package indexer
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// MaxFetchedByRange is the maximum number of containers returned by one call
+// to GetContainerRange
+const MaxFetchedByRange = 1024
+
+// Index is the API service for the indexer. The methods use the index of a
+// chain's accepted decisions, or the index of its accepted vertices if their
+// arguments set Vertices. Only Avalanche chains have vertices.
+type Index struct {
+	log          logging.Logger
+	indexer      *Indexer
+	chainManager chains.Manager
+}
+
+// NewService returns a new index API service
+func NewService(log logging.Logger, indexer *Indexer, chainManager chains.Manager) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Index{
+		log:          log,
+		indexer:      indexer,
+		chainManager: chainManager,
+	}, "index")
+	return &common.HTTPHandler{Handler: newServer}
+}
+
+// FormattedContainer is a container returned by the API
+type FormattedContainer struct {
+	ID        ids.ID          `json:"id"`
+	Bytes     formatting.CB58 `json:"bytes"`
+	Timestamp cjson.Uint64    `json:"timestamp"`
+	Index     cjson.Uint64    `json:"index"`
+}
+
+func newFormattedContainer(container Container) FormattedContainer {
+	return FormattedContainer{
+		ID:        container.ID,
+		Bytes:     formatting.CB58{Bytes: container.Bytes},
+		Timestamp: cjson.Uint64(container.Timestamp),
+		Index:     cjson.Uint64(container.Index),
+	}
+}
+
+// GetLastAcceptedArgs are the arguments for calling GetLastAccepted
+type GetLastAcceptedArgs struct {
+	BlockchainID string `json:"blockchainID"`
+	Vertices     bool   `json:"vertices"`
+}
+
+// GetLastAccepted returns the most recently indexed container of a chain
+func (service *Index) GetLastAccepted(_ *http.Request, args *GetLastAcceptedArgs, reply *FormattedContainer) error {
+	service.log.Debug("Index: GetLastAccepted called with %s", args.BlockchainID)
+
+	chainIndex, err := service.chainIndex(args.BlockchainID, args.Vertices)
+	if err != nil {
+		return err
+	}
+	container, err := chainIndex.LastAccepted()
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container)
+	return nil
+}
+
+// GetContainerByIndexArgs are the arguments for calling GetContainerByIndex
+type GetContainerByIndexArgs struct {
+	BlockchainID string       `json:"blockchainID"`
+	Index        cjson.Uint64 `json:"index"`
+	Vertices     bool         `json:"vertices"`
+}
+
+// GetContainerByIndex returns the container of a chain at the given position
+// in acceptance order
+func (service *Index) GetContainerByIndex(_ *http.Request, args *GetContainerByIndexArgs, reply *FormattedContainer) error {
+	service.log.Debug("Index: GetContainerByIndex called with %s, %d", args.BlockchainID, args.Index)
+
+	chainIndex, err := service.chainIndex(args.BlockchainID, args.Vertices)
+	if err != nil {
+		return err
+	}
+	container, err := chainIndex.ContainerByIndex(uint64(args.Index))
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container)
+	return nil
+}
+
+// GetContainerByIDArgs are the arguments for calling GetContainerByID
+type GetContainerByIDArgs struct {
+	BlockchainID string `json:"blockchainID"`
+	ContainerID  string `json:"containerID"`
+	Vertices     bool   `json:"vertices"`
+}
+
+// GetContainerByID returns an indexed container of a chain
+func (service *Index) GetContainerByID(_ *http.Request, args *GetContainerByIDArgs, reply *FormattedContainer) error {
+	service.log.Debug("Index: GetContainerByID called with %s, %s", args.BlockchainID, args.ContainerID)
+
+	chainIndex, err := service.chainIndex(args.BlockchainID, args.Vertices)
+	if err != nil {
+		return err
+	}
+	containerID, err := ids.FromString(args.ContainerID)
+	if err != nil {
+		return fmt.Errorf("problem parsing containerID %q: %w", args.ContainerID, err)
+	}
+	index, err := chainIndex.Index(containerID)
+	if err != nil {
+		return err
+	}
+	container, err := chainIndex.ContainerByIndex(index)
+	if err != nil {
+		return err
+	}
+	*reply = newFormattedContainer(container)
+	return nil
+}
+
+// GetContainerRangeArgs are the arguments for calling GetContainerRange
+type GetContainerRangeArgs struct {
+	BlockchainID string       `json:"blockchainID"`
+	StartIndex   cjson.Uint64 `json:"startIndex"`
+	NumToFetch   cjson.Uint64 `json:"numToFetch"`
+	Vertices     bool         `json:"vertices"`
+}
+
+// GetContainerRangeReply are the results from calling GetContainerRange
+type GetContainerRangeReply struct {
+	Containers []FormattedContainer `json:"containers"`
+}
+
+// GetContainerRange returns at most [NumToFetch] containers of a chain, in
+// acceptance order, starting at [StartIndex]
+func (service *Index) GetContainerRange(_ *http.Request, args *GetContainerRangeArgs, reply *GetContainerRangeReply) error {
+	service.log.Debug("Index: GetContainerRange called with %s, %d, %d", args.BlockchainID, args.StartIndex, args.NumToFetch)
+
+	if args.NumToFetch == 0 || args.NumToFetch > MaxFetchedByRange {
+		return fmt.Errorf("numToFetch must be in [1, %d]", MaxFetchedByRange)
+	}
+	chainIndex, err := service.chainIndex(args.BlockchainID, args.Vertices)
+	if err != nil {
+		return err
+	}
+	containers, err := chainIndex.ContainerRange(uint64(args.StartIndex), uint64(args.NumToFetch))
+	if err != nil {
+		return err
+	}
+	reply.Containers = make([]FormattedContainer, len(containers))
+	for i, container := range containers {
+		reply.Containers[i] = newFormattedContainer(container)
+	}
+	return nil
+}
+
+// GetIndexArgs are the arguments for calling GetIndex
+type GetIndexArgs struct {
+	BlockchainID string `json:"blockchainID"`
+	ContainerID  string `json:"containerID"`
+	Vertices     bool   `json:"vertices"`
+}
+
+// GetIndexReply are the results from calling GetIndex
+type GetIndexReply struct {
+	Index cjson.Uint64 `json:"index"`
+}
+
+// GetIndex returns the position of a container in its chain's acceptance
+// order
+func (service *Index) GetIndex(_ *http.Request, args *GetIndexArgs, reply *GetIndexReply) error {
+	service.log.Debug("Index: GetIndex called with %s, %s", args.BlockchainID, args.ContainerID)
+
+	chainIndex, err := service.chainIndex(args.BlockchainID, args.Vertices)
+	if err != nil {
+		return err
+	}
+	containerID, err := ids.FromString(args.ContainerID)
+	if err != nil {
+		return fmt.Errorf("problem parsing containerID %q: %w", args.ContainerID, err)
+	}
+	index, err := chainIndex.Index(containerID)
+	reply.Index = cjson.Uint64(index)
+	return err
+}
+
+func (service *Index) chainIndex(blockchainID string, vertices bool) (*index, error) {
+	chainID, err := service.chainManager.Lookup(blockchainID)
+	if err != nil {
+		return nil, fmt.Errorf("unknown blockchainID %q: %w", blockchainID, err)
+	}
+	return service.indexer.index(chainID, vertices)
+}
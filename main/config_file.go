@@ -0,0 +1,178 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ava-labs/gecko/snow"
+)
+
+// chainsKey is the key of the config file section that configures individual
+// chains
+const chainsKey = "chains"
+
+// chainConfig is the configuration of a single chain in a config file
+type chainConfig struct {
+	Upgrades          snow.Upgrades `json:"upgrades"`
+	LogLevel          string        `json:"log-level"`
+	LogDisplayLevel   string        `json:"log-display-level"`
+	MempoolTxPriority string        `json:"mempool-tx-priority"`
+}
+
+// loadConfigFile sets the flags of [fs] that haven't been set yet to their
+// values in the config file at [path], and returns the chain configs in the
+// file, keyed by chain ID or alias.
+//
+// The file is YAML if its extension is .yaml or .yml, and JSON otherwise. Its
+// keys are flag names. Lists are joined with commas, so a comma separated flag
+// such as bootstrap-ips can be given as a list.
+func loadConfigFile(fs *flag.FlagSet, path string) (map[string]chainConfig, error) {
+	entries, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	alreadySet := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { alreadySet[f.Name] = true })
+
+	chains := map[string]chainConfig{}
+	for name, raw := range entries {
+		if name == chainsKey {
+			if err := json.Unmarshal(raw, &chains); err != nil {
+				return nil, fmt.Errorf("invalid %s section in config file: %w", chainsKey, err)
+			}
+			continue
+		}
+		if fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("config file sets unknown flag %q", name)
+		}
+		if alreadySet[name] {
+			continue
+		}
+		value, err := flagValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %q in config file: %w", name, err)
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid value of %q in config file: %w", name, err)
+		}
+	}
+	return chains, nil
+}
+
+// readConfigFile returns the entries of the config file at [path], keyed by
+// flag name
+func readConfigFile(path string) (map[string]json.RawMessage, error) {
+	fileBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if fileBytes, err = yamlToJSON(fileBytes); err != nil {
+			return nil, fmt.Errorf("couldn't parse config file %s: %w", path, err)
+		}
+	}
+
+	entries := map[string]json.RawMessage{}
+	if err := json.Unmarshal(fileBytes, &entries); err != nil {
+		return nil, fmt.Errorf("couldn't parse config file %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// flagValue returns the command line form of the JSON value [raw]
+func flagValue(raw json.RawMessage) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		elements := make([]string, len(value))
+		for i, element := range value {
+			str, err := scalarValue(element)
+			if err != nil {
+				return "", err
+			}
+			elements[i] = str
+		}
+		return strings.Join(elements, ","), nil
+	default:
+		return scalarValue(value)
+	}
+}
+
+func scalarValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return fmt.Sprint(value), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("expected a string, number, boolean or list but got %T", value)
+	}
+}
+
+// yamlToJSON converts the YAML document [yamlBytes] to JSON
+func yamlToJSON(yamlBytes []byte) ([]byte, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(yamlBytes, &value); err != nil {
+		return nil, err
+	}
+	value, err := jsonCompatible(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// jsonCompatible replaces the maps in [value], which YAML decodes with
+// interface{} keys, with maps that have string keys
+func jsonCompatible(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string key but got %v", k)
+			}
+			v, err := jsonCompatible(v)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case []interface{}:
+		for i, v := range value {
+			v, err := jsonCompatible(v)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = v
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
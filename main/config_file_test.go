@@ -0,0 +1,213 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testFlagSet returns a flag set with a flag of each kind a config file sets
+func testFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("public-ip", "", "")
+	fs.Uint("http-port", 9650, "")
+	fs.Bool("staking-tls-enabled", true, "")
+	fs.String("bootstrap-ips", "", "")
+	return fs
+}
+
+// writeConfigFile writes [contents] to a file named [name] in a new temporary
+// directory and returns the file's path
+func writeConfigFile(t *testing.T, name, contents string) string {
+	dir, err := ioutil.TempDir("", "config_file_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+	}{
+		{
+			name: "json",
+			file: "config.json",
+			contents: `{
+				"public-ip": "1.2.3.4",
+				"http-port": 9651,
+				"staking-tls-enabled": false,
+				"bootstrap-ips": ["5.6.7.8:9651", "9.10.11.12:9651"],
+				"chains": {"X": {"log-level": "debug"}}
+			}`,
+		},
+		{
+			name: "yaml",
+			file: "config.yaml",
+			contents: `
+public-ip: 1.2.3.4
+http-port: 9651
+staking-tls-enabled: false
+bootstrap-ips:
+  - 5.6.7.8:9651
+  - 9.10.11.12:9651
+chains:
+  X:
+    log-level: debug
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.file, test.contents)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			fs := testFlagSet()
+			chains, err := loadConfigFile(fs, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := map[string]string{
+				"public-ip":           "1.2.3.4",
+				"http-port":           "9651",
+				"staking-tls-enabled": "false",
+				"bootstrap-ips":       "5.6.7.8:9651,9.10.11.12:9651",
+			}
+			for name, value := range expected {
+				if got := fs.Lookup(name).Value.String(); got != value {
+					t.Fatalf("%s was set to %q but should have been set to %q", name, got, value)
+				}
+			}
+			if len(chains) != 1 || chains["X"].LogLevel != "debug" {
+				t.Fatalf("Wrong chain configs %v", chains)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFlagOverrides(t *testing.T) {
+	path := writeConfigFile(t, "config.yml", "public-ip: 1.2.3.4\nhttp-port: 9651\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	fs := testFlagSet()
+	if err := fs.Parse([]string{"--public-ip=5.6.7.8"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := fs.Lookup("public-ip").Value.String(); got != "5.6.7.8" {
+		t.Fatalf("The command line flag should have overridden the config file but public-ip is %q", got)
+	}
+	if got := fs.Lookup("http-port").Value.String(); got != "9651" {
+		t.Fatalf("http-port should have been read from the config file but is %q", got)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+	}{
+		{
+			name:     "unknown json key",
+			file:     "config.json",
+			contents: `{"unknown-flag": 1}`,
+		},
+		{
+			name:     "unknown yaml key",
+			file:     "config.yaml",
+			contents: "unknown-flag: 1\n",
+		},
+		{
+			name:     "invalid value",
+			file:     "config.json",
+			contents: `{"http-port": "not a port"}`,
+		},
+		{
+			name:     "nested value",
+			file:     "config.json",
+			contents: `{"public-ip": {"ip": "1.2.3.4"}}`,
+		},
+		{
+			name:     "malformed json",
+			file:     "config.json",
+			contents: `{"public-ip": `,
+		},
+		{
+			name:     "malformed yaml",
+			file:     "config.yaml",
+			contents: "public-ip: [1.2.3.4\n",
+		},
+		{
+			name:     "non-string yaml key",
+			file:     "config.yaml",
+			contents: "chains:\n  1: {}\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeConfigFile(t, test.file, test.contents)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			if _, err := loadConfigFile(testFlagSet(), path); err == nil {
+				t.Fatalf("Should have errored")
+			}
+		})
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+		err      bool
+	}{
+		{raw: `"1.2.3.4"`, expected: "1.2.3.4"},
+		{raw: `9650`, expected: "9650"},
+		{raw: `10000000000000000000`, expected: "10000000000000000000"},
+		{raw: `0.5`, expected: "0.5"},
+		{raw: `true`, expected: "true"},
+		{raw: `null`, expected: ""},
+		{raw: `["a", 1, false]`, expected: "a,1,false"},
+		{raw: `[]`, expected: ""},
+		{raw: `{"a": 1}`, err: true},
+		{raw: `[["a"]]`, err: true},
+		{raw: `[`, err: true},
+	}
+	for _, test := range tests {
+		value, err := flagValue(json.RawMessage(test.raw))
+		switch {
+		case test.err && err == nil:
+			t.Fatalf("%s should have errored", test.raw)
+		case !test.err && err != nil:
+			t.Fatalf("%s errored with %s", test.raw, err)
+		case value != test.expected:
+			t.Fatalf("%s returned %q but should have returned %q", test.raw, value, test.expected)
+		}
+	}
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	jsonBytes, err := yamlToJSON([]byte("a:\n  b: [1, c]\nd: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"a":{"b":[1,"c"]},"d":true}`; string(jsonBytes) != expected {
+		t.Fatalf("Returned %s but should have returned %s", jsonBytes, expected)
+	}
+}
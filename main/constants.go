@@ -3,6 +3,12 @@
 
 package main
 
+// Exit codes of the node
+const (
+	exitRuntimeError = 1 // The node failed after its configuration was loaded
+	exitConfigError  = 2 // The configuration is invalid
+)
+
 const (
 	gecko = "  ___       ________               __            ___\n" +
 		" / _ \\_/\\  /  _____/  ____   ____ |  | ______   / _ \\_/\\\n" +
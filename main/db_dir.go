@@ -0,0 +1,241 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/backup"
+	"github.com/ava-labs/gecko/database/encdb"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+const (
+	// dbVersion is the version of the format of the node's database, which
+	// includes the keystore. Databases of different versions are kept in
+	// different directories, so a node never opens one it can't read.
+	dbVersion = "v0.2.0"
+
+	// dbMarker is a file that every LevelDB database contains
+	dbMarker = "CURRENT"
+
+	// Types of the persistent storage. BadgerDB and RocksDB databases are kept
+	// in subdirectories of the directory returned by dbPath.
+	leveldbType  = "leveldb"
+	badgerdbType = "badgerdb"
+	rocksdbType  = "rocksdb"
+)
+
+var (
+	errNoPassphraseFile = errors.New("an encrypted database needs --db-passphrase-file")
+	errReadOnlyRestore  = errors.New("a backup can't be restored into a read-only database")
+)
+
+// rocksdbConfig is the configuration of a RocksDB database
+type rocksdbConfig struct {
+	blockCacheSize  int
+	bloomFilterBits int
+	compactionStyle string
+}
+
+// overlayDB keeps the writes to a read-only database in memory, so the node
+// can run on top of it without modifying it
+type overlayDB struct {
+	*versiondb.Database
+	base database.Database
+}
+
+// newOverlayDB returns a database that reads from [base] and never writes to
+// it
+func newOverlayDB(base database.Database) *overlayDB {
+	return &overlayDB{
+		Database: versiondb.New(base),
+		base:     base,
+	}
+}
+
+// Close implements the Database interface. The writes kept in memory are
+// dropped.
+func (db *overlayDB) Close() error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		db.Database.Close(),
+		db.base.Close(),
+	)
+	return errs.Err
+}
+
+// encryptedDB encrypts the values it stores in a database
+type encryptedDB struct {
+	*encdb.Database
+	base database.Database
+}
+
+// newEncryptedDB returns a database that encrypts the values it stores in
+// [base] with the passphrase in [passphraseFile]. [base] is closed if the
+// database can't be opened.
+func newEncryptedDB(base database.Database, passphraseFile string) (database.Database, error) {
+	passphrase, err := readPassphrase(passphraseFile)
+	if err == nil {
+		var db *encdb.Database
+		if db, err = encdb.Open(passphrase, base, encdb.DefaultConfig); err == nil {
+			return &encryptedDB{
+				Database: db,
+				base:     base,
+			}, nil
+		}
+	}
+	base.Close()
+	return nil, err
+}
+
+// Close implements the Database interface
+func (db *encryptedDB) Close() error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		db.Database.Close(),
+		db.base.Close(),
+	)
+	return errs.Err
+}
+
+// readPassphrase returns the contents of [passphraseFile] without a trailing
+// newline
+func readPassphrase(passphraseFile string) ([]byte, error) {
+	if passphraseFile == "" {
+		return nil, errNoPassphraseFile
+	}
+	passphrase, err := ioutil.ReadFile(passphraseFile)
+	if err != nil {
+		return nil, err
+	}
+	passphrase = bytes.TrimRight(passphrase, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("the passphrase file %s is empty", passphraseFile)
+	}
+	return passphrase, nil
+}
+
+// dbPath returns the directory of the database of the network [networkName] in
+// [dbDir], which is <dbDir>/<networkName>/<dbVersion>.
+//
+// Earlier releases kept the database directly in the network's directory. Such
+// a database is moved to the versioned directory, unless [readOnly], in which
+// case it's used where it is. The returned notes describe the migrations done
+// and the databases of other versions that were found.
+func dbPath(dbDir, networkName string, readOnly bool) (string, []string, error) {
+	if exists(filepath.Join(dbDir, dbMarker)) {
+		return "", nil, fmt.Errorf("the database in %s isn't in a network's directory. "+
+			"If it belongs to the %s network, move its files to %s. Otherwise, move them to the directory of their network",
+			dbDir, networkName, filepath.Join(dbDir, networkName, dbVersion))
+	}
+
+	networkDir := filepath.Join(dbDir, networkName)
+	versionDir := filepath.Join(networkDir, dbVersion)
+	notes := []string(nil)
+
+	if exists(filepath.Join(networkDir, dbMarker)) {
+		if exists(filepath.Join(versionDir, dbMarker)) {
+			return "", nil, fmt.Errorf("both %s and %s contain a database. Remove the one that shouldn't be used",
+				networkDir, versionDir)
+		}
+		if readOnly {
+			notes = append(notes, fmt.Sprintf("the database in %s is opened read-only, so it isn't moved to %s", networkDir, versionDir))
+			return networkDir, notes, nil
+		}
+		if err := migrateDB(networkDir, versionDir); err != nil {
+			return "", nil, fmt.Errorf("couldn't move the database in %s to %s: %w", networkDir, versionDir, err)
+		}
+		notes = append(notes, fmt.Sprintf("moved the database in %s to %s", networkDir, versionDir))
+	}
+
+	if !exists(versionDir) {
+		entries, _ := ioutil.ReadDir(networkDir)
+		for _, entry := range entries {
+			if entry.IsDir() && strings.HasPrefix(entry.Name(), "v") {
+				notes = append(notes, fmt.Sprintf("found a database of version %s in %s, which this node doesn't use. "+
+					"A new %s database is created instead", entry.Name(), networkDir, dbVersion))
+			}
+		}
+	}
+	return versionDir, notes, nil
+}
+
+// migrateDB moves the files of the database in [from] to [to]. The marker file
+// is moved last, so a migration that was interrupted is resumed on restart.
+func migrateDB(from, to string) error {
+	if err := os.MkdirAll(to, 0700); err != nil {
+		return err
+	}
+	entries, err := ioutil.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == dbMarker {
+			continue
+		}
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Rename(filepath.Join(from, dbMarker), filepath.Join(to, dbMarker))
+}
+
+// setAsideDB moves the database in [dir], if there's one, out of the way of a
+// backup that's restored into [dir], and returns where it was moved
+func setAsideDB(dir string) (string, error) {
+	if !exists(dir) {
+		return "", nil
+	}
+	asideDir := filepath.Join(filepath.Dir(dir), fmt.Sprintf("before-restore-%s-%d", filepath.Base(dir), time.Now().Unix()))
+	return asideDir, os.Rename(dir, asideDir)
+}
+
+// restoreDB restores the backup in [path] to [db], the empty database in [dir],
+// and returns a note describing the restore. The database that was in [dir]
+// before, if any, was set aside in [asideDir].
+//
+// If restoring fails, [db] is closed, [dir] is removed and the database in
+// [asideDir] is moved back, so a scheduled restore is tried again on the next
+// start.
+func restoreDB(db database.Database, dir, asideDir, path string) (string, error) {
+	pairs, err := backup.RestoreFile(db, path)
+	if err != nil {
+		errs := wrappers.Errs{}
+		errs.Add(
+			db.Close(),
+			os.RemoveAll(dir),
+		)
+		if asideDir != "" && !errs.Errored() {
+			errs.Add(os.Rename(asideDir, dir))
+		}
+		if errs.Errored() {
+			return "", fmt.Errorf("couldn't restore %s: %w. Moving the database in %s back to %s failed with: %s", path, err, asideDir, dir, errs.Err)
+		}
+		return "", fmt.Errorf("couldn't restore %s: %w", path, err)
+	}
+
+	if asideDir == "" {
+		return fmt.Sprintf("restored %d pairs from %s", pairs, path), nil
+	}
+	// The restore is done, so the database set aside doesn't schedule it
+	// anymore
+	_ = os.Remove(filepath.Join(asideDir, backup.PendingRestoreFile))
+	return fmt.Sprintf("restored %d pairs from %s. The database from before the restore was moved to %s", pairs, path, asideDir), nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,246 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/gecko/database/backup"
+	"github.com/ava-labs/gecko/database/memdb"
+)
+
+const testNetworkName = "testnet"
+
+// writeFiles creates each of [files], relative to [dir], with its base name as
+// contents
+func writeFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(filepath.Base(file)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkFiles fails the test unless each of [files], relative to [dir], exists
+// and has its base name as contents
+func checkFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != filepath.Base(file) {
+			t.Fatalf("%s has the wrong contents %q", file, contents)
+		}
+	}
+}
+
+func TestDBPath(t *testing.T) {
+	networkDir := testNetworkName
+	versionDir := filepath.Join(networkDir, dbVersion)
+
+	tests := []struct {
+		name     string
+		files    []string // Created before dbPath is called
+		readOnly bool
+		path     string   // Expected result, relative to the db directory
+		notes    int      // Expected number of notes
+		err      bool     // True if dbPath should error
+		moved    []string // Files expected in the versioned directory afterwards
+		kept     []string // Files expected in the network's directory afterwards
+	}{
+		{
+			name: "fresh",
+			path: versionDir,
+		},
+		{
+			name:  "current",
+			files: []string{filepath.Join(versionDir, dbMarker), filepath.Join(versionDir, "000001.ldb")},
+			path:  versionDir,
+			moved: []string{dbMarker, "000001.ldb"},
+		},
+		{
+			name:  "legacy",
+			files: []string{filepath.Join(networkDir, dbMarker), filepath.Join(networkDir, "000001.ldb")},
+			path:  versionDir,
+			notes: 1,
+			moved: []string{dbMarker, "000001.ldb"},
+		},
+		{
+			name: "resume interrupted migration",
+			files: []string{
+				filepath.Join(networkDir, dbMarker),
+				filepath.Join(networkDir, "000002.ldb"),
+				filepath.Join(versionDir, "000001.ldb"),
+			},
+			path:  versionDir,
+			notes: 1,
+			moved: []string{dbMarker, "000001.ldb", "000002.ldb"},
+		},
+		{
+			name:     "legacy read-only",
+			files:    []string{filepath.Join(networkDir, dbMarker), filepath.Join(networkDir, "000001.ldb")},
+			readOnly: true,
+			path:     networkDir,
+			notes:    1,
+			kept:     []string{dbMarker, "000001.ldb"},
+		},
+		{
+			name: "target exists",
+			files: []string{
+				filepath.Join(networkDir, dbMarker),
+				filepath.Join(versionDir, dbMarker),
+			},
+			err:  true,
+			kept: []string{dbMarker},
+		},
+		{
+			name:  "not in a network's directory",
+			files: []string{dbMarker},
+			err:   true,
+		},
+		{
+			name:  "other version",
+			files: []string{filepath.Join(networkDir, "v0.1.0", dbMarker)},
+			path:  versionDir,
+			notes: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dbDir, err := ioutil.TempDir("", "db_dir_test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dbDir)
+
+			writeFiles(t, dbDir, test.files...)
+
+			path, notes, err := dbPath(dbDir, testNetworkName, test.readOnly)
+			switch {
+			case test.err && err == nil:
+				t.Fatalf("Should have errored")
+			case !test.err && err != nil:
+				t.Fatal(err)
+			case !test.err && path != filepath.Join(dbDir, test.path):
+				t.Fatalf("Returned %s but should have returned %s", path, filepath.Join(dbDir, test.path))
+			case len(notes) != test.notes:
+				t.Fatalf("Returned notes %v but should have returned %d notes", notes, test.notes)
+			}
+			checkFiles(t, filepath.Join(dbDir, versionDir), test.moved...)
+			checkFiles(t, filepath.Join(dbDir, networkDir), test.kept...)
+		})
+	}
+}
+
+func TestMigrateDBFailure(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "db_dir_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dbDir)
+
+	from := filepath.Join(dbDir, "from")
+	to := filepath.Join(dbDir, "to")
+	writeFiles(t, from, dbMarker, "000001.ldb", "000002.ldb")
+
+	// A non-empty directory in the way of one of the files makes the migration
+	// fail partway through
+	obstacle := filepath.Join(to, "000002.ldb")
+	writeFiles(t, obstacle, "file")
+
+	if err := migrateDB(from, to); err == nil {
+		t.Fatalf("Should have errored")
+	}
+	if !exists(filepath.Join(from, dbMarker)) || exists(filepath.Join(to, dbMarker)) {
+		t.Fatalf("The marker should be moved last")
+	}
+
+	if err := os.RemoveAll(obstacle); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateDB(from, to); err != nil {
+		t.Fatal(err)
+	}
+	checkFiles(t, to, dbMarker, "000001.ldb", "000002.ldb")
+	if exists(filepath.Join(from, dbMarker)) {
+		t.Fatalf("The marker should have been moved")
+	}
+}
+
+func TestRestoreDB(t *testing.T) {
+	root, err := ioutil.TempDir("", "restoredb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, testNetworkName, dbVersion)
+	backupPath := filepath.Join(root, "db.backup")
+	source := memdb.New()
+	if err := source.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backup.BackupFile(source, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, dir, dbMarker)
+	if err := backup.ScheduleRestore(dir, filepath.Join(root, "missing.backup")); err == nil {
+		t.Fatalf("Scheduling the restore of a missing backup should have failed")
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, backup.PendingRestoreFile), []byte(filepath.Join(root, "not.backup")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, "not.backup")
+
+	// A failed restore moves the database that was set aside back, so the
+	// restore is tried again
+	asideDir, err := setAsideDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, dir, dbMarker)
+	if _, err := restoreDB(memdb.New(), dir, asideDir, filepath.Join(root, "not.backup")); err == nil {
+		t.Fatalf("Restoring a file that isn't a backup should have failed")
+	}
+	checkFiles(t, dir, dbMarker)
+	if exists(asideDir) {
+		t.Fatalf("Database set aside should have been moved back")
+	}
+
+	if err := backup.ScheduleRestore(dir, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	asideDir, err = setAsideDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists(dir) {
+		t.Fatalf("Database should have been set aside")
+	}
+	db := memdb.New()
+	if _, err := restoreDB(db, dir, asideDir, backupPath); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Fatalf("Restored value is %q, %v ; Expected \"value\"", value, err)
+	}
+	checkFiles(t, asideDir, dbMarker)
+	if pending, err := backup.PendingRestore(asideDir); err != nil || pending != "" {
+		t.Fatalf("Database set aside shouldn't schedule the restore anymore, but schedules %q, %v", pending, err)
+	}
+
+	// A database that isn't empty isn't restored into
+	if _, err := restoreDB(db, dir, "", backupPath); err == nil {
+		t.Fatalf("Restoring into a database that isn't empty should have failed")
+	}
+}
@@ -0,0 +1,41 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix is the prefix of the environment variables that set flags
+const envPrefix = "GECKO_"
+
+// envName returns the environment variable that sets the flag [name]. For
+// example, log-level is set by GECKO_LOG_LEVEL.
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// loadEnv sets the flags of [fs] that weren't set on the command line to the
+// values of their environment variables, if those are set
+func loadEnv(fs *flag.FlagSet) error {
+	setOnCLI := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setOnCLI[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || setOnCLI[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value of %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,125 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setEnv sets the environment variables in [env] and returns a function that
+// unsets them
+func setEnv(t *testing.T, env map[string]string) func() {
+	for name, value := range env {
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for name := range env {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if name := envName("staking-tls-enabled"); name != "GECKO_STAKING_TLS_ENABLED" {
+		t.Fatalf("Wrong environment variable %s", name)
+	}
+}
+
+// TestLoadEnvPrecedence checks that a flag is set from, in order of
+// precedence, the command line, the environment, the config file and the
+// flag's default, the way the node's parameters are parsed
+func TestLoadEnvPrecedence(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"public-ip": "3.3.3.3", "http-port": 3}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	defer setEnv(t, map[string]string{
+		"GECKO_PUBLIC_IP":     "2.2.2.2",
+		"GECKO_HTTP_PORT":     "2",
+		"GECKO_BOOTSTRAP_IPS": "2.2.2.2:2",
+	})()
+
+	fs := testFlagSet()
+	if err := fs.Parse([]string{"--public-ip=1.1.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadEnv(fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(fs, path); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"public-ip":           "1.1.1.1",   // Command line
+		"http-port":           "2",         // Environment
+		"bootstrap-ips":       "2.2.2.2:2", // Environment only
+		"staking-tls-enabled": "true",      // Default
+	}
+	for name, value := range expected {
+		if got := fs.Lookup(name).Value.String(); got != value {
+			t.Fatalf("%s was set to %q but should have been set to %q", name, got, value)
+		}
+	}
+
+	fs = testFlagSet()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("GECKO_HTTP_PORT")
+	if err := loadEnv(fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(fs, path); err != nil {
+		t.Fatal(err)
+	}
+	if got := fs.Lookup("public-ip").Value.String(); got != "2.2.2.2" {
+		t.Fatalf("The environment should have overridden the config file but public-ip is %q", got)
+	}
+	if got := fs.Lookup("http-port").Value.String(); got != "3" {
+		t.Fatalf("http-port should have been read from the config file but is %q", got)
+	}
+}
+
+func TestLoadEnvMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "GECKO_HTTP_PORT", value: "not a port"},
+		{name: "GECKO_HTTP_PORT", value: "-1"},
+		{name: "GECKO_HTTP_PORT", value: ""},
+		{name: "GECKO_STAKING_TLS_ENABLED", value: "maybe"},
+	}
+	for _, test := range tests {
+		unset := setEnv(t, map[string]string{test.name: test.value})
+
+		fs := testFlagSet()
+		if err := fs.Parse(nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := loadEnv(fs); err == nil {
+			t.Fatalf("%s=%q should have errored", test.name, test.value)
+		}
+		unset()
+	}
+}
+
+func TestLoadEnvMalformedOverriddenOnCLI(t *testing.T) {
+	defer setEnv(t, map[string]string{"GECKO_HTTP_PORT": "not a port"})()
+
+	fs := testFlagSet()
+	if err := fs.Parse([]string{"--http-port=9651"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadEnv(fs); err != nil {
+		t.Fatalf("An environment variable overridden on the command line shouldn't be parsed but errored with %s", err)
+	}
+	if got := fs.Lookup("http-port").Value.String(); got != "9651" {
+		t.Fatalf("http-port should have been set on the command line but is %q", got)
+	}
+}
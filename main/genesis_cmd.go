@@ -0,0 +1,99 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ava-labs/gecko/genesis"
+)
+
+// genesisCommand is the subcommand that builds the genesis of a network
+// instead of running a node, as in `ava genesis --network-id=5 --spec=spec.json`
+const genesisCommand = "genesis"
+
+// isSubcommand returns true if the node was started to run a subcommand
+func isSubcommand() bool {
+	return len(os.Args) > 1 && (os.Args[1] == genesisCommand || os.Args[1] == localnetCommand)
+}
+
+// runSubcommand runs the subcommand [name] with [args]. Returns the exit code.
+func runSubcommand(name string, args []string) int {
+	if name == localnetCommand {
+		return runLocalnet(args)
+	}
+	return runGenesis(args)
+}
+
+// runGenesis builds the genesis of the network described by [args] and writes
+// its genesis data, chains and validators as JSON. Returns the exit code.
+func runGenesis(args []string) int {
+	fs := flag.NewFlagSet("gecko genesis", flag.ContinueOnError)
+	networkName := fs.String("network-id", genesis.LocalName, "Network ID of the network whose genesis is built. If custom, the network ID is read from the spec")
+	specFile := fs.String("spec", "", "Path to a JSON file that defines the genesis accounts, validators and chains, in the format of the Platform Chain's buildGenesis API. If empty, the spec is read from stdin")
+	outputFile := fs.String("output", "", "Path of the file the genesis is written to. If empty, the genesis is written to stdout")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	if err := buildGenesis(*networkName, *specFile, *outputFile); err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't build genesis: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func buildGenesis(networkName, specFile, outputFile string) error {
+	var (
+		specBytes []byte
+		err       error
+	)
+	if specFile == "" {
+		specBytes, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		specBytes, err = ioutil.ReadFile(specFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	spec := genesis.Spec{}
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		return fmt.Errorf("couldn't parse spec: %w", err)
+	}
+
+	// The network ID of a custom network is the one in its spec
+	var networkID uint32
+	if strings.EqualFold(networkName, genesis.CustomName) {
+		networkID, err = genesis.CustomNetworkID(&spec)
+	} else {
+		networkID, err = genesis.NetworkID(networkName)
+	}
+	if err != nil {
+		return err
+	}
+	network, err := genesis.Build(networkID, &spec)
+	if err != nil {
+		return err
+	}
+
+	networkBytes, err := json.MarshalIndent(network, "", "\t")
+	if err != nil {
+		return err
+	}
+	networkBytes = append(networkBytes, '\n')
+	if outputFile == "" {
+		_, err = os.Stdout.Write(networkBytes)
+		return err
+	}
+	return ioutil.WriteFile(outputFile, networkBytes, 0644)
+}
@@ -0,0 +1,77 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ava-labs/gecko/testnetwork"
+)
+
+// localnetCommand is the subcommand that runs a local network of nodes, each
+// in its own process, as in `ava localnet --nodes=5 -- --log-level=debug`.
+// Flags after -- are passed to every node.
+const localnetCommand = "localnet"
+
+// runLocalnet runs the local network described by [args] until the process is
+// interrupted. Returns the exit code.
+func runLocalnet(args []string) int {
+	fs := flag.NewFlagSet("gecko localnet", flag.ContinueOnError)
+	numNodes := fs.Int("nodes", testnetwork.DefaultNumNodes, "Number of nodes in the network, each of which is a validator at genesis")
+	dir := fs.String("dir", "", "Directory that the network's genesis, and each node's staking key, database and logs, are written to. If empty, a temporary directory is used")
+	basePort := fs.Uint("base-port", testnetwork.DefaultBasePort, "Port of the first node's API. The nodes' API and staking ports are consecutive from it")
+	startTimeout := fs.Duration("start-timeout", testnetwork.DefaultStartTimeout, "How long the nodes have to start serving their APIs")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't find the node binary: %s\n", err)
+		return 1
+	}
+	if *dir == "" {
+		if *dir, err = ioutil.TempDir("", "localnet"); err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't create the network's directory: %s\n", err)
+			return 1
+		}
+	}
+
+	net, err := testnetwork.New(testnetwork.Config{
+		Binary:       binary,
+		Dir:          *dir,
+		NumNodes:     *numNodes,
+		BasePort:     uint16(*basePort),
+		Args:         fs.Args(),
+		StartTimeout: *startTimeout,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't start the network: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("local network is running in %s\n", *dir)
+	for _, node := range net.Nodes() {
+		fmt.Printf("node %s: API at %s, staking port %d\n", node.ID, node.URI(), node.StakingPort)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	fmt.Println("stopping the local network")
+	if err := net.Stop(); err != nil {
+		fmt.Fprintf(os.Stderr, "couldn't stop the network cleanly: %s\n", err)
+		return 1
+	}
+	return 0
+}
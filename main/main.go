@@ -5,6 +5,8 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path"
 
 	"github.com/ava-labs/gecko/node"
@@ -16,10 +18,18 @@ import (
 // main is the primary entry point to Ava. This can either create a CLI to an
 //     existing node or create a new node.
 func main() {
+	if isSubcommand() {
+		os.Exit(runSubcommand(os.Args[1], os.Args[2:]))
+	}
+	os.Exit(run())
+}
+
+// run runs the node until it's stopped, and returns the process's exit code
+func run() (exitCode int) {
 	// Err is set based on the CLI arguments
 	if Err != nil {
 		fmt.Printf("parsing parameters returned with error %s\n", Err)
-		return
+		return exitConfigError
 	}
 
 	config := Config.LoggingConfig
@@ -30,16 +40,36 @@ func main() {
 	log, err := factory.Make()
 	if err != nil {
 		fmt.Printf("starting logger failed with: %s\n", err)
-		return
+		return exitConfigError
 	}
 	fmt.Println(gecko)
 
-	defer func() { recover() }()
+	for _, note := range DBNotes {
+		log.Warn("%s", note)
+	}
+
+	defer func() {
+		if recover() != nil {
+			exitCode = exitRuntimeError
+		}
+	}()
 
 	defer log.Stop()
 	defer log.StopOnPanic()
 	defer Config.DB.Close()
 
+	if PIDFile != "" {
+		if err := ioutil.WriteFile(PIDFile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+			log.Fatal("couldn't write the pid file: %s", err)
+			return exitConfigError
+		}
+		defer os.Remove(PIDFile)
+	}
+
+	signalsDone := make(chan struct{})
+	defer close(signalsDone)
+	go log.RecoverAndPanic(func() { handleSignals(log, factory, ConfigFile, signalsDone) })
+
 	// Track if sybil control is enforced
 	if !Config.EnableStaking {
 		log.Warn("Staking and p2p encryption are disabled. Packet spoofing is possible.")
@@ -53,7 +83,7 @@ func main() {
 
 	if err := Config.ConsensusParams.Valid(); err != nil {
 		log.Fatal("consensus parameters are invalid: %s", err)
-		return
+		return exitConfigError
 	}
 
 	// Track if assertions should be executed
@@ -86,17 +116,21 @@ func main() {
 	// MainNode is a global variable in the node.go file
 	if err := node.MainNode.Initialize(&Config, log, factory); err != nil {
 		log.Fatal("error initializing node state: %s", err)
-		return
+		return exitRuntimeError
 	}
 
 	log.Debug("Starting servers")
 	if err := node.MainNode.StartConsensusServer(); err != nil {
 		log.Fatal("problem starting servers: %s", err)
-		return
+		return exitRuntimeError
 	}
 
 	defer node.MainNode.Shutdown()
 
 	log.Debug("Dispatching node handlers")
-	node.MainNode.Dispatch()
+	if err := node.MainNode.Dispatch(); err != nil {
+		log.Fatal("node stopped due to: %s", err)
+		return exitRuntimeError
+	}
+	return 0
 }
@@ -4,41 +4,69 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
+	"github.com/ava-labs/gecko/api"
+	"github.com/ava-labs/gecko/database/backup"
+	"github.com/ava-labs/gecko/database/badgerdb"
 	"github.com/ava-labs/gecko/database/leveldb"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/genesis"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/node"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/dynamicip"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 )
 
 // Results of parsing the CLI
 var (
 	Config = node.Config{}
 	Err    error
+
+	// Migrations and restores of the database directory, and databases of
+	// other versions that were found, to report once logging has started
+	DBNotes []string
+
+	// Path of the config file, reloaded on SIGHUP
+	ConfigFile string
+
+	// Path of the file the node's process ID is written to
+	PIDFile string
 )
 
 var (
-	errBootstrapMismatch = errors.New("more bootstrap IDs provided than bootstrap IPs")
+	errBootstrapMismatch   = errors.New("more bootstrap IDs provided than bootstrap IPs")
+	errNoAuthPasswordFile  = errors.New("requiring API auth needs --api-auth-password-file")
+	errNoCustomGenesisFile = errors.New("a custom network needs --genesis-file")
 )
 
 // Parse the CLI arguments
 func init() {
+	if isSubcommand() {
+		return // Subcommands parse their own arguments
+	}
+
 	errs := &wrappers.Errs{}
 	defer func() { Err = errs.Err }()
 
@@ -47,12 +75,33 @@ func init() {
 
 	fs := flag.NewFlagSet("gecko", flag.ContinueOnError)
 
+	// Process:
+	fs.StringVar(&PIDFile, "pid-file", "", "Path of a file the process ID of the node is written to while it runs")
+
+	// Config file:
+	configFile := fs.String("config-file", "", "Path to a JSON or YAML file whose keys are the names of flags. Flags given on the command line or by GECKO_* environment variables take precedence over the file. A chains section maps chain IDs or aliases to their upgrades, log-level, log-display-level and mempool-tx-priority")
+
 	// NetworkID:
-	networkName := fs.String("network-id", genesis.LocalName, "Network ID this node will connect to")
+	networkName := fs.String("network-id", genesis.LocalName, "Network ID this node will connect to. If custom, the network ID is read from genesis-file")
+	genesisFile := fs.String("genesis-file", "", "Path to a JSON file that defines the network ID, genesis accounts, validators and chains of a custom network, in the format of the Platform Chain's buildGenesis API. If empty, the hardcoded genesis of network-id is used")
 
 	// Ava fees:
 	fs.Uint64Var(&Config.AvaTxFee, "ava-tx-fee", 0, "Ava transaction fee, in $nAva")
 
+	// Mempool:
+	fs.IntVar(&Config.MempoolConfig.MaxTxs, "mempool-max-txs", mempool.DefaultMaxTxs, "Maximum number of unissued transactions each built-in VM holds")
+	fs.IntVar(&Config.MempoolConfig.MaxBytes, "mempool-max-bytes", mempool.DefaultMaxBytes, "Maximum total size, in bytes, of the unissued transactions each built-in VM holds")
+	fs.StringVar(&Config.AVMFeeAsset, "avm-fee-asset", "", "Alias or ID of the asset AVM transactions pay fees in. If empty, AVM transactions don't pay fees")
+	fs.IntVar(&Config.AVMReissueLimit, "avm-tx-reissue-limit", 0, "Maximum number of times a transaction issued to the AVM is re-issued after being dropped before entering consensus. If 0, dropped transactions aren't re-issued")
+
+	// VM plugins:
+	chainVMPlugins := fs.String("vm-plugins", "", "Comma separated list of chain VMs served by plugins, each formatted as <VM ID>=<path of the plugin's executable>. The plugin is started when a chain of the VM is created")
+	dagVMPlugins := fs.String("dag-vm-plugins", "", "Comma separated list of DAG VMs served by plugins, each formatted as <VM ID>=<path of the plugin's executable>")
+
+	// Uptime rewards:
+	uptimeRewards := fs.Bool("uptime-rewards-enabled", false, "Only prefer to reward stakers whose validator's uptime, as measured by this node, meets the requirement")
+	uptimeRequirement := fs.Float64("uptime-reward-requirement", .6, "Fraction of the staking period a validator must be connected to this node to be rewarded, if uptime rewards are enabled")
+
 	// Assertions:
 	fs.BoolVar(&loggingConfig.Assertions, "assertions-enabled", true, "Turn on assertion execution")
 
@@ -62,12 +111,34 @@ func init() {
 	// Database:
 	db := fs.Bool("db-enabled", true, "Turn on persistent storage")
 	dbDir := fs.String("db-dir", "db", "Database directory for Ava state")
+	dbType := fs.String("db-type", leveldbType, fmt.Sprintf("Type of the persistent storage. Should be one of {%s, %s, %s}. %s is only available if the node was built with the %s build tag. Databases of other types than %s are kept in subdirectories of the database directory named after their type, so switching types starts from an empty database", leveldbType, badgerdbType, rocksdbType, rocksdbType, rocksdbType, leveldbType))
+	leveldbConf := leveldb.Config{}
+	fs.IntVar(&leveldbConf.BlockCacheSize, "leveldb-block-cache-size", leveldb.DefaultConfig.BlockCacheSize, "Number of bytes of uncompressed blocks a LevelDB database caches in memory")
+	fs.IntVar(&leveldbConf.WriteBufferSize, "leveldb-write-buffer-size", leveldb.DefaultConfig.WriteBufferSize, "Number of bytes of writes a LevelDB database buffers in memory before writing them to a table")
+	fs.IntVar(&leveldbConf.HandleCap, "leveldb-handle-cap", leveldb.DefaultConfig.HandleCap, "Number of files a LevelDB database keeps open")
+	fs.IntVar(&leveldbConf.CompactionTableSize, "leveldb-compaction-table-size", leveldb.DefaultConfig.CompactionTableSize, "Number of bytes of the tables a LevelDB database's compactions write. Larger tables mean fewer compactions")
+	fs.IntVar(&leveldbConf.BloomFilterBits, "leveldb-bloom-filter-bits", leveldb.DefaultConfig.BloomFilterBits, "Number of bits per key of the bloom filters of a LevelDB database's tables. If 0, tables don't have bloom filters")
+	rocksdbConf := rocksdbConfig{}
+	fs.IntVar(&rocksdbConf.blockCacheSize, "rocksdb-block-cache-size", 512*1024*1024, "Number of bytes of uncompressed blocks a RocksDB database caches in memory")
+	fs.IntVar(&rocksdbConf.bloomFilterBits, "rocksdb-bloom-filter-bits", 10, "Number of bits per key of the bloom filters of a RocksDB database's tables. If 0, tables don't have bloom filters")
+	fs.StringVar(&rocksdbConf.compactionStyle, "rocksdb-compaction-style", "level", "Compaction style of a RocksDB database. Should be one of {level, universal}. Universal compaction stalls less under heavy writes, at the cost of using more space")
+	dbEncrypt := fs.Bool("db-encrypt", false, "If true, the values of the database are encrypted on disk with a key derived from the passphrase in --db-passphrase-file. Keys aren't encrypted. Encrypted values are kept apart from unencrypted ones, so enabling encryption starts from an empty database")
+	dbPassphraseFile := fs.String("db-passphrase-file", "", "Path to a file whose contents, without a trailing newline, are the passphrase the database is encrypted with. Only used if --db-encrypt is set")
+	dbReadOnly := fs.Bool("db-read-only", false, fmt.Sprintf("If true, the %s database is opened read-only, without its exclusive lock, so it can be inspected while no other process writes to it. The node's writes are kept in memory and dropped on shutdown", leveldbType))
+	dbRestoreFile := fs.String("db-restore-file", "", "Path to a backup, made with the admin API's backupDatabase, to restore into the database before the node starts. The database directory must not contain a database yet. To restore over an existing database, schedule the restore with the admin API's restoreDatabase, which sets the database aside on the next start")
+	trackSubnets := fs.String("track-subnets", "", "Comma separated list of the IDs of the subnets, besides the default subnet, whose chains this node runs. The chains of other subnets aren't created")
+	fs.BoolVar(&Config.ReadOnly, "read-only", false, "If true, the node bootstraps, follows consensus and serves queries, but never votes and rejects the transactions issued to it. Its database is opened in strict mode, which verifies every block that's read and refuses to recover a corrupted database")
+	stateMode := fs.String("state-mode", "archive", "How much historical state the chains keep. Should be one of {archive, pruned}. Archive nodes can serve historical queries and the ancestors of any accepted container, and advertise that to their peers. In pruned mode, the C-Chain only keeps the state tries of recent blocks, and the other chains only delete state if --state-prune is set")
+	fs.BoolVar(&Config.StatePrune, "state-prune", false, "If true, the rejected blocks, vertices and transactions of the chains are deleted from their databases in the background once they're decided. Spent UTXOs are always deleted when they're spent, and accepted containers are kept. Requires --state-mode=pruned")
 
 	// IP:
-	consensusIP := fs.String("public-ip", "", "Public IP of this node")
+	consensusIP := fs.String("public-ip", "", "Public IP of this node. If empty, the IP is asked of the NAT router")
+	dynamicPublicIP := fs.String("dynamic-public-ip", "", "If set, the public IP of this node is resolved with this service, and re-resolved every dynamic-update-duration. Should be one of {stun, ifconfig}. Overrides public-ip")
+	fs.DurationVar(&Config.DynamicUpdateDuration, "dynamic-update-duration", 5*time.Minute, "How often the public IP is re-resolved when dynamic-public-ip is set")
 
 	// HTTP Server:
 	httpPort := fs.Uint("http-port", 9650, "Port of the HTTP server")
+	httpHosts := fs.String("http-hosts", "", "Comma separated list of the addresses the HTTP server listens on, each at http-port. If empty, the server listens on every interface. Example: 127.0.0.1,10.0.0.5")
 	fs.BoolVar(&Config.EnableHTTPS, "http-tls-enabled", false, "Upgrade the HTTP server to HTTPs")
 	fs.StringVar(&Config.HTTPSKeyFile, "http-tls-key-file", "", "TLS private key file for the HTTPs server")
 	fs.StringVar(&Config.HTTPSCertFile, "http-tls-cert-file", "", "TLS certificate file for the HTTPs server")
@@ -78,14 +149,36 @@ func init() {
 
 	// Staking:
 	consensusPort := fs.Uint("staking-port", 9651, "Port of the consensus server")
+	fs.StringVar(&Config.StakingHost, "staking-host", "", "Address the consensus server listens on, at staking-port. If empty, the server listens on public-ip. Use 0.0.0.0 to listen on every interface")
 	fs.BoolVar(&Config.EnableStaking, "staking-tls-enabled", true, "Require TLS to authenticate staking connections")
 	fs.StringVar(&Config.StakingKeyFile, "staking-tls-key-file", "", "TLS private key file for staking connections")
 	fs.StringVar(&Config.StakingCertFile, "staking-tls-cert-file", "", "TLS certificate file for staking connections")
 
+	// Consensus networking:
+	fs.DurationVar(&Config.MinRequestTimeout, "network-timeout-min", 500*time.Millisecond, "Minimum time a validator has to respond to a consensus request. Each validator's timeout adapts to its response latency")
+	fs.DurationVar(&Config.MaxRequestTimeout, "network-timeout-max", 4*time.Second, "Maximum time a validator has to respond to a consensus request")
+	fs.Uint64Var(&Config.InboundThrottling.BytesPerSec, "network-inbound-bytes-per-sec", 16<<20, "Maximum number of bytes of consensus messages each peer can send this node per second. Messages past the limit are dropped. If 0, there is no limit")
+	fs.Uint64Var(&Config.InboundThrottling.MsgsPerSec, "network-inbound-msgs-per-sec", 256, "Maximum number of consensus messages of each type each peer can send this node per second. Messages past the limit are dropped. If 0, there is no limit")
+	fs.Uint64Var(&Config.OutboundThrottling.BytesPerSec, "network-outbound-bytes-per-sec", 0, "Maximum number of bytes of consensus messages this node sends each peer per second. If 0, there is no limit")
+	fs.Uint64Var(&Config.OutboundThrottling.MsgsPerSec, "network-outbound-msgs-per-sec", 0, "Maximum number of consensus messages of each type this node sends each peer per second. If 0, there is no limit")
+	throttleBurst := fs.Duration("network-throttle-burst", 10*time.Second, "A peer can exceed its message limits by up to this long's worth of bytes and messages after a quiet period")
+	fs.Uint64Var(&Config.InboundThrottling.MaxViolations, "network-throttle-max-violations", 1024, "A peer whose inbound messages are dropped more than this many times within network-throttle-violation-window is disconnected. If 0, peers aren't disconnected")
+	fs.DurationVar(&Config.InboundThrottling.ViolationWindow, "network-throttle-violation-window", time.Minute, "Window over which the dropped inbound messages of a peer are counted")
+	chainWeights := fs.String("router-chain-weights", "", "Comma separated list of the weights of chains' inbound message queues, each formatted as <chain>=<weight>. A chain is an ID or alias. Each round, the router passes each chain up to its weight in messages, so a flood of messages for one chain can't delay the others. Chains of the default subnet default to 4, other chains to 1. Example: X=8,mychain=2")
+
 	// Logging:
 	logsDir := fs.String("log-dir", "", "Logging directory for Ava")
 	logLevel := fs.String("log-level", "info", "The log level. Should be one of {verbo, debug, info, warn, error, fatal, off}")
 	logDisplayLevel := fs.String("log-display-level", "", "The log display level. If left blank, will inherit the value of log-level. Otherwise, should be one of {verbo, debug, info, warn, error, fatal, off}")
+	fs.DurationVar(&loggingConfig.RotationInterval, "log-rotation-interval", loggingConfig.RotationInterval, "Maximum age of a log file before it is rotated")
+	fs.IntVar(&loggingConfig.FileSize, "log-file-size", loggingConfig.FileSize, "Maximum size, in bytes, of a log file before it is rotated")
+	fs.IntVar(&loggingConfig.RotationSize, "log-max-files", loggingConfig.RotationSize, "Maximum number of rotated log files kept in each log directory")
+	fs.IntVar(&loggingConfig.MaxTotalSize, "log-max-total-size", loggingConfig.MaxTotalSize, "Maximum total size, in bytes, of the rotated log files kept in each log directory. If 0, only log-max-files limits them")
+	logCompression := fs.Bool("log-compression-enabled", true, "If true, rotated log files are gzipped")
+	logLevels := fs.String("log-levels", "", "Comma separated list of log levels of named loggers, each formatted as <name>=<level>. A name is a subsystem, such as network, http, consensus or database, or a chain's ID or alias. Example: network=debug,consensus=verbo")
+	logDisplayLevels := fs.String("log-display-levels", "", "Comma separated list of display levels of named loggers, each formatted as <name>=<level>. Example: http=warn,P=debug")
+	logSinks := fs.String("log-sinks", "", "Comma separated list of remote destinations that log records are sent to, each formatted as <level>:<type>:<url>. The type is one of {syslog, loki, http}, and only records at least as severe as the level are sent. Example: warn:loki:http://127.0.0.1:3100,error:syslog:udp://127.0.0.1:514")
+	logFormat := fs.String("log-format", "plain", "The format of log records. Should be one of {plain, json}. With json, every record is written as one JSON object per line")
 
 	fs.IntVar(&Config.ConsensusParams.K, "snow-sample-size", 20, "Number of nodes to query for each network poll")
 	fs.IntVar(&Config.ConsensusParams.Alpha, "snow-quorum-size", 18, "Alpha value to use for required number positive results")
@@ -97,8 +190,41 @@ func init() {
 	// Enable/Disable APIs:
 	fs.BoolVar(&Config.AdminAPIEnabled, "api-admin-enabled", true, "If true, this node exposes the Admin API")
 	fs.BoolVar(&Config.KeystoreAPIEnabled, "api-keystore-enabled", true, "If true, this node exposes the Keystore API")
+	fs.IntVar(&Config.KeystoreUserQuota, "keystore-user-quota", 0, "Maximum number of bytes of data each keystore user may store. If 0, there is no limit")
 	fs.BoolVar(&Config.MetricsAPIEnabled, "api-metrics-enabled", true, "If true, this node exposes the Metrics API")
+	fs.BoolVar(&Config.HealthAPIEnabled, "api-health-enabled", true, "If true, this node exposes the Health API")
+	fs.DurationVar(&Config.HealthCheckFrequency, "health-check-frequency", 30*time.Second, "Time between runs of the health checks")
+	fs.Uint64Var(&Config.HealthMinFreeDisk, "health-min-free-disk", 1<<30, "Minimum number of bytes available on the database's disk for the node to be healthy. If 0, there is no minimum")
+	fs.Float64Var(&Config.HealthMinFreeDiskFraction, "health-min-free-disk-fraction", 0.05, "Minimum fraction of the database's disk available for the node to be healthy. If 0, there is no minimum")
+	fs.BoolVar(&Config.EventsAPIEnabled, "api-events-enabled", true, "If true, this node exposes the Events API, a WebSocket that pushes the containers accepted by each chain to the clients subscribed to the chain, or to an address or asset of the chain")
+	fs.BoolVar(&Config.InfoAPIEnabled, "api-info-enabled", true, "If true, this node exposes the Info API, which lists the peers this node has been connected to")
 	fs.BoolVar(&Config.IPCEnabled, "api-ipcs-enabled", false, "If true, IPCs can be opened")
+	fs.BoolVar(&Config.APIAuthRequired, "api-auth-required", false, "If true, calls to the Admin, Keystore and IPC APIs must carry a token in their Authorization header. Tokens are issued by the Auth API to those who know the password in --api-auth-password-file")
+	apiAuthPasswordFile := fs.String("api-auth-password-file", "", "Path to a file whose contents, without a trailing newline, are the password API auth tokens are issued with. Only used if --api-auth-required is set")
+	fs.BoolVar(&Config.IndexEnabled, "index-enabled", false, "If true, the decisions accepted by each chain, and the vertices accepted by each Avalanche chain, are indexed in acceptance order and exposed by the Index API. The index is kept in its own database, so it can be enabled at any time, but only what is accepted while it's enabled is indexed")
+	chainRoutes := fs.String("api-chain-routes", "", "Comma separated list of custom routes of chains' APIs, each formatted as <route>=<chain>. A chain is an ID or alias, and its API is also served under /ext/bc/<route>. Example: mychain/v1=X")
+	apiPlugins := fs.String("api-plugins", "", "Comma separated list of API plugins, each formatted as <name>=<path of a Go plugin or grpc://<host>:<port> of an external backend>. A plugin's API is served under /ext/plugin/<name>. Example: stats=/opt/gecko/stats.so,ops=grpc://127.0.0.1:9700")
+
+	// External signer:
+	fs.StringVar(&Config.SignerURI, "signer-uri", "", "URI of an external signing service that wallet operations request signatures from. If empty, only keys in the keystore are used")
+	signerOps := fs.String("signer-operations", "", "Comma separated list of the operations the external signer may be asked to sign. Example: avm.send")
+	fs.DurationVar(&Config.SignerTimeout, "signer-timeout", 10*time.Second, "Timeout for requests to the external signer")
+
+	// Shutdown:
+	fs.DurationVar(&Config.ShutdownGracePeriod, "shutdown-grace-period", 30*time.Second, "Maximum time to wait, when the node is stopped, for API requests and chains to finish their in-flight work before the database is closed. If 0, the node waits indefinitely")
+
+	// Upgrades:
+	chainUpgradesFile := fs.String("chain-upgrades-file", "", "Path to a JSON file that maps chain IDs or aliases to the rule changes scheduled for their VM. Every node of the network must schedule the same upgrades")
+	fs.StringVar(&Config.ReleaseManifest, "release-manifest", "", "Path or URL of the signed release manifest that this node's version is periodically checked against. If empty, the version is only checked against the versions of the connected validators")
+	releaseKey := fs.String("release-key", "", "CB58 encoded secp256k1 public key that the release manifest must be signed with")
+
+	// Snapshots:
+	fs.StringVar(&Config.SnapshotDir, "snapshot-dir", "snapshots", "Directory of the snapshots of chains' databases that this node serves to its peers")
+	fs.BoolVar(&Config.SnapshotAPIEnabled, "api-snapshot-enabled", false, "If true, this node exposes the Snapshot API, which creates snapshots of chains' databases")
+	snapshotSync := fs.String("snapshot-sync", "", "Comma separated list of snapshots that are downloaded from peers, before the chains are started, each formatted as <chain>=<snapshot ID>. A chain is an ID or alias. A snapshot is only downloaded into an empty database. The snapshot ID must come from a trusted source, since the chain's state isn't verified. Example: X=2Ctt6eGAeo4MLqTmGa7AdRecuVMPGWEX9wSsCLBYrLhX4a394i")
+
+	// Replay:
+	replayChains := fs.String("replay-chains", "", "Comma separated list of the IDs or aliases of chains whose accepted containers are replayed against a fresh VM on startup, to check that execution is deterministic. Example: X,P")
 
 	// Throughput Server
 	throughputPort := fs.Uint("xput-server-port", 9652, "Port of the deprecated throughput test server")
@@ -113,24 +239,118 @@ func init() {
 
 	if ferr != nil {
 		// other type of error occurred when parsing args
-		os.Exit(2)
+		os.Exit(exitConfigError)
 	}
 
-	networkID, err := genesis.NetworkID(*networkName)
-	errs.Add(err)
+	// Flags given on the command line take precedence over environment
+	// variables, which take precedence over the config file
+	errs.Add(loadEnv(fs))
+
+	chainConfigs := map[string]chainConfig{}
+	ConfigFile = *configFile
+	if *configFile != "" {
+		chainConfigs, err = loadConfigFile(fs, *configFile)
+		errs.Add(err)
+	}
+
+	if strings.EqualFold(*networkName, genesis.CustomName) {
+		// The network ID of a custom network is the one in its genesis file
+		if *genesisFile == "" {
+			errs.Add(errNoCustomGenesisFile)
+		} else {
+			Config.NetworkID, Config.GenesisBytes, err = genesis.CustomFromFile(*genesisFile)
+			errs.Add(err)
+		}
+	} else {
+		var networkID uint32
+		networkID, err = genesis.NetworkID(*networkName)
+		errs.Add(err)
+
+		Config.NetworkID = networkID
 
-	if networkID != genesis.LocalID {
-		errs.Add(fmt.Errorf("the only supported networkID is: %s", genesis.LocalName))
+		if *genesisFile != "" {
+			Config.GenesisBytes, err = genesis.FromFile(networkID, *genesisFile)
+			errs.Add(err)
+		} else if networkID != genesis.LocalID {
+			errs.Add(fmt.Errorf("the only supported networkID without a genesis file is: %s", genesis.LocalName))
+		}
 	}
 
-	Config.NetworkID = networkID
+	// Uptime rewards:
+	if *uptimeRewards {
+		if *uptimeRequirement <= 0 || *uptimeRequirement > 1 {
+			errs.Add(fmt.Errorf("uptime reward requirement must be in (0, 1] but is %f", *uptimeRequirement))
+		}
+		Config.UptimeRewardThreshold = *uptimeRequirement
+	}
 
 	// DB:
 	if *db && err == nil {
 		// TODO: Add better params here
-		dbPath := path.Join(*dbDir, genesis.NetworkName(Config.NetworkID))
-		db, err := leveldb.New(dbPath, 0, 0, 0)
-		Config.DB = db
+		dir, notes, err := dbPath(*dbDir, genesis.NetworkName(Config.NetworkID), *dbReadOnly)
+		DBNotes = notes
+		Config.DBDir = dir
+
+		// Backups are only restored into an empty database, before any chain
+		// runs
+		restoreFile, asideDir := *dbRestoreFile, ""
+		switch {
+		case err != nil:
+		case restoreFile != "" && *dbReadOnly:
+			err = errReadOnlyRestore
+		case restoreFile != "" && exists(dir):
+			err = fmt.Errorf("--db-restore-file can only restore into an empty database, but %s contains one", dir)
+		case restoreFile == "":
+			restoreFile, err = backup.PendingRestore(dir)
+			switch {
+			case err != nil, restoreFile == "":
+			case *dbReadOnly:
+				DBNotes = append(DBNotes, fmt.Sprintf("the database is opened read-only, so the restore of %s scheduled in %s isn't done", restoreFile, dir))
+				restoreFile = ""
+			default:
+				asideDir, err = setAsideDB(dir)
+			}
+		}
+
+		if err == nil {
+			switch {
+			case *dbType == leveldbType && *dbReadOnly:
+				var ldb *leveldb.Database
+				if ldb, err = leveldb.NewReadOnly(dir, leveldbConf); err == nil {
+					Config.DB = newOverlayDB(ldb)
+				}
+			case *dbReadOnly:
+				err = fmt.Errorf("only a %s database can be opened read-only", leveldbType)
+			case *dbType == leveldbType && Config.ReadOnly:
+				Config.DB, err = leveldb.NewStrict(dir, leveldbConf)
+			case *dbType == leveldbType:
+				Config.DB, err = leveldb.New(dir, leveldbConf)
+			case *dbType == badgerdbType && Config.ReadOnly:
+				Config.DB, err = badgerdb.NewStrict(filepath.Join(dir, badgerdbType))
+			case *dbType == badgerdbType:
+				Config.DB, err = badgerdb.New(filepath.Join(dir, badgerdbType))
+			case *dbType == rocksdbType:
+				// RocksDB always verifies checksums, so it has no strict mode
+				Config.DB, err = openRocksDB(filepath.Join(dir, rocksdbType), rocksdbConf)
+			default:
+				err = fmt.Errorf("unknown database type %q. Should be one of {%s, %s, %s}", *dbType, leveldbType, badgerdbType, rocksdbType)
+			}
+		}
+		if err == nil && *dbEncrypt {
+			Config.DB, err = newEncryptedDB(Config.DB, *dbPassphraseFile)
+		}
+		if err != nil && asideDir != "" {
+			// The empty database couldn't be opened, so the database that was
+			// set aside is moved back
+			if os.RemoveAll(dir) == nil {
+				_ = os.Rename(asideDir, dir)
+			}
+		} else if err == nil && restoreFile != "" {
+			var note string
+			if note, err = restoreDB(Config.DB, dir, asideDir, restoreFile); err == nil {
+				DBNotes = append(DBNotes, note)
+			}
+		}
 		errs.Add(err)
 	} else {
 		Config.DB = memdb.New()
@@ -139,11 +359,20 @@ func init() {
 	Config.Nat = nat.Any()
 
 	var ip net.IP
-	// If public IP is not specified, get it using shell command dig
-	if *consensusIP == "" {
+	switch {
+	case *dynamicPublicIP != "":
+		Config.DynamicPublicIPResolver, err = dynamicip.NewResolver(*dynamicPublicIP)
+		if err == nil {
+			ip, err = Config.DynamicPublicIPResolver.Resolve()
+		}
+		errs.Add(err)
+	case *consensusIP == "":
+		// If public IP is not specified, ask the NAT router for it
 		ip, err = Config.Nat.ExternalIP()
-		errs.Add(fmt.Errorf("%s\nIf you are trying to create a local network, try adding --public-ip=127.0.0.1", err))
-	} else {
+		if err != nil {
+			errs.Add(fmt.Errorf("%s\nIf you are trying to create a local network, try adding --public-ip=127.0.0.1", err))
+		}
+	default:
 		ip = net.ParseIP(*consensusIP)
 	}
 
@@ -193,6 +422,11 @@ func init() {
 
 	// HTTP:
 	Config.HTTPPort = uint16(*httpPort)
+	for _, host := range strings.Split(*httpHosts, ",") {
+		if host != "" {
+			Config.HTTPHosts = append(Config.HTTPHosts, host)
+		}
+	}
 
 	// Logging:
 	if *logsDir != "" {
@@ -209,11 +443,304 @@ func init() {
 	errs.Add(err)
 	loggingConfig.DisplayLevel = displayLevel
 
+	format, err := logging.ToFormat(*logFormat)
+	errs.Add(err)
+	loggingConfig.Format = format
+	loggingConfig.DisableCompression = !*logCompression
+
+	for _, sink := range strings.Split(*logSinks, ",") {
+		if sink == "" {
+			continue
+		}
+		sinkConfig, err := logging.ToSinkConfig(sink)
+		errs.Add(err)
+		loggingConfig.Sinks = append(loggingConfig.Sinks, sinkConfig)
+	}
+
+	if loggingConfig.RotationInterval <= 0 {
+		errs.Add(fmt.Errorf("log-rotation-interval must be positive, got %s", loggingConfig.RotationInterval))
+	}
+	if loggingConfig.FileSize <= 0 {
+		errs.Add(fmt.Errorf("log-file-size must be positive, got %d", loggingConfig.FileSize))
+	}
+	if loggingConfig.RotationSize < 0 || loggingConfig.MaxTotalSize < 0 {
+		errs.Add(errors.New("log-max-files and log-max-total-size can't be negative"))
+	}
+
 	Config.LoggingConfig = loggingConfig
 
+	Config.LogLevels, err = parseLogLevels(*logLevels)
+	errs.Add(err)
+	Config.LogDisplayLevels, err = parseLogLevels(*logDisplayLevels)
+	errs.Add(err)
+
+	// External signer:
+	for _, op := range strings.Split(*signerOps, ",") {
+		if op != "" {
+			Config.SignerOperations = append(Config.SignerOperations, op)
+		}
+	}
+
+	// Upgrades:
+	if *chainUpgradesFile != "" {
+		upgradesBytes, err := ioutil.ReadFile(*chainUpgradesFile)
+		errs.Add(err)
+		if err == nil {
+			errs.Add(json.Unmarshal(upgradesBytes, &Config.ChainUpgrades))
+		}
+		for chain, upgrades := range Config.ChainUpgrades {
+			if err := upgrades.Verify(); err != nil {
+				errs.Add(fmt.Errorf("invalid upgrades for chain %s: %w", chain, err))
+			}
+		}
+	}
+	if Config.ReleaseManifest != "" {
+		keyBytes := formatting.CB58{}
+		if err := keyBytes.FromString(*releaseKey); err != nil {
+			errs.Add(fmt.Errorf("release-manifest requires a valid release-key: %w", err))
+		} else {
+			Config.ReleaseKey, err = (&crypto.FactorySECP256K1R{}).ToPublicKey(keyBytes.Bytes)
+			errs.Add(err)
+		}
+	}
+
+	// Chain configs. Upgrades from chain-upgrades-file and log levels from
+	// log-levels and log-display-levels take precedence.
+	if Config.ChainUpgrades == nil {
+		Config.ChainUpgrades = make(map[string]snow.Upgrades)
+	}
+	if Config.LogLevels == nil {
+		Config.LogLevels = make(map[string]logging.Level)
+	}
+	if Config.LogDisplayLevels == nil {
+		Config.LogDisplayLevels = make(map[string]logging.Level)
+	}
+	Config.MempoolConfig.ChainPolicies = make(map[string]mempool.Policy)
+	for chain, chainConfig := range chainConfigs {
+		if _, exists := Config.ChainUpgrades[chain]; !exists && len(chainConfig.Upgrades) > 0 {
+			if err := chainConfig.Upgrades.Verify(); err != nil {
+				errs.Add(fmt.Errorf("invalid upgrades for chain %s: %w", chain, err))
+			}
+			Config.ChainUpgrades[chain] = chainConfig.Upgrades
+		}
+		if _, exists := Config.LogLevels[chain]; !exists && chainConfig.LogLevel != "" {
+			level, err := logging.ToLevel(chainConfig.LogLevel)
+			errs.Add(err)
+			Config.LogLevels[chain] = level
+		}
+		if _, exists := Config.LogDisplayLevels[chain]; !exists && chainConfig.LogDisplayLevel != "" {
+			level, err := logging.ToLevel(chainConfig.LogDisplayLevel)
+			errs.Add(err)
+			Config.LogDisplayLevels[chain] = level
+		}
+		if chainConfig.MempoolTxPriority != "" {
+			policy, policyErr := mempool.ParsePolicy(chainConfig.MempoolTxPriority)
+			errs.Add(policyErr)
+			Config.MempoolConfig.ChainPolicies[chain] = policy
+		}
+	}
+
+	// Subnets:
+	for _, subnet := range strings.Split(*trackSubnets, ",") {
+		if subnet == "" {
+			continue
+		}
+		subnetID, err := ids.FromString(subnet)
+		if err != nil {
+			errs.Add(fmt.Errorf("invalid subnet ID %q in track-subnets: %w", subnet, err))
+			continue
+		}
+		Config.TrackedSubnets.Add(subnetID)
+	}
+
+	// Consensus networking:
+	Config.ChainWeights, err = parseChainWeights(*chainWeights)
+	errs.Add(err)
+	if Config.MinRequestTimeout <= 0 || Config.MinRequestTimeout > Config.MaxRequestTimeout {
+		errs.Add(fmt.Errorf("network-timeout-min (%s) should be positive and at most network-timeout-max (%s)", Config.MinRequestTimeout, Config.MaxRequestTimeout))
+	}
+	if *throttleBurst < 0 {
+		errs.Add(fmt.Errorf("network-throttle-burst (%s) shouldn't be negative", *throttleBurst))
+	}
+	Config.InboundThrottling.Burst = *throttleBurst
+	Config.OutboundThrottling.Burst = *throttleBurst
+
+	// State mode:
+	Config.StateMode, err = snow.ParseStateMode(*stateMode)
+	errs.Add(err)
+	if Config.StatePrune && Config.StateMode != snow.PrunedState {
+		errs.Add(errors.New("state-prune requires state-mode to be pruned"))
+	}
+
+	// Read-only mode:
+	if Config.ReadOnly && Config.SignerURI != "" {
+		errs.Add(errors.New("a read-only node can't use an external signer"))
+	}
+	if Config.ReadOnly && Config.ThroughputServerEnabled {
+		errs.Add(errors.New("a read-only node can't run the throughput test server"))
+	}
+
+	// API auth:
+	if Config.APIAuthRequired {
+		if *apiAuthPasswordFile == "" {
+			errs.Add(errNoAuthPasswordFile)
+		} else {
+			password, err := readPassphrase(*apiAuthPasswordFile)
+			errs.Add(err)
+			Config.APIAuthPassword = string(password)
+		}
+	}
+
+	// API routes:
+	Config.ChainRoutes, err = parseChainRoutes(*chainRoutes)
+	errs.Add(err)
+	Config.APIPlugins, err = parseAPIPlugins(*apiPlugins)
+	errs.Add(err)
+
+	// VM plugins:
+	Config.ChainVMPlugins, err = parseVMPlugins(*chainVMPlugins)
+	errs.Add(err)
+	Config.DAGVMPlugins, err = parseVMPlugins(*dagVMPlugins)
+	errs.Add(err)
+
+	// Snapshots:
+	Config.SnapshotSync, err = parseSnapshotSync(*snapshotSync)
+	errs.Add(err)
+
+	// Replay:
+	for _, chain := range strings.Split(*replayChains, ",") {
+		if chain != "" {
+			Config.ReplayChains = append(Config.ReplayChains, chain)
+		}
+	}
+
 	// Throughput:
 	Config.ThroughputPort = uint16(*throughputPort)
 
 	// Router used for consensus
 	Config.ConsensusRouter = &router.ChainRouter{}
 }
+
+// parseSnapshotSync parses a comma separated list of <chain>=<snapshot ID>
+func parseSnapshotSync(s string) (map[string]ids.ID, error) {
+	snapshots := make(map[string]ids.ID)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("snapshot %q should be formatted as <chain>=<snapshot ID>", entry)
+		}
+		snapshotID, err := ids.FromString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot ID %q in snapshot-sync: %w", parts[1], err)
+		}
+		snapshots[parts[0]] = snapshotID
+	}
+	return snapshots, nil
+}
+
+// parseChainWeights parses a comma separated list of <chain>=<weight>
+func parseChainWeights(s string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("chain weight %q should be formatted as <chain>=<weight>", entry)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil || weight < 1 {
+			return nil, fmt.Errorf("weight of chain %s should be a positive integer but is %q", parts[0], parts[1])
+		}
+		weights[parts[0]] = weight
+	}
+	return weights, nil
+}
+
+// parseChainRoutes parses a comma separated list of <route>=<chain>
+func parseChainRoutes(s string) (map[string]string, error) {
+	routes := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("chain route %q should be formatted as <route>=<chain>", entry)
+		}
+		if err := api.CheckChainRoute(parts[0]); err != nil {
+			return nil, err
+		}
+		routes[parts[0]] = parts[1]
+	}
+	return routes, nil
+}
+
+// parseAPIPlugins parses a comma separated list of <name>=<target>
+func parseAPIPlugins(s string) (map[string]string, error) {
+	plugins := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("API plugin %q should be formatted as <name>=<target>", entry)
+		}
+		if url.PathEscape(parts[0]) != parts[0] {
+			return nil, fmt.Errorf("API plugin name %q can't be used in a URL path", parts[0])
+		}
+		if _, exists := plugins[parts[0]]; exists {
+			return nil, fmt.Errorf("API plugin %q is listed more than once", parts[0])
+		}
+		plugins[parts[0]] = parts[1]
+	}
+	return plugins, nil
+}
+
+// parseVMPlugins parses a comma separated list of <VM ID>=<path>
+func parseVMPlugins(s string) (map[[32]byte]string, error) {
+	plugins := make(map[[32]byte]string)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("VM plugin %q should be formatted as <VM ID>=<path>", entry)
+		}
+		vmID, err := ids.FromString(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("VM plugin %q has an invalid VM ID: %w", entry, err)
+		}
+		if _, exists := plugins[vmID.Key()]; exists {
+			return nil, fmt.Errorf("VM %s is listed more than once", vmID)
+		}
+		plugins[vmID.Key()] = parts[1]
+	}
+	return plugins, nil
+}
+
+// parseLogLevels parses a comma separated list of <name>=<level>
+func parseLogLevels(s string) (map[string]logging.Level, error) {
+	levels := make(map[string]logging.Level)
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("log level %q should be formatted as <name>=<level>", entry)
+		}
+		level, err := logging.ToLevel(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		levels[parts[0]] = level
+	}
+	return levels, nil
+}
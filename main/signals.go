@@ -0,0 +1,117 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"syscall"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// handleSignals handles the signals that control a running node until [done]
+// is closed. SIGHUP reloads the levels of the named loggers from the config
+// file at [configPath]. SIGUSR1 writes the stacks of all goroutines to [log].
+func handleSignals(log logging.Logger, factory logging.Factory, configPath string, done <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	serveSignals(log, factory, configPath, signals, done)
+}
+
+// serveSignals handles the signals received on [signals] until [done] is
+// closed
+func serveSignals(log logging.Logger, factory logging.Factory, configPath string, signals <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			switch sig {
+			case syscall.SIGHUP:
+				if configPath == "" {
+					log.Warn("SIGHUP received but there is no config file to reload")
+					continue
+				}
+				if err := reloadLogLevels(factory, configPath); err != nil {
+					log.Error("couldn't reload the log levels from %s: %s", configPath, err)
+					continue
+				}
+				log.Info("reloaded the log levels from %s", configPath)
+			case syscall.SIGUSR1:
+				stacks := bytes.Buffer{}
+				if err := pprof.Lookup("goroutine").WriteTo(&stacks, 2); err != nil {
+					log.Error("couldn't dump the goroutines: %s", err)
+					continue
+				}
+				log.Info("goroutine dump:\n%s", stacks.String())
+			}
+		}
+	}
+}
+
+// reloadLogLevels sets the levels of the named loggers to the ones in the
+// log-levels and log-display-levels entries and the chains section of the
+// config file at [path]. They override the levels given on the command line.
+func reloadLogLevels(factory logging.Factory, path string) error {
+	entries, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	logLevels, err := namedLogLevels(entries["log-levels"])
+	if err != nil {
+		return fmt.Errorf("invalid log-levels: %w", err)
+	}
+	displayLevels, err := namedLogLevels(entries["log-display-levels"])
+	if err != nil {
+		return fmt.Errorf("invalid log-display-levels: %w", err)
+	}
+
+	if raw, ok := entries[chainsKey]; ok {
+		chains := map[string]chainConfig{}
+		if err := json.Unmarshal(raw, &chains); err != nil {
+			return fmt.Errorf("invalid %s section: %w", chainsKey, err)
+		}
+		for chain, config := range chains {
+			if config.LogLevel != "" {
+				if logLevels[chain], err = logging.ToLevel(config.LogLevel); err != nil {
+					return err
+				}
+			}
+			if config.LogDisplayLevel != "" {
+				if displayLevels[chain], err = logging.ToLevel(config.LogDisplayLevel); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for name, level := range logLevels {
+		factory.SetLogLevel(name, level)
+	}
+	for name, level := range displayLevels {
+		factory.SetDisplayLevel(name, level)
+	}
+	return nil
+}
+
+// namedLogLevels parses a config file entry in the format of the log-levels
+// flag. A missing entry has no levels.
+func namedLogLevels(raw json.RawMessage) (map[string]logging.Level, error) {
+	if raw == nil {
+		return map[string]logging.Level{}, nil
+	}
+	value, err := flagValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	return parseLogLevels(value)
+}
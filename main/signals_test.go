@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// testSignalFactory records the levels set on it
+type testSignalFactory struct {
+	logging.Factory
+	logLevels, displayLevels map[string]logging.Level
+}
+
+func newTestSignalFactory() *testSignalFactory {
+	return &testSignalFactory{
+		logLevels:     map[string]logging.Level{},
+		displayLevels: map[string]logging.Level{},
+	}
+}
+
+func (f *testSignalFactory) SetLogLevel(name string, level logging.Level) {
+	f.logLevels[name] = level
+}
+
+func (f *testSignalFactory) SetDisplayLevel(name string, level logging.Level) {
+	f.displayLevels[name] = level
+}
+
+// testSignalLog records the messages logged at the info, warn and error
+// levels
+type testSignalLog struct {
+	logging.NoLog
+	infos, warns, errors []string
+}
+
+func (l *testSignalLog) Info(format string, args ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+
+func (l *testSignalLog) Warn(format string, args ...interface{}) {
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+func (l *testSignalLog) Error(format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+// sendSignals handles [sigs] and returns once they have all been handled
+func sendSignals(log logging.Logger, factory logging.Factory, configPath string, sigs ...os.Signal) {
+	signals := make(chan os.Signal)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		serveSignals(log, factory, configPath, signals, done)
+		close(stopped)
+	}()
+
+	for _, sig := range sigs {
+		signals <- sig
+	}
+	// [signals] is unbuffered, so the last signal was received and is handled
+	// before [done] is noticed
+	close(done)
+	<-stopped
+}
+
+func TestServeSignalsReload(t *testing.T) {
+	path := writeConfigFile(t, "config.yaml", `
+log-levels: network=debug,http=verbo
+log-display-levels: network=warn
+chains:
+  X:
+    log-level: error
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	log := &testSignalLog{}
+	factory := newTestSignalFactory()
+	sendSignals(log, factory, path, syscall.SIGHUP)
+
+	if len(log.errors) != 0 {
+		t.Fatalf("Reloading the log levels errored with %v", log.errors)
+	}
+	expectedLogLevels := map[string]logging.Level{
+		"network": logging.Debug,
+		"http":    logging.Verbo,
+		"X":       logging.Error,
+	}
+	if len(factory.logLevels) != len(expectedLogLevels) {
+		t.Fatalf("Set log levels %v but should have set %v", factory.logLevels, expectedLogLevels)
+	}
+	for name, level := range expectedLogLevels {
+		if factory.logLevels[name] != level {
+			t.Fatalf("Set log levels %v but should have set %v", factory.logLevels, expectedLogLevels)
+		}
+	}
+	if len(factory.displayLevels) != 1 || factory.displayLevels["network"] != logging.Warn {
+		t.Fatalf("Wrong display levels %v", factory.displayLevels)
+	}
+}
+
+func TestServeSignalsReloadErrors(t *testing.T) {
+	log := &testSignalLog{}
+	factory := newTestSignalFactory()
+	sendSignals(log, factory, "", syscall.SIGHUP)
+	if len(log.warns) != 1 || len(factory.logLevels) != 0 {
+		t.Fatalf("SIGHUP without a config file should only have warned")
+	}
+
+	path := writeConfigFile(t, "config.json", `{"log-levels": "network=debug,http=unknown"}`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	log = &testSignalLog{}
+	sendSignals(log, factory, path, syscall.SIGHUP)
+	if len(log.errors) != 1 || len(factory.logLevels) != 0 {
+		t.Fatalf("An invalid config file should have been reported without setting any level")
+	}
+}
+
+func TestServeSignalsGoroutineDump(t *testing.T) {
+	log := &testSignalLog{}
+	sendSignals(log, newTestSignalFactory(), "", syscall.SIGUSR1)
+
+	if len(log.infos) != 1 || !strings.Contains(log.infos[0], "TestServeSignalsGoroutineDump") {
+		t.Fatalf("SIGUSR1 should have logged the stacks of the goroutines")
+	}
+}
@@ -5,6 +5,7 @@ package networking
 
 import (
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils"
 )
@@ -16,11 +17,12 @@ type Builder struct{ Codec }
 func (m Builder) GetVersion() (Msg, error) { return m.Pack(GetVersion, nil) }
 
 // Version message
-func (m Builder) Version(networkID uint32, myTime uint64, myVersion string) (Msg, error) {
+func (m Builder) Version(networkID uint32, myTime uint64, myVersion string, stateMode snow.StateMode) (Msg, error) {
 	return m.Pack(Version, map[Field]interface{}{
 		NetworkID:  networkID,
 		MyTime:     myTime,
 		VersionStr: myVersion,
+		StateMode:  uint32(stateMode),
 	})
 }
 
@@ -130,6 +132,96 @@ func (m Builder) Chits(chainID ids.ID, requestID uint32, containerIDs ids.Set) (
 	})
 }
 
+// GetStateSummaries message
+func (m Builder) GetStateSummaries(chainID ids.ID, requestID uint32) (Msg, error) {
+	return m.Pack(GetStateSummaries, map[Field]interface{}{
+		ChainID:   chainID.Bytes(),
+		RequestID: requestID,
+	})
+}
+
+// StateSummaries message
+func (m Builder) StateSummaries(chainID ids.ID, requestID uint32, summaries [][]byte) (Msg, error) {
+	return m.Pack(StateSummaries, map[Field]interface{}{
+		ChainID:   chainID.Bytes(),
+		RequestID: requestID,
+		Summaries: summaries,
+	})
+}
+
+// GetStateChunk message
+func (m Builder) GetStateChunk(chainID ids.ID, requestID uint32, chunkID ids.ID) (Msg, error) {
+	return m.Pack(GetStateChunk, map[Field]interface{}{
+		ChainID:     chainID.Bytes(),
+		RequestID:   requestID,
+		ContainerID: chunkID.Bytes(),
+	})
+}
+
+// StateChunk message
+func (m Builder) StateChunk(chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte) (Msg, error) {
+	return m.Pack(StateChunk, map[Field]interface{}{
+		ChainID:        chainID.Bytes(),
+		RequestID:      requestID,
+		ContainerID:    chunkID.Bytes(),
+		ContainerBytes: chunk,
+	})
+}
+
+// GetAncestors message
+func (m Builder) GetAncestors(chainID ids.ID, requestID uint32, containerID ids.ID) (Msg, error) {
+	return m.Pack(GetAncestors, map[Field]interface{}{
+		ChainID:     chainID.Bytes(),
+		RequestID:   requestID,
+		ContainerID: containerID.Bytes(),
+	})
+}
+
+// MultiPut message
+func (m Builder) MultiPut(chainID ids.ID, requestID uint32, containers [][]byte) (Msg, error) {
+	return m.Pack(MultiPut, map[Field]interface{}{
+		ChainID:    chainID.Bytes(),
+		RequestID:  requestID,
+		Containers: containers,
+	})
+}
+
+// GetSnapshot message
+func (m Builder) GetSnapshot(chainID ids.ID, requestID uint32) (Msg, error) {
+	return m.Pack(GetSnapshot, map[Field]interface{}{
+		ChainID:   chainID.Bytes(),
+		RequestID: requestID,
+	})
+}
+
+// Snapshot message
+func (m Builder) Snapshot(chainID ids.ID, requestID uint32, manifest []byte) (Msg, error) {
+	return m.Pack(Snapshot, map[Field]interface{}{
+		ChainID:   chainID.Bytes(),
+		RequestID: requestID,
+		Bytes:     manifest,
+	})
+}
+
+// GetSnapshotChunk message
+func (m Builder) GetSnapshotChunk(chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32) (Msg, error) {
+	return m.Pack(GetSnapshotChunk, map[Field]interface{}{
+		ChainID:     chainID.Bytes(),
+		RequestID:   requestID,
+		ContainerID: snapshotID.Bytes(),
+		ChunkIndex:  index,
+	})
+}
+
+// SnapshotChunk message
+func (m Builder) SnapshotChunk(chainID ids.ID, requestID uint32, chunk []byte) (Msg, error) {
+	return m.Pack(SnapshotChunk, map[Field]interface{}{
+		ChainID:        chainID.Bytes(),
+		RequestID:      requestID,
+		ContainerBytes: chunk,
+	})
+}
+
 // Ping message
 func (m Builder) Ping() (Msg, error) { return m.Pack(Ping, nil) }
 
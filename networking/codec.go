@@ -28,13 +28,24 @@ func (Codec) Pack(op salticidae.Opcode, fields map[Field]interface{}) (Msg, erro
 		return nil, errBadOp
 	}
 
-	p := wrappers.Packer{MaxSize: math.MaxInt32}
+	// The datastream copies the packed bytes, so the buffer can be reused as
+	// soon as the datastream is created
+	p := wrappers.NewPooledPacker(math.MaxInt32)
+	defer p.Release()
+
 	for _, field := range message {
 		data, ok := fields[field]
 		if !ok {
 			return nil, errMissingField
 		}
-		field.Packer()(&p, data)
+		field.Packer()(p, data)
+	}
+	for _, field := range OptionalMessageFields[op] {
+		data, ok := fields[field]
+		if !ok {
+			break
+		}
+		field.Packer()(p, data)
 	}
 
 	if p.Errored() { // Prevent the datastream from leaking
@@ -55,19 +66,27 @@ func (Codec) Parse(op salticidae.Opcode, ds salticidae.DataStream) (Msg, error)
 		return nil, errBadOp
 	}
 
-	// TODO: make this work without copy
 	size := ds.Size()
-	p := wrappers.Packer{Bytes: make([]byte, size)}
-
 	byteHandle := ds.GetDataInPlace(size)
 	defer byteHandle.Release()
 
-	copy(p.Bytes, byteHandle.Get())
+	// The stream's bytes are read in place, and are freed once the handle is
+	// released, so only the byte slices that are unpacked are copied
+	p := wrappers.Packer{
+		Bytes:    byteHandle.Get(),
+		Borrowed: true,
+	}
 
 	fields := make(map[Field]interface{}, len(message))
 	for _, field := range message {
 		fields[field] = field.Unpacker()(&p)
 	}
+	for _, field := range OptionalMessageFields[op] {
+		if p.Offset >= size {
+			break
+		}
+		fields[field] = field.Unpacker()(&p)
+	}
 
 	if p.Offset != size {
 		return nil, errBadLength
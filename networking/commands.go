@@ -27,6 +27,10 @@ const (
 	TxID                        // Used for throughput tests
 	Tx                          // Used for throughput tests
 	Status                      // Used for throughput tests
+	Summaries                   // Used for state sync
+	StateMode                   // Used in handshake
+	Containers                  // Used for bootstrapping
+	ChunkIndex                  // Used for snapshots
 )
 
 // Packer returns the packer function that can be used to pack this field.
@@ -58,6 +62,14 @@ func (f Field) Packer() func(*wrappers.Packer, interface{}) {
 		return wrappers.TryPackBytes
 	case Status:
 		return wrappers.TryPackInt
+	case Summaries:
+		return wrappers.TryPackBytesList
+	case StateMode:
+		return wrappers.TryPackInt
+	case Containers:
+		return wrappers.TryPackBytesList
+	case ChunkIndex:
+		return wrappers.TryPackInt
 	default:
 		return nil
 	}
@@ -92,6 +104,14 @@ func (f Field) Unpacker() func(*wrappers.Packer) interface{} {
 		return wrappers.TryUnpackBytes
 	case Status:
 		return wrappers.TryUnpackInt
+	case Summaries:
+		return wrappers.TryUnpackBytesList
+	case StateMode:
+		return wrappers.TryUnpackInt
+	case Containers:
+		return wrappers.TryUnpackBytesList
+	case ChunkIndex:
+		return wrappers.TryUnpackInt
 	default:
 		return nil
 	}
@@ -123,6 +143,14 @@ func (f Field) String() string {
 		return "Tx"
 	case Status:
 		return "Status"
+	case Summaries:
+		return "Summaries"
+	case StateMode:
+		return "StateMode"
+	case Containers:
+		return "Containers"
+	case ChunkIndex:
+		return "ChunkIndex"
 	default:
 		return "Unknown Field"
 	}
@@ -154,6 +182,19 @@ const (
 	// Throughput test:
 	IssueTx
 	DecidedTx
+	// State sync:
+	GetStateSummaries
+	StateSummaries
+	GetStateChunk
+	StateChunk
+	// Bootstrapping ancestors:
+	GetAncestors
+	MultiPut
+	// Snapshots:
+	GetSnapshot
+	Snapshot
+	GetSnapshotChunk
+	SnapshotChunk
 )
 
 // Defines the messages that can be sent/received with this network
@@ -183,5 +224,25 @@ var (
 		// Throughput test:
 		IssueTx:   []Field{ChainID, Tx},
 		DecidedTx: []Field{TxID, Status},
+		// State sync:
+		GetStateSummaries: []Field{ChainID, RequestID},
+		StateSummaries:    []Field{ChainID, RequestID, Summaries},
+		GetStateChunk:     []Field{ChainID, RequestID, ContainerID},
+		StateChunk:        []Field{ChainID, RequestID, ContainerID, ContainerBytes},
+		// Bootstrapping ancestors:
+		GetAncestors: []Field{ChainID, RequestID, ContainerID},
+		MultiPut:     []Field{ChainID, RequestID, Containers},
+		// Snapshots:
+		GetSnapshot:      []Field{ChainID, RequestID},
+		Snapshot:         []Field{ChainID, RequestID, Bytes},
+		GetSnapshotChunk: []Field{ChainID, RequestID, ContainerID, ChunkIndex},
+		SnapshotChunk:    []Field{ChainID, RequestID, ContainerBytes},
+	}
+
+	// OptionalMessageFields are fields appended to messages after the
+	// messages were first defined. Peers running older versions don't send
+	// them, so they're only parsed if the message has bytes left over.
+	OptionalMessageFields = map[salticidae.Opcode][]Field{
+		Version: []Field{StateMode},
 	}
 )
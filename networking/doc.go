@@ -0,0 +1,16 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package networking connects this node to its peers over salticidae's peer
+// network.
+//
+// Dialing peers isn't retried with utils/retry. A peer is added to the peer
+// network with AddPeer, whether it's a beacon or a known peer read from the
+// peerstore on restart, and from then on salticidae's event loop dials it,
+// and dials it again after every failed attempt or lost connection, on its
+// own schedule. No attempt returns an error to Go: peerHandler is only called
+// once a connection is made or lost. So there's no failing call to wrap, and a
+// retry loop on the Go side would dial peers salticidae is already dialing.
+// utils/retry is for the connections the node's code dials itself, such as
+// the xput client's connection to its node.
+package networking
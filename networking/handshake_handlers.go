@@ -28,7 +28,10 @@ import (
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/networking/peerstore"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/networking"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/hashing"
@@ -56,7 +59,7 @@ Periodically gossip peerlists.
 
 const (
 	// CurrentVersion this avalanche instance is executing.
-	CurrentVersion = "avalanche/0.0.1"
+	CurrentVersion = "avalanche/0.0.2"
 	// MaxClockDifference allowed between connected nodes.
 	MaxClockDifference = time.Minute
 	// PeerListGossipSpacing is the amount of time to wait between pushing this
@@ -90,15 +93,23 @@ type Handshake struct {
 
 	log           logging.Logger
 	vdrs          validators.Set
-	myAddr        salticidae.NetAddr
+	myAddrLock    sync.RWMutex
+	myAddr        salticidae.NetAddr // The address this node is reachable at
 	myID          ids.ShortID
 	net           salticidae.PeerNetwork
 	enableStaking bool // Should only be false for local tests
+	stateMode     snow.StateMode
 
 	clock       timer.Clock
 	pending     AddrCert // Connections that I haven't gotten version messages from
 	connections AddrCert // Connections that I think are connected
 
+	uptimeTracker *uptime.Tracker  // Records when peers connect and disconnect
+	peerStore     *peerstore.Store // Persists the peers this node was connected to
+
+	peerInfosLock sync.RWMutex
+	peerInfos     map[[20]byte]peerInfo // Connected peer ID -> what it advertised
+
 	versionTimeout   timer.TimeoutManager
 	peerListGossiper *timer.Repeater
 
@@ -117,6 +128,9 @@ func (nm *Handshake) Initialize(
 	registerer prometheus.Registerer,
 	enableStaking bool,
 	networkID uint32,
+	uptimeTracker *uptime.Tracker,
+	stateMode snow.StateMode,
+	peerStore *peerstore.Store,
 ) {
 	log.AssertTrue(nm.net == nil, "Should only register network handlers once")
 	nm.log = log
@@ -126,6 +140,10 @@ func (nm *Handshake) Initialize(
 	nm.net = peerNet
 	nm.enableStaking = enableStaking
 	nm.networkID = networkID
+	nm.uptimeTracker = uptimeTracker
+	nm.stateMode = stateMode
+	nm.peerStore = peerStore
+	nm.peerInfos = make(map[[20]byte]peerInfo)
 
 	net := peerNet.AsMsgNetwork()
 
@@ -208,6 +226,77 @@ func (nm *Handshake) gossipPeerList() {
 // connected to this node.
 func (nm *Handshake) Connections() Connections { return &nm.connections }
 
+// peerInfo is what a peer advertised in its handshake
+type peerInfo struct {
+	version   string
+	stateMode snow.StateMode
+}
+
+// PeerStateMode returns the state mode that the connected peer [peerID]
+// advertised in its handshake. Only peers in the archive state mode can serve
+// historical queries and the ancestors of any accepted container.
+func (nm *Handshake) PeerStateMode(peerID ids.ShortID) (snow.StateMode, bool) {
+	nm.peerInfosLock.RLock()
+	defer nm.peerInfosLock.RUnlock()
+
+	info, ok := nm.peerInfos[peerID.Key()]
+	return info.stateMode, ok
+}
+
+// PeerVersions returns the versions that the connected peers advertised in
+// their handshakes
+func (nm *Handshake) PeerVersions() map[[20]byte]string {
+	nm.peerInfosLock.RLock()
+	defer nm.peerInfosLock.RUnlock()
+
+	versions := make(map[[20]byte]string, len(nm.peerInfos))
+	for peerID, info := range nm.peerInfos {
+		versions[peerID] = info.version
+	}
+	return versions
+}
+
+// setPeerInfo records what [peerID] advertised in its handshake
+func (nm *Handshake) setPeerInfo(peerID ids.ShortID, info peerInfo) {
+	nm.peerInfosLock.Lock()
+	defer nm.peerInfosLock.Unlock()
+
+	nm.peerInfos[peerID.Key()] = info
+}
+
+// forgetPeerInfo forgets what the disconnected peer [peerID] advertised
+func (nm *Handshake) forgetPeerInfo(peerID ids.ShortID) {
+	nm.peerInfosLock.Lock()
+	defer nm.peerInfosLock.Unlock()
+
+	delete(nm.peerInfos, peerID.Key())
+}
+
+// rememberPeer persists what this node knows of the peer [peerID], whose IP is
+// [ip], so it's reconnected to after this node restarts
+func (nm *Handshake) rememberPeer(peerID ids.ShortID, ip utils.IPDesc) {
+	if nm.peerStore == nil {
+		return
+	}
+
+	nm.peerInfosLock.RLock()
+	info := nm.peerInfos[peerID.Key()]
+	nm.peerInfosLock.RUnlock()
+
+	perf := nm.uptimeTracker.Performance(peerID, nm.uptimeTracker.Start())
+	err := nm.peerStore.Put(peerstore.Peer{
+		NodeID:         peerID,
+		IP:             ip,
+		Version:        info.version,
+		LastSeen:       nm.clock.Time(),
+		Uptime:         perf.Uptime,
+		AverageLatency: perf.AverageLatency,
+	})
+	if err != nil {
+		nm.log.Warn("failed to persist peer %s due to %s", peerID, err)
+	}
+}
+
 // Shutdown the network
 func (nm *Handshake) Shutdown() {
 	nm.versionTimeout.Stop()
@@ -227,7 +316,7 @@ func (nm *Handshake) SendGetVersion(addr salticidae.NetAddr) {
 // SendVersion to the requested peer
 func (nm *Handshake) SendVersion(addr salticidae.NetAddr) error {
 	build := Builder{}
-	v, err := build.Version(nm.networkID, nm.clock.Unix(), CurrentVersion)
+	v, err := build.Version(nm.networkID, nm.clock.Unix(), CurrentVersion, nm.stateMode)
 	if err != nil {
 		return fmt.Errorf("packing Version failed due to %s", err)
 	}
@@ -236,6 +325,32 @@ func (nm *Handshake) SendVersion(addr salticidae.NetAddr) error {
 	return nil
 }
 
+// UpdateIP sets the address this node is reachable at to [ip], after the
+// node's public IP changed
+func (nm *Handshake) UpdateIP(ip utils.IPDesc) error {
+	cErr := salticidae.NewError()
+	addr := salticidae.NewNetAddrFromIPPortString(ip.String(), true, &cErr)
+	if code := cErr.GetCode(); code != 0 {
+		return errors.New(salticidae.StrError(code))
+	}
+
+	nm.myAddrLock.Lock()
+	defer nm.myAddrLock.Unlock()
+
+	nm.myAddr.Free()
+	nm.myAddr = addr
+	nm.log.Info("advertising the new address %s", ip)
+	return nil
+}
+
+// isMyAddr returns true if [addr] is the address this node is reachable at
+func (nm *Handshake) isMyAddr(addr salticidae.NetAddr) bool {
+	nm.myAddrLock.RLock()
+	defer nm.myAddrLock.RUnlock()
+
+	return nm.myAddr.IsEq(addr)
+}
+
 // SendPeerList to the requested peer
 func (nm *Handshake) SendPeerList(addrs ...salticidae.NetAddr) error {
 	if len(addrs) == 0 {
@@ -310,6 +425,9 @@ func peerHandler(_conn *C.struct_peernetwork_conn_t, connected C.bool, _ unsafe.
 			cert = pendingCert
 		} else if connectedCert, exists := HandshakeNet.connections.GetID(addr); exists {
 			cert = connectedCert
+			HandshakeNet.uptimeTracker.Disconnected(cert)
+			HandshakeNet.rememberPeer(cert, ip)
+			HandshakeNet.forgetPeerInfo(cert)
 		} else {
 			return
 		}
@@ -456,10 +574,22 @@ func version(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.P
 		return
 	}
 
-	HandshakeNet.log.Debug("Finishing handshake with %s", toIPDesc(addr))
+	// Peers running versions before 0.0.2 don't advertise a state mode, and
+	// keep all historical state
+	info := peerInfo{
+		version:   pMsg.Get(VersionStr).(string),
+		stateMode: snow.ArchiveState,
+	}
+	if stateMode, ok := pMsg.Get(StateMode).(uint32); ok {
+		info.stateMode = snow.StateMode(stateMode)
+	}
+	HandshakeNet.log.Debug("Finishing handshake with %s, which runs %s and keeps %s state", toIPDesc(addr), info.version, info.stateMode)
 
 	HandshakeNet.SendPeerList(addr)
 	HandshakeNet.connections.Add(addr, cert)
+	HandshakeNet.setPeerInfo(cert, info)
+	HandshakeNet.uptimeTracker.Connected(cert)
+	HandshakeNet.rememberPeer(cert, toIPDesc(addr))
 
 	HandshakeNet.versionTimeout.Remove(cert.LongID())
 
@@ -523,7 +653,7 @@ func peerList(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 	for _, ip := range ips {
 		HandshakeNet.log.Verbo("Trying to adding peer %s", ip)
 		addr := salticidae.NewNetAddrFromIPPortString(ip.String(), false, &cErr)
-		if cErr.GetCode() == 0 && !HandshakeNet.myAddr.IsEq(addr) { // Make sure not to connect to myself
+		if cErr.GetCode() == 0 && !HandshakeNet.isMyAddr(addr) { // Make sure not to connect to myself
 			ip := toIPDesc(addr)
 
 			if !HandshakeNet.pending.ContainsIP(addr) && !HandshakeNet.connections.ContainsIP(addr) {
@@ -0,0 +1,162 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package peerstore persists what this node knows of the peers it has been
+// connected to, so a restarted node can reconnect to them without depending
+// only on its beacons.
+package peerstore
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// Peer is what this node knows of a peer it has been connected to
+type Peer struct {
+	NodeID ids.ShortID
+	IP     utils.IPDesc
+
+	// Version the peer advertised in its last handshake
+	Version string
+
+	// When this node was last connected to the peer
+	LastSeen time.Time
+
+	// Fraction of the time this node was running that the peer was connected
+	// to it, and the average time the peer took to respond to a request, as of
+	// when the peer was last seen
+	Uptime         float64
+	AverageLatency time.Duration
+}
+
+// Store persists peers in a database, keyed by their node IDs. It's safe for
+// concurrent use.
+type Store struct {
+	lock sync.Mutex
+	db   database.Database // node ID --> peer
+}
+
+// New returns a store of the peers in [db]
+func New(db database.Database) *Store { return &Store{db: db} }
+
+// Put [peer] into the store, replacing what was known of it
+func (s *Store) Put(peer Peer) error {
+	p := wrappers.Packer{MaxSize: 1 << 10}
+	p.PackIP(peer.IP)
+	p.PackStr(peer.Version)
+	p.PackLong(uint64(peer.LastSeen.Unix()))
+	p.PackLong(math.Float64bits(peer.Uptime))
+	p.PackLong(uint64(peer.AverageLatency))
+	if p.Errored() {
+		return p.Err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Put(peer.NodeID.Bytes(), p.Bytes)
+}
+
+// Get what's known of [nodeID]. Returns database.ErrNotFound if the peer isn't
+// in the store.
+func (s *Store) Get(nodeID ids.ShortID) (Peer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(nodeID.Bytes())
+	if err != nil {
+		return Peer{}, err
+	}
+	return parsePeer(nodeID, value)
+}
+
+// Peers returns the peers in the store, most recently seen first
+func (s *Store) Peers() ([]Peer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	it := s.db.NewIterator()
+	defer it.Release()
+
+	peers := []Peer(nil)
+	for it.Next() {
+		nodeID, err := ids.ToShortID(it.Key())
+		if err != nil {
+			return nil, err
+		}
+		peer, err := parsePeer(nodeID, it.Value())
+		if err != nil {
+			return nil, err
+		}
+		peers = append(peers, peer)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(peers, func(i, j int) bool { return peers[i].LastSeen.After(peers[j].LastSeen) })
+	return peers, nil
+}
+
+// Seeds returns up to [num] of the peers seen at or after [since] to connect
+// to, the ones with the best uptime first
+func (s *Store) Seeds(num int, since time.Time) ([]Peer, error) {
+	peers, err := s.Peers()
+	if err != nil {
+		return nil, err
+	}
+	seeds := []Peer(nil)
+	for _, peer := range peers {
+		if !peer.LastSeen.Before(since) {
+			seeds = append(seeds, peer)
+		}
+	}
+	sort.SliceStable(seeds, func(i, j int) bool { return seeds[i].Uptime > seeds[j].Uptime })
+	if len(seeds) > num {
+		seeds = seeds[:num]
+	}
+	return seeds, nil
+}
+
+// Forget the peers that haven't been seen since [before], and return how many
+// were forgotten
+func (s *Store) Forget(before time.Time) (int, error) {
+	peers, err := s.Peers()
+	if err != nil {
+		return 0, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	batch := s.db.NewBatch()
+	forgotten := 0
+	for _, peer := range peers {
+		if peer.LastSeen.Before(before) {
+			if err := batch.Delete(peer.NodeID.Bytes()); err != nil {
+				return 0, err
+			}
+			forgotten++
+		}
+	}
+	return forgotten, batch.Write()
+}
+
+func parsePeer(nodeID ids.ShortID, value []byte) (Peer, error) {
+	p := wrappers.Packer{Bytes: value}
+	peer := Peer{
+		NodeID:         nodeID,
+		IP:             p.UnpackIP(),
+		Version:        p.UnpackStr(),
+		LastSeen:       time.Unix(int64(p.UnpackLong()), 0),
+		Uptime:         math.Float64frombits(p.UnpackLong()),
+		AverageLatency: time.Duration(p.UnpackLong()),
+	}
+	return peer, p.Err
+}
@@ -0,0 +1,101 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peerstore
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils"
+)
+
+func testPeer(id byte, lastSeen time.Time, uptime float64) Peer {
+	return Peer{
+		NodeID:         ids.NewShortID([20]byte{id}),
+		IP:             utils.IPDesc{IP: net.IPv4(127, 0, 0, id), Port: 9651},
+		Version:        "avalanche/0.0.1",
+		LastSeen:       lastSeen,
+		Uptime:         uptime,
+		AverageLatency: time.Duration(id) * time.Millisecond,
+	}
+}
+
+func TestStorePutGet(t *testing.T) {
+	db := memdb.New()
+	s := New(db)
+
+	peer := testPeer(1, time.Unix(1000, 0), .75)
+	if err := s.Put(peer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Peers outlive the store, like they outlive the node
+	got, err := New(db).Get(peer.NodeID)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case !got.NodeID.Equals(peer.NodeID):
+		t.Fatalf("Got node ID %s but should have got %s", got.NodeID, peer.NodeID)
+	case !got.IP.Equal(peer.IP):
+		t.Fatalf("Got IP %s but should have got %s", got.IP, peer.IP)
+	case got.Version != peer.Version:
+		t.Fatalf("Got version %s but should have got %s", got.Version, peer.Version)
+	case !got.LastSeen.Equal(peer.LastSeen):
+		t.Fatalf("Got last seen %s but should have got %s", got.LastSeen, peer.LastSeen)
+	case got.Uptime != peer.Uptime:
+		t.Fatalf("Got uptime %f but should have got %f", got.Uptime, peer.Uptime)
+	case got.AverageLatency != peer.AverageLatency:
+		t.Fatalf("Got latency %s but should have got %s", got.AverageLatency, peer.AverageLatency)
+	}
+
+	if _, err := s.Get(ids.NewShortID([20]byte{2})); err != database.ErrNotFound {
+		t.Fatalf("Should have failed with %s but failed with %v", database.ErrNotFound, err)
+	}
+}
+
+func TestStoreSeeds(t *testing.T) {
+	s := New(memdb.New())
+	peers := []Peer{
+		testPeer(1, time.Unix(1000, 0), .9), // Too old to seed
+		testPeer(2, time.Unix(2000, 0), .5),
+		testPeer(3, time.Unix(3000, 0), .8),
+		testPeer(4, time.Unix(4000, 0), .2),
+	}
+	for _, peer := range peers {
+		if err := s.Put(peer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seeds, err := s.Seeds(2, time.Unix(2000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeds) != 2 {
+		t.Fatalf("Should have returned 2 seeds but returned %d", len(seeds))
+	}
+	if !seeds[0].NodeID.Equals(peers[2].NodeID) || !seeds[1].NodeID.Equals(peers[1].NodeID) {
+		t.Fatalf("Should have seeded the recent peers with the best uptime")
+	}
+
+	if forgotten, err := s.Forget(time.Unix(2000, 0)); err != nil {
+		t.Fatal(err)
+	} else if forgotten != 1 {
+		t.Fatalf("Should have forgotten 1 peer but forgot %d", forgotten)
+	}
+	known, err := s.Peers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(known) != 3 {
+		t.Fatalf("Should know 3 peers but knows %d", len(known))
+	}
+	if !known[0].NodeID.Equals(peers[3].NodeID) {
+		t.Fatalf("Should have listed the most recently seen peer first")
+	}
+}
@@ -13,6 +13,16 @@ package networking
 // void pushQuery(msg_t *, msgnetwork_conn_t *, void *);
 // void pullQuery(msg_t *, msgnetwork_conn_t *, void *);
 // void chits(msg_t *, msgnetwork_conn_t *, void *);
+// void getStateSummaries(msg_t *, msgnetwork_conn_t *, void *);
+// void stateSummaries(msg_t *, msgnetwork_conn_t *, void *);
+// void getStateChunk(msg_t *, msgnetwork_conn_t *, void *);
+// void stateChunk(msg_t *, msgnetwork_conn_t *, void *);
+// void getAncestors(msg_t *, msgnetwork_conn_t *, void *);
+// void multiPut(msg_t *, msgnetwork_conn_t *, void *);
+// void getSnapshot(msg_t *, msgnetwork_conn_t *, void *);
+// void snapshot(msg_t *, msgnetwork_conn_t *, void *);
+// void getSnapshotChunk(msg_t *, msgnetwork_conn_t *, void *);
+// void snapshotChunk(msg_t *, msgnetwork_conn_t *, void *);
 import "C"
 
 import (
@@ -25,7 +35,9 @@ import (
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking"
 	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/snow/networking/throttling"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -39,8 +51,41 @@ var (
 
 var (
 	errConnectionDropped = errors.New("connection dropped before receiving message")
+	errThrottled         = errors.New("peer exceeded its message limits")
 )
 
+// msgTypes are the types of the consensus messages, as they're labeled in the
+// failure metrics and bounded by the throttlers
+var msgTypes = map[salticidae.Opcode]string{
+	GetAcceptedFrontier: networking.GetAcceptedFrontierMsg,
+	AcceptedFrontier:    networking.AcceptedFrontierMsg,
+	GetAccepted:         networking.GetAcceptedMsg,
+	Accepted:            networking.AcceptedMsg,
+	Get:                 networking.GetMsg,
+	Put:                 networking.PutMsg,
+	PushQuery:           networking.PushQueryMsg,
+	PullQuery:           networking.PullQueryMsg,
+	Chits:               networking.ChitsMsg,
+	GetStateSummaries:   networking.GetStateSummariesMsg,
+	StateSummaries:      networking.StateSummariesMsg,
+	GetStateChunk:       networking.GetStateChunkMsg,
+	StateChunk:          networking.StateChunkMsg,
+	GetAncestors:        networking.GetAncestorsMsg,
+	MultiPut:            networking.MultiPutMsg,
+	GetSnapshot:         networking.GetSnapshotMsg,
+	Snapshot:            networking.SnapshotMsg,
+	GetSnapshotChunk:    networking.GetSnapshotChunkMsg,
+	SnapshotChunk:       networking.SnapshotChunkMsg,
+}
+
+// SnapshotHandler handles the snapshot messages received from peers
+type SnapshotHandler interface {
+	GetSnapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	Snapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32, manifest []byte)
+	GetSnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32)
+	SnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunk []byte)
+}
+
 // Voting implements the SenderExternal interface with a c++ library.
 type Voting struct {
 	votingMetrics
@@ -50,12 +95,20 @@ type Voting struct {
 	net   salticidae.PeerNetwork
 	conns Connections
 
-	router   router.Router
-	executor timer.Executor
+	router    router.Router
+	snapshots SnapshotHandler
+	executor  timer.Executor
+
+	// Counts messages that weren't delivered. May be nil.
+	failures *networking.MessageFailures
+
+	// Bound the messages received from, and sent to, each peer. Peers that
+	// keep exceeding their inbound bounds are disconnected. May be nil.
+	inbound, outbound *throttling.Throttler
 }
 
 // Initialize to the c networking library. Should only be called once ever.
-func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, registerer prometheus.Registerer) {
+func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet salticidae.PeerNetwork, conns Connections, router router.Router, failures *networking.MessageFailures, inbound, outbound *throttling.Throttler, registerer prometheus.Registerer) {
 	log.AssertTrue(s.net == nil, "Should only register network handlers once")
 	log.AssertTrue(s.conns == nil, "Should only set connections once")
 	log.AssertTrue(s.router == nil, "Should only set the router once")
@@ -65,6 +118,9 @@ func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet sal
 	s.net = peerNet
 	s.conns = conns
 	s.router = router
+	s.failures = failures
+	s.inbound = inbound
+	s.outbound = outbound
 
 	s.votingMetrics.Initialize(log, registerer)
 
@@ -79,11 +135,25 @@ func (s *Voting) Initialize(log logging.Logger, vdrs validators.Set, peerNet sal
 	net.RegHandler(PushQuery, salticidae.MsgNetworkMsgCallback(C.pushQuery), nil)
 	net.RegHandler(PullQuery, salticidae.MsgNetworkMsgCallback(C.pullQuery), nil)
 	net.RegHandler(Chits, salticidae.MsgNetworkMsgCallback(C.chits), nil)
+	net.RegHandler(GetStateSummaries, salticidae.MsgNetworkMsgCallback(C.getStateSummaries), nil)
+	net.RegHandler(StateSummaries, salticidae.MsgNetworkMsgCallback(C.stateSummaries), nil)
+	net.RegHandler(GetStateChunk, salticidae.MsgNetworkMsgCallback(C.getStateChunk), nil)
+	net.RegHandler(StateChunk, salticidae.MsgNetworkMsgCallback(C.stateChunk), nil)
+	net.RegHandler(GetAncestors, salticidae.MsgNetworkMsgCallback(C.getAncestors), nil)
+	net.RegHandler(MultiPut, salticidae.MsgNetworkMsgCallback(C.multiPut), nil)
+	net.RegHandler(GetSnapshot, salticidae.MsgNetworkMsgCallback(C.getSnapshot), nil)
+	net.RegHandler(Snapshot, salticidae.MsgNetworkMsgCallback(C.snapshot), nil)
+	net.RegHandler(GetSnapshotChunk, salticidae.MsgNetworkMsgCallback(C.getSnapshotChunk), nil)
+	net.RegHandler(SnapshotChunk, salticidae.MsgNetworkMsgCallback(C.snapshotChunk), nil)
 
 	s.executor.Initialize()
 	go log.RecoverAndPanic(s.executor.Dispatch)
 }
 
+// SetSnapshotHandler sets the handler of snapshot messages. Snapshot messages
+// are dropped until it's set. Should be called before the network is started.
+func (s *Voting) SetSnapshotHandler(handler SnapshotHandler) { s.snapshots = handler }
+
 // Shutdown threads
 func (s *Voting) Shutdown() { s.executor.Stop() }
 
@@ -114,7 +184,7 @@ func (s *Voting) Accept(chainID, containerID ids.ID, container []byte) error {
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addrs...)
+	s.send(networking.PutMsg, msg, addrs...)
 	s.numPutSent.Add(float64(len(addrs)))
 	return nil
 }
@@ -130,6 +200,7 @@ func (s *Voting) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID,
 			s.log.Verbo("Sending a GetAcceptedFrontier to %s", toIPDesc(addr))
 		} else {
 			s.log.Debug("Attempted to send a GetAcceptedFrontier message to a disconnected validator: %s", vID)
+			s.failures.SendFailed(networking.GetAcceptedFrontierMsg, vID)
 			s.executor.Add(func() { s.router.GetAcceptedFrontierFailed(vID, chainID, requestID) })
 		}
 	}
@@ -146,7 +217,7 @@ func (s *Voting) GetAcceptedFrontier(validatorIDs ids.ShortSet, chainID ids.ID,
 		chainID,
 		requestID,
 	)
-	s.send(msg, addrs...)
+	s.send(networking.GetAcceptedFrontierMsg, msg, addrs...)
 	s.numGetAcceptedFrontierSent.Add(float64(len(addrs)))
 }
 
@@ -155,6 +226,7 @@ func (s *Voting) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, reque
 	addr, exists := s.conns.GetIP(validatorID)
 	if !exists {
 		s.log.Debug("Attempted to send an AcceptedFrontier message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.AcceptedFrontierMsg, validatorID)
 		return // Validator is not connected
 	}
 
@@ -162,6 +234,7 @@ func (s *Voting) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, reque
 	msg, err := build.AcceptedFrontier(chainID, requestID, containerIDs)
 	if err != nil {
 		s.log.Error("Attempted to pack too large of an AcceptedFrontier message.\nNumber of containerIDs: %d", containerIDs.Len())
+		s.failures.SendFailed(networking.AcceptedFrontierMsg, validatorID)
 		return // Packing message failed
 	}
 
@@ -177,7 +250,7 @@ func (s *Voting) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, reque
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addr)
+	s.send(networking.AcceptedFrontierMsg, msg, addr)
 	s.numAcceptedFrontierSent.Inc()
 }
 
@@ -192,6 +265,7 @@ func (s *Voting) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestI
 			s.log.Verbo("Sending a GetAccepted to %s", toIPDesc(addr))
 		} else {
 			s.log.Debug("Attempted to send a GetAccepted message to a disconnected validator: %s", vID)
+			s.failures.SendFailed(networking.GetAcceptedMsg, vID)
 			s.executor.Add(func() { s.router.GetAcceptedFailed(vID, chainID, requestID) })
 		}
 	}
@@ -201,6 +275,7 @@ func (s *Voting) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestI
 	if err != nil {
 		for _, addr := range addrs {
 			if validatorID, exists := s.conns.GetID(addr); exists {
+				s.failures.SendFailed(networking.GetAcceptedMsg, validatorID)
 				s.executor.Add(func() { s.router.GetAcceptedFailed(validatorID, chainID, requestID) })
 			}
 		}
@@ -218,7 +293,7 @@ func (s *Voting) GetAccepted(validatorIDs ids.ShortSet, chainID ids.ID, requestI
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addrs...)
+	s.send(networking.GetAcceptedMsg, msg, addrs...)
 	s.numGetAcceptedSent.Add(float64(len(addrs)))
 }
 
@@ -227,6 +302,7 @@ func (s *Voting) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uin
 	addr, exists := s.conns.GetIP(validatorID)
 	if !exists {
 		s.log.Debug("Attempted to send an Accepted message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.AcceptedMsg, validatorID)
 		return // Validator is not connected
 	}
 
@@ -234,6 +310,7 @@ func (s *Voting) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uin
 	msg, err := build.Accepted(chainID, requestID, containerIDs)
 	if err != nil {
 		s.log.Error("Attempted to pack too large of an Accepted message.\nNumber of containerIDs: %d", containerIDs.Len())
+		s.failures.SendFailed(networking.AcceptedMsg, validatorID)
 		return // Packing message failed
 	}
 
@@ -249,7 +326,7 @@ func (s *Voting) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uin
 		requestID,
 		containerIDs,
 	)
-	s.send(msg, addr)
+	s.send(networking.AcceptedMsg, msg, addr)
 	s.numAcceptedSent.Inc()
 }
 
@@ -258,6 +335,7 @@ func (s *Voting) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	addr, exists := s.conns.GetIP(validatorID)
 	if !exists {
 		s.log.Debug("Attempted to send a Get message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.GetMsg, validatorID)
 		s.executor.Add(func() { s.router.GetFailed(validatorID, chainID, requestID, containerID) })
 		return // Validator is not connected
 	}
@@ -278,7 +356,7 @@ func (s *Voting) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 		requestID,
 		containerID,
 	)
-	s.send(msg, addr)
+	s.send(networking.GetMsg, msg, addr)
 	s.numGetSent.Inc()
 }
 
@@ -287,6 +365,7 @@ func (s *Voting) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	addr, exists := s.conns.GetIP(validatorID)
 	if !exists {
 		s.log.Debug("Attempted to send a Container message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.PutMsg, validatorID)
 		return // Validator is not connected
 	}
 
@@ -294,6 +373,7 @@ func (s *Voting) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 	msg, err := build.Put(chainID, requestID, containerID, container)
 	if err != nil {
 		s.log.Error("Attempted to pack too large of a Put message.\nContainer length: %d", len(container))
+		s.failures.SendFailed(networking.PutMsg, validatorID)
 		return // Packing message failed
 	}
 
@@ -311,7 +391,7 @@ func (s *Voting) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32,
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addr)
+	s.send(networking.PutMsg, msg, addr)
 	s.numPutSent.Inc()
 }
 
@@ -326,6 +406,7 @@ func (s *Voting) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 			s.log.Verbo("Sending a PushQuery to %s", toIPDesc(addr))
 		} else {
 			s.log.Debug("Attempted to send a PushQuery message to a disconnected validator: %s", vID)
+			s.failures.SendFailed(networking.PushQueryMsg, vID)
 			s.executor.Add(func() { s.router.QueryFailed(vID, chainID, requestID) })
 		}
 	}
@@ -335,6 +416,7 @@ func (s *Voting) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 	if err != nil {
 		for _, addr := range addrs {
 			if validatorID, exists := s.conns.GetID(addr); exists {
+				s.failures.SendFailed(networking.PushQueryMsg, validatorID)
 				s.executor.Add(func() { s.router.QueryFailed(validatorID, chainID, requestID) })
 			}
 		}
@@ -354,7 +436,7 @@ func (s *Voting) PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 		containerID,
 		formatting.DumpBytes{Bytes: container},
 	)
-	s.send(msg, addrs...)
+	s.send(networking.PushQueryMsg, msg, addrs...)
 	s.numPushQuerySent.Add(float64(len(addrs)))
 }
 
@@ -369,6 +451,7 @@ func (s *Voting) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 			s.log.Verbo("Sending a PushQuery to %s", toIPDesc(addr))
 		} else {
 			s.log.Warn("Attempted to send a PushQuery message to a disconnected validator: %s", vID)
+			s.failures.SendFailed(networking.PullQueryMsg, vID)
 			s.executor.Add(func() { s.router.QueryFailed(vID, chainID, requestID) })
 		}
 	}
@@ -387,7 +470,7 @@ func (s *Voting) PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID
 		requestID,
 		containerID,
 	)
-	s.send(msg, addrs...)
+	s.send(networking.PullQueryMsg, msg, addrs...)
 	s.numPullQuerySent.Add(float64(len(addrs)))
 }
 
@@ -396,6 +479,7 @@ func (s *Voting) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32
 	addr, exists := s.conns.GetIP(validatorID)
 	if !exists {
 		s.log.Debug("Attempted to send a Chits message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.ChitsMsg, validatorID)
 		return // Validator is not connected
 	}
 
@@ -403,6 +487,7 @@ func (s *Voting) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32
 	msg, err := build.Chits(chainID, requestID, votes)
 	if err != nil {
 		s.log.Error("Attempted to pack too large of a Chits message.\nChits length: %d", votes.Len())
+		s.failures.SendFailed(networking.ChitsMsg, validatorID)
 		return // Packing message failed
 	}
 
@@ -418,24 +503,351 @@ func (s *Voting) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32
 		requestID,
 		votes.Len(),
 	)
-	s.send(msg, addr)
+	s.send(networking.ChitsMsg, msg, addr)
 	s.numChitsSent.Inc()
 }
 
-func (s *Voting) send(msg Msg, addrs ...salticidae.NetAddr) {
+// GetStateSummaries implements the Sender interface.
+func (s *Voting) GetStateSummaries(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	addrs := []salticidae.NetAddr(nil)
+	validatorIDList := validatorIDs.List()
+	for _, validatorID := range validatorIDList {
+		vID := validatorID
+		if addr, exists := s.conns.GetIP(vID); exists {
+			addrs = append(addrs, addr)
+			s.log.Verbo("Sending a GetStateSummaries to %s", toIPDesc(addr))
+		} else {
+			s.log.Debug("Attempted to send a GetStateSummaries message to a disconnected validator: %s", vID)
+			s.failures.SendFailed(networking.GetStateSummariesMsg, vID)
+			s.executor.Add(func() { s.router.GetStateSummariesFailed(vID, chainID, requestID) })
+		}
+	}
+
+	build := Builder{}
+	msg, err := build.GetStateSummaries(chainID, requestID)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetStateSummaries message."+
+		"\nNumber of Validators: %d"+
+		"\nChain: %s"+
+		"\nRequest ID: %d",
+		len(addrs),
+		chainID,
+		requestID,
+	)
+	s.send(networking.GetStateSummariesMsg, msg, addrs...)
+	s.numGetStateSummariesSent.Add(float64(len(addrs)))
+}
+
+// StateSummaries implements the Sender interface.
+func (s *Voting) StateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a StateSummaries message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.StateSummariesMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.StateSummaries(chainID, requestID, summaries)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a StateSummaries message.\nNumber of summaries: %d", len(summaries))
+		s.failures.SendFailed(networking.StateSummariesMsg, validatorID)
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a StateSummaries message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nNumber of Summaries: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(summaries),
+	)
+	s.send(networking.StateSummariesMsg, msg, addr)
+	s.numStateSummariesSent.Inc()
+}
+
+// GetStateChunk implements the Sender interface.
+func (s *Voting) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a GetStateChunk message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.GetStateChunkMsg, validatorID)
+		s.executor.Add(func() { s.router.GetStateChunkFailed(validatorID, chainID, requestID, chunkID) })
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.GetStateChunk(chainID, requestID, chunkID)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetStateChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nChunk ID: %s",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		chunkID,
+	)
+	s.send(networking.GetStateChunkMsg, msg, addr)
+	s.numGetStateChunkSent.Inc()
+}
+
+// StateChunk implements the Sender interface.
+func (s *Voting) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a StateChunk message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.StateChunkMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.StateChunk(chainID, requestID, chunkID, chunk)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a StateChunk message.\nChunk length: %d", len(chunk))
+		s.failures.SendFailed(networking.StateChunkMsg, validatorID)
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a StateChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nChunk ID: %s"+
+		"\nChunk length: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		chunkID,
+		len(chunk),
+	)
+	s.send(networking.StateChunkMsg, msg, addr)
+	s.numStateChunkSent.Inc()
+}
+
+// GetAncestors implements the Sender interface.
+func (s *Voting) GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a GetAncestors message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.GetAncestorsMsg, validatorID)
+		s.executor.Add(func() { s.router.GetAncestorsFailed(validatorID, chainID, requestID) })
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.GetAncestors(chainID, requestID, containerID)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetAncestors message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nContainer ID: %s",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		containerID,
+	)
+	s.send(networking.GetAncestorsMsg, msg, addr)
+	s.numGetAncestorsSent.Inc()
+}
+
+// MultiPut implements the Sender interface.
+func (s *Voting) MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a MultiPut message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.MultiPutMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.MultiPut(chainID, requestID, containers)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a MultiPut message.\nNumber of containers: %d", len(containers))
+		s.failures.SendFailed(networking.MultiPutMsg, validatorID)
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a MultiPut message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nNumber of Containers: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(containers),
+	)
+	s.send(networking.MultiPutMsg, msg, addr)
+	s.numMultiPutSent.Inc()
+}
+
+// GetSnapshot asks the validators for their snapshot of the chain
+func (s *Voting) GetSnapshot(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	addrs := []salticidae.NetAddr(nil)
+	for _, validatorID := range validatorIDs.List() {
+		if addr, exists := s.conns.GetIP(validatorID); exists {
+			addrs = append(addrs, addr)
+		} else {
+			s.log.Debug("Attempted to send a GetSnapshot message to a disconnected validator: %s", validatorID)
+			s.failures.SendFailed(networking.GetSnapshotMsg, validatorID)
+		}
+	}
+
+	build := Builder{}
+	msg, err := build.GetSnapshot(chainID, requestID)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetSnapshot message."+
+		"\nNumber of Validators: %d"+
+		"\nChain: %s"+
+		"\nRequest ID: %d",
+		len(addrs),
+		chainID,
+		requestID,
+	)
+	s.send(networking.GetSnapshotMsg, msg, addrs...)
+	s.numGetSnapshotSent.Add(float64(len(addrs)))
+}
+
+// Snapshot sends the manifest of this node's snapshot of the chain
+func (s *Voting) Snapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32, manifest []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a Snapshot message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.SnapshotMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.Snapshot(chainID, requestID, manifest)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a Snapshot message.\nManifest length: %d", len(manifest))
+		s.failures.SendFailed(networking.SnapshotMsg, validatorID)
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a Snapshot message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+	)
+	s.send(networking.SnapshotMsg, msg, addr)
+	s.numSnapshotSent.Inc()
+}
+
+// GetSnapshotChunk asks the validator for a chunk of a snapshot
+func (s *Voting) GetSnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a GetSnapshotChunk message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.GetSnapshotChunkMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.GetSnapshotChunk(chainID, requestID, snapshotID, index)
+	s.log.AssertNoError(err)
+
+	s.log.Verbo("Sending a GetSnapshotChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nSnapshot ID: %s"+
+		"\nChunk Index: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		snapshotID,
+		index,
+	)
+	s.send(networking.GetSnapshotChunkMsg, msg, addr)
+	s.numGetSnapshotChunkSent.Inc()
+}
+
+// SnapshotChunk sends a chunk of this node's snapshot of the chain
+func (s *Voting) SnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunk []byte) {
+	addr, exists := s.conns.GetIP(validatorID)
+	if !exists {
+		s.log.Debug("Attempted to send a SnapshotChunk message to a disconnected validator: %s", validatorID)
+		s.failures.SendFailed(networking.SnapshotChunkMsg, validatorID)
+		return // Validator is not connected
+	}
+
+	build := Builder{}
+	msg, err := build.SnapshotChunk(chainID, requestID, chunk)
+	if err != nil {
+		s.log.Error("Attempted to pack too large of a SnapshotChunk message.\nChunk length: %d", len(chunk))
+		s.failures.SendFailed(networking.SnapshotChunkMsg, validatorID)
+		return // Packing message failed
+	}
+
+	s.log.Verbo("Sending a SnapshotChunk message."+
+		"\nValidator: %s"+
+		"\nDestination: %s"+
+		"\nChain: %s"+
+		"\nRequest ID: %d"+
+		"\nChunk Length: %d",
+		validatorID,
+		toIPDesc(addr),
+		chainID,
+		requestID,
+		len(chunk),
+	)
+	s.send(networking.SnapshotChunkMsg, msg, addr)
+	s.numSnapshotChunkSent.Inc()
+}
+
+// send [msg] to each of [addrs]. Messages that are dropped by a full outbound
+// queue are counted as [msgType] failures.
+func (s *Voting) send(msgType string, msg Msg, addrs ...salticidae.NetAddr) {
 	ds := msg.DataStream()
 	defer ds.Free()
 	ba := salticidae.NewByteArrayMovedFromDataStream(ds, false)
 	defer ba.Free()
+	size := ds.Size()
 	cMsg := salticidae.NewMsgMovedFromByteArray(msg.Op(), ba, false)
 	defer cMsg.Free()
 
-	switch len(addrs) {
-	case 0:
-	case 1:
-		s.net.SendMsg(cMsg, addrs[0])
-	default:
-		s.net.MulticastMsgByMove(cMsg, addrs)
+	for _, addr := range addrs {
+		id, exists := s.conns.GetID(addr)
+		if exists && s.outbound != nil && !s.outbound.Allow(id, msgType, size) {
+			s.log.Verbo("Throttled a %s message to %s", msgType, id)
+			s.failures.Throttled(msgType, id, false)
+			continue
+		}
+		if s.net.SendMsg(cMsg, addr) {
+			continue
+		}
+		if exists {
+			s.log.Debug("Dropped a %s message to %s", msgType, id)
+			s.failures.Dropped(msgType, id)
+		}
 	}
 }
 
@@ -447,7 +859,7 @@ func getAcceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t
 
 	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetAcceptedFrontier)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
@@ -461,13 +873,13 @@ func acceptedFrontier(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, AcceptedFrontier)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
 	containerIDs := ids.Set{}
 	for _, containerIDBytes := range msg.Get(ContainerIDs).([][]byte) {
-		containerID, err := ids.ToID(containerIDBytes)
+		containerID, err := ids.ToInternedID(containerIDBytes)
 		if err != nil {
 			VotingNet.log.Warn("Error parsing ContainerID: %v", containerIDBytes)
 			return
@@ -485,13 +897,13 @@ func getAccepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsa
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetAccepted)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
 	containerIDs := ids.Set{}
 	for _, containerIDBytes := range msg.Get(ContainerIDs).([][]byte) {
-		containerID, err := ids.ToID(containerIDBytes)
+		containerID, err := ids.ToInternedID(containerIDBytes)
 		if err != nil {
 			VotingNet.log.Warn("Error parsing ContainerID: %v", containerIDBytes)
 			return
@@ -509,13 +921,13 @@ func accepted(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Accepted)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
 	containerIDs := ids.Set{}
 	for _, containerIDBytes := range msg.Get(ContainerIDs).([][]byte) {
-		containerID, err := ids.ToID(containerIDBytes)
+		containerID, err := ids.ToInternedID(containerIDBytes)
 		if err != nil {
 			VotingNet.log.Warn("Error parsing ContainerID: %v", containerIDBytes)
 			return
@@ -533,11 +945,11 @@ func get(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Get)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
-	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
+	containerID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
 
 	VotingNet.router.Get(validatorID, chainID, requestID, containerID)
 }
@@ -549,11 +961,11 @@ func put(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Point
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Put)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
-	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
+	containerID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
 
 	containerBytes := msg.Get(ContainerBytes).([]byte)
 
@@ -567,11 +979,11 @@ func pushQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, PushQuery)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
-	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
+	containerID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
 
 	containerBytes := msg.Get(ContainerBytes).([]byte)
 
@@ -585,11 +997,11 @@ func pullQuery(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, PullQuery)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
-	containerID, _ := ids.ToID(msg.Get(ContainerID).([]byte))
+	containerID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
 
 	VotingNet.router.PullQuery(validatorID, chainID, requestID, containerID)
 }
@@ -601,13 +1013,13 @@ func chits(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Poi
 
 	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Chits)
 	if err != nil {
-		VotingNet.log.Error("Failed to sanitize message due to: %s", err)
+		VotingNet.sanitizeFailed(err)
 		return
 	}
 
 	votes := ids.Set{}
 	for _, voteBytes := range msg.Get(ContainerIDs).([][]byte) {
-		vote, err := ids.ToID(voteBytes)
+		vote, err := ids.ToInternedID(voteBytes)
 		if err != nil {
 			VotingNet.log.Warn("Error parsing chit: %v", voteBytes)
 			return
@@ -618,6 +1030,185 @@ func chits(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Poi
 	VotingNet.router.Chits(validatorID, chainID, requestID, votes)
 }
 
+// getStateSummaries handles the recept of a getStateSummaries message
+//export getStateSummaries
+func getStateSummaries(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetStateSummariesReceived.Inc()
+
+	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetStateSummaries)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	VotingNet.router.GetStateSummaries(validatorID, chainID, requestID)
+}
+
+// stateSummaries handles the recept of a stateSummaries message
+//export stateSummaries
+func stateSummaries(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numStateSummariesReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, StateSummaries)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	summaries := msg.Get(Summaries).([][]byte)
+
+	VotingNet.router.StateSummaries(validatorID, chainID, requestID, summaries)
+}
+
+// getStateChunk handles the recept of a getStateChunk message
+//export getStateChunk
+func getStateChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetStateChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetStateChunk)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	chunkID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
+
+	VotingNet.router.GetStateChunk(validatorID, chainID, requestID, chunkID)
+}
+
+// stateChunk handles the recept of a stateChunk message
+//export stateChunk
+func stateChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numStateChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, StateChunk)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	chunkID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
+
+	chunk := msg.Get(ContainerBytes).([]byte)
+
+	VotingNet.router.StateChunk(validatorID, chainID, requestID, chunkID, chunk)
+}
+
+// getAncestors handles the recept of a getAncestors message
+//export getAncestors
+func getAncestors(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetAncestorsReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetAncestors)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	containerID, _ := ids.ToInternedID(msg.Get(ContainerID).([]byte))
+
+	VotingNet.router.GetAncestors(validatorID, chainID, requestID, containerID)
+}
+
+// multiPut handles the receipt of a multiPut message
+//export multiPut
+func multiPut(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numMultiPutReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, MultiPut)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	containers := msg.Get(Containers).([][]byte)
+
+	VotingNet.router.MultiPut(validatorID, chainID, requestID, containers)
+}
+
+// getSnapshot handles the recept of a getSnapshot message
+//export getSnapshot
+func getSnapshot(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetSnapshotReceived.Inc()
+
+	validatorID, chainID, requestID, _, err := VotingNet.sanitize(_msg, _conn, GetSnapshot)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	if handler := VotingNet.snapshots; handler != nil {
+		VotingNet.executor.Add(func() { handler.GetSnapshot(validatorID, chainID, requestID) })
+	}
+}
+
+// snapshot handles the recept of a snapshot message
+//export snapshot
+func snapshot(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numSnapshotReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, Snapshot)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	manifest := msg.Get(Bytes).([]byte)
+
+	if handler := VotingNet.snapshots; handler != nil {
+		VotingNet.executor.Add(func() { handler.Snapshot(validatorID, chainID, requestID, manifest) })
+	}
+}
+
+// getSnapshotChunk handles the recept of a getSnapshotChunk message
+//export getSnapshotChunk
+func getSnapshotChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numGetSnapshotChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, GetSnapshotChunk)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	snapshotID, err := ids.ToID(msg.Get(ContainerID).([]byte))
+	VotingNet.log.AssertNoError(err)
+
+	index := msg.Get(ChunkIndex).(uint32)
+
+	if handler := VotingNet.snapshots; handler != nil {
+		VotingNet.executor.Add(func() { handler.GetSnapshotChunk(validatorID, chainID, requestID, snapshotID, index) })
+	}
+}
+
+// snapshotChunk handles the recept of a snapshotChunk message
+//export snapshotChunk
+func snapshotChunk(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe.Pointer) {
+	VotingNet.numSnapshotChunkReceived.Inc()
+
+	validatorID, chainID, requestID, msg, err := VotingNet.sanitize(_msg, _conn, SnapshotChunk)
+	if err != nil {
+		VotingNet.sanitizeFailed(err)
+		return
+	}
+
+	chunk := msg.Get(ContainerBytes).([]byte)
+
+	if handler := VotingNet.snapshots; handler != nil {
+		VotingNet.executor.Add(func() { handler.SnapshotChunk(validatorID, chainID, requestID, chunk) })
+	}
+}
+
+// sanitizeFailed logs why a received message was dropped. Throttled messages
+// aren't logged as errors, so a flooding peer doesn't flood the log too.
+func (s *Voting) sanitizeFailed(err error) {
+	if err == errThrottled {
+		s.log.Verbo("Dropped a message due to: %s", err)
+		return
+	}
+	s.log.Error("Failed to sanitize message due to: %s", err)
+}
+
 func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, op salticidae.Opcode) (ids.ShortID, ids.ID, uint32, Msg, error) {
 	conn := salticidae.PeerNetworkConnFromC(salticidae.CPeerNetworkConn((*C.peernetwork_conn_t)(_conn)))
 	addr := conn.GetPeerAddr(false)
@@ -633,13 +1224,23 @@ func (s *Voting) sanitize(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_
 	}
 
 	msg := salticidae.MsgFromC(salticidae.CMsg(_msg))
+	payload := msg.GetPayloadByMove()
+	if msgType := msgTypes[op]; s.inbound != nil && !s.inbound.Allow(validatorID, msgType, payload.Size()) {
+		s.failures.Throttled(msgType, validatorID, true)
+		if s.inbound.Abusive(validatorID) {
+			s.log.Warn("Disconnecting from %s, which keeps exceeding its message limits", validatorID)
+			s.net.DelPeer(addr)
+		}
+		return ids.ShortID{}, ids.ID{}, 0, nil, errThrottled
+	}
+
 	codec := Codec{}
-	pMsg, err := codec.Parse(op, msg.GetPayloadByMove())
+	pMsg, err := codec.Parse(op, payload)
 	if err != nil {
 		return ids.ShortID{}, ids.ID{}, 0, nil, err // The message couldn't be parsed
 	}
 
-	chainID, err := ids.ToID(pMsg.Get(ChainID).([]byte))
+	chainID, err := ids.ToInternedID(pMsg.Get(ChainID).([]byte))
 	s.log.AssertNoError(err)
 
 	requestID := pMsg.Get(RequestID).(uint32)
@@ -18,7 +18,17 @@ type votingMetrics struct {
 	numPutSent, numPutReceived,
 	numPushQuerySent, numPushQueryReceived,
 	numPullQuerySent, numPullQueryReceived,
-	numChitsSent, numChitsReceived prometheus.Counter
+	numChitsSent, numChitsReceived,
+	numGetStateSummariesSent, numGetStateSummariesReceived,
+	numStateSummariesSent, numStateSummariesReceived,
+	numGetStateChunkSent, numGetStateChunkReceived,
+	numStateChunkSent, numStateChunkReceived,
+	numGetAncestorsSent, numGetAncestorsReceived,
+	numMultiPutSent, numMultiPutReceived,
+	numGetSnapshotSent, numGetSnapshotReceived,
+	numSnapshotSent, numSnapshotReceived,
+	numGetSnapshotChunkSent, numGetSnapshotChunkReceived,
+	numSnapshotChunkSent, numSnapshotChunkReceived prometheus.Counter
 }
 
 func (vm *votingMetrics) Initialize(log logging.Logger, registerer prometheus.Registerer) {
@@ -130,6 +140,126 @@ func (vm *votingMetrics) Initialize(log logging.Logger, registerer prometheus.Re
 			Name:      "chits_received",
 			Help:      "Number of chits messages received",
 		})
+	vm.numGetStateSummariesSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_state_summaries_sent",
+			Help:      "Number of get state summaries messages sent",
+		})
+	vm.numGetStateSummariesReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_state_summaries_received",
+			Help:      "Number of get state summaries messages received",
+		})
+	vm.numStateSummariesSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "state_summaries_sent",
+			Help:      "Number of state summaries messages sent",
+		})
+	vm.numStateSummariesReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "state_summaries_received",
+			Help:      "Number of state summaries messages received",
+		})
+	vm.numGetStateChunkSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_state_chunk_sent",
+			Help:      "Number of get state chunk messages sent",
+		})
+	vm.numGetStateChunkReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_state_chunk_received",
+			Help:      "Number of get state chunk messages received",
+		})
+	vm.numStateChunkSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "state_chunk_sent",
+			Help:      "Number of state chunk messages sent",
+		})
+	vm.numStateChunkReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "state_chunk_received",
+			Help:      "Number of state chunk messages received",
+		})
+	vm.numGetAncestorsSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_ancestors_sent",
+			Help:      "Number of get ancestors messages sent",
+		})
+	vm.numGetAncestorsReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_ancestors_received",
+			Help:      "Number of get ancestors messages received",
+		})
+	vm.numMultiPutSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "multi_put_sent",
+			Help:      "Number of multi put messages sent",
+		})
+	vm.numMultiPutReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "multi_put_received",
+			Help:      "Number of multi put messages received",
+		})
+	vm.numGetSnapshotSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_snapshot_sent",
+			Help:      "Number of get snapshot messages sent",
+		})
+	vm.numGetSnapshotReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_snapshot_received",
+			Help:      "Number of get snapshot messages received",
+		})
+	vm.numSnapshotSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "snapshot_sent",
+			Help:      "Number of snapshot messages sent",
+		})
+	vm.numSnapshotReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "snapshot_received",
+			Help:      "Number of snapshot messages received",
+		})
+	vm.numGetSnapshotChunkSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_snapshot_chunk_sent",
+			Help:      "Number of get snapshot chunk messages sent",
+		})
+	vm.numGetSnapshotChunkReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "get_snapshot_chunk_received",
+			Help:      "Number of get snapshot chunk messages received",
+		})
+	vm.numSnapshotChunkSent = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "snapshot_chunk_sent",
+			Help:      "Number of snapshot chunk messages sent",
+		})
+	vm.numSnapshotChunkReceived = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "gecko",
+			Name:      "snapshot_chunk_received",
+			Help:      "Number of snapshot chunk messages received",
+		})
 
 	if err := registerer.Register(vm.numGetAcceptedFrontierSent); err != nil {
 		log.Error("Failed to register get_accepted_frontier_sent statistics due to %s", err)
@@ -185,4 +315,64 @@ func (vm *votingMetrics) Initialize(log logging.Logger, registerer prometheus.Re
 	if err := registerer.Register(vm.numChitsReceived); err != nil {
 		log.Error("Failed to register chits_received statistics due to %s", err)
 	}
+	if err := registerer.Register(vm.numGetStateSummariesSent); err != nil {
+		log.Error("Failed to register get_state_summaries_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetStateSummariesReceived); err != nil {
+		log.Error("Failed to register get_state_summaries_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numStateSummariesSent); err != nil {
+		log.Error("Failed to register state_summaries_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numStateSummariesReceived); err != nil {
+		log.Error("Failed to register state_summaries_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetStateChunkSent); err != nil {
+		log.Error("Failed to register get_state_chunk_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetStateChunkReceived); err != nil {
+		log.Error("Failed to register get_state_chunk_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numStateChunkSent); err != nil {
+		log.Error("Failed to register state_chunk_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numStateChunkReceived); err != nil {
+		log.Error("Failed to register state_chunk_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetAncestorsSent); err != nil {
+		log.Error("Failed to register get_ancestors_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetAncestorsReceived); err != nil {
+		log.Error("Failed to register get_ancestors_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numMultiPutSent); err != nil {
+		log.Error("Failed to register multi_put_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numMultiPutReceived); err != nil {
+		log.Error("Failed to register multi_put_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetSnapshotSent); err != nil {
+		log.Error("Failed to register get_snapshot_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetSnapshotReceived); err != nil {
+		log.Error("Failed to register get_snapshot_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numSnapshotSent); err != nil {
+		log.Error("Failed to register snapshot_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numSnapshotReceived); err != nil {
+		log.Error("Failed to register snapshot_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetSnapshotChunkSent); err != nil {
+		log.Error("Failed to register get_snapshot_chunk_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numGetSnapshotChunkReceived); err != nil {
+		log.Error("Failed to register get_snapshot_chunk_received statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numSnapshotChunkSent); err != nil {
+		log.Error("Failed to register snapshot_chunk_sent statistics due to %s", err)
+	}
+	if err := registerer.Register(vm.numSnapshotChunkReceived); err != nil {
+		log.Error("Failed to register snapshot_chunk_received statistics due to %s", err)
+	}
 }
@@ -4,13 +4,21 @@
 package node
 
 import (
+	"time"
+
 	"github.com/ava-labs/go-ethereum/p2p/nat"
 
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/consensus/avalanche"
 	"github.com/ava-labs/gecko/snow/networking/router"
+	"github.com/ava-labs/gecko/snow/networking/throttling"
 	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/dynamicip"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 )
 
 // Config contains all of the configurations of an Ava node.
@@ -18,12 +26,39 @@ type Config struct {
 	// protocol to use for opening the network interface
 	Nat nat.Interface
 
+	// If non-nil, the public IP of this node is resolved with
+	// [DynamicPublicIPResolver] every [DynamicUpdateDuration]
+	DynamicPublicIPResolver dynamicip.Resolver
+	DynamicUpdateDuration   time.Duration
+
 	// ID of the network this node should connect to
 	NetworkID uint32
 
+	// Genesis data of the network. If nil, the hardcoded genesis of NetworkID
+	// is used.
+	GenesisBytes []byte
+
 	// Transaction fee configuration
 	AvaTxFee uint64
 
+	// Mempool configuration
+	MempoolConfig mempool.Config
+
+	// Asset that AVM transactions pay fees in
+	AVMFeeAsset string
+
+	// Maximum number of times a dropped AVM transaction is re-issued
+	AVMReissueLimit int
+
+	// VM ID --> path of the plugin that serves the VM, for chain VMs and DAG
+	// VMs that run out of process
+	ChainVMPlugins, DAGVMPlugins map[[32]byte]string
+
+	// If positive, stakers are only preferred to be rewarded if their
+	// validator's measured uptime is at least this fraction of their staking
+	// period
+	UptimeRewardThreshold float64
+
 	// Assertions configuration
 	EnableAssertions bool
 
@@ -33,12 +68,31 @@ type Config struct {
 	// Database to use for the node
 	DB database.Database
 
+	// How much historical state the node's chains keep
+	StateMode snow.StateMode
+
+	// StatePrune is true if the blocks, vertices and transactions the chains
+	// rejected are deleted from their databases
+	StatePrune bool
+
+	// ReadOnly is true if the node follows consensus and serves queries, but
+	// never votes and rejects the transactions issued to it
+	ReadOnly bool
+
+	// IDs of the subnets, besides the default subnet, whose chains this node
+	// runs
+	TrackedSubnets ids.Set
+
 	// Staking configuration
 	StakingIP       utils.IPDesc
 	EnableStaking   bool
 	StakingKeyFile  string
 	StakingCertFile string
 
+	// Address the staking server listens on, at the port of [StakingIP]. If
+	// empty, the server listens on the IP of [StakingIP].
+	StakingHost string
+
 	// Bootstrapping configuration
 	BootstrapPeers []*Peer
 
@@ -48,17 +102,80 @@ type Config struct {
 	HTTPSKeyFile  string
 	HTTPSCertFile string
 
+	// Addresses the HTTP server listens on, at [HTTPPort]. If empty, the
+	// server listens on every interface.
+	HTTPHosts []string
+
 	// Enable/Disable APIs
 	AdminAPIEnabled    bool
 	KeystoreAPIEnabled bool
 	MetricsAPIEnabled  bool
+	HealthAPIEnabled   bool
+	EventsAPIEnabled   bool
+	InfoAPIEnabled     bool
+
+	// If [APIAuthRequired], calls to the Admin, Keystore and IPC APIs must
+	// carry a token issued with [APIAuthPassword] by the Auth API
+	APIAuthRequired bool
+	APIAuthPassword string
+
+	// Health checks are run every [HealthCheckFrequency]. The disk of the
+	// database in [DBDir] is unhealthy if less than [HealthMinFreeDisk] bytes,
+	// or less than [HealthMinFreeDiskFraction] of it, is available.
+	DBDir                     string
+	HealthCheckFrequency      time.Duration
+	HealthMinFreeDisk         uint64
+	HealthMinFreeDiskFraction float64
+
+	// If positive, the maximum number of bytes each keystore user may store
+	KeystoreUserQuota int
+
+	// External signer configuration. If [SignerURI] is empty, wallet
+	// operations only sign with keys from the keystore.
+	SignerURI        string
+	SignerOperations []string
+	SignerTimeout    time.Duration
+
+	// Path or URL of the release manifest the node's version is checked
+	// against, and the key the manifest must be signed with. If
+	// [ReleaseManifest] is empty, the version is only checked against the
+	// versions of the connected validators.
+	ReleaseManifest string
+	ReleaseKey      crypto.PublicKey
+
+	// IDs or aliases of the chains whose accepted containers are replayed
+	// against a fresh instance of their VM when they are created
+	ReplayChains []string
+
+	// Chain ID or alias --> rule changes scheduled for the chain's VM
+	ChainUpgrades map[string]snow.Upgrades
+
+	// Chain ID or alias --> weight of the chain's inbound message queue. The
+	// router passes each chain up to its weight in messages per round.
+	ChainWeights map[string]int
+
+	// Maximum time to wait, on shutdown, for API requests and chains to finish
+	// their in-flight work. If not positive, shutdown waits indefinitely.
+	ShutdownGracePeriod time.Duration
 
 	// Logging configuration
 	LoggingConfig logging.Config
 
+	// Logger name --> level of the loggers with that name. A logger is named
+	// by its subsystem, such as "network" or "http", or by its chain's ID or
+	// alias.
+	LogLevels, LogDisplayLevels map[string]logging.Level
+
 	// Consensus configuration
 	ConsensusParams avalanche.Parameters
 
+	// Bounds of the timeouts of requests to other validators. Within them, a
+	// request's timeout adapts to the response latency of its validator.
+	MinRequestTimeout, MaxRequestTimeout time.Duration
+
+	// Bounds of the consensus messages received from, and sent to, each peer
+	InboundThrottling, OutboundThrottling throttling.Config
+
 	// Throughput configuration
 	ThroughputPort          uint16
 	ThroughputServerEnabled bool
@@ -66,6 +183,28 @@ type Config struct {
 	// IPCEnabled configuration
 	IPCEnabled bool
 
+	// IndexEnabled is true if the decisions accepted by each chain are indexed
+	IndexEnabled bool
+
+	// Route --> ID or alias of the chain whose API is also served under
+	// /ext/bc/<route>
+	ChainRoutes map[string]string
+
+	// Plugin name --> path of a Go plugin, or grpc:// address of an external
+	// backend, whose API is served under /ext/plugin/<name>
+	APIPlugins map[string]string
+
+	// Directory of the snapshots of chains' databases that this node serves
+	SnapshotDir string
+
+	// SnapshotAPIEnabled is true if snapshots can be created with the Snapshot
+	// API
+	SnapshotAPIEnabled bool
+
+	// Chain ID or alias --> ID of the snapshot that is downloaded from peers
+	// into the chain's database before the chains are started
+	SnapshotSync map[string]ids.ID
+
 	// Router that is used to handle incoming consensus messages
 	ConsensusRouter router.Router
 }
@@ -9,44 +9,79 @@ package node
 import "C"
 
 import (
+	"context"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/url"
+	"path"
+	"strconv"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/ava-labs/salticidae-go"
 
 	"github.com/ava-labs/gecko/api"
 	"github.com/ava-labs/gecko/api/admin"
+	"github.com/ava-labs/gecko/api/auth"
+	"github.com/ava-labs/gecko/api/encoding"
+	"github.com/ava-labs/gecko/api/events"
+	"github.com/ava-labs/gecko/api/health"
+	"github.com/ava-labs/gecko/api/info"
 	"github.com/ava-labs/gecko/api/ipcs"
 	"github.com/ava-labs/gecko/api/keystore"
 	"github.com/ava-labs/gecko/api/metrics"
+	"github.com/ava-labs/gecko/api/plugins"
+	"github.com/ava-labs/gecko/api/signer"
 	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/corruptabledb"
 	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/genesis"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/indexer"
 	"github.com/ava-labs/gecko/networking"
+	"github.com/ava-labs/gecko/networking/peerstore"
 	"github.com/ava-labs/gecko/networking/xputtest"
+	"github.com/ava-labs/gecko/snapshot"
+	"github.com/ava-labs/gecko/snow"
+	snownetworking "github.com/ava-labs/gecko/snow/networking"
+	"github.com/ava-labs/gecko/snow/networking/throttling"
 	"github.com/ava-labs/gecko/snow/triggers"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/dynamicip"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/version"
 	"github.com/ava-labs/gecko/vms"
 	"github.com/ava-labs/gecko/vms/avm"
+	"github.com/ava-labs/gecko/vms/blsfx"
+	"github.com/ava-labs/gecko/vms/ed25519fx"
 	"github.com/ava-labs/gecko/vms/evm"
+	"github.com/ava-labs/gecko/vms/htlcfx"
 	"github.com/ava-labs/gecko/vms/platformvm"
+	"github.com/ava-labs/gecko/vms/rpcchainvm"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
 	"github.com/ava-labs/gecko/vms/spchainvm"
 	"github.com/ava-labs/gecko/vms/spdagvm"
+	"github.com/ava-labs/gecko/vms/timelockfx"
 	"github.com/ava-labs/gecko/vms/timestampvm"
 )
 
 const (
 	maxMessageSize = 1 << 25 // maximum size of a message sent with salticidae
+
+	// The known peers seen within [knownPeerMaxAge] are kept, and up to
+	// [maxSeededPeers] of them are connected to when the node starts
+	knownPeerMaxAge = 14 * 24 * time.Hour
+	maxSeededPeers  = 50
 )
 
 // MainNode is the reference for node callbacks
@@ -57,6 +92,7 @@ type Node struct {
 	Log        logging.Logger
 	LogFactory logging.Factory
 	HTTPLog    logging.Logger
+	NetworkLog logging.Logger
 
 	// This node's unique ID used when communicating with other nodes
 	// (in consensus, for example)
@@ -65,9 +101,15 @@ type Node struct {
 	// Storage for this node
 	DB database.Database
 
+	// Runs the health checks, if the Health API is enabled
+	health *health.Health
+
 	// Handles calls to Keystore API
 	keystoreServer keystore.Keystore
 
+	// Manages shared memory
+	sharedMemory atomic.Memory
+
 	// Manages creation of blockchains and routing messages to them
 	chainManager chains.Manager
 
@@ -80,6 +122,8 @@ type Node struct {
 
 	// Event loop manager
 	EC salticidae.EventContext
+	// Fatal error that stopped the event loop, if any
+	fatalErr error
 	// Network that manages validator peers
 	PeerNet salticidae.PeerNetwork
 	// Network that manages clients
@@ -93,6 +137,15 @@ type Node struct {
 	// current validators of the network
 	vdrs validators.Manager
 
+	// Records the uptime and responsiveness of peers
+	uptimeTracker *uptime.Tracker
+
+	// Persists the peers this node has been connected to
+	peerStore *peerstore.Store
+
+	// Counts the consensus messages that weren't delivered
+	msgFailures *snownetworking.MessageFailures
+
 	// APIs that handle client messages
 	// TODO: Remove
 	Issuer     *xputtest.Issuer
@@ -101,6 +154,27 @@ type Node struct {
 	// Handles HTTP API calls
 	APIServer api.Server
 
+	// Checks that this node's version is still compatible with the network
+	versionAdvisor *versionAdvisor
+
+	// Indexes the decisions accepted by each chain. Nil if indexing is
+	// disabled.
+	indexer *indexer.Indexer
+
+	// Creates, serves and downloads snapshots of the chains' databases
+	snapshots *snapshot.Manager
+
+	// API plugins that were loaded
+	plugins []plugins.Plugin
+
+	// Custom routes chains' APIs are served under
+	chainRoutes *api.ChainRoutes
+
+	// IP this node is reachable at, and what keeps it up to date if it's
+	// resolved dynamically. [ipUpdater] is nil if it isn't.
+	publicIP  *utils.DynamicIPDesc
+	ipUpdater *dynamicip.IPUpdater
+
 	// This node's configuration
 	Config *Config
 }
@@ -122,6 +196,7 @@ func errorHandler(_err *C.struct_SalticidaeCError, fatal C.bool, _ unsafe.Pointe
 	err := (*salticidae.Error)(unsafe.Pointer(_err))
 	if fatal {
 		MainNode.Log.Fatal("Error during async call: %s", salticidae.StrError(err.GetCode()))
+		MainNode.fatalErr = errors.New(salticidae.StrError(err.GetCode()))
 		MainNode.EC.Stop()
 		return
 	}
@@ -188,9 +263,18 @@ func (n *Node) initValidatorNet() error {
 		return errors.New(salticidae.StrError(code))
 	}
 
+	n.uptimeTracker = uptime.NewTracker()
+
+	n.peerStore = peerstore.New(prefixdb.New([]byte("peers"), n.DB))
+	if forgotten, err := n.peerStore.Forget(time.Now().Add(-knownPeerMaxAge)); err != nil {
+		return fmt.Errorf("failed to forget old peers: %w", err)
+	} else if forgotten > 0 {
+		n.Log.Debug("forgot %d peers that weren't seen in %s", forgotten, knownPeerMaxAge)
+	}
+
 	n.ValidatorAPI = &networking.HandshakeNet
 	n.ValidatorAPI.Initialize(
-		/*log=*/ n.Log,
+		/*log=*/ n.NetworkLog,
 		/*validators=*/ defaultSubnetValidators,
 		/*myIP=*/ serverIP,
 		/*myID=*/ n.ID,
@@ -198,6 +282,9 @@ func (n *Node) initValidatorNet() error {
 		/*metrics=*/ n.Config.ConsensusParams.Metrics,
 		/*enableStaking=*/ n.Config.EnableStaking,
 		/*networkID=*/ n.Config.NetworkID,
+		/*uptimeTracker=*/ n.uptimeTracker,
+		/*stateMode=*/ n.Config.StateMode,
+		/*peerStore=*/ n.peerStore,
 	)
 
 	return nil
@@ -208,7 +295,17 @@ func (n *Node) initConsensusNet() {
 	n.Log.AssertTrue(ok, "should have initialize the validator set already")
 
 	n.ConsensusAPI = &networking.VotingNet
-	n.ConsensusAPI.Initialize(n.Log, vdrs, n.PeerNet, n.ValidatorAPI.Connections(), n.chainManager.Router(), n.Config.ConsensusParams.Metrics)
+	n.ConsensusAPI.Initialize(
+		n.NetworkLog,
+		vdrs,
+		n.PeerNet,
+		n.ValidatorAPI.Connections(),
+		n.chainManager.Router(),
+		n.msgFailures,
+		throttling.New(n.Config.InboundThrottling),
+		throttling.New(n.Config.OutboundThrottling),
+		n.Config.ConsensusParams.Metrics,
+	)
 
 	n.Log.AssertNoError(n.ConsensusDispatcher.Register("gossip", n.ConsensusAPI))
 }
@@ -232,8 +329,13 @@ func (n *Node) StartConsensusServer() error {
 
 	err := salticidae.NewError()
 
-	// The IP this node listens on for P2P messaging
-	serverIP := salticidae.NewNetAddrFromIPPortString(n.Config.StakingIP.String(), true, &err)
+	// The IP this node listens on for P2P messaging, which may differ from the
+	// public IP it advertises
+	listenIP := n.Config.StakingIP.String()
+	if n.Config.StakingHost != "" {
+		listenIP = net.JoinHostPort(n.Config.StakingHost, strconv.Itoa(int(n.Config.StakingIP.Port)))
+	}
+	serverIP := salticidae.NewNetAddrFromIPPortString(listenIP, true, &err)
 	if code := err.GetCode(); code != 0 {
 		return fmt.Errorf("failed to create ip addr: %s", salticidae.StrError(code))
 	}
@@ -272,12 +374,36 @@ func (n *Node) StartConsensusServer() error {
 		}
 	}
 
+	// Reconnect to the peers this node knew before it restarted, so it doesn't
+	// depend only on the bootstrap nodes to rediscover the network. The peer
+	// network keeps dialing each added peer until it connects, so the dials
+	// aren't retried here.
+	seeds, seedErr := n.peerStore.Seeds(maxSeededPeers, time.Now().Add(-knownPeerMaxAge))
+	if seedErr != nil {
+		n.Log.Warn("failed to read the known peers due to %s", seedErr)
+	}
+	for _, peer := range seeds {
+		if peer.IP.Equal(n.Config.StakingIP) {
+			continue
+		}
+		n.Log.Debug("reconnecting to known peer %s at %s", peer.NodeID, peer.IP)
+		knownIP := salticidae.NewNetAddrFromIPPortString(peer.IP.String(), true, &err)
+		if code := err.GetCode(); code != 0 {
+			n.Log.Warn("failed to create ip addr of known peer %s: %s", peer.NodeID, salticidae.StrError(code))
+			continue
+		}
+		n.PeerNet.AddPeer(knownIP)
+	}
+
 	return nil
 }
 
 // Dispatch starts the node's servers.
-// Returns when the node exits.
-func (n *Node) Dispatch() { n.EC.Dispatch() }
+// Returns when the node exits, with the fatal error that stopped it, if any.
+func (n *Node) Dispatch() error {
+	n.EC.Dispatch()
+	return n.fatalErr
+}
 
 /*
  ******************************************************************************
@@ -285,7 +411,9 @@ func (n *Node) Dispatch() { n.EC.Dispatch() }
  ******************************************************************************
  */
 
-func (n *Node) initDatabase() { n.DB = n.Config.DB }
+// Once an operation of the database fails unexpectedly, the database fails
+// every later operation, rather than risk persisting an inconsistent state
+func (n *Node) initDatabase() { n.DB = corruptabledb.New(n.Config.DB) }
 
 // Initialize this node's ID
 // If staking is disabled, a node's ID is a hash of its IP
@@ -322,12 +450,31 @@ func (n *Node) initNodeID() error {
 // its factory needs to reference n.chainManager, which is nil right now
 func (n *Node) initVMManager() {
 	n.vmManager = vms.NewManager(&n.APIServer, n.HTTPLog)
-	n.vmManager.RegisterVMFactory(avm.ID, &avm.Factory{})
+	n.vmManager.RegisterVMFactory(avm.ID, &avm.Factory{
+		MempoolConfig: n.Config.MempoolConfig,
+		FeeAsset:      n.Config.AVMFeeAsset,
+		ReissueLimit:  n.Config.AVMReissueLimit,
+	})
 	n.vmManager.RegisterVMFactory(evm.ID, &evm.Factory{})
 	n.vmManager.RegisterVMFactory(spdagvm.ID, &spdagvm.Factory{TxFee: n.Config.AvaTxFee})
 	n.vmManager.RegisterVMFactory(spchainvm.ID, &spchainvm.Factory{})
 	n.vmManager.RegisterVMFactory(secp256k1fx.ID, &secp256k1fx.Factory{})
+	n.vmManager.RegisterVMFactory(blsfx.ID, &blsfx.Factory{})
+	n.vmManager.RegisterVMFactory(ed25519fx.ID, &ed25519fx.Factory{})
+	n.vmManager.RegisterVMFactory(timelockfx.ID, &timelockfx.Factory{})
+	n.vmManager.RegisterVMFactory(htlcfx.ID, &htlcfx.Factory{})
 	n.vmManager.RegisterVMFactory(timestampvm.ID, &timestampvm.Factory{})
+
+	for vmKey, path := range n.Config.ChainVMPlugins {
+		if err := n.vmManager.RegisterVMFactory(ids.NewID(vmKey), &rpcchainvm.Factory{Path: path}); err != nil {
+			n.Log.Error("couldn't register the VM of plugin %s: %s", path, err)
+		}
+	}
+	for vmKey, path := range n.Config.DAGVMPlugins {
+		if err := n.vmManager.RegisterVMFactory(ids.NewID(vmKey), &rpcchainvm.DAGFactory{Path: path}); err != nil {
+			n.Log.Error("couldn't register the VM of plugin %s: %s", path, err)
+		}
+	}
 }
 
 // Create the EventDispatcher used for hooking events
@@ -356,8 +503,12 @@ func (n *Node) initChains() {
 	n.vmManager.RegisterVMFactory(
 		/*vmID=*/ platformvm.ID,
 		/*vmFactory=*/ &platformvm.Factory{
-			ChainManager: n.chainManager,
-			Validators:   vdrs,
+			ChainManager:  n.chainManager,
+			Validators:    vdrs,
+			MempoolConfig: n.Config.MempoolConfig,
+
+			UptimeTracker:         n.uptimeTracker,
+			UptimeRewardThreshold: n.Config.UptimeRewardThreshold,
 		},
 	)
 
@@ -366,22 +517,49 @@ func (n *Node) initChains() {
 		beacons.Add(validators.NewValidator(peer.ID, 1))
 	}
 
-	genesisBytes := genesis.Genesis(n.Config.NetworkID)
+	genesisBytes := n.genesisBytes()
 
 	// Create the Platform Chain
 	n.chainManager.ForceCreateChain(chains.ChainParameters{
 		ID:            ids.Empty,
+		SubnetID:      platformvm.DefaultSubnetID,
 		GenesisData:   genesisBytes, // Specifies other chains to create
 		VMAlias:       platformvm.ID.String(),
 		CustomBeacons: beacons,
 	})
 }
 
+// initVersionAdvisor starts checking this node's version against the versions
+// of the connected validators and the release manifest
+func (n *Node) initVersionAdvisor() error {
+	myVersion, err := version.Parse(networking.CurrentVersion)
+	if err != nil {
+		return err
+	}
+	vdrs, ok := n.vdrs.GetValidatorSet(platformvm.DefaultSubnetID)
+	n.Log.AssertTrue(ok, "should have initialize the validator set already")
+
+	n.versionAdvisor = newVersionAdvisor(n.Log, myVersion, vdrs, n.ValidatorAPI, n.Config.ReleaseManifest, n.Config.ReleaseKey)
+	return nil
+}
+
 // initAPIServer initializes the server that handles HTTP calls
-func (n *Node) initAPIServer() {
+func (n *Node) initAPIServer() error {
 	n.Log.Info("Initializing API server")
 
-	n.APIServer.Initialize(n.Log, n.LogFactory, n.Config.HTTPPort)
+	n.APIServer.Initialize(n.Log, n.LogFactory, n.Config.HTTPPort, n.Config.HTTPHosts...)
+
+	if n.Config.APIAuthRequired {
+		n.Log.Info("requiring auth tokens for the privileged APIs")
+		a, err := auth.New(n.Config.APIAuthPassword)
+		if err != nil {
+			return fmt.Errorf("problem with the API auth password: %w", err)
+		}
+		n.APIServer.RequireAuth(a)
+		if err := n.APIServer.AddRoute(auth.NewService(n.Log, a), &sync.RWMutex{}, "auth", "", n.HTTPLog); err != nil {
+			return err
+		}
+	}
 
 	if n.Config.EnableHTTPS {
 		n.Log.Debug("Initializing API server with TLS Enabled")
@@ -395,10 +573,25 @@ func (n *Node) initAPIServer() {
 		n.Log.Debug("Initializing API server with TLS Disabled")
 		go n.Log.RecoverAndPanic(func() { n.APIServer.Dispatch() })
 	}
+	return nil
 }
 
 // Assumes n.DB, n.vdrs all initialized (non-nil)
 func (n *Node) initChainManager() {
+	// Wallet operations may request signatures from an external signer
+	var externalSigner snow.Signer
+	if n.Config.SignerURI != "" {
+		n.Log.Info("using the external signer at %s to sign %v", n.Config.SignerURI, n.Config.SignerOperations)
+		externalSigner = signer.NewAllowlist(
+			signer.NewClient(n.Config.SignerURI, n.Config.SignerTimeout),
+			n.Config.SignerOperations,
+		)
+	}
+
+	vdrs, ok := n.vdrs.GetValidatorSet(platformvm.DefaultSubnetID)
+	n.Log.AssertTrue(ok, "should have initialize the validator set already")
+	n.msgFailures = snownetworking.NewMessageFailures(n.Log, vdrs, n.Config.ConsensusParams.Metrics)
+
 	n.chainManager = chains.New(
 		n.Log,
 		n.LogFactory,
@@ -413,22 +606,44 @@ func (n *Node) initChainManager() {
 		n.ID,
 		n.Config.NetworkID,
 		n.ValidatorAPI,
+		n.uptimeTracker,
 		&n.APIServer,
 		&n.keystoreServer,
+		externalSigner,
+		&n.sharedMemory,
+		n.Config.ReplayChains,
+		n.Config.ChainUpgrades,
+		n.Config.ChainWeights,
+		n.Config.MinRequestTimeout,
+		n.Config.MaxRequestTimeout,
+		n.Config.StateMode,
+		n.Config.StatePrune,
+		n.Config.ReadOnly,
+		n.Config.TrackedSubnets,
+		n.msgFailures,
 	)
 
 	n.chainManager.AddRegistrant(&n.APIServer)
 }
 
+// initSharedMemory initializes the memory that chains use to atomically
+// exchange elements with each other
+func (n *Node) initSharedMemory() {
+	n.Log.Info("initializing SharedMemory")
+	sharedMemoryDB := prefixdb.New([]byte("shared memory"), n.DB)
+	n.sharedMemory.Initialize(n.Log, sharedMemoryDB)
+}
+
 // initWallet initializes the Wallet service
 // Assumes n.APIServer is already set
 func (n *Node) initKeystoreAPI() {
 	n.Log.Info("initializing Keystore API")
 	keystoreDB := prefixdb.New([]byte("keystore"), n.DB)
 	n.keystoreServer.Initialize(n.Log, keystoreDB)
+	n.keystoreServer.UserQuota = n.Config.KeystoreUserQuota
 	keystoreHandler := n.keystoreServer.CreateHandler()
 	if n.Config.KeystoreAPIEnabled {
-		n.APIServer.AddRoute(keystoreHandler, &sync.RWMutex{}, "keystore", "", n.HTTPLog)
+		n.APIServer.AddPrivilegedRoute(keystoreHandler, &sync.RWMutex{}, "keystore", "", n.HTTPLog)
 	}
 }
 
@@ -443,30 +658,238 @@ func (n *Node) initMetricsAPI() {
 	n.Config.ConsensusParams.Metrics = registry
 }
 
+// initEncodingAPI initializes the Encoding API service
+// Assumes n.APIServer is already set
+func (n *Node) initEncodingAPI() {
+	n.Log.Info("initializing Encoding API")
+	service := encoding.NewService(n.Log)
+	n.APIServer.AddRoute(service, &sync.RWMutex{}, "encoding", "", n.HTTPLog)
+}
+
 // initAdminAPI initializes the Admin API service
 // Assumes n.log, n.chainManager, and n.ValidatorAPI already initialized
 func (n *Node) initAdminAPI() {
 	if n.Config.AdminAPIEnabled {
 		n.Log.Info("initializing Admin API")
-		service := admin.NewService(n.ID, n.Config.NetworkID, n.Log, n.chainManager, n.ValidatorAPI.Connections(), &n.APIServer)
-		n.APIServer.AddRoute(service, &sync.RWMutex{}, "admin", "", n.HTTPLog)
+		service := admin.NewService(n.ID, n.Config.NetworkID, n.Log, n.LogFactory, n.chainManager, n.ValidatorAPI.Connections(), &n.APIServer, n.chainRoutes, n.versionAdvisor, n.DB, n.Config.DBDir)
+		n.APIServer.AddPrivilegedRoute(service, &sync.RWMutex{}, "admin", "", n.HTTPLog)
+	}
+}
+
+// initPublicIP starts re-resolving the IP this node is reachable at if it's
+// resolved dynamically. When it changes, the network advertises the new IP.
+// Assumes n.ValidatorAPI already initialized
+func (n *Node) initPublicIP() {
+	n.publicIP = utils.NewDynamicIPDesc(n.Config.StakingIP.IP, n.Config.StakingIP.Port)
+	if n.Config.DynamicPublicIPResolver == nil {
+		return
+	}
+	n.ipUpdater = dynamicip.NewIPUpdater(
+		n.Log,
+		n.Config.DynamicPublicIPResolver,
+		n.publicIP,
+		n.Config.DynamicUpdateDuration,
+		func(ip utils.IPDesc) {
+			if err := n.ValidatorAPI.UpdateIP(ip); err != nil {
+				n.Log.Error("failed to advertise the new IP %s: %s", ip, err)
+			}
+		},
+	)
+	go n.Log.RecoverAndPanic(n.ipUpdater.Dispatch)
+}
+
+// initInfoAPI initializes the Info API service
+// Assumes n.peerStore and n.versionAdvisor already initialized
+func (n *Node) initInfoAPI() {
+	if n.Config.InfoAPIEnabled {
+		n.Log.Info("initializing Info API")
+		service := info.NewService(n.Log, n.peerStore, n.publicIP, n.versionAdvisor)
+		n.APIServer.AddRoute(service, &sync.RWMutex{}, "info", "", n.HTTPLog)
+	}
+}
+
+// initHealthAPI initializes the Health API service, which checks the node's
+// database and the database of each chain, the space left on the database's
+// disk, and that the node's version is still compatible with the network
+// Assumes n.DB, n.chainManager and n.versionAdvisor already initialized
+func (n *Node) initHealthAPI() {
+	if !n.Config.HealthAPIEnabled {
+		return
+	}
+	n.Log.Info("initializing Health API")
+	n.health = health.NewService(n.Log, n.DB, n.Config.HealthCheckFrequency)
+	n.health.RegisterCheck("database", health.NewDatabaseCheck(prefixdb.New([]byte("health"), n.DB)))
+	if n.Config.DBDir != "" {
+		n.health.RegisterCheck("disk", health.NewDiskCheck(n.Config.DBDir, n.Config.HealthMinFreeDisk, n.Config.HealthMinFreeDiskFraction))
+	}
+	n.health.RegisterCheck("version", health.NewVersionCheck(n.versionAdvisor))
+	n.chainManager.AddRegistrant(n.health)
+	n.APIServer.AddRoute(n.health.CreateHandler(), &sync.RWMutex{}, "health", "", n.HTTPLog)
+}
+
+// initEventsAPI initializes the Events API service, which pushes accepted
+// containers to the clients subscribed to them
+// Assumes n.DecisionDispatcher and n.chainManager already initialized
+func (n *Node) initEventsAPI() {
+	if n.Config.EventsAPIEnabled {
+		n.Log.Info("initializing Events API")
+		service := events.NewService(n.Log, n.DecisionDispatcher)
+		n.chainManager.AddRegistrant(service)
+		n.APIServer.AddRoute(service.CreateHandler(), &sync.RWMutex{}, "events", "", n.HTTPLog)
+	}
+}
+
+// initChainRoutes serves chains' APIs under the configured and persisted
+// routes
+// Assumes n.DB and n.chainManager already initialized
+func (n *Node) initChainRoutes() error {
+	routesDB := prefixdb.New([]byte("chainRoutes"), n.DB)
+	chainRoutes, err := api.NewChainRoutes(n.Log, routesDB, &n.APIServer, n.Config.ChainRoutes)
+	if err != nil {
+		return err
+	}
+	n.chainRoutes = chainRoutes
+	n.chainManager.AddRegistrant(chainRoutes)
+	return nil
+}
+
+// initIndexer initializes the indexer and the Index API service
+// Assumes n.DB, n.DecisionDispatcher and n.chainManager already initialized
+func (n *Node) initIndexer() {
+	if n.Config.IndexEnabled {
+		n.Log.Info("initializing indexer")
+		indexDB := prefixdb.New([]byte("index"), n.DB)
+		n.indexer = indexer.NewIndexer(n.Log, indexDB, n.DecisionDispatcher, n.ConsensusDispatcher)
+		n.chainManager.AddRegistrant(n.indexer)
+
+		service := indexer.NewService(n.Log, n.indexer, n.chainManager)
+		n.APIServer.AddRoute(service, &sync.RWMutex{}, "index", "", n.HTTPLog)
 	}
 }
 
+// initPluginAPIs loads the API plugins and serves their APIs
+// Assumes n.chainManager is already initialized
+func (n *Node) initPluginAPIs() error {
+	node := plugins.NewNode(n.ID, n.Config.NetworkID, networking.CurrentVersion, n.chainManager)
+	for name, target := range n.Config.APIPlugins {
+		n.Log.Info("initializing API plugin %s from %s", name, target)
+		plugin, err := plugins.Load(target)
+		if err != nil {
+			return fmt.Errorf("couldn't load API plugin %s: %w", name, err)
+		}
+		pluginLog, err := n.LogFactory.MakeSubdir(path.Join("plugin", name))
+		if err != nil {
+			return fmt.Errorf("problem initializing the logger of API plugin %s: %w", name, err)
+		}
+		handlers, err := plugin.Initialize(node, pluginLog)
+		if err != nil {
+			return fmt.Errorf("couldn't initialize API plugin %s: %w", name, err)
+		}
+		n.plugins = append(n.plugins, plugin)
+
+		for extension, handler := range handlers {
+			if _, err := url.ParseRequestURI(extension); extension != "" && err != nil {
+				n.Log.Warn("API plugin %s has a malformed route: %s", name, extension)
+				continue
+			}
+			if err := n.APIServer.AddRoute(handler, &sync.RWMutex{}, "plugin/"+name, extension, n.HTTPLog); err != nil {
+				return fmt.Errorf("couldn't add a route of API plugin %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// initSnapshots initializes the snapshot manager and the Snapshot API service
+// Assumes n.DB, n.ConsensusAPI, n.ValidatorAPI and n.chainManager already
+// initialized
+func (n *Node) initSnapshots() error {
+	n.Log.Info("initializing snapshots")
+	manager, err := snapshot.NewManager(n.Log, n.Config.SnapshotDir, n.DB, n.ConsensusAPI, n.ValidatorAPI.Connections())
+	if err != nil {
+		return err
+	}
+	n.snapshots = manager
+	n.ConsensusAPI.SetSnapshotHandler(manager)
+	n.chainManager.AddRegistrant(manager)
+	go n.Log.RecoverAndPanic(manager.Dispatch)
+
+	if n.Config.SnapshotAPIEnabled {
+		n.Log.Info("initializing Snapshot API")
+		service := snapshot.NewService(n.Log, manager, n.chainManager)
+		n.APIServer.AddRoute(service, &sync.RWMutex{}, "snapshot", "", n.HTTPLog)
+	}
+	return nil
+}
+
+// downloadSnapshots downloads the configured snapshots, and the snapshots whose
+// download was interrupted, and calls [onFinished] once every download
+// finished. A configured snapshot isn't downloaded into a chain that already
+// has a database.
+// Assumes n.snapshots and the chains' aliases already initialized
+func (n *Node) downloadSnapshots(onFinished func()) error {
+	downloads, err := n.snapshots.Interrupted()
+	if err != nil {
+		return err
+	}
+	for chain, snapshotID := range n.Config.SnapshotSync {
+		chainID, err := n.chainManager.Lookup(chain)
+		if err != nil {
+			if chainID, err = ids.FromString(chain); err != nil {
+				return fmt.Errorf("unknown chain %q in snapshot-sync", chain)
+			}
+		}
+		downloads[chainID.Key()] = snapshotID
+	}
+
+	wg := sync.WaitGroup{}
+	numDownloads := 0
+	for chainKey, snapshotID := range downloads {
+		chainID := ids.NewID(chainKey)
+		wg.Add(1)
+		switch err := n.snapshots.Download(chainID, snapshotID, wg.Done); err {
+		case nil:
+			numDownloads++
+		case snapshot.ErrHasDatabase:
+			n.Log.Info("not downloading snapshot %s since chain %s already has a database", snapshotID, chainID)
+			wg.Done()
+		default:
+			return fmt.Errorf("couldn't download snapshot %s of chain %s: %w", snapshotID, chainID, err)
+		}
+	}
+	if numDownloads == 0 {
+		onFinished()
+		return nil
+	}
+	go n.Log.RecoverAndPanic(func() {
+		wg.Wait()
+		onFinished()
+	})
+	return nil
+}
+
 // initIPCAPI initializes the IPC API service
 // Assumes n.log and n.chainManager already initialized
 func (n *Node) initIPCAPI() {
 	if n.Config.IPCEnabled {
 		n.Log.Info("initializing IPC API")
 		service := ipcs.NewService(n.Log, n.chainManager, n.DecisionDispatcher, &n.APIServer)
-		n.APIServer.AddRoute(service, &sync.RWMutex{}, "ipcs", "", n.HTTPLog)
+		n.APIServer.AddPrivilegedRoute(service, &sync.RWMutex{}, "ipcs", "", n.HTTPLog)
 	}
 }
 
+// Returns the genesis data of the network
+func (n *Node) genesisBytes() []byte {
+	if n.Config.GenesisBytes != nil {
+		return n.Config.GenesisBytes
+	}
+	return genesis.Genesis(n.Config.NetworkID)
+}
+
 // Give chains and VMs aliases as specified by the genesis information
 func (n *Node) initAliases() {
 	n.Log.Info("initializing aliases")
-	defaultAliases, chainAliases, vmAliases := genesis.Aliases(n.Config.NetworkID)
+	defaultAliases, chainAliases, vmAliases := genesis.GenesisAliases(n.genesisBytes())
 	for chainIDKey, aliases := range chainAliases {
 		chainID := ids.NewID(chainIDKey)
 		for _, alias := range aliases {
@@ -484,6 +907,24 @@ func (n *Node) initAliases() {
 	}
 }
 
+// Set the levels of the named loggers. Names that are chain aliases are
+// replaced by the chain's ID.
+func (n *Node) initLogLevels() {
+	n.Log.Info("initializing log levels")
+	loggerName := func(name string) string {
+		if chainID, err := n.chainManager.Lookup(name); err == nil {
+			return chainID.String()
+		}
+		return name
+	}
+	for name, level := range n.Config.LogLevels {
+		n.LogFactory.SetLogLevel(loggerName(name), level)
+	}
+	for name, level := range n.Config.LogDisplayLevels {
+		n.LogFactory.SetDisplayLevel(loggerName(name), level)
+	}
+}
+
 // Initialize this node
 func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logging.Factory) error {
 	n.Log = logger
@@ -496,44 +937,121 @@ func (n *Node) Initialize(Config *Config, logger logging.Logger, logFactory logg
 	}
 	n.HTTPLog = httpLog
 
+	networkLog, err := logFactory.MakeSubdir("network")
+	if err != nil {
+		return fmt.Errorf("problem initializing network logger: %w", err)
+	}
+	n.NetworkLog = networkLog
+
 	n.initDatabase() // Set up the node's database
 
+	n.initSharedMemory() // Initialize shared memory
+
 	if err = n.initNodeID(); err != nil { // Derive this node's ID
 		return fmt.Errorf("problem initializing staker ID: %w", err)
 	}
 
 	// Start HTTP APIs
-	n.initAPIServer()   // Start the API Server
+	if err = n.initAPIServer(); err != nil { // Start the API Server
+		return fmt.Errorf("problem initializing API server: %w", err)
+	}
 	n.initKeystoreAPI() // Start the Keystore API
 	n.initMetricsAPI()  // Start the Metrics API
+	n.initEncodingAPI() // Start the Encoding API
 
 	// Start node-to-node consensus server
 	if err = n.initNetlib(); err != nil { // Set up all networking
 		return fmt.Errorf("problem initializing networking: %w", err)
 	}
-	n.initValidatorNet()    // Set up the validator handshake + authentication
+	if err = n.initValidatorNet(); err != nil { // Set up the validator handshake + authentication
+		return fmt.Errorf("problem initializing validator network: %w", err)
+	}
+
+	n.initPublicIP()        // Keep track of the IP this node is reachable at
 	n.initVMManager()       // Set up the vm manager
 	n.initEventDispatcher() // Set up the event dipatcher
 	n.initChainManager()    // Set up the chain manager
 	n.initConsensusNet()    // Set up the main consensus network
 
+	if err = n.initChainRoutes(); err != nil { // Serve chains under custom routes
+		return fmt.Errorf("problem initializing chain routes: %w", err)
+	}
+
+	if err = n.initVersionAdvisor(); err != nil { // Check the node's version against the network
+		return fmt.Errorf("problem initializing version advisor: %w", err)
+	}
+
 	// TODO: Remove once API is fully featured for throughput tests
 	if n.Config.ThroughputServerEnabled {
 		n.initClients() // Set up the client servers
 	}
 
-	n.initAdminAPI() // Start the Admin API
-	n.initIPCAPI()   // Start the IPC API
-	n.initAliases()  // Set up aliases
-	n.initChains()   // Start the Platform chain
+	n.initAdminAPI()  // Start the Admin API
+	n.initInfoAPI()   // Start the Info API
+	n.initHealthAPI() // Start the Health API
+	n.initEventsAPI() // Start the Events API
+	n.initIPCAPI()    // Start the IPC API
+	n.initIndexer()   // Start the indexer
+
+	if err = n.initPluginAPIs(); err != nil { // Start the API plugins
+		return fmt.Errorf("problem initializing API plugins: %w", err)
+	}
+
+	if err = n.initSnapshots(); err != nil { // Start serving snapshots
+		return fmt.Errorf("problem initializing snapshots: %w", err)
+	}
+
+	n.initAliases()   // Set up aliases
+	n.initLogLevels() // Set the levels of the named loggers
+
+	// Start the Platform chain once the chains' snapshots are downloaded
+	if err = n.downloadSnapshots(n.initChains); err != nil {
+		return fmt.Errorf("problem downloading snapshots: %w", err)
+	}
 
 	return nil
 }
 
-// Shutdown this node
+// Shutdown this node. API requests and messages that were already received are
+// handled, and then every chain's engine and VM are shut down, which flushes
+// their databases. The node's database should only be closed after Shutdown
+// returns.
 func (n *Node) Shutdown() {
 	n.Log.Info("shutting down the node")
+
+	ctx := context.Background()
+	if n.Config.ShutdownGracePeriod > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Config.ShutdownGracePeriod)
+		defer cancel()
+	}
+
+	if err := n.APIServer.Shutdown(ctx); err != nil {
+		n.Log.Warn("API server didn't shut down cleanly: %s", err)
+	}
 	n.ValidatorAPI.Shutdown()
 	n.ConsensusAPI.Shutdown()
-	n.chainManager.Shutdown()
+	n.snapshots.Stop()
+	n.versionAdvisor.stop()
+	if n.ipUpdater != nil {
+		n.ipUpdater.Stop()
+	}
+	if n.health != nil {
+		n.health.Stop()
+	}
+	for _, plugin := range n.plugins {
+		plugin.Shutdown()
+	}
+
+	chainsDone := make(chan struct{})
+	go func() {
+		n.chainManager.Shutdown()
+		close(chainsDone)
+	}()
+	select {
+	case <-chainsDone:
+		n.Log.Info("all chains shut down")
+	case <-ctx.Done():
+		n.Log.Error("chains didn't shut down within the grace period of %s", n.Config.ShutdownGracePeriod)
+	}
 }
@@ -0,0 +1,130 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/networking"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/utils/version"
+)
+
+const (
+	// versionCheckInterval is the time between two checks of this node's
+	// version against the network
+	versionCheckInterval = 10 * time.Minute
+
+	// manifestFetchTimeout is the maximum time to fetch the release manifest
+	manifestFetchTimeout = 10 * time.Second
+)
+
+// versionAdvisor checks that this node's version is still compatible with the
+// versions run by the connected validators and with the release manifest
+type versionAdvisor struct {
+	log        logging.Logger
+	myVersion  version.Version
+	vdrs       validators.Set
+	peers      *networking.Handshake
+	manifest   string // Path or URL of the release manifest. May be empty.
+	releaseKey crypto.PublicKey
+
+	lock           sync.Mutex
+	latestManifest *version.Manifest
+
+	checker *timer.Repeater
+}
+
+func newVersionAdvisor(
+	log logging.Logger,
+	myVersion version.Version,
+	vdrs validators.Set,
+	peers *networking.Handshake,
+	manifest string,
+	releaseKey crypto.PublicKey,
+) *versionAdvisor {
+	a := &versionAdvisor{
+		log:        log,
+		myVersion:  myVersion,
+		vdrs:       vdrs,
+		peers:      peers,
+		manifest:   manifest,
+		releaseKey: releaseKey,
+	}
+	// The manifest is loaded right away, while the peers are still connecting
+	go log.RecoverAndPanic(a.check)
+
+	a.checker = timer.NewRepeater(a.check, versionCheckInterval)
+	go log.RecoverAndPanic(a.checker.Dispatch)
+	return a
+}
+
+// Advisory returns whether this node should be upgraded
+func (a *versionAdvisor) Advisory() version.Advisory {
+	a.lock.Lock()
+	manifest := a.latestManifest
+	a.lock.Unlock()
+
+	peerStake := map[string]uint64{}
+	peerVersions := a.peers.PeerVersions()
+	for _, vdr := range a.vdrs.List() {
+		if peerVersion, ok := peerVersions[vdr.ID().Key()]; ok {
+			peerStake[peerVersion] += vdr.Weight()
+		}
+	}
+	return version.Advise(a.myVersion, peerStake, manifest)
+}
+
+// check refreshes the release manifest and logs the advisory's warnings
+func (a *versionAdvisor) check() {
+	if a.manifest != "" {
+		manifest, err := a.fetchManifest()
+		if err != nil {
+			a.log.Warn("couldn't load the release manifest from %s: %s", a.manifest, err)
+		} else {
+			a.lock.Lock()
+			a.latestManifest = manifest
+			a.lock.Unlock()
+		}
+	}
+
+	for _, warning := range a.Advisory().Warnings {
+		a.log.Warn("version advisory: %s", warning)
+	}
+}
+
+func (a *versionAdvisor) fetchManifest() (*version.Manifest, error) {
+	var (
+		signedBytes []byte
+		err         error
+	)
+	if strings.HasPrefix(a.manifest, "http://") || strings.HasPrefix(a.manifest, "https://") {
+		client := http.Client{Timeout: manifestFetchTimeout}
+		resp, err := client.Get(a.manifest)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		signedBytes, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+	} else if signedBytes, err = ioutil.ReadFile(a.manifest); err != nil {
+		return nil, err
+	}
+	return version.ParseManifest(signedBytes, a.releaseKey)
+}
+
+func (a *versionAdvisor) stop() { a.checker.Stop() }
@@ -11,10 +11,12 @@ GECKO_PKG=github.com/ava-labs/gecko
 GECKO_PATH="$GOPATH/src/$GECKO_PKG"
 if [[ -d "$GECKO_PATH/.git" ]]; then
     cd "$GECKO_PATH"
-    go get -t -v "./..."
+    go get ${BUILD_TAGS:+-tags "$BUILD_TAGS"} -t -v "./..."
     cd -
 else
-    go get -t -v "$GECKO_PKG/..."
+    go get ${BUILD_TAGS:+-tags "$BUILD_TAGS"} -t -v "$GECKO_PKG/..."
 fi
-go build -o "$PREFIX/ava" "$GECKO_PATH/main/"*.go
+# BUILD_TAGS can enable optional features, such as the rocksdb database
+go build ${BUILD_TAGS:+-tags "$BUILD_TAGS"} -o "$PREFIX/ava" "$GECKO_PATH/main/"
 go build -o "$PREFIX/xputtest" "$GECKO_PATH/xputtest/"*.go
+go build ${BUILD_TAGS:+-tags "$BUILD_TAGS"} -o "$PREFIX/gecko-db" "$GECKO_PATH/geckodb/"
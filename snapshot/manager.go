@@ -0,0 +1,533 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	manifestFile = "manifest"
+	tmpSuffix    = ".tmp"
+
+	// requestTimeout is the time a peer has to respond to a chunk request
+	requestTimeout = 15 * time.Second
+
+	// maxChunkSize is the largest chunk a snapshot is split into
+	maxChunkSize = 1 << 24
+
+	// maxOutstandingChunks is the maximum number of chunk requests that a
+	// download keeps outstanding
+	maxOutstandingChunks = 8
+
+	// advertisementFrequency is how often the connected peers are asked for
+	// their snapshot of a chain, until a peer advertises the snapshot being
+	// downloaded
+	advertisementFrequency = 5 * time.Second
+
+	// tickFrequency is how often outstanding requests are checked for
+	// timeouts
+	tickFrequency = time.Second
+)
+
+var (
+	// ErrHasDatabase is returned when a snapshot is downloaded into the
+	// database of a chain that already has one
+	ErrHasDatabase = errors.New("chain already has a database")
+
+	downloadsPrefix = []byte("snapshot downloads")
+
+	errNotRunning = errors.New("chain isn't running on this node")
+	errRunning    = errors.New("chain is already running on this node")
+	errCreating   = errors.New("a snapshot of the chain is already being created")
+)
+
+// Sender sends snapshot messages to peers
+type Sender interface {
+	GetSnapshot(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
+	Snapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32, manifest []byte)
+	GetSnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32)
+	SnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunk []byte)
+}
+
+// Peers returns the IDs of the connected peers
+type Peers interface {
+	IDs() ids.ShortSet
+}
+
+// Manager creates snapshots of the databases of the chains this node runs,
+// serves them to peers, and downloads snapshots from peers.
+//
+// A created snapshot is written to the snapshot directory, under the chain's
+// ID, and replaces the chain's previous snapshot. It's served until it's
+// replaced.
+//
+// A snapshot is downloaded into the database of a chain that isn't running
+// yet. Downloads are recorded in the database until they finish, so an
+// interrupted download is resumed after a restart.
+type Manager struct {
+	log    logging.Logger
+	dir    string
+	db     database.Database // Each chain's database is under the chain's ID
+	sender Sender
+	peers  Peers
+	clock  timer.Clock
+
+	lock      sync.Mutex
+	requestID uint32
+	chains    map[[32]byte]*snow.Context // chain ID --> context of the running chain
+	creating  ids.Set                    // chains whose snapshot is being created
+	served    map[[32]byte]*Manifest     // chain ID --> latest snapshot of the chain
+	downloads map[[32]byte]*download     // chain ID --> snapshot being downloaded
+
+	// chain ID --> ID of the snapshot being downloaded into the chain's
+	// database
+	downloadsDB database.Database
+
+	ticker *timer.Repeater
+}
+
+type download struct {
+	snapshotID ids.ID
+	db         database.Database
+	onFinished func()
+
+	manifest *Manifest    // nil until a peer advertises the snapshot
+	sources  ids.ShortSet // Peers that advertised the snapshot
+	toFetch  []uint32     // Indices of the chunks that aren't requested yet
+	fetched  map[uint32]bool
+	requests map[uint32]chunkRequest // request ID --> outstanding request
+
+	// The last time the connected peers were asked for their snapshot
+	lastAdvertisementRequest time.Time
+}
+
+type chunkRequest struct {
+	validatorID ids.ShortID
+	index       uint32
+	deadline    time.Time
+}
+
+// NewManager returns a manager that keeps its snapshots in [dir] and the
+// chains' databases in [db]. The snapshots previously created in [dir] are
+// served.
+func NewManager(log logging.Logger, dir string, db database.Database, sender Sender, peers Peers) (*Manager, error) {
+	m := &Manager{
+		log:         log,
+		dir:         dir,
+		db:          db,
+		sender:      sender,
+		peers:       peers,
+		chains:      make(map[[32]byte]*snow.Context),
+		served:      make(map[[32]byte]*Manifest),
+		downloads:   make(map[[32]byte]*download),
+		downloadsDB: prefixdb.New(downloadsPrefix, db),
+	}
+	m.ticker = timer.NewRepeater(m.tick, tickFrequency)
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		chainID, err := ids.FromString(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue // Not a snapshot, such as an interrupted creation
+		}
+		manifestBytes, err := ioutil.ReadFile(filepath.Join(dir, entry.Name(), manifestFile))
+		if err != nil {
+			log.Warn("couldn't read the snapshot of chain %s due to %s", chainID, err)
+			continue
+		}
+		manifest, err := ParseManifest(manifestBytes)
+		if err != nil {
+			log.Warn("couldn't parse the snapshot of chain %s due to %s", chainID, err)
+			continue
+		}
+		m.served[chainID.Key()] = manifest
+		log.Info("serving snapshot %s of chain %s", manifest.ID(), chainID)
+	}
+	return m, nil
+}
+
+// Dispatch handles the timeouts of requests until Stop is called
+func (m *Manager) Dispatch() { m.ticker.Dispatch() }
+
+// Stop handling timeouts
+func (m *Manager) Stop() { m.ticker.Stop() }
+
+// RegisterChain implements the chains.Registrant interface
+func (m *Manager) RegisterChain(ctx *snow.Context, _ interface{}) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.chains[ctx.ChainID.Key()] = ctx
+}
+
+// DeregisterChain implements the chains.Deregistrant interface
+func (m *Manager) DeregisterChain(ctx *snow.Context) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.chains, ctx.ChainID.Key())
+}
+
+// Create a snapshot of the database of the running chain [chainID], and
+// return the snapshot's ID. The chain doesn't process messages while its
+// database is read, so the snapshot is consistent. The chain should be
+// bootstrapped, so the snapshot is useful to peers.
+func (m *Manager) Create(chainID ids.ID) (ids.ID, error) {
+	m.lock.Lock()
+	ctx, ok := m.chains[chainID.Key()]
+	if !ok {
+		m.lock.Unlock()
+		return ids.ID{}, errNotRunning
+	}
+	if m.creating.Contains(chainID) {
+		m.lock.Unlock()
+		return ids.ID{}, errCreating
+	}
+	m.creating.Add(chainID)
+	m.lock.Unlock()
+
+	defer func() {
+		m.lock.Lock()
+		m.creating.Remove(chainID)
+		m.lock.Unlock()
+	}()
+
+	// The snapshot is written next to the current one, which is replaced once
+	// the new snapshot is complete
+	chainDir := filepath.Join(m.dir, chainID.String())
+	tmpDir := chainDir + tmpSuffix
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return ids.ID{}, err
+	}
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return ids.ID{}, err
+	}
+
+	manifest := &Manifest{ChainID: chainID}
+	ctx.Lock.Lock()
+	_, err := database.WriteChunks(prefixdb.New(chainID.Bytes(), m.db), maxChunkSize, func(chunk []byte) error {
+		if len(manifest.Chunks) >= maxChunks {
+			return errTooManyChunks
+		}
+		chunkPath := filepath.Join(tmpDir, strconv.Itoa(len(manifest.Chunks)))
+		if err := ioutil.WriteFile(chunkPath, chunk, 0600); err != nil {
+			return err
+		}
+		manifest.Chunks = append(manifest.Chunks, ids.NewID(hashing.ComputeHash256Array(chunk)))
+		return nil
+	})
+	ctx.Lock.Unlock()
+	if err == nil && len(manifest.Chunks) == 0 {
+		err = errNoChunks
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(tmpDir, manifestFile), manifest.Bytes(), 0600)
+	}
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return ids.ID{}, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// Chunks of the previous snapshot aren't served while it's replaced
+	delete(m.served, chainID.Key())
+	if err := os.RemoveAll(chainDir); err != nil {
+		return ids.ID{}, err
+	}
+	if err := os.Rename(tmpDir, chainDir); err != nil {
+		return ids.ID{}, err
+	}
+	m.served[chainID.Key()] = manifest
+
+	snapshotID := manifest.ID()
+	m.log.Info("created snapshot %s of chain %s with %d chunks", snapshotID, chainID, len(manifest.Chunks))
+	return snapshotID, nil
+}
+
+// Latest returns the latest snapshot of [chainID] created by this node
+func (m *Manager) Latest(chainID ids.ID) (*Manifest, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	manifest, ok := m.served[chainID.Key()]
+	return manifest, ok
+}
+
+// Download the snapshot [snapshotID] of [chainID] into the chain's database.
+// The chain must not be running, and its database must be empty, unless the
+// download is resuming an interrupted download of the same snapshot. If the
+// database isn't empty, ErrHasDatabase is returned.
+// [onFinished] is called once every chunk is stored.
+func (m *Manager) Download(chainID, snapshotID ids.ID, onFinished func()) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	chainKey := chainID.Key()
+	if _, ok := m.chains[chainKey]; ok {
+		return errRunning
+	}
+	if _, ok := m.downloads[chainKey]; ok {
+		return fmt.Errorf("a snapshot of chain %s is already being downloaded", chainID)
+	}
+
+	chainDB := prefixdb.New(chainID.Bytes(), m.db)
+	downloadingBytes, err := m.downloadsDB.Get(chainID.Bytes())
+	switch err {
+	case nil:
+		downloading, err := ids.ToID(downloadingBytes)
+		if err != nil {
+			return err
+		}
+		if !downloading.Equals(snapshotID) {
+			return fmt.Errorf("the download of snapshot %s of chain %s was interrupted and must be finished first", downloading, chainID)
+		}
+		m.log.Info("resuming the download of snapshot %s of chain %s", snapshotID, chainID)
+	case database.ErrNotFound:
+		it := chainDB.NewIterator()
+		hasData := it.Next()
+		it.Release()
+		if hasData {
+			return ErrHasDatabase
+		}
+		if err := m.downloadsDB.Put(chainID.Bytes(), snapshotID.Bytes()); err != nil {
+			return err
+		}
+		m.log.Info("downloading snapshot %s of chain %s", snapshotID, chainID)
+	default:
+		return err
+	}
+
+	m.downloads[chainKey] = &download{
+		snapshotID: snapshotID,
+		db:         chainDB,
+		onFinished: onFinished,
+		fetched:    make(map[uint32]bool),
+		requests:   make(map[uint32]chunkRequest),
+	}
+	return nil
+}
+
+// Interrupted returns the snapshots, by chain, whose download was interrupted
+func (m *Manager) Interrupted() (map[[32]byte]ids.ID, error) {
+	interrupted := make(map[[32]byte]ids.ID)
+
+	it := m.downloadsDB.NewIterator()
+	defer it.Release()
+	for it.Next() {
+		chainID, err := ids.ToID(it.Key())
+		if err != nil {
+			return nil, err
+		}
+		snapshotID, err := ids.ToID(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		interrupted[chainID.Key()] = snapshotID
+	}
+	return interrupted, it.Error()
+}
+
+// GetSnapshot responds with the latest snapshot of [chainID], or with an empty
+// manifest if this node has no snapshot of the chain
+func (m *Manager) GetSnapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	m.lock.Lock()
+	manifest, ok := m.served[chainID.Key()]
+	m.lock.Unlock()
+
+	manifestBytes := []byte(nil)
+	if ok {
+		manifestBytes = manifest.Bytes()
+	}
+	m.sender.Snapshot(validatorID, chainID, requestID, manifestBytes)
+}
+
+// Snapshot handles the advertisement of a snapshot by a peer
+func (m *Manager) Snapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32, manifestBytes []byte) {
+	if len(manifestBytes) == 0 {
+		return // The peer has no snapshot of the chain
+	}
+	manifest, err := ParseManifest(manifestBytes)
+	if err != nil {
+		m.log.Debug("couldn't parse the snapshot of chain %s advertised by %s due to %s", chainID, validatorID, err)
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	d, ok := m.downloads[chainID.Key()]
+	if !ok || !manifest.ChainID.Equals(chainID) || !manifest.ID().Equals(d.snapshotID) {
+		return // The advertised snapshot isn't being downloaded
+	}
+	if d.manifest == nil {
+		d.manifest = manifest
+		for i := range manifest.Chunks {
+			if index := uint32(i); !d.fetched[index] {
+				d.toFetch = append(d.toFetch, index)
+			}
+		}
+	}
+	d.sources.Add(validatorID)
+	m.sendRequests(chainID, d)
+}
+
+// GetSnapshotChunk responds with a chunk of the latest snapshot of [chainID]
+func (m *Manager) GetSnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32) {
+	m.lock.Lock()
+	manifest, ok := m.served[chainID.Key()]
+	m.lock.Unlock()
+
+	if !ok || !manifest.ID().Equals(snapshotID) || int(index) >= len(manifest.Chunks) {
+		m.log.Debug("dropping request from %s for chunk %d of snapshot %s, which isn't served", validatorID, index, snapshotID)
+		return
+	}
+	chunk, err := ioutil.ReadFile(filepath.Join(m.dir, chainID.String(), strconv.Itoa(int(index))))
+	if err != nil {
+		m.log.Warn("couldn't read chunk %d of snapshot %s due to %s", index, snapshotID, err)
+		return
+	}
+	m.sender.SnapshotChunk(validatorID, chainID, requestID, chunk)
+}
+
+// SnapshotChunk handles a chunk sent by a peer
+func (m *Manager) SnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunk []byte) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	chainKey := chainID.Key()
+	d, ok := m.downloads[chainKey]
+	if !ok {
+		return
+	}
+	request, ok := d.requests[requestID]
+	if !ok || !request.validatorID.Equals(validatorID) {
+		m.log.Debug("dropping unexpected chunk from %s with requestID %d", validatorID, requestID)
+		return
+	}
+	delete(d.requests, requestID)
+
+	if err := d.manifest.Verify(request.index, chunk); err != nil {
+		// The peer isn't asked for chunks of this snapshot again
+		m.log.Warn("chunk %d of snapshot %s sent by %s is invalid", request.index, d.snapshotID, validatorID)
+		d.sources.Remove(validatorID)
+		d.toFetch = append(d.toFetch, request.index)
+		m.sendRequests(chainID, d)
+		return
+	}
+	if !d.fetched[request.index] {
+		if _, err := database.RestoreChunk(d.db, chunk); err != nil {
+			m.log.Error("couldn't store chunk %d of snapshot %s due to %s", request.index, d.snapshotID, err)
+			d.toFetch = append(d.toFetch, request.index)
+			m.sendRequests(chainID, d)
+			return
+		}
+		d.fetched[request.index] = true
+	}
+
+	if len(d.fetched) < len(d.manifest.Chunks) {
+		m.sendRequests(chainID, d)
+		return
+	}
+
+	if err := m.downloadsDB.Delete(chainID.Bytes()); err != nil {
+		m.log.Error("couldn't record the end of the download of snapshot %s due to %s", d.snapshotID, err)
+		return
+	}
+	delete(m.downloads, chainKey)
+	m.log.Info("finished downloading snapshot %s of chain %s", d.snapshotID, chainID)
+	if d.onFinished != nil {
+		go m.log.RecoverAndPanic(d.onFinished)
+	}
+}
+
+// sendRequests requests chunks of the snapshot from the peers that advertised
+// it, spreading the requests over the peers
+func (m *Manager) sendRequests(chainID ids.ID, d *download) {
+	if d.sources.Len() == 0 {
+		return
+	}
+
+	numRequests := make(map[[20]byte]int)
+	for _, request := range d.requests {
+		numRequests[request.validatorID.Key()]++
+	}
+
+	for len(d.requests) < maxOutstandingChunks && len(d.toFetch) > 0 {
+		index := d.toFetch[0]
+		d.toFetch = d.toFetch[1:]
+		if d.fetched[index] {
+			continue
+		}
+
+		validatorID := ids.ShortID{}
+		minRequests := -1
+		for _, sourceID := range d.sources.List() {
+			if n := numRequests[sourceID.Key()]; minRequests == -1 || n < minRequests {
+				validatorID, minRequests = sourceID, n
+			}
+		}
+		numRequests[validatorID.Key()]++
+
+		m.requestID++
+		d.requests[m.requestID] = chunkRequest{
+			validatorID: validatorID,
+			index:       index,
+			deadline:    m.clock.Time().Add(requestTimeout),
+		}
+		m.sender.GetSnapshotChunk(validatorID, chainID, m.requestID, d.snapshotID, index)
+	}
+}
+
+// tick re-requests the chunks whose requests timed out, and asks the
+// connected peers for their snapshots while no peer advertised the snapshot
+// being downloaded
+func (m *Manager) tick() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := m.clock.Time()
+	for chainKey, d := range m.downloads {
+		chainID := ids.NewID(chainKey)
+
+		for requestID, request := range d.requests {
+			if now.Before(request.deadline) {
+				continue
+			}
+			m.log.Debug("request for chunk %d of snapshot %s timed out", request.index, d.snapshotID)
+			delete(d.requests, requestID)
+			d.toFetch = append(d.toFetch, request.index)
+		}
+
+		if d.sources.Len() == 0 && now.Sub(d.lastAdvertisementRequest) >= advertisementFrequency {
+			d.lastAdvertisementRequest = now
+			m.requestID++
+			m.sender.GetSnapshot(m.peers.IDs(), chainID, m.requestID)
+		}
+		m.sendRequests(chainID, d)
+	}
+}
@@ -0,0 +1,198 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// testNetwork queues the messages sent between managers, so a manager never
+// handles a message while it holds its lock
+type testNetwork struct {
+	managers map[[20]byte]*Manager
+	queue    []func()
+}
+
+type testSender struct {
+	net *testNetwork
+	id  ids.ShortID
+}
+
+func (s *testSender) GetSnapshot(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	for _, validatorID := range validatorIDs.List() {
+		to := s.net.managers[validatorID.Key()]
+		s.net.queue = append(s.net.queue, func() { to.GetSnapshot(s.id, chainID, requestID) })
+	}
+}
+
+func (s *testSender) Snapshot(validatorID ids.ShortID, chainID ids.ID, requestID uint32, manifest []byte) {
+	to := s.net.managers[validatorID.Key()]
+	s.net.queue = append(s.net.queue, func() { to.Snapshot(s.id, chainID, requestID, manifest) })
+}
+
+func (s *testSender) GetSnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, snapshotID ids.ID, index uint32) {
+	to := s.net.managers[validatorID.Key()]
+	s.net.queue = append(s.net.queue, func() { to.GetSnapshotChunk(s.id, chainID, requestID, snapshotID, index) })
+}
+
+func (s *testSender) SnapshotChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunk []byte) {
+	to := s.net.managers[validatorID.Key()]
+	s.net.queue = append(s.net.queue, func() { to.SnapshotChunk(s.id, chainID, requestID, chunk) })
+}
+
+func (net *testNetwork) IDs() ids.ShortSet {
+	peers := ids.ShortSet{}
+	for key := range net.managers {
+		peers.Add(ids.NewShortID(key))
+	}
+	return peers
+}
+
+func (net *testNetwork) deliver() {
+	for len(net.queue) > 0 {
+		msg := net.queue[0]
+		net.queue = net.queue[1:]
+		msg()
+	}
+}
+
+func (net *testNetwork) newManager(t *testing.T, id ids.ShortID, db database.Database) *Manager {
+	dir, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(logging.NoLog{}, dir, db, &testSender{net: net, id: id}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.managers[id.Key()] = m
+	return m
+}
+
+// setupSnapshot creates a snapshot, of more than one chunk, on the server
+func setupSnapshot(t *testing.T, server *Manager, serverDB database.Database) (*snow.Context, ids.ID) {
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = ids.Empty.Prefix(7)
+	chainDB := prefixdb.New(ctx.ChainID.Bytes(), serverDB)
+	for i := byte(0); i < 3; i++ {
+		if err := chainDB.Put([]byte{i}, bytes.Repeat([]byte{i}, database.ChunkSize/2+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := server.Create(ctx.ChainID); err != errNotRunning {
+		t.Fatalf("Should have refused to snapshot a chain that isn't running")
+	}
+	server.RegisterChain(ctx, nil)
+
+	snapshotID, err := server.Create(ctx.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest, ok := server.Latest(ctx.ChainID); !ok || len(manifest.Chunks) != 2 || !manifest.ID().Equals(snapshotID) {
+		t.Fatalf("Wrong snapshot served")
+	}
+	return ctx, snapshotID
+}
+
+func TestManagerDownload(t *testing.T) {
+	net := &testNetwork{managers: make(map[[20]byte]*Manager)}
+	serverDB, clientDB := memdb.New(), memdb.New()
+	server := net.newManager(t, ids.NewShortID([20]byte{1}), serverDB)
+	client := net.newManager(t, ids.NewShortID([20]byte{2}), clientDB)
+	defer os.RemoveAll(server.dir)
+	defer os.RemoveAll(client.dir)
+
+	ctx, snapshotID := setupSnapshot(t, server, serverDB)
+
+	// A restarted server serves the snapshot it created
+	reloaded, err := NewManager(logging.NoLog{}, server.dir, serverDB, server.sender, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest, ok := reloaded.Latest(ctx.ChainID); !ok || !manifest.ID().Equals(snapshotID) {
+		t.Fatalf("Snapshot should have been loaded")
+	}
+
+	finished := make(chan struct{})
+	if err := client.Download(ctx.ChainID, snapshotID, func() { close(finished) }); err != nil {
+		t.Fatal(err)
+	}
+	if interrupted, err := client.Interrupted(); err != nil {
+		t.Fatal(err)
+	} else if len(interrupted) != 1 || !interrupted[ctx.ChainID.Key()].Equals(snapshotID) {
+		t.Fatalf("Download should have been recorded")
+	}
+
+	client.tick()
+	net.deliver()
+	<-finished
+
+	serverChainDB := prefixdb.New(ctx.ChainID.Bytes(), serverDB)
+	clientChainDB := prefixdb.New(ctx.ChainID.Bytes(), clientDB)
+	for i := byte(0); i < 3; i++ {
+		expected, err := serverChainDB.Get([]byte{i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, err := clientChainDB.Get([]byte{i}); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(value, expected) {
+			t.Fatalf("Wrong value restored for key %d", i)
+		}
+	}
+	if interrupted, err := client.Interrupted(); err != nil {
+		t.Fatal(err)
+	} else if len(interrupted) != 0 {
+		t.Fatalf("Finished download shouldn't be recorded")
+	}
+
+	if err := client.Download(ctx.ChainID, snapshotID, nil); err != ErrHasDatabase {
+		t.Fatalf("Should have refused to download into a chain's database")
+	}
+}
+
+func TestManagerDownloadInvalidChunk(t *testing.T) {
+	net := &testNetwork{managers: make(map[[20]byte]*Manager)}
+	serverDB, clientDB := memdb.New(), memdb.New()
+	server := net.newManager(t, ids.NewShortID([20]byte{1}), serverDB)
+	client := net.newManager(t, ids.NewShortID([20]byte{2}), clientDB)
+	defer os.RemoveAll(server.dir)
+	defer os.RemoveAll(client.dir)
+
+	ctx, snapshotID := setupSnapshot(t, server, serverDB)
+
+	// The server serves a corrupted chunk
+	chunkPath := filepath.Join(server.dir, ctx.ChainID.String(), "1")
+	if err := ioutil.WriteFile(chunkPath, []byte{1, 2, 3}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Download(ctx.ChainID, snapshotID, func() { t.Fatalf("Download shouldn't have finished") }); err != nil {
+		t.Fatal(err)
+	}
+	client.tick()
+	net.deliver()
+
+	d := client.downloads[ctx.ChainID.Key()]
+	if d == nil {
+		t.Fatalf("Download should be in progress")
+	}
+	if d.sources.Len() != 0 {
+		t.Fatalf("Peer that sent an invalid chunk should no longer be a source")
+	}
+	if d.fetched[1] || len(d.toFetch) != 1 || d.toFetch[0] != 1 {
+		t.Fatalf("Invalid chunk should be fetched again")
+	}
+}
@@ -0,0 +1,88 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errNoChunks       = errors.New("snapshot has no chunks")
+	errTrailingBytes  = errors.New("manifest has trailing bytes")
+	errTooManyChunks  = errors.New("manifest has too many chunks")
+	errWrongChunkHash = errors.New("chunk doesn't match the manifest")
+)
+
+// maxChunks is the maximum number of chunks in a snapshot. It keeps a manifest
+// within the size of a message.
+const maxChunks = 1 << 16
+
+// Manifest describes a snapshot of a chain's database. The database's
+// key/value pairs are split, in key order, into chunks. A snapshot is
+// identified by the hash of its manifest, and the manifest lists the hash of
+// each chunk, so every chunk downloaded from a peer can be verified.
+type Manifest struct {
+	ChainID ids.ID
+	Chunks  []ids.ID // Hash of each chunk
+}
+
+// ID returns the ID of the snapshot
+func (m *Manifest) ID() ids.ID { return ids.NewID(hashing.ComputeHash256Array(m.Bytes())) }
+
+// Bytes returns the binary representation of this manifest
+func (m *Manifest) Bytes() []byte {
+	p := wrappers.Packer{Bytes: make([]byte, hashing.HashLen+wrappers.IntLen+hashing.HashLen*len(m.Chunks))}
+	p.PackFixedBytes(m.ChainID.Bytes())
+	p.PackInt(uint32(len(m.Chunks)))
+	for _, chunkID := range m.Chunks {
+		p.PackFixedBytes(chunkID.Bytes())
+	}
+	return p.Bytes
+}
+
+// Verify returns nil if [chunk] is the chunk at position [index] of the
+// snapshot
+func (m *Manifest) Verify(index uint32, chunk []byte) error {
+	if int(index) >= len(m.Chunks) {
+		return errWrongChunkHash
+	}
+	if chunkID := ids.NewID(hashing.ComputeHash256Array(chunk)); !chunkID.Equals(m.Chunks[index]) {
+		return errWrongChunkHash
+	}
+	return nil
+}
+
+// ParseManifest parses the binary representation of a manifest
+func ParseManifest(b []byte) (*Manifest, error) {
+	p := wrappers.Packer{Bytes: b}
+	chainID, _ := ids.ToID(p.UnpackFixedBytes(hashing.HashLen))
+	numChunks := p.UnpackInt()
+	switch {
+	case p.Errored():
+		return nil, p.Err
+	case numChunks == 0:
+		return nil, errNoChunks
+	case numChunks > maxChunks:
+		return nil, errTooManyChunks
+	}
+
+	manifest := &Manifest{
+		ChainID: chainID,
+		Chunks:  make([]ids.ID, numChunks),
+	}
+	for i := range manifest.Chunks {
+		manifest.Chunks[i], _ = ids.ToID(p.UnpackFixedBytes(hashing.HashLen))
+	}
+	if p.Errored() {
+		return nil, p.Err
+	}
+	if p.Offset != len(b) {
+		return nil, errTrailingBytes
+	}
+	return manifest, nil
+}
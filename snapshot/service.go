@@ -0,0 +1,83 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snapshot
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/rpc/v2"
+
+	"github.com/ava-labs/gecko/chains"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Snapshot is the API service for creating the snapshots this node serves
+type Snapshot struct {
+	log          logging.Logger
+	manager      *Manager
+	chainManager chains.Manager
+}
+
+// NewService returns a new snapshot API service
+func NewService(log logging.Logger, manager *Manager, chainManager chains.Manager) *common.HTTPHandler {
+	newServer := rpc.NewServer()
+	codec := cjson.NewCodec()
+	newServer.RegisterCodec(codec, "application/json")
+	newServer.RegisterCodec(codec, "application/json;charset=UTF-8")
+	newServer.RegisterService(&Snapshot{
+		log:          log,
+		manager:      manager,
+		chainManager: chainManager,
+	}, "snapshot")
+	return &common.HTTPHandler{Handler: newServer}
+}
+
+// SnapshotArgs are the arguments for calling CreateSnapshot and GetSnapshot
+type SnapshotArgs struct {
+	BlockchainID string `json:"blockchainID"`
+}
+
+// SnapshotReply are the results from calling CreateSnapshot and GetSnapshot
+type SnapshotReply struct {
+	SnapshotID ids.ID       `json:"snapshotID"`
+	NumChunks  cjson.Uint32 `json:"numChunks"`
+}
+
+// CreateSnapshot creates a snapshot of a chain's database, which replaces the
+// chain's previous snapshot. The chain stops processing messages while its
+// database is read.
+func (service *Snapshot) CreateSnapshot(_ *http.Request, args *SnapshotArgs, reply *SnapshotReply) error {
+	service.log.Info("Snapshot: CreateSnapshot called with %s", args.BlockchainID)
+
+	chainID, err := service.chainManager.Lookup(args.BlockchainID)
+	if err != nil {
+		return fmt.Errorf("unknown blockchainID %q: %w", args.BlockchainID, err)
+	}
+	if _, err := service.manager.Create(chainID); err != nil {
+		return fmt.Errorf("couldn't create a snapshot of chain %s: %w", chainID, err)
+	}
+	return service.GetSnapshot(nil, args, reply)
+}
+
+// GetSnapshot returns the snapshot of a chain that this node serves
+func (service *Snapshot) GetSnapshot(_ *http.Request, args *SnapshotArgs, reply *SnapshotReply) error {
+	service.log.Debug("Snapshot: GetSnapshot called with %s", args.BlockchainID)
+
+	chainID, err := service.chainManager.Lookup(args.BlockchainID)
+	if err != nil {
+		return fmt.Errorf("unknown blockchainID %q: %w", args.BlockchainID, err)
+	}
+	manifest, ok := service.manager.Latest(chainID)
+	if !ok {
+		return fmt.Errorf("there is no snapshot of chain %s", chainID)
+	}
+	reply.SnapshotID = manifest.ID()
+	reply.NumChunks = cjson.Uint32(len(manifest.Chunks))
+	return nil
+}
@@ -4,6 +4,8 @@
 package avalanche
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/ids"
@@ -36,8 +38,15 @@ type Topological struct {
 	numProcessing            prometheus.Gauge
 	numAccepted, numRejected prometheus.Counter
 
+	// Time from a vertex being added to it being accepted, and the number of
+	// preferred txs that lost their preference without being accepted
+	acceptLatency      prometheus.Histogram
+	numPreferenceFlips prometheus.Counter
+
 	// Maps vtxID -> vtx
 	nodes map[[32]byte]Vertex
+	// Maps vtxID -> when the vertex was added
+	added map[[32]byte]time.Time
 	// Tracks the conflict relations
 	cg snowstorm.Consensus
 
@@ -59,7 +68,7 @@ type kahnNode struct {
 
 // Initialize implements the Avalanche interface
 func (ta *Topological) Initialize(ctx *snow.Context, params Parameters, frontier []Vertex) {
-	ctx.Log.AssertDeferredNoError(params.Valid)
+	ctx.ConsensusLog.AssertDeferredNoError(params.Valid)
 
 	ta.ctx = ctx
 	ta.params = params
@@ -82,18 +91,38 @@ func (ta *Topological) Initialize(ctx *snow.Context, params Parameters, frontier
 			Name:      "vtx_rejected",
 			Help:      "Number of vertices rejected",
 		})
+	ta.acceptLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: params.Namespace,
+			Name:      "vtx_accept_latency",
+			Help:      "Time from a vertex being issued into consensus to it being accepted, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(.01, 2, 12), // 10ms to 20s
+		})
+	ta.numPreferenceFlips = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: params.Namespace,
+			Name:      "tx_preference_flips",
+			Help:      "Number of preferred txs that lost their preference to a conflicting tx without being accepted",
+		})
 
 	if err := ta.params.Metrics.Register(ta.numProcessing); err != nil {
-		ta.ctx.Log.Error("Failed to register vtx_processing statistics due to %s", err)
+		ta.ctx.ConsensusLog.Error("Failed to register vtx_processing statistics due to %s", err)
 	}
 	if err := ta.params.Metrics.Register(ta.numAccepted); err != nil {
-		ta.ctx.Log.Error("Failed to register vtx_accepted statistics due to %s", err)
+		ta.ctx.ConsensusLog.Error("Failed to register vtx_accepted statistics due to %s", err)
 	}
 	if err := ta.params.Metrics.Register(ta.numRejected); err != nil {
-		ta.ctx.Log.Error("Failed to register vtx_rejected statistics due to %s", err)
+		ta.ctx.ConsensusLog.Error("Failed to register vtx_rejected statistics due to %s", err)
+	}
+	if err := ta.params.Metrics.Register(ta.acceptLatency); err != nil {
+		ta.ctx.ConsensusLog.Error("Failed to register vtx_accept_latency statistics due to %s", err)
+	}
+	if err := ta.params.Metrics.Register(ta.numPreferenceFlips); err != nil {
+		ta.ctx.ConsensusLog.Error("Failed to register tx_preference_flips statistics due to %s", err)
 	}
 
 	ta.nodes = make(map[[32]byte]Vertex)
+	ta.added = make(map[[32]byte]time.Time)
 
 	ta.cg = &snowstorm.Directed{}
 	ta.cg.Initialize(ctx, params.Parameters)
@@ -113,7 +142,7 @@ func (ta *Topological) IsVirtuous(tx snowstorm.Tx) bool { return ta.cg.IsVirtuou
 
 // Add implements the Avalanche interface
 func (ta *Topological) Add(vtx Vertex) {
-	ta.ctx.Log.AssertTrue(vtx != nil, "Attempting to insert nil vertex")
+	ta.ctx.ConsensusLog.AssertTrue(vtx != nil, "Attempting to insert nil vertex")
 
 	vtxID := vtx.ID()
 	key := vtxID.Key()
@@ -133,6 +162,7 @@ func (ta *Topological) Add(vtx Vertex) {
 	}
 
 	ta.nodes[key] = vtx // Add this vertex to the set of nodes
+	ta.added[key] = time.Now()
 	ta.numProcessing.Inc()
 
 	ta.update(vtx) // Update the vertex and it's ancestry
@@ -161,15 +191,36 @@ func (ta *Topological) Preferences() ids.Set { return ta.preferred }
 
 // RecordPoll implements the Avalanche interface
 func (ta *Topological) RecordPoll(responses ids.UniqueBag) {
+	// Remember the preferred txs, to count the ones that lose their preference:
+	// O(|Live Set|)
+	preferences := ta.cg.Preferences()
+	preferredTxs := []snowstorm.Tx(nil)
+	for _, vtx := range ta.nodes {
+		for _, tx := range vtx.Txs() {
+			if preferences.Contains(tx.ID()) {
+				preferredTxs = append(preferredTxs, tx)
+			}
+		}
+	}
+
 	// Set up the topological sort: O(|Live Set|)
 	kahns, leaves := ta.calculateInDegree(responses)
 	// Collect the votes for each transaction: O(|Live Set|)
 	votes := ta.pushVotes(kahns, leaves)
 	// Update the conflict graph: O(|Transactions|)
-	ta.ctx.Log.Verbo("Updating consumer confidences based on:\n%s", &votes)
+	ta.ctx.ConsensusLog.Verbo("Updating consumer confidences based on:\n%s", &votes)
 	ta.cg.RecordPoll(votes)
 	// Update the dag: O(|Live Set|)
 	ta.updateFrontiers()
+
+	flipped := ids.Set{}
+	preferences = ta.cg.Preferences()
+	for _, tx := range preferredTxs {
+		if txID := tx.ID(); tx.Status() != choices.Accepted && !preferences.Contains(txID) {
+			flipped.Add(txID) // A tx may be in many vertices
+		}
+	}
+	ta.numPreferenceFlips.Add(float64(flipped.Len()))
 }
 
 // Quiesce implements the Avalanche interface
@@ -369,6 +420,7 @@ func (ta *Topological) update(vtx Vertex) {
 			vtx.Reject() // My parent is rejected, so I should be rejected
 			ta.numRejected.Inc()
 			delete(ta.nodes, vtxKey)
+			delete(ta.added, vtxKey)
 			ta.numProcessing.Dec()
 
 			ta.preferenceCache[vtxKey] = false
@@ -421,7 +473,11 @@ func (ta *Topological) update(vtx Vertex) {
 		ta.ctx.ConsensusDispatcher.Accept(ta.ctx.ChainID, vtxID, vtx.Bytes())
 		vtx.Accept()
 		ta.numAccepted.Inc()
+		if added, ok := ta.added[vtxKey]; ok {
+			ta.acceptLatency.Observe(time.Since(added).Seconds())
+		}
 		delete(ta.nodes, vtxKey)
+		delete(ta.added, vtxKey)
 		ta.numProcessing.Dec()
 	case rejectable:
 		// I'm rejectable, why not reject?
@@ -431,6 +487,7 @@ func (ta *Topological) update(vtx Vertex) {
 
 		ta.numRejected.Inc()
 		delete(ta.nodes, vtxKey)
+		delete(ta.added, vtxKey)
 		ta.numProcessing.Dec()
 	}
 }
@@ -4,10 +4,13 @@
 package snowman
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowball"
 )
 
@@ -27,6 +30,11 @@ type Topological struct {
 	numProcessing            prometheus.Gauge
 	numAccepted, numRejected prometheus.Counter
 
+	// Time from a block being added to it being accepted, and the number of
+	// polls that moved the preference off of the previously preferred branch
+	acceptLatency prometheus.Histogram
+	numReorgs     prometheus.Counter
+
 	head  ids.ID
 	nodes map[[32]byte]node // ParentID -> Snowball instance
 	tail  ids.ID
@@ -37,6 +45,7 @@ type node struct {
 	ts    *Topological
 	blkID ids.ID
 	blk   Block
+	added time.Time
 
 	shouldFalter bool
 	sb           snowball.Consensus
@@ -57,7 +66,7 @@ type votes struct {
 
 // Initialize implements the Snowman interface
 func (ts *Topological) Initialize(ctx *snow.Context, params snowball.Parameters, rootID ids.ID) {
-	ctx.Log.AssertDeferredNoError(params.Valid)
+	ctx.ConsensusLog.AssertDeferredNoError(params.Valid)
 
 	ts.ctx = ctx
 	ts.params = params
@@ -80,15 +89,34 @@ func (ts *Topological) Initialize(ctx *snow.Context, params snowball.Parameters,
 			Name:      "rejected",
 			Help:      "Number of blocks rejected",
 		})
+	ts.acceptLatency = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: params.Namespace,
+			Name:      "accept_latency",
+			Help:      "Time from a block being issued into consensus to it being accepted, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(.01, 2, 12), // 10ms to 20s
+		})
+	ts.numReorgs = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: params.Namespace,
+			Name:      "reorgs",
+			Help:      "Number of polls that switched the preference to a block that doesn't extend the previously preferred block",
+		})
 
 	if err := ts.params.Metrics.Register(ts.numProcessing); err != nil {
-		ts.ctx.Log.Error("Failed to register processing statistics due to %s", err)
+		ts.ctx.ConsensusLog.Error("Failed to register processing statistics due to %s", err)
 	}
 	if err := ts.params.Metrics.Register(ts.numAccepted); err != nil {
-		ts.ctx.Log.Error("Failed to register accepted statistics due to %s", err)
+		ts.ctx.ConsensusLog.Error("Failed to register accepted statistics due to %s", err)
 	}
 	if err := ts.params.Metrics.Register(ts.numRejected); err != nil {
-		ts.ctx.Log.Error("Failed to register rejected statistics due to %s", err)
+		ts.ctx.ConsensusLog.Error("Failed to register rejected statistics due to %s", err)
+	}
+	if err := ts.params.Metrics.Register(ts.acceptLatency); err != nil {
+		ts.ctx.ConsensusLog.Error("Failed to register accept_latency statistics due to %s", err)
+	}
+	if err := ts.params.Metrics.Register(ts.numReorgs); err != nil {
+		ts.ctx.ConsensusLog.Error("Failed to register reorgs statistics due to %s", err)
 	}
 
 	ts.head = rootID
@@ -124,6 +152,7 @@ func (ts *Topological) Add(blk Block) {
 			ts:    ts,
 			blkID: blkID,
 			blk:   blk,
+			added: time.Now(),
 		}
 
 		// If we are extending the tail, this is the new tail
@@ -168,6 +197,8 @@ func (ts *Topological) Preference() ids.ID { return ts.tail }
 // Runtime = 3 * |live set| + |votes|
 // Space = |live set| + |votes|
 func (ts *Topological) RecordPoll(votes ids.Bag) {
+	oldTail := ts.nodes[ts.tail.Key()]
+
 	// Runtime = |live set| + |votes| ; Space = |live set| + |votes|
 	kahnGraph, leaves := ts.calculateInDegree(votes)
 
@@ -182,6 +213,23 @@ func (ts *Topological) RecordPoll(votes ids.Bag) {
 	}
 
 	ts.tail = tn.blkID
+	if ts.reorged(oldTail) {
+		ts.numReorgs.Inc()
+	}
+}
+
+// reorged returns true if the preferred block [oldTail] is neither an ancestor
+// of the current preference nor accepted
+func (ts *Topological) reorged(oldTail node) bool {
+	if oldTail.blk == nil {
+		return false // The old preference was the last accepted block
+	}
+	for n, ok := ts.nodes[ts.tail.Key()]; ok && n.blk != nil; n, ok = ts.nodes[n.blk.Parent().ID().Key()] {
+		if n.blkID.Equals(oldTail.blkID) {
+			return false
+		}
+	}
+	return oldTail.blk.Status() != choices.Accepted
 }
 
 // Finalized implements the Snowman interface
@@ -287,7 +335,7 @@ func (ts *Topological) vote(voteStack []votes) ids.ID {
 		headNode := ts.nodes[headKey]
 		headNode.shouldFalter = true
 
-		ts.ctx.Log.Verbo("No progress was made on this vote even though we have %d nodes", len(ts.nodes))
+		ts.ctx.ConsensusLog.Verbo("No progress was made on this vote even though we have %d nodes", len(ts.nodes))
 
 		ts.nodes[headKey] = headNode
 		return ts.tail
@@ -310,7 +358,7 @@ func (ts *Topological) vote(voteStack []votes) ids.ID {
 		if parentNode.shouldFalter {
 			parentNode.sb.RecordUnsuccessfulPoll()
 			parentNode.shouldFalter = false
-			ts.ctx.Log.Verbo("Reset confidence on %s", parentNode.blkID)
+			ts.ctx.ConsensusLog.Verbo("Reset confidence on %s", parentNode.blkID)
 		}
 		parentNode.sb.RecordPoll(voteGroup.votes)
 
@@ -344,7 +392,7 @@ func (ts *Topological) vote(voteStack []votes) ids.ID {
 					// The existence check is needed in case the current node
 					// was finalized. However, in this case, we still need to
 					// check for the next id.
-					ts.ctx.Log.Verbo("Defering confidence reset on %s with %d children. NextID: %s", childID, len(parentNode.children), nextID)
+					ts.ctx.ConsensusLog.Verbo("Defering confidence reset on %s with %d children. NextID: %s", childID, len(parentNode.children), nextID)
 					childNode.shouldFalter = true
 					ts.nodes[childIDBytes] = childNode
 				}
@@ -376,7 +424,8 @@ func (ts *Topological) accept(n node) {
 
 	ts.head = pref
 	child := n.children[pref.Key()]
-	ts.ctx.Log.Verbo("Accepting block with ID %s", child.ID())
+	ts.ctx.ConsensusLog.Verbo("Accepting block with ID %s", child.ID())
+	ts.acceptLatency.Observe(time.Since(ts.nodes[pref.Key()].added).Seconds())
 
 	bytes := child.Bytes()
 	ts.ctx.DecisionDispatcher.Accept(ts.ctx.ChainID, child.ID(), bytes)
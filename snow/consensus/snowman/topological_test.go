@@ -5,6 +5,14 @@ package snowman
 
 import (
 	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowball"
 )
 
 func TestTopologicalParams(t *testing.T) { ParamsTest(t, TopologicalFactory{}) }
@@ -30,3 +38,53 @@ func TestTopologicalIssuedTest(t *testing.T) { IssuedTest(t, TopologicalFactory{
 func TestTopologicalMetricsError(t *testing.T) { MetricsErrorTest(t, TopologicalFactory{}) }
 
 func TestTopologicalConsistent(t *testing.T) { ConsistentTest(t, TopologicalFactory{}) }
+
+func TestTopologicalMetrics(t *testing.T) {
+	sm := Topological{}
+	params := snowball.Parameters{
+		Metrics: prometheus.NewRegistry(),
+		K:       1, Alpha: 1, BetaVirtuous: 3, BetaRogue: 5,
+	}
+	sm.Initialize(snow.DefaultContextTest(), params, Genesis.ID())
+
+	block0 := &Blk{
+		parent: Genesis,
+		id:     ids.Empty.Prefix(1),
+	}
+	block1 := &Blk{
+		parent: Genesis,
+		id:     ids.Empty.Prefix(2),
+	}
+	sm.Add(block0)
+	sm.Add(block1)
+
+	votes := ids.Bag{}
+	votes.Add(block1.id)
+	sm.RecordPoll(votes)
+	if pref := sm.Preference(); !pref.Equals(block1.id) {
+		t.Fatalf("Wrong preference. Expected %s, got %s", block1.id, pref)
+	}
+	metric := &dto.Metric{}
+	if err := sm.numReorgs.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if reorgs := metric.GetCounter().GetValue(); reorgs != 1 {
+		t.Fatalf("Should have counted 1 reorg but counted %f", reorgs)
+	}
+
+	for block1.Status() != choices.Accepted {
+		sm.RecordPoll(votes)
+	}
+	if err := sm.acceptLatency.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if accepted := metric.GetHistogram().GetSampleCount(); accepted != 1 {
+		t.Fatalf("Should have observed the latency of 1 accepted block but observed %d", accepted)
+	}
+	if err := sm.numReorgs.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if reorgs := metric.GetCounter().GetValue(); reorgs != 1 {
+		t.Fatalf("Accepting the preference shouldn't be counted as a reorg")
+	}
+}
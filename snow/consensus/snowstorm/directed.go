@@ -70,7 +70,7 @@ type flatNode struct {
 
 // Initialize implements the Consensus interface
 func (dg *Directed) Initialize(ctx *snow.Context, params snowball.Parameters) {
-	ctx.Log.AssertDeferredNoError(params.Valid)
+	ctx.ConsensusLog.AssertDeferredNoError(params.Valid)
 
 	dg.ctx = ctx
 	dg.params = params
@@ -101,16 +101,16 @@ func (dg *Directed) Initialize(ctx *snow.Context, params snowball.Parameters) {
 		})
 
 	if err := dg.params.Metrics.Register(dg.numProcessingVirtuous); err != nil {
-		dg.ctx.Log.Error("Failed to register tx_processing_virtuous statistics due to %s", err)
+		dg.ctx.ConsensusLog.Error("Failed to register tx_processing_virtuous statistics due to %s", err)
 	}
 	if err := dg.params.Metrics.Register(dg.numProcessingRogue); err != nil {
-		dg.ctx.Log.Error("Failed to register tx_processing_rogue statistics due to %s", err)
+		dg.ctx.ConsensusLog.Error("Failed to register tx_processing_rogue statistics due to %s", err)
 	}
 	if err := dg.params.Metrics.Register(dg.numAccepted); err != nil {
-		dg.ctx.Log.Error("Failed to register tx_accepted statistics due to %s", err)
+		dg.ctx.ConsensusLog.Error("Failed to register tx_accepted statistics due to %s", err)
 	}
 	if err := dg.params.Metrics.Register(dg.numRejected); err != nil {
-		dg.ctx.Log.Error("Failed to register tx_rejected statistics due to %s", err)
+		dg.ctx.ConsensusLog.Error("Failed to register tx_rejected statistics due to %s", err)
 	}
 
 	dg.spends = make(map[[32]byte]ids.Set)
@@ -275,7 +275,7 @@ func (dg *Directed) RecordPoll(votes ids.Bag) {
 		}
 		fn.lastVote = dg.currentVote
 
-		dg.ctx.Log.Verbo("Increasing (bias, confidence) of %s from (%d, %d) to (%d, %d)", toInc, fn.bias, fn.confidence, fn.bias+1, fn.confidence+1)
+		dg.ctx.ConsensusLog.Verbo("Increasing (bias, confidence) of %s from (%d, %d) to (%d, %d)", toInc, fn.bias, fn.confidence, fn.bias+1, fn.confidence+1)
 
 		fn.bias++
 		fn.confidence++
@@ -294,14 +294,14 @@ func (dg *Directed) RecordPoll(votes ids.Bag) {
 // Quiesce implements the Consensus interface
 func (dg *Directed) Quiesce() bool {
 	numVirtuous := dg.virtuousVoting.Len()
-	dg.ctx.Log.Verbo("Conflict graph has %d voting virtuous transactions and %d transactions", numVirtuous, len(dg.nodes))
+	dg.ctx.ConsensusLog.Verbo("Conflict graph has %d voting virtuous transactions and %d transactions", numVirtuous, len(dg.nodes))
 	return numVirtuous == 0
 }
 
 // Finalized implements the Consensus interface
 func (dg *Directed) Finalized() bool {
 	numNodes := len(dg.nodes)
-	dg.ctx.Log.Verbo("Conflict graph has %d pending transactions", numNodes)
+	dg.ctx.ConsensusLog.Verbo("Conflict graph has %d pending transactions", numNodes)
 	return numNodes == 0
 }
 
@@ -65,7 +65,7 @@ type inputNode struct {
 
 // Initialize implements the ConflictGraph interface
 func (ig *Input) Initialize(ctx *snow.Context, params snowball.Parameters) {
-	ctx.Log.AssertDeferredNoError(params.Valid)
+	ctx.ConsensusLog.AssertDeferredNoError(params.Valid)
 
 	ig.ctx = ctx
 	ig.params = params
@@ -92,13 +92,13 @@ func (ig *Input) Initialize(ctx *snow.Context, params snowball.Parameters) {
 		})
 
 	if err := ig.params.Metrics.Register(ig.numProcessing); err != nil {
-		ig.ctx.Log.Error("Failed to register tx_processing statistics due to %s", err)
+		ig.ctx.ConsensusLog.Error("Failed to register tx_processing statistics due to %s", err)
 	}
 	if err := ig.params.Metrics.Register(ig.numAccepted); err != nil {
-		ig.ctx.Log.Error("Failed to register tx_accepted statistics due to %s", err)
+		ig.ctx.ConsensusLog.Error("Failed to register tx_accepted statistics due to %s", err)
 	}
 	if err := ig.params.Metrics.Register(ig.numRejected); err != nil {
-		ig.ctx.Log.Error("Failed to register tx_rejected statistics due to %s", err)
+		ig.ctx.ConsensusLog.Error("Failed to register tx_rejected statistics due to %s", err)
 	}
 
 	ig.txs = make(map[[32]byte]txNode)
@@ -411,14 +411,14 @@ func (ig *Input) removeConflict(id ids.ID, inputIDs ...ids.ID) {
 // Quiesce implements the ConflictGraph interface
 func (ig *Input) Quiesce() bool {
 	numVirtuous := ig.virtuousVoting.Len()
-	ig.ctx.Log.Verbo("Conflict graph has %d voting virtuous transactions and %d transactions", numVirtuous, len(ig.txs))
+	ig.ctx.ConsensusLog.Verbo("Conflict graph has %d voting virtuous transactions and %d transactions", numVirtuous, len(ig.txs))
 	return numVirtuous == 0
 }
 
 // Finalized implements the ConflictGraph interface
 func (ig *Input) Finalized() bool {
 	numTxs := len(ig.txs)
-	ig.ctx.Log.Verbo("Conflict graph has %d pending transactions", numTxs)
+	ig.ctx.ConsensusLog.Verbo("Conflict graph has %d pending transactions", numTxs)
 	return numTxs == 0
 }
 
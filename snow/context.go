@@ -4,16 +4,24 @@
 package snow
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/pruning"
 	"github.com/ava-labs/gecko/snow/triggers"
 	"github.com/ava-labs/gecko/utils/logging"
 )
 
+// ErrReadOnly is returned when a transaction is issued to a read-only node
+var ErrReadOnly = errors.New("this node is read-only, so it doesn't accept issued transactions")
+
 // Callable ...
 type Callable interface {
 	Call(writer http.ResponseWriter, method, base, endpoint string, body io.Reader, headers map[string]string) error
@@ -24,6 +32,19 @@ type Keystore interface {
 	GetDatabase(username, password string) (database.Database, error)
 }
 
+// Signer signs messages with keys that are held outside of this node
+type Signer interface {
+	// Sign returns the recoverable signature of [msg] by the key of [addr].
+	// [op] is the type of the operation the signature authorizes.
+	Sign(op string, addr ids.ShortID, msg []byte) ([]byte, error)
+}
+
+// VMLookup ...
+type VMLookup interface {
+	// ChainVM returns the ID of the VM running the chain [chainID]
+	ChainVM(chainID ids.ID) (ids.ID, error)
+}
+
 // AliasLookup ...
 type AliasLookup interface {
 	Lookup(alias string) (ids.ID, error)
@@ -34,17 +55,71 @@ type AliasLookup interface {
 // [NetworkID] is the ID of the network this context exists within.
 // [ChainID] is the ID of the chain this context exists within.
 // [NodeID] is the ID of this node
+// [Log] is the log of the VM
+// [ConsensusLog] is the log of the chain's consensus engine and of the
+// messages it sends and handles, so its level can be set apart from the VM's
+// [Namespace] is the namespace of the metrics this chain registers with
+// [Metrics]
+// [Upgrades] is the schedule of the rule changes of this chain's VM
+// [StateMode] is how much historical state the VM should keep
+// [Pruner] deletes the keys of the VM's database that it no longer needs. It's
+// nil if the chain's state isn't pruned.
+// [ReadOnly] is true if the VM should reject the transactions issued to it
+// [VMLookup] returns the VMs of the chains this node runs
+//
+// The context also carries the ID of the operation, such as the handling of a
+// message from the network, that is currently being traced. It is set by the
+// chain's handler before the engine is called, so VMs can read it to tag their
+// own work.
 type Context struct {
 	NetworkID           uint32
 	ChainID             ids.ID
 	NodeID              ids.ShortID
 	Log                 logging.Logger
+	ConsensusLog        logging.Logger
 	DecisionDispatcher  *triggers.EventDispatcher
 	ConsensusDispatcher *triggers.EventDispatcher
 	Lock                sync.RWMutex
 	HTTP                Callable
 	Keystore            Keystore
+	Signer              Signer
 	BCLookup            AliasLookup
+	SharedMemory        atomic.SharedMemory
+	Namespace           string
+	Metrics             prometheus.Registerer
+	Upgrades            Upgrades
+	StateMode           StateMode
+	Pruner              *pruning.Pruner
+	ReadOnly            bool
+	VMLookup            VMLookup
+
+	traceLock sync.RWMutex
+	traceID   uint64
+}
+
+// TraceID returns the ID of the operation that is currently being traced, or
+// 0 if no operation is being traced
+func (ctx *Context) TraceID() uint64 {
+	ctx.traceLock.RLock()
+	defer ctx.traceLock.RUnlock()
+
+	return ctx.traceID
+}
+
+// SetTraceID sets the ID of the operation that is currently being traced
+func (ctx *Context) SetTraceID(traceID uint64) {
+	ctx.traceLock.Lock()
+	defer ctx.traceLock.Unlock()
+
+	ctx.traceID = traceID
+}
+
+// EnableTracing tags the messages logged by [ctx.Log] with the ID of the
+// operation that is currently being traced, if the logger supports it
+func (ctx *Context) EnableTracing() {
+	if tracer, ok := ctx.Log.(logging.Tracer); ok {
+		tracer.SetTracer(ctx.TraceID)
+	}
 }
 
 // DefaultContextTest ...
@@ -57,8 +132,10 @@ func DefaultContextTest() *Context {
 		ChainID:             ids.Empty,
 		NodeID:              ids.ShortEmpty,
 		Log:                 logging.NoLog{},
+		ConsensusLog:        logging.NoLog{},
 		DecisionDispatcher:  &decisionED,
 		ConsensusDispatcher: &consensusED,
 		BCLookup:            &ids.Aliaser{},
+		Metrics:             prometheus.NewRegistry(),
 	}
 }
@@ -30,7 +30,7 @@ type bootstrapper struct {
 	metrics
 	common.Bootstrapper
 
-	pending    ids.Set
+	stored     ids.Set // Vertices that were queued to be executed
 	finished   bool
 	onFinished func()
 }
@@ -46,6 +46,7 @@ func (b *bootstrapper) Initialize(config BootstrapConfig) {
 	})
 
 	b.TxBlocked.SetParser(&txParser{
+		ctx:         b.BootstrapConfig.Context,
 		numAccepted: b.numBootstrappedTx,
 		numDropped:  b.numDroppedTx,
 		vm:          b.VM,
@@ -75,72 +76,105 @@ func (b *bootstrapper) FilterAccepted(containerIDs ids.Set) ids.Set {
 
 // ForceAccepted ...
 func (b *bootstrapper) ForceAccepted(acceptedContainerIDs ids.Set) {
+	// Vertices queued before a restart don't need to be fetched again, but
+	// their missing ancestors do
+	b.fetchMissing()
 	for _, vtxID := range acceptedContainerIDs.List() {
 		b.fetch(vtxID)
 	}
 
-	if numPending := b.pending.Len(); numPending == 0 {
+	if numPending := b.NumFetching(); numPending == 0 {
 		// TODO: This typically indicates bootstrapping has failed, so this
 		// should be handled appropriately
 		b.finish()
 	}
 }
 
-// Put ...
-func (b *bootstrapper) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte) {
-	b.BootstrapConfig.Context.Log.Verbo("Put called for vertexID %s", vtxID)
+// MultiPut ...
+func (b *bootstrapper) MultiPut(vdr ids.ShortID, requestID uint32, vtxs [][]byte) {
+	vtxID, ok := b.FetchResponded(vdr, requestID)
+	if !ok {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("Received an unexpected MultiPut from %s with requestID %d", vdr, requestID)
+		return
+	}
+	b.BootstrapConfig.Context.ConsensusLog.Verbo("MultiPut called for vertexID %s with %d vertices", vtxID, len(vtxs))
 
-	if !b.pending.Contains(vtxID) {
+	if len(vtxs) == 0 {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("MultiPut from %s didn't contain %s", vdr, vtxID)
+		b.Refetch(vdr, vtxID)
 		return
 	}
 
-	vtx, err := b.State.ParseVertex(vtxBytes)
+	vtx, err := b.State.ParseVertex(vtxs[0])
 	if err != nil {
-		b.BootstrapConfig.Context.Log.Warn("ParseVertex failed due to %s for block:\n%s",
+		b.BootstrapConfig.Context.ConsensusLog.Warn("ParseVertex failed due to %s for block:\n%s",
 			err,
-			formatting.DumpBytes{Bytes: vtxBytes})
-		b.GetFailed(vdr, requestID, vtxID)
+			formatting.DumpBytes{Bytes: vtxs[0]})
+		b.Refetch(vdr, vtxID)
 		return
 	}
+	if !vtx.ID().Equals(vtxID) {
+		b.BootstrapConfig.Context.ConsensusLog.Warn("MultiPut from %s contained %s rather than the requested %s", vdr, vtx.ID(), vtxID)
+		b.Refetch(vdr, vtxID)
+		return
+	}
+
+	// Parse the ancestors, so they're known when the vertex's ancestry is
+	// walked. Only ancestors of the requested vertex are parsed.
+	ancestorIDs := ids.Set{}
+	for _, parent := range vtx.Parents() {
+		ancestorIDs.Add(parent.ID())
+	}
+	for _, ancestorBytes := range vtxs[1:] {
+		ancestor, err := b.State.ParseVertex(ancestorBytes)
+		if err != nil || !ancestorIDs.Contains(ancestor.ID()) {
+			break
+		}
+		for _, parent := range ancestor.Parents() {
+			ancestorIDs.Add(parent.ID())
+		}
+	}
 
+	b.Fetched(vtxID)
 	b.addVertex(vtx)
 }
 
+// GetAncestorsFailed ...
+func (b *bootstrapper) GetAncestorsFailed(vdr ids.ShortID, requestID uint32) {
+	if vtxID, ok := b.FetchFailed(vdr, requestID); ok {
+		b.Refetch(vdr, vtxID)
+	}
+}
+
+// Put handles the response of a validator that was sent a Get request
+// because it may not support GetAncestors
+func (b *bootstrapper) Put(vdr ids.ShortID, requestID uint32, _ ids.ID, vtxBytes []byte) {
+	b.MultiPut(vdr, requestID, [][]byte{vtxBytes})
+}
+
 // GetFailed ...
-func (b *bootstrapper) GetFailed(_ ids.ShortID, _ uint32, vtxID ids.ID) { b.sendRequest(vtxID) }
+func (b *bootstrapper) GetFailed(vdr ids.ShortID, requestID uint32, _ ids.ID) {
+	b.GetAncestorsFailed(vdr, requestID)
+}
 
 func (b *bootstrapper) fetch(vtxID ids.ID) {
-	if b.pending.Contains(vtxID) {
+	if b.Fetching(vtxID) || b.queued(vtxID) {
 		return
 	}
 
 	vtx, err := b.State.GetVertex(vtxID)
 	if err != nil {
-		b.sendRequest(vtxID)
+		b.Fetch(vtxID)
+		b.numPendingRequests.Set(float64(b.NumFetching()))
 		return
 	}
 	b.storeVertex(vtx)
 }
 
-func (b *bootstrapper) sendRequest(vtxID ids.ID) {
-	validators := b.BootstrapConfig.Validators.Sample(1)
-	if len(validators) == 0 {
-		b.BootstrapConfig.Context.Log.Error("Dropping request for %s as there are no validators", vtxID)
-		return
-	}
-	validatorID := validators[0].ID()
-	b.RequestID++
-
-	b.pending.Add(vtxID)
-	b.BootstrapConfig.Sender.Get(validatorID, b.RequestID, vtxID)
-
-	b.numPendingRequests.Set(float64(b.pending.Len()))
-}
-
 func (b *bootstrapper) addVertex(vtx avalanche.Vertex) {
 	b.storeVertex(vtx)
 
-	if numPending := b.pending.Len(); numPending == 0 {
+	if numPending := b.NumFetching(); numPending == 0 {
 		b.finish()
 	}
 }
@@ -156,12 +190,20 @@ func (b *bootstrapper) storeVertex(vtx avalanche.Vertex) {
 		vtxID := vtx.ID()
 		switch status := vtx.Status(); status {
 		case choices.Unknown:
-			b.sendRequest(vtxID)
+			b.Fetch(vtxID)
 		case choices.Processing:
-			b.pending.Remove(vtxID)
+			if b.queued(vtxID) {
+				// Vertices may share ancestors, responses to parallel
+				// requests may overlap, and vertices may have been queued
+				// before a restart, so this vertex and its ancestors may
+				// already be stored
+				continue
+			}
+			b.stored.Add(vtxID)
+			b.Fetched(vtxID)
 
 			if err := b.VtxBlocked.Push(&vertexJob{
-				numAccepted: b.numBootstrappedVtx,
+						numAccepted: b.numBootstrappedVtx,
 				numDropped:  b.numDroppedVtx,
 				vtx:         vtx,
 			}); err == nil {
@@ -169,6 +211,7 @@ func (b *bootstrapper) storeVertex(vtx avalanche.Vertex) {
 			}
 			for _, tx := range vtx.Txs() {
 				if err := b.TxBlocked.Push(&txJob{
+					ctx:         b.BootstrapConfig.Context,
 					numAccepted: b.numBootstrappedVtx,
 					numDropped:  b.numDroppedVtx,
 					tx:          tx,
@@ -181,16 +224,35 @@ func (b *bootstrapper) storeVertex(vtx avalanche.Vertex) {
 				vts = append(vts, parent)
 			}
 		case choices.Accepted:
-			b.BootstrapConfig.Context.Log.Verbo("Bootstrapping confirmed %s", vtxID)
+			b.BootstrapConfig.Context.ConsensusLog.Verbo("Bootstrapping confirmed %s", vtxID)
 		case choices.Rejected:
-			b.BootstrapConfig.Context.Log.Error("Bootstrapping wants to accept %s, however it was previously rejected", vtxID)
+			b.BootstrapConfig.Context.ConsensusLog.Error("Bootstrapping wants to accept %s, however it was previously rejected", vtxID)
 		}
 	}
 
-	numPending := b.pending.Len()
+	// Persist the queued vertices and transactions, so they don't need to be
+	// fetched again if the node restarts before bootstrapping finishes
+	if err := b.VtxBlocked.Commit(); err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("Failed to commit the vertex bootstrapping queue due to %s", err)
+	}
+	if err := b.TxBlocked.Commit(); err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("Failed to commit the transaction bootstrapping queue due to %s", err)
+	}
+
+	numPending := b.NumFetching()
 	b.numPendingRequests.Set(float64(numPending))
 }
 
+// queued returns whether [vtxID] was queued to be executed, possibly before a
+// restart
+func (b *bootstrapper) queued(vtxID ids.ID) bool {
+	if b.stored.Contains(vtxID) {
+		return true
+	}
+	has, err := b.VtxBlocked.Has(vtxID)
+	return err == nil && has
+}
+
 func (b *bootstrapper) finish() {
 	if b.finished {
 		return
@@ -199,16 +261,40 @@ func (b *bootstrapper) finish() {
 	b.executeAll(b.TxBlocked, b.numBlockedTx)
 	b.executeAll(b.VtxBlocked, b.numBlockedVtx)
 
+	// Vertices left in the queue are blocked on an ancestor that wasn't
+	// fetched, so bootstrapping continues until it is
+	if b.fetchMissing() {
+		return
+	}
+
 	// Start consensus
 	b.onFinished()
 	b.finished = true
 }
 
+// fetchMissing fetches the ancestors that queued vertices are blocked on, but
+// that weren't queued. It returns true if any vertices are being fetched.
+func (b *bootstrapper) fetchMissing() bool {
+	missingIDs, err := b.VtxBlocked.MissingDependencies()
+	if err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("Failed to read the vertex bootstrapping queue due to %s", err)
+	}
+	for _, vtxID := range missingIDs.List() {
+		b.fetch(vtxID)
+	}
+	return b.NumFetching() > 0
+}
+
 func (b *bootstrapper) executeAll(jobs *queue.Jobs, numBlocked prometheus.Gauge) {
 	for job, err := jobs.Pop(); err == nil; job, err = jobs.Pop() {
 		numBlocked.Dec()
 		if err := jobs.Execute(job); err != nil {
-			b.BootstrapConfig.Context.Log.Warn("Error executing: %s", err)
+			b.BootstrapConfig.Context.ConsensusLog.Warn("Error executing: %s", err)
+		}
+		// Executing a job twice is a no-op, so it's safe for the VM to
+		// persist a job's result before the queue records its execution
+		if err := jobs.Commit(); err != nil {
+			b.BootstrapConfig.Context.ConsensusLog.Error("Failed to commit the bootstrapping queue due to %s", err)
 		}
 	}
 }
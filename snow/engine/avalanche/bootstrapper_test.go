@@ -133,7 +133,7 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 	}
 
 	vtxIDToReqID := map[[32]byte]uint32{}
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -153,7 +153,7 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	state.getVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	if numReqs := len(vtxIDToReqID); numReqs != 3 {
 		t.Fatalf("Should have requested %d vertices, %d were requested", 3, numReqs)
@@ -202,11 +202,11 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 
 		switch {
 		case vtxID.Equals(vtxID0):
-			bs.Put(peerID, reqID, vtxID, vtxBytes0)
+			bs.MultiPut(peerID, reqID, [][]byte{vtxBytes0})
 		case vtxID.Equals(vtxID1):
-			bs.Put(peerID, reqID, vtxID, vtxBytes1)
+			bs.MultiPut(peerID, reqID, [][]byte{vtxBytes1})
 		case vtxID.Equals(vtxID2):
-			bs.Put(peerID, reqID, vtxID, vtxBytes2)
+			bs.MultiPut(peerID, reqID, [][]byte{vtxBytes2})
 		default:
 			t.Fatalf("Requested unknown vertex")
 		}
@@ -272,7 +272,7 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	}
 
 	requestID := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -288,7 +288,6 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	state.getVertex = nil
-	sender.GetF = nil
 
 	state.parseVertex = func(vtxBytes []byte) (avalanche.Vertex, error) {
 		switch {
@@ -316,8 +315,16 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *requestID, vtxID1, vtxBytes1)
-	bs.Put(peerID, *requestID, vtxID0, vtxBytes0)
+	// The wrong vertex should cause the requested vertex to be requested again
+	oldRequestID := *requestID
+	bs.MultiPut(peerID, *requestID, [][]byte{vtxBytes1})
+	if *requestID == oldRequestID {
+		t.Fatalf("Should have requested the vertex again")
+	}
+
+	sender.GetAncestorsF = nil
+
+	bs.MultiPut(peerID, *requestID, [][]byte{vtxBytes0})
 
 	state.parseVertex = nil
 	state.edge = nil
@@ -377,7 +384,7 @@ func TestBootstrapperVertexDependencies(t *testing.T) {
 	}
 
 	reqIDPtr := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -393,7 +400,7 @@ func TestBootstrapperVertexDependencies(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	state.getVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	state.parseVertex = func(vtxBytes []byte) (avalanche.Vertex, error) {
 		switch {
@@ -403,7 +410,7 @@ func TestBootstrapperVertexDependencies(t *testing.T) {
 		t.Fatal(errParsedUnknownVertex)
 		return nil, errParsedUnknownVertex
 	}
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -416,10 +423,10 @@ func TestBootstrapperVertexDependencies(t *testing.T) {
 		*reqIDPtr = reqID
 	}
 
-	bs.Put(peerID, *reqIDPtr, vtxID1, vtxBytes1)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes1})
 
 	state.parseVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	if vtx0.Status() != choices.Unknown {
 		t.Fatalf("Vertex should be unknown")
@@ -463,7 +470,7 @@ func TestBootstrapperVertexDependencies(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *reqIDPtr, vtxID0, vtxBytes0)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes0})
 
 	state.parseVertex = nil
 	bs.onFinished = nil
@@ -551,7 +558,7 @@ func TestBootstrapperTxDependencies(t *testing.T) {
 	}
 
 	reqIDPtr := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -567,7 +574,7 @@ func TestBootstrapperTxDependencies(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	state.getVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	state.parseVertex = func(vtxBytes []byte) (avalanche.Vertex, error) {
 		switch {
@@ -577,7 +584,7 @@ func TestBootstrapperTxDependencies(t *testing.T) {
 		t.Fatal(errParsedUnknownVertex)
 		return nil, errParsedUnknownVertex
 	}
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -590,10 +597,10 @@ func TestBootstrapperTxDependencies(t *testing.T) {
 		*reqIDPtr = reqID
 	}
 
-	bs.Put(peerID, *reqIDPtr, vtxID1, vtxBytes1)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes1})
 
 	state.parseVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	if tx0.Status() != choices.Processing {
 		t.Fatalf("Tx should be processing")
@@ -655,7 +662,7 @@ func TestBootstrapperTxDependencies(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *reqIDPtr, vtxID0, vtxBytes0)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes0})
 
 	state.parseVertex = nil
 	bs.onFinished = nil
@@ -747,7 +754,7 @@ func TestBootstrapperMissingTxDependency(t *testing.T) {
 	}
 
 	reqIDPtr := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -763,7 +770,7 @@ func TestBootstrapperMissingTxDependency(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	state.getVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	state.parseVertex = func(vtxBytes []byte) (avalanche.Vertex, error) {
 		switch {
@@ -773,7 +780,7 @@ func TestBootstrapperMissingTxDependency(t *testing.T) {
 		t.Fatal(errParsedUnknownVertex)
 		return nil, errParsedUnknownVertex
 	}
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested vertex from %s, requested from %s", peerID, vdr)
 		}
@@ -786,10 +793,10 @@ func TestBootstrapperMissingTxDependency(t *testing.T) {
 		*reqIDPtr = reqID
 	}
 
-	bs.Put(peerID, *reqIDPtr, vtxID1, vtxBytes1)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes1})
 
 	state.parseVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	if tx0.Status() != choices.Unknown {
 		t.Fatalf("Tx should be unknown")
@@ -847,7 +854,7 @@ func TestBootstrapperMissingTxDependency(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *reqIDPtr, vtxID0, vtxBytes0)
+	bs.MultiPut(peerID, *reqIDPtr, [][]byte{vtxBytes0})
 
 	state.parseVertex = nil
 	bs.onFinished = nil
@@ -995,7 +1002,7 @@ func TestBootstrapperPartialFetch(t *testing.T) {
 		}
 	}
 
-	sender.CantGet = false
+	sender.CantGetAncestors = false
 
 	bs.ForceAccepted(acceptedIDs)
 
@@ -1003,7 +1010,7 @@ func TestBootstrapperPartialFetch(t *testing.T) {
 		t.Fatalf("should have requested a vertex")
 	}
 
-	if bs.pending.Len() != 1 {
+	if bs.NumFetching() != 1 {
 		t.Fatalf("wrong number pending")
 	}
 }
@@ -46,13 +46,13 @@ func (i *issuer) Update() {
 
 	for _, tx := range i.vtx.Txs() {
 		if err := tx.Verify(); err != nil {
-			i.t.Config.Context.Log.Debug("Transaction failed verification due to %s, dropping vertex", err)
+			i.t.Config.Context.ConsensusLog.Debug("Transaction failed verification due to %s, dropping vertex", err)
 			i.t.vtxBlocked.Abandon(vtxID)
 			return
 		}
 	}
 
-	i.t.Config.Context.Log.Verbo("Adding vertex to consensus:\n%s", i.vtx)
+	i.t.Config.Context.ConsensusLog.Verbo("Adding vertex to consensus:\n%s", i.vtx)
 
 	i.t.Consensus.Add(i.vtx)
 
@@ -68,7 +68,7 @@ func (i *issuer) Update() {
 	if numVdrs := len(vdrs); numVdrs == p.K && i.t.polls.Add(i.t.RequestID, vdrSet.Len()) {
 		i.t.Config.Sender.PushQuery(vdrSet, i.t.RequestID, vtxID, i.vtx.Bytes())
 	} else if numVdrs < p.K {
-		i.t.Config.Context.Log.Error("Query for %s was dropped due to an insufficient number of validators", vtxID)
+		i.t.Config.Context.ConsensusLog.Error("Query for %s was dropped due to an insufficient number of validators", vtxID)
 	}
 
 	i.t.vtxBlocked.Fulfill(vtxID)
@@ -15,6 +15,10 @@ type metrics struct {
 	numBootstrappedTx, numDroppedTx prometheus.Counter
 
 	numPolls, numVtxRequests, numTxRequests, numPendingVtx prometheus.Gauge
+
+	pollDuration                             prometheus.Histogram
+	numVotes, numFailedVotes                 prometheus.Counter
+	numSuccessfulPolls, numUnsuccessfulPolls prometheus.Counter
 }
 
 // Initialize implements the Engine interface
@@ -85,6 +89,37 @@ func (m *metrics) Initialize(log logging.Logger, namespace string, registerer pr
 			Name:      "av_blocked_vts",
 			Help:      "Number of blocked vertices",
 		})
+	m.pollDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "av_poll_duration",
+			Help:      "Time from a network poll being sent to it finishing, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(.005, 2, 12), // 5ms to 10s
+		})
+	m.numVotes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "av_votes",
+			Help:      "Number of votes received in response to network polls",
+		})
+	m.numFailedVotes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "av_votes_failed",
+			Help:      "Number of network poll queries that failed or timed out",
+		})
+	m.numSuccessfulPolls = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "av_polls_successful",
+			Help:      "Number of finished network polls in which a vertex received an alpha majority",
+		})
+	m.numUnsuccessfulPolls = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "av_polls_unsuccessful",
+			Help:      "Number of finished network polls in which no vertex received an alpha majority",
+		})
 
 	if err := registerer.Register(m.numPendingRequests); err != nil {
 		log.Error("Failed to register av_bs_vtx_requests statistics due to %s", err)
@@ -119,4 +154,19 @@ func (m *metrics) Initialize(log logging.Logger, namespace string, registerer pr
 	if err := registerer.Register(m.numPendingVtx); err != nil {
 		log.Error("Failed to register av_blocked_vts statistics due to %s", err)
 	}
+	if err := registerer.Register(m.pollDuration); err != nil {
+		log.Error("Failed to register av_poll_duration statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numVotes); err != nil {
+		log.Error("Failed to register av_votes statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numFailedVotes); err != nil {
+		log.Error("Failed to register av_votes_failed statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numSuccessfulPolls); err != nil {
+		log.Error("Failed to register av_polls_successful statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numUnsuccessfulPolls); err != nil {
+		log.Error("Failed to register av_polls_unsuccessful statistics due to %s", err)
+	}
 }
@@ -6,6 +6,7 @@ package avalanche
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -32,7 +33,12 @@ import (
 type polls struct {
 	log      logging.Logger
 	numPolls prometheus.Gauge
+	alpha    int
 	m        map[uint32]poll
+
+	pollDuration                             prometheus.Histogram
+	numVotes, numFailedVotes                 prometheus.Counter
+	numSuccessfulPolls, numUnsuccessfulPolls prometheus.Counter
 }
 
 // Add to the current set of polls
@@ -42,6 +48,7 @@ func (p *polls) Add(requestID uint32, numPolled int) bool {
 	poll, exists := p.m[requestID]
 	if !exists {
 		poll.numPending = numPolled
+		poll.start = time.Now()
 		p.m[requestID] = poll
 
 		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
@@ -59,17 +66,36 @@ func (p *polls) Vote(requestID uint32, vdr ids.ShortID, votes []ids.ID) (ids.Uni
 		return nil, false
 	}
 
+	if len(votes) == 0 {
+		p.numFailedVotes.Inc() // Failed queries are recorded as empty votes
+	} else {
+		p.numVotes.Inc()
+	}
+
 	poll.Vote(votes)
 	if poll.Finished() {
 		p.log.Verbo("Poll is finished")
-		delete(p.m, requestID)
-		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
+		p.finish(requestID, poll)
 		return poll.votes, true
 	}
 	p.m[requestID] = poll
 	return nil, false
 }
 
+// finish removes the finished poll [requestID], and tracks its duration and
+// whether a vertex received an alpha majority of direct votes
+func (p *polls) finish(requestID uint32, poll poll) {
+	delete(p.m, requestID)
+	p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
+
+	p.pollDuration.Observe(time.Since(poll.start).Seconds())
+	if bag := poll.votes.Bag(p.alpha); bag.Threshold().Len() > 0 {
+		p.numSuccessfulPolls.Inc()
+	} else {
+		p.numUnsuccessfulPolls.Inc()
+	}
+}
+
 func (p *polls) String() string {
 	sb := strings.Builder{}
 
@@ -85,6 +111,7 @@ func (p *polls) String() string {
 type poll struct {
 	votes      ids.UniqueBag
 	numPending int
+	start      time.Time
 }
 
 // Vote registers a vote for this poll
@@ -63,6 +63,16 @@ func (s *prefixedState) SetVertex(vtx *vertex) {
 	s.state.SetVertex(vID, vtx)
 }
 
+// VertexKey returns the key of the database that the vertex [id] is stored at
+func (s *prefixedState) VertexKey(id ids.ID) []byte {
+	if cachedVtxIDIntf, found := s.vtx.Get(id); found {
+		return cachedVtxIDIntf.(ids.ID).Bytes()
+	}
+	vID := id.Prefix(vtxID)
+	s.vtx.Put(id, vID)
+	return vID.Bytes()
+}
+
 func (s *prefixedState) Status(id ids.ID) choices.Status {
 	sID := ids.ID{}
 	if cachedStatusIDIntf, found := s.status.Get(id); found {
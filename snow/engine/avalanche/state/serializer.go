@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/snow/pruning"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/math"
 
@@ -34,6 +35,9 @@ var (
 
 // Serializer manages the state of multiple vertices
 type Serializer struct {
+	// If non-nil, the rejected vertices are pruned from the database
+	Pruner *pruning.Pruner
+
 	ctx   *snow.Context
 	vm    avaeng.DAGVM
 	state *prefixedState
@@ -72,8 +76,12 @@ func (s *Serializer) ParseVertex(b []byte) (avacon.Vertex, error) {
 		serializer: s,
 		vtxID:      vtx.ID(),
 	}
-	if uVtx.Status() == choices.Unknown {
+	switch {
+	case uVtx.Status() == choices.Unknown:
 		uVtx.setVertex(vtx)
+	case uVtx.v.vtx == nil:
+		// The vertex was pruned after it was rejected
+		uVtx.v.vtx = vtx
 	}
 
 	s.db.Commit()
@@ -144,7 +152,8 @@ func (s *Serializer) getVertex(vtxID ids.ID) (*uniqueVertex, error) {
 		serializer: s,
 		vtxID:      vtxID,
 	}
-	if vtx.Status() == choices.Unknown {
+	// The vertex may have been pruned after it was rejected
+	if vtx.Status() == choices.Unknown || vtx.v.vtx == nil {
 		return nil, errUnknownVertex
 	}
 	return vtx, nil
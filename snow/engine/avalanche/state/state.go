@@ -32,7 +32,7 @@ func (s *state) Vertex(id ids.ID) *vertex {
 			s.dbCache.Put(id, vtx) // Cache the element
 			return vtx
 		}
-		s.serializer.ctx.Log.Error("Parsing failed on saved vertex.\nPrefixed key = %s\nBytes = %s",
+		s.serializer.ctx.ConsensusLog.Error("Parsing failed on saved vertex.\nPrefixed key = %s\nBytes = %s",
 			id,
 			formatting.DumpBytes{Bytes: b})
 	}
@@ -66,7 +66,7 @@ func (s *state) Status(id ids.ID) choices.Status {
 			s.dbCache.Put(id, status)
 			return status
 		}
-		s.serializer.ctx.Log.Error("Parsing failed on saved status.\nPrefixed key = %s\nBytes = \n%s",
+		s.serializer.ctx.ConsensusLog.Error("Parsing failed on saved status.\nPrefixed key = %s\nBytes = \n%s",
 			id,
 			formatting.DumpBytes{Bytes: b})
 	}
@@ -87,8 +87,8 @@ func (s *state) SetStatus(id ids.ID, status choices.Status) {
 
 	p.PackInt(uint32(status))
 
-	s.serializer.ctx.Log.AssertNoError(p.Err)
-	s.serializer.ctx.Log.AssertTrue(p.Offset == len(p.Bytes), "Wrong offset after packing")
+	s.serializer.ctx.ConsensusLog.AssertNoError(p.Err)
+	s.serializer.ctx.ConsensusLog.AssertTrue(p.Offset == len(p.Bytes), "Wrong offset after packing")
 
 	s.db.Put(id.Bytes(), p.Bytes)
 }
@@ -112,7 +112,7 @@ func (s *state) Edge(id ids.ID) []ids.ID {
 			s.dbCache.Put(id, frontier)
 			return frontier
 		}
-		s.serializer.ctx.Log.Error("Parsing failed on saved ids.\nPrefixed key = %s\nBytes = %s",
+		s.serializer.ctx.ConsensusLog.Error("Parsing failed on saved ids.\nPrefixed key = %s\nBytes = %s",
 			id,
 			formatting.DumpBytes{Bytes: b})
 	}
@@ -137,8 +137,8 @@ func (s *state) SetEdge(id ids.ID, frontier []ids.ID) {
 		p.PackFixedBytes(id.Bytes())
 	}
 
-	s.serializer.ctx.Log.AssertNoError(p.Err)
-	s.serializer.ctx.Log.AssertTrue(p.Offset == len(p.Bytes), "Wrong offset after packing")
+	s.serializer.ctx.ConsensusLog.AssertNoError(p.Err)
+	s.serializer.ctx.ConsensusLog.AssertTrue(p.Offset == len(p.Bytes), "Wrong offset after packing")
 
 	s.db.Put(id.Bytes(), p.Bytes)
 }
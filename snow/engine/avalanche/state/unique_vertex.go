@@ -101,6 +101,13 @@ func (vtx *uniqueVertex) Reject() {
 	vtx.v.parents = nil
 
 	vtx.serializer.db.Commit()
+
+	// The vertex's status is kept, so it's never issued again
+	if pruner := vtx.serializer.Pruner; pruner != nil {
+		if err := pruner.Schedule(vtx.serializer.state.VertexKey(vtx.vtxID)); err != nil {
+			vtx.serializer.ctx.ConsensusLog.Warn("couldn't schedule rejected vertex %s to be pruned due to %s", vtx.vtxID, err)
+		}
+	}
 }
 
 func (vtx *uniqueVertex) Status() choices.Status { vtx.refresh(); return vtx.v.status }
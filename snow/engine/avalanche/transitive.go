@@ -6,6 +6,7 @@ package avalanche
 import (
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/avalanche"
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
 	"github.com/ava-labs/gecko/snow/engine/common"
@@ -35,16 +36,22 @@ type Transitive struct {
 
 // Initialize implements the Engine interface
 func (t *Transitive) Initialize(config Config) {
-	config.Context.Log.Info("Initializing Avalanche consensus")
+	config.Context.ConsensusLog.Info("Initializing Avalanche consensus")
 
 	t.Config = config
-	t.metrics.Initialize(config.Context.Log, config.Params.Namespace, config.Params.Metrics)
+	t.metrics.Initialize(config.Context.ConsensusLog, config.Params.Namespace, config.Params.Metrics)
 
 	t.onFinished = t.finishBootstrapping
 	t.bootstrapper.Initialize(config.BootstrapConfig)
 
-	t.polls.log = config.Context.Log
+	t.polls.log = config.Context.ConsensusLog
 	t.polls.numPolls = t.numPolls
+	t.polls.alpha = config.Params.Alpha
+	t.polls.pollDuration = t.pollDuration
+	t.polls.numVotes = t.numVotes
+	t.polls.numFailedVotes = t.numFailedVotes
+	t.polls.numSuccessfulPolls = t.numSuccessfulPolls
+	t.polls.numUnsuccessfulPolls = t.numUnsuccessfulPolls
 	t.polls.m = make(map[uint32]poll)
 }
 
@@ -55,7 +62,7 @@ func (t *Transitive) finishBootstrapping() {
 		if vtx, err := t.Config.State.GetVertex(vtxID); err == nil {
 			frontier = append(frontier, vtx)
 		} else {
-			t.Config.Context.Log.Error("Vertex %s failed to be loaded from the frontier with %s", vtxID, err)
+			t.Config.Context.ConsensusLog.Error("Vertex %s failed to be loaded from the frontier with %s", vtxID, err)
 		}
 	}
 	t.Consensus.Initialize(t.Config.Context, t.Params, frontier)
@@ -64,7 +71,7 @@ func (t *Transitive) finishBootstrapping() {
 
 // Shutdown implements the Engine interface
 func (t *Transitive) Shutdown() {
-	t.Config.Context.Log.Info("Shutting down Avalanche consensus")
+	t.Config.Context.ConsensusLog.Info("Shutting down Avalanche consensus")
 	t.Config.VM.Shutdown()
 }
 
@@ -79,9 +86,45 @@ func (t *Transitive) Get(vdr ids.ShortID, requestID uint32, vtxID ids.ID) {
 	}
 }
 
+// GetAncestors implements the Engine interface
+func (t *Transitive) GetAncestors(vdr ids.ShortID, requestID uint32, vtxID ids.ID) {
+	vtx, err := t.Config.State.GetVertex(vtxID)
+	if err != nil || vtx.Status() == choices.Unknown {
+		t.Config.Context.ConsensusLog.Verbo("Dropping GetAncestors for unknown vertex %s", vtxID)
+		return
+	}
+
+	// Walk the vertex's ancestry breadth first, so parents are sent after
+	// their children
+	vtxs := [][]byte{}
+	size := 0
+	queue := []avalanche.Vertex{vtx}
+	visited := ids.Set{}
+	visited.Add(vtxID)
+	for len(queue) > 0 && len(vtxs) < common.MaxContainersPerMultiPut {
+		vtx := queue[0]
+		queue = queue[1:]
+
+		vtxBytes := vtx.Bytes()
+		if size += len(vtxBytes); len(vtxs) > 0 && size > common.MaxContainersLen {
+			break
+		}
+		vtxs = append(vtxs, vtxBytes)
+
+		for _, parent := range vtx.Parents() {
+			if parentID := parent.ID(); !visited.Contains(parentID) && parent.Status() != choices.Unknown {
+				visited.Add(parentID)
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	t.Config.Sender.MultiPut(vdr, requestID, vtxs)
+}
+
 // Put implements the Engine interface
 func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxBytes []byte) {
-	t.Config.Context.Log.Verbo("Put called for vertexID %s", vtxID)
+	t.Config.Context.ConsensusLog.Verbo("Put called for vertexID %s", vtxID)
 
 	if !t.bootstrapped {
 		t.bootstrapper.Put(vdr, requestID, vtxID, vtxBytes)
@@ -90,7 +133,7 @@ func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtxByt
 
 	vtx, err := t.Config.State.ParseVertex(vtxBytes)
 	if err != nil {
-		t.Config.Context.Log.Warn("ParseVertex failed due to %s for block:\n%s",
+		t.Config.Context.ConsensusLog.Warn("ParseVertex failed due to %s for block:\n%s",
 			err,
 			formatting.DumpBytes{Bytes: vtxBytes})
 		t.GetFailed(vdr, requestID, vtxID)
@@ -123,10 +166,28 @@ func (t *Transitive) GetFailed(vdr ids.ShortID, requestID uint32, vtxID ids.ID)
 	t.numBlockedVtx.Set(float64(t.pending.Len()))
 }
 
+// MultiPut implements the Engine interface
+func (t *Transitive) MultiPut(vdr ids.ShortID, requestID uint32, vtxs [][]byte) {
+	if !t.bootstrapped {
+		t.bootstrapper.MultiPut(vdr, requestID, vtxs)
+		return
+	}
+	t.Config.Context.ConsensusLog.Debug("Dropping MultiPut(%s, %d) as bootstrapping has finished", vdr, requestID)
+}
+
+// GetAncestorsFailed implements the Engine interface
+func (t *Transitive) GetAncestorsFailed(vdr ids.ShortID, requestID uint32) {
+	if !t.bootstrapped {
+		t.bootstrapper.GetAncestorsFailed(vdr, requestID)
+		return
+	}
+	t.Config.Context.ConsensusLog.Debug("Dropping GetAncestorsFailed(%s, %d) as bootstrapping has finished", vdr, requestID)
+}
+
 // PullQuery implements the Engine interface
 func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Debug("Dropping PullQuery for %s due to bootstrapping", vtxID)
+		t.Config.Context.ConsensusLog.Debug("Dropping PullQuery for %s due to bootstrapping", vtxID)
 		return
 	}
 
@@ -147,7 +208,7 @@ func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID)
 // PushQuery implements the Engine interface
 func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID, vtx []byte) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Debug("Dropping PushQuery for %s due to bootstrapping", vtxID)
+		t.Config.Context.ConsensusLog.Debug("Dropping PushQuery for %s due to bootstrapping", vtxID)
 		return
 	}
 
@@ -158,7 +219,7 @@ func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, vtxID ids.ID,
 // Chits implements the Engine interface
 func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes ids.Set) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Warn("Dropping Chits due to bootstrapping")
+		t.Config.Context.ConsensusLog.Warn("Dropping Chits due to bootstrapping")
 		return
 	}
 
@@ -186,7 +247,7 @@ func (t *Transitive) QueryFailed(vdr ids.ShortID, requestID uint32) {
 // Notify implements the Engine interface
 func (t *Transitive) Notify(msg common.Message) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Warn("Dropping Notify due to bootstrapping")
+		t.Config.Context.ConsensusLog.Warn("Dropping Notify due to bootstrapping")
 		return
 	}
 
@@ -274,7 +335,7 @@ func (t *Transitive) insert(vtx avalanche.Vertex) {
 		}
 	}
 
-	t.Config.Context.Log.Verbo("Vertex: %s is blocking on %d vertices and %d transactions", vtxID, i.vtxDeps.Len(), i.txDeps.Len())
+	t.Config.Context.ConsensusLog.Verbo("Vertex: %s is blocking on %d vertices and %d transactions", vtxID, i.vtxDeps.Len(), i.txDeps.Len())
 
 	t.vtxBlocked.Register(&vtxIssuer{i: i})
 	t.txBlocked.Register(&txIssuer{i: i})
@@ -322,7 +383,7 @@ func (t *Transitive) batch(txs []snowstorm.Tx, force, empty bool) {
 }
 
 func (t *Transitive) issueBatch(txs []snowstorm.Tx) {
-	t.Config.Context.Log.Verbo("Batching %d transactions into a new vertex", len(txs))
+	t.Config.Context.ConsensusLog.Verbo("Batching %d transactions into a new vertex", len(txs))
 
 	virtuousIDs := t.Consensus.Virtuous().List()
 	sampler := random.Uniform{N: len(virtuousIDs)}
@@ -334,13 +395,13 @@ func (t *Transitive) issueBatch(txs []snowstorm.Tx) {
 	if vtx, err := t.Config.State.BuildVertex(parentIDs, txs); err == nil {
 		t.insert(vtx)
 	} else {
-		t.Config.Context.Log.Warn("Error building new vertex with %d parents and %d transactions", len(parentIDs), len(txs))
+		t.Config.Context.ConsensusLog.Warn("Error building new vertex with %d parents and %d transactions", len(parentIDs), len(txs))
 	}
 }
 
 func (t *Transitive) sendRequest(vdr ids.ShortID, vtxID ids.ID) {
 	if t.vtxReqs.Contains(vtxID) {
-		t.Config.Context.Log.Debug("Not requesting a vertex because we have recently sent a request")
+		t.Config.Context.ConsensusLog.Debug("Not requesting a vertex because we have recently sent a request")
 		return
 	}
 
@@ -2255,7 +2255,7 @@ func TestEngineBootstrappingIntoConsensus(t *testing.T) {
 		panic("Unknown vertex requested")
 	}
 
-	sender.GetF = func(inVdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(inVdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdrID.Equals(inVdr) {
 			t.Fatalf("Asking wrong validator for vertex")
 		}
@@ -2268,7 +2268,7 @@ func TestEngineBootstrappingIntoConsensus(t *testing.T) {
 	te.Accepted(vdrID, *requestID, acceptedFrontier)
 
 	st.getVertex = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	vm.ParseTxF = func(b []byte) (snowstorm.Tx, error) {
 		switch {
@@ -2298,7 +2298,7 @@ func TestEngineBootstrappingIntoConsensus(t *testing.T) {
 		panic("Unknown bytes provided")
 	}
 
-	te.Put(vdrID, *requestID, vtxID0, vtxBytes0)
+	te.MultiPut(vdrID, *requestID, [][]byte{vtxBytes0})
 
 	vm.ParseTxF = nil
 	st.parseVertex = nil
@@ -23,3 +23,13 @@ type DAGVM interface {
 	// Retrieve a transaction that was submitted previously
 	GetTx(ids.ID) (snowstorm.Tx, error)
 }
+
+// TxIndexer describes the functionality that allows the transactions accepted
+// by an avalanche VM to be retrieved in the order they were accepted
+type TxIndexer interface {
+	// Returns the number of transactions that have been accepted
+	NumAcceptedTxs() (uint64, error)
+
+	// Returns the ID of the transaction accepted at [index]
+	GetTxIDAtIndex(index uint64) (ids.ID, error)
+}
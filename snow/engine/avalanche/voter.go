@@ -35,7 +35,7 @@ func (v *voter) Update() {
 		return
 	}
 
-	v.t.Config.Context.Log.Debug("Finishing poll with:\n%s", &results)
+	v.t.Config.Context.ConsensusLog.Debug("Finishing poll with:\n%s", &results)
 	v.t.Consensus.RecordPoll(results)
 
 	txs := []snowstorm.Tx(nil)
@@ -43,20 +43,20 @@ func (v *voter) Update() {
 		if tx, err := v.t.Config.VM.GetTx(orphanID); err == nil {
 			txs = append(txs, tx)
 		} else {
-			v.t.Config.Context.Log.Warn("Failed to fetch %s during attempted re-issuance", orphanID)
+			v.t.Config.Context.ConsensusLog.Warn("Failed to fetch %s during attempted re-issuance", orphanID)
 		}
 	}
 	if len(txs) > 0 {
-		v.t.Config.Context.Log.Debug("Re-issuing %d transactions", len(txs))
+		v.t.Config.Context.ConsensusLog.Debug("Re-issuing %d transactions", len(txs))
 	}
 	v.t.batch(txs, true /*=force*/, false /*empty*/)
 
 	if v.t.Consensus.Quiesce() {
-		v.t.Config.Context.Log.Verbo("Avalanche engine can quiesce")
+		v.t.Config.Context.ConsensusLog.Verbo("Avalanche engine can quiesce")
 		return
 	}
 
-	v.t.Config.Context.Log.Verbo("Avalanche engine can't quiesce")
+	v.t.Config.Context.ConsensusLog.Verbo("Avalanche engine can't quiesce")
 
 	if len(v.t.polls.m) == 0 {
 		v.t.repoll()
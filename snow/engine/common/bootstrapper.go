@@ -5,8 +5,57 @@ package common
 
 import (
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/retry"
 )
 
+const (
+	// MaxContainersPerMultiPut is the maximum number of containers sent in
+	// response to a GetAncestors message
+	MaxContainersPerMultiPut = 2000
+
+	// MaxContainersLen is the maximum total size, in bytes, of the containers
+	// sent in response to a GetAncestors message
+	MaxContainersLen = 1 << 21
+)
+
+var (
+	// FetchWarnAttempts is the number of failed requests for a container
+	// after which a bootstrapper warns that it can't fetch it. It only sets
+	// when the warning is logged: the container is still requested again, as
+	// bootstrapping can't finish without it, and the warning is repeated each
+	// time the number of failures doubles.
+	FetchWarnAttempts = 64
+
+	// fetchRetryConfig is how a container is re-requested after a failed
+	// request. It's retried without a limit, and without a delay, as the
+	// requests are already paced by the timeout of each request.
+	fetchRetryConfig = retry.Config{MaxAttempts: 0}
+
+	// MaxOutstandingFetches is the maximum number of GetAncestors requests
+	// that a bootstrapper keeps outstanding with each validator. Containers
+	// are fetched from several validators in parallel, and the containers
+	// that can't be requested yet are queued until a response arrives.
+	MaxOutstandingFetches = 4
+)
+
+// fetchFailure tracks the failed requests for a container
+type fetchFailure struct {
+	backoff *retry.Backoff
+
+	// Validators that failed to send the container since it was last
+	// requested from every validator. The container isn't requested from
+	// them again until every other validator has failed too.
+	validators ids.ShortSet
+}
+
+// fetch is an outstanding GetAncestors request, or a Get request if the
+// validator is [legacy]
+type fetch struct {
+	validatorID ids.ShortID
+	containerID ids.ID
+	legacy      bool
+}
+
 // Bootstrapper implements the Engine interface.
 type Bootstrapper struct {
 	Config
@@ -17,12 +66,27 @@ type Bootstrapper struct {
 	pendingAccepted ids.ShortSet
 	accepted        ids.Bag
 
+	// fetchFailures tracks the failed requests for each container
+	fetchFailures map[[32]byte]*fetchFailure
+
+	fetching   ids.Set          // Containers that are requested or queued to be requested
+	toFetch    []ids.ID         // Containers waiting for a validator to be requested from
+	fetches    map[uint32]fetch // Request ID --> outstanding GetAncestors request
+	numFetches map[[20]byte]int // Validator ID --> number of outstanding requests
+
+	// Validators that failed to answer a GetAncestors request. They may run a
+	// version that doesn't support GetAncestors, so they're sent Get requests,
+	// and their Put responses are handled as a MultiPut of one container.
+	legacy ids.ShortSet
+
 	RequestID uint32
 }
 
 // Initialize implements the Engine interface.
 func (b *Bootstrapper) Initialize(config Config) {
 	b.Config = config
+	b.fetches = make(map[uint32]fetch)
+	b.numFetches = make(map[[20]byte]int)
 
 	for _, vdr := range b.Beacons.List() {
 		vdrID := vdr.ID()
@@ -33,10 +97,194 @@ func (b *Bootstrapper) Initialize(config Config) {
 	b.accepted.SetThreshold(config.Alpha)
 }
 
+// Fetched records that [containerID] was fetched, so its failed requests are
+// forgotten
+func (b *Bootstrapper) Fetched(containerID ids.ID) {
+	b.fetching.Remove(containerID)
+	delete(b.fetchFailures, containerID.Key())
+}
+
+// Fetch requests [containerID] and its ancestors, unless it's already being
+// fetched
+func (b *Bootstrapper) Fetch(containerID ids.ID) {
+	if b.fetching.Contains(containerID) {
+		return
+	}
+	numVdrs := b.Validators.Len()
+	if b.Validators.Contains(b.Context.NodeID) {
+		numVdrs-- // Containers can't be fetched from this node
+	}
+	if numVdrs == 0 {
+		// The container stays queued, as bootstrapping can't finish without it
+		b.Context.ConsensusLog.Warn("Can't request %s as there are no validators", containerID)
+	}
+
+	b.fetching.Add(containerID)
+	b.toFetch = append(b.toFetch, containerID)
+	b.sendFetches()
+}
+
+// Fetching returns whether [containerID] is requested or queued to be
+func (b *Bootstrapper) Fetching(containerID ids.ID) bool { return b.fetching.Contains(containerID) }
+
+// NumFetching returns the number of containers that are requested or queued to
+// be
+func (b *Bootstrapper) NumFetching() int { return b.fetching.Len() }
+
+// FetchResponded records that [validatorID] responded to the GetAncestors or
+// Get request [requestID]. It returns the ID of the requested container, and
+// false if the request wasn't outstanding.
+func (b *Bootstrapper) FetchResponded(validatorID ids.ShortID, requestID uint32) (ids.ID, bool) {
+	req, ok := b.removeFetch(validatorID, requestID)
+	if !ok {
+		return ids.ID{}, false
+	}
+
+	// The validator has room for another request
+	b.sendFetches()
+	return req.containerID, true
+}
+
+// FetchFailed records that [validatorID] failed to respond to the GetAncestors
+// or Get request [requestID]. A validator that fails a GetAncestors request is
+// sent Get requests from then on, until one of those fails too. It returns the
+// ID of the requested container, and false if the request wasn't outstanding.
+func (b *Bootstrapper) FetchFailed(validatorID ids.ShortID, requestID uint32) (ids.ID, bool) {
+	req, ok := b.removeFetch(validatorID, requestID)
+	if !ok {
+		return ids.ID{}, false
+	}
+	if req.legacy {
+		b.legacy.Remove(validatorID)
+	} else {
+		b.legacy.Add(validatorID)
+	}
+
+	// The validator has room for another request
+	b.sendFetches()
+	return req.containerID, true
+}
+
+// fetchFailed records that [validatorID] failed to send [containerID]
+func (b *Bootstrapper) fetchFailed(containerID ids.ID, validatorID ids.ShortID) {
+	if b.fetchFailures == nil {
+		b.fetchFailures = make(map[[32]byte]*fetchFailure)
+	}
+
+	key := containerID.Key()
+	failure, exists := b.fetchFailures[key]
+	if !exists {
+		failure = &fetchFailure{backoff: retry.NewBackoff(fetchRetryConfig)}
+		b.fetchFailures[key] = failure
+	}
+	failure.validators.Add(validatorID)
+
+	failure.backoff.Next() // Never runs out of attempts, so the container is always requested again
+	if attempts := failure.backoff.Attempts(); attempts >= FetchWarnAttempts && attempts&(attempts-1) == 0 {
+		b.Context.ConsensusLog.Warn("Failed to fetch %s after %d attempts. Bootstrapping can't finish until it's fetched", containerID, attempts)
+	}
+}
+
+// removeFetch removes the outstanding request [requestID] to [validatorID]
+func (b *Bootstrapper) removeFetch(validatorID ids.ShortID, requestID uint32) (fetch, bool) {
+	req, exists := b.fetches[requestID]
+	if !exists || !req.validatorID.Equals(validatorID) {
+		return fetch{}, false
+	}
+	delete(b.fetches, requestID)
+
+	key := validatorID.Key()
+	if b.numFetches[key]--; b.numFetches[key] == 0 {
+		delete(b.numFetches, key)
+	}
+	return req, true
+}
+
+// Refetch requests [containerID] again after [validatorID] failed to send it.
+// The container is never given up on, as bootstrapping can't finish without
+// it. It's requested from the validators that haven't failed to send it yet,
+// so every validator is tried before any is asked twice.
+func (b *Bootstrapper) Refetch(validatorID ids.ShortID, containerID ids.ID) {
+	if !b.fetching.Contains(containerID) {
+		return
+	}
+	b.fetchFailed(containerID, validatorID)
+	b.toFetch = append(b.toFetch, containerID)
+	b.sendFetches()
+}
+
+// sendFetches requests the queued containers from the validators that have
+// fewer than MaxOutstandingFetches outstanding requests
+func (b *Bootstrapper) sendFetches() {
+	for len(b.toFetch) > 0 {
+		containerID := b.toFetch[0]
+		if !b.fetching.Contains(containerID) {
+			// The container arrived, as an ancestor of another container,
+			// while it was queued
+			b.toFetch = b.toFetch[1:]
+			continue
+		}
+
+		validatorID, ok := b.fetchValidator(containerID)
+		if !ok {
+			return
+		}
+		b.toFetch = b.toFetch[1:]
+
+		b.RequestID++
+		legacy := b.legacy.Contains(validatorID)
+		b.fetches[b.RequestID] = fetch{
+			validatorID: validatorID,
+			containerID: containerID,
+			legacy:      legacy,
+		}
+		b.numFetches[validatorID.Key()]++
+		if legacy {
+			b.Sender.Get(validatorID, b.RequestID, containerID)
+		} else {
+			b.Sender.GetAncestors(validatorID, b.RequestID, containerID)
+		}
+	}
+}
+
+// fetchValidator returns the validator to request [containerID] from, or false
+// if every validator it can be requested from has MaxOutstandingFetches
+// outstanding requests. Validators that failed to send the container are
+// skipped until every validator has failed, and then they're all tried again.
+// Among the others, the validator with the fewest outstanding requests is
+// returned. Validators are sampled by stake, which breaks ties.
+func (b *Bootstrapper) fetchValidator(containerID ids.ID) (ids.ShortID, bool) {
+	failure, exists := b.fetchFailures[containerID.Key()]
+	if !exists {
+		failure = &fetchFailure{}
+	}
+	failed := failure.validators
+
+	vdrs := b.Validators.Sample(b.Validators.Len())
+	best, bestFetches, untried := ids.ShortID{}, MaxOutstandingFetches, false
+	for _, vdr := range vdrs {
+		vdrID := vdr.ID()
+		if vdrID.Equals(b.Context.NodeID) || failed.Contains(vdrID) {
+			continue
+		}
+		untried = true
+		if numFetches := b.numFetches[vdrID.Key()]; numFetches < bestFetches {
+			best, bestFetches = vdrID, numFetches
+		}
+	}
+	if !untried && failed.Len() > 0 {
+		// Every validator failed to send the container, so start another
+		// round through all of them
+		failure.validators.Clear()
+		return b.fetchValidator(containerID)
+	}
+	return best, bestFetches < MaxOutstandingFetches
+}
+
 // Startup implements the Engine interface.
 func (b *Bootstrapper) Startup() {
 	if b.pendingAcceptedFrontier.Len() == 0 {
-		b.Context.Log.Info("Bootstrapping skipped due to no provided bootstraps")
+		b.Context.ConsensusLog.Info("Bootstrapping skipped due to no provided bootstraps")
 		b.Bootstrapable.ForceAccepted(ids.Set{})
 		return
 	}
@@ -61,7 +309,7 @@ func (b *Bootstrapper) GetAcceptedFrontierFailed(validatorID ids.ShortID, reques
 // AcceptedFrontier implements the Engine interface.
 func (b *Bootstrapper) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
 	if !b.pendingAcceptedFrontier.Contains(validatorID) {
-		b.Context.Log.Debug("Received an AcceptedFrontier message from %s unexpectedly", validatorID)
+		b.Context.ConsensusLog.Debug("Received an AcceptedFrontier message from %s unexpectedly", validatorID)
 		return
 	}
 	b.pendingAcceptedFrontier.Remove(validatorID)
@@ -90,7 +338,7 @@ func (b *Bootstrapper) GetAcceptedFailed(validatorID ids.ShortID, requestID uint
 // Accepted implements the Engine interface.
 func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
 	if !b.pendingAccepted.Contains(validatorID) {
-		b.Context.Log.Debug("Received an Accepted message from %s unexpectedly", validatorID)
+		b.Context.ConsensusLog.Debug("Received an Accepted message from %s unexpectedly", validatorID)
 		return
 	}
 	b.pendingAccepted.Remove(validatorID)
@@ -100,11 +348,39 @@ func (b *Bootstrapper) Accepted(validatorID ids.ShortID, requestID uint32, conta
 	if b.pendingAccepted.Len() == 0 {
 		accepted := b.accepted.Threshold()
 		if size := accepted.Len(); size == 0 && b.Config.Beacons.Len() > 0 {
-			b.Context.Log.Warn("Bootstrapping finished with no accepted frontier. This is likely a result of failing to be able to connect to the specified bootstraps, or no transactions have been issued on this network yet")
+			b.Context.ConsensusLog.Warn("Bootstrapping finished with no accepted frontier. This is likely a result of failing to be able to connect to the specified bootstraps, or no transactions have been issued on this network yet")
 		} else {
-			b.Context.Log.Info("Bootstrapping finished with %d vertices in the accepted frontier", size)
+			b.Context.ConsensusLog.Info("Bootstrapping finished with %d vertices in the accepted frontier", size)
 		}
 
 		b.Bootstrapable.ForceAccepted(accepted)
 	}
 }
+
+// GetStateSummaries implements the Engine interface. By default, no state
+// summaries are advertised.
+func (b *Bootstrapper) GetStateSummaries(validatorID ids.ShortID, requestID uint32) {
+	b.Sender.StateSummaries(validatorID, requestID, nil)
+}
+
+// StateSummaries implements the Engine interface.
+func (b *Bootstrapper) StateSummaries(validatorID ids.ShortID, requestID uint32, _ [][]byte) {
+	b.Context.ConsensusLog.Debug("Received a StateSummaries message from %s unexpectedly", validatorID)
+}
+
+// GetStateSummariesFailed implements the Engine interface.
+func (b *Bootstrapper) GetStateSummariesFailed(ids.ShortID, uint32) {}
+
+// GetStateChunk implements the Engine interface. By default, no state chunks
+// are served.
+func (b *Bootstrapper) GetStateChunk(validatorID ids.ShortID, _ uint32, chunkID ids.ID) {
+	b.Context.ConsensusLog.Debug("Dropping request from %s for state chunk %s as state sync isn't supported", validatorID, chunkID)
+}
+
+// StateChunk implements the Engine interface.
+func (b *Bootstrapper) StateChunk(validatorID ids.ShortID, _ uint32, chunkID ids.ID, _ []byte) {
+	b.Context.ConsensusLog.Debug("Received state chunk %s from %s unexpectedly", chunkID, validatorID)
+}
+
+// GetStateChunkFailed implements the Engine interface.
+func (b *Bootstrapper) GetStateChunkFailed(ids.ShortID, uint32, ids.ID) {}
@@ -29,6 +29,7 @@ type ExternalHandler interface {
 	AcceptedHandler
 	FetchHandler
 	QueryHandler
+	StateSyncHandler
 }
 
 // FrontierHandler defines how a consensus engine reacts to frontier messages
@@ -80,6 +81,19 @@ type FetchHandler interface {
 
 	// Notify this engine that a get request it issued has failed.
 	GetFailed(validatorID ids.ShortID, requestID uint32, containerID ids.ID)
+
+	// GetAncestors notifies this consensus engine that the specified validator
+	// requested that this engine send the specified container and as many of
+	// its ancestors as fit in the response
+	GetAncestors(validatorID ids.ShortID, requestID uint32, containerID ids.ID)
+
+	// MultiPut notifies this consensus engine of the requested container and
+	// its ancestors, in the order they were sent: the requested container
+	// first, followed by its ancestors
+	MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte)
+
+	// Notify this engine that a GetAncestors request it issued has failed.
+	GetAncestorsFailed(validatorID ids.ShortID, requestID uint32)
 }
 
 // QueryHandler defines how a consensus engine reacts to query messages from
@@ -106,6 +120,37 @@ type QueryHandler interface {
 	QueryFailed(validatorID ids.ShortID, requestID uint32)
 }
 
+// StateSyncHandler defines how a consensus engine reacts to state sync
+// messages from other validators
+type StateSyncHandler interface {
+	// GetStateSummaries notifies this consensus engine that the specified
+	// validator requested the summaries of the state this engine's VM can
+	// serve
+	GetStateSummaries(validatorID ids.ShortID, requestID uint32)
+
+	// StateSummaries notifies this consensus engine of the state summaries
+	// advertised by the specified validator
+	StateSummaries(validatorID ids.ShortID, requestID uint32, summaries [][]byte)
+
+	// GetStateSummariesFailed notifies this consensus engine that the state
+	// summaries requested from the specified validator should be considered
+	// lost
+	GetStateSummariesFailed(validatorID ids.ShortID, requestID uint32)
+
+	// GetStateChunk notifies this consensus engine that the specified
+	// validator requested that this engine send the specified chunk of state
+	// to it
+	GetStateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID)
+
+	// StateChunk notifies this consensus engine of the chunk of state with the
+	// specified ID and body
+	StateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte)
+
+	// GetStateChunkFailed notifies this consensus engine that a state chunk
+	// request it issued has failed
+	GetStateChunkFailed(validatorID ids.ShortID, requestID uint32, chunkID ids.ID)
+}
+
 // InternalHandler defines how this consensus engine reacts to messages from
 // other components of this validator
 type InternalHandler interface {
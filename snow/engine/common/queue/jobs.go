@@ -17,7 +17,10 @@ var (
 	errDuplicate = errors.New("duplicated container")
 )
 
-// Jobs ...
+// Jobs is a queue of jobs that are executed once their dependencies have
+// been executed. The queue is stored in a database, and changes are persisted
+// when they're committed, so a queue can be resumed after a restart by calling
+// New with the same database.
 type Jobs struct {
 	parser Parser
 	baseDB database.Database
@@ -77,6 +80,31 @@ func (j *Jobs) HasNext() (bool, error) {
 	return size > 0, err
 }
 
+// Has returns whether the job [jobID] was pushed to the queue
+func (j *Jobs) Has(jobID ids.ID) (bool, error) { return j.state.HasJob(j.db, jobID) }
+
+// MissingDependencies returns the IDs of the jobs that queued jobs are blocked
+// on, but that weren't pushed to the queue. When a queue is resumed after a
+// restart, these are the jobs that still need to be fetched.
+func (j *Jobs) MissingDependencies() (ids.Set, error) {
+	it := j.db.NewIteratorWithPrefix([]byte{blockingID})
+	defer it.Release()
+
+	missing := ids.Set{}
+	for it.Next() {
+		depID, err := ids.ToID(it.Key()[1:])
+		if err != nil {
+			return nil, err
+		}
+		if has, err := j.Has(depID); err != nil {
+			return nil, err
+		} else if !has {
+			missing.Add(depID)
+		}
+	}
+	return missing, it.Error()
+}
+
 // Execute ...
 func (j *Jobs) Execute(job Job) error {
 	job.Execute()
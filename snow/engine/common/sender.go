@@ -14,6 +14,7 @@ type Sender interface {
 	AcceptedSender
 	FetchSender
 	QuerySender
+	StateSyncSender
 }
 
 // FrontierSender defines how a consensus engine sends frontier messages to
@@ -52,6 +53,15 @@ type FetchSender interface {
 	// Tell the specified validator that the container whose ID is <containerID>
 	// has body <container>
 	Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte)
+
+	// GetAncestors requests that the specified validator sends the container
+	// whose ID is [containerID] and as many of its ancestors as fit in the
+	// response
+	GetAncestors(validatorID ids.ShortID, requestID uint32, containerID ids.ID)
+
+	// MultiPut responds to a GetAncestors message with the requested container
+	// followed by its ancestors
+	MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte)
 }
 
 // QuerySender defines how a consensus engine sends query messages to other
@@ -70,3 +80,23 @@ type QuerySender interface {
 	// Chits sends chits to the specified validator
 	Chits(validatorID ids.ShortID, requestID uint32, votes ids.Set)
 }
+
+// StateSyncSender defines how a consensus engine sends state sync messages to
+// other validators
+type StateSyncSender interface {
+	// GetStateSummaries requests that every validator in [validatorIDs] sends
+	// a StateSummaries message with the summaries of the state it can serve.
+	GetStateSummaries(validatorIDs ids.ShortSet, requestID uint32)
+
+	// StateSummaries responds to a GetStateSummaries message with the
+	// summaries of the state this engine's VM can serve.
+	StateSummaries(validatorID ids.ShortID, requestID uint32, summaries [][]byte)
+
+	// GetStateChunk requests that the specified validator sends the chunk of
+	// state whose ID is [chunkID].
+	GetStateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID)
+
+	// StateChunk responds to a GetStateChunk message with the chunk of state
+	// whose ID is [chunkID].
+	StateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte)
+}
@@ -36,7 +36,19 @@ type EngineTest struct {
 	CantPushQuery,
 	CantPullQuery,
 	CantQueryFailed,
-	CantChits bool
+	CantChits,
+
+	CantGetStateSummaries,
+	CantGetStateSummariesFailed,
+	CantStateSummaries,
+
+	CantGetStateChunk,
+	CantGetStateChunkFailed,
+	CantStateChunk,
+
+	CantGetAncestors,
+	CantGetAncestorsFailed,
+	CantMultiPut bool
 
 	StartupF, ShutdownF                                                                func()
 	ContextF                                                                           func() *snow.Context
@@ -45,6 +57,15 @@ type EngineTest struct {
 	PutF, PushQueryF                                                                   func(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte)
 	GetAcceptedFrontierF, GetAcceptedFrontierFailedF, GetAcceptedFailedF, QueryFailedF func(validatorID ids.ShortID, requestID uint32)
 	AcceptedFrontierF, GetAcceptedF, AcceptedF, ChitsF                                 func(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set)
+
+	GetStateSummariesF, GetStateSummariesFailedF func(validatorID ids.ShortID, requestID uint32)
+	StateSummariesF                              func(validatorID ids.ShortID, requestID uint32, summaries [][]byte)
+	GetStateChunkF, GetStateChunkFailedF         func(validatorID ids.ShortID, requestID uint32, chunkID ids.ID)
+	StateChunkF                                  func(validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte)
+
+	GetAncestorsF       func(validatorID ids.ShortID, requestID uint32, containerID ids.ID)
+	GetAncestorsFailedF func(validatorID ids.ShortID, requestID uint32)
+	MultiPutF           func(validatorID ids.ShortID, requestID uint32, containers [][]byte)
 }
 
 // Default ...
@@ -72,6 +93,18 @@ func (e *EngineTest) Default(cant bool) {
 	e.CantPullQuery = cant
 	e.CantQueryFailed = cant
 	e.CantChits = cant
+
+	e.CantGetStateSummaries = cant
+	e.CantGetStateSummariesFailed = cant
+	e.CantStateSummaries = cant
+
+	e.CantGetStateChunk = cant
+	e.CantGetStateChunkFailed = cant
+	e.CantStateChunk = cant
+
+	e.CantGetAncestors = cant
+	e.CantGetAncestorsFailed = cant
+	e.CantMultiPut = cant
 }
 
 // Startup ...
@@ -228,3 +261,84 @@ func (e *EngineTest) Chits(validatorID ids.ShortID, requestID uint32, containerI
 		e.T.Fatalf("Unexpectedly called Chits")
 	}
 }
+
+// GetStateSummaries ...
+func (e *EngineTest) GetStateSummaries(validatorID ids.ShortID, requestID uint32) {
+	if e.GetStateSummariesF != nil {
+		e.GetStateSummariesF(validatorID, requestID)
+	} else if e.CantGetStateSummaries && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateSummaries")
+	}
+}
+
+// GetStateSummariesFailed ...
+func (e *EngineTest) GetStateSummariesFailed(validatorID ids.ShortID, requestID uint32) {
+	if e.GetStateSummariesFailedF != nil {
+		e.GetStateSummariesFailedF(validatorID, requestID)
+	} else if e.CantGetStateSummariesFailed && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateSummariesFailed")
+	}
+}
+
+// StateSummaries ...
+func (e *EngineTest) StateSummaries(validatorID ids.ShortID, requestID uint32, summaries [][]byte) {
+	if e.StateSummariesF != nil {
+		e.StateSummariesF(validatorID, requestID, summaries)
+	} else if e.CantStateSummaries && e.T != nil {
+		e.T.Fatalf("Unexpectedly called StateSummaries")
+	}
+}
+
+// GetStateChunk ...
+func (e *EngineTest) GetStateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID) {
+	if e.GetStateChunkF != nil {
+		e.GetStateChunkF(validatorID, requestID, chunkID)
+	} else if e.CantGetStateChunk && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// GetStateChunkFailed ...
+func (e *EngineTest) GetStateChunkFailed(validatorID ids.ShortID, requestID uint32, chunkID ids.ID) {
+	if e.GetStateChunkFailedF != nil {
+		e.GetStateChunkFailedF(validatorID, requestID, chunkID)
+	} else if e.CantGetStateChunkFailed && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetStateChunkFailed")
+	}
+}
+
+// StateChunk ...
+func (e *EngineTest) StateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	if e.StateChunkF != nil {
+		e.StateChunkF(validatorID, requestID, chunkID, chunk)
+	} else if e.CantStateChunk && e.T != nil {
+		e.T.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
+// GetAncestors ...
+func (e *EngineTest) GetAncestors(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
+	if e.GetAncestorsF != nil {
+		e.GetAncestorsF(validatorID, requestID, containerID)
+	} else if e.CantGetAncestors && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetAncestors")
+	}
+}
+
+// GetAncestorsFailed ...
+func (e *EngineTest) GetAncestorsFailed(validatorID ids.ShortID, requestID uint32) {
+	if e.GetAncestorsFailedF != nil {
+		e.GetAncestorsFailedF(validatorID, requestID)
+	} else if e.CantGetAncestorsFailed && e.T != nil {
+		e.T.Fatalf("Unexpectedly called GetAncestorsFailed")
+	}
+}
+
+// MultiPut ...
+func (e *EngineTest) MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte) {
+	if e.MultiPutF != nil {
+		e.MultiPutF(validatorID, requestID, containers)
+	} else if e.CantMultiPut && e.T != nil {
+		e.T.Fatalf("Unexpectedly called MultiPut")
+	}
+}
@@ -16,7 +16,10 @@ type SenderTest struct {
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
 	CantGet, CantPut,
-	CantPullQuery, CantPushQuery, CantChits bool
+	CantPullQuery, CantPushQuery, CantChits,
+	CantGetStateSummaries, CantStateSummaries,
+	CantGetStateChunk, CantStateChunk,
+	CantGetAncestors, CantMultiPut bool
 
 	GetAcceptedFrontierF func(ids.ShortSet, uint32)
 	AcceptedFrontierF    func(ids.ShortID, uint32, ids.Set)
@@ -27,6 +30,12 @@ type SenderTest struct {
 	PushQueryF           func(ids.ShortSet, uint32, ids.ID, []byte)
 	PullQueryF           func(ids.ShortSet, uint32, ids.ID)
 	ChitsF               func(ids.ShortID, uint32, ids.Set)
+	GetStateSummariesF   func(ids.ShortSet, uint32)
+	StateSummariesF      func(ids.ShortID, uint32, [][]byte)
+	GetStateChunkF       func(ids.ShortID, uint32, ids.ID)
+	StateChunkF          func(ids.ShortID, uint32, ids.ID, []byte)
+	GetAncestorsF        func(ids.ShortID, uint32, ids.ID)
+	MultiPutF            func(ids.ShortID, uint32, [][]byte)
 }
 
 // Default set the default callable value to [cant]
@@ -40,6 +49,12 @@ func (s *SenderTest) Default(cant bool) {
 	s.CantPullQuery = cant
 	s.CantPushQuery = cant
 	s.CantChits = cant
+	s.CantGetStateSummaries = cant
+	s.CantStateSummaries = cant
+	s.CantGetStateChunk = cant
+	s.CantStateChunk = cant
+	s.CantGetAncestors = cant
+	s.CantMultiPut = cant
 }
 
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
@@ -140,3 +155,69 @@ func (s *SenderTest) Chits(vdr ids.ShortID, requestID uint32, votes ids.Set) {
 		s.T.Fatalf("Unexpectedly called Chits")
 	}
 }
+
+// GetStateSummaries calls GetStateSummariesF if it was initialized. If it
+// wasn't initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) GetStateSummaries(vdrs ids.ShortSet, requestID uint32) {
+	if s.GetStateSummariesF != nil {
+		s.GetStateSummariesF(vdrs, requestID)
+	} else if s.CantGetStateSummaries && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateSummaries")
+	}
+}
+
+// StateSummaries calls StateSummariesF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) StateSummaries(vdr ids.ShortID, requestID uint32, summaries [][]byte) {
+	if s.StateSummariesF != nil {
+		s.StateSummariesF(vdr, requestID, summaries)
+	} else if s.CantStateSummaries && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateSummaries")
+	}
+}
+
+// GetStateChunk calls GetStateChunkF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) GetStateChunk(vdr ids.ShortID, requestID uint32, chunkID ids.ID) {
+	if s.GetStateChunkF != nil {
+		s.GetStateChunkF(vdr, requestID, chunkID)
+	} else if s.CantGetStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// StateChunk calls StateChunkF if it was initialized. If it wasn't initialized
+// and this function shouldn't be called and testing was initialized, then
+// testing will fail.
+func (s *SenderTest) StateChunk(vdr ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	if s.StateChunkF != nil {
+		s.StateChunkF(vdr, requestID, chunkID, chunk)
+	} else if s.CantStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
+// GetAncestors calls GetAncestorsF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *SenderTest) GetAncestors(vdr ids.ShortID, requestID uint32, containerID ids.ID) {
+	if s.GetAncestorsF != nil {
+		s.GetAncestorsF(vdr, requestID, containerID)
+	} else if s.CantGetAncestors && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetAncestors")
+	}
+}
+
+// MultiPut calls MultiPutF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *SenderTest) MultiPut(vdr ids.ShortID, requestID uint32, containers [][]byte) {
+	if s.MultiPutF != nil {
+		s.MultiPutF(vdr, requestID, containers)
+	} else if s.CantMultiPut && s.T != nil {
+		s.T.Fatalf("Unexpectedly called MultiPut")
+	}
+}
@@ -5,6 +5,8 @@ package common
 
 import (
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/migration"
+	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
 )
 
@@ -73,3 +75,41 @@ type StaticVM interface {
 	// genesis bytes this VM can interpret.
 	CreateStaticHandlers() map[string]*HTTPHandler
 }
+
+// StateHasher describes the functionality that allows the state of a VM to be
+// compared with the state of another instance of the VM, such as when a chain
+// is replayed.
+type StateHasher interface {
+	// Returns a digest of the state of the VM after its last accepted
+	// container. Two instances of the VM that accepted the same containers
+	// must return the same digest.
+	StateHash() (ids.ID, error)
+}
+
+// MigratableVM describes the functionality that allows a VM to change the
+// format of its database. The migrations the VM's database hasn't been through
+// are run before the VM is initialized.
+type MigratableVM interface {
+	// Returns the migrations of the VM's database, sorted by version
+	Migrations() []migration.Migration
+}
+
+// VersionedVM describes the functionality that allows a VM to declare its
+// version and the rule changes it implements. A chain can only schedule the
+// rule changes that its VM implements.
+type VersionedVM interface {
+	// Returns the version of the VM
+	Version() string
+
+	// Returns the names of the rule changes this version of the VM implements.
+	// The VM checks whether a rule change is active with the schedule in its
+	// context.
+	Upgrades() []string
+}
+
+// SubscribableVM describes the functionality that allows clients to subscribe
+// to the containers a VM accepts by the addresses and assets they involve.
+type SubscribableVM interface {
+	// Returns the addresses and the asset IDs of the outputs of [container]
+	ContainerSubjects(container []byte) (addresses []ids.ShortID, assets []ids.ID, err error)
+}
@@ -7,12 +7,14 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common/queue"
 )
 
 type parser struct {
+	ctx                     *snow.Context
 	numAccepted, numDropped prometheus.Counter
 	vm                      ChainVM
 }
@@ -23,6 +25,7 @@ func (p *parser) Parse(blkBytes []byte) (queue.Job, error) {
 		return nil, err
 	}
 	return &blockJob{
+		ctx:         p.ctx,
 		numAccepted: p.numAccepted,
 		numDropped:  p.numDropped,
 		blk:         blk,
@@ -30,6 +33,7 @@ func (p *parser) Parse(blkBytes []byte) (queue.Job, error) {
 }
 
 type blockJob struct {
+	ctx                     *snow.Context
 	numAccepted, numDropped prometheus.Counter
 	blk                     snowman.Block
 }
@@ -54,6 +58,10 @@ func (b *blockJob) Execute() {
 		if err := b.blk.Verify(); err == nil {
 			b.blk.Accept()
 			b.numAccepted.Inc()
+
+			// Only decisions are reported, so blocks accepted while
+			// bootstrapping aren't gossiped to the network
+			b.ctx.DecisionDispatcher.Accept(b.ctx.ChainID, b.blk.ID(), b.blk.Bytes())
 		} else {
 			b.numDropped.Inc()
 		}
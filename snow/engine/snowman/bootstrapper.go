@@ -30,9 +30,23 @@ type bootstrapper struct {
 	metrics
 	common.Bootstrapper
 
-	pending    ids.Set
+	stored     ids.Set // Blocks that were queued to be executed
 	finished   bool
 	onFinished func()
+
+	// State sync, which is only attempted if the VM supports it
+	stateSyncVM      StateSyncableVM
+	pendingSummaries ids.ShortSet
+	summaryVotes     ids.Bag
+	summaries        map[[32]byte]*StateSummary // summary ID --> summary
+	summaryVdrs      map[[32]byte]ids.ShortSet  // summary ID --> validators that advertised it
+
+	syncing       *StateSummary // The summary of the state being fetched
+	chunkVdrs     []ids.ShortID // The validators chunks are fetched from
+	nextChunkVdr  int
+	pendingChunks ids.Set
+	chunks        map[[32]byte][]byte // chunk ID --> chunk
+	chunkFailures map[[32]byte]int    // chunk ID --> number of failed requests
 }
 
 // Initialize this engine.
@@ -40,6 +54,7 @@ func (b *bootstrapper) Initialize(config BootstrapConfig) {
 	b.BootstrapConfig = config
 
 	b.Blocked.SetParser(&parser{
+		ctx:         b.BootstrapConfig.Context,
 		numAccepted: b.numBootstrapped,
 		numDropped:  b.numDropped,
 		vm:          b.VM,
@@ -69,72 +84,100 @@ func (b *bootstrapper) FilterAccepted(containerIDs ids.Set) ids.Set {
 
 // ForceAccepted ...
 func (b *bootstrapper) ForceAccepted(acceptedContainerIDs ids.Set) {
+	// Blocks queued before a restart don't need to be fetched again, but
+	// their missing ancestors do
+	b.fetchMissing()
 	for _, blkID := range acceptedContainerIDs.List() {
 		b.fetch(blkID)
 	}
 
-	if numPending := b.pending.Len(); numPending == 0 {
+	if numPending := b.NumFetching(); numPending == 0 {
 		// TODO: This typically indicates bootstrapping has failed, so this
 		// should be handled appropriately
 		b.finish()
 	}
 }
 
-// Put ...
-func (b *bootstrapper) Put(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkBytes []byte) {
-	b.BootstrapConfig.Context.Log.Verbo("Put called for blkID %s", blkID)
+// MultiPut ...
+func (b *bootstrapper) MultiPut(vdr ids.ShortID, requestID uint32, blks [][]byte) {
+	blkID, ok := b.FetchResponded(vdr, requestID)
+	if !ok {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("Received an unexpected MultiPut from %s with requestID %d", vdr, requestID)
+		return
+	}
+	b.BootstrapConfig.Context.ConsensusLog.Verbo("MultiPut called for blkID %s with %d blocks", blkID, len(blks))
 
-	if !b.pending.Contains(blkID) {
+	if len(blks) == 0 {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("MultiPut from %s didn't contain %s", vdr, blkID)
+		b.Refetch(vdr, blkID)
 		return
 	}
 
-	blk, err := b.VM.ParseBlock(blkBytes)
+	blk, err := b.VM.ParseBlock(blks[0])
 	if err != nil {
-		b.BootstrapConfig.Context.Log.Warn("ParseBlock failed due to %s for block:\n%s",
+		b.BootstrapConfig.Context.ConsensusLog.Warn("ParseBlock failed due to %s for block:\n%s",
 			err,
-			formatting.DumpBytes{Bytes: blkBytes})
-		b.GetFailed(vdr, requestID, blkID)
+			formatting.DumpBytes{Bytes: blks[0]})
+		b.Refetch(vdr, blkID)
+		return
+	}
+	if !blk.ID().Equals(blkID) {
+		b.BootstrapConfig.Context.ConsensusLog.Warn("MultiPut from %s contained %s rather than the requested %s", vdr, blk.ID(), blkID)
+		b.Refetch(vdr, blkID)
 		return
 	}
 
+	// Parse the ancestors, so they're known when the block's ancestry is
+	// walked. Only the chain of parents of the requested block is parsed.
+	child := blk
+	for _, ancestorBytes := range blks[1:] {
+		ancestor, err := b.VM.ParseBlock(ancestorBytes)
+		if err != nil || !ancestor.ID().Equals(child.Parent().ID()) {
+			break
+		}
+		child = ancestor
+	}
+
+	b.Fetched(blkID)
 	b.addBlock(blk)
 }
 
+// GetAncestorsFailed ...
+func (b *bootstrapper) GetAncestorsFailed(vdr ids.ShortID, requestID uint32) {
+	if blkID, ok := b.FetchFailed(vdr, requestID); ok {
+		b.Refetch(vdr, blkID)
+	}
+}
+
+// Put handles the response of a validator that was sent a Get request
+// because it may not support GetAncestors
+func (b *bootstrapper) Put(vdr ids.ShortID, requestID uint32, _ ids.ID, blkBytes []byte) {
+	b.MultiPut(vdr, requestID, [][]byte{blkBytes})
+}
+
 // GetFailed ...
-func (b *bootstrapper) GetFailed(_ ids.ShortID, _ uint32, blkID ids.ID) { b.sendRequest(blkID) }
+func (b *bootstrapper) GetFailed(vdr ids.ShortID, requestID uint32, _ ids.ID) {
+	b.GetAncestorsFailed(vdr, requestID)
+}
 
 func (b *bootstrapper) fetch(blkID ids.ID) {
-	if b.pending.Contains(blkID) {
+	if b.Fetching(blkID) || b.queued(blkID) {
 		return
 	}
 
 	blk, err := b.VM.GetBlock(blkID)
 	if err != nil {
-		b.sendRequest(blkID)
+		b.Fetch(blkID)
+		b.numPendingRequests.Set(float64(b.NumFetching()))
 		return
 	}
 	b.storeBlock(blk)
 }
 
-func (b *bootstrapper) sendRequest(blkID ids.ID) {
-	validators := b.BootstrapConfig.Validators.Sample(1)
-	if len(validators) == 0 {
-		b.BootstrapConfig.Context.Log.Error("Dropping request for %s as there are no validators", blkID)
-		return
-	}
-	validatorID := validators[0].ID()
-	b.RequestID++
-
-	b.pending.Add(blkID)
-	b.BootstrapConfig.Sender.Get(validatorID, b.RequestID, blkID)
-
-	b.numPendingRequests.Set(float64(b.pending.Len()))
-}
-
 func (b *bootstrapper) addBlock(blk snowman.Block) {
 	b.storeBlock(blk)
 
-	if numPending := b.pending.Len(); numPending == 0 {
+	if numPending := b.NumFetching(); numPending == 0 {
 		b.finish()
 	}
 }
@@ -143,9 +186,18 @@ func (b *bootstrapper) storeBlock(blk snowman.Block) {
 	status := blk.Status()
 	blkID := blk.ID()
 	for status == choices.Processing {
-		b.pending.Remove(blkID)
+		if b.queued(blkID) {
+			// Responses to parallel requests may overlap, and blocks may have
+			// been queued before a restart, in which case this block and its
+			// ancestors were already stored
+			b.numPendingRequests.Set(float64(b.NumFetching()))
+			return
+		}
+		b.stored.Add(blkID)
+		b.Fetched(blkID)
 
 		if err := b.Blocked.Push(&blockJob{
+			ctx:         b.BootstrapConfig.Context,
 			numAccepted: b.numBootstrapped,
 			numDropped:  b.numDropped,
 			blk:         blk,
@@ -160,17 +212,33 @@ func (b *bootstrapper) storeBlock(blk snowman.Block) {
 
 	switch status := blk.Status(); status {
 	case choices.Unknown:
-		b.sendRequest(blkID)
+		b.Fetch(blkID)
 	case choices.Accepted:
-		b.BootstrapConfig.Context.Log.Verbo("Bootstrapping confirmed %s", blkID)
+		b.BootstrapConfig.Context.ConsensusLog.Verbo("Bootstrapping confirmed %s", blkID)
 	case choices.Rejected:
-		b.BootstrapConfig.Context.Log.Error("Bootstrapping wants to accept %s, however it was previously rejected", blkID)
+		b.BootstrapConfig.Context.ConsensusLog.Error("Bootstrapping wants to accept %s, however it was previously rejected", blkID)
+	}
+
+	// Persist the queued blocks, so they don't need to be fetched again if
+	// the node restarts before bootstrapping finishes
+	if err := b.Blocked.Commit(); err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("Failed to commit the bootstrapping queue due to %s", err)
 	}
 
-	numPending := b.pending.Len()
+	numPending := b.NumFetching()
 	b.numPendingRequests.Set(float64(numPending))
 }
 
+// queued returns whether [blkID] was queued to be executed, possibly before a
+// restart
+func (b *bootstrapper) queued(blkID ids.ID) bool {
+	if b.stored.Contains(blkID) {
+		return true
+	}
+	has, err := b.Blocked.Has(blkID)
+	return err == nil && has
+}
+
 func (b *bootstrapper) finish() {
 	if b.finished {
 		return
@@ -178,6 +246,12 @@ func (b *bootstrapper) finish() {
 
 	b.executeAll(b.Blocked, b.numBlocked)
 
+	// Blocks left in the queue are blocked on an ancestor that wasn't
+	// fetched, so bootstrapping continues until it is
+	if b.fetchMissing() {
+		return
+	}
+
 	// Start consensus
 	b.onFinished()
 	b.finished = true
@@ -187,11 +261,29 @@ func (b *bootstrapper) finish() {
 	}
 }
 
+// fetchMissing fetches the ancestors that queued blocks are blocked on, but
+// that weren't queued. It returns true if any blocks are being fetched.
+func (b *bootstrapper) fetchMissing() bool {
+	missingIDs, err := b.Blocked.MissingDependencies()
+	if err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("Failed to read the bootstrapping queue due to %s", err)
+	}
+	for _, blkID := range missingIDs.List() {
+		b.fetch(blkID)
+	}
+	return b.NumFetching() > 0
+}
+
 func (b *bootstrapper) executeAll(jobs *queue.Jobs, numBlocked prometheus.Gauge) {
 	for job, err := jobs.Pop(); err == nil; job, err = jobs.Pop() {
 		numBlocked.Dec()
 		if err := jobs.Execute(job); err != nil {
-			b.BootstrapConfig.Context.Log.Warn("Error executing: %s", err)
+			b.BootstrapConfig.Context.ConsensusLog.Warn("Error executing: %s", err)
+		}
+		// Executing a job twice is a no-op, so it's safe for the VM to
+		// persist a job's result before the queue records its execution
+		if err := jobs.Commit(); err != nil {
+			b.BootstrapConfig.Context.ConsensusLog.Error("Failed to commit the bootstrapping queue due to %s", err)
 		}
 	}
 }
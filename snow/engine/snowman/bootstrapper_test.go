@@ -22,6 +22,7 @@ import (
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
 	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/hashing"
 )
 
 var (
@@ -113,7 +114,7 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 	}
 
 	reqID := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, innerReqID uint32, blkID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, innerReqID uint32, blkID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested block from %s, requested from %s", peerID, vdr)
 		}
@@ -129,7 +130,7 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	vm.GetBlockF = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	vm.ParseBlockF = func(blkBytes []byte) (snowman.Block, error) {
 		switch {
@@ -143,7 +144,7 @@ func TestBootstrapperSingleFrontier(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *reqID, blkID1, blkBytes1)
+	bs.MultiPut(peerID, *reqID, [][]byte{blkBytes1})
 
 	vm.ParseBlockF = nil
 	bs.onFinished = nil
@@ -206,7 +207,7 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	}
 
 	requestID := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested block from %s, requested from %s", peerID, vdr)
 		}
@@ -222,12 +223,13 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	vm.GetBlockF = nil
-	sender.GetF = nil
 
 	vm.ParseBlockF = func(blkBytes []byte) (snowman.Block, error) {
 		switch {
 		case bytes.Equal(blkBytes, blkBytes1):
 			return blk1, nil
+		case bytes.Equal(blkBytes, blkBytes2):
+			return blk2, nil
 		}
 		t.Fatal(errUnknownBlock)
 		return nil, errUnknownBlock
@@ -236,8 +238,16 @@ func TestBootstrapperUnknownByzantineResponse(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *requestID, blkID2, blkBytes2)
-	bs.Put(peerID, *requestID, blkID1, blkBytes1)
+	// The wrong block should cause the requested block to be requested again
+	oldRequestID := *requestID
+	bs.MultiPut(peerID, *requestID, [][]byte{blkBytes2})
+	if *requestID == oldRequestID {
+		t.Fatalf("Should have requested the block again")
+	}
+
+	sender.GetAncestorsF = nil
+
+	bs.MultiPut(peerID, *requestID, [][]byte{blkBytes1})
 
 	vm.ParseBlockF = nil
 
@@ -302,7 +312,7 @@ func TestBootstrapperDependency(t *testing.T) {
 	}
 
 	requestID := new(uint32)
-	sender.GetF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, vtxID ids.ID) {
 		if !vdr.Equals(peerID) {
 			t.Fatalf("Should have requested block from %s, requested from %s", peerID, vdr)
 		}
@@ -318,7 +328,7 @@ func TestBootstrapperDependency(t *testing.T) {
 	bs.ForceAccepted(acceptedIDs)
 
 	vm.GetBlockF = nil
-	sender.GetF = nil
+	sender.GetAncestorsF = nil
 
 	vm.ParseBlockF = func(blkBytes []byte) (snowman.Block, error) {
 		switch {
@@ -336,7 +346,7 @@ func TestBootstrapperDependency(t *testing.T) {
 	finished := new(bool)
 	bs.onFinished = func() { *finished = true }
 
-	bs.Put(peerID, *requestID, blkID1, blkBytes1)
+	bs.MultiPut(peerID, *requestID, [][]byte{blkBytes1})
 
 	if !*finished {
 		t.Fatalf("Bootstrapping should have finished")
@@ -463,7 +473,7 @@ func TestBootstrapperPartialFetch(t *testing.T) {
 		}
 	}
 
-	sender.CantGet = false
+	sender.CantGetAncestors = false
 	bs.onFinished = func() {}
 
 	bs.ForceAccepted(acceptedIDs)
@@ -472,7 +482,628 @@ func TestBootstrapperPartialFetch(t *testing.T) {
 		t.Fatalf("should have requested a block")
 	}
 
-	if bs.pending.Len() != 1 {
+	if bs.NumFetching() != 1 {
 		t.Fatalf("wrong number pending")
 	}
 }
+
+func TestBootstrapperPersistsQueue(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	db := memdb.New()
+	blocked, err := queue.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Blocked = blocked
+
+	blkID0 := ids.Empty.Prefix(0)
+	blkID1 := ids.Empty.Prefix(1)
+	blkID2 := ids.Empty.Prefix(2)
+
+	blkBytes1 := []byte{1}
+
+	blk0 := &Blk{
+		id:     blkID0,
+		height: 0,
+		status: choices.Accepted,
+	}
+	blk1 := &Blk{
+		parent: blk0,
+		id:     blkID1,
+		height: 1,
+		status: choices.Processing,
+		bytes:  blkBytes1,
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	acceptedIDs := ids.Set{}
+	acceptedIDs.Add(blkID1, blkID2)
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) { return nil, errUnknownBlock }
+
+	requests := map[[32]byte]uint32{}
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, blkID ids.ID) {
+		requests[blkID.Key()] = reqID
+	}
+	bs.onFinished = func() {}
+
+	bs.ForceAccepted(acceptedIDs)
+
+	vm.ParseBlockF = func(blkBytes []byte) (snowman.Block, error) {
+		if !bytes.Equal(blkBytes, blkBytes1) {
+			t.Fatal(errUnknownBlock)
+		}
+		return blk1, nil
+	}
+
+	// Only one of the blocks arrives before the node restarts
+	bs.MultiPut(peerID, requests[blkID1.Key()], [][]byte{blkBytes1})
+
+	if bs.finished {
+		t.Fatalf("Bootstrapping shouldn't have finished")
+	}
+
+	restarted, err := queue.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.SetParser(&parser{
+		ctx:         config.Context,
+		numAccepted: bs.numBootstrapped,
+		numDropped:  bs.numDropped,
+		vm:          vm,
+	})
+	if hasNext, err := restarted.HasNext(); err != nil {
+		t.Fatal(err)
+	} else if !hasNext {
+		t.Fatalf("The queued block should have been persisted")
+	}
+	job, err := restarted.Pop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.ID().Equals(blkID1) {
+		t.Fatalf("Should have persisted %s but persisted %s", blkID1, job.ID())
+	}
+}
+
+func TestBootstrapperResumesQueue(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	db := memdb.New()
+	blocked, err := queue.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Blocked = blocked
+
+	blkID0 := ids.Empty.Prefix(0)
+	blkID1 := ids.Empty.Prefix(1)
+	blkID2 := ids.Empty.Prefix(2)
+
+	blkBytes1 := []byte{1}
+	blkBytes2 := []byte{2}
+
+	blk0 := &Blk{
+		id:     blkID0,
+		height: 0,
+		status: choices.Accepted,
+	}
+	blk1 := &Blk{
+		parent: blk0,
+		id:     blkID1,
+		height: 1,
+		status: choices.Unknown,
+		bytes:  blkBytes1,
+	}
+	blk2 := &Blk{
+		parent: blk1,
+		id:     blkID2,
+		height: 2,
+		status: choices.Processing,
+		bytes:  blkBytes2,
+	}
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) { return nil, errUnknownBlock }
+	vm.ParseBlockF = func(blkBytes []byte) (snowman.Block, error) {
+		switch {
+		case bytes.Equal(blkBytes, blkBytes1):
+			blk1.status = choices.Processing
+			return blk1, nil
+		case bytes.Equal(blkBytes, blkBytes2):
+			return blk2, nil
+		}
+		t.Fatal(errUnknownBlock)
+		panic(errUnknownBlock)
+	}
+
+	requests := map[[32]byte]uint32{}
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, blkID ids.ID) {
+		requests[blkID.Key()] = reqID
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+	bs.onFinished = func() {}
+
+	acceptedIDs := ids.Set{}
+	acceptedIDs.Add(blkID2)
+
+	bs.ForceAccepted(acceptedIDs)
+
+	// The block arrives, but the node restarts before its parent does
+	bs.MultiPut(peerID, requests[blkID2.Key()], [][]byte{blkBytes2})
+	if _, requested := requests[blkID1.Key()]; !requested {
+		t.Fatalf("Should have requested the parent of the queued block")
+	}
+
+	restarted, err := queue.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Blocked = restarted
+	requests = map[[32]byte]uint32{}
+
+	bs = bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+	finished := new(bool)
+	bs.onFinished = func() { *finished = true }
+
+	bs.ForceAccepted(acceptedIDs)
+
+	if _, requested := requests[blkID2.Key()]; requested {
+		t.Fatalf("Shouldn't have requested a block that was queued before the restart")
+	}
+	reqID, requested := requests[blkID1.Key()]
+	if !requested {
+		t.Fatalf("Should have requested the missing parent of the queued block")
+	}
+	if len(requests) != 1 {
+		t.Fatalf("Should have only requested %s", blkID1)
+	}
+
+	bs.MultiPut(peerID, reqID, [][]byte{blkBytes1})
+
+	switch {
+	case !*finished:
+		t.Fatalf("Bootstrapping should have finished")
+	case blk1.Status() != choices.Accepted:
+		t.Fatalf("Block should be accepted")
+	case blk2.Status() != choices.Accepted:
+		t.Fatalf("Block should be accepted")
+	}
+}
+
+func TestBootstrapperParallelFetch(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	otherPeer := validators.GenerateRandomValidator(1)
+	otherPeerID := otherPeer.ID()
+	config.Validators.Add(otherPeer)
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	acceptedIDs := ids.Set{}
+	numBlks := 3*common.MaxOutstandingFetches - 1
+	for i := 0; i < numBlks; i++ {
+		acceptedIDs.Add(ids.Empty.Prefix(uint64(i)))
+	}
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) { return nil, errUnknownBlock }
+
+	requests := map[[20]byte][]uint32{}
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, blkID ids.ID) {
+		if !vdr.Equals(peerID) && !vdr.Equals(otherPeerID) {
+			t.Fatalf("Requested block from unknown validator %s", vdr)
+		}
+		requests[vdr.Key()] = append(requests[vdr.Key()], reqID)
+	}
+	bs.onFinished = func() {}
+
+	bs.ForceAccepted(acceptedIDs)
+
+	if numFetching := bs.NumFetching(); numFetching != numBlks {
+		t.Fatalf("Should be fetching %d blocks but is fetching %d", numBlks, numFetching)
+	}
+	for _, vdrID := range []ids.ShortID{peerID, otherPeerID} {
+		if numReqs := len(requests[vdrID.Key()]); numReqs != common.MaxOutstandingFetches {
+			t.Fatalf("Should have sent %d requests to %s but sent %d", common.MaxOutstandingFetches, vdrID, numReqs)
+		}
+	}
+
+	// A failed request frees up the validator for a queued block. As the
+	// validator may not support GetAncestors, the block is requested with Get.
+	getReqs := []uint32(nil)
+	getBlkIDs := []ids.ID(nil)
+	sender.GetF = func(vdr ids.ShortID, reqID uint32, blkID ids.ID) {
+		if !vdr.Equals(peerID) {
+			t.Fatalf("Should have sent Get to %s but sent it to %s", peerID, vdr)
+		}
+		getReqs = append(getReqs, reqID)
+		getBlkIDs = append(getBlkIDs, blkID)
+	}
+	bs.GetAncestorsFailed(peerID, requests[peerID.Key()][0])
+	if numReqs := len(requests[peerID.Key()]); numReqs != common.MaxOutstandingFetches {
+		t.Fatalf("Should have sent %d GetAncestors requests to %s but sent %d", common.MaxOutstandingFetches, peerID, numReqs)
+	}
+	if len(getReqs) != 1 {
+		t.Fatalf("Should have sent %d Get requests to %s but sent %d", 1, peerID, len(getReqs))
+	}
+
+	// The Put response to the Get request is handled like a MultiPut
+	blkBytes := []byte{1}
+	blk := &Blk{
+		parent: &Blk{id: ids.Empty.Prefix(uint64(numBlks)), status: choices.Accepted},
+		id:     getBlkIDs[0],
+		height: 1,
+		status: choices.Processing,
+		bytes:  blkBytes,
+	}
+	vm.ParseBlockF = func(b []byte) (snowman.Block, error) {
+		if !bytes.Equal(b, blkBytes) {
+			t.Fatalf("Parsed unexpected bytes")
+		}
+		return blk, nil
+	}
+	numFetching := bs.NumFetching()
+	bs.Put(peerID, getReqs[0], blk.ID(), blkBytes)
+	if bs.Fetching(blk.ID()) || bs.NumFetching() != numFetching-1 {
+		t.Fatalf("Should have fetched the block with Get")
+	}
+}
+
+func TestBootstrapperStateSync(t *testing.T) {
+	config, peerID, sender, _ := newConfig(t)
+
+	vm := &StateSyncableVMTest{}
+	vm.T = t
+	vm.Default(true)
+	config.VM = vm
+
+	chunks := [][]byte{{1}, {2, 3}}
+	chunkIDs := []ids.ID{
+		ids.NewID(hashing.ComputeHash256Array(chunks[0])),
+		ids.NewID(hashing.ComputeHash256Array(chunks[1])),
+	}
+	summary, err := NewStateSummary(5, ids.Empty.Prefix(5), chunkIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	vm.GetBlockIDAtHeightF = func(uint64) (ids.ID, error) { return ids.ID{}, errUnknownBlock }
+
+	reqID := new(uint32)
+	sender.GetStateSummariesF = func(vdrs ids.ShortSet, innerReqID uint32) {
+		if !vdrs.Contains(peerID) {
+			t.Fatalf("Should have requested state summaries from %s", peerID)
+		}
+		*reqID = innerReqID
+	}
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) {
+		t.Fatalf("Should have state synced before bootstrapping")
+	}
+
+	bs.Startup()
+
+	chunkReqs := make(map[[32]byte]uint32)
+	sender.GetStateChunkF = func(vdr ids.ShortID, innerReqID uint32, chunkID ids.ID) {
+		if !vdr.Equals(peerID) {
+			t.Fatalf("Should have requested chunk from %s, requested from %s", peerID, vdr)
+		}
+		chunkReqs[chunkID.Key()] = innerReqID
+	}
+
+	bs.StateSummaries(peerID, *reqID, [][]byte{summary.Bytes(), {0}})
+
+	if len(chunkReqs) != len(chunkIDs) {
+		t.Fatalf("Should have requested %d chunks, requested %d", len(chunkIDs), len(chunkReqs))
+	}
+
+	// A chunk that doesn't match its ID is requested again
+	chunkReqID := chunkReqs[chunkIDs[0].Key()]
+	delete(chunkReqs, chunkIDs[0].Key())
+	bs.StateChunk(peerID, chunkReqID, chunkIDs[0], chunks[1])
+	if _, ok := chunkReqs[chunkIDs[0].Key()]; !ok {
+		t.Fatalf("Should have requested the chunk again")
+	}
+
+	synced := new(bool)
+	vm.SyncStateF = func(syncSummary *StateSummary, syncChunks [][]byte) error {
+		if !syncSummary.ID().Equals(summary.ID()) {
+			t.Fatalf("Synced to the wrong summary")
+		}
+		for i, chunk := range chunks {
+			if !bytes.Equal(syncChunks[i], chunk) {
+				t.Fatalf("Chunk %d should be %v but is %v", i, chunk, syncChunks[i])
+			}
+		}
+		*synced = true
+		return nil
+	}
+	bootstrapping := new(bool)
+	sender.GetAcceptedFrontierF = func(vdrs ids.ShortSet, _ uint32) {
+		if !*synced {
+			t.Fatalf("Should have state synced before bootstrapping")
+		}
+		*bootstrapping = true
+	}
+
+	for i, chunkID := range chunkIDs {
+		bs.StateChunk(peerID, chunkReqs[chunkID.Key()], chunkID, chunks[i])
+	}
+
+	if !*synced {
+		t.Fatalf("Should have state synced")
+	}
+	if !*bootstrapping {
+		t.Fatalf("Should have started bootstrapping from the synced block")
+	}
+}
+
+func TestBootstrapperStateSyncChunkFailures(t *testing.T) {
+	config, peerID, sender, _ := newConfig(t)
+
+	vm := &StateSyncableVMTest{}
+	vm.T = t
+	vm.Default(true)
+	config.VM = vm
+
+	chunkIDs := []ids.ID{
+		ids.NewID(hashing.ComputeHash256Array([]byte{1})),
+		ids.NewID(hashing.ComputeHash256Array([]byte{2})),
+	}
+	summary, err := NewStateSummary(5, ids.Empty.Prefix(5), chunkIDs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	vm.GetBlockIDAtHeightF = func(uint64) (ids.ID, error) { return ids.ID{}, errUnknownBlock }
+
+	reqID := new(uint32)
+	sender.GetStateSummariesF = func(_ ids.ShortSet, innerReqID uint32) { *reqID = innerReqID }
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) {
+		t.Fatalf("Should have state synced before bootstrapping")
+	}
+
+	bs.Startup()
+
+	type chunkReq struct {
+		reqID   uint32
+		chunkID ids.ID
+	}
+	chunkReqs := []chunkReq(nil)
+	sender.GetStateChunkF = func(_ ids.ShortID, innerReqID uint32, chunkID ids.ID) {
+		chunkReqs = append(chunkReqs, chunkReq{reqID: innerReqID, chunkID: chunkID})
+	}
+	vm.SyncStateF = func(*StateSummary, [][]byte) error {
+		t.Fatalf("Shouldn't have synced the state without its chunks")
+		return nil
+	}
+	bootstrapping := new(bool)
+	sender.GetAcceptedFrontierF = func(ids.ShortSet, uint32) { *bootstrapping = true }
+
+	bs.StateSummaries(peerID, *reqID, [][]byte{summary.Bytes()})
+
+	// Every beacon fails every chunk request
+	for numFailed := 0; numFailed < len(chunkReqs); numFailed++ {
+		req := chunkReqs[numFailed]
+		bs.GetStateChunkFailed(peerID, req.reqID, req.chunkID)
+	}
+
+	if !*bootstrapping {
+		t.Fatalf("Should have abandoned state sync and started bootstrapping")
+	}
+	// Each chunk is requested from the beacon [maxChunkRequestsPerVdr] times
+	if expected := maxChunkRequestsPerVdr * len(chunkIDs); len(chunkReqs) != expected {
+		t.Fatalf("Should have requested chunks %d times, requested %d times", expected, len(chunkReqs))
+	}
+	if bs.pendingChunks.Len() != 0 {
+		t.Fatalf("Shouldn't be fetching chunks after abandoning state sync")
+	}
+}
+
+func TestBootstrapperServeStateSync(t *testing.T) {
+	config, peerID, sender, _ := newConfig(t)
+
+	vm := &StateSyncableVMTest{}
+	vm.T = t
+	vm.Default(true)
+	config.VM = vm
+
+	chunk := []byte{1, 2, 3}
+	chunkID := ids.NewID(hashing.ComputeHash256Array(chunk))
+	summary, err := NewStateSummary(1, ids.Empty.Prefix(1), []ids.ID{chunkID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	vm.StateSummariesF = func() ([]*StateSummary, error) { return []*StateSummary{summary}, nil }
+	sentSummaries := new(bool)
+	sender.StateSummariesF = func(vdr ids.ShortID, reqID uint32, summaries [][]byte) {
+		switch {
+		case !vdr.Equals(peerID):
+			t.Fatalf("Should have sent state summaries to %s, sent to %s", peerID, vdr)
+		case reqID != 7:
+			t.Fatalf("Should have responded to request 7, responded to %d", reqID)
+		case len(summaries) != 1:
+			t.Fatalf("Should have sent 1 summary, sent %d", len(summaries))
+		}
+		parsed, err := ParseStateSummary(summaries[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.ID().Equals(summary.ID()) || parsed.Height != summary.Height || len(parsed.ChunkIDs) != 1 {
+			t.Fatalf("Sent the wrong summary")
+		}
+		*sentSummaries = true
+	}
+
+	bs.GetStateSummaries(peerID, 7)
+	if !*sentSummaries {
+		t.Fatalf("Should have sent the state summaries")
+	}
+
+	vm.GetStateChunkF = func(id ids.ID) ([]byte, error) {
+		if id.Equals(chunkID) {
+			return chunk, nil
+		}
+		return nil, errUnknownBlock
+	}
+	sentChunk := new(bool)
+	sender.StateChunkF = func(vdr ids.ShortID, reqID uint32, id ids.ID, sent []byte) {
+		if !id.Equals(chunkID) || !bytes.Equal(sent, chunk) {
+			t.Fatalf("Sent the wrong chunk")
+		}
+		*sentChunk = true
+	}
+
+	bs.GetStateChunk(peerID, 8, ids.Empty)
+	if *sentChunk {
+		t.Fatalf("Shouldn't have sent an unknown chunk")
+	}
+	bs.GetStateChunk(peerID, 8, chunkID)
+	if !*sentChunk {
+		t.Fatalf("Should have sent the chunk")
+	}
+}
+
+func TestBootstrapperRetryFetch(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	otherPeer := validators.GenerateRandomValidator(1)
+	otherPeerID := otherPeer.ID()
+	config.Validators.Add(otherPeer)
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	blkID := ids.Empty.Prefix(0)
+	acceptedIDs := ids.Set{}
+	acceptedIDs.Add(blkID)
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) { return nil, errUnknownBlock }
+
+	vdrs := []ids.ShortID(nil)
+	reqIDs := []uint32(nil)
+	request := func(vdr ids.ShortID, reqID uint32, reqBlkID ids.ID) {
+		if !reqBlkID.Equals(blkID) {
+			t.Fatalf("Requested unexpected block %s", reqBlkID)
+		}
+		vdrs = append(vdrs, vdr)
+		reqIDs = append(reqIDs, reqID)
+	}
+	sender.GetAncestorsF = request
+	sender.GetF = request
+	bs.onFinished = func() { t.Fatalf("Bootstrapping finished without the block") }
+
+	bs.ForceAccepted(acceptedIDs)
+
+	// The block is requested again after every failure, from the validator
+	// that hasn't failed to send it yet, and is never given up on
+	for i := 0; i < 2*common.FetchWarnAttempts; i++ {
+		if len(reqIDs) != i+1 {
+			t.Fatalf("Should have sent %d requests but sent %d", i+1, len(reqIDs))
+		}
+		if i%2 == 1 && vdrs[i].Equals(vdrs[i-1]) {
+			t.Fatalf("Should have requested the block from the other validator after %s failed", vdrs[i-1])
+		}
+		if vdr := vdrs[i]; !vdr.Equals(peerID) && !vdr.Equals(otherPeerID) {
+			t.Fatalf("Requested block from unknown validator %s", vdr)
+		}
+		bs.GetAncestorsFailed(vdrs[i], reqIDs[i])
+		if !bs.Fetching(blkID) {
+			t.Fatalf("Should still be fetching the block after %d failures", i+1)
+		}
+	}
+}
+
+func TestBootstrapperKeepsMissingBlock(t *testing.T) {
+	config, peerID, sender, vm := newConfig(t)
+
+	bs := bootstrapper{}
+	bs.metrics.Initialize(config.Context.Log, fmt.Sprintf("gecko_%s", config.Context.ChainID), prometheus.NewRegistry())
+	bs.Initialize(config)
+
+	blkID := ids.Empty.Prefix(0)
+	acceptedIDs := ids.Set{}
+	acceptedIDs.Add(blkID)
+
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) { return nil, errUnknownBlock }
+
+	reqIDs := []uint32(nil)
+	sender.GetAncestorsF = func(vdr ids.ShortID, reqID uint32, reqBlkID ids.ID) {
+		if !vdr.Equals(peerID) || !reqBlkID.Equals(blkID) {
+			t.Fatalf("Requested unexpected block %s from %s", reqBlkID, vdr)
+		}
+		reqIDs = append(reqIDs, reqID)
+	}
+	finished := false
+	bs.onFinished = func() { finished = true }
+
+	bs.ForceAccepted(acceptedIDs)
+
+	// An empty response doesn't drop the block, and doesn't finish
+	// bootstrapping without it
+	for i := 0; i < 3; i++ {
+		bs.MultiPut(peerID, reqIDs[i], nil)
+		if finished {
+			t.Fatalf("Bootstrapping finished without the block")
+		}
+		if !bs.Fetching(blkID) {
+			t.Fatalf("Should still be fetching the block")
+		}
+		if len(reqIDs) != i+2 {
+			t.Fatalf("Should have requested the block again")
+		}
+	}
+
+	blkBytes := []byte{1}
+	blk := &Blk{
+		parent: &Blk{id: ids.Empty.Prefix(1), status: choices.Accepted},
+		id:     blkID,
+		height: 1,
+		status: choices.Processing,
+		bytes:  blkBytes,
+	}
+	vm.ParseBlockF = func(b []byte) (snowman.Block, error) {
+		if !bytes.Equal(b, blkBytes) {
+			t.Fatalf("Parsed unexpected bytes")
+		}
+		return blk, nil
+	}
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) {
+		if blkID.Equals(blk.ID()) {
+			return blk, nil
+		}
+		return nil, errUnknownBlock
+	}
+
+	bs.MultiPut(peerID, reqIDs[len(reqIDs)-1], [][]byte{blkBytes})
+	if !finished {
+		t.Fatalf("Bootstrapping should have finished")
+	}
+	if blk.Status() != choices.Accepted {
+		t.Fatalf("Block should be accepted")
+	}
+}
@@ -14,6 +14,10 @@ type metrics struct {
 	numBootstrapped, numDropped    prometheus.Counter
 
 	numPolls, numBlkRequests, numBlockedBlk prometheus.Gauge
+
+	pollDuration                             prometheus.Histogram
+	numVotes, numFailedVotes                 prometheus.Counter
+	numSuccessfulPolls, numUnsuccessfulPolls prometheus.Counter
 }
 
 // Initialize implements the Engine interface
@@ -60,6 +64,37 @@ func (m *metrics) Initialize(log logging.Logger, namespace string, registerer pr
 			Name:      "sm_blocked_blks",
 			Help:      "Number of blocked vertices",
 		})
+	m.pollDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "sm_poll_duration",
+			Help:      "Time from a network poll being sent to it finishing, in seconds",
+			Buckets:   prometheus.ExponentialBuckets(.005, 2, 12), // 5ms to 10s
+		})
+	m.numVotes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sm_votes",
+			Help:      "Number of votes received in response to network polls",
+		})
+	m.numFailedVotes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sm_votes_failed",
+			Help:      "Number of network poll queries that failed or timed out",
+		})
+	m.numSuccessfulPolls = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sm_polls_successful",
+			Help:      "Number of finished network polls in which a block received an alpha majority",
+		})
+	m.numUnsuccessfulPolls = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sm_polls_unsuccessful",
+			Help:      "Number of finished network polls in which no block received an alpha majority",
+		})
 
 	if err := registerer.Register(m.numPendingRequests); err != nil {
 		log.Error("Failed to register sm_bs_requests statistics due to %s", err)
@@ -82,4 +117,19 @@ func (m *metrics) Initialize(log logging.Logger, namespace string, registerer pr
 	if err := registerer.Register(m.numBlockedBlk); err != nil {
 		log.Error("Failed to register sm_blocked_blks statistics due to %s", err)
 	}
+	if err := registerer.Register(m.pollDuration); err != nil {
+		log.Error("Failed to register sm_poll_duration statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numVotes); err != nil {
+		log.Error("Failed to register sm_votes statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numFailedVotes); err != nil {
+		log.Error("Failed to register sm_votes_failed statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numSuccessfulPolls); err != nil {
+		log.Error("Failed to register sm_polls_successful statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numUnsuccessfulPolls); err != nil {
+		log.Error("Failed to register sm_polls_unsuccessful statistics due to %s", err)
+	}
 }
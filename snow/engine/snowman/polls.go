@@ -6,6 +6,7 @@ package snowman
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -17,6 +18,10 @@ type polls struct {
 	numPolls prometheus.Gauge
 	alpha    int
 	m        map[uint32]poll
+
+	pollDuration                             prometheus.Histogram
+	numVotes, numFailedVotes                 prometheus.Counter
+	numSuccessfulPolls, numUnsuccessfulPolls prometheus.Counter
 }
 
 // Add to the current set of polls
@@ -27,6 +32,7 @@ func (p *polls) Add(requestID uint32, numPolled int) bool {
 	if !exists {
 		poll.alpha = p.alpha
 		poll.numPolled = numPolled
+		poll.start = time.Now()
 		p.m[requestID] = poll
 
 		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
@@ -42,10 +48,10 @@ func (p *polls) Vote(requestID uint32, vdr ids.ShortID, vote ids.ID) (ids.Bag, b
 	if !exists {
 		return ids.Bag{}, false
 	}
+	p.numVotes.Inc()
 	poll.Vote(vote)
 	if poll.Finished() {
-		delete(p.m, requestID)
-		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
+		p.finish(requestID, poll)
 		return poll.votes, true
 	}
 	p.m[requestID] = poll
@@ -59,17 +65,31 @@ func (p *polls) CancelVote(requestID uint32, vdr ids.ShortID) (ids.Bag, bool) {
 	if !exists {
 		return ids.Bag{}, false
 	}
+	p.numFailedVotes.Inc()
 
 	poll.CancelVote()
 	if poll.Finished() {
-		delete(p.m, requestID)
-		p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
+		p.finish(requestID, poll)
 		return poll.votes, true
 	}
 	p.m[requestID] = poll
 	return ids.Bag{}, false
 }
 
+// finish removes the finished poll [requestID], and tracks its duration and
+// whether a block received an alpha majority
+func (p *polls) finish(requestID uint32, poll poll) {
+	delete(p.m, requestID)
+	p.numPolls.Set(float64(len(p.m))) // Tracks performance statistics
+
+	p.pollDuration.Observe(time.Since(poll.start).Seconds())
+	if _, freq := poll.votes.Mode(); freq >= p.alpha {
+		p.numSuccessfulPolls.Inc()
+	} else {
+		p.numUnsuccessfulPolls.Inc()
+	}
+}
+
 func (p *polls) String() string {
 	sb := strings.Builder{}
 
@@ -86,6 +106,7 @@ type poll struct {
 	alpha     int
 	votes     ids.Bag
 	numPolled int
+	start     time.Time
 }
 
 // Vote registers a vote for this poll
@@ -0,0 +1,98 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowman
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+var (
+	errExtraSpace = errors.New("trailing buffer space")
+)
+
+// StateSummary describes the state of a chain at an accepted block
+type StateSummary struct {
+	id    ids.ID
+	bytes []byte
+
+	// Height of the block the state was summarized at
+	Height uint64
+
+	// ID of the block the state was summarized at
+	BlockID ids.ID
+
+	// IDs of the chunks the state is split into. The ID of a chunk is the
+	// hash of its bytes.
+	ChunkIDs []ids.ID
+}
+
+// NewStateSummary returns the summary of the state at the block [blkID], at
+// [height], that is split into the chunks [chunkIDs]
+func NewStateSummary(height uint64, blkID ids.ID, chunkIDs []ids.ID) (*StateSummary, error) {
+	chunkIDBytes := make([][]byte, len(chunkIDs))
+	for i, chunkID := range chunkIDs {
+		chunkIDBytes[i] = chunkID.Bytes()
+	}
+
+	p := wrappers.Packer{MaxSize: wrappers.LongLen + hashing.HashLen + wrappers.IntLen + len(chunkIDs)*hashing.HashLen}
+	p.PackLong(height)
+	p.PackFixedBytes(blkID.Bytes())
+	p.PackFixedByteSlices(chunkIDBytes)
+	if p.Errored() {
+		return nil, p.Err
+	}
+
+	return &StateSummary{
+		id:       ids.NewID(hashing.ComputeHash256Array(p.Bytes)),
+		bytes:    p.Bytes,
+		Height:   height,
+		BlockID:  blkID,
+		ChunkIDs: chunkIDs,
+	}, nil
+}
+
+// ParseStateSummary parses the summary whose byte representation is [b]
+func ParseStateSummary(b []byte) (*StateSummary, error) {
+	p := wrappers.Packer{Bytes: b}
+	height := p.UnpackLong()
+	blkIDBytes := p.UnpackFixedBytes(hashing.HashLen)
+	chunkIDBytes := p.UnpackFixedByteSlices(hashing.HashLen)
+	if p.Errored() {
+		return nil, p.Err
+	}
+	if p.Offset != len(b) {
+		return nil, errExtraSpace
+	}
+
+	blkID, err := ids.ToID(blkIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	chunkIDs := make([]ids.ID, len(chunkIDBytes))
+	for i, chunkIDB := range chunkIDBytes {
+		chunkID, err := ids.ToID(chunkIDB)
+		if err != nil {
+			return nil, err
+		}
+		chunkIDs[i] = chunkID
+	}
+
+	return &StateSummary{
+		id:       ids.NewID(hashing.ComputeHash256Array(b)),
+		bytes:    b,
+		Height:   height,
+		BlockID:  blkID,
+		ChunkIDs: chunkIDs,
+	}, nil
+}
+
+// ID returns the hash of the byte representation of this summary
+func (s *StateSummary) ID() ids.ID { return s.id }
+
+// Bytes returns the byte representation of this summary
+func (s *StateSummary) Bytes() []byte { return s.bytes }
@@ -0,0 +1,243 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snowman
+
+import (
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// maxChunkRequestsPerVdr is the number of times each validator that advertised
+// the summary being synced is asked for a chunk. Once a chunk's requests have
+// all failed, state sync is abandoned.
+const maxChunkRequestsPerVdr = 2
+
+// Startup implements the Engine interface.
+//
+// If the VM supports state sync and hasn't accepted any blocks past genesis,
+// the beacons are asked for the summaries of the state they can serve. The
+// most recent summary advertised by at least alpha beacons is fetched, and
+// bootstrapping continues from it. Otherwise, bootstrapping starts from the
+// last accepted block.
+func (b *bootstrapper) Startup() {
+	vm, ok := b.VM.(StateSyncableVM)
+	if !ok || b.BootstrapConfig.Beacons.Len() == 0 {
+		b.Bootstrapper.Startup()
+		return
+	}
+	if _, err := vm.GetBlockIDAtHeight(1); err == nil {
+		b.BootstrapConfig.Context.ConsensusLog.Info("State sync skipped as blocks past genesis were already accepted")
+		b.Bootstrapper.Startup()
+		return
+	}
+
+	b.stateSyncVM = vm
+	b.summaries = make(map[[32]byte]*StateSummary)
+	b.summaryVdrs = make(map[[32]byte]ids.ShortSet)
+	b.summaryVotes.SetThreshold(b.BootstrapConfig.Alpha)
+	for _, vdr := range b.BootstrapConfig.Beacons.List() {
+		b.pendingSummaries.Add(vdr.ID())
+	}
+
+	vdrs := ids.ShortSet{}
+	vdrs.Union(b.pendingSummaries)
+
+	b.RequestID++
+	b.BootstrapConfig.Sender.GetStateSummaries(vdrs, b.RequestID)
+}
+
+// GetStateSummaries implements the Engine interface.
+func (b *bootstrapper) GetStateSummaries(vdr ids.ShortID, requestID uint32) {
+	vm, ok := b.VM.(StateSyncableVM)
+	if !ok {
+		b.Bootstrapper.GetStateSummaries(vdr, requestID)
+		return
+	}
+
+	summaries, err := vm.StateSummaries()
+	if err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Warn("Failed to get the state summaries due to %s", err)
+		summaries = nil
+	}
+	summaryBytes := make([][]byte, len(summaries))
+	for i, summary := range summaries {
+		summaryBytes[i] = summary.Bytes()
+	}
+	b.BootstrapConfig.Sender.StateSummaries(vdr, requestID, summaryBytes)
+}
+
+// StateSummaries implements the Engine interface.
+func (b *bootstrapper) StateSummaries(vdr ids.ShortID, requestID uint32, summaries [][]byte) {
+	if !b.pendingSummaries.Contains(vdr) {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("Received a StateSummaries message from %s unexpectedly", vdr)
+		return
+	}
+	b.pendingSummaries.Remove(vdr)
+
+	// Each validator votes at most once for each summary
+	summaryIDs := ids.Set{}
+	for _, summaryBytes := range summaries {
+		summary, err := ParseStateSummary(summaryBytes)
+		if err != nil {
+			b.BootstrapConfig.Context.ConsensusLog.Debug("Failed to parse a state summary from %s due to %s", vdr, err)
+			continue
+		}
+		summaryID := summary.ID()
+		if summaryIDs.Contains(summaryID) {
+			continue
+		}
+		summaryIDs.Add(summaryID)
+
+		key := summaryID.Key()
+		if _, exists := b.summaries[key]; !exists {
+			b.summaries[key] = summary
+		}
+		vdrs := b.summaryVdrs[key]
+		vdrs.Add(vdr)
+		b.summaryVdrs[key] = vdrs
+	}
+	b.summaryVotes.Add(summaryIDs.List()...)
+
+	if b.pendingSummaries.Len() == 0 {
+		b.selectSummary()
+	}
+}
+
+// GetStateSummariesFailed implements the Engine interface.
+func (b *bootstrapper) GetStateSummariesFailed(vdr ids.ShortID, requestID uint32) {
+	b.StateSummaries(vdr, requestID, nil)
+}
+
+// GetStateChunk implements the Engine interface.
+func (b *bootstrapper) GetStateChunk(vdr ids.ShortID, requestID uint32, chunkID ids.ID) {
+	vm, ok := b.VM.(StateSyncableVM)
+	if !ok {
+		b.Bootstrapper.GetStateChunk(vdr, requestID, chunkID)
+		return
+	}
+
+	chunk, err := vm.GetStateChunk(chunkID)
+	if err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("Dropping request from %s for state chunk %s due to %s", vdr, chunkID, err)
+		return
+	}
+	b.BootstrapConfig.Sender.StateChunk(vdr, requestID, chunkID, chunk)
+}
+
+// StateChunk implements the Engine interface.
+func (b *bootstrapper) StateChunk(vdr ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	if b.syncing == nil || !b.pendingChunks.Contains(chunkID) {
+		b.BootstrapConfig.Context.ConsensusLog.Debug("Received state chunk %s from %s unexpectedly", chunkID, vdr)
+		return
+	}
+
+	if hash := ids.NewID(hashing.ComputeHash256Array(chunk)); !hash.Equals(chunkID) {
+		b.BootstrapConfig.Context.ConsensusLog.Warn("Received state chunk from %s with ID %s, but expected %s", vdr, hash, chunkID)
+		b.GetStateChunkFailed(vdr, requestID, chunkID)
+		return
+	}
+
+	b.pendingChunks.Remove(chunkID)
+	b.chunks[chunkID.Key()] = chunk
+
+	if b.pendingChunks.Len() == 0 {
+		b.syncState()
+	}
+}
+
+// GetStateChunkFailed implements the Engine interface.
+func (b *bootstrapper) GetStateChunkFailed(_ ids.ShortID, _ uint32, chunkID ids.ID) {
+	if b.syncing == nil || !b.pendingChunks.Contains(chunkID) {
+		return
+	}
+
+	key := chunkID.Key()
+	b.chunkFailures[key]++
+	if b.chunkFailures[key] >= maxChunkRequestsPerVdr*len(b.chunkVdrs) {
+		b.BootstrapConfig.Context.ConsensusLog.Warn("State sync to block %s abandoned as chunk %s couldn't be fetched after %d requests. Bootstrapping from the last accepted block instead",
+			b.syncing.BlockID,
+			chunkID,
+			b.chunkFailures[key])
+		b.finishStateSync()
+		return
+	}
+	b.fetchChunk(chunkID)
+}
+
+// selectSummary starts fetching the chunks of the most recent state summary
+// that was advertised by at least alpha beacons
+func (b *bootstrapper) selectSummary() {
+	for _, summaryID := range b.summaryVotes.Threshold().List() {
+		summary := b.summaries[summaryID.Key()]
+		if b.syncing == nil || summary.Height > b.syncing.Height {
+			b.syncing = summary
+		}
+	}
+	if b.syncing == nil {
+		b.BootstrapConfig.Context.ConsensusLog.Info("State sync skipped as no state summary was advertised by enough beacons")
+		b.finishStateSync()
+		return
+	}
+
+	b.BootstrapConfig.Context.ConsensusLog.Info("State syncing to block %s at height %d from %d chunks",
+		b.syncing.BlockID,
+		b.syncing.Height,
+		len(b.syncing.ChunkIDs))
+
+	b.chunkVdrs = b.summaryVdrs[b.syncing.ID().Key()].List()
+	b.chunks = make(map[[32]byte][]byte)
+	b.chunkFailures = make(map[[32]byte]int)
+	b.pendingChunks.Add(b.syncing.ChunkIDs...)
+	if b.pendingChunks.Len() == 0 {
+		b.syncState()
+		return
+	}
+	for _, chunkID := range b.pendingChunks.List() {
+		b.fetchChunk(chunkID)
+	}
+}
+
+// fetchChunk requests [chunkID] from the next validator that advertised the
+// summary being synced
+func (b *bootstrapper) fetchChunk(chunkID ids.ID) {
+	vdr := b.chunkVdrs[b.nextChunkVdr%len(b.chunkVdrs)]
+	b.nextChunkVdr++
+
+	b.RequestID++
+	b.BootstrapConfig.Sender.GetStateChunk(vdr, b.RequestID, chunkID)
+}
+
+// syncState passes the fetched chunks to the VM, and then bootstraps from the
+// synced block
+func (b *bootstrapper) syncState() {
+	chunks := make([][]byte, len(b.syncing.ChunkIDs))
+	for i, chunkID := range b.syncing.ChunkIDs {
+		chunks[i] = b.chunks[chunkID.Key()]
+	}
+
+	if err := b.stateSyncVM.SyncState(b.syncing, chunks); err != nil {
+		b.BootstrapConfig.Context.ConsensusLog.Error("State sync to block %s failed due to %s. Bootstrapping from the last accepted block instead",
+			b.syncing.BlockID,
+			err)
+	} else {
+		b.BootstrapConfig.Context.ConsensusLog.Info("State synced to block %s at height %d",
+			b.syncing.BlockID,
+			b.syncing.Height)
+	}
+	b.finishStateSync()
+}
+
+// finishStateSync releases the state sync state and starts bootstrapping
+func (b *bootstrapper) finishStateSync() {
+	b.stateSyncVM = nil
+	b.summaries = nil
+	b.summaryVdrs = nil
+	b.syncing = nil
+	b.chunkVdrs = nil
+	b.pendingChunks.Clear()
+	b.chunks = nil
+	b.chunkFailures = nil
+
+	b.Bootstrapper.Startup()
+}
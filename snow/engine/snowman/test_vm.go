@@ -15,6 +15,12 @@ var (
 	errBuildBlock = errors.New("unexpectedly called BuildBlock")
 	errParseBlock = errors.New("unexpectedly called ParseBlock")
 	errGetBlock   = errors.New("unexpectedly called GetBlock")
+
+	errGetBlockIDAtHeight = errors.New("unexpectedly called GetBlockIDAtHeight")
+
+	errStateSummaries = errors.New("unexpectedly called StateSummaries")
+	errGetStateChunk  = errors.New("unexpectedly called GetStateChunk")
+	errSyncState      = errors.New("unexpectedly called SyncState")
 )
 
 // VMTest ...
@@ -25,13 +31,15 @@ type VMTest struct {
 	CantParseBlock,
 	CantGetBlock,
 	CantSetPreference,
-	CantLastAccepted bool
+	CantLastAccepted,
+	CantGetBlockIDAtHeight bool
 
-	BuildBlockF    func() (snowman.Block, error)
-	ParseBlockF    func([]byte) (snowman.Block, error)
-	GetBlockF      func(ids.ID) (snowman.Block, error)
-	SetPreferenceF func(ids.ID)
-	LastAcceptedF  func() ids.ID
+	BuildBlockF         func() (snowman.Block, error)
+	ParseBlockF         func([]byte) (snowman.Block, error)
+	GetBlockF           func(ids.ID) (snowman.Block, error)
+	SetPreferenceF      func(ids.ID)
+	LastAcceptedF       func() ids.ID
+	GetBlockIDAtHeightF func(uint64) (ids.ID, error)
 }
 
 // Default ...
@@ -43,6 +51,7 @@ func (vm *VMTest) Default(cant bool) {
 	vm.CantGetBlock = cant
 	vm.CantSetPreference = cant
 	vm.CantLastAccepted = cant
+	vm.CantGetBlockIDAtHeight = cant
 }
 
 // BuildBlock ...
@@ -97,3 +106,69 @@ func (vm *VMTest) LastAccepted() ids.ID {
 	}
 	return ids.ID{}
 }
+
+// GetBlockIDAtHeight ...
+func (vm *VMTest) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	if vm.GetBlockIDAtHeightF != nil {
+		return vm.GetBlockIDAtHeightF(height)
+	}
+	if vm.CantGetBlockIDAtHeight && vm.T != nil {
+		vm.T.Fatal(errGetBlockIDAtHeight)
+	}
+	return ids.ID{}, errGetBlockIDAtHeight
+}
+
+// StateSyncableVMTest ...
+type StateSyncableVMTest struct {
+	VMTest
+
+	CantStateSummaries,
+	CantGetStateChunk,
+	CantSyncState bool
+
+	StateSummariesF func() ([]*StateSummary, error)
+	GetStateChunkF  func(ids.ID) ([]byte, error)
+	SyncStateF      func(*StateSummary, [][]byte) error
+}
+
+// Default ...
+func (vm *StateSyncableVMTest) Default(cant bool) {
+	vm.VMTest.Default(cant)
+
+	vm.CantStateSummaries = cant
+	vm.CantGetStateChunk = cant
+	vm.CantSyncState = cant
+}
+
+// StateSummaries ...
+func (vm *StateSyncableVMTest) StateSummaries() ([]*StateSummary, error) {
+	if vm.StateSummariesF != nil {
+		return vm.StateSummariesF()
+	}
+	if vm.CantStateSummaries && vm.T != nil {
+		vm.T.Fatal(errStateSummaries)
+	}
+	return nil, errStateSummaries
+}
+
+// GetStateChunk ...
+func (vm *StateSyncableVMTest) GetStateChunk(chunkID ids.ID) ([]byte, error) {
+	if vm.GetStateChunkF != nil {
+		return vm.GetStateChunkF(chunkID)
+	}
+	if vm.CantGetStateChunk && vm.T != nil {
+		vm.T.Fatal(errGetStateChunk)
+	}
+	return nil, errGetStateChunk
+}
+
+// SyncState ...
+func (vm *StateSyncableVMTest) SyncState(summary *StateSummary, chunks [][]byte) error {
+	if vm.SyncStateF != nil {
+		return vm.SyncStateF(summary, chunks)
+	}
+	if vm.CantSyncState && vm.T != nil {
+		vm.T.Fatal(errSyncState)
+	}
+	return errSyncState
+}
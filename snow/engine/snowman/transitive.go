@@ -30,16 +30,21 @@ type Transitive struct {
 
 // Initialize implements the Engine interface
 func (t *Transitive) Initialize(config Config) {
-	config.Context.Log.Info("Initializing Snowman consensus")
+	config.Context.ConsensusLog.Info("Initializing Snowman consensus")
 
 	t.Config = config
-	t.metrics.Initialize(config.Context.Log, config.Params.Namespace, config.Params.Metrics)
+	t.metrics.Initialize(config.Context.ConsensusLog, config.Params.Namespace, config.Params.Metrics)
 
 	t.onFinished = t.finishBootstrapping
 	t.bootstrapper.Initialize(config.BootstrapConfig)
 
-	t.polls.log = config.Context.Log
+	t.polls.log = config.Context.ConsensusLog
 	t.polls.numPolls = t.numPolls
+	t.polls.pollDuration = t.pollDuration
+	t.polls.numVotes = t.numVotes
+	t.polls.numFailedVotes = t.numFailedVotes
+	t.polls.numSuccessfulPolls = t.numSuccessfulPolls
+	t.polls.numUnsuccessfulPolls = t.numUnsuccessfulPolls
 	t.polls.alpha = t.Params.Alpha
 	t.polls.m = make(map[uint32]poll)
 }
@@ -53,7 +58,7 @@ func (t *Transitive) finishBootstrapping() {
 
 // Shutdown implements the Engine interface
 func (t *Transitive) Shutdown() {
-	t.Config.Context.Log.Info("Shutting down Snowman consensus")
+	t.Config.Context.ConsensusLog.Info("Shutting down Snowman consensus")
 	t.Config.VM.Shutdown()
 }
 
@@ -67,9 +72,35 @@ func (t *Transitive) Get(vdr ids.ShortID, requestID uint32, blkID ids.ID) {
 	}
 }
 
+// GetAncestors implements the Engine interface
+func (t *Transitive) GetAncestors(vdr ids.ShortID, requestID uint32, blkID ids.ID) {
+	blk, err := t.Config.VM.GetBlock(blkID)
+	if err != nil || blk.Status() == choices.Unknown {
+		t.Config.Context.ConsensusLog.Verbo("Dropping GetAncestors for unknown block %s", blkID)
+		return
+	}
+
+	// Send the block followed by its ancestors, newest first
+	blks := [][]byte{}
+	size := 0
+	for len(blks) < common.MaxContainersPerMultiPut && blk.Status() != choices.Unknown {
+		blkBytes := blk.Bytes()
+		if size += len(blkBytes); len(blks) > 0 && size > common.MaxContainersLen {
+			break
+		}
+		blks = append(blks, blkBytes)
+
+		if blk = blk.Parent(); blk == nil {
+			break
+		}
+	}
+
+	t.Config.Sender.MultiPut(vdr, requestID, blks)
+}
+
 // Put implements the Engine interface
 func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkBytes []byte) {
-	t.Config.Context.Log.Verbo("Put called for blockID %s", blkID)
+	t.Config.Context.ConsensusLog.Verbo("Put called for blockID %s", blkID)
 
 	if !t.bootstrapped {
 		t.bootstrapper.Put(vdr, requestID, blkID, blkBytes)
@@ -78,7 +109,7 @@ func (t *Transitive) Put(vdr ids.ShortID, requestID uint32, blkID ids.ID, blkByt
 
 	blk, err := t.Config.VM.ParseBlock(blkBytes)
 	if err != nil {
-		t.Config.Context.Log.Warn("ParseBlock failed due to %s for block:\n%s",
+		t.Config.Context.ConsensusLog.Warn("ParseBlock failed due to %s for block:\n%s",
 			err,
 			formatting.DumpBytes{Bytes: blkBytes})
 		t.GetFailed(vdr, requestID, blkID)
@@ -103,10 +134,28 @@ func (t *Transitive) GetFailed(vdr ids.ShortID, requestID uint32, blkID ids.ID)
 	t.numBlockedBlk.Set(float64(t.pending.Len()))
 }
 
+// MultiPut implements the Engine interface
+func (t *Transitive) MultiPut(vdr ids.ShortID, requestID uint32, blks [][]byte) {
+	if !t.bootstrapped {
+		t.bootstrapper.MultiPut(vdr, requestID, blks)
+		return
+	}
+	t.Config.Context.ConsensusLog.Debug("Dropping MultiPut(%s, %d) as bootstrapping has finished", vdr, requestID)
+}
+
+// GetAncestorsFailed implements the Engine interface
+func (t *Transitive) GetAncestorsFailed(vdr ids.ShortID, requestID uint32) {
+	if !t.bootstrapped {
+		t.bootstrapper.GetAncestorsFailed(vdr, requestID)
+		return
+	}
+	t.Config.Context.ConsensusLog.Debug("Dropping GetAncestorsFailed(%s, %d) as bootstrapping has finished", vdr, requestID)
+}
+
 // PullQuery implements the Engine interface
 func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, blkID ids.ID) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Debug("Dropping PullQuery for %s due to bootstrapping", blkID)
+		t.Config.Context.ConsensusLog.Debug("Dropping PullQuery for %s due to bootstrapping", blkID)
 		return
 	}
 
@@ -127,7 +176,7 @@ func (t *Transitive) PullQuery(vdr ids.ShortID, requestID uint32, blkID ids.ID)
 // PushQuery implements the Engine interface
 func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, blkID ids.ID, blk []byte) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Debug("Dropping PushQuery for %s due to bootstrapping", blkID)
+		t.Config.Context.ConsensusLog.Debug("Dropping PushQuery for %s due to bootstrapping", blkID)
 		return
 	}
 
@@ -138,19 +187,19 @@ func (t *Transitive) PushQuery(vdr ids.ShortID, requestID uint32, blkID ids.ID,
 // Chits implements the Engine interface
 func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes ids.Set) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Warn("Dropping Chits due to bootstrapping")
+		t.Config.Context.ConsensusLog.Warn("Dropping Chits due to bootstrapping")
 		return
 	}
 
 	// Since this is snowman, there should only be one ID in the vote set
 	if votes.Len() != 1 {
-		t.Config.Context.Log.Warn("Chits was called with the wrong number of votes %d. ValidatorID: %s, RequestID: %d", votes.Len(), vdr, requestID)
+		t.Config.Context.ConsensusLog.Warn("Chits was called with the wrong number of votes %d. ValidatorID: %s, RequestID: %d", votes.Len(), vdr, requestID)
 		t.QueryFailed(vdr, requestID)
 		return
 	}
 	vote := votes.List()[0]
 
-	t.Config.Context.Log.Verbo("Chit was called. RequestID: %v. Vote: %s", requestID, vote)
+	t.Config.Context.ConsensusLog.Verbo("Chit was called. RequestID: %v. Vote: %s", requestID, vote)
 
 	v := &voter{
 		t:         t,
@@ -169,7 +218,7 @@ func (t *Transitive) Chits(vdr ids.ShortID, requestID uint32, votes ids.Set) {
 // QueryFailed implements the Engine interface
 func (t *Transitive) QueryFailed(vdr ids.ShortID, requestID uint32) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Warn("Dropping QueryFailed due to bootstrapping")
+		t.Config.Context.ConsensusLog.Warn("Dropping QueryFailed due to bootstrapping")
 		return
 	}
 
@@ -183,31 +232,31 @@ func (t *Transitive) QueryFailed(vdr ids.ShortID, requestID uint32) {
 // Notify implements the Engine interface
 func (t *Transitive) Notify(msg common.Message) {
 	if !t.bootstrapped {
-		t.Config.Context.Log.Warn("Dropping Notify due to bootstrapping")
+		t.Config.Context.ConsensusLog.Warn("Dropping Notify due to bootstrapping")
 		return
 	}
 
-	t.Config.Context.Log.Verbo("Snowman engine notified of %s from the vm", msg)
+	t.Config.Context.ConsensusLog.Verbo("Snowman engine notified of %s from the vm", msg)
 	switch msg {
 	case common.PendingTxs:
 		if blk, err := t.Config.VM.BuildBlock(); err == nil {
 			if status := blk.Status(); status != choices.Processing {
-				t.Config.Context.Log.Warn("Attempting to issue a block with status: %s, expected Processing", status)
+				t.Config.Context.ConsensusLog.Warn("Attempting to issue a block with status: %s, expected Processing", status)
 			}
 			parentID := blk.Parent().ID()
 			if pref := t.Consensus.Preference(); !parentID.Equals(pref) {
-				t.Config.Context.Log.Warn("Built block with parent: %s, expected %s", parentID, pref)
+				t.Config.Context.ConsensusLog.Warn("Built block with parent: %s, expected %s", parentID, pref)
 			}
 			if t.insertAll(blk) {
-				t.Config.Context.Log.Verbo("Successfully issued new block from the VM")
+				t.Config.Context.ConsensusLog.Verbo("Successfully issued new block from the VM")
 			} else {
-				t.Config.Context.Log.Warn("VM.BuildBlock returned a block that is pending for ancestors")
+				t.Config.Context.ConsensusLog.Warn("VM.BuildBlock returned a block that is pending for ancestors")
 			}
 		} else {
-			t.Config.Context.Log.Verbo("VM.BuildBlock errored with %s", err)
+			t.Config.Context.ConsensusLog.Verbo("VM.BuildBlock errored with %s", err)
 		}
 	default:
-		t.Config.Context.Log.Warn("Unexpected message from the VM: %s", msg)
+		t.Config.Context.ConsensusLog.Warn("Unexpected message from the VM: %s", msg)
 	}
 }
 
@@ -265,7 +314,7 @@ func (t *Transitive) insert(blk snowman.Block) {
 
 	if parent := blk.Parent(); !t.Consensus.Issued(parent) {
 		parentID := parent.ID()
-		t.Config.Context.Log.Verbo("Block waiting for parent %s", parentID)
+		t.Config.Context.ConsensusLog.Verbo("Block waiting for parent %s", parentID)
 		i.deps.Add(parentID)
 	}
 
@@ -283,13 +332,13 @@ func (t *Transitive) sendRequest(vdr ids.ShortID, blkID ids.ID) {
 		t.numBlkRequests.Set(float64(t.blkReqs.Len())) // Tracks performance statistics
 
 		t.RequestID++
-		t.Config.Context.Log.Verbo("Sending Get message for %s", blkID)
+		t.Config.Context.ConsensusLog.Verbo("Sending Get message for %s", blkID)
 		t.Config.Sender.Get(vdr, t.RequestID, blkID)
 	}
 }
 
 func (t *Transitive) pullSample(blkID ids.ID) {
-	t.Config.Context.Log.Verbo("About to sample from: %s", t.Config.Validators)
+	t.Config.Context.ConsensusLog.Verbo("About to sample from: %s", t.Config.Validators)
 	p := t.Consensus.Parameters()
 	vdrs := t.Config.Validators.Sample(p.K)
 	vdrSet := ids.ShortSet{}
@@ -301,12 +350,12 @@ func (t *Transitive) pullSample(blkID ids.ID) {
 	if numVdrs := len(vdrs); numVdrs == p.K && t.polls.Add(t.RequestID, vdrSet.Len()) {
 		t.Config.Sender.PullQuery(vdrSet, t.RequestID, blkID)
 	} else if numVdrs < p.K {
-		t.Config.Context.Log.Error("Query for %s was dropped due to an insufficient number of validators", blkID)
+		t.Config.Context.ConsensusLog.Error("Query for %s was dropped due to an insufficient number of validators", blkID)
 	}
 }
 
 func (t *Transitive) pushSample(blk snowman.Block) {
-	t.Config.Context.Log.Verbo("About to sample from: %s", t.Config.Validators)
+	t.Config.Context.ConsensusLog.Verbo("About to sample from: %s", t.Config.Validators)
 	p := t.Consensus.Parameters()
 	vdrs := t.Config.Validators.Sample(p.K)
 	vdrSet := ids.ShortSet{}
@@ -318,7 +367,7 @@ func (t *Transitive) pushSample(blk snowman.Block) {
 	if numVdrs := len(vdrs); numVdrs == p.K && t.polls.Add(t.RequestID, vdrSet.Len()) {
 		t.Config.Sender.PushQuery(vdrSet, t.RequestID, blk.ID(), blk.Bytes())
 	} else if numVdrs < p.K {
-		t.Config.Context.Log.Error("Query for %s was dropped due to an insufficient number of validators", blk.ID())
+		t.Config.Context.ConsensusLog.Error("Query for %s was dropped due to an insufficient number of validators", blk.ID())
 	}
 }
 
@@ -331,13 +380,13 @@ func (t *Transitive) deliver(blk snowman.Block) {
 	t.pending.Remove(blkID)
 
 	if err := blk.Verify(); err != nil {
-		t.Config.Context.Log.Debug("Block failed verification due to %s, dropping block", err)
+		t.Config.Context.ConsensusLog.Debug("Block failed verification due to %s, dropping block", err)
 		t.blocked.Abandon(blkID)
 		t.numBlockedBlk.Set(float64(t.pending.Len())) // Tracks performance statistics
 		return
 	}
 
-	t.Config.Context.Log.Verbo("Adding block to consensus: %s", blkID)
+	t.Config.Context.ConsensusLog.Verbo("Adding block to consensus: %s", blkID)
 
 	t.Consensus.Add(blk)
 	t.pushSample(blk)
@@ -348,7 +397,7 @@ func (t *Transitive) deliver(blk snowman.Block) {
 	case OracleBlock:
 		for _, blk := range blk.Options() {
 			if err := blk.Verify(); err != nil {
-				t.Config.Context.Log.Debug("Block failed verification due to %s, dropping block", err)
+				t.Config.Context.ConsensusLog.Debug("Block failed verification due to %s, dropping block", err)
 				t.blocked.Abandon(blk.ID())
 				dropped = append(dropped, blk)
 			} else {
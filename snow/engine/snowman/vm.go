@@ -54,4 +54,38 @@ type ChainVM interface {
 	// a definitionally accepted block, the Genesis block, that will be
 	// returned.
 	LastAccepted() ids.ID
+
+	// GetBlockIDAtHeight returns the ID of the accepted block at [height].
+	//
+	// The genesis block is at height 0. If no block at [height] has been
+	// accepted, an error should be returned.
+	GetBlockIDAtHeight(height uint64) (ids.ID, error)
+}
+
+// StateSyncableVM defines the functionality a Snowman VM must implement to
+// allow new nodes to start validating from a recent height, rather than
+// re-executing every block since genesis.
+//
+// The state at an accepted block is split into chunks, each of which is
+// identified by the hash of its bytes. A StateSummary lists the chunks of the
+// state at a height.
+type StateSyncableVM interface {
+	ChainVM
+
+	// StateSummaries returns the summaries of the state at the recent heights
+	// this VM can serve chunks for.
+	StateSummaries() ([]*StateSummary, error)
+
+	// GetStateChunk returns the chunk of state whose ID is [chunkID].
+	//
+	// If the chunk isn't available, an error should be returned.
+	GetStateChunk(chunkID ids.ID) ([]byte, error)
+
+	// SyncState replaces the state of this VM with the state described by
+	// [summary]. [chunks] are the chunks of the state, in the order they are
+	// listed in [summary], and have been verified against their IDs.
+	//
+	// On success, the block described by [summary] should be the last
+	// accepted block.
+	SyncState(summary *StateSummary, chunks [][]byte) error
 }
@@ -41,17 +41,17 @@ func (v *voter) Update() {
 		return
 	}
 
-	v.t.Config.Context.Log.Verbo("Finishing poll [%d] with:\n%s", v.requestID, &results)
+	v.t.Config.Context.ConsensusLog.Verbo("Finishing poll [%d] with:\n%s", v.requestID, &results)
 	v.t.Consensus.RecordPoll(results)
 
 	v.t.Config.VM.SetPreference(v.t.Consensus.Preference())
 
 	if v.t.Consensus.Finalized() {
-		v.t.Config.Context.Log.Verbo("Snowman engine can quiesce")
+		v.t.Config.Context.ConsensusLog.Verbo("Snowman engine can quiesce")
 		return
 	}
 
-	v.t.Config.Context.Log.Verbo("Snowman engine can't quiesce")
+	v.t.Config.Context.ConsensusLog.Verbo("Snowman engine can't quiesce")
 
 	if len(v.t.polls.m) == 0 {
 		v.t.repoll()
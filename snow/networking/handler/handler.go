@@ -5,6 +5,7 @@ package handler
 
 import (
 	"sync"
+	"time"
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
@@ -14,6 +15,8 @@ import (
 // Handler passes incoming messages from the network to the consensus engine
 // (Actually, it receives the incoming messages from a ChainRouter, but same difference)
 type Handler struct {
+	metrics
+
 	msgs    chan message
 	wg      sync.WaitGroup
 	engine  common.Engine
@@ -32,11 +35,19 @@ func (h *Handler) Initialize(engine common.Engine, msgChan <-chan common.Message
 // Context of this Handler
 func (h *Handler) Context() *snow.Context { return h.engine.Context() }
 
+// Space returns the number of messages that can be passed to this Handler
+// before its buffer is full
+func (h *Handler) Space() int { return cap(h.msgs) - len(h.msgs) }
+
 // Dispatch waits for incoming messages from the network
 // and, when they arrive, sends them to the consensus engine
 func (h *Handler) Dispatch() {
 	defer h.wg.Done()
 
+	// The engine's context is only guaranteed to be set once the handler starts
+	ctx := h.engine.Context()
+	h.metrics.Initialize(ctx.ConsensusLog, ctx.Namespace, ctx.Metrics, h.msgs)
+
 	for {
 		select {
 		case msg := <-h.msgs:
@@ -60,7 +71,17 @@ func (h *Handler) dispatchMsg(msg message) bool {
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 
-	ctx.Log.Verbo("Forwarding message to consensus: %s", msg)
+	start := time.Now()
+	defer func() {
+		h.numHandled.Inc()
+		h.handlingSec.Add(time.Since(start).Seconds())
+	}()
+
+	// Everything logged while handling this message is tagged with its trace
+	ctx.SetTraceID(msg.traceID)
+	defer ctx.SetTraceID(0)
+
+	ctx.ConsensusLog.Verbo("Forwarding message to consensus: %s", msg)
 
 	switch msg.messageType {
 	case getAcceptedFrontierMsg:
@@ -89,6 +110,24 @@ func (h *Handler) dispatchMsg(msg message) bool {
 		h.engine.QueryFailed(msg.validatorID, msg.requestID)
 	case chitsMsg:
 		h.engine.Chits(msg.validatorID, msg.requestID, msg.containerIDs)
+	case getStateSummariesMsg:
+		h.engine.GetStateSummaries(msg.validatorID, msg.requestID)
+	case stateSummariesMsg:
+		h.engine.StateSummaries(msg.validatorID, msg.requestID, msg.summaries)
+	case getStateSummariesFailedMsg:
+		h.engine.GetStateSummariesFailed(msg.validatorID, msg.requestID)
+	case getStateChunkMsg:
+		h.engine.GetStateChunk(msg.validatorID, msg.requestID, msg.containerID)
+	case stateChunkMsg:
+		h.engine.StateChunk(msg.validatorID, msg.requestID, msg.containerID, msg.container)
+	case getStateChunkFailedMsg:
+		h.engine.GetStateChunkFailed(msg.validatorID, msg.requestID, msg.containerID)
+	case getAncestorsMsg:
+		h.engine.GetAncestors(msg.validatorID, msg.requestID, msg.containerID)
+	case multiPutMsg:
+		h.engine.MultiPut(msg.validatorID, msg.requestID, msg.containers)
+	case getAncestorsFailedMsg:
+		h.engine.GetAncestorsFailed(msg.validatorID, msg.requestID)
 	case notifyMsg:
 		h.engine.Notify(msg.notification)
 	case shutdownMsg:
@@ -100,9 +139,10 @@ func (h *Handler) dispatchMsg(msg message) bool {
 
 // GetAcceptedFrontier passes a GetAcceptedFrontier message received from the
 // network to the consensus engine.
-func (h *Handler) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32) {
+func (h *Handler) GetAcceptedFrontier(traceID uint64, validatorID ids.ShortID, requestID uint32) {
 	h.msgs <- message{
 		messageType: getAcceptedFrontierMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 	}
@@ -110,9 +150,10 @@ func (h *Handler) GetAcceptedFrontier(validatorID ids.ShortID, requestID uint32)
 
 // AcceptedFrontier passes a AcceptedFrontier message received from the network
 // to the consensus engine.
-func (h *Handler) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
+func (h *Handler) AcceptedFrontier(traceID uint64, validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
 	h.msgs <- message{
 		messageType:  acceptedFrontierMsg,
+		traceID:      traceID,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
@@ -121,9 +162,10 @@ func (h *Handler) AcceptedFrontier(validatorID ids.ShortID, requestID uint32, co
 
 // GetAcceptedFrontierFailed passes a GetAcceptedFrontierFailed message received
 // from the network to the consensus engine.
-func (h *Handler) GetAcceptedFrontierFailed(validatorID ids.ShortID, requestID uint32) {
+func (h *Handler) GetAcceptedFrontierFailed(traceID uint64, validatorID ids.ShortID, requestID uint32) {
 	h.msgs <- message{
 		messageType: getAcceptedFrontierFailedMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 	}
@@ -131,9 +173,10 @@ func (h *Handler) GetAcceptedFrontierFailed(validatorID ids.ShortID, requestID u
 
 // GetAccepted passes a GetAccepted message received from the
 // network to the consensus engine.
-func (h *Handler) GetAccepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
+func (h *Handler) GetAccepted(traceID uint64, validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
 	h.msgs <- message{
 		messageType:  getAcceptedMsg,
+		traceID:      traceID,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
@@ -142,9 +185,10 @@ func (h *Handler) GetAccepted(validatorID ids.ShortID, requestID uint32, contain
 
 // Accepted passes a Accepted message received from the network to the consensus
 // engine.
-func (h *Handler) Accepted(validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
+func (h *Handler) Accepted(traceID uint64, validatorID ids.ShortID, requestID uint32, containerIDs ids.Set) {
 	h.msgs <- message{
 		messageType:  acceptedMsg,
+		traceID:      traceID,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: containerIDs,
@@ -153,18 +197,20 @@ func (h *Handler) Accepted(validatorID ids.ShortID, requestID uint32, containerI
 
 // GetAcceptedFailed passes a GetAcceptedFailed message received from the
 // network to the consensus engine.
-func (h *Handler) GetAcceptedFailed(validatorID ids.ShortID, requestID uint32) {
+func (h *Handler) GetAcceptedFailed(traceID uint64, validatorID ids.ShortID, requestID uint32) {
 	h.msgs <- message{
 		messageType: getAcceptedFailedMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 	}
 }
 
 // Get passes a Get message received from the network to the consensus engine.
-func (h *Handler) Get(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
+func (h *Handler) Get(traceID uint64, validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
 	h.msgs <- message{
 		messageType: getMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
@@ -172,9 +218,10 @@ func (h *Handler) Get(validatorID ids.ShortID, requestID uint32, containerID ids
 }
 
 // Put passes a Put message received from the network to the consensus engine.
-func (h *Handler) Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) {
+func (h *Handler) Put(traceID uint64, validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) {
 	h.msgs <- message{
 		messageType: putMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
@@ -183,9 +230,10 @@ func (h *Handler) Put(validatorID ids.ShortID, requestID uint32, containerID ids
 }
 
 // GetFailed passes a GetFailed message to the consensus engine.
-func (h *Handler) GetFailed(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
+func (h *Handler) GetFailed(traceID uint64, validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
 	h.msgs <- message{
 		messageType: getFailedMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: containerID,
@@ -193,9 +241,10 @@ func (h *Handler) GetFailed(validatorID ids.ShortID, requestID uint32, container
 }
 
 // PushQuery passes a PushQuery message received from the network to the consensus engine.
-func (h *Handler) PushQuery(validatorID ids.ShortID, requestID uint32, blockID ids.ID, block []byte) {
+func (h *Handler) PushQuery(traceID uint64, validatorID ids.ShortID, requestID uint32, blockID ids.ID, block []byte) {
 	h.msgs <- message{
 		messageType: pushQueryMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: blockID,
@@ -204,9 +253,10 @@ func (h *Handler) PushQuery(validatorID ids.ShortID, requestID uint32, blockID i
 }
 
 // PullQuery passes a PullQuery message received from the network to the consensus engine.
-func (h *Handler) PullQuery(validatorID ids.ShortID, requestID uint32, blockID ids.ID) {
+func (h *Handler) PullQuery(traceID uint64, validatorID ids.ShortID, requestID uint32, blockID ids.ID) {
 	h.msgs <- message{
 		messageType: pullQueryMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 		containerID: blockID,
@@ -214,9 +264,10 @@ func (h *Handler) PullQuery(validatorID ids.ShortID, requestID uint32, blockID i
 }
 
 // Chits passes a Chits message received from the network to the consensus engine.
-func (h *Handler) Chits(validatorID ids.ShortID, requestID uint32, votes ids.Set) {
+func (h *Handler) Chits(traceID uint64, validatorID ids.ShortID, requestID uint32, votes ids.Set) {
 	h.msgs <- message{
 		messageType:  chitsMsg,
+		traceID:      traceID,
 		validatorID:  validatorID,
 		requestID:    requestID,
 		containerIDs: votes,
@@ -224,9 +275,116 @@ func (h *Handler) Chits(validatorID ids.ShortID, requestID uint32, votes ids.Set
 }
 
 // QueryFailed passes a QueryFailed message received from the network to the consensus engine.
-func (h *Handler) QueryFailed(validatorID ids.ShortID, requestID uint32) {
+func (h *Handler) QueryFailed(traceID uint64, validatorID ids.ShortID, requestID uint32) {
 	h.msgs <- message{
 		messageType: queryFailedMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+	}
+}
+
+// GetStateSummaries passes a GetStateSummaries message received from the
+// network to the consensus engine.
+func (h *Handler) GetStateSummaries(traceID uint64, validatorID ids.ShortID, requestID uint32) {
+	h.msgs <- message{
+		messageType: getStateSummariesMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+	}
+}
+
+// StateSummaries passes a StateSummaries message received from the network to
+// the consensus engine.
+func (h *Handler) StateSummaries(traceID uint64, validatorID ids.ShortID, requestID uint32, summaries [][]byte) {
+	h.msgs <- message{
+		messageType: stateSummariesMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		summaries:   summaries,
+	}
+}
+
+// GetStateSummariesFailed passes a GetStateSummariesFailed message to the
+// consensus engine.
+func (h *Handler) GetStateSummariesFailed(traceID uint64, validatorID ids.ShortID, requestID uint32) {
+	h.msgs <- message{
+		messageType: getStateSummariesFailedMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+	}
+}
+
+// GetStateChunk passes a GetStateChunk message received from the network to
+// the consensus engine.
+func (h *Handler) GetStateChunk(traceID uint64, validatorID ids.ShortID, requestID uint32, chunkID ids.ID) {
+	h.msgs <- message{
+		messageType: getStateChunkMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		containerID: chunkID,
+	}
+}
+
+// StateChunk passes a StateChunk message received from the network to the
+// consensus engine.
+func (h *Handler) StateChunk(traceID uint64, validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	h.msgs <- message{
+		messageType: stateChunkMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		containerID: chunkID,
+		container:   chunk,
+	}
+}
+
+// GetStateChunkFailed passes a GetStateChunkFailed message to the consensus
+// engine.
+func (h *Handler) GetStateChunkFailed(traceID uint64, validatorID ids.ShortID, requestID uint32, chunkID ids.ID) {
+	h.msgs <- message{
+		messageType: getStateChunkFailedMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		containerID: chunkID,
+	}
+}
+
+// GetAncestors passes a GetAncestors message received from the network to the
+// consensus engine.
+func (h *Handler) GetAncestors(traceID uint64, validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
+	h.msgs <- message{
+		messageType: getAncestorsMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		containerID: containerID,
+	}
+}
+
+// MultiPut passes a MultiPut message received from the network to the
+// consensus engine.
+func (h *Handler) MultiPut(traceID uint64, validatorID ids.ShortID, requestID uint32, containers [][]byte) {
+	h.msgs <- message{
+		messageType: multiPutMsg,
+		traceID:     traceID,
+		validatorID: validatorID,
+		requestID:   requestID,
+		containers:  containers,
+	}
+}
+
+// GetAncestorsFailed passes a GetAncestorsFailed message to the consensus
+// engine.
+func (h *Handler) GetAncestorsFailed(traceID uint64, validatorID ids.ShortID, requestID uint32) {
+	h.msgs <- message{
+		messageType: getAncestorsFailedMsg,
+		traceID:     traceID,
 		validatorID: validatorID,
 		requestID:   requestID,
 	}
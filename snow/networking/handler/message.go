@@ -9,6 +9,7 @@ import (
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
 )
 
 type msgType int
@@ -28,27 +29,48 @@ const (
 	pullQueryMsg
 	chitsMsg
 	queryFailedMsg
+	getStateSummariesMsg
+	stateSummariesMsg
+	getStateSummariesFailedMsg
+	getStateChunkMsg
+	stateChunkMsg
+	getStateChunkFailedMsg
+	getAncestorsMsg
+	multiPutMsg
+	getAncestorsFailedMsg
 	notifyMsg
 	shutdownMsg
 )
 
 type message struct {
 	messageType  msgType
+	traceID      uint64
 	validatorID  ids.ShortID
 	requestID    uint32
 	containerID  ids.ID
 	container    []byte
 	containerIDs ids.Set
+	summaries    [][]byte
+	containers   [][]byte
 	notification common.Message
 }
 
 func (m message) String() string {
 	sb := strings.Builder{}
 	sb.WriteString(fmt.Sprintf("\n    messageType: %s", m.messageType.String()))
+	if m.traceID != 0 {
+		sb.WriteString(fmt.Sprintf("\n    traceID: %s", logging.FormatTrace(m.traceID)))
+	}
 	sb.WriteString(fmt.Sprintf("\n    validatorID: %s", m.validatorID.String()))
 	sb.WriteString(fmt.Sprintf("\n    requestID: %d", m.requestID))
 	sb.WriteString(fmt.Sprintf("\n    containerID: %s", m.containerID.String()))
 	sb.WriteString(fmt.Sprintf("\n    containerIDs: %s", m.containerIDs.String()))
+	if m.messageType == stateSummariesMsg {
+		sb.WriteString(fmt.Sprintf("\n    numSummaries: %d", len(m.summaries)))
+	}
+	if m.messageType == multiPutMsg {
+		sb.WriteString(fmt.Sprintf("\n    numContainers: %d", len(m.containers)))
+	}
 	if m.messageType == notifyMsg {
 		sb.WriteString(fmt.Sprintf("\n    notification: %s", m.notification.String()))
 	}
@@ -85,6 +107,24 @@ func (t msgType) String() string {
 		return "Chits Message"
 	case queryFailedMsg:
 		return "Query Failed Message"
+	case getStateSummariesMsg:
+		return "Get State Summaries Message"
+	case stateSummariesMsg:
+		return "State Summaries Message"
+	case getStateSummariesFailedMsg:
+		return "Get State Summaries Failed Message"
+	case getStateChunkMsg:
+		return "Get State Chunk Message"
+	case stateChunkMsg:
+		return "State Chunk Message"
+	case getStateChunkFailedMsg:
+		return "Get State Chunk Failed Message"
+	case getAncestorsMsg:
+		return "Get Ancestors Message"
+	case multiPutMsg:
+		return "Multi Put Message"
+	case getAncestorsFailedMsg:
+		return "Get Ancestors Failed Message"
 	case notifyMsg:
 		return "Notify Message"
 	case shutdownMsg:
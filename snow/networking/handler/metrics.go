@@ -0,0 +1,49 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package handler
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type metrics struct {
+	numHandled  prometheus.Counter
+	handlingSec prometheus.Counter
+	numPending  prometheus.GaugeFunc
+}
+
+// Initialize the metrics of a handler whose queue of pending messages is [msgs]
+func (m *metrics) Initialize(log logging.Logger, namespace string, registerer prometheus.Registerer, msgs chan message) {
+	m.numHandled = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "handler_msgs",
+			Help:      "Number of messages handled",
+		})
+	m.handlingSec = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "handler_busy_seconds",
+			Help:      "Time spent handling messages while holding the chain's lock",
+		})
+	m.numPending = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "handler_pending",
+			Help:      "Number of messages waiting to be handled",
+		},
+		func() float64 { return float64(len(msgs)) })
+
+	if err := registerer.Register(m.numHandled); err != nil {
+		log.Error("Failed to register handler_msgs statistics due to %s", err)
+	}
+	if err := registerer.Register(m.handlingSec); err != nil {
+		log.Error("Failed to register handler_busy_seconds statistics due to %s", err)
+	}
+	if err := registerer.Register(m.numPending); err != nil {
+		log.Error("Failed to register handler_pending statistics due to %s", err)
+	}
+}
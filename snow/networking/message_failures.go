@@ -0,0 +1,151 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// Types of consensus messages, as they're labeled in the failure metrics
+const (
+	GetAcceptedFrontierMsg = "get_accepted_frontier"
+	AcceptedFrontierMsg    = "accepted_frontier"
+	GetAcceptedMsg         = "get_accepted"
+	AcceptedMsg            = "accepted"
+	GetMsg                 = "get"
+	PutMsg                 = "put"
+	PushQueryMsg           = "push_query"
+	PullQueryMsg           = "pull_query"
+	ChitsMsg               = "chits"
+	GetStateSummariesMsg   = "get_state_summaries"
+	StateSummariesMsg      = "state_summaries"
+	GetStateChunkMsg       = "get_state_chunk"
+	StateChunkMsg          = "state_chunk"
+	GetAncestorsMsg        = "get_ancestors"
+	MultiPutMsg            = "multi_put"
+	GetSnapshotMsg         = "get_snapshot"
+	SnapshotMsg            = "snapshot"
+	GetSnapshotChunkMsg    = "get_snapshot_chunk"
+	SnapshotChunkMsg       = "snapshot_chunk"
+)
+
+// Classes of peers, as they're labeled in the failure metrics
+const (
+	validatorPeer    = "validator"
+	nonValidatorPeer = "non_validator"
+)
+
+// Directions of throttled messages, as they're labeled in the failure metrics
+const (
+	inbound  = "inbound"
+	outbound = "outbound"
+)
+
+// MessageFailures counts the consensus messages that weren't delivered, by
+// type of message and by whether the peer is a validator. A nil
+// MessageFailures counts nothing.
+type MessageFailures struct {
+	vdrs validators.Set
+
+	dropped, sendFailed, timedOut, throttled *prometheus.CounterVec
+}
+
+// NewMessageFailures returns counters of undelivered messages, registered
+// with [registerer]. Peers in [vdrs] are counted as validators.
+func NewMessageFailures(log logging.Logger, vdrs validators.Set, registerer prometheus.Registerer) *MessageFailures {
+	labels := []string{"type", "peer"}
+	f := &MessageFailures{
+		vdrs: vdrs,
+		dropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "gecko",
+				Name:      "msgs_dropped",
+				Help:      "Number of messages dropped by a full outbound queue or a chain's full inbound queue, or received for a chain this node isn't running",
+			},
+			labels,
+		),
+		sendFailed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "gecko",
+				Name:      "msgs_send_failed",
+				Help:      "Number of messages that weren't sent because the peer was disconnected or the message couldn't be built",
+			},
+			labels,
+		),
+		timedOut: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "gecko",
+				Name:      "msgs_timed_out",
+				Help:      "Number of requests that timed out before a response was received",
+			},
+			labels,
+		),
+		throttled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "gecko",
+				Name:      "msgs_throttled",
+				Help:      "Number of messages dropped because the peer exceeded its bytes or messages per second",
+			},
+			append(labels, "direction"),
+		),
+	}
+
+	if err := registerer.Register(f.dropped); err != nil {
+		log.Error("Failed to register msgs_dropped statistics due to %s", err)
+	}
+	if err := registerer.Register(f.sendFailed); err != nil {
+		log.Error("Failed to register msgs_send_failed statistics due to %s", err)
+	}
+	if err := registerer.Register(f.timedOut); err != nil {
+		log.Error("Failed to register msgs_timed_out statistics due to %s", err)
+	}
+	if err := registerer.Register(f.throttled); err != nil {
+		log.Error("Failed to register msgs_throttled statistics due to %s", err)
+	}
+	return f
+}
+
+// Dropped records that a [msgType] message to or from [peerID] was dropped
+func (f *MessageFailures) Dropped(msgType string, peerID ids.ShortID) {
+	if f != nil {
+		f.dropped.WithLabelValues(msgType, f.peerClass(peerID)).Inc()
+	}
+}
+
+// SendFailed records that a [msgType] message to [peerID] wasn't sent
+func (f *MessageFailures) SendFailed(msgType string, peerID ids.ShortID) {
+	if f != nil {
+		f.sendFailed.WithLabelValues(msgType, f.peerClass(peerID)).Inc()
+	}
+}
+
+// TimedOut records that a [msgType] request to [peerID] timed out
+func (f *MessageFailures) TimedOut(msgType string, peerID ids.ShortID) {
+	if f != nil {
+		f.timedOut.WithLabelValues(msgType, f.peerClass(peerID)).Inc()
+	}
+}
+
+// Throttled records that a [msgType] message from [peerID], if [received], or
+// to [peerID] otherwise, was dropped by a throttler
+func (f *MessageFailures) Throttled(msgType string, peerID ids.ShortID, received bool) {
+	if f != nil {
+		direction := outbound
+		if received {
+			direction = inbound
+		}
+		f.throttled.WithLabelValues(msgType, f.peerClass(peerID), direction).Inc()
+	}
+}
+
+func (f *MessageFailures) peerClass(peerID ids.ShortID) string {
+	if f.vdrs != nil && f.vdrs.Contains(peerID) {
+		return validatorPeer
+	}
+	return nonValidatorPeer
+}
@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package networking
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestMessageFailuresPeerClass(t *testing.T) {
+	vdr := validators.GenerateRandomValidator(1)
+	vdrs := validators.NewSet()
+	vdrs.Add(vdr)
+
+	registerer := prometheus.NewRegistry()
+	failures := NewMessageFailures(logging.NoLog{}, vdrs, registerer)
+
+	failures.TimedOut(GetMsg, vdr.ID())
+	failures.TimedOut(GetMsg, vdr.ID())
+	failures.TimedOut(GetMsg, ids.NewShortID([20]byte{1}))
+
+	if count := counterValue(t, failures.timedOut, GetMsg, validatorPeer); count != 2 {
+		t.Fatalf("Should have counted 2 timeouts of a validator but counted %f", count)
+	}
+	if count := counterValue(t, failures.timedOut, GetMsg, nonValidatorPeer); count != 1 {
+		t.Fatalf("Should have counted 1 timeout of a non-validator but counted %f", count)
+	}
+
+	// A nil MessageFailures counts nothing
+	(*MessageFailures)(nil).Dropped(PutMsg, vdr.ID())
+}
+
+func counterValue(t *testing.T, vec *prometheus.CounterVec, labels ...string) float64 {
+	metric := &dto.Metric{}
+	if err := vec.WithLabelValues(labels...).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestMessageFailuresThrottledDirection(t *testing.T) {
+	registerer := prometheus.NewRegistry()
+	failures := NewMessageFailures(logging.NoLog{}, nil, registerer)
+
+	peerID := ids.NewShortID([20]byte{1})
+	failures.Throttled(PushQueryMsg, peerID, true)
+	failures.Throttled(PushQueryMsg, peerID, true)
+	failures.Throttled(PushQueryMsg, peerID, false)
+
+	if count := counterValue(t, failures.throttled, PushQueryMsg, nonValidatorPeer, inbound); count != 2 {
+		t.Fatalf("Should have counted 2 inbound throttled messages but counted %f", count)
+	}
+	if count := counterValue(t, failures.throttled, PushQueryMsg, nonValidatorPeer, outbound); count != 1 {
+		t.Fatalf("Should have counted 1 outbound throttled message but counted %f", count)
+	}
+}
@@ -0,0 +1,165 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package router
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking/handler"
+)
+
+const (
+	// DefaultWeight is the weight of a chain whose weight isn't configured
+	DefaultWeight = 1
+
+	// DefaultSubnetWeight is the weight of a chain of the default subnet whose
+	// weight isn't configured. The X, P and C chains are drained ahead of the
+	// chains of other subnets.
+	DefaultSubnetWeight = 4
+
+	// maxQueueSize is the number of messages from the network that can be
+	// queued for a chain. Messages routed to a full queue are dropped.
+	maxQueueSize = 1024
+
+	// retryInterval is how long the dispatcher waits before it retries to pass
+	// messages to handlers whose buffers were full
+	retryInterval = 10 * time.Millisecond
+)
+
+// chainQueue holds the messages routed to a chain that haven't been passed to
+// the chain's handler yet
+type chainQueue struct {
+	handler *handler.Handler
+	// Max number of messages passed to the handler in each round of draining
+	weight int
+
+	msgs []queuedMsg
+	// Number of queued messages from the network. Internal messages, such as
+	// timed out requests, aren't dropped, so they don't count toward the bound.
+	numExternal int
+}
+
+type queuedMsg struct {
+	traceID  uint64
+	external bool
+	deliver  func(chain *handler.Handler, traceID uint64)
+}
+
+// route queues a message of type [msgType] from [validatorID] for the chain
+// [chainID]. [deliver] passes the message to the chain's handler. Returns
+// false if the message was dropped because the chain's queue is full, in which
+// case the request that the message responds to should time out instead.
+func (sr *ChainRouter) route(msgType string, validatorID ids.ShortID, chainID ids.ID, deliver func(*handler.Handler, uint64)) bool {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	queue, exists := sr.chains[chainID.Key()]
+	switch {
+	case !exists:
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+		sr.failures.Dropped(msgType, validatorID)
+		return true
+	case queue.numExternal >= maxQueueSize:
+		sr.log.Debug("Dropping %s message from %s because the queue of chain %s is full", msgType, validatorID, chainID)
+		sr.failures.Dropped(msgType, validatorID)
+		return false
+	}
+	queue.numExternal++
+	sr.push(queue, queuedMsg{external: true, deliver: deliver})
+	return true
+}
+
+// routeInternal queues a message, which is never dropped, for the chain
+// [chainID]
+func (sr *ChainRouter) routeInternal(chainID ids.ID, deliver func(*handler.Handler, uint64)) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	if queue, exists := sr.chains[chainID.Key()]; exists {
+		sr.push(queue, queuedMsg{deliver: deliver})
+	} else {
+		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	}
+}
+
+// push [msg] onto [queue] and wake the dispatcher. Assumes the lock is held.
+func (sr *ChainRouter) push(queue *chainQueue, msg queuedMsg) {
+	msg.traceID = sr.newTraceID()
+	queue.msgs = append(queue.msgs, msg)
+
+	select {
+	case sr.queued <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch passes the queued messages to the chains' handlers until the router
+// is shut down
+func (sr *ChainRouter) dispatch() {
+	for {
+		passed, pending := sr.drain()
+		if passed {
+			continue
+		}
+
+		// If messages are still queued, their handlers' buffers are full, so
+		// they're retried after a while
+		var retry <-chan time.Time
+		if pending {
+			retry = time.After(retryInterval)
+		}
+		select {
+		case <-sr.queued:
+		case <-retry:
+		case <-sr.closed:
+			return
+		}
+	}
+}
+
+// drain runs one round of passing queued messages to the chains' handlers.
+// Each handler is passed up to its chain's weight in messages, and no more
+// than fit in its buffer, so passing messages never blocks. Returns whether
+// any message was passed, and whether any message is still queued.
+func (sr *ChainRouter) drain() (passed bool, pending bool) {
+	sr.lock.Lock()
+	defer sr.lock.Unlock()
+
+	for _, queue := range sr.queues {
+		n := queue.weight
+		if space := queue.handler.Space(); space < n {
+			n = space
+		}
+		if len(queue.msgs) < n {
+			n = len(queue.msgs)
+		}
+
+		for i, msg := range queue.msgs[:n] {
+			msg.deliver(queue.handler, msg.traceID)
+			if msg.external {
+				queue.numExternal--
+			}
+			queue.msgs[i] = queuedMsg{}
+		}
+		queue.msgs = queue.msgs[n:]
+		if len(queue.msgs) == 0 {
+			queue.msgs = nil
+		}
+
+		passed = passed || n > 0
+		pending = pending || len(queue.msgs) > 0
+	}
+	return passed, pending
+}
+
+// removeQueue returns [queues] without [queue]
+func removeQueue(queues []*chainQueue, queue *chainQueue) []*chainQueue {
+	for i, q := range queues {
+		if q == queue {
+			return append(queues[:i], queues[i+1:]...)
+		}
+	}
+	return queues
+}
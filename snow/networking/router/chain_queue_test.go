@@ -0,0 +1,124 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/networking/handler"
+	"github.com/ava-labs/gecko/snow/networking/timeout"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func newTestChain(t *testing.T, chainID ids.ID, bufferSize int) (*handler.Handler, *common.EngineTest) {
+	ctx := snow.DefaultContextTest()
+	ctx.ChainID = chainID
+
+	engine := &common.EngineTest{T: t}
+	engine.Default(false)
+	engine.ContextF = func() *snow.Context { return ctx }
+
+	h := &handler.Handler{}
+	h.Initialize(engine, nil, bufferSize)
+	return h, engine
+}
+
+func TestFloodedChainDoesntDelayOthers(t *testing.T) {
+	tm := timeout.Manager{}
+	tm.Initialize(time.Hour)
+	go tm.Dispatch()
+
+	sr := ChainRouter{}
+	sr.Initialize(logging.NoLog{}, &tm)
+
+	floodedID := ids.Empty.Prefix(0)
+	flooded, floodedEngine := newTestChain(t, floodedID, 1)
+	release := make(chan struct{})
+	floodedEngine.PullQueryF = func(ids.ShortID, uint32, ids.ID) { <-release }
+
+	otherID := ids.Empty.Prefix(1)
+	other, otherEngine := newTestChain(t, otherID, 1)
+	handled := make(chan struct{}, 1)
+	otherEngine.PullQueryF = func(ids.ShortID, uint32, ids.ID) { handled <- struct{}{} }
+
+	sr.AddChain(flooded, DefaultWeight)
+	sr.AddChain(other, DefaultWeight)
+	go flooded.Dispatch()
+	go other.Dispatch()
+
+	// The flooded chain's engine is stuck, so its queue fills up
+	vdrID := ids.NewShortID([20]byte{1})
+	for i := 0; i < maxQueueSize+10; i++ {
+		sr.PullQuery(vdrID, floodedID, uint32(i), ids.Empty)
+	}
+	sr.PullQuery(vdrID, otherID, 0, ids.Empty)
+
+	select {
+	case <-handled:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The other chain's message should have been handled")
+	}
+
+	sr.lock.Lock()
+	numQueued := sr.chains[floodedID.Key()].numExternal
+	sr.lock.Unlock()
+	// At most 2 messages were passed to the handler: the one being handled,
+	// and the one in its buffer
+	if numQueued > maxQueueSize || numQueued < maxQueueSize-2 {
+		t.Fatalf("Flooded chain should have about %d queued messages but has %d", maxQueueSize, numQueued)
+	}
+
+	close(release)
+	sr.Shutdown()
+}
+
+func TestWeightedDrain(t *testing.T) {
+	// The dispatcher isn't started, so the queues are only drained by the test
+	sr := ChainRouter{
+		log:    logging.NoLog{},
+		chains: make(map[[32]byte]*chainQueue),
+		queued: make(chan struct{}, 1),
+	}
+
+	heavyID := ids.Empty.Prefix(0)
+	heavy, _ := newTestChain(t, heavyID, 6)
+	lightID := ids.Empty.Prefix(1)
+	light, _ := newTestChain(t, lightID, 10)
+	sr.AddChain(heavy, 3)
+	sr.AddChain(light, 1)
+
+	vdrID := ids.NewShortID([20]byte{1})
+	for i := 0; i < 8; i++ {
+		sr.Get(vdrID, heavyID, uint32(i), ids.Empty)
+		sr.Get(vdrID, lightID, uint32(i), ids.Empty)
+	}
+
+	if passed, pending := sr.drain(); !passed || !pending {
+		t.Fatalf("Some of the messages should have been passed")
+	}
+	if numPassed := 6 - heavy.Space(); numPassed != 3 {
+		t.Fatalf("Heavy chain should have been passed 3 messages but was passed %d", numPassed)
+	}
+	if numPassed := 10 - light.Space(); numPassed != 1 {
+		t.Fatalf("Light chain should have been passed 1 message but was passed %d", numPassed)
+	}
+
+	// Messages aren't passed to a full handler
+	for i := 0; i < 4; i++ {
+		sr.drain()
+	}
+	if space := heavy.Space(); space != 0 {
+		t.Fatalf("Heavy chain's buffer should be full but has space for %d messages", space)
+	}
+	if numQueued := len(sr.chains[heavyID.Key()].msgs); numQueued != 2 {
+		t.Fatalf("Heavy chain should have 2 queued messages but has %d", numQueued)
+	}
+	if numPassed := 10 - light.Space(); numPassed != 5 {
+		t.Fatalf("Light chain should have been passed 5 messages but was passed %d", numPassed)
+	}
+}
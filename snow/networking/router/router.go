@@ -5,6 +5,7 @@ package router
 
 import (
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -16,10 +17,11 @@ type Router interface {
 	ExternalRouter
 	InternalRouter
 
-	AddChain(chain *handler.Handler)
+	AddChain(chain *handler.Handler, weight int)
 	RemoveChain(chainID ids.ID)
 	Shutdown()
 	Initialize(log logging.Logger, timeouts *timeout.Manager)
+	TrackFailures(failures *networking.MessageFailures)
 }
 
 // ExternalRouter routes messages from the network to the
@@ -34,6 +36,12 @@ type ExternalRouter interface {
 	PushQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
 	PullQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
 	Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set)
+	GetStateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	StateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte)
+	GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID)
+	StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte)
+	GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
+	MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte)
 }
 
 // InternalRouter deals with messages internal to this node
@@ -42,4 +50,7 @@ type InternalRouter interface {
 	GetAcceptedFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 	GetFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
 	QueryFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	GetStateSummariesFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
+	GetStateChunkFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID)
+	GetAncestorsFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32)
 }
@@ -5,8 +5,10 @@ package router
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/networking"
 	"github.com/ava-labs/gecko/snow/networking/handler"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
 	"github.com/ava-labs/gecko/utils/logging"
@@ -16,11 +18,29 @@ import (
 // to the consensus engines that the messages are intended for.
 // Note that consensus engines are uniquely identified by the ID of the chain
 // that they are working on.
+//
+// Each chain has its own bounded queue of routed messages. The queues are
+// drained into the chains' handlers in weighted rounds, so a chain that is
+// flooded with messages can't delay the other chains.
 type ChainRouter struct {
 	log      logging.Logger
-	lock     sync.RWMutex
-	chains   map[[32]byte]*handler.Handler
+	lock     sync.Mutex
+	chains   map[[32]byte]*chainQueue
+	queues   []*chainQueue // The chains' queues, in the order they're drained
 	timeouts *timeout.Manager
+
+	// Signalled when a message is queued
+	queued chan struct{}
+	// Closed when the router is shut down
+	closed   chan struct{}
+	shutdown bool
+
+	// Counts the messages routed to chains that aren't running, or whose
+	// queues are full. May be nil.
+	failures *networking.MessageFailures
+
+	// lastTraceID is the trace ID that was given to the last routed message
+	lastTraceID uint64
 }
 
 // Initialize the router
@@ -28,59 +48,84 @@ type ChainRouter struct {
 // associated with the request that caused the incoming message, if applicable
 func (sr *ChainRouter) Initialize(log logging.Logger, timeouts *timeout.Manager) {
 	sr.log = log
-	sr.chains = make(map[[32]byte]*handler.Handler)
+	sr.chains = make(map[[32]byte]*chainQueue)
 	sr.timeouts = timeouts
+	sr.queued = make(chan struct{}, 1)
+	sr.closed = make(chan struct{})
+
+	go log.RecoverAndPanic(sr.dispatch)
 }
 
-// AddChain registers the specified chain so that incoming
-// messages can be routed to it
-func (sr *ChainRouter) AddChain(chain *handler.Handler) {
+// TrackFailures counts the messages routed to chains that aren't running, or
+// whose queues are full, in [failures]. Should be called before any messages
+// are routed.
+func (sr *ChainRouter) TrackFailures(failures *networking.MessageFailures) { sr.failures = failures }
+
+// newTraceID returns the ID that traces the handling of a routed message
+func (sr *ChainRouter) newTraceID() uint64 { return atomic.AddUint64(&sr.lastTraceID, 1) }
+
+// AddChain registers the specified chain so that incoming messages can be
+// routed to it. In each round of draining the queues, up to [weight] of the
+// chain's messages are passed to its handler, which should be buffered.
+func (sr *ChainRouter) AddChain(chain *handler.Handler, weight int) {
+	if weight < 1 {
+		weight = DefaultWeight
+	}
+	queue := &chainQueue{
+		handler: chain,
+		weight:  weight,
+	}
+
 	sr.lock.Lock()
 	defer sr.lock.Unlock()
 
-	sr.chains[chain.Context().ChainID.Key()] = chain
+	key := chain.Context().ChainID.Key()
+	if old, exists := sr.chains[key]; exists {
+		sr.queues = removeQueue(sr.queues, old)
+	}
+	sr.chains[key] = queue
+	sr.queues = append(sr.queues, queue)
 }
 
 // RemoveChain removes the specified chain so that incoming
-// messages can't be routed to it
+// messages can't be routed to it. Messages that are still queued for the
+// chain are dropped.
 func (sr *ChainRouter) RemoveChain(chainID ids.ID) {
 	sr.lock.Lock()
-	defer sr.lock.Unlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Shutdown()
+	queue, exists := sr.chains[chainID.Key()]
+	if exists {
 		delete(sr.chains, chainID.Key())
-	} else {
+		sr.queues = removeQueue(sr.queues, queue)
+	}
+	sr.lock.Unlock()
+
+	if !exists {
 		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+		return
 	}
+	// The chain is shut down without holding the lock, as the chain may route
+	// messages while it handles the messages it already received
+	queue.handler.Shutdown()
 }
 
 // GetAcceptedFrontier routes an incoming GetAcceptedFrontier request from the
 // validator with ID [validatorID]  to the consensus engine working on the
 // chain with ID [chainID]
 func (sr *ChainRouter) GetAcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.GetAcceptedFrontier(validatorID, requestID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.route(networking.GetAcceptedFrontierMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAcceptedFrontier(traceID, validatorID, requestID)
+	})
 }
 
 // AcceptedFrontier routes an incoming AcceptedFrontier request from the
 // validator with ID [validatorID]  to the consensus engine working on the
 // chain with ID [chainID]
 func (sr *ChainRouter) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.AcceptedFrontier(validatorID, requestID, containerIDs)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.AcceptedFrontier(traceID, validatorID, requestID, containerIDs)
+	}
+	if sr.route(networking.AcceptedFrontierMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
 	}
 }
 
@@ -88,43 +133,30 @@ func (sr *ChainRouter) AcceptedFrontier(validatorID ids.ShortID, chainID ids.ID,
 // request from the validator with ID [validatorID]  to the consensus engine
 // working on the chain with ID [chainID]
 func (sr *ChainRouter) GetAcceptedFrontierFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
 	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.GetAcceptedFrontierFailed(validatorID, requestID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAcceptedFrontierFailed(traceID, validatorID, requestID)
+	})
 }
 
 // GetAccepted routes an incoming GetAccepted request from the
 // validator with ID [validatorID]  to the consensus engine working on the
 // chain with ID [chainID]
 func (sr *ChainRouter) GetAccepted(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.GetAccepted(validatorID, requestID, containerIDs)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.route(networking.GetAcceptedMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAccepted(traceID, validatorID, requestID, containerIDs)
+	})
 }
 
 // Accepted routes an incoming Accepted request from the validator with ID
 // [validatorID]  to the consensus engine working on the chain with ID
 // [chainID]
 func (sr *ChainRouter) Accepted(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Accepted(validatorID, requestID, containerIDs)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.Accepted(traceID, validatorID, requestID, containerIDs)
+	}
+	if sr.route(networking.AcceptedMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
 	}
 }
 
@@ -132,125 +164,183 @@ func (sr *ChainRouter) Accepted(validatorID ids.ShortID, chainID ids.ID, request
 // validator with ID [validatorID]  to the consensus engine working on the
 // chain with ID [chainID]
 func (sr *ChainRouter) GetAcceptedFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
 	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.GetAcceptedFailed(validatorID, requestID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAcceptedFailed(traceID, validatorID, requestID)
+	})
 }
 
 // Get routes an incoming Get request from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) Get(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Get(validatorID, requestID, containerID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.route(networking.GetMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.Get(traceID, validatorID, requestID, containerID)
+	})
 }
 
 // Put routes an incoming Put request from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) Put(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	// This message came in response to a Get message from this node, and when we sent that Get
-	// message we set a timeout. Since we got a response, cancel the timeout.
-	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Put(validatorID, requestID, containerID, container)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.Put(traceID, validatorID, requestID, containerID, container)
+	}
+	if sr.route(networking.PutMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
 	}
 }
 
 // GetFailed routes an incoming GetFailed message from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) GetFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
 	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.GetFailed(validatorID, requestID, containerID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetFailed(traceID, validatorID, requestID, containerID)
+	})
 }
 
 // PushQuery routes an incoming PushQuery request from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) PushQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.PushQuery(validatorID, requestID, containerID, container)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.route(networking.PushQueryMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.PushQuery(traceID, validatorID, requestID, containerID, container)
+	})
 }
 
 // PullQuery routes an incoming PullQuery request from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) PullQuery(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.PullQuery(validatorID, requestID, containerID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
-	}
+	sr.route(networking.PullQueryMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.PullQuery(traceID, validatorID, requestID, containerID)
+	})
 }
 
 // Chits routes an incoming Chits message from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
-
-	// Cancel timeout we set when sent the message asking for these Chits
-	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.Chits(validatorID, requestID, votes)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.Chits(traceID, validatorID, requestID, votes)
+	}
+	if sr.route(networking.ChitsMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
 	}
 }
 
 // QueryFailed routes an incoming QueryFailed message from the validator with ID [validatorID]
 // to the consensus engine working on the chain with ID [chainID]
 func (sr *ChainRouter) QueryFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.QueryFailed(traceID, validatorID, requestID)
+	})
+}
+
+// GetStateSummaries routes an incoming GetStateSummaries request from the
+// validator with ID [validatorID] to the consensus engine working on the chain
+// with ID [chainID]
+func (sr *ChainRouter) GetStateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	sr.route(networking.GetStateSummariesMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetStateSummaries(traceID, validatorID, requestID)
+	})
+}
+
+// StateSummaries routes an incoming StateSummaries message from the validator
+// with ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (sr *ChainRouter) StateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte) {
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.StateSummaries(traceID, validatorID, requestID, summaries)
+	}
+	if sr.route(networking.StateSummariesMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
+	}
+}
 
+// GetStateSummariesFailed routes an incoming GetStateSummariesFailed message
+// from the validator with ID [validatorID] to the consensus engine working on
+// the chain with ID [chainID]
+func (sr *ChainRouter) GetStateSummariesFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
 	sr.timeouts.Cancel(validatorID, chainID, requestID)
-	if chain, exists := sr.chains[chainID.Key()]; exists {
-		chain.QueryFailed(validatorID, requestID)
-	} else {
-		sr.log.Warn("Message referenced a chain, %s, this validator is not validating", chainID)
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetStateSummariesFailed(traceID, validatorID, requestID)
+	})
+}
+
+// GetStateChunk routes an incoming GetStateChunk request from the validator
+// with ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (sr *ChainRouter) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID) {
+	sr.route(networking.GetStateChunkMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetStateChunk(traceID, validatorID, requestID, chunkID)
+	})
+}
+
+// StateChunk routes an incoming StateChunk message from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (sr *ChainRouter) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.StateChunk(traceID, validatorID, requestID, chunkID, chunk)
+	}
+	if sr.route(networking.StateChunkMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
 	}
 }
 
-// Shutdown shuts down this router
-func (sr *ChainRouter) Shutdown() {
-	sr.lock.RLock()
-	defer sr.lock.RUnlock()
+// GetStateChunkFailed routes an incoming GetStateChunkFailed message from the
+// validator with ID [validatorID] to the consensus engine working on the chain
+// with ID [chainID]
+func (sr *ChainRouter) GetStateChunkFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID) {
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetStateChunkFailed(traceID, validatorID, requestID, chunkID)
+	})
+}
 
-	sr.shutdown()
+// GetAncestors routes an incoming GetAncestors request from the validator with
+// ID [validatorID] to the consensus engine working on the chain with ID
+// [chainID]
+func (sr *ChainRouter) GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	sr.route(networking.GetAncestorsMsg, validatorID, chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAncestors(traceID, validatorID, requestID, containerID)
+	})
 }
 
-func (sr *ChainRouter) shutdown() {
-	for _, chain := range sr.chains {
-		chain.Shutdown()
+// MultiPut routes an incoming MultiPut message from the validator with ID
+// [validatorID] to the consensus engine working on the chain with ID [chainID]
+func (sr *ChainRouter) MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte) {
+	deliver := func(chain *handler.Handler, traceID uint64) {
+		chain.MultiPut(traceID, validatorID, requestID, containers)
+	}
+	if sr.route(networking.MultiPutMsg, validatorID, chainID, deliver) {
+		sr.timeouts.Cancel(validatorID, chainID, requestID)
+	}
+}
+
+// GetAncestorsFailed routes an incoming GetAncestorsFailed message from the
+// validator with ID [validatorID] to the consensus engine working on the chain
+// with ID [chainID]
+func (sr *ChainRouter) GetAncestorsFailed(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
+	sr.timeouts.Cancel(validatorID, chainID, requestID)
+	sr.routeInternal(chainID, func(chain *handler.Handler, traceID uint64) {
+		chain.GetAncestorsFailed(traceID, validatorID, requestID)
+	})
+}
+
+// Shutdown shuts down this router and the chains. Messages that are still
+// queued are dropped.
+func (sr *ChainRouter) Shutdown() {
+	sr.lock.Lock()
+	if sr.shutdown {
+		sr.lock.Unlock()
+		return
+	}
+	sr.shutdown = true
+	close(sr.closed)
+	queues := sr.queues
+	sr.chains = make(map[[32]byte]*chainQueue)
+	sr.queues = nil
+	sr.lock.Unlock()
+
+	for _, queue := range queues {
+		queue.handler.Shutdown()
 	}
 }
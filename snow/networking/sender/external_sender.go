@@ -20,4 +20,12 @@ type ExternalSender interface {
 	PushQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
 	PullQuery(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID)
 	Chits(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set)
+
+	GetStateSummaries(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
+	StateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte)
+	GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID)
+	StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte)
+
+	GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
+	MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte)
 }
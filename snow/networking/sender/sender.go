@@ -6,6 +6,7 @@ package sender
 import (
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/networking"
 	"github.com/ava-labs/gecko/snow/networking/router"
 	"github.com/ava-labs/gecko/snow/networking/timeout"
 )
@@ -16,6 +17,9 @@ type Sender struct {
 	sender   ExternalSender // Actually does the sending over the network
 	router   router.Router
 	timeouts *timeout.Manager
+
+	// Counts the requests that time out. May be nil.
+	failures *networking.MessageFailures
 }
 
 // Initialize this sender
@@ -26,6 +30,10 @@ func (s *Sender) Initialize(ctx *snow.Context, sender ExternalSender, router rou
 	s.timeouts = timeouts
 }
 
+// TrackFailures counts the requests that time out in [failures]. Should be
+// called before any requests are sent.
+func (s *Sender) TrackFailures(failures *networking.MessageFailures) { s.failures = failures }
+
 // Context of this sender
 func (s *Sender) Context() *snow.Context { return s.ctx }
 
@@ -39,6 +47,7 @@ func (s *Sender) GetAcceptedFrontier(validatorIDs ids.ShortSet, requestID uint32
 	for _, validatorID := range validatorList {
 		vID := validatorID
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.failures.TimedOut(networking.GetAcceptedFrontierMsg, vID)
 			s.router.GetAcceptedFrontierFailed(vID, s.ctx.ChainID, requestID)
 		})
 	}
@@ -64,6 +73,7 @@ func (s *Sender) GetAccepted(validatorIDs ids.ShortSet, requestID uint32, contai
 	for _, validatorID := range validatorList {
 		vID := validatorID
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.failures.TimedOut(networking.GetAcceptedMsg, vID)
 			s.router.GetAcceptedFailed(vID, s.ctx.ChainID, requestID)
 		})
 	}
@@ -84,10 +94,11 @@ func (s *Sender) Accepted(validatorID ids.ShortID, requestID uint32, containerID
 // consensus engine would like the recipient to send this consensus engine the
 // specified container.
 func (s *Sender) Get(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
-	s.ctx.Log.Verbo("Sending Get to validator %s. RequestID: %d. ContainerID: %s", validatorID, requestID, containerID)
+	s.ctx.ConsensusLog.Verbo("Sending Get to validator %s. RequestID: %d. ContainerID: %s", validatorID, requestID, containerID)
 	// Add a timeout -- if we don't get a response before the timeout expires,
 	// send this consensus engine a GetFailed message
 	s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+		s.failures.TimedOut(networking.GetMsg, validatorID)
 		s.router.GetFailed(validatorID, s.ctx.ChainID, requestID, containerID)
 	})
 	s.sender.Get(validatorID, s.ctx.ChainID, requestID, containerID)
@@ -98,7 +109,7 @@ func (s *Sender) Get(validatorID ids.ShortID, requestID uint32, containerID ids.
 // The Put message signifies that this consensus engine is giving to the recipient
 // the contents of the specified container.
 func (s *Sender) Put(validatorID ids.ShortID, requestID uint32, containerID ids.ID, container []byte) {
-	s.ctx.Log.Verbo("Sending Put to validator %s. RequestID: %d. ContainerID: %s", validatorID, requestID, containerID)
+	s.ctx.ConsensusLog.Verbo("Sending Put to validator %s. RequestID: %d. ContainerID: %s", validatorID, requestID, containerID)
 	s.sender.Put(validatorID, s.ctx.ChainID, requestID, containerID, container)
 }
 
@@ -107,7 +118,7 @@ func (s *Sender) Put(validatorID ids.ShortID, requestID uint32, containerID ids.
 // The PushQuery message signifies that this consensus engine would like each validator to send
 // their preferred frontier given the existence of the specified container.
 func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containerID ids.ID, container []byte) {
-	s.ctx.Log.Verbo("Sending PushQuery to validators %v. RequestID: %d. ContainerID: %s", validatorIDs, requestID, containerID)
+	s.ctx.ConsensusLog.Verbo("Sending PushQuery to validators %v. RequestID: %d. ContainerID: %s", validatorIDs, requestID, containerID)
 	// If one of the validators in [validatorIDs] is myself, send this message directly
 	// to my own router rather than sending it over the network
 	if validatorIDs.Contains(s.ctx.NodeID) { // One of the validators in [validatorIDs] was myself
@@ -121,6 +132,7 @@ func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 	for _, validatorID := range validatorList {
 		vID := validatorID
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.failures.TimedOut(networking.PushQueryMsg, vID)
 			s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
 		})
 	}
@@ -132,7 +144,7 @@ func (s *Sender) PushQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 // The PullQuery message signifies that this consensus engine would like each validator to send
 // their preferred frontier.
 func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containerID ids.ID) {
-	s.ctx.Log.Verbo("Sending PullQuery. RequestID: %d. ContainerID: %s", requestID, containerID)
+	s.ctx.ConsensusLog.Verbo("Sending PullQuery. RequestID: %d. ContainerID: %s", requestID, containerID)
 	// If one of the validators in [validatorIDs] is myself, send this message directly
 	// to my own router rather than sending it over the network
 	if validatorIDs.Contains(s.ctx.NodeID) { // One of the validators in [validatorIDs] was myself
@@ -146,6 +158,7 @@ func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 	for _, validatorID := range validatorList {
 		vID := validatorID
 		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.failures.TimedOut(networking.PullQueryMsg, vID)
 			s.router.QueryFailed(vID, s.ctx.ChainID, requestID)
 		})
 	}
@@ -154,12 +167,82 @@ func (s *Sender) PullQuery(validatorIDs ids.ShortSet, requestID uint32, containe
 
 // Chits sends chits
 func (s *Sender) Chits(validatorID ids.ShortID, requestID uint32, votes ids.Set) {
-	s.ctx.Log.Verbo("Sending Chits to validator %s. RequestID: %d. Votes: %s", validatorID, requestID, votes)
+	s.ctx.ConsensusLog.Verbo("Sending Chits to validator %s. RequestID: %d. Votes: %s", validatorID, requestID, votes)
 	// If [validatorID] is myself, send this message directly
 	// to my own router rather than sending it over the network
 	if validatorID.Equals(s.ctx.NodeID) {
 		go s.router.Chits(validatorID, s.ctx.ChainID, requestID, votes)
 		return
 	}
+	// A read-only node never votes for other validators. It still answers
+	// its own queries, so its own polls finish.
+	if s.ctx.ReadOnly {
+		s.ctx.ConsensusLog.Verbo("Not sending Chits to validator %s because this node is read-only. RequestID: %d", validatorID, requestID)
+		return
+	}
 	s.sender.Chits(validatorID, s.ctx.ChainID, requestID, votes)
 }
+
+// GetStateSummaries sends a GetStateSummaries message to the specified
+// validators, asking for the summaries of the state their VMs can serve.
+func (s *Sender) GetStateSummaries(validatorIDs ids.ShortSet, requestID uint32) {
+	s.ctx.ConsensusLog.Verbo("Sending GetStateSummaries to validators %v. RequestID: %d", validatorIDs, requestID)
+	if validatorIDs.Contains(s.ctx.NodeID) {
+		validatorIDs.Remove(s.ctx.NodeID)
+		go s.router.GetStateSummaries(s.ctx.NodeID, s.ctx.ChainID, requestID)
+	}
+	validatorList := validatorIDs.List()
+	for _, validatorID := range validatorList {
+		vID := validatorID
+		s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+			s.failures.TimedOut(networking.GetStateSummariesMsg, vID)
+			s.router.GetStateSummariesFailed(vID, s.ctx.ChainID, requestID)
+		})
+	}
+	s.sender.GetStateSummaries(validatorIDs, s.ctx.ChainID, requestID)
+}
+
+// StateSummaries sends a StateSummaries message to the specified validator
+func (s *Sender) StateSummaries(validatorID ids.ShortID, requestID uint32, summaries [][]byte) {
+	s.ctx.ConsensusLog.Verbo("Sending StateSummaries to validator %s. RequestID: %d. NumSummaries: %d", validatorID, requestID, len(summaries))
+	if validatorID.Equals(s.ctx.NodeID) {
+		go s.router.StateSummaries(validatorID, s.ctx.ChainID, requestID, summaries)
+		return
+	}
+	s.sender.StateSummaries(validatorID, s.ctx.ChainID, requestID, summaries)
+}
+
+// GetStateChunk sends a GetStateChunk message to the specified validator,
+// asking for the chunk of state whose ID is [chunkID]
+func (s *Sender) GetStateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID) {
+	s.ctx.ConsensusLog.Verbo("Sending GetStateChunk to validator %s. RequestID: %d. ChunkID: %s", validatorID, requestID, chunkID)
+	s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+		s.failures.TimedOut(networking.GetStateChunkMsg, validatorID)
+		s.router.GetStateChunkFailed(validatorID, s.ctx.ChainID, requestID, chunkID)
+	})
+	s.sender.GetStateChunk(validatorID, s.ctx.ChainID, requestID, chunkID)
+}
+
+// StateChunk sends a StateChunk message to the specified validator
+func (s *Sender) StateChunk(validatorID ids.ShortID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	s.ctx.ConsensusLog.Verbo("Sending StateChunk to validator %s. RequestID: %d. ChunkID: %s", validatorID, requestID, chunkID)
+	s.sender.StateChunk(validatorID, s.ctx.ChainID, requestID, chunkID, chunk)
+}
+
+// GetAncestors sends a GetAncestors message to the specified validator, asking
+// for the container whose ID is [containerID] and as many of its ancestors as
+// fit in the response
+func (s *Sender) GetAncestors(validatorID ids.ShortID, requestID uint32, containerID ids.ID) {
+	s.ctx.ConsensusLog.Verbo("Sending GetAncestors to validator %s. RequestID: %d. ContainerID: %s", validatorID, requestID, containerID)
+	s.timeouts.Register(validatorID, s.ctx.ChainID, requestID, func() {
+		s.failures.TimedOut(networking.GetAncestorsMsg, validatorID)
+		s.router.GetAncestorsFailed(validatorID, s.ctx.ChainID, requestID)
+	})
+	s.sender.GetAncestors(validatorID, s.ctx.ChainID, requestID, containerID)
+}
+
+// MultiPut sends a MultiPut message to the specified validator
+func (s *Sender) MultiPut(validatorID ids.ShortID, requestID uint32, containers [][]byte) {
+	s.ctx.ConsensusLog.Verbo("Sending MultiPut to validator %s. RequestID: %d. NumContainers: %d", validatorID, requestID, len(containers))
+	s.sender.MultiPut(validatorID, s.ctx.ChainID, requestID, containers)
+}
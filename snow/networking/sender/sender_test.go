@@ -46,7 +46,7 @@ func TestTimeout(t *testing.T) {
 	handler.Initialize(&engine, nil, 1)
 	go handler.Dispatch()
 
-	router.AddChain(&handler)
+	router.AddChain(&handler, 1)
 
 	vdrIDs := ids.ShortSet{}
 	vdrIDs.Add(ids.NewShortID([20]byte{255}))
@@ -60,3 +60,49 @@ func TestTimeout(t *testing.T) {
 		t.Fatalf("Timeouts should have fired")
 	}
 }
+
+func TestReadOnlyChits(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+	ctx.ReadOnly = true
+
+	external := &ExternalSenderTest{T: t}
+	external.Default(true)
+
+	tm := timeout.Manager{}
+	tm.Initialize(time.Hour)
+
+	router := router.ChainRouter{}
+	router.Initialize(logging.NoLog{}, &tm)
+
+	sender := Sender{}
+	sender.Initialize(ctx, external, &router, &tm)
+
+	// Fails the test if the chits are sent
+	sender.Chits(ids.NewShortID([20]byte{255}), 0, ids.Set{})
+
+	engine := common.EngineTest{T: t}
+	engine.Default(true)
+
+	engine.ContextF = func() *snow.Context { return ctx }
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	engine.ChitsF = func(validatorID ids.ShortID, requestID uint32, _ ids.Set) {
+		if !validatorID.Equals(ctx.NodeID) || requestID != 1 {
+			t.Fatalf("Wrong chits received")
+		}
+		wg.Done()
+	}
+
+	handler := handler.Handler{}
+	handler.Initialize(&engine, nil, 1)
+	go handler.Dispatch()
+
+	router.AddChain(&handler, 1)
+
+	// The chits of a read-only node to itself are still delivered
+	sender.Chits(ctx.NodeID, 1, ids.Set{})
+
+	wg.Wait()
+}
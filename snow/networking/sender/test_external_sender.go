@@ -17,7 +17,10 @@ type ExternalSenderTest struct {
 	CantGetAcceptedFrontier, CantAcceptedFrontier,
 	CantGetAccepted, CantAccepted,
 	CantGet, CantPut,
-	CantPullQuery, CantPushQuery, CantChits bool
+	CantPullQuery, CantPushQuery, CantChits,
+	CantGetStateSummaries, CantStateSummaries,
+	CantGetStateChunk, CantStateChunk,
+	CantGetAncestors, CantMultiPut bool
 
 	GetAcceptedFrontierF func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
 	AcceptedFrontierF    func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerIDs ids.Set)
@@ -28,6 +31,12 @@ type ExternalSenderTest struct {
 	PushQueryF           func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID, container []byte)
 	PullQueryF           func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32, containerID ids.ID)
 	ChitsF               func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, votes ids.Set)
+	GetStateSummariesF   func(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32)
+	StateSummariesF      func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte)
+	GetStateChunkF       func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID)
+	StateChunkF          func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte)
+	GetAncestorsF        func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID)
+	MultiPutF            func(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte)
 }
 
 // Default set the default callable value to [cant]
@@ -41,6 +50,12 @@ func (s *ExternalSenderTest) Default(cant bool) {
 	s.CantPullQuery = cant
 	s.CantPushQuery = cant
 	s.CantChits = cant
+	s.CantGetStateSummaries = cant
+	s.CantStateSummaries = cant
+	s.CantGetStateChunk = cant
+	s.CantStateChunk = cant
+	s.CantGetAncestors = cant
+	s.CantMultiPut = cant
 }
 
 // GetAcceptedFrontier calls GetAcceptedFrontierF if it was initialized. If it
@@ -159,3 +174,81 @@ func (s *ExternalSenderTest) Chits(vdr ids.ShortID, chainID ids.ID, requestID ui
 		s.B.Fatalf("Unexpectedly called Chits")
 	}
 }
+
+// GetStateSummaries calls GetStateSummariesF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) GetStateSummaries(validatorIDs ids.ShortSet, chainID ids.ID, requestID uint32) {
+	if s.GetStateSummariesF != nil {
+		s.GetStateSummariesF(validatorIDs, chainID, requestID)
+	} else if s.CantGetStateSummaries && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateSummaries")
+	} else if s.CantGetStateSummaries && s.B != nil {
+		s.B.Fatalf("Unexpectedly called GetStateSummaries")
+	}
+}
+
+// StateSummaries calls StateSummariesF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) StateSummaries(validatorID ids.ShortID, chainID ids.ID, requestID uint32, summaries [][]byte) {
+	if s.StateSummariesF != nil {
+		s.StateSummariesF(validatorID, chainID, requestID, summaries)
+	} else if s.CantStateSummaries && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateSummaries")
+	} else if s.CantStateSummaries && s.B != nil {
+		s.B.Fatalf("Unexpectedly called StateSummaries")
+	}
+}
+
+// GetStateChunk calls GetStateChunkF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) GetStateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID) {
+	if s.GetStateChunkF != nil {
+		s.GetStateChunkF(validatorID, chainID, requestID, chunkID)
+	} else if s.CantGetStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetStateChunk")
+	} else if s.CantGetStateChunk && s.B != nil {
+		s.B.Fatalf("Unexpectedly called GetStateChunk")
+	}
+}
+
+// StateChunk calls StateChunkF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) StateChunk(validatorID ids.ShortID, chainID ids.ID, requestID uint32, chunkID ids.ID, chunk []byte) {
+	if s.StateChunkF != nil {
+		s.StateChunkF(validatorID, chainID, requestID, chunkID, chunk)
+	} else if s.CantStateChunk && s.T != nil {
+		s.T.Fatalf("Unexpectedly called StateChunk")
+	} else if s.CantStateChunk && s.B != nil {
+		s.B.Fatalf("Unexpectedly called StateChunk")
+	}
+}
+
+// GetAncestors calls GetAncestorsF if it was initialized. If it wasn't
+// initialized and this function shouldn't be called and testing was
+// initialized, then testing will fail.
+func (s *ExternalSenderTest) GetAncestors(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containerID ids.ID) {
+	if s.GetAncestorsF != nil {
+		s.GetAncestorsF(validatorID, chainID, requestID, containerID)
+	} else if s.CantGetAncestors && s.T != nil {
+		s.T.Fatalf("Unexpectedly called GetAncestors")
+	} else if s.CantGetAncestors && s.B != nil {
+		s.B.Fatalf("Unexpectedly called GetAncestors")
+	}
+}
+
+// MultiPut calls MultiPutF if it was initialized. If it wasn't initialized and
+// this function shouldn't be called and testing was initialized, then testing
+// will fail.
+func (s *ExternalSenderTest) MultiPut(validatorID ids.ShortID, chainID ids.ID, requestID uint32, containers [][]byte) {
+	if s.MultiPutF != nil {
+		s.MultiPutF(validatorID, chainID, requestID, containers)
+	} else if s.CantMultiPut && s.T != nil {
+		s.T.Fatalf("Unexpectedly called MultiPut")
+	} else if s.CantMultiPut && s.B != nil {
+		s.B.Fatalf("Unexpectedly called MultiPut")
+	}
+}
@@ -0,0 +1,179 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package throttling
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Config of a throttler. A limit of 0 doesn't bound anything.
+type Config struct {
+	// Number of bytes that can be exchanged with each peer per second
+	BytesPerSec uint64
+
+	// Number of messages of each type that can be exchanged with each peer per
+	// second
+	MsgsPerSec uint64
+
+	// A peer can exceed its limits by a burst of up to [Burst] worth of bytes
+	// and messages, after a quiet period
+	Burst time.Duration
+
+	// A peer whose messages are throttled more than [MaxViolations] times
+	// within [ViolationWindow] is abusive. If [MaxViolations] is 0, no peer is
+	// abusive.
+	MaxViolations   uint64
+	ViolationWindow time.Duration
+}
+
+// bucket of tokens that refills at a constant rate, up to its capacity
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take [n] tokens from the bucket at time [now], if it holds them. A bucket
+// that's full can always be taken from, so a message larger than the burst is
+// let through once the peer has been quiet long enough, and the bucket goes
+// into debt.
+func (b *bucket) take(n, rate, capacity float64, now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > capacity {
+		b.tokens = capacity
+	}
+	b.last = now
+	if b.tokens < n && b.tokens < capacity {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+type peer struct {
+	bytes bucket
+	msgs  map[string]*bucket // message type --> bucket
+
+	lastActive  time.Time
+	violations  uint64
+	windowStart time.Time
+}
+
+// Throttler bounds the bytes and messages exchanged with each peer, and
+// reports the peers that keep exceeding their bounds. It's safe for concurrent
+// use.
+type Throttler struct {
+	config Config
+
+	lock      sync.Mutex
+	clock     timer.Clock
+	peers     map[[20]byte]*peer
+	lastPrune time.Time
+}
+
+// New returns a throttler that bounds each peer by [config]
+func New(config Config) *Throttler {
+	t := &Throttler{
+		config: config,
+		peers:  make(map[[20]byte]*peer),
+	}
+	t.lastPrune = t.clock.Time()
+	return t
+}
+
+// Allow returns true if a [msgType] message of [size] bytes can be exchanged
+// with [peerID] now. If it returns false, the message should be dropped, and
+// it counts as a violation of the peer's bounds.
+func (t *Throttler) Allow(peerID ids.ShortID, msgType string, size int) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	t.prune(now)
+
+	p := t.peer(peerID, now)
+	p.lastActive = now
+
+	burst := t.config.Burst.Seconds()
+	bytesRate := float64(t.config.BytesPerSec)
+	msgsRate := float64(t.config.MsgsPerSec)
+
+	// Tokens are only taken if the message is let through, so the bytes are
+	// checked against a copy of their bucket
+	bytes := p.bytes
+	allowed := t.config.BytesPerSec == 0 || bytes.take(float64(size), bytesRate, bytesRate*burst, now)
+	if allowed && t.config.MsgsPerSec > 0 {
+		msgs, exists := p.msgs[msgType]
+		if !exists {
+			msgs = &bucket{tokens: msgsRate * burst, last: now}
+			p.msgs[msgType] = msgs
+		}
+		allowed = msgs.take(1, msgsRate, msgsRate*burst, now)
+	}
+	if !allowed {
+		if now.Sub(p.windowStart) > t.config.ViolationWindow {
+			p.violations = 0
+			p.windowStart = now
+		}
+		p.violations++
+		return false
+	}
+	p.bytes = bytes
+	return true
+}
+
+// Abusive returns true if [peerID] exceeded its bounds more than the maximum
+// number of times within the current window
+func (t *Throttler) Abusive(peerID ids.ShortID) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p, exists := t.peers[peerID.Key()]
+	return exists &&
+		t.config.MaxViolations > 0 &&
+		p.violations > t.config.MaxViolations &&
+		t.clock.Time().Sub(p.windowStart) <= t.config.ViolationWindow
+}
+
+// assumes the lock is held
+func (t *Throttler) peer(peerID ids.ShortID, now time.Time) *peer {
+	key := peerID.Key()
+	p, exists := t.peers[key]
+	if !exists {
+		burst := t.config.Burst.Seconds()
+		p = &peer{
+			bytes:       bucket{tokens: float64(t.config.BytesPerSec) * burst, last: now},
+			msgs:        make(map[string]*bucket),
+			windowStart: now,
+		}
+		t.peers[key] = p
+	}
+	return p
+}
+
+// prune forgets the peers that have been idle for longer than it takes their
+// buckets to refill, and outside of their violation window, since forgetting
+// them doesn't change how they're throttled. Assumes the lock is held.
+func (t *Throttler) prune(now time.Time) {
+	idle := t.config.Burst
+	if t.config.ViolationWindow > idle {
+		idle = t.config.ViolationWindow
+	}
+	if now.Sub(t.lastPrune) <= idle {
+		return
+	}
+	t.lastPrune = now
+
+	rate := float64(t.config.BytesPerSec)
+	capacity := rate * t.config.Burst.Seconds()
+	for key, p := range t.peers {
+		refilled := p.bytes.tokens + now.Sub(p.bytes.last).Seconds()*rate
+		if now.Sub(p.lastActive) > idle && refilled >= capacity {
+			delete(t.peers, key)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package throttling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestThrottlerBytes(t *testing.T) {
+	throttler := New(Config{
+		BytesPerSec: 100,
+		Burst:       time.Second,
+	})
+	now := time.Now()
+	throttler.clock.Set(now)
+	peerID := ids.NewShortID([20]byte{1})
+
+	if !throttler.Allow(peerID, "put", 60) {
+		t.Fatalf("Should have allowed a message within the burst")
+	}
+	if throttler.Allow(peerID, "put", 60) {
+		t.Fatalf("Shouldn't have allowed a message past the burst")
+	}
+	if !throttler.Allow(ids.NewShortID([20]byte{2}), "put", 60) {
+		t.Fatalf("Should have bounded each peer separately")
+	}
+
+	throttler.clock.Set(now.Add(200 * time.Millisecond))
+	if !throttler.Allow(peerID, "put", 60) {
+		t.Fatalf("Should have allowed a message once the bytes refilled")
+	}
+
+	// A message larger than the burst is allowed once the peer was quiet
+	throttler.clock.Set(now.Add(2 * time.Second))
+	if !throttler.Allow(peerID, "put", 500) {
+		t.Fatalf("Should have allowed a large message after a quiet period")
+	}
+	throttler.clock.Set(now.Add(4 * time.Second))
+	if throttler.Allow(peerID, "put", 1) {
+		t.Fatalf("Shouldn't have allowed a message before the large message was paid for")
+	}
+}
+
+func TestThrottlerMsgsAndAbuse(t *testing.T) {
+	throttler := New(Config{
+		MsgsPerSec:      2,
+		Burst:           time.Second,
+		MaxViolations:   2,
+		ViolationWindow: time.Minute,
+	})
+	now := time.Now()
+	throttler.clock.Set(now)
+	peerID := ids.NewShortID([20]byte{1})
+
+	for i := 0; i < 2; i++ {
+		if !throttler.Allow(peerID, "push_query", 1) {
+			t.Fatalf("Should have allowed message %d", i)
+		}
+	}
+	if !throttler.Allow(peerID, "get", 1) {
+		t.Fatalf("Should have bounded each message type separately")
+	}
+	for i := 0; i < 3; i++ {
+		if throttler.Allow(peerID, "push_query", 1) {
+			t.Fatalf("Shouldn't have allowed a message past the limit")
+		}
+	}
+	if !throttler.Abusive(peerID) {
+		t.Fatalf("Should have reported the peer as abusive")
+	}
+
+	throttler.clock.Set(now.Add(2 * time.Minute))
+	if throttler.Abusive(peerID) {
+		t.Fatalf("Should have forgiven the peer after the violation window")
+	}
+	if !throttler.Allow(peerID, "push_query", 1) {
+		t.Fatalf("Should have allowed a message after the peer was quiet")
+	}
+}
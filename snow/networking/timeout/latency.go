@@ -0,0 +1,57 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timeout
+
+import (
+	"time"
+)
+
+// latency estimates the response latency of a validator the way TCP estimates
+// a connection's round trip time (RFC 6298): as a moving average of the
+// samples, and a moving average of their deviation from it. Like TCP, the
+// timeout is doubled each time a request times out, until a response arrives.
+type latency struct {
+	mean, deviation time.Duration
+	observed        bool
+
+	// If non-zero, the timeout after requests timed out since the last
+	// response
+	backoff time.Duration
+}
+
+// observe a response that took [sample]
+func (l *latency) observe(sample time.Duration) {
+	l.backoff = 0
+	if !l.observed {
+		l.mean = sample
+		l.deviation = sample / 2
+		l.observed = true
+		return
+	}
+
+	diff := l.mean - sample
+	if diff < 0 {
+		diff = -diff
+	}
+	l.deviation = (3*l.deviation + diff) / 4
+	l.mean = (7*l.mean + sample) / 8
+}
+
+// timedOut records that a request that was given [timeout] timed out, so the
+// next requests are given twice as long, up to [ceiling]
+func (l *latency) timedOut(timeout, ceiling time.Duration) {
+	l.backoff = 2 * timeout
+	if l.backoff > ceiling {
+		l.backoff = ceiling
+	}
+}
+
+// timeout returns how long a request should be given before it's considered
+// lost
+func (l *latency) timeout() time.Duration {
+	if l.backoff > 0 {
+		return l.backoff
+	}
+	return l.mean + 4*l.deviation
+}
@@ -4,16 +4,35 @@
 package timeout
 
 import (
+	"sync"
 	"time"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/wrappers"
 )
 
 // Manager registers and fires timeouts for the snow API.
-type Manager struct{ tm timer.TimeoutManager }
+type Manager struct {
+	tm timer.TimeoutManager
+
+	// If non-nil, the responsiveness of validators is reported to the tracker
+	tracker  *uptime.Tracker
+	clock    timer.Clock
+	lock     sync.Mutex
+	requests map[[32]byte]time.Time // request ID --> time the request was sent
+
+	// If [adaptive], a request times out after a duration that is derived from
+	// the response latencies of the validator it was sent to, and bounded by
+	// [floor] and [ceiling]. Otherwise, it times out after [duration], which is
+	// also used for validators that haven't responded yet.
+	duration       time.Duration
+	adaptive       bool
+	floor, ceiling time.Duration
+	latencies      map[[20]byte]*latency // validator ID --> response latency
+}
 
 // Initialize this timeout manager.
 //
@@ -22,7 +41,67 @@ type Manager struct{ tm timer.TimeoutManager }
 //
 // [duration] is the amount of time to allow for external requests
 // before the request times out.
-func (m *Manager) Initialize(duration time.Duration) { m.tm.Initialize(duration) }
+func (m *Manager) Initialize(duration time.Duration) {
+	m.duration = duration
+	m.tm.InitializeWithClock(duration, &m.clock)
+}
+
+// Clock returns the clock that timeouts and response times are measured with
+func (m *Manager) Clock() *timer.Clock { return &m.clock }
+
+// Timeout fires the requests that have timed out
+func (m *Manager) Timeout() { m.tm.Timeout() }
+
+// Track the responsiveness of the validators requests are sent to, and report
+// it to [tracker]. Should be called before any requests are registered.
+func (m *Manager) Track(tracker *uptime.Tracker) {
+	m.tracker = tracker
+	if m.requests == nil {
+		m.requests = make(map[[32]byte]time.Time)
+	}
+}
+
+// Adapt the timeouts of requests to the response latencies of the validators
+// they're sent to, within [floor] and [ceiling]. Should be called before any
+// requests are registered.
+func (m *Manager) Adapt(floor, ceiling time.Duration) {
+	m.adaptive = true
+	m.floor = floor
+	m.ceiling = ceiling
+	m.latencies = make(map[[20]byte]*latency)
+	if m.requests == nil {
+		m.requests = make(map[[32]byte]time.Time)
+	}
+}
+
+// TimeoutDuration returns how long requests sent to [validatorID] have to be
+// responded to
+func (m *Manager) TimeoutDuration(validatorID ids.ShortID) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.timeoutDuration(validatorID)
+}
+
+// Assumes the lock is held
+func (m *Manager) timeoutDuration(validatorID ids.ShortID) time.Duration {
+	if !m.adaptive {
+		return m.duration
+	}
+
+	duration := m.duration
+	if l, ok := m.latencies[validatorID.Key()]; ok && (l.observed || l.backoff > 0) {
+		duration = l.timeout()
+	}
+	switch {
+	case duration < m.floor:
+		return m.floor
+	case duration > m.ceiling:
+		return m.ceiling
+	default:
+		return duration
+	}
+}
 
 // Dispatch ...
 func (m *Manager) Dispatch() { m.tm.Dispatch() }
@@ -30,12 +109,93 @@ func (m *Manager) Dispatch() { m.tm.Dispatch() }
 // Register request to time out unless Manager.Cancel is called
 // before the timeout duration passes, with the same request parameters.
 func (m *Manager) Register(validatorID ids.ShortID, chainID ids.ID, requestID uint32, timeout func()) {
-	m.tm.Put(createRequestID(validatorID, chainID, requestID), timeout)
+	id := createRequestID(validatorID, chainID, requestID)
+	if m.requests == nil {
+		m.tm.Put(id, timeout)
+		return
+	}
+
+	m.lock.Lock()
+	m.requests[id.Key()] = m.clock.Time()
+	duration := m.timeoutDuration(validatorID)
+	m.lock.Unlock()
+
+	m.tm.PutWithDuration(id, duration, func() {
+		if _, ok := m.remove(id); ok {
+			if m.tracker != nil {
+				m.tracker.TimedOut(validatorID)
+			}
+			m.timedOut(validatorID, duration)
+		}
+		timeout()
+	})
 }
 
 // Cancel request timeout with the specified parameters.
 func (m *Manager) Cancel(validatorID ids.ShortID, chainID ids.ID, requestID uint32) {
-	m.tm.Remove(createRequestID(validatorID, chainID, requestID))
+	id := createRequestID(validatorID, chainID, requestID)
+	m.tm.Remove(id)
+
+	if m.requests == nil {
+		return
+	}
+	if sent, ok := m.remove(id); ok {
+		latency := m.clock.Time().Sub(sent)
+		if m.tracker != nil {
+			m.tracker.Responded(validatorID, latency)
+		}
+		m.observe(validatorID, latency)
+	}
+}
+
+// observe that a request to [validatorID] took [sample] to be responded to
+func (m *Manager) observe(validatorID ids.ShortID, sample time.Duration) {
+	if !m.adaptive {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := validatorID.Key()
+	l, ok := m.latencies[key]
+	if !ok {
+		l = &latency{}
+		m.latencies[key] = l
+	}
+	l.observe(sample)
+}
+
+// timedOut records that a request to [validatorID] that was given [duration]
+// timed out. The validator's timeout is backed off (RFC 6298 section 5.5) until
+// it responds again. A timeout isn't a latency sample, so the estimate of its
+// latency isn't changed.
+func (m *Manager) timedOut(validatorID ids.ShortID, duration time.Duration) {
+	if !m.adaptive {
+		return
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := validatorID.Key()
+	l, ok := m.latencies[key]
+	if !ok {
+		l = &latency{}
+		m.latencies[key] = l
+	}
+	l.timedOut(duration, m.ceiling)
+}
+
+// remove the pending request [id], returning when it was sent
+func (m *Manager) remove(id ids.ID) (time.Time, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := id.Key()
+	sent, ok := m.requests[key]
+	delete(m.requests, key)
+	return sent, ok
 }
 
 func createRequestID(validatorID ids.ShortID, chainID ids.ID, requestID uint32) ids.ID {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/uptime"
 )
 
 func TestManagerFire(t *testing.T) {
@@ -46,3 +47,146 @@ func TestManagerCancel(t *testing.T) {
 		t.Fatalf("Should have cancelled the function")
 	}
 }
+
+func TestManagerTrack(t *testing.T) {
+	tracker := uptime.NewTracker()
+
+	manager := Manager{}
+	manager.Initialize(time.Millisecond)
+	manager.Track(tracker)
+	go manager.Dispatch()
+
+	vdrID := ids.NewShortID([20]byte{1})
+	chainID := ids.NewID([32]byte{})
+
+	manager.Register(vdrID, chainID, 0, func() {})
+	manager.Cancel(vdrID, chainID, 0)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	manager.Register(vdrID, chainID, 1, wg.Done)
+
+	wg.Wait()
+
+	perf := tracker.Performance(vdrID, time.Time{})
+	if perf.Responses != 1 {
+		t.Fatalf("Should have recorded %d response but recorded %d", 1, perf.Responses)
+	}
+	if perf.Timeouts != 1 {
+		t.Fatalf("Should have recorded %d timeout but recorded %d", 1, perf.Timeouts)
+	}
+}
+
+func TestManagerClock(t *testing.T) {
+	tracker := uptime.NewTracker()
+
+	manager := Manager{}
+	manager.Initialize(time.Hour)
+	manager.Track(tracker)
+	manager.Clock().Set(time.Unix(1000, 0))
+
+	vdrID := ids.NewShortID([20]byte{1})
+	chainID := ids.NewID([32]byte{})
+
+	manager.Register(vdrID, chainID, 0, func() {})
+	manager.Clock().Advance(time.Second)
+	manager.Cancel(vdrID, chainID, 0)
+
+	fired := false
+	manager.Register(vdrID, chainID, 1, func() { fired = true })
+	manager.Clock().Advance(2 * time.Hour)
+	manager.Timeout()
+
+	if !fired {
+		t.Fatalf("Should have timed out the request")
+	}
+	perf := tracker.Performance(vdrID, time.Time{})
+	if perf.Responses != 1 || perf.Timeouts != 1 {
+		t.Fatalf("Should have recorded 1 response and 1 timeout but recorded %d and %d", perf.Responses, perf.Timeouts)
+	}
+}
+
+func TestManagerAdapt(t *testing.T) {
+	manager := Manager{}
+	manager.Initialize(2 * time.Second)
+	manager.Adapt(500*time.Millisecond, 10*time.Second)
+	manager.Clock().Set(time.Unix(1000, 0))
+
+	fastID := ids.NewShortID([20]byte{1})
+	slowID := ids.NewShortID([20]byte{2})
+	chainID := ids.NewID([32]byte{})
+
+	if duration := manager.TimeoutDuration(fastID); duration != 2*time.Second {
+		t.Fatalf("Validator without responses should have the default timeout but has %s", duration)
+	}
+
+	for i := uint32(0); i < 10; i++ {
+		manager.Register(fastID, chainID, i, func() {})
+		manager.Register(slowID, chainID, i, func() {})
+		manager.Clock().Advance(10 * time.Millisecond)
+		manager.Cancel(fastID, chainID, i)
+		manager.Clock().Advance(time.Second)
+		manager.Cancel(slowID, chainID, i)
+	}
+
+	if duration := manager.TimeoutDuration(fastID); duration != 500*time.Millisecond {
+		t.Fatalf("Fast validator's timeout should be the floor but is %s", duration)
+	}
+	slowDuration := manager.TimeoutDuration(slowID)
+	if slowDuration <= time.Second || slowDuration >= 2*time.Second {
+		t.Fatalf("Slow validator's timeout should be just over its latency but is %s", slowDuration)
+	}
+
+	// A dead validator is detected at its own deadline
+	fastFired := false
+	slowFired := false
+	manager.Register(fastID, chainID, 10, func() { fastFired = true })
+	manager.Register(slowID, chainID, 10, func() { slowFired = true })
+	manager.Clock().Advance(time.Second)
+	manager.Timeout()
+	if !fastFired || slowFired {
+		t.Fatalf("Only the fast validator's request should have timed out")
+	}
+	manager.Clock().Advance(time.Second)
+	manager.Timeout()
+	if !slowFired {
+		t.Fatalf("The slow validator's request should have timed out")
+	}
+
+	// Each timeout doubles the validator's timeout, up to the ceiling
+	expected := time.Second
+	for i := uint32(11); i < 20; i++ {
+		if duration := manager.TimeoutDuration(fastID); duration != expected {
+			t.Fatalf("Fast validator's timeout should have backed off to %s but is %s", expected, duration)
+		}
+		fired := false
+		manager.Register(fastID, chainID, i, func() { fired = true })
+		manager.Clock().Advance(expected + time.Millisecond)
+		manager.Timeout()
+		if !fired {
+			t.Fatalf("Fast validator's request should have timed out")
+		}
+		if expected *= 2; expected > 10*time.Second {
+			expected = 10 * time.Second
+		}
+	}
+
+	// A response ends the backoff, and timeouts don't change the estimate of
+	// the validator's latency
+	manager.Register(fastID, chainID, 20, func() {})
+	manager.Clock().Advance(10 * time.Millisecond)
+	manager.Cancel(fastID, chainID, 20)
+	if duration := manager.TimeoutDuration(fastID); duration != 500*time.Millisecond {
+		t.Fatalf("Fast validator's timeout should be the floor after it responded but is %s", duration)
+	}
+
+	// Validators that never responded back off from the default timeout
+	newID := ids.NewShortID([20]byte{3})
+	manager.Register(newID, chainID, 0, func() {})
+	manager.Clock().Advance(2*time.Second + time.Millisecond)
+	manager.Timeout()
+	if duration := manager.TimeoutDuration(newID); duration != 4*time.Second {
+		t.Fatalf("New validator's timeout should have backed off to %s but is %s", 4*time.Second, duration)
+	}
+}
@@ -0,0 +1,164 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package pruning deletes the containers that a chain rejected from the chain's
+// database: rejected blocks, vertices and transactions. Their statuses are
+// kept, so they're never issued again.
+//
+// Spent UTXOs aren't pruned here. The AVM deletes a UTXO, and its entry in the
+// funds of each of its addresses, when the transaction that spends it is
+// accepted, so they never accumulate. The accepted containers are kept, as
+// peers bootstrap from them.
+package pruning
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// maxPassSize is the maximum number of keys deleted by a pass
+const maxPassSize = 4096
+
+// Pruner deletes the keys that were scheduled to be pruned from a database in
+// background passes. The scheduled keys are queued in another database, by the
+// order they were scheduled in, so they are pruned even if the node restarts
+// before they are.
+//
+// A key is only deleted by the pass after the one that follows its scheduling,
+// so the decision that made it unreachable has been committed by then.
+type Pruner struct {
+	log   logging.Logger
+	db    database.Database // database the keys are deleted from
+	queue database.Database // sequence number --> key scheduled to be pruned
+
+	lock sync.Mutex
+	// The keys in [head, ready) can be pruned by the next pass, and the keys
+	// in [ready, tail) by the pass after it
+	head, ready, tail uint64
+
+	// If non-nil, prunes the database periodically
+	repeater *timer.Repeater
+}
+
+// New returns a pruner that deletes the keys scheduled to be pruned from [db].
+// The scheduled keys are queued in [queue], which must be used by one pruner
+// only. If [frequency] is positive, a pass is run every [frequency] until the
+// pruner is stopped.
+func New(log logging.Logger, db, queue database.Database, frequency time.Duration) (*Pruner, error) {
+	p := &Pruner{
+		log:   log,
+		db:    db,
+		queue: queue,
+	}
+
+	// Recover the keys that were scheduled before the node restarted
+	it := queue.NewIterator()
+	first := true
+	for it.Next() {
+		seq := binary.BigEndian.Uint64(it.Key())
+		if first {
+			p.head = seq
+			first = false
+		}
+		p.tail = seq + 1
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return nil, err
+	}
+	p.ready = p.head
+
+	if frequency > 0 {
+		p.repeater = timer.NewRepeater(func() {
+			// Failures are retried by the next pass
+			if _, err := p.Prune(); err != nil {
+				p.log.Warn("pruning failed due to %s", err)
+			}
+		}, frequency)
+		go log.RecoverAndPanic(p.repeater.Dispatch)
+	}
+	return p, nil
+}
+
+// Schedule [key] of the database to be pruned
+func (p *Pruner) Schedule(key []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.queue.Put(seqKey(p.tail), key); err != nil {
+		return err
+	}
+	p.tail++
+	return nil
+}
+
+// Pending returns the number of keys that are scheduled to be pruned
+func (p *Pruner) Pending() uint64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.tail - p.head
+}
+
+// Prune runs a pass, and returns the number of keys it deleted
+func (p *Pruner) Prune() (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	end := p.ready
+	if end-p.head > maxPassSize {
+		end = p.head + maxPassSize
+	}
+
+	it := p.queue.NewIteratorWithStart(seqKey(p.head))
+	defer it.Release()
+
+	batch := p.db.NewBatch()
+	pruned := 0
+	for it.Next() {
+		if binary.BigEndian.Uint64(it.Key()) >= end {
+			break
+		}
+		if err := batch.Delete(it.Value()); err != nil {
+			return 0, err
+		}
+		pruned++
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	if err := p.queue.DeleteRange(seqKey(p.head), seqKey(end)); err != nil {
+		return 0, err
+	}
+
+	p.head = end
+	if p.head == p.ready {
+		p.ready = p.tail
+	}
+	if pruned > 0 {
+		p.log.Debug("pruned %d keys, %d keys are still scheduled", pruned, p.tail-p.head)
+	}
+	return pruned, nil
+}
+
+// Stop running passes periodically
+func (p *Pruner) Stop() {
+	if p.repeater != nil {
+		p.repeater.Stop()
+	}
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,114 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruning
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestPrunerWaitsAPass(t *testing.T) {
+	db := memdb.New()
+	queue := memdb.New()
+	p, err := New(logging.NoLog{}, db, queue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := []byte{1}
+	if err := db.Put(key, []byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Schedule(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if pruned, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	} else if pruned != 0 {
+		t.Fatalf("Shouldn't have pruned a key scheduled after the previous pass")
+	}
+	if has, err := db.Has(key); err != nil {
+		t.Fatal(err)
+	} else if !has {
+		t.Fatalf("Shouldn't have deleted the key yet")
+	}
+
+	if pruned, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	} else if pruned != 1 {
+		t.Fatalf("Should have pruned 1 key but pruned %d", pruned)
+	}
+	if has, err := db.Has(key); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Should have deleted the key")
+	}
+	if pending := p.Pending(); pending != 0 {
+		t.Fatalf("Shouldn't have any keys scheduled but has %d", pending)
+	}
+	if it := queue.NewIterator(); it.Next() {
+		t.Fatalf("Should have removed the pruned keys from the queue")
+	}
+}
+
+func TestPrunerRestart(t *testing.T) {
+	db := memdb.New()
+	queue := memdb.New()
+	p, err := New(logging.NoLog{}, db, queue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := byte(0); i < 3; i++ {
+		if err := db.Put([]byte{i}, []byte{i}); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Schedule([]byte{i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Schedule([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put([]byte{1}, []byte{1}); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = New(logging.NoLog{}, db, queue, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := p.Pending(); pending != 1 {
+		t.Fatalf("Should have recovered 1 scheduled key but recovered %d", pending)
+	}
+	if _, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if pruned, err := p.Prune(); err != nil {
+		t.Fatal(err)
+	} else if pruned != 1 {
+		t.Fatalf("Should have pruned the recovered key but pruned %d keys", pruned)
+	}
+	if has, err := db.Has([]byte{1}); err != nil {
+		t.Fatal(err)
+	} else if has {
+		t.Fatalf("Should have deleted the recovered key")
+	}
+
+	// Keys are scheduled after the ones that were already pruned
+	if err := p.Schedule([]byte{2}); err != nil {
+		t.Fatal(err)
+	}
+	if pending := p.Pending(); pending != 1 {
+		t.Fatalf("Should have 1 scheduled key but has %d", pending)
+	}
+}
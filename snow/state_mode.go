@@ -0,0 +1,48 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snow
+
+import (
+	"fmt"
+)
+
+// StateMode is how much historical state a node keeps
+type StateMode uint32
+
+const (
+	// ArchiveState keeps all historical state, so the node can serve historical
+	// queries and the ancestors of any accepted container
+	ArchiveState StateMode = iota
+
+	// PrunedState lets VMs discard historical state they no longer need to
+	// verify new containers. The EVM only keeps the state tries of recent
+	// blocks. The other VMs, and the vertices of the Avalanche chains, only
+	// discard state if pruning is also enabled, in which case the rejected
+	// containers are deleted. Accepted containers are always kept, but a
+	// pruned node can't be relied on for historical queries.
+	PrunedState
+)
+
+// ParseStateMode returns the state mode named [name]
+func ParseStateMode(name string) (StateMode, error) {
+	switch name {
+	case "archive":
+		return ArchiveState, nil
+	case "pruned":
+		return PrunedState, nil
+	default:
+		return 0, fmt.Errorf("unknown state mode %q, expected archive or pruned", name)
+	}
+}
+
+func (m StateMode) String() string {
+	switch m {
+	case ArchiveState:
+		return "archive"
+	case PrunedState:
+		return "pruned"
+	default:
+		return fmt.Sprintf("Unknown state mode: %d", uint32(m))
+	}
+}
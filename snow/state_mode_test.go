@@ -0,0 +1,24 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snow
+
+import (
+	"testing"
+)
+
+func TestParseStateMode(t *testing.T) {
+	for _, mode := range []StateMode{ArchiveState, PrunedState} {
+		parsed, err := ParseStateMode(mode.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != mode {
+			t.Fatalf("ParseStateMode Returned: %s ; Expected: %s", parsed, mode)
+		}
+	}
+
+	if _, err := ParseStateMode("full"); err == nil {
+		t.Fatalf("Should have errored on an unknown state mode")
+	}
+}
@@ -0,0 +1,180 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package triggers
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+// DefaultQueueSize is the number of events an asynchronous handler buffers by
+// default
+const DefaultQueueSize = 1024
+
+// OverflowPolicy decides what happens to an event that is dispatched to an
+// asynchronous handler whose queue is full
+type OverflowPolicy int
+
+// Overflow policies
+const (
+	// DropNewest drops the event that is being dispatched
+	DropNewest OverflowPolicy = iota
+	// DropOldest drops the oldest queued event to make room for the event that
+	// is being dispatched
+	DropOldest
+	// Block waits until there is room in the queue. This blocks the thread that
+	// emitted the event, so it should only be used by handlers that must see
+	// every event.
+	Block
+)
+
+// AsyncConfig describes how events are queued for an asynchronous handler
+type AsyncConfig struct {
+	// QueueSize is the number of events that can be waiting to be handled. If
+	// it isn't positive, DefaultQueueSize is used.
+	QueueSize int
+	// Policy decides what happens to events when the queue is full
+	Policy OverflowPolicy
+}
+
+type eventType int
+
+const (
+	acceptEvent eventType = iota
+	rejectEvent
+	issueEvent
+)
+
+type event struct {
+	eventType
+	chainID, containerID ids.ID
+	container            []byte
+}
+
+// asyncHandler delivers events to [handler] from its own goroutine, so that a
+// slow handler doesn't block the thread that emitted the event
+type asyncHandler struct {
+	log        logging.Logger
+	identifier string
+	handler    interface{}
+	policy     OverflowPolicy
+
+	events  chan event
+	quit    chan struct{}
+	dropped uint64
+	wg      sync.WaitGroup
+}
+
+func newAsyncHandler(log logging.Logger, identifier string, handler interface{}, config AsyncConfig) *asyncHandler {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	h := &asyncHandler{
+		log:        log,
+		identifier: identifier,
+		handler:    handler,
+		policy:     config.Policy,
+		events:     make(chan event, config.QueueSize),
+		quit:       make(chan struct{}),
+	}
+	h.wg.Add(1)
+	go h.run()
+	return h
+}
+
+// Accept implements the Acceptor interface
+func (h *asyncHandler) Accept(chainID, containerID ids.ID, container []byte) error {
+	if _, ok := h.handler.(Acceptor); ok {
+		h.push(event{acceptEvent, chainID, containerID, container})
+	}
+	return nil
+}
+
+// Reject implements the Rejector interface
+func (h *asyncHandler) Reject(chainID, containerID ids.ID, container []byte) error {
+	if _, ok := h.handler.(Rejector); ok {
+		h.push(event{rejectEvent, chainID, containerID, container})
+	}
+	return nil
+}
+
+// Issue implements the Issuer interface
+func (h *asyncHandler) Issue(chainID, containerID ids.ID, container []byte) error {
+	if _, ok := h.handler.(Issuer); ok {
+		h.push(event{issueEvent, chainID, containerID, container})
+	}
+	return nil
+}
+
+// push queues [e]. Events are only pushed while the dispatcher's lock is held,
+// so there is a single producer.
+func (h *asyncHandler) push(e event) {
+	select {
+	case h.events <- e:
+		return
+	default:
+	}
+
+	switch h.policy {
+	case Block:
+		select {
+		case h.events <- e:
+		case <-h.quit:
+		}
+	case DropOldest:
+		select {
+		case <-h.events:
+			atomic.AddUint64(&h.dropped, 1)
+		default:
+		}
+		select {
+		case h.events <- e:
+		default:
+			atomic.AddUint64(&h.dropped, 1)
+		}
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+}
+
+func (h *asyncHandler) run() {
+	defer h.wg.Done()
+
+	for {
+		select {
+		case e := <-h.events:
+			if dropped := atomic.SwapUint64(&h.dropped, 0); dropped > 0 {
+				h.log.Warn("dropped %d events for %s because its queue was full", dropped, h.identifier)
+			}
+			h.handle(e)
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+func (h *asyncHandler) handle(e event) {
+	var err error
+	switch e.eventType {
+	case acceptEvent:
+		err = h.handler.(Acceptor).Accept(e.chainID, e.containerID, e.container)
+	case rejectEvent:
+		err = h.handler.(Rejector).Reject(e.chainID, e.containerID, e.container)
+	case issueEvent:
+		err = h.handler.(Issuer).Issue(e.chainID, e.containerID, e.container)
+	}
+	if err != nil {
+		h.log.Error("unable to handle event on %s for chainID %s: %s", h.identifier, e.chainID, err)
+	}
+}
+
+// stop discards the queued events and waits for the event that is being
+// handled, if any, to finish
+func (h *asyncHandler) stop() {
+	close(h.quit)
+	h.wg.Wait()
+}
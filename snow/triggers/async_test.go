@@ -0,0 +1,95 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package triggers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type blockingAcceptor struct {
+	unblock  chan struct{}
+	accepted chan ids.ID
+}
+
+func (a *blockingAcceptor) Accept(_, containerID ids.ID, _ []byte) error {
+	<-a.unblock
+	a.accepted <- containerID
+	return nil
+}
+
+func TestAsyncHandlerDoesntBlock(t *testing.T) {
+	ed := EventDispatcher{}
+	ed.Initialize(logging.NoLog{})
+
+	acceptor := &blockingAcceptor{
+		unblock:  make(chan struct{}),
+		accepted: make(chan ids.ID, 10),
+	}
+	chainID := ids.NewID([32]byte{1})
+	config := AsyncConfig{QueueSize: 1, Policy: DropOldest}
+	if err := ed.RegisterChainAsync(chainID, "slow", acceptor, config); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first event is being handled, the second is queued, and the third
+	// replaces the second
+	done := make(chan struct{})
+	go func() {
+		for i := byte(0); i < 3; i++ {
+			ed.Accept(chainID, ids.NewID([32]byte{i}), nil)
+			if i == 0 {
+				// Wait for the handler to take the first event off the queue
+				for len(ed.chainHandlers[chainID.Key()]["slow"].(*asyncHandler).events) != 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Dispatching blocked on a slow handler")
+	}
+
+	close(acceptor.unblock)
+	for _, expected := range []byte{0, 2} {
+		if containerID := <-acceptor.accepted; !containerID.Equals(ids.NewID([32]byte{expected})) {
+			t.Fatalf("Handled the wrong event %s", containerID)
+		}
+	}
+
+	if err := ed.DeregisterChain(chainID, "slow"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case containerID := <-acceptor.accepted:
+		t.Fatalf("Unexpectedly handled %s", containerID)
+	default:
+	}
+}
+
+func TestAsyncHandlerDropNewest(t *testing.T) {
+	h := &asyncHandler{
+		handler: &blockingAcceptor{},
+		policy:  DropNewest,
+		events:  make(chan event, 1),
+		quit:    make(chan struct{}),
+	}
+
+	h.push(event{containerID: ids.NewID([32]byte{1})})
+	h.push(event{containerID: ids.NewID([32]byte{2})})
+
+	if e := <-h.events; !e.containerID.Equals(ids.NewID([32]byte{1})) {
+		t.Fatalf("The newest event should have been dropped")
+	}
+	if h.dropped != 1 {
+		t.Fatalf("Expected 1 dropped event, got %d", h.dropped)
+	}
+}
@@ -142,6 +142,28 @@ func (ed *EventDispatcher) RegisterChain(chainID ids.ID, identifier string, hand
 	return nil
 }
 
+// RegisterChainAsync places a new chain handler into the system. Events are
+// delivered to the handler from its own goroutine through a queue described by
+// [config], so a slow handler doesn't block the thread that emitted the event.
+func (ed *EventDispatcher) RegisterChainAsync(chainID ids.ID, identifier string, handler interface{}, config AsyncConfig) error {
+	ed.lock.Lock()
+	defer ed.lock.Unlock()
+
+	chainIDKey := chainID.Key()
+	events, exist := ed.chainHandlers[chainIDKey]
+	if !exist {
+		events = make(map[string]interface{})
+		ed.chainHandlers[chainIDKey] = events
+	}
+
+	if _, ok := events[identifier]; ok {
+		return fmt.Errorf("handler %s already exists on chain %s", identifier, chainID)
+	}
+
+	events[identifier] = newAsyncHandler(ed.log, identifier, handler, config)
+	return nil
+}
+
 // DeregisterChain removes a chain handler from the system
 func (ed *EventDispatcher) DeregisterChain(chainID ids.ID, identifier string) error {
 	ed.lock.Lock()
@@ -157,6 +179,10 @@ func (ed *EventDispatcher) DeregisterChain(chainID ids.ID, identifier string) er
 		return fmt.Errorf("handler %s does not exist on chain %s", identifier, chainID)
 	}
 
+	if handler, ok := events[identifier].(*asyncHandler); ok {
+		handler.stop()
+	}
+
 	if len(events) == 1 {
 		delete(ed.chainHandlers, chainIDKey)
 	} else {
@@ -178,6 +204,20 @@ func (ed *EventDispatcher) Register(identifier string, handler interface{}) erro
 	return nil
 }
 
+// RegisterAsync places a new handler into the system. Events are delivered to
+// the handler from its own goroutine through a queue described by [config].
+func (ed *EventDispatcher) RegisterAsync(identifier string, handler interface{}, config AsyncConfig) error {
+	ed.lock.Lock()
+	defer ed.lock.Unlock()
+
+	if _, exist := ed.handlers[identifier]; exist {
+		return fmt.Errorf("handler %s already exists", identifier)
+	}
+
+	ed.handlers[identifier] = newAsyncHandler(ed.log, identifier, handler, config)
+	return nil
+}
+
 // Deregister removes a handler from the system
 func (ed *EventDispatcher) Deregister(identifier string) error {
 	ed.lock.Lock()
@@ -187,6 +227,10 @@ func (ed *EventDispatcher) Deregister(identifier string) error {
 		return fmt.Errorf("handler %s already exists", identifier)
 	}
 
+	if handler, ok := ed.handlers[identifier].(*asyncHandler); ok {
+		handler.stop()
+	}
+
 	delete(ed.handlers, identifier)
 	return nil
 }
@@ -0,0 +1,54 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errUnnamedUpgrade   = errors.New("upgrades must be named")
+	errDuplicateUpgrade = errors.New("duplicated upgrade")
+)
+
+// Upgrade is a change of the rules of a VM. It is activated by the first
+// container whose height is at least [Height] and whose timestamp is at least
+// [Time]. A zero [Height] or [Time] doesn't constrain the activation.
+type Upgrade struct {
+	Name   string    `json:"name"`
+	Height uint64    `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// Upgrades is the schedule of the rule changes of a chain
+type Upgrades []Upgrade
+
+// Verify that the schedule is well-formed
+func (u Upgrades) Verify() error {
+	names := make(map[string]bool, len(u))
+	for _, upgrade := range u {
+		switch {
+		case upgrade.Name == "":
+			return errUnnamedUpgrade
+		case names[upgrade.Name]:
+			return fmt.Errorf("%w: %s", errDuplicateUpgrade, upgrade.Name)
+		}
+		names[upgrade.Name] = true
+	}
+	return nil
+}
+
+// IsActivated returns true if the upgrade [name] applies to a container at
+// [height] with timestamp [timestamp]. Upgrades that aren't scheduled are never
+// activated.
+func (u Upgrades) IsActivated(name string, height uint64, timestamp time.Time) bool {
+	for _, upgrade := range u {
+		if upgrade.Name == name {
+			return height >= upgrade.Height && !timestamp.Before(upgrade.Time)
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package snow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpgradesVerify(t *testing.T) {
+	if err := (Upgrades{{Name: "a"}, {Name: "b"}}).Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Upgrades{{Name: ""}}).Verify(); err == nil {
+		t.Fatalf("Should have errored due to an unnamed upgrade")
+	}
+	if err := (Upgrades{{Name: "a"}, {Name: "a", Height: 1}}).Verify(); err == nil {
+		t.Fatalf("Should have errored due to a duplicated upgrade")
+	}
+}
+
+func TestUpgradesIsActivated(t *testing.T) {
+	activation := time.Unix(1000, 0)
+	upgrades := Upgrades{
+		{Name: "height", Height: 10},
+		{Name: "time", Time: activation},
+		{Name: "both", Height: 10, Time: activation},
+	}
+
+	tests := []struct {
+		name      string
+		height    uint64
+		timestamp time.Time
+		expected  bool
+	}{
+		{"height", 9, activation, false},
+		{"height", 10, time.Unix(0, 0), true},
+		{"time", 0, activation.Add(-time.Second), false},
+		{"time", 0, activation, true},
+		{"both", 10, activation.Add(-time.Second), false},
+		{"both", 9, activation, false},
+		{"both", 10, activation, true},
+		{"unscheduled", 100, activation, false},
+	}
+	for _, test := range tests {
+		if activated := upgrades.IsActivated(test.name, test.height, test.timestamp); activated != test.expected {
+			t.Fatalf("IsActivated(%s, %d, %s) returned %v, expected %v", test.name, test.height, test.timestamp, activated, test.expected)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package uptime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// Performance of a peer, as observed by this node
+type Performance struct {
+	// Fraction of the observation period the peer was connected to this node,
+	// in [0, 1]
+	Uptime float64
+
+	// Number of requests sent to the peer that it responded to in time
+	Responses uint64
+
+	// Number of requests sent to the peer that timed out
+	Timeouts uint64
+
+	// Average time it took the peer to respond to a request
+	AverageLatency time.Duration
+}
+
+type peer struct {
+	connected   bool
+	connectedAt time.Time
+	upTime      time.Duration
+
+	responses    uint64
+	timeouts     uint64
+	totalLatency time.Duration
+}
+
+// Tracker records the uptime and responsiveness of peers, as observed by this
+// node, since the tracker was created. It's safe for concurrent use.
+type Tracker struct {
+	lock  sync.Mutex
+	clock timer.Clock
+	start time.Time
+
+	// nodeID --> what this node has observed of the peer
+	peers map[[20]byte]*peer
+}
+
+// NewTracker returns a new tracker that starts observing peers now
+func NewTracker() *Tracker {
+	t := &Tracker{peers: make(map[[20]byte]*peer)}
+	t.start = t.clock.Time()
+	return t
+}
+
+// Connected marks [nodeID] as connected to this node
+func (t *Tracker) Connected(nodeID ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peer(nodeID)
+	if p.connected {
+		return
+	}
+	p.connected = true
+	p.connectedAt = t.clock.Time()
+}
+
+// Disconnected marks [nodeID] as disconnected from this node
+func (t *Tracker) Disconnected(nodeID ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peer(nodeID)
+	if !p.connected {
+		return
+	}
+	p.connected = false
+	p.upTime += t.clock.Time().Sub(p.connectedAt)
+}
+
+// Responded records that [nodeID] responded to a request after [latency]
+func (t *Tracker) Responded(nodeID ids.ShortID, latency time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.peer(nodeID)
+	p.responses++
+	p.totalLatency += latency
+}
+
+// TimedOut records that [nodeID] didn't respond to a request in time
+func (t *Tracker) TimedOut(nodeID ids.ShortID) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.peer(nodeID).timeouts++
+}
+
+// Performance returns what this node has observed of [nodeID] over the period
+// starting at [since], or at the creation of the tracker if that's later.
+//
+// Uptime is only recorded in total, so any time the peer was connected before
+// [since] is counted towards the period. The reported uptime is an upper bound
+// on the peer's true uptime over the period.
+func (t *Tracker) Performance(nodeID ids.ShortID, since time.Time) Performance {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.clock.Time()
+	if since.Before(t.start) {
+		since = t.start
+	}
+
+	perf := Performance{}
+	p, exists := t.peers[nodeID.Key()]
+	if !exists {
+		return perf
+	}
+
+	upTime := p.upTime
+	if p.connected {
+		upTime += now.Sub(p.connectedAt)
+	}
+	switch period := now.Sub(since); {
+	case period <= 0 || upTime >= period:
+		if upTime > 0 {
+			perf.Uptime = 1
+		}
+	default:
+		perf.Uptime = float64(upTime) / float64(period)
+	}
+
+	perf.Responses = p.responses
+	perf.Timeouts = p.timeouts
+	if p.responses > 0 {
+		perf.AverageLatency = p.totalLatency / time.Duration(p.responses)
+	}
+	return perf
+}
+
+// Start returns the time the tracker started observing peers
+func (t *Tracker) Start() time.Time { return t.start }
+
+// assumes the lock is held
+func (t *Tracker) peer(nodeID ids.ShortID) *peer {
+	key := nodeID.Key()
+	p, exists := t.peers[key]
+	if !exists {
+		p = &peer{}
+		t.peers[key] = p
+	}
+	return p
+}
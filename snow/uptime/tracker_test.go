@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package uptime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestTrackerUptime(t *testing.T) {
+	tracker := NewTracker()
+	start := tracker.Start()
+	tracker.clock.Set(start)
+
+	nodeID := ids.NewShortID([20]byte{1})
+
+	tracker.clock.Set(start.Add(10 * time.Second))
+	tracker.Connected(nodeID)
+	tracker.clock.Set(start.Add(40 * time.Second))
+	tracker.Disconnected(nodeID)
+	tracker.clock.Set(start.Add(50 * time.Second))
+	tracker.Connected(nodeID)
+	tracker.Connected(nodeID) // Duplicated connection events are ignored
+	tracker.clock.Set(start.Add(100 * time.Second))
+
+	if perf := tracker.Performance(nodeID, time.Time{}); perf.Uptime != .8 {
+		t.Fatalf("Uptime should have been %f but was %f", .8, perf.Uptime)
+	}
+	if perf := tracker.Performance(nodeID, start.Add(60*time.Second)); perf.Uptime != 1 {
+		t.Fatalf("Uptime should have been capped at %d but was %f", 1, perf.Uptime)
+	}
+	if perf := tracker.Performance(ids.NewShortID([20]byte{2}), time.Time{}); perf.Uptime != 0 {
+		t.Fatalf("Unknown peer should have no uptime")
+	}
+}
+
+func TestTrackerResponsiveness(t *testing.T) {
+	tracker := NewTracker()
+
+	nodeID := ids.NewShortID([20]byte{1})
+
+	tracker.Responded(nodeID, time.Second)
+	tracker.Responded(nodeID, 3*time.Second)
+	tracker.TimedOut(nodeID)
+
+	perf := tracker.Performance(nodeID, time.Time{})
+	switch {
+	case perf.Responses != 2:
+		t.Fatalf("Should have recorded %d responses but recorded %d", 2, perf.Responses)
+	case perf.Timeouts != 1:
+		t.Fatalf("Should have recorded %d timeouts but recorded %d", 1, perf.Timeouts)
+	case perf.AverageLatency != 2*time.Second:
+		t.Fatalf("Average latency should have been %s but was %s", 2*time.Second, perf.AverageLatency)
+	}
+}
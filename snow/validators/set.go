@@ -49,14 +49,14 @@ func NewSet() Set { return &set{vdrMap: make(map[[20]byte]int)} }
 
 // set of validators. Validator function results are cached. Therefore, to
 // update a validators weight, one should ensure to call add with the updated
-// validator. Sample will run in O(NumValidators) time. All other functions run
-// in O(1) time.
+// validator. Sample will run in O(size*log(NumValidators)) time. Add and Remove
+// run in O(log(NumValidators)) time. All other functions run in O(1) time.
 // set implements Set
 type set struct {
 	lock     sync.Mutex
 	vdrMap   map[[20]byte]int
 	vdrSlice []Validator
-	sampler  random.Weighted
+	sampler  random.WeightedTree
 }
 
 // Set implements the Set interface.
@@ -70,7 +70,7 @@ func (s *set) Set(vdrs []Validator) {
 func (s *set) set(vdrs []Validator) {
 	s.vdrMap = make(map[[20]byte]int, len(vdrs))
 	s.vdrSlice = s.vdrSlice[:0]
-	s.sampler.Weights = s.sampler.Weights[:0]
+	s.sampler.Clear()
 
 	for _, vdr := range vdrs {
 		s.add(vdr)
@@ -99,7 +99,7 @@ func (s *set) add(vdr Validator) {
 	i := len(s.vdrSlice)
 	s.vdrMap[vdrID.Key()] = i
 	s.vdrSlice = append(s.vdrSlice, vdr)
-	s.sampler.Weights = append(s.sampler.Weights, w)
+	s.sampler.Append(w)
 }
 
 // Remove implements the Set interface.
@@ -126,12 +126,12 @@ func (s *set) remove(vdrID ids.ShortID) {
 	// Move e -> i
 	s.vdrMap[eKey] = i
 	s.vdrSlice[i] = eVdr
-	s.sampler.Weights[i] = s.sampler.Weights[e]
+	s.sampler.Update(i, s.sampler.Weight(e))
 
 	// Remove i
 	delete(s.vdrMap, iKey)
 	s.vdrSlice = s.vdrSlice[:e]
-	s.sampler.Weights = s.sampler.Weights[:e]
+	s.sampler.RemoveLast()
 }
 
 // Contains implements the Set interface.
@@ -182,7 +182,8 @@ func (s *set) Sample(size int) []Validator {
 func (s *set) sample(size int) []Validator {
 	list := make([]Validator, size)[:0]
 
-	s.sampler.Replace() // Must replace, otherwise changes won't be reflected
+	defer s.sampler.Replace()
+
 	for ; size > 0 && s.sampler.CanSample(); size-- {
 		i := s.sampler.Sample()
 		list = append(list, s.vdrSlice[i])
@@ -203,7 +204,7 @@ func (s *set) string() string {
 	sb.WriteString(fmt.Sprintf("Validator Set: (Size = %d)", len(s.vdrSlice)))
 	format := fmt.Sprintf("\n    Validator[%s]: %%33s, %%d", formatting.IntFormat(len(s.vdrSlice)-1))
 	for i, vdr := range s.vdrSlice {
-		sb.WriteString(fmt.Sprintf(format, i, vdr.ID(), s.sampler.Weights[i]))
+		sb.WriteString(fmt.Sprintf(format, i, vdr.ID(), s.sampler.Weight(i)))
 	}
 
 	return sb.String()
@@ -0,0 +1,63 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testnetwork
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/genesis"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/vms/platformvm"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+const (
+	// validatorWeight is the weight of each of the network's validators
+	validatorWeight = 100
+
+	// validationPeriod is how long the network's validators validate
+	validationPeriod = 365 * 24 * time.Hour
+)
+
+// Genesis returns the spec of a network that starts at [start], whose
+// validators are [nodeIDs], and whose accounts and chains are those of the
+// local network
+func Genesis(nodeIDs []ids.ShortID, start time.Time) (*genesis.Spec, error) {
+	local := platformvm.Genesis{}
+	if _, err := platformvm.Codec.Unmarshal(genesis.Genesis(genesis.LocalID), &local); err != nil {
+		return nil, err
+	}
+
+	spec := &genesis.Spec{Time: cjson.Uint64(start.Unix())}
+	for _, account := range local.Accounts {
+		spec.Accounts = append(spec.Accounts, platformvm.APIAccount{
+			Address: account.Address,
+			Balance: cjson.Uint64(account.Balance),
+		})
+	}
+	for _, chain := range local.Chains {
+		spec.Chains = append(spec.Chains, platformvm.APIChain{
+			GenesisData: formatting.CB58{Bytes: chain.GenesisData},
+			VMID:        chain.VMID,
+			FxIDs:       chain.FxIDs,
+			Name:        chain.ChainName,
+		})
+	}
+
+	weight := cjson.Uint64(validatorWeight)
+	for _, nodeID := range nodeIDs {
+		spec.Validators = append(spec.Validators, platformvm.APIDefaultSubnetValidator{
+			APIValidator: platformvm.APIValidator{
+				StartTime: cjson.Uint64(start.Unix()),
+				EndTime:   cjson.Uint64(start.Add(validationPeriod).Unix()),
+				Weight:    &weight,
+				ID:        nodeID,
+			},
+			Destination: genesis.ParsedAddresses[0],
+		})
+	}
+	return spec, nil
+}
@@ -0,0 +1,71 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testnetwork
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+// stakingKeyBits is the size of the generated staking keys. It's smaller than
+// a production key's so that a network's keys are generated quickly.
+const stakingKeyBits = 2048
+
+// StakingKey is the TLS key and certificate a node uses for staking
+// connections, PEM encoded
+type StakingKey struct {
+	Key, Cert []byte
+}
+
+// NewStakingKey returns a new staking key with a self-signed certificate
+func NewStakingKey() (*StakingKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, stakingKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"gecko test network"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingKey{
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}),
+	}, nil
+}
+
+// NodeID returns the ID of the node that stakes with this key, which is
+// derived from the certificate the same way the node derives it
+func (k *StakingKey) NodeID() (ids.ShortID, error) {
+	block, _ := pem.Decode(k.Cert)
+	if block == nil {
+		return ids.ShortID{}, errNoCertificate
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return ids.ToShortID(hashing.PubkeyBytesToAddress(cert.Raw))
+}
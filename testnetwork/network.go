@@ -0,0 +1,216 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package testnetwork launches local networks of nodes, for integration tests
+// and for developing subnets. Each node runs in its own process, since a
+// node's consensus networking is global to its process.
+package testnetwork
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/ava-labs/gecko/genesis"
+	"github.com/ava-labs/gecko/ids"
+)
+
+const (
+	// DefaultNumNodes is the number of nodes in a network, if not configured
+	DefaultNumNodes = 5
+
+	// DefaultBasePort is the first port of a network's nodes, if not
+	// configured
+	DefaultBasePort = 9650
+
+	// DefaultStartTimeout is how long the nodes have to start, if not
+	// configured
+	DefaultStartTimeout = time.Minute
+
+	genesisFile = "genesis.json"
+)
+
+var (
+	errNoBinary      = errors.New("the path of the node binary must be given")
+	errNoDir         = errors.New("the network's directory must be given")
+	errNoCertificate = errors.New("staking key has no certificate")
+)
+
+// Config of a test network
+type Config struct {
+	// Path of the node binary
+	Binary string
+
+	// Directory that the genesis, and each node's staking key, database and
+	// logs, are written to
+	Dir string
+
+	// Number of nodes. Each is a validator at genesis.
+	NumNodes int
+
+	// The nodes listen on consecutive ports from BasePort. The i-th node
+	// serves its API at BasePort+2i, and its staking connections at
+	// BasePort+2i+1.
+	BasePort uint16
+
+	// Flags that each node is started with, besides those that set up the
+	// network. They take precedence over the network's flags.
+	Args []string
+
+	// If non-nil, the output of the nodes is written to Output
+	Output io.Writer
+
+	// How long the nodes have to start serving their APIs
+	StartTimeout time.Duration
+}
+
+// Network is a local network of nodes that share a custom genesis
+type Network struct {
+	nodes []*Node
+}
+
+// New launches the network described by [config], and waits for its nodes to
+// serve their APIs. The first node is the bootstrap beacon of the others.
+func New(config Config) (*Network, error) {
+	switch {
+	case config.Binary == "":
+		return nil, errNoBinary
+	case config.Dir == "":
+		return nil, errNoDir
+	}
+	if config.NumNodes <= 0 {
+		config.NumNodes = DefaultNumNodes
+	}
+	if config.BasePort == 0 {
+		config.BasePort = DefaultBasePort
+	}
+	if config.StartTimeout <= 0 {
+		config.StartTimeout = DefaultStartTimeout
+	}
+
+	net, err := newNetwork(config)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(config.StartTimeout)
+	for _, node := range net.nodes {
+		if err := node.start(); err != nil {
+			net.Stop()
+			return nil, fmt.Errorf("couldn't start node %s: %w", node.ID, err)
+		}
+	}
+	for _, node := range net.nodes {
+		if err := node.waitReady(deadline); err != nil {
+			net.Stop()
+			return nil, err
+		}
+	}
+	return net, nil
+}
+
+// newNetwork writes the network's genesis and staking keys, and prepares the
+// commands that run its nodes
+func newNetwork(config Config) (*Network, error) {
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*StakingKey, config.NumNodes)
+	nodeIDs := make([]ids.ShortID, config.NumNodes)
+	for i := range keys {
+		key, err := NewStakingKey()
+		if err != nil {
+			return nil, err
+		}
+		nodeID, err := key.NodeID()
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		nodeIDs[i] = nodeID
+	}
+
+	spec, err := Genesis(nodeIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	specBytes, err := json.MarshalIndent(spec, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	genesisPath := filepath.Join(config.Dir, genesisFile)
+	if err := ioutil.WriteFile(genesisPath, specBytes, 0600); err != nil {
+		return nil, err
+	}
+
+	net := &Network{}
+	for i, key := range keys {
+		node := &Node{
+			ID:          nodeIDs[i],
+			HTTPPort:    config.BasePort + uint16(2*i),
+			StakingPort: config.BasePort + uint16(2*i+1),
+			Dir:         filepath.Join(config.Dir, fmt.Sprintf("node%d", i+1)),
+		}
+		if err := os.MkdirAll(node.Dir, 0700); err != nil {
+			return nil, err
+		}
+		keyPath := filepath.Join(node.Dir, "staker.key")
+		certPath := filepath.Join(node.Dir, "staker.crt")
+		if err := ioutil.WriteFile(keyPath, key.Key, 0600); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(certPath, key.Cert, 0600); err != nil {
+			return nil, err
+		}
+
+		args := []string{
+			"--network-id=" + genesis.LocalName,
+			"--genesis-file=" + genesisPath,
+			"--public-ip=127.0.0.1",
+			"--http-hosts=127.0.0.1",
+			fmt.Sprintf("--http-port=%d", node.HTTPPort),
+			fmt.Sprintf("--staking-port=%d", node.StakingPort),
+			"--staking-tls-key-file=" + keyPath,
+			"--staking-tls-cert-file=" + certPath,
+			"--db-dir=" + filepath.Join(node.Dir, "db"),
+			"--log-dir=" + filepath.Join(node.Dir, "logs"),
+			fmt.Sprintf("--snow-sample-size=%d", config.NumNodes),
+			fmt.Sprintf("--snow-quorum-size=%d", config.NumNodes/2+1),
+		}
+		if i > 0 {
+			beacon := net.nodes[0]
+			args = append(args,
+				fmt.Sprintf("--bootstrap-ips=127.0.0.1:%d", beacon.StakingPort),
+				"--bootstrap-ids="+beacon.ID.String(),
+			)
+		}
+		args = append(args, config.Args...)
+
+		node.cmd = exec.Command(config.Binary, args...)
+		node.cmd.Stdout = config.Output
+		node.cmd.Stderr = config.Output
+		net.nodes = append(net.nodes, node)
+	}
+	return net, nil
+}
+
+// Nodes of the network. The first is the bootstrap beacon of the others.
+func (n *Network) Nodes() []*Node { return n.nodes }
+
+// Stop the network's nodes. Returns the first error that a node returned.
+func (n *Network) Stop() error {
+	var err error
+	for _, node := range n.nodes {
+		if stopErr := node.Stop(); err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testnetwork
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/gecko/genesis"
+)
+
+func TestNewNetwork(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testnetwork")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	net, err := newNetwork(Config{
+		Binary:   "ava",
+		Dir:      dir,
+		NumNodes: 3,
+		BasePort: 20000,
+		Args:     []string{"--log-level=debug"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := net.Nodes()
+	if len(nodes) != 3 {
+		t.Fatalf("Network should have 3 nodes but has %d", len(nodes))
+	}
+
+	specBytes, err := ioutil.ReadFile(filepath.Join(dir, genesisFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := genesis.Spec{}
+	if err := json.Unmarshal(specBytes, &spec); err != nil {
+		t.Fatal(err)
+	}
+	network, err := genesis.Build(genesis.LocalID, &spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(network.Validators) != 3 {
+		t.Fatalf("Genesis should have 3 validators but has %d", len(network.Validators))
+	}
+	if len(network.Chains) == 0 {
+		t.Fatalf("Genesis should have the local network's chains")
+	}
+
+	for i, node := range nodes {
+		isValidator := false
+		for _, vdr := range network.Validators {
+			isValidator = isValidator || vdr.ID.Equals(node.ID)
+		}
+		if !isValidator {
+			t.Fatalf("Node %s should be a validator at genesis", node.ID)
+		}
+		if node.HTTPPort != uint16(20000+2*i) || node.StakingPort != uint16(20001+2*i) {
+			t.Fatalf("Node %d has the wrong ports %d and %d", i, node.HTTPPort, node.StakingPort)
+		}
+
+		key := &StakingKey{}
+		if key.Cert, err = ioutil.ReadFile(filepath.Join(node.Dir, "staker.crt")); err != nil {
+			t.Fatal(err)
+		}
+		if nodeID, err := key.NodeID(); err != nil || !nodeID.Equals(node.ID) {
+			t.Fatalf("Node's ID should be derived from its certificate")
+		}
+
+		args := strings.Join(node.cmd.Args, " ")
+		bootstraps := strings.Contains(args, "--bootstrap-ids="+nodes[0].ID.String())
+		if bootstraps != (i > 0) {
+			t.Fatalf("Only the nodes after the first should bootstrap from the first")
+		}
+		if !strings.HasSuffix(args, "--log-level=debug") {
+			t.Fatalf("Configured flags should be given last, so they take precedence")
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package testnetwork
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/gorilla/rpc/v2/json2"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+const (
+	// stopTimeout is how long a node has to shut down before it's killed
+	stopTimeout = 30 * time.Second
+
+	// pollInterval is how often a starting node is checked for readiness
+	pollInterval = 250 * time.Millisecond
+)
+
+// Node is a node of a test network, running in its own process
+type Node struct {
+	ID          ids.ShortID
+	HTTPPort    uint16
+	StakingPort uint16
+
+	// Directory of the node's staking key, database and logs
+	Dir string
+
+	cmd    *exec.Cmd
+	client http.Client
+
+	// Closed when the process exits, after [err] is set
+	exited chan struct{}
+	err    error
+}
+
+// URI of the node's HTTP server
+func (n *Node) URI() string { return fmt.Sprintf("http://127.0.0.1:%d", n.HTTPPort) }
+
+// Call the JSON-RPC [method] of the API served at /ext/[endpoint], such as
+// "admin" or "bc/X"
+func (n *Node) Call(endpoint, method string, args, reply interface{}) error {
+	reqBytes, err := json2.EncodeClientRequest(method, args)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.URI()+"/ext/"+endpoint, "application/json", bytes.NewReader(reqBytes))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", method, resp.StatusCode)
+	}
+	return json2.DecodeClientResponse(resp.Body, reply)
+}
+
+// start the node's process
+func (n *Node) start() error {
+	if err := n.cmd.Start(); err != nil {
+		return err
+	}
+	n.exited = make(chan struct{})
+	go func() {
+		n.err = n.cmd.Wait()
+		close(n.exited)
+	}()
+	return nil
+}
+
+// waitReady waits until the node serves its API, or [deadline] passes
+func (n *Node) waitReady(deadline time.Time) error {
+	for {
+		reply := struct {
+			NodeID ids.ShortID `json:"nodeID"`
+		}{}
+		err := n.Call("admin", "admin.getNodeID", struct{}{}, &reply)
+		switch {
+		case err == nil && reply.NodeID.Equals(n.ID):
+			return nil
+		case err == nil:
+			return fmt.Errorf("node at %s has ID %s instead of %s", n.URI(), reply.NodeID, n.ID)
+		case time.Now().After(deadline):
+			return fmt.Errorf("node %s didn't start in time: %w", n.ID, err)
+		}
+
+		select {
+		case <-n.exited:
+			return fmt.Errorf("node %s exited while starting: %v", n.ID, n.err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Stop the node, killing it if it doesn't shut down in time
+func (n *Node) Stop() error {
+	if n.exited == nil {
+		return nil
+	}
+	select {
+	case <-n.exited:
+		return nil
+	default:
+	}
+
+	if err := n.cmd.Process.Signal(os.Interrupt); err != nil {
+		return err
+	}
+	select {
+	case <-n.exited:
+		return nil
+	case <-time.After(stopTimeout):
+		if err := n.cmd.Process.Kill(); err != nil {
+			return err
+		}
+		<-n.exited
+		return fmt.Errorf("node %s was killed because it didn't shut down in time", n.ID)
+	}
+}
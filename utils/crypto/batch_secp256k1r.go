@@ -0,0 +1,110 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errWrongSigner = errors.New("signature wasn't made by the expected signer")
+)
+
+// BatchVerifierSECP256K1R verifies that a batch of recoverable signatures were
+// made by their expected signers. Signatures that appear more than once in the
+// batch are only recovered once, and the rest are recovered concurrently.
+type BatchVerifierSECP256K1R struct {
+	factory *FactorySECP256K1R
+
+	// Hash of the signed message and signature --> index of the first such
+	// signature in the batch
+	indices map[[32]byte]int
+	sigs    []batchSig
+}
+
+type batchSig struct {
+	hash, sig []byte
+	signers   []ids.ShortID // Each must be the signer of [sig]
+}
+
+// NewBatchVerifierSECP256K1R returns an empty batch that recovers public keys
+// with [factory]
+func NewBatchVerifierSECP256K1R(factory *FactorySECP256K1R) *BatchVerifierSECP256K1R {
+	return &BatchVerifierSECP256K1R{
+		factory: factory,
+		indices: make(map[[32]byte]int),
+	}
+}
+
+// Add to the batch that [sig] is a signature of [hash] by [signer]
+func (b *BatchVerifierSECP256K1R) Add(hash, sig []byte, signer ids.ShortID) {
+	keyBytes := make([]byte, len(hash)+len(sig))
+	copy(keyBytes, hash)
+	copy(keyBytes[len(hash):], sig)
+	key := hashing.ComputeHash256Array(keyBytes)
+
+	if i, ok := b.indices[key]; ok {
+		b.sigs[i].signers = append(b.sigs[i].signers, signer)
+		return
+	}
+	b.indices[key] = len(b.sigs)
+	b.sigs = append(b.sigs, batchSig{
+		hash:    hash,
+		sig:     sig,
+		signers: []ids.ShortID{signer},
+	})
+}
+
+// Len returns the number of distinct signatures in the batch
+func (b *BatchVerifierSECP256K1R) Len() int { return len(b.sigs) }
+
+// Verify returns nil if every signature in the batch was made by its expected
+// signers. Otherwise, the error of the first invalid signature is returned, in
+// the order the signatures were first added to the batch.
+func (b *BatchVerifierSECP256K1R) Verify() error {
+	errs := make([]error, len(b.sigs))
+
+	numWorkers := runtime.NumCPU()
+	if numWorkers > len(b.sigs) {
+		numWorkers = len(b.sigs)
+	}
+	wg := sync.WaitGroup{}
+	wg.Add(numWorkers)
+	for worker := 0; worker < numWorkers; worker++ {
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < len(b.sigs); i += numWorkers {
+				errs[i] = b.verify(&b.sigs[i])
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *BatchVerifierSECP256K1R) verify(sig *batchSig) error {
+	pk, err := b.factory.RecoverHashPublicKey(sig.hash, sig.sig)
+	if err != nil {
+		return err
+	}
+	addr := pk.Address()
+	for _, signer := range sig.signers {
+		if !signer.Equals(addr) {
+			return fmt.Errorf("%w: expected %s but was %s", errWrongSigner, signer, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+func TestBatchVerifierSECP256K1R(t *testing.T) {
+	f := FactorySECP256K1R{}
+	batch := NewBatchVerifierSECP256K1R(&f)
+
+	for i := byte(0); i < 10; i++ {
+		key, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := hashing.ComputeHash256([]byte{i})
+		sig, err := key.SignHash(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch.Add(hash, sig, key.PublicKey().Address())
+		batch.Add(hash, sig, key.PublicKey().Address()) // Duplicates are only recovered once
+	}
+
+	if batch.Len() != 10 {
+		t.Fatalf("Batch has %d distinct signatures, expected 10", batch.Len())
+	}
+	if err := batch.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchVerifierSECP256K1RWrongSigner(t *testing.T) {
+	f := FactorySECP256K1R{}
+	batch := NewBatchVerifierSECP256K1R(&f)
+
+	key, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := hashing.ComputeHash256([]byte{1})
+	sig, err := key.SignHash(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch.Add(hash, sig, key.PublicKey().Address())
+	batch.Add(hash, sig, ids.ShortEmpty)
+
+	if err := batch.Verify(); !errors.Is(err, errWrongSigner) {
+		t.Fatalf("Should have errored due to a wrong signer but returned %v", err)
+	}
+}
+
+func TestBatchVerifierSECP256K1RInvalidSignature(t *testing.T) {
+	f := FactorySECP256K1R{}
+	batch := NewBatchVerifierSECP256K1R(&f)
+
+	batch.Add(hashing.ComputeHash256([]byte{1}), make([]byte, SECP256K1RSigLen), ids.ShortEmpty)
+
+	if err := batch.Verify(); err == nil {
+		t.Fatalf("Should have errored due to an invalid signature")
+	}
+}
+
+func TestBatchVerifierSECP256K1REmpty(t *testing.T) {
+	f := FactorySECP256K1R{}
+	if err := NewBatchVerifierSECP256K1R(&f).Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,209 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+
+	blst "github.com/supranational/blst/bindings/go"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+const (
+	// BLSPrivateKeyLen is the number of bytes in a serialized BLS private key
+	BLSPrivateKeyLen = blst.BLST_SCALAR_BYTES
+
+	// BLSPublicKeyLen is the number of bytes in a compressed BLS public key
+	BLSPublicKeyLen = blst.BLST_P1_COMPRESS_BYTES
+
+	// BLSSignatureLen is the number of bytes in a compressed BLS signature
+	BLSSignatureLen = blst.BLST_P2_COMPRESS_BYTES
+)
+
+// BLS signatures use the message augmentation scheme, so every signer signs
+// its own public key prepended to the message. This makes aggregate signatures
+// safe against rogue key attacks without a proof of possession of each key.
+var blsDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_AUG_")
+
+var (
+	errInvalidBLSPublicKey  = errors.New("invalid BLS public key")
+	errInvalidBLSPrivateKey = errors.New("invalid BLS private key")
+	errInvalidBLSSignature  = errors.New("invalid BLS signature")
+	errNoBLSSignatures      = errors.New("no BLS signatures to aggregate")
+	errBLSKeyGeneration     = errors.New("failed to generate BLS private key")
+)
+
+// FactoryBLS creates BLS12-381 keys. Public keys are in G1 and signatures are
+// in G2.
+type FactoryBLS struct{}
+
+// NewPrivateKey implements the Factory interface
+func (*FactoryBLS) NewPrivateKey() (PrivateKey, error) {
+	ikm := [32]byte{}
+	if _, err := rand.Read(ikm[:]); err != nil {
+		return nil, err
+	}
+	sk := blst.KeyGen(ikm[:])
+	if sk == nil {
+		return nil, errBLSKeyGeneration
+	}
+	return &PrivateKeyBLS{sk: sk}, nil
+}
+
+// ToPublicKey implements the Factory interface
+func (*FactoryBLS) ToPublicKey(b []byte) (PublicKey, error) {
+	if len(b) != BLSPublicKeyLen {
+		return nil, errInvalidBLSPublicKey
+	}
+	pk := new(blst.P1Affine).Uncompress(b)
+	if pk == nil || !pk.KeyValidate() {
+		return nil, errInvalidBLSPublicKey
+	}
+	return &PublicKeyBLS{
+		pk:    pk,
+		bytes: append([]byte(nil), b...),
+	}, nil
+}
+
+// ToPrivateKey implements the Factory interface
+func (*FactoryBLS) ToPrivateKey(b []byte) (PrivateKey, error) {
+	if len(b) != BLSPrivateKeyLen {
+		return nil, errInvalidBLSPrivateKey
+	}
+	sk := new(blst.SecretKey).Deserialize(b)
+	if sk == nil || !sk.Valid() {
+		return nil, errInvalidBLSPrivateKey
+	}
+	return &PrivateKeyBLS{sk: sk}, nil
+}
+
+// AggregateSignatures returns the signature that aggregates [sigs]
+func (*FactoryBLS) AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errNoBLSSignatures
+	}
+	for _, sig := range sigs {
+		if len(sig) != BLSSignatureLen {
+			return nil, errInvalidBLSSignature
+		}
+	}
+	agg := new(blst.P2Aggregate)
+	if !agg.AggregateCompressed(sigs, true) {
+		return nil, errInvalidBLSSignature
+	}
+	return agg.ToAffine().Compress(), nil
+}
+
+// VerifyAggregate returns true if [sig] is the aggregate of the signatures of
+// [msg] by each of the keys in [pks]
+func (*FactoryBLS) VerifyAggregate(pks []*PublicKeyBLS, msg, sig []byte) bool {
+	if len(pks) == 0 || len(sig) != BLSSignatureLen {
+		return false
+	}
+	aggSig := new(blst.P2Affine).Uncompress(sig)
+	if aggSig == nil {
+		return false
+	}
+	points := make([]*blst.P1Affine, len(pks))
+	msgs := make([]blst.Message, len(pks))
+	augs := make([][]byte, len(pks))
+	for i, pk := range pks {
+		points[i] = pk.pk
+		msgs[i] = msg
+		augs[i] = pk.bytes
+	}
+	return aggSig.AggregateVerify(
+		true, // Check that the signature is in G2
+		points,
+		false, // The public keys were validated when they were parsed
+		msgs,
+		blsDST,
+		true, // Hash the messages to G2
+		augs,
+	)
+}
+
+// PublicKeyBLS ...
+type PublicKeyBLS struct {
+	pk    *blst.P1Affine
+	bytes []byte
+	addr  ids.ShortID
+}
+
+// Verify implements the PublicKey interface
+func (k *PublicKeyBLS) Verify(msg, sig []byte) bool {
+	if len(sig) != BLSSignatureLen {
+		return false
+	}
+	s := new(blst.P2Affine).Uncompress(sig)
+	if s == nil {
+		return false
+	}
+	return s.Verify(
+		true, // Check that the signature is in G2
+		k.pk,
+		false, // The public key was validated when it was parsed
+		msg,
+		blsDST,
+		true, // Hash the message to G2
+		k.bytes,
+	)
+}
+
+// VerifyHash implements the PublicKey interface
+func (k *PublicKeyBLS) VerifyHash(hash, sig []byte) bool {
+	return k.Verify(hash, sig)
+}
+
+// Address implements the PublicKey interface
+func (k *PublicKeyBLS) Address() ids.ShortID {
+	if k.addr.IsZero() {
+		addr, err := ids.ToShortID(hashing.PubkeyBytesToAddress(k.Bytes()))
+		if err != nil {
+			panic(err)
+		}
+		k.addr = addr
+	}
+	return k.addr
+}
+
+// Bytes implements the PublicKey interface
+func (k *PublicKeyBLS) Bytes() []byte { return k.bytes }
+
+// PrivateKeyBLS ...
+type PrivateKeyBLS struct {
+	sk *blst.SecretKey
+	pk *PublicKeyBLS
+}
+
+// PublicKey implements the PrivateKey interface
+func (k *PrivateKeyBLS) PublicKey() PublicKey { return k.publicKey() }
+
+func (k *PrivateKeyBLS) publicKey() *PublicKeyBLS {
+	if k.pk == nil {
+		pk := new(blst.P1Affine).From(k.sk)
+		k.pk = &PublicKeyBLS{
+			pk:    pk,
+			bytes: pk.Compress(),
+		}
+	}
+	return k.pk
+}
+
+// Sign implements the PrivateKey interface
+func (k *PrivateKeyBLS) Sign(msg []byte) ([]byte, error) {
+	pk := k.publicKey()
+	return new(blst.P2Affine).Sign(k.sk, msg, blsDST, pk.bytes).Compress(), nil
+}
+
+// SignHash implements the PrivateKey interface
+func (k *PrivateKeyBLS) SignHash(hash []byte) ([]byte, error) {
+	return k.Sign(hash)
+}
+
+// Bytes implements the PrivateKey interface
+func (k *PrivateKeyBLS) Bytes() []byte { return k.sk.Serialize() }
@@ -0,0 +1,150 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBLSSignVerify(t *testing.T) {
+	f := FactoryBLS{}
+	sk, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello")
+	sig, err := sk.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != BLSSignatureLen {
+		t.Fatalf("signature should be %d bytes but was %d", BLSSignatureLen, len(sig))
+	}
+
+	pk := sk.PublicKey()
+	if !pk.Verify(msg, sig) {
+		t.Fatalf("should have verified the signature")
+	}
+	if pk.Verify([]byte("goodbye"), sig) {
+		t.Fatalf("shouldn't have verified the signature of a different message")
+	}
+
+	otherSK, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherSK.PublicKey().Verify(msg, sig) {
+		t.Fatalf("shouldn't have verified the signature with a different key")
+	}
+	if pk.Verify(msg, sig[1:]) {
+		t.Fatalf("shouldn't have verified a truncated signature")
+	}
+}
+
+func TestBLSGenRecreate(t *testing.T) {
+	f := FactoryBLS{}
+	sk, err := f.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsedSK, err := f.ToPrivateKey(sk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sk.Bytes(), parsedSK.Bytes()) {
+		t.Fatalf("private key changed when it was parsed")
+	}
+
+	pk := sk.PublicKey()
+	parsedPK, err := f.ToPublicKey(pk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Address().Equals(parsedPK.Address()) {
+		t.Fatalf("address changed when the public key was parsed")
+	}
+	if !parsedPK.Address().Equals(parsedSK.PublicKey().Address()) {
+		t.Fatalf("parsed keys should have the same address")
+	}
+
+	pkBytes := append([]byte(nil), pk.Bytes()...)
+	parsedPK, err = f.ToPublicKey(pkBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkBytes[0] ^= 1
+	if !bytes.Equal(parsedPK.Bytes(), pk.Bytes()) {
+		t.Fatalf("parsed public key shouldn't share memory with the bytes it was parsed from")
+	}
+}
+
+func TestBLSInvalidKeys(t *testing.T) {
+	f := FactoryBLS{}
+	if _, err := f.ToPublicKey(make([]byte, BLSPublicKeyLen)); err == nil {
+		t.Fatalf("should have errored on an invalid public key")
+	}
+	if _, err := f.ToPublicKey(nil); err == nil {
+		t.Fatalf("should have errored on an empty public key")
+	}
+	if _, err := f.ToPrivateKey(make([]byte, BLSPrivateKeyLen)); err == nil {
+		t.Fatalf("should have errored on a zero private key")
+	}
+	if _, err := f.ToPrivateKey(nil); err == nil {
+		t.Fatalf("should have errored on an empty private key")
+	}
+}
+
+func TestBLSAggregate(t *testing.T) {
+	f := FactoryBLS{}
+	msg := []byte("hello")
+
+	pks := []*PublicKeyBLS(nil)
+	sigs := [][]byte(nil)
+	for i := 0; i < 3; i++ {
+		sk, err := f.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := sk.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pks = append(pks, sk.PublicKey().(*PublicKeyBLS))
+		sigs = append(sigs, sig)
+	}
+
+	aggSig, err := f.AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.VerifyAggregate(pks, msg, aggSig) {
+		t.Fatalf("should have verified the aggregate signature")
+	}
+	if f.VerifyAggregate(pks, []byte("goodbye"), aggSig) {
+		t.Fatalf("shouldn't have verified the aggregate signature of a different message")
+	}
+	if f.VerifyAggregate(pks[1:], msg, aggSig) {
+		t.Fatalf("shouldn't have verified the aggregate signature without all its signers")
+	}
+	if f.VerifyAggregate(nil, msg, aggSig) {
+		t.Fatalf("shouldn't have verified the aggregate signature without any signers")
+	}
+
+	partialSig, err := f.AggregateSignatures(sigs[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.VerifyAggregate(pks, msg, partialSig) {
+		t.Fatalf("shouldn't have verified an aggregate signature missing a signature")
+	}
+
+	if _, err := f.AggregateSignatures(nil); err == nil {
+		t.Fatalf("should have errored when aggregating no signatures")
+	}
+	if _, err := f.AggregateSignatures([][]byte{make([]byte, BLSSignatureLen)}); err == nil {
+		t.Fatalf("should have errored when aggregating an invalid signature")
+	}
+}
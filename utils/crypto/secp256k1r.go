@@ -83,6 +83,9 @@ func (f *FactorySECP256K1R) RecoverHashPublicKey(hash, sig []byte) (PublicKey, e
 		return nil, err
 	}
 	pubkey := &PublicKeySECP256K1{pk: rawPubkey}
+	// Cached keys are shared between goroutines, so their lazily computed
+	// fields are computed before the key is cached
+	pubkey.Address()
 	f.Cache.Put(id, pubkey)
 	return pubkey, nil
 }
@@ -42,6 +42,35 @@ func TestCachedRecover(t *testing.T) {
 	}
 }
 
+func TestCachedRecoverShared(t *testing.T) {
+	f := FactorySECP256K1R{Cache: cache.LRU{Size: 16}}
+	key, _ := f.NewPrivateKey()
+
+	hash := hashing.ComputeHash256([]byte{1, 2, 3})
+	sig, _ := key.SignHash(hash)
+	addr := key.PublicKey().Address()
+
+	// Re-verifications of the same signature share the cached public key
+	for i := 0; i < 3; i++ {
+		batch := NewBatchVerifierSECP256K1R(&f)
+		for j := byte(0); j < 4; j++ {
+			batch.Add(hash, sig, addr)
+			otherHash := hashing.ComputeHash256([]byte{j})
+			otherSig, _ := key.SignHash(otherHash)
+			batch.Add(otherHash, otherSig, addr)
+		}
+		if err := batch.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pub1, _ := f.RecoverHashPublicKey(hash, sig)
+	pub2, _ := f.RecoverHashPublicKey(hash, sig)
+	if pub1 != pub2 {
+		t.Fatalf("Should have returned the same public key")
+	}
+}
+
 func TestExtensive(t *testing.T) {
 	f := FactorySECP256K1R{}
 
@@ -0,0 +1,238 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package dynamicip resolves the IP this node is reachable at from the
+// internet, and keeps it up to date when it changes.
+package dynamicip
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// STUN resolves the public IP with a STUN server
+	STUN = "stun"
+	// IFConfig resolves the public IP with the ifconfig.co service
+	IFConfig = "ifconfig"
+
+	stunServer  = "stun.l.google.com:19302"
+	ifconfigURL = "https://ifconfig.co/ip"
+
+	resolveTimeout = 10 * time.Second
+
+	// maxIPResponseSize is the largest response read from an HTTP service
+	maxIPResponseSize = 256
+
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunHeaderLen       = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXORMappedAddress = 0x0020
+)
+
+var errSTUNNoAddress = errors.New("STUN server didn't return this host's address")
+
+// Resolver resolves the IP this host is reachable at from the internet
+type Resolver interface {
+	Resolve() (net.IP, error)
+}
+
+// NewResolver returns the resolver that uses [service], which is STUN or
+// IFConfig
+func NewResolver(service string) (Resolver, error) {
+	switch strings.ToLower(service) {
+	case STUN:
+		return &stunResolver{server: stunServer}, nil
+	case IFConfig:
+		return &httpResolver{
+			client: http.Client{Timeout: resolveTimeout},
+			url:    ifconfigURL,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown IP resolution service %q. Should be one of {%s, %s}", service, STUN, IFConfig)
+	}
+}
+
+// httpResolver resolves the IP with a service that returns the IP a request
+// came from as plain text
+type httpResolver struct {
+	client http.Client
+	url    string
+}
+
+func (r *httpResolver) Resolve() (net.IP, error) {
+	resp, err := r.client.Get(r.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %s", r.url, resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxIPResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(string(bytes.TrimSpace(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("%s returned an invalid IP", r.url)
+	}
+	return ip, nil
+}
+
+// stunResolver resolves the IP with a STUN binding request (RFC 5389)
+type stunResolver struct{ server string }
+
+func (r *stunResolver) Resolve() (net.IP, error) {
+	conn, err := net.Dial("udp4", r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(req, stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+	if _, err := rand.Read(req[8:stunHeaderLen]); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(resolveTimeout)); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, 1500)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return nil, err
+	}
+	return parseSTUNResponse(resp[:n], req[8:stunHeaderLen])
+}
+
+// parseSTUNResponse returns the address in the response [resp] to the binding
+// request with the transaction ID [txID]
+func parseSTUNResponse(resp, txID []byte) (net.IP, error) {
+	if len(resp) < stunHeaderLen ||
+		binary.BigEndian.Uint16(resp) != stunBindingResponse ||
+		binary.BigEndian.Uint32(resp[4:]) != stunMagicCookie ||
+		!bytes.Equal(resp[8:stunHeaderLen], txID) {
+		return nil, errors.New("malformed STUN response")
+	}
+
+	attrs := resp[stunHeaderLen:]
+	if msgLen := int(binary.BigEndian.Uint16(resp[2:])); msgLen < len(attrs) {
+		attrs = attrs[:msgLen]
+	}
+	var mapped net.IP
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs)
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if len(attrs) < 4+attrLen {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+		// Attributes are padded to a multiple of 4 bytes
+		if next := 4 + (attrLen+3)&^3; next < len(attrs) {
+			attrs = attrs[next:]
+		} else {
+			attrs = nil
+		}
+
+		// An IPv4 address is: reserved byte, family 0x01, port, 4 byte IP
+		if len(value) != 8 || value[1] != 0x01 {
+			continue
+		}
+		ip := net.IPv4(value[4], value[5], value[6], value[7]).To4()
+		switch attrType {
+		case stunAttrXORMappedAddress:
+			cookie := make([]byte, 4)
+			binary.BigEndian.PutUint32(cookie, stunMagicCookie)
+			for i := range ip {
+				ip[i] ^= cookie[i]
+			}
+			return ip, nil
+		case stunAttrMappedAddress:
+			mapped = ip
+		}
+	}
+	if mapped == nil {
+		return nil, errSTUNNoAddress
+	}
+	return mapped, nil
+}
+
+// IPUpdater keeps an IP up to date with the IP a resolver returns
+type IPUpdater struct {
+	log       logging.Logger
+	resolver  Resolver
+	ip        *utils.DynamicIPDesc
+	frequency time.Duration
+	onChange  []func(utils.IPDesc)
+
+	closer    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewIPUpdater returns an updater that resolves the IP of [ip] with [resolver]
+// every [frequency], and calls [onChange] with the new IP when it changes
+func NewIPUpdater(log logging.Logger, resolver Resolver, ip *utils.DynamicIPDesc, frequency time.Duration, onChange ...func(utils.IPDesc)) *IPUpdater {
+	return &IPUpdater{
+		log:       log,
+		resolver:  resolver,
+		ip:        ip,
+		frequency: frequency,
+		onChange:  onChange,
+		closer:    make(chan struct{}),
+	}
+}
+
+// Dispatch updates the IP until Stop is called
+func (u *IPUpdater) Dispatch() {
+	ticker := time.NewTicker(u.frequency)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			u.update()
+		case <-u.closer:
+			return
+		}
+	}
+}
+
+func (u *IPUpdater) update() {
+	newIP, err := u.resolver.Resolve()
+	if err != nil {
+		u.log.Warn("failed to resolve this node's public IP: %s", err)
+		return
+	}
+	oldIP := u.ip.IP()
+	if oldIP.IP.Equal(newIP) {
+		return
+	}
+	u.ip.UpdateIP(newIP)
+	u.log.Info("this node's public IP changed from %s to %s", oldIP.IP, newIP)
+	for _, onChange := range u.onChange {
+		onChange(u.ip.IP())
+	}
+}
+
+// Stop updating the IP
+func (u *IPUpdater) Stop() { u.closeOnce.Do(func() { close(u.closer) }) }
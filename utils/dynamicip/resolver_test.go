@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package dynamicip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+func TestParseSTUNResponse(t *testing.T) {
+	txID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	resp := []byte{
+		0x01, 0x01, 0x00, 0x18, // Binding response, 24 bytes of attributes
+		0x21, 0x12, 0xA4, 0x42, // Magic cookie
+	}
+	resp = append(resp, txID...)
+	resp = append(resp,
+		0x80, 0x22, 0x00, 0x03, 'a', 'b', 'c', 0x00, // Software, padded
+		0x00, 0x20, 0x00, 0x08, // XOR-MAPPED-ADDRESS
+		0x00, 0x01, 0x00, 0x00, // IPv4
+		203^0x21, 0^0x12, 113^0xA4, 9^0x42,
+	)
+
+	ip, err := parseSTUNResponse(resp, txID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.IPv4(203, 0, 113, 9)) {
+		t.Fatalf("IP should be 203.0.113.9 but is %s", ip)
+	}
+
+	if _, err := parseSTUNResponse(resp, make([]byte, 12)); err == nil {
+		t.Fatalf("Should have rejected a response to another request")
+	}
+}
+
+type testResolver struct{ ip net.IP }
+
+func (r *testResolver) Resolve() (net.IP, error) { return r.ip, nil }
+
+func TestIPUpdater(t *testing.T) {
+	ip := utils.NewDynamicIPDesc(net.IPv4(198, 51, 100, 1), 9651)
+	changes := []utils.IPDesc(nil)
+	u := NewIPUpdater(logging.NoLog{}, &testResolver{ip: net.IPv4(198, 51, 100, 2)}, ip, time.Hour, func(ip utils.IPDesc) {
+		changes = append(changes, ip)
+	})
+	u.update()
+
+	if current := ip.IP(); !current.IP.Equal(net.IPv4(198, 51, 100, 2)) || current.Port != 9651 {
+		t.Fatalf("IP should have been updated to 198.51.100.2:9651 but is %s", current)
+	}
+	if len(changes) != 1 || !changes[0].Equal(ip.IP()) {
+		t.Fatalf("The change should have been reported once but was reported %v", changes)
+	}
+
+	// An unchanged IP isn't reported
+	u.update()
+	if len(changes) != 1 {
+		t.Fatalf("An unchanged IP shouldn't have been reported")
+	}
+}
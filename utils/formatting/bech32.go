@@ -0,0 +1,215 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	bech32Charset     = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Sep         = '1'
+	bech32ChecksumLen = 6
+	bech32MaxLen      = 90
+
+	// AddressSep separates the chain alias from the rest of an address
+	AddressSep = "-"
+)
+
+var (
+	// The human readable parts of bech32 addresses on the named networks.
+	// Other networks use [defaultHRP].
+	networkHRPs = map[uint32]string{
+		1:     "ava",      // mainnet
+		2:     "borealis", // testnet
+		12345: "local",
+	}
+	defaultHRP = "custom"
+
+	bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	errBech32TooLong      = errors.New("bech32 string is too long")
+	errBech32MixedCase    = errors.New("bech32 string has mixed case")
+	errBech32InvalidChar  = errors.New("bech32 string has an invalid character")
+	errBech32NoSeparator  = errors.New("bech32 string has no separator")
+	errBech32BadChecksum  = errors.New("bech32 string has an invalid checksum")
+	errBech32InvalidHRP   = errors.New("invalid bech32 human readable part")
+	errBech32InvalidData  = errors.New("invalid bech32 data")
+	errNoChainAlias       = errors.New("address has no chain alias")
+	errMultipleChainAlias = errors.New("address has more than one chain alias")
+)
+
+// Bech32HRP returns the human readable part of bech32 addresses on the network
+// with ID [networkID]
+func Bech32HRP(networkID uint32) string {
+	if hrp, ok := networkHRPs[networkID]; ok {
+		return hrp
+	}
+	return defaultHRP
+}
+
+// FormatBech32 returns the bech32 encoding of [payload] with the human
+// readable part [hrp]
+func FormatBech32(hrp string, payload []byte) (string, error) {
+	if len(hrp) == 0 {
+		return "", errBech32InvalidHRP
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 || (c >= 'A' && c <= 'Z') {
+			return "", errBech32InvalidHRP
+		}
+	}
+	data, err := convertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	data = append(data, bech32Checksum(hrp, data)...)
+	if len(hrp)+1+len(data) > bech32MaxLen {
+		return "", errBech32TooLong
+	}
+
+	s := strings.Builder{}
+	s.Grow(len(hrp) + 1 + len(data))
+	s.WriteString(hrp)
+	s.WriteByte(bech32Sep)
+	for _, d := range data {
+		s.WriteByte(bech32Charset[d])
+	}
+	return s.String(), nil
+}
+
+// ParseBech32 parses a string encoded by FormatBech32, and returns its human
+// readable part and payload
+func ParseBech32(str string) (string, []byte, error) {
+	if len(str) > bech32MaxLen {
+		return "", nil, errBech32TooLong
+	}
+	lower := strings.ToLower(str)
+	if lower != str && strings.ToUpper(str) != str {
+		return "", nil, errBech32MixedCase
+	}
+	for i := 0; i < len(lower); i++ {
+		if lower[i] < 33 || lower[i] > 126 {
+			return "", nil, errBech32InvalidChar
+		}
+	}
+
+	sepIndex := strings.LastIndexByte(lower, bech32Sep)
+	switch {
+	case sepIndex < 0:
+		return "", nil, errBech32NoSeparator
+	case sepIndex == 0:
+		return "", nil, errBech32InvalidHRP
+	case sepIndex+1+bech32ChecksumLen > len(lower):
+		return "", nil, errBech32BadChecksum
+	}
+
+	hrp := lower[:sepIndex]
+	data := make([]byte, len(lower)-sepIndex-1)
+	for i := range data {
+		d := strings.IndexByte(bech32Charset, lower[sepIndex+1+i])
+		if d < 0 {
+			return "", nil, errBech32InvalidChar
+		}
+		data[i] = byte(d)
+	}
+	if bech32Polymod(append(bech32ExpandHRP(hrp), data...)) != 1 {
+		return "", nil, errBech32BadChecksum
+	}
+
+	payload, err := convertBits(data[:len(data)-bech32ChecksumLen], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, payload, nil
+}
+
+// FormatAddress returns [addr] formatted as a bech32 address with the human
+// readable part [hrp], prefixed by [chainAlias]
+func FormatAddress(chainAlias, hrp string, addr []byte) (string, error) {
+	addrStr, err := FormatBech32(hrp, addr)
+	if err != nil {
+		return "", err
+	}
+	return chainAlias + AddressSep + addrStr, nil
+}
+
+// ParseAddress parses an address formatted by FormatAddress, and returns its
+// chain alias, human readable part and address bytes
+func ParseAddress(addrStr string) (string, string, []byte, error) {
+	switch count := strings.Count(addrStr, AddressSep); {
+	case count == 0:
+		return "", "", nil, errNoChainAlias
+	case count > 1:
+		return "", "", nil, errMultipleChainAlias
+	}
+	parts := strings.SplitN(addrStr, AddressSep, 2)
+	hrp, addr, err := ParseBech32(parts[1])
+	return parts[0], hrp, addr, err
+}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range bech32Generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandHRP(hrp string) []byte {
+	expanded := make([]byte, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded[i] = hrp[i] >> 5
+		expanded[len(hrp)+1+i] = hrp[i] & 31
+	}
+	return expanded
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32ExpandHRP(hrp), data...)
+	values = append(values, make([]byte, bech32ChecksumLen)...)
+	mod := bech32Polymod(values) ^ 1
+
+	checksum := make([]byte, bech32ChecksumLen)
+	for i := range checksum {
+		checksum[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups [data] from groups of [fromBits] bits to groups of
+// [toBits] bits. If [pad], the last group is padded with zeros. Otherwise, the
+// padding must be less than [fromBits] zero bits.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	acc := uint32(0)
+	bits := uint(0)
+	maxV := uint32(1)<<toBits - 1
+	converted := make([]byte, 0, (uint(len(data))*fromBits+toBits-1)/toBits)
+	for _, b := range data {
+		if uint32(b)>>fromBits != 0 {
+			return nil, fmt.Errorf("%w: value %d exceeds %d bits", errBech32InvalidData, b, fromBits)
+		}
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(acc>>bits&maxV))
+		}
+	}
+	switch {
+	case pad && bits > 0:
+		converted = append(converted, byte(acc<<(toBits-bits)&maxV))
+	case !pad && (bits >= fromBits || acc<<(toBits-bits)&maxV != 0):
+		return nil, fmt.Errorf("%w: invalid padding", errBech32InvalidData)
+	}
+	return converted, nil
+}
@@ -0,0 +1,118 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBech32Valid(t *testing.T) {
+	// Test vectors from BIP 173
+	valid := []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"11" + strings.Repeat("q", 82) + "c8247j",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+		"?1ezyfcl",
+	}
+	for _, str := range valid {
+		hrp, data, err := ParseBech32(str)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %s", str, err)
+		}
+		sepIndex := strings.LastIndexByte(str, '1')
+		if hrp != strings.ToLower(str[:sepIndex]) {
+			t.Fatalf("Parsed the wrong human readable part %s from %s", hrp, str)
+		}
+		// Only strings whose data is a whole number of bytes can be re-encoded
+		if numBits := 5 * (len(str) - sepIndex - 1 - bech32ChecksumLen); numBits%8 != 0 {
+			continue
+		}
+		formatted, err := FormatBech32(hrp, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if formatted != strings.ToLower(str) {
+			t.Fatalf("Expected %s, got %s", strings.ToLower(str), formatted)
+		}
+	}
+}
+
+func TestBech32Invalid(t *testing.T) {
+	// Test vectors from BIP 173
+	invalid := []string{
+		"\x201nwldj5",         // HRP character out of range
+		"\x7f1axkwrx",         // HRP character out of range
+		"pzry9x0s0muk",        // No separator
+		"1pzry9x0s0muk",       // Empty HRP
+		"x1b4n0q5v",           // Invalid data character
+		"li1dgmt3",            // Too short checksum
+		"A1G7SGD8",            // Checksum calculated with uppercase HRP
+		"10a06t8",             // Empty HRP
+		"1qzzfhee",            // Empty HRP
+		"a12UEL5L",            // Mixed case
+		"ava1qqqqqqqqqqqqqqq", // Bad checksum
+		"an84characterslonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1569pvx", // Too long
+	}
+	for _, str := range invalid {
+		if _, _, err := ParseBech32(str); err == nil {
+			t.Fatalf("Should have failed to parse %q", str)
+		}
+	}
+}
+
+func TestBech32Address(t *testing.T) {
+	addr := []byte{
+		0x01, 0x5c, 0xce, 0x6c, 0x55, 0xd6, 0xb5, 0x09,
+		0x84, 0x5c, 0x8c, 0x4e, 0x30, 0xbe, 0xd9, 0x8d,
+		0x39, 0x1a, 0xe7, 0xf0,
+	}
+	addrStr, err := FormatAddress("X", Bech32HRP(12345), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(addrStr, "X-local1") {
+		t.Fatalf("Address %s should have been prefixed by the chain alias and network", addrStr)
+	}
+
+	chainAlias, hrp, parsedAddr, err := ParseAddress(addrStr)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case chainAlias != "X":
+		t.Fatalf("Expected chain alias X, got %s", chainAlias)
+	case hrp != "local":
+		t.Fatalf("Expected human readable part local, got %s", hrp)
+	case !bytes.Equal(addr, parsedAddr):
+		t.Fatalf("Expected 0x%x, got 0x%x", addr, parsedAddr)
+	}
+
+	// Changing any character is detected
+	corrupted := []byte(addrStr)
+	if corrupted[len(corrupted)-1] == 'q' {
+		corrupted[len(corrupted)-1] = 'p'
+	} else {
+		corrupted[len(corrupted)-1] = 'q'
+	}
+	if _, _, _, err := ParseAddress(string(corrupted)); err == nil {
+		t.Fatalf("Should have failed to parse a corrupted address")
+	}
+
+	if _, _, _, err := ParseAddress(addrStr[2:]); err == nil {
+		t.Fatalf("Should have failed to parse an address without a chain alias")
+	}
+}
+
+func TestBech32HRP(t *testing.T) {
+	if hrp := Bech32HRP(1); hrp != "ava" {
+		t.Fatalf("Expected ava, got %s", hrp)
+	}
+	if hrp := Bech32HRP(1337); hrp != defaultHRP {
+		t.Fatalf("Expected %s, got %s", defaultHRP, hrp)
+	}
+}
@@ -0,0 +1,139 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Names of the encodings that are always available
+const (
+	CB58Encoding   = "cb58"
+	HexEncoding    = "hex"
+	Base64Encoding = "base64"
+)
+
+var (
+	encodingsLock sync.RWMutex
+	encodings     = map[string]Encoding{
+		CB58Encoding:   cb58Encoding{},
+		HexEncoding:    hexEncoding{},
+		Base64Encoding: base64Encoding{},
+	}
+
+	errUnknownEncoding    = errors.New("unknown encoding")
+	errDuplicatedEncoding = errors.New("encoding is already registered")
+)
+
+// Encoding is a representation of bytes as a string
+type Encoding interface {
+	// Encode returns the string representation of [b]
+	Encode(b []byte) string
+
+	// Decode returns the bytes that [str] represents
+	Decode(str string) ([]byte, error)
+}
+
+// RegisterEncoding makes [encoding] available by [name]
+func RegisterEncoding(name string, encoding Encoding) error {
+	name = strings.ToLower(name)
+
+	encodingsLock.Lock()
+	defer encodingsLock.Unlock()
+
+	if _, exists := encodings[name]; exists {
+		return fmt.Errorf("%w: %s", errDuplicatedEncoding, name)
+	}
+	encodings[name] = encoding
+	return nil
+}
+
+// GetEncoding returns the encoding registered as [name]. The empty name is
+// CB58, which is the default encoding of the APIs.
+func GetEncoding(name string) (Encoding, error) {
+	if name == "" {
+		name = CB58Encoding
+	}
+	name = strings.ToLower(name)
+
+	encodingsLock.RLock()
+	defer encodingsLock.RUnlock()
+
+	encoding, ok := encodings[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownEncoding, name)
+	}
+	return encoding, nil
+}
+
+// EncodedBytes formats bytes in JSON with an encoding. CB58 is used if the
+// encoding isn't set.
+type EncodedBytes struct {
+	Bytes    []byte
+	Encoding Encoding
+}
+
+// UnmarshalJSON ...
+func (e *EncodedBytes) UnmarshalJSON(b []byte) error {
+	str := string(b)
+	if str == "null" {
+		return nil
+	}
+	str, err := strconv.Unquote(str)
+	if err != nil {
+		return errMissingQuotes
+	}
+	e.Bytes, err = e.encoding().Decode(str)
+	return err
+}
+
+// MarshalJSON ...
+func (e EncodedBytes) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(e.String())), nil
+}
+
+func (e EncodedBytes) String() string { return e.encoding().Encode(e.Bytes) }
+
+func (e EncodedBytes) encoding() Encoding {
+	if e.Encoding == nil {
+		return cb58Encoding{}
+	}
+	return e.Encoding
+}
+
+type cb58Encoding struct{}
+
+func (cb58Encoding) Encode(b []byte) string { return CB58{Bytes: b}.String() }
+
+func (cb58Encoding) Decode(str string) ([]byte, error) {
+	cb58 := CB58{}
+	err := cb58.FromString(str)
+	return cb58.Bytes, err
+}
+
+// Hex strings are written with a 0x prefix, which is optional when parsing
+type hexEncoding struct{}
+
+func (hexEncoding) Encode(b []byte) string { return "0x" + hex.EncodeToString(b) }
+
+func (hexEncoding) Decode(str string) ([]byte, error) {
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") {
+		str = str[2:]
+	}
+	return hex.DecodeString(str)
+}
+
+type base64Encoding struct{}
+
+func (base64Encoding) Encode(b []byte) string { return base64.StdEncoding.EncodeToString(b) }
+
+func (base64Encoding) Decode(str string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(str)
+}
@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package formatting
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEncodings(t *testing.T) {
+	b := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 255}
+	expected := map[string]string{
+		"":             "1NVSVezva3bAtJesnUj",
+		CB58Encoding:   "1NVSVezva3bAtJesnUj",
+		HexEncoding:    "0x00010203040506070809ff",
+		Base64Encoding: "AAECAwQFBgcICf8=",
+	}
+	for name, str := range expected {
+		encoding, err := GetEncoding(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encoded := encoding.Encode(b); encoded != str {
+			t.Fatalf("Expected %s, got %s", str, encoded)
+		}
+		decoded, err := encoding.Decode(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, decoded) {
+			t.Fatalf("Expected 0x%x, got 0x%x", b, decoded)
+		}
+	}
+
+	if _, err := GetEncoding("base32"); err == nil {
+		t.Fatalf("Should have errored due to an unknown encoding")
+	}
+}
+
+type upperHexEncoding struct{ hexEncoding }
+
+func (e upperHexEncoding) Encode(b []byte) string {
+	return strings.ToUpper(e.hexEncoding.Encode(b)[2:])
+}
+
+func TestRegisterEncoding(t *testing.T) {
+	if err := RegisterEncoding("upperhex", upperHexEncoding{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterEncoding("UpperHex", upperHexEncoding{}); err == nil {
+		t.Fatalf("Should have errored due to registering an encoding twice")
+	}
+
+	encoding, err := GetEncoding("UPPERHEX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if str := encoding.Encode([]byte{0xab}); str != "AB" {
+		t.Fatalf("Expected AB, got %s", str)
+	}
+}
+
+func TestEncodedBytesJSON(t *testing.T) {
+	b := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 255}
+	hexEncoding, err := GetEncoding(HexEncoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonBytes, err := json.Marshal([]EncodedBytes{{Bytes: b}, {Bytes: b, Encoding: hexEncoding}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `["1NVSVezva3bAtJesnUj","0x00010203040506070809ff"]`; string(jsonBytes) != expected {
+		t.Fatalf("Expected %s, got %s", expected, jsonBytes)
+	}
+
+	parsed := EncodedBytes{Encoding: hexEncoding}
+	if err := json.Unmarshal([]byte(`"0x00010203040506070809ff"`), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, parsed.Bytes) {
+		t.Fatalf("Expected 0x%x, got 0x%x", b, parsed.Bytes)
+	}
+	if err := json.Unmarshal([]byte(`0x00`), &parsed); err == nil {
+		t.Fatalf("Should have errored due to missing quotes")
+	}
+}
@@ -0,0 +1,79 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"crypto/sha256"
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+)
+
+// NewHash256 returns a streaming hasher that computes the same hash as
+// ComputeHash256
+func NewHash256() hash.Hash { return sha256.New() }
+
+// NewHash160 returns a streaming hasher that computes the same hash as
+// ComputeHash160
+func NewHash160() hash.Hash { return ripemd160.New() }
+
+// NewKeccak256 returns a streaming hasher that computes the same hash as
+// ComputeKeccak256. This is the legacy Keccak used by Ethereum, not the
+// standardized SHA3-256.
+func NewKeccak256() hash.Hash { return sha3.NewLegacyKeccak256() }
+
+// NewBlake2b256 returns a streaming hasher that computes the same hash as
+// ComputeBlake2b256
+func NewBlake2b256() hash.Hash {
+	h, err := blake2b.New256(nil)
+	if err != nil {
+		// Only returned for keys that are too long, and no key is used
+		panic(err)
+	}
+	return h
+}
+
+// ComputeKeccak256Array computes the 256 bit Keccak hash of [buf]
+func ComputeKeccak256Array(buf []byte) Hash256 {
+	h := Hash256{}
+	copy(h[:], ComputeKeccak256(buf))
+	return h
+}
+
+// ComputeKeccak256 computes the 256 bit Keccak hash of [buf]
+func ComputeKeccak256(buf []byte) []byte {
+	return computeRanges(NewKeccak256(), buf, [][2]int{{0, len(buf)}})
+}
+
+// ComputeKeccak256Ranges computes the 256 bit Keccak hash of [buf] in the
+// ranges specified, in the same way as ComputeHash256Ranges
+func ComputeKeccak256Ranges(buf []byte, ranges [][2]int) []byte {
+	return computeRanges(NewKeccak256(), buf, ranges)
+}
+
+// ComputeBlake2b256Array computes the 256 bit BLAKE2b hash of [buf]
+func ComputeBlake2b256Array(buf []byte) Hash256 { return blake2b.Sum256(buf) }
+
+// ComputeBlake2b256 computes the 256 bit BLAKE2b hash of [buf]
+func ComputeBlake2b256(buf []byte) []byte {
+	arr := ComputeBlake2b256Array(buf)
+	return arr[:]
+}
+
+// ComputeBlake2b256Ranges computes the 256 bit BLAKE2b hash of [buf] in the
+// ranges specified, in the same way as ComputeHash256Ranges
+func ComputeBlake2b256Ranges(buf []byte, ranges [][2]int) []byte {
+	return computeRanges(NewBlake2b256(), buf, ranges)
+}
+
+func computeRanges(h hash.Hash, buf []byte, ranges [][2]int) []byte {
+	for _, r := range ranges {
+		if _, err := h.Write(buf[r[0]:r[1]]); err != nil {
+			panic(err)
+		}
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package hashing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestKeccak256(t *testing.T) {
+	// Known answers of Ethereum's Keccak-256
+	for input, expected := range map[string]string{
+		"":    "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	} {
+		if h := hex.EncodeToString(ComputeKeccak256([]byte(input))); h != expected {
+			t.Fatalf("Keccak256(%q) = %s, expected %s", input, h, expected)
+		}
+		h := ComputeKeccak256Array([]byte(input))
+		if hex.EncodeToString(h[:]) != expected {
+			t.Fatalf("Keccak256Array(%q) = %x, expected %s", input, h, expected)
+		}
+	}
+}
+
+func TestBlake2b256(t *testing.T) {
+	expected := "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"
+	if h := hex.EncodeToString(ComputeBlake2b256([]byte("abc"))); h != expected {
+		t.Fatalf("Blake2b256(\"abc\") = %s, expected %s", h, expected)
+	}
+}
+
+func TestComputeRanges(t *testing.T) {
+	buf := []byte{1, 2, 4, 8, 16}
+	ranges := [][2]int{{1, 2}, {3, 5}}
+	subset := []byte{2, 8, 16}
+
+	if !bytes.Equal(ComputeKeccak256Ranges(buf, ranges), ComputeKeccak256(subset)) {
+		t.Fatalf("Keccak256 of the ranges should equal the hash of the subset")
+	}
+	if !bytes.Equal(ComputeBlake2b256Ranges(buf, ranges), ComputeBlake2b256(subset)) {
+		t.Fatalf("Blake2b256 of the ranges should equal the hash of the subset")
+	}
+
+	// The streaming hashers compute the same hashes as the one-shot functions
+	h := NewHash256()
+	h.Write(subset)
+	if !bytes.Equal(h.Sum(nil), ComputeHash256Ranges(buf, ranges)) {
+		t.Fatalf("Streaming sha256 hash differs")
+	}
+	h = NewHash160()
+	h.Write(subset)
+	if !bytes.Equal(h.Sum(nil), ComputeHash160(subset)) {
+		t.Fatalf("Streaming ripemd160 hash differs")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var (
@@ -62,3 +63,30 @@ func MyIP() net.IP {
 	// TODO: Change this to consult a json-returning external service
 	return net.ParseIP("127.0.0.1")
 }
+
+// DynamicIPDesc is an IPDesc whose IP can change while it's in use
+type DynamicIPDesc struct {
+	lock   sync.RWMutex
+	ipDesc IPDesc
+}
+
+// NewDynamicIPDesc returns a DynamicIPDesc of [ip] and [port]
+func NewDynamicIPDesc(ip net.IP, port uint16) *DynamicIPDesc {
+	return &DynamicIPDesc{ipDesc: IPDesc{IP: ip, Port: port}}
+}
+
+// IP returns the current IP and port
+func (d *DynamicIPDesc) IP() IPDesc {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	return d.ipDesc
+}
+
+// UpdateIP sets the IP to [ip]
+func (d *DynamicIPDesc) UpdateIP(ip net.IP) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.ipDesc.IP = ip
+}
@@ -0,0 +1,141 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	stdjson "encoding/json"
+)
+
+// Floats with an integral value smaller than this are written as integers
+const maxIntegralFloat = 1e21
+
+var (
+	errInvalidNumber = errors.New("invalid JSON number")
+)
+
+// Canonical returns the canonical JSON encoding of [v], so that digests of
+// the encoding are the same in every implementation:
+//   * There is no insignificant whitespace.
+//   * Object keys are sorted by their UTF-8 bytes.
+//   * Strings only escape the characters JSON requires to be escaped.
+//   * Integers are written exactly. Other numbers with an integral value
+//     below 1e21 are written as integers, without an exponent or fraction.
+//     The rest are written in the shortest form that parses to the same
+//     float64.
+// [v] is first encoded with encoding/json, so its MarshalJSON methods and
+// struct tags are respected.
+func Canonical(v interface{}) ([]byte, error) {
+	b, err := stdjson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := stdjson.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch value := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(value))
+	case stdjson.Number:
+		num, err := canonicalNumber(value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(num)
+	case string:
+		return writeCanonicalString(buf, value)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elt := range value {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elt); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, value[key]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", value)
+	}
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, str string) error {
+	encoder := stdjson.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(str); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // Remove the newline written by Encode
+	return nil
+}
+
+func canonicalNumber(num stdjson.Number) (string, error) {
+	str := num.String()
+	if !strings.ContainsAny(str, ".eE") {
+		// Integers are kept exact, even if they don't fit in a float64
+		i, ok := new(big.Int).SetString(str, 10)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", errInvalidNumber, str)
+		}
+		return i.String(), nil
+	}
+
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidNumber, str)
+	}
+	if f == 0 {
+		return "0", nil // Negative zero is written as 0
+	}
+	if f == math.Trunc(f) && math.Abs(f) < maxIntegralFloat {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	type inner struct {
+		Zebra  string  `json:"zebra"`
+		Apple  float64 `json:"apple"`
+		Amount Uint64  `json:"amount"`
+	}
+	type outer struct {
+		List  []interface{}          `json:"list"`
+		Inner inner                  `json:"inner"`
+		Map   map[string]interface{} `json:"map"`
+		Nil   *inner                 `json:"nil"`
+	}
+
+	val := outer{
+		List: []interface{}{true, 1.5, 2.0, 1e21, -0.0, "<&>"},
+		Inner: inner{
+			Zebra:  "z\n\"",
+			Apple:  0.1,
+			Amount: 18446744073709551615,
+		},
+		Map: map[string]interface{}{
+			"b": 1,
+			"a": []int{},
+			"":  nil,
+		},
+	}
+
+	expected := `{"inner":{"amount":"18446744073709551615","apple":0.1,"zebra":"z\n\""},` +
+		`"list":[true,1.5,2,1e+21,0,"<&>"],"map":{"":null,"a":[],"b":1},"nil":null}`
+
+	b, err := Canonical(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != expected {
+		t.Fatalf("Canonical returned:\n%s\nexpected:\n%s", b, expected)
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	tests := map[string]string{
+		"0":                     "0",
+		"-0":                    "0",
+		"123456789012345678901": "123456789012345678901",
+		"1.0":                   "1",
+		"1e3":                   "1000",
+		"1E-7":                  "1e-07",
+		"-2.50":                 "-2.5",
+	}
+	for num, expected := range tests {
+		canonical, err := Canonical(rawNumber(num))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(canonical) != expected {
+			t.Fatalf("Canonical form of %s is %s, expected %s", num, canonical, expected)
+		}
+	}
+}
+
+type rawNumber string
+
+func (n rawNumber) MarshalJSON() ([]byte, error) { return []byte(n), nil }
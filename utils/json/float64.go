@@ -0,0 +1,30 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import "strconv"
+
+// Float64 ...
+type Float64 float64
+
+// MarshalJSON ...
+func (f Float64) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + strconv.FormatFloat(float64(f), 'f', 4, 64) + "\""), nil
+}
+
+// UnmarshalJSON ...
+func (f *Float64) UnmarshalJSON(b []byte) error {
+	str := string(b)
+	if str == "null" {
+		return nil
+	}
+	if len(str) >= 2 {
+		if lastIndex := len(str) - 1; str[0] == '"' && str[lastIndex] == '"' {
+			str = str[1:lastIndex]
+		}
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	*f = Float64(val)
+	return err
+}
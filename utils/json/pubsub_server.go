@@ -12,6 +12,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/logging"
 )
 
 const (
@@ -35,6 +36,9 @@ const (
 
 	// Maximum number of pending messages to send to a peer.
 	maxPendingMessages = 256 // messages
+
+	// Maximum number of channels a peer may subscribe to on a dynamic server.
+	maxDynamicChannels = 1024 // channels
 )
 
 var upgrader = websocket.Upgrader{
@@ -49,7 +53,11 @@ var (
 
 // PubSubServer maintains the set of active clients and sends messages to the clients.
 type PubSubServer struct {
-	ctx *snow.Context
+	log logging.Logger
+
+	// If true, channels are created when they're first subscribed to, and
+	// removed once they have no subscribers
+	dynamic bool
 
 	lock     sync.Mutex
 	conns    map[*Connection]map[string]struct{}
@@ -58,8 +66,20 @@ type PubSubServer struct {
 
 // NewPubSubServer ...
 func NewPubSubServer(ctx *snow.Context) *PubSubServer {
+	return newPubSubServer(ctx.Log, false)
+}
+
+// NewDynamicPubSubServer returns a server whose channels don't need to be
+// registered. A channel exists while it has subscribers, and messages
+// published to a channel without subscribers are dropped.
+func NewDynamicPubSubServer(log logging.Logger) *PubSubServer {
+	return newPubSubServer(log, true)
+}
+
+func newPubSubServer(log logging.Logger, dynamic bool) *PubSubServer {
 	return &PubSubServer{
-		ctx:      ctx,
+		log:      log,
+		dynamic:  dynamic,
 		conns:    make(map[*Connection]map[string]struct{}),
 		channels: make(map[string]map[*Connection]struct{}),
 	}
@@ -68,7 +88,7 @@ func NewPubSubServer(ctx *snow.Context) *PubSubServer {
 func (s *PubSubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	wsConn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		s.ctx.Log.Debug("Failed to upgrade %s", err)
+		s.log.Debug("Failed to upgrade %s", err)
 		return
 	}
 	conn := &Connection{s: s, conn: wsConn, send: make(chan interface{}, maxPendingMessages)}
@@ -82,7 +102,9 @@ func (s *PubSubServer) Publish(channel string, msg interface{}) {
 
 	conns, exists := s.channels[channel]
 	if !exists {
-		s.ctx.Log.Warn("attempted to publush to an unknown channel %s", channel)
+		if !s.dynamic {
+			s.log.Warn("attempted to publush to an unknown channel %s", channel)
+		}
 		return
 	}
 
@@ -95,7 +117,7 @@ func (s *PubSubServer) Publish(channel string, msg interface{}) {
 		select {
 		case conn.send <- pubMsg:
 		default:
-			s.ctx.Log.Verbo("dropping message to subscribed connection due to too many pending messages")
+			s.log.Verbo("dropping message to subscribed connection due to too many pending messages")
 		}
 	}
 }
@@ -128,13 +150,14 @@ func (s *PubSubServer) removeConnection(conn *Connection) {
 
 	channels, exists := s.conns[conn]
 	if !exists {
-		s.ctx.Log.Warn("attempted to remove an unknown connection")
+		s.log.Warn("attempted to remove an unknown connection")
 		return
 	}
 
 	for channel := range channels {
-		delete(s.channels[channel], conn)
+		s.unsubscribe(conn, channel)
 	}
+	delete(s.conns, conn)
 }
 
 func (s *PubSubServer) addChannel(conn *Connection, channel string) {
@@ -148,7 +171,11 @@ func (s *PubSubServer) addChannel(conn *Connection, channel string) {
 
 	conns, exists := s.channels[channel]
 	if !exists {
-		return
+		if !s.dynamic || len(channels) >= maxDynamicChannels {
+			return
+		}
+		conns = make(map[*Connection]struct{})
+		s.channels[channel] = conns
 	}
 
 	channels[channel] = struct{}{}
@@ -164,13 +191,22 @@ func (s *PubSubServer) removeChannel(conn *Connection, channel string) {
 		return
 	}
 
+	delete(channels, channel)
+	s.unsubscribe(conn, channel)
+}
+
+// unsubscribe [conn] from [channel], and remove the channel if it's dynamic
+// and has no subscribers left. Assumes the lock is held.
+func (s *PubSubServer) unsubscribe(conn *Connection, channel string) {
 	conns, exists := s.channels[channel]
 	if !exists {
 		return
 	}
 
-	delete(channels, channel)
 	delete(conns, conn)
+	if s.dynamic && len(conns) == 0 {
+		delete(s.channels, channel)
+	}
 }
 
 type publish struct {
@@ -214,7 +250,7 @@ func (c *Connection) readPump() {
 		err := c.conn.ReadJSON(&msg)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.s.ctx.Log.Debug("Unexpected close in websockets: %s", err)
+				c.s.log.Debug("Unexpected close in websockets: %s", err)
 			}
 			break
 		}
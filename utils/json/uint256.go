@@ -0,0 +1,115 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	errUint256Overflow = errors.New("value overflows 256 bits")
+)
+
+// Uint256 is an unsigned integer of up to 256 bits. Like Uint64, it is encoded
+// as a decimal string, so amounts that overflow a uint64, such as the total
+// supply of a custom asset, can be reported without losing precision.
+//
+// A Uint256 is immutable, so it can be copied freely. The zero value is 0.
+type Uint256 struct{ i *big.Int }
+
+// NewUint256 returns [val] as a Uint256
+func NewUint256(val uint64) Uint256 { return Uint256{i: new(big.Int).SetUint64(val)} }
+
+// NewUint256FromBig returns [val] as a Uint256, or an error if it's negative or
+// overflows 256 bits
+func NewUint256FromBig(val *big.Int) (Uint256, error) {
+	if val.Sign() < 0 {
+		return Uint256{}, errors.New("negative value can't be a uint256")
+	}
+	if val.Cmp(maxUint256) > 0 {
+		return Uint256{}, errUint256Overflow
+	}
+	return Uint256{i: new(big.Int).Set(val)}, nil
+}
+
+// ParseUint256 parses the decimal string [str]
+func ParseUint256(str string) (Uint256, error) {
+	if len(str) == 0 {
+		return Uint256{}, errors.New("empty string can't be parsed as a uint256")
+	}
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return Uint256{}, fmt.Errorf("%q isn't a non-negative decimal number", str)
+		}
+	}
+	i, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Uint256{}, fmt.Errorf("%q isn't a non-negative decimal number", str)
+	}
+	if i.Cmp(maxUint256) > 0 {
+		return Uint256{}, errUint256Overflow
+	}
+	return Uint256{i: i}, nil
+}
+
+// Big returns a copy of the value
+func (u Uint256) Big() *big.Int {
+	if u.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(u.i)
+}
+
+// Add64 returns u + [val], or an error if the sum overflows 256 bits
+func (u Uint256) Add64(val uint64) (Uint256, error) {
+	sum := u.Big()
+	sum.Add(sum, new(big.Int).SetUint64(val))
+	if sum.Cmp(maxUint256) > 0 {
+		return u, errUint256Overflow
+	}
+	return Uint256{i: sum}, nil
+}
+
+// Uint64 returns the value as a uint64, and false if it doesn't fit in 64 bits
+func (u Uint256) Uint64() (uint64, bool) {
+	if u.i == nil {
+		return 0, true
+	}
+	return u.i.Uint64(), u.i.IsUint64()
+}
+
+func (u Uint256) String() string {
+	if u.i == nil {
+		return "0"
+	}
+	return u.i.String()
+}
+
+// MarshalJSON ...
+func (u Uint256) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + u.String() + "\""), nil
+}
+
+// UnmarshalJSON ...
+func (u *Uint256) UnmarshalJSON(b []byte) error {
+	str := string(b)
+	if str == "null" {
+		return nil
+	}
+	if len(str) >= 2 {
+		if lastIndex := len(str) - 1; str[0] == '"' && str[lastIndex] == '"' {
+			str = str[1:lastIndex]
+		}
+	}
+	val, err := ParseUint256(str)
+	if err != nil {
+		return err
+	}
+	*u = val
+	return nil
+}
@@ -0,0 +1,73 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package json
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestUint256(t *testing.T) {
+	u := NewUint256(math.MaxUint64)
+	u, err := u.Add64(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := u.Uint64(); ok {
+		t.Fatalf("2^64 shouldn't fit in a uint64")
+	}
+
+	b, err := json.Marshal(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"18446744073709551616"` {
+		t.Fatalf("Wrong encoding %s", b)
+	}
+
+	parsed := Uint256{}
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.String() != u.String() {
+		t.Fatalf("Expected %s, got %s", u, parsed)
+	}
+
+	// Adding to a copy doesn't change the original
+	if _, err := parsed.Add64(5); err != nil || parsed.String() != u.String() {
+		t.Fatalf("Add64 shouldn't have modified the value")
+	}
+}
+
+func TestUint256Zero(t *testing.T) {
+	u := Uint256{}
+	if b, err := json.Marshal(u); err != nil || string(b) != `"0"` {
+		t.Fatalf("Zero value should be encoded as \"0\", got %s", b)
+	}
+	if val, ok := u.Uint64(); !ok || val != 0 {
+		t.Fatalf("Zero value should be 0")
+	}
+}
+
+func TestUint256Invalid(t *testing.T) {
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	u := Uint256{}
+	for _, str := range []string{`""`, `"-1"`, `"+1"`, `"1.5"`, `"0x10"`, `"1e3"`, `"` + max[:len(max)-1] + `6"`} {
+		if err := json.Unmarshal([]byte(str), &u); err == nil {
+			t.Fatalf("Should have failed to parse %s", str)
+		}
+	}
+	if err := json.Unmarshal([]byte(`123`), &u); err != nil || u.String() != "123" {
+		t.Fatalf("Should have parsed an unquoted number")
+	}
+
+	maxVal, err := ParseUint256(max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maxVal.Add64(1); err == nil {
+		t.Fatalf("Add64 should have overflowed")
+	}
+}
@@ -15,10 +15,13 @@ const DefaultLogDirectory = "~/.gecko/logs"
 // Config ...
 type Config struct {
 	RotationInterval                                                                                time.Duration
-	FileSize, RotationSize, FlushSize                                                               int
+	FileSize, RotationSize, MaxTotalSize, FlushSize                                                 int
 	DisableLogging, DisableDisplaying, DisableContextualDisplaying, DisableFlushOnWrite, Assertions bool
+	DisableCompression                                                                              bool
 	LogLevel, DisplayLevel                                                                          Level
-	Directory, MsgPrefix                                                                            string
+	Format                                                                                          Format
+	Directory, MsgPrefix, Chain, Module                                                             string
+	Sinks                                                                                           []SinkConfig
 }
 
 // DefaultConfig ...
@@ -5,6 +5,7 @@ package logging
 
 import (
 	"path"
+	"sync"
 
 	"github.com/ava-labs/gecko/ids"
 )
@@ -14,61 +15,164 @@ type Factory interface {
 	Make() (Logger, error)
 	MakeChain(chainID ids.ID, subdir string) (Logger, error)
 	MakeSubdir(subdir string) (Logger, error)
+
+	// SetLogLevel sets the log level of the loggers named [name], including
+	// the ones made later. A logger is named by its subdirectory, such as
+	// "http", and the loggers of a chain are also named by the chain's ID.
+	SetLogLevel(name string, level Level)
+	// SetDisplayLevel sets the display level of the loggers named [name],
+	// including the ones made later.
+	SetDisplayLevel(name string, level Level)
+	// GetLevels returns the log and display levels of the loggers named
+	// [name]. If no level was set for [name], the default levels are returned.
+	GetLevels(name string) (logLevel, displayLevel Level)
+
 	Close()
 }
 
 // factory ...
 type factory struct {
+	lock   sync.Mutex
 	config Config
 
-	loggers []Logger
+	// The sinks are shared by all the loggers, so they are started with the
+	// first logger and stopped after the last one
+	sinks    []*sink
+	sinksErr error
+	started  bool
+
+	// Levels set for named loggers
+	logLevels, displayLevels map[string]Level
+
+	loggers []*Log
 }
 
 // NewFactory ...
 func NewFactory(config Config) Factory {
 	return &factory{
-		config: config,
+		config:        config,
+		logLevels:     make(map[string]Level),
+		displayLevels: make(map[string]Level),
 	}
 }
 
 // Make ...
 func (f *factory) Make() (Logger, error) {
-	l, err := New(f.config)
-	if err == nil {
-		f.loggers = append(f.loggers, l)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.make(f.config)
+}
+
+func (f *factory) make(config Config) (Logger, error) {
+	if !f.started {
+		f.started = true
+		f.sinks, f.sinksErr = newSinks(f.config.Sinks)
+	}
+	if f.sinksErr != nil {
+		return nil, f.sinksErr
 	}
-	return l, err
+
+	// The chain's levels take precedence over the module's levels
+	for _, name := range []string{config.Module, config.Chain} {
+		if name == "" {
+			continue
+		}
+		if level, ok := f.logLevels[name]; ok {
+			config.LogLevel = level
+		}
+		if level, ok := f.displayLevels[name]; ok {
+			config.DisplayLevel = level
+		}
+	}
+
+	l, err := newLog(config, f.sinks)
+	if err != nil {
+		return nil, err
+	}
+	f.loggers = append(f.loggers, l)
+	return l, nil
 }
 
 // MakeChain ...
 func (f *factory) MakeChain(chainID ids.ID, subdir string) (Logger, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	config := f.config
 	config.MsgPrefix = "SN " + chainID.String()
+	config.Chain = chainID.String()
+	config.Module = subdir
 	config.Directory = path.Join(config.Directory, "chain", chainID.String(), subdir)
 
-	log, err := New(config)
-	if err == nil {
-		f.loggers = append(f.loggers, log)
-	}
-	return log, err
+	return f.make(config)
 }
 
 // MakeSubdir ...
 func (f *factory) MakeSubdir(subdir string) (Logger, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	config := f.config
 	config.Directory = path.Join(config.Directory, subdir)
+	config.Module = subdir
 
-	log, err := New(config)
-	if err == nil {
-		f.loggers = append(f.loggers, log)
+	return f.make(config)
+}
+
+// SetLogLevel ...
+func (f *factory) SetLogLevel(name string, level Level) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.logLevels[name] = level
+	for _, l := range f.loggers {
+		if l.config.Module == name || l.config.Chain == name {
+			l.SetLogLevel(level)
+		}
+	}
+}
+
+// SetDisplayLevel ...
+func (f *factory) SetDisplayLevel(name string, level Level) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.displayLevels[name] = level
+	for _, l := range f.loggers {
+		if l.config.Module == name || l.config.Chain == name {
+			l.SetDisplayLevel(level)
+		}
 	}
-	return log, err
+}
+
+// GetLevels ...
+func (f *factory) GetLevels(name string) (Level, Level) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	logLevel, ok := f.logLevels[name]
+	if !ok {
+		logLevel = f.config.LogLevel
+	}
+	displayLevel, ok := f.displayLevels[name]
+	if !ok {
+		displayLevel = f.config.DisplayLevel
+	}
+	return logLevel, displayLevel
 }
 
 // Close ...
 func (f *factory) Close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
 	for _, log := range f.loggers {
 		log.Stop()
 	}
 	f.loggers = nil
+
+	stopSinks(f.sinks)
+	f.sinks = nil
+	f.started = false
 }
@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestFactoryNamedLevels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := NewFactory(Config{
+		RotationInterval:  time.Hour,
+		FileSize:          1 << 20,
+		RotationSize:      1,
+		FlushSize:         1,
+		LogLevel:          Info,
+		DisplayLevel:      Info,
+		DisableDisplaying: true,
+		Directory:         dir,
+	})
+	defer f.Close()
+
+	chainID := ids.NewID([32]byte{1})
+	f.SetLogLevel("network", Debug)
+	f.SetLogLevel(chainID.String(), Verbo)
+
+	main, err := f.Make()
+	if err != nil {
+		t.Fatal(err)
+	}
+	network, err := f.MakeSubdir("network")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainHTTP, err := f.MakeChain(chainID, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level := main.(*Log).config.LogLevel; level != Info {
+		t.Fatalf("Unnamed logger should have kept the default level, got %s", level)
+	}
+	if level := network.(*Log).config.LogLevel; level != Debug {
+		t.Fatalf("Expected network log level %s, got %s", Debug, level)
+	}
+	if level := chainHTTP.(*Log).config.LogLevel; level != Verbo {
+		t.Fatalf("Chain's level should have taken precedence, got %s", level)
+	}
+
+	// Changing a level applies to the existing loggers
+	f.SetDisplayLevel("network", Error)
+	if level := network.(*Log).config.DisplayLevel; level != Error {
+		t.Fatalf("Expected network display level %s, got %s", Error, level)
+	}
+	if logLevel, displayLevel := f.GetLevels("network"); logLevel != Debug || displayLevel != Error {
+		t.Fatalf("Wrong network levels %s, %s", logLevel, displayLevel)
+	}
+	if logLevel, displayLevel := f.GetLevels("http"); logLevel != Info || displayLevel != Info {
+		t.Fatalf("Unset levels should have been the defaults, got %s, %s", logLevel, displayLevel)
+	}
+}
@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Format is the way log records are written
+type Format int
+
+// Enum ...
+const (
+	// Plain records are written as human readable lines
+	Plain Format = iota
+	// JSON records are written as one JSON object per line
+	JSON
+)
+
+// ToFormat ...
+func ToFormat(f string) (Format, error) {
+	switch strings.ToLower(f) {
+	case "", "plain":
+		return Plain, nil
+	case "json":
+		return JSON, nil
+	default:
+		return Plain, fmt.Errorf("unknown log format: %s", f)
+	}
+}
+
+func (f Format) String() string {
+	switch f {
+	case Plain:
+		return "plain"
+	case JSON:
+		return "json"
+	default:
+		return "?"
+	}
+}
+
+// Field is a named value attached to a log message. When passed as an argument
+// of a log message, it is formatted as key=value in the message, and is also
+// included in the fields of JSON records.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns a field with the name [key] and the value [value]
+func F(key string, value interface{}) Field { return Field{Key: key, Value: value} }
+
+func (f Field) String() string { return fmt.Sprintf("%s=%v", f.Key, f.Value) }
+
+// record is the structure of a JSON formatted log message
+type record struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Chain     string                 `json:"chain,omitempty"`
+	Module    string                 `json:"module,omitempty"`
+	Caller    string                 `json:"caller"`
+	Trace     string                 `json:"trace,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+
+	level Level
+	time  time.Time
+}
+
+func newRecord(config *Config, level Level, loc, msg string, args []interface{}, now time.Time) *record {
+	r := &record{
+		Timestamp: now.UTC().Format(time.RFC3339Nano),
+		Level:     strings.ToLower(strings.TrimSpace(level.String())),
+		Chain:     config.Chain,
+		Module:    config.Module,
+		Caller:    loc,
+		Message:   msg,
+		level:     level,
+		time:      now,
+	}
+	for _, arg := range args {
+		field, ok := arg.(Field)
+		if !ok {
+			continue
+		}
+		if r.Fields == nil {
+			r.Fields = make(map[string]interface{})
+		}
+		r.Fields[field.Key] = jsonValue(field.Value)
+	}
+	return r
+}
+
+// setTrace tags the record with the operation [trace], unless it is 0
+func (r *record) setTrace(trace uint64) {
+	if trace != 0 {
+		r.Trace = FormatTrace(trace)
+	}
+}
+
+// marshal returns the JSON encoding of the record
+func (r *record) marshal() []byte {
+	b, err := json.Marshal(r)
+	if err == nil {
+		return b
+	}
+	// Fields that can't be marshalled are replaced by their string form. The
+	// record may be shared with sinks, so it isn't modified.
+	safe := *r
+	safe.Fields = make(map[string]interface{}, len(r.Fields))
+	for key, value := range r.Fields {
+		safe.Fields[key] = fmt.Sprint(value)
+	}
+	if b, err = json.Marshal(&safe); err != nil {
+		return []byte(fmt.Sprintf("{\"level\":\"error\",\"message\":%q}", err))
+	}
+	return b
+}
+
+func formatJSON(r *record) string { return string(r.marshal()) + "\n" }
+
+// jsonValue returns the value to marshal for [value]. Errors and values with a
+// String method are written as strings, rather than as their internal structure.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,114 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatJSON(t *testing.T) {
+	config := &Config{
+		Format: JSON,
+		Chain:  "chain",
+		Module: "http",
+	}
+	now := time.Date(2020, time.March, 1, 12, 30, 0, 0, time.UTC)
+	args := []interface{}{F("height", 5), F("err", errors.New("oops")), "extra"}
+	output := formatJSON(newRecord(config, Warn, "snow/engine.go#10", "accepted", args, now))
+
+	if !strings.HasSuffix(output, "\n") || strings.Count(output, "\n") != 1 {
+		t.Fatalf("Record should be written on exactly one line: %q", output)
+	}
+
+	r := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(output), &r); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"timestamp": "2020-03-01T12:30:00Z",
+		"level":     "warn",
+		"chain":     "chain",
+		"module":    "http",
+		"caller":    "snow/engine.go#10",
+		"message":   "accepted",
+	}
+	for key, value := range expected {
+		if r[key] != value {
+			t.Fatalf("Expected %s to be %v, got %v", key, value, r[key])
+		}
+	}
+
+	fields, ok := r["fields"].(map[string]interface{})
+	switch {
+	case !ok:
+		t.Fatalf("Record should have had fields")
+	case len(fields) != 2:
+		t.Fatalf("Expected 2 fields, got %d", len(fields))
+	case fields["height"] != float64(5):
+		t.Fatalf("Expected height 5, got %v", fields["height"])
+	case fields["err"] != "oops":
+		t.Fatalf("Expected err oops, got %v", fields["err"])
+	}
+}
+
+func TestFormatJSONUnmarshallableField(t *testing.T) {
+	output := formatJSON(newRecord(&Config{}, Info, "?", "msg", []interface{}{F("ch", make(chan int))}, time.Now()))
+
+	r := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(output), &r); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r["chain"]; ok {
+		t.Fatalf("Empty chain shouldn't have been written")
+	}
+	if fields, ok := r["fields"].(map[string]interface{}); !ok || fields["ch"] == nil {
+		t.Fatalf("Unmarshallable field should have been written as a string")
+	}
+}
+
+func TestLogPlainField(t *testing.T) {
+	l := &Log{config: Config{Format: Plain}}
+	args := []interface{}{F("height", 5)}
+	output := l.format(Info, "?", fmt.Sprintf("accepted %s", args...), args, time.Now(), 0)
+	if !strings.Contains(output, "accepted height=5") {
+		t.Fatalf("Plain record should have included the field as key=value: %q", output)
+	}
+}
+
+func TestToFormat(t *testing.T) {
+	for str, expected := range map[string]Format{"": Plain, "plain": Plain, "JSON": JSON} {
+		if format, err := ToFormat(str); err != nil || format != expected {
+			t.Fatalf("Expected %s to parse as %s", str, expected)
+		}
+	}
+	if _, err := ToFormat("xml"); err == nil {
+		t.Fatalf("Should have errored on an unknown format")
+	}
+}
+
+func TestLogTrace(t *testing.T) {
+	l := &Log{config: Config{Format: Plain}}
+
+	if output := l.format(Info, "?", "msg", nil, time.Now(), 0); strings.Contains(output, "trace") {
+		t.Fatalf("Untraced record shouldn't have included a trace: %q", output)
+	}
+	if output := l.format(Info, "?", "msg", nil, time.Now(), 0xab); !strings.Contains(output, "{trace 00000000000000ab}") {
+		t.Fatalf("Plain record should have included the trace: %q", output)
+	}
+
+	l.config.Format = JSON
+	output := l.format(Info, "?", "msg", nil, time.Now(), 0xab)
+	r := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(output), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r["trace"] != "00000000000000ab" {
+		t.Fatalf("JSON record should have included the trace: %q", output)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"path"
 	"runtime"
 	"strings"
 	"sync"
@@ -26,45 +25,66 @@ type Log struct {
 	needsFlush                       *sync.Cond
 	w                                *bufio.Writer
 
+	// sinks that records are sent to. If [ownsSinks], they are stopped when
+	// the log is stopped.
+	sinks     []*sink
+	ownsSinks bool
+
+	// tracer returns the ID of the operation that is currently traced, or 0
+	tracer func() uint64
+
 	closed bool
 }
 
 // New ...
 func New(config Config) (*Log, error) {
+	sinks, err := newSinks(config.Sinks)
+	if err != nil {
+		return nil, err
+	}
+	l, err := newLog(config, sinks)
+	if err != nil {
+		stopSinks(sinks)
+		return nil, err
+	}
+	l.ownsSinks = true
+	return l, nil
+}
+
+func newLog(config Config, sinks []*sink) (*Log, error) {
 	if err := os.MkdirAll(config.Directory, os.ModePerm); err != nil {
 		return nil, err
 	}
-	l := &Log{config: config}
+	l := &Log{
+		config: config,
+		sinks:  sinks,
+	}
 	l.needsFlush = sync.NewCond(&l.flushLock)
 
 	l.wg.Add(1)
 
-	go l.RecoverAndPanic(l.run)
+	go l.RecoverAndPanic(func() { l.run(config) })
 
 	return l, nil
 }
 
-func (l *Log) run() {
+func (l *Log) run(config Config) {
 	defer l.wg.Done()
 
 	l.writeLock.Lock()
 	defer l.writeLock.Unlock()
 
-	fileIndex := 0
-	filename := path.Join(l.config.Directory, fmt.Sprintf("%d.log", fileIndex))
-	f, err := os.Create(filename)
+	r, err := newRotator(config)
 	if err != nil {
 		panic(err)
 	}
-	l.w = bufio.NewWriter(f)
+	l.w = bufio.NewWriter(r)
 
 	closed := false
-	nextRotation := time.Now().Add(l.config.RotationInterval)
-	currentSize := 0
 	for !closed {
 		l.writeLock.Unlock()
 		l.flushLock.Lock()
-		for l.size < l.config.FlushSize && !l.closed {
+		for l.size < config.FlushSize && !l.closed {
 			l.needsFlush.Wait()
 		}
 		closed = l.closed
@@ -75,31 +95,22 @@ func (l *Log) run() {
 		l.writeLock.Lock()
 
 		for _, msg := range prevMessages {
-			n, _ := l.w.WriteString(msg)
-			currentSize += n
+			l.w.WriteString(msg)
 		}
 
-		if !l.config.DisableFlushOnWrite {
+		if !config.DisableFlushOnWrite {
 			l.w.Flush()
 		}
 
-		if now := time.Now(); nextRotation.Before(now) || currentSize > l.config.FileSize {
-			nextRotation = now.Add(l.config.RotationInterval)
-			currentSize = 0
+		if now := time.Now(); r.shouldRotate(now) {
 			l.w.Flush()
-			f.Close()
-
-			fileIndex = (fileIndex + 1) % l.config.RotationSize
-			filename := path.Join(l.config.Directory, fmt.Sprintf("%d.log", fileIndex))
-			f, err = os.Create(filename)
-			if err != nil {
+			if err := r.rotate(now); err != nil {
 				panic(err)
 			}
-			l.w = bufio.NewWriter(f)
 		}
 	}
 	l.w.Flush()
-	f.Close()
+	r.Close()
 }
 
 func (l *Log) Write(p []byte) (int, error) {
@@ -117,6 +128,10 @@ func (l *Log) Stop() {
 	l.flushLock.Unlock()
 
 	l.wg.Wait()
+
+	if l.ownsSinks {
+		stopSinks(l.sinks)
+	}
 }
 
 // Should only be called from [Level] functions.
@@ -131,11 +146,44 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 	shouldLog := !l.config.DisableLogging && level <= l.config.LogLevel
 	shouldDisplay := (!l.config.DisableDisplaying && level <= l.config.DisplayLevel) || level == Fatal
 
+	shouldSend := false
+	for _, s := range l.sinks {
+		shouldSend = shouldSend || s.accepts(level)
+	}
+
+	if !shouldLog && !shouldDisplay && !shouldSend {
+		return
+	}
+
+	loc := "?"
+	if _, file, no, ok := runtime.Caller(2); ok {
+		loc = fmt.Sprintf("%s#%d", file, no)
+	}
+	if i := strings.Index(loc, "gecko/"); i != -1 {
+		loc = loc[i+5:]
+	}
+	msg := fmt.Sprintf(format, args...)
+	now := time.Now()
+	trace := uint64(0)
+	if l.tracer != nil {
+		trace = l.tracer()
+	}
+
+	if shouldSend {
+		r := newRecord(&l.config, level, loc, msg, args, now)
+		r.setTrace(trace)
+		for _, s := range l.sinks {
+			if s.accepts(level) {
+				s.enqueue(r)
+			}
+		}
+	}
+
 	if !shouldLog && !shouldDisplay {
 		return
 	}
 
-	output := l.format(level, format, args...)
+	output := l.format(level, loc, msg, args, now, trace)
 
 	if shouldLog {
 		l.flushLock.Lock()
@@ -147,31 +195,34 @@ func (l *Log) log(level Level, format string, args ...interface{}) {
 
 	if shouldDisplay {
 		if l.config.DisableContextualDisplaying {
-			fmt.Println(fmt.Sprintf(format, args...))
+			fmt.Println(msg)
+		} else if l.config.Format == JSON {
+			fmt.Print(output)
 		} else {
 			fmt.Print(level.Color().Wrap(output))
 		}
 	}
 }
 
-func (l *Log) format(level Level, format string, args ...interface{}) string {
-	loc := "?"
-	if _, file, no, ok := runtime.Caller(3); ok {
-		loc = fmt.Sprintf("%s#%d", file, no)
+func (l *Log) format(level Level, loc, msg string, args []interface{}, now time.Time, trace uint64) string {
+	if l.config.Format == JSON {
+		r := newRecord(&l.config, level, loc, msg, args, now)
+		r.setTrace(trace)
+		return formatJSON(r)
 	}
-	if i := strings.Index(loc, "gecko/"); i != -1 {
-		loc = loc[i+5:]
-	}
-	text := fmt.Sprintf("%s: %s", loc, fmt.Sprintf(format, args...))
+	text := fmt.Sprintf("%s: %s", loc, msg)
 
 	prefix := ""
 	if l.config.MsgPrefix != "" {
 		prefix = fmt.Sprintf(" <%s>", l.config.MsgPrefix)
 	}
+	if trace != 0 {
+		prefix += fmt.Sprintf(" {trace %s}", FormatTrace(trace))
+	}
 
 	return fmt.Sprintf("%s[%s]%s %s\n",
 		level,
-		time.Now().Format("01-02|15:04:05"),
+		now.Format("01-02|15:04:05"),
 		prefix,
 		text)
 }
@@ -277,6 +328,14 @@ func (l *Log) SetPrefix(prefix string) {
 	l.config.MsgPrefix = prefix
 }
 
+// SetTracer implements the Tracer interface
+func (l *Log) SetTracer(tracer func() uint64) {
+	l.configLock.Lock()
+	defer l.configLock.Unlock()
+
+	l.tracer = tracer
+}
+
 // SetLoggingEnabled ...
 func (l *Log) SetLoggingEnabled(enabled bool) {
 	l.configLock.Lock()
@@ -0,0 +1,174 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// currentLogFile is the name of the file that is being written to
+	currentLogFile = "current.log"
+
+	// Rotated files are named by the time they were rotated, so sorting them by
+	// name sorts them from oldest to newest
+	rotatedLogPrefix     = "log-"
+	rotatedLogTimeFormat = "20060102T150405.000000000"
+	logExtension         = ".log"
+	compressedExtension  = ".gz"
+)
+
+// rotator writes to the current log file of a directory. When the current file
+// gets too large or too old, it is moved aside, compressed, and the oldest
+// rotated files are removed until the retention limits are met.
+type rotator struct {
+	config Config
+
+	f       *os.File
+	size    int
+	created time.Time
+}
+
+func newRotator(config Config) (*rotator, error) {
+	r := &rotator{config: config}
+	// A current file left by a previous run is rotated rather than truncated
+	if _, err := os.Stat(r.currentPath()); err == nil {
+		if err := r.archive(time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return r, r.open(time.Now())
+}
+
+func (r *rotator) Write(p []byte) (int, error) {
+	n, err := r.f.Write(p)
+	r.size += n
+	return n, err
+}
+
+// shouldRotate returns true if the current file has exceeded its size or age
+func (r *rotator) shouldRotate(now time.Time) bool {
+	return r.size > r.config.FileSize || !now.Before(r.created.Add(r.config.RotationInterval))
+}
+
+// rotate moves aside the current file and starts a new one
+func (r *rotator) rotate(now time.Time) error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if err := r.archive(now); err != nil {
+		return err
+	}
+	return r.open(now)
+}
+
+func (r *rotator) Close() error { return r.f.Close() }
+
+func (r *rotator) open(now time.Time) error {
+	f, err := os.Create(r.currentPath())
+	if err != nil {
+		return err
+	}
+	r.f = f
+	r.size = 0
+	r.created = now
+	return nil
+}
+
+// archive renames the current file, compresses it if enabled, and removes the
+// rotated files that exceed the retention limits
+func (r *rotator) archive(now time.Time) error {
+	name := r.rotatedName(now)
+	if err := os.Rename(r.currentPath(), path.Join(r.config.Directory, name)); err != nil {
+		return err
+	}
+	if !r.config.DisableCompression {
+		if err := compressFile(path.Join(r.config.Directory, name)); err != nil {
+			return err
+		}
+	}
+	return r.prune()
+}
+
+// rotatedName returns an unused name for a file rotated at [now]
+func (r *rotator) rotatedName(now time.Time) string {
+	for {
+		name := rotatedLogPrefix + now.UTC().Format(rotatedLogTimeFormat) + logExtension
+		_, err := os.Stat(path.Join(r.config.Directory, name))
+		_, errCompressed := os.Stat(path.Join(r.config.Directory, name+compressedExtension))
+		if os.IsNotExist(err) && os.IsNotExist(errCompressed) {
+			return name
+		}
+		now = now.Add(time.Nanosecond)
+	}
+}
+
+// prune removes the oldest rotated files until at most [RotationSize] files
+// remain and, if [MaxTotalSize] is positive, they use at most [MaxTotalSize]
+// bytes
+func (r *rotator) prune() error {
+	files, err := ioutil.ReadDir(r.config.Directory)
+	if err != nil {
+		return err
+	}
+	rotated := []os.FileInfo(nil)
+	totalSize := int64(0)
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), rotatedLogPrefix) {
+			continue
+		}
+		rotated = append(rotated, file)
+		totalSize += file.Size()
+	}
+	sort.Slice(rotated, func(i, j int) bool { return rotated[i].Name() < rotated[j].Name() })
+
+	maxTotalSize := int64(r.config.MaxTotalSize)
+	for len(rotated) > 0 && (len(rotated) > r.config.RotationSize || (maxTotalSize > 0 && totalSize > maxTotalSize)) {
+		if err := os.Remove(path.Join(r.config.Directory, rotated[0].Name())); err != nil {
+			return err
+		}
+		totalSize -= rotated[0].Size()
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+func (r *rotator) currentPath() string { return path.Join(r.config.Directory, currentLogFile) }
+
+// compressFile replaces the file at [filename] with its gzipped version
+func compressFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filename + compressedExtension)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(dst)
+	if _, err := io.Copy(w, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := w.Close(); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	return os.Remove(filename)
+}
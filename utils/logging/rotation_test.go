@@ -0,0 +1,185 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func rotatedFiles(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string(nil)
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), rotatedLogPrefix) {
+			names = append(names, file.Name())
+		}
+	}
+	return names
+}
+
+func TestRotatorRotatesBySize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newRotator(Config{
+		Directory:        dir,
+		FileSize:         4,
+		RotationSize:     2,
+		RotationInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	now := time.Now()
+	for i, msg := range []string{"first", "second", "third"} {
+		if r.shouldRotate(now) {
+			t.Fatalf("Shouldn't have rotated before writing %s", msg)
+		}
+		if _, err := r.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		if !r.shouldRotate(now) {
+			t.Fatalf("Should have rotated after writing %s", msg)
+		}
+		if err := r.rotate(now.Add(time.Duration(i) * time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := rotatedFiles(t, dir)
+	if len(names) != 2 {
+		t.Fatalf("Expected 2 rotated files, got %v", names)
+	}
+	for i, expected := range []string{"second", "third"} {
+		if !strings.HasSuffix(names[i], logExtension+compressedExtension) {
+			t.Fatalf("Rotated file %s should have been compressed", names[i])
+		}
+		f, err := os.Open(path.Join(dir, names[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(gr)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(contents) != expected {
+			t.Fatalf("Expected %s, got %s", expected, contents)
+		}
+	}
+}
+
+func TestRotatorRotatesByAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newRotator(Config{
+		Directory:        dir,
+		FileSize:         1 << 20,
+		RotationSize:     1,
+		RotationInterval: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if r.shouldRotate(r.created.Add(time.Second)) {
+		t.Fatalf("Shouldn't have rotated a new file")
+	}
+	if !r.shouldRotate(r.created.Add(time.Minute)) {
+		t.Fatalf("Should have rotated an old file")
+	}
+}
+
+func TestRotatorMaxTotalSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r, err := newRotator(Config{
+		Directory:          dir,
+		FileSize:           1,
+		RotationSize:       10,
+		MaxTotalSize:       25,
+		RotationInterval:   time.Hour,
+		DisableCompression: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := r.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.rotate(now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if names := rotatedFiles(t, dir); len(names) != 2 {
+		t.Fatalf("Expected 2 rotated files, got %v", names)
+	}
+}
+
+func TestRotatorKeepsPreviousRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(path.Join(dir, currentLogFile), []byte("previous run"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newRotator(Config{
+		Directory:          dir,
+		FileSize:           1 << 20,
+		RotationSize:       1,
+		RotationInterval:   time.Hour,
+		DisableCompression: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	names := rotatedFiles(t, dir)
+	if len(names) != 1 {
+		t.Fatalf("Expected the previous log to have been rotated, got %v", names)
+	}
+	contents, err := ioutil.ReadFile(path.Join(dir, names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "previous run" {
+		t.Fatalf("Expected the previous log to have been kept, got %s", contents)
+	}
+}
@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/retry"
+)
+
+// Types of remote sinks
+const (
+	SyslogSink = "syslog"
+	LokiSink   = "loki"
+	HTTPSink   = "http"
+)
+
+const (
+	// Number of records a sink holds while they are waiting to be sent. When
+	// the buffer is full, new records are dropped.
+	sinkBufferSize = 4096
+
+	// Maximum number of records sent in one request
+	sinkBatchSize = 256
+
+	// Maximum time a record waits before its batch is sent
+	sinkFlushInterval = time.Second
+
+	sinkTimeout = 10 * time.Second
+)
+
+// sinkRetry describes how a batch that failed to be sent is retried. The batch
+// is dropped after it failed to be sent MaxAttempts times.
+var sinkRetry = retry.Config{
+	MaxAttempts:  5,
+	InitialDelay: 250 * time.Millisecond,
+	Jitter:       .2,
+}
+
+var errUnknownSinkType = errors.New("unknown log sink type")
+
+// SinkConfig describes a remote destination of log records
+type SinkConfig struct {
+	// Type is one of SyslogSink, LokiSink or HTTPSink
+	Type string
+	// URL is the address records are sent to
+	URL string
+	// Only records at least as severe as Level are sent
+	Level Level
+}
+
+// ToSinkConfig parses a sink described as <level>:<type>:<url>, for example
+// warn:loki:http://127.0.0.1:3100
+func ToSinkConfig(s string) (SinkConfig, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 {
+		return SinkConfig{}, fmt.Errorf("log sink %q should be formatted as <level>:<type>:<url>", s)
+	}
+	level, err := ToLevel(parts[0])
+	if err != nil {
+		return SinkConfig{}, err
+	}
+	config := SinkConfig{
+		Type:  strings.ToLower(parts[1]),
+		URL:   parts[2],
+		Level: level,
+	}
+	_, err = newSender(config)
+	return config, err
+}
+
+// sender delivers batches of records to a remote destination
+type sender interface {
+	send(records []*record) error
+	close()
+}
+
+func newSender(config SinkConfig) (sender, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Type {
+	case SyslogSink:
+		return newSyslogSender(u)
+	case LokiSink:
+		return newLokiSender(u)
+	case HTTPSink:
+		return newHTTPSender(u)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownSinkType, config.Type)
+	}
+}
+
+// sink buffers records and sends them in batches from its own goroutine, so
+// that a slow or unreachable destination never blocks the logger
+type sink struct {
+	config SinkConfig
+	sender sender
+
+	lock    sync.RWMutex
+	closed  bool
+	records chan *record
+	wg      sync.WaitGroup
+
+	dropped uint64 // atomic
+}
+
+func newSinks(configs []SinkConfig) ([]*sink, error) {
+	sinks := make([]*sink, 0, len(configs))
+	for _, config := range configs {
+		s, err := newSink(config)
+		if err != nil {
+			stopSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func stopSinks(sinks []*sink) {
+	for _, s := range sinks {
+		s.stop()
+	}
+}
+
+func newSink(config SinkConfig) (*sink, error) {
+	sender, err := newSender(config)
+	if err != nil {
+		return nil, err
+	}
+	s := &sink{
+		config:  config,
+		sender:  sender,
+		records: make(chan *record, sinkBufferSize),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+func (s *sink) accepts(level Level) bool { return level <= s.config.Level }
+
+// enqueue adds [r] to the records to send, or drops it if the buffer is full
+func (s *sink) enqueue(r *record) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.records <- r:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+func (s *sink) run() {
+	defer s.wg.Done()
+	defer s.sender.close()
+
+	ticker := time.NewTicker(sinkFlushInterval)
+	defer ticker.Stop()
+
+	batch := []*record(nil)
+	for {
+		select {
+		case r, ok := <-s.records:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) < sinkBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.flush(batch)
+		batch = nil
+	}
+}
+
+// flush sends [batch], retrying with an exponential backoff
+func (s *sink) flush(batch []*record) {
+	if dropped := atomic.SwapUint64(&s.dropped, 0); dropped > 0 {
+		// Let the destination know that records are missing
+		batch = append(batch, &record{
+			Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+			Level:     "warn",
+			Caller:    "?",
+			Message:   fmt.Sprintf("dropped %d log records because the sink's buffer was full", dropped),
+			level:     Warn,
+			time:      time.Now(),
+		})
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	_ = retry.Do(context.Background(), sinkRetry, func() error { return s.sender.send(batch) })
+}
+
+// stop sends the buffered records and stops the sink
+func (s *sink) stop() {
+	s.lock.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.records)
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+}
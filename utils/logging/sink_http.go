@@ -0,0 +1,145 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+// httpSender posts batches of records to an HTTP endpoint
+type httpSender struct {
+	url    string
+	client http.Client
+
+	// encode returns the request body of a batch of records
+	encode func([]*record) ([]byte, error)
+}
+
+// newHTTPSender returns a sender that posts batches as a JSON array of the
+// records, formatted the same way as the JSON log format
+func newHTTPSender(u *url.URL) (*httpSender, error) {
+	if err := checkHTTPURL(u); err != nil {
+		return nil, err
+	}
+	return &httpSender{
+		url:    u.String(),
+		client: http.Client{Timeout: sinkTimeout},
+		encode: encodeRecords,
+	}, nil
+}
+
+// newLokiSender returns a sender that pushes batches to Loki. If the URL has no
+// path, the default push path is used.
+func newLokiSender(u *url.URL) (*httpSender, error) {
+	if err := checkHTTPURL(u); err != nil {
+		return nil, err
+	}
+	pushURL := *u
+	if pushURL.Path == "" || pushURL.Path == "/" {
+		pushURL.Path = lokiPushPath
+	}
+	return &httpSender{
+		url:    pushURL.String(),
+		client: http.Client{Timeout: sinkTimeout},
+		encode: encodeLokiStreams,
+	}, nil
+}
+
+func checkHTTPURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("log sink URL should start with http:// or https://, got %s", u)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("log sink URL %s has no host", u)
+	}
+	return nil
+}
+
+func (s *httpSender) send(records []*record) error {
+	body, err := s.encode(records)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("log sink %s responded with %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *httpSender) close() { s.client.CloseIdleConnections() }
+
+func encodeRecords(records []*record) ([]byte, error) {
+	msgs := make([]json.RawMessage, len(records))
+	for i, r := range records {
+		msgs[i] = r.marshal()
+	}
+	return json.Marshal(msgs)
+}
+
+// lokiStream is a set of log lines that share the same labels
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encodeLokiStreams groups the records into streams labeled by their level,
+// chain and module. Each line is the JSON formatted record.
+func encodeLokiStreams(records []*record) ([]byte, error) {
+	// Loki requires the lines of a stream to be in order
+	sorted := make([]*record, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].time.Before(sorted[j].time) })
+
+	streams := map[[3]string]*lokiStream{}
+	keys := [][3]string(nil)
+	for _, r := range sorted {
+		key := [3]string{r.Level, r.Chain, r.Module}
+		stream, exists := streams[key]
+		if !exists {
+			labels := map[string]string{
+				"job":   syslogAppName,
+				"level": r.Level,
+			}
+			if r.Chain != "" {
+				labels["chain"] = r.Chain
+			}
+			if r.Module != "" {
+				labels["module"] = r.Module
+			}
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			keys = append(keys, key)
+		}
+		stream.Values = append(stream.Values, [2]string{
+			strconv.FormatInt(r.time.UnixNano(), 10),
+			string(r.marshal()),
+		})
+	}
+
+	push := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range keys {
+		push.Streams = append(push.Streams, streams[key])
+	}
+	return json.Marshal(push)
+}
@@ -0,0 +1,113 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	syslogAppName = "gecko"
+
+	// Records are sent with the local0 facility
+	syslogFacility = 16
+)
+
+// syslogSender sends records as RFC 5424 messages over UDP or TCP. Over TCP,
+// messages are separated by newlines.
+type syslogSender struct {
+	network, address, hostname string
+	pid                        int
+
+	conn net.Conn
+}
+
+func newSyslogSender(u *url.URL) (*syslogSender, error) {
+	if u.Scheme != "udp" && u.Scheme != "tcp" {
+		return nil, fmt.Errorf("syslog sink URL should start with udp:// or tcp://, got %s", u)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("syslog sink URL %s has no host", u)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	return &syslogSender{
+		network:  u.Scheme,
+		address:  u.Host,
+		hostname: hostname,
+		pid:      os.Getpid(),
+	}, nil
+}
+
+func (s *syslogSender) send(records []*record) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, sinkTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	for len(records) > 0 {
+		if err := s.conn.SetWriteDeadline(time.Now().Add(sinkTimeout)); err != nil {
+			return err
+		}
+		if _, err := s.conn.Write(s.format(records[0])); err != nil {
+			// Records that were already written aren't sent again
+			s.close()
+			return err
+		}
+		records = records[1:]
+	}
+	return nil
+}
+
+func (s *syslogSender) format(r *record) []byte {
+	module := r.Module
+	if module == "" {
+		module = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s: %s",
+		syslogFacility*8+syslogSeverity(r.level),
+		r.time.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		syslogAppName,
+		s.pid,
+		module,
+		r.Caller,
+		r.Message,
+	)
+	if s.network == "tcp" {
+		msg += "\n"
+	}
+	return []byte(msg)
+}
+
+func (s *syslogSender) close() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// syslogSeverity returns the RFC 5424 severity of [level]
+func syslogSeverity(level Level) int {
+	switch level {
+	case Fatal:
+		return 2 // Critical
+	case Error:
+		return 3 // Error
+	case Warn:
+		return 4 // Warning
+	case Info:
+		return 6 // Informational
+	default:
+		return 7 // Debug
+	}
+}
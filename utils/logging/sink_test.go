@@ -0,0 +1,189 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestToSinkConfig(t *testing.T) {
+	config, err := ToSinkConfig("warn:loki:http://127.0.0.1:3100")
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case config.Type != LokiSink:
+		t.Fatalf("Expected type %s, got %s", LokiSink, config.Type)
+	case config.URL != "http://127.0.0.1:3100":
+		t.Fatalf("Wrong URL %s", config.URL)
+	case config.Level != Warn:
+		t.Fatalf("Expected level %s, got %s", Warn, config.Level)
+	}
+
+	invalid := []string{
+		"warn:loki",                    // Missing URL
+		"loud:loki:http://127.0.0.1",   // Unknown level
+		"warn:kafka:http://127.0.0.1",  // Unknown type
+		"warn:syslog:http://127.0.0.1", // Wrong scheme
+		"warn:http:127.0.0.1",          // Missing scheme
+	}
+	for _, s := range invalid {
+		if _, err := ToSinkConfig(s); err == nil {
+			t.Fatalf("Should have failed to parse %s", s)
+		}
+	}
+}
+
+type testServer struct {
+	lock     sync.Mutex
+	failures int
+	bodies   [][]byte
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	s.bodies = append(s.bodies, body)
+}
+
+func newTestLog(t *testing.T, sinks ...SinkConfig) (*Log, func()) {
+	dir, err := ioutil.TempDir("", "gecko-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := New(Config{
+		RotationInterval:  time.Hour,
+		FileSize:          1 << 20,
+		RotationSize:      1,
+		FlushSize:         1,
+		LogLevel:          Verbo,
+		DisableDisplaying: true,
+		Directory:         dir,
+		Module:            "test",
+		Sinks:             sinks,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, func() { os.RemoveAll(dir) }
+}
+
+func TestHTTPSinkRetries(t *testing.T) {
+	server := &testServer{failures: 2}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	l, cleanup := newTestLog(t, SinkConfig{Type: HTTPSink, URL: httpServer.URL, Level: Warn})
+	defer cleanup()
+
+	l.Error("first %s", F("height", 5))
+	l.Info("ignored")
+	l.Warn("second")
+	l.Stop()
+
+	records := []map[string]interface{}(nil)
+	for _, body := range server.bodies {
+		batch := []map[string]interface{}(nil)
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, batch...)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(records))
+	}
+	if records[0]["message"] != "first height=5" || records[0]["level"] != "error" || records[0]["module"] != "test" {
+		t.Fatalf("Wrong first record: %v", records[0])
+	}
+	if records[1]["message"] != "second" {
+		t.Fatalf("Wrong second record: %v", records[1])
+	}
+}
+
+func TestLokiSink(t *testing.T) {
+	server := &testServer{}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	l, cleanup := newTestLog(t, SinkConfig{Type: LokiSink, URL: httpServer.URL, Level: Info})
+	defer cleanup()
+
+	l.Info("first")
+	l.Error("second")
+	l.Info("third")
+	l.Stop()
+
+	// The records may have been pushed in more than one batch
+	streams := map[string]*lokiStream{}
+	for _, body := range server.bodies {
+		push := struct {
+			Streams []lokiStream `json:"streams"`
+		}{}
+		if err := json.Unmarshal(body, &push); err != nil {
+			t.Fatal(err)
+		}
+		for _, stream := range push.Streams {
+			stream := stream
+			if existing, ok := streams[stream.Stream["level"]]; ok {
+				existing.Values = append(existing.Values, stream.Values...)
+			} else {
+				streams[stream.Stream["level"]] = &stream
+			}
+		}
+	}
+	if len(streams) != 2 {
+		t.Fatalf("Expected a stream per level, got %d", len(streams))
+	}
+	info := streams["info"]
+	if info == nil || info.Stream["module"] != "test" || info.Stream["job"] != "gecko" || len(info.Values) != 2 {
+		t.Fatalf("Wrong info stream: %v", info)
+	}
+	if !strings.Contains(info.Values[0][1], `"message":"first"`) || !strings.Contains(info.Values[1][1], `"message":"third"`) {
+		t.Fatalf("Wrong info lines: %v", info.Values)
+	}
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l, cleanup := newTestLog(t, SinkConfig{Type: SyslogSink, URL: "udp://" + conn.LocalAddr().String(), Level: Error})
+	defer cleanup()
+
+	l.Error("broken")
+	l.Stop()
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// local0 facility with the error severity
+	if !strings.HasPrefix(msg, "<131>1 ") || !strings.HasSuffix(msg, ": broken") {
+		t.Fatalf("Wrong syslog message: %s", msg)
+	}
+}
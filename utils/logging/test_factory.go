@@ -19,5 +19,14 @@ func (NoFactory) MakeChain(ids.ID, string) (Logger, error) { return NoLog{}, nil
 // MakeSubdir ...
 func (NoFactory) MakeSubdir(string) (Logger, error) { return NoLog{}, nil }
 
+// SetLogLevel ...
+func (NoFactory) SetLogLevel(string, Level) {}
+
+// SetDisplayLevel ...
+func (NoFactory) SetDisplayLevel(string, Level) {}
+
+// GetLevels ...
+func (NoFactory) GetLevels(string) (Level, Level) { return Off, Off }
+
 // Close ...
 func (NoFactory) Close() {}
@@ -0,0 +1,20 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package logging
+
+import (
+	"fmt"
+)
+
+// Tracer is implemented by loggers that can tag their messages with the ID of
+// the operation that is being traced, so that all the messages logged while
+// handling one operation can be found together
+type Tracer interface {
+	// SetTracer sets the function that returns the ID of the operation that is
+	// currently traced. If it returns 0, messages aren't tagged.
+	SetTracer(func() uint64)
+}
+
+// FormatTrace returns the string form of the trace ID [trace]
+func FormatTrace(trace uint64) string { return fmt.Sprintf("%016x", trace) }
@@ -0,0 +1,72 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package math
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+)
+
+var (
+	errDivideByZero = errors.New("division by zero")
+	errNegative     = errors.New("negative value")
+)
+
+// Sum64 returns the sum of [values], or an error if the sum overflows
+func Sum64(values ...uint64) (uint64, error) {
+	sum := uint64(0)
+	for _, value := range values {
+		newSum, err := Add64(sum, value)
+		if err != nil {
+			return 0, err
+		}
+		sum = newSum
+	}
+	return sum, nil
+}
+
+// MulDiv64 returns a * b / c, rounded down. The product is computed with 128
+// bits, so it only errors if the quotient doesn't fit in 64 bits or if c is 0.
+func MulDiv64(a, b, c uint64) (uint64, error) {
+	if c == 0 {
+		return 0, errDivideByZero
+	}
+	hi, lo := bits.Mul64(a, b)
+	if hi >= c {
+		return 0, errOverflow
+	}
+	quo, _ := bits.Div64(hi, lo, c)
+	return quo, nil
+}
+
+// BigSum64 returns the sum of [values]. The sum never overflows, so it can be
+// used to total amounts, such as the supply of a custom asset, that may exceed
+// 64 bits.
+func BigSum64(values ...uint64) *big.Int {
+	sum := new(big.Int)
+	addend := new(big.Int)
+	for _, value := range values {
+		sum.Add(sum, addend.SetUint64(value))
+	}
+	return sum
+}
+
+// BigAdd64 adds [value] to [sum] and returns [sum]
+func BigAdd64(sum *big.Int, value uint64) *big.Int {
+	return sum.Add(sum, new(big.Int).SetUint64(value))
+}
+
+// BigToUint64 returns [value] as a uint64, or an error if it doesn't fit in 64
+// bits
+func BigToUint64(value *big.Int) (uint64, error) {
+	switch {
+	case value.Sign() < 0:
+		return 0, errNegative
+	case !value.IsUint64():
+		return 0, errOverflow
+	default:
+		return value.Uint64(), nil
+	}
+}
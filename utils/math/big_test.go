@@ -0,0 +1,62 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package math
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSum64(t *testing.T) {
+	if sum, err := Sum64(); err != nil || sum != 0 {
+		t.Fatalf("Expected 0, got %d: %v", sum, err)
+	}
+	if sum, err := Sum64(1, 2, maxUint64-3); err != nil || sum != maxUint64 {
+		t.Fatalf("Expected %d, got %d: %v", maxUint64, sum, err)
+	}
+	if _, err := Sum64(1, 2, maxUint64-2); err == nil {
+		t.Fatalf("Sum64 should have overflowed")
+	}
+}
+
+func TestMulDiv64(t *testing.T) {
+	if quo, err := MulDiv64(10, 3, 4); err != nil || quo != 7 {
+		t.Fatalf("Expected 7, got %d: %v", quo, err)
+	}
+	// The product overflows 64 bits, but the quotient doesn't
+	expected := new(big.Int).SetUint64(maxUint64)
+	expected.Mul(expected, big.NewInt(999999))
+	expected.Div(expected, big.NewInt(1000000))
+	if quo, err := MulDiv64(maxUint64, 999999, 1000000); err != nil || quo != expected.Uint64() {
+		t.Fatalf("Expected %s, got %d: %v", expected, quo, err)
+	}
+	if quo, err := MulDiv64(maxUint64, maxUint64, maxUint64); err != nil || quo != maxUint64 {
+		t.Fatalf("Expected %d, got %d: %v", maxUint64, quo, err)
+	}
+	if _, err := MulDiv64(maxUint64, 2, 1); err == nil {
+		t.Fatalf("MulDiv64 should have overflowed")
+	}
+	if _, err := MulDiv64(1, 1, 0); err == nil {
+		t.Fatalf("MulDiv64 should have errored due to division by zero")
+	}
+}
+
+func TestBigSum64(t *testing.T) {
+	sum := BigSum64(maxUint64, maxUint64, 2)
+	expected := new(big.Int).Lsh(big.NewInt(1), 65)
+	if sum.Cmp(expected) != 0 {
+		t.Fatalf("Expected %s, got %s", expected, sum)
+	}
+	if _, err := BigToUint64(sum); err == nil {
+		t.Fatalf("BigToUint64 should have overflowed")
+	}
+
+	sum = BigAdd64(new(big.Int), maxUint64)
+	if value, err := BigToUint64(sum); err != nil || value != maxUint64 {
+		t.Fatalf("Expected %d, got %d: %v", maxUint64, value, err)
+	}
+	if _, err := BigToUint64(big.NewInt(-1)); err == nil {
+		t.Fatalf("BigToUint64 should have errored on a negative value")
+	}
+}
@@ -0,0 +1,140 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package random
+
+import (
+	"math"
+	"math/rand"
+)
+
+// WeightedTree implements the Sampler interface by sampling based on a heap
+// structure that is maintained as weights are added, changed, and removed.
+//
+// Unlike Weighted, the tree never needs to be rebuilt. Changing a weight runs
+// in O(log(n)) time, sampling runs in O(log(n)) time, and Replace only restores
+// the elements that were sampled, so drawing k elements without replacement
+// from a large set takes O(k*log(n)) time rather than O(n).
+type WeightedTree struct {
+	// weights are the weights of the elements, including those that have been
+	// sampled
+	weights []int64
+	// current are the weights of the elements, or 0 if they have been sampled
+	current []int64
+	// cumWeights[i] is the weight of element i plus the weights of all its
+	// children in the heap, ignoring elements that have been sampled
+	cumWeights []int64
+	// sampled are the elements that were sampled since the last Replace
+	sampled []int
+}
+
+// Len returns the number of elements in the tree
+func (s *WeightedTree) Len() int { return len(s.weights) }
+
+// Weight returns the weight of element [i]
+func (s *WeightedTree) Weight(i int) uint64 { return uint64(s.weights[i]) }
+
+// Append adds an element with weight [weight] to the end of the tree. Any
+// sampled elements are replaced first.
+func (s *WeightedTree) Append(weight uint64) {
+	s.Replace()
+
+	s.weights = append(s.weights, 0)
+	s.current = append(s.current, 0)
+	s.cumWeights = append(s.cumWeights, 0)
+	s.Update(len(s.weights)-1, weight)
+}
+
+// Update sets the weight of element [i] to [weight]. Any sampled elements are
+// replaced first.
+func (s *WeightedTree) Update(i int, weight uint64) {
+	s.Replace()
+
+	if weight > math.MaxInt64 || uint64(s.total()-s.weights[i]) > math.MaxInt64-weight {
+		panic("Weight too large")
+	}
+	s.changeWeight(i, int64(weight)-s.weights[i])
+	s.weights[i] = int64(weight)
+	s.current[i] = int64(weight)
+}
+
+// RemoveLast removes the last element from the tree. Any sampled elements are
+// replaced first.
+func (s *WeightedTree) RemoveLast() {
+	last := len(s.weights) - 1
+	s.Update(last, 0)
+
+	s.weights = s.weights[:last]
+	s.current = s.current[:last]
+	s.cumWeights = s.cumWeights[:last]
+}
+
+// Clear removes all the elements from the tree
+func (s *WeightedTree) Clear() {
+	s.weights = s.weights[:0]
+	s.current = s.current[:0]
+	s.cumWeights = s.cumWeights[:0]
+	s.sampled = s.sampled[:0]
+}
+
+// Sample returns a number in [0, Len) with probability proportional to the
+// weight of the element at that index, and removes the element until Replace is
+// called. Assumes CanSample returns true.
+func (s *WeightedTree) Sample() int {
+	i := s.SampleReplace()
+	s.changeWeight(i, -s.current[i])
+	s.current[i] = 0
+	s.sampled = append(s.sampled, i)
+	return i
+}
+
+// SampleReplace returns a number in [0, Len) with probability proportional to
+// the weight of the element at that index. Assumes CanSample returns true. The
+// returned index is not removed.
+func (s *WeightedTree) SampleReplace() int {
+	for w, i := rand.Int63n(s.cumWeights[0]), 0; ; {
+		w -= s.current[i]
+		if w < 0 {
+			return i
+		}
+
+		i = i*2 + 1 // We shouldn't return this element, so check the left child
+
+		if lw := s.cumWeights[i]; lw <= w {
+			// If the weight is greater than the left weight, you should move to
+			// the right child
+			w -= lw
+			i++
+		}
+	}
+}
+
+// CanSample returns true if there are elements with weight left to sample
+func (s *WeightedTree) CanSample() bool { return s.total() > 0 }
+
+// Replace all the sampled elements. Takes O(k*log(Len)) time, where k is the
+// number of elements sampled since the last Replace.
+func (s *WeightedTree) Replace() {
+	for _, i := range s.sampled {
+		s.changeWeight(i, s.weights[i])
+		s.current[i] = s.weights[i]
+	}
+	s.sampled = s.sampled[:0]
+}
+
+func (s *WeightedTree) total() int64 {
+	if len(s.cumWeights) == 0 {
+		return 0
+	}
+	return s.cumWeights[0]
+}
+
+// changeWeight adds [change] to the cumulative weight of element [i] and all of
+// its parents
+func (s *WeightedTree) changeWeight(i int, change int64) {
+	s.cumWeights[i] += change
+	for i > 0 {
+		i = (i - 1) / 2
+		s.cumWeights[i] += change
+	}
+}
@@ -0,0 +1,130 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package random
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func newWeightedTree(weights ...uint64) *WeightedTree {
+	s := &WeightedTree{}
+	for _, w := range weights {
+		s.Append(w)
+	}
+	return s
+}
+
+func TestWeightedTree(t *testing.T) {
+	rand.Seed(0)
+
+	counts := [countSize]int{}
+	s := newWeightedTree(0, 1, 2, 3, 4)
+	for i := 0; i < iterations; i++ {
+		subset := Subset(s, 1)
+		for _, j := range subset {
+			counts[j]++
+		}
+		if len(subset) != 1 {
+			t.Fatalf("Incorrect size")
+		}
+		s.Replace()
+	}
+
+	for i := 0; i < countSize; i++ {
+		expected := float64(i) * iterations / 10
+		if math.Abs(float64(counts[i])-expected) > threshold {
+			t.Fatalf("Index seems biased: %s i=%d e=%f", fmt.Sprint(counts), i, expected)
+		}
+	}
+}
+
+func TestWeightedTreeWithoutReplacement(t *testing.T) {
+	s := newWeightedTree(1, 0, 2, 3)
+
+	sampled := map[int]bool{}
+	for s.CanSample() {
+		i := s.Sample()
+		if sampled[i] {
+			t.Fatalf("Sampled %d twice", i)
+		}
+		sampled[i] = true
+	}
+	if len(sampled) != 3 || sampled[1] {
+		t.Fatalf("Sampled the wrong elements: %v", sampled)
+	}
+
+	s.Replace()
+	if !s.CanSample() {
+		t.Fatalf("Should be able to sample")
+	}
+	if s.Weight(2) != 2 {
+		t.Fatalf("Replacing should have kept the weights")
+	}
+}
+
+func TestWeightedTreeUpdate(t *testing.T) {
+	s := newWeightedTree(1, 0, 0)
+
+	if s.Sample() != 0 {
+		t.Fatalf("Wrong sample")
+	}
+	if s.CanSample() {
+		t.Fatalf("Shouldn't be able to sample")
+	}
+
+	// Updating replaces the sampled elements
+	s.Update(0, 0)
+	s.Update(2, 5)
+	if s.SampleReplace() != 2 {
+		t.Fatalf("Wrong sample")
+	}
+
+	s.RemoveLast()
+	if s.Len() != 2 {
+		t.Fatalf("Wrong length %d", s.Len())
+	}
+	if s.CanSample() {
+		t.Fatalf("Shouldn't be able to sample")
+	}
+
+	s.Clear()
+	if s.Len() != 0 || s.CanSample() {
+		t.Fatalf("Clearing should have removed all the elements")
+	}
+}
+
+func TestWeightedTreeTooLarge(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Should have panicked due to the weight being too large")
+		}
+	}()
+
+	newWeightedTree(math.MaxInt64, 1)
+}
+
+func BenchmarkWeightedSample(b *testing.B) {
+	weights := make([]uint64, 10000)
+	for i := range weights {
+		weights[i] = uint64(i + 1)
+	}
+
+	b.Run("Weighted", func(b *testing.B) {
+		s := &Weighted{Weights: weights}
+		for i := 0; i < b.N; i++ {
+			s.Replace()
+			Subset(s, 20)
+		}
+	})
+	b.Run("WeightedTree", func(b *testing.B) {
+		s := newWeightedTree(weights...)
+		for i := 0; i < b.N; i++ {
+			s.Replace()
+			Subset(s, 20)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Config describes how a failing operation is retried
+type Config struct {
+	// MaxAttempts is the maximum number of times the operation is attempted.
+	// If MaxAttempts is 0, the operation is retried until it succeeds.
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first retry
+	InitialDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. If MaxDelay is 0, the delay is
+	// uncapped.
+	MaxDelay time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry. If
+	// Multiplier is less than 1, the delay doubles.
+	Multiplier float64
+
+	// Jitter is the fraction, in [0, 1], of each delay that is randomized. A
+	// delay d is replaced by a delay in [d*(1-Jitter), d].
+	Jitter float64
+}
+
+// Backoff tracks the failed attempts of an operation and computes the delay
+// before the next attempt
+type Backoff struct {
+	config   Config
+	attempts int
+	delay    time.Duration
+}
+
+// NewBackoff returns a new backoff that follows [config]
+func NewBackoff(config Config) *Backoff {
+	b := &Backoff{config: config}
+	b.Reset()
+	return b
+}
+
+// Attempts returns the number of failed attempts recorded
+func (b *Backoff) Attempts() int { return b.attempts }
+
+// Reset forgets all the failed attempts
+func (b *Backoff) Reset() {
+	b.attempts = 0
+	b.delay = b.config.InitialDelay
+}
+
+// Next records a failed attempt. It returns the delay before the operation
+// should be attempted again, and false if no attempts are left.
+func (b *Backoff) Next() (time.Duration, bool) {
+	b.attempts++
+	if b.config.MaxAttempts > 0 && b.attempts >= b.config.MaxAttempts {
+		return 0, false
+	}
+
+	delay := b.delay
+	multiplier := b.config.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	b.delay = time.Duration(float64(b.delay) * multiplier)
+	if max := b.config.MaxDelay; max > 0 && (b.delay > max || b.delay < 0) {
+		b.delay = max
+	}
+
+	if jitter := b.config.Jitter; jitter > 0 && delay > 0 {
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay, true
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps [err] so that Do returns it without retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// Do calls [f] until it succeeds, returns an error wrapped by Permanent, runs
+// out of attempts, or [ctx] is done. The last error returned by [f] is
+// returned, or the context's error if it was done before [f] succeeded.
+func Do(ctx context.Context, config Config, f func() error) error {
+	backoff := NewBackoff(config)
+	for {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+
+		delay, ok := backoff.Next()
+		if !ok {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
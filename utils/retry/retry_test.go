@@ -0,0 +1,108 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("non-nil error")
+
+func TestBackoff(t *testing.T) {
+	b := NewBackoff(Config{
+		MaxAttempts:  4,
+		InitialDelay: time.Second,
+		MaxDelay:     3 * time.Second,
+	})
+
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second} {
+		delay, ok := b.Next()
+		if !ok {
+			t.Fatalf("Should have had attempts left")
+		}
+		if delay != expected {
+			t.Fatalf("Expected a delay of %s, got %s", expected, delay)
+		}
+	}
+	if _, ok := b.Next(); ok {
+		t.Fatalf("Shouldn't have had attempts left")
+	}
+	if b.Attempts() != 4 {
+		t.Fatalf("Expected 4 attempts, got %d", b.Attempts())
+	}
+
+	b.Reset()
+	if delay, ok := b.Next(); !ok || delay != time.Second {
+		t.Fatalf("Reset should have restarted the backoff")
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := NewBackoff(Config{
+		InitialDelay: time.Second,
+		Multiplier:   1,
+		Jitter:       .5,
+	})
+
+	for i := 0; i < 100; i++ {
+		delay, ok := b.Next()
+		if !ok {
+			t.Fatalf("An unlimited backoff should always have attempts left")
+		}
+		if delay < time.Second/2 || delay > time.Second {
+			t.Fatalf("Delay %s is outside of the jitter range", delay)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	config := Config{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	calls := 0
+	err := Do(context.Background(), config, func() error {
+		calls++
+		if calls < 2 {
+			return errTest
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("Should have succeeded on the second attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = Do(context.Background(), config, func() error {
+		calls++
+		return errTest
+	})
+	if err != errTest || calls != 3 {
+		t.Fatalf("Should have failed after 3 attempts, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = Do(context.Background(), config, func() error {
+		calls++
+		return Permanent(errTest)
+	})
+	if err != errTest || calls != 1 {
+		t.Fatalf("Shouldn't have retried a permanent error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestDoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Do(ctx, Config{InitialDelay: time.Hour}, func() error {
+		calls++
+		return errTest
+	})
+	if err != context.Canceled || calls != 1 {
+		t.Fatalf("Should have stopped when the context was cancelled, got %v after %d calls", err, calls)
+	}
+}
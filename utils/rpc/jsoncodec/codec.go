@@ -0,0 +1,31 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package jsoncodec is the gRPC codec that encodes the messages of the node's
+// gRPC services, such as those of plugins, as JSON. Importing the package
+// registers the codec, so servers decode the messages of clients that call with
+// the content subtype Name.
+package jsoncodec
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// Name is the content subtype that messages encoded by the codec are sent with
+const Name = "json"
+
+func init() { encoding.RegisterCodec(Codec{}) }
+
+// Codec encodes gRPC messages as JSON
+type Codec struct{}
+
+// Marshal implements the encoding.Codec interface
+func (Codec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal implements the encoding.Codec interface
+func (Codec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// Name implements the encoding.Codec interface
+func (Codec) Name() string { return Name }
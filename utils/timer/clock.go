@@ -4,23 +4,54 @@
 package timer
 
 import (
+	"sync"
 	"time"
 )
 
-// Clock acts as a thin wrapper around global time that allows for easy testing
+// Clock acts as a thin wrapper around global time that allows for easy testing.
+// Components that read the time from a clock can be driven deterministically
+// by setting or advancing it, rather than by sleeping.
 type Clock struct {
+	lock  sync.RWMutex
 	faked bool
 	time  time.Time
 }
 
 // Set the time on the clock
-func (c *Clock) Set(time time.Time) { c.faked = true; c.time = time }
+func (c *Clock) Set(time time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.faked = true
+	c.time = time
+}
+
+// Advance the time on the clock by [duration]. If the clock wasn't set, it is
+// advanced from the current global time.
+func (c *Clock) Advance(duration time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.faked {
+		c.faked = true
+		c.time = time.Now()
+	}
+	c.time = c.time.Add(duration)
+}
 
 // Sync this clock with global time
-func (c *Clock) Sync() { c.faked = false }
+func (c *Clock) Sync() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.faked = false
+}
 
 // Time returns the time on this clock
 func (c *Clock) Time() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	if c.faked {
 		return c.time
 	}
@@ -14,22 +14,31 @@ import (
 type timeoutHandler func()
 
 type timeout struct {
-	id      ids.ID
-	handler timeoutHandler
-	timer   time.Time
+	id       ids.ID
+	handler  timeoutHandler
+	deadline time.Time
 }
 
 // TimeoutManager is a manager for timeouts.
 type TimeoutManager struct {
 	lock        sync.Mutex
-	duration    time.Duration // Amount of time before a timeout
+	duration    time.Duration // Default amount of time before a timeout
 	timeoutMap  map[[32]byte]*list.Element
-	timeoutList *list.List
-	timer       *Timer // Timer that will fire to clear the timeouts
+	timeoutList *list.List // Sorted by deadline
+	timer       *Timer     // Timer that will fire to clear the timeouts
+	clock       *Clock     // Source of the time that timeouts are measured with
 }
 
 // Initialize is a constructor b/c Golang, in its wisdom, doesn't ... have them?
 func (tm *TimeoutManager) Initialize(duration time.Duration) {
+	tm.InitializeWithClock(duration, &Clock{})
+}
+
+// InitializeWithClock initializes the manager to measure timeouts with
+// [clock]. After advancing [clock], calling Timeout fires the expired timeouts
+// without waiting for the timer.
+func (tm *TimeoutManager) InitializeWithClock(duration time.Duration, clock *Clock) {
+	tm.clock = clock
 	tm.duration = duration
 	tm.timeoutMap = make(map[[32]byte]*list.Element)
 	tm.timeoutList = list.New()
@@ -47,7 +56,16 @@ func (tm *TimeoutManager) Put(id ids.ID, handler func()) {
 	tm.lock.Lock()
 	defer tm.lock.Unlock()
 
-	tm.put(id, handler)
+	tm.put(id, tm.duration, handler)
+}
+
+// PutWithDuration puts hash into the hash map, to time out after [duration]
+// rather than the manager's default duration
+func (tm *TimeoutManager) PutWithDuration(id ids.ID, duration time.Duration, handler func()) {
+	tm.lock.Lock()
+	defer tm.lock.Unlock()
+
+	tm.put(id, duration, handler)
 }
 
 // Remove the item that no longer needs to be there.
@@ -67,10 +85,10 @@ func (tm *TimeoutManager) Timeout() {
 }
 
 func (tm *TimeoutManager) timeout() {
-	timeBound := time.Now().Add(-tm.duration)
+	now := tm.clock.Time()
 	// removeExpiredHead returns false once there is nothing left to remove
 	for {
-		timeout := tm.removeExpiredHead(timeBound)
+		timeout := tm.removeExpiredHead(now)
 		if timeout == nil {
 			break
 		}
@@ -83,16 +101,30 @@ func (tm *TimeoutManager) timeout() {
 	tm.registerTimeout()
 }
 
-func (tm *TimeoutManager) put(id ids.ID, handler timeoutHandler) {
+func (tm *TimeoutManager) put(id ids.ID, duration time.Duration, handler timeoutHandler) {
 	tm.remove(id)
 
-	tm.timeoutMap[id.Key()] = tm.timeoutList.PushBack(timeout{
-		id:      id,
-		handler: handler,
-		timer:   time.Now(),
-	})
+	t := timeout{
+		id:       id,
+		handler:  handler,
+		deadline: tm.clock.Time().Add(duration),
+	}
+
+	// Timeouts usually have similar durations, so the new timeout's place is
+	// searched for from the back
+	prev := tm.timeoutList.Back()
+	for prev != nil && prev.Value.(timeout).deadline.After(t.deadline) {
+		prev = prev.Prev()
+	}
+	var e *list.Element
+	if prev == nil {
+		e = tm.timeoutList.PushFront(t)
+	} else {
+		e = tm.timeoutList.InsertAfter(t, prev)
+	}
+	tm.timeoutMap[id.Key()] = e
 
-	if tm.timeoutList.Len() == 1 {
+	if tm.timeoutList.Front() == e {
 		tm.registerTimeout()
 	}
 }
@@ -116,8 +148,7 @@ func (tm *TimeoutManager) removeExpiredHead(t time.Time) func() {
 	e := tm.timeoutList.Front()
 	head := e.Value.(timeout)
 
-	headTime := head.timer
-	if headTime.Before(t) {
+	if head.deadline.Before(t) {
 		tm.remove(head.id)
 		return head.handler
 	}
@@ -134,9 +165,5 @@ func (tm *TimeoutManager) registerTimeout() {
 	e := tm.timeoutList.Front()
 	head := e.Value.(timeout)
 
-	timeBound := time.Now().Add(-tm.duration)
-	headTime := head.timer
-	duration := headTime.Sub(timeBound)
-
-	tm.timer.SetTimeoutIn(duration)
+	tm.timer.SetTimeoutIn(head.deadline.Sub(tm.clock.Time()))
 }
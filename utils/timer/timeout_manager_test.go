@@ -23,3 +23,51 @@ func TestTimeoutManager(t *testing.T) {
 	tm.Put(ids.NewID([32]byte{}), wg.Done)
 	tm.Put(ids.NewID([32]byte{1}), wg.Done)
 }
+
+func TestTimeoutManagerClock(t *testing.T) {
+	clock := &Clock{}
+	clock.Set(time.Unix(1000, 0))
+
+	tm := TimeoutManager{}
+	tm.InitializeWithClock(time.Hour, clock)
+
+	fired := 0
+	tm.Put(ids.NewID([32]byte{}), func() { fired++ })
+	tm.Put(ids.NewID([32]byte{1}), func() { fired++ })
+
+	tm.Timeout()
+	if fired != 0 {
+		t.Fatalf("Shouldn't have fired before the clock advanced")
+	}
+
+	clock.Advance(time.Hour + time.Second)
+	tm.Timeout()
+	if fired != 2 {
+		t.Fatalf("Should have fired %d timeouts but fired %d", 2, fired)
+	}
+}
+
+func TestTimeoutManagerDurations(t *testing.T) {
+	clock := &Clock{}
+	clock.Set(time.Unix(1000, 0))
+
+	tm := TimeoutManager{}
+	tm.InitializeWithClock(time.Hour, clock)
+
+	fired := []int(nil)
+	tm.Put(ids.NewID([32]byte{}), func() { fired = append(fired, 0) })
+	tm.PutWithDuration(ids.NewID([32]byte{1}), time.Minute, func() { fired = append(fired, 1) })
+	tm.PutWithDuration(ids.NewID([32]byte{2}), 2*time.Minute, func() { fired = append(fired, 2) })
+
+	clock.Advance(90 * time.Second)
+	tm.Timeout()
+	if len(fired) != 1 || fired[0] != 1 {
+		t.Fatalf("Only the shortest timeout should have fired but %v did", fired)
+	}
+
+	clock.Advance(time.Hour)
+	tm.Timeout()
+	if len(fired) != 3 || fired[1] != 2 || fired[2] != 0 {
+		t.Fatalf("Timeouts should have fired in order of their deadlines but fired in order %v", fired)
+	}
+}
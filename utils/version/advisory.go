@@ -0,0 +1,81 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package version
+
+import (
+	"fmt"
+	"sort"
+
+	cjson "github.com/ava-labs/gecko/utils/json"
+)
+
+// Advisory tells whether a node should be upgraded to stay compatible with the
+// network
+type Advisory struct {
+	Version string `json:"version"`
+
+	// Version --> stake of the connected validators running it
+	PeerVersions map[string]cjson.Uint64 `json:"peerVersions"`
+
+	// From the release manifest, if one was loaded
+	MinimumVersion string `json:"minimumVersion,omitempty"`
+	LatestVersion  string `json:"latestVersion,omitempty"`
+	Notes          string `json:"notes,omitempty"`
+
+	// Compatible is false if the node is older than the minimum version
+	Compatible bool     `json:"compatible"`
+	Warnings   []string `json:"warnings"`
+}
+
+// Advise returns the advisory of a node running [myVersion]. [peerStake] maps
+// the versions of the connected validators to their stake. [manifest] is the
+// release manifest, and may be nil.
+func Advise(myVersion Version, peerStake map[string]uint64, manifest *Manifest) Advisory {
+	advisory := Advisory{
+		Version:      myVersion.String(),
+		PeerVersions: make(map[string]cjson.Uint64, len(peerStake)),
+		Compatible:   true,
+		Warnings:     []string{},
+	}
+
+	if manifest != nil {
+		advisory.MinimumVersion = manifest.MinimumVersion
+		advisory.LatestVersion = manifest.LatestVersion
+		advisory.Notes = manifest.Notes
+
+		if minimum, err := Parse(manifest.MinimumVersion); err == nil && myVersion.Compare(minimum) < 0 {
+			advisory.Compatible = false
+			advisory.Warnings = append(advisory.Warnings, fmt.Sprintf(
+				"this node runs %s, which is older than the network's minimum compatible version %s. Upgrade before it's partitioned from the network",
+				myVersion, minimum))
+		}
+		if latest, err := Parse(manifest.LatestVersion); err == nil && myVersion.Compare(latest) < 0 {
+			advisory.Warnings = append(advisory.Warnings, fmt.Sprintf("version %s is available", latest))
+		}
+	}
+
+	totalStake, newerStake := uint64(0), uint64(0)
+	newest := myVersion
+	for versionStr, stake := range peerStake {
+		advisory.PeerVersions[versionStr] = cjson.Uint64(stake)
+		totalStake += stake
+
+		peerVersion, err := Parse(versionStr)
+		if err != nil || peerVersion.App != myVersion.App || peerVersion.Compare(myVersion) <= 0 {
+			continue
+		}
+		newerStake += stake
+		if peerVersion.Compare(newest) > 0 {
+			newest = peerVersion
+		}
+	}
+	// Most of the connected stake upgrading is a sign that an upgrade is coming
+	if totalStake > 0 && newerStake > totalStake/2 {
+		advisory.Warnings = append(advisory.Warnings, fmt.Sprintf(
+			"%d%% of the connected stake runs a newer version than this node's %s, up to %s",
+			newerStake*100/totalStake, myVersion, newest))
+	}
+	sort.Strings(advisory.Warnings)
+	return advisory
+}
@@ -0,0 +1,58 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package version
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+var (
+	errBadSignature = errors.New("release manifest isn't signed by the release key")
+)
+
+// Manifest describes the releases of the node. It's published by the release
+// team, who sign it with the release key.
+type Manifest struct {
+	// Nodes older than MinimumVersion are no longer compatible with the
+	// network, or won't be once a scheduled upgrade activates
+	MinimumVersion string `json:"minimumVersion"`
+	// LatestVersion is the newest release
+	LatestVersion string `json:"latestVersion"`
+	// Notes about the latest release, such as when its upgrade activates
+	Notes string `json:"notes"`
+}
+
+// SignedManifest is a manifest and its signature by the release key
+type SignedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature formatting.CB58 `json:"signature"`
+}
+
+// ParseManifest returns the manifest in the JSON encoded SignedManifest
+// [signedBytes], if it's signed by [releaseKey]
+func ParseManifest(signedBytes []byte, releaseKey crypto.PublicKey) (*Manifest, error) {
+	signed := SignedManifest{}
+	if err := json.Unmarshal(signedBytes, &signed); err != nil {
+		return nil, err
+	}
+	if !releaseKey.Verify(signed.Manifest, signed.Signature.Bytes) {
+		return nil, errBadSignature
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(signed.Manifest, manifest); err != nil {
+		return nil, err
+	}
+	if _, err := Parse(manifest.MinimumVersion); err != nil {
+		return nil, err
+	}
+	if _, err := Parse(manifest.LatestVersion); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -0,0 +1,66 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errNoApp          = errors.New("version must be formatted as <app>/<major>.<minor>.<patch>")
+	errWrongNumFields = errors.New("version number must be formatted as <major>.<minor>.<patch>")
+)
+
+// Version of a node, such as avalanche/0.0.1
+type Version struct {
+	App                 string
+	Major, Minor, Patch int
+}
+
+// Parse the version [s], formatted as <app>/<major>.<minor>.<patch>
+func Parse(s string) (Version, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return Version{}, errNoApp
+	}
+	numbers := strings.Split(parts[1], ".")
+	if len(numbers) != 3 {
+		return Version{}, errWrongNumFields
+	}
+	fields := [3]int{}
+	for i, number := range numbers {
+		field, err := strconv.Atoi(number)
+		if err != nil || field < 0 {
+			return Version{}, fmt.Errorf("invalid version number %q", parts[1])
+		}
+		fields[i] = field
+	}
+	return Version{
+		App:   parts[0],
+		Major: fields[0],
+		Minor: fields[1],
+		Patch: fields[2],
+	}, nil
+}
+
+// Compare returns a negative number if [v] is older than [o], 0 if they're
+// the same version, and a positive number if [v] is newer. The app isn't
+// compared.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return v.Major - o.Major
+	case v.Minor != o.Minor:
+		return v.Minor - o.Minor
+	default:
+		return v.Patch - o.Patch
+	}
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%s/%d.%d.%d", v.App, v.Major, v.Minor, v.Patch)
+}
@@ -0,0 +1,119 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package version
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("avalanche/1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.App != "avalanche" || v.Major != 1 || v.Minor != 2 || v.Patch != 3 {
+		t.Fatalf("Parse Returned: %+v", v)
+	}
+	if str := v.String(); str != "avalanche/1.2.3" {
+		t.Fatalf("String Returned: %s ; Expected: %s", str, "avalanche/1.2.3")
+	}
+
+	for _, bad := range []string{"", "1.2.3", "avalanche/1.2", "avalanche/1.x.3", "avalanche/1.-2.3"} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("Should have errored parsing %q", bad)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	older, _ := Parse("avalanche/0.9.12")
+	newer, _ := Parse("avalanche/0.10.0")
+
+	if older.Compare(newer) >= 0 {
+		t.Fatalf("%s should be older than %s", older, newer)
+	}
+	if newer.Compare(older) <= 0 {
+		t.Fatalf("%s should be newer than %s", newer, older)
+	}
+	if older.Compare(older) != 0 {
+		t.Fatalf("%s should be the same as itself", older)
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	factory := crypto.FactorySECP256K1R{}
+	skIntf, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := skIntf.(*crypto.PrivateKeySECP256K1R)
+
+	manifestBytes, err := json.Marshal(Manifest{
+		MinimumVersion: "avalanche/0.1.0",
+		LatestVersion:  "avalanche/0.2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := sk.Sign(manifestBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedBytes, err := json.Marshal(SignedManifest{
+		Manifest:  manifestBytes,
+		Signature: formatting.CB58{Bytes: sig},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := ParseManifest(signedBytes, sk.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.LatestVersion != "avalanche/0.2.0" {
+		t.Fatalf("Wrong latest version %s", manifest.LatestVersion)
+	}
+
+	otherSK, err := factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseManifest(signedBytes, otherSK.PublicKey()); err != errBadSignature {
+		t.Fatalf("Should have rejected a manifest signed by another key but returned %v", err)
+	}
+}
+
+func TestAdvise(t *testing.T) {
+	myVersion, _ := Parse("avalanche/0.1.0")
+
+	advisory := Advise(myVersion, map[string]uint64{
+		"avalanche/0.1.0": 10,
+		"avalanche/0.2.0": 30,
+	}, &Manifest{
+		MinimumVersion: "avalanche/0.2.0",
+		LatestVersion:  "avalanche/0.2.0",
+	})
+	if advisory.Compatible {
+		t.Fatalf("Node older than the minimum version should be incompatible")
+	}
+	if len(advisory.Warnings) != 3 {
+		t.Fatalf("Should have warned about the minimum version, the latest version and the peers but warned %v", advisory.Warnings)
+	}
+	if stake := advisory.PeerVersions["avalanche/0.2.0"]; stake != 30 {
+		t.Fatalf("Wrong stake %d", stake)
+	}
+
+	advisory = Advise(myVersion, map[string]uint64{
+		"avalanche/0.1.0": 30,
+		"avalanche/0.2.0": 10,
+	}, nil)
+	if !advisory.Compatible || len(advisory.Warnings) != 0 {
+		t.Fatalf("A minority of upgraded peers shouldn't raise an advisory but got %v", advisory.Warnings)
+	}
+}
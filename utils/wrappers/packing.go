@@ -7,6 +7,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"math"
+	"sync"
 
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/hashing"
@@ -24,6 +25,13 @@ const (
 	IntLen = 4
 	// LongLen is the number of bytes per long
 	LongLen = 8
+
+	// DefaultPooledSize is the initial capacity of pooled byte arrays
+	DefaultPooledSize = 1 << 10
+	// MaxPooledSize is the largest capacity of a byte array that will be
+	// returned to the pool. Larger byte arrays are left to the garbage
+	// collector so that a few large messages don't pin memory.
+	MaxPooledSize = 1 << 20
 )
 
 var (
@@ -34,7 +42,20 @@ var (
 	errBadBool        = errors.New("unexpected value when unpacking bool")
 )
 
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		bytes := make([]byte, 0, DefaultPooledSize)
+		return &bytes
+	},
+}
+
 // Packer packs and unpacks a byte array from/to standard values
+//
+// Byte slices returned by UnpackFixedBytes, and the functions built on it,
+// alias the packer's byte array rather than being copied. They are only valid
+// for as long as the byte array is. If the packer reads from a byte array that
+// it doesn't own, such as a buffer that will be reused after unpacking,
+// [Borrowed] should be set so that unpacked byte slices are copied instead.
 type Packer struct {
 	Errs
 
@@ -44,6 +65,41 @@ type Packer struct {
 	Bytes []byte
 	// The offset that is being written to in the byte array
 	Offset int
+	// Borrowed is true if the byte array may be modified or freed after
+	// unpacking, so unpacked byte slices must not alias it
+	Borrowed bool
+
+	// pooled is the pool entry that the byte array was taken from, or nil if
+	// it wasn't taken from the pool
+	pooled *[]byte
+}
+
+// NewPooledPacker returns a packer, with the provided maximum size, whose byte
+// array is taken from a pool. The packer owns its byte array until Release is
+// called.
+func NewPooledPacker(maxSize int) *Packer {
+	pooled := bufferPool.Get().(*[]byte)
+	return &Packer{
+		MaxSize: maxSize,
+		Bytes:   (*pooled)[:0],
+		pooled:  pooled,
+	}
+}
+
+// Release returns the byte array to the pool if it was taken from one. After
+// Release is called, neither the byte array nor any byte slices that alias it
+// may be used. Calling Release on a packer that isn't pooled is a no-op.
+func (p *Packer) Release() {
+	if p.pooled == nil {
+		return
+	}
+	if cap(p.Bytes) <= MaxPooledSize {
+		*p.pooled = p.Bytes[:0]
+		bufferPool.Put(p.pooled)
+	}
+	p.pooled = nil
+	p.Bytes = nil
+	p.Offset = 0
 }
 
 // CheckSpace requires that there is at least [bytes] of write space left in the
@@ -210,14 +266,18 @@ func (p *Packer) PackFixedBytes(bytes []byte) {
 }
 
 // UnpackFixedBytes unpack a byte slice, with no length descriptor from the byte
-// array
+// array. Unless the packer's byte array is borrowed, the returned slice aliases
+// it.
 func (p *Packer) UnpackFixedBytes(size int) []byte {
 	p.CheckSpace(size)
 	if p.Errored() {
 		return nil
 	}
 
-	bytes := p.Bytes[p.Offset : p.Offset+size]
+	bytes := p.Bytes[p.Offset : p.Offset+size : p.Offset+size]
+	if p.Borrowed {
+		bytes = append([]byte(nil), bytes...)
+	}
 	p.Offset += size
 	return bytes
 }
@@ -252,6 +312,26 @@ func (p *Packer) UnpackFixedByteSlices(size int) [][]byte {
 	return bytes
 }
 
+// PackByteSlices append a list of variable length byte slices to the byte
+// array
+func (p *Packer) PackByteSlices(byteSlices [][]byte) {
+	p.PackInt(uint32(len(byteSlices)))
+	for _, bytes := range byteSlices {
+		p.PackBytes(bytes)
+	}
+}
+
+// UnpackByteSlices unpack a list of variable length byte slices from the byte
+// array
+func (p *Packer) UnpackByteSlices() [][]byte {
+	sliceSize := p.UnpackInt()
+	bytes := [][]byte(nil)
+	for i := uint32(0); i < sliceSize && !p.Errored(); i++ {
+		bytes = append(bytes, p.UnpackBytes())
+	}
+	return bytes
+}
+
 // PackStr append a string to the byte array
 func (p *Packer) PackStr(str string) {
 	strSize := len(str)
@@ -264,8 +344,17 @@ func (p *Packer) PackStr(str string) {
 
 // UnpackStr unpacks a string from the byte array
 func (p *Packer) UnpackStr() string {
-	strSize := p.UnpackShort()
-	return string(p.UnpackFixedBytes(int(strSize)))
+	strSize := int(p.UnpackShort())
+	p.CheckSpace(strSize)
+	if p.Errored() {
+		return ""
+	}
+
+	// Converting to a string copies the bytes, so this never aliases the
+	// byte array
+	str := string(p.Bytes[p.Offset : p.Offset+strSize])
+	p.Offset += strSize
+	return str
 }
 
 // PackIP unpacks an ip port pair from the byte array
@@ -428,6 +517,20 @@ func TryUnpackBytes(packer *Packer) interface{} {
 	return packer.UnpackBytes()
 }
 
+// TryPackBytesList attempts to pack the value as a list of byte slices
+func TryPackBytesList(packer *Packer, valIntf interface{}) {
+	if val, ok := valIntf.([][]byte); ok {
+		packer.PackByteSlices(val)
+	} else {
+		packer.Add(errBadType)
+	}
+}
+
+// TryUnpackBytesList attempts to unpack the value as a list of byte slices
+func TryUnpackBytesList(packer *Packer) interface{} {
+	return packer.UnpackByteSlices()
+}
+
 // TryPackStr attempts to pack the value as a string
 func TryPackStr(packer *Packer, valIntf interface{}) {
 	if val, ok := valIntf.(string); ok {
@@ -5,6 +5,7 @@ package wrappers
 
 import (
 	"bytes"
+	"math"
 	"testing"
 )
 
@@ -151,3 +152,109 @@ func TestPackBool(t *testing.T) {
 		t.Fatal("got back wrong values")
 	}
 }
+
+func TestPackByteSlices(t *testing.T) {
+	byteSlices := [][]byte{{0x01}, {}, {0x02, 0x03}}
+
+	p := Packer{MaxSize: 1024}
+	p.PackByteSlices(byteSlices)
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x01, 0x01,
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x02, 0x02, 0x03,
+	}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer.PackByteSlices wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	p2 := Packer{Bytes: p.Bytes}
+	unpacked := p2.UnpackByteSlices()
+	if p2.Errored() {
+		t.Fatal(p2.Err)
+	}
+	if len(unpacked) != len(byteSlices) {
+		t.Fatalf("Unpacked %d byte slices but expected %d", len(unpacked), len(byteSlices))
+	}
+	for i, byteSlice := range byteSlices {
+		if !bytes.Equal(unpacked[i], byteSlice) {
+			t.Fatalf("Unpacked %v but expected %v", unpacked[i], byteSlice)
+		}
+	}
+}
+
+func TestPackerUnpackAliasing(t *testing.T) {
+	b := []byte{0x00, 0x00, 0x00, 0x02, 0x01, 0x02, 0x03}
+
+	p := Packer{Bytes: b}
+	unpacked := p.UnpackBytes()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	if !bytes.Equal(unpacked, []byte{0x01, 0x02}) {
+		t.Fatalf("Unpacked %v", unpacked)
+	}
+	// Appending must not overwrite the rest of the byte array
+	_ = append(unpacked, 0xff)
+	if b[6] != 0x03 {
+		t.Fatalf("Appending to an unpacked slice modified the byte array")
+	}
+	b[4] = 0x05
+	if unpacked[0] != 0x05 {
+		t.Fatalf("An owned byte array should have been aliased")
+	}
+
+	p = Packer{Bytes: b, Borrowed: true}
+	unpacked = p.UnpackBytes()
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	b[4] = 0x01
+	if unpacked[0] != 0x05 {
+		t.Fatalf("A borrowed byte array shouldn't have been aliased")
+	}
+}
+
+func TestPooledPacker(t *testing.T) {
+	p := NewPooledPacker(1024)
+	p.PackInt(1)
+	p.PackBytes([]byte{0x01, 0x02})
+	if p.Errored() {
+		t.Fatal(p.Err)
+	}
+	expected := []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x01, 0x02}
+	if !bytes.Equal(p.Bytes, expected) {
+		t.Fatalf("Packer wrote:\n%v\nExpected:\n%v", p.Bytes, expected)
+	}
+
+	p.Release()
+	if p.Bytes != nil || p.Offset != 0 {
+		t.Fatalf("Release should have cleared the byte array")
+	}
+	// Releasing twice is a no-op
+	p.Release()
+
+	p = NewPooledPacker(4)
+	if len(p.Bytes) != 0 {
+		t.Fatalf("A pooled byte array should start empty")
+	}
+	p.PackLong(1)
+	if !p.Errored() {
+		t.Fatalf("Should have errored due to the maximum size")
+	}
+	p.Release()
+}
+
+func BenchmarkPooledPacker(b *testing.B) {
+	payload := make([]byte, 512)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := NewPooledPacker(math.MaxInt32)
+		p.PackBytes(payload)
+		p.Release()
+	}
+}
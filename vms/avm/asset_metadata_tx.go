@@ -0,0 +1,166 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+const (
+	maxLogoURILen = 256
+)
+
+var (
+	errNilAssetMetadata   = errors.New("nil asset metadata is not valid")
+	errLogoURITooLong     = fmt.Errorf("logo URI is too long, maximum size is %d", maxLogoURILen)
+	errNotAssetIssuer     = errors.New("asset metadata wasn't signed by an issuer of the asset")
+	errStaleAssetMetadata = errors.New("asset metadata version must be greater than the version of the registered metadata")
+	errTxNotAssetMetadata = errors.New("transaction doesn't register asset metadata")
+)
+
+// AssetMetadata describes an asset to wallets
+type AssetMetadata struct {
+	AssetID      ids.ID `serialize:"true"` // The asset being described
+	Version      uint32 `serialize:"true"` // Must increase with every update of the asset's metadata
+	Name         string `serialize:"true"`
+	Symbol       string `serialize:"true"`
+	Denomination byte   `serialize:"true"`
+	LogoURI      string `serialize:"true"`
+}
+
+// Verify that the metadata is well-formed
+func (md *AssetMetadata) Verify() error {
+	switch {
+	case md == nil:
+		return errNilAssetMetadata
+	case md.AssetID.IsZero():
+		return errUnknownAssetID
+	case len(md.Name) > maxNameLen:
+		return errNameTooLong
+	case len(md.Symbol) > maxSymbolLen:
+		return errSymbolTooLong
+	case md.Denomination > maxDenomination:
+		return errDenominationTooLarge
+	case len(md.LogoURI) > maxLogoURILen:
+		return errLogoURITooLong
+	case strings.TrimSpace(md.Name) != md.Name:
+		return errUnexpectedWhitespace
+	case strings.TrimSpace(md.Symbol) != md.Symbol:
+		return errUnexpectedWhitespace
+	}
+
+	for _, str := range []string{md.Name, md.Symbol, md.LogoURI} {
+		for _, r := range str {
+			if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+				return errUnprintableASCIICharacter
+			}
+		}
+	}
+	return nil
+}
+
+// AssetMetadataTx is a transaction that registers the metadata of an asset.
+// The metadata must be signed by an issuer of the asset, which is an address
+// that owned one of the asset's initial outputs.
+type AssetMetadataTx struct {
+	BaseTx    `serialize:"true"`
+	Metadata  AssetMetadata                 `serialize:"true"`
+	IssuerSig [crypto.SECP256K1RSigLen]byte `serialize:"true"` // Signature of the issuer on the byte repr. of the metadata
+}
+
+// AssetIDs returns the IDs of the assets this transaction depends on
+func (t *AssetMetadataTx) AssetIDs() ids.Set {
+	assets := t.BaseTx.AssetIDs()
+	assets.Add(t.Metadata.AssetID)
+	return assets
+}
+
+// Issuer returns the address that signed the metadata
+func (t *AssetMetadataTx) Issuer(c codec.Codec) (ids.ShortID, error) {
+	metadataBytes, err := c.Marshal(&t.Metadata)
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	factory := crypto.FactorySECP256K1R{}
+	key, err := factory.RecoverPublicKey(metadataBytes, t.IssuerSig[:])
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return key.Address(), nil
+}
+
+// SyntacticVerify that this transaction is well-formed.
+func (t *AssetMetadataTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, numFxs int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	}
+
+	if err := t.BaseTx.SyntacticVerify(ctx, c, numFxs); err != nil {
+		return err
+	}
+	return t.Metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid.
+func (t *AssetMetadataTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+
+	asset := &UniqueTx{
+		vm:   vm,
+		txID: t.Metadata.AssetID,
+	}
+	if status := asset.Status(); !status.Fetched() {
+		return errUnknownAssetID
+	}
+	createAssetTx, ok := asset.t.tx.UnsignedTx.(*CreateAssetTx)
+	if !ok {
+		return errTxNotCreateAsset
+	}
+
+	issuer, err := t.Issuer(vm.codec)
+	if err != nil {
+		return err
+	}
+	if !isAssetIssuer(createAssetTx, issuer) {
+		return errNotAssetIssuer
+	}
+
+	registered, err := vm.getAssetMetadata(t.Metadata.AssetID)
+	if err == nil && registered.Metadata.Version >= t.Metadata.Version {
+		return errStaleAssetMetadata
+	}
+	return nil
+}
+
+// isAssetIssuer returns true if [addr] owns one of the initial outputs of the
+// asset created by [tx]
+func isAssetIssuer(tx *CreateAssetTx, addr ids.ShortID) bool {
+	addrBytes := addr.Bytes()
+	for _, state := range tx.States {
+		for _, out := range state.Outs {
+			addressable, ok := out.(FxAddressable)
+			if !ok {
+				continue
+			}
+			for _, owner := range addressable.Addresses() {
+				if bytes.Equal(owner, addrBytes) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
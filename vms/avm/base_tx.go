@@ -88,6 +88,18 @@ func (t *BaseTx) UTXOs() []*UTXO {
 
 // SyntacticVerify that this transaction is well-formed.
 func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	if err := t.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+	if err := verifyFunds(t.Ins, t.Outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// verifyFormat verifies that the inputs and outputs of this transaction are
+// well-formed, without verifying that the inputs fund the outputs
+func (t *BaseTx) verifyFormat(ctx *snow.Context, c codec.Codec) error {
 	switch {
 	case t == nil:
 		return errNilTx
@@ -114,9 +126,14 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 	if !isSortedAndUniqueTransferableInputs(t.Ins) {
 		return errInputsNotSortedUnique
 	}
+	return nil
+}
 
+// verifyFunds verifies that [ins] consume at least as much of each asset as
+// [outs] produce
+func verifyFunds(ins []*TransferableInput, outs []*TransferableOutput) error {
 	consumedFunds := map[[32]byte]uint64{}
-	for _, in := range t.Ins {
+	for _, in := range ins {
 		assetID := in.AssetID()
 		amount := in.Input().Amount()
 
@@ -129,7 +146,7 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 		}
 	}
 	producedFunds := map[[32]byte]uint64{}
-	for _, out := range t.Outs {
+	for _, out := range outs {
 		assetID := out.AssetID()
 		amount := out.Output().Amount()
 
@@ -150,8 +167,7 @@ func (t *BaseTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error
 			return errInsufficientFunds
 		}
 	}
-
-	return t.metadata.Verify()
+	return nil
 }
 
 // SemanticVerify that this transaction is valid to be spent.
@@ -0,0 +1,161 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	errNoExportOutputs  = errors.New("no export outputs")
+	errExportToSelf     = errors.New("can't export to the chain the tx is issued on")
+	errNilDestinationID = errors.New("nil destination chain ID is not valid")
+)
+
+// ExportTx is a transaction that exports UTXOs to another chain. The exported
+// outputs aren't added to this chain's UTXO set. Once the transaction is
+// accepted, they're put in the memory this chain shares with the destination
+// chain, which can then import them.
+type ExportTx struct {
+	BaseTx `serialize:"true"`
+
+	DestinationChain ids.ID                `serialize:"true"` // Chain the outputs are exported to
+	ExportedOuts     []*TransferableOutput `serialize:"true"` // Outputs that are exported
+}
+
+// ExportedUTXOs returns the UTXOs this transaction exports
+func (t *ExportTx) ExportedUTXOs() []*UTXO {
+	txID := t.ID()
+	utxos := make([]*UTXO, len(t.ExportedOuts))
+	for i, out := range t.ExportedOuts {
+		utxos[i] = &UTXO{
+			UTXOID: UTXOID{
+				TxID:        txID,
+				OutputIndex: uint32(len(t.Outs) + i),
+			},
+			Asset: Asset{
+				ID: out.AssetID(),
+			},
+			Out: out.Out,
+		}
+	}
+	return utxos
+}
+
+// SyntacticVerify that this transaction is well-formed.
+func (t *ExportTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	case t.DestinationChain.IsZero():
+		return errNilDestinationID
+	case t.DestinationChain.Equals(ctx.ChainID):
+		return errExportToSelf
+	case len(t.ExportedOuts) == 0:
+		return errNoExportOutputs
+	}
+
+	if err := t.BaseTx.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+
+	for _, out := range t.ExportedOuts {
+		if err := out.Verify(); err != nil {
+			return err
+		}
+	}
+	if !IsSortedTransferableOutputs(t.ExportedOuts, c) {
+		return errOutputsNotSorted
+	}
+
+	outs := make([]*TransferableOutput, 0, len(t.Outs)+len(t.ExportedOuts))
+	outs = append(outs, t.Outs...)
+	outs = append(outs, t.ExportedOuts...)
+	if err := verifyFunds(t.Ins, outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid to be spent.
+func (t *ExportTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+
+	for _, out := range t.ExportedOuts {
+		fxIndex, err := vm.getFx(out.Out)
+		if err != nil {
+			return err
+		}
+		if !vm.verifyFxUsage(fxIndex, out.AssetID()) {
+			return errIncompatibleFx
+		}
+	}
+
+	if t.DestinationChain.Equals(platformChainID) {
+		for i, utxo := range t.ExportedUTXOs() {
+			if _, err := vm.transfer(&utxo.UTXOID, t.ExportedOuts[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// AtomicRequests returns the requests that put the exported UTXOs in the
+// memory shared with the destination chain. This transaction is put there
+// too, under its ID, so the destination chain can check the UTXOs it imports
+// against the transaction this chain accepted.
+//
+// The Platform Chain can't parse UTXOs or this transaction, so the UTXOs
+// exported to it are put there as the transfers they're imported as, under
+// the same IDs.
+func (t *ExportTx) AtomicRequests(vm *VM) (map[[32]byte]*atomic.Requests, error) {
+	utxos := t.ExportedUTXOs()
+	if t.DestinationChain.Equals(platformChainID) {
+		elems := make([]*atomic.Element, len(utxos))
+		for i, utxo := range utxos {
+			transfer, err := vm.transfer(&utxo.UTXOID, t.ExportedOuts[i])
+			if err != nil {
+				return nil, err
+			}
+			transferBytes, err := transfer.Bytes()
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = &atomic.Element{
+				Key:   utxo.InputID().Bytes(),
+				Value: transferBytes,
+			}
+		}
+		return map[[32]byte]*atomic.Requests{
+			t.DestinationChain.Key(): {PutRequests: elems},
+		}, nil
+	}
+
+	elems := make([]*atomic.Element, len(utxos), len(utxos)+1)
+	for i, utxo := range utxos {
+		utxoBytes, err := vm.codec.Marshal(utxo)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = &atomic.Element{
+			Key:   utxo.InputID().Bytes(),
+			Value: utxoBytes,
+		}
+	}
+	elems = append(elems, &atomic.Element{
+		Key:   t.ID().Bytes(),
+		Value: t.Bytes(),
+	})
+	return map[[32]byte]*atomic.Requests{
+		t.DestinationChain.Key(): {PutRequests: elems},
+	}, nil
+}
@@ -4,7 +4,15 @@
 package avm
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 )
 
 // ID that this VM uses when labeled
@@ -12,8 +20,67 @@ var (
 	ID = ids.NewID([32]byte{'a', 'v', 'm'})
 )
 
+var errGenesisAssetsNotSortedUnique = errors.New("genesis assets must be sorted by alias and unique")
+
 // Factory ...
-type Factory struct{}
+type Factory struct {
+	MempoolConfig mempool.Config
+	FeeAsset      string
+	ReissueLimit  int
+	CodecLimits   codec.Limits
+}
 
 // New ...
-func (f *Factory) New() interface{} { return &VM{} }
+func (f *Factory) New() interface{} {
+	return &VM{
+		MempoolConfig: f.MempoolConfig,
+		FeeAsset:      f.FeeAsset,
+		ReissueLimit:  f.ReissueLimit,
+		CodecLimits:   f.CodecLimits,
+	}
+}
+
+// VerifyGenesis returns nil if [genesisBytes] is a well formed genesis for a
+// chain of this VM running [fxs]. [fxs] are initialized by this call.
+func (f *Factory) VerifyGenesis(genesisBytes []byte, fxs []*common.Fx) error {
+	vm := &VM{
+		CodecLimits:   f.CodecLimits,
+		typeToFxIndex: map[reflect.Type]int{},
+	}
+	if err := vm.initFxs(fxs); err != nil {
+		return err
+	}
+
+	genesis := Genesis{}
+	if err := vm.codec.Unmarshal(genesisBytes, &genesis); err != nil {
+		return err
+	}
+	if !genesis.IsSortedAndUnique() {
+		return errGenesisAssetsNotSortedUnique
+	}
+
+	for _, genesisTx := range genesis.Txs {
+		if len(genesisTx.Outs) != 0 {
+			return errGenesisAssetMustHaveState
+		}
+		tx := Tx{
+			UnsignedTx: &genesisTx.CreateAssetTx,
+		}
+		txBytes, err := vm.codec.Marshal(&tx)
+		if err != nil {
+			return err
+		}
+		tx.Initialize(txBytes)
+
+		// The ID of the chain isn't known until it is created, so the network
+		// and chain IDs of genesis txs aren't checked
+		ctx := &snow.Context{
+			NetworkID: genesisTx.NetID,
+			ChainID:   genesisTx.BCID,
+		}
+		if err := genesisTx.CreateAssetTx.SyntacticVerify(ctx, vm.codec, len(fxs)); err != nil {
+			return fmt.Errorf("genesis asset '%s' is invalid: %w", genesisTx.Alias, err)
+		}
+	}
+	return nil
+}
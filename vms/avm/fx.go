@@ -32,6 +32,19 @@ type Fx interface {
 	VerifyOperation(tx interface{}, utxos, ins, creds, outs []interface{}) error
 }
 
+// FxBatchVerifier is the interface a feature extension may provide to verify
+// the signatures of a transaction together, after the rest of the transaction
+// is verified
+type FxBatchVerifier interface {
+	// StartBatch defers the verification of signatures until the matching
+	// call to VerifyBatch. Batches may be nested.
+	StartBatch()
+
+	// VerifyBatch ends the last started batch, and returns nil if every
+	// signature deferred since it was started is valid
+	VerifyBatch() error
+}
+
 // FxTransferable is the interface a feature extension must provide to transfer
 // value between features extensions.
 type FxTransferable interface {
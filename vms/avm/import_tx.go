@@ -0,0 +1,263 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	errNoImportInputs  = errors.New("no import inputs")
+	errImportFromSelf  = errors.New("can't import from the chain the tx is issued on")
+	errNilSourceID     = errors.New("nil source chain ID is not valid")
+	errNoSharedMemory  = errors.New("chain has no shared memory to import from")
+	errMissingImported = errors.New("imported UTXO isn't in shared memory")
+	errMissingExport   = errors.New("export tx of the imported UTXO isn't in shared memory")
+	errForgedExport    = errors.New("export tx in shared memory isn't the tx the source chain accepted")
+	errForgedImport    = errors.New("imported UTXO wasn't exported by the tx it names")
+)
+
+// ImportTx is a transaction that imports UTXOs that another chain exported to
+// this chain. Once the transaction is accepted, the imported UTXOs are removed
+// from the memory this chain shares with the source chain.
+type ImportTx struct {
+	BaseTx `serialize:"true"`
+
+	SourceChain ids.ID               `serialize:"true"` // Chain the UTXOs were exported from
+	ImportedIns []*TransferableInput `serialize:"true"` // Inputs that consume the imported UTXOs
+}
+
+// InputUTXOs track which UTXOs this transaction is consuming.
+func (t *ImportTx) InputUTXOs() []*UTXOID {
+	utxos := t.BaseTx.InputUTXOs()
+	for _, in := range t.ImportedIns {
+		// The input's UTXOID is copied so that this tx isn't changed
+		utxoID := in.UTXOID
+		utxoID.Symbol = true
+		utxos = append(utxos, &utxoID)
+	}
+	return utxos
+}
+
+// AssetIDs returns the IDs of the assets this transaction depends on
+func (t *ImportTx) AssetIDs() ids.Set {
+	assets := t.BaseTx.AssetIDs()
+	for _, in := range t.ImportedIns {
+		assets.Add(in.AssetID())
+	}
+	return assets
+}
+
+// SyntacticVerify that this transaction is well-formed.
+func (t *ImportTx) SyntacticVerify(ctx *snow.Context, c codec.Codec, _ int) error {
+	switch {
+	case t == nil:
+		return errNilTx
+	case t.SourceChain.IsZero():
+		return errNilSourceID
+	case t.SourceChain.Equals(ctx.ChainID):
+		return errImportFromSelf
+	case len(t.ImportedIns) == 0:
+		return errNoImportInputs
+	}
+
+	if err := t.BaseTx.verifyFormat(ctx, c); err != nil {
+		return err
+	}
+
+	for _, in := range t.ImportedIns {
+		if err := in.Verify(); err != nil {
+			return err
+		}
+	}
+	if !isSortedAndUniqueTransferableInputs(t.ImportedIns) {
+		return errInputsNotSortedUnique
+	}
+
+	ins := make([]*TransferableInput, 0, len(t.Ins)+len(t.ImportedIns))
+	ins = append(ins, t.Ins...)
+	ins = append(ins, t.ImportedIns...)
+	if err := verifyFunds(ins, t.Outs); err != nil {
+		return err
+	}
+	return t.metadata.Verify()
+}
+
+// SemanticVerify that this transaction is valid to be spent.
+func (t *ImportTx) SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error {
+	if err := t.BaseTx.SemanticVerify(vm, uTx, creds); err != nil {
+		return err
+	}
+	if vm.ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+
+	utxos, err := vm.sharedUTXOs(t.SourceChain, t.importedKeys())
+	if err != nil {
+		return err
+	}
+	if !t.SourceChain.Equals(platformChainID) {
+		if err := t.verifyExports(vm, utxos); err != nil {
+			return err
+		}
+	}
+
+	offset := len(t.Ins)
+	for i, in := range t.ImportedIns {
+		cred := creds[offset+i]
+
+		fxIndex, err := vm.getFx(cred.Cred)
+		if err != nil {
+			return err
+		}
+		fx := vm.fxs[fxIndex].Fx
+
+		utxo := utxos[i]
+		utxoAssetID := utxo.AssetID()
+		inAssetID := in.AssetID()
+		if !utxoAssetID.Equals(inAssetID) {
+			return errAssetIDMismatch
+		}
+
+		if !vm.verifyFxUsage(fxIndex, inAssetID) {
+			return errIncompatibleFx
+		}
+
+		if err := fx.VerifyTransfer(uTx, utxo.Out, in.In, cred.Cred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyExports verifies that each of [utxos], the UTXOs this transaction
+// imports, was exported by the tx it names.
+//
+// The contents of shared memory aren't trusted. Each imported UTXO must be one
+// of the UTXOs exported by the tx it names, which is only trusted if its bytes
+// hash to its ID, as the source chain's consensus agreed on that ID rather
+// than on anything this node stored. The Platform Chain doesn't put its txs in
+// shared memory, so its transfers are only checked to be stored under their
+// IDs.
+func (t *ImportTx) verifyExports(vm *VM, utxos []*UTXO) error {
+	exportTxs := map[[32]byte]*ExportTx{}
+	for i, in := range t.ImportedIns {
+		exportTx, ok := exportTxs[in.TxID.Key()]
+		if !ok {
+			var err error
+			exportTx, err = vm.getExportTx(t.SourceChain, in.TxID)
+			if err != nil {
+				return err
+			}
+			exportTxs[in.TxID.Key()] = exportTx
+		}
+
+		exported := exportTx.ExportedUTXOs()
+		index := int(in.OutputIndex) - len(exportTx.Outs)
+		if index < 0 || index >= len(exported) {
+			return errForgedImport
+		}
+		exportedBytes, err := vm.codec.Marshal(exported[index])
+		if err != nil {
+			return err
+		}
+		utxoBytes, err := vm.codec.Marshal(utxos[i])
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(exportedBytes, utxoBytes) {
+			return errForgedImport
+		}
+	}
+	return nil
+}
+
+// AtomicRequests returns the requests that remove the imported UTXOs from the
+// memory shared with the source chain. An export tx is removed with the last
+// of the UTXOs it exported. The Platform Chain doesn't put its txs there.
+func (t *ImportTx) AtomicRequests(vm *VM) (map[[32]byte]*atomic.Requests, error) {
+	keys := t.importedKeys()
+	if t.SourceChain.Equals(platformChainID) {
+		return map[[32]byte]*atomic.Requests{
+			t.SourceChain.Key(): {RemoveRequests: keys},
+		}, nil
+	}
+
+	imported := ids.Set{}
+	exportTxIDs := ids.Set{}
+	for _, in := range t.ImportedIns {
+		imported.Add(in.InputID())
+		exportTxIDs.Add(in.TxID)
+	}
+
+	for _, txID := range exportTxIDs.List() {
+		exportTx, err := vm.getExportTx(t.SourceChain, txID)
+		if err != nil {
+			return nil, err
+		}
+		pending := false
+		for _, utxo := range exportTx.ExportedUTXOs() {
+			inputID := utxo.InputID()
+			if imported.Contains(inputID) {
+				continue
+			}
+			_, err := vm.ctx.SharedMemory.Get(t.SourceChain, [][]byte{inputID.Bytes()})
+			if err == nil {
+				pending = true
+				break
+			}
+			if err != database.ErrNotFound {
+				return nil, err
+			}
+		}
+		if !pending {
+			keys = append(keys, txID.Bytes())
+		}
+	}
+	return map[[32]byte]*atomic.Requests{
+		t.SourceChain.Key(): {RemoveRequests: keys},
+	}, nil
+}
+
+// importedKeys returns the keys the imported UTXOs are stored under in shared
+// memory
+func (t *ImportTx) importedKeys() [][]byte {
+	keys := make([][]byte, len(t.ImportedIns))
+	for i, in := range t.ImportedIns {
+		keys[i] = in.InputID().Bytes()
+	}
+	return keys
+}
+
+// getExportTx returns the export tx [txID] that [sourceChain] put in the
+// memory it shares with this chain. Returns an error if the bytes stored under
+// [txID] don't hash to it, or aren't an export from [sourceChain] to this
+// chain.
+func (vm *VM) getExportTx(sourceChain, txID ids.ID) (*ExportTx, error) {
+	txsBytes, err := vm.ctx.SharedMemory.Get(sourceChain, [][]byte{txID.Bytes()})
+	if err != nil {
+		return nil, errMissingExport
+	}
+	tx := &Tx{}
+	if err := vm.codec.Unmarshal(txsBytes[0], tx); err != nil {
+		return nil, errForgedExport
+	}
+	tx.Initialize(txsBytes[0])
+
+	exportTx, ok := tx.UnsignedTx.(*ExportTx)
+	switch {
+	case !ok, !tx.ID().Equals(txID):
+		return nil, errForgedExport
+	case !exportTx.BCID.Equals(sourceChain), !exportTx.DestinationChain.Equals(vm.ctx.ChainID):
+		return nil, errForgedExport
+	}
+	return exportTx, nil
+}
@@ -0,0 +1,559 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/components/mempool"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	peerChainID = ids.NewID([32]byte{6, 7, 8, 9, 10})
+
+	errUnknownChain = errors.New("unknown chain")
+)
+
+// atomicVM returns a VM of the chain [vmChainID] whose database is built on
+// [db], as is [m]
+func atomicVM(t *testing.T, db database.Database, m *atomic.Memory, vmChainID ids.ID, genesisBytes []byte) *VM {
+	vmCtx := snow.DefaultContextTest()
+	vmCtx.NetworkID = networkID
+	vmCtx.ChainID = vmChainID
+	vmCtx.SharedMemory = m.NewSharedMemory(vmChainID)
+	vmCtx.VMLookup = chainVMs{
+		chainID.Key():     ID,
+		peerChainID.Key(): ID,
+	}
+
+	vm := &VM{}
+	err := vm.Initialize(
+		vmCtx,
+		prefixdb.New(vmChainID.Bytes(), db),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.batchTimeout = 0
+	return vm
+}
+
+// chainVMs maps chain IDs to the IDs of the VMs running them
+type chainVMs map[[32]byte]ids.ID
+
+func (vms chainVMs) ChainVM(chainID ids.ID) (ids.ID, error) {
+	if vmID, ok := vms[chainID.Key()]; ok {
+		return vmID, nil
+	}
+	return ids.ID{}, errUnknownChain
+}
+
+// signTx signs every input of [tx] with [key] and parses it with [vm]
+func signTx(t *testing.T, vm *VM, tx *Tx, key *crypto.PrivateKeySECP256K1R) *UniqueTx {
+	unsignedBytes, err := vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := key.Sign(unsignedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+
+	for range tx.InputUTXOs() {
+		tx.Creds = append(tx.Creds, &Credential{
+			Cred: &secp256k1fx.Credential{
+				Sigs: [][crypto.SECP256K1RSigLen]byte{fixedSig},
+			},
+		})
+	}
+
+	b, err := vm.codec.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uTx, err := vm.parseTx(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uTx
+}
+
+func TestExportImport(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+
+	db := memdb.New()
+	m := &atomic.Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), db))
+
+	exporter := atomicVM(t, db, m, chainID, genesisBytes)
+	importer := atomicVM(t, db, m, peerChainID, genesisBytes)
+
+	addr := keys[1].PublicKey().Address()
+	exportTx := signTx(t, exporter, &Tx{UnsignedTx: &ExportTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+			Ins: []*TransferableInput{&TransferableInput{
+				UTXOID: UTXOID{
+					TxID:        assetID,
+					OutputIndex: 1,
+				},
+				Asset: Asset{ID: assetID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   50000,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+		},
+		DestinationChain: peerChainID,
+		ExportedOuts: []*TransferableOutput{&TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: 50000,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}},
+	}}, keys[0])
+
+	if err := exportTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	exportTx.Accept()
+	if status := exportTx.Status(); status != choices.Accepted {
+		t.Fatalf("Export should have been accepted, but is %s", status)
+	}
+	if _, err := exporter.state.UTXO(genesisTx.ID().Prefix(1)); err == nil {
+		t.Fatalf("Exported UTXO should have been spent")
+	}
+
+	exportedUTXOID := UTXOID{TxID: exportTx.ID()}
+	if _, err := m.NewSharedMemory(peerChainID).Get(chainID, [][]byte{exportedUTXOID.InputID().Bytes()}); err != nil {
+		t.Fatalf("Exported UTXO should be in shared memory: %s", err)
+	}
+
+	newImportTx := func(utxoID UTXOID, inAmount, outAmount uint64) *UniqueTx {
+		return signTx(t, importer, &Tx{UnsignedTx: &ImportTx{
+			BaseTx: BaseTx{
+				NetID: networkID,
+				BCID:  peerChainID,
+				Outs: []*TransferableOutput{&TransferableOutput{
+					Asset: Asset{ID: assetID},
+					Out: &secp256k1fx.TransferOutput{
+						Amt: outAmount,
+						OutputOwners: secp256k1fx.OutputOwners{
+							Threshold: 1,
+							Addrs:     []ids.ShortID{addr},
+						},
+					},
+				}},
+			},
+			SourceChain: chainID,
+			ImportedIns: []*TransferableInput{&TransferableInput{
+				UTXOID: utxoID,
+				Asset:  Asset{ID: assetID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   inAmount,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+		}}, keys[1])
+	}
+
+	// Forge UTXOs of the export tx in shared memory. Putting them through
+	// shared memory updates its root, so the forged contents are consistent
+	// with the root the importer's shared memory reports.
+	forge := func(utxoID UTXOID, amount uint64) *atomic.Element {
+		utxoBytes, err := exporter.codec.Marshal(&UTXO{
+			UTXOID: utxoID,
+			Asset:  Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &atomic.Element{Key: utxoID.InputID().Bytes(), Value: utxoBytes}
+	}
+	exporterMemory := m.NewSharedMemory(chainID)
+	importerMemory := m.NewSharedMemory(peerChainID)
+
+	// A UTXO the export tx never exported isn't imported
+	extraUTXOID := UTXOID{TxID: exportTx.ID(), OutputIndex: 1}
+	if err := exporterMemory.Put(peerChainID, []*atomic.Element{forge(extraUTXOID, 100000)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := newImportTx(extraUTXOID, 100000, 100000).Verify(); err == nil {
+		t.Fatalf("Shouldn't import a UTXO that the export tx didn't export")
+	}
+	if err := importerMemory.Remove(chainID, [][]byte{extraUTXOID.InputID().Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An exported UTXO that was replaced with a larger one isn't imported
+	exportedKey := exportedUTXOID.InputID().Bytes()
+	exported, err := importerMemory.Get(chainID, [][]byte{exportedKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := importerMemory.Remove(chainID, [][]byte{exportedKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporterMemory.Put(peerChainID, []*atomic.Element{forge(exportedUTXOID, 100000)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := newImportTx(exportedUTXOID, 100000, 100000).Verify(); err == nil {
+		t.Fatalf("Shouldn't import a UTXO that differs from the one the export tx exported")
+	}
+	if err := importerMemory.Remove(chainID, [][]byte{exportedKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporterMemory.Put(peerChainID, []*atomic.Element{&atomic.Element{Key: exportedKey, Value: exported[0]}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An export tx that was replaced with a different one isn't trusted
+	exportTxKey := exportTx.ID().Bytes()
+	if err := importerMemory.Remove(chainID, [][]byte{exportTxKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporterMemory.Put(peerChainID, []*atomic.Element{&atomic.Element{Key: exportTxKey, Value: genesisTx.Bytes()}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := newImportTx(exportedUTXOID, 50000, 45000).Verify(); err == nil {
+		t.Fatalf("Shouldn't import a UTXO whose export tx doesn't hash to its ID")
+	}
+	if err := importerMemory.Remove(chainID, [][]byte{exportTxKey}); err != nil {
+		t.Fatal(err)
+	}
+	if err := exporterMemory.Put(peerChainID, []*atomic.Element{&atomic.Element{Key: exportTxKey, Value: exportTx.Bytes()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	importTx := newImportTx(exportedUTXOID, 50000, 50000)
+	if deps := importTx.Dependencies(); len(deps) != 1 || !deps[0].ID().Equals(assetID) {
+		t.Fatalf("Import should only depend on the asset")
+	}
+	if err := importTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	importTx.Accept()
+	if status := importTx.Status(); status != choices.Accepted {
+		t.Fatalf("Import should have been accepted, but is %s", status)
+	}
+
+	if _, err := m.NewSharedMemory(peerChainID).Get(chainID, [][]byte{exportedUTXOID.InputID().Bytes()}); err == nil {
+		t.Fatalf("Imported UTXO should have been removed from shared memory")
+	}
+	if _, err := importerMemory.Get(chainID, [][]byte{exportTxKey}); err == nil {
+		t.Fatalf("Export tx should have been removed with the last UTXO it exported")
+	}
+	addrs := ids.Set{}
+	addrs.Add(ids.NewID(hashing.ComputeHash256Array(addr.Bytes())))
+	utxos, err := importer.GetUTXOs(addrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funded := false
+	for _, utxo := range utxos {
+		funded = funded || utxo.TxID.Equals(importTx.ID())
+	}
+	if !funded {
+		t.Fatalf("Should have funded the output of the import")
+	}
+
+	// The UTXO was already imported, so importing it again should fail
+	reimportTx := newImportTx(exportedUTXOID, 50000, 40000)
+	if err := reimportTx.Verify(); err == nil {
+		t.Fatalf("Shouldn't be able to import the same UTXO twice")
+	}
+}
+
+func TestAtomicTxFees(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+
+	db := memdb.New()
+	m := &atomic.Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), db))
+
+	exporter := atomicVM(t, db, m, chainID, genesisBytes)
+	importer := atomicVM(t, db, m, peerChainID, genesisBytes)
+	for _, vm := range []*VM{exporter, importer} {
+		vm.feeAssetID = assetID
+		vm.mempool.Initialize(logging.NoLog{}, mempool.Config{MaxTxs: 1}, "", nil)
+	}
+
+	addr := keys[1].PublicKey().Address()
+	output := func(amount uint64) *TransferableOutput {
+		return &TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{addr},
+				},
+			},
+		}
+	}
+	input := func(utxoID UTXOID, amount uint64) *TransferableInput {
+		return &TransferableInput{
+			UTXOID: utxoID,
+			Asset:  Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   amount,
+				Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+			},
+		}
+	}
+	genesisUTXO := func(index uint32) UTXOID { return UTXOID{TxID: assetID, OutputIndex: index} }
+
+	// A tx that burns 2000 fills the exporter's mempool
+	baseTx := signTx(t, exporter, &Tx{UnsignedTx: &BaseTx{
+		NetID: networkID,
+		BCID:  chainID,
+		Ins:   []*TransferableInput{input(genesisUTXO(0), 50000)},
+		Outs:  []*TransferableOutput{output(48000)},
+	}}, keys[0])
+	if err := exporter.issueTx(baseTx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The export only burns what it neither outputs nor exports, so it can't
+	// evict the tx that burns more
+	exportTx := signTx(t, exporter, &Tx{UnsignedTx: &ExportTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  chainID,
+			Ins:   []*TransferableInput{input(genesisUTXO(1), 50000)},
+		},
+		DestinationChain: peerChainID,
+		ExportedOuts:     []*TransferableOutput{output(49000)},
+	}}, keys[0])
+	if fee := exporter.txFee(exportTx); fee != 1000 {
+		t.Fatalf("Export should burn 1000, but burns %d", fee)
+	}
+	if err := exporter.issueTx(exportTx); err == nil {
+		t.Fatalf("Export shouldn't have evicted a tx that burns more")
+	}
+	if !exporter.mempool.Has(baseTx.ID()) {
+		t.Fatalf("Tx that burns more should still be in the mempool")
+	}
+
+	if err := exportTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	exportTx.Accept()
+
+	// A tx that burns 500 fills the importer's mempool
+	importerBaseTx := signTx(t, importer, &Tx{UnsignedTx: &BaseTx{
+		NetID: networkID,
+		BCID:  peerChainID,
+		Ins:   []*TransferableInput{input(genesisUTXO(0), 50000)},
+		Outs:  []*TransferableOutput{output(49500)},
+	}}, keys[0])
+	if err := importer.issueTx(importerBaseTx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The import burns what it imports but doesn't output, so it evicts the
+	// tx that burns less
+	importTx := signTx(t, importer, &Tx{UnsignedTx: &ImportTx{
+		BaseTx: BaseTx{
+			NetID: networkID,
+			BCID:  peerChainID,
+			Outs:  []*TransferableOutput{output(48000)},
+		},
+		SourceChain: chainID,
+		ImportedIns: []*TransferableInput{input(UTXOID{TxID: exportTx.ID()}, 49000)},
+	}}, keys[1])
+	if fee := importer.txFee(importTx); fee != 1000 {
+		t.Fatalf("Import should burn 1000, but burns %d", fee)
+	}
+	if err := importer.issueTx(importTx); err != nil {
+		t.Fatal(err)
+	}
+	if importer.mempool.Has(importerBaseTx.ID()) {
+		t.Fatalf("Import should have evicted the tx that burns less")
+	}
+	if !importer.mempool.Has(importTx.ID()) {
+		t.Fatalf("Import should be in the mempool")
+	}
+
+	// Reading the inputs of the import doesn't change it
+	importTx.t.tx.InputUTXOs()
+	if unsigned := importTx.t.tx.UnsignedTx.(*ImportTx); unsigned.ImportedIns[0].Symbol {
+		t.Fatalf("Listing the consumed UTXOs shouldn't mark the import's inputs")
+	}
+}
+
+func TestPlatformChainTransfers(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	assetID := genesisTx.ID()
+
+	db := memdb.New()
+	m := &atomic.Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), db))
+	platformMemory := m.NewSharedMemory(platformChainID)
+
+	vm := atomicVM(t, db, m, chainID, genesisBytes)
+	vm.feeAssetID = assetID
+
+	addr := keys[1].PublicKey().Address()
+	newExportTx := func(index uint32, addrs ...ids.ShortID) *UniqueTx {
+		return signTx(t, vm, &Tx{UnsignedTx: &ExportTx{
+			BaseTx: BaseTx{
+				NetID: networkID,
+				BCID:  chainID,
+				Ins: []*TransferableInput{&TransferableInput{
+					UTXOID: UTXOID{
+						TxID:        assetID,
+						OutputIndex: index,
+					},
+					Asset: Asset{ID: assetID},
+					In: &secp256k1fx.TransferInput{
+						Amt:   50000,
+						Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+					},
+				}},
+			},
+			DestinationChain: platformChainID,
+			ExportedOuts: []*TransferableOutput{&TransferableOutput{
+				Asset: Asset{ID: assetID},
+				Out: &secp256k1fx.TransferOutput{
+					Amt: 50000,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     addrs,
+					},
+				},
+			}},
+		}}, keys[0])
+	}
+
+	// An account is controlled by a single key
+	if err := newExportTx(0, addr, keys[2].PublicKey().Address()).Verify(); err != errNotTransferable {
+		t.Fatalf("Shouldn't export an output that multiple keys can spend to the Platform Chain, but got %v", err)
+	}
+
+	exportTx := newExportTx(1, addr)
+	if err := exportTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	exportTx.Accept()
+
+	exportedUTXOID := UTXOID{TxID: exportTx.ID()}
+	exported, err := platformMemory.Get(chainID, [][]byte{exportedUTXOID.InputID().Bytes()})
+	if err != nil {
+		t.Fatalf("Exported UTXO should be in shared memory: %s", err)
+	}
+	transfer, err := atomic.ParseTransfer(exportedUTXOID.InputID(), exported[0])
+	if err != nil {
+		t.Fatalf("Exported UTXO should be a transfer: %s", err)
+	}
+	if transfer.Amount != 50000 || !transfer.To.Equals(addr) {
+		t.Fatalf("Exported the wrong transfer: %+v", transfer)
+	}
+	if _, err := platformMemory.Get(chainID, [][]byte{exportTx.ID().Bytes()}); err == nil {
+		t.Fatalf("Export tx shouldn't be put in the memory shared with the Platform Chain")
+	}
+
+	// The Platform Chain exports a transfer to this chain
+	imported := &atomic.Transfer{
+		SourceTx: ids.NewID([32]byte{1, 2, 3}),
+		Amount:   30000,
+		To:       addr,
+	}
+	importedBytes, err := imported.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	importedUTXOID := UTXOID{TxID: imported.SourceTx}
+	newImportTx := func(utxoID UTXOID) *UniqueTx {
+		return signTx(t, vm, &Tx{UnsignedTx: &ImportTx{
+			BaseTx: BaseTx{
+				NetID: networkID,
+				BCID:  chainID,
+				Outs: []*TransferableOutput{&TransferableOutput{
+					Asset: Asset{ID: assetID},
+					Out: &secp256k1fx.TransferOutput{
+						Amt: 30000,
+						OutputOwners: secp256k1fx.OutputOwners{
+							Threshold: 1,
+							Addrs:     []ids.ShortID{addr},
+						},
+					},
+				}},
+			},
+			SourceChain: platformChainID,
+			ImportedIns: []*TransferableInput{&TransferableInput{
+				UTXOID: utxoID,
+				Asset:  Asset{ID: assetID},
+				In: &secp256k1fx.TransferInput{
+					Amt:   30000,
+					Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+				},
+			}},
+		}}, keys[1])
+	}
+
+	// A transfer that isn't stored under its ID isn't imported
+	forgedUTXOID := UTXOID{TxID: imported.SourceTx, OutputIndex: 1}
+	if err := platformMemory.Put(chainID, []*atomic.Element{&atomic.Element{Key: forgedUTXOID.InputID().Bytes(), Value: importedBytes}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := newImportTx(forgedUTXOID).Verify(); err == nil {
+		t.Fatalf("Shouldn't import a transfer stored under another ID")
+	}
+
+	if err := platformMemory.Put(chainID, []*atomic.Element{&atomic.Element{Key: importedUTXOID.InputID().Bytes(), Value: importedBytes}}); err != nil {
+		t.Fatal(err)
+	}
+	importTx := newImportTx(importedUTXOID)
+	if err := importTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	importTx.Accept()
+	if status := importTx.Status(); status != choices.Accepted {
+		t.Fatalf("Import should have been accepted, but is %s", status)
+	}
+	if exported, err := platformMemory.Exported(chainID, [][]byte{importedUTXOID.InputID().Bytes()}); err != nil {
+		t.Fatal(err)
+	} else if exported[0] {
+		t.Fatalf("Imported transfer should have been removed from shared memory")
+	}
+}
@@ -4,7 +4,10 @@
 package avm
 
 import (
+	"math/big"
+
 	"github.com/ava-labs/gecko/cache"
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/hashing"
@@ -16,10 +19,19 @@ const (
 	txStatusID
 	fundsID
 	dbInitializedID
+	acceptedTxID
+	numAcceptedTxsID
+	assetMetadataID
+	addressTxID
+	numAddressTxsID
+	balanceID
+	addressIndexInitializedID
 )
 
 var (
-	dbInitialized = ids.Empty.Prefix(dbInitializedID)
+	dbInitialized           = ids.Empty.Prefix(dbInitializedID)
+	numAcceptedTxs          = ids.Empty.Prefix(numAcceptedTxsID)
+	addressIndexInitialized = ids.Empty.Prefix(addressIndexInitializedID)
 )
 
 // prefixedState wraps a state object. By prefixing the state, there will be no
@@ -44,6 +56,9 @@ func (s *prefixedState) SetTx(id ids.ID, tx *Tx) error {
 	return s.state.SetTx(s.uniqueID(id, txID, s.tx), tx)
 }
 
+// TxKey returns the key of the database that the transaction [id] is stored at
+func (s *prefixedState) TxKey(id ids.ID) []byte { return s.uniqueID(id, txID, s.tx).Bytes() }
+
 // UTXO attempts to load a utxo from storage.
 func (s *prefixedState) UTXO(id ids.ID) (*UTXO, error) {
 	return s.state.UTXO(s.uniqueID(id, utxoID, s.utxo))
@@ -84,6 +99,109 @@ func (s *prefixedState) SetFunds(id ids.ID, idSlice []ids.ID) error {
 	return s.state.SetIDs(s.uniqueID(id, fundsID, s.funds), idSlice)
 }
 
+// NumAcceptedTxs returns the number of transactions that have been indexed in
+// the order they were accepted.
+func (s *prefixedState) NumAcceptedTxs() (uint64, error) {
+	numAccepted, err := s.state.Uint64(numAcceptedTxs)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return numAccepted, err
+}
+
+// AcceptedTxID returns the ID of the transaction that was accepted at [index].
+// The genesis transactions are at the first indices.
+func (s *prefixedState) AcceptedTxID(index uint64) (ids.ID, error) {
+	return s.state.ID(ids.Empty.Prefix(acceptedTxID, index))
+}
+
+// AddAcceptedTx indexes [txID] as the most recently accepted transaction.
+func (s *prefixedState) AddAcceptedTx(txID ids.ID) error {
+	index, err := s.NumAcceptedTxs()
+	if err != nil {
+		return err
+	}
+	if err := s.state.SetID(ids.Empty.Prefix(acceptedTxID, index), txID); err != nil {
+		return err
+	}
+	return s.state.SetUint64(numAcceptedTxs, index+1)
+}
+
+// AssetMetadataTxID returns the ID of the transaction that registered the
+// current metadata of [assetID].
+func (s *prefixedState) AssetMetadataTxID(assetID ids.ID) (ids.ID, error) {
+	return s.state.ID(assetID.Prefix(assetMetadataID))
+}
+
+// SetAssetMetadataTxID saves [txID] as the transaction that registered the
+// current metadata of [assetID].
+func (s *prefixedState) SetAssetMetadataTxID(assetID, txID ids.ID) error {
+	return s.state.SetID(assetID.Prefix(assetMetadataID), txID)
+}
+
+// AddressIndexInitialized returns the status of the address index. If the
+// index hasn't been built, the status will be unknown.
+func (s *prefixedState) AddressIndexInitialized() (choices.Status, error) {
+	return s.state.Status(addressIndexInitialized)
+}
+
+// SetAddressIndexInitialized saves the provided status of the address index.
+func (s *prefixedState) SetAddressIndexInitialized(status choices.Status) error {
+	return s.state.SetStatus(addressIndexInitialized, status)
+}
+
+// NumAddressTxs returns the number of accepted transactions that the 32 byte
+// representation of an address is referenced in.
+func (s *prefixedState) NumAddressTxs(addr ids.ID) (uint64, error) {
+	numTxs, err := s.state.Uint64(addr.Prefix(numAddressTxsID))
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return numTxs, err
+}
+
+// AddressTxID returns the ID of the [index]th accepted transaction that the
+// address is referenced in.
+func (s *prefixedState) AddressTxID(addr ids.ID, index uint64) (ids.ID, error) {
+	return s.state.ID(addr.Prefix(addressTxID, index))
+}
+
+// AddAddressTx indexes [txID] as the most recently accepted transaction that
+// the address is referenced in.
+func (s *prefixedState) AddAddressTx(addr, txID ids.ID) error {
+	index, err := s.NumAddressTxs(addr)
+	if err != nil {
+		return err
+	}
+	if err := s.state.SetID(addr.Prefix(addressTxID, index), txID); err != nil {
+		return err
+	}
+	return s.state.SetUint64(addr.Prefix(numAddressTxsID), index+1)
+}
+
+// Balance returns the amount of [assetID] held in the transferable utxos that
+// the address is referenced in.
+func (s *prefixedState) Balance(addr, assetID ids.ID) (*big.Int, error) {
+	balance, err := s.state.BigInt(balanceKey(addr, assetID))
+	if err == database.ErrNotFound {
+		return new(big.Int), nil
+	}
+	return balance, err
+}
+
+// SetBalance saves the amount of [assetID] the address holds.
+func (s *prefixedState) SetBalance(addr, assetID ids.ID, balance *big.Int) error {
+	return s.state.SetBigInt(balanceKey(addr, assetID), balance)
+}
+
+// balanceKey returns the key of the balance of [assetID] of the address
+func balanceKey(addr, assetID ids.ID) ids.ID {
+	key := make([]byte, 0, 2*hashing.HashLen)
+	key = append(key, addr.Bytes()...)
+	key = append(key, assetID.Bytes()...)
+	return ids.NewID(hashing.ComputeHash256Array(key)).Prefix(balanceID)
+}
+
 func (s *prefixedState) uniqueID(id ids.ID, prefix uint64, cacher cache.Cacher) ids.ID {
 	if cachedIDIntf, found := cacher.Get(id); found {
 		return cachedIDIntf.(ids.ID)
@@ -108,6 +226,9 @@ func (s *prefixedState) SpendUTXO(utxoID ids.ID) error {
 		return nil
 	}
 
+	if err := s.updateBalances(addressable.Addresses(), utxo, true); err != nil {
+		return err
+	}
 	return s.removeUTXO(addressable.Addresses(), utxoID)
 }
 
@@ -137,6 +258,9 @@ func (s *prefixedState) FundUTXO(utxo *UTXO) error {
 		return nil
 	}
 
+	if err := s.updateBalances(addressable.Addresses(), utxo, false); err != nil {
+		return err
+	}
 	return s.addUTXO(addressable.Addresses(), utxoID)
 }
 
@@ -153,3 +277,30 @@ func (s *prefixedState) addUTXO(addrs [][]byte, utxoID ids.ID) error {
 	}
 	return nil
 }
+
+// updateBalances adds the amount of [utxo], if it's transferable, to the
+// balance of each of [addrs], or subtracts it if the utxo is [spent].
+func (s *prefixedState) updateBalances(addrs [][]byte, utxo *UTXO, spent bool) error {
+	transferable, ok := utxo.Out.(FxTransferable)
+	if !ok {
+		return nil
+	}
+	amount := new(big.Int).SetUint64(transferable.Amount())
+	assetID := utxo.AssetID()
+	for _, addr := range addrs {
+		addrID := ids.NewID(hashing.ComputeHash256Array(addr))
+		balance, err := s.Balance(addrID, assetID)
+		if err != nil {
+			return err
+		}
+		if spent {
+			balance.Sub(balance, amount)
+		} else {
+			balance.Add(balance, amount)
+		}
+		if err := s.SetBalance(addrID, assetID, balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
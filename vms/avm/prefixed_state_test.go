@@ -6,6 +6,7 @@ package avm
 import (
 	"testing"
 
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/crypto"
@@ -137,11 +138,27 @@ func TestPrefixedFundingNoAddresses(t *testing.T) {
 	}
 }
 
+// numKeys returns the number of keys in [db]
+func numKeys(t *testing.T, db database.Iteratee) int {
+	it := db.NewIterator()
+	defer it.Release()
+
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
 func TestPrefixedFundingAddresses(t *testing.T) {
 	vm := GenesisVM(t)
 	state := vm.state
 
 	vm.codec.RegisterType(&testAddressable{})
+	keysBefore := numKeys(t, vm.db)
 
 	utxo := &UTXO{
 		UTXOID: UTXOID{
@@ -176,4 +193,8 @@ func TestPrefixedFundingAddresses(t *testing.T) {
 	if err == nil {
 		t.Fatalf("Should have returned no utxoIDs")
 	}
+	// Spent utxos don't leave index entries behind for pruning to delete
+	if keysAfter := numKeys(t, vm.db); keysAfter != keysBefore {
+		t.Fatalf("Spending the utxo left %d keys behind", keysAfter-keysBefore)
+	}
 }
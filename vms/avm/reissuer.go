@@ -0,0 +1,165 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"time"
+
+	"github.com/ava-labs/gecko/cache"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+const (
+	// Time a tx can go without being issued into consensus before it's
+	// considered dropped
+	reissueTimeout = 30 * time.Second
+
+	reissuerName = "avm reissuer"
+)
+
+// reissuer re-issues txs that were issued to this node, but that were dropped
+// before entering consensus. A tx can be dropped because it conflicted with a
+// processing tx when it was batched into a vertex, because it failed
+// verification due to a race with its dependencies, or because it was evicted
+// from the mempool. A dropped tx is re-issued at most [limit] times, as long as
+// it still verifies. Once a tx is given up on, its status is reset and its
+// issuer is notified that it was rejected.
+//
+// All the methods of the reissuer must be called with the context lock held.
+type reissuer struct {
+	vm    *VM
+	limit int
+
+	// txID --> number of times the tx has been re-issued, for txs that are
+	// waiting to enter consensus
+	attempts map[[32]byte]int
+
+	// txID --> number of times the tx was re-issued before being given up on
+	dropped cache.Cacher
+
+	timeouts timer.TimeoutManager
+}
+
+func (r *reissuer) Initialize(vm *VM, limit int) error {
+	r.vm = vm
+	r.limit = limit
+	r.attempts = make(map[[32]byte]int)
+	r.dropped = &cache.LRU{Size: idCacheSize}
+	r.timeouts.Initialize(reissueTimeout)
+	go vm.ctx.Log.RecoverAndPanic(r.timeouts.Dispatch)
+
+	if limit <= 0 {
+		return nil
+	}
+	return vm.ctx.DecisionDispatcher.RegisterChain(vm.ctx.ChainID, reissuerName, r)
+}
+
+func (r *reissuer) Shutdown() {
+	r.timeouts.Stop()
+	if r.limit <= 0 {
+		return
+	}
+	if err := r.vm.ctx.DecisionDispatcher.DeregisterChain(r.vm.ctx.ChainID, reissuerName); err != nil {
+		r.vm.ctx.Log.Error("Deregistering the reissuer failed with %s", err)
+	}
+}
+
+// Track starts tracking [txID], which was just issued to this node. Does
+// nothing if re-issuance is disabled.
+func (r *reissuer) Track(txID ids.ID) {
+	if r.limit <= 0 {
+		return
+	}
+	r.attempts[txID.Key()] = 0
+	r.setTimeout(txID)
+}
+
+// Issue implements the triggers.Issuer interface. It's called when a tx enters
+// consensus, at which point consensus is responsible for deciding it.
+func (r *reissuer) Issue(_, txID ids.ID, _ []byte) error {
+	key := txID.Key()
+	if _, tracked := r.attempts[key]; tracked {
+		delete(r.attempts, key)
+		r.timeouts.Remove(txID)
+	}
+	return nil
+}
+
+// Evicted is called when [txID] is evicted from the mempool. A tracked tx is
+// re-issued when it times out. Any other tx is dropped.
+func (r *reissuer) Evicted(txID ids.ID) {
+	if _, tracked := r.attempts[txID.Key()]; tracked {
+		return
+	}
+	r.drop(txID, 0, "it was evicted from the mempool")
+}
+
+// Reissues returns the number of times [txID] has been re-issued, and whether
+// it was dropped after exhausting its re-issuances or failing verification
+func (r *reissuer) Reissues(txID ids.ID) (int, bool) {
+	if attempts, tracked := r.attempts[txID.Key()]; tracked {
+		return attempts, false
+	}
+	if attempts, dropped := r.dropped.Get(txID); dropped {
+		return attempts.(int), true
+	}
+	return 0, false
+}
+
+func (r *reissuer) setTimeout(txID ids.ID) {
+	r.timeouts.Put(txID, func() {
+		r.vm.ctx.Lock.Lock()
+		defer r.vm.ctx.Lock.Unlock()
+
+		r.reissue(txID)
+	})
+}
+
+// reissue [txID], which didn't enter consensus in time
+func (r *reissuer) reissue(txID ids.ID) {
+	key := txID.Key()
+	attempts, tracked := r.attempts[key]
+	if !tracked {
+		return
+	}
+
+	tx := &UniqueTx{
+		vm:   r.vm,
+		txID: txID,
+	}
+	if tx.Status().Decided() {
+		delete(r.attempts, key)
+		return
+	}
+	if r.vm.mempool.Has(txID) {
+		// The tx is still waiting to be sent to consensus
+		r.setTimeout(txID)
+		return
+	}
+
+	if attempts >= r.limit {
+		r.drop(txID, attempts, "it was re-issued too many times")
+		return
+	}
+	if err := tx.reverify(); err != nil {
+		r.drop(txID, attempts, err.Error())
+		return
+	}
+	if err := r.vm.issueTx(tx); err != nil {
+		r.drop(txID, attempts, err.Error())
+		return
+	}
+
+	r.attempts[key] = attempts + 1
+	r.setTimeout(txID)
+	r.vm.ctx.Log.Debug("Re-issued dropped tx %s, attempt %d of %d", txID, attempts+1, r.limit)
+}
+
+func (r *reissuer) drop(txID ids.ID, attempts int, reason string) {
+	delete(r.attempts, txID.Key())
+	r.dropped.Put(txID, attempts)
+	r.vm.dropTx(txID)
+	r.vm.ctx.Log.Debug("Not re-issuing dropped tx %s because %s", txID, reason)
+}
@@ -0,0 +1,234 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/vms/components/mempool"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+func reissueVM(t *testing.T, limit int) (*VM, *Tx) {
+	genesisBytes := BuildGenesisTest(t)
+
+	vm := &VM{ReissueLimit: limit}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.batchTimeout = 0
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	tx := &Tx{UnsignedTx: &OperationTx{BaseTx: BaseTx{
+		NetID: networkID,
+		BCID:  chainID,
+		Ins: []*TransferableInput{
+			&TransferableInput{
+				UTXOID: UTXOID{
+					TxID:        genesisTx.ID(),
+					OutputIndex: 1,
+				},
+				Asset: Asset{ID: genesisTx.ID()},
+				In: &secp256k1fx.TransferInput{
+					Amt: 50000,
+					Input: secp256k1fx.Input{
+						SigIndices: []uint32{0},
+					},
+				},
+			},
+		},
+	}}}
+
+	unsignedBytes, err := vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := keys[0].Sign(unsignedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixedSig := [crypto.SECP256K1RSigLen]byte{}
+	copy(fixedSig[:], sig)
+
+	tx.Creds = append(tx.Creds, &Credential{
+		Cred: &secp256k1fx.Credential{
+			Sigs: [][crypto.SECP256K1RSigLen]byte{fixedSig},
+		},
+	})
+
+	b, err := vm.codec.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Initialize(b)
+	return vm, tx
+}
+
+func TestReissueDroppedTx(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 1)
+	defer vm.Shutdown()
+
+	txID, err := vm.IssueTx(tx.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tx is still in the mempool, so it shouldn't be re-issued
+	vm.reissuer.reissue(txID)
+	if reissues, _ := vm.reissuer.Reissues(txID); reissues != 0 {
+		t.Fatalf("Shouldn't have re-issued a tx that is in the mempool")
+	}
+
+	// The tx is sent to consensus, but never issued into it
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have returned %d tx(s)", 1)
+	}
+	vm.reissuer.reissue(txID)
+	if !vm.mempool.Has(txID) {
+		t.Fatalf("Should have re-issued the dropped tx")
+	}
+
+	service := Service{vm: vm}
+	reply := GetTxStatusReply{}
+	if err := service.GetTxStatus(nil, &GetTxStatusArgs{TxID: txID}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Reissues != 1 || reply.Dropped {
+		t.Fatalf("Wrong re-issuance status: reissues=%d dropped=%v", reply.Reissues, reply.Dropped)
+	}
+
+	// The tx was already re-issued as many times as allowed
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have returned %d tx(s)", 1)
+	}
+	vm.reissuer.reissue(txID)
+	if vm.mempool.Has(txID) {
+		t.Fatalf("Shouldn't have re-issued the tx past the limit")
+	}
+
+	if err := service.GetTxStatus(nil, &GetTxStatusArgs{TxID: txID}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Reissues != 1 || !reply.Dropped {
+		t.Fatalf("Wrong re-issuance status: reissues=%d dropped=%v", reply.Reissues, reply.Dropped)
+	}
+}
+
+func TestReissueStopsOnceIssued(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 1)
+	defer vm.Shutdown()
+
+	txID, err := vm.IssueTx(tx.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have returned %d tx(s)", 1)
+	}
+
+	// Consensus notifies the reissuer once the tx is issued into it
+	ctx.DecisionDispatcher.Issue(ctx.ChainID, txID, tx.Bytes())
+
+	vm.reissuer.reissue(txID)
+	if vm.mempool.Has(txID) {
+		t.Fatalf("Shouldn't have re-issued a tx that entered consensus")
+	}
+	if reissues, dropped := vm.reissuer.Reissues(txID); reissues != 0 || dropped {
+		t.Fatalf("Shouldn't be tracking a tx that entered consensus")
+	}
+}
+
+func TestReissueDisabled(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 0)
+	defer vm.Shutdown()
+
+	txID, err := vm.IssueTx(tx.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have returned %d tx(s)", 1)
+	}
+
+	vm.reissuer.reissue(txID)
+	if vm.mempool.Has(txID) {
+		t.Fatalf("Shouldn't have re-issued the tx when re-issuance is disabled")
+	}
+}
+
+func TestEvictedTxDropped(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 0)
+	defer vm.Shutdown()
+	vm.mempool.Initialize(ctx.Log, mempool.Config{MaxTxs: 1}, "", nil)
+
+	decided := []choices.Status(nil)
+	txID, err := vm.IssueTx(tx.Bytes(), func(status choices.Status) {
+		decided = append(decided, status)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Evicts the oldest of the txs paying no fee
+	otherTx := &snowstorm.TestTx{
+		Identifier: ids.Empty.Prefix(1),
+		Stat:       choices.Processing,
+		Bits:       []byte{1},
+	}
+	if err := vm.issueTx(otherTx); err != nil {
+		t.Fatal(err)
+	}
+	if vm.mempool.Has(txID) {
+		t.Fatalf("Should have evicted the tx")
+	}
+
+	if len(decided) != 1 || decided[0] != choices.Rejected {
+		t.Fatalf("Should have notified the issuer that the tx was rejected")
+	}
+	service := Service{vm: vm}
+	reply := GetTxStatusReply{}
+	if err := service.GetTxStatus(nil, &GetTxStatusArgs{TxID: txID}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Status != choices.Unknown || !reply.Dropped {
+		t.Fatalf("Wrong status of evicted tx: status=%s dropped=%v", reply.Status, reply.Dropped)
+	}
+
+	// The tx can be issued again
+	if _, err := vm.IssueTx(tx.Bytes(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if status := (&UniqueTx{vm: vm, txID: txID}).Status(); status != choices.Processing {
+		t.Fatalf("Status should be %s but is %s", choices.Processing, status)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"sort"
 
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils"
@@ -19,7 +20,22 @@ import (
 	"github.com/ava-labs/gecko/utils/json"
 	"github.com/ava-labs/gecko/utils/math"
 	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/htlcfx"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
+	"github.com/ava-labs/gecko/vms/timelockfx"
+)
+
+const (
+	// MaxConsolidatedUTXOs is the maximum number of UTXOs that ConsolidateUTXOs
+	// merges in one transaction
+	MaxConsolidatedUTXOs = 256
+
+	// maxUTXOsToFetch is the maximum number of UTXOs that GetUTXOs returns
+	maxUTXOsToFetch = 1024
+
+	// maxAddressTxsPageSize is the maximum number of transactions that
+	// GetAddressTxs returns
+	maxAddressTxsPageSize = 1024
 )
 
 var (
@@ -37,6 +53,18 @@ var (
 	errUnknownOutputType         = errors.New("unknown output type")
 	errUnneededAddress           = errors.New("address not required to sign")
 	errUnknownCredentialType     = errors.New("unknown credential type")
+	errNoLocktime                = errors.New("locktime must be positive")
+	errTimelockUnsupported       = errors.New("this chain doesn't support time locked outputs")
+	errHTLCUnsupported           = errors.New("this chain doesn't support hashed timelock contracts")
+	errInvalidHashLock           = errors.New("hash lock must be a SHA-256 hash")
+	errNotHTLC                   = errors.New("utxo isn't a hashed timelock contract")
+	errCantSpendHTLC             = errors.New("user can't sign for the hashed timelock contract")
+	errNothingToConsolidate      = errors.New("address doesn't have at least two UTXOs to consolidate")
+	errNoSpendAddresses          = errors.New("no addresses to spend from were provided")
+	errAlreadySigned             = errors.New("transaction already has credentials")
+	errNoSigner                  = errors.New("no external signer is configured")
+	errTxNotExport               = errors.New("transaction isn't an export transaction")
+	errCantImport                = errors.New("destination chain can't import funds from this chain")
 )
 
 // Service defines the base service for the asset vm
@@ -73,6 +101,12 @@ type GetTxStatusArgs struct {
 // GetTxStatusReply defines the GetTxStatus replies returned from the API
 type GetTxStatusReply struct {
 	Status choices.Status `json:"status"`
+
+	// Number of times the tx was re-issued after being dropped
+	Reissues json.Uint32 `json:"reissues,omitempty"`
+
+	// True if the tx was dropped and won't be re-issued again
+	Dropped bool `json:"dropped,omitempty"`
 }
 
 // GetTxStatus returns the status of the specified transaction
@@ -89,23 +123,160 @@ func (service *Service) GetTxStatus(r *http.Request, args *GetTxStatusArgs, repl
 	}
 
 	reply.Status = tx.Status()
+	reissues, dropped := service.vm.reissuer.Reissues(args.TxID)
+	reply.Reissues = json.Uint32(reissues)
+	reply.Dropped = dropped
+	return nil
+}
+
+// GetTxIDAtIndexArgs are arguments for passing into GetTxIDAtIndex requests
+type GetTxIDAtIndexArgs struct {
+	Index json.Uint64 `json:"index"`
+}
+
+// GetTxIDAtIndexReply defines the GetTxIDAtIndex replies returned from the API
+type GetTxIDAtIndexReply struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// GetTxIDAtIndex returns the ID of the transaction that was accepted at the
+// provided index. The genesis transactions are at the first indices.
+func (service *Service) GetTxIDAtIndex(r *http.Request, args *GetTxIDAtIndexArgs, reply *GetTxIDAtIndexReply) error {
+	service.vm.ctx.Log.Verbo("GetTxIDAtIndex called with %d", args.Index)
+
+	txID, err := service.vm.GetTxIDAtIndex(uint64(args.Index))
+	if err != nil {
+		return fmt.Errorf("couldn't get the tx accepted at index %d: %w", args.Index, err)
+	}
+	reply.TxID = txID
 	return nil
 }
 
-// GetUTXOsArgs are arguments for passing into GetUTXOs requests
+// Index is a position in the UTXOs of a set of addresses, as returned by
+// GetUTXOs
+type Index struct {
+	Address string `json:"address"`
+	UTXO    string `json:"utxo"`
+}
+
+// GetUTXOsArgs are arguments for passing into GetUTXOs requests. GetUTXOs
+// returns at most [Limit] UTXOs, or maxUTXOsToFetch if [Limit] is 0, starting
+// after [StartIndex] if it's set. If [AssetID] is set, only the UTXOs of that
+// asset are returned. GetTimeLockedUTXOs ignores these fields.
 type GetUTXOsArgs struct {
-	Addresses []string `json:"addresses"`
+	Addresses  []string    `json:"addresses"`
+	Encoding   string      `json:"encoding"` // Encoding of the returned UTXOs. CB58 if empty.
+	Limit      json.Uint32 `json:"limit"`
+	StartIndex Index       `json:"startIndex"`
+	AssetID    string      `json:"assetID"`
 }
 
-// GetUTXOsReply defines the GetUTXOs replies returned from the API
+// GetUTXOsReply defines the GetUTXOs replies returned from the API. The next
+// page of UTXOs starts after [EndIndex]. Fewer than the requested number of
+// UTXOs are returned only once there are no more.
 type GetUTXOsReply struct {
-	UTXOs []formatting.CB58 `json:"utxos"`
+	NumFetched json.Uint64               `json:"numFetched"`
+	UTXOs      []formatting.EncodedBytes `json:"utxos"`
+	EndIndex   Index                     `json:"endIndex"`
 }
 
-// GetUTXOs creates an empty account with the name passed in
+// GetUTXOs returns a page of the UTXOs that reference at least one of the
+// provided addresses. UTXOs are ordered by address, then by UTXO ID.
 func (service *Service) GetUTXOs(r *http.Request, args *GetUTXOsArgs, reply *GetUTXOsReply) error {
 	service.vm.ctx.Log.Verbo("GetUTXOs called with %s", args.Addresses)
 
+	encoding, err := formatting.GetEncoding(args.Encoding)
+	if err != nil {
+		return err
+	}
+
+	addrSet := ids.Set{}
+	addrNames := make(map[[32]byte]string, len(args.Addresses)) // hashed address --> address
+	for _, addr := range args.Addresses {
+		addrBytes, err := service.vm.Parse(addr)
+		if err != nil {
+			return err
+		}
+		addrID := ids.NewID(hashing.ComputeHash256Array(addrBytes))
+		addrSet.Add(addrID)
+		addrNames[addrID.Key()] = addr
+	}
+
+	startAddr, startUTXOID := ids.ID{}, ids.ID{}
+	if args.StartIndex.Address != "" {
+		addrBytes, err := service.vm.Parse(args.StartIndex.Address)
+		if err != nil {
+			return fmt.Errorf("couldn't parse start index address: %w", err)
+		}
+		startAddr = ids.NewID(hashing.ComputeHash256Array(addrBytes))
+		if startUTXOID, err = ids.FromString(args.StartIndex.UTXO); err != nil {
+			return fmt.Errorf("couldn't parse start index utxo: %w", err)
+		}
+	}
+
+	assetID := ids.ID{}
+	if args.AssetID != "" {
+		if assetID, err = service.vm.Lookup(args.AssetID); err != nil {
+			if assetID, err = ids.FromString(args.AssetID); err != nil {
+				return err
+			}
+		}
+	}
+
+	limit := int(args.Limit)
+	if limit <= 0 || limit > maxUTXOsToFetch {
+		limit = maxUTXOsToFetch
+	}
+
+	utxos, endAddr, endUTXOID, err := service.vm.GetPaginatedUTXOs(addrSet, startAddr, startUTXOID, assetID, limit)
+	if err != nil {
+		return err
+	}
+
+	reply.NumFetched = json.Uint64(len(utxos))
+	reply.EndIndex = args.StartIndex
+	if !endAddr.Equals(startAddr) || !endUTXOID.Equals(startUTXOID) {
+		reply.EndIndex = Index{
+			Address: addrNames[endAddr.Key()],
+			UTXO:    endUTXOID.String(),
+		}
+	}
+	reply.UTXOs = []formatting.EncodedBytes{}
+	for _, utxo := range utxos {
+		b, err := service.vm.codec.Marshal(utxo)
+		if err != nil {
+			return err
+		}
+		reply.UTXOs = append(reply.UTXOs, formatting.EncodedBytes{Bytes: b, Encoding: encoding})
+	}
+	return nil
+}
+
+// GetTimeLockedUTXOsReply defines the GetTimeLockedUTXOs replies returned from
+// the API
+type GetTimeLockedUTXOsReply struct {
+	UTXOs []TimeLockedUTXO `json:"utxos"`
+}
+
+// TimeLockedUTXO describes a time locked UTXO
+type TimeLockedUTXO struct {
+	UTXO     formatting.EncodedBytes `json:"utxo"`
+	AssetID  ids.ID                  `json:"assetID"`
+	Amount   json.Uint64             `json:"amount"`
+	Locktime json.Uint64             `json:"locktime"`
+	Unlocked bool                    `json:"unlocked"`
+}
+
+// GetTimeLockedUTXOs returns the time locked UTXOs that reference at least one
+// of the provided addresses
+func (service *Service) GetTimeLockedUTXOs(r *http.Request, args *GetUTXOsArgs, reply *GetTimeLockedUTXOsReply) error {
+	service.vm.ctx.Log.Verbo("GetTimeLockedUTXOs called with %s", args.Addresses)
+
+	encoding, err := formatting.GetEncoding(args.Encoding)
+	if err != nil {
+		return err
+	}
+
 	addrSet := ids.Set{}
 	for _, addr := range args.Addresses {
 		addrBytes, err := service.vm.Parse(addr)
@@ -120,13 +291,24 @@ func (service *Service) GetUTXOs(r *http.Request, args *GetUTXOsArgs, reply *Get
 		return err
 	}
 
-	reply.UTXOs = []formatting.CB58{}
+	now := service.vm.clock.Unix()
+	reply.UTXOs = []TimeLockedUTXO{}
 	for _, utxo := range utxos {
+		out, ok := utxo.Out.(*timelockfx.TransferOutput)
+		if !ok {
+			continue
+		}
 		b, err := service.vm.codec.Marshal(utxo)
 		if err != nil {
 			return err
 		}
-		reply.UTXOs = append(reply.UTXOs, formatting.CB58{Bytes: b})
+		reply.UTXOs = append(reply.UTXOs, TimeLockedUTXO{
+			UTXO:     formatting.EncodedBytes{Bytes: b, Encoding: encoding},
+			AssetID:  utxo.AssetID(),
+			Amount:   json.Uint64(out.Amt),
+			Locktime: json.Uint64(out.Locktime),
+			Unlocked: out.Unlocked(now),
+		})
 	}
 	return nil
 }
@@ -176,6 +358,141 @@ func (service *Service) GetAssetDescription(_ *http.Request, args *GetAssetDescr
 	return nil
 }
 
+// GetAssetMetadataArgs are arguments for passing into GetAssetMetadata requests
+type GetAssetMetadataArgs struct {
+	AssetID string `json:"assetID"`
+}
+
+// GetAssetMetadataReply defines the GetAssetMetadata replies returned from the API
+type GetAssetMetadataReply struct {
+	AssetID      ids.ID      `json:"assetID"`
+	Version      json.Uint32 `json:"version"`
+	Name         string      `json:"name"`
+	Symbol       string      `json:"symbol"`
+	Denomination json.Uint8  `json:"denomination"`
+	LogoURI      string      `json:"logoURI"`
+	Issuer       string      `json:"issuer"`
+	TxID         ids.ID      `json:"txID"`
+}
+
+// GetAssetMetadata returns the metadata registered on chain for an asset by
+// one of its issuers
+func (service *Service) GetAssetMetadata(_ *http.Request, args *GetAssetMetadataArgs, reply *GetAssetMetadataReply) error {
+	service.vm.ctx.Log.Verbo("GetAssetMetadata called with %s", args.AssetID)
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := service.vm.getAssetMetadata(assetID)
+	if err != nil {
+		return fmt.Errorf("no metadata registered for asset %s", assetID)
+	}
+	issuer, err := tx.Issuer(service.vm.codec)
+	if err != nil {
+		return err
+	}
+
+	reply.AssetID = assetID
+	reply.Version = json.Uint32(tx.Metadata.Version)
+	reply.Name = tx.Metadata.Name
+	reply.Symbol = tx.Metadata.Symbol
+	reply.Denomination = json.Uint8(tx.Metadata.Denomination)
+	reply.LogoURI = tx.Metadata.LogoURI
+	reply.Issuer = service.vm.Format(issuer.Bytes())
+	reply.TxID = tx.ID()
+	return nil
+}
+
+// SetAssetMetadataArgs are arguments for passing into SetAssetMetadata requests
+type SetAssetMetadataArgs struct {
+	Username     string      `json:"username"`
+	Password     string      `json:"password"`
+	AssetID      string      `json:"assetID"`
+	Version      json.Uint32 `json:"version"`
+	Name         string      `json:"name"`
+	Symbol       string      `json:"symbol"`
+	Denomination json.Uint8  `json:"denomination"`
+	LogoURI      string      `json:"logoURI"`
+
+	// Issuer is the address that signs the metadata. It must have owned one
+	// of the asset's initial outputs.
+	Issuer string `json:"issuer"`
+}
+
+// SetAssetMetadata issues a transaction that registers the metadata of an
+// asset, signed by a key of the user that is an issuer of the asset
+func (service *Service) SetAssetMetadata(_ *http.Request, args *SetAssetMetadataArgs, reply *IssueTxReply) error {
+	service.vm.ctx.Log.Verbo("SetAssetMetadata called with %s", args.AssetID)
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	issuer, err := service.vm.Parse(args.Issuer)
+	if err != nil {
+		return fmt.Errorf("problem parsing issuer address: %w", err)
+	}
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+	user := userState{vm: service.vm}
+	sk, err := user.Key(db, ids.NewID(hashing.ComputeHash256Array(issuer)))
+	if err != nil {
+		return fmt.Errorf("problem retrieving private key: %w", err)
+	}
+
+	metadataTx := &AssetMetadataTx{
+		BaseTx: BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+		},
+		Metadata: AssetMetadata{
+			AssetID:      assetID,
+			Version:      uint32(args.Version),
+			Name:         args.Name,
+			Symbol:       args.Symbol,
+			Denomination: byte(args.Denomination),
+			LogoURI:      args.LogoURI,
+		},
+	}
+	if err := metadataTx.Metadata.Verify(); err != nil {
+		return err
+	}
+
+	metadataBytes, err := service.vm.codec.Marshal(&metadataTx.Metadata)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	sig, err := sk.Sign(metadataBytes)
+	if err != nil {
+		return fmt.Errorf("problem signing metadata: %w", err)
+	}
+	copy(metadataTx.IssuerSig[:], sig)
+
+	txBytes, err := service.vm.codec.Marshal(&Tx{UnsignedTx: metadataTx})
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	txID, err := service.vm.IssueTx(txBytes, nil)
+	if err != nil {
+		return fmt.Errorf("problem issuing transaction: %w", err)
+	}
+	reply.TxID = txID
+	return nil
+}
+
 // GetBalanceArgs are arguments for passing into GetBalance requests
 type GetBalanceArgs struct {
 	Address string `json:"address"`
@@ -184,7 +501,7 @@ type GetBalanceArgs struct {
 
 // GetBalanceReply defines the GetBalance replies returned from the API
 type GetBalanceReply struct {
-	Balance json.Uint64 `json:"balance"`
+	Balance json.Uint256 `json:"balance"`
 }
 
 // GetBalance returns the amount of an asset that an address at least partially owns
@@ -204,27 +521,143 @@ func (service *Service) GetBalance(r *http.Request, args *GetBalanceArgs, reply
 		}
 	}
 
+	// The balance is read from the address index, rather than summed over
+	// the address's utxos
+	balance, err := service.vm.state.Balance(ids.NewID(hashing.ComputeHash256Array(address)), assetID)
+	if err != nil {
+		return err
+	}
+	reply.Balance, err = json.NewUint256FromBig(balance)
+	return err
+}
+
+// GetAddressTxsArgs are arguments for passing into GetAddressTxs requests
+type GetAddressTxsArgs struct {
+	Address string `json:"address"`
+	// Index of the first transaction to return
+	Cursor json.Uint64 `json:"cursor"`
+	// Maximum number of transactions to return. If 0, or more than
+	// maxAddressTxsPageSize, maxAddressTxsPageSize.
+	PageSize json.Uint64 `json:"pageSize"`
+}
+
+// GetAddressTxsReply defines the GetAddressTxs replies returned from the API
+type GetAddressTxsReply struct {
+	TxIDs []ids.ID `json:"txIDs"`
+	// Index of the first transaction of the next page
+	Cursor json.Uint64 `json:"cursor"`
+}
+
+// GetAddressTxs returns a page of the IDs of the accepted transactions that
+// spend or produce a utxo that references the address, in the order they were
+// accepted
+func (service *Service) GetAddressTxs(r *http.Request, args *GetAddressTxsArgs, reply *GetAddressTxsReply) error {
+	service.vm.ctx.Log.Verbo("GetAddressTxs called with address: %s cursor: %d", args.Address, args.Cursor)
+
+	address, err := service.vm.Parse(args.Address)
+	if err != nil {
+		return err
+	}
+	addr := ids.NewID(hashing.ComputeHash256Array(address))
+
+	pageSize := uint64(args.PageSize)
+	if pageSize == 0 || pageSize > maxAddressTxsPageSize {
+		pageSize = maxAddressTxsPageSize
+	}
+	numTxs, err := service.vm.NumAddressTxs(addr)
+	if err != nil {
+		return err
+	}
+
+	reply.TxIDs = []ids.ID{}
+	index := uint64(args.Cursor)
+	for ; index < numTxs && uint64(len(reply.TxIDs)) < pageSize; index++ {
+		txID, err := service.vm.GetAddressTxID(addr, index)
+		if err != nil {
+			return fmt.Errorf("couldn't get the tx of %s at index %d: %w", args.Address, index, err)
+		}
+		reply.TxIDs = append(reply.TxIDs, txID)
+	}
+	reply.Cursor = json.Uint64(index)
+	return nil
+}
+
+// GetAllBalancesArgs are arguments for passing into GetAllBalances requests
+type GetAllBalancesArgs struct {
+	Addresses []string `json:"addresses"`
+}
+
+// GetAllBalancesReply defines the GetAllBalances replies returned from the API
+type GetAllBalancesReply struct {
+	Balances []AssetBalance `json:"balances"`
+}
+
+// AssetBalance is the amount of an asset that a set of addresses at least
+// partially owns. [Locked] is the amount held in time locked outputs that
+// can't be spent yet.
+type AssetBalance struct {
+	AssetID  ids.ID       `json:"assetID"`
+	Balance  json.Uint256 `json:"balance"`
+	Unlocked json.Uint256 `json:"unlocked"`
+	Locked   json.Uint256 `json:"locked"`
+}
+
+// GetAllBalances returns the amount of every asset that the provided addresses
+// at least partially own, sorted by asset ID
+func (service *Service) GetAllBalances(r *http.Request, args *GetAllBalancesArgs, reply *GetAllBalancesReply) error {
+	service.vm.ctx.Log.Verbo("GetAllBalances called with %s", args.Addresses)
+
 	addrSet := ids.Set{}
-	addrSet.Add(ids.NewID(hashing.ComputeHash256Array(address)))
+	for _, addr := range args.Addresses {
+		addrBytes, err := service.vm.Parse(addr)
+		if err != nil {
+			return err
+		}
+		addrSet.Add(ids.NewID(hashing.ComputeHash256Array(addrBytes)))
+	}
 
 	utxos, err := service.vm.GetUTXOs(addrSet)
 	if err != nil {
 		return err
 	}
 
+	now := service.vm.clock.Unix()
+	assetIDs := []ids.ID{}
+	balances := make(map[[32]byte]*AssetBalance)
 	for _, utxo := range utxos {
-		if utxo.AssetID().Equals(assetID) {
-			transferable, ok := utxo.Out.(FxTransferable)
-			if !ok {
-				continue
-			}
-			amt, err := math.Add64(transferable.Amount(), uint64(reply.Balance))
-			if err != nil {
-				return err
-			}
-			reply.Balance = json.Uint64(amt)
+		transferable, ok := utxo.Out.(FxTransferable)
+		if !ok {
+			continue
+		}
+
+		assetID := utxo.AssetID()
+		balance, ok := balances[assetID.Key()]
+		if !ok {
+			balance = &AssetBalance{AssetID: assetID}
+			balances[assetID.Key()] = balance
+			assetIDs = append(assetIDs, assetID)
+		}
+
+		// The sums may exceed 64 bits for assets with a huge supply
+		amt := transferable.Amount()
+		balance.Balance, err = balance.Balance.Add64(amt)
+		if err != nil {
+			return err
+		}
+
+		// Can't overflow as the total didn't
+		if out, ok := utxo.Out.(*timelockfx.TransferOutput); ok && !out.Unlocked(now) {
+			balance.Locked, _ = balance.Locked.Add64(amt)
+		} else {
+			balance.Unlocked, _ = balance.Unlocked.Add64(amt)
 		}
 	}
+
+	ids.SortIDs(assetIDs)
+	reply.Balances = make([]AssetBalance, len(assetIDs))
+	for i, assetID := range assetIDs {
+		reply.Balances[i] = *balances[assetID.Key()]
+	}
 	return nil
 }
 
@@ -405,11 +838,13 @@ func (service *Service) CreateVariableCapAsset(r *http.Request, args *CreateVari
 type CreateAddressArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	KeyType  string `json:"keyType"` // secp256k1 if empty
 }
 
 // CreateAddressReply define the reply from a CreateAddress call
 type CreateAddressReply struct {
-	Address string `json:"address"`
+	Address       string `json:"address"`
+	Bech32Address string `json:"bech32Address"`
 }
 
 // CreateAddress creates an address for the user [args.Username]
@@ -421,28 +856,33 @@ func (service *Service) CreateAddress(r *http.Request, args *CreateAddressArgs,
 		return fmt.Errorf("problem retrieving user: %w", err)
 	}
 
+	keyDB, factory, err := keyStore(db, args.KeyType)
+	if err != nil {
+		return err
+	}
+
 	user := userState{vm: service.vm}
 
-	factory := crypto.FactorySECP256K1R{}
-	skIntf, err := factory.NewPrivateKey()
+	sk, err := factory.NewPrivateKey()
 	if err != nil {
 		return fmt.Errorf("problem generating private key: %w", err)
 	}
-	sk := skIntf.(*crypto.PrivateKeySECP256K1R)
 
-	if err := user.SetKey(db, sk); err != nil {
+	if err := user.SetKey(keyDB, sk); err != nil {
 		return fmt.Errorf("problem saving private key: %w", err)
 	}
 
-	addresses, _ := user.Addresses(db)
+	addresses, _ := user.Addresses(keyDB)
 	addresses = append(addresses, ids.NewID(hashing.ComputeHash256Array(sk.PublicKey().Address().Bytes())))
 
-	if err := user.SetAddresses(db, addresses); err != nil {
+	if err := user.SetAddresses(keyDB, addresses); err != nil {
 		return fmt.Errorf("problem saving address: %w", err)
 	}
 
-	reply.Address = service.vm.Format(sk.PublicKey().Address().Bytes())
-	return nil
+	addr := sk.PublicKey().Address().Bytes()
+	reply.Address = service.vm.Format(addr)
+	reply.Bech32Address, err = service.vm.FormatBech32(addr)
+	return err
 }
 
 // ExportKeyArgs are arguments for ExportKey
@@ -450,6 +890,7 @@ type ExportKeyArgs struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Address  string `json:"address"`
+	KeyType  string `json:"keyType"` // secp256k1 if empty
 }
 
 // ExportKeyReply is the response for ExportKey
@@ -472,9 +913,14 @@ func (service *Service) ExportKey(r *http.Request, args *ExportKeyArgs, reply *E
 		return fmt.Errorf("problem retrieving user: %w", err)
 	}
 
+	keyDB, factory, err := keyStore(db, args.KeyType)
+	if err != nil {
+		return err
+	}
+
 	user := userState{vm: service.vm}
 
-	sk, err := user.Key(db, ids.NewID(hashing.ComputeHash256Array(address)))
+	sk, err := user.FactoryKey(keyDB, factory, ids.NewID(hashing.ComputeHash256Array(address)))
 	if err != nil {
 		return fmt.Errorf("problem retrieving private key: %w", err)
 	}
@@ -488,12 +934,14 @@ type ImportKeyArgs struct {
 	Username   string          `json:"username"`
 	Password   string          `json:"password"`
 	PrivateKey formatting.CB58 `json:"privateKey"`
+	KeyType    string          `json:"keyType"` // secp256k1 if empty
 }
 
 // ImportKeyReply is the response for ImportKey
 type ImportKeyReply struct {
 	// The address controlled by the PrivateKey provided in the arguments
-	Address string `json:"address"`
+	Address       string `json:"address"`
+	Bech32Address string `json:"bech32Address"`
 }
 
 // ImportKey adds a private key to the provided user
@@ -505,28 +953,33 @@ func (service *Service) ImportKey(r *http.Request, args *ImportKeyArgs, reply *I
 		return fmt.Errorf("problem retrieving data: %w", err)
 	}
 
+	keyDB, factory, err := keyStore(db, args.KeyType)
+	if err != nil {
+		return err
+	}
+
 	user := userState{vm: service.vm}
 
-	factory := crypto.FactorySECP256K1R{}
-	skIntf, err := factory.ToPrivateKey(args.PrivateKey.Bytes)
+	sk, err := factory.ToPrivateKey(args.PrivateKey.Bytes)
 	if err != nil {
 		return fmt.Errorf("problem parsing private key %s: %w", args.PrivateKey, err)
 	}
-	sk := skIntf.(*crypto.PrivateKeySECP256K1R)
 
-	if err := user.SetKey(db, sk); err != nil {
+	if err := user.SetKey(keyDB, sk); err != nil {
 		return fmt.Errorf("problem saving key %w", err)
 	}
 
-	addresses, _ := user.Addresses(db)
+	addresses, _ := user.Addresses(keyDB)
 	addresses = append(addresses, ids.NewID(hashing.ComputeHash256Array(sk.PublicKey().Address().Bytes())))
 
-	if err := user.SetAddresses(db, addresses); err != nil {
+	if err := user.SetAddresses(keyDB, addresses); err != nil {
 		return fmt.Errorf("problem saving addresses: %w", err)
 	}
 
-	reply.Address = service.vm.Format(sk.PublicKey().Address().Bytes())
-	return nil
+	addr := sk.PublicKey().Address().Bytes()
+	reply.Address = service.vm.Format(addr)
+	reply.Bech32Address, err = service.vm.FormatBech32(addr)
+	return err
 }
 
 // SendArgs are arguments for passing into Send requests
@@ -547,41 +1000,159 @@ type SendReply struct {
 func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply) error {
 	service.vm.ctx.Log.Verbo("Send called with username: %s", args.Username)
 
-	if args.Amount == 0 {
-		return errInvalidAmount
-	}
-
-	assetID, err := service.vm.Lookup(args.AssetID)
-	if err != nil {
-		assetID, err = ids.FromString(args.AssetID)
-		if err != nil {
-			return fmt.Errorf("asset '%s' not found", args.AssetID)
+	return service.send(args, func(amount uint64, to, _ ids.ShortID) FxTransferable {
+		return &secp256k1fx.TransferOutput{
+			Amt: amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{to},
+			},
 		}
-	}
-
-	toBytes, err := service.vm.Parse(args.To)
-	if err != nil {
-		return fmt.Errorf("problem parsing to address: %w", err)
-	}
-	to, err := ids.ToShortID(toBytes)
-	if err != nil {
-		return fmt.Errorf("problem parsing to address: %w", err)
-	}
-
-	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
-	if err != nil {
-		return fmt.Errorf("problem retrieving user: %w", err)
-	}
+	}, reply)
+}
 
-	user := userState{vm: service.vm}
+// SendTimeLockedArgs are arguments for passing into SendTimeLocked requests
+type SendTimeLockedArgs struct {
+	SendArgs
 
-	addresses, _ := user.Addresses(db)
+	// Unix time before which the sent funds can't be spent
+	Locktime json.Uint64 `json:"locktime"`
+}
 
-	addrs := ids.Set{}
-	addrs.Add(addresses...)
-	utxos, err := service.vm.GetUTXOs(addrs)
+// SendTimeLocked sends funds that can't be spent before [args.Locktime]. The
+// asset must have been created with support for the timelock fx. Returns the ID
+// of the newly created transaction.
+func (service *Service) SendTimeLocked(r *http.Request, args *SendTimeLockedArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("SendTimeLocked called with username: %s", args.Username)
+
+	switch {
+	case args.Locktime == 0:
+		return errNoLocktime
+	case !service.vm.hasFx(timelockfx.ID):
+		return errTimelockUnsupported
+	}
+	return service.send(&args.SendArgs, func(amount uint64, to, _ ids.ShortID) FxTransferable {
+		return &timelockfx.TransferOutput{
+			Amt:      amount,
+			Locktime: uint64(args.Locktime),
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{to},
+			},
+		}
+	}, reply)
+}
+
+// CreateHTLCArgs are arguments for passing into CreateHTLC requests
+type CreateHTLCArgs struct {
+	SendArgs
+
+	// SHA-256 hash of the secret that [To] must reveal to claim the funds
+	HashLock formatting.CB58 `json:"hashLock"`
+
+	// Unix time from which the funds can't be claimed, and can be refunded to
+	// the sender instead
+	Locktime json.Uint64 `json:"locktime"`
+}
+
+// CreateHTLC sends funds to [args.To] in a hashed timelock contract. Before
+// [args.Locktime], [args.To] can claim the funds by revealing the preimage of
+// [args.HashLock]. After that, the funds can be refunded to the sender. The
+// asset must have been created with support for the htlc fx. Returns the ID of
+// the newly created transaction.
+func (service *Service) CreateHTLC(r *http.Request, args *CreateHTLCArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("CreateHTLC called with username: %s", args.Username)
+
+	hashLock := [hashing.HashLen]byte{}
+	switch {
+	case len(args.HashLock.Bytes) != hashing.HashLen:
+		return errInvalidHashLock
+	case args.Locktime == 0:
+		return errNoLocktime
+	case !service.vm.hasFx(htlcfx.ID):
+		return errHTLCUnsupported
+	}
+	copy(hashLock[:], args.HashLock.Bytes)
+
+	return service.send(&args.SendArgs, func(amount uint64, to, from ids.ShortID) FxTransferable {
+		return &htlcfx.TransferOutput{
+			Amt:      amount,
+			Locktime: uint64(args.Locktime),
+			HashLock: hashLock,
+			Claim: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{to},
+			},
+			Refund: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{from},
+			},
+		}
+	}, reply)
+}
+
+// HTLCArgs identify a hashed timelock contract that a user is spending
+type HTLCArgs struct {
+	Username    string      `json:"username"`
+	Password    string      `json:"password"`
+	TxID        ids.ID      `json:"txID"`
+	OutputIndex json.Uint32 `json:"outputIndex"`
+}
+
+// RedeemHTLCArgs are arguments for passing into RedeemHTLC requests
+type RedeemHTLCArgs struct {
+	HTLCArgs
+
+	// Secret whose SHA-256 hash is the contract's hash lock
+	Preimage formatting.CB58 `json:"preimage"`
+}
+
+// RedeemHTLC claims the funds in a hashed timelock contract that hasn't
+// expired, by revealing the preimage of its hash lock. Returns the ID of the
+// newly created transaction.
+func (service *Service) RedeemHTLC(r *http.Request, args *RedeemHTLCArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("RedeemHTLC called with username: %s", args.Username)
+
+	return service.spendHTLC(&args.HTLCArgs, args.Preimage.Bytes, false, reply)
+}
+
+// RefundHTLC refunds the funds in an expired hashed timelock contract. Returns
+// the ID of the newly created transaction.
+func (service *Service) RefundHTLC(r *http.Request, args *HTLCArgs, reply *SendReply) error {
+	service.vm.ctx.Log.Verbo("RefundHTLC called with username: %s", args.Username)
+
+	return service.spendHTLC(args, nil, true, reply)
+}
+
+// spendHTLC sends the funds in the contract identified by [args] to the user.
+// If [refund], the funds are refunded. Otherwise, they're claimed by revealing
+// [preimage].
+func (service *Service) spendHTLC(args *HTLCArgs, preimage []byte, refund bool, reply *SendReply) error {
+	utxoID := UTXOID{
+		TxID:        args.TxID,
+		OutputIndex: uint32(args.OutputIndex),
+	}
+	utxo, err := service.vm.state.UTXO(utxoID.InputID())
+	if err != nil {
+		return errUnknownUTXO
+	}
+	out, ok := utxo.Out.(*htlcfx.TransferOutput)
+	if !ok {
+		return errNotHTLC
+	}
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
 	if err != nil {
-		return fmt.Errorf("problem retrieving user's UTXOs: %w", err)
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+
+	// A user without addresses can't spend the contract, which is reported
+	// below
+	addresses, err := user.Addresses(db)
+	if err != nil && err != database.ErrNotFound {
+		return fmt.Errorf("problem retrieving user's addresses: %w", err)
 	}
 
 	kc := secp256k1fx.NewKeychain()
@@ -593,98 +1164,689 @@ func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply)
 		kc.Add(sk)
 	}
 
-	amountSpent := uint64(0)
-	time := service.vm.clock.Unix()
+	owners := &out.Claim
+	if refund {
+		owners = &out.Refund
+	}
+	sigIndices, signers, able := kc.Match(owners)
+	if !able || len(signers) == 0 {
+		return errCantSpendHTLC
+	}
 
-	ins := []*TransferableInput{}
-	keys := [][]*crypto.PrivateKeySECP256K1R{}
-	for _, utxo := range utxos {
-		if !utxo.AssetID().Equals(assetID) {
-			continue
+	var in FxTransferable = &htlcfx.ClaimInput{
+		Amt:      out.Amt,
+		Preimage: preimage,
+		Input:    secp256k1fx.Input{SigIndices: sigIndices},
+	}
+	if refund {
+		in = &htlcfx.RefundInput{
+			Amt:   out.Amt,
+			Input: secp256k1fx.Input{SigIndices: sigIndices},
 		}
-		inputIntf, signers, err := kc.Spend(utxo.Out, time)
+	}
+
+	tx := Tx{
+		UnsignedTx: &BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs: []*TransferableOutput{&TransferableOutput{
+				Asset: utxo.Asset,
+				Out: &secp256k1fx.TransferOutput{
+					Amt: out.Amt,
+					OutputOwners: secp256k1fx.OutputOwners{
+						Threshold: 1,
+						Addrs:     []ids.ShortID{signers[0].PublicKey().Address()},
+					},
+				},
+			}},
+			Ins: []*TransferableInput{&TransferableInput{
+				UTXOID: utxoID,
+				Asset:  utxo.Asset,
+				In:     in,
+			}},
+		},
+	}
+
+	txID, err := service.signAndIssue(&tx, [][]*crypto.PrivateKeySECP256K1R{signers}, func(cred *secp256k1fx.Credential) verify.Verifiable {
+		return &htlcfx.Credential{Credential: *cred}
+	})
+	if err != nil {
+		return err
+	}
+	reply.TxID = txID
+	return nil
+}
+
+// send [args.Amount] of [args.AssetID] to [args.To]. [newOut] returns the
+// output that sends [amount] to [to]. [from] is an address of the sender.
+func (service *Service) send(args *SendArgs, newOut func(amount uint64, to, from ids.ShortID) FxTransferable, reply *SendReply) error {
+	if args.Amount == 0 {
+		return errInvalidAmount
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
 		if err != nil {
-			continue
-		}
-		input, ok := inputIntf.(FxTransferable)
-		if !ok {
-			continue
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
 		}
-		spent, err := math.Add64(amountSpent, input.Amount())
+	}
+
+	toBytes, err := service.vm.Parse(args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+	to, err := ids.ToShortID(toBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+
+	kc, utxos, err := service.userFunds(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
+
+	ins, keys, changeOuts, err := service.spendWithChange(kc, utxos, assetID, uint64(args.Amount))
+	if err != nil {
+		return err
+	}
+
+	changeAddr := kc.Keys[0].PublicKey().Address()
+	outs := append(changeOuts, &TransferableOutput{
+		Asset: Asset{
+			ID: assetID,
+		},
+		Out: newOut(uint64(args.Amount), to, changeAddr),
+	})
+
+	SortTransferableOutputs(outs, service.vm.codec)
+
+	tx := Tx{
+		UnsignedTx: &BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+			Ins:   ins,
+		},
+	}
+
+	txID, err := service.signAndIssue(&tx, keys, func(cred *secp256k1fx.Credential) verify.Verifiable {
+		return cred
+	})
+	if err != nil {
+		return err
+	}
+	reply.TxID = txID
+	return nil
+}
+
+// userFunds returns a keychain of the keys of the user [username], and the
+// UTXOs that reference the user's addresses
+func (service *Service) userFunds(username, password string) (*secp256k1fx.Keychain, []*UTXO, error) {
+	db, err := service.vm.ctx.Keystore.GetDatabase(username, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+
+	addresses, _ := user.Addresses(db)
+
+	addrs := ids.Set{}
+	addrs.Add(addresses...)
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem retrieving user's UTXOs: %w", err)
+	}
+
+	kc := secp256k1fx.NewKeychain()
+	for _, addr := range addresses {
+		sk, err := user.Key(db, addr)
 		if err != nil {
-			return errSpendOverflow
+			return nil, nil, fmt.Errorf("problem retrieving private key: %w", err)
 		}
-		amountSpent = spent
+		kc.Add(sk)
+	}
+	return kc, utxos, nil
+}
 
-		in := &TransferableInput{
-			UTXOID: utxo.UTXOID,
-			Asset:  Asset{ID: assetID},
-			In:     input,
+// spendWithChange returns inputs that spend at least [amount] of [assetID]
+// from the [utxos] that [kc] can spend, the keys that must sign each input,
+// and the output, if any, that returns the change to the first key of [kc]
+func (service *Service) spendWithChange(kc *secp256k1fx.Keychain, utxos []*UTXO, assetID ids.ID, amount uint64) ([]*TransferableInput, [][]*crypto.PrivateKeySECP256K1R, []*TransferableOutput, error) {
+	ins, signers, amountSpent, err := service.spend(utxos, assetID, amount, kc.Addresses())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keys := make([][]*crypto.PrivateKeySECP256K1R, len(signers))
+	for i, inputSigners := range signers {
+		for _, addr := range inputSigners {
+			key, _ := kc.Get(addr)
+			keys[i] = append(keys[i], key)
 		}
+	}
 
-		ins = append(ins, in)
-		keys = append(keys, signers)
+	outs := []*TransferableOutput(nil)
+	if amountSpent > amount {
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{
+				ID: assetID,
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amountSpent - amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{kc.Keys[0].PublicKey().Address()},
+				},
+			},
+		})
+	}
+	return ins, keys, outs, nil
+}
 
-		if amountSpent >= uint64(args.Amount) {
-			break
+// ExportArgs are arguments for passing into Export requests
+type ExportArgs struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Amount   json.Uint64 `json:"amount"`
+	AssetID  string      `json:"assetID"`
+
+	// Address that can import the funds on the destination chain. The chain
+	// is the one the address is formatted for, and must be another chain
+	// running this VM that this node runs, as only those can import the
+	// exported funds. The Platform Chain can import them too if they're of the
+	// fee asset, which is $AVA, and are credited to the address's account.
+	To string `json:"to"`
+}
+
+// ExportReply defines the Export replies returned from the API
+type ExportReply struct {
+	TxID ids.ID `json:"txID"`
+
+	// IDs of the exported UTXOs. The import call of the destination chain
+	// takes these, and GetExportStatus tracks whether they were imported.
+	UTXOIDs []ids.ID `json:"utxoIDs"`
+}
+
+// Export issues a transaction that exports funds to another chain. Once the
+// transaction is accepted, the funds can be imported by the destination chain.
+func (service *Service) Export(r *http.Request, args *ExportArgs, reply *ExportReply) error {
+	service.vm.ctx.Log.Verbo("Export called with username: %s", args.Username)
+
+	if args.Amount == 0 {
+		return errInvalidAmount
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
 		}
 	}
 
-	if amountSpent < uint64(args.Amount) {
-		return errInsufficientFunds
+	destinationChain, toBytes, err := service.vm.parseChainAddress(args.To)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
+	}
+	if destinationChain.Equals(service.vm.ctx.ChainID) {
+		return errExportToSelf
+	}
+	if !service.vm.canImport(destinationChain, assetID) {
+		return fmt.Errorf("%w: %s", errCantImport, destinationChain)
+	}
+	to, err := ids.ToShortID(toBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing to address: %w", err)
 	}
 
-	SortTransferableInputsWithSigners(ins, keys)
+	kc, utxos, err := service.userFunds(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
 
-	outs := []*TransferableOutput{
-		&TransferableOutput{
+	ins, keys, outs, err := service.spendWithChange(kc, utxos, assetID, uint64(args.Amount))
+	if err != nil {
+		return err
+	}
+
+	exportTx := &ExportTx{
+		BaseTx: BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+			Ins:   ins,
+		},
+		DestinationChain: destinationChain,
+		ExportedOuts: []*TransferableOutput{&TransferableOutput{
 			Asset: Asset{
 				ID: assetID,
 			},
 			Out: &secp256k1fx.TransferOutput{
-				Amt:      uint64(args.Amount),
-				Locktime: 0,
+				Amt: uint64(args.Amount),
 				OutputOwners: secp256k1fx.OutputOwners{
 					Threshold: 1,
 					Addrs:     []ids.ShortID{to},
 				},
 			},
-		},
+		}},
 	}
 
-	if amountSpent > uint64(args.Amount) {
-		changeAddr := kc.Keys[0].PublicKey().Address()
-		outs = append(outs,
-			&TransferableOutput{
-				Asset: Asset{
-					ID: assetID,
-				},
-				Out: &secp256k1fx.TransferOutput{
-					Amt:      amountSpent - uint64(args.Amount),
-					Locktime: 0,
-					OutputOwners: secp256k1fx.OutputOwners{
-						Threshold: 1,
-						Addrs:     []ids.ShortID{changeAddr},
-					},
+	txID, err := service.signAndIssue(&Tx{UnsignedTx: exportTx}, keys, func(cred *secp256k1fx.Credential) verify.Verifiable {
+		return cred
+	})
+	if err != nil {
+		return err
+	}
+
+	reply.TxID = txID
+	for i := range exportTx.ExportedOuts {
+		utxoID := UTXOID{
+			TxID:        txID,
+			OutputIndex: uint32(len(exportTx.Outs) + i),
+		}
+		reply.UTXOIDs = append(reply.UTXOIDs, utxoID.InputID())
+	}
+	return nil
+}
+
+// ImportArgs are arguments for passing into Import requests
+type ImportArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Chain the UTXOs were exported from
+	SourceChain string `json:"sourceChain"`
+
+	// IDs of the exported UTXOs to import, as returned by the export call of
+	// the source chain. The transfers the Platform Chain exports are imported
+	// as UTXOs of the fee asset.
+	UTXOIDs []ids.ID `json:"utxoIDs"`
+
+	// Address of this chain that receives the imported funds
+	To string `json:"to"`
+}
+
+// Import issues a transaction that imports UTXOs another chain exported to this
+// chain. The user must control the UTXOs.
+func (service *Service) Import(r *http.Request, args *ImportArgs, reply *IssueTxReply) error {
+	service.vm.ctx.Log.Verbo("Import called with username: %s", args.Username)
+
+	if len(args.UTXOIDs) == 0 {
+		return errNoImportInputs
+	}
+	if service.vm.ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+
+	sourceChain, err := service.vm.ctx.BCLookup.Lookup(args.SourceChain)
+	if err != nil {
+		sourceChain, err = ids.FromString(args.SourceChain)
+		if err != nil {
+			return fmt.Errorf("chain '%s' not found", args.SourceChain)
+		}
+	}
+	if sourceChain.Equals(service.vm.ctx.ChainID) {
+		return errImportFromSelf
+	}
+
+	to, err := service.parseAddress(args.To)
+	if err != nil {
+		return err
+	}
+
+	kc, _, err := service.userFunds(args.Username, args.Password)
+	if err != nil {
+		return err
+	}
+
+	utxoKeys := make([][]byte, len(args.UTXOIDs))
+	for i, utxoID := range args.UTXOIDs {
+		utxoKeys[i] = utxoID.Bytes()
+	}
+	utxos, err := service.vm.sharedUTXOs(sourceChain, utxoKeys)
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported UTXOs: %w", err)
+	}
+
+	time := service.vm.clock.Unix()
+	amounts := map[[32]byte]uint64{}
+	ins := []*TransferableInput{}
+	keys := [][]*crypto.PrivateKeySECP256K1R{}
+	for i, utxo := range utxos {
+		input, signers, err := kc.Spend(utxo.Out, time)
+		if err != nil {
+			return fmt.Errorf("can't spend exported UTXO %s: %w", args.UTXOIDs[i], err)
+		}
+		in, ok := input.(FxTransferable)
+		if !ok {
+			return errUnknownOutputType
+		}
+
+		assetID := utxo.AssetID()
+		amount, err := math.Add64(amounts[assetID.Key()], in.Amount())
+		if err != nil {
+			return errSpendOverflow
+		}
+		amounts[assetID.Key()] = amount
+
+		ins = append(ins, &TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  utxo.Asset,
+			In:     in,
+		})
+		keys = append(keys, signers)
+	}
+	SortTransferableInputsWithSigners(ins, keys)
+
+	outs := []*TransferableOutput{}
+	for assetKey, amount := range amounts {
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{
+				ID: ids.NewID(assetKey),
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{to},
 				},
 			},
-		)
+		})
+	}
+	SortTransferableOutputs(outs, service.vm.codec)
+
+	tx := Tx{UnsignedTx: &ImportTx{
+		BaseTx: BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+		},
+		SourceChain: sourceChain,
+		ImportedIns: ins,
+	}}
+
+	txID, err := service.signAndIssue(&tx, keys, func(cred *secp256k1fx.Credential) verify.Verifiable {
+		return cred
+	})
+	if err != nil {
+		return err
+	}
+	reply.TxID = txID
+	return nil
+}
+
+// Statuses of the transfer an export tx makes
+const (
+	transferExporting = "Exporting" // The export tx hasn't been decided yet
+	transferExported  = "Exported"  // Some exported UTXOs haven't been imported yet
+	transferImported  = "Imported"  // Every exported UTXO was imported
+	transferFailed    = "Failed"    // The export tx was rejected or dropped
+)
+
+// GetExportStatusArgs are arguments for passing into GetExportStatus requests
+type GetExportStatusArgs struct {
+	TxID ids.ID `json:"txID"`
+}
+
+// GetExportStatusReply defines the GetExportStatus replies returned from the
+// API
+type GetExportStatusReply struct {
+	// Status of the transfer as a whole: Exporting, Exported, Imported or
+	// Failed
+	Status string `json:"status"`
+
+	ExportStatus     choices.Status `json:"exportStatus"`
+	DestinationChain ids.ID         `json:"destinationChain"`
+
+	// Exported UTXOs that the destination chain hasn't imported yet
+	PendingUTXOIDs []ids.ID `json:"pendingUTXOIDs"`
+}
+
+// GetExportStatus returns the status of the transfer made by an export tx,
+// from the export tx being issued to the destination chain importing every
+// exported UTXO
+func (service *Service) GetExportStatus(r *http.Request, args *GetExportStatusArgs, reply *GetExportStatusReply) error {
+	service.vm.ctx.Log.Verbo("GetExportStatus called with %s", args.TxID)
+
+	if args.TxID.IsZero() {
+		return errNilTxID
+	}
+
+	tx, err := service.vm.state.Tx(args.TxID)
+	if err != nil {
+		return errUnknownTx
+	}
+	exportTx, ok := tx.UnsignedTx.(*ExportTx)
+	if !ok {
+		return errTxNotExport
+	}
+
+	uTx := UniqueTx{
+		vm:   service.vm,
+		txID: args.TxID,
+	}
+	reply.ExportStatus = uTx.Status()
+	reply.DestinationChain = exportTx.DestinationChain
+
+	if _, dropped := service.vm.reissuer.Reissues(args.TxID); dropped {
+		reply.Status = transferFailed
+		return nil
+	}
+	switch reply.ExportStatus {
+	case choices.Accepted:
+	case choices.Rejected:
+		reply.Status = transferFailed
+		return nil
+	default:
+		reply.Status = transferExporting
+		return nil
+	}
+
+	if service.vm.ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+	utxos := exportTx.ExportedUTXOs()
+	utxoKeys := make([][]byte, len(utxos))
+	for i, utxo := range utxos {
+		utxoKeys[i] = utxo.InputID().Bytes()
+	}
+	exported, err := service.vm.ctx.SharedMemory.Exported(exportTx.DestinationChain, utxoKeys)
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported UTXOs: %w", err)
+	}
+	for i, pending := range exported {
+		if pending {
+			reply.PendingUTXOIDs = append(reply.PendingUTXOIDs, utxos[i].InputID())
+		}
+	}
+
+	reply.Status = transferImported
+	if len(reply.PendingUTXOIDs) > 0 {
+		reply.Status = transferExported
+	}
+	return nil
+}
+
+// ConsolidateUTXOsArgs are arguments for passing into ConsolidateUTXOs requests
+type ConsolidateUTXOsArgs struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Address whose UTXOs are consolidated. The user must control it.
+	Address string `json:"address"`
+	AssetID string `json:"assetID"`
+
+	// If positive, only UTXOs holding less than this amount are consolidated
+	Below json.Uint64 `json:"below"`
+
+	// Maximum number of UTXOs to consolidate. If 0 or greater than
+	// MaxConsolidatedUTXOs, MaxConsolidatedUTXOs is used.
+	MaxUTXOs json.Uint32 `json:"maxUTXOs"`
+}
+
+// ConsolidateUTXOsReply defines the ConsolidateUTXOs replies returned from the
+// API
+type ConsolidateUTXOsReply struct {
+	TxID ids.ID `json:"txID"`
+
+	// Number of UTXOs that were consolidated, and the amount they held
+	Consolidated json.Uint32 `json:"consolidated"`
+	Amount       json.Uint64 `json:"amount"`
+}
+
+// ConsolidateUTXOs issues a transaction that merges the smallest UTXOs of an
+// asset held by an address into a single UTXO held by the address. Addresses
+// that receive many small payments accumulate UTXOs that are too numerous to
+// spend in a single transaction.
+func (service *Service) ConsolidateUTXOs(r *http.Request, args *ConsolidateUTXOsArgs, reply *ConsolidateUTXOsReply) error {
+	service.vm.ctx.Log.Verbo("ConsolidateUTXOs called with username: %s", args.Username)
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	addrBytes, err := service.vm.Parse(args.Address)
+	if err != nil {
+		return fmt.Errorf("problem parsing address: %w", err)
+	}
+	addr, err := ids.ToShortID(addrBytes)
+	if err != nil {
+		return fmt.Errorf("problem parsing address: %w", err)
+	}
+	addrID := ids.NewID(hashing.ComputeHash256Array(addrBytes))
+
+	db, err := service.vm.ctx.Keystore.GetDatabase(args.Username, args.Password)
+	if err != nil {
+		return fmt.Errorf("problem retrieving user: %w", err)
+	}
+
+	user := userState{vm: service.vm}
+	sk, err := user.Key(db, addrID)
+	if err != nil {
+		return fmt.Errorf("problem retrieving private key: %w", err)
+	}
+
+	// Only UTXOs the address can spend on its own are consolidated
+	kc := secp256k1fx.NewKeychain()
+	kc.Add(sk)
+
+	addrs := ids.Set{}
+	addrs.Add(addrID)
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return fmt.Errorf("problem retrieving UTXOs: %w", err)
+	}
+
+	maxUTXOs := int(args.MaxUTXOs)
+	if maxUTXOs == 0 || maxUTXOs > MaxConsolidatedUTXOs {
+		maxUTXOs = MaxConsolidatedUTXOs
+	}
+	time := service.vm.clock.Unix()
+
+	ins := []*TransferableInput{}
+	keys := [][]*crypto.PrivateKeySECP256K1R{}
+	for _, utxo := range utxos {
+		if !utxo.AssetID().Equals(assetID) {
+			continue
+		}
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok || (args.Below != 0 && out.Amount() >= uint64(args.Below)) {
+			continue
+		}
+		inputIntf, signers, err := kc.Spend(out, time)
+		if err != nil {
+			continue
+		}
+		input, ok := inputIntf.(FxTransferable)
+		if !ok {
+			continue
+		}
+		ins = append(ins, &TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  Asset{ID: assetID},
+			In:     input,
+		})
+		keys = append(keys, signers)
+	}
+
+	// Consolidate the smallest UTXOs first
+	sort.Stable(&innerSortTransferableInputsByAmount{ins: ins, signers: keys})
+
+	amount := uint64(0)
+	numIns := 0
+	for ; numIns < len(ins) && numIns < maxUTXOs; numIns++ {
+		newAmount, err := math.Add64(amount, ins[numIns].Input().Amount())
+		if err != nil {
+			break
+		}
+		amount = newAmount
+	}
+	ins = ins[:numIns]
+	keys = keys[:numIns]
+	if len(ins) < 2 {
+		return errNothingToConsolidate
 	}
 
-	SortTransferableOutputs(outs, service.vm.codec)
+	SortTransferableInputsWithSigners(ins, keys)
 
 	tx := Tx{
 		UnsignedTx: &BaseTx{
 			NetID: service.vm.ctx.NetworkID,
 			BCID:  service.vm.ctx.ChainID,
-			Outs:  outs,
-			Ins:   ins,
+			Outs: []*TransferableOutput{
+				&TransferableOutput{
+					Asset: Asset{ID: assetID},
+					Out: &secp256k1fx.TransferOutput{
+						Amt: amount,
+						OutputOwners: secp256k1fx.OutputOwners{
+							Threshold: 1,
+							Addrs:     []ids.ShortID{addr},
+						},
+					},
+				},
+			},
+			Ins: ins,
 		},
 	}
 
+	txID, err := service.signAndIssue(&tx, keys, func(cred *secp256k1fx.Credential) verify.Verifiable {
+		return cred
+	})
+	if err != nil {
+		return err
+	}
+	reply.TxID = txID
+	reply.Consolidated = json.Uint32(len(ins))
+	reply.Amount = json.Uint64(amount)
+	return nil
+}
+
+type innerSortTransferableInputsByAmount struct {
+	ins     []*TransferableInput
+	signers [][]*crypto.PrivateKeySECP256K1R
+}
+
+func (ins *innerSortTransferableInputsByAmount) Less(i, j int) bool {
+	return ins.ins[i].Input().Amount() < ins.ins[j].Input().Amount()
+}
+func (ins *innerSortTransferableInputsByAmount) Len() int { return len(ins.ins) }
+func (ins *innerSortTransferableInputsByAmount) Swap(i, j int) {
+	ins.ins[j], ins.ins[i] = ins.ins[i], ins.ins[j]
+	ins.signers[j], ins.signers[i] = ins.signers[i], ins.signers[j]
+}
+
+// signAndIssue signs the i'th input of [tx] with [keys][i] and issues [tx].
+// [newCred] wraps the signatures of an input in a credential of the input's
+// fx.
+func (service *Service) signAndIssue(tx *Tx, keys [][]*crypto.PrivateKeySECP256K1R, newCred func(*secp256k1fx.Credential) verify.Verifiable) (ids.ID, error) {
 	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
 	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+		return ids.ID{}, fmt.Errorf("problem creating transaction: %w", err)
 	}
 	hash := hashing.ComputeHash256(unsignedBytes)
 
@@ -693,28 +1855,26 @@ func (service *Service) Send(r *http.Request, args *SendArgs, reply *SendReply)
 		for _, key := range credKeys {
 			sig, err := key.SignHash(hash)
 			if err != nil {
-				return fmt.Errorf("problem creating transaction: %w", err)
+				return ids.ID{}, fmt.Errorf("problem creating transaction: %w", err)
 			}
 			fixedSig := [crypto.SECP256K1RSigLen]byte{}
 			copy(fixedSig[:], sig)
 
 			cred.Sigs = append(cred.Sigs, fixedSig)
 		}
-		tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+		tx.Creds = append(tx.Creds, &Credential{Cred: newCred(cred)})
 	}
 
 	b, err := service.vm.codec.Marshal(tx)
 	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+		return ids.ID{}, fmt.Errorf("problem creating transaction: %w", err)
 	}
 
 	txID, err := service.vm.IssueTx(b, nil)
 	if err != nil {
-		return fmt.Errorf("problem issuing transaction: %w", err)
+		return ids.ID{}, fmt.Errorf("problem issuing transaction: %w", err)
 	}
-
-	reply.TxID = txID
-	return nil
+	return txID, nil
 }
 
 type innerSortTransferableInputsWithSigners struct {
@@ -759,11 +1919,14 @@ type CreateMintTxArgs struct {
 	AssetID string      `json:"assetID"`
 	To      string      `json:"to"`
 	Minters []string    `json:"minters"`
+
+	// Encoding of the returned transaction. CB58 if empty.
+	Encoding string `json:"encoding"`
 }
 
 // CreateMintTxReply defines the CreateMintTx replies returned from the API
 type CreateMintTxReply struct {
-	Tx formatting.CB58 `json:"tx"`
+	Tx formatting.EncodedBytes `json:"tx"`
 }
 
 // CreateMintTx returns the newly created unsigned transaction
@@ -774,6 +1937,11 @@ func (service *Service) CreateMintTx(r *http.Request, args *CreateMintTxArgs, re
 		return errInvalidMintAmount
 	}
 
+	encoding, err := formatting.GetEncoding(args.Encoding)
+	if err != nil {
+		return err
+	}
+
 	assetID, err := service.vm.Lookup(args.AssetID)
 	if err != nil {
 		assetID, err = ids.FromString(args.AssetID)
@@ -874,7 +2042,7 @@ func (service *Service) CreateMintTx(r *http.Request, args *CreateMintTxArgs, re
 			if err != nil {
 				return fmt.Errorf("problem creating transaction: %w", err)
 			}
-			reply.Tx.Bytes = txBytes
+			reply.Tx = formatting.EncodedBytes{Bytes: txBytes, Encoding: encoding}
 			return nil
 		}
 	}
@@ -994,3 +2162,362 @@ func (service *Service) SignMintTx(r *http.Request, args *SignMintTxArgs, reply
 	reply.Tx.Bytes = txBytes
 	return nil
 }
+
+// BuildUnsignedTxArgs are arguments for passing into BuildUnsignedTx requests
+type BuildUnsignedTxArgs struct {
+	// Addresses whose UTXOs may be spent
+	From []string `json:"from"`
+
+	Amount  json.Uint64 `json:"amount"`
+	AssetID string      `json:"assetID"`
+	To      string      `json:"to"`
+
+	// Address the change is sent to. If empty, defaults to the first address
+	// in [From].
+	ChangeAddr string `json:"changeAddr"`
+}
+
+// BuildUnsignedTxReply defines the BuildUnsignedTx replies returned from the
+// API
+type BuildUnsignedTxReply struct {
+	// The transaction, without credentials
+	Tx formatting.CB58 `json:"tx"`
+
+	// The digest every signature on the transaction must be over
+	Digest formatting.CB58 `json:"digest"`
+
+	// Signers[i] are the addresses that must sign for the i'th input, in the
+	// order their signatures must be provided
+	Signers [][]string `json:"signers"`
+}
+
+// BuildUnsignedTx returns a transaction that sends [args.Amount] of
+// [args.AssetID] to [args.To] from the UTXOs of [args.From], without signing
+// it. The keys of the addresses don't need to be held by this node. The
+// transaction can be signed offline and issued with IssueSignedTx.
+func (service *Service) BuildUnsignedTx(r *http.Request, args *BuildUnsignedTxArgs, reply *BuildUnsignedTxReply) error {
+	service.vm.ctx.Log.Verbo("BuildUnsignedTx called")
+
+	tx, signers, err := service.buildUnsignedTx(args)
+	if err != nil {
+		return err
+	}
+
+	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+	txBytes, err := service.vm.codec.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	reply.Tx.Bytes = txBytes
+	reply.Digest.Bytes = hashing.ComputeHash256(unsignedBytes)
+	reply.Signers = make([][]string, len(signers))
+	for i, inputSigners := range signers {
+		for _, signer := range inputSigners {
+			reply.Signers[i] = append(reply.Signers[i], service.vm.Format(signer.Bytes()))
+		}
+	}
+	return nil
+}
+
+// buildUnsignedTx returns the transaction described by [args], without
+// credentials, and the addresses that must sign for each of its inputs
+func (service *Service) buildUnsignedTx(args *BuildUnsignedTxArgs) (*Tx, [][]ids.ShortID, error) {
+	if args.Amount == 0 {
+		return nil, nil, errInvalidAmount
+	}
+	if len(args.From) == 0 {
+		return nil, nil, errNoSpendAddresses
+	}
+
+	assetID, err := service.vm.Lookup(args.AssetID)
+	if err != nil {
+		assetID, err = ids.FromString(args.AssetID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("asset '%s' not found", args.AssetID)
+		}
+	}
+
+	to, err := service.parseAddress(args.To)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := ids.ShortSet{}
+	addrs := ids.Set{}
+	for _, addrStr := range args.From {
+		addr, err := service.parseAddress(addrStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		from.Add(addr)
+		addrs.Add(ids.NewID(hashing.ComputeHash256Array(addr.Bytes())))
+	}
+
+	changeAddrStr := args.ChangeAddr
+	if changeAddrStr == "" {
+		changeAddrStr = args.From[0]
+	}
+	changeAddr, err := service.parseAddress(changeAddrStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	utxos, err := service.vm.GetUTXOs(addrs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem retrieving UTXOs: %w", err)
+	}
+
+	ins, signers, amountSpent, err := service.spend(utxos, assetID, uint64(args.Amount), from)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outs := []*TransferableOutput{
+		&TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: uint64(args.Amount),
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{to},
+				},
+			},
+		},
+	}
+	if amountSpent > uint64(args.Amount) {
+		outs = append(outs, &TransferableOutput{
+			Asset: Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amountSpent - uint64(args.Amount),
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{changeAddr},
+				},
+			},
+		})
+	}
+	SortTransferableOutputs(outs, service.vm.codec)
+
+	tx := &Tx{
+		UnsignedTx: &BaseTx{
+			NetID: service.vm.ctx.NetworkID,
+			BCID:  service.vm.ctx.ChainID,
+			Outs:  outs,
+			Ins:   ins,
+		},
+	}
+
+	return tx, signers, nil
+}
+
+// IssueSignedTxArgs are arguments for passing into IssueSignedTx requests
+type IssueSignedTxArgs struct {
+	// The output of BuildUnsignedTx
+	Tx formatting.CB58 `json:"tx"`
+
+	// Signatures[i] are the signatures over the transaction's digest for the
+	// i'th input, in the order the signers were returned by BuildUnsignedTx
+	Signatures [][]formatting.CB58 `json:"signatures"`
+}
+
+// IssueSignedTx attaches [args.Signatures] to the unsigned transaction
+// [args.Tx] and issues it
+func (service *Service) IssueSignedTx(r *http.Request, args *IssueSignedTxArgs, reply *IssueTxReply) error {
+	service.vm.ctx.Log.Verbo("IssueSignedTx called")
+
+	tx := Tx{}
+	if err := service.vm.codec.Unmarshal(args.Tx.Bytes, &tx); err != nil {
+		return fmt.Errorf("problem parsing transaction: %w", err)
+	}
+	if len(tx.Creds) != 0 {
+		return errAlreadySigned
+	}
+	if numIns := len(tx.InputUTXOs()); numIns != len(args.Signatures) {
+		return fmt.Errorf("transaction has %d inputs but signatures were provided for %d", numIns, len(args.Signatures))
+	}
+
+	for _, sigs := range args.Signatures {
+		cred := &secp256k1fx.Credential{}
+		for _, sig := range sigs {
+			if len(sig.Bytes) != crypto.SECP256K1RSigLen {
+				return fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig.Bytes))
+			}
+			fixedSig := [crypto.SECP256K1RSigLen]byte{}
+			copy(fixedSig[:], sig.Bytes)
+			cred.Sigs = append(cred.Sigs, fixedSig)
+		}
+		tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+	}
+
+	txBytes, err := service.vm.codec.Marshal(&tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	txID, err := service.vm.IssueTx(txBytes, nil)
+	if err != nil {
+		return fmt.Errorf("problem issuing transaction: %w", err)
+	}
+	reply.TxID = txID
+	return nil
+}
+
+// SignerOpSend is the operation the external signer is asked to authorize when
+// SendFromSigner is called
+const SignerOpSend = "avm.send"
+
+// SendFromSigner builds the transaction described by [args], has each of its
+// inputs signed by the node's external signer and issues it
+func (service *Service) SendFromSigner(r *http.Request, args *BuildUnsignedTxArgs, reply *IssueTxReply) error {
+	service.vm.ctx.Log.Verbo("SendFromSigner called")
+
+	signer := service.vm.ctx.Signer
+	if signer == nil {
+		return errNoSigner
+	}
+
+	tx, signers, err := service.buildUnsignedTx(args)
+	if err != nil {
+		return err
+	}
+
+	unsignedBytes, err := service.vm.codec.Marshal(&tx.UnsignedTx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	for _, inputSigners := range signers {
+		cred := &secp256k1fx.Credential{}
+		for _, addr := range inputSigners {
+			sig, err := signer.Sign(SignerOpSend, addr, unsignedBytes)
+			if err != nil {
+				return fmt.Errorf("problem signing transaction with %s: %w", service.vm.Format(addr.Bytes()), err)
+			}
+			if len(sig) != crypto.SECP256K1RSigLen {
+				return fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+			}
+			fixedSig := [crypto.SECP256K1RSigLen]byte{}
+			copy(fixedSig[:], sig)
+			cred.Sigs = append(cred.Sigs, fixedSig)
+		}
+		tx.Creds = append(tx.Creds, &Credential{Cred: cred})
+	}
+
+	txBytes, err := service.vm.codec.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	txID, err := service.vm.IssueTx(txBytes, nil)
+	if err != nil {
+		return fmt.Errorf("problem issuing transaction: %w", err)
+	}
+	reply.TxID = txID
+	return nil
+}
+
+// parseAddress returns the address [addrStr] is the formatted form of
+func (service *Service) parseAddress(addrStr string) (ids.ShortID, error) {
+	addrBytes, err := service.vm.Parse(addrStr)
+	if err != nil {
+		return ids.ShortID{}, fmt.Errorf("problem parsing address '%s': %w", addrStr, err)
+	}
+	addr, err := ids.ToShortID(addrBytes)
+	if err != nil {
+		return ids.ShortID{}, fmt.Errorf("problem parsing address '%s': %w", addrStr, err)
+	}
+	return addr, nil
+}
+
+// spend returns inputs that spend at least [amount] of [assetID] from the
+// [utxos] that [from] can spend, sorted, along with the addresses that must
+// sign for each input and the amount the inputs spend
+func (service *Service) spend(utxos []*UTXO, assetID ids.ID, amount uint64, from ids.ShortSet) ([]*TransferableInput, [][]ids.ShortID, uint64, error) {
+	amountSpent := uint64(0)
+	time := service.vm.clock.Unix()
+
+	ins := []*TransferableInput{}
+	signers := [][]ids.ShortID{}
+	for _, utxo := range utxos {
+		if !utxo.AssetID().Equals(assetID) {
+			continue
+		}
+		out, ok := utxo.Out.(*secp256k1fx.TransferOutput)
+		if !ok || time < out.Locktime {
+			continue
+		}
+		sigIndices, inputSigners, able := matchOwners(&out.OutputOwners, from)
+		if !able {
+			continue
+		}
+		spent, err := math.Add64(amountSpent, out.Amount())
+		if err != nil {
+			return nil, nil, 0, errSpendOverflow
+		}
+		amountSpent = spent
+
+		ins = append(ins, &TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  Asset{ID: assetID},
+			In: &secp256k1fx.TransferInput{
+				Amt:   out.Amount(),
+				Input: secp256k1fx.Input{SigIndices: sigIndices},
+			},
+		})
+		signers = append(signers, inputSigners)
+
+		if amountSpent >= amount {
+			break
+		}
+	}
+
+	if amountSpent < amount {
+		return nil, nil, 0, errInsufficientFunds
+	}
+
+	sort.Sort(&innerSortTransferableInputsWithAddrs{ins: ins, signers: signers})
+	return ins, signers, amountSpent, nil
+}
+
+// matchOwners returns the signature indices and addresses of the signers from
+// [addrs] that can spend an output owned by [owners], and whether there are
+// enough of them to meet the threshold
+func matchOwners(owners *secp256k1fx.OutputOwners, addrs ids.ShortSet) ([]uint32, []ids.ShortID, bool) {
+	sigIndices := []uint32{}
+	signers := []ids.ShortID{}
+	for i := uint32(0); i < uint32(len(owners.Addrs)) && uint32(len(signers)) < owners.Threshold; i++ {
+		if addr := owners.Addrs[i]; addrs.Contains(addr) {
+			sigIndices = append(sigIndices, i)
+			signers = append(signers, addr)
+		}
+	}
+	return sigIndices, signers, uint32(len(signers)) == owners.Threshold
+}
+
+type innerSortTransferableInputsWithAddrs struct {
+	ins     []*TransferableInput
+	signers [][]ids.ShortID
+}
+
+func (ins *innerSortTransferableInputsWithAddrs) Less(i, j int) bool {
+	iID, iIndex := ins.ins[i].InputSource()
+	jID, jIndex := ins.ins[j].InputSource()
+
+	switch bytes.Compare(iID.Bytes(), jID.Bytes()) {
+	case -1:
+		return true
+	case 0:
+		return iIndex < jIndex
+	default:
+		return false
+	}
+}
+func (ins *innerSortTransferableInputsWithAddrs) Len() int { return len(ins.ins) }
+func (ins *innerSortTransferableInputsWithAddrs) Swap(i, j int) {
+	ins.ins[j], ins.ins[i] = ins.ins[i], ins.ins[j]
+	ins.signers[j], ins.signers[i] = ins.signers[i], ins.signers[j]
+}
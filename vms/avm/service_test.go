@@ -4,12 +4,24 @@
 package avm
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/ava-labs/gecko/api/keystore"
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/vms/secp256k1fx"
+	"github.com/ava-labs/gecko/vms/timelockfx"
 )
 
 func TestGetAssetDescription(t *testing.T) {
@@ -93,8 +105,268 @@ func TestGetBalance(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if reply.Balance != 300000 {
-		t.Fatalf("Wrong balance returned from GetBalance %d", reply.Balance)
+	if reply.Balance.String() != "300000" {
+		t.Fatalf("Wrong balance returned from GetBalance %s", reply.Balance)
+	}
+}
+
+func TestGetUTXOsEncoding(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	addrs := []string{vm.Format(keys[0].PublicKey().Address().Bytes())}
+	cb58Reply := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs}, &cb58Reply); err != nil {
+		t.Fatal(err)
+	}
+	hexReply := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs, Encoding: formatting.HexEncoding}, &hexReply); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cb58Reply.UTXOs) == 0 || len(cb58Reply.UTXOs) != len(hexReply.UTXOs) {
+		t.Fatalf("Should have returned the same UTXOs in both encodings")
+	}
+	cb58UTXOs := map[string]bool{}
+	for _, utxo := range cb58Reply.UTXOs {
+		cb58UTXOs[string(utxo.Bytes)] = true
+	}
+	for _, utxo := range hexReply.UTXOs {
+		if str := utxo.String(); !strings.HasPrefix(str, "0x") {
+			t.Fatalf("UTXO %s should have been hex encoded", str)
+		}
+		if !cb58UTXOs[string(utxo.Bytes)] {
+			t.Fatalf("Should have returned the same UTXOs in both encodings")
+		}
+	}
+
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs, Encoding: "base32"}, &GetUTXOsReply{}); err == nil {
+		t.Fatalf("Should have errored due to an unknown encoding")
+	}
+}
+
+func TestGetUTXOsPagination(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	addrs := []string{vm.Format(keys[0].PublicKey().Address().Bytes())}
+	allReply := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs}, &allReply); err != nil {
+		t.Fatal(err)
+	}
+	if allReply.NumFetched != 7 {
+		t.Fatalf("Should have returned 7 UTXOs but returned %d", allReply.NumFetched)
+	}
+
+	paged := map[string]bool{}
+	args := GetUTXOsArgs{Addresses: addrs, Limit: 3}
+	for {
+		reply := GetUTXOsReply{}
+		if err := s.GetUTXOs(nil, &args, &reply); err != nil {
+			t.Fatal(err)
+		}
+		for _, utxo := range reply.UTXOs {
+			if paged[string(utxo.Bytes)] {
+				t.Fatalf("Returned the same UTXO on two pages")
+			}
+			paged[string(utxo.Bytes)] = true
+		}
+		if reply.NumFetched < 3 {
+			break
+		}
+		args.StartIndex = reply.EndIndex
+	}
+	if len(paged) != len(allReply.UTXOs) {
+		t.Fatalf("Paging should have returned %d UTXOs but returned %d", len(allReply.UTXOs), len(paged))
+	}
+	for _, utxo := range allReply.UTXOs {
+		if !paged[string(utxo.Bytes)] {
+			t.Fatalf("Paging should have returned every UTXO")
+		}
+	}
+
+	assetID := ids.NewID([32]byte{0xff}).String()
+	assetReply := GetUTXOsReply{}
+	if err := s.GetUTXOs(nil, &GetUTXOsArgs{Addresses: addrs, AssetID: assetID}, &assetReply); err != nil {
+		t.Fatal(err)
+	}
+	if assetReply.NumFetched != 0 {
+		t.Fatalf("Shouldn't have returned UTXOs of another asset")
+	}
+}
+
+func TestGetBalanceBech32(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	s := Service{vm: vm}
+
+	addr, err := vm.FormatBech32(keys[0].PublicKey().Address().Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := GetBalanceReply{}
+	if err := s.GetBalance(nil, &GetBalanceArgs{
+		Address: addr,
+		AssetID: genesisTx.ID().String(),
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Balance.String() != "300000" {
+		t.Fatalf("Wrong balance returned from GetBalance %s", reply.Balance)
+	}
+
+	otherNetworkAddr, err := formatting.FormatAddress(
+		vm.primaryAlias(),
+		"ava", // The mainnet human readable part
+		keys[0].PublicKey().Address().Bytes(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.Parse(otherNetworkAddr); err != errWrongNetwork {
+		t.Fatalf("Should have errored due to an address of a different network")
+	}
+}
+
+func TestGetAllBalances(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{
+			&common.Fx{
+				ID: ids.Empty,
+				Fx: &secp256k1fx.Fx{},
+			},
+			&common.Fx{
+				ID: timelockfx.ID,
+				Fx: &timelockfx.Fx{},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	avaAssetID := genesisTx.ID()
+
+	// Time lock some of the asset until after the VM's current time
+	lockedUTXO := &UTXO{
+		UTXOID: UTXOID{TxID: ids.Empty},
+		Asset:  Asset{ID: avaAssetID},
+		Out: &timelockfx.TransferOutput{
+			Amt:      1000,
+			Locktime: vm.clock.Unix() + 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{keys[0].PublicKey().Address()},
+			},
+		},
+	}
+	if err := vm.state.FundUTXO(lockedUTXO); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Service{vm: vm}
+
+	reply := GetAllBalancesReply{}
+	err = s.GetAllBalances(nil, &GetAllBalancesArgs{
+		Addresses: []string{vm.Format(keys[0].PublicKey().Address().Bytes())},
+	}, &reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetIDs := []ids.ID{}
+	var avaBalance *AssetBalance
+	for i, balance := range reply.Balances {
+		assetIDs = append(assetIDs, balance.AssetID)
+		if balance.AssetID.Equals(avaAssetID) {
+			avaBalance = &reply.Balances[i]
+		}
+		if sum := new(big.Int).Add(balance.Locked.Big(), balance.Unlocked.Big()); sum.Cmp(balance.Balance.Big()) != 0 {
+			t.Fatalf("Balance of %s isn't the sum of its locked and unlocked amounts", balance.AssetID)
+		}
+	}
+	if !ids.IsSortedAndUniqueIDs(assetIDs) {
+		t.Fatalf("Balances should be sorted by asset ID")
+	}
+	if avaBalance == nil {
+		t.Fatalf("Should have returned the balance of %s", avaAssetID)
+	}
+	if avaBalance.Balance.String() != "301000" || avaBalance.Unlocked.String() != "300000" || avaBalance.Locked.String() != "1000" {
+		t.Fatalf("Wrong balance returned from GetAllBalances %s (%s unlocked, %s locked)",
+			avaBalance.Balance, avaBalance.Unlocked, avaBalance.Locked)
 	}
 }
 
@@ -186,3 +458,627 @@ func TestCreateVariableCapAsset(t *testing.T) {
 		t.Fatalf("Wrong assetID returned from CreateFixedCapAsset %s", reply.AssetID)
 	}
 }
+
+func TestSendTimeLockedUnsupported(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	reply := SendReply{}
+	if err := s.SendTimeLocked(nil, &SendTimeLockedArgs{Locktime: 0}, &reply); err != errNoLocktime {
+		t.Fatalf("Should have errored due to no locktime")
+	}
+	if err := s.SendTimeLocked(nil, &SendTimeLockedArgs{Locktime: 1}, &reply); err != errTimelockUnsupported {
+		t.Fatalf("Should have errored due to the timelock fx not being supported")
+	}
+
+	utxosReply := GetTimeLockedUTXOsReply{}
+	err = s.GetTimeLockedUTXOs(nil, &GetUTXOsArgs{
+		Addresses: []string{vm.Format(keys[0].PublicKey().Address().Bytes())},
+	}, &utxosReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(utxosReply.UTXOs) != 0 {
+		t.Fatalf("Shouldn't have returned any time locked UTXOs")
+	}
+}
+
+func TestCreateHTLCInvalid(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	reply := SendReply{}
+	args := &CreateHTLCArgs{Locktime: 1}
+	if err := s.CreateHTLC(nil, args, &reply); err != errInvalidHashLock {
+		t.Fatalf("Should have errored due to an invalid hash lock")
+	}
+	args.HashLock.Bytes = make([]byte, 32)
+	if err := s.CreateHTLC(nil, args, &reply); err != errHTLCUnsupported {
+		t.Fatalf("Should have errored due to the htlc fx not being supported")
+	}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	err = s.RedeemHTLC(nil, &RedeemHTLCArgs{HTLCArgs: HTLCArgs{TxID: genesisTx.ID()}}, &reply)
+	if err != errNotHTLC {
+		t.Fatalf("Should have errored due to the UTXO not being a contract")
+	}
+}
+
+func TestConsolidateUTXOs(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	ks := keystore.Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	ctx.Keystore = ks.NewBlockchainKeyStore(chainID)
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	username, password := "bob", "9ve3GvD2Yhq3pKqRpq9XJXdDkFafahEv"
+	if err := ks.CreateUser(nil, &keystore.CreateUserArgs{Username: username, Password: password}, &keystore.CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Service{vm: vm}
+	err = s.ImportKey(nil, &ImportKeyArgs{
+		Username:   username,
+		Password:   password,
+		PrivateKey: formatting.CB58{Bytes: keys[0].Bytes()},
+	}, &ImportKeyReply{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	args := &ConsolidateUTXOsArgs{
+		Username: username,
+		Password: password,
+		Address:  vm.Format(keys[0].PublicKey().Address().Bytes()),
+		AssetID:  genesisTx.ID().String(),
+		Below:    50000,
+	}
+	reply := ConsolidateUTXOsReply{}
+	if err := s.ConsolidateUTXOs(nil, args, &reply); err != errNothingToConsolidate {
+		t.Fatalf("Should have errored due to no UTXOs holding less than the threshold")
+	}
+
+	// The address holds UTXOs of 50000, 50000, 100000 and 100000
+	args.Below = 0
+	args.MaxUTXOs = 3
+	if err := s.ConsolidateUTXOs(nil, args, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Consolidated != 3 || reply.Amount != 200000 {
+		t.Fatalf("Should have consolidated the %d smallest UTXOs holding %d, but consolidated %d holding %d",
+			3, 200000, reply.Consolidated, reply.Amount)
+	}
+
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have issued the consolidation tx")
+	} else if !txs[0].ID().Equals(reply.TxID) {
+		t.Fatalf("Issued the wrong tx")
+	}
+}
+
+func TestBuildUnsignedTxAndIssueSignedTx(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	from := vm.Format(keys[0].PublicKey().Address().Bytes())
+	buildReply := BuildUnsignedTxReply{}
+	err = s.BuildUnsignedTx(nil, &BuildUnsignedTxArgs{
+		From:    []string{from},
+		Amount:  150000,
+		AssetID: genesisTx.ID().String(),
+		To:      vm.Format(keys[1].PublicKey().Address().Bytes()),
+	}, &buildReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buildReply.Signers) < 2 {
+		t.Fatalf("Should have spent at least %d UTXOs but spent %d", 2, len(buildReply.Signers))
+	}
+
+	sig, err := keys[0].SignHash(buildReply.Digest.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := [][]formatting.CB58{}
+	for _, signers := range buildReply.Signers {
+		if len(signers) != 1 || signers[0] != from {
+			t.Fatalf("Each input should be signed by %s but was signed by %v", from, signers)
+		}
+		sigs = append(sigs, []formatting.CB58{formatting.CB58{Bytes: sig}})
+	}
+
+	issueReply := IssueTxReply{}
+	if err := s.IssueSignedTx(nil, &IssueSignedTxArgs{Tx: buildReply.Tx, Signatures: sigs[1:]}, &issueReply); err == nil {
+		t.Fatalf("Should have errored due to a missing signature")
+	}
+	if err := s.IssueSignedTx(nil, &IssueSignedTxArgs{Tx: buildReply.Tx, Signatures: sigs}, &issueReply); err != nil {
+		t.Fatal(err)
+	}
+
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have issued the signed tx")
+	} else if !txs[0].ID().Equals(issueReply.TxID) {
+		t.Fatalf("Issued the wrong tx")
+	}
+}
+
+type testSigner struct {
+	ops []string
+}
+
+func (s *testSigner) Sign(op string, addr ids.ShortID, msg []byte) ([]byte, error) {
+	s.ops = append(s.ops, op)
+	for _, key := range keys {
+		if key.PublicKey().Address().Equals(addr) {
+			return key.Sign(msg)
+		}
+	}
+	return nil, errUnneededAddress
+}
+
+func TestSendFromSigner(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	s := Service{vm: vm}
+
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+	args := &BuildUnsignedTxArgs{
+		From:    []string{vm.Format(keys[0].PublicKey().Address().Bytes())},
+		Amount:  150000,
+		AssetID: genesisTx.ID().String(),
+		To:      vm.Format(keys[1].PublicKey().Address().Bytes()),
+	}
+
+	reply := IssueTxReply{}
+	if err := s.SendFromSigner(nil, args, &reply); err != errNoSigner {
+		t.Fatalf("Should have errored with %q but got %v", errNoSigner, err)
+	}
+
+	signer := &testSigner{}
+	ctx.Signer = signer
+	defer func() { ctx.Signer = nil }()
+
+	if err := s.SendFromSigner(nil, args, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(signer.ops) < 2 {
+		t.Fatalf("Should have signed at least %d inputs but signed %d", 2, len(signer.ops))
+	}
+	for _, op := range signer.ops {
+		if op != SignerOpSend {
+			t.Fatalf("Should have signed operation %q but signed %q", SignerOpSend, op)
+		}
+	}
+
+	if txs := vm.PendingTxs(); len(txs) != 1 {
+		t.Fatalf("Should have issued the signed tx")
+	} else if !txs[0].ID().Equals(reply.TxID) {
+		t.Fatalf("Issued the wrong tx")
+	}
+}
+
+func TestSetAndGetAssetMetadata(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	ks := keystore.Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	ctx.Keystore = ks.NewBlockchainKeyStore(chainID)
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	username, password := "bob", "9ve3GvD2Yhq3pKqRpq9XJXdDkFafahEv"
+	if err := ks.CreateUser(nil, &keystore.CreateUserArgs{Username: username, Password: password}, &keystore.CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Service{vm: vm}
+	for _, key := range keys[:2] {
+		err := s.ImportKey(nil, &ImportKeyArgs{
+			Username:   username,
+			Password:   password,
+			PrivateKey: formatting.CB58{Bytes: key.Bytes()},
+		}, &ImportKeyReply{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.GetAssetMetadata(nil, &GetAssetMetadataArgs{AssetID: "asset1"}, &GetAssetMetadataReply{}); err == nil {
+		t.Fatalf("Should have errored due to no registered metadata")
+	}
+
+	args := &SetAssetMetadataArgs{
+		Username:     username,
+		Password:     password,
+		AssetID:      "asset1",
+		Version:      1,
+		Name:         "Fixed Cap Asset",
+		Symbol:       "FCA",
+		Denomination: 2,
+		LogoURI:      "https://example.com/fca.png",
+		Issuer:       vm.Format(keys[1].PublicKey().Address().Bytes()),
+	}
+	if err := s.SetAssetMetadata(nil, args, &IssueTxReply{}); err == nil {
+		t.Fatalf("Should have errored due to the issuer not owning an initial output of the asset")
+	}
+
+	args.Issuer = vm.Format(keys[0].PublicKey().Address().Bytes())
+	setReply := IssueTxReply{}
+	if err := s.SetAssetMetadata(nil, args, &setReply); err != nil {
+		t.Fatal(err)
+	}
+
+	txs := vm.PendingTxs()
+	if len(txs) != 1 {
+		t.Fatalf("Should have issued the metadata tx")
+	}
+	txs[0].Accept()
+
+	reply := GetAssetMetadataReply{}
+	if err := s.GetAssetMetadata(nil, &GetAssetMetadataArgs{AssetID: "asset1"}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case !reply.TxID.Equals(setReply.TxID):
+		t.Fatalf("Wrong metadata tx returned")
+	case reply.Version != 1:
+		t.Fatalf("Wrong version returned: %d", reply.Version)
+	case reply.Name != args.Name || reply.Symbol != args.Symbol || reply.Denomination != args.Denomination || reply.LogoURI != args.LogoURI:
+		t.Fatalf("Wrong metadata returned: %+v", reply)
+	case reply.Issuer != args.Issuer:
+		t.Fatalf("Wrong issuer returned: %s", reply.Issuer)
+	}
+
+	args.Name = "Renamed Fixed Cap Asset"
+	if err := s.SetAssetMetadata(nil, args, &IssueTxReply{}); err == nil {
+		t.Fatalf("Should have errored due to the version not being increased")
+	}
+}
+
+func TestServiceED25519Keys(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	ks := keystore.Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	ctx.Keystore = ks.NewBlockchainKeyStore(chainID)
+	defer func() { ctx.Keystore = nil }()
+
+	vm := &VM{}
+	err := vm.Initialize(
+		ctx,
+		memdb.New(),
+		genesisBytes,
+		make(chan common.Message, 1),
+		[]*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Shutdown()
+
+	username, password := "bob", "9ve3GvD2Yhq3pKqRpq9XJXdDkFafahEv"
+	if err := ks.CreateUser(nil, &keystore.CreateUserArgs{Username: username, Password: password}, &keystore.CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := Service{vm: vm}
+	createReply := CreateAddressReply{}
+	if err := s.CreateAddress(nil, &CreateAddressArgs{
+		Username: username,
+		Password: password,
+		KeyType:  keyTypeED25519,
+	}, &createReply); err != nil {
+		t.Fatal(err)
+	}
+
+	exportReply := ExportKeyReply{}
+	if err := s.ExportKey(nil, &ExportKeyArgs{
+		Username: username,
+		Password: password,
+		Address:  createReply.Address,
+		KeyType:  keyTypeED25519,
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	factory := crypto.FactoryED25519{}
+	sk, err := factory.ToPrivateKey(exportReply.PrivateKey.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr := vm.Format(sk.PublicKey().Address().Bytes()); addr != createReply.Address {
+		t.Fatalf("Exported the key of %s rather than %s", addr, createReply.Address)
+	}
+
+	// The ed25519 key isn't one of the user's secp256k1 keys
+	if err := s.ExportKey(nil, &ExportKeyArgs{
+		Username: username,
+		Password: password,
+		Address:  createReply.Address,
+	}, &ExportKeyReply{}); err == nil {
+		t.Fatalf("Should have errored due to exporting an ed25519 key as a secp256k1 key")
+	}
+
+	importReply := ImportKeyReply{}
+	if err := s.ImportKey(nil, &ImportKeyArgs{
+		Username:   username,
+		Password:   password,
+		PrivateKey: exportReply.PrivateKey,
+		KeyType:    keyTypeED25519,
+	}, &importReply); err != nil {
+		t.Fatal(err)
+	}
+	if importReply.Address != createReply.Address {
+		t.Fatalf("Imported the key of %s rather than %s", importReply.Address, createReply.Address)
+	}
+
+	if err := s.CreateAddress(nil, &CreateAddressArgs{
+		Username: username,
+		Password: password,
+		KeyType:  "rsa",
+	}, &CreateAddressReply{}); err == nil {
+		t.Fatalf("Should have errored due to an unknown key type")
+	}
+}
+
+func TestExportImportStatus(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+	genesisTx := GetFirstTxFromGenesisTest(genesisBytes, t)
+
+	db := memdb.New()
+	m := &atomic.Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), db))
+
+	exporter := atomicVM(t, db, m, chainID, genesisBytes)
+	defer exporter.Shutdown()
+	importer := atomicVM(t, db, m, peerChainID, genesisBytes)
+	defer importer.Shutdown()
+
+	ks := keystore.Keystore{}
+	ks.Initialize(logging.NoLog{}, memdb.New())
+	exporter.ctx.Keystore = ks.NewBlockchainKeyStore(chainID)
+	importer.ctx.Keystore = ks.NewBlockchainKeyStore(peerChainID)
+
+	username, password := "bob", "9ve3GvD2Yhq3pKqRpq9XJXdDkFafahEv"
+	if err := ks.CreateUser(nil, &keystore.CreateUserArgs{Username: username, Password: password}, &keystore.CreateUserReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	exportService := Service{vm: exporter}
+	if err := exportService.ImportKey(nil, &ImportKeyArgs{
+		Username:   username,
+		Password:   password,
+		PrivateKey: formatting.CB58{Bytes: keys[0].Bytes()},
+	}, &ImportKeyReply{}); err != nil {
+		t.Fatal(err)
+	}
+	importService := Service{vm: importer}
+	if err := importService.ImportKey(nil, &ImportKeyArgs{
+		Username:   username,
+		Password:   password,
+		PrivateKey: formatting.CB58{Bytes: keys[1].Bytes()},
+	}, &ImportKeyReply{}); err != nil {
+		t.Fatal(err)
+	}
+
+	to := importer.Format(keys[1].PublicKey().Address().Bytes())
+	exportReply := ExportReply{}
+	if err := exportService.Export(nil, &ExportArgs{
+		Username: username,
+		Password: password,
+		Amount:   60000,
+		AssetID:  genesisTx.ID().String(),
+		To:       exporter.Format(keys[1].PublicKey().Address().Bytes()),
+	}, &exportReply); err != errExportToSelf {
+		t.Fatalf("Shouldn't be able to export to the chain itself")
+	}
+	if err := exportService.Export(nil, &ExportArgs{
+		Username: username,
+		Password: password,
+		Amount:   60000,
+		AssetID:  genesisTx.ID().String(),
+		To:       fmt.Sprintf("%s%s%s", ids.NewID([32]byte{11}), addressSep, formatting.CB58{Bytes: keys[1].PublicKey().Address().Bytes()}),
+	}, &exportReply); !errors.Is(err, errCantImport) {
+		t.Fatalf("Shouldn't be able to export to a chain that can't import the funds, got %v", err)
+	}
+	if err := exportService.Export(nil, &ExportArgs{
+		Username: username,
+		Password: password,
+		Amount:   60000,
+		AssetID:  genesisTx.ID().String(),
+		To:       to,
+	}, &exportReply); err != nil {
+		t.Fatal(err)
+	}
+	if len(exportReply.UTXOIDs) != 1 {
+		t.Fatalf("Should have exported one UTXO")
+	}
+
+	statusArgs := &GetExportStatusArgs{TxID: exportReply.TxID}
+	statusReply := GetExportStatusReply{}
+	if err := exportService.GetExportStatus(nil, statusArgs, &statusReply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case statusReply.Status != transferExporting:
+		t.Fatalf("Transfer should be %s, but is %s", transferExporting, statusReply.Status)
+	case statusReply.ExportStatus != choices.Processing:
+		t.Fatalf("Export should be processing, but is %s", statusReply.ExportStatus)
+	case !statusReply.DestinationChain.Equals(peerChainID):
+		t.Fatalf("Wrong destination chain")
+	}
+
+	exportTx, err := exporter.GetTx(exportReply.TxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exportTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	exportTx.Accept()
+
+	statusReply = GetExportStatusReply{}
+	if err := exportService.GetExportStatus(nil, statusArgs, &statusReply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case statusReply.Status != transferExported:
+		t.Fatalf("Transfer should be %s, but is %s", transferExported, statusReply.Status)
+	case len(statusReply.PendingUTXOIDs) != 1 || !statusReply.PendingUTXOIDs[0].Equals(exportReply.UTXOIDs[0]):
+		t.Fatalf("The exported UTXO should be pending import")
+	}
+
+	importReply := IssueTxReply{}
+	if err := importService.Import(nil, &ImportArgs{
+		Username:    username,
+		Password:    password,
+		SourceChain: chainID.String(),
+		UTXOIDs:     exportReply.UTXOIDs,
+		To:          to,
+	}, &importReply); err != nil {
+		t.Fatal(err)
+	}
+	importTx, err := importer.GetTx(importReply.TxID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := importTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	importTx.Accept()
+
+	statusReply = GetExportStatusReply{}
+	if err := exportService.GetExportStatus(nil, statusArgs, &statusReply); err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case statusReply.Status != transferImported:
+		t.Fatalf("Transfer should be %s, but is %s", transferImported, statusReply.Status)
+	case len(statusReply.PendingUTXOIDs) != 0:
+		t.Fatalf("No UTXOs should be pending import")
+	}
+}
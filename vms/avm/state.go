@@ -5,6 +5,7 @@ package avm
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/ids"
@@ -174,3 +175,96 @@ func (s *state) SetIDs(id ids.ID, idSlice []ids.ID) error {
 
 	return s.vm.db.Put(id.Bytes(), bytes)
 }
+
+// ID returns an ID from storage
+func (s *state) ID(id ids.ID) (ids.ID, error) {
+	if idIntf, found := s.c.Get(id); found {
+		if value, ok := idIntf.(ids.ID); ok {
+			return value, nil
+		}
+		return ids.ID{}, errCacheTypeMismatch
+	}
+
+	bytes, err := s.vm.db.Get(id.Bytes())
+	if err != nil {
+		return ids.ID{}, err
+	}
+
+	value, err := ids.ToID(bytes)
+	if err != nil {
+		return ids.ID{}, err
+	}
+
+	s.c.Put(id, value)
+	return value, nil
+}
+
+// SetID saves an ID to storage.
+func (s *state) SetID(id ids.ID, value ids.ID) error {
+	s.c.Put(id, value)
+	return s.vm.db.Put(id.Bytes(), value.Bytes())
+}
+
+// Uint64 returns a uint64 from storage
+func (s *state) Uint64(id ids.ID) (uint64, error) {
+	if valueIntf, found := s.c.Get(id); found {
+		if value, ok := valueIntf.(uint64); ok {
+			return value, nil
+		}
+		return 0, errCacheTypeMismatch
+	}
+
+	bytes, err := s.vm.db.Get(id.Bytes())
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	if err := s.vm.codec.Unmarshal(bytes, &value); err != nil {
+		return 0, err
+	}
+
+	s.c.Put(id, value)
+	return value, nil
+}
+
+// SetUint64 saves a uint64 to storage.
+func (s *state) SetUint64(id ids.ID, value uint64) error {
+	s.c.Put(id, value)
+
+	bytes, err := s.vm.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.vm.db.Put(id.Bytes(), bytes)
+}
+
+// BigInt returns a non-negative big integer from storage
+func (s *state) BigInt(id ids.ID) (*big.Int, error) {
+	if valueIntf, found := s.c.Get(id); found {
+		if value, ok := valueIntf.(*big.Int); ok {
+			return new(big.Int).Set(value), nil
+		}
+		return nil, errCacheTypeMismatch
+	}
+
+	bytes, err := s.vm.db.Get(id.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	value := new(big.Int).SetBytes(bytes)
+	s.c.Put(id, value)
+	return new(big.Int).Set(value), nil
+}
+
+// SetBigInt saves a non-negative big integer to storage. Zero isn't stored.
+func (s *state) SetBigInt(id ids.ID, value *big.Int) error {
+	if value.Sign() <= 0 {
+		s.c.Evict(id)
+		return s.vm.db.Delete(id.Bytes())
+	}
+
+	s.c.Put(id, new(big.Int).Set(value))
+	return s.vm.db.Put(id.Bytes(), value.Bytes())
+}
@@ -0,0 +1,102 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package avm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNoFeeAsset      = errors.New("chain has no fee asset to exchange with the Platform Chain")
+	errNotTransferable = errors.New("only outputs of the fee asset that one key can spend at any time can be exported to the Platform Chain")
+)
+
+// platformChainID is the ID of the Platform Chain, which is the same on every
+// network. It holds $AVA in accounts rather than in UTXOs, so this VM
+// exchanges the fee asset, which is $AVA, with it as atomic.Transfers.
+var platformChainID = ids.Empty
+
+// transfer returns [out], the output [utxoID], as the transfer the Platform
+// Chain imports. An account is controlled by a single key, so only outputs
+// that a single key can spend at any time can be exported.
+func (vm *VM) transfer(utxoID *UTXOID, out *TransferableOutput) (*atomic.Transfer, error) {
+	if vm.feeAssetID.IsZero() {
+		return nil, errNoFeeAsset
+	}
+	transferOut, ok := out.Out.(*secp256k1fx.TransferOutput)
+	switch {
+	case !out.AssetID().Equals(vm.feeAssetID), !ok:
+		return nil, errNotTransferable
+	case transferOut.Locktime != 0, transferOut.Threshold != 1, len(transferOut.Addrs) != 1:
+		return nil, errNotTransferable
+	}
+	return &atomic.Transfer{
+		SourceTx: utxoID.TxID,
+		Index:    utxoID.OutputIndex,
+		Amount:   transferOut.Amt,
+		To:       transferOut.Addrs[0],
+	}, nil
+}
+
+// transferUTXO returns the UTXO of the fee asset that [t], a transfer exported
+// by the Platform Chain, is imported as
+func (vm *VM) transferUTXO(t *atomic.Transfer) (*UTXO, error) {
+	if vm.feeAssetID.IsZero() {
+		return nil, errNoFeeAsset
+	}
+	return &UTXO{
+		UTXOID: UTXOID{
+			TxID:        t.SourceTx,
+			OutputIndex: t.Index,
+		},
+		Asset: Asset{
+			ID: vm.feeAssetID,
+		},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: t.Amount,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{t.To},
+			},
+		},
+	}, nil
+}
+
+// sharedUTXOs returns the UTXOs [sourceChain] exported to this chain under
+// [keys]. The transfers exported by the Platform Chain are returned as the
+// UTXOs they're imported as.
+func (vm *VM) sharedUTXOs(sourceChain ids.ID, keys [][]byte) ([]*UTXO, error) {
+	values, err := vm.ctx.SharedMemory.Get(sourceChain, keys)
+	if err != nil {
+		return nil, errMissingImported
+	}
+	utxos := make([]*UTXO, len(values))
+	for i, value := range values {
+		if !sourceChain.Equals(platformChainID) {
+			utxo := &UTXO{}
+			if err := vm.codec.Unmarshal(value, utxo); err != nil {
+				return nil, err
+			}
+			utxos[i] = utxo
+			continue
+		}
+
+		transferID, err := ids.ToID(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		t, err := atomic.ParseTransfer(transferID, value)
+		if err != nil {
+			return nil, err
+		}
+		if utxos[i], err = vm.transferUTXO(t); err != nil {
+			return nil, err
+		}
+	}
+	return utxos, nil
+}
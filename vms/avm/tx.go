@@ -6,6 +6,7 @@ package avm
 import (
 	"errors"
 
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/ids"
 
 	"github.com/ava-labs/gecko/snow"
@@ -34,6 +35,12 @@ type UnsignedTx interface {
 	SemanticVerify(vm *VM, uTx *UniqueTx, creds []*Credential) error
 }
 
+// atomicTx is a transaction that changes the memory this chain shares with
+// other chains once it's accepted
+type atomicTx interface {
+	AtomicRequests(vm *VM) (map[[32]byte]*atomic.Requests, error)
+}
+
 // Tx is the core operation that can be performed. The tx uses the UTXO model.
 // Specifically, a txs inputs will consume previous txs outputs. A tx will be
 // valid if the inputs have the authority to consume the outputs they are
@@ -79,5 +86,12 @@ func (t *Tx) SemanticVerify(vm *VM, uTx *UniqueTx) error {
 		return errNilTx
 	}
 
-	return t.UnsignedTx.SemanticVerify(vm, uTx, t.Creds)
+	// The signatures of the transaction are verified together once the rest of
+	// the transaction has been verified
+	vm.startBatches()
+	err := t.UnsignedTx.SemanticVerify(vm, uTx, t.Creds)
+	if batchErr := vm.verifyBatches(); err == nil {
+		err = batchErr
+	}
+	return err
 }
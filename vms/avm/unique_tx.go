@@ -101,7 +101,18 @@ func (tx *UniqueTx) Accept() {
 	}
 
 	// Remove spent utxos
-	for _, utxoID := range tx.InputIDs().List() {
+	addrs := ids.Set{}
+	for _, in := range tx.InputUTXOs() {
+		if in.Symbol {
+			continue // Imported utxos are removed from shared memory
+		}
+		utxoID := in.InputID()
+		utxo, err := tx.vm.state.UTXO(utxoID)
+		if err != nil {
+			tx.vm.ctx.Log.Error("Failed to spend utxo %s due to %s", utxoID, err)
+			return
+		}
+		addrs.Union(utxoAddresses(utxo))
 		if err := tx.vm.state.SpendUTXO(utxoID); err != nil {
 			tx.vm.ctx.Log.Error("Failed to spend utxo %s due to %s", utxoID, err)
 			return
@@ -110,6 +121,7 @@ func (tx *UniqueTx) Accept() {
 
 	// Add new utxos
 	for _, utxo := range tx.UTXOs() {
+		addrs.Union(utxoAddresses(utxo))
 		if err := tx.vm.state.FundUTXO(utxo); err != nil {
 			tx.vm.ctx.Log.Error("Failed to fund utxo %s due to %s", utxoID, err)
 			return
@@ -117,9 +129,27 @@ func (tx *UniqueTx) Accept() {
 	}
 
 	txID := tx.ID()
+	for _, addr := range addrs.List() {
+		if err := tx.vm.state.AddAddressTx(addr, txID); err != nil {
+			tx.vm.ctx.Log.Error("Failed to index accepted tx %s by address due to %s", txID, err)
+			return
+		}
+	}
+	if metadataTx, ok := tx.t.tx.UnsignedTx.(*AssetMetadataTx); ok {
+		if err := tx.vm.setAssetMetadata(txID, metadataTx); err != nil {
+			tx.vm.ctx.Log.Error("Failed to register asset metadata of %s due to %s", txID, err)
+			return
+		}
+	}
+
+	if err := tx.vm.state.AddAcceptedTx(txID); err != nil {
+		tx.vm.ctx.Log.Error("Failed to index accepted tx %s due to %s", txID, err)
+		return
+	}
+
 	tx.vm.ctx.Log.Verbo("Accepting Tx: %s", txID)
 
-	if err := tx.vm.db.Commit(); err != nil {
+	if err := tx.vm.commitAccept(tx.t.tx.UnsignedTx); err != nil {
 		tx.vm.ctx.Log.Error("Failed to commit accept %s due to %s", tx.txID, err)
 	}
 
@@ -146,6 +176,13 @@ func (tx *UniqueTx) Reject() {
 		tx.vm.ctx.Log.Error("Failed to commit reject %s due to %s", tx.txID, err)
 	}
 
+	// The transaction's status is kept, so it's never issued again
+	if pruner := tx.vm.ctx.Pruner; pruner != nil {
+		if err := pruner.Schedule(tx.vm.state.TxKey(txID)); err != nil {
+			tx.vm.ctx.Log.Warn("Failed to schedule rejected tx %s to be pruned due to %s", txID, err)
+		}
+	}
+
 	tx.vm.pubsub.Publish("rejected", txID)
 
 	tx.t.deps = nil // Needed to prevent a memory leak
@@ -170,6 +207,9 @@ func (tx *UniqueTx) Dependencies() []snowstorm.Tx {
 
 	txIDs := ids.Set{}
 	for _, in := range tx.InputUTXOs() {
+		if in.Symbol {
+			continue // Imported utxos were produced on another chain
+		}
 		txID, _ := in.InputSource()
 		if !txIDs.Contains(txID) {
 			txIDs.Add(txID)
@@ -278,6 +318,16 @@ func (tx *UniqueTx) SemanticVerify() error {
 	return tx.t.validity
 }
 
+// reverify discards the cached result of verifying this transaction and
+// verifies it again
+func (tx *UniqueTx) reverify() error {
+	tx.refresh()
+	tx.t.verifiedTx = false
+	tx.t.verifiedState = false
+	tx.t.validity = nil
+	return tx.Verify()
+}
+
 // UnsignedBytes returns the unsigned bytes of the transaction
 func (tx *UniqueTx) UnsignedBytes() []byte {
 	b, err := tx.vm.codec.Marshal(&tx.t.tx.UnsignedTx)
@@ -4,13 +4,44 @@
 package avm
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/prefixdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/hashing"
 )
 
-var addresses = ids.Empty
+// Key types a user can store
+const (
+	keyTypeSECP256K1 = "secp256k1"
+	keyTypeED25519   = "ed25519"
+)
+
+var (
+	addresses = ids.Empty
+
+	// ed25519 keys, and their addresses, are kept apart from the secp256k1
+	// keys so that endpoints spending with secp256k1 keys never load them
+	ed25519Prefix = []byte("ed25519")
+
+	errUnknownKeyType = errors.New("unknown key type")
+)
+
+// keyStore returns the database that keys of [keyType] are stored in, and the
+// factory that creates and parses them. The empty key type is secp256k1.
+func keyStore(db database.Database, keyType string) (database.Database, crypto.Factory, error) {
+	switch keyType {
+	case "", keyTypeSECP256K1:
+		return db, &crypto.FactorySECP256K1R{}, nil
+	case keyTypeED25519:
+		return prefixdb.New(ed25519Prefix, db), &crypto.FactoryED25519{}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", errUnknownKeyType, keyType)
+	}
+}
 
 type userState struct{ vm *VM }
 
@@ -34,20 +65,22 @@ func (s *userState) Addresses(db database.Database) ([]ids.ID, error) {
 	return addresses, nil
 }
 
-func (s *userState) SetKey(db database.Database, sk *crypto.PrivateKeySECP256K1R) error {
+func (s *userState) SetKey(db database.Database, sk crypto.PrivateKey) error {
 	return db.Put(hashing.ComputeHash256(sk.PublicKey().Address().Bytes()), sk.Bytes())
 }
 
 func (s *userState) Key(db database.Database, address ids.ID) (*crypto.PrivateKeySECP256K1R, error) {
-	factory := crypto.FactorySECP256K1R{}
-
-	bytes, err := db.Get(address.Bytes())
+	sk, err := s.FactoryKey(db, &crypto.FactorySECP256K1R{}, address)
 	if err != nil {
 		return nil, err
 	}
-	sk, err := factory.ToPrivateKey(bytes)
+	return sk.(*crypto.PrivateKeySECP256K1R), nil
+}
+
+func (s *userState) FactoryKey(db database.Database, factory crypto.Factory, address ids.ID) (crypto.PrivateKey, error) {
+	bytes, err := db.Get(address.Bytes())
 	if err != nil {
 		return nil, err
 	}
-	return sk.(*crypto.PrivateKeySECP256K1R), nil
+	return factory.ToPrivateKey(bytes)
 }
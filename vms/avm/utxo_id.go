@@ -20,6 +20,10 @@ type UTXOID struct {
 	TxID        ids.ID `serialize:"true"`
 	OutputIndex uint32 `serialize:"true"`
 
+	// Symbol is true if the UTXO was exported to this chain by another chain,
+	// so it isn't in this chain's UTXO set
+	Symbol bool
+
 	// Cached:
 	id ids.ID
 }
@@ -4,8 +4,10 @@
 package avm
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"strings"
 	"time"
@@ -21,9 +23,12 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/math"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/utils/wrappers"
 	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 
 	cjson "github.com/ava-labs/gecko/utils/json"
 )
@@ -35,6 +40,9 @@ const (
 	idCacheSize    = 10000
 	txCacheSize    = 10000
 	addressSep     = "-"
+
+	// version of this VM. Changes whenever the rules of the VM change.
+	version = "avm/0.1.0"
 )
 
 var (
@@ -43,12 +51,30 @@ var (
 	errGenesisAssetMustHaveState = errors.New("genesis asset must have non-empty state")
 	errInvalidAddress            = errors.New("invalid address")
 	errWrongBlockchainID         = errors.New("wrong blockchain ID")
+	errWrongNetwork              = errors.New("address is for a different network")
 )
 
 // VM implements the avalanche.DAGVM interface
 type VM struct {
 	ids.Aliaser
 
+	// Limits on the transactions waiting to be issued to consensus
+	MempoolConfig mempool.Config
+
+	// Alias or ID of the asset that transactions pay fees in. The amount of
+	// this asset burned by a transaction is treated as its fee. If empty, no
+	// transaction pays a fee.
+	FeeAsset string
+
+	// Maximum number of times a transaction issued to this VM is re-issued
+	// after being dropped before entering consensus. If 0, dropped
+	// transactions aren't re-issued.
+	ReissueLimit int
+
+	// Limits on the resources used to parse containers. If zero, the codec's
+	// default limits are used.
+	CodecLimits codec.Limits
+
 	// Contains information of where this VM is executing
 	ctx *snow.Context
 
@@ -62,10 +88,14 @@ type VM struct {
 	// State management
 	state *prefixedState
 
+	// Asset that transactions pay fees in. Empty if there isn't one.
+	feeAssetID ids.ID
+
 	// Transaction issuing
 	timer        *timer.Timer
 	batchTimeout time.Duration
-	txs          []snowstorm.Tx
+	mempool      mempool.Mempool
+	reissuer     reissuer
 	toEngine     chan<- common.Message
 
 	baseDB database.Database
@@ -86,8 +116,12 @@ func (cr *codecRegistry) RegisterType(val interface{}) error {
 	cr.typeToFxIndex[valType] = cr.index
 	return cr.codec.RegisterType(val)
 }
+func (cr *codecRegistry) Skip(num int)                              { cr.codec.Skip(num) }
 func (cr *codecRegistry) Marshal(val interface{}) ([]byte, error)   { return cr.codec.Marshal(val) }
 func (cr *codecRegistry) Unmarshal(b []byte, val interface{}) error { return cr.codec.Unmarshal(b, val) }
+func (cr *codecRegistry) UnmarshalFrom(r io.Reader, val interface{}) error {
+	return cr.codec.UnmarshalFrom(r, val)
+}
 
 /*
  ******************************************************************************
@@ -107,6 +141,11 @@ func (vm *VM) Initialize(
 	vm.toEngine = toEngine
 	vm.baseDB = db
 	vm.db = versiondb.New(db)
+	if ctx.Metrics != nil {
+		if err := vm.db.Register(ctx.Namespace, ctx.Metrics); err != nil {
+			ctx.Log.Error("Failed to register versiondb statistics due to %s", err)
+		}
+	}
 	vm.typeToFxIndex = map[reflect.Type]int{}
 	vm.Aliaser.Initialize()
 
@@ -122,6 +161,9 @@ func (vm *VM) Initialize(
 		return errs.Err
 	}
 
+	mempoolConfig := vm.MempoolConfig.ChainConfig(ctx.ChainID, ctx.BCLookup.Lookup)
+	vm.mempool.Initialize(ctx.Log, mempoolConfig, ctx.Namespace, ctx.Metrics)
+
 	vm.state = &prefixedState{
 		state: &state{
 			c:  &cache.LRU{Size: stateCacheSize},
@@ -136,45 +178,35 @@ func (vm *VM) Initialize(
 		uniqueTx: &cache.EvictableLRU{Size: txCacheSize},
 	}
 
-	c := codec.NewDefault()
-	c.RegisterType(&BaseTx{})
-	c.RegisterType(&CreateAssetTx{})
-	c.RegisterType(&OperationTx{})
-
-	vm.fxs = make([]*parsedFx, len(fxs))
-	for i, fxContainer := range fxs {
-		if fxContainer == nil {
-			return errIncompatibleFx
-		}
-		fx, ok := fxContainer.Fx.(Fx)
-		if !ok {
-			return errIncompatibleFx
-		}
-		vm.fxs[i] = &parsedFx{
-			ID: fxContainer.ID,
-			Fx: fx,
-		}
-		vm.codec = &codecRegistry{
-			index:         i,
-			typeToFxIndex: vm.typeToFxIndex,
-			codec:         c,
-		}
-		if err := fx.Initialize(vm); err != nil {
-			return err
-		}
+	if err := vm.initFxs(fxs); err != nil {
+		return err
 	}
 
-	vm.codec = c
-
 	if err := vm.initAliases(genesisBytes); err != nil {
 		return err
 	}
 
+	if vm.FeeAsset != "" {
+		feeAssetID, err := vm.Lookup(vm.FeeAsset)
+		if err != nil {
+			feeAssetID, err = ids.FromString(vm.FeeAsset)
+			if err != nil {
+				return fmt.Errorf("problem parsing fee asset %s: %w", vm.FeeAsset, err)
+			}
+		}
+		vm.feeAssetID = feeAssetID
+	}
+
 	if dbStatus, err := vm.state.DBInitialized(); err != nil || dbStatus == choices.Unknown {
 		if err := vm.initState(genesisBytes); err != nil {
 			return err
 		}
 	}
+	if indexStatus, err := vm.state.AddressIndexInitialized(); err != nil || indexStatus == choices.Unknown {
+		if err := vm.initAddressIndex(); err != nil {
+			return fmt.Errorf("problem building the address index: %w", err)
+		}
+	}
 
 	vm.timer = timer.NewTimer(func() {
 		ctx.Lock.Lock()
@@ -185,12 +217,20 @@ func (vm *VM) Initialize(
 	go ctx.Log.RecoverAndPanic(vm.timer.Dispatch)
 	vm.batchTimeout = batchTimeout
 
+	if err := vm.reissuer.Initialize(vm, vm.ReissueLimit); err != nil {
+		return err
+	}
+
 	return vm.db.Commit()
 }
 
 // Shutdown implements the avalanche.DAGVM interface
 func (vm *VM) Shutdown() {
 	vm.timer.Stop()
+	vm.reissuer.Shutdown()
+	if err := vm.db.Commit(); err != nil {
+		vm.ctx.Log.Error("Flushing the database failed with %s", err)
+	}
 	if err := vm.baseDB.Close(); err != nil {
 		vm.ctx.Log.Error("Closing the database failed with %s", err)
 	}
@@ -226,8 +266,11 @@ func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
 func (vm *VM) PendingTxs() []snowstorm.Tx {
 	vm.timer.Cancel()
 
-	txs := vm.txs
-	vm.txs = nil
+	pending := vm.mempool.PopAll()
+	txs := make([]snowstorm.Tx, len(pending))
+	for i, tx := range pending {
+		txs[i] = tx.(snowstorm.Tx)
+	}
 	return txs
 }
 
@@ -245,6 +288,60 @@ func (vm *VM) GetTx(txID ids.ID) (snowstorm.Tx, error) {
 	return tx, tx.Verify()
 }
 
+// NumAcceptedTxs returns the number of transactions that have been accepted,
+// including the genesis transactions
+func (vm *VM) NumAcceptedTxs() (uint64, error) { return vm.state.NumAcceptedTxs() }
+
+// GetTxIDAtIndex returns the ID of the transaction that was accepted at
+// [index]. The genesis transactions are accepted first, in order.
+func (vm *VM) GetTxIDAtIndex(index uint64) (ids.ID, error) {
+	return vm.state.AcceptedTxID(index)
+}
+
+// NumAddressTxs returns the number of accepted transactions that the 32 byte
+// representation of an address is referenced in
+func (vm *VM) NumAddressTxs(addr ids.ID) (uint64, error) { return vm.state.NumAddressTxs(addr) }
+
+// GetAddressTxID returns the ID of the [index]th accepted transaction that the
+// 32 byte representation of an address is referenced in. Transactions are
+// indexed in the order they were accepted.
+func (vm *VM) GetAddressTxID(addr ids.ID, index uint64) (ids.ID, error) {
+	return vm.state.AddressTxID(addr, index)
+}
+
+// Version implements the common.VersionedVM interface
+func (vm *VM) Version() string { return version }
+
+// Upgrades implements the common.VersionedVM interface
+func (vm *VM) Upgrades() []string { return nil }
+
+// ContainerSubjects implements the common.SubscribableVM interface. The
+// subjects of a transaction are the addresses and assets of the UTXOs it
+// produces.
+func (vm *VM) ContainerSubjects(container []byte) ([]ids.ShortID, []ids.ID, error) {
+	tx := &Tx{}
+	if err := vm.codec.Unmarshal(container, tx); err != nil {
+		return nil, nil, err
+	}
+	tx.Initialize(container)
+
+	addrs := ids.ShortSet{}
+	assets := ids.Set{}
+	for _, utxo := range tx.UTXOs() {
+		assets.Add(utxo.AssetID())
+		addressable, ok := utxo.Out.(FxAddressable)
+		if !ok {
+			continue
+		}
+		for _, addrBytes := range addressable.Addresses() {
+			if addr, err := ids.ToShortID(addrBytes); err == nil {
+				addrs.Add(addr)
+			}
+		}
+	}
+	return addrs.List(), assets.List(), nil
+}
+
 /*
  ******************************************************************************
  ********************************** JSON API **********************************
@@ -256,6 +353,9 @@ func (vm *VM) GetTx(txID ids.ID) (snowstorm.Tx, error) {
 // either accepted or rejected with the appropriate status. This function will
 // go out of scope when the transaction is removed from memory.
 func (vm *VM) IssueTx(b []byte, onDecide func(choices.Status)) (ids.ID, error) {
+	if vm.ctx.ReadOnly {
+		return ids.ID{}, snow.ErrReadOnly
+	}
 	tx, err := vm.parseTx(b)
 	if err != nil {
 		return ids.ID{}, err
@@ -263,7 +363,10 @@ func (vm *VM) IssueTx(b []byte, onDecide func(choices.Status)) (ids.ID, error) {
 	if err := tx.Verify(); err != nil {
 		return ids.ID{}, err
 	}
-	vm.issueTx(tx)
+	if err := vm.issueTx(tx); err != nil {
+		return ids.ID{}, err
+	}
+	vm.reissuer.Track(tx.ID())
 	tx.t.onDecide = onDecide
 	return tx.ID(), nil
 }
@@ -288,6 +391,56 @@ func (vm *VM) GetUTXOs(addrs ids.Set) ([]*UTXO, error) {
 	return utxos, nil
 }
 
+// GetPaginatedUTXOs returns at most [limit] of the utxos that at least one of
+// the provided addresses is referenced in, and that are of [assetID] unless
+// [assetID] is zero. The utxos are ordered by address, then by utxo ID, and the
+// first one returned is the one after the utxo [startUTXOID] of [startAddr],
+// unless [startAddr] is zero. The address and ID of the last utxo examined are
+// returned too, so that the next page can start after it.
+//
+// A utxo referenced by several addresses is returned once per page, but may be
+// returned again on a later page.
+func (vm *VM) GetPaginatedUTXOs(addrs ids.Set, startAddr, startUTXOID, assetID ids.ID, limit int) ([]*UTXO, ids.ID, ids.ID, error) {
+	addrList := addrs.List()
+	ids.SortIDs(addrList)
+
+	lastAddr, lastUTXOID := startAddr, startUTXOID
+	seen := ids.Set{}
+	utxos := []*UTXO{}
+	for _, addr := range addrList {
+		if !startAddr.IsZero() && bytes.Compare(addr.Bytes(), startAddr.Bytes()) < 0 {
+			continue
+		}
+		funds, _ := vm.state.Funds(addr)
+		utxoIDs := append([]ids.ID(nil), funds...) // Funds may be cached, so it isn't sorted in place
+		ids.SortIDs(utxoIDs)
+
+		for _, utxoID := range utxoIDs {
+			if !startAddr.IsZero() && addr.Equals(startAddr) && bytes.Compare(utxoID.Bytes(), startUTXOID.Bytes()) <= 0 {
+				continue
+			}
+			if len(utxos) >= limit {
+				return utxos, lastAddr, lastUTXOID, nil
+			}
+			lastAddr, lastUTXOID = addr, utxoID
+			if seen.Contains(utxoID) {
+				continue
+			}
+			seen.Add(utxoID)
+
+			utxo, err := vm.state.UTXO(utxoID)
+			if err != nil {
+				return nil, ids.ID{}, ids.ID{}, err
+			}
+			if !assetID.IsZero() && !utxo.AssetID().Equals(assetID) {
+				continue
+			}
+			utxos = append(utxos, utxo)
+		}
+	}
+	return utxos, lastAddr, lastUTXOID, nil
+}
+
 /*
  ******************************************************************************
  *********************************** Fx API ***********************************
@@ -309,7 +462,7 @@ func (vm *VM) Codec() codec.Codec { return vm.codec }
 // FlushTxs into consensus
 func (vm *VM) FlushTxs() {
 	vm.timer.Cancel()
-	if len(vm.txs) != 0 {
+	if vm.mempool.Len() != 0 {
 		select {
 		case vm.toEngine <- common.PendingTxs:
 		default:
@@ -325,6 +478,51 @@ func (vm *VM) FlushTxs() {
  ******************************************************************************
  */
 
+// initFxs initializes [fxs] and sets the codec of this VM to one that can
+// parse the types of this VM and of [fxs]
+func (vm *VM) initFxs(fxs []*common.Fx) error {
+	limits := vm.CodecLimits
+	if limits == (codec.Limits{}) {
+		limits = codec.DefaultLimits()
+	}
+	c := codec.NewVersioned(0, limits)
+	c.RegisterType(&BaseTx{})
+	c.RegisterType(&CreateAssetTx{})
+	c.RegisterType(&OperationTx{})
+
+	vm.fxs = make([]*parsedFx, len(fxs))
+	for i, fxContainer := range fxs {
+		if fxContainer == nil {
+			return errIncompatibleFx
+		}
+		fx, ok := fxContainer.Fx.(Fx)
+		if !ok {
+			return errIncompatibleFx
+		}
+		vm.fxs[i] = &parsedFx{
+			ID: fxContainer.ID,
+			Fx: fx,
+		}
+		vm.codec = &codecRegistry{
+			index:         i,
+			typeToFxIndex: vm.typeToFxIndex,
+			codec:         c,
+		}
+		if err := fx.Initialize(vm); err != nil {
+			return err
+		}
+	}
+
+	// Registered after the fxs' types so the type IDs of the fxs' types are
+	// unchanged
+	c.RegisterType(&AssetMetadataTx{})
+	c.RegisterType(&ExportTx{})
+	c.RegisterType(&ImportTx{})
+
+	vm.codec = c
+	return nil
+}
+
 func (vm *VM) initAliases(genesisBytes []byte) error {
 	genesis := Genesis{}
 	if err := vm.codec.Unmarshal(genesisBytes, &genesis); err != nil {
@@ -383,16 +581,145 @@ func (vm *VM) initState(genesisBytes []byte) error {
 		if err := vm.state.SetStatus(txID, choices.Accepted); err != nil {
 			return err
 		}
+		if err := vm.state.AddAcceptedTx(txID); err != nil {
+			return err
+		}
+		addrs := ids.Set{}
 		for _, utxo := range tx.UTXOs() {
+			addrs.Union(utxoAddresses(utxo))
 			if err := vm.state.FundUTXO(utxo); err != nil {
 				return err
 			}
 		}
+		for _, addr := range addrs.List() {
+			if err := vm.state.AddAddressTx(addr, txID); err != nil {
+				return err
+			}
+		}
 	}
 
+	if err := vm.state.SetAddressIndexInitialized(choices.Processing); err != nil {
+		return err
+	}
 	return vm.state.SetDBInitialized(choices.Processing)
 }
 
+// initAddressIndex builds the address index of a database that was created
+// before the index was maintained, by replaying the accepted transactions.
+func (vm *VM) initAddressIndex() error {
+	numTxs, err := vm.state.NumAcceptedTxs()
+	if err != nil {
+		return err
+	}
+	vm.ctx.Log.Info("Building the address index from %d accepted transactions", numTxs)
+
+	addrs := ids.Set{}
+	for i := uint64(0); i < numTxs; i++ {
+		txID, err := vm.state.AcceptedTxID(i)
+		if err != nil {
+			return err
+		}
+		tx, err := vm.state.Tx(txID)
+		if err != nil {
+			return err
+		}
+
+		txAddrs := ids.Set{}
+		for _, in := range tx.InputUTXOs() {
+			if in.Symbol {
+				continue
+			}
+			utxo, err := vm.spentUTXO(in)
+			if err != nil {
+				return err
+			}
+			txAddrs.Union(utxoAddresses(utxo))
+		}
+		for _, utxo := range tx.UTXOs() {
+			txAddrs.Union(utxoAddresses(utxo))
+		}
+		for _, addr := range txAddrs.List() {
+			if err := vm.state.AddAddressTx(addr, txID); err != nil {
+				return err
+			}
+		}
+		addrs.Union(txAddrs)
+	}
+
+	// The balances are those of the utxos that are still unspent
+	utxoIDs := ids.Set{}
+	for _, addr := range addrs.List() {
+		funds, _ := vm.state.Funds(addr)
+		utxoIDs.Add(funds...)
+	}
+	for _, utxoID := range utxoIDs.List() {
+		utxo, err := vm.state.UTXO(utxoID)
+		if err != nil {
+			return err
+		}
+		if addressable, ok := utxo.Out.(FxAddressable); ok {
+			if err := vm.state.updateBalances(addressable.Addresses(), utxo, false); err != nil {
+				return err
+			}
+		}
+	}
+	return vm.state.SetAddressIndexInitialized(choices.Processing)
+}
+
+// commitAccept commits the changes made by accepting [tx]. If [tx] changes
+// shared memory, the changes are written in the same write as the memory's.
+func (vm *VM) commitAccept(tx UnsignedTx) error {
+	atomicTx, ok := tx.(atomicTx)
+	if !ok {
+		return vm.db.Commit()
+	}
+	if vm.ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+
+	requests, err := atomicTx.AtomicRequests(vm)
+	if err != nil {
+		return err
+	}
+	batch, err := vm.db.CommitBatch()
+	if err != nil {
+		return err
+	}
+	defer vm.db.Abort()
+
+	return vm.ctx.SharedMemory.Apply(requests, batch)
+}
+
+// spentUTXO returns the utxo [in] refers to, from the transaction that
+// produced it, so it can be found after it was spent
+func (vm *VM) spentUTXO(in *UTXOID) (*UTXO, error) {
+	tx, err := vm.state.Tx(in.TxID)
+	if err != nil {
+		return nil, err
+	}
+	inputID := in.InputID()
+	for _, utxo := range tx.UTXOs() {
+		if utxo.InputID().Equals(inputID) {
+			return utxo, nil
+		}
+	}
+	return nil, errUnknownUTXO
+}
+
+// utxoAddresses returns the 32 byte representations of the addresses [utxo]
+// references
+func utxoAddresses(utxo *UTXO) ids.Set {
+	addrs := ids.Set{}
+	addressable, ok := utxo.Out.(FxAddressable)
+	if !ok {
+		return addrs
+	}
+	for _, addr := range addressable.Addresses() {
+		addrs.Add(ids.NewID(hashing.ComputeHash256Array(addr)))
+	}
+	return addrs
+}
+
 func (vm *VM) parseTx(b []byte) (*UniqueTx, error) {
 	rawTx := &Tx{}
 	err := vm.codec.Unmarshal(b, rawTx)
@@ -422,14 +749,95 @@ func (vm *VM) parseTx(b []byte) (*UniqueTx, error) {
 	return tx, nil
 }
 
-func (vm *VM) issueTx(tx snowstorm.Tx) {
-	vm.txs = append(vm.txs, tx)
+func (vm *VM) issueTx(tx snowstorm.Tx) error {
+	evicted, err := vm.mempool.Add(tx.ID(), tx, vm.txFee(tx))
+	if err != nil {
+		return err
+	}
+	for _, evictedID := range evicted {
+		vm.ctx.Log.Debug("Evicted tx %s from the mempool", evictedID)
+		vm.reissuer.Evicted(evictedID)
+	}
+
 	switch {
-	case len(vm.txs) == batchSize:
+	case vm.mempool.Len() == batchSize:
 		vm.FlushTxs()
-	case len(vm.txs) == 1:
+	case vm.mempool.Len() == 1:
 		vm.timer.SetTimeoutIn(vm.batchTimeout)
 	}
+	return nil
+}
+
+// dropTx gives up on [txID], which was dropped before it entered consensus.
+// Its status is reset, so that it can be issued again, and its onDecide
+// callback is called with choices.Rejected.
+func (vm *VM) dropTx(txID ids.ID) {
+	tx := &UniqueTx{
+		vm:   vm,
+		txID: txID,
+	}
+	if tx.Status() != choices.Processing || vm.mempool.Has(txID) {
+		return
+	}
+
+	if err := tx.setStatus(choices.Unknown); err != nil {
+		vm.ctx.Log.Error("Failed to reset the status of dropped tx %s due to %s", txID, err)
+	}
+	if err := vm.db.Commit(); err != nil {
+		vm.ctx.Log.Error("Failed to commit the drop of tx %s due to %s", txID, err)
+	}
+
+	if onDecide := tx.t.onDecide; onDecide != nil {
+		tx.t.onDecide = nil
+		onDecide(choices.Rejected)
+	}
+}
+
+// txFee returns the amount of the fee asset that [tx] burns
+func (vm *VM) txFee(tx snowstorm.Tx) uint64 {
+	uniqueTx, ok := tx.(*UniqueTx)
+	if !ok || vm.feeAssetID.IsZero() || uniqueTx.t.tx == nil {
+		return 0
+	}
+
+	// The funds an export tx puts in shared memory aren't burned, and the
+	// funds an import tx takes from it are consumed
+	ins := uniqueTx.t.tx.Inputs()
+	outs := uniqueTx.t.tx.Outputs()
+	switch atomicTx := uniqueTx.t.tx.UnsignedTx.(type) {
+	case *ImportTx:
+		ins = append(ins[:len(ins):len(ins)], atomicTx.ImportedIns...)
+	case *ExportTx:
+		outs = append(outs[:len(outs):len(outs)], atomicTx.ExportedOuts...)
+	}
+
+	consumed := uint64(0)
+	for _, in := range ins {
+		if in.AssetID().Equals(vm.feeAssetID) {
+			newConsumed, err := math.Add64(consumed, in.Input().Amount())
+			if err != nil {
+				return 0
+			}
+			consumed = newConsumed
+		}
+	}
+	produced := uint64(0)
+	for _, out := range outs {
+		if out.AssetID().Equals(vm.feeAssetID) {
+			newProduced, err := math.Add64(produced, out.Output().Amount())
+			if err != nil {
+				return 0
+			}
+			produced = newProduced
+		}
+	}
+	// Syntactic verification ensures that the sums don't overflow and that
+	// [consumed] >= [produced], so this is only a safeguard
+	fee, err := math.Sub64(consumed, produced)
+	if err != nil {
+		return 0
+	}
+	return fee
 }
 
 func (vm *VM) getFx(val interface{}) (int, error) {
@@ -441,6 +849,45 @@ func (vm *VM) getFx(val interface{}) (int, error) {
 	return fx, nil
 }
 
+// hasFx returns true if this VM is running the fx with ID [fxID]
+func (vm *VM) hasFx(fxID ids.ID) bool {
+	for _, fx := range vm.fxs {
+		if fx.ID.Equals(fxID) {
+			return true
+		}
+	}
+	return false
+}
+
+// getAssetMetadata returns the transaction that registered the current
+// metadata of [assetID]
+func (vm *VM) getAssetMetadata(assetID ids.ID) (*AssetMetadataTx, error) {
+	txID, err := vm.state.AssetMetadataTxID(assetID)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := vm.state.Tx(txID)
+	if err != nil {
+		return nil, err
+	}
+	metadataTx, ok := tx.UnsignedTx.(*AssetMetadataTx)
+	if !ok {
+		return nil, errTxNotAssetMetadata
+	}
+	return metadataTx, nil
+}
+
+// setAssetMetadata registers the metadata of the accepted transaction [tx],
+// unless newer metadata of the asset was already registered
+func (vm *VM) setAssetMetadata(txID ids.ID, tx *AssetMetadataTx) error {
+	assetID := tx.Metadata.AssetID
+	registered, err := vm.getAssetMetadata(assetID)
+	if err == nil && registered.Metadata.Version >= tx.Metadata.Version {
+		return nil
+	}
+	return vm.state.SetAssetMetadataTxID(assetID, txID)
+}
+
 func (vm *VM) verifyFxUsage(fxID int, assetID ids.ID) bool {
 	tx := &UniqueTx{
 		vm:   vm,
@@ -463,10 +910,61 @@ func (vm *VM) verifyFxUsage(fxID int, assetID ids.ID) bool {
 	return false
 }
 
-// Parse ...
+// startBatches starts a batch of signatures in every fx that supports batch
+// verification
+func (vm *VM) startBatches() {
+	for _, fx := range vm.fxs {
+		if batcher, ok := fx.Fx.(FxBatchVerifier); ok {
+			batcher.StartBatch()
+		}
+	}
+}
+
+// verifyBatches ends the batches started by the last call to startBatches and
+// returns the first error of the batches
+func (vm *VM) verifyBatches() error {
+	errs := wrappers.Errs{}
+	for _, fx := range vm.fxs {
+		if batcher, ok := fx.Fx.(FxBatchVerifier); ok {
+			errs.Add(batcher.VerifyBatch())
+		}
+	}
+	return errs.Err
+}
+
+// Parse an address formatted by Format or FormatBech32
 func (vm *VM) Parse(addrStr string) ([]byte, error) {
+	bcID, addr, err := vm.parseChainAddress(addrStr)
+	if err != nil {
+		return nil, err
+	}
+	if !bcID.Equals(vm.ctx.ChainID) {
+		return nil, errWrongBlockchainID
+	}
+	return addr, nil
+}
+
+// canImport returns true if the chain [chainID] can import the UTXOs of
+// [assetID] this chain exports. Chains running this VM import them, and the
+// VM of a chain is only known if this node runs the chain. The Platform Chain
+// only imports the fee asset.
+func (vm *VM) canImport(chainID, assetID ids.ID) bool {
+	if chainID.Equals(platformChainID) {
+		return !vm.feeAssetID.IsZero() && assetID.Equals(vm.feeAssetID)
+	}
+	if vm.ctx.VMLookup == nil {
+		return false
+	}
+	vmID, err := vm.ctx.VMLookup.ChainVM(chainID)
+	return err == nil && vmID.Equals(ID)
+}
+
+// parseChainAddress parses an address of any chain on this network, formatted
+// the way Format or FormatBech32 format an address of this chain. Returns the
+// ID of the chain the address is of.
+func (vm *VM) parseChainAddress(addrStr string) (ids.ID, []byte, error) {
 	if count := strings.Count(addrStr, addressSep); count != 1 {
-		return nil, errInvalidAddress
+		return ids.ID{}, nil, errInvalidAddress
 	}
 	addressParts := strings.SplitN(addrStr, addressSep, 2)
 	bcAlias := addressParts[0]
@@ -475,24 +973,34 @@ func (vm *VM) Parse(addrStr string) ([]byte, error) {
 	if err != nil {
 		bcID, err = ids.FromString(bcAlias)
 		if err != nil {
-			return nil, err
+			return ids.ID{}, nil, err
 		}
 	}
-	if !bcID.Equals(vm.ctx.ChainID) {
-		return nil, errWrongBlockchainID
+	if hrp, addr, err := formatting.ParseBech32(rawAddr); err == nil {
+		if hrp != formatting.Bech32HRP(vm.ctx.NetworkID) {
+			return ids.ID{}, nil, errWrongNetwork
+		}
+		return bcID, addr, nil
 	}
 	cb58 := formatting.CB58{}
 	err = cb58.FromString(rawAddr)
-	return cb58.Bytes, err
+	return bcID, cb58.Bytes, err
+}
+
+// FormatBech32 returns [b] formatted as a bech32 address of this chain, with
+// the human readable part of this network
+func (vm *VM) FormatBech32(b []byte) (string, error) {
+	return formatting.FormatAddress(vm.primaryAlias(), formatting.Bech32HRP(vm.ctx.NetworkID), b)
 }
 
 // Format ...
 func (vm *VM) Format(b []byte) string {
-	var bcAlias string
+	return fmt.Sprintf("%s%s%s", vm.primaryAlias(), addressSep, formatting.CB58{Bytes: b})
+}
+
+func (vm *VM) primaryAlias() string {
 	if alias, err := vm.ctx.BCLookup.PrimaryAlias(vm.ctx.ChainID); err == nil {
-		bcAlias = alias
-	} else {
-		bcAlias = vm.ctx.ChainID.String()
+		return alias
 	}
-	return fmt.Sprintf("%s%s%s", bcAlias, addressSep, formatting.CB58{Bytes: b})
+	return vm.ctx.ChainID.String()
 }
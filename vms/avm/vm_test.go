@@ -5,6 +5,7 @@ package avm
 
 import (
 	"bytes"
+	"math/big"
 	"testing"
 
 	"github.com/ava-labs/gecko/database/memdb"
@@ -413,6 +414,31 @@ func TestFxInitializationFailure(t *testing.T) {
 	}
 }
 
+func TestFactoryVerifyGenesis(t *testing.T) {
+	genesisBytes := BuildGenesisTest(t)
+
+	factory := &Factory{}
+	newFxs := func() []*common.Fx {
+		return []*common.Fx{&common.Fx{
+			ID: ids.Empty,
+			Fx: &secp256k1fx.Fx{},
+		}}
+	}
+
+	if err := factory.VerifyGenesis(genesisBytes, newFxs()); err != nil {
+		t.Fatal(err)
+	}
+	if err := factory.VerifyGenesis(genesisBytes[:len(genesisBytes)-1], newFxs()); err == nil {
+		t.Fatalf("Should have errored due to truncated genesis bytes")
+	}
+	if err := factory.VerifyGenesis(genesisBytes, nil); err == nil {
+		t.Fatalf("Should have errored due to a missing fx")
+	}
+	if err := factory.VerifyGenesis(genesisBytes, []*common.Fx{nil}); err == nil {
+		t.Fatalf("Should have errored due to an invalid fx")
+	}
+}
+
 type testTxBytes struct{ unsignedBytes []byte }
 
 func (tx *testTxBytes) UnsignedBytes() []byte { return tx.unsignedBytes }
@@ -550,6 +576,17 @@ func TestGenesisGetUTXOs(t *testing.T) {
 
 // Test issuing a transaction that consumes a currently pending UTXO. The
 // transaction should be issued successfully.
+func TestIssueTxReadOnly(t *testing.T) {
+	vm := GenesisVM(t)
+
+	ctx.ReadOnly = true
+	defer func() { ctx.ReadOnly = false }()
+
+	if _, err := vm.IssueTx(nil, nil); err != snow.ErrReadOnly {
+		t.Fatalf("A read-only VM should have rejected the tx but returned %v", err)
+	}
+}
+
 func TestIssueDependentTx(t *testing.T) {
 	genesisBytes := BuildGenesisTest(t)
 
@@ -705,3 +742,115 @@ func TestIssueDependentTx(t *testing.T) {
 		t.Fatalf("Should have returned %d tx(s)", 2)
 	}
 }
+
+func TestGetTxIDAtIndex(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 0)
+	defer vm.Shutdown()
+
+	genesisTx := GetFirstTxFromGenesisTest(BuildGenesisTest(t), t)
+	if txID, err := vm.GetTxIDAtIndex(0); err != nil {
+		t.Fatal(err)
+	} else if !txID.Equals(genesisTx.ID()) {
+		t.Fatalf("Genesis tx should have been accepted first")
+	}
+
+	numGenesisTxs, err := vm.state.NumAcceptedTxs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.GetTxIDAtIndex(numGenesisTxs); err == nil {
+		t.Fatalf("Shouldn't have indexed a tx that wasn't accepted")
+	}
+
+	txID, err := vm.IssueTx(tx.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uniqueTx := &UniqueTx{
+		vm:   vm,
+		txID: txID,
+	}
+	if err := uniqueTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	uniqueTx.Accept()
+
+	if indexedTxID, err := vm.GetTxIDAtIndex(numGenesisTxs); err != nil {
+		t.Fatal(err)
+	} else if !indexedTxID.Equals(txID) {
+		t.Fatalf("Should have indexed the accepted tx after the genesis txs")
+	}
+}
+
+func TestAddressIndex(t *testing.T) {
+	ctx.Lock.Lock()
+	defer ctx.Lock.Unlock()
+
+	vm, tx := reissueVM(t, 0)
+	defer vm.Shutdown()
+
+	genesisTx := GetFirstTxFromGenesisTest(BuildGenesisTest(t), t)
+	assetID := genesisTx.ID()
+	addr := ids.NewID(hashing.ComputeHash256Array(keys[0].PublicKey().Address().Bytes()))
+
+	numGenesisTxs, err := vm.NumAddressTxs(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numGenesisTxs == 0 {
+		t.Fatalf("Should have indexed the genesis txs of the address")
+	}
+	if balance, err := vm.state.Balance(addr, assetID); err != nil {
+		t.Fatal(err)
+	} else if balance.Uint64() != 300000 {
+		t.Fatalf("Wrong genesis balance %s", balance)
+	}
+
+	txID, err := vm.IssueTx(tx.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uniqueTx := &UniqueTx{
+		vm:   vm,
+		txID: txID,
+	}
+	if err := uniqueTx.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	uniqueTx.Accept()
+
+	if indexedTxID, err := vm.GetAddressTxID(addr, numGenesisTxs); err != nil {
+		t.Fatal(err)
+	} else if !indexedTxID.Equals(txID) {
+		t.Fatalf("Should have indexed the accepted tx after the genesis txs")
+	}
+	if balance, err := vm.state.Balance(addr, assetID); err != nil {
+		t.Fatal(err)
+	} else if balance.Uint64() != 250000 {
+		t.Fatalf("Wrong balance %s after spending 50000", balance)
+	}
+
+	// Rebuilding the index of the address should produce the same index
+	if err := vm.state.state.SetUint64(addr.Prefix(numAddressTxsID), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.state.SetBalance(addr, assetID, new(big.Int)); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.initAddressIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if numTxs, err := vm.NumAddressTxs(addr); err != nil {
+		t.Fatal(err)
+	} else if numTxs != numGenesisTxs+1 {
+		t.Fatalf("Rebuilt index has %d txs but should have %d", numTxs, numGenesisTxs+1)
+	}
+	if balance, err := vm.state.Balance(addr, assetID); err != nil {
+		t.Fatal(err)
+	} else if balance.Uint64() != 250000 {
+		t.Fatalf("Wrong rebuilt balance %s", balance)
+	}
+}
@@ -0,0 +1,29 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blsfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+)
+
+var (
+	errNilCredential = errors.New("nil credential")
+)
+
+// Credential is the aggregate of the signatures of every signer of an input
+type Credential struct {
+	Sig [crypto.BLSSignatureLen]byte `serialize:"true"`
+}
+
+// Verify ...
+func (cr *Credential) Verify() error {
+	switch {
+	case cr == nil:
+		return errNilCredential
+	default:
+		return nil
+	}
+}
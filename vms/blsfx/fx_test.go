@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blsfx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var (
+	txBytes = []byte{0, 1, 2, 3, 4, 5}
+)
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct{ bytes []byte }
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+// newTestOwners returns [n] private keys and the owners that require
+// [threshold] of them to sign
+func newTestOwners(t *testing.T, threshold uint32, n int) ([]crypto.PrivateKey, OutputOwners) {
+	factory := crypto.FactoryBLS{}
+	sks := make([]crypto.PrivateKey, n)
+	owners := OutputOwners{Threshold: threshold}
+	for i := range sks {
+		sk, err := factory.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sks[i] = sk
+		pk := [crypto.BLSPublicKeyLen]byte{}
+		copy(pk[:], sk.PublicKey().Bytes())
+		owners.Keys = append(owners.Keys, pk)
+	}
+	owners.Sort()
+
+	// Order the private keys the same way as the public keys
+	sorted := make([]crypto.PrivateKey, n)
+	for i, pk := range owners.Keys {
+		for _, sk := range sks {
+			if bytes.Equal(sk.PublicKey().Bytes(), pk[:]) {
+				sorted[i] = sk
+			}
+		}
+	}
+	return sorted, owners
+}
+
+func newTestCredential(t *testing.T, tx Tx, sks ...crypto.PrivateKey) *Credential {
+	factory := crypto.FactoryBLS{}
+	txHash := hashing.ComputeHash256(tx.UnsignedBytes())
+	sigs := make([][]byte, len(sks))
+	for i, sk := range sks {
+		sig, err := sk.Sign(txHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs[i] = sig
+	}
+	sig, err := factory.AggregateSignatures(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred := &Credential{}
+	copy(cred.Sig[:], sig)
+	return cred
+}
+
+func newTestFx(t *testing.T) *Fx {
+	vm := testVM{}
+	vm.clock.Set(time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC))
+	fx := &Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	return fx
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &testTx{bytes: txBytes}
+	sks, owners := newTestOwners(t, 2, 3)
+
+	out := &TransferOutput{
+		Amt:          1,
+		OutputOwners: owners,
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: Input{SigIndices: []uint32{0, 2}},
+	}
+
+	if err := fx.VerifyTransfer(tx, out, in, newTestCredential(t, tx, sks[0], sks[2])); err != nil {
+		t.Fatal(err)
+	}
+	if err := fx.VerifyTransfer(tx, out, in, newTestCredential(t, tx, sks[0], sks[1])); err == nil {
+		t.Fatalf("Should have errored due to the wrong signers")
+	}
+	if err := fx.VerifyTransfer(tx, out, in, newTestCredential(t, tx, sks[0])); err == nil {
+		t.Fatalf("Should have errored due to a missing signature")
+	}
+	if err := fx.VerifyTransfer(&testTx{bytes: []byte{6}}, out, in, newTestCredential(t, tx, sks[0], sks[2])); err == nil {
+		t.Fatalf("Should have errored due to signatures of a different tx")
+	}
+}
+
+func TestFxVerifyTransferInvalid(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &testTx{bytes: txBytes}
+	sks, owners := newTestOwners(t, 1, 1)
+	cred := newTestCredential(t, tx, sks[0])
+
+	out := &TransferOutput{
+		Amt:          1,
+		OutputOwners: owners,
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: Input{SigIndices: []uint32{0}},
+	}
+
+	if err := fx.VerifyTransfer(nil, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a nil tx")
+	}
+	if err := fx.VerifyTransfer(tx, out, in, nil); err == nil {
+		t.Fatalf("Should have errored due to a nil credential")
+	}
+
+	in.Amt = 2
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to mismatched amounts")
+	}
+	in.Amt = 1
+
+	out.Locktime = uint64(fx.vm.Clock().Unix()) + 1
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a timelocked output")
+	}
+	out.Locktime = 0
+
+	in.SigIndices = []uint32{1}
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to an out of bounds signer")
+	}
+}
+
+func TestFxVerifyOperation(t *testing.T) {
+	fx := newTestFx(t)
+	tx := &testTx{bytes: txBytes}
+	sks, owners := newTestOwners(t, 1, 2)
+
+	utxo := &MintOutput{OutputOwners: owners}
+	in := &MintInput{Input: Input{SigIndices: []uint32{1}}}
+	cred := newTestCredential(t, tx, sks[1])
+	mintOut := &MintOutput{OutputOwners: owners}
+	transferOut := &TransferOutput{
+		Amt:          1,
+		OutputOwners: owners,
+	}
+
+	utxos := []interface{}{utxo}
+	ins := []interface{}{in}
+	creds := []interface{}{cred}
+	outs := []interface{}{mintOut, transferOut}
+	if err := fx.VerifyOperation(tx, utxos, ins, creds, outs); err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherOwners := newTestOwners(t, 1, 1)
+	outs = []interface{}{&MintOutput{OutputOwners: otherOwners}, transferOut}
+	if err := fx.VerifyOperation(tx, utxos, ins, creds, outs); err == nil {
+		t.Fatalf("Should have errored due to the mint output changing owners")
+	}
+}
+
+func TestOutputOwnersVerify(t *testing.T) {
+	_, owners := newTestOwners(t, 1, 2)
+	if err := owners.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	unsorted := OutputOwners{
+		Threshold: 1,
+		Keys:      [][crypto.BLSPublicKeyLen]byte{owners.Keys[1], owners.Keys[0]},
+	}
+	if err := unsorted.Verify(); err == nil {
+		t.Fatalf("Should have errored due to unsorted keys")
+	}
+
+	invalid := OutputOwners{
+		Threshold: 1,
+		Keys:      [][crypto.BLSPublicKeyLen]byte{{}},
+	}
+	if err := invalid.Verify(); err == nil {
+		t.Fatalf("Should have errored due to an invalid key")
+	}
+
+	unspendable := OutputOwners{
+		Threshold: 3,
+		Keys:      owners.Keys,
+	}
+	if err := unspendable.Verify(); err == nil {
+		t.Fatalf("Should have errored due to an unspendable output")
+	}
+}
@@ -0,0 +1,84 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blsfx
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/utils"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errNilOutput           = errors.New("nil output")
+	errOutputUnspendable   = errors.New("output is unspendable")
+	errOutputUnoptimized   = errors.New("output representation should be optimized")
+	errKeysNotSortedUnique = errors.New("public keys not sorted and unique")
+	errInvalidPublicKey    = errors.New("invalid public key")
+)
+
+// OutputOwners are the public keys that control an output, and the number of
+// them that must sign to spend it
+type OutputOwners struct {
+	Threshold uint32                         `serialize:"true"`
+	Keys      [][crypto.BLSPublicKeyLen]byte `serialize:"true"`
+}
+
+// Addresses returns the addresses that manage this output
+func (out *OutputOwners) Addresses() [][]byte {
+	addrs := make([][]byte, len(out.Keys))
+	for i, key := range out.Keys {
+		addrs[i] = hashing.PubkeyBytesToAddress(key[:])
+	}
+	return addrs
+}
+
+// Equals returns true if the provided owners create the same condition
+func (out *OutputOwners) Equals(other *OutputOwners) bool {
+	if out == other {
+		return true
+	}
+	if out == nil || other == nil || out.Threshold != other.Threshold || len(out.Keys) != len(other.Keys) {
+		return false
+	}
+	for i, key := range out.Keys {
+		if key != other.Keys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify ...
+func (out *OutputOwners) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	case out.Threshold > uint32(len(out.Keys)):
+		return errOutputUnspendable
+	case out.Threshold == 0 && len(out.Keys) > 0:
+		return errOutputUnoptimized
+	case !utils.IsSortedAndUnique(innerSortKeys(out.Keys)):
+		return errKeysNotSortedUnique
+	}
+	factory := crypto.FactoryBLS{}
+	for _, key := range out.Keys {
+		if _, err := factory.ToPublicKey(key[:]); err != nil {
+			return errInvalidPublicKey
+		}
+	}
+	return nil
+}
+
+// Sort ...
+func (out *OutputOwners) Sort() { sort.Sort(innerSortKeys(out.Keys)) }
+
+type innerSortKeys [][crypto.BLSPublicKeyLen]byte
+
+func (keys innerSortKeys) Less(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) == -1 }
+func (keys innerSortKeys) Len() int           { return len(keys) }
+func (keys innerSortKeys) Swap(i, j int)      { keys[j], keys[i] = keys[i], keys[j] }
@@ -6,7 +6,9 @@ package codec
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 	"unicode"
 
 	"github.com/ava-labs/gecko/utils/wrappers"
@@ -27,10 +29,21 @@ const (
 const (
 	defaultMaxSize        = 1 << 18 // default max size, in bytes, of something being marshalled by Marshal()
 	defaultMaxSliceLength = 1 << 18 // default max length of a slice being marshalled by Marshal()
+	defaultMaxDepth       = 64      // default max nesting depth of something being unmarshalled by Unmarshal()
+	defaultAllocationRate = 64      // default number of bytes Unmarshal() may allocate per byte of its input
+
+	readChunkSize = 1 << 16 // number of bytes UnmarshalFrom reads at a time
+)
+
+var (
+	// ErrTooDeep is returned when a value being unmarshalled is nested more
+	// deeply than the codec's max depth
+	ErrTooDeep = errors.New("value is nested too deeply")
+	// ErrAllocationLimit is returned when unmarshalling a value would allocate
+	// more memory than the codec's max allocation
+	ErrAllocationLimit = errors.New("value would allocate too much memory")
 )
 
-// ErrBadCodec is returned when one tries to perform an operation
-// using an unknown codec
 var (
 	errBadCodec                  = errors.New("wrong or unknown codec used")
 	errNil                       = errors.New("can't marshal nil value")
@@ -45,6 +58,25 @@ var (
 	errSliceTooLarge             = errors.New("slice too large")
 )
 
+// Limits bound the resources a codec uses to unmarshal a value
+type Limits struct {
+	MaxSize     int // Max size, in bytes, of a serialized value
+	MaxSliceLen int // Max number of elements in a slice
+	MaxDepth    int // Max nesting depth of structs, arrays, slices, pointers and interfaces
+	// Max number of bytes allocated to unmarshal a single value. If it isn't
+	// positive, a multiple of MaxSize is used.
+	MaxAllocation int
+}
+
+// DefaultLimits returns reasonable default limits
+func DefaultLimits() Limits {
+	return Limits{
+		MaxSize:     defaultMaxSize,
+		MaxSliceLen: defaultMaxSliceLength,
+		MaxDepth:    defaultMaxDepth,
+	}
+}
+
 // Verify that the codec is a known codec value. Returns nil if the codec is
 // valid.
 func (c Type) Verify() error {
@@ -71,9 +103,13 @@ func (c Type) String() string {
 
 // Codec handles marshaling and unmarshaling of structs
 type codec struct {
+	version     uint16 // Fields added in later versions aren't serialized
 	maxSize     int
 	maxSliceLen int
+	maxDepth    int
+	maxAlloc    int
 
+	nextTypeID   uint32
 	typeIDToType map[uint32]reflect.Type
 	typeToTypeID map[reflect.Type]uint32
 }
@@ -81,35 +117,61 @@ type codec struct {
 // Codec marshals and unmarshals
 type Codec interface {
 	RegisterType(interface{}) error
+	Skip(int)
 	Marshal(interface{}) ([]byte, error)
 	Unmarshal([]byte, interface{}) error
+	UnmarshalFrom(io.Reader, interface{}) error
 }
 
 // New returns a new codec
 func New(maxSize, maxSliceLen int) Codec {
-	return codec{
-		maxSize:      maxSize,
-		maxSliceLen:  maxSliceLen,
+	return NewVersioned(0, Limits{
+		MaxSize:     maxSize,
+		MaxSliceLen: maxSliceLen,
+		MaxDepth:    defaultMaxDepth,
+	})
+}
+
+// NewDefault returns a new codec with reasonable default values
+func NewDefault() Codec { return NewVersioned(0, DefaultLimits()) }
+
+// NewVersioned returns a new codec for version [version] of a wire format.
+// Struct fields tagged with `version:"n"` are only serialized by codecs of
+// version n or later.
+func NewVersioned(version uint16, limits Limits) Codec {
+	maxAlloc := limits.MaxAllocation
+	if maxAlloc <= 0 {
+		maxAlloc = defaultAllocationRate * limits.MaxSize
+	}
+	return &codec{
+		version:      version,
+		maxSize:      limits.MaxSize,
+		maxSliceLen:  limits.MaxSliceLen,
+		maxDepth:     limits.MaxDepth,
+		maxAlloc:     maxAlloc,
 		typeIDToType: map[uint32]reflect.Type{},
 		typeToTypeID: map[reflect.Type]uint32{},
 	}
 }
 
-// NewDefault returns a new codec with reasonable default values
-func NewDefault() Codec { return New(defaultMaxSize, defaultMaxSliceLength) }
-
 // RegisterType is used to register types that may be unmarshaled into an interface typed value
 // [val] is a value of the type being registered
-func (c codec) RegisterType(val interface{}) error {
+func (c *codec) RegisterType(val interface{}) error {
 	valType := reflect.TypeOf(val)
 	if _, exists := c.typeToTypeID[valType]; exists {
 		return fmt.Errorf("type %v has already been registered", valType)
 	}
-	c.typeIDToType[uint32(len(c.typeIDToType))] = reflect.TypeOf(val)
-	c.typeToTypeID[valType] = uint32(len(c.typeIDToType) - 1)
+	c.typeIDToType[c.nextTypeID] = valType
+	c.typeToTypeID[valType] = c.nextTypeID
+	c.nextTypeID++
 	return nil
 }
 
+// Skip the next [num] type IDs. A later version of a wire format uses this to
+// keep the type IDs of the types it inherits from an earlier version when the
+// earlier version registered types that were since removed.
+func (c *codec) Skip(num int) { c.nextTypeID += uint32(num) }
+
 // A few notes:
 // 1) See codec_test.go for examples of usage
 // 2) We use "marshal" and "serialize" interchangeably, and "unmarshal" and "deserialize" interchangeably
@@ -127,7 +189,7 @@ func (c codec) RegisterType(val interface{}) error {
 // Marshal returns the byte representation of [value]
 // If you want to marshal an interface, [value] must be a pointer
 // to the interface
-func (c codec) Marshal(value interface{}) ([]byte, error) {
+func (c *codec) Marshal(value interface{}) ([]byte, error) {
 	if value == nil {
 		return nil, errNil
 	}
@@ -136,7 +198,7 @@ func (c codec) Marshal(value interface{}) ([]byte, error) {
 }
 
 // Marshal [value] to bytes
-func (c codec) marshal(value reflect.Value) ([]byte, error) {
+func (c *codec) marshal(value reflect.Value) ([]byte, error) {
 	p := wrappers.Packer{MaxSize: c.maxSize, Bytes: []byte{}}
 	t := value.Type()
 
@@ -213,7 +275,7 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 	case reflect.Struct:
 		for i := 0; i < t.NumField(); i++ { // Go through all fields of this struct
 			field := t.Field(i)
-			if !shouldSerialize(field) { // Skip fields we don't need to serialize
+			if !c.shouldSerialize(field) { // Skip fields we don't need to serialize
 				continue
 			}
 			if unicode.IsLower(rune(field.Name[0])) { // Can only marshal exported fields
@@ -240,7 +302,7 @@ func (c codec) marshal(value reflect.Value) ([]byte, error) {
 
 // Unmarshal unmarshals [bytes] into [dest], where
 // [dest] must be a pointer or interface
-func (c codec) Unmarshal(bytes []byte, dest interface{}) error {
+func (c *codec) Unmarshal(bytes []byte, dest interface{}) error {
 	p := &wrappers.Packer{Bytes: bytes}
 
 	if len(bytes) > c.maxSize {
@@ -259,7 +321,8 @@ func (c codec) Unmarshal(bytes []byte, dest interface{}) error {
 
 	destVal := destPtr.Elem()
 
-	err := c.unmarshal(p, destVal)
+	budget := c.maxAlloc
+	err := c.unmarshal(p, destVal, 0, &budget)
 	if err != nil {
 		return err
 	}
@@ -270,10 +333,48 @@ func (c codec) Unmarshal(bytes []byte, dest interface{}) error {
 	return nil
 }
 
+// UnmarshalFrom reads a serialized value from [r] and unmarshals it into
+// [dest], where [dest] must be a pointer or interface. [r] is read in chunks,
+// so no more than the codec's max size is read or allocated regardless of how
+// large the value in [r] is.
+func (c *codec) UnmarshalFrom(r io.Reader, dest interface{}) error {
+	bytes := []byte{}
+	for {
+		chunkSize := readChunkSize
+		if remaining := c.maxSize + 1 - len(bytes); remaining < chunkSize {
+			chunkSize = remaining // Read one byte past the max size to detect oversized values
+		}
+		if chunkSize <= 0 {
+			return errSliceTooLarge
+		}
+
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(r, chunk)
+		bytes = append(bytes, chunk[:n]...)
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			return c.Unmarshal(bytes, dest)
+		default:
+			return err
+		}
+	}
+}
+
 // Unmarshal bytes from [p] into [field]
 // [field] must be addressable
-func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
+// [depth] is the number of values [field] is nested in
+// [budget] is the number of bytes that may still be allocated
+func (c *codec) unmarshal(p *wrappers.Packer, field reflect.Value, depth int, budget *int) error {
 	kind := field.Kind()
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Interface, reflect.Struct, reflect.Ptr:
+		if depth >= c.maxDepth {
+			return ErrTooDeep
+		}
+		depth++
+	}
+
 	switch kind {
 	case reflect.Uint8:
 		field.SetUint(uint64(p.UnpackByte()))
@@ -298,24 +399,35 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		if sliceLen < 0 || sliceLen > c.maxSliceLen {
 			return errSliceTooLarge
 		}
+		// Don't allocate more elements than the remaining bytes could hold
+		if eltSize := c.minSize(field.Type().Elem(), depth); eltSize > 0 && sliceLen > (len(p.Bytes)-p.Offset)/eltSize {
+			return errSliceTooLarge
+		}
+		if err := allocate(budget, sliceLen, field.Type().Elem().Size()); err != nil {
+			return err
+		}
 
 		// First set [field] to be a slice of the appropriate type/capacity (right now [field] is nil)
 		slice := reflect.MakeSlice(field.Type(), sliceLen, sliceLen)
 		field.Set(slice)
 		// Unmarshal each element into the appropriate index of the slice
 		for i := 0; i < sliceLen; i++ {
-			if err := c.unmarshal(p, field.Index(i)); err != nil {
+			if err := c.unmarshal(p, field.Index(i), depth, budget); err != nil {
 				return err
 			}
 		}
 	case reflect.Array:
 		for i := 0; i < field.Len(); i++ {
-			if err := c.unmarshal(p, field.Index(i)); err != nil {
+			if err := c.unmarshal(p, field.Index(i), depth, budget); err != nil {
 				return err
 			}
 		}
 	case reflect.String:
-		field.SetString(p.UnpackStr())
+		str := p.UnpackStr()
+		if err := allocate(budget, len(str), 1); err != nil {
+			return err
+		}
+		field.SetString(str)
 	case reflect.Interface:
 		// Get the type ID
 		typeID := p.UnpackInt()
@@ -324,9 +436,12 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		if !ok {
 			return errUnmarshalUnregisteredType
 		}
+		if err := allocate(budget, 1, typ.Size()); err != nil {
+			return err
+		}
 		concreteInstancePtr := reflect.New(typ) // instance of the proper type
 		// Unmarshal into the struct
-		if err := c.unmarshal(p, concreteInstancePtr.Elem()); err != nil {
+		if err := c.unmarshal(p, concreteInstancePtr.Elem(), depth, budget); err != nil {
 			return err
 		}
 		// And assign the filled struct to the field
@@ -337,14 +452,14 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 		// Go through all the fields and umarshal into each
 		for i := 0; i < structType.NumField(); i++ {
 			structField := structType.Field(i)
-			if !shouldSerialize(structField) { // Skip fields we don't need to unmarshal
+			if !c.shouldSerialize(structField) { // Skip fields we don't need to unmarshal
 				continue
 			}
 			if unicode.IsLower(rune(structField.Name[0])) { // Only unmarshal into exported field
 				return errUnmarshalUnexportedField
 			}
-			field := field.Field(i)                       // Get the field
-			if err := c.unmarshal(p, field); err != nil { // Unmarshal into the field
+			field := field.Field(i)                                      // Get the field
+			if err := c.unmarshal(p, field, depth, budget); err != nil { // Unmarshal into the field
 				return err
 			}
 			if p.Errored() { // If there was an error just return immediately
@@ -354,10 +469,13 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 	case reflect.Ptr:
 		// Get the type this pointer points to
 		underlyingType := field.Type().Elem()
+		if err := allocate(budget, 1, underlyingType.Size()); err != nil {
+			return err
+		}
 		// Create a new pointer to a new value of the underlying type
 		underlyingValue := reflect.New(underlyingType)
 		// Fill the value
-		if err := c.unmarshal(p, underlyingValue.Elem()); err != nil {
+		if err := c.unmarshal(p, underlyingValue.Elem(), depth, budget); err != nil {
 			return err
 		}
 		// Assign to the top-level struct's member
@@ -370,10 +488,61 @@ func (c codec) unmarshal(p *wrappers.Packer, field reflect.Value) error {
 	return p.Err
 }
 
-// Returns true iff [field] should be serialized
-func shouldSerialize(field reflect.StructField) bool {
-	if field.Tag.Get("serialize") == "true" {
+// Charges [budget] for [num] values of [size] bytes each, or returns
+// ErrAllocationLimit if the budget can't afford them
+func allocate(budget *int, num int, size uintptr) error {
+	if size == 0 {
+		return nil
+	}
+	if uintptr(num) > uintptr(*budget)/size {
+		return ErrAllocationLimit
+	}
+	*budget -= num * int(size)
+	return nil
+}
+
+// Returns the minimum number of bytes a value of type [t] is serialized to.
+// [depth] is the number of values a value of type [t] is nested in, and bounds
+// the recursion on recursive types.
+func (c *codec) minSize(t reflect.Type, depth int) int {
+	if depth >= c.maxDepth {
+		return 0
+	}
+	switch t.Kind() {
+	case reflect.Uint8, reflect.Int8, reflect.Bool:
+		return wrappers.ByteLen
+	case reflect.Uint16, reflect.Int16, reflect.String:
+		return wrappers.ShortLen
+	case reflect.Uint32, reflect.Int32, reflect.Slice, reflect.Interface:
+		return wrappers.IntLen
+	case reflect.Uint64, reflect.Int64:
+		return wrappers.LongLen
+	case reflect.Array:
+		return t.Len() * c.minSize(t.Elem(), depth+1)
+	case reflect.Ptr:
+		return c.minSize(t.Elem(), depth+1)
+	case reflect.Struct:
+		size := 0
+		for i := 0; i < t.NumField(); i++ {
+			if field := t.Field(i); c.shouldSerialize(field) {
+				size += c.minSize(field.Type, depth+1)
+			}
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// Returns true iff [field] should be serialized by this version of the codec
+func (c *codec) shouldSerialize(field reflect.StructField) bool {
+	if field.Tag.Get("serialize") != "true" {
+		return false
+	}
+	versionTag, ok := field.Tag.Lookup("version")
+	if !ok {
 		return true
 	}
-	return false
+	version, err := strconv.ParseUint(versionTag, 10, 16)
+	return err == nil && uint16(version) <= c.version
 }
@@ -538,3 +538,108 @@ func TestTooLargeUnmarshal(t *testing.T) {
 		t.Fatalf("Should have errored due to too many bytes provided")
 	}
 }
+
+// Ensure deserializing a value nested deeper than the limit errors correctly
+func TestUnmarshalTooDeep(t *testing.T) {
+	val := [][][]byte{{{1}}}
+
+	codec := NewDefault()
+	bytes, err := codec.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shallowCodec := NewVersioned(0, Limits{
+		MaxSize:     defaultMaxSize,
+		MaxSliceLen: defaultMaxSliceLength,
+		MaxDepth:    2,
+	})
+	unmarshalled := [][][]byte{}
+	if err := shallowCodec.Unmarshal(bytes, &unmarshalled); err != ErrTooDeep {
+		t.Fatalf("Should have errored due to the value being nested too deeply")
+	}
+	if err := codec.Unmarshal(bytes, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure deserializing a slice longer than the remaining bytes could hold
+// errors before the slice is allocated
+func TestSliceLongerThanBytes(t *testing.T) {
+	codec := New(defaultMaxSize, 1<<30)
+
+	val := []uint64{}
+	b := []byte{0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}
+	if err := codec.Unmarshal(b, &val); err != errSliceTooLarge {
+		t.Fatalf("Should have errored due to the slice being longer than the bytes")
+	}
+}
+
+type wideStruct struct {
+	Padding [1 << 16]byte
+	Val     byte `serialize:"true"`
+}
+
+// Ensure deserializing a small value that would allocate a lot of memory errors
+// before the memory is allocated
+func TestUnmarshalAllocationLimit(t *testing.T) {
+	codec := NewDefault()
+
+	// Each 1 byte element allocates 64 KiB
+	b := []byte{0x00, 0x00, 0x04, 0x00}
+	b = append(b, make([]byte, 1<<10)...)
+	val := []wideStruct{}
+	if err := codec.Unmarshal(b, &val); err != ErrAllocationLimit {
+		t.Fatalf("Should have errored due to the value allocating too much memory")
+	}
+
+	b = []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x01}
+	if err := codec.Unmarshal(b, &val); err != nil {
+		t.Fatal(err)
+	}
+	if len(val) != 2 || val[1].Val != 1 {
+		t.Fatalf("Unmarshalled the wrong value")
+	}
+
+	// The budget is shared by every value nested in the value being unmarshalled
+	smallCodec := NewVersioned(0, Limits{
+		MaxSize:       defaultMaxSize,
+		MaxSliceLen:   defaultMaxSliceLength,
+		MaxDepth:      defaultMaxDepth,
+		MaxAllocation: 1 << 10,
+	})
+	strs := []string{}
+	b, err := codec.Marshal([]string{string(make([]byte, 600)), string(make([]byte, 600))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := smallCodec.Unmarshal(b, &strs); err != ErrAllocationLimit {
+		t.Fatalf("Should have errored due to the value allocating too much memory")
+	}
+}
+
+type infiniteReader struct{}
+
+func (infiniteReader) Read(b []byte) (int, error) { return len(b), nil }
+
+func TestUnmarshalFrom(t *testing.T) {
+	codec := NewDefault()
+
+	val := []string{"hello", "world"}
+	b, err := codec.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unmarshalled := []string{}
+	if err := codec.UnmarshalFrom(bytes.NewReader(b), &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(val, unmarshalled) {
+		t.Fatalf("Unmarshalled %v, expected %v", unmarshalled, val)
+	}
+
+	if err := codec.UnmarshalFrom(infiniteReader{}, &unmarshalled); err != errSliceTooLarge {
+		t.Fatalf("Should have errored due to the reader exceeding the max size")
+	}
+}
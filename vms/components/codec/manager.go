@@ -0,0 +1,133 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/gecko/utils/wrappers"
+)
+
+// VersionSize is the number of bytes of the version prefix of bytes
+// serialized by a Manager
+const VersionSize = wrappers.ShortLen
+
+// LegacyMarker prefixes the version of the bytes a legacy manager serializes
+// with a version other than 0. The codec never serializes a slice length or a
+// type ID of 0xffffffff, and the rare version 0 bytes that start with the
+// marker are prefixed by it and version 0 too, so bytes are only parsed as
+// prefixed if they start with the marker.
+var LegacyMarker = []byte{0xff, 0xff, 0xff, 0xff}
+
+var (
+	errUnknownVersion    = errors.New("unknown codec version")
+	errDuplicatedVersion = errors.New("codec version is already registered")
+	errCantUnpackVersion = errors.New("couldn't unpack the codec version")
+)
+
+// Manager serializes values with one of several versions of a wire format.
+// Serialized bytes are prefixed by the version of the codec that produced
+// them, so bytes serialized by an earlier version can still be parsed after a
+// VM moves to a later version. A legacy manager doesn't prefix version 0, and
+// prefixes the other versions with LegacyMarker.
+type Manager interface {
+	// Associate [c] with [version]
+	RegisterCodec(version uint16, c Codec) error
+
+	// Marshal [value] with the codec of [version]. The returned bytes are
+	// prefixed by [version].
+	Marshal(version uint16, value interface{}) ([]byte, error)
+
+	// Unmarshal [bytes] into [dest] with the codec of the version that [bytes]
+	// are prefixed by. Returns that version.
+	Unmarshal(bytes []byte, dest interface{}) (uint16, error)
+}
+
+type manager struct {
+	codecs map[uint16]Codec
+
+	// If true, version 0 isn't prefixed, and the other versions are prefixed
+	// by LegacyMarker before the version
+	unprefixedV0 bool
+}
+
+// NewManager returns a new codec manager with no registered versions
+func NewManager() Manager {
+	return &manager{codecs: make(map[uint16]Codec)}
+}
+
+// NewLegacyManager returns a new codec manager with no registered versions.
+// Version 0 is serialized without a version prefix, so that a VM that
+// serialized with a Codec before it moved to a Manager keeps producing, and
+// can still parse, the same bytes.
+func NewLegacyManager() Manager {
+	return &manager{
+		codecs:       make(map[uint16]Codec),
+		unprefixedV0: true,
+	}
+}
+
+// RegisterCodec implements the Manager interface
+func (m *manager) RegisterCodec(version uint16, c Codec) error {
+	if _, exists := m.codecs[version]; exists {
+		return fmt.Errorf("%w: %d", errDuplicatedVersion, version)
+	}
+	m.codecs[version] = c
+	return nil
+}
+
+// Marshal implements the Manager interface
+func (m *manager) Marshal(version uint16, value interface{}) ([]byte, error) {
+	c, ok := m.codecs[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", errUnknownVersion, version)
+	}
+	valueBytes, err := c.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var marker []byte
+	if m.unprefixedV0 {
+		if version == 0 && !bytes.HasPrefix(valueBytes, LegacyMarker) {
+			return valueBytes, nil
+		}
+		marker = LegacyMarker
+	}
+
+	size := len(marker) + VersionSize + len(valueBytes)
+	p := wrappers.Packer{MaxSize: size, Bytes: make([]byte, 0, size)}
+	p.PackFixedBytes(marker)
+	p.PackShort(version)
+	p.PackFixedBytes(valueBytes)
+	return p.Bytes, p.Err
+}
+
+// Unmarshal implements the Manager interface
+func (m *manager) Unmarshal(b []byte, dest interface{}) (uint16, error) {
+	if m.unprefixedV0 {
+		if !bytes.HasPrefix(b, LegacyMarker) {
+			return 0, m.unmarshal(0, b, dest)
+		}
+		b = b[len(LegacyMarker):]
+	}
+
+	p := wrappers.Packer{Bytes: b}
+	version := p.UnpackShort()
+	if p.Errored() {
+		return 0, errCantUnpackVersion
+	}
+	return version, m.unmarshal(version, b[VersionSize:], dest)
+}
+
+// Unmarshal [b], which has no version prefix, with the codec of [version]
+func (m *manager) unmarshal(version uint16, b []byte, dest interface{}) error {
+	c, ok := m.codecs[version]
+	if !ok {
+		return fmt.Errorf("%w: %d", errUnknownVersion, version)
+	}
+	return c.Unmarshal(b, dest)
+}
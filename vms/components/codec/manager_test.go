@@ -0,0 +1,234 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type versionedStruct struct {
+	Original uint32 `serialize:"true"`
+	Added    string `serialize:"true" version:"1"`
+	Foo      Foo    `serialize:"true"`
+}
+
+// MyInnerStruct4 implements Foo, and was added in version 1
+type MyInnerStruct4 struct {
+	Num uint64 `serialize:"true"`
+}
+
+func (m *MyInnerStruct4) Foo() int {
+	return 4
+}
+
+func newTestManager(t *testing.T) Manager {
+	return registerTestCodecs(t, NewManager())
+}
+
+func registerTestCodecs(t *testing.T, m Manager) Manager {
+	v0 := NewVersioned(0, DefaultLimits())
+	if err := v0.RegisterType(&MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v0.RegisterType(&MyInnerStruct2{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Version 1 dropped MyInnerStruct2 and added MyInnerStruct4
+	v1 := NewVersioned(1, DefaultLimits())
+	if err := v1.RegisterType(&MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	v1.Skip(1)
+	if err := v1.RegisterType(&MyInnerStruct4{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.RegisterCodec(0, v0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterCodec(1, v1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RegisterCodec(1, v1); !errors.Is(err, errDuplicatedVersion) {
+		t.Fatalf("Should have errored due to registering version 1 twice")
+	}
+	return m
+}
+
+func TestManagerVersions(t *testing.T) {
+	m := newTestManager(t)
+
+	val := &versionedStruct{
+		Original: 5,
+		Added:    "new",
+		Foo:      &MyInnerStruct{Str: "foo"},
+	}
+
+	v0Bytes, err := m.Marshal(0, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Bytes, err := m.Marshal(1, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v1Bytes) <= len(v0Bytes) {
+		t.Fatalf("Version 1 should have serialized the added field")
+	}
+
+	v0Val := versionedStruct{}
+	version, err := m.Unmarshal(v0Bytes, &v0Val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("Unmarshalled with version %d, expected 0", version)
+	}
+	if v0Val.Original != 5 || v0Val.Added != "" || v0Val.Foo.(*MyInnerStruct).Str != "foo" {
+		t.Fatalf("Wrong value unmarshalled with version 0: %+v", v0Val)
+	}
+
+	v1Val := versionedStruct{}
+	version, err = m.Unmarshal(v1Bytes, &v1Val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Fatalf("Unmarshalled with version %d, expected 1", version)
+	}
+	if v1Val.Original != 5 || v1Val.Added != "new" || v1Val.Foo.(*MyInnerStruct).Str != "foo" {
+		t.Fatalf("Wrong value unmarshalled with version 1: %+v", v1Val)
+	}
+}
+
+func TestManagerTypeIDs(t *testing.T) {
+	m := newTestManager(t)
+
+	if _, err := m.Marshal(0, &versionedStruct{Foo: &MyInnerStruct4{}}); err == nil {
+		t.Fatalf("Version 0 shouldn't be able to marshal a type added in version 1")
+	}
+	if _, err := m.Marshal(1, &versionedStruct{Foo: &MyInnerStruct2{}}); err == nil {
+		t.Fatalf("Version 1 shouldn't be able to marshal a type dropped in version 1")
+	}
+
+	v1Bytes, err := m.Marshal(1, &versionedStruct{Foo: &MyInnerStruct4{Num: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The type ID of MyInnerStruct2 was skipped, so MyInnerStruct4 has type ID 2
+	typeIDOffset := VersionSize + 4 + 2 + len("") // Version, Original and Added
+	if typeID := v1Bytes[typeIDOffset+3]; typeID != 2 {
+		t.Fatalf("MyInnerStruct4 has type ID %d, expected 2", typeID)
+	}
+
+	val := versionedStruct{}
+	if _, err := m.Unmarshal(v1Bytes, &val); err != nil {
+		t.Fatal(err)
+	}
+	if foo, ok := val.Foo.(*MyInnerStruct4); !ok || foo.Num != 3 {
+		t.Fatalf("Wrong value unmarshalled: %+v", val)
+	}
+}
+
+func TestManagerUnknownVersion(t *testing.T) {
+	m := newTestManager(t)
+
+	if _, err := m.Marshal(2, &versionedStruct{}); !errors.Is(err, errUnknownVersion) {
+		t.Fatalf("Should have errored due to an unknown version")
+	}
+	if _, err := m.Unmarshal([]byte{0, 2, 0, 0, 0, 0}, &versionedStruct{}); !errors.Is(err, errUnknownVersion) {
+		t.Fatalf("Should have errored due to an unknown version")
+	}
+	if _, err := m.Unmarshal([]byte{0}, &versionedStruct{}); err != errCantUnpackVersion {
+		t.Fatalf("Should have errored due to a missing version")
+	}
+}
+
+func TestLegacyManager(t *testing.T) {
+	m := registerTestCodecs(t, NewLegacyManager())
+
+	val := &versionedStruct{
+		Original: 5,
+		Added:    "new",
+		Foo:      &MyInnerStruct{Str: "foo"},
+	}
+
+	legacy := NewVersioned(0, DefaultLimits())
+	if err := legacy.RegisterType(&MyInnerStruct{}); err != nil {
+		t.Fatal(err)
+	}
+	legacyBytes, err := legacy.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v0Bytes, err := m.Marshal(0, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v0Bytes, legacyBytes) {
+		t.Fatalf("Version 0 should be serialized without a prefix")
+	}
+
+	v0Val := versionedStruct{}
+	version, err := m.Unmarshal(legacyBytes, &v0Val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("Unmarshalled with version %d, expected 0", version)
+	}
+	if v0Val.Original != 5 || v0Val.Added != "" || v0Val.Foo.(*MyInnerStruct).Str != "foo" {
+		t.Fatalf("Wrong value unmarshalled with version 0: %+v", v0Val)
+	}
+
+	v1Bytes, err := m.Marshal(1, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Val := versionedStruct{}
+	version, err = m.Unmarshal(v1Bytes, &v1Val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 {
+		t.Fatalf("Unmarshalled with version %d, expected 1", version)
+	}
+	if v1Val.Added != "new" {
+		t.Fatalf("Wrong value unmarshalled with version 1: %+v", v1Val)
+	}
+	if !bytes.HasPrefix(v1Bytes, LegacyMarker) {
+		t.Fatalf("Version 1 should be prefixed by the legacy marker")
+	}
+
+	// Version 0 bytes that start like version 1 bytes would without the
+	// marker are still parsed with version 0
+	looksV1 := &versionedStruct{Original: 1 << 16, Foo: &MyInnerStruct{}}
+	looksV1Bytes, err := m.Marshal(0, looksV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, err := m.Unmarshal(looksV1Bytes, &versionedStruct{}); err != nil || version != 0 {
+		t.Fatalf("Unmarshalled with version %d, expected 0: %v", version, err)
+	}
+
+	// Version 0 bytes that start with the marker are prefixed, so they
+	// aren't mistaken for another version
+	looksPrefixed := &versionedStruct{Original: 0xffffffff, Foo: &MyInnerStruct{}}
+	looksPrefixedBytes, err := m.Marshal(0, looksPrefixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	looksPrefixedVal := versionedStruct{}
+	version, err = m.Unmarshal(looksPrefixedBytes, &looksPrefixedVal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 || looksPrefixedVal.Original != 0xffffffff {
+		t.Fatalf("Unmarshalled %+v with version %d, expected version 0", looksPrefixedVal, version)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/vms/components/missing"
+	"github.com/ava-labs/gecko/vms/components/state"
 )
 
 var (
@@ -56,15 +57,40 @@ func (b *Block) Parent() snowman.Block {
 func (b *Block) Accept() {
 	b.SetStatus(choices.Accepted)                           // Change state of this block
 	b.VM.State.PutStatus(b.VM.DB, b.ID(), choices.Accepted) // Persist data
+	b.indexHeight()
 	b.VM.State.PutLastAccepted(b.VM.DB, b.ID())
 	b.VM.lastAccepted = b.ID() // Change state of VM
 }
 
+// indexHeight records that [b], which is being accepted, is the accepted block
+// at the height after the last accepted block. The genesis block is at height
+// 0.
+func (b *Block) indexHeight() {
+	height, err := b.VM.State.GetLastAcceptedHeight(b.VM.DB)
+	switch {
+	case err == nil:
+		height++
+	case b.VM.lastAccepted.IsZero(): // [b] is the genesis block
+		height = 0
+	default: // Blocks accepted before heights were indexed aren't indexed
+		return
+	}
+	b.VM.State.PutBlockIDAtHeight(b.VM.DB, height, b.ID())
+	b.VM.State.PutLastAcceptedHeight(b.VM.DB, height)
+}
+
 // Reject sets this block's status to Rejected and saves the status in state
 // Recall that b.vm.DB.Commit() must be called to persist to the DB
+// If the chain's state is pruned, the block's bytes are scheduled to be
+// deleted. Its status is kept, so it's never verified again.
 func (b *Block) Reject() {
 	b.SetStatus(choices.Rejected)
 	b.VM.State.PutStatus(b.VM.DB, b.ID(), choices.Rejected)
+	if pruner := b.VM.Ctx.Pruner; pruner != nil {
+		if err := pruner.Schedule(b.VM.State.DBKey(state.BlockTypeID, b.ID())); err != nil {
+			b.VM.Ctx.Log.Warn("couldn't schedule rejected block %s to be pruned due to %s", b.ID(), err)
+		}
+	}
 }
 
 // Status returns the status of this block
@@ -17,6 +17,12 @@ var errWrongType = errors.New("got unexpected type from database")
 // state.Get(Db, IDTypeID, lastAcceptedID) == ID of last accepted block
 var lastAcceptedID = ids.NewID([32]byte{'l', 'a', 's', 't'})
 
+// state.GetUint64(Db, lastAcceptedHeightID) == height of last accepted block
+var lastAcceptedHeightID = ids.NewID([32]byte{'l', 'a', 's', 't', 'h', 'e', 'i', 'g', 'h', 't'})
+
+// state.GetID(Db, heightID.Prefix(height)) == ID of accepted block at [height]
+var heightID = ids.NewID([32]byte{'h', 'e', 'i', 'g', 'h', 't'})
+
 // SnowmanState is a wrapper around state.State
 // In additions to the methods exposed by state.State,
 // SnowmanState exposes a few methods needed for managing
@@ -27,6 +33,10 @@ type SnowmanState interface {
 	PutBlock(database.Database, snowman.Block) error
 	GetLastAccepted(database.Database) (ids.ID, error)
 	PutLastAccepted(database.Database, ids.ID) error
+	GetLastAcceptedHeight(database.Database) (uint64, error)
+	PutLastAcceptedHeight(database.Database, uint64) error
+	GetBlockIDAtHeight(database.Database, uint64) (ids.ID, error)
+	PutBlockIDAtHeight(database.Database, uint64, ids.ID) error
 }
 
 // implements SnowmanState
@@ -66,6 +76,28 @@ func (s *snowmanState) PutLastAccepted(db database.Database, lastAccepted ids.ID
 	return s.PutID(db, lastAcceptedID, lastAccepted)
 }
 
+// GetLastAcceptedHeight returns the height of the last accepted block in [db]
+func (s *snowmanState) GetLastAcceptedHeight(db database.Database) (uint64, error) {
+	return s.GetUint64(db, lastAcceptedHeightID)
+}
+
+// PutLastAcceptedHeight sets the height of the last accepted block in [db] to
+// [height]
+func (s *snowmanState) PutLastAcceptedHeight(db database.Database, height uint64) error {
+	return s.PutUint64(db, lastAcceptedHeightID, height)
+}
+
+// GetBlockIDAtHeight returns the ID of the accepted block at [height] in [db]
+func (s *snowmanState) GetBlockIDAtHeight(db database.Database, height uint64) (ids.ID, error) {
+	return s.GetID(db, heightID.Prefix(height))
+}
+
+// PutBlockIDAtHeight sets the ID of the accepted block at [height] in [db] to
+// [blockID]
+func (s *snowmanState) PutBlockIDAtHeight(db database.Database, height uint64, blockID ids.ID) error {
+	return s.PutID(db, heightID.Prefix(height), blockID)
+}
+
 // NewSnowmanState returns a new SnowmanState
 func NewSnowmanState(unmarshalBlockFunc func([]byte) (snowman.Block, error)) (SnowmanState, error) {
 	rawState := state.NewState()
@@ -16,6 +16,7 @@ import (
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
 	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/utils/wrappers"
 	"github.com/ava-labs/gecko/vms/components/state"
 )
 
@@ -63,6 +64,30 @@ func (svm *SnowmanVM) Preferred() ids.ID { return svm.preferred }
 // LastAccepted returns the block most recently accepted
 func (svm *SnowmanVM) LastAccepted() ids.ID { return svm.lastAccepted }
 
+// SetLastAccepted records in [db] that the block [blkID], at [height], is the
+// last accepted block, and builds on it. It's used when the VM's state is
+// replaced by the state at [blkID], rather than by accepting [blkID].
+func (svm *SnowmanVM) SetLastAccepted(db database.Database, blkID ids.ID, height uint64) error {
+	errs := wrappers.Errs{}
+	errs.Add(
+		svm.State.PutStatus(db, blkID, choices.Accepted),
+		svm.State.PutBlockIDAtHeight(db, height, blkID),
+		svm.State.PutLastAcceptedHeight(db, height),
+		svm.State.PutLastAccepted(db, blkID),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	svm.lastAccepted = blkID
+	svm.preferred = blkID
+	return nil
+}
+
+// GetBlockIDAtHeight returns the ID of the accepted block at [height]
+func (svm *SnowmanVM) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	return svm.State.GetBlockIDAtHeight(svm.DB, height)
+}
+
 // ParseBlock parses [bytes] to a block
 func (svm *SnowmanVM) ParseBlock(bytes []byte) (snowman.Block, error) {
 	return svm.unmarshalBlockFunc(bytes)
@@ -83,9 +108,13 @@ func (svm *SnowmanVM) GetBlock(ID ids.ID) (snowman.Block, error) {
 
 // Shutdown this vm
 func (svm *SnowmanVM) Shutdown() {
-	svm.DB.Commit()              // Flush DB
-	svm.DB.GetDatabase().Close() // close underlying database
-	svm.DB.Close()               // close versionDB
+	if err := svm.DB.Commit(); err != nil { // Flush DB
+		svm.Ctx.Log.Error("Flushing the database failed with %s", err)
+	}
+	if err := svm.DB.GetDatabase().Close(); err != nil { // close underlying database
+		svm.Ctx.Log.Error("Closing the database failed with %s", err)
+	}
+	svm.DB.Close() // close versionDB
 }
 
 // DBInitialized returns true iff [svm]'s database has values in it already
@@ -149,6 +178,11 @@ func (svm *SnowmanVM) Initialize(
 	svm.Ctx = ctx
 	svm.ToEngine = toEngine
 	svm.DB = versiondb.New(db)
+	if ctx.Metrics != nil {
+		if err := svm.DB.Register(ctx.Namespace, ctx.Metrics); err != nil {
+			ctx.Log.Error("Failed to register versiondb statistics due to %s", err)
+		}
+	}
 
 	var err error
 	svm.State, err = NewSnowmanState(unmarshalBlockFunc)
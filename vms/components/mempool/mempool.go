@@ -0,0 +1,374 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"container/heap"
+	"container/list"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// DefaultMaxTxs is the default maximum number of txs in a mempool
+	DefaultMaxTxs = 8192
+
+	// DefaultMaxBytes is the default maximum total size, in bytes, of the txs
+	// in a mempool
+	DefaultMaxBytes = 32 * 1024 * 1024
+)
+
+var (
+	errDuplicateTx = errors.New("tx is already in the mempool")
+	errTxTooLarge  = errors.New("tx is larger than the mempool")
+	errMempoolFull = errors.New("mempool is full of txs with higher fees")
+)
+
+// Policy is the order in which txs are removed from a mempool to be issued to
+// consensus
+type Policy byte
+
+// Policies that can be used by a mempool
+const (
+	// ArrivalOrder issues the oldest txs first
+	ArrivalOrder Policy = iota
+
+	// FeeDensityOrder issues the txs paying the highest fee per byte first.
+	// Txs paying the same fee per byte are issued oldest first. Only VMs that
+	// charge fees can order by fee: AVM txs pay fees only when a fee asset is
+	// configured, and platform decision txs pay a fee of 0, so without fees
+	// this is the same as ArrivalOrder.
+	FeeDensityOrder
+)
+
+// ParsePolicy returns the policy named [name]
+func ParsePolicy(name string) (Policy, error) {
+	switch name {
+	case "arrival":
+		return ArrivalOrder, nil
+	case "fee-density":
+		return FeeDensityOrder, nil
+	default:
+		return 0, fmt.Errorf("unknown mempool policy %q. Should be one of {arrival, fee-density}", name)
+	}
+}
+
+func (p Policy) String() string {
+	switch p {
+	case ArrivalOrder:
+		return "arrival"
+	case FeeDensityOrder:
+		return "fee-density"
+	default:
+		return "unknown"
+	}
+}
+
+// Tx is a transaction that can be stored in a mempool
+type Tx interface {
+	Bytes() []byte
+}
+
+// Config is the configuration of a mempool. Zero values are replaced with
+// the defaults.
+type Config struct {
+	MaxTxs   int
+	MaxBytes int
+	Policy   Policy
+
+	// ChainPolicies are the policies of individual chains, keyed by chain ID
+	// or alias. They take precedence over Policy.
+	ChainPolicies map[string]Policy
+}
+
+// ChainConfig returns the configuration of the mempool of the chain
+// [chainID]. The keys of [c.ChainPolicies] are resolved to chain IDs with
+// [lookup].
+func (c Config) ChainConfig(chainID ids.ID, lookup func(string) (ids.ID, error)) Config {
+	for name, policy := range c.ChainPolicies {
+		if name == chainID.String() {
+			c.Policy = policy
+			break
+		}
+		if id, err := lookup(name); err == nil && id.Equals(chainID) {
+			c.Policy = policy
+			break
+		}
+	}
+	c.ChainPolicies = nil
+	return c
+}
+
+// Mempool holds the txs that were issued to a VM but haven't been issued to
+// consensus yet.
+//
+// The size of the mempool is capped by both the number of txs and the number
+// of bytes it holds. When adding a tx would exceed a cap, txs are evicted in
+// order of lowest fee first and, for equal fees, oldest first. If making room
+// for a tx would evict a tx that pays a higher fee, the new tx is denied
+// instead.
+type Mempool struct {
+	config Config
+
+	txs     map[[32]byte]*entry
+	order   list.List // Txs in the order they were added
+	evict   entryHeap // Txs in the order they should be evicted
+	issue   entryHeap // Txs in the order they should be issued, by fee density
+	bytes   int
+	nextSeq uint64
+
+	numTxs, numBytes      prometheus.Gauge
+	numEvicted, numDenied prometheus.Counter
+}
+
+type entry struct {
+	txID ids.ID
+	tx   Tx
+	fee  uint64
+	seq  uint64
+	size int
+	elem *list.Element // position of this entry in the arrival order
+
+	evictIndex int // index of this entry in the eviction heap
+	issueIndex int // index of this entry in the issuance heap
+}
+
+// Initialize the mempool. The mempool's metrics are registered with
+// [registerer] under [namespace]. If [registerer] is nil, the metrics aren't
+// registered.
+func (m *Mempool) Initialize(log logging.Logger, config Config, namespace string, registerer prometheus.Registerer) {
+	if config.MaxTxs <= 0 {
+		config.MaxTxs = DefaultMaxTxs
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxBytes
+	}
+	m.config = config
+	m.txs = make(map[[32]byte]*entry)
+	m.order.Init()
+	m.evict = entryHeap{
+		less:  lowerFee,
+		index: func(e *entry) *int { return &e.evictIndex },
+	}
+	m.issue = entryHeap{
+		less:  higherFeeDensity,
+		index: func(e *entry) *int { return &e.issueIndex },
+	}
+
+	m.numTxs = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mempool_txs",
+			Help:      "Number of txs in the mempool",
+		})
+	m.numBytes = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "mempool_bytes",
+			Help:      "Number of bytes of txs in the mempool",
+		})
+	m.numEvicted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mempool_evicted",
+			Help:      "Number of txs evicted from the mempool to make room for other txs",
+		})
+	m.numDenied = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mempool_denied",
+			Help:      "Number of txs not added to the mempool because it was full",
+		})
+
+	if registerer == nil {
+		return
+	}
+	for _, collector := range []prometheus.Collector{m.numTxs, m.numBytes, m.numEvicted, m.numDenied} {
+		if err := registerer.Register(collector); err != nil {
+			log.Error("Failed to register mempool statistics due to %s", err)
+		}
+	}
+}
+
+// Add [tx], whose ID is [txID], to the mempool. [fee] is the fee paid by
+// [tx]. Returns the IDs of the txs that were evicted to make room for [tx].
+func (m *Mempool) Add(txID ids.ID, tx Tx, fee uint64) ([]ids.ID, error) {
+	key := txID.Key()
+	if _, exists := m.txs[key]; exists {
+		return nil, errDuplicateTx
+	}
+
+	size := len(tx.Bytes())
+	if size > m.config.MaxBytes {
+		m.numDenied.Inc()
+		return nil, errTxTooLarge
+	}
+
+	// Take the txs that would need to be evicted off of the eviction heap. If
+	// any of them pays a higher fee than [tx], put them back and deny [tx].
+	victims := []*entry(nil)
+	numTxs, bytes := len(m.txs), m.bytes
+	for numTxs+1 > m.config.MaxTxs || bytes+size > m.config.MaxBytes {
+		e := heap.Pop(&m.evict).(*entry)
+		victims = append(victims, e)
+		if e.fee > fee {
+			for _, victim := range victims {
+				heap.Push(&m.evict, victim)
+			}
+			m.numDenied.Inc()
+			return nil, errMempoolFull
+		}
+		numTxs--
+		bytes -= e.size
+	}
+
+	evicted := make([]ids.ID, len(victims))
+	for i, victim := range victims {
+		delete(m.txs, victim.txID.Key())
+		m.order.Remove(victim.elem)
+		heap.Remove(&m.issue, victim.issueIndex)
+		m.bytes -= victim.size
+		m.numEvicted.Inc()
+		evicted[i] = victim.txID
+	}
+
+	e := &entry{
+		txID: txID,
+		tx:   tx,
+		fee:  fee,
+		seq:  m.nextSeq,
+		size: size,
+	}
+	m.nextSeq++
+	e.elem = m.order.PushBack(e)
+	heap.Push(&m.evict, e)
+	heap.Push(&m.issue, e)
+	m.txs[key] = e
+	m.bytes += size
+	m.updateGauges()
+	return evicted, nil
+}
+
+// Has returns true if the tx with ID [txID] is in the mempool
+func (m *Mempool) Has(txID ids.ID) bool {
+	_, exists := m.txs[txID.Key()]
+	return exists
+}
+
+// Remove the tx with ID [txID] from the mempool. Returns the removed tx, or
+// nil if it wasn't in the mempool.
+func (m *Mempool) Remove(txID ids.ID) Tx {
+	e, exists := m.txs[txID.Key()]
+	if !exists {
+		return nil
+	}
+	m.remove(e)
+	m.updateGauges()
+	return e.tx
+}
+
+// Pop removes and returns up to [n] txs from the mempool, in the order
+// specified by the mempool's policy
+func (m *Mempool) Pop(n int) []Tx {
+	txs := []Tx(nil)
+	for len(txs) < n && len(m.txs) > 0 {
+		var e *entry
+		switch m.config.Policy {
+		case FeeDensityOrder:
+			e = m.issue.entries[0]
+		default:
+			e = m.order.Front().Value.(*entry)
+		}
+		m.remove(e)
+		txs = append(txs, e.tx)
+	}
+	m.updateGauges()
+	return txs
+}
+
+// PopAll removes and returns all the txs in the mempool, in the order
+// specified by the mempool's policy
+func (m *Mempool) PopAll() []Tx { return m.Pop(len(m.txs)) }
+
+// Len returns the number of txs in the mempool
+func (m *Mempool) Len() int { return len(m.txs) }
+
+// Size returns the number of bytes of txs in the mempool
+func (m *Mempool) Size() int { return m.bytes }
+
+func (m *Mempool) remove(e *entry) {
+	delete(m.txs, e.txID.Key())
+	m.order.Remove(e.elem)
+	heap.Remove(&m.evict, e.evictIndex)
+	heap.Remove(&m.issue, e.issueIndex)
+	m.bytes -= e.size
+}
+
+func (m *Mempool) updateGauges() {
+	m.numTxs.Set(float64(len(m.txs)))
+	m.numBytes.Set(float64(m.bytes))
+}
+
+// lowerFee returns true if [a] pays a lower fee than [b], or if they pay the
+// same fee and [a] is older
+func lowerFee(a, b *entry) bool {
+	if a.fee != b.fee {
+		return a.fee < b.fee
+	}
+	return a.seq < b.seq
+}
+
+// higherFeeDensity returns true if [a] pays a higher fee per byte than [b], or
+// if they pay the same fee per byte and [a] is older
+func higherFeeDensity(a, b *entry) bool {
+	// a.fee/a.size > b.fee/b.size iff a.fee*b.size > b.fee*a.size. The products
+	// are compared as 128 bit numbers so they can't overflow.
+	aHi, aLo := bits.Mul64(a.fee, uint64(b.size))
+	bHi, bLo := bits.Mul64(b.fee, uint64(a.size))
+	if aHi != bHi {
+		return aHi > bHi
+	}
+	if aLo != bLo {
+		return aLo > bLo
+	}
+	return a.seq < b.seq
+}
+
+// entryHeap is a heap of entries. The entry at the top of the heap is the one
+// that is [less] than all others.
+type entryHeap struct {
+	entries []*entry
+	less    func(a, b *entry) bool
+	index   func(e *entry) *int // The field of an entry that holds its index
+}
+
+func (h *entryHeap) Len() int { return len(h.entries) }
+
+func (h *entryHeap) Less(i, j int) bool { return h.less(h.entries[i], h.entries[j]) }
+
+func (h *entryHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	*h.index(h.entries[i]) = i
+	*h.index(h.entries[j]) = j
+}
+
+func (h *entryHeap) Push(x interface{}) {
+	e := x.(*entry)
+	*h.index(e) = len(h.entries)
+	h.entries = append(h.entries, e)
+}
+
+func (h *entryHeap) Pop() interface{} {
+	last := len(h.entries) - 1
+	e := h.entries[last]
+	h.entries[last] = nil
+	h.entries = h.entries[:last]
+	return e
+}
@@ -0,0 +1,224 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package mempool
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type testTx struct {
+	id    ids.ID
+	bytes []byte
+}
+
+func (tx *testTx) Bytes() []byte { return tx.bytes }
+
+func newTestTx(id byte, size int) *testTx {
+	return &testTx{
+		id:    ids.NewID([32]byte{id}),
+		bytes: make([]byte, size),
+	}
+}
+
+func newTestMempool(config Config) *Mempool {
+	m := &Mempool{}
+	m.Initialize(logging.NoLog{}, config, "", prometheus.NewRegistry())
+	return m
+}
+
+func TestMempoolPopOrder(t *testing.T) {
+	m := newTestMempool(Config{})
+
+	tx0 := newTestTx(0, 1)
+	tx1 := newTestTx(1, 1)
+	tx2 := newTestTx(2, 1)
+	for i, tx := range []*testTx{tx0, tx1, tx2} {
+		if _, err := m.Add(tx.id, tx, uint64(3-i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Add(tx0.id, tx0, 0); err == nil {
+		t.Fatalf("Should have errored due to adding a duplicated tx")
+	}
+
+	if removed := m.Remove(tx1.id); removed != tx1 {
+		t.Fatalf("Should have removed tx1")
+	}
+	if m.Has(tx1.id) {
+		t.Fatalf("Shouldn't have tx1 after removing it")
+	}
+
+	txs := m.PopAll()
+	switch {
+	case len(txs) != 2:
+		t.Fatalf("Should have popped 2 txs, popped %d", len(txs))
+	case txs[0] != tx0, txs[1] != tx2:
+		t.Fatalf("Should have popped the txs in the order they were added")
+	case m.Len() != 0, m.Size() != 0:
+		t.Fatalf("Mempool should be empty")
+	}
+}
+
+func TestMempoolEvictsByFeeThenAge(t *testing.T) {
+	m := newTestMempool(Config{MaxTxs: 3})
+
+	tx0 := newTestTx(0, 1)
+	tx1 := newTestTx(1, 1)
+	tx2 := newTestTx(2, 1)
+	tx3 := newTestTx(3, 1)
+	tx4 := newTestTx(4, 1)
+
+	if _, err := m.Add(tx0.id, tx0, 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx1.id, tx1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx2.id, tx2, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Add(tx3.id, tx3, 0); err == nil {
+		t.Fatalf("Should have denied a tx paying a lower fee than every tx in the mempool")
+	}
+
+	evicted, err := m.Add(tx3.id, tx3, 1)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(evicted) != 1 || !evicted[0].Equals(tx1.id):
+		t.Fatalf("Should have evicted the oldest of the cheapest txs")
+	}
+
+	evicted, err = m.Add(tx4.id, tx4, 10)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(evicted) != 1 || !evicted[0].Equals(tx2.id):
+		t.Fatalf("Should have evicted the oldest of the cheapest txs")
+	case m.Len() != 3:
+		t.Fatalf("Mempool should be full")
+	}
+}
+
+func TestMempoolMaxBytes(t *testing.T) {
+	m := newTestMempool(Config{MaxBytes: 10})
+
+	tx0 := newTestTx(0, 4)
+	tx1 := newTestTx(1, 4)
+	tx2 := newTestTx(2, 8)
+	tx3 := newTestTx(3, 11)
+
+	if _, err := m.Add(tx3.id, tx3, 100); err == nil {
+		t.Fatalf("Should have denied a tx larger than the mempool")
+	}
+	if _, err := m.Add(tx0.id, tx0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx1.id, tx1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Add(tx2.id, tx2, 1); err == nil {
+		t.Fatalf("Should have denied a tx that would evict a tx paying a higher fee")
+	}
+	if !m.Has(tx0.id) || !m.Has(tx1.id) || m.Size() != 8 {
+		t.Fatalf("A denied tx shouldn't modify the mempool")
+	}
+
+	evicted, err := m.Add(tx2.id, tx2, 2)
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case len(evicted) != 2:
+		t.Fatalf("Should have evicted both txs")
+	case m.Size() != 8:
+		t.Fatalf("Wrong mempool size")
+	}
+}
+
+func TestMempoolFeeDensityOrder(t *testing.T) {
+	m := newTestMempool(Config{Policy: FeeDensityOrder})
+
+	tx0 := newTestTx(0, 10) // 1 per byte
+	tx1 := newTestTx(1, 2)  // 2 per byte
+	tx2 := newTestTx(2, 1)  // 1 per byte
+	tx3 := newTestTx(3, 1)  // 0 per byte
+
+	if _, err := m.Add(tx0.id, tx0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx1.id, tx1, 4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx2.id, tx2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Add(tx3.id, tx3, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if txs := m.Pop(1); len(txs) != 1 || txs[0] != tx1 {
+		t.Fatalf("Should have popped the tx paying the most per byte first")
+	}
+	m.Remove(tx2.id)
+	txs := m.PopAll()
+	switch {
+	case len(txs) != 2:
+		t.Fatalf("Should have popped 2 txs, popped %d", len(txs))
+	case txs[0] != tx0, txs[1] != tx3:
+		t.Fatalf("Popped the txs in the wrong order")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, policy := range []Policy{ArrivalOrder, FeeDensityOrder} {
+		if parsed, err := ParsePolicy(policy.String()); err != nil {
+			t.Fatal(err)
+		} else if parsed != policy {
+			t.Fatalf("Parsed %s as %s", policy, parsed)
+		}
+	}
+	if _, err := ParsePolicy("lifo"); err == nil {
+		t.Fatalf("Should have errored due to an unknown policy")
+	}
+}
+
+func TestChainConfig(t *testing.T) {
+	xChainID := ids.NewID([32]byte{'x'})
+	pChainID := ids.NewID([32]byte{'p'})
+	otherChainID := ids.NewID([32]byte{'o'})
+
+	aliaser := ids.Aliaser{}
+	aliaser.Initialize()
+	if err := aliaser.Alias(xChainID, "X"); err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{
+		MaxTxs: 10,
+		Policy: ArrivalOrder,
+		ChainPolicies: map[string]Policy{
+			"X":               FeeDensityOrder,
+			pChainID.String(): FeeDensityOrder,
+		},
+	}
+
+	if chainConfig := config.ChainConfig(xChainID, aliaser.Lookup); chainConfig.Policy != FeeDensityOrder {
+		t.Fatalf("Should have used the policy of the chain's alias")
+	} else if chainConfig.MaxTxs != 10 {
+		t.Fatalf("Should have kept the size of the mempool")
+	}
+	if chainConfig := config.ChainConfig(pChainID, aliaser.Lookup); chainConfig.Policy != FeeDensityOrder {
+		t.Fatalf("Should have used the policy of the chain's ID")
+	}
+	if chainConfig := config.ChainConfig(otherChainID, aliaser.Lookup); chainConfig.Policy != ArrivalOrder {
+		t.Fatalf("Should have used the default policy")
+	}
+}
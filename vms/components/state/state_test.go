@@ -435,3 +435,23 @@ func TestHappyPath(t *testing.T) {
 		t.Fatal("values should be same")
 	}
 }
+
+func TestPutGetUint64(t *testing.T) {
+	state := NewState()
+	db := memdb.New()
+	defer db.Close()
+
+	key := ids.NewID([32]byte{1, 2, 3})
+	if _, err := state.GetUint64(db, key); err == nil {
+		t.Fatal("should have failed because no such key exists")
+	}
+
+	if err := state.PutUint64(db, key, 12345); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := state.GetUint64(db, key); err != nil {
+		t.Fatal(err)
+	} else if value != 12345 {
+		t.Fatalf("expected %d but got %d", 12345, value)
+	}
+}
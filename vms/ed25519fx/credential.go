@@ -0,0 +1,45 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// PublicKeyLen is the number of bytes in a public key
+	PublicKeyLen = ed25519.PublicKeySize
+
+	// SignatureLen is the number of bytes in a signature
+	SignatureLen = ed25519.SignatureSize
+)
+
+var (
+	errNilCredential = errors.New("nil credential")
+)
+
+// Signature is a signature along with the public key that made it. Ed25519
+// public keys can't be recovered from their signatures, so the key must be
+// provided to check that it hashes to the expected address.
+type Signature struct {
+	PublicKey [PublicKeyLen]byte `serialize:"true"`
+	Sig       [SignatureLen]byte `serialize:"true"`
+}
+
+// Credential ...
+type Credential struct {
+	Sigs []Signature `serialize:"true"`
+}
+
+// Verify ...
+func (cr *Credential) Verify() error {
+	switch {
+	case cr == nil:
+		return errNilCredential
+	default:
+		return nil
+	}
+}
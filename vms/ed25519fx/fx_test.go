@@ -0,0 +1,216 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+)
+
+var txBytes = []byte{0, 1, 2, 3, 4, 5}
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct{ bytes []byte }
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+func setup(t *testing.T) (*Fx, *Keychain, *crypto.PrivateKeyED25519) {
+	vm := &testVM{}
+	vm.clock.Set(time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC))
+	fx := &Fx{}
+	if err := fx.Initialize(vm); err != nil {
+		t.Fatal(err)
+	}
+	kc := NewKeychain()
+	sk, err := kc.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fx, kc, sk
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	fx, kc, sk := setup(t)
+	tx := &testTx{bytes: txBytes}
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+		},
+	}
+	inIntf, keys, err := kc.Spend(out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := kc.Sign(txBytes, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(tx, out, inIntf, cred); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxVerifyTransferWrongSig(t *testing.T) {
+	fx, kc, sk := setup(t)
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+		},
+	}
+	inIntf, keys, err := kc.Spend(out, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := kc.Sign([]byte{1}, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(&testTx{bytes: txBytes}, out, inIntf, cred); err == nil {
+		t.Fatalf("Should have errored due to a signature of the wrong bytes")
+	}
+}
+
+func TestFxVerifyTransferWrongSigner(t *testing.T) {
+	fx, kc, sk := setup(t)
+	other, err := kc.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+		},
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: Input{SigIndices: []uint32{0}},
+	}
+	cred, err := kc.Sign(txBytes, []*crypto.PrivateKeyED25519{other})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(&testTx{bytes: txBytes}, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a signature by the wrong key")
+	}
+}
+
+func TestFxVerifyTransferSigIndexOutOfBounds(t *testing.T) {
+	fx, kc, sk := setup(t)
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+		},
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: Input{SigIndices: []uint32{1}},
+	}
+	cred, err := kc.Sign(txBytes, []*crypto.PrivateKeyED25519{sk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(&testTx{bytes: txBytes}, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to an out of bounds signer index")
+	}
+}
+
+func TestFxVerifyTransferTimelocked(t *testing.T) {
+	fx, kc, sk := setup(t)
+	out := &TransferOutput{
+		Amt:      1,
+		Locktime: uint64(fx.vm.Clock().Unix() + 1),
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+		},
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: Input{SigIndices: []uint32{0}},
+	}
+	cred, err := kc.Sign(txBytes, []*crypto.PrivateKeyED25519{sk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(&testTx{bytes: txBytes}, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a timelocked output")
+	}
+}
+
+func TestFxVerifyOperation(t *testing.T) {
+	fx, kc, sk := setup(t)
+	owners := OutputOwners{
+		Threshold: 1,
+		Addrs:     []ids.ShortID{sk.PublicKey().Address()},
+	}
+	utxo := &MintOutput{OutputOwners: owners}
+	in := &MintInput{Input: Input{SigIndices: []uint32{0}}}
+	cred, err := kc.Sign(txBytes, []*crypto.PrivateKeyED25519{sk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mintOutput := &MintOutput{OutputOwners: owners}
+	transferOutput := &TransferOutput{Amt: 1, OutputOwners: owners}
+
+	if err := fx.VerifyOperation(
+		&testTx{bytes: txBytes},
+		[]interface{}{utxo},
+		[]interface{}{in},
+		[]interface{}{cred},
+		[]interface{}{mintOutput, transferOutput},
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFxCredentialSerialization(t *testing.T) {
+	_, kc, sk := setup(t)
+	cred, err := kc.Sign(txBytes, []*crypto.PrivateKeyED25519{sk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := codec.NewDefault()
+	credBytes, err := c.Marshal(cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedCred := &Credential{}
+	if err := c.Unmarshal(credBytes, parsedCred); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsedCred.Sigs) != 1 || parsedCred.Sigs[0] != cred.Sigs[0] {
+		t.Fatalf("Credential changed when it was serialized")
+	}
+}
@@ -0,0 +1,150 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/verify"
+)
+
+var (
+	errLockedFunds = errors.New("funds currently locked")
+	errCantSpend   = errors.New("utxo couldn't be spent")
+)
+
+// Keychain is a collection of keys that can be used to spend outputs
+type Keychain struct {
+	factory        *crypto.FactoryED25519
+	addrToKeyIndex map[[20]byte]int
+
+	// These can be used to iterate over. However, they should not be modified externally.
+	Addrs ids.ShortSet
+	Keys  []*crypto.PrivateKeyED25519
+}
+
+// NewKeychain returns a new, empty, keychain
+func NewKeychain() *Keychain {
+	return &Keychain{
+		factory:        &crypto.FactoryED25519{},
+		addrToKeyIndex: make(map[[20]byte]int),
+	}
+}
+
+// Add a new key to the key chain
+func (kc *Keychain) Add(key *crypto.PrivateKeyED25519) {
+	addr := key.PublicKey().Address()
+	addrHash := addr.Key()
+	if _, ok := kc.addrToKeyIndex[addrHash]; !ok {
+		kc.addrToKeyIndex[addrHash] = len(kc.Keys)
+		kc.Keys = append(kc.Keys, key)
+		kc.Addrs.Add(addr)
+	}
+}
+
+// Get a key from the keychain. If the key is unknown, the second return value
+// is false
+func (kc Keychain) Get(id ids.ShortID) (*crypto.PrivateKeyED25519, bool) {
+	if i, ok := kc.addrToKeyIndex[id.Key()]; ok {
+		return kc.Keys[i], true
+	}
+	return &crypto.PrivateKeyED25519{}, false
+}
+
+// Addresses returns a list of addresses this keychain manages
+func (kc Keychain) Addresses() ids.ShortSet { return kc.Addrs }
+
+// New returns a newly generated private key
+func (kc *Keychain) New() (*crypto.PrivateKeyED25519, error) {
+	skGen, err := kc.factory.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sk := skGen.(*crypto.PrivateKeyED25519)
+	kc.Add(sk)
+	return sk, nil
+}
+
+// Spend attempts to create an input
+func (kc *Keychain) Spend(out verify.Verifiable, time uint64) (verify.Verifiable, []*crypto.PrivateKeyED25519, error) {
+	switch out := out.(type) {
+	case *MintOutput:
+		if sigIndices, keys, able := kc.Match(&out.OutputOwners); able {
+			return &MintInput{
+				Input: Input{
+					SigIndices: sigIndices,
+				},
+			}, keys, nil
+		}
+	case *TransferOutput:
+		if time < out.Locktime {
+			return nil, nil, errLockedFunds
+		}
+		if sigIndices, keys, able := kc.Match(&out.OutputOwners); able {
+			return &TransferInput{
+				Amt: out.Amt,
+				Input: Input{
+					SigIndices: sigIndices,
+				},
+			}, keys, nil
+		}
+	}
+	return nil, nil, errCantSpend
+}
+
+// Match attempts to match a list of addresses up to the provided threshold
+func (kc *Keychain) Match(owners *OutputOwners) ([]uint32, []*crypto.PrivateKeyED25519, bool) {
+	sigs := []uint32{}
+	keys := []*crypto.PrivateKeyED25519{}
+	for i := uint32(0); i < uint32(len(owners.Addrs)) && uint32(len(keys)) < owners.Threshold; i++ {
+		if key, exists := kc.Get(owners.Addrs[i]); exists {
+			sigs = append(sigs, i)
+			keys = append(keys, key)
+		}
+	}
+	return sigs, keys, uint32(len(keys)) == owners.Threshold
+}
+
+// Sign [unsignedBytes] with [keys], in order, and return the credential that
+// contains the signatures
+func (kc *Keychain) Sign(unsignedBytes []byte, keys []*crypto.PrivateKeyED25519) (*Credential, error) {
+	hash := hashing.ComputeHash256(unsignedBytes)
+	cred := &Credential{Sigs: make([]Signature, len(keys))}
+	for i, key := range keys {
+		sig, err := key.SignHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		copy(cred.Sigs[i].PublicKey[:], key.PublicKey().Bytes())
+		copy(cred.Sigs[i].Sig[:], sig)
+	}
+	return cred, nil
+}
+
+// PrefixedString returns the key chain as a string representation with [prefix]
+// added before every line.
+func (kc *Keychain) PrefixedString(prefix string) string {
+	s := strings.Builder{}
+
+	format := fmt.Sprintf("%%sKey[%s]: Key: %%s Address: %%s\n",
+		formatting.IntFormat(len(kc.Keys)-1))
+	for i, key := range kc.Keys {
+		s.WriteString(fmt.Sprintf(format,
+			prefix,
+			i,
+			formatting.CB58{Bytes: key.Bytes()},
+			key.PublicKey().Address()))
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func (kc *Keychain) String() string { return kc.PrefixedString("") }
@@ -0,0 +1,9 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+// MintInput ...
+type MintInput struct {
+	Input `serialize:"true"`
+}
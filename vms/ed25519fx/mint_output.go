@@ -0,0 +1,19 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+// MintOutput ...
+type MintOutput struct {
+	OutputOwners `serialize:"true"`
+}
+
+// Verify ...
+func (out *MintOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	default:
+		return out.OutputOwners.Verify()
+	}
+}
@@ -0,0 +1,33 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+import (
+	"errors"
+)
+
+var (
+	errNoValueInput = errors.New("input has no value")
+)
+
+// TransferInput ...
+type TransferInput struct {
+	Amt   uint64 `serialize:"true"`
+	Input `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this input produces
+func (in *TransferInput) Amount() uint64 { return in.Amt }
+
+// Verify this input is syntactically valid
+func (in *TransferInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	case in.Amt == 0:
+		return errNoValueInput
+	default:
+		return in.Input.Verify()
+	}
+}
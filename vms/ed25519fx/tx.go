@@ -0,0 +1,9 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ed25519fx
+
+// Tx that this Fx is supporting
+type Tx interface {
+	UnsignedBytes() []byte
+}
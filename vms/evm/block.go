@@ -59,6 +59,9 @@ func (b *Block) Parent() snowman.Block {
 
 // Verify implements the snowman.Block interface
 func (b *Block) Verify() error {
+	if err := b.vm.feeSchedule.VerifyBlock(b.ethBlock); err != nil {
+		return err
+	}
 	_, err := b.vm.chain.InsertChain([]*types.Block{b.ethBlock})
 	return err
 }
@@ -0,0 +1,110 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/go-ethereum/core/types"
+)
+
+var (
+	errNoInitialFeeConfig   = errors.New("the first fee config must be active from height 0")
+	errFeeConfigsNotSorted  = errors.New("fee configs must be sorted by strictly increasing height")
+	errInvalidMinGasPrice   = errors.New("minimum gas price must be non-negative")
+	errInvalidBlockGasLimit = errors.New("block gas limit must be positive")
+)
+
+// FeeConfig is the fee parameters of the chain from block [Height] onward
+type FeeConfig struct {
+	Height        uint64   `json:"height"`
+	MinGasPrice   *big.Int `json:"minGasPrice"`
+	BlockGasLimit uint64   `json:"blockGasLimit"`
+}
+
+// Verify that the fee config is well-formed
+func (c *FeeConfig) Verify() error {
+	switch {
+	case c.MinGasPrice == nil || c.MinGasPrice.Sign() < 0:
+		return errInvalidMinGasPrice
+	case c.BlockGasLimit == 0:
+		return errInvalidBlockGasLimit
+	default:
+		return nil
+	}
+}
+
+// chainConfig is the configuration of this VM that is read from the genesis
+// data, in addition to the fields of core.Genesis
+type chainConfig struct {
+	// The fee parameters of the chain, sorted by the height they activate at.
+	// If empty, the fees aren't restricted beyond the defaults of the node.
+	FeeConfigs feeSchedule `json:"feeConfigs"`
+}
+
+// feeSchedule is a list of fee configs sorted by activation height
+type feeSchedule []FeeConfig
+
+// Verify that the schedule is well-formed
+func (s feeSchedule) Verify() error {
+	for i, config := range s {
+		if err := config.Verify(); err != nil {
+			return fmt.Errorf("fee config at height %d is invalid: %w", config.Height, err)
+		}
+		switch {
+		case i == 0 && config.Height != 0:
+			return errNoInitialFeeConfig
+		case i > 0 && config.Height <= s[i-1].Height:
+			return errFeeConfigsNotSorted
+		}
+	}
+	return nil
+}
+
+// At returns the fee config in effect at [height] and the next config to be
+// activated, if any. Returns false if the schedule is empty.
+func (s feeSchedule) At(height uint64) (*FeeConfig, *FeeConfig, bool) {
+	if len(s) == 0 {
+		return nil, nil, false
+	}
+	i := 0
+	for i+1 < len(s) && s[i+1].Height <= height {
+		i++
+	}
+	if i+1 < len(s) {
+		return &s[i], &s[i+1], true
+	}
+	return &s[i], nil, true
+}
+
+// MaxBlockGasLimit returns the largest block gas limit in the schedule
+func (s feeSchedule) MaxBlockGasLimit() uint64 {
+	max := uint64(0)
+	for _, config := range s {
+		if config.BlockGasLimit > max {
+			max = config.BlockGasLimit
+		}
+	}
+	return max
+}
+
+// VerifyBlock returns nil if [block] respects the fee config in effect at its
+// height
+func (s feeSchedule) VerifyBlock(block *types.Block) error {
+	config, _, ok := s.At(block.NumberU64())
+	if !ok {
+		return nil
+	}
+	if gasLimit := block.GasLimit(); gasLimit > config.BlockGasLimit {
+		return fmt.Errorf("block gas limit %d exceeds the limit of %d", gasLimit, config.BlockGasLimit)
+	}
+	for _, tx := range block.Transactions() {
+		if tx.GasPrice().Cmp(config.MinGasPrice) < 0 {
+			return fmt.Errorf("tx %s has gas price %s, below the minimum of %s", tx.Hash().Hex(), tx.GasPrice(), config.MinGasPrice)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestFeeScheduleAt(t *testing.T) {
+	chainCfg := chainConfig{}
+	err := json.Unmarshal([]byte(`{"feeConfigs":[{"height":0,"minGasPrice":1000000000,"blockGasLimit":8000000},{"height":100,"minGasPrice":25000000000,"blockGasLimit":10000000}]}`), &chainCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule := chainCfg.FeeConfigs
+	if err := schedule.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	config, next, ok := schedule.At(99)
+	switch {
+	case !ok:
+		t.Fatalf("Should have returned a fee config")
+	case config.Height != 0 || config.BlockGasLimit != 8000000:
+		t.Fatalf("Wrong fee config returned at height 99: %+v", config)
+	case next == nil || next.Height != 100:
+		t.Fatalf("Wrong next fee config returned at height 99: %+v", next)
+	}
+
+	config, next, ok = schedule.At(100)
+	switch {
+	case !ok:
+		t.Fatalf("Should have returned a fee config")
+	case config.MinGasPrice.Cmp(big.NewInt(25000000000)) != 0 || config.BlockGasLimit != 10000000:
+		t.Fatalf("Wrong fee config returned at height 100: %+v", config)
+	case next != nil:
+		t.Fatalf("Shouldn't have returned a next fee config at height 100")
+	}
+
+	if max := schedule.MaxBlockGasLimit(); max != 10000000 {
+		t.Fatalf("Max block gas limit should be %d but is %d", 10000000, max)
+	}
+
+	if _, _, ok := (feeSchedule{}).At(0); ok {
+		t.Fatalf("An empty schedule shouldn't return a fee config")
+	}
+}
+
+func TestFeeScheduleVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule feeSchedule
+		err      error
+	}{
+		{
+			name:     "empty",
+			schedule: nil,
+		},
+		{
+			name: "no initial config",
+			schedule: feeSchedule{
+				{Height: 1, MinGasPrice: big.NewInt(1), BlockGasLimit: 1},
+			},
+			err: errNoInitialFeeConfig,
+		},
+		{
+			name: "unsorted",
+			schedule: feeSchedule{
+				{Height: 0, MinGasPrice: big.NewInt(1), BlockGasLimit: 1},
+				{Height: 5, MinGasPrice: big.NewInt(1), BlockGasLimit: 1},
+				{Height: 5, MinGasPrice: big.NewInt(1), BlockGasLimit: 1},
+			},
+			err: errFeeConfigsNotSorted,
+		},
+		{
+			name: "no gas price",
+			schedule: feeSchedule{
+				{Height: 0, BlockGasLimit: 1},
+			},
+			err: errInvalidMinGasPrice,
+		},
+		{
+			name: "no gas limit",
+			schedule: feeSchedule{
+				{Height: 0, MinGasPrice: big.NewInt(1)},
+			},
+			err: errInvalidBlockGasLimit,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schedule.Verify()
+			if test.err == nil && err != nil {
+				t.Fatal(err)
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("Should have errored with %q but got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ava-labs/gecko/snow"
+)
+
+// maxRequestSize is the largest request body a read-only node inspects
+const maxRequestSize = 5 * 1024 * 1024
+
+// issuanceMethods are the RPC methods that a read-only node rejects
+var issuanceMethods = map[string]bool{
+	"eth_sendRawTransaction":          true,
+	"eth_sendTransaction":             true,
+	"personal_sendTransaction":        true,
+	"personal_signAndSendTransaction": true,
+}
+
+// readOnlyHandler rejects the RPC requests that issue transactions, and passes
+// the others to [handler]
+type readOnlyHandler struct{ handler http.Handler }
+
+func (h readOnlyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if issuesTx(body) {
+		http.Error(w, snow.ErrReadOnly.Error(), http.StatusForbidden)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	h.handler.ServeHTTP(w, r)
+}
+
+// issuesTx returns true if the RPC request, or any request of the batch,
+// [body] calls an issuance method
+func issuesTx(body []byte) bool {
+	type call struct {
+		Method string `json:"method"`
+	}
+
+	calls := []call(nil)
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &calls); err != nil {
+			return false // The request is rejected by the RPC server
+		}
+	} else {
+		c := call{}
+		if err := json.Unmarshal(trimmed, &c); err != nil {
+			return false // The request is rejected by the RPC server
+		}
+		calls = append(calls, c)
+	}
+
+	for _, c := range calls {
+		if issuanceMethods[c.Method] {
+			return true
+		}
+	}
+	return false
+}
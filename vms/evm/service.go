@@ -6,6 +6,7 @@ package evm
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -21,6 +22,10 @@ const (
 	version = "Athereum 1.0"
 )
 
+var (
+	errNoFeeSchedule = errors.New("this chain doesn't configure its fees")
+)
+
 // test constants
 const (
 	GenesisTestAddr = "0x751a0b96e1042bee789452ecb20253fba40dbe85"
@@ -73,6 +78,33 @@ func (api *SnowmanAPI) GetAcceptedFront(ctx context.Context) (*GetAcceptedFrontR
 	}, nil
 }
 
+// GetFeeConfigReply defines the reply that will be sent from the GetFeeConfig
+// API call
+type GetFeeConfigReply struct {
+	// Height of the next block
+	Height hexutil.Uint64 `json:"height"`
+	// Fee parameters in effect for the next block
+	MinGasPrice   *hexutil.Big   `json:"minGasPrice"`
+	BlockGasLimit hexutil.Uint64 `json:"blockGasLimit"`
+	// The next scheduled change of the fee parameters, if any
+	Next *FeeConfig `json:"next"`
+}
+
+// GetFeeConfig returns the fee parameters in effect for the next block
+func (api *SnowmanAPI) GetFeeConfig(ctx context.Context) (*GetFeeConfigReply, error) {
+	height := api.vm.getLastAccepted().ethBlock.NumberU64() + 1
+	config, next, ok := api.vm.feeSchedule.At(height)
+	if !ok {
+		return nil, errNoFeeSchedule
+	}
+	return &GetFeeConfigReply{
+		Height:        hexutil.Uint64(height),
+		MinGasPrice:   (*hexutil.Big)(config.MinGasPrice),
+		BlockGasLimit: hexutil.Uint64(config.BlockGasLimit),
+		Next:          next,
+	}, nil
+}
+
 // GetGenesisBalance returns the current funds in the genesis
 func (api *DebugAPI) GetGenesisBalance(ctx context.Context) (*hexutil.Big, error) {
 	lastAccepted := api.vm.getLastAccepted()
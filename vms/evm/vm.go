@@ -19,6 +19,7 @@ import (
 	"github.com/ava-labs/coreth/node"
 
 	"github.com/ava-labs/go-ethereum/common"
+	"github.com/ava-labs/go-ethereum/core/rawdb"
 	"github.com/ava-labs/go-ethereum/core/types"
 	"github.com/ava-labs/go-ethereum/rlp"
 	"github.com/ava-labs/go-ethereum/rpc"
@@ -94,6 +95,9 @@ type VM struct {
 
 	genlock      sync.Mutex
 	txSubmitChan <-chan struct{}
+
+	// The fee parameters of the chain by height. May be empty.
+	feeSchedule feeSchedule
 }
 
 /*
@@ -124,12 +128,34 @@ func (vm *VM) Initialize(
 
 	vm.chainID = g.Config.ChainID
 
+	chainCfg := chainConfig{}
+	if err := json.Unmarshal(b, &chainCfg); err != nil {
+		return err
+	}
+	if err := chainCfg.FeeConfigs.Verify(); err != nil {
+		return err
+	}
+	vm.feeSchedule = chainCfg.FeeConfigs
+
 	config := eth.DefaultConfig
 	config.ManualCanonical = true
 	config.Genesis = g
 	config.Miner.ManualMining = true
 	config.Miner.DisableUncle = true
-	if err := config.SetGCMode("archive"); err != nil {
+	if len(vm.feeSchedule) > 0 {
+		// Blocks are built towards the largest gas limit of the schedule, and
+		// capped at the limit in effect at their height when their header is
+		// created.
+		gasLimit := vm.feeSchedule.MaxBlockGasLimit()
+		config.Miner.GasFloor = gasLimit
+		config.Miner.GasCeil = gasLimit
+	}
+	// A pruned node only keeps the state tries of recent blocks
+	gcMode := "archive"
+	if ctx.StateMode == snow.PrunedState {
+		gcMode = "full"
+	}
+	if err := config.SetGCMode(gcMode); err != nil {
 		panic(err)
 	}
 	nodecfg := node.Config{NoUSB: true}
@@ -143,6 +169,10 @@ func (vm *VM) Initialize(
 			panic("cannot generate hid")
 		}
 		header.Extra = append(header.Extra, hid...)
+
+		if feeConfig, _, ok := vm.feeSchedule.At(header.Number.Uint64()); ok && header.GasLimit > feeConfig.BlockGasLimit {
+			header.GasLimit = feeConfig.BlockGasLimit
+		}
 	})
 	chain.SetOnSeal(func(block *types.Block) error {
 		if len(block.Transactions()) == 0 {
@@ -233,6 +263,7 @@ func (vm *VM) Initialize(
 		vm:       vm,
 	}
 	vm.ctx.Log.Info(fmt.Sprintf("lastAccepted = %s", vm.lastAccepted.ethBlock.Hash().Hex()))
+	vm.setMinGasPrice(lastAccepted.NumberU64()+1, false)
 
 	// TODO: shutdown this go routine
 	go vm.ctx.Log.RecoverAndPanic(func() {
@@ -322,6 +353,20 @@ func (vm *VM) LastAccepted() ids.ID {
 	return vm.lastAccepted.ID()
 }
 
+// GetBlockIDAtHeight implements the snowman.ChainVM interface
+func (vm *VM) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	// Only blocks up to the last accepted block are known to be accepted. The
+	// canonical chain past it follows the preferred blocks.
+	if height > vm.getLastAccepted().ethBlock.NumberU64() {
+		return ids.ID{}, errUnknownBlock
+	}
+	hash := rawdb.ReadCanonicalHash(vm.chaindb, height)
+	if hash == (common.Hash{}) {
+		return ids.ID{}, errUnknownBlock
+	}
+	return ids.NewID(hash), nil
+}
+
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers() map[string]*commonEng.HTTPHandler {
 	handler := vm.chain.NewRPCHandler()
@@ -331,6 +376,13 @@ func (vm *VM) CreateHandlers() map[string]*commonEng.HTTPHandler {
 	handler.RegisterName("web3", &Web3API{})
 	handler.RegisterName("debug", &DebugAPI{vm})
 
+	// A read-only node doesn't serve websockets, since the calls made over
+	// them can't be inspected
+	if vm.ctx.ReadOnly {
+		return map[string]*commonEng.HTTPHandler{
+			"/rpc": &commonEng.HTTPHandler{LockOptions: commonEng.NoLock, Handler: readOnlyHandler{handler: handler}},
+		}
+	}
 	return map[string]*commonEng.HTTPHandler{
 		"/rpc": &commonEng.HTTPHandler{LockOptions: commonEng.NoLock, Handler: handler},
 		"/ws":  &commonEng.HTTPHandler{LockOptions: commonEng.NoLock, Handler: handler.WebsocketHandler([]string{"*"})},
@@ -359,6 +411,7 @@ func (vm *VM) updateStatus(blockID ids.ID, status choices.Status) {
 
 	if status == choices.Accepted {
 		vm.lastAccepted = vm.getBlock(blockID)
+		vm.setMinGasPrice(vm.lastAccepted.ethBlock.NumberU64()+1, true)
 		// TODO: improve this naive implementation
 		if atomic.SwapUint32(&vm.writingMetadata, 1) == 0 {
 			go vm.ctx.Log.RecoverAndPanic(vm.writeBackMetadata)
@@ -367,6 +420,18 @@ func (vm *VM) updateStatus(blockID ids.ID, status choices.Status) {
 	vm.blockStatusCache.Put(blockID, status)
 }
 
+// setMinGasPrice sets the minimum gas price of the tx pool to the one in effect
+// at [height]. If [onActivation], the price is only set if a new fee config is
+// activated at [height].
+func (vm *VM) setMinGasPrice(height uint64, onActivation bool) {
+	feeConfig, _, ok := vm.feeSchedule.At(height)
+	if !ok || (onActivation && feeConfig.Height != height) {
+		return
+	}
+	vm.ctx.Log.Info("setting the minimum gas price to %s from height %d", feeConfig.MinGasPrice, height)
+	vm.chain.GetTxPool().SetGasPrice(feeConfig.MinGasPrice)
+}
+
 func (vm *VM) getCachedBlock(blockID ids.ID) *types.Block {
 	return vm.chain.GetBlockByHash(blockID.Key())
 }
@@ -0,0 +1,124 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package htlcfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errWrongVMType         = errors.New("wrong vm type")
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongInputType      = errors.New("wrong input type")
+	errWrongCredentialType = errors.New("wrong credential type")
+
+	errOperationNotSupported = errors.New("hashed timelock contracts don't support operations")
+
+	errWrongAmounts  = errors.New("input is consuming a different amount than expected")
+	errWrongPreimage = errors.New("preimage doesn't match the hash lock")
+	errExpired       = errors.New("contract has expired and can only be refunded")
+	errNotExpired    = errors.New("contract hasn't expired and can't be refunded")
+)
+
+// Fx is a feature extension for hashed timelock contracts, which allow funds
+// to be swapped atomically with funds on another chain
+type Fx struct {
+	vm VM
+
+	// Only used to verify signatures, so its types aren't registered
+	secpFx secp256k1fx.Fx
+}
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	vm, ok := vmIntf.(VM)
+	if !ok {
+		return errWrongVMType
+	}
+
+	c := vm.Codec()
+	c.RegisterType(&TransferOutput{})
+	c.RegisterType(&ClaimInput{})
+	c.RegisterType(&RefundInput{})
+	c.RegisterType(&Credential{})
+
+	fx.vm = vm
+	return fx.secpFx.InitializeVM(vm)
+}
+
+// VerifyOperation ...
+func (fx *Fx) VerifyOperation(interface{}, []interface{}, []interface{}, []interface{}, []interface{}) error {
+	return errOperationNotSupported
+}
+
+// VerifyTransfer ...
+func (fx *Fx) VerifyTransfer(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*TransferOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	switch in := inIntf.(type) {
+	case *ClaimInput:
+		return fx.verifyClaim(tx, utxo, in, cred)
+	case *RefundInput:
+		return fx.verifyRefund(tx, utxo, in, cred)
+	default:
+		return errWrongInputType
+	}
+}
+
+func (fx *Fx) verifyClaim(tx secp256k1fx.Tx, utxo *TransferOutput, in *ClaimInput, cred *Credential) error {
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+
+	clock := fx.vm.Clock()
+	switch {
+	case utxo.Amt != in.Amt:
+		return errWrongAmounts
+	case utxo.Expired(clock.Unix()):
+		return errExpired
+	case hashing.ComputeHash256Array(in.Preimage) != utxo.HashLock:
+		return errWrongPreimage
+	}
+
+	return fx.secpFx.VerifyCredentials(tx, &utxo.Claim, &in.Input, &cred.Credential)
+}
+
+func (fx *Fx) verifyRefund(tx secp256k1fx.Tx, utxo *TransferOutput, in *RefundInput, cred *Credential) error {
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+
+	clock := fx.vm.Clock()
+	switch {
+	case utxo.Amt != in.Amt:
+		return errWrongAmounts
+	case !utxo.Expired(clock.Unix()):
+		return errNotExpired
+	}
+
+	return fx.secpFx.VerifyCredentials(tx, &utxo.Refund, &in.Input, &cred.Credential)
+}
+
+// StartBatch defers the verification of signatures by this fx until the
+// matching call to VerifyBatch
+func (fx *Fx) StartBatch() { fx.secpFx.StartBatch() }
+
+// VerifyBatch ends the last started batch, and returns nil if every signature
+// added to it is valid
+func (fx *Fx) VerifyBatch() error { return fx.secpFx.VerifyBatch() }
@@ -0,0 +1,158 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package htlcfx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	txBytes  = []byte{0, 1, 2, 3, 4, 5}
+	sigBytes = [crypto.SECP256K1RSigLen]byte{
+		0x0e, 0x33, 0x4e, 0xbc, 0x67, 0xa7, 0x3f, 0xe8,
+		0x24, 0x33, 0xac, 0xa3, 0x47, 0x88, 0xa6, 0x3d,
+		0x58, 0xe5, 0x8e, 0xf0, 0x3a, 0xd5, 0x84, 0xf1,
+		0xbc, 0xa3, 0xb2, 0xd2, 0x5d, 0x51, 0xd6, 0x9b,
+		0x0f, 0x28, 0x5d, 0xcd, 0x3f, 0x71, 0x17, 0x0a,
+		0xf9, 0xbf, 0x2d, 0xb1, 0x10, 0x26, 0x5c, 0xe9,
+		0xdc, 0xc3, 0x9d, 0x7a, 0x01, 0x50, 0x9d, 0xe8,
+		0x35, 0xbd, 0xcb, 0x29, 0x3a, 0xd1, 0x49, 0x32,
+		0x00,
+	}
+	addrBytes = [hashing.AddrLen]byte{
+		0x01, 0x5c, 0xce, 0x6c, 0x55, 0xd6, 0xb5, 0x09,
+		0x84, 0x5c, 0x8c, 0x4e, 0x30, 0xbe, 0xd9, 0x8d,
+		0x39, 0x1a, 0xe7, 0xf0,
+	}
+	otherAddrBytes = [hashing.AddrLen]byte{1}
+	preimage       = []byte("secret")
+)
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct{ bytes []byte }
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+func newTestOutput(locktime uint64, claim, refund [hashing.AddrLen]byte) *TransferOutput {
+	return &TransferOutput{
+		Amt:      1,
+		Locktime: locktime,
+		HashLock: hashing.ComputeHash256Array(preimage),
+		Claim: secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.NewShortID(claim)},
+		},
+		Refund: secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.NewShortID(refund)},
+		},
+	}
+}
+
+func newTestCredential() *Credential {
+	return &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{sigBytes},
+	}}
+}
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyClaim(t *testing.T) {
+	vm := testVM{}
+	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
+	vm.clock.Set(date)
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	out := newTestOutput(uint64(date.Unix())+1, addrBytes, otherAddrBytes)
+	in := &ClaimInput{
+		Amt:      1,
+		Preimage: preimage,
+		Input:    secp256k1fx.Input{SigIndices: []uint32{0}},
+	}
+	cred := newTestCredential()
+
+	if err := fx.VerifyTransfer(tx, out, in, cred); err != nil {
+		t.Fatal(err)
+	}
+
+	in.Preimage = []byte("wrong secret")
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to the wrong preimage")
+	}
+	in.Preimage = preimage
+
+	vm.clock.Set(date.Add(time.Second))
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to an expired contract")
+	}
+	vm.clock.Set(date)
+
+	out = newTestOutput(uint64(date.Unix())+1, otherAddrBytes, addrBytes)
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to being signed by the refund address")
+	}
+}
+
+func TestFxVerifyRefund(t *testing.T) {
+	vm := testVM{}
+	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
+	vm.clock.Set(date)
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	out := newTestOutput(uint64(date.Unix())+1, otherAddrBytes, addrBytes)
+	in := &RefundInput{
+		Amt:   1,
+		Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+	}
+	cred := newTestCredential()
+
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to the contract not having expired")
+	}
+
+	vm.clock.Set(date.Add(time.Second))
+	if err := fx.VerifyTransfer(tx, out, in, cred); err != nil {
+		t.Fatal(err)
+	}
+
+	out = newTestOutput(uint64(date.Unix())+1, addrBytes, otherAddrBytes)
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to being signed by the claim address")
+	}
+}
+
+func TestTransferOutputAddresses(t *testing.T) {
+	out := newTestOutput(1, addrBytes, addrBytes)
+	if addrs := out.Addresses(); len(addrs) != 1 {
+		t.Fatalf("Should have returned 1 address, returned %d", len(addrs))
+	}
+	out = newTestOutput(1, addrBytes, otherAddrBytes)
+	if addrs := out.Addresses(); len(addrs) != 2 {
+		t.Fatalf("Should have returned 2 addresses, returned %d", len(addrs))
+	}
+}
@@ -0,0 +1,65 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package htlcfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+// MaxPreimageLen is the maximum length of a preimage
+const MaxPreimageLen = 256
+
+var (
+	errNilInput         = errors.New("nil input")
+	errNoValueInput     = errors.New("input has no value")
+	errPreimageTooLarge = errors.New("preimage is too large")
+)
+
+// ClaimInput claims a TransferOutput before it expires by revealing the
+// preimage of its hash lock
+type ClaimInput struct {
+	Amt               uint64 `serialize:"true"`
+	Preimage          []byte `serialize:"true"`
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this input produces
+func (in *ClaimInput) Amount() uint64 { return in.Amt }
+
+// Verify this input is syntactically valid
+func (in *ClaimInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	case in.Amt == 0:
+		return errNoValueInput
+	case len(in.Preimage) > MaxPreimageLen:
+		return errPreimageTooLarge
+	default:
+		return in.Input.Verify()
+	}
+}
+
+// RefundInput refunds a TransferOutput once it has expired
+type RefundInput struct {
+	Amt               uint64 `serialize:"true"`
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this input produces
+func (in *RefundInput) Amount() uint64 { return in.Amt }
+
+// Verify this input is syntactically valid
+func (in *RefundInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	case in.Amt == 0:
+		return errNoValueInput
+	default:
+		return in.Input.Verify()
+	}
+}
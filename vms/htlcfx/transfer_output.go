@@ -0,0 +1,70 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package htlcfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilOutput     = errors.New("nil output")
+	errNoValueOutput = errors.New("output has no value")
+	errNoLocktime    = errors.New("output has no expiry")
+)
+
+// TransferOutput is a hashed timelock contract. Before [Locktime], a unix
+// timestamp, it can be claimed by [Claim] by revealing the preimage of
+// [HashLock]. From [Locktime] on, it can only be refunded to [Refund].
+//
+// [HashLock] is a SHA-256 hash, so a contract can be paired with a contract
+// on a chain such as Bitcoin that locks funds with the same hash.
+type TransferOutput struct {
+	Amt      uint64                   `serialize:"true"`
+	Locktime uint64                   `serialize:"true"`
+	HashLock [hashing.HashLen]byte    `serialize:"true"`
+	Claim    secp256k1fx.OutputOwners `serialize:"true"`
+	Refund   secp256k1fx.OutputOwners `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this output consumes
+func (out *TransferOutput) Amount() uint64 { return out.Amt }
+
+// Expired returns true if this contract can only be refunded at [time]
+func (out *TransferOutput) Expired(time uint64) bool { return out.Locktime <= time }
+
+// Addresses returns the addresses that can claim or be refunded this output
+func (out *TransferOutput) Addresses() [][]byte {
+	addrs := ids.ShortSet{}
+	addrs.Add(out.Claim.Addrs...)
+	addrs.Add(out.Refund.Addrs...)
+
+	addrList := addrs.List()
+	ids.SortShortIDs(addrList)
+
+	addrBytes := make([][]byte, 0, len(addrList))
+	for _, addr := range addrList {
+		addrBytes = append(addrBytes, addr.Bytes())
+	}
+	return addrBytes
+}
+
+// Verify ...
+func (out *TransferOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	case out.Amt == 0:
+		return errNoValueOutput
+	case out.Locktime == 0:
+		return errNoLocktime
+	}
+	if err := out.Claim.Verify(); err != nil {
+		return err
+	}
+	return out.Refund.Verify()
+}
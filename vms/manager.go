@@ -19,6 +19,14 @@ type VMFactory interface {
 	New() interface{}
 }
 
+// A GenesisVerifier is a VMFactory that can check the genesis data of a chain
+// without creating the chain
+type GenesisVerifier interface {
+	// VerifyGenesis returns nil if [genesisBytes] is a valid genesis for a
+	// chain of the VM running the fxs [fxs]
+	VerifyGenesis(genesisBytes []byte, fxs []*common.Fx) error
+}
+
 // Manager is a VM manager.
 // It has the following functionality:
 //   1) Register a VM factory. To register a VM is to associate its ID with a
@@ -47,7 +47,7 @@ func (vm *VM) newAbortBlock(parentID ids.ID) *Abort {
 	// We serialize this block as a Block so that it can be deserialized into a
 	// Block
 	blk := Block(abort)
-	bytes, err := Codec.Marshal(&blk)
+	bytes, err := Codec.Marshal(codecVersion, &blk)
 	if err != nil {
 		return nil
 	}
@@ -120,7 +120,7 @@ func (a Account) Verify() error {
 
 // Bytes returns the byte representation of this account
 func (a Account) Bytes() []byte {
-	bytes, _ := Codec.Marshal(a)
+	bytes, _ := Codec.Marshal(codecVersion, a)
 	return bytes
 }
 
@@ -158,13 +158,13 @@ func TestMarshalAccount(t *testing.T) {
 		defaultBalance,
 	)
 
-	bytes, err := Codec.Marshal(account)
+	bytes, err := Codec.Marshal(codecVersion, account)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	accountUnmarshaled := &Account{}
-	err = Codec.Unmarshal(bytes, accountUnmarshaled)
+	_, err = Codec.Unmarshal(bytes, accountUnmarshaled)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -46,7 +46,7 @@ type addDefaultSubnetDelegatorTx struct {
 // initialize [tx]
 func (tx *addDefaultSubnetDelegatorTx) initialize(vm *VM) error {
 	tx.vm = vm
-	bytes, err := Codec.Marshal(tx) // byte representation of the signed transaction
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
 	tx.bytes = bytes
 	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
 	return err
@@ -82,7 +82,7 @@ func (tx *addDefaultSubnetDelegatorTx) SyntacticVerify() error {
 
 	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetDelegatorTx)
 	// Byte representation of the unsigned transaction
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
 		return err
 	}
@@ -186,6 +186,26 @@ func (tx *addDefaultSubnetDelegatorTx) InitiallyPrefersCommit() bool {
 	return tx.StartTime().After(tx.vm.clock.Time())
 }
 
+// SignAddDefaultSubnetDelegatorTx returns [unsignedTx] signed by [key], in the
+// format accepted by IssueTx
+func SignAddDefaultSubnetDelegatorTx(unsignedTx UnsignedAddDefaultSubnetDelegatorTx, key *crypto.PrivateKeySECP256K1R) ([]byte, error) {
+	tx := addDefaultSubnetDelegatorTx{UnsignedAddDefaultSubnetDelegatorTx: unsignedTx}
+
+	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetDelegatorTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.Sign(unsignedBytes)
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return Codec.Marshal(codecVersion, genericTx{Tx: &tx})
+}
+
 func (vm *VM) newAddDefaultSubnetDelegatorTx(
 	nonce,
 	weight,
@@ -213,7 +233,7 @@ func (vm *VM) newAddDefaultSubnetDelegatorTx(
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetDelegatorTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr. of unsigned tx
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
 	if err != nil {
 		return nil, err
 	}
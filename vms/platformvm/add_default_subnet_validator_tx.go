@@ -53,7 +53,7 @@ type addDefaultSubnetValidatorTx struct {
 // initialize [tx]
 func (tx *addDefaultSubnetValidatorTx) initialize(vm *VM) error {
 	tx.vm = vm
-	bytes, err := Codec.Marshal(tx) // byte representation of the signed transaction
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
 	tx.bytes = bytes
 	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
 	return err
@@ -93,7 +93,7 @@ func (tx *addDefaultSubnetValidatorTx) SyntacticVerify() error {
 
 	// Byte representation of the unsigned transaction
 	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetValidatorTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
 		return err
 	}
@@ -152,7 +152,9 @@ func (tx *addDefaultSubnetValidatorTx) SemanticVerify(db database.Database) (*ve
 	}
 	currentValidators := validators.NewSet()
 	currentValidators.Set(tx.vm.getValidators(currentEvents))
-	if currentValidators.Contains(tx.NodeID) {
+	// A validator that rotated to a new node ID keeps its old ones until the
+	// end of its staking period
+	if _, err := currentEvents.getDefaultSubnetStaker(tx.NodeID); err == nil || currentValidators.Contains(tx.NodeID) {
 		return nil, nil, nil, nil, fmt.Errorf("validator with ID %s already in the current default validator set",
 			tx.NodeID)
 	}
@@ -217,7 +219,7 @@ func (vm *VM) newAddDefaultSubnetValidatorTx(nonce, stakeAmt, startTime, endTime
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetValidatorTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr. of unsigned tx
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +63,7 @@ type addNonDefaultSubnetValidatorTx struct {
 
 // initialize [tx]
 func (tx *addNonDefaultSubnetValidatorTx) initialize(vm *VM) error {
-	bytes, err := Codec.Marshal(tx) // byte representation of the signed transaction
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
 	if err != nil {
 		return err
 	}
@@ -107,7 +107,7 @@ func (tx *addNonDefaultSubnetValidatorTx) SyntacticVerify() error {
 
 	// Byte representation of the unsigned transaction
 	unsignedIntf := interface{}(&tx.UnsignedAddNonDefaultSubnetValidatorTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
 		return err
 	}
@@ -133,16 +133,20 @@ func (tx *addNonDefaultSubnetValidatorTx) SyntacticVerify() error {
 	return nil
 }
 
-// getDefaultSubnetStaker ...
+// getDefaultSubnetStaker returns the tx that added the validator of the
+// default subnet with node ID [id]. A rotated validator is matched by any of
+// the node IDs it had during its staking period.
 func (h *EventHeap) getDefaultSubnetStaker(id ids.ShortID) (*addDefaultSubnetValidatorTx, error) {
 	for _, txIntf := range h.Txs {
-		tx, ok := txIntf.(*addDefaultSubnetValidatorTx)
-		if !ok {
-			continue
-		}
-
-		if id.Equals(tx.NodeID) {
-			return tx, nil
+		switch tx := txIntf.(type) {
+		case *addDefaultSubnetValidatorTx:
+			if id.Equals(tx.NodeID) {
+				return tx, nil
+			}
+		case *rotatedValidatorTx:
+			if tx.hadNodeID(id) {
+				return &tx.Staker, nil
+			}
 		}
 	}
 	return nil, errors.New("couldn't find validator in the default subnet")
@@ -331,7 +335,7 @@ func (vm *VM) newAddNonDefaultSubnetValidatorTx(
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedAddNonDefaultSubnetValidatorTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr. of unsigned tx
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
 	if err != nil {
 		return nil, err
 	}
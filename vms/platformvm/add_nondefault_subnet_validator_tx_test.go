@@ -613,13 +613,13 @@ func TestAddNonDefaultSubnetValidatorMarshal(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	txBytes, err := Codec.Marshal(tx)
+	txBytes, err := Codec.Marshal(codecVersion, tx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	var unmarshaledTx addNonDefaultSubnetValidatorTx
-	if err := Codec.Unmarshal(txBytes, &unmarshaledTx); err != nil {
+	if _, err := Codec.Unmarshal(txBytes, &unmarshaledTx); err != nil {
 		t.Fatal(err)
 	}
 	if err := unmarshaledTx.initialize(vm); err != nil {
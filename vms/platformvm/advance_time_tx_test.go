@@ -223,13 +223,13 @@ func TestAdvanceTimeTxUnmarshal(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	bytes, err := Codec.Marshal(tx)
+	bytes, err := Codec.Marshal(codecVersion, tx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	var unmarshaledTx advanceTimeTx
-	err = Codec.Unmarshal(bytes, &unmarshaledTx)
+	_, err = Codec.Unmarshal(bytes, &unmarshaledTx)
 	if err != nil {
 		t.Fatal(err)
 	}
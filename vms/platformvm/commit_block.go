@@ -47,7 +47,7 @@ func (vm *VM) newCommitBlock(parentID ids.ID) *Commit {
 	// We serialize this block as a Block so that it can be deserialized into a
 	// Block
 	blk := Block(commit)
-	bytes, err := Codec.Marshal(&blk)
+	bytes, err := Codec.Marshal(codecVersion, &blk)
 	if err != nil {
 		return nil
 	}
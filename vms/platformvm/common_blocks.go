@@ -8,6 +8,7 @@ import (
 
 	"github.com/ava-labs/gecko/vms/components/missing"
 
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
@@ -182,6 +183,10 @@ type CommonDecisionBlock struct {
 
 	// to be executed if this block is accepted
 	onAcceptFunc func()
+
+	// changes to the shared memory made if this block is accepted, keyed by
+	// the chain they're made to
+	requests map[[32]byte]*atomic.Requests
 }
 
 // initialize this block
@@ -208,6 +213,24 @@ func (cdb *CommonDecisionBlock) onAccept() database.Database {
 	return cdb.onAcceptDB
 }
 
+// commit the vm's DB. If this block changes the shared memory, the changes are
+// made in the same write as the commit.
+func (cdb *CommonDecisionBlock) commit() error {
+	if len(cdb.requests) == 0 {
+		return cdb.vm.DB.Commit()
+	}
+	if cdb.vm.Ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+	defer cdb.vm.DB.Abort()
+
+	batch, err := cdb.vm.DB.CommitBatch()
+	if err != nil {
+		return err
+	}
+	return cdb.vm.Ctx.SharedMemory.Apply(cdb.requests, batch)
+}
+
 // Accept implements the snowman.Block interface
 func (cdb *CommonDecisionBlock) Accept() {
 	cdb.VM.Ctx.Log.Verbo("Accepting block with ID %s", cdb.ID())
@@ -218,8 +241,8 @@ func (cdb *CommonDecisionBlock) Accept() {
 	if err := cdb.onAcceptDB.Commit(); err != nil {
 		cdb.vm.Ctx.Log.Warn("unable to commit onAcceptDB")
 	}
-	if err := cdb.vm.DB.Commit(); err != nil {
-		cdb.vm.Ctx.Log.Warn("unable to commit vm's DB")
+	if err := cdb.commit(); err != nil {
+		cdb.vm.Ctx.Log.Warn("unable to commit vm's DB: %s", err)
 	}
 
 	for _, child := range cdb.children {
@@ -228,6 +251,9 @@ func (cdb *CommonDecisionBlock) Accept() {
 	if cdb.onAcceptFunc != nil {
 		cdb.onAcceptFunc()
 	}
+	if err := cdb.vm.summarizeState(cdb.ID()); err != nil {
+		cdb.vm.Ctx.Log.Warn("unable to summarize the chain state at block %s: %s", cdb.ID(), err)
+	}
 
 	parent := cdb.parentBlock()
 	// remove this block and its parent from memory
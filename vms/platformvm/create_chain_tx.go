@@ -17,6 +17,7 @@ import (
 var (
 	errInvalidVMID             = errors.New("invalid VM ID")
 	errFxIDsNotSortedAndUnique = errors.New("feature extensions IDs must be sorted and unique")
+	errUnknownChainSubnet      = errors.New("the chain's subnet doesn't exist")
 )
 
 // UnsignedCreateChainTx is an unsigned CreateChainTx
@@ -39,6 +40,21 @@ type UnsignedCreateChainTx struct {
 
 	// Byte representation of state of the new chain
 	GenesisData []byte `serialize:"true"`
+
+	// ID of the subnet that validates the new chain. Only serialized by
+	// subnetCodecVersion, so it's the default subnet in the bytes of earlier
+	// versions.
+	SubnetID ids.ID `serialize:"true" version:"1"`
+}
+
+// version returns the codec version [tx] is serialized with. Only the chains
+// of subnets other than the default subnet need the version that serializes
+// the subnet.
+func (tx *UnsignedCreateChainTx) version() uint16 {
+	if tx.SubnetID.IsZero() || tx.SubnetID.Equals(DefaultSubnetID) {
+		return codecVersion
+	}
+	return subnetCodecVersion
 }
 
 // CreateChainTx is a proposal to create a chain
@@ -55,7 +71,11 @@ type CreateChainTx struct {
 
 func (tx *CreateChainTx) initialize(vm *VM) error {
 	tx.vm = vm
-	txBytes, err := Codec.Marshal(tx) // byte repr. of the signed tx
+	if tx.SubnetID.IsZero() {
+		// The subnet isn't serialized by earlier versions
+		tx.SubnetID = DefaultSubnetID
+	}
+	txBytes, err := Codec.Marshal(tx.version(), tx) // byte repr. of the signed tx
 	tx.bytes = txBytes
 	tx.id = ids.NewID(hashing.ComputeHash256Array(txBytes))
 	return err
@@ -90,7 +110,7 @@ func (tx *CreateChainTx) SyntacticVerify() error {
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedCreateChainTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // byte repr of unsigned tx
+	unsignedBytes, err := Codec.Marshal(tx.version(), &unsignedIntf) // byte repr of unsigned tx
 	if err != nil {
 		return err
 	}
@@ -110,6 +130,12 @@ func (tx *CreateChainTx) SemanticVerify(db database.Database) (func(), error) {
 		return nil, err
 	}
 
+	if !tx.SubnetID.Equals(DefaultSubnetID) {
+		if _, err := tx.vm.getSubnet(db, tx.SubnetID); err != nil {
+			return nil, errUnknownChainSubnet
+		}
+	}
+
 	currentChains, err := tx.vm.getChains(db) // chains that currently exist
 	if err != nil {
 		return nil, errDBChains
@@ -141,6 +167,7 @@ func (tx *CreateChainTx) SemanticVerify(db database.Database) (func(), error) {
 	onAccept := func() {
 		chainParams := chains.ChainParameters{
 			ID:          tx.ID(),
+			SubnetID:    tx.SubnetID,
 			GenesisData: tx.GenesisData,
 			VMAlias:     tx.VMID.String(),
 		}
@@ -162,11 +189,23 @@ type createChainList []*CreateChainTx
 
 // Bytes returns the byte representation of a list of *CreateChainTx
 func (chains createChainList) Bytes() []byte {
-	bytes, _ := Codec.Marshal(chains)
+	bytes, _ := Codec.Marshal(chains.version(), chains)
 	return bytes
 }
 
-func (vm *VM) newCreateChainTx(nonce uint64, genesisData []byte, vmID ids.ID, fxIDs []ids.ID, chainName string, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*CreateChainTx, error) {
+// version returns the codec version [chains] are serialized with, which is the
+// latest version any of them needs
+func (chains createChainList) version() uint16 {
+	version := uint16(codecVersion)
+	for _, chain := range chains {
+		if chainVersion := chain.version(); chainVersion > version {
+			version = chainVersion
+		}
+	}
+	return version
+}
+
+func (vm *VM) newCreateChainTx(nonce uint64, genesisData []byte, vmID ids.ID, fxIDs []ids.ID, chainName string, subnetID ids.ID, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*CreateChainTx, error) {
 	tx := &CreateChainTx{
 		UnsignedCreateChainTx: UnsignedCreateChainTx{
 			NetworkID:   networkID,
@@ -175,11 +214,12 @@ func (vm *VM) newCreateChainTx(nonce uint64, genesisData []byte, vmID ids.ID, fx
 			VMID:        vmID,
 			FxIDs:       fxIDs,
 			ChainName:   chainName,
+			SubnetID:    subnetID,
 		},
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedCreateChainTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf) // Byte repr. of unsigned transaction
+	unsignedBytes, err := Codec.Marshal(tx.version(), &unsignedIntf) // Byte repr. of unsigned transaction
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@
 package platformvm
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/ava-labs/gecko/database/versiondb"
@@ -28,6 +29,7 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 		avm.ID,
 		nil,
 		"chain name",
+		DefaultSubnetID,
 		testNetworkID+1,
 		defaultKey,
 	)
@@ -47,6 +49,7 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 		avm.ID,
 		nil,
 		"chain name",
+		DefaultSubnetID,
 		testNetworkID,
 		defaultKey,
 	)
@@ -65,6 +68,7 @@ func TestCreateChainTxSyntacticVerify(t *testing.T) {
 		avm.ID,
 		nil,
 		"chain name",
+		DefaultSubnetID,
 		testNetworkID,
 		defaultKey,
 	)
@@ -87,6 +91,7 @@ func TestSemanticVerify(t *testing.T) {
 		avm.ID,
 		nil,
 		"chain name",
+		DefaultSubnetID,
 		testNetworkID,
 		defaultKey,
 	)
@@ -123,6 +128,7 @@ func TestSemanticVerifyAlreadyExisting(t *testing.T) {
 		avm.ID,
 		nil,
 		"chain name",
+		DefaultSubnetID,
 		testNetworkID,
 		defaultKey,
 	)
@@ -140,3 +146,99 @@ func TestSemanticVerifyAlreadyExisting(t *testing.T) {
 		t.Fatalf("should have failed because there is already a chain with ID %s", tx.id)
 	}
 }
+
+func TestCreateChainTxSubnet(t *testing.T) {
+	vm := defaultVM()
+
+	defaultTx, err := vm.newCreateChainTx(
+		defaultNonce+1,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		DefaultSubnetID,
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyBytes, err := Codec.Marshal(codecVersion, defaultTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(defaultTx.Bytes(), legacyBytes) {
+		t.Fatalf("Chains of the default subnet should be serialized as before")
+	}
+
+	tx, err := vm.newCreateChainTx(
+		defaultNonce+1,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		testSubnet1.ID,
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The subnet of the chain is kept when the chain is persisted
+	db := versiondb.New(vm.DB)
+	if _, err := tx.SemanticVerify(db); err != nil {
+		t.Fatal(err)
+	}
+	chains, err := vm.getChains(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, chain := range chains {
+		if chain.ID().Equals(tx.ID()) {
+			found = true
+			if !chain.SubnetID.Equals(testSubnet1.ID) {
+				t.Fatalf("Chain should be validated by subnet %s but is validated by %s", testSubnet1.ID, chain.SubnetID)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("Should have added the chain to the set of chains")
+	}
+
+	// and when it's sent in a block
+	blk, err := vm.newStandardBlock(vm.LastAccepted(), []DecisionTx{tx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := vm.ParseBlock(blk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsedTx := parsed.(*StandardBlock).Txs[0].(*CreateChainTx)
+	if !parsedTx.ID().Equals(tx.ID()) || !parsedTx.SubnetID.Equals(testSubnet1.ID) {
+		t.Fatalf("Parsed chain should be validated by subnet %s but is validated by %s", testSubnet1.ID, parsedTx.SubnetID)
+	}
+
+	// The subnet must exist
+	tx, err = vm.newCreateChainTx(
+		defaultNonce+1,
+		nil,
+		avm.ID,
+		nil,
+		"chain name",
+		ids.NewID([32]byte{1}),
+		testNetworkID,
+		defaultKey,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err != errUnknownChainSubnet {
+		t.Fatalf("Should have errored due to an unknown subnet but returned %v", err)
+	}
+}
@@ -78,7 +78,7 @@ func (tx *CreateSubnetTx) SyntacticVerify() error {
 
 	// Byte representation of the unsigned transaction
 	unsignedIntf := interface{}(&tx.UnsignedCreateSubnetTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
 		return err
 	}
@@ -137,7 +137,7 @@ func (tx *CreateSubnetTx) Bytes() []byte {
 		return tx.bytes
 	}
 	var err error
-	tx.bytes, err = Codec.Marshal(tx)
+	tx.bytes, err = Codec.Marshal(codecVersion, tx)
 	if err != nil {
 		tx.vm.Ctx.Log.Error("problem marshaling tx: %v", err)
 	}
@@ -147,7 +147,7 @@ func (tx *CreateSubnetTx) Bytes() []byte {
 // initialize sets [tx.vm] to [vm]
 func (tx *CreateSubnetTx) initialize(vm *VM) error {
 	tx.vm = vm
-	txBytes, err := Codec.Marshal(tx) // byte repr. of the signed tx
+	txBytes, err := Codec.Marshal(codecVersion, tx) // byte repr. of the signed tx
 	if err != nil {
 		return err
 	}
@@ -171,7 +171,7 @@ func (vm *VM) newCreateSubnetTx(networkID uint32, nonce uint64, controlKeys []id
 	}
 
 	unsignedIntf := interface{}(&tx.UnsignedCreateSubnetTx)
-	unsignedBytes, err := Codec.Marshal(&unsignedIntf)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +190,6 @@ type CreateSubnetTxList []*CreateSubnetTx
 
 // Bytes returns the binary representation of [lst]
 func (lst CreateSubnetTxList) Bytes() []byte {
-	bytes, _ := Codec.Marshal(lst)
+	bytes, _ := Codec.Marshal(codecVersion, lst)
 	return bytes
 }
@@ -50,8 +50,8 @@ func (h *EventHeap) Less(i, j int) bool {
 	case iTime.Unix() < jTime.Unix():
 		return true
 	case iTime == jTime:
-		_, iOk := iTx.(*addDefaultSubnetValidatorTx)
-		_, jOk := jTx.(*addDefaultSubnetValidatorTx)
+		iOk := isDefaultSubnetValidator(iTx)
+		jOk := isDefaultSubnetValidator(jTx)
 
 		if iOk != jOk {
 			return iOk == h.SortByStartTime
@@ -91,8 +91,19 @@ func (h *EventHeap) Pop() interface{} {
 	return val
 }
 
+// isDefaultSubnetValidator returns true if [tx] is a validator, rather than a
+// delegator, of the default subnet
+func isDefaultSubnetValidator(tx TimedTx) bool {
+	switch tx.(type) {
+	case *addDefaultSubnetValidatorTx, *rotatedValidatorTx:
+		return true
+	default:
+		return false
+	}
+}
+
 // Bytes returns the byte representation of this heap
 func (h *EventHeap) Bytes() []byte {
-	bytes, _ := Codec.Marshal(h)
+	bytes, _ := Codec.Marshal(codecVersion, h)
 	return bytes
 }
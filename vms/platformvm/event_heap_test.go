@@ -229,13 +229,13 @@ func TestTxHeapStopValidatorVsDelegatorOrdering(t *testing.T) {
 func TestMarshalAddValidatorTxHeap(t *testing.T) {
 	validators := GenesisCurrentValidators()
 
-	bytes, err := Codec.Marshal(validators)
+	bytes, err := Codec.Marshal(codecVersion, validators)
 	if err != nil {
 		t.Fatal("err")
 	}
 
 	stakersUnmarshaled := EventHeap{}
-	if err := Codec.Unmarshal(bytes, &stakersUnmarshaled); err != nil {
+	if _, err := Codec.Unmarshal(bytes, &stakersUnmarshaled); err != nil {
 		t.Fatal(err)
 	}
 
@@ -0,0 +1,186 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errExportToSelf     = errors.New("can't export to the Platform Chain")
+	errNoExportAmount   = errors.New("export amount must be positive")
+	errNoExportReceiver = errors.New("export must be to an address")
+)
+
+// UnsignedExportTx is an unsigned exportTx
+type UnsignedExportTx struct {
+	// NetworkID is the ID of the network this tx was issued on
+	NetworkID uint32 `serialize:"true"`
+
+	// Next unused nonce of the account the $AVA is exported from.
+	// The tx fee is paid from this account.
+	Nonce uint64 `serialize:"true"`
+
+	// ID of the chain the $AVA is exported to
+	DestinationChain ids.ID `serialize:"true"`
+
+	// Amount of $AVA exported, in nAVA
+	Amount uint64 `serialize:"true"`
+
+	// Address on the destination chain that can import the $AVA
+	To ids.ShortID `serialize:"true"`
+}
+
+// exportTx is a transaction that, if it is in an accepted block, removes $AVA
+// from the account of the key that signed it and puts it in the memory this
+// chain shares with the destination chain, as an atomic.Transfer to [To]. The
+// destination chain can then import it.
+type exportTx struct {
+	UnsignedExportTx `serialize:"true"`
+
+	// Signature on the byte repr. of UnsignedExportTx
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm       *VM
+	id       ids.ID
+	senderID ids.ShortID
+
+	// Byte representation of the signed transaction
+	bytes []byte
+}
+
+// initialize [tx]
+func (tx *exportTx) initialize(vm *VM) error {
+	tx.vm = vm
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
+	tx.bytes = bytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
+	return err
+}
+
+func (tx *exportTx) ID() ids.ID { return tx.id }
+
+// Bytes returns the byte representation of [tx]
+func (tx *exportTx) Bytes() []byte { return tx.bytes }
+
+// transfer returns the transfer [tx] exports
+func (tx *exportTx) transfer() *atomic.Transfer {
+	return &atomic.Transfer{
+		SourceTx: tx.id,
+		Amount:   tx.Amount,
+		To:       tx.To,
+	}
+}
+
+// SyntacticVerify that this transaction is well formed
+// If [tx] is valid, this method also populates [tx.senderID]
+func (tx *exportTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case !tx.senderID.IsZero():
+		return nil // Only verify the transaction once
+	case tx.id.IsZero():
+		return errInvalidID
+	case tx.NetworkID != tx.vm.Ctx.NetworkID:
+		return errWrongNetworkID
+	case tx.DestinationChain.IsZero():
+		return errInvalidID
+	case tx.DestinationChain.Equals(tx.vm.Ctx.ChainID):
+		return errExportToSelf
+	case tx.Amount == 0:
+		return errNoExportAmount
+	case tx.To.IsZero():
+		return errNoExportReceiver
+	}
+
+	// Byte representation of the unsigned transaction
+	unsignedIntf := interface{}(&tx.UnsignedExportTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
+	if err != nil {
+		return err
+	}
+
+	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:]) // the public key that signed [tx]
+	if err != nil {
+		return err
+	}
+	tx.senderID = key.Address()
+
+	return nil
+}
+
+// SemanticVerify returns nil if [tx] is valid given the state in [db]
+func (tx *exportTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	// Remove the exported $AVA and the tx fee from the sender's account
+	account, err := tx.vm.getAccount(db, tx.senderID)
+	if err != nil {
+		return nil, errDBAccount
+	}
+	account, err = account.Remove(tx.Amount, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+
+	// Record the export so the status of the transfer can be looked up
+	if err := tx.vm.putExportTx(db, tx); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// atomicRequests returns the request that puts the exported transfer in the
+// memory shared with the destination chain
+func (tx *exportTx) atomicRequests() (map[[32]byte]*atomic.Requests, error) {
+	transfer := tx.transfer()
+	transferBytes, err := transfer.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return map[[32]byte]*atomic.Requests{
+		tx.DestinationChain.Key(): {PutRequests: []*atomic.Element{&atomic.Element{
+			Key:   transfer.ID().Bytes(),
+			Value: transferBytes,
+		}}},
+	}, nil
+}
+
+func (vm *VM) newExportTx(nonce uint64, destinationChain ids.ID, amount uint64, to ids.ShortID, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*exportTx, error) {
+	tx := &exportTx{
+		UnsignedExportTx: UnsignedExportTx{
+			NetworkID:        networkID,
+			Nonce:            nonce,
+			DestinationChain: destinationChain,
+			Amount:           amount,
+			To:               to,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedExportTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.Sign(unsignedBytes) // Sign the transaction
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
@@ -0,0 +1,212 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/database/prefixdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/vms/avm"
+)
+
+// ID of the chain running the AVM in tests
+var avmChainID = ids.NewID([32]byte{'x'})
+
+// chainVMs maps chain IDs to the IDs of the VMs running them
+type chainVMs map[[32]byte]ids.ID
+
+func (vms chainVMs) ChainVM(chainID ids.ID) (ids.ID, error) {
+	if vmID, ok := vms[chainID.Key()]; ok {
+		return vmID, nil
+	}
+	return ids.ID{}, errors.New("unknown chain")
+}
+
+// atomicVM returns a VM whose database is built on the same database as [m]
+func atomicVM() (*VM, *atomic.Memory) {
+	db := memdb.New()
+	m := &atomic.Memory{}
+	m.Initialize(logging.NoLog{}, prefixdb.New([]byte("shared memory"), db))
+
+	ctx := defaultContext()
+	ctx.SharedMemory = m.NewSharedMemory(ctx.ChainID)
+	ctx.VMLookup = chainVMs{avmChainID.Key(): avm.ID}
+	return defaultVMWithDB(ctx, prefixdb.New([]byte("platform"), db)), m
+}
+
+// acceptAtomicTx builds a block of [tx], then verifies and accepts it
+func acceptAtomicTx(t *testing.T, vm *VM, tx atomicTx) {
+	vm.Ctx.Lock.Lock()
+	defer vm.Ctx.Lock.Unlock()
+
+	if err := vm.issueDecisionTx(tx.ID(), tx); err != nil {
+		t.Fatal(err)
+	}
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	blk.Accept()
+}
+
+func TestExportAVA(t *testing.T) {
+	vm, m := atomicVM()
+	service := Service{vm: vm}
+	to := ids.NewShortID([20]byte{1})
+
+	tx, err := vm.newExportTx(defaultNonce+1, avmChainID, 5, to, testNetworkID, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := GetExportStatusReply{}
+	if err := service.GetExportStatus(nil, &GetExportStatusArgs{TxID: tx.ID()}, &status); err == nil {
+		t.Fatal("should have failed to get the status of an export that wasn't issued")
+	}
+
+	acceptAtomicTx(t, vm, tx)
+
+	account, err := vm.getAccount(vm.DB, keys[0].PublicKey().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := defaultBalance - 5 - txFee; account.Balance != expected {
+		t.Fatalf("Balance should have been %d but was %d", expected, account.Balance)
+	}
+
+	avmMemory := m.NewSharedMemory(avmChainID)
+	transfer := tx.transfer()
+	values, err := avmMemory.Get(vm.Ctx.ChainID, [][]byte{transfer.ID().Bytes()})
+	if err != nil {
+		t.Fatalf("Exported transfer should be in shared memory: %s", err)
+	}
+	if parsed, err := atomic.ParseTransfer(transfer.ID(), values[0]); err != nil {
+		t.Fatal(err)
+	} else if parsed.Amount != 5 || !parsed.To.Equals(to) {
+		t.Fatalf("Exported %+v but should have exported %+v", parsed, transfer)
+	}
+
+	if err := service.GetExportStatus(nil, &GetExportStatusArgs{TxID: tx.ID()}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != transferExported || !status.TransferID.Equals(transfer.ID()) {
+		t.Fatalf("Status should have been %s of %s but was %s of %s", transferExported, transfer.ID(), status.Status, status.TransferID)
+	}
+
+	// The AVM imports the transfer
+	if err := avmMemory.Remove(vm.Ctx.ChainID, [][]byte{transfer.ID().Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.GetExportStatus(nil, &GetExportStatusArgs{TxID: tx.ID()}, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != transferImported {
+		t.Fatalf("Status should have been %s but was %s", transferImported, status.Status)
+	}
+
+	// Exports must be to a chain running the AVM
+	if err := service.ExportAVA(nil, &ExportAVAArgs{
+		DestinationChain: ids.NewID([32]byte{'c'}).String(),
+		To:               to,
+		Amount:           5,
+	}, &ExportAVAResponse{}); err == nil {
+		t.Fatal("should have failed to export to a chain that doesn't run the AVM")
+	}
+}
+
+func TestImportAVA(t *testing.T) {
+	vm, m := atomicVM()
+	service := Service{vm: vm}
+	avmMemory := m.NewSharedMemory(avmChainID)
+	addr := keys[1].PublicKey().Address()
+
+	transfer := &atomic.Transfer{
+		SourceTx: ids.NewID([32]byte{1}),
+		Amount:   7,
+		To:       addr,
+	}
+	transferBytes, err := transfer.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := avmMemory.Put(vm.Ctx.ChainID, []*atomic.Element{&atomic.Element{
+		Key:   transfer.ID().Bytes(),
+		Value: transferBytes,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := ImportAVAResponse{}
+	if err := service.ImportAVA(nil, &ImportAVAArgs{
+		SourceChain: avmChainID.String(),
+		TransferIDs: []ids.ID{transfer.ID()},
+	}, &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Amount != 7 {
+		t.Fatalf("Import should have imported 7 nAVA but imports %d", reply.Amount)
+	}
+
+	// Only the key the transfer is to can import it
+	stolen, err := vm.newImportTx(defaultNonce+1, avmChainID, []ids.ID{transfer.ID()}, testNetworkID, keys[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stolen.SemanticVerify(vm.DB); err != errTransferNotOwned {
+		t.Fatalf("Should have failed to import a transfer to another address but got %v", err)
+	}
+
+	tx, err := vm.newImportTx(defaultNonce+1, avmChainID, []ids.ID{transfer.ID()}, testNetworkID, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Ctx.Lock.Lock()
+	if err := vm.issueDecisionTx(tx.ID(), tx); err != nil {
+		t.Fatal(err)
+	}
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Ctx.Lock.Unlock()
+	if err := blk.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transfer is still in shared memory until the block is accepted, so
+	// a child of the block can't import it again
+	again, err := vm.newImportTx(defaultNonce+2, avmChainID, []ids.ID{transfer.ID()}, testNetworkID, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := vm.newStandardBlock(blk.ID(), []DecisionTx{again})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Verify(); err != errTransferImportedTwice {
+		t.Fatalf("Should have failed to import a transfer a processing block imports but got %v", err)
+	}
+
+	blk.Accept()
+
+	account, err := vm.getAccount(vm.DB, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := defaultBalance + 7 - txFee; account.Balance != expected {
+		t.Fatalf("Balance should have been %d but was %d", expected, account.Balance)
+	}
+	if exported, err := avmMemory.Exported(vm.Ctx.ChainID, [][]byte{transfer.ID().Bytes()}); err != nil {
+		t.Fatal(err)
+	} else if exported[0] {
+		t.Fatal("Imported transfer should have been removed from shared memory")
+	}
+}
@@ -6,7 +6,9 @@ package platformvm
 import (
 	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 )
 
 // ID of the platform VM
@@ -16,14 +18,22 @@ var (
 
 // Factory can create new instances of the Platform Chain
 type Factory struct {
-	ChainManager chains.Manager
-	Validators   validators.Manager
+	ChainManager  chains.Manager
+	Validators    validators.Manager
+	MempoolConfig mempool.Config
+
+	UptimeTracker         *uptime.Tracker
+	UptimeRewardThreshold float64
 }
 
 // New returns a new instance of the Platform Chain
 func (f *Factory) New() interface{} {
 	return &VM{
-		ChainManager: f.ChainManager,
-		Validators:   f.Validators,
+		ChainManager:  f.ChainManager,
+		Validators:    f.Validators,
+		MempoolConfig: f.MempoolConfig,
+
+		UptimeTracker:         f.UptimeTracker,
+		UptimeRewardThreshold: f.UptimeRewardThreshold,
 	}
 }
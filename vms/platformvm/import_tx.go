@@ -0,0 +1,208 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/chains/atomic"
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/math"
+)
+
+var (
+	errNoSharedMemory        = errors.New("chain has no shared memory to exchange $AVA with other chains")
+	errImportFromSelf        = errors.New("can't import from the Platform Chain")
+	errNoImportedTransfers   = errors.New("import must import at least one transfer")
+	errTransfersNotSorted    = errors.New("imported transfers must be sorted and unique")
+	errTransferNotOwned      = errors.New("imported transfer isn't to the key that signed the import")
+	errMissingTransfer       = errors.New("imported transfer isn't in shared memory")
+	errTransferImportedTwice = errors.New("transfer is imported by more than one tx")
+)
+
+// UnsignedImportTx is an unsigned importTx
+type UnsignedImportTx struct {
+	// NetworkID is the ID of the network this tx was issued on
+	NetworkID uint32 `serialize:"true"`
+
+	// Next unused nonce of the account the $AVA is imported to
+	Nonce uint64 `serialize:"true"`
+
+	// ID of the chain that exported the transfers
+	SourceChain ids.ID `serialize:"true"`
+
+	// IDs of the imported transfers
+	TransferIDs []ids.ID `serialize:"true"`
+}
+
+// importTx is a transaction that, if it is in an accepted block, removes
+// atomic.Transfers from the memory this chain shares with the source chain and
+// adds the $AVA they hold to the account of the key that signed it. Every
+// transfer must be to that key's address.
+type importTx struct {
+	UnsignedImportTx `serialize:"true"`
+
+	// Signature on the byte repr. of UnsignedImportTx
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm       *VM
+	id       ids.ID
+	senderID ids.ShortID
+
+	// Byte representation of the signed transaction
+	bytes []byte
+}
+
+// initialize [tx]
+func (tx *importTx) initialize(vm *VM) error {
+	tx.vm = vm
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
+	tx.bytes = bytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
+	return err
+}
+
+func (tx *importTx) ID() ids.ID { return tx.id }
+
+// Bytes returns the byte representation of [tx]
+func (tx *importTx) Bytes() []byte { return tx.bytes }
+
+// SyntacticVerify that this transaction is well formed
+// If [tx] is valid, this method also populates [tx.senderID]
+func (tx *importTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case !tx.senderID.IsZero():
+		return nil // Only verify the transaction once
+	case tx.id.IsZero():
+		return errInvalidID
+	case tx.NetworkID != tx.vm.Ctx.NetworkID:
+		return errWrongNetworkID
+	case tx.SourceChain.IsZero():
+		return errInvalidID
+	case tx.SourceChain.Equals(tx.vm.Ctx.ChainID):
+		return errImportFromSelf
+	case len(tx.TransferIDs) == 0:
+		return errNoImportedTransfers
+	case !ids.IsSortedAndUniqueIDs(tx.TransferIDs):
+		return errTransfersNotSorted
+	}
+
+	// Byte representation of the unsigned transaction
+	unsignedIntf := interface{}(&tx.UnsignedImportTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
+	if err != nil {
+		return err
+	}
+
+	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:]) // the public key that signed [tx]
+	if err != nil {
+		return err
+	}
+	tx.senderID = key.Address()
+
+	return nil
+}
+
+// transfers returns the transfers [tx] imports, as they are in shared memory
+func (tx *importTx) transfers() ([]*atomic.Transfer, error) {
+	if tx.vm.Ctx.SharedMemory == nil {
+		return nil, errNoSharedMemory
+	}
+	keys := make([][]byte, len(tx.TransferIDs))
+	for i, transferID := range tx.TransferIDs {
+		keys[i] = transferID.Bytes()
+	}
+	values, err := tx.vm.Ctx.SharedMemory.Get(tx.SourceChain, keys)
+	if err != nil {
+		return nil, errMissingTransfer
+	}
+	transfers := make([]*atomic.Transfer, len(values))
+	for i, value := range values {
+		if transfers[i], err = atomic.ParseTransfer(tx.TransferIDs[i], value); err != nil {
+			return nil, err
+		}
+	}
+	return transfers, nil
+}
+
+// SemanticVerify returns nil if [tx] is valid given the state in [db]
+func (tx *importTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	transfers, err := tx.transfers()
+	if err != nil {
+		return nil, err
+	}
+	amount := uint64(0)
+	for _, transfer := range transfers {
+		if !transfer.To.Equals(tx.senderID) {
+			return nil, errTransferNotOwned
+		}
+		if amount, err = math.Add64(amount, transfer.Amount); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pay the tx fee and add the imported $AVA to the sender's account
+	account, err := tx.vm.getAccount(db, tx.senderID)
+	if err != nil {
+		return nil, errDBAccount
+	}
+	if account, err = account.Remove(0, tx.Nonce); err != nil {
+		return nil, err
+	}
+	if account, err = account.Add(amount); err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// atomicRequests returns the request that removes the imported transfers from
+// the memory shared with the source chain
+func (tx *importTx) atomicRequests() (map[[32]byte]*atomic.Requests, error) {
+	keys := make([][]byte, len(tx.TransferIDs))
+	for i, transferID := range tx.TransferIDs {
+		keys[i] = transferID.Bytes()
+	}
+	return map[[32]byte]*atomic.Requests{
+		tx.SourceChain.Key(): {RemoveRequests: keys},
+	}, nil
+}
+
+func (vm *VM) newImportTx(nonce uint64, sourceChain ids.ID, transferIDs []ids.ID, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*importTx, error) {
+	transferIDs = append([]ids.ID(nil), transferIDs...)
+	ids.SortIDs(transferIDs)
+	tx := &importTx{
+		UnsignedImportTx: UnsignedImportTx{
+			NetworkID:   networkID,
+			Nonce:       nonce,
+			SourceChain: sourceChain,
+			TransferIDs: transferIDs,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedImportTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.Sign(unsignedBytes) // Sign the transaction
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/vms/components/core"
 )
@@ -101,6 +102,24 @@ func (pb *ProposalBlock) Verify() error {
 		return err
 	}
 
+	// Report the decision of a tx issued with IssueTx
+	if tx, ok := pb.Tx.(TimedTx); ok {
+		txID := tx.ID()
+		onCommitFunc, onAbortFunc := pb.onCommitFunc, pb.onAbortFunc
+		pb.onCommitFunc = func() {
+			if onCommitFunc != nil {
+				onCommitFunc()
+			}
+			pb.vm.decided(txID, choices.Accepted)
+		}
+		pb.onAbortFunc = func() {
+			if onAbortFunc != nil {
+				onAbortFunc()
+			}
+			pb.vm.decided(txID, choices.Rejected)
+		}
+	}
+
 	pb.vm.currentBlocks[pb.ID().Key()] = pb
 	parent.addChild(pb)
 	return nil
@@ -142,7 +161,7 @@ func (vm *VM) newProposalBlock(parentID ids.ID, tx ProposalTx) (*ProposalBlock,
 	// We marshal the block in this way (as a Block) so that we can unmarshal
 	// it into a Block (rather than a *ProposalBlock)
 	block := Block(pb)
-	bytes, err := Codec.Marshal(&block)
+	bytes, err := Codec.Marshal(codecVersion, &block)
 	if err != nil {
 		return nil, err
 	}
@@ -21,5 +21,14 @@ func reward(duration time.Duration, amount uint64, inflationRate float64) uint64
 	// Amount of the reward in $AVA
 	reward := value - float64(amount)
 
-	return uint64(reward)
+	// Converting a float that doesn't fit in a uint64 is implementation
+	// defined, so clamp the reward rather than letting it wrap
+	switch {
+	case !(reward > 0):
+		return 0
+	case reward >= math.MaxUint64:
+		return math.MaxUint64
+	default:
+		return uint64(reward)
+	}
 }
@@ -0,0 +1,91 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/math"
+)
+
+// rewardPayout is the outcome, for an account, of the end of a staking period
+// on the default subnet. The payouts of an account are indexed when the
+// staker is removed, so they can be queried after the staker is gone.
+type rewardPayout struct {
+	// ID of the tx that added the staker
+	TxID ids.ID `serialize:"true"`
+
+	// Node ID the stake was validating, or delegated to, at the end of the
+	// staking period
+	NodeID ids.ShortID `serialize:"true"`
+
+	// True if the staker was a delegator
+	Delegator bool `serialize:"true"`
+
+	// True if the account is the validator's, and [Reward] is its share of a
+	// delegator's reward. Such a payout doesn't return the stake.
+	DelegationFee bool `serialize:"true"`
+
+	// Amount of $AVA staked, and of the reward paid to the account
+	StakeAmount uint64 `serialize:"true"`
+	Reward      uint64 `serialize:"true"`
+
+	// Unix time the staking period ended
+	EndTime uint64 `serialize:"true"`
+}
+
+// rewardPayoutList is a list of payouts that can be stored in the state
+type rewardPayoutList []rewardPayout
+
+// Bytes returns the byte representation of the payouts
+func (payouts rewardPayoutList) Bytes() []byte {
+	bytes, _ := Codec.Marshal(codecVersion, []rewardPayout(payouts))
+	return bytes
+}
+
+// delegationRewards returns how the reward of [delegator], if it's rewarded,
+// is split between it and [validator], which it delegated to
+func delegationRewards(validator *addDefaultSubnetValidatorTx, delegator *addDefaultSubnetDelegatorTx) (uint64, uint64, error) {
+	reward := reward(delegator.Duration(), delegator.Wght, InflationRate)
+
+	// Because validator.Shares <= NumberOfShares this will never underflow
+	delegatorShares := NumberOfShares - uint64(validator.Shares)
+	// The product is computed with 128 bits, and because
+	// delegatorShares <= NumberOfShares the quotient will never overflow
+	delegatorReward, err := math.MulDiv64(delegatorShares, reward, NumberOfShares)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Because delegatorReward <= reward this will never underflow
+	return delegatorReward, reward - delegatorReward, nil
+}
+
+// get the reward payouts of the account with address [address], oldest first
+func (vm *VM) getRewardPayouts(db database.Database, address ids.ShortID) ([]rewardPayout, error) {
+	key := address.LongID()
+	has, err := vm.State.Has(db, rewardsTypeID, key)
+	if err != nil || !has {
+		return nil, err
+	}
+	payoutsIntf, err := vm.State.Get(db, rewardsTypeID, key)
+	if err != nil {
+		return nil, err
+	}
+	payouts, ok := payoutsIntf.([]rewardPayout)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve []rewardPayout from database but got different type")
+		return nil, errDB
+	}
+	return payouts, nil
+}
+
+// add [payout] to the reward payouts of the account with address [address]
+func (vm *VM) putRewardPayout(db database.Database, address ids.ShortID, payout rewardPayout) error {
+	payouts, err := vm.getRewardPayouts(db, address)
+	if err != nil {
+		return err
+	}
+	return vm.State.Put(db, rewardsTypeID, address.LongID(), rewardPayoutList(append(payouts, payout)))
+}
@@ -33,6 +33,9 @@ type rewardValidatorTx struct {
 	TxID ids.ID `serialize:"true"`
 
 	vm *VM
+
+	// The staker being removed. Set in SemanticVerify.
+	staker TimedTx
 }
 
 func (tx *rewardValidatorTx) initialize(vm *VM) error {
@@ -94,6 +97,7 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 	}
 
 	heap.Pop(currentEvents) // Remove validator from the validator set
+	tx.staker = vdrTx
 
 	onCommitDB := versiondb.New(db)
 	// If this tx's proposal is committed, remove the validator from the validator set and update the
@@ -109,7 +113,13 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		return nil, nil, nil, nil, errDBPutCurrentValidators
 	}
 
-	switch vdrTx := vdrTx.(type) {
+	staker := vdrTx
+	if rotated, ok := vdrTx.(*rotatedValidatorTx); ok {
+		// A rotated validator is rewarded as the validator it was added as
+		staker = &rotated.Staker
+	}
+
+	switch vdrTx := staker.(type) {
 	case *addDefaultSubnetValidatorTx:
 		duration := vdrTx.Duration()
 		amount := vdrTx.Wght
@@ -149,28 +159,33 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		if err := tx.vm.putAccount(onAbortDB, accountNoReward); err != nil {
 			return nil, nil, nil, nil, errDBPutAccount
 		}
+
+		payout := rewardPayout{
+			TxID:        vdrTx.ID(),
+			NodeID:      tx.staker.Vdr().ID(), // A rotated validator's current node ID
+			StakeAmount: amount,
+			Reward:      reward,
+			EndTime:     vdrTx.End,
+		}
+		if err := tx.vm.putRewardPayout(onCommitDB, accountID, payout); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		payout.Reward = 0
+		if err := tx.vm.putRewardPayout(onAbortDB, accountID, payout); err != nil {
+			return nil, nil, nil, nil, err
+		}
 	case *addDefaultSubnetDelegatorTx:
 		parentTx, err := currentEvents.getDefaultSubnetStaker(vdrTx.NodeID)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
 
-		duration := vdrTx.Duration()
 		amount := vdrTx.Wght
-		reward := reward(duration, amount, InflationRate)
-
-		// Because parentTx.Shares <= NumberOfShares this will never underflow
-		delegatorShares := NumberOfShares - uint64(parentTx.Shares)
-		// Because delegatorShares <= NumberOfShares this will never overflow
-		delegatorReward := delegatorShares * (reward / NumberOfShares)
-		// Delay rounding as long as possible for small numbers
-		if optimisticReward, err := math.Mul64(delegatorShares, reward); err == nil {
-			delegatorReward = optimisticReward / NumberOfShares
+		delegatorReward, validatorReward, err := delegationRewards(parentTx, vdrTx)
+		if err != nil {
+			return nil, nil, nil, nil, err
 		}
 
-		// Because delegatorReward <= reward this will never underflow
-		validatorReward := reward - delegatorReward
-
 		delegatorAmountWithReward, err := math.Add64(amount, delegatorReward)
 		if err != nil {
 			delegatorAmountWithReward = amount
@@ -227,6 +242,27 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 		if err := tx.vm.putAccount(onCommitDB, validatorAccountWithReward); err != nil {
 			return nil, nil, nil, nil, errDBPutAccount
 		}
+
+		payout := rewardPayout{
+			TxID:        vdrTx.ID(),
+			NodeID:      vdrTx.NodeID,
+			Delegator:   true,
+			StakeAmount: amount,
+			Reward:      delegatorReward,
+			EndTime:     vdrTx.End,
+		}
+		if err := tx.vm.putRewardPayout(onCommitDB, delegatorAccountID, payout); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		payout.Reward = 0
+		if err := tx.vm.putRewardPayout(onAbortDB, delegatorAccountID, payout); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		payout.DelegationFee = true
+		payout.Reward = validatorReward
+		if err := tx.vm.putRewardPayout(onCommitDB, validatorAccountID, payout); err != nil {
+			return nil, nil, nil, nil, err
+		}
 	default:
 		return nil, nil, nil, nil, errShouldBeDSValidator
 	}
@@ -243,14 +279,17 @@ func (tx *rewardValidatorTx) SemanticVerify(db database.Database) (*versiondb.Da
 	return onCommitDB, onAbortDB, updateValidators, updateValidators, nil
 }
 
-// InitiallyPrefersCommit returns true.
+// InitiallyPrefersCommit returns true if the staker should be rewarded.
 //
-// Right now, *Commit (that is, remove the validator and reward them) is always
-// preferred over *Abort (remove the validator but don't reward them.)
+// *Commit (that is, remove the validator and reward them) is preferred over
+// *Abort (remove the validator but don't reward them) unless uptime based
+// rewards are enabled and this node measured the validator's uptime during the
+// staking period to be insufficient.
 //
-// TODO: A validator should receive a reward only if they are sufficiently
-// responsive and correct during the time they are validating.
-func (tx *rewardValidatorTx) InitiallyPrefersCommit() bool { return true }
+// TODO: A validator should also be correct during the time they are validating.
+func (tx *rewardValidatorTx) InitiallyPrefersCommit() bool {
+	return tx.vm.eligibleForReward(tx.staker)
+}
 
 // RewardStakerTx creates a new transaction that proposes to remove the staker
 // [validatorID] from the default validator set.
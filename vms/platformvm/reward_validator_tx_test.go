@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/utils/crypto"
 )
 
@@ -231,3 +232,41 @@ func TestRewardDelegatorTxSemanticVerify(t *testing.T) {
 		t.Fatalf("expected account balance to be %d was %d", expectedBalance, account.Balance)
 	}
 }
+
+func TestRewardValidatorTxUptime(t *testing.T) {
+	vm := defaultVM()
+	vm.UptimeTracker = uptime.NewTracker()
+
+	currentValidators, err := vm.getCurrentValidators(vm.DB, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextToRemove := currentValidators.Peek().(*addDefaultSubnetValidatorTx)
+
+	if err := vm.putTimestamp(vm.DB, defaultValidateEndTime); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := vm.newRewardValidatorTx(nextToRemove.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, _, err := tx.SemanticVerify(vm.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tx.InitiallyPrefersCommit() {
+		t.Fatalf("Should prefer to reward the validator when uptime rewards are disabled")
+	}
+
+	// The validator was never connected to this node
+	vm.UptimeRewardThreshold = .5
+	if tx.InitiallyPrefersCommit() {
+		t.Fatalf("Shouldn't prefer to reward a validator with insufficient uptime")
+	}
+
+	// This node doesn't measure its own uptime
+	vm.Ctx.NodeID = nextToRemove.NodeID
+	if !tx.InitiallyPrefersCommit() {
+		t.Fatalf("Should prefer to reward this node")
+	}
+}
@@ -0,0 +1,294 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/validators"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+)
+
+var (
+	errUnknownValidator     = errors.New("no validator of the default subnet was added by the specified tx")
+	errUnauthorizedRotation = errors.New("a validator can only be rotated by the key that added it")
+	errSameNodeID           = errors.New("validator already has the specified node ID")
+	errRotatedValidatorTx   = errors.New("a rotated validator can't be issued")
+)
+
+// UnsignedRotateValidatorTx is an unsigned rotateValidatorTx
+type UnsignedRotateValidatorTx struct {
+	// NetworkID is the ID of the network this tx was issued on
+	NetworkID uint32 `serialize:"true"`
+
+	// Next unused nonce of the account that added the validator.
+	// The tx fee is paid from this account.
+	Nonce uint64 `serialize:"true"`
+
+	// ID of the tx that added the validator to the default subnet
+	TxID ids.ID `serialize:"true"`
+
+	// ID of the node the validator is rotating to
+	NodeID ids.ShortID `serialize:"true"`
+}
+
+// rotateValidatorTx is a transaction that, if it is in an accepted block,
+// changes the node ID of a validator of the default subnet for the rest of its
+// staking period. The validator keeps its stake, its delegators and its end
+// time. This allows a validator whose staking key was lost or compromised to
+// continue validating from a new node.
+//
+// The tx must be signed by the key that signed the tx that added the
+// validator. Only the validator's membership of the default subnet is rotated.
+type rotateValidatorTx struct {
+	UnsignedRotateValidatorTx `serialize:"true"`
+
+	// Signature on the byte repr. of UnsignedRotateValidatorTx
+	Sig [crypto.SECP256K1RSigLen]byte `serialize:"true"`
+
+	vm       *VM
+	id       ids.ID
+	senderID ids.ShortID
+
+	// Byte representation of the signed transaction
+	bytes []byte
+}
+
+// initialize [tx]
+func (tx *rotateValidatorTx) initialize(vm *VM) error {
+	tx.vm = vm
+	bytes, err := Codec.Marshal(codecVersion, tx) // byte representation of the signed transaction
+	tx.bytes = bytes
+	tx.id = ids.NewID(hashing.ComputeHash256Array(bytes))
+	return err
+}
+
+func (tx *rotateValidatorTx) ID() ids.ID { return tx.id }
+
+// Bytes returns the byte representation of [tx]
+func (tx *rotateValidatorTx) Bytes() []byte { return tx.bytes }
+
+// SyntacticVerify that this transaction is well formed
+// If [tx] is valid, this method also populates [tx.senderID]
+func (tx *rotateValidatorTx) SyntacticVerify() error {
+	switch {
+	case tx == nil:
+		return errNilTx
+	case !tx.senderID.IsZero():
+		return nil // Only verify the transaction once
+	case tx.id.IsZero():
+		return errInvalidID
+	case tx.NetworkID != tx.vm.Ctx.NetworkID:
+		return errWrongNetworkID
+	case tx.TxID.IsZero():
+		return errInvalidID
+	case tx.NodeID.IsZero():
+		return errInvalidID
+	}
+
+	// Byte representation of the unsigned transaction
+	unsignedIntf := interface{}(&tx.UnsignedRotateValidatorTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
+	if err != nil {
+		return err
+	}
+
+	key, err := tx.vm.factory.RecoverPublicKey(unsignedBytes, tx.Sig[:]) // the public key that signed [tx]
+	if err != nil {
+		return err
+	}
+	tx.senderID = key.Address()
+
+	return nil
+}
+
+// SemanticVerify returns nil if [tx] is valid given the state in [db]
+func (tx *rotateValidatorTx) SemanticVerify(db database.Database) (func(), error) {
+	if err := tx.SyntacticVerify(); err != nil {
+		return nil, err
+	}
+
+	currentEvents, err := tx.vm.getCurrentValidators(db, DefaultSubnetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the validator being rotated
+	index := -1
+	for i, txIntf := range currentEvents.Txs {
+		if txIntf.ID().Equals(tx.TxID) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, errUnknownValidator
+	}
+	var rotated *rotatedValidatorTx
+	switch staker := currentEvents.Txs[index].(type) {
+	case *addDefaultSubnetValidatorTx:
+		rotated = &rotatedValidatorTx{Staker: *staker}
+	case *rotatedValidatorTx:
+		rotated = &rotatedValidatorTx{
+			Staker:         staker.Staker,
+			RotatedNodeIDs: append([]ids.ShortID(nil), staker.RotatedNodeIDs...),
+		}
+	default:
+		return nil, errUnknownValidator
+	}
+
+	// Ensure [tx] was signed by the key that added the validator
+	if err := rotated.Staker.SyntacticVerify(); err != nil {
+		return nil, fmt.Errorf("couldn't recover the key that added the validator: %w", err)
+	}
+	if !tx.senderID.Equals(rotated.Staker.senderID) {
+		return nil, errUnauthorizedRotation
+	}
+
+	if nodeID := rotated.NodeID(); nodeID.Equals(tx.NodeID) {
+		return nil, errSameNodeID
+	}
+
+	// Ensure the new node isn't, and wasn't during this staking period, a
+	// validator of the default subnet
+	currentValidators := validators.NewSet()
+	currentValidators.Set(tx.vm.getValidators(currentEvents))
+	if _, err := currentEvents.getDefaultSubnetStaker(tx.NodeID); err == nil || currentValidators.Contains(tx.NodeID) {
+		return nil, fmt.Errorf("validator with ID %s already in the current default validator set",
+			tx.NodeID)
+	}
+	pendingEvents, err := tx.vm.getPendingValidators(db, DefaultSubnetID)
+	if err != nil {
+		return nil, err
+	}
+	pendingValidators := validators.NewSet()
+	pendingValidators.Set(tx.vm.getValidators(pendingEvents))
+	if pendingValidators.Contains(tx.NodeID) {
+		return nil, fmt.Errorf("validator with ID %s already in the pending default validator set",
+			tx.NodeID)
+	}
+
+	// Deduct tx fee from the account that added the validator
+	account, err := tx.vm.getAccount(db, tx.senderID)
+	if err != nil {
+		return nil, errDBAccount
+	}
+	account, err = account.Remove(0, tx.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.vm.putAccount(db, account); err != nil {
+		return nil, err
+	}
+
+	// The rotated validator has the same ID and times as the one it replaces,
+	// so the order of the heap is unchanged
+	rotated.RotatedNodeIDs = append(rotated.RotatedNodeIDs, tx.NodeID)
+	currentEvents.Txs[index] = rotated
+	heap.Fix(currentEvents, index)
+	if err := tx.vm.putCurrentValidators(db, currentEvents, DefaultSubnetID); err != nil {
+		return nil, err
+	}
+
+	onAccept := func() {
+		if err := tx.vm.updateValidators(DefaultSubnetID); err != nil {
+			tx.vm.Ctx.Log.Fatal("failed to update validators on the default subnet: %s", err)
+		}
+	}
+	return onAccept, nil
+}
+
+func (vm *VM) newRotateValidatorTx(nonce uint64, txID ids.ID, nodeID ids.ShortID, networkID uint32, key *crypto.PrivateKeySECP256K1R) (*rotateValidatorTx, error) {
+	tx := &rotateValidatorTx{
+		UnsignedRotateValidatorTx: UnsignedRotateValidatorTx{
+			NetworkID: networkID,
+			Nonce:     nonce,
+			TxID:      txID,
+			NodeID:    nodeID,
+		},
+	}
+
+	unsignedIntf := interface{}(&tx.UnsignedRotateValidatorTx)
+	unsignedBytes, err := Codec.Marshal(codecVersion, &unsignedIntf) // byte repr. of unsigned tx
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := key.Sign(unsignedBytes) // Sign the transaction
+	if err != nil {
+		return nil, err
+	}
+	copy(tx.Sig[:], sig)
+
+	return tx, tx.initialize(vm)
+}
+
+// rotatedValidatorTx is a validator of the default subnet whose node ID was
+// changed by one or more rotateValidatorTxs. It replaces the validator in the
+// current validator set, and keeps the ID, stake and times of the tx that
+// added the validator.
+type rotatedValidatorTx struct {
+	// The tx that added the validator
+	Staker addDefaultSubnetValidatorTx `serialize:"true"`
+
+	// The node IDs the validator rotated to, oldest first
+	RotatedNodeIDs []ids.ShortID `serialize:"true"`
+}
+
+func (tx *rotatedValidatorTx) initialize(vm *VM) error { return tx.Staker.initialize(vm) }
+
+// ID returns the ID of the tx that added the validator
+func (tx *rotatedValidatorTx) ID() ids.ID { return tx.Staker.ID() }
+
+// StartTime returns the time the validator started validating
+func (tx *rotatedValidatorTx) StartTime() time.Time { return tx.Staker.StartTime() }
+
+// EndTime returns the time the validator stops validating
+func (tx *rotatedValidatorTx) EndTime() time.Time { return tx.Staker.EndTime() }
+
+// NodeID returns the node ID the validator currently validates with
+func (tx *rotatedValidatorTx) NodeID() ids.ShortID {
+	if numRotations := len(tx.RotatedNodeIDs); numRotations > 0 {
+		return tx.RotatedNodeIDs[numRotations-1]
+	}
+	return tx.Staker.NodeID
+}
+
+// Vdr returns the validator with its current node ID
+func (tx *rotatedValidatorTx) Vdr() validators.Validator {
+	return &Validator{
+		NodeID: tx.NodeID(),
+		Wght:   tx.Staker.Wght,
+	}
+}
+
+// hadNodeID returns true if the validator validated with [nodeID] at any
+// point during its staking period
+func (tx *rotatedValidatorTx) hadNodeID(nodeID ids.ShortID) bool {
+	if nodeID.Equals(tx.Staker.NodeID) {
+		return true
+	}
+	for _, rotatedID := range tx.RotatedNodeIDs {
+		if nodeID.Equals(rotatedID) {
+			return true
+		}
+	}
+	return false
+}
+
+// SemanticVerify returns an error, as a rotated validator is only ever created
+// by a rotateValidatorTx
+func (tx *rotatedValidatorTx) SemanticVerify(database.Database) (*versiondb.Database, *versiondb.Database, func(), func(), error) {
+	return nil, nil, nil, nil, errRotatedValidatorTx
+}
+
+// InitiallyPrefersCommit returns false
+func (tx *rotatedValidatorTx) InitiallyPrefersCommit() bool { return false }
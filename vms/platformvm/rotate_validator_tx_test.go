@@ -0,0 +1,136 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+)
+
+func TestRotateValidatorTxSemanticVerify(t *testing.T) {
+	vm := defaultVM()
+
+	oldNodeID := keys[0].PublicKey().Address()
+	newNodeID := ids.NewShortID([20]byte{1, 2, 3, 4, 5})
+
+	currentValidators, err := vm.getCurrentValidators(vm.DB, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	staker, err := currentValidators.getDefaultSubnetStaker(oldNodeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Case 1: Signed by a key other than the one that added the validator
+	tx, err := vm.newRotateValidatorTx(defaultNonce+1, staker.ID(), newNodeID, testNetworkID, keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err != errUnauthorizedRotation {
+		t.Fatalf("should have failed with %q but got %v", errUnauthorizedRotation, err)
+	}
+
+	// Case 2: Rotating to the node ID of another validator
+	tx, err = vm.newRotateValidatorTx(defaultNonce+1, staker.ID(), keys[1].PublicKey().Address(), testNetworkID, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err == nil {
+		t.Fatal("should have failed because the node is already a validator")
+	}
+
+	// Case 3: Unknown validator
+	tx, err = vm.newRotateValidatorTx(defaultNonce+1, ids.Empty.Prefix(1), newNodeID, testNetworkID, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(vm.DB)); err != errUnknownValidator {
+		t.Fatalf("should have failed with %q but got %v", errUnknownValidator, err)
+	}
+
+	// Case 4: Happy path
+	tx, err = vm.newRotateValidatorTx(defaultNonce+1, staker.ID(), newNodeID, testNetworkID, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := versiondb.New(vm.DB)
+	if _, err := tx.SemanticVerify(db); err != nil {
+		t.Fatal(err)
+	}
+
+	currentValidators, err = vm.getCurrentValidators(db, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numValidators := currentValidators.Len(); numValidators != len(keys) {
+		t.Fatalf("Should be %d validators but are %d", len(keys), numValidators)
+	}
+	vdrs := vm.getValidators(currentValidators)
+	foundNew := false
+	for _, vdr := range vdrs {
+		switch vdrID := vdr.ID(); {
+		case vdrID.Equals(oldNodeID):
+			t.Fatal("validator should have been rotated away from its old node ID")
+		case vdrID.Equals(newNodeID):
+			foundNew = true
+			if vdr.Weight() != defaultStakeAmount {
+				t.Fatalf("rotated validator should have weight %d but has %d", defaultStakeAmount, vdr.Weight())
+			}
+		}
+	}
+	if !foundNew {
+		t.Fatal("validator should have been rotated to its new node ID")
+	}
+
+	// Delegators of the old node ID resolve to the rotated validator
+	for _, nodeID := range []ids.ShortID{oldNodeID, newNodeID} {
+		rotatedStaker, err := currentValidators.getDefaultSubnetStaker(nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rotatedStaker.ID().Equals(staker.ID()) {
+			t.Fatalf("rotated validator should have kept ID %s but has %s", staker.ID(), rotatedStaker.ID())
+		}
+	}
+
+	account, err := vm.getAccount(db, keys[0].PublicKey().Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Nonce != defaultNonce+1 {
+		t.Fatalf("account nonce should be %d but is %d", defaultNonce+1, account.Nonce)
+	}
+
+	// The old node ID can't be used again during the staking period
+	tx, err = vm.newRotateValidatorTx(defaultNonce+2, staker.ID(), oldNodeID, testNetworkID, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.SemanticVerify(versiondb.New(db)); err == nil {
+		t.Fatal("should have failed because the validator already had the node ID")
+	}
+
+	// The rotated validator is rewarded at the end of its staking period
+	if err := vm.putTimestamp(db, defaultValidateEndTime); err != nil {
+		t.Fatal(err)
+	}
+	rewardTx, err := vm.newRewardValidatorTx(currentValidators.Peek().ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	onCommitDB, _, _, _, err := rewardTx.SemanticVerify(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentValidators, err = vm.getCurrentValidators(onCommitDB, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numValidators := currentValidators.Len(); numValidators != len(keys)-1 {
+		t.Fatalf("Should be %d validators but are %d", len(keys)-1, numValidators)
+	}
+}
@@ -9,14 +9,20 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gorilla/rpc/v2/json2"
 
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/json"
+	"github.com/ava-labs/gecko/utils/math"
+	"github.com/ava-labs/gecko/vms/avm"
 )
 
 var (
@@ -30,6 +36,9 @@ var (
 	errNoDestination        = errors.New("call is missing field 'stakeDestination'")
 	errNoSource             = errors.New("call is missing field 'stakeSource'")
 	errGetStakeSource       = errors.New("couldn't get account specified in 'stakeSource'")
+	errNoUptimeTracker      = errors.New("this node doesn't track the performance of validators")
+	errNotDSValidator       = errors.New("node isn't a current or pending validator of the default subnet")
+	errNoAVMChain           = errors.New("this node doesn't run the AVM on the chain")
 )
 
 var key *crypto.PrivateKeySECP256K1R
@@ -121,6 +130,35 @@ func (service *Service) GetSubnets(_ *http.Request, args *GetSubnetsArgs, respon
 	return nil
 }
 
+/*
+ ******************************************************
+ ******************* Get Blocks ***********************
+ ******************************************************
+ */
+
+// GetBlockIDAtHeightArgs are the arguments for calling GetBlockIDAtHeight
+type GetBlockIDAtHeightArgs struct {
+	Height json.Uint64 `json:"height"`
+}
+
+// GetBlockIDAtHeightReply are the results from calling GetBlockIDAtHeight
+type GetBlockIDAtHeightReply struct {
+	BlockID ids.ID `json:"blockID"`
+}
+
+// GetBlockIDAtHeight returns the ID of the accepted block at the provided
+// height. The genesis block is at height 0.
+func (service *Service) GetBlockIDAtHeight(_ *http.Request, args *GetBlockIDAtHeightArgs, reply *GetBlockIDAtHeightReply) error {
+	service.vm.Ctx.Log.Debug("GetBlockIDAtHeight called with {Height = %d}", args.Height)
+
+	blockID, err := service.vm.GetBlockIDAtHeight(uint64(args.Height))
+	if err != nil {
+		return fmt.Errorf("couldn't get the accepted block at height %d: %w", args.Height, err)
+	}
+	reply.BlockID = blockID
+	return nil
+}
+
 /*
  ******************************************************
  **************** Get/Sample Validators ***************
@@ -267,6 +305,299 @@ func (service *Service) SampleValidators(_ *http.Request, args *SampleValidators
 	return nil
 }
 
+// GetValidatorPerformanceArgs are the arguments for calling GetValidatorPerformance
+type GetValidatorPerformanceArgs struct {
+	// Subnet whose validators we're getting the performance of
+	// If omitted, defaults to default subnet
+	SubnetID ids.ID `json:"subnetID"`
+}
+
+// APIValidatorPerformance is the performance of a validator, as observed by
+// this node since the later of the validator's start time and this node's start
+type APIValidatorPerformance struct {
+	ID ids.ShortID `json:"id"`
+
+	// Fraction of the time the validator was connected to this node
+	Uptime json.Float64 `json:"uptime"`
+
+	// Number of requests the validator responded to in time, and that timed out
+	Responses json.Uint64 `json:"responses"`
+	Timeouts  json.Uint64 `json:"timeouts"`
+
+	// Average time, in milliseconds, the validator took to respond to a request
+	AverageLatency json.Uint64 `json:"averageLatency"`
+
+	// True if the validator's uptime meets the requirement to be rewarded.
+	// Omitted unless uptime based rewards are enabled.
+	RewardEligible *bool `json:"rewardEligible,omitempty"`
+}
+
+// GetValidatorPerformanceReply are the results from calling GetValidatorPerformance
+type GetValidatorPerformanceReply struct {
+	Validators []APIValidatorPerformance `json:"validators"`
+}
+
+// GetValidatorPerformance returns the uptime and responsiveness of the current
+// validators, as observed by this node
+func (service *Service) GetValidatorPerformance(_ *http.Request, args *GetValidatorPerformanceArgs, reply *GetValidatorPerformanceReply) error {
+	service.vm.Ctx.Log.Debug("GetValidatorPerformance called")
+
+	if service.vm.UptimeTracker == nil {
+		return errNoUptimeTracker
+	}
+	if args.SubnetID.IsZero() {
+		args.SubnetID = DefaultSubnetID
+	}
+
+	validators, err := service.vm.getCurrentValidators(service.vm.DB, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("couldn't get validators of subnet with ID %s. Does it exist?", args.SubnetID)
+	}
+
+	reply.Validators = make([]APIValidatorPerformance, validators.Len())
+	for i, tx := range validators.Txs {
+		vdrID := tx.Vdr().ID()
+		perf := service.vm.UptimeTracker.Performance(vdrID, tx.StartTime())
+		reply.Validators[i] = APIValidatorPerformance{
+			ID:             vdrID,
+			Uptime:         json.Float64(perf.Uptime),
+			Responses:      json.Uint64(perf.Responses),
+			Timeouts:       json.Uint64(perf.Timeouts),
+			AverageLatency: json.Uint64(perf.AverageLatency / time.Millisecond),
+		}
+		if service.vm.UptimeRewardThreshold > 0 && args.SubnetID.Equals(DefaultSubnetID) {
+			eligible := service.vm.eligibleForReward(tx)
+			reply.Validators[i].RewardEligible = &eligible
+		}
+	}
+	return nil
+}
+
+/*
+ ******************************************************
+ ***************** Staking Rewards ********************
+ ******************************************************
+ */
+
+// GetRewardEstimateArgs are the arguments for calling GetRewardEstimate
+type GetRewardEstimateArgs struct {
+	// Node ID of a current or pending validator of the default subnet
+	NodeID ids.ShortID `json:"nodeID"`
+}
+
+// GetRewardEstimateReply are the results from calling GetRewardEstimate
+type GetRewardEstimateReply struct {
+	StakeAmount json.Uint64 `json:"stakeAmount"`
+	StartTime   json.Uint64 `json:"startTime"`
+	EndTime     json.Uint64 `json:"endTime"`
+
+	// True if the validator hasn't started validating yet
+	Pending bool `json:"pending"`
+
+	// Reward the validator earns on its own stake
+	Reward json.Uint64 `json:"reward"`
+
+	// Share of the rewards of the current and pending delegators of the
+	// validator that the validator earns
+	DelegationFees json.Uint64 `json:"delegationFees"`
+	Delegators     json.Uint64 `json:"delegators"`
+}
+
+// GetRewardEstimate returns the reward a validator of the default subnet
+// earns at the end of its staking period, if it's rewarded and its delegators
+// are rewarded
+func (service *Service) GetRewardEstimate(_ *http.Request, args *GetRewardEstimateArgs, reply *GetRewardEstimateReply) error {
+	service.vm.Ctx.Log.Debug("GetRewardEstimate called with NodeID: %s", args.NodeID)
+
+	current, pending, err := service.defaultSubnetStakers()
+	if err != nil {
+		return err
+	}
+	validator, isPending, err := findDefaultSubnetStaker(current, pending, args.NodeID)
+	if err != nil {
+		return err
+	}
+
+	delegationFees := uint64(0)
+	delegators := 0
+	for _, stakers := range []*EventHeap{current, pending} {
+		for _, txIntf := range stakers.Txs {
+			delegator, ok := txIntf.(*addDefaultSubnetDelegatorTx)
+			if !ok {
+				continue
+			}
+			delegatee, _, err := findDefaultSubnetStaker(current, pending, delegator.NodeID)
+			if err != nil || !delegatee.ID().Equals(validator.ID()) {
+				continue
+			}
+			_, fee, err := delegationRewards(validator, delegator)
+			if err != nil {
+				return err
+			}
+			if delegationFees, err = math.Add64(delegationFees, fee); err != nil {
+				return err
+			}
+			delegators++
+		}
+	}
+
+	reply.StakeAmount = json.Uint64(validator.Wght)
+	reply.StartTime = json.Uint64(validator.Start)
+	reply.EndTime = json.Uint64(validator.End)
+	reply.Pending = isPending
+	reply.Reward = json.Uint64(reward(validator.Duration(), validator.Wght, InflationRate))
+	reply.DelegationFees = json.Uint64(delegationFees)
+	reply.Delegators = json.Uint64(delegators)
+	return nil
+}
+
+// GetRewardPayoutsArgs are the arguments for calling GetRewardPayouts
+type GetRewardPayoutsArgs struct {
+	// Address of the account whose payouts are returned
+	Address ids.ShortID `json:"address"`
+}
+
+// APIRewardPayout is the outcome, for an account, of the end of a staking
+// period on the default subnet
+type APIRewardPayout struct {
+	// ID of the tx that added the staker
+	TxID   ids.ID      `json:"txID"`
+	NodeID ids.ShortID `json:"nodeID"`
+
+	// True if the staker was a delegator
+	Delegator bool `json:"delegator"`
+
+	// True if the account is the validator's, and [Reward] is its share of a
+	// delegator's reward
+	DelegationFee bool `json:"delegationFee"`
+
+	StakeAmount json.Uint64 `json:"stakeAmount"`
+	Reward      json.Uint64 `json:"reward"`
+	EndTime     json.Uint64 `json:"endTime"`
+}
+
+// GetRewardPayoutsReply are the results from calling GetRewardPayouts
+type GetRewardPayoutsReply struct {
+	Payouts []APIRewardPayout `json:"payouts"`
+}
+
+// GetRewardPayouts returns the reward payouts an account received when the
+// staking periods it was the destination of ended, oldest first. A staker that
+// wasn't rewarded has a payout with no reward.
+func (service *Service) GetRewardPayouts(_ *http.Request, args *GetRewardPayoutsArgs, reply *GetRewardPayoutsReply) error {
+	service.vm.Ctx.Log.Debug("GetRewardPayouts called with Address: %s", args.Address)
+
+	payouts, err := service.vm.getRewardPayouts(service.vm.DB, args.Address)
+	if err != nil {
+		return fmt.Errorf("couldn't get the reward payouts of %s: %w", args.Address, err)
+	}
+
+	reply.Payouts = make([]APIRewardPayout, len(payouts))
+	for i, payout := range payouts {
+		reply.Payouts[i] = APIRewardPayout{
+			TxID:          payout.TxID,
+			NodeID:        payout.NodeID,
+			Delegator:     payout.Delegator,
+			DelegationFee: payout.DelegationFee,
+			StakeAmount:   json.Uint64(payout.StakeAmount),
+			Reward:        json.Uint64(payout.Reward),
+			EndTime:       json.Uint64(payout.EndTime),
+		}
+	}
+	return nil
+}
+
+// GetDelegationsArgs are the arguments for calling GetDelegations
+type GetDelegationsArgs struct {
+	// Address of the account the delegated stake is returned to
+	Address ids.ShortID `json:"address"`
+}
+
+// APIDelegation is a current or pending delegation on the default subnet
+type APIDelegation struct {
+	TxID        ids.ID      `json:"txID"`
+	NodeID      ids.ShortID `json:"nodeID"`
+	StakeAmount json.Uint64 `json:"stakeAmount"`
+	StartTime   json.Uint64 `json:"startTime"`
+	EndTime     json.Uint64 `json:"endTime"`
+
+	// True if the delegation hasn't started yet
+	Pending bool `json:"pending"`
+
+	// Reward of the delegator if it's rewarded, net of the validator's share
+	PotentialReward json.Uint64 `json:"potentialReward"`
+}
+
+// GetDelegationsReply are the results from calling GetDelegations
+type GetDelegationsReply struct {
+	Delegations []APIDelegation `json:"delegations"`
+}
+
+// GetDelegations returns the current and pending delegations whose stake, and
+// reward, are returned to an account
+func (service *Service) GetDelegations(_ *http.Request, args *GetDelegationsArgs, reply *GetDelegationsReply) error {
+	service.vm.Ctx.Log.Debug("GetDelegations called with Address: %s", args.Address)
+
+	current, pending, err := service.defaultSubnetStakers()
+	if err != nil {
+		return err
+	}
+
+	reply.Delegations = []APIDelegation{}
+	for _, stakers := range []*EventHeap{current, pending} {
+		for _, txIntf := range stakers.Txs {
+			delegator, ok := txIntf.(*addDefaultSubnetDelegatorTx)
+			if !ok || !delegator.Destination.Equals(args.Address) {
+				continue
+			}
+			delegation := APIDelegation{
+				TxID:        delegator.ID(),
+				NodeID:      delegator.NodeID,
+				StakeAmount: json.Uint64(delegator.Wght),
+				StartTime:   json.Uint64(delegator.Start),
+				EndTime:     json.Uint64(delegator.End),
+				Pending:     stakers == pending,
+			}
+			if validator, _, err := findDefaultSubnetStaker(current, pending, delegator.NodeID); err == nil {
+				potentialReward, _, err := delegationRewards(validator, delegator)
+				if err != nil {
+					return err
+				}
+				delegation.PotentialReward = json.Uint64(potentialReward)
+			}
+			reply.Delegations = append(reply.Delegations, delegation)
+		}
+	}
+	return nil
+}
+
+// defaultSubnetStakers returns the current and pending stakers of the default
+// subnet
+func (service *Service) defaultSubnetStakers() (*EventHeap, *EventHeap, error) {
+	current, err := service.vm.getCurrentValidators(service.vm.DB, DefaultSubnetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get the current validators of the default subnet: %w", err)
+	}
+	pending, err := service.vm.getPendingValidators(service.vm.DB, DefaultSubnetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get the pending validators of the default subnet: %w", err)
+	}
+	return current, pending, nil
+}
+
+// findDefaultSubnetStaker returns the tx that added the current or pending
+// validator of the default subnet with node ID [nodeID], and true if the
+// validator is pending
+func findDefaultSubnetStaker(current, pending *EventHeap, nodeID ids.ShortID) (*addDefaultSubnetValidatorTx, bool, error) {
+	if validator, err := current.getDefaultSubnetStaker(nodeID); err == nil {
+		return validator, false, nil
+	}
+	if validator, err := pending.getDefaultSubnetStaker(nodeID); err == nil {
+		return validator, true, nil
+	}
+	return nil, false, errNotDSValidator
+}
+
 /*
  ******************************************************
  *************** Get/Create Accounts ******************
@@ -371,6 +702,9 @@ type CreateAccountArgs struct {
 type CreateAccountReply struct {
 	// Address of the newly created account
 	Address ids.ShortID `json:"address"`
+
+	// Address of the newly created account, formatted in bech32
+	Bech32Address string `json:"bech32Address"`
 }
 
 // CreateAccount creates a new account on the Platform Chain
@@ -419,7 +753,16 @@ func (service *Service) CreateAccount(_ *http.Request, args *CreateAccountArgs,
 
 	reply.Address = privKey.PublicKey().Address()
 
-	return nil
+	chainAlias, err := service.vm.Ctx.BCLookup.PrimaryAlias(service.vm.Ctx.ChainID)
+	if err != nil {
+		chainAlias = service.vm.Ctx.ChainID.String()
+	}
+	reply.Bech32Address, err = formatting.FormatAddress(
+		chainAlias,
+		formatting.Bech32HRP(service.vm.Ctx.NetworkID),
+		reply.Address.Bytes(),
+	)
+	return err
 }
 
 type genericTx struct {
@@ -471,7 +814,7 @@ func (service *Service) AddDefaultSubnetValidator(_ *http.Request, args *AddDefa
 		Shares:      uint32(args.DelegationFeeRate),
 	}}
 
-	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
 	if err != nil {
 		return fmt.Errorf("problem while creating transaction: %w", err)
 	}
@@ -521,7 +864,7 @@ func (service *Service) AddDefaultSubnetDelegator(_ *http.Request, args *AddDefa
 		Destination: args.Destination,
 	}}
 
-	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
 	if err != nil {
 		return fmt.Errorf("problem while creating transaction: %w", err)
 	}
@@ -574,7 +917,7 @@ func (service *Service) AddNonDefaultSubnetValidator(_ *http.Request, args *AddN
 		bytes:       nil,
 	}
 
-	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
 	if err != nil {
 		return errCreatingTransaction
 	}
@@ -583,6 +926,52 @@ func (service *Service) AddNonDefaultSubnetValidator(_ *http.Request, args *AddN
 	return nil
 }
 
+// RotateValidatorArgs are the arguments to RotateValidator
+type RotateValidatorArgs struct {
+	// ID of the tx that added the validator to the default subnet
+	TxID ids.ID `json:"txID"`
+
+	// ID of the node the validator is rotating to
+	NodeID ids.ShortID `json:"nodeID"`
+
+	// Next unused nonce of the account that added the validator
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// RotateValidatorResponse is the response from a call to RotateValidator
+type RotateValidatorResponse struct {
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+}
+
+// RotateValidator returns an unsigned transaction to change the node ID of a
+// current validator of the default subnet for the rest of its staking period.
+// The returned unsigned transaction must be signed using Sign, by the key that
+// signed the tx that added the validator.
+func (service *Service) RotateValidator(_ *http.Request, args *RotateValidatorArgs, reply *RotateValidatorResponse) error {
+	service.vm.Ctx.Log.Debug("platform.RotateValidator called")
+
+	if args.NodeID.IsZero() { // If ID unspecified, rotate to this node's ID
+		args.NodeID = service.vm.Ctx.NodeID
+	}
+
+	// Create the transaction
+	tx := rotateValidatorTx{UnsignedRotateValidatorTx: UnsignedRotateValidatorTx{
+		NetworkID: service.vm.Ctx.NetworkID,
+		Nonce:     uint64(args.PayerNonce),
+		TxID:      args.TxID,
+		NodeID:    args.NodeID,
+	}}
+
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
+	if err != nil {
+		return fmt.Errorf("problem while creating transaction: %w", err)
+	}
+
+	reply.UnsignedTx.Bytes = txBytes
+	return nil
+}
+
 /*
  ******************************************************
  **************** Sign/Issue Txs **********************
@@ -629,150 +1018,180 @@ func (service *Service) Sign(_ *http.Request, args *SignArgs, reply *SignRespons
 	}
 
 	genTx := genericTx{}
-	if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+	if _, err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
 		return err
 	}
 
-	switch tx := genTx.Tx.(type) {
-	case *addDefaultSubnetValidatorTx:
-		genTx.Tx, err = service.signAddDefaultSubnetValidatorTx(tx, key)
-	case *addDefaultSubnetDelegatorTx:
-		genTx.Tx, err = service.signAddDefaultSubnetDelegatorTx(tx, key)
-	case *addNonDefaultSubnetValidatorTx:
-		genTx.Tx, err = service.signAddNonDefaultSubnetValidatorTx(tx, key)
-	case *CreateSubnetTx:
-		genTx.Tx, err = service.signCreateSubnetTx(tx, key)
-	default:
-		err = errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx")
+	unsignedBytes, err := unsignedTxBytes(genTx.Tx)
+	if err != nil {
+		return err
 	}
+	sig, err := key.Sign(unsignedBytes)
 	if err != nil {
+		return errors.New("error while signing")
+	}
+	if err := service.addSignature(genTx.Tx, key.PublicKey().Address(), sig); err != nil {
 		return err
 	}
 
-	reply.Tx.Bytes, err = Codec.Marshal(genTx)
+	reply.Tx.Bytes, err = Codec.Marshal(codecVersion, genTx)
 	return err
 }
 
-// Sign [unsigned] with [key]
-func (service *Service) signAddDefaultSubnetValidatorTx(tx *addDefaultSubnetValidatorTx, key *crypto.PrivateKeySECP256K1R) (*addDefaultSubnetValidatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
+// GetTxDigestArgs are the arguments to GetTxDigest
+type GetTxDigestArgs struct {
+	// The unsigned or partially signed tx
+	Tx formatting.CB58 `json:"tx"`
+}
 
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetValidatorTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
-	}
+// GetTxDigestResponse is the response from GetTxDigest
+type GetTxDigestResponse struct {
+	// The digest every signature on the tx must be over
+	Digest formatting.CB58 `json:"digest"`
+}
 
-	sig, err := key.Sign(unsignedTxBytes)
-	if err != nil {
-		return nil, errors.New("error while signing")
+// GetTxDigest returns the digest that signatures on [args.Tx] are over, so the
+// tx can be signed without the keystore. The signatures can be added to the tx
+// with AddSignature.
+func (service *Service) GetTxDigest(_ *http.Request, args *GetTxDigestArgs, reply *GetTxDigestResponse) error {
+	service.vm.Ctx.Log.Debug("platform.getTxDigest called")
+
+	genTx := genericTx{}
+	if _, err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+		return err
 	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	unsignedBytes, err := unsignedTxBytes(genTx.Tx)
+	if err != nil {
+		return err
 	}
-	copy(tx.Sig[:], sig)
+	reply.Digest.Bytes = hashing.ComputeHash256(unsignedBytes)
+	return nil
+}
+
+// AddSignatureArgs are the arguments to AddSignature
+type AddSignatureArgs struct {
+	// The unsigned or partially signed tx
+	Tx formatting.CB58 `json:"tx"`
+
+	// Signature over the tx's digest, as returned by GetTxDigest
+	Signature formatting.CB58 `json:"signature"`
+}
 
-	return tx, nil
+// AddSignatureResponse is the response from AddSignature
+type AddSignatureResponse struct {
+	// The tx, with the signature added
+	Tx formatting.CB58 `json:"tx"`
 }
 
-// Sign [unsigned] with [key]
-func (service *Service) signAddDefaultSubnetDelegatorTx(tx *addDefaultSubnetDelegatorTx, key *crypto.PrivateKeySECP256K1R) (*addDefaultSubnetDelegatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
+// AddSignature adds [args.Signature] to [args.Tx] as Sign would have if it
+// held the key that made the signature
+func (service *Service) AddSignature(_ *http.Request, args *AddSignatureArgs, reply *AddSignatureResponse) error {
+	service.vm.Ctx.Log.Debug("platform.addSignature called")
 
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedAddDefaultSubnetDelegatorTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
+	genTx := genericTx{}
+	if _, err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+		return err
+	}
+	unsignedBytes, err := unsignedTxBytes(genTx.Tx)
 	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
+		return err
 	}
-
-	sig, err := key.Sign(unsignedTxBytes)
+	key, err := service.vm.factory.RecoverPublicKey(unsignedBytes, args.Signature.Bytes)
 	if err != nil {
-		return nil, errors.New("error while signing")
+		return fmt.Errorf("couldn't recover the signer: %w", err)
 	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+	if err := service.addSignature(genTx.Tx, key.Address(), args.Signature.Bytes); err != nil {
+		return err
 	}
-	copy(tx.Sig[:], sig)
 
-	return tx, nil
+	reply.Tx.Bytes, err = Codec.Marshal(codecVersion, genTx)
+	return err
 }
 
-// Sign [xt] with [key]
-func (service *Service) signCreateSubnetTx(tx *CreateSubnetTx, key *crypto.PrivateKeySECP256K1R) (*CreateSubnetTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddDefaultSubnetValidatorTx called")
-
-	// TODO: Should we check if tx is already signed?
-	unsignedIntf := interface{}(&tx.UnsignedCreateSubnetTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
+// unsignedTxBytes returns the byte repr. of the unsigned part of [tx], which is
+// what signatures on [tx] are over
+func unsignedTxBytes(tx interface{}) ([]byte, error) {
+	var unsignedIntf interface{}
+	switch tx := tx.(type) {
+	case *addDefaultSubnetValidatorTx:
+		unsignedIntf = &tx.UnsignedAddDefaultSubnetValidatorTx
+	case *addDefaultSubnetDelegatorTx:
+		unsignedIntf = &tx.UnsignedAddDefaultSubnetDelegatorTx
+	case *addNonDefaultSubnetValidatorTx:
+		unsignedIntf = &tx.UnsignedAddNonDefaultSubnetValidatorTx
+	case *CreateSubnetTx:
+		unsignedIntf = &tx.UnsignedCreateSubnetTx
+	case *rotateValidatorTx:
+		unsignedIntf = &tx.UnsignedRotateValidatorTx
+	case *exportTx:
+		unsignedIntf = &tx.UnsignedExportTx
+	case *importTx:
+		unsignedIntf = &tx.UnsignedImportTx
+	default:
+		return nil, errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx, rotateValidatorTx, exportTx, importTx")
 	}
-
-	sig, err := key.Sign(unsignedTxBytes)
+	unsignedTxBytes, err := Codec.Marshal(codecVersion, &unsignedIntf)
 	if err != nil {
-		return nil, errors.New("error while signing")
-	}
-	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
 	}
-	copy(tx.Sig[:], sig)
-
-	return tx, nil
+	return unsignedTxBytes, nil
 }
 
-// Signs an unsigned or partially signed addNonDefaultSubnetValidatorTx with [key]
-// If [key] is a control key for the subnet and there is an empty spot in tx.ControlSigs, signs there
-// If [key] is a control key for the subnet and there is no empty spot in tx.ControlSigs, signs as payer
-// If [key] is not a control key, sign as payer (account controlled by [key] pays the tx fee)
+// addSignature puts [sig], a signature on [tx] by [signer], into [tx].
+//
+// For an addNonDefaultSubnetValidatorTx:
+// If [signer] is a control key for the subnet and there is an empty spot in tx.ControlSigs, signs there
+// If [signer] is a control key for the subnet and there is no empty spot in tx.ControlSigs, signs as payer
+// If [signer] is not a control key, sign as payer (account controlled by [signer] pays the tx fee)
 // Sorts tx.ControlSigs before returning
 // Assumes each element of tx.ControlSigs is actually a signature, not just empty bytes
-func (service *Service) signAddNonDefaultSubnetValidatorTx(tx *addNonDefaultSubnetValidatorTx, key *crypto.PrivateKeySECP256K1R) (*addNonDefaultSubnetValidatorTx, error) {
-	service.vm.Ctx.Log.Debug("platform.signAddNonDefaultSubnetValidatorTx called")
-
-	// Compute the byte repr. of the unsigned tx and the signature of [key] over it
-	unsignedIntf := interface{}(&tx.UnsignedAddNonDefaultSubnetValidatorTx)
-	unsignedTxBytes, err := Codec.Marshal(&unsignedIntf)
-	if err != nil {
-		return nil, fmt.Errorf("error serializing unsigned tx: %v", err)
-	}
-	sig, err := key.Sign(unsignedTxBytes)
-	if err != nil {
-		return nil, errors.New("error while signing")
-	}
+func (service *Service) addSignature(tx interface{}, signer ids.ShortID, sig []byte) error {
 	if len(sig) != crypto.SECP256K1RSigLen {
-		return nil, fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
+		return fmt.Errorf("expected signature to be length %d but was length %d", crypto.SECP256K1RSigLen, len(sig))
 	}
 
-	// Get information about the subnet
-	subnet, err := service.vm.getSubnet(service.vm.DB, tx.SubnetID())
-	if err != nil {
-		return nil, fmt.Errorf("problem getting subnet information: %v", err)
-	}
-
-	// Find the location at which [key] should put its signature.
-	// If [key] is a control key for this subnet and there is an empty spot in tx.ControlSigs, sign there
-	// If [key] is a control key for this subnet and there is no empty spot in tx.ControlSigs, sign as payer
-	// If [key] is not a control key, sign as payer (account controlled by [key] pays the tx fee)
-	controlKeySet := ids.ShortSet{}
-	controlKeySet.Add(subnet.ControlKeys...)
-	isControlKey := controlKeySet.Contains(key.PublicKey().Address())
+	// TODO: Should we check if tx is already signed?
+	switch tx := tx.(type) {
+	case *addDefaultSubnetValidatorTx:
+		copy(tx.Sig[:], sig)
+	case *addDefaultSubnetDelegatorTx:
+		copy(tx.Sig[:], sig)
+	case *CreateSubnetTx:
+		copy(tx.Sig[:], sig)
+	case *rotateValidatorTx:
+		copy(tx.Sig[:], sig)
+	case *exportTx:
+		copy(tx.Sig[:], sig)
+	case *importTx:
+		copy(tx.Sig[:], sig)
+	case *addNonDefaultSubnetValidatorTx:
+		// Get information about the subnet
+		subnet, err := service.vm.getSubnet(service.vm.DB, tx.SubnetID())
+		if err != nil {
+			return fmt.Errorf("problem getting subnet information: %v", err)
+		}
 
-	payerSigEmpty := tx.PayerSig == [crypto.SECP256K1RSigLen]byte{} // true if no key has signed to pay the tx fee
+		// Find the location at which [signer] should put its signature.
+		controlKeySet := ids.ShortSet{}
+		controlKeySet.Add(subnet.ControlKeys...)
+		isControlKey := controlKeySet.Contains(signer)
 
-	if isControlKey && len(tx.ControlSigs) != int(subnet.Threshold) { // Sign as controlSig
-		tx.ControlSigs = append(tx.ControlSigs, [crypto.SECP256K1RSigLen]byte{})
-		copy(tx.ControlSigs[len(tx.ControlSigs)-1][:], sig)
-	} else if payerSigEmpty { // sign as payer
-		copy(tx.PayerSig[:], sig)
-	} else {
-		return nil, errors.New("no place for key to sign")
-	}
+		payerSigEmpty := tx.PayerSig == [crypto.SECP256K1RSigLen]byte{} // true if no key has signed to pay the tx fee
 
-	crypto.SortSECP2561RSigs(tx.ControlSigs)
+		if isControlKey && len(tx.ControlSigs) != int(subnet.Threshold) { // Sign as controlSig
+			tx.ControlSigs = append(tx.ControlSigs, [crypto.SECP256K1RSigLen]byte{})
+			copy(tx.ControlSigs[len(tx.ControlSigs)-1][:], sig)
+		} else if payerSigEmpty { // sign as payer
+			copy(tx.PayerSig[:], sig)
+		} else {
+			return errors.New("no place for key to sign")
+		}
 
-	return tx, nil
+		crypto.SortSECP2561RSigs(tx.ControlSigs)
+	default:
+		return errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx, rotateValidatorTx, exportTx, importTx")
+	}
+	return nil
 }
 
 // IssueTxArgs are the arguments to IssueTx
@@ -789,12 +1208,17 @@ type IssueTxResponse struct {
 
 // IssueTx issues the transaction [args.Tx] to the network
 func (service *Service) IssueTx(_ *http.Request, args *IssueTxArgs, response *IssueTxResponse) error {
+	if service.vm.Ctx.ReadOnly {
+		return snow.ErrReadOnly
+	}
 	genTx := genericTx{}
-	if err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
+	if _, err := Codec.Unmarshal(args.Tx.Bytes, &genTx); err != nil {
 		return err
 	}
 
 	switch tx := genTx.Tx.(type) {
+	case *rotatedValidatorTx:
+		return errRotatedValidatorTx
 	case TimedTx:
 		if err := tx.initialize(service.vm); err != nil {
 			return fmt.Errorf("error initializing tx: %s", err)
@@ -807,13 +1231,270 @@ func (service *Service) IssueTx(_ *http.Request, args *IssueTxArgs, response *Is
 		if err := tx.initialize(service.vm); err != nil {
 			return fmt.Errorf("error initializing tx: %s", err)
 		}
-		service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
-		defer service.vm.resetTimer()
+		if err := service.vm.issueDecisionTx(tx.ID, tx); err != nil {
+			return fmt.Errorf("error issuing tx: %w", err)
+		}
 		response.TxID = tx.ID
 		return nil
+	case *rotateValidatorTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		if err := service.vm.issueDecisionTx(tx.ID(), tx); err != nil {
+			return fmt.Errorf("error issuing tx: %w", err)
+		}
+		response.TxID = tx.ID()
+		return nil
+	case atomicTx:
+		if err := tx.initialize(service.vm); err != nil {
+			return fmt.Errorf("error initializing tx: %s", err)
+		}
+		if err := service.vm.issueDecisionTx(tx.ID(), tx); err != nil {
+			return fmt.Errorf("error issuing tx: %w", err)
+		}
+		response.TxID = tx.ID()
+		return nil
 	default:
-		return errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addDefaultSubnetDelegatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx")
+		return errors.New("Could not parse given tx. Must be one of: addDefaultSubnetValidatorTx, addDefaultSubnetDelegatorTx, addNonDefaultSubnetValidatorTx, createSubnetTx, rotateValidatorTx, exportTx, importTx")
+	}
+}
+
+/*
+ ******************************************************
+ ********** Exchange $AVA with the X-Chain ************
+ ******************************************************
+ */
+
+// Statuses of the transfer an export tx makes
+const (
+	transferExporting = "Exporting" // The export tx hasn't been accepted yet
+	transferExported  = "Exported"  // The destination chain hasn't imported the transfer yet
+	transferImported  = "Imported"  // The destination chain imported the transfer
+	transferFailed    = "Failed"    // The export tx was dropped
+)
+
+// avmChain returns the ID of the chain with alias or ID [chain], which must be
+// a chain running the AVM that this node runs. Only those chains exchange $AVA
+// with the Platform Chain.
+func (service *Service) avmChain(chain string) (ids.ID, error) {
+	chainID, err := service.vm.Ctx.BCLookup.Lookup(chain)
+	if err != nil {
+		chainID, err = ids.FromString(chain)
+		if err != nil {
+			return ids.ID{}, fmt.Errorf("chain '%s' not found", chain)
+		}
+	}
+	if service.vm.Ctx.VMLookup == nil {
+		return ids.ID{}, errNoAVMChain
+	}
+	if vmID, err := service.vm.Ctx.VMLookup.ChainVM(chainID); err != nil || !vmID.Equals(avm.ID) {
+		return ids.ID{}, fmt.Errorf("%w: %s", errNoAVMChain, chainID)
+	}
+	return chainID, nil
+}
+
+// ExportAVAArgs are the arguments to ExportAVA
+type ExportAVAArgs struct {
+	// Chain the $AVA is exported to, by alias or ID. Must be a chain running
+	// the AVM that this node runs, such as the X-Chain.
+	DestinationChain string `json:"destinationChain"`
+
+	// Address on the destination chain that can import the $AVA
+	To ids.ShortID `json:"to"`
+
+	// Amount of $AVA exported, in nAVA
+	Amount json.Uint64 `json:"amount"`
+
+	// Next unused nonce of the account the $AVA is exported from
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// ExportAVAResponse is the response from a call to ExportAVA
+type ExportAVAResponse struct {
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+}
+
+// ExportAVA returns an unsigned transaction to export $AVA from an account to
+// an address on another chain. The returned unsigned transaction must be
+// signed using Sign, by the key of the account. Once the issued transaction is
+// accepted, the destination chain's import call imports the $AVA; its ID is
+// the TransferID that GetExportStatus returns.
+func (service *Service) ExportAVA(_ *http.Request, args *ExportAVAArgs, response *ExportAVAResponse) error {
+	service.vm.Ctx.Log.Debug("platform.exportAVA called")
+
+	switch {
+	case args.Amount == 0:
+		return errNoExportAmount
+	case args.To.IsZero():
+		return errNoExportReceiver
+	}
+	destinationChain, err := service.avmChain(args.DestinationChain)
+	if err != nil {
+		return err
+	}
+
+	// Create the transaction
+	tx := exportTx{UnsignedExportTx: UnsignedExportTx{
+		NetworkID:        service.vm.Ctx.NetworkID,
+		Nonce:            uint64(args.PayerNonce),
+		DestinationChain: destinationChain,
+		Amount:           uint64(args.Amount),
+		To:               args.To,
+	}}
+
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
+	if err != nil {
+		return fmt.Errorf("problem while creating transaction: %w", err)
+	}
+
+	response.UnsignedTx.Bytes = txBytes
+	return nil
+}
+
+// ImportAVAArgs are the arguments to ImportAVA
+type ImportAVAArgs struct {
+	// Chain the $AVA was exported from, by alias or ID
+	SourceChain string `json:"sourceChain"`
+
+	// IDs of the exported $AVA, as returned by the source chain's export
+	// call. Each must be to the address of the key that signs the import.
+	TransferIDs []ids.ID `json:"transferIDs"`
+
+	// Next unused nonce of the account the $AVA is imported to
+	PayerNonce json.Uint64 `json:"payerNonce"`
+}
+
+// ImportAVAResponse is the response from a call to ImportAVA
+type ImportAVAResponse struct {
+	// The unsigned transaction
+	UnsignedTx formatting.CB58 `json:"unsignedTx"`
+
+	// Amount of $AVA the transaction imports, in nAVA
+	Amount json.Uint64 `json:"amount"`
+}
+
+// ImportAVA returns an unsigned transaction to import $AVA that another chain
+// exported to the Platform Chain. The $AVA is added to the account of the key
+// the returned unsigned transaction is signed with using Sign.
+func (service *Service) ImportAVA(_ *http.Request, args *ImportAVAArgs, response *ImportAVAResponse) error {
+	service.vm.Ctx.Log.Debug("platform.importAVA called")
+
+	if len(args.TransferIDs) == 0 {
+		return errNoImportedTransfers
 	}
+	sourceChain, err := service.avmChain(args.SourceChain)
+	if err != nil {
+		return err
+	}
+
+	transferIDs := append([]ids.ID(nil), args.TransferIDs...)
+	ids.SortIDs(transferIDs)
+	tx := importTx{
+		UnsignedImportTx: UnsignedImportTx{
+			NetworkID:   service.vm.Ctx.NetworkID,
+			Nonce:       uint64(args.PayerNonce),
+			SourceChain: sourceChain,
+			TransferIDs: transferIDs,
+		},
+		vm: service.vm,
+	}
+
+	// Fail now, rather than once the transaction is issued, if the transfers
+	// can't be imported
+	transfers, err := tx.transfers()
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported transfers: %w", err)
+	}
+	amount := uint64(0)
+	for _, transfer := range transfers {
+		if amount, err = math.Add64(amount, transfer.Amount); err != nil {
+			return err
+		}
+	}
+
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
+	if err != nil {
+		return fmt.Errorf("problem while creating transaction: %w", err)
+	}
+
+	response.UnsignedTx.Bytes = txBytes
+	response.Amount = json.Uint64(amount)
+	return nil
+}
+
+// GetExportStatusArgs are the arguments to GetExportStatus
+type GetExportStatusArgs struct {
+	// ID of the export transaction
+	TxID ids.ID `json:"txID"`
+}
+
+// GetExportStatusReply is the reply from calling GetExportStatus
+type GetExportStatusReply struct {
+	// Status of the transfer as a whole: Exporting, Exported, Imported or
+	// Failed
+	Status string `json:"status"`
+
+	// ID of the transfer the destination chain imports
+	TransferID ids.ID `json:"transferID"`
+}
+
+// GetExportStatus returns the status of the transfer made by an export
+// transaction, from the transaction being issued to the destination chain
+// importing the transfer
+func (service *Service) GetExportStatus(_ *http.Request, args *GetExportStatusArgs, reply *GetExportStatusReply) error {
+	service.vm.Ctx.Log.Debug("platform.getExportStatus called")
+
+	if args.TxID.IsZero() {
+		return errInvalidID
+	}
+	transfer := atomic.Transfer{SourceTx: args.TxID}
+	reply.TransferID = transfer.ID()
+
+	tx, err := service.vm.getExportTx(service.vm.DB, args.TxID)
+	if err != nil {
+		// The export hasn't been accepted
+		switch {
+		case service.vm.unissuedDecisionTxs.Has(args.TxID):
+			reply.Status = transferExporting
+		case service.exportPreferred(args.TxID):
+			reply.Status = transferExporting
+		default:
+			if _, dropped := service.vm.droppedTxs.Get(args.TxID); !dropped {
+				return fmt.Errorf("unknown export tx %s", args.TxID)
+			}
+			reply.Status = transferFailed
+		}
+		return nil
+	}
+
+	if service.vm.Ctx.SharedMemory == nil {
+		return errNoSharedMemory
+	}
+	exported, err := service.vm.Ctx.SharedMemory.Exported(tx.DestinationChain, [][]byte{reply.TransferID.Bytes()})
+	if err != nil {
+		return fmt.Errorf("problem retrieving exported transfer: %w", err)
+	}
+	reply.Status = transferImported
+	if exported[0] {
+		reply.Status = transferExported
+	}
+	return nil
+}
+
+// exportPreferred returns true if the export tx [txID] is in the preferred
+// chain, but hasn't been accepted
+func (service *Service) exportPreferred(txID ids.ID) bool {
+	preferred, err := service.vm.getBlock(service.vm.Preferred())
+	if err != nil {
+		return false
+	}
+	block, ok := preferred.(decision)
+	if !ok {
+		return false
+	}
+	_, err = service.vm.getExportTx(block.onAccept(), txID)
+	return err == nil
 }
 
 /*
@@ -855,7 +1536,7 @@ func (service *Service) CreateSubnet(_ *http.Request, args *CreateSubnetArgs, re
 		bytes: nil,
 	}
 
-	txBytes, err := Codec.Marshal(genericTx{Tx: &tx})
+	txBytes, err := Codec.Marshal(codecVersion, genericTx{Tx: &tx})
 	if err != nil {
 		return errCreatingTransaction
 	}
@@ -882,6 +1563,10 @@ type CreateBlockchainArgs struct {
 	// Human-readable name for the new blockchain, not necessarily unique
 	Name string `json:"name"`
 
+	// ID of the subnet that validates the new blockchain. Defaults to the
+	// default subnet.
+	SubnetID ids.ID `json:"subnetID"`
+
 	// To generate the byte representation of the genesis data for this blockchain,
 	// a POST request with body [GenesisData] is made to the API method whose name is [Method], whose
 	// endpoint is [Endpoint]. See Platform Chain documentation for more info and examples.
@@ -902,16 +1587,45 @@ type CreateBlockchainReply struct {
 
 // CreateBlockchain issues a transaction to the network to create a new blockchain
 func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchainArgs, reply *CreateBlockchainReply) error {
+	vmID, fxIDs, genesisBytes, err := service.parseBlockchainSpec(args)
+	if err != nil {
+		return err
+	}
+
+	if args.SubnetID.IsZero() {
+		args.SubnetID = DefaultSubnetID
+	}
+
+	// TODO: Should use the key store to sign this transaction.
+	// TODO: Nonce shouldn't always be 0
+	tx, err := service.vm.newCreateChainTx(0, genesisBytes, vmID, fxIDs, args.Name, args.SubnetID, service.vm.Ctx.NetworkID, key)
+	if err != nil {
+		return fmt.Errorf("problem creating transaction: %w", err)
+	}
+
+	// Add this tx to the set of unissued txs
+	if err := service.vm.issueDecisionTx(tx.ID(), tx); err != nil {
+		return fmt.Errorf("problem issuing transaction: %w", err)
+	}
+
+	reply.BlockchainID = tx.ID()
+
+	return nil
+}
+
+// parseBlockchainSpec returns the IDs of the VM and fxs of the blockchain
+// described by [args], and the bytes of its genesis data
+func (service *Service) parseBlockchainSpec(args *CreateBlockchainArgs) (ids.ID, []ids.ID, []byte, error) {
 	vmID, err := service.vm.ChainManager.LookupVM(args.VMID)
 	if err != nil {
-		return fmt.Errorf("no VM with ID '%s' found", args.VMID)
+		return ids.ID{}, nil, nil, fmt.Errorf("no VM with ID '%s' found", args.VMID)
 	}
 
 	fxIDs := []ids.ID(nil)
 	for _, fxIDStr := range args.FxIDs {
 		fxID, err := service.vm.ChainManager.LookupVM(fxIDStr)
 		if err != nil {
-			return fmt.Errorf("no FX with ID '%s' found", fxIDStr)
+			return ids.ID{}, nil, nil, fmt.Errorf("no FX with ID '%s' found", fxIDStr)
 		}
 		fxIDs = append(fxIDs, fxID)
 	}
@@ -920,7 +1634,7 @@ func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchain
 	if args.Method != "" {
 		buf, err := json2.EncodeClientRequest(args.Method, args.GenesisData)
 		if err != nil {
-			return fmt.Errorf("problem building blockchain genesis state: %w", err)
+			return ids.ID{}, nil, nil, fmt.Errorf("problem building blockchain genesis state: %w", err)
 		}
 
 		writer := httptest.NewRecorder()
@@ -937,26 +1651,53 @@ func (service *Service) CreateBlockchain(_ *http.Request, args *CreateBlockchain
 
 		result := CreateGenesisReply{}
 		if err := json2.DecodeClientResponse(writer.Body, &result); err != nil {
-			return fmt.Errorf("problem building blockchain genesis state: %w", err)
+			return ids.ID{}, nil, nil, fmt.Errorf("problem building blockchain genesis state: %w", err)
 		}
 		genesisBytes = result.Bytes.Bytes
 	} else if args.GenesisData != nil {
-		return errNoMethodWithGenesis
+		return ids.ID{}, nil, nil, errNoMethodWithGenesis
 	}
 
-	// TODO: Should use the key store to sign this transaction.
-	// TODO: Nonce shouldn't always be 0
-	tx, err := service.vm.newCreateChainTx(0, genesisBytes, vmID, fxIDs, args.Name, service.vm.Ctx.NetworkID, key)
+	return vmID, fxIDs, genesisBytes, nil
+}
+
+// ValidateBlockchainSpecReply is the reply from calling ValidateBlockchainSpec
+type ValidateBlockchainSpecReply struct {
+	// ID of the VM the blockchain would run
+	VMID ids.ID `json:"vmID"`
+
+	// IDs of the FXs the VM would run
+	FxIDs []ids.ID `json:"fxIDs"`
+
+	// The genesis data of the blockchain
+	GenesisBytes formatting.CB58 `json:"genesisBytes"`
+
+	// True if the genesis data was checked by the VM. False if the VM can't
+	// check genesis data before the blockchain is created.
+	GenesisVerified bool `json:"genesisVerified"`
+}
+
+// ValidateBlockchainSpec checks that a blockchain could be created with the
+// arguments [args] to CreateBlockchain, without issuing a transaction.
+// If the VM supports it, the genesis data is parsed by the VM.
+func (service *Service) ValidateBlockchainSpec(_ *http.Request, args *CreateBlockchainArgs, reply *ValidateBlockchainSpecReply) error {
+	vmID, fxIDs, genesisBytes, err := service.parseBlockchainSpec(args)
 	if err != nil {
-		return fmt.Errorf("problem creating transaction: %w", err)
+		return err
+	}
+	if !ids.IsSortedAndUniqueIDs(fxIDs) {
+		return errFxIDsNotSortedAndUnique
 	}
 
-	// Add this tx to the set of unissued txs
-	service.vm.unissuedDecisionTxs = append(service.vm.unissuedDecisionTxs, tx)
-	service.vm.resetTimer()
-
-	reply.BlockchainID = tx.ID()
+	verified, err := service.vm.ChainManager.VerifyGenesis(vmID, fxIDs, genesisBytes)
+	if err != nil {
+		return fmt.Errorf("invalid genesis data: %w", err)
+	}
 
+	reply.VMID = vmID
+	reply.FxIDs = fxIDs
+	reply.GenesisBytes.Bytes = genesisBytes
+	reply.GenesisVerified = verified
 	return nil
 }
 
@@ -1001,6 +1742,9 @@ func (service *Service) GetBlockchainStatus(_ *http.Request, args *GetBlockchain
 		return nil
 	}
 
+	if _, dropped := service.vm.droppedTxs.Get(bID); dropped {
+		reply.Status = Dropped
+	}
 	return nil
 }
 
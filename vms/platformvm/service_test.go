@@ -6,6 +6,11 @@ package platformvm
 import (
 	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/formatting"
 )
 
 func TestAddDefaultSubnetValidator(t *testing.T) {
@@ -32,3 +37,171 @@ func TestCreateBlockchainArgsParsing(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestExternalSigning(t *testing.T) {
+	vm := defaultVM()
+	service := Service{vm: vm}
+
+	unsignedReply := RotateValidatorResponse{}
+	err := service.RotateValidator(nil, &RotateValidatorArgs{
+		TxID:       ids.Empty.Prefix(1),
+		NodeID:     ids.NewShortID([20]byte{1, 2, 3}),
+		PayerNonce: defaultNonce + 1,
+	}, &unsignedReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digestReply := GetTxDigestResponse{}
+	if err := service.GetTxDigest(nil, &GetTxDigestArgs{Tx: unsignedReply.UnsignedTx}, &digestReply); err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := keys[0].SignHash(digestReply.Digest.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedReply := AddSignatureResponse{}
+	err = service.AddSignature(nil, &AddSignatureArgs{
+		Tx:        unsignedReply.UnsignedTx,
+		Signature: formatting.CB58{Bytes: sig},
+	}, &signedReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genTx := genericTx{}
+	if _, err := Codec.Unmarshal(signedReply.Tx.Bytes, &genTx); err != nil {
+		t.Fatal(err)
+	}
+	tx, ok := genTx.Tx.(*rotateValidatorTx)
+	if !ok {
+		t.Fatalf("Should have returned a %T but returned a %T", tx, genTx.Tx)
+	}
+	if err := tx.initialize(vm); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SyntacticVerify(); err != nil {
+		t.Fatal(err)
+	}
+	if signer := keys[0].PublicKey().Address(); !tx.senderID.Equals(signer) {
+		t.Fatalf("Tx should have been signed by %s but was signed by %s", signer, tx.senderID)
+	}
+}
+
+func TestRewardQueries(t *testing.T) {
+	vm := defaultVM()
+	service := Service{vm: vm}
+
+	keyIntf1, err := vm.factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key1 := keyIntf1.(*crypto.PrivateKeySECP256K1R)
+
+	keyIntf2, err := vm.factory.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2 := keyIntf2.(*crypto.PrivateKeySECP256K1R)
+
+	vdrTx, err := vm.newAddDefaultSubnetValidatorTx(
+		defaultNonce+1,     // nonce
+		defaultStakeAmount, // stakeAmt
+		uint64(defaultValidateEndTime.Add(-365*24*time.Hour).Unix())-1,
+		uint64(defaultValidateEndTime.Unix())-1,
+		key1.PublicKey().Address(), // node ID
+		key1.PublicKey().Address(), // destination
+		NumberOfShares/4,
+		testNetworkID,
+		key1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delTx, err := vm.newAddDefaultSubnetDelegatorTx(
+		defaultNonce+1,     // nonce
+		defaultStakeAmount, // stakeAmt
+		uint64(defaultValidateEndTime.Add(-365*24*time.Hour).Unix())-1,
+		uint64(defaultValidateEndTime.Unix())-1,
+		key1.PublicKey().Address(), // node ID
+		key2.PublicKey().Address(), // destination
+		testNetworkID,
+		key2,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	currentValidators, err := vm.getCurrentValidators(vm.DB, DefaultSubnetID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentValidators.Add(vdrTx)
+	currentValidators.Add(delTx)
+	if err := vm.putCurrentValidators(vm.DB, currentValidators, DefaultSubnetID); err != nil {
+		t.Fatal(err)
+	}
+
+	estimate := GetRewardEstimateReply{}
+	if err := service.GetRewardEstimate(nil, &GetRewardEstimateArgs{NodeID: vdrTx.NodeID}, &estimate); err != nil {
+		t.Fatal(err)
+	}
+	if uint64(estimate.Reward) != defaultStakeAmount/25 || uint64(estimate.DelegationFees) != defaultStakeAmount/100 || estimate.Delegators != 1 {
+		t.Fatalf("Wrong reward estimate %+v", estimate)
+	}
+	if err := service.GetRewardEstimate(nil, &GetRewardEstimateArgs{NodeID: key2.PublicKey().Address()}, &estimate); err != errNotDSValidator {
+		t.Fatalf("Should have errored because the node isn't a validator but returned %v", err)
+	}
+
+	delegations := GetDelegationsReply{}
+	if err := service.GetDelegations(nil, &GetDelegationsArgs{Address: delTx.Destination}, &delegations); err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations.Delegations) != 1 || uint64(delegations.Delegations[0].PotentialReward) != defaultStakeAmount*3/100 {
+		t.Fatalf("Wrong delegations %+v", delegations.Delegations)
+	}
+
+	// Reward the delegator
+	if err := vm.putTimestamp(vm.DB, defaultValidateEndTime.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	tx, err := vm.newRewardValidatorTx(delTx.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	onCommitDB, _, _, _, err := tx.SemanticVerify(vm.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := onCommitDB.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	payouts := GetRewardPayoutsReply{}
+	if err := service.GetRewardPayouts(nil, &GetRewardPayoutsArgs{Address: delTx.Destination}, &payouts); err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts.Payouts) != 1 {
+		t.Fatalf("Should have indexed 1 payout but indexed %d", len(payouts.Payouts))
+	}
+	if payout := payouts.Payouts[0]; !payout.TxID.Equals(delTx.ID()) || !payout.Delegator || uint64(payout.Reward) != defaultStakeAmount*3/100 {
+		t.Fatalf("Wrong payout %+v", payout)
+	}
+
+	payouts = GetRewardPayoutsReply{}
+	if err := service.GetRewardPayouts(nil, &GetRewardPayoutsArgs{Address: vdrTx.Destination}, &payouts); err != nil {
+		t.Fatal(err)
+	}
+	if len(payouts.Payouts) != 1 || !payouts.Payouts[0].DelegationFee || uint64(payouts.Payouts[0].Reward) != defaultStakeAmount/100 {
+		t.Fatalf("Wrong delegation fee payouts %+v", payouts.Payouts)
+	}
+
+	delegations = GetDelegationsReply{}
+	if err := service.GetDelegations(nil, &GetDelegationsArgs{Address: delTx.Destination}, &delegations); err != nil {
+		t.Fatal(err)
+	}
+	if len(delegations.Delegations) != 0 {
+		t.Fatalf("The rewarded delegation shouldn't be current")
+	}
+}
@@ -4,6 +4,7 @@
 package platformvm
 
 import (
+	"github.com/ava-labs/gecko/chains/atomic"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
@@ -14,12 +15,27 @@ import (
 type DecisionTx interface {
 	initialize(vm *VM) error
 
+	// Bytes returns the binary representation of this transaction
+	Bytes() []byte
+
 	// Attempt to verify this transaction with the provided state. The provided
 	// database can be modified arbitrarily. If a nil error is returned, it is
 	// assumped onAccept is non-nil.
 	SemanticVerify(database.Database) (onAccept func(), err error)
 }
 
+// atomicTx is a DecisionTx that also changes the memory this chain shares with
+// other chains when it's accepted
+type atomicTx interface {
+	DecisionTx
+
+	ID() ids.ID
+
+	// atomicRequests returns the changes to the shared memory, keyed by the
+	// chain they're made to
+	atomicRequests() (map[[32]byte]*atomic.Requests, error)
+}
+
 // StandardBlock being accepted results in the transactions contained in the
 // block to be accepted and committed to the chain.
 type StandardBlock struct {
@@ -68,6 +84,12 @@ func (sb *StandardBlock) Verify() error {
 		}
 	}
 
+	requests, err := sb.atomicRequests()
+	if err != nil {
+		return err
+	}
+	sb.requests = requests
+
 	if numFuncs := len(funcs); numFuncs == 1 {
 		sb.onAcceptFunc = funcs[0]
 	} else if numFuncs > 1 {
@@ -83,6 +105,65 @@ func (sb *StandardBlock) Verify() error {
 	return nil
 }
 
+// atomicRequests returns the changes the txs of [sb] make to the shared memory.
+// The transfers a tx imports are only removed from the shared memory once its
+// block is accepted, so a transfer mustn't also be imported by another tx of
+// [sb] or of a processing ancestor of [sb].
+func (sb *StandardBlock) atomicRequests() (map[[32]byte]*atomic.Requests, error) {
+	imported := map[[32]byte]ids.Set{} // chain ID --> IDs of transfers imported from it
+	for ancestor := Block(sb); ancestor != nil && !ancestor.Status().Decided(); ancestor = ancestor.parentBlock() {
+		if blk, ok := ancestor.(*StandardBlock); ok {
+			for _, tx := range blk.Txs {
+				if tx, ok := tx.(*importTx); ok {
+					chainKey := tx.SourceChain.Key()
+					transferIDs := imported[chainKey]
+					for _, transferID := range tx.TransferIDs {
+						if transferIDs.Contains(transferID) {
+							return nil, errTransferImportedTwice
+						}
+						transferIDs.Add(transferID)
+					}
+					imported[chainKey] = transferIDs
+				}
+			}
+		}
+	}
+
+	requests := map[[32]byte]*atomic.Requests{}
+	for _, tx := range sb.Txs {
+		tx, ok := tx.(atomicTx)
+		if !ok {
+			continue
+		}
+		txRequests, err := tx.atomicRequests()
+		if err != nil {
+			return nil, err
+		}
+		for chainKey, txChainRequests := range txRequests {
+			chainRequests, ok := requests[chainKey]
+			if !ok {
+				chainRequests = &atomic.Requests{}
+				requests[chainKey] = chainRequests
+			}
+			chainRequests.RemoveRequests = append(chainRequests.RemoveRequests, txChainRequests.RemoveRequests...)
+			chainRequests.PutRequests = append(chainRequests.PutRequests, txChainRequests.PutRequests...)
+		}
+	}
+	return requests, nil
+}
+
+// txsVersion returns the codec version a block of [txs] is serialized with,
+// which is the latest version any of them needs
+func txsVersion(txs []DecisionTx) uint16 {
+	version := uint16(codecVersion)
+	for _, tx := range txs {
+		if chain, ok := tx.(*CreateChainTx); ok && chain.version() > version {
+			version = chain.version()
+		}
+	}
+	return version
+}
+
 // newStandardBlock returns a new *StandardBlock where the block's parent, a
 // decision block, has ID [parentID].
 func (vm *VM) newStandardBlock(parentID ids.ID, txs []DecisionTx) (*StandardBlock, error) {
@@ -99,7 +180,7 @@ func (vm *VM) newStandardBlock(parentID ids.ID, txs []DecisionTx) (*StandardBloc
 	// We serialize this block as a Block so that it can be deserialized into a
 	// Block
 	blk := Block(sb)
-	bytes, err := Codec.Marshal(&blk)
+	bytes, err := Codec.Marshal(txsVersion(txs), &blk)
 	if err != nil {
 		return nil, err
 	}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 )
 
@@ -138,14 +139,61 @@ func (vm *VM) getAccount(db database.Database, address ids.ShortID) (Account, er
 }
 
 // put an account in [db]
+// The addresses of the accounts are indexed in the order the accounts were
+// created, so the accounts in [db] can be enumerated
 func (vm *VM) putAccount(db database.Database, account Account) error {
-	err := vm.State.Put(db, accountTypeID, account.Address.LongID(), account)
+	longID := account.Address.LongID()
+	exists, err := vm.State.Has(db, accountTypeID, longID)
 	if err != nil {
 		return errDBPutAccount
 	}
+	if !exists {
+		if err := vm.indexAccount(db, account.Address); err != nil {
+			return errDBPutAccount
+		}
+	}
+	if err := vm.State.Put(db, accountTypeID, longID, account); err != nil {
+		return errDBPutAccount
+	}
 	return nil
 }
 
+// add [address] to the index of the addresses of the accounts in [db]
+func (vm *VM) indexAccount(db database.Database, address ids.ShortID) error {
+	numAccounts, err := vm.getNumAccounts(db)
+	if err != nil {
+		return err
+	}
+	if err := vm.State.PutID(db, accountsKey.Prefix(numAccounts), address.LongID()); err != nil {
+		return err
+	}
+	return vm.State.PutUint64(db, numAccountsKey, numAccounts+1)
+}
+
+// get the number of accounts indexed in [db]
+func (vm *VM) getNumAccounts(db database.Database) (uint64, error) {
+	numAccounts, err := vm.State.GetUint64(db, numAccountsKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return numAccounts, err
+}
+
+// get the address of the [index]th account indexed in [db]
+func (vm *VM) getAccountAddress(db database.Database, index uint64) (ids.ShortID, error) {
+	longID, err := vm.State.GetID(db, accountsKey.Prefix(index))
+	if err != nil {
+		return ids.ShortID{}, err
+	}
+	return ids.ToShortID(longID.Bytes()[:20])
+}
+
+// returns true if every account in [db] is indexed, which is only the case for
+// the databases whose chain state was initialized with the index
+func (vm *VM) accountIndexComplete(db database.Database) bool {
+	return vm.State.GetStatus(db, accountIndexCompleteKey) == choices.Accepted
+}
+
 // get the blockchains that exist
 func (vm *VM) getChains(db database.Database) ([]*CreateChainTx, error) {
 	chainsInterface, err := vm.State.Get(db, chainsTypeID, chainsKey)
@@ -225,12 +273,32 @@ func (vm *VM) getSubnet(db database.Database, ID ids.ID) (*CreateSubnetTx, error
 	return nil, fmt.Errorf("couldn't find subnet with ID %s", ID)
 }
 
+// put [tx], an export tx that was accepted, in [db], so the status of the
+// transfer it exports can be looked up
+func (vm *VM) putExportTx(db database.Database, tx *exportTx) error {
+	return vm.State.Put(db, exportTxTypeID, tx.ID(), tx)
+}
+
+// get the export tx with ID [txID] from [db]
+func (vm *VM) getExportTx(db database.Database, txID ids.ID) (*exportTx, error) {
+	txIntf, err := vm.State.Get(db, exportTxTypeID, txID)
+	if err != nil {
+		return nil, err
+	}
+	tx, ok := txIntf.(*exportTx)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve *exportTx from database but got different type")
+		return nil, errDB
+	}
+	return tx, nil
+}
+
 // register each type that we'll be storing in the database
 // so that [vm.State] knows how to unmarshal these types from bytes
 func (vm *VM) registerDBTypes() {
 	unmarshalValidatorsFunc := func(bytes []byte) (interface{}, error) {
 		stakers := EventHeap{}
-		if err := Codec.Unmarshal(bytes, &stakers); err != nil {
+		if _, err := Codec.Unmarshal(bytes, &stakers); err != nil {
 			return nil, err
 		}
 		for _, tx := range stakers.Txs {
@@ -246,7 +314,7 @@ func (vm *VM) registerDBTypes() {
 
 	unmarshalAccountFunc := func(bytes []byte) (interface{}, error) {
 		var account Account
-		if err := Codec.Unmarshal(bytes, &account); err != nil {
+		if _, err := Codec.Unmarshal(bytes, &account); err != nil {
 			return nil, err
 		}
 		return account, nil
@@ -257,7 +325,7 @@ func (vm *VM) registerDBTypes() {
 
 	unmarshalChainsFunc := func(bytes []byte) (interface{}, error) {
 		var chains []*CreateChainTx
-		if err := Codec.Unmarshal(bytes, &chains); err != nil {
+		if _, err := Codec.Unmarshal(bytes, &chains); err != nil {
 			return nil, err
 		}
 		for _, chain := range chains {
@@ -273,7 +341,7 @@ func (vm *VM) registerDBTypes() {
 
 	unmarshalSubnetsFunc := func(bytes []byte) (interface{}, error) {
 		var subnets []*CreateSubnetTx
-		if err := Codec.Unmarshal(bytes, &subnets); err != nil {
+		if _, err := Codec.Unmarshal(bytes, &subnets); err != nil {
 			return nil, err
 		}
 		for _, subnet := range subnets {
@@ -286,6 +354,44 @@ func (vm *VM) registerDBTypes() {
 	if err := vm.State.RegisterType(subnetsTypeID, unmarshalSubnetsFunc); err != nil {
 		vm.Ctx.Log.Warn(errRegisteringType.Error())
 	}
+
+	unmarshalRewardsFunc := func(bytes []byte) (interface{}, error) {
+		var payouts []rewardPayout
+		if _, err := Codec.Unmarshal(bytes, &payouts); err != nil {
+			return nil, err
+		}
+		return payouts, nil
+	}
+	if err := vm.State.RegisterType(rewardsTypeID, unmarshalRewardsFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
+
+	unmarshalStateChunkFunc := func(bytes []byte) (interface{}, error) { return bytes, nil }
+	if err := vm.State.RegisterType(stateChunkTypeID, unmarshalStateChunkFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
+
+	unmarshalStateSummariesFunc := func(bytes []byte) (interface{}, error) {
+		var summaries [][]byte
+		if _, err := Codec.Unmarshal(bytes, &summaries); err != nil {
+			return nil, err
+		}
+		return summaries, nil
+	}
+	if err := vm.State.RegisterType(stateSummariesTypeID, unmarshalStateSummariesFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
+
+	unmarshalExportTxFunc := func(bytes []byte) (interface{}, error) {
+		tx := &exportTx{}
+		if _, err := Codec.Unmarshal(bytes, tx); err != nil {
+			return nil, err
+		}
+		return tx, tx.initialize(vm)
+	}
+	if err := vm.State.RegisterType(exportTxTypeID, unmarshalExportTxFunc); err != nil {
+		vm.Ctx.Log.Warn(errRegisteringType.Error())
+	}
 }
 
 // Unmarshal a Block from bytes and initialize it
@@ -299,7 +405,7 @@ func (vm *VM) registerDBTypes() {
 func (vm *VM) unmarshalBlockFunc(bytes []byte) (snowman.Block, error) {
 	// Parse the serialized fields from bytes into a new block
 	var block Block
-	if err := Codec.Unmarshal(bytes, &block); err != nil {
+	if _, err := Codec.Unmarshal(bytes, &block); err != nil {
 		return nil, err
 	}
 	// Populate the un-serialized fields of the block
@@ -0,0 +1,359 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/versiondb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/vms/components/state"
+
+	smeng "github.com/ava-labs/gecko/snow/engine/snowman"
+)
+
+const (
+	// stateSummaryInterval is the number of heights between the heights the
+	// chain state is summarized at
+	stateSummaryInterval = 1024
+
+	// maxStateSummaries is the number of the most recent summaries whose
+	// chunks are served to the nodes that state sync
+	maxStateSummaries = 2
+
+	// stateChunkSize is the size, in bytes, at which a chunk of the chain state
+	// is closed
+	stateChunkSize = 1 << 20
+)
+
+var (
+	errSyncedPastGenesis  = errors.New("blocks past genesis were already accepted")
+	errWrongSummaryBlock  = errors.New("the state doesn't hold the block it was summarized at")
+	errUnexpectedStateKey = errors.New("the state holds keys that aren't part of the chain state")
+)
+
+// stateBytes is a chunk of the chain state that can be stored in the state
+type stateBytes []byte
+
+// Bytes returns the chunk
+func (b stateBytes) Bytes() []byte { return b }
+
+// stateSummaryList is a list of state summaries that can be stored in the
+// state
+type stateSummaryList [][]byte
+
+// Bytes returns the byte representation of the summaries
+func (summaries stateSummaryList) Bytes() []byte {
+	bytes, _ := Codec.Marshal(codecVersion, [][]byte(summaries))
+	return bytes
+}
+
+// StateSummaries implements the snowman.StateSyncableVM interface
+func (vm *VM) StateSummaries() ([]*smeng.StateSummary, error) {
+	return vm.getStateSummaries(vm.DB)
+}
+
+// GetStateChunk implements the snowman.StateSyncableVM interface
+func (vm *VM) GetStateChunk(chunkID ids.ID) ([]byte, error) {
+	chunkIntf, err := vm.State.Get(vm.DB, stateChunkTypeID, chunkID)
+	if err != nil {
+		return nil, err
+	}
+	chunk, ok := chunkIntf.([]byte)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve []byte from database but got different type")
+		return nil, errDB
+	}
+	return chunk, nil
+}
+
+// SyncState implements the snowman.StateSyncableVM interface.
+//
+// The chunks are written through a versiondb, and are only committed if they
+// hold exactly the chain state at the block they were summarized at. The
+// chains created after genesis are created once the state is committed.
+func (vm *VM) SyncState(summary *smeng.StateSummary, chunks [][]byte) error {
+	if _, err := vm.GetBlockIDAtHeight(1); err == nil {
+		return errSyncedPastGenesis
+	}
+	genesisChains, err := vm.getChains(vm.DB)
+	if err != nil {
+		return err
+	}
+
+	db := versiondb.New(vm.DB)
+	written := make(map[string]struct{})
+	for _, chunk := range chunks {
+		p := wrappers.Packer{Bytes: chunk}
+		for p.Offset < len(chunk) && !p.Errored() {
+			key := p.UnpackBytes()
+			value := p.UnpackBytes()
+			if p.Errored() {
+				break
+			}
+			if err := db.Put(key, value); err != nil {
+				return err
+			}
+			written[string(key)] = struct{}{}
+		}
+		if p.Errored() {
+			return p.Err
+		}
+	}
+
+	blk, err := vm.State.GetBlock(db, summary.BlockID)
+	if err != nil {
+		return err
+	}
+	if !blk.ID().Equals(summary.BlockID) {
+		return errWrongSummaryBlock
+	}
+	keys, err := vm.chainStateKeys(db, summary.BlockID)
+	if err != nil {
+		return err
+	}
+	if len(keys) != len(written) {
+		return errUnexpectedStateKey
+	}
+	for _, key := range keys {
+		if _, ok := written[string(key)]; !ok {
+			return errUnexpectedStateKey
+		}
+	}
+	if _, err := vm.getTimestamp(db); err != nil {
+		return err
+	}
+	chains, err := vm.getChains(db)
+	if err != nil {
+		return err
+	}
+
+	if err := vm.State.PutStatus(db, accountIndexCompleteKey, choices.Accepted); err != nil {
+		return err
+	}
+	if err := vm.SetLastAccepted(db, summary.BlockID, summary.Height); err != nil {
+		return err
+	}
+	if err := db.Commit(); err != nil {
+		return err
+	}
+	if err := vm.DB.Commit(); err != nil {
+		return err
+	}
+
+	// The blocks that were processing built on genesis
+	vm.currentBlocks = make(map[[32]byte]Block)
+
+	subnets, err := vm.getSubnets(vm.DB)
+	if err != nil {
+		return err
+	}
+	for _, subnet := range subnets {
+		if err := vm.updateValidators(subnet.ID); err != nil {
+			vm.Ctx.Log.Debug("failed to update the validators of subnet %s: %s", subnet.ID, err)
+		}
+	}
+	if err := vm.updateValidators(DefaultSubnetID); err != nil {
+		return err
+	}
+
+	created := ids.Set{}
+	for _, chain := range genesisChains {
+		created.Add(chain.ID())
+	}
+	for _, chain := range chains {
+		if !created.Contains(chain.ID()) && vm.ChainManager != nil {
+			vm.createChain(chain)
+		}
+	}
+
+	vm.resetTimer()
+	return nil
+}
+
+// summarizeState summarizes the chain state at the block [blkID], which was
+// just accepted, if it's at a height the state is summarized at. The chunks
+// of the summary are stored so they can be served to the nodes that state
+// sync.
+func (vm *VM) summarizeState(blkID ids.ID) error {
+	// The chain state can't be enumerated if accounts were created before
+	// they were indexed
+	if !vm.accountIndexComplete(vm.DB) {
+		return nil
+	}
+	height, err := vm.State.GetLastAcceptedHeight(vm.DB)
+	if err != nil || height%stateSummaryInterval != 0 {
+		return nil // Blocks accepted before heights were indexed aren't summarized
+	}
+
+	keys, err := vm.chainStateKeys(vm.DB, blkID)
+	if err != nil {
+		return err
+	}
+	chunks, err := vm.chunkState(vm.DB, keys)
+	if err != nil {
+		return err
+	}
+	chunkIDs := make([]ids.ID, len(chunks))
+	for i, chunk := range chunks {
+		chunkIDs[i] = ids.NewID(hashing.ComputeHash256Array(chunk))
+		if err := vm.State.Put(vm.DB, stateChunkTypeID, chunkIDs[i], stateBytes(chunk)); err != nil {
+			return err
+		}
+	}
+	summary, err := smeng.NewStateSummary(height, blkID, chunkIDs)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := vm.getStateSummaries(vm.DB)
+	if err != nil {
+		return err
+	}
+	summaries = append(summaries, summary)
+	if dropped := len(summaries) - maxStateSummaries; dropped > 0 {
+		// Chunks that are still part of a served summary are kept
+		served := ids.Set{}
+		for _, kept := range summaries[dropped:] {
+			served.Add(kept.ChunkIDs...)
+		}
+		for _, old := range summaries[:dropped] {
+			for _, chunkID := range old.ChunkIDs {
+				if served.Contains(chunkID) {
+					continue
+				}
+				if err := vm.State.Put(vm.DB, stateChunkTypeID, chunkID, nil); err != nil {
+					return err
+				}
+			}
+		}
+		summaries = summaries[dropped:]
+	}
+	if err := vm.putStateSummaries(vm.DB, summaries); err != nil {
+		return err
+	}
+
+	vm.Ctx.Log.Debug("summarized the chain state at block %s, at height %d, into %d chunks", blkID, height, len(chunks))
+	return vm.DB.Commit()
+}
+
+// chainStateKeys returns the keys of [db] that hold the chain state at the
+// accepted block [blkID]. These are the keys a block's verification may read,
+// and the keys of the block itself.
+func (vm *VM) chainStateKeys(db database.Database, blkID ids.ID) ([][]byte, error) {
+	keys := [][]byte{
+		vm.State.DBKey(state.BlockTypeID, blkID),
+		vm.State.DBKey(state.StatusTypeID, blkID),
+		vm.State.DBKey(state.TimeTypeID, timestampKey),
+		vm.State.DBKey(chainsTypeID, chainsKey),
+		vm.State.DBKey(subnetsTypeID, subnetsKey),
+	}
+
+	subnets, err := vm.getSubnets(db)
+	if err != nil {
+		return nil, err
+	}
+	subnetIDs := []ids.ID{DefaultSubnetID}
+	for _, subnet := range subnets {
+		subnetIDs = append(subnetIDs, subnet.ID)
+	}
+	for _, subnetID := range subnetIDs {
+		for _, prefix := range []uint64{currentValidatorsPrefix, pendingValidatorsPrefix} {
+			key := subnetID.Prefix(prefix)
+			has, err := vm.State.Has(db, validatorsTypeID, key)
+			if err != nil {
+				return nil, err
+			}
+			if has {
+				keys = append(keys, vm.State.DBKey(validatorsTypeID, key))
+			}
+		}
+	}
+
+	numAccounts, err := vm.getNumAccounts(db)
+	if err != nil {
+		return nil, err
+	}
+	if numAccounts > 0 {
+		keys = append(keys, vm.State.DBKey(state.Uint64TypeID, numAccountsKey))
+	}
+	for i := uint64(0); i < numAccounts; i++ {
+		address, err := vm.getAccountAddress(db, i)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys,
+			vm.State.DBKey(state.IDTypeID, accountsKey.Prefix(i)),
+			vm.State.DBKey(accountTypeID, address.LongID()),
+		)
+	}
+	return keys, nil
+}
+
+// chunkState splits the key/value pairs of [db] whose keys are [keys] into
+// chunks, in key order
+func (vm *VM) chunkState(db database.Database, keys [][]byte) ([][]byte, error) {
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	chunks := [][]byte(nil)
+	p := wrappers.Packer{MaxSize: 2 * stateChunkSize}
+	for _, key := range keys {
+		value, err := db.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		p.PackBytes(key)
+		p.PackBytes(value)
+		if p.Errored() {
+			return nil, p.Err
+		}
+		if p.Offset >= stateChunkSize {
+			chunks = append(chunks, p.Bytes)
+			p = wrappers.Packer{MaxSize: 2 * stateChunkSize}
+		}
+	}
+	if p.Offset > 0 {
+		chunks = append(chunks, p.Bytes)
+	}
+	return chunks, nil
+}
+
+// get the summaries of the chain state this node serves, oldest first
+func (vm *VM) getStateSummaries(db database.Database) ([]*smeng.StateSummary, error) {
+	has, err := vm.State.Has(db, stateSummariesTypeID, stateSummariesKey)
+	if err != nil || !has {
+		return nil, err
+	}
+	summariesIntf, err := vm.State.Get(db, stateSummariesTypeID, stateSummariesKey)
+	if err != nil {
+		return nil, err
+	}
+	summaryBytes, ok := summariesIntf.([][]byte)
+	if !ok {
+		vm.Ctx.Log.Warn("expected to retrieve [][]byte from database but got different type")
+		return nil, errDB
+	}
+	summaries := make([]*smeng.StateSummary, len(summaryBytes))
+	for i, b := range summaryBytes {
+		if summaries[i], err = smeng.ParseStateSummary(b); err != nil {
+			return nil, err
+		}
+	}
+	return summaries, nil
+}
+
+// put the summaries of the chain state this node serves
+func (vm *VM) putStateSummaries(db database.Database, summaries []*smeng.StateSummary) error {
+	summaryBytes := make(stateSummaryList, len(summaries))
+	for i, summary := range summaries {
+		summaryBytes[i] = summary.Bytes()
+	}
+	return vm.State.Put(db, stateSummariesTypeID, stateSummariesKey, summaryBytes)
+}
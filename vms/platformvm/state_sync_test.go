@@ -0,0 +1,127 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/wrappers"
+
+	smeng "github.com/ava-labs/gecko/snow/engine/snowman"
+)
+
+// summarize the chain state of [vm] as if its last accepted block was at the
+// first height the state is summarized at, and return the summary and chunks
+func testSummary(t *testing.T, vm *VM) (*smeng.StateSummary, [][]byte) {
+	if err := vm.State.PutLastAcceptedHeight(vm.DB, stateSummaryInterval); err != nil {
+		t.Fatal(err)
+	}
+	if err := vm.summarizeState(vm.LastAccepted()); err != nil {
+		t.Fatal(err)
+	}
+	summaries, err := vm.StateSummaries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("Should have summarized the state once but has %d summaries", len(summaries))
+	}
+	summary := summaries[0]
+	chunks := make([][]byte, len(summary.ChunkIDs))
+	for i, chunkID := range summary.ChunkIDs {
+		if chunks[i], err = vm.GetStateChunk(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return summary, chunks
+}
+
+func TestSyncState(t *testing.T) {
+	vm := defaultVM()
+	vm.Ctx.Lock.Lock()
+	defer func() {
+		vm.Shutdown()
+		vm.Ctx.Lock.Unlock()
+	}()
+
+	// Change the chain state from genesis
+	newAddress := ids.NewShortID([20]byte{1, 2, 3})
+	if err := vm.putAccount(vm.DB, newAccount(newAddress, 1, 5)); err != nil {
+		t.Fatal(err)
+	}
+	timestamp := defaultGenesisTime.Add(time.Hour)
+	if err := vm.putTimestamp(vm.DB, timestamp); err != nil {
+		t.Fatal(err)
+	}
+	summary, chunks := testSummary(t, vm)
+	if summary.Height != stateSummaryInterval || !summary.BlockID.Equals(vm.LastAccepted()) {
+		t.Fatalf("Summarized the state at the wrong block")
+	}
+
+	syncVM := defaultVM()
+	syncVM.Ctx.Lock.Lock()
+	defer func() {
+		syncVM.Shutdown()
+		syncVM.Ctx.Lock.Unlock()
+	}()
+	if err := syncVM.SyncState(summary, chunks); err != nil {
+		t.Fatal(err)
+	}
+
+	if !syncVM.LastAccepted().Equals(summary.BlockID) {
+		t.Fatalf("Should have accepted the synced block")
+	}
+	if blkID, err := syncVM.GetBlockIDAtHeight(summary.Height); err != nil {
+		t.Fatal(err)
+	} else if !blkID.Equals(summary.BlockID) {
+		t.Fatalf("Should have indexed the synced block at its height")
+	}
+	if account, err := syncVM.getAccount(syncVM.DB, newAddress); err != nil {
+		t.Fatal(err)
+	} else if account.Balance != 5 || account.Nonce != 1 {
+		t.Fatalf("Synced the wrong account %+v", account)
+	}
+	if synced, err := syncVM.getTimestamp(syncVM.DB); err != nil {
+		t.Fatal(err)
+	} else if !synced.Equal(timestamp) {
+		t.Fatalf("Synced timestamp %s but should have synced %s", synced, timestamp)
+	}
+	if !syncVM.accountIndexComplete(syncVM.DB) {
+		t.Fatalf("The synced accounts should be indexed")
+	}
+}
+
+func TestSyncStateUnexpectedKey(t *testing.T) {
+	vm := defaultVM()
+	vm.Ctx.Lock.Lock()
+	defer func() {
+		vm.Shutdown()
+		vm.Ctx.Lock.Unlock()
+	}()
+	summary, chunks := testSummary(t, vm)
+
+	// A summary that also overwrites a key outside of the chain state
+	p := wrappers.Packer{MaxSize: stateChunkSize}
+	p.PackBytes(stateSummariesKey.Bytes())
+	p.PackBytes([]byte{1})
+	chunks = append(chunks, p.Bytes)
+
+	syncVM := defaultVM()
+	syncVM.Ctx.Lock.Lock()
+	defer func() {
+		syncVM.Shutdown()
+		syncVM.Ctx.Lock.Unlock()
+	}()
+	if err := syncVM.SyncState(summary, chunks); err != errUnexpectedStateKey {
+		t.Fatalf("Should have failed with %s but failed with %v", errUnexpectedStateKey, err)
+	}
+	if !syncVM.LastAccepted().Equals(vm.LastAccepted()) {
+		t.Fatalf("Shouldn't have changed the last accepted block")
+	}
+	if height, err := syncVM.State.GetLastAcceptedHeight(syncVM.DB); err == nil && height != 0 {
+		t.Fatalf("Shouldn't have committed the synced state")
+	}
+}
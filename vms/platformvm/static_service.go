@@ -90,7 +90,7 @@ type APIChain struct {
 // [Chains] are the chains that exist at genesis.
 // [Time] is the Platform Chain's time at network genesis.
 type BuildGenesisArgs struct {
-	NetworkID  json.Uint32                 `json:"address"`
+	NetworkID  json.Uint32                 `json:"networkID"`
 	Accounts   []APIAccount                `json:"accounts"`
 	Validators []APIDefaultSubnetValidator `json:"defaultSubnetValidators"`
 	Chains     []APIChain                  `json:"chains"`
@@ -204,7 +204,7 @@ func (*StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, repl
 		Timestamp:  uint64(args.Time),
 	}
 	// Marshal genesis to bytes
-	bytes, err := Codec.Marshal(genesis)
+	bytes, err := Codec.Marshal(codecVersion, genesis)
 	reply.Bytes.Bytes = bytes
 	return err
 }
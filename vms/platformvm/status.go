@@ -19,11 +19,13 @@ type Status uint32
 // [Preferred] means the operation is known and preferred, but hasn't been decided yet
 // [Created] means the operation occurred, but isn't managed locally
 // [Validating] means the operation was accepted and is managed locally
+// [Dropped] means the operation was dropped before it was put into a block
 const (
 	Unknown Status = iota
 	Preferred
 	Created
 	Validating
+	Dropped
 )
 
 // MarshalJSON ...
@@ -49,6 +51,8 @@ func (s *Status) UnmarshalJSON(b []byte) error {
 		*s = Created
 	case "\"Validating\"":
 		*s = Validating
+	case "\"Dropped\"":
+		*s = Dropped
 	default:
 		return errUnknownStatus
 	}
@@ -58,7 +62,7 @@ func (s *Status) UnmarshalJSON(b []byte) error {
 // Valid returns nil if the status is a valid status.
 func (s Status) Valid() error {
 	switch s {
-	case Unknown, Preferred, Created, Validating:
+	case Unknown, Preferred, Created, Validating, Dropped:
 		return nil
 	default:
 		return errUnknownStatus
@@ -75,6 +79,8 @@ func (s Status) String() string {
 		return "Created"
 	case Validating:
 		return "Validating"
+	case Dropped:
+		return "Dropped"
 	default:
 		return "Invalid status"
 	}
@@ -17,6 +17,8 @@ func TestStatusValid(t *testing.T) {
 		t.Fatalf("%s failed verification", Preferred)
 	} else if err := Unknown.Valid(); err != nil {
 		t.Fatalf("%s failed verification", Unknown)
+	} else if err := Dropped.Valid(); err != nil {
+		t.Fatalf("%s failed verification", Dropped)
 	} else if badStatus := Status(math.MaxInt32); badStatus.Valid() == nil {
 		t.Fatalf("%s passed verification", badStatus)
 	}
@@ -31,6 +33,8 @@ func TestStatusString(t *testing.T) {
 		t.Fatalf("%s failed printing", Preferred)
 	} else if Unknown.String() != "Unknown" {
 		t.Fatalf("%s failed printing", Unknown)
+	} else if Dropped.String() != "Dropped" {
+		t.Fatalf("%s failed printing", Dropped)
 	} else if badStatus := Status(math.MaxInt32); badStatus.String() != "Invalid status" {
 		t.Fatalf("%s failed printing", badStatus)
 	}
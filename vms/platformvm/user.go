@@ -42,7 +42,7 @@ func (u *user) getAccountIDs() ([]ids.ShortID, error) {
 		return nil, errDB
 	}
 	accountIDs := []ids.ShortID{}
-	if err := Codec.Unmarshal(bytes, &accountIDs); err != nil {
+	if _, err := Codec.Unmarshal(bytes, &accountIDs); err != nil {
 		return nil, err
 	}
 	return accountIDs, nil
@@ -89,7 +89,7 @@ func (u *user) putAccount(privKey *crypto.PrivateKeySECP256K1R) error {
 		}
 	}
 	accountIDs = append(accountIDs, newAccountID)
-	bytes, err := Codec.Marshal(accountIDs)
+	bytes, err := Codec.Marshal(codecVersion, accountIDs)
 	if err != nil {
 		return err
 	}
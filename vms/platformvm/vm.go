@@ -11,13 +11,16 @@ import (
 
 	stdmath "math"
 
+	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/chains"
 	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/versiondb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/snow/consensus/snowman"
 	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/snow/uptime"
 	"github.com/ava-labs/gecko/snow/validators"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/math"
@@ -26,6 +29,7 @@ import (
 	"github.com/ava-labs/gecko/utils/wrappers"
 	"github.com/ava-labs/gecko/vms/components/codec"
 	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 )
 
 const (
@@ -35,6 +39,10 @@ const (
 	chainsTypeID
 	blockTypeID
 	subnetsTypeID
+	rewardsTypeID
+	stateChunkTypeID
+	stateSummariesTypeID
+	exportTxTypeID
 
 	// Delta is the synchrony bound used for safe decision making
 	Delta = 10 * time.Second // TODO change to longer period (2 minutes?) before release
@@ -58,9 +66,18 @@ const (
 	// their funds for.
 	MaximumStakingDuration = 365 * 24 * time.Hour
 
+	// version of this VM. Changes whenever the rules of the VM change.
+	version = "platformvm/0.1.0"
+
 	// NumberOfShares is the number of shares that a delegator is
 	// rewarded
 	NumberOfShares = 1000000
+
+	// The number of public keys recovered from signatures to cache
+	recoverCacheSize = 2048
+
+	// The number of IDs of dropped decision txs to remember
+	droppedTxsCacheSize = 2048
 )
 
 var (
@@ -75,6 +92,15 @@ var (
 	pendingValidatorsKey = ids.NewID([32]byte{'p', 'e', 'n', 'd', 'i', 'n', 'g'})
 	chainsKey            = ids.NewID([32]byte{'c', 'h', 'a', 'i', 'n', 's'})
 	subnetsKey           = ids.NewID([32]byte{'s', 'u', 'b', 'n', 'e', 't', 's'})
+
+	// state.GetUint64(db, numAccountsKey) == number of accounts in the index
+	numAccountsKey = ids.NewID([32]byte{'n', 'u', 'm', 'a', 'c', 'c', 'o', 'u', 'n', 't', 's'})
+	// state.GetID(db, accountsKey.Prefix(i)) == address of the [i]th account
+	accountsKey = ids.NewID([32]byte{'a', 'c', 'c', 'o', 'u', 'n', 't', 's'})
+	// Accepted iff every account is in the index
+	accountIndexCompleteKey = ids.NewID([32]byte{'a', 'c', 'c', 'o', 'u', 'n', 't', 'i', 'n', 'd', 'e', 'x'})
+	// The summaries of the chain state this node serves
+	stateSummariesKey = ids.NewID([32]byte{'s', 'u', 'm', 'm', 'a', 'r', 'i', 'e', 's'})
 )
 
 var (
@@ -96,40 +122,73 @@ var (
 	errMissingBlock           = errors.New("missing block")
 )
 
-// Codec does serialization and deserialization
-var Codec codec.Codec
+// codecVersion is the version of the wire format this VM serializes with. A
+// later version is registered alongside the earlier ones, so bytes persisted
+// by an earlier version can still be parsed.
+const codecVersion = 0
+
+// subnetCodecVersion is the version of the wire format that serializes the
+// subnet of a chain. Only the chains of subnets other than the default subnet
+// are serialized with it, so the bytes and IDs of the other chains, and of the
+// genesis, are the same as before.
+const subnetCodecVersion = 1
+
+// Codec does serialization and deserialization. Version 0 bytes aren't
+// prefixed, so they're the same as the bytes serialized before the codec was
+// versioned. Bytes of later versions are prefixed by their version.
+var Codec codec.Manager
 
 func init() {
-	Codec = codec.NewDefault()
+	Codec = codec.NewLegacyManager()
 
 	errs := wrappers.Errs{}
+	for _, version := range []uint16{codecVersion, subnetCodecVersion} {
+		registerCodec(&errs, version)
+	}
+	if errs.Errored() {
+		panic(errs.Err)
+	}
+}
+
+// registerCodec registers the codec of [version] with Codec
+func registerCodec(errs *wrappers.Errs, version uint16) {
+	c := codec.NewVersioned(version, codec.DefaultLimits())
 	errs.Add(
-		Codec.RegisterType(&ProposalBlock{}),
-		Codec.RegisterType(&Abort{}),
-		Codec.RegisterType(&Commit{}),
-		Codec.RegisterType(&StandardBlock{}),
+		c.RegisterType(&ProposalBlock{}),
+		c.RegisterType(&Abort{}),
+		c.RegisterType(&Commit{}),
+		c.RegisterType(&StandardBlock{}),
+
+		c.RegisterType(&UnsignedAddDefaultSubnetValidatorTx{}),
+		c.RegisterType(&addDefaultSubnetValidatorTx{}),
+
+		c.RegisterType(&UnsignedAddNonDefaultSubnetValidatorTx{}),
+		c.RegisterType(&addNonDefaultSubnetValidatorTx{}),
 
-		Codec.RegisterType(&UnsignedAddDefaultSubnetValidatorTx{}),
-		Codec.RegisterType(&addDefaultSubnetValidatorTx{}),
+		c.RegisterType(&UnsignedAddDefaultSubnetDelegatorTx{}),
+		c.RegisterType(&addDefaultSubnetDelegatorTx{}),
 
-		Codec.RegisterType(&UnsignedAddNonDefaultSubnetValidatorTx{}),
-		Codec.RegisterType(&addNonDefaultSubnetValidatorTx{}),
+		c.RegisterType(&UnsignedCreateChainTx{}),
+		c.RegisterType(&CreateChainTx{}),
 
-		Codec.RegisterType(&UnsignedAddDefaultSubnetDelegatorTx{}),
-		Codec.RegisterType(&addDefaultSubnetDelegatorTx{}),
+		c.RegisterType(&UnsignedCreateSubnetTx{}),
+		c.RegisterType(&CreateSubnetTx{}),
 
-		Codec.RegisterType(&UnsignedCreateChainTx{}),
-		Codec.RegisterType(&CreateChainTx{}),
+		c.RegisterType(&advanceTimeTx{}),
+		c.RegisterType(&rewardValidatorTx{}),
 
-		Codec.RegisterType(&UnsignedCreateSubnetTx{}),
-		Codec.RegisterType(&CreateSubnetTx{}),
+		c.RegisterType(&UnsignedRotateValidatorTx{}),
+		c.RegisterType(&rotateValidatorTx{}),
+		c.RegisterType(&rotatedValidatorTx{}),
 
-		Codec.RegisterType(&advanceTimeTx{}),
-		Codec.RegisterType(&rewardValidatorTx{}),
+		c.RegisterType(&UnsignedExportTx{}),
+		c.RegisterType(&exportTx{}),
+
+		c.RegisterType(&UnsignedImportTx{}),
+		c.RegisterType(&importTx{}),
+
+		Codec.RegisterCodec(version, c),
 	)
-	if errs.Errored() {
-		panic(errs.Err)
-	}
 }
 
 // VM implements the snowman.ChainVM interface
@@ -138,9 +197,21 @@ type VM struct {
 
 	Validators validators.Manager
 
+	// Limits on the decision transactions that have not been put into blocks
+	MempoolConfig mempool.Config
+
 	// The node's chain manager
 	ChainManager chains.Manager
 
+	// Records the uptime and responsiveness of validators observed by this
+	// node. May be nil.
+	UptimeTracker *uptime.Tracker
+
+	// If positive, a staker is only preferred to be rewarded if this node
+	// measured its validator's uptime during the staking period to be at least
+	// this fraction
+	UptimeRewardThreshold float64
+
 	// Used to create and use keys.
 	factory crypto.FactorySECP256K1R
 
@@ -153,7 +224,14 @@ type VM struct {
 
 	// Transactions that have not been put into blocks yet
 	unissuedEvents      *EventHeap
-	unissuedDecisionTxs []DecisionTx
+	unissuedDecisionTxs mempool.Mempool
+
+	// IDs of decision txs that were evicted from [unissuedDecisionTxs]
+	droppedTxs cache.LRU
+
+	// Key: ID of a tx issued with IssueTx
+	// Value: function to call when the tx is decided
+	onDecide map[[32]byte]func(choices.Status)
 
 	// This timer goes off when it is time for the next validator to add/leave the validator set
 	// When it goes off resetTimer() is called, triggering creation of a new block
@@ -181,6 +259,8 @@ func (vm *VM) Initialize(
 		return err
 	}
 
+	vm.factory.Cache.Size = recoverCacheSize
+
 	// Register this VM's types with the database so we can get/put structs to/from it
 	vm.registerDBTypes()
 
@@ -188,13 +268,18 @@ func (vm *VM) Initialize(
 	// the provided genesis state
 	if !vm.DBInitialized() {
 		genesis := &Genesis{}
-		if err := Codec.Unmarshal(genesisBytes, genesis); err != nil {
+		if _, err := Codec.Unmarshal(genesisBytes, genesis); err != nil {
 			return err
 		}
 		if err := genesis.Initialize(); err != nil {
 			return err
 		}
 
+		// The accounts are indexed from genesis on
+		if err := vm.State.PutStatus(vm.DB, accountIndexCompleteKey, choices.Accepted); err != nil {
+			return errDB
+		}
+
 		// Persist accounts that exist at genesis
 		for _, account := range genesis.Accounts {
 			if err := vm.putAccount(vm.DB, account); err != nil {
@@ -255,6 +340,10 @@ func (vm *VM) Initialize(
 	// Transactions from clients that have not yet been put into blocks
 	// and added to consensus
 	vm.unissuedEvents = &EventHeap{SortByStartTime: true}
+	vm.onDecide = make(map[[32]byte]func(choices.Status))
+	mempoolConfig := vm.MempoolConfig.ChainConfig(ctx.ChainID, ctx.BCLookup.Lookup)
+	vm.unissuedDecisionTxs.Initialize(ctx.Log, mempoolConfig, ctx.Namespace, ctx.Metrics)
+	vm.droppedTxs.Size = droppedTxsCacheSize
 
 	vm.currentBlocks = make(map[[32]byte]Block)
 	vm.timer = timer.NewTimer(func() {
@@ -290,40 +379,53 @@ func (vm *VM) initBlockchains() error {
 		return err
 	}
 	for _, chain := range existingChains { // Create each blockchain
-		chainParams := chains.ChainParameters{
-			ID:          chain.ID(),
-			GenesisData: chain.GenesisData,
-			VMAlias:     chain.VMID.String(),
-		}
-		for _, fxID := range chain.FxIDs {
-			chainParams.FxAliases = append(chainParams.FxAliases, fxID.String())
-		}
-		vm.ChainManager.CreateChain(chainParams)
+		vm.createChain(chain)
 	}
 	return nil
 }
 
+// Create the blockchain that [chain] created
+func (vm *VM) createChain(chain *CreateChainTx) {
+	chainParams := chains.ChainParameters{
+		ID:          chain.ID(),
+		SubnetID:    chain.SubnetID,
+		GenesisData: chain.GenesisData,
+		VMAlias:     chain.VMID.String(),
+	}
+	for _, fxID := range chain.FxIDs {
+		chainParams.FxAliases = append(chainParams.FxAliases, fxID.String())
+	}
+	vm.ChainManager.CreateChain(chainParams)
+}
+
 // Shutdown this blockchain
 func (vm *VM) Shutdown() {
 	vm.timer.Stop()
-	if err := vm.DB.Close(); err != nil {
-		vm.Ctx.Log.Error("Closing the database failed with %s", err)
-	}
+	vm.SnowmanVM.Shutdown()
 }
 
+// Clock returns the clock that staking times are checked against. Simulations
+// can set or advance it to move the chain forward without waiting.
+func (vm *VM) Clock() *timer.Clock { return &vm.clock }
+
+// Version implements the common.VersionedVM interface
+func (vm *VM) Version() string { return version }
+
+// Upgrades implements the common.VersionedVM interface
+func (vm *VM) Upgrades() []string { return nil }
+
 // BuildBlock builds a block to be added to consensus
 func (vm *VM) BuildBlock() (snowman.Block, error) {
 	vm.Ctx.Log.Debug("in BuildBlock")
 	preferredID := vm.Preferred()
 
 	// If there are pending decision txs, build a block with a batch of them
-	if len(vm.unissuedDecisionTxs) > 0 {
-		numTxs := BatchSize
-		if numTxs > len(vm.unissuedDecisionTxs) {
-			numTxs = len(vm.unissuedDecisionTxs)
+	if vm.unissuedDecisionTxs.Len() > 0 {
+		unissuedTxs := vm.unissuedDecisionTxs.Pop(BatchSize)
+		txs := make([]DecisionTx, len(unissuedTxs))
+		for i, tx := range unissuedTxs {
+			txs[i] = tx.(DecisionTx)
 		}
-		var txs []DecisionTx
-		txs, vm.unissuedDecisionTxs = vm.unissuedDecisionTxs[:numTxs], vm.unissuedDecisionTxs[numTxs:]
 		blk, err := vm.newStandardBlock(preferredID, txs)
 		if err != nil {
 			return nil, err
@@ -428,6 +530,7 @@ func (vm *VM) BuildBlock() (snowman.Block, error) {
 			return blk, vm.DB.Commit()
 		}
 		vm.Ctx.Log.Debug("dropping tx to add validator because start time too late")
+		vm.decided(tx.ID(), choices.Rejected)
 	}
 
 	vm.Ctx.Log.Debug("BuildBlock returning error (no blocks)")
@@ -499,12 +602,75 @@ func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
 	}
 }
 
+// issueDecisionTx adds [tx], whose ID is [txID], to the decision txs that will
+// be put into blocks. Txs evicted from the mempool to make room for [tx] are
+// dropped.
+func (vm *VM) issueDecisionTx(txID ids.ID, tx DecisionTx) error {
+	if vm.Ctx.ReadOnly {
+		return snow.ErrReadOnly
+	}
+	// Decision txs all pay [txFee], which is 0, so the mempool can't order or
+	// evict them by fee
+	evicted, err := vm.unissuedDecisionTxs.Add(txID, tx, txFee)
+	if err != nil {
+		return err
+	}
+	vm.droppedTxs.Evict(txID)
+	for _, evictedID := range evicted {
+		vm.Ctx.Log.Debug("evicted decision tx %s from the mempool", evictedID)
+		vm.droppedTxs.Put(evictedID, nil)
+		vm.decided(evictedID, choices.Rejected)
+	}
+	vm.resetTimer()
+	return nil
+}
+
+// IssueTx issues [b], a tx to add a staker formatted as it's returned by the
+// API, and calls [onDecide] when its proposal is committed or aborted, or when
+// it's dropped. Returns the ID of the tx.
+func (vm *VM) IssueTx(b []byte, onDecide func(choices.Status)) (ids.ID, error) {
+	if vm.Ctx.ReadOnly {
+		return ids.ID{}, snow.ErrReadOnly
+	}
+	genTx := genericTx{}
+	if _, err := Codec.Unmarshal(b, &genTx); err != nil {
+		return ids.ID{}, err
+	}
+
+	switch tx := genTx.Tx.(type) {
+	case *rotatedValidatorTx:
+		return ids.ID{}, errRotatedValidatorTx
+	case TimedTx:
+		if err := tx.initialize(vm); err != nil {
+			return ids.ID{}, fmt.Errorf("error initializing tx: %s", err)
+		}
+		if onDecide != nil {
+			vm.onDecide[tx.ID().Key()] = onDecide
+		}
+		vm.unissuedEvents.Push(tx)
+		vm.resetTimer()
+		return tx.ID(), nil
+	default:
+		return ids.ID{}, errors.New("only transactions that add stakers can be issued")
+	}
+}
+
+// decided calls the function registered by IssueTx for the tx [txID], if
+// there is one
+func (vm *VM) decided(txID ids.ID, status choices.Status) {
+	key := txID.Key()
+	if onDecide, ok := vm.onDecide[key]; ok {
+		delete(vm.onDecide, key)
+		onDecide(status)
+	}
+}
+
 // Check if there is a block ready to be added to consensus
 // If so, notify the consensus engine
 func (vm *VM) resetTimer() {
 	// If there is a pending CreateChainTx, trigger building of a block
 	// with that transaction
-	if len(vm.unissuedDecisionTxs) > 0 {
+	if vm.unissuedDecisionTxs.Len() > 0 {
 		vm.SnowmanVM.NotifyBlockReady()
 		return
 	}
@@ -563,8 +729,9 @@ func (vm *VM) resetTimer() {
 			return
 		}
 		// If the tx doesn't meet the syncrony bound, drop it
-		vm.unissuedEvents.Remove()
+		tx := vm.unissuedEvents.Remove()
 		vm.Ctx.Log.Debug("dropping tx to add validator because its start time has passed")
+		vm.decided(tx.ID(), choices.Rejected)
 	}
 
 	waitTime := nextValidatorSetChangeTime.Sub(localTime)
@@ -647,10 +814,26 @@ func (vm *VM) calculateValidators(db database.Database, timestamp time.Time, sub
 }
 
 func (vm *VM) getValidators(validatorEvents *EventHeap) []validators.Validator {
+	// Delegators of a validator that rotated to a new node ID delegate to the
+	// new node ID
+	rotatedIDs := make(map[[20]byte]ids.ShortID)
+	for _, event := range validatorEvents.Txs {
+		if rotated, ok := event.(*rotatedValidatorTx); ok {
+			nodeID := rotated.NodeID()
+			rotatedIDs[rotated.Staker.NodeID.Key()] = nodeID
+			for _, rotatedID := range rotated.RotatedNodeIDs {
+				rotatedIDs[rotatedID.Key()] = nodeID
+			}
+		}
+	}
+
 	vdrMap := make(map[[20]byte]*Validator, validatorEvents.Len())
 	for _, event := range validatorEvents.Txs {
 		vdr := event.Vdr()
 		vdrID := vdr.ID()
+		if nodeID, ok := rotatedIDs[vdrID.Key()]; ok {
+			vdrID = nodeID
+		}
 		vdrKey := vdrID.Key()
 		validator, exists := vdrMap[vdrKey]
 		if !exists {
@@ -683,6 +866,29 @@ func (vm *VM) updateValidators(subnetID ids.ID) error {
 	}
 
 	validators := vm.getValidators(currentValidators)
+	wasValidating := validatorSet.Contains(vm.Ctx.NodeID)
 	validatorSet.Set(validators)
+
+	// Every node runs the chains of the default subnet, but only the validators
+	// of any other subnet run its chains
+	if wasValidating && !validatorSet.Contains(vm.Ctx.NodeID) && !subnetID.Equals(DefaultSubnetID) && vm.ChainManager != nil {
+		vm.Ctx.Log.Info("this node stopped validating subnet %s, so its chains are being removed", subnetID)
+		go vm.Ctx.Log.RecoverAndPanic(func() { vm.ChainManager.RemoveSubnet(subnetID) })
+	}
 	return nil
 }
+
+// eligibleForReward returns false if uptime based rewards are enabled and this
+// node measured the uptime of [staker]'s validator over its staking period to
+// be below the threshold
+func (vm *VM) eligibleForReward(staker TimedTx) bool {
+	if vm.UptimeTracker == nil || vm.UptimeRewardThreshold <= 0 || staker == nil {
+		return true
+	}
+	vdrID := staker.Vdr().ID()
+	if vdrID.Equals(vm.Ctx.NodeID) {
+		return true // This node doesn't track its own uptime
+	}
+	perf := vm.UptimeTracker.Performance(vdrID, staker.StartTime())
+	return perf.Uptime >= vm.UptimeRewardThreshold
+}
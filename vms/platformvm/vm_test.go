@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ava-labs/gecko/database"
 	"github.com/ava-labs/gecko/database/memdb"
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/snow"
@@ -19,6 +20,7 @@ import (
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/formatting"
 	"github.com/ava-labs/gecko/vms/components/core"
+	"github.com/ava-labs/gecko/vms/components/mempool"
 	"github.com/ava-labs/gecko/vms/timestampvm"
 )
 
@@ -94,7 +96,11 @@ func defaultContext() *snow.Context {
 	return ctx
 }
 
-func defaultVM() *VM {
+func defaultVM() *VM { return defaultVMWithDB(defaultContext(), memdb.New()) }
+
+// defaultVMWithDB returns the VM of defaultVM with context [ctx], whose
+// database is [db]
+func defaultVMWithDB(ctx *snow.Context, db database.Database) *VM {
 	genesisAccounts := GenesisAccounts()
 	genesisValidators := GenesisCurrentValidators()
 	genesisChains := make([]*CreateChainTx, 0)
@@ -106,7 +112,7 @@ func defaultVM() *VM {
 		Timestamp:  uint64(defaultGenesisTime.Unix()),
 	}
 
-	genesisBytes, err := Codec.Marshal(genesisState)
+	genesisBytes, err := Codec.Marshal(codecVersion, genesisState)
 	if err != nil {
 		panic(err)
 	}
@@ -120,9 +126,7 @@ func defaultVM() *VM {
 	vm.Validators.PutValidatorSet(DefaultSubnetID, defaultSubnet)
 
 	vm.clock.Set(defaultGenesisTime)
-	db := memdb.New()
 	msgChan := make(chan common.Message, 1)
-	ctx := defaultContext()
 	if err := vm.Initialize(ctx, db, genesisBytes, msgChan, nil); err != nil {
 		panic(err)
 	}
@@ -418,6 +422,84 @@ func TestAddDefaultSubnetValidatorReject(t *testing.T) {
 	}
 }
 
+// Ensure the decision of a tx issued with IssueTx is reported
+func TestIssueTxDecided(t *testing.T) {
+	vm := defaultVM()
+	startTime := defaultGenesisTime.Add(Delta).Add(1 * time.Second)
+	endTime := startTime.Add(MinimumStakingDuration)
+
+	txBytes, err := SignAddDefaultSubnetDelegatorTx(UnsignedAddDefaultSubnetDelegatorTx{
+		DurationValidator: DurationValidator{
+			Validator: Validator{
+				NodeID: defaultKey.PublicKey().Address(),
+				Wght:   MinimumStakeAmount,
+			},
+			Start: uint64(startTime.Unix()),
+			End:   uint64(endTime.Unix()),
+		},
+		NetworkID:   testNetworkID,
+		Nonce:       defaultNonce + 1,
+		Destination: defaultKey.PublicKey().Address(),
+	}, defaultKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := []choices.Status(nil)
+	onDecide := func(status choices.Status) { statuses = append(statuses, status) }
+
+	vm.Ctx.Lock.Lock()
+	defer vm.Ctx.Lock.Unlock()
+
+	if _, err := vm.IssueTx(txBytes, onDecide); err != nil {
+		t.Fatal(err)
+	}
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := blk.(*ProposalBlock)
+	if err := block.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	block.Accept()
+	commit := block.Options()[0].(*Commit)
+	if err := commit.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 0 {
+		t.Fatalf("The tx shouldn't be decided before its proposal is")
+	}
+	commit.Accept()
+	if len(statuses) != 1 || statuses[0] != choices.Accepted {
+		t.Fatalf("The tx should have been reported as accepted")
+	}
+
+	// A tx that starts too soon is dropped
+	txBytes, err = SignAddDefaultSubnetDelegatorTx(UnsignedAddDefaultSubnetDelegatorTx{
+		DurationValidator: DurationValidator{
+			Validator: Validator{
+				NodeID: defaultKey.PublicKey().Address(),
+				Wght:   MinimumStakeAmount,
+			},
+			Start: uint64(defaultGenesisTime.Unix()),
+			End:   uint64(defaultGenesisTime.Add(MinimumStakingDuration).Unix()),
+		},
+		NetworkID:   testNetworkID,
+		Nonce:       defaultNonce + 2,
+		Destination: defaultKey.PublicKey().Address(),
+	}, defaultKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vm.IssueTx(txBytes, onDecide); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[1] != choices.Rejected {
+		t.Fatalf("The dropped tx should have been reported as rejected")
+	}
+}
+
 // Accept proposal to add validator to non-default subnet
 func TestAddNonDefaultSubnetValidatorAccept(t *testing.T) {
 	vm := defaultVM()
@@ -765,6 +847,7 @@ func TestCreateChain(t *testing.T) {
 		timestampvm.ID,
 		nil,
 		"name ",
+		DefaultSubnetID,
 		testNetworkID,
 		keys[0],
 	)
@@ -773,7 +856,9 @@ func TestCreateChain(t *testing.T) {
 	}
 
 	vm.Ctx.Lock.Lock()
-	vm.unissuedDecisionTxs = append(vm.unissuedDecisionTxs, tx)
+	if _, err := vm.unissuedDecisionTxs.Add(tx.ID(), tx, 0); err != nil {
+		t.Fatal(err)
+	}
 	blk, err := vm.BuildBlock() // should contain proposal to create chain
 	if err != nil {
 		t.Fatal(err)
@@ -834,7 +919,9 @@ func TestCreateSubnet(t *testing.T) {
 	}
 
 	vm.Ctx.Lock.Lock()
-	vm.unissuedDecisionTxs = append(vm.unissuedDecisionTxs, createSubnetTx)
+	if _, err := vm.unissuedDecisionTxs.Add(createSubnetTx.ID, createSubnetTx, 0); err != nil {
+		t.Fatal(err)
+	}
 	blk, err := vm.BuildBlock() // should contain proposal to create subnet
 	if err != nil {
 		t.Fatal(err)
@@ -1060,3 +1147,101 @@ func TestCreateSubnet(t *testing.T) {
 	}
 
 }
+
+func TestGetBlockIDAtHeight(t *testing.T) {
+	vm := defaultVM()
+
+	if blkID, err := vm.GetBlockIDAtHeight(0); err != nil {
+		t.Fatal(err)
+	} else if !blkID.Equals(vm.LastAccepted()) {
+		t.Fatalf("Genesis block should be at height 0")
+	}
+	if _, err := vm.GetBlockIDAtHeight(1); err == nil {
+		t.Fatalf("Shouldn't have a block at height 1 yet")
+	}
+
+	// Fast forward clock to time for genesis validators to leave
+	vm.clock.Set(defaultValidateEndTime)
+
+	vm.Ctx.Lock.Lock()
+	blk, err := vm.BuildBlock() // should contain proposal to advance time
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm.Ctx.Lock.Unlock()
+
+	block := blk.(*ProposalBlock)
+	if err := block.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	commit := block.Options()[0]
+	block.Accept()
+	if err := commit.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	commit.Accept()
+
+	for height, expectedID := range []ids.ID{block.ID(), commit.ID()} {
+		if blkID, err := vm.GetBlockIDAtHeight(uint64(height + 1)); err != nil {
+			t.Fatal(err)
+		} else if !blkID.Equals(expectedID) {
+			t.Fatalf("Wrong block at height %d", height+1)
+		}
+	}
+}
+
+// Ensure a decision tx evicted from the mempool is dropped
+func TestEvictedDecisionTxDropped(t *testing.T) {
+	vm := defaultVM()
+	vm.unissuedDecisionTxs.Initialize(vm.Ctx.Log, mempool.Config{MaxTxs: 1}, "", nil)
+
+	txs := []*CreateChainTx(nil)
+	for _, name := range []string{"first", "second"} {
+		tx, err := vm.newCreateChainTx(
+			defaultNonce+1,
+			nil,
+			timestampvm.ID,
+			nil,
+			name,
+			DefaultSubnetID,
+			testNetworkID,
+			keys[0],
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+	}
+
+	vm.Ctx.Lock.Lock()
+	defer vm.Ctx.Lock.Unlock()
+
+	if err := vm.issueDecisionTx(txs[0].ID(), txs[0]); err != nil {
+		t.Fatal(err)
+	}
+	decided := []choices.Status(nil)
+	vm.onDecide[txs[0].ID().Key()] = func(status choices.Status) {
+		decided = append(decided, status)
+	}
+	if err := vm.issueDecisionTx(txs[1].ID(), txs[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(decided) != 1 || decided[0] != choices.Rejected {
+		t.Fatalf("Should have notified that the evicted tx was rejected")
+	}
+	if _, dropped := vm.droppedTxs.Get(txs[0].ID()); !dropped {
+		t.Fatalf("Should have marked the evicted tx as dropped")
+	}
+
+	// Re-issuing the tx makes room for it by evicting the other tx
+	if err := vm.issueDecisionTx(txs[0].ID(), txs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, dropped := vm.droppedTxs.Get(txs[0].ID()); dropped {
+		t.Fatalf("Shouldn't mark a re-issued tx as dropped")
+	}
+	if _, dropped := vm.droppedTxs.Get(txs[1].ID()); !dropped {
+		t.Fatalf("Should have marked the evicted tx as dropped")
+	}
+}
@@ -0,0 +1,325 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/rpcdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/rpc/jsoncodec"
+)
+
+const (
+	// maxRequestSize is the largest request body that's forwarded to a plugin
+	maxRequestSize = 1 << 22
+
+	// callTimeout is how long a call to a plugin's VM may take. A plugin that
+	// hangs fails the calls to its VM rather than blocking its chain.
+	callTimeout = 30 * time.Second
+)
+
+var errFxsNotSupported = errors.New("VMs in plugins can't run fxs")
+
+// client of a VM served by a plugin. It implements the functionality that
+// chain and DAG VMs share.
+type client struct {
+	// Path of the plugin's executable. The plugin is started when the VM is
+	// initialized, unless [conn] was already connected to it.
+	path string
+	proc *process
+	dir  string // Private directory of the Unix sockets of the VM and engine
+
+	conn   *grpc.ClientConn
+	engine *grpc.Server // Serves the VM's database and engine services
+
+	ctx *snow.Context
+}
+
+// call the method [name] of the VM service
+func (c *client) call(name string, req, reply interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	return c.conn.Invoke(
+		ctx,
+		"/"+VMService+"/"+name,
+		req,
+		reply,
+		grpc.CallContentSubtype(jsoncodec.Name),
+	)
+}
+
+// Initialize implements the common.VM interface
+func (c *client) Initialize(
+	ctx *snow.Context,
+	db database.Database,
+	genesisBytes []byte,
+	toEngine chan<- common.Message,
+	fxs []*common.Fx,
+) error {
+	if len(fxs) > 0 {
+		return errFxsNotSupported
+	}
+	c.ctx = ctx
+
+	// TempDir creates a directory only the user running the node can access
+	dir, err := ioutil.TempDir("", "gecko-plugin")
+	if err != nil {
+		return err
+	}
+	c.dir = dir
+
+	if c.conn == nil {
+		proc, err := startProcess(c.path, c.dir, ctx.Log)
+		if err != nil {
+			os.RemoveAll(c.dir)
+			return err
+		}
+		c.proc = proc
+		c.conn = proc.conn
+	}
+
+	listener, err := listen(c.dir, engineSocket)
+	if err != nil {
+		c.stop()
+		return err
+	}
+	c.engine = grpc.NewServer()
+	rpcdb.RegisterDatabaseServer(c.engine, rpcdb.NewServer(db))
+	c.engine.RegisterService(&engineServiceDesc, &engineServer{
+		log:      ctx.Log,
+		toEngine: toEngine,
+	})
+	go c.engine.Serve(listener)
+
+	req := &initializeRequest{
+		NetworkID:    ctx.NetworkID,
+		ChainID:      ctx.ChainID,
+		NodeID:       ctx.NodeID,
+		GenesisBytes: genesisBytes,
+		EngineAddr:   listener.Addr().String(),
+	}
+	if err := c.call("Initialize", req, &emptyMsg{}); err != nil {
+		c.stop()
+		return err
+	}
+	return nil
+}
+
+// Shutdown implements the common.VM interface
+func (c *client) Shutdown() {
+	if c.conn == nil {
+		return
+	}
+	if err := c.call("Shutdown", &emptyMsg{}, &emptyMsg{}); err != nil {
+		c.ctx.Log.Warn("failed to shut down the VM in plugin %s: %s", c.path, err)
+	}
+	c.stop()
+}
+
+// stop serving the VM's database and engine services, and stop the plugin if
+// it was started by this client
+func (c *client) stop() {
+	if c.engine != nil {
+		c.engine.Stop()
+	}
+	if c.proc != nil {
+		c.proc.stop()
+	} else if err := c.conn.Close(); err != nil {
+		c.ctx.Log.Debug("failed to close the connection to plugin %s: %s", c.path, err)
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		c.ctx.Log.Debug("failed to remove the socket directory %s of plugin %s: %s", c.dir, c.path, err)
+	}
+}
+
+// CreateHandlers implements the common.VM interface. The handlers forward
+// their requests to the plugin, which takes the locks they need.
+func (c *client) CreateHandlers() map[string]*common.HTTPHandler {
+	reply := &createHandlersReply{}
+	if err := c.call("CreateHandlers", &emptyMsg{}, reply); err != nil {
+		c.ctx.Log.Error("failed to create the handlers of the VM in plugin %s: %s", c.path, err)
+		return nil
+	}
+
+	handlers := make(map[string]*common.HTTPHandler, len(reply.Handlers))
+	for _, h := range reply.Handlers {
+		handlers[h.Extension] = &common.HTTPHandler{
+			LockOptions: common.NoLock,
+			Handler:     &httpHandler{client: c, extension: h.Extension},
+		}
+	}
+	return handlers
+}
+
+// httpHandler forwards the requests to one handler of a VM in a plugin
+type httpHandler struct {
+	client    *client
+	extension string
+}
+
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req := &httpRequest{
+		Extension: h.extension,
+		Method:    r.Method,
+		URL:       r.URL.String(),
+		Header:    r.Header,
+		Body:      body,
+	}
+	resp := &httpResponse{}
+	if err := h.client.call("Handle", req, resp); err != nil {
+		h.client.ctx.Log.Debug("VM in plugin %s failed to handle a request to %s: %s", h.client.path, req.URL, err)
+		http.Error(w, "VM failed to handle the request", http.StatusBadGateway)
+		return
+	}
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.Code)
+	if _, err := w.Write(resp.Body); err != nil {
+		h.client.ctx.Log.Debug("failed to write the response to a request to %s: %s", req.URL, err)
+	}
+}
+
+// VMClient is a snowman.ChainVM that's served by a plugin
+type VMClient struct{ client }
+
+// NewClient returns the chain VM served over [conn]
+func NewClient(conn *grpc.ClientConn) *VMClient { return &VMClient{client: client{conn: conn}} }
+
+// BuildBlock implements the snowman.ChainVM interface
+func (vm *VMClient) BuildBlock() (snowman.Block, error) {
+	reply := &blockReply{}
+	if err := vm.call("BuildBlock", &emptyMsg{}, reply); err != nil {
+		return nil, err
+	}
+	return vm.newBlock(reply), nil
+}
+
+// ParseBlock implements the snowman.ChainVM interface
+func (vm *VMClient) ParseBlock(b []byte) (snowman.Block, error) {
+	reply := &blockReply{}
+	if err := vm.call("ParseBlock", &bytesRequest{Bytes: b}, reply); err != nil {
+		return nil, err
+	}
+	return vm.newBlock(reply), nil
+}
+
+// GetBlock implements the snowman.ChainVM interface
+func (vm *VMClient) GetBlock(blkID ids.ID) (snowman.Block, error) {
+	reply := &blockReply{}
+	if err := vm.call("GetBlock", &idRequest{ID: blkID}, reply); err != nil {
+		return nil, err
+	}
+	return vm.newBlock(reply), nil
+}
+
+// SetPreference implements the snowman.ChainVM interface
+func (vm *VMClient) SetPreference(blkID ids.ID) {
+	if err := vm.call("SetPreference", &idRequest{ID: blkID}, &emptyMsg{}); err != nil {
+		vm.ctx.Log.Error("failed to set the preference of the VM in plugin %s to %s: %s", vm.path, blkID, err)
+	}
+}
+
+// LastAccepted implements the snowman.ChainVM interface
+func (vm *VMClient) LastAccepted() ids.ID {
+	reply := &idRequest{}
+	if err := vm.call("LastAccepted", &emptyMsg{}, reply); err != nil {
+		vm.ctx.Log.Error("failed to get the last accepted block of the VM in plugin %s: %s", vm.path, err)
+	}
+	return reply.ID
+}
+
+// GetBlockIDAtHeight implements the snowman.ChainVM interface
+func (vm *VMClient) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	reply := &idRequest{}
+	if err := vm.call("GetBlockIDAtHeight", &heightRequest{Height: height}, reply); err != nil {
+		return ids.ID{}, err
+	}
+	return reply.ID, nil
+}
+
+func (vm *VMClient) newBlock(reply *blockReply) *blockClient {
+	return &blockClient{
+		vm:       vm,
+		id:       reply.ID,
+		parentID: reply.ParentID,
+		status:   reply.Status,
+		bytes:    reply.Bytes,
+	}
+}
+
+// DAGVMClient is an avalanche.DAGVM that's served by a plugin
+type DAGVMClient struct{ client }
+
+// NewDAGClient returns the DAG VM served over [conn]
+func NewDAGClient(conn *grpc.ClientConn) *DAGVMClient {
+	return &DAGVMClient{client: client{conn: conn}}
+}
+
+// PendingTxs implements the avalanche.DAGVM interface
+func (vm *DAGVMClient) PendingTxs() []snowstorm.Tx {
+	reply := &pendingTxsReply{}
+	if err := vm.call("PendingTxs", &emptyMsg{}, reply); err != nil {
+		vm.ctx.Log.Error("failed to get the pending txs of the VM in plugin %s: %s", vm.path, err)
+		return nil
+	}
+	txs := make([]snowstorm.Tx, len(reply.Txs))
+	for i := range reply.Txs {
+		txs[i] = vm.newTx(&reply.Txs[i])
+	}
+	return txs
+}
+
+// ParseTx implements the avalanche.DAGVM interface
+func (vm *DAGVMClient) ParseTx(b []byte) (snowstorm.Tx, error) {
+	reply := &txReply{}
+	if err := vm.call("ParseTx", &bytesRequest{Bytes: b}, reply); err != nil {
+		return nil, err
+	}
+	return vm.newTx(reply), nil
+}
+
+// GetTx implements the avalanche.DAGVM interface
+func (vm *DAGVMClient) GetTx(txID ids.ID) (snowstorm.Tx, error) {
+	reply := &txReply{}
+	if err := vm.call("GetTx", &idRequest{ID: txID}, reply); err != nil {
+		return nil, err
+	}
+	return vm.newTx(reply), nil
+}
+
+func (vm *DAGVMClient) newTx(reply *txReply) *txClient {
+	tx := &txClient{
+		vm:     vm,
+		id:     reply.ID,
+		status: reply.Status,
+		bytes:  reply.Bytes,
+		deps:   reply.Dependencies,
+	}
+	tx.inputs.Add(reply.InputIDs...)
+	return tx
+}
@@ -0,0 +1,132 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+)
+
+// blockClient is a block of a VM in a plugin. It's verified and decided by
+// the plugin.
+type blockClient struct {
+	vm *VMClient
+
+	id       ids.ID
+	parentID ids.ID
+	status   choices.Status
+	bytes    []byte
+}
+
+// ID implements the snowman.Block interface
+func (b *blockClient) ID() ids.ID { return b.id }
+
+// Status implements the snowman.Block interface
+func (b *blockClient) Status() choices.Status { return b.status }
+
+// Bytes implements the snowman.Block interface
+func (b *blockClient) Bytes() []byte { return b.bytes }
+
+// Parent implements the snowman.Block interface. If the plugin doesn't know
+// the parent, a block with status Unknown is returned.
+func (b *blockClient) Parent() snowman.Block {
+	if parent, err := b.vm.GetBlock(b.parentID); err == nil {
+		return parent
+	}
+	return &blockClient{
+		vm:     b.vm,
+		id:     b.parentID,
+		status: choices.Unknown,
+	}
+}
+
+// Verify implements the snowman.Block interface
+func (b *blockClient) Verify() error {
+	return b.vm.call("BlockVerify", &idRequest{ID: b.id}, &emptyMsg{})
+}
+
+// Accept implements the snowman.Block interface
+func (b *blockClient) Accept() {
+	if err := b.vm.call("BlockAccept", &idRequest{ID: b.id}, &emptyMsg{}); err != nil {
+		b.vm.ctx.Log.Error("failed to accept block %s in plugin %s: %s", b.id, b.vm.path, err)
+		return
+	}
+	b.status = choices.Accepted
+}
+
+// Reject implements the snowman.Block interface
+func (b *blockClient) Reject() {
+	if err := b.vm.call("BlockReject", &idRequest{ID: b.id}, &emptyMsg{}); err != nil {
+		b.vm.ctx.Log.Error("failed to reject block %s in plugin %s: %s", b.id, b.vm.path, err)
+		return
+	}
+	b.status = choices.Rejected
+}
+
+// txClient is a transaction of a VM in a plugin. It's verified and decided by
+// the plugin.
+type txClient struct {
+	vm *DAGVMClient
+
+	id     ids.ID
+	status choices.Status
+	bytes  []byte
+	deps   []ids.ID
+	inputs ids.Set
+}
+
+// ID implements the snowstorm.Tx interface
+func (tx *txClient) ID() ids.ID { return tx.id }
+
+// Status implements the snowstorm.Tx interface
+func (tx *txClient) Status() choices.Status { return tx.status }
+
+// Bytes implements the snowstorm.Tx interface
+func (tx *txClient) Bytes() []byte { return tx.bytes }
+
+// InputIDs implements the snowstorm.Tx interface
+func (tx *txClient) InputIDs() ids.Set { return tx.inputs }
+
+// Dependencies implements the snowstorm.Tx interface. The dependencies the
+// plugin doesn't know have status Unknown.
+func (tx *txClient) Dependencies() []snowstorm.Tx {
+	deps := make([]snowstorm.Tx, len(tx.deps))
+	for i, depID := range tx.deps {
+		if dep, err := tx.vm.GetTx(depID); err == nil {
+			deps[i] = dep
+		} else {
+			deps[i] = &txClient{
+				vm:     tx.vm,
+				id:     depID,
+				status: choices.Unknown,
+			}
+		}
+	}
+	return deps
+}
+
+// Verify implements the snowstorm.Tx interface
+func (tx *txClient) Verify() error {
+	return tx.vm.call("TxVerify", &idRequest{ID: tx.id}, &emptyMsg{})
+}
+
+// Accept implements the snowstorm.Tx interface
+func (tx *txClient) Accept() {
+	if err := tx.vm.call("TxAccept", &idRequest{ID: tx.id}, &emptyMsg{}); err != nil {
+		tx.vm.ctx.Log.Error("failed to accept tx %s in plugin %s: %s", tx.id, tx.vm.path, err)
+		return
+	}
+	tx.status = choices.Accepted
+}
+
+// Reject implements the snowstorm.Tx interface
+func (tx *txClient) Reject() {
+	if err := tx.vm.call("TxReject", &idRequest{ID: tx.id}, &emptyMsg{}); err != nil {
+		tx.vm.ctx.Log.Error("failed to reject tx %s in plugin %s: %s", tx.id, tx.vm.path, err)
+		return
+	}
+	tx.status = choices.Rejected
+}
@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	notifyMethod = "/" + EngineService + "/Notify"
+	logMethod    = "/" + EngineService + "/Log"
+)
+
+// engineServer is served by the node to a plugin
+type engineServer struct {
+	log      logging.Logger
+	toEngine chan<- common.Message
+}
+
+func (s *engineServer) notify(req *notifyRequest) (*emptyMsg, error) {
+	select {
+	case s.toEngine <- req.Message:
+	default:
+		s.log.Debug("dropping message %s from the plugin to the consensus engine", req.Message)
+	}
+	return &emptyMsg{}, nil
+}
+
+func (s *engineServer) logMsg(req *logRequest) (*emptyMsg, error) {
+	switch req.Level {
+	case logging.Fatal:
+		s.log.Fatal("%s", req.Msg)
+	case logging.Error:
+		s.log.Error("%s", req.Msg)
+	case logging.Warn:
+		s.log.Warn("%s", req.Msg)
+	case logging.Info:
+		s.log.Info("%s", req.Msg)
+	case logging.Debug:
+		s.log.Debug("%s", req.Msg)
+	default:
+		s.log.Verbo("%s", req.Msg)
+	}
+	return &emptyMsg{}, nil
+}
+
+var engineServiceDesc = grpc.ServiceDesc{
+	ServiceName: EngineService,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(EngineService, "Notify", func() interface{} { return &notifyRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*engineServer).notify(req.(*notifyRequest))
+		}),
+		unaryMethod(EngineService, "Log", func() interface{} { return &logRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*engineServer).logMsg(req.(*logRequest))
+		}),
+	},
+}
+
+// pluginLog is the logger of a VM in a plugin. It sends the messages it logs
+// to the node, which logs them with the chain's logger.
+type pluginLog struct {
+	logging.NoLog
+	conn *grpc.ClientConn
+}
+
+func (l *pluginLog) send(level logging.Level, format string, args ...interface{}) {
+	// A message that can't be sent can't be logged either
+	_ = l.conn.Invoke(context.Background(), logMethod, &logRequest{Level: level, Msg: fmt.Sprintf(format, args...)}, &emptyMsg{})
+}
+
+func (l *pluginLog) Write(p []byte) (int, error) {
+	l.send(logging.Info, "%s", p)
+	return len(p), nil
+}
+
+func (l *pluginLog) Fatal(format string, args ...interface{}) { l.send(logging.Fatal, format, args...) }
+func (l *pluginLog) Error(format string, args ...interface{}) { l.send(logging.Error, format, args...) }
+func (l *pluginLog) Warn(format string, args ...interface{})  { l.send(logging.Warn, format, args...) }
+func (l *pluginLog) Info(format string, args ...interface{})  { l.send(logging.Info, format, args...) }
+func (l *pluginLog) Debug(format string, args ...interface{}) { l.send(logging.Debug, format, args...) }
+func (l *pluginLog) Verbo(format string, args ...interface{}) { l.send(logging.Verbo, format, args...) }
+
+// unaryMethod returns the description of the method [name] of [service],
+// which decodes its requests into the values returned by [newRequest] and
+// handles them with [handle]
+func unaryMethod(service, name string, newRequest func() interface{}, handle func(srv, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	fullMethod := "/" + service + "/" + name
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newRequest()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return handle(srv, req)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handler)
+		},
+	}
+}
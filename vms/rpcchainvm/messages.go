@@ -0,0 +1,112 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"net/http"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+const (
+	// VMService is the name of the gRPC service a plugin serves its VM as.
+	// Its messages are encoded as JSON, with the content subtype "json".
+	VMService = "gecko.rpcchainvm.VM"
+
+	// EngineService is the name of the gRPC service the node serves to a
+	// plugin, next to the VM's database, so the plugin can notify the
+	// consensus engine and log with the chain's logger
+	EngineService = "gecko.rpcchainvm.Engine"
+
+	// handshakePrefix starts the line a plugin writes to its standard output
+	// once it's serving its VM. The line is formatted as
+	// <handshakePrefix>|<protocolVersion>|<address>, where the address is the
+	// path of the Unix socket the VM is served on.
+	handshakePrefix = "gecko-rpcchainvm"
+
+	// protocolVersion is the version of the messages exchanged with plugins
+	protocolVersion = 2
+)
+
+type emptyMsg struct{}
+
+type initializeRequest struct {
+	NetworkID    uint32      `json:"networkID"`
+	ChainID      ids.ID      `json:"chainID"`
+	NodeID       ids.ShortID `json:"nodeID"`
+	GenesisBytes []byte      `json:"genesisBytes"`
+
+	// Path of the Unix socket of the node's database and engine services
+	EngineAddr string `json:"engineAddr"`
+}
+
+type handler struct {
+	Extension   string            `json:"extension"`
+	LockOptions common.LockOption `json:"lockOptions"`
+}
+
+type createHandlersReply struct {
+	Handlers []handler `json:"handlers"`
+}
+
+type httpRequest struct {
+	Extension string      `json:"extension"`
+	Method    string      `json:"method"`
+	URL       string      `json:"url"`
+	Header    http.Header `json:"header"`
+	Body      []byte      `json:"body"`
+}
+
+type httpResponse struct {
+	Code   int         `json:"code"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+type idRequest struct {
+	ID ids.ID `json:"id"`
+}
+
+type bytesRequest struct {
+	Bytes []byte `json:"bytes"`
+}
+
+type heightRequest struct {
+	Height uint64 `json:"height"`
+}
+
+type statusReply struct {
+	Status choices.Status `json:"status"`
+}
+
+type blockReply struct {
+	ID       ids.ID         `json:"id"`
+	ParentID ids.ID         `json:"parentID"`
+	Status   choices.Status `json:"status"`
+	Bytes    []byte         `json:"bytes"`
+}
+
+type txReply struct {
+	ID           ids.ID         `json:"id"`
+	Status       choices.Status `json:"status"`
+	Bytes        []byte         `json:"bytes"`
+	Dependencies []ids.ID       `json:"dependencies"`
+	InputIDs     []ids.ID       `json:"inputIDs"`
+}
+
+type pendingTxsReply struct {
+	Txs []txReply `json:"txs"`
+}
+
+type notifyRequest struct {
+	Message common.Message `json:"message"`
+}
+
+type logRequest struct {
+	Level logging.Level `json:"level"`
+	Msg   string        `json:"msg"`
+}
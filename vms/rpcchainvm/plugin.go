@@ -0,0 +1,60 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package rpcchainvm runs VMs in plugins: separate processes that serve a VM
+// to the node over gRPC. A plugin has its own build and release cycle, and a
+// panic in a plugin stops its chain rather than the node.
+//
+// A plugin's main function calls Serve with its VM. The node starts the
+// plugin when a chain of the VM is created, and serves the chain's database to
+// the plugin over gRPC with rpcdb.
+package rpcchainvm
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/snow/engine/common"
+)
+
+var errNotStartedByNode = fmt.Errorf("a plugin must be started by a node, which sets %s", dirEnv)
+
+// Serve [vm], which is either a snowman.ChainVM or an avalanche.DAGVM, to the
+// node that started this process. Returns once the node shut the VM down.
+func Serve(vm common.VM) error {
+	dir := os.Getenv(dirEnv)
+	if dir == "" {
+		return errNotStartedByNode
+	}
+	listener, err := listen(dir, vmSocket)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer()
+	vmServer := NewServer(vm)
+	RegisterVMServer(server, vmServer)
+
+	go func() {
+		<-vmServer.Closed()
+		server.GracefulStop()
+	}()
+
+	if _, err := fmt.Fprint(os.Stdout, handshake(listener.Addr())); err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// Factory creates the chain VMs served by the plugin at [Path]
+type Factory struct{ Path string }
+
+// New implements the vms.VMFactory interface
+func (f *Factory) New() interface{} { return &VMClient{client: client{path: f.Path}} }
+
+// DAGFactory creates the DAG VMs served by the plugin at [Path]
+type DAGFactory struct{ Path string }
+
+// New implements the vms.VMFactory interface
+func (f *DAGFactory) New() interface{} { return &DAGVMClient{client: client{path: f.Path}} }
@@ -0,0 +1,174 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/rpc/jsoncodec"
+)
+
+const (
+	// handshakeTimeout is how long a plugin has to start serving its VM
+	handshakeTimeout = 10 * time.Second
+
+	// exitTimeout is how long a plugin has to exit once its VM is shut down,
+	// before it's killed
+	exitTimeout = 5 * time.Second
+
+	// dirEnv is the environment variable that tells a plugin the directory to
+	// create the Unix socket of its VM in. The node creates the directory, and
+	// only the user running the node can access it.
+	dirEnv = "GECKO_PLUGIN_DIR"
+
+	// Names of the Unix sockets the VM and the node's engine services are
+	// served on
+	vmSocket     = "vm.sock"
+	engineSocket = "engine.sock"
+)
+
+// process is a plugin that was started by the node
+type process struct {
+	cmd    *exec.Cmd
+	conn   *grpc.ClientConn
+	exited chan struct{}
+}
+
+// startProcess starts the plugin at [path] and connects to the VM it serves.
+// The plugin serves the VM in the private directory [dir]. The plugin's output
+// is logged to [log].
+func startProcess(path, dir string, log logging.Logger) (*process, error) {
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), dirEnv+"="+dir)
+	cmd.Stderr = log
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("couldn't start plugin %s: %w", path, err)
+	}
+	p := &process{
+		cmd:    cmd,
+		exited: make(chan struct{}),
+	}
+
+	// The first line of the plugin's output is its handshake, and the rest is
+	// logged. The plugin is only waited on once its output has been read, as
+	// Wait closes the pipe it's read from.
+	lines := make(chan string, 1)
+	go func() {
+		reader := bufio.NewReader(stdout)
+		line, _ := reader.ReadString('\n')
+		lines <- line
+		if _, err := io.Copy(log, reader); err != nil {
+			log.Debug("stopped logging the output of plugin %s: %s", path, err)
+		}
+
+		// A plugin that exits on its own, such as one that panicked, only
+		// fails the calls to its VM
+		if err := cmd.Wait(); err != nil {
+			log.Error("plugin %s exited: %s", path, err)
+		}
+		close(p.exited)
+	}()
+
+	var line string
+	select {
+	case line = <-lines:
+	case <-time.After(handshakeTimeout):
+		p.kill()
+		return nil, fmt.Errorf("plugin %s didn't start serving its VM within %s", path, handshakeTimeout)
+	}
+	addr, err := parseHandshake(line)
+	if err == nil && filepath.Dir(addr) != filepath.Clean(dir) {
+		err = fmt.Errorf("VM is served at %s, outside of %s", addr, dir)
+	}
+	if err != nil {
+		p.kill()
+		return nil, fmt.Errorf("plugin %s failed its handshake: %w", path, err)
+	}
+	conn, err := dial(addr)
+	if err != nil {
+		p.kill()
+		return nil, err
+	}
+	p.conn = conn
+	return p, nil
+}
+
+// stop waits for the plugin to exit, and kills it if it doesn't
+func (p *process) stop() {
+	p.conn.Close()
+	select {
+	case <-p.exited:
+	case <-time.After(exitTimeout):
+		p.kill()
+	}
+}
+
+func (p *process) kill() {
+	if err := p.cmd.Process.Signal(os.Kill); err == nil {
+		<-p.exited
+	}
+}
+
+// listen on the Unix socket [name] in the private directory [dir]. Only the
+// user running the node can connect to it.
+func listen(dir, name string) (net.Listener, error) {
+	path := filepath.Join(dir, name)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// dial the gRPC server listening on the Unix socket at [path]
+func dial(path string) (*grpc.ClientConn, error) {
+	return grpc.Dial(
+		path,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsoncodec.Name)),
+	)
+}
+
+// handshake returns the line a plugin writes once it serves its VM at [addr]
+func handshake(addr net.Addr) string {
+	return fmt.Sprintf("%s|%d|%s\n", handshakePrefix, protocolVersion, addr)
+}
+
+// parseHandshake returns the address of the VM a plugin serves, given the
+// plugin's handshake [line]
+func parseHandshake(line string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 3 || parts[0] != handshakePrefix {
+		return "", fmt.Errorf("unexpected handshake %q", line)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil || version != protocolVersion {
+		return "", fmt.Errorf("plugin speaks protocol version %s but the node speaks %d", parts[1], protocolVersion)
+	}
+	return parts[2], nil
+}
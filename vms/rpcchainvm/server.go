@@ -0,0 +1,589 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/database/rpcdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/snow/engine/avalanche"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	snowmaneng "github.com/ava-labs/gecko/snow/engine/snowman"
+	"github.com/ava-labs/gecko/snow/triggers"
+)
+
+var (
+	errNotInitialized  = errors.New("the VM hasn't been initialized")
+	errNotChainVM      = errors.New("the VM isn't a chain VM")
+	errNotDAGVM        = errors.New("the VM isn't a DAG VM")
+	errUnknownHandler  = errors.New("unknown HTTP handler")
+	errUnknownDecision = errors.New("unknown block or transaction")
+)
+
+// VMServer serves a VM over gRPC from a plugin process. The VM is either a
+// snowman.ChainVM or an avalanche.DAGVM.
+//
+// The VM is called with the lock of its context held, like it is by the
+// consensus engine of a node.
+type VMServer struct {
+	vm common.VM
+
+	ctx      *snow.Context
+	conn     *grpc.ClientConn // Connection to the node's engine service
+	handlers map[string]*common.HTTPHandler
+
+	// Blocks and transactions that were sent to the node and aren't decided
+	// yet, so the node can decide them
+	blks map[[32]byte]snowman.Block
+	txs  map[[32]byte]snowstorm.Tx
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewServer returns a server of [vm]
+func NewServer(vm common.VM) *VMServer {
+	return &VMServer{
+		vm:     vm,
+		blks:   make(map[[32]byte]snowman.Block),
+		txs:    make(map[[32]byte]snowstorm.Tx),
+		closed: make(chan struct{}),
+	}
+}
+
+// RegisterVMServer registers [srv] as the VM served by [s]
+func RegisterVMServer(s *grpc.Server, srv *VMServer) {
+	s.RegisterService(&vmServiceDesc, srv)
+}
+
+// Closed returns a channel that's closed once the node shut the VM down
+func (s *VMServer) Closed() <-chan struct{} { return s.closed }
+
+func (s *VMServer) initialize(req *initializeRequest) (*emptyMsg, error) {
+	conn, err := dial(req.EngineAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &pluginLog{conn: conn}
+	decisionDispatcher := &triggers.EventDispatcher{}
+	decisionDispatcher.Initialize(log)
+	consensusDispatcher := &triggers.EventDispatcher{}
+	consensusDispatcher.Initialize(log)
+
+	// The keystore, shared memory and HTTP client of the node aren't available
+	// to plugins
+	s.ctx = &snow.Context{
+		NetworkID:           req.NetworkID,
+		ChainID:             req.ChainID,
+		NodeID:              req.NodeID,
+		Log:                 log,
+		ConsensusLog:        log,
+		DecisionDispatcher:  decisionDispatcher,
+		ConsensusDispatcher: consensusDispatcher,
+		BCLookup:            &ids.Aliaser{},
+		Metrics:             prometheus.NewRegistry(),
+	}
+	s.conn = conn
+
+	toEngine := make(chan common.Message, 1)
+	go s.forward(toEngine)
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	if err := s.vm.Initialize(s.ctx, rpcdb.NewClient(conn), req.GenesisBytes, toEngine, nil); err != nil {
+		s.close()
+		return nil, err
+	}
+	return &emptyMsg{}, nil
+}
+
+// forward the messages the VM sends to the consensus engine to the node,
+// until the VM is shut down
+func (s *VMServer) forward(toEngine <-chan common.Message) {
+	for {
+		select {
+		case msg := <-toEngine:
+			if err := s.conn.Invoke(context.Background(), notifyMethod, &notifyRequest{Message: msg}, &emptyMsg{}); err != nil {
+				s.ctx.Log.Debug("failed to send message %s to the consensus engine: %s", msg, err)
+			}
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+func (s *VMServer) shutdown(*emptyMsg) (*emptyMsg, error) {
+	if s.ctx == nil {
+		s.close()
+		return &emptyMsg{}, nil
+	}
+
+	s.ctx.Lock.Lock()
+	s.vm.Shutdown()
+	s.ctx.Lock.Unlock()
+
+	s.close()
+	return &emptyMsg{}, s.conn.Close()
+}
+
+func (s *VMServer) close() { s.closeOnce.Do(func() { close(s.closed) }) }
+
+func (s *VMServer) createHandlers(*emptyMsg) (*createHandlersReply, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	s.handlers = s.vm.CreateHandlers()
+	s.ctx.Lock.Unlock()
+
+	reply := &createHandlersReply{}
+	for extension, h := range s.handlers {
+		reply.Handlers = append(reply.Handlers, handler{
+			Extension:   extension,
+			LockOptions: h.LockOptions,
+		})
+	}
+	return reply, nil
+}
+
+// handle an API request that the node forwarded, with the lock the handler
+// asked for
+func (s *VMServer) handle(req *httpRequest) (*httpResponse, error) {
+	h, exists := s.handlers[req.Extension]
+	if !exists {
+		return nil, errUnknownHandler
+	}
+
+	r, err := http.NewRequest(req.Method, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header = req.Header
+	w := httptest.NewRecorder()
+
+	switch h.LockOptions {
+	case common.WriteLock:
+		s.ctx.Lock.Lock()
+		h.Handler.ServeHTTP(w, r)
+		s.ctx.Lock.Unlock()
+	case common.ReadLock:
+		s.ctx.Lock.RLock()
+		h.Handler.ServeHTTP(w, r)
+		s.ctx.Lock.RUnlock()
+	default:
+		h.Handler.ServeHTTP(w, r)
+	}
+
+	return &httpResponse{
+		Code:   w.Code,
+		Header: w.Header(),
+		Body:   w.Body.Bytes(),
+	}, nil
+}
+
+func (s *VMServer) chainVM() (snowmaneng.ChainVM, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+	vm, ok := s.vm.(snowmaneng.ChainVM)
+	if !ok {
+		return nil, errNotChainVM
+	}
+	return vm, nil
+}
+
+func (s *VMServer) dagVM() (avalanche.DAGVM, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+	vm, ok := s.vm.(avalanche.DAGVM)
+	if !ok {
+		return nil, errNotDAGVM
+	}
+	return vm, nil
+}
+
+// blockReply describes [blk] to the node. Only the parent of a processing
+// block is described, since it is the only one asked for. Assumes the lock
+// is held.
+func (s *VMServer) blockReply(blk snowman.Block) *blockReply {
+	reply := &blockReply{
+		ID:     blk.ID(),
+		Status: blk.Status(),
+		Bytes:  blk.Bytes(),
+	}
+	if reply.Status == choices.Processing {
+		s.blks[reply.ID.Key()] = blk
+		reply.ParentID = blk.Parent().ID()
+	}
+	return reply
+}
+
+func (s *VMServer) buildBlock(*emptyMsg) (*blockReply, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := vm.BuildBlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.blockReply(blk), nil
+}
+
+func (s *VMServer) parseBlock(req *bytesRequest) (*blockReply, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := vm.ParseBlock(req.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return s.blockReply(blk), nil
+}
+
+func (s *VMServer) getBlock(req *idRequest) (*blockReply, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := vm.GetBlock(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.blockReply(blk), nil
+}
+
+func (s *VMServer) setPreference(req *idRequest) (*emptyMsg, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	vm.SetPreference(req.ID)
+	return &emptyMsg{}, nil
+}
+
+func (s *VMServer) lastAccepted(*emptyMsg) (*idRequest, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	return &idRequest{ID: vm.LastAccepted()}, nil
+}
+
+func (s *VMServer) getBlockIDAtHeight(req *heightRequest) (*idRequest, error) {
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blkID, err := vm.GetBlockIDAtHeight(req.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &idRequest{ID: blkID}, nil
+}
+
+// block returns the block [blkID] that was sent to the node. Assumes the lock
+// is held.
+func (s *VMServer) block(blkID ids.ID) (snowman.Block, error) {
+	if blk, exists := s.blks[blkID.Key()]; exists {
+		return blk, nil
+	}
+	vm, err := s.chainVM()
+	if err != nil {
+		return nil, err
+	}
+	if blk, err := vm.GetBlock(blkID); err == nil {
+		return blk, nil
+	}
+	return nil, errUnknownDecision
+}
+
+func (s *VMServer) blockVerify(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := s.block(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &emptyMsg{}, blk.Verify()
+}
+
+func (s *VMServer) blockAccept(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := s.block(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	blk.Accept()
+	delete(s.blks, req.ID.Key())
+	return &emptyMsg{}, nil
+}
+
+func (s *VMServer) blockReject(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	blk, err := s.block(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	blk.Reject()
+	delete(s.blks, req.ID.Key())
+	return &emptyMsg{}, nil
+}
+
+// txReply describes [tx] to the node. Assumes the lock is held.
+func (s *VMServer) txReply(tx snowstorm.Tx) txReply {
+	status := tx.Status()
+	if status == choices.Processing {
+		s.txs[tx.ID().Key()] = tx
+	}
+	reply := txReply{
+		ID:       tx.ID(),
+		Status:   status,
+		Bytes:    tx.Bytes(),
+		InputIDs: tx.InputIDs().List(),
+	}
+	for _, dep := range tx.Dependencies() {
+		reply.Dependencies = append(reply.Dependencies, dep.ID())
+	}
+	return reply
+}
+
+func (s *VMServer) pendingTxs(*emptyMsg) (*pendingTxsReply, error) {
+	vm, err := s.dagVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	reply := &pendingTxsReply{}
+	for _, tx := range vm.PendingTxs() {
+		reply.Txs = append(reply.Txs, s.txReply(tx))
+	}
+	return reply, nil
+}
+
+func (s *VMServer) parseTx(req *bytesRequest) (*txReply, error) {
+	vm, err := s.dagVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	tx, err := vm.ParseTx(req.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	reply := s.txReply(tx)
+	return &reply, nil
+}
+
+func (s *VMServer) getTx(req *idRequest) (*txReply, error) {
+	vm, err := s.dagVM()
+	if err != nil {
+		return nil, err
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	tx, err := vm.GetTx(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	reply := s.txReply(tx)
+	return &reply, nil
+}
+
+// tx returns the transaction [txID] that was sent to the node. Assumes the
+// lock is held.
+func (s *VMServer) tx(txID ids.ID) (snowstorm.Tx, error) {
+	if tx, exists := s.txs[txID.Key()]; exists {
+		return tx, nil
+	}
+	vm, err := s.dagVM()
+	if err != nil {
+		return nil, err
+	}
+	if tx, err := vm.GetTx(txID); err == nil {
+		return tx, nil
+	}
+	return nil, errUnknownDecision
+}
+
+func (s *VMServer) txVerify(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	tx, err := s.tx(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &emptyMsg{}, tx.Verify()
+}
+
+func (s *VMServer) txAccept(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	tx, err := s.tx(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Accept()
+	delete(s.txs, req.ID.Key())
+	return &emptyMsg{}, nil
+}
+
+func (s *VMServer) txReject(req *idRequest) (*emptyMsg, error) {
+	if s.ctx == nil {
+		return nil, errNotInitialized
+	}
+
+	s.ctx.Lock.Lock()
+	defer s.ctx.Lock.Unlock()
+
+	tx, err := s.tx(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	tx.Reject()
+	delete(s.txs, req.ID.Key())
+	return &emptyMsg{}, nil
+}
+
+var vmServiceDesc = grpc.ServiceDesc{
+	ServiceName: VMService,
+	// Only a *VMServer can be registered, by RegisterVMServer
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unaryMethod(VMService, "Initialize", func() interface{} { return &initializeRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).initialize(req.(*initializeRequest))
+		}),
+		unaryMethod(VMService, "Shutdown", func() interface{} { return &emptyMsg{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).shutdown(req.(*emptyMsg))
+		}),
+		unaryMethod(VMService, "CreateHandlers", func() interface{} { return &emptyMsg{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).createHandlers(req.(*emptyMsg))
+		}),
+		unaryMethod(VMService, "Handle", func() interface{} { return &httpRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).handle(req.(*httpRequest))
+		}),
+		unaryMethod(VMService, "BuildBlock", func() interface{} { return &emptyMsg{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).buildBlock(req.(*emptyMsg))
+		}),
+		unaryMethod(VMService, "ParseBlock", func() interface{} { return &bytesRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).parseBlock(req.(*bytesRequest))
+		}),
+		unaryMethod(VMService, "GetBlock", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).getBlock(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "SetPreference", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).setPreference(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "LastAccepted", func() interface{} { return &emptyMsg{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).lastAccepted(req.(*emptyMsg))
+		}),
+		unaryMethod(VMService, "GetBlockIDAtHeight", func() interface{} { return &heightRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).getBlockIDAtHeight(req.(*heightRequest))
+		}),
+		unaryMethod(VMService, "BlockVerify", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).blockVerify(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "BlockAccept", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).blockAccept(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "BlockReject", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).blockReject(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "PendingTxs", func() interface{} { return &emptyMsg{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).pendingTxs(req.(*emptyMsg))
+		}),
+		unaryMethod(VMService, "ParseTx", func() interface{} { return &bytesRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).parseTx(req.(*bytesRequest))
+		}),
+		unaryMethod(VMService, "GetTx", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).getTx(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "TxVerify", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).txVerify(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "TxAccept", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).txAccept(req.(*idRequest))
+		}),
+		unaryMethod(VMService, "TxReject", func() interface{} { return &idRequest{} }, func(srv, req interface{}) (interface{}, error) {
+			return srv.(*VMServer).txReject(req.(*idRequest))
+		}),
+	},
+}
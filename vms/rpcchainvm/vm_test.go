@@ -0,0 +1,263 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rpcchainvm
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/ava-labs/gecko/database"
+	"github.com/ava-labs/gecko/database/memdb"
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/snow/consensus/snowman"
+	"github.com/ava-labs/gecko/snow/consensus/snowstorm"
+	"github.com/ava-labs/gecko/snow/engine/avalanche"
+	"github.com/ava-labs/gecko/snow/engine/common"
+	snowmaneng "github.com/ava-labs/gecko/snow/engine/snowman"
+)
+
+var errInvalidBlock = errors.New("invalid block")
+
+type testBlock struct {
+	id     ids.ID
+	parent snowman.Block
+	status choices.Status
+	bytes  []byte
+	valid  bool
+}
+
+func (b *testBlock) ID() ids.ID             { return b.id }
+func (b *testBlock) Parent() snowman.Block  { return b.parent }
+func (b *testBlock) Status() choices.Status { return b.status }
+func (b *testBlock) Bytes() []byte          { return b.bytes }
+func (b *testBlock) Accept()                { b.status = choices.Accepted }
+func (b *testBlock) Reject()                { b.status = choices.Rejected }
+func (b *testBlock) Verify() error {
+	if !b.valid {
+		return errInvalidBlock
+	}
+	return nil
+}
+
+// serve [vm] as a plugin would
+func serve(t *testing.T, vm common.VM) (*grpc.ClientConn, func()) {
+	dir, err := ioutil.TempDir("", "rpcchainvm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := listen(dir, vmSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterVMServer(server, NewServer(vm))
+	go server.Serve(listener)
+
+	addr, err := parseHandshake(handshake(listener.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, func() {
+		server.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestChainVM(t *testing.T) {
+	genesis := &testBlock{id: ids.NewID([32]byte{1}), status: choices.Accepted, bytes: []byte{1}}
+	blk := &testBlock{id: ids.NewID([32]byte{2}), parent: genesis, status: choices.Processing, bytes: []byte{2}, valid: true}
+
+	vm := &snowmaneng.VMTest{}
+	vm.T = t
+	vm.Default(true)
+
+	var pluginDB database.Database
+	vm.InitializeF = func(ctx *snow.Context, db database.Database, genesisBytes []byte, toEngine chan<- common.Message, _ []*common.Fx) error {
+		if !bytes.Equal(genesisBytes, genesis.bytes) {
+			t.Fatalf("Plugin was initialized with the wrong genesis")
+		}
+		ctx.Log.Info("initialized")
+		pluginDB = db
+		toEngine <- common.PendingTxs
+		return db.Put([]byte("key"), []byte("value"))
+	}
+	vm.CreateHandlersF = func() map[string]*common.HTTPHandler {
+		return map[string]*common.HTTPHandler{
+			"/echo": {Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+				body := &bytes.Buffer{}
+				body.ReadFrom(r.Body)
+				w.Write(body.Bytes())
+			})},
+		}
+	}
+	vm.BuildBlockF = func() (snowman.Block, error) { return blk, nil }
+	vm.GetBlockF = func(blkID ids.ID) (snowman.Block, error) {
+		if blkID.Equals(genesis.id) {
+			return genesis, nil
+		}
+		return nil, errors.New("unknown block")
+	}
+	vm.LastAcceptedF = func() ids.ID { return genesis.id }
+	vm.ShutdownF = func() {}
+
+	conn, stop := serve(t, vm)
+	defer stop()
+
+	db := memdb.New()
+	toEngine := make(chan common.Message, 1)
+	client := NewClient(conn)
+	if err := client.Initialize(snow.DefaultContextTest(), db, genesis.bytes, toEngine, nil); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := db.Get([]byte("key")); err != nil || !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Plugin should have written to the node's database")
+	}
+	if msg := <-toEngine; msg != common.PendingTxs {
+		t.Fatalf("Should have forwarded %s but forwarded %s", common.PendingTxs, msg)
+	}
+
+	handlers := client.CreateHandlers()
+	echo, exists := handlers["/echo"]
+	if !exists {
+		t.Fatalf("Should have returned a handler for /echo")
+	}
+	w := httptest.NewRecorder()
+	echo.Handler.ServeHTTP(w, httptest.NewRequest("POST", "/ext/bc/chain/echo", bytes.NewBufferString("hello")))
+	if w.Code != http.StatusTeapot || w.Body.String() != "hello" {
+		t.Fatalf("Should have returned the plugin's response but returned %d %q", w.Code, w.Body.String())
+	}
+
+	if lastAccepted := client.LastAccepted(); !lastAccepted.Equals(genesis.id) {
+		t.Fatalf("Last accepted should be %s but is %s", genesis.id, lastAccepted)
+	}
+
+	built, err := client.BuildBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !built.ID().Equals(blk.id) || !bytes.Equal(built.Bytes(), blk.bytes) || built.Status() != choices.Processing {
+		t.Fatalf("Built the wrong block")
+	}
+	if parent := built.Parent(); !parent.ID().Equals(genesis.id) || parent.Status() != choices.Accepted {
+		t.Fatalf("Built block has the wrong parent")
+	}
+	if err := built.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	built.Accept()
+	if blk.Status() != choices.Accepted || built.Status() != choices.Accepted {
+		t.Fatalf("Block should have been accepted in the plugin")
+	}
+
+	client.Shutdown()
+	if _, err := pluginDB.Get([]byte("key")); err == nil {
+		t.Fatalf("Plugin's database should have been closed when the VM was shut down")
+	}
+}
+
+func TestDAGVM(t *testing.T) {
+	dep := &snowstorm.TestTx{Identifier: ids.NewID([32]byte{1}), Stat: choices.Accepted, Bits: []byte{1}}
+	tx := &snowstorm.TestTx{
+		Identifier: ids.NewID([32]byte{2}),
+		Deps:       []snowstorm.Tx{dep},
+		Stat:       choices.Processing,
+		Bits:       []byte{2},
+	}
+	tx.Ins.Add(ids.NewID([32]byte{3}))
+
+	vm := &avalanche.VMTest{}
+	vm.T = t
+	vm.Default(true)
+	vm.InitializeF = func(*snow.Context, database.Database, []byte, chan<- common.Message, []*common.Fx) error { return nil }
+	vm.PendingTxsF = func() []snowstorm.Tx { return []snowstorm.Tx{tx} }
+	vm.GetTxF = func(txID ids.ID) (snowstorm.Tx, error) {
+		if txID.Equals(dep.ID()) {
+			return dep, nil
+		}
+		return nil, errors.New("unknown tx")
+	}
+
+	conn, stop := serve(t, vm)
+	defer stop()
+
+	client := NewDAGClient(conn)
+	if err := client.Initialize(snow.DefaultContextTest(), memdb.New(), nil, make(chan common.Message, 1), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	txs := client.PendingTxs()
+	if len(txs) != 1 {
+		t.Fatalf("Should have returned 1 pending tx but returned %d", len(txs))
+	}
+	pending := txs[0]
+	if !pending.ID().Equals(tx.ID()) || !pending.InputIDs().Equals(tx.InputIDs()) {
+		t.Fatalf("Returned the wrong pending tx")
+	}
+	if deps := pending.Dependencies(); len(deps) != 1 || !deps[0].ID().Equals(dep.ID()) || deps[0].Status() != choices.Accepted {
+		t.Fatalf("Pending tx has the wrong dependencies")
+	}
+	pending.Reject()
+	if tx.Status() != choices.Rejected || pending.Status() != choices.Rejected {
+		t.Fatalf("Tx should have been rejected in the plugin")
+	}
+}
+
+func TestParseHandshake(t *testing.T) {
+	if _, err := parseHandshake("gecko-rpcchainvm|0|127.0.0.1:9000\n"); err == nil {
+		t.Fatalf("Should have failed to parse a handshake of another protocol version")
+	}
+	if _, err := parseHandshake("hello\n"); err == nil {
+		t.Fatalf("Should have failed to parse a malformed handshake")
+	}
+}
+
+func TestListen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpcchainvm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listener, err := listen(dir, engineSocket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	for path, expected := range map[string]os.FileMode{dir: 0700, filepath.Join(dir, engineSocket): 0600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != expected {
+			t.Fatalf("%s has mode %o ; Expected %o", path, mode, expected)
+		}
+	}
+}
+
+func TestServeNotStartedByNode(t *testing.T) {
+	if dir, ok := os.LookupEnv(dirEnv); ok {
+		defer os.Setenv(dirEnv, dir)
+	}
+	os.Unsetenv(dirEnv)
+
+	if err := Serve(&snowmaneng.VMTest{}); err != errNotStartedByNode {
+		t.Fatalf("Serve returned %v ; Expected %s", err, errNotStartedByNode)
+	}
+}
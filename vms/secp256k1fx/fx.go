@@ -6,11 +6,16 @@ package secp256k1fx
 import (
 	"errors"
 
+	"github.com/ava-labs/gecko/cache"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/vms/components/verify"
 )
 
+// The number of recovered public keys to cache. Credentials are recovered
+// repeatedly as a tx is gossiped, verified and accepted.
+const recoverCacheSize = 2048
+
 var (
 	errWrongVMType         = errors.New("wrong vm type")
 	errWrongTxType         = errors.New("wrong tx type")
@@ -31,29 +36,44 @@ var (
 	errTooFewSigners                  = errors.New("input has less signers than expected")
 	errInputCredentialSignersMismatch = errors.New("input expected a different number of signers than provided in the credential")
 	errWrongSigner                    = errors.New("credential does not produce expected signer")
+	errNoBatch                        = errors.New("no batch of signatures was started")
 )
 
 // Fx ...
 type Fx struct {
 	vm          VM
 	secpFactory crypto.FactorySECP256K1R
+
+	// While a batch is started, signatures are added to the last batch rather
+	// than being verified immediately
+	batches []*crypto.BatchVerifierSECP256K1R
 }
 
 // Initialize ...
 func (fx *Fx) Initialize(vmIntf interface{}) error {
-	vm, ok := vmIntf.(VM)
-	if !ok {
-		return errWrongVMType
+	if err := fx.InitializeVM(vmIntf); err != nil {
+		return err
 	}
 
-	c := vm.Codec()
+	c := fx.vm.Codec()
 	c.RegisterType(&MintOutput{})
 	c.RegisterType(&TransferOutput{})
 	c.RegisterType(&MintInput{})
 	c.RegisterType(&TransferInput{})
 	c.RegisterType(&Credential{})
+	return nil
+}
 
+// InitializeVM prepares this fx to verify credentials for [vmIntf] without
+// registering its types with the VM's codec. Fxs that verify the credentials
+// of their outputs with this fx initialize it this way.
+func (fx *Fx) InitializeVM(vmIntf interface{}) error {
+	vm, ok := vmIntf.(VM)
+	if !ok {
+		return errWrongVMType
+	}
 	fx.vm = vm
+	fx.secpFactory = crypto.FactorySECP256K1R{Cache: cache.LRU{Size: recoverCacheSize}}
 	return nil
 }
 
@@ -107,7 +127,7 @@ func (fx *Fx) verifyOperation(tx Tx, utxo *MintOutput, in *MintInput, cred *Cred
 		return errWrongMintCreated
 	}
 
-	return fx.verifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
 }
 
 // VerifyTransfer ...
@@ -144,10 +164,12 @@ func (fx *Fx) verifyTransfer(tx Tx, utxo *TransferOutput, in *TransferInput, cre
 		return errTimelocked
 	}
 
-	return fx.verifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
+	return fx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, cred)
 }
 
-func (fx *Fx) verifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Credential) error {
+// VerifyCredentials returns nil if [cred] proves that [in] is signed by the
+// owners of [out], as required to spend [out] in [tx]
+func (fx *Fx) VerifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Credential) error {
 	numSigs := len(in.SigIndices)
 	switch {
 	case out.Threshold < uint32(numSigs):
@@ -164,6 +186,11 @@ func (fx *Fx) verifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Crede
 	for i, index := range in.SigIndices {
 		sig := cred.Sigs[i]
 
+		if numBatches := len(fx.batches); numBatches > 0 {
+			fx.batches[numBatches-1].Add(txHash, sig[:], out.Addrs[index])
+			continue
+		}
+
 		pk, err := fx.secpFactory.RecoverHashPublicKey(txHash, sig[:])
 		if err != nil {
 			return err
@@ -177,3 +204,23 @@ func (fx *Fx) verifyCredentials(tx Tx, out *OutputOwners, in *Input, cred *Crede
 
 	return nil
 }
+
+// StartBatch defers the verification of signatures by this fx until the
+// matching call to VerifyBatch. Batches may be nested, in which case each
+// batch only contains the signatures added since it was started.
+func (fx *Fx) StartBatch() {
+	fx.batches = append(fx.batches, crypto.NewBatchVerifierSECP256K1R(&fx.secpFactory))
+}
+
+// VerifyBatch ends the last started batch, and returns nil if every signature
+// added to it is valid
+func (fx *Fx) VerifyBatch() error {
+	numBatches := len(fx.batches)
+	if numBatches == 0 {
+		return errNoBatch
+	}
+	batch := fx.batches[numBatches-1]
+	fx.batches[numBatches-1] = nil
+	fx.batches = fx.batches[:numBatches-1]
+	return batch.Verify()
+}
@@ -548,6 +548,73 @@ func TestFxVerifyTransferWrongSigner(t *testing.T) {
 	}
 }
 
+func TestFxVerifyTransferBatch(t *testing.T) {
+	vm := testVM{}
+	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
+	vm.clock.Set(date)
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{
+		bytes: txBytes,
+	}
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs: []ids.ShortID{
+				ids.NewShortID(addrBytes),
+			},
+		},
+	}
+	wrongOut := &TransferOutput{
+		Amt: 1,
+		OutputOwners: OutputOwners{
+			Threshold: 1,
+			Addrs: []ids.ShortID{
+				ids.ShortEmpty,
+			},
+		},
+	}
+	in := &TransferInput{
+		Amt: 1,
+		Input: Input{
+			SigIndices: []uint32{0},
+		},
+	}
+	cred := &Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{
+			sigBytes,
+		},
+	}
+
+	if err := fx.VerifyBatch(); err != errNoBatch {
+		t.Fatalf("Should have errored due to no batch being started")
+	}
+
+	fx.StartBatch()
+	if err := fx.VerifyTransfer(tx, out, in, cred); err != nil {
+		t.Fatal(err)
+	}
+
+	// A nested batch only verifies its own signatures
+	fx.StartBatch()
+	if err := fx.VerifyTransfer(tx, wrongOut, in, cred); err != nil {
+		t.Fatalf("Signatures should have been deferred to the batch but errored with %s", err)
+	}
+	if err := fx.VerifyBatch(); err == nil {
+		t.Fatalf("Should have errored due to a wrong signer")
+	}
+
+	if err := fx.VerifyBatch(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fx.VerifyTransfer(tx, wrongOut, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a wrong signer once the batches ended")
+	}
+}
+
 func TestFxVerifyOperation(t *testing.T) {
 	vm := testVM{}
 	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
@@ -107,7 +107,7 @@ func ConsensusLeader(numBlocks, numTxsPerBlock int, b *testing.B) {
 		handler.Initialize(&engine, msgChan, 1000)
 
 		// Allow incoming messages to be routed to the new chain
-		router.AddChain(handler)
+		router.AddChain(handler, 1)
 		go ctx.Log.RecoverAndPanic(handler.Dispatch)
 
 		engine.Startup()
@@ -238,7 +238,7 @@ func ConsensusFollower(numBlocks, numTxsPerBlock int, b *testing.B) {
 		handler.Initialize(&engine, msgChan, 1000)
 
 		// Allow incoming messages to be routed to the new chain
-		router.AddChain(handler)
+		router.AddChain(handler, 1)
 		go ctx.Log.RecoverAndPanic(handler.Dispatch)
 
 		engine.Startup()
@@ -228,6 +228,15 @@ func (lb *LiveBlock) VerifyState() error {
 		lb.validity = err
 	}
 
+	// The parent is the last accepted block in its database
+	if height, err := lb.vm.state.LastAcceptedHeight(lb.db); err != nil {
+		lb.validity = err
+	} else if err := lb.vm.state.SetLastAcceptedHeight(lb.db, height+1); err != nil {
+		lb.validity = err
+	} else if err := lb.vm.state.SetBlockIDAtHeight(lb.db, height+1, lb.ID()); err != nil {
+		lb.validity = err
+	}
+
 	// If this block is valid, add it as a child of its parent
 	// and add this block to currentBlocks
 	if lb.validity == nil {
@@ -16,11 +16,14 @@ const (
 	statusID
 	lastAcceptedID
 	dbInitializedID
+	lastAcceptedHeightID
+	heightID
 )
 
 var (
-	lastAccepted  = ids.Empty.Prefix(lastAcceptedID)
-	dbInitialized = ids.Empty.Prefix(dbInitializedID)
+	lastAccepted       = ids.Empty.Prefix(lastAcceptedID)
+	dbInitialized      = ids.Empty.Prefix(dbInitializedID)
+	lastAcceptedHeight = ids.Empty.Prefix(lastAcceptedHeightID)
 )
 
 // prefixedState wraps a state object. By prefixing the state, there will be no
@@ -70,6 +73,27 @@ func (s *prefixedState) SetLastAccepted(db database.Database, id ids.ID) error {
 	return s.state.SetAlias(db, lastAccepted, id)
 }
 
+// LastAcceptedHeight returns the height of the last accepted block from
+// storage.
+func (s *prefixedState) LastAcceptedHeight(db database.Database) (uint64, error) {
+	return s.state.Height(db, lastAcceptedHeight)
+}
+
+// SetLastAcceptedHeight saves the height of the last accepted block to storage.
+func (s *prefixedState) SetLastAcceptedHeight(db database.Database, height uint64) error {
+	return s.state.SetHeight(db, lastAcceptedHeight, height)
+}
+
+// BlockIDAtHeight returns the ID of the accepted block at [height] from storage.
+func (s *prefixedState) BlockIDAtHeight(db database.Database, height uint64) (ids.ID, error) {
+	return s.state.Alias(db, ids.Empty.Prefix(heightID, height))
+}
+
+// SetBlockIDAtHeight saves the ID of the accepted block at [height] to storage.
+func (s *prefixedState) SetBlockIDAtHeight(db database.Database, height uint64, id ids.ID) error {
+	return s.state.SetAlias(db, ids.Empty.Prefix(heightID, height), id)
+}
+
 // DBInitialized returns the status of this database. If the database is
 // uninitialized, the status will be unknown.
 func (s *prefixedState) DBInitialized(db database.Database) (choices.Status, error) {
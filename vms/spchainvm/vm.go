@@ -191,6 +191,11 @@ func (vm *VM) SetPreference(preferred ids.ID) { vm.preferred = preferred }
 // LastAccepted returns the last accepted block ID
 func (vm *VM) LastAccepted() ids.ID { return vm.lastAccepted }
 
+// GetBlockIDAtHeight implements the snowman.ChainVM interface
+func (vm *VM) GetBlockIDAtHeight(height uint64) (ids.ID, error) {
+	return vm.state.BlockIDAtHeight(vm.baseDB, height)
+}
+
 // CreateHandlers makes new service objects with references to the vm
 func (vm *VM) CreateHandlers() map[string]*common.HTTPHandler {
 	newServer := rpc.NewServer()
@@ -218,6 +223,9 @@ func (vm *VM) CreateStaticHandlers() map[string]*common.HTTPHandler {
 // IssueTx ...
 // TODO: Remove this
 func (vm *VM) IssueTx(b []byte, onDecide func(choices.Status)) (ids.ID, error) {
+	if vm.ctx.ReadOnly {
+		return ids.ID{}, snow.ErrReadOnly
+	}
 	codec := Codec{}
 	tx, err := codec.UnmarshalTx(b)
 	if err != nil {
@@ -283,6 +291,8 @@ func (vm *VM) initState(genesisBytes []byte) error {
 	errs.Add(vm.state.SetBlock(vdb, block.ID(), block))
 	errs.Add(vm.state.SetStatus(vdb, block.ID(), choices.Accepted))
 	errs.Add(vm.state.SetLastAccepted(vdb, block.ID()))
+	errs.Add(vm.state.SetLastAcceptedHeight(vdb, 0))
+	errs.Add(vm.state.SetBlockIDAtHeight(vdb, 0, block.ID()))
 	for _, account := range accounts {
 		errs.Add(vm.state.SetAccount(vdb, account.ID().LongID(), account))
 	}
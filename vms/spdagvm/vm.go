@@ -94,6 +94,11 @@ func (vm *VM) Initialize(
 	vm.ctx = ctx
 	vm.baseDB = db
 	vm.db = versiondb.New(db)
+	if ctx.Metrics != nil {
+		if err := vm.db.Register(ctx.Namespace, ctx.Metrics); err != nil {
+			ctx.Log.Error("Failed to register versiondb statistics due to %s", err)
+		}
+	}
 	vm.state = &prefixedState{
 		state: &state{
 			c:  &cache.LRU{Size: stateCacheSize},
@@ -131,6 +136,9 @@ func (vm *VM) Initialize(
 // Shutdown implements the avalanche.DAGVM interface
 func (vm *VM) Shutdown() {
 	vm.timer.Stop()
+	if err := vm.db.Commit(); err != nil {
+		vm.ctx.Log.Error("Flushing the database failed with %s", err)
+	}
 	if err := vm.baseDB.Close(); err != nil {
 		vm.ctx.Log.Error("Closing the database failed with %s", err)
 	}
@@ -465,6 +473,9 @@ func (vm *VM) FlushTxs() {
 
 // IssueTx implements the avalanche.DAGVM interface
 func (vm *VM) IssueTx(b []byte, finalized func(choices.Status)) (ids.ID, error) {
+	if vm.ctx.ReadOnly {
+		return ids.ID{}, snow.ErrReadOnly
+	}
 	tx, err := vm.parseTx(b, finalized)
 	if err != nil {
 		return ids.ID{}, err
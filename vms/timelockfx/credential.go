@@ -0,0 +1,27 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timelockfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilCredential = errors.New("nil credential")
+)
+
+// Credential holds the signatures that authorize a TransferInput
+type Credential struct {
+	secp256k1fx.Credential `serialize:"true"`
+}
+
+// Verify ...
+func (cr *Credential) Verify() error {
+	if cr == nil {
+		return errNilCredential
+	}
+	return cr.Credential.Verify()
+}
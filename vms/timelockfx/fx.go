@@ -0,0 +1,100 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timelockfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/components/verify"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errWrongVMType         = errors.New("wrong vm type")
+	errWrongTxType         = errors.New("wrong tx type")
+	errWrongUTXOType       = errors.New("wrong utxo type")
+	errWrongInputType      = errors.New("wrong input type")
+	errWrongCredentialType = errors.New("wrong credential type")
+
+	errOperationNotSupported = errors.New("time locked outputs don't support operations")
+
+	errWrongAmounts = errors.New("input is consuming a different amount than expected")
+	errTimelocked   = errors.New("output is time locked")
+)
+
+// Fx is a feature extension for outputs that can't be spent before a given
+// time. The time is checked against the chain's clock when the output is
+// spent.
+type Fx struct {
+	vm VM
+
+	// Only used to verify signatures, so its types aren't registered
+	secpFx secp256k1fx.Fx
+}
+
+// Initialize ...
+func (fx *Fx) Initialize(vmIntf interface{}) error {
+	vm, ok := vmIntf.(VM)
+	if !ok {
+		return errWrongVMType
+	}
+
+	c := vm.Codec()
+	c.RegisterType(&TransferOutput{})
+	c.RegisterType(&TransferInput{})
+	c.RegisterType(&Credential{})
+
+	fx.vm = vm
+	return fx.secpFx.InitializeVM(vm)
+}
+
+// VerifyOperation ...
+func (fx *Fx) VerifyOperation(interface{}, []interface{}, []interface{}, []interface{}, []interface{}) error {
+	return errOperationNotSupported
+}
+
+// VerifyTransfer ...
+func (fx *Fx) VerifyTransfer(txIntf, utxoIntf, inIntf, credIntf interface{}) error {
+	tx, ok := txIntf.(secp256k1fx.Tx)
+	if !ok {
+		return errWrongTxType
+	}
+	utxo, ok := utxoIntf.(*TransferOutput)
+	if !ok {
+		return errWrongUTXOType
+	}
+	in, ok := inIntf.(*TransferInput)
+	if !ok {
+		return errWrongInputType
+	}
+	cred, ok := credIntf.(*Credential)
+	if !ok {
+		return errWrongCredentialType
+	}
+	return fx.verifyTransfer(tx, utxo, in, cred)
+}
+
+func (fx *Fx) verifyTransfer(tx secp256k1fx.Tx, utxo *TransferOutput, in *TransferInput, cred *Credential) error {
+	if err := verify.All(utxo, in, cred); err != nil {
+		return err
+	}
+
+	clock := fx.vm.Clock()
+	switch {
+	case utxo.Amt != in.Amt:
+		return errWrongAmounts
+	case !utxo.Unlocked(clock.Unix()):
+		return errTimelocked
+	}
+
+	return fx.secpFx.VerifyCredentials(tx, &utxo.OutputOwners, &in.Input, &cred.Credential)
+}
+
+// StartBatch defers the verification of signatures by this fx until the
+// matching call to VerifyBatch
+func (fx *Fx) StartBatch() { fx.secpFx.StartBatch() }
+
+// VerifyBatch ends the last started batch, and returns nil if every signature
+// added to it is valid
+func (fx *Fx) VerifyBatch() error { return fx.secpFx.VerifyBatch() }
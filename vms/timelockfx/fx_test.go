@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timelockfx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/utils/crypto"
+	"github.com/ava-labs/gecko/utils/hashing"
+	"github.com/ava-labs/gecko/utils/timer"
+	"github.com/ava-labs/gecko/vms/components/codec"
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	txBytes  = []byte{0, 1, 2, 3, 4, 5}
+	sigBytes = [crypto.SECP256K1RSigLen]byte{
+		0x0e, 0x33, 0x4e, 0xbc, 0x67, 0xa7, 0x3f, 0xe8,
+		0x24, 0x33, 0xac, 0xa3, 0x47, 0x88, 0xa6, 0x3d,
+		0x58, 0xe5, 0x8e, 0xf0, 0x3a, 0xd5, 0x84, 0xf1,
+		0xbc, 0xa3, 0xb2, 0xd2, 0x5d, 0x51, 0xd6, 0x9b,
+		0x0f, 0x28, 0x5d, 0xcd, 0x3f, 0x71, 0x17, 0x0a,
+		0xf9, 0xbf, 0x2d, 0xb1, 0x10, 0x26, 0x5c, 0xe9,
+		0xdc, 0xc3, 0x9d, 0x7a, 0x01, 0x50, 0x9d, 0xe8,
+		0x35, 0xbd, 0xcb, 0x29, 0x3a, 0xd1, 0x49, 0x32,
+		0x00,
+	}
+	addrBytes = [hashing.AddrLen]byte{
+		0x01, 0x5c, 0xce, 0x6c, 0x55, 0xd6, 0xb5, 0x09,
+		0x84, 0x5c, 0x8c, 0x4e, 0x30, 0xbe, 0xd9, 0x8d,
+		0x39, 0x1a, 0xe7, 0xf0,
+	}
+)
+
+type testVM struct{ clock timer.Clock }
+
+func (vm *testVM) Codec() codec.Codec { return codec.NewDefault() }
+
+func (vm *testVM) Clock() *timer.Clock { return &vm.clock }
+
+type testTx struct{ bytes []byte }
+
+func (tx *testTx) UnsignedBytes() []byte { return tx.bytes }
+
+func TestFxInitializeInvalid(t *testing.T) {
+	fx := Fx{}
+	if err := fx.Initialize(nil); err == nil {
+		t.Fatalf("Should have returned an error")
+	}
+}
+
+func TestFxVerifyTransfer(t *testing.T) {
+	vm := testVM{}
+	date := time.Date(2019, time.January, 19, 16, 25, 17, 3, time.UTC)
+	vm.clock.Set(date)
+	fx := Fx{}
+	if err := fx.Initialize(&vm); err != nil {
+		t.Fatal(err)
+	}
+	tx := &testTx{bytes: txBytes}
+	out := &TransferOutput{
+		Amt:      1,
+		Locktime: uint64(date.Unix()) + 1,
+		OutputOwners: secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.NewShortID(addrBytes)},
+		},
+	}
+	in := &TransferInput{
+		Amt:   1,
+		Input: secp256k1fx.Input{SigIndices: []uint32{0}},
+	}
+	cred := &Credential{Credential: secp256k1fx.Credential{
+		Sigs: [][crypto.SECP256K1RSigLen]byte{sigBytes},
+	}}
+
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to a time locked output")
+	}
+
+	vm.clock.Set(date.Add(time.Second))
+	if err := fx.VerifyTransfer(tx, out, in, cred); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fx.VerifyTransfer(&testTx{bytes: []byte{6}}, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to the wrong signer")
+	}
+	in.Amt = 2
+	if err := fx.VerifyTransfer(tx, out, in, cred); err == nil {
+		t.Fatalf("Should have errored due to mismatched amounts")
+	}
+}
+
+func TestFxVerifyOperation(t *testing.T) {
+	fx := Fx{}
+	if err := fx.VerifyOperation(nil, nil, nil, nil, nil); err == nil {
+		t.Fatalf("Should have errored because operations aren't supported")
+	}
+}
+
+func TestTransferOutputVerify(t *testing.T) {
+	out := &TransferOutput{
+		Amt: 1,
+		OutputOwners: secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.NewShortID(addrBytes)},
+		},
+	}
+	if err := out.Verify(); err == nil {
+		t.Fatalf("Should have errored due to no locktime")
+	}
+	out.Locktime = 1
+	if err := out.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
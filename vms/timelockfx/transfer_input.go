@@ -0,0 +1,36 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timelockfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilInput     = errors.New("nil input")
+	errNoValueInput = errors.New("input has no value")
+)
+
+// TransferInput spends a TransferOutput
+type TransferInput struct {
+	Amt               uint64 `serialize:"true"`
+	secp256k1fx.Input `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this input produces
+func (in *TransferInput) Amount() uint64 { return in.Amt }
+
+// Verify this input is syntactically valid
+func (in *TransferInput) Verify() error {
+	switch {
+	case in == nil:
+		return errNilInput
+	case in.Amt == 0:
+		return errNoValueInput
+	default:
+		return in.Input.Verify()
+	}
+}
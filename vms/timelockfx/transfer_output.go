@@ -0,0 +1,45 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package timelockfx
+
+import (
+	"errors"
+
+	"github.com/ava-labs/gecko/vms/secp256k1fx"
+)
+
+var (
+	errNilOutput     = errors.New("nil output")
+	errNoValueOutput = errors.New("output has no value")
+	errNoLocktime    = errors.New("output isn't time locked")
+)
+
+// TransferOutput is an output that can't be spent before [Locktime], a unix
+// timestamp. Once unlocked, it's spent like a secp256k1fx output.
+type TransferOutput struct {
+	Amt      uint64 `serialize:"true"`
+	Locktime uint64 `serialize:"true"`
+
+	secp256k1fx.OutputOwners `serialize:"true"`
+}
+
+// Amount returns the quantity of the asset this output consumes
+func (out *TransferOutput) Amount() uint64 { return out.Amt }
+
+// Unlocked returns true if this output can be spent at [time]
+func (out *TransferOutput) Unlocked(time uint64) bool { return out.Locktime <= time }
+
+// Verify ...
+func (out *TransferOutput) Verify() error {
+	switch {
+	case out == nil:
+		return errNilOutput
+	case out.Amt == 0:
+		return errNoValueOutput
+	case out.Locktime == 0:
+		return errNoLocktime
+	default:
+		return out.OutputOwners.Verify()
+	}
+}
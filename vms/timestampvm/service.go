@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow"
 
 	"github.com/ava-labs/gecko/utils/formatting"
 )
@@ -33,6 +34,9 @@ type ProposeBlockReply struct{ Success bool }
 // ProposeBlock is an API method to propose a new block whose data is [args].Data.
 // [args].Data must be a string repr. of a 32 byte array
 func (s *Service) ProposeBlock(_ *http.Request, args *ProposeBlockArgs, reply *ProposeBlockReply) error {
+	if s.vm.Ctx.ReadOnly {
+		return snow.ErrReadOnly
+	}
 	byteFormatter := formatting.CB58{}
 	if err := byteFormatter.FromString(args.Data); err != nil {
 		return errBadData
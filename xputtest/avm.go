@@ -4,6 +4,9 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/ava-labs/salticidae-go"
@@ -13,12 +16,22 @@ import (
 	"github.com/ava-labs/gecko/networking"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/formatting"
+	"github.com/ava-labs/gecko/utils/hashing"
 	"github.com/ava-labs/gecko/utils/timer"
 	"github.com/ava-labs/gecko/vms/avm"
 	"github.com/ava-labs/gecko/vms/platformvm"
 	"github.com/ava-labs/gecko/xputtest/avmwallet"
+	"github.com/ava-labs/gecko/xputtest/load"
 )
 
+// stakeStartDelay is how long, after the earliest start time the platform
+// chain accepts, a delegation issued by the test starts. Gives the platform
+// chain time to propose it.
+const stakeStartDelay = 30 * time.Second
+
+// platformChainID is the ID of the platform chain
+var platformChainID = ids.Empty
+
 // benchmark an instance of the avm
 func (n *network) benchmarkAVM(chain *platformvm.CreateChainTx) {
 	genesisBytes := chain.GenesisData
@@ -52,62 +65,195 @@ func (n *network) benchmarkAVM(chain *platformvm.CreateChainTx) {
 
 	assetID := genesisTx.ID()
 
-	n.log.AssertNoError(wallet.GenerateTxs(config.NumTxs, assetID))
+	numTransfers, numConflicts := 0, 0
+	if config.Mix[load.Transfer] > 0 {
+		numTransfers = config.NumTxs
+	}
+	if weight := config.Mix[load.Conflict]; weight > 0 {
+		total := uint64(0)
+		for _, w := range config.Mix {
+			total += w
+		}
+		// Enough pairs for the expected share of issuances, rounded up
+		numConflicts = int((uint64(config.NumTxs)*weight + total - 1) / total)
+	}
 
-	go n.log.RecoverAndPanic(func() { n.IssueAVM(chain.ID(), assetID, wallet) })
-}
+	n.log.AssertNoError(wallet.GenerateTxs(numTransfers, assetID))
+	if numConflicts > 0 {
+		_, err := wallet.GenerateConflicts(numConflicts, assetID)
+		n.log.AssertNoError(err)
+	}
 
-// issue transactions to the instance of the avm funded by the provided wallet
-func (n *network) IssueAVM(chainID ids.ID, assetID ids.ID, wallet *avmwallet.Wallet) {
-	n.log.Debug("Issuing with %d", wallet.Balance(assetID))
-	numAccepted := 0
-	numPending := 0
+	issuer := &avmIssuer{
+		net:     n,
+		chainID: chain.ID(),
+		wallet:  wallet,
+	}
+	if config.Mix[load.Stake] > 0 {
+		n.log.AssertNoError(issuer.initStaking(sk.(*crypto.PrivateKeySECP256K1R)))
+	}
 
-	n.decided <- ids.ID{}
+	generator, err := load.NewGenerator(n.log, load.Config{
+		Mix:            config.Mix,
+		Ramp:           config.Ramp,
+		NumTxs:         config.NumTxs,
+		MaxOutstanding: config.MaxOutstandingTxs,
+		Timeout:        config.TxTimeout,
+		Seed:           config.Seed,
+	}, issuer)
+	n.log.AssertNoError(err)
+	n.generator = generator
 
-	// track the last second of transactions
-	meter := timer.TimedMeter{Duration: time.Second}
-	for d := range n.decided {
-		// display the TPS every 1000 txs
-		if numAccepted%1000 == 0 {
-			n.log.Info("TPS: %d", meter.Ticks())
-		}
+	go n.log.RecoverAndPanic(func() { n.runLoadTest(generator) })
+}
+
+// run [generator] until the load test is done, and report its results
+func (n *network) runLoadTest(generator *load.Generator) {
+	generator.Run(nil)
 
-		// d is the ID of the tx that was accepted
-		if !d.IsZero() {
-			meter.Tick()
-			n.log.Debug("Finalized %s", d)
-			numAccepted++
-			numPending--
+	report := generator.Report()
+	n.log.Info("done with test:\n%s", &report)
+	if config.ReportFile != "" {
+		if err := ioutil.WriteFile(config.ReportFile, []byte(report.String()+"\n"), 0644); err != nil {
+			n.log.Error("failed to write the report to %s due to %s", config.ReportFile, err)
 		}
+	}
+	net.ec.Stop()
+}
 
-		// Issue all the txs that we can right now
-		for numPending < config.MaxOutstandingTxs && wallet.Balance(assetID) > 0 && numAccepted+numPending < config.NumTxs {
-			tx := wallet.NextTx()
-			n.log.AssertTrue(tx != nil, "Tx creation failed")
+// avmIssuer issues the transactions of a load test to the avm and, to stake,
+// to the platform chain
+type avmIssuer struct {
+	net     *network
+	chainID ids.ID
+	wallet  *avmwallet.Wallet
 
-			// send the IssueTx message
-			it, err := n.build.IssueTx(chainID, tx.Bytes())
-			n.log.AssertNoError(err)
-			ds := it.DataStream()
-			ba := salticidae.NewByteArrayMovedFromDataStream(ds, false)
-			newMsg := salticidae.NewMsgMovedFromByteArray(networking.IssueTx, ba, false)
+	// Number of transfers issued so far. A conflict can be issued once the
+	// transfer that produced the UTXO it spends has been issued.
+	numTransfers int
 
-			n.conn.GetNet().SendMsg(newMsg, n.conn)
+	// Staking state. [stakeKey] is nil if staking isn't enabled.
+	clock        timer.Clock
+	stakeKey     *crypto.PrivateKeySECP256K1R
+	stakeNonce   uint64
+	validatorID  ids.ShortID
+	validatorEnd time.Time
+}
+
+// initStaking prepares to delegate, from the account controlled by [key], to
+// the genesis validator that validates the longest
+func (i *avmIssuer) initStaking(key *crypto.PrivateKeySECP256K1R) error {
+	genesisState := platformvm.Genesis{}
+	if _, err := platformvm.Codec.Unmarshal(genesis.Genesis(i.net.networkID), &genesisState); err != nil {
+		return err
+	}
 
-			ds.Free()
-			ba.Free()
-			newMsg.Free()
+	addr := key.PublicKey().Address()
+	found := false
+	for _, account := range genesisState.Accounts {
+		if account.Address.Equals(addr) {
+			i.stakeNonce = account.Nonce
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no platform account is controlled by key %d", config.Key)
+	}
 
-			numPending++
-			n.log.Debug("Sent tx, pending = %d, accepted = %d", numPending, numAccepted)
+	for _, validator := range genesisState.Validators.Txs {
+		if endTime := validator.EndTime(); endTime.After(i.validatorEnd) {
+			i.validatorID = validator.Vdr().ID()
+			i.validatorEnd = endTime
 		}
+	}
+	if i.validatorEnd.IsZero() {
+		return errors.New("there are no genesis validators to delegate to")
+	}
+	i.stakeKey = key
+	return nil
+}
 
-		// If we are done issuing txs, return from the function
-		if numAccepted+numPending >= config.NumTxs {
-			n.log.Info("done with test")
-			net.ec.Stop()
-			return
+// Issue implements the load.Issuer interface
+func (i *avmIssuer) Issue(kind load.TxKind) ([]ids.ID, error) {
+	switch kind {
+	case load.Transfer:
+		tx := i.wallet.NextTx()
+		if tx == nil {
+			return nil, load.ErrExhausted
 		}
+		i.numTransfers++
+		return []ids.ID{i.send(i.chainID, tx.Bytes())}, nil
+	case load.Conflict:
+		conflict := i.wallet.PeekConflict()
+		switch {
+		case conflict == nil:
+			return nil, load.ErrExhausted
+		case conflict.Source >= i.numTransfers:
+			return nil, load.ErrNotReady
+		}
+		i.wallet.NextConflict()
+		return []ids.ID{
+			i.send(i.chainID, conflict.Txs[0].Bytes()),
+			i.send(i.chainID, conflict.Txs[1].Bytes()),
+		}, nil
+	case load.Stake:
+		return i.issueStake()
+	default:
+		return nil, fmt.Errorf("unknown transaction kind %s", kind)
 	}
 }
+
+// issueStake issues a delegation of the minimum stake amount, for the minimum
+// staking duration, to the platform chain
+func (i *avmIssuer) issueStake() ([]ids.ID, error) {
+	if i.stakeKey == nil {
+		return nil, load.ErrExhausted
+	}
+
+	// The delegation must start after the platform chain proposes it
+	startTime := i.clock.Time().Add(platformvm.Delta + stakeStartDelay)
+	endTime := startTime.Add(platformvm.MinimumStakingDuration)
+	if endTime.After(i.validatorEnd) {
+		i.net.log.Warn("no genesis validator validates long enough to be delegated to")
+		return nil, load.ErrExhausted
+	}
+
+	addr := i.stakeKey.PublicKey().Address()
+	txBytes, err := platformvm.SignAddDefaultSubnetDelegatorTx(platformvm.UnsignedAddDefaultSubnetDelegatorTx{
+		DurationValidator: platformvm.DurationValidator{
+			Validator: platformvm.Validator{
+				NodeID: i.validatorID,
+				Wght:   platformvm.MinimumStakeAmount,
+			},
+			Start: uint64(startTime.Unix()),
+			End:   uint64(endTime.Unix()),
+		},
+		NetworkID:   i.net.networkID,
+		Nonce:       i.stakeNonce + 1,
+		Destination: addr,
+	}, i.stakeKey)
+	if err != nil {
+		return nil, err
+	}
+	i.stakeNonce++
+	return []ids.ID{i.send(platformChainID, txBytes)}, nil
+}
+
+// send an IssueTx message for [txBytes], and return the ID the decision of the
+// tx will be reported with
+func (i *avmIssuer) send(chainID ids.ID, txBytes []byte) ids.ID {
+	n := i.net
+	it, err := n.build.IssueTx(chainID, txBytes)
+	n.log.AssertNoError(err)
+	ds := it.DataStream()
+	ba := salticidae.NewByteArrayMovedFromDataStream(ds, false)
+	newMsg := salticidae.NewMsgMovedFromByteArray(networking.IssueTx, ba, false)
+
+	n.conn.GetNet().SendMsg(newMsg, n.conn)
+
+	ds.Free()
+	ba.Free()
+	newMsg.Free()
+
+	return ids.NewID(hashing.ComputeHash256Array(txBytes))
+}
@@ -37,8 +37,17 @@ type Wallet struct {
 	balance map[[32]byte]uint64
 	txFee   uint64
 
-	txsSent int32
-	txs     []*avm.Tx
+	txs       []*avm.Tx
+	conflicts []*Conflict
+}
+
+// Conflict is a pair of transactions that spend the same UTXO
+type Conflict struct {
+	Txs [2]*avm.Tx
+
+	// Position, in the order they were generated, of the transaction that
+	// produced the spent UTXO
+	Source int
 }
 
 // NewWallet returns a new Wallet
@@ -173,8 +182,6 @@ func (w *Wallet) CreateTx(assetID ids.ID, amount uint64, destAddr ids.ShortID) (
 		return nil, errors.New("insufficient funds")
 	}
 
-	avm.SortTransferableInputsWithSigners(ins, keys)
-
 	outs := []*avm.TransferableOutput{
 		&avm.TransferableOutput{
 			Asset: avm.Asset{ID: assetID},
@@ -209,6 +216,47 @@ func (w *Wallet) CreateTx(assetID ids.ID, amount uint64, destAddr ids.ShortID) (
 		)
 	}
 
+	return w.signTx(ins, keys, outs)
+}
+
+// spendTx returns a tx that sends all of [utxo] to [destAddr]
+func (w *Wallet) spendTx(utxo *avm.UTXO, destAddr ids.ShortID) (*avm.Tx, error) {
+	inputIntf, signers, err := w.keychain.Spend(utxo.Out, w.clock.Unix())
+	if err != nil {
+		return nil, err
+	}
+	input, ok := inputIntf.(avm.FxTransferable)
+	if !ok {
+		return nil, errors.New("utxo isn't transferable")
+	}
+	assetID := utxo.AssetID()
+
+	ins := []*avm.TransferableInput{
+		&avm.TransferableInput{
+			UTXOID: utxo.UTXOID,
+			Asset:  avm.Asset{ID: assetID},
+			In:     input,
+		},
+	}
+	outs := []*avm.TransferableOutput{
+		&avm.TransferableOutput{
+			Asset: avm.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:      input.Amount(),
+				Locktime: 0,
+				OutputOwners: secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{destAddr},
+				},
+			},
+		},
+	}
+	return w.signTx(ins, [][]*crypto.PrivateKeySECP256K1R{signers}, outs)
+}
+
+// signTx returns a tx that spends [ins], signed by [keys], to [outs]
+func (w *Wallet) signTx(ins []*avm.TransferableInput, keys [][]*crypto.PrivateKeySECP256K1R, outs []*avm.TransferableOutput) (*avm.Tx, error) {
+	avm.SortTransferableInputsWithSigners(ins, keys)
 	avm.SortTransferableOutputs(outs, w.codec)
 
 	tx := &avm.Tx{
@@ -264,6 +312,8 @@ func (w *Wallet) GenerateTxs(numTxs int, assetID ids.ID) error {
 	frequency := numTxs / 50
 	if frequency > 1000 {
 		frequency = 1000
+	} else if frequency < 1 {
+		frequency = 1
 	}
 
 	w.txs = make([]*avm.Tx, numTxs)
@@ -295,6 +345,65 @@ func (w *Wallet) GenerateTxs(numTxs int, assetID ids.ID) error {
 	return nil
 }
 
+// GenerateConflicts generates up to [numConflicts] pairs of conflicting
+// transactions of [assetID], and returns how many were generated. Each pair
+// spends a UTXO that no transaction generated by GenerateTxs spends, so
+// whichever transaction of the pair is accepted, the other transactions can be
+// accepted. The UTXOs produced earliest are spent first, so pairs can be issued
+// early in the test. Must be called after GenerateTxs, and before NextTx.
+func (w *Wallet) GenerateConflicts(numConflicts int, assetID ids.ID) (int, error) {
+	w.log.Info("Generating %d pairs of conflicting transactions", numConflicts)
+
+	w.conflicts = make([]*Conflict, 0, numConflicts)
+	for i := 0; i < len(w.txs) && len(w.conflicts) < numConflicts; i++ {
+		for _, utxo := range w.txs[i].UTXOs() {
+			if len(w.conflicts) == numConflicts {
+				break
+			}
+			utxoID := utxo.InputID()
+			if !utxo.AssetID().Equals(assetID) || w.utxoSet.Get(utxoID) == nil {
+				continue // The UTXO is spent by a generated transaction
+			}
+
+			conflict := &Conflict{Source: i}
+			for j := range conflict.Txs {
+				addr, err := w.CreateAddress()
+				if err != nil {
+					return 0, err
+				}
+				if conflict.Txs[j], err = w.spendTx(utxo, addr); err != nil {
+					return 0, err
+				}
+			}
+			w.RemoveUTXO(utxoID)
+			w.conflicts = append(w.conflicts, conflict)
+		}
+	}
+
+	w.log.Info("Finished generating %d out of %d pairs of conflicting transactions", len(w.conflicts), numConflicts)
+	return len(w.conflicts), nil
+}
+
+// NextConflict returns the next pair of conflicting transactions to be sent as
+// part of xput test
+func (w *Wallet) NextConflict() *Conflict {
+	if len(w.conflicts) == 0 {
+		return nil
+	}
+	conflict := w.conflicts[0]
+	w.conflicts = w.conflicts[1:]
+	return conflict
+}
+
+// PeekConflict returns the pair of conflicting transactions that NextConflict
+// returns next, without removing it
+func (w *Wallet) PeekConflict() *Conflict {
+	if len(w.conflicts) == 0 {
+		return nil
+	}
+	return w.conflicts[0]
+}
+
 // NextTx returns the next tx to be sent as part of xput test
 func (w *Wallet) NextTx() *avm.Tx {
 	if len(w.txs) == 0 {
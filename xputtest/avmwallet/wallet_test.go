@@ -300,3 +300,95 @@ func TestWalletWithGenesis(t *testing.T) {
 		}
 	}
 }
+
+func TestWalletGenerateConflicts(t *testing.T) {
+	ctx := snow.DefaultContextTest()
+	ctx.NetworkID = 12345
+	ctx.ChainID = ids.NewID([32]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+	w, err := NewWallet(logging.NoLog{}, ctx.NetworkID, ctx.ChainID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := ids.Empty.Prefix(0)
+	addr, err := w.GetAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.AddUTXO(&avm.UTXO{
+		UTXOID: avm.UTXOID{TxID: ids.Empty.Prefix(1)},
+		Asset:  avm.Asset{ID: assetID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1000,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{addr},
+			},
+		},
+	})
+
+	if err := w.GenerateTxs(10, assetID); err != nil {
+		t.Fatal(err)
+	}
+	if numConflicts, err := w.GenerateConflicts(5, assetID); err != nil {
+		t.Fatal(err)
+	} else if numConflicts != 5 {
+		t.Fatalf("Should have generated 5 conflicts but generated %d", numConflicts)
+	}
+
+	spent := ids.Set{}
+	for _, tx := range w.txs {
+		for _, utxoID := range tx.InputUTXOs() {
+			spent.Add(utxoID.InputID())
+		}
+	}
+
+	lastSource := 0
+	for i := 0; i < 5; i++ {
+		if peeked := w.PeekConflict(); peeked != w.PeekConflict() || peeked == nil {
+			t.Fatalf("Peek should return the next conflict")
+		}
+		conflict := w.NextConflict()
+		if conflict.Source < lastSource {
+			t.Fatalf("Conflicts should spend the earliest produced UTXOs first")
+		}
+		lastSource = conflict.Source
+
+		inputs0 := conflict.Txs[0].InputUTXOs()
+		inputs1 := conflict.Txs[1].InputUTXOs()
+		if len(inputs0) != 1 || len(inputs1) != 1 {
+			t.Fatalf("Conflicting txs should spend one UTXO each")
+		}
+		utxoID := inputs0[0].InputID()
+		if !utxoID.Equals(inputs1[0].InputID()) {
+			t.Fatalf("Txs should conflict")
+		}
+		if conflict.Txs[0].ID().Equals(conflict.Txs[1].ID()) {
+			t.Fatalf("Conflicting txs should differ")
+		}
+		if spent.Contains(utxoID) {
+			t.Fatalf("Conflicting txs shouldn't spend a UTXO spent by a generated tx")
+		}
+		produced := false
+		for _, utxo := range w.txs[conflict.Source].UTXOs() {
+			produced = produced || utxo.InputID().Equals(utxoID)
+		}
+		if !produced {
+			t.Fatalf("Conflicting txs should spend a UTXO produced by their source tx")
+		}
+		for _, tx := range conflict.Txs {
+			if err := tx.SyntacticVerify(ctx, w.codec, 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if conflict := w.NextConflict(); conflict != nil {
+		t.Fatalf("Should have run out of conflicts")
+	}
+	if numConflicts, err := w.GenerateConflicts(10, assetID); err != nil {
+		t.Fatal(err)
+	} else if numConflicts > 5 {
+		t.Fatalf("Only the UTXOs that weren't spent by the generated txs or conflicts should have been spent")
+	}
+}
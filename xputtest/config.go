@@ -4,8 +4,11 @@
 package main
 
 import (
+	"time"
+
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/xputtest/load"
 )
 
 // Config contains all of the configurations of an Ava client.
@@ -27,4 +30,11 @@ type Config struct {
 	// MaxOutstandingTxs describes how many txs to pipeline
 	Key, NumTxs, MaxOutstandingTxs int
 	Chain                          ChainType
+
+	// Load test configurations of the avm test
+	Mix        load.Mix
+	Ramp       load.Ramp
+	TxTimeout  time.Duration
+	Seed       int64
+	ReportFile string
 }
@@ -0,0 +1,275 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/timer"
+)
+
+// tickFrequency is how often a running generator issues transactions
+const tickFrequency = 10 * time.Millisecond
+
+var (
+	// ErrExhausted is returned by an Issuer that can't issue any more
+	// transactions of a kind
+	ErrExhausted = errors.New("no more transactions of this kind can be issued")
+
+	// ErrNotReady is returned by an Issuer that can't issue a transaction of a
+	// kind until more transactions of other kinds are issued
+	ErrNotReady = errors.New("transactions of this kind can't be issued yet")
+
+	errNoMix = errors.New("transaction mix has no weights")
+)
+
+// Issuer issues transactions to the chains being tested
+type Issuer interface {
+	// Issue transactions of [kind] and return their IDs. A conflict issues two
+	// transactions, at most one of which is accepted.
+	Issue(kind TxKind) ([]ids.ID, error)
+}
+
+// Config of a load test
+type Config struct {
+	// Relative frequency of each kind of transaction
+	Mix Mix
+
+	// Target issuance rate
+	Ramp Ramp
+
+	// Number of issuances. A conflict is one issuance.
+	NumTxs int
+
+	// Maximum number of transactions that are issued but not decided
+	MaxOutstanding int
+
+	// Time after which an undecided transaction is counted as timed out
+	Timeout time.Duration
+
+	// Seed of the choice of the kind of each transaction, so the same
+	// sequence of kinds is issued by every run
+	Seed int64
+}
+
+// Report of a load test. Each array is indexed by TxKind.
+type Report struct {
+	Duration time.Duration
+
+	Issued, Accepted, Rejected, TimedOut [numTxKinds]uint64
+
+	// Time from issuance to decision of the decided transactions
+	Latency [numTxKinds]Histogram
+}
+
+func (r *Report) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("duration=%s", r.Duration))
+	numAccepted := uint64(0)
+	for kind := TxKind(0); kind < numTxKinds; kind++ {
+		numAccepted += r.Accepted[kind]
+		if r.Issued[kind] == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s: issued=%d accepted=%d rejected=%d timedOut=%d\n latency: %s",
+			kind,
+			r.Issued[kind],
+			r.Accepted[kind],
+			r.Rejected[kind],
+			r.TimedOut[kind],
+			&r.Latency[kind],
+		))
+	}
+	if seconds := r.Duration.Seconds(); seconds > 0 {
+		sb.WriteString(fmt.Sprintf("\naccepted tps=%.1f", float64(numAccepted)/seconds))
+	}
+	return sb.String()
+}
+
+type pendingTx struct {
+	kind   TxKind
+	issued time.Time
+}
+
+// Generator issues transactions at the rate and in the mix of its config, and
+// measures the time until they're decided
+type Generator struct {
+	log    logging.Logger
+	config Config
+	issuer Issuer
+	clock  timer.Clock
+	rand   *rand.Rand
+
+	lock      sync.Mutex
+	start     time.Time
+	numIssued int
+	exhausted [numTxKinds]bool
+	pending   map[[32]byte]pendingTx
+	report    Report
+	done      chan struct{}
+}
+
+// NewGenerator returns a generator that issues transactions with [issuer]
+func NewGenerator(log logging.Logger, config Config, issuer Issuer) (*Generator, error) {
+	switch {
+	case !config.Mix.canIssue([numTxKinds]bool{}):
+		return nil, errNoMix
+	case config.NumTxs <= 0:
+		return nil, errors.New("number of transactions must be positive")
+	case config.MaxOutstanding <= 0:
+		return nil, errors.New("maximum outstanding transactions must be positive")
+	case config.Timeout <= 0:
+		return nil, errors.New("timeout must be positive")
+	}
+	return &Generator{
+		log:     log,
+		config:  config,
+		issuer:  issuer,
+		rand:    rand.New(rand.NewSource(config.Seed)),
+		pending: make(map[[32]byte]pendingTx),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Run the load test until every transaction is issued and decided or timed
+// out, or until [stop] is closed
+func (g *Generator) Run(stop <-chan struct{}) {
+	g.log.Info("starting load test. mix: %s, rate: %s", g.config.Mix, g.config.Ramp)
+
+	ticker := time.NewTicker(tickFrequency)
+	defer ticker.Stop()
+
+	for !g.Tick() {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Tick issues the transactions the ramp allows, and counts the transactions
+// that timed out. Returns true once the load test is done.
+func (g *Generator) Tick() bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	now := g.clock.Time()
+	if g.start.IsZero() {
+		g.start = now
+	}
+
+	for key, tx := range g.pending {
+		if now.Sub(tx.issued) >= g.config.Timeout {
+			g.log.Debug("%s %s timed out", tx.kind, ids.NewID(key))
+			delete(g.pending, key)
+			g.report.TimedOut[tx.kind]++
+		}
+	}
+
+	target := g.config.NumTxs
+	if !g.config.Ramp.Unlimited() {
+		if ramped := int(g.config.Ramp.Target(now.Sub(g.start))); ramped < target {
+			target = ramped
+		}
+	}
+
+	// Kinds that can't be issued this tick
+	skip := g.exhausted
+	for g.numIssued < target && len(g.pending) < g.config.MaxOutstanding {
+		kind, ok := g.config.Mix.sample(g.rand, skip)
+		if !ok {
+			break
+		}
+		txIDs, err := g.issuer.Issue(kind)
+		switch err {
+		case nil:
+		case ErrNotReady:
+			skip[kind] = true
+			continue
+		case ErrExhausted:
+			g.log.Info("no more %s transactions can be issued", kind)
+			g.exhausted[kind] = true
+			skip[kind] = true
+			continue
+		default:
+			g.log.Error("failed to issue a %s transaction due to %s", kind, err)
+			g.exhausted[kind] = true
+			skip[kind] = true
+			continue
+		}
+
+		g.numIssued++
+		for _, txID := range txIDs {
+			g.pending[txID.Key()] = pendingTx{
+				kind:   kind,
+				issued: now,
+			}
+			g.report.Issued[kind]++
+		}
+	}
+
+	if len(g.pending) > 0 {
+		return false
+	}
+	if g.numIssued < g.config.NumTxs && g.config.Mix.canIssue(g.exhausted) {
+		return false
+	}
+
+	select {
+	case <-g.done:
+	default:
+		g.report.Duration = now.Sub(g.start)
+		close(g.done)
+	}
+	return true
+}
+
+// Decided records that the transaction [txID] was decided with [status]
+func (g *Generator) Decided(txID ids.ID, status choices.Status) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	key := txID.Key()
+	tx, ok := g.pending[key]
+	if !ok {
+		return
+	}
+	delete(g.pending, key)
+
+	switch status {
+	case choices.Accepted:
+		g.report.Accepted[tx.kind]++
+	case choices.Rejected:
+		g.report.Rejected[tx.kind]++
+	}
+	g.report.Latency[tx.kind].Observe(g.clock.Time().Sub(tx.issued))
+}
+
+// Done returns a channel that's closed once the load test is done
+func (g *Generator) Done() <-chan struct{} { return g.done }
+
+// Report returns the results of the load test so far
+func (g *Generator) Report() Report {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	report := g.report
+	select {
+	case <-g.done:
+	default:
+		if !g.start.IsZero() {
+			report.Duration = g.clock.Time().Sub(g.start)
+		}
+	}
+	return report
+}
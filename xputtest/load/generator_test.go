@@ -0,0 +1,227 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/gecko/ids"
+	"github.com/ava-labs/gecko/snow/choices"
+	"github.com/ava-labs/gecko/utils/logging"
+)
+
+type testIssuer struct {
+	issued   []ids.ID
+	kinds    []TxKind
+	maxTxs   [numTxKinds]int
+	notReady [numTxKinds]bool
+}
+
+func (i *testIssuer) Issue(kind TxKind) ([]ids.ID, error) {
+	if i.notReady[kind] {
+		return nil, ErrNotReady
+	}
+	if i.maxTxs[kind] == 0 {
+		return nil, ErrExhausted
+	}
+	i.maxTxs[kind]--
+
+	numTxs := 1
+	if kind == Conflict {
+		numTxs = 2
+	}
+	txIDs := []ids.ID(nil)
+	for j := 0; j < numTxs; j++ {
+		txID := ids.Empty.Prefix(uint64(len(i.issued)))
+		i.issued = append(i.issued, txID)
+		txIDs = append(txIDs, txID)
+	}
+	i.kinds = append(i.kinds, kind)
+	return txIDs, nil
+}
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("transfer=8,stake=1,conflict=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mix != (Mix{8, 1, 1}) {
+		t.Fatalf("Wrong mix: %s", mix)
+	}
+	if _, err := ParseMix("transfer"); err == nil {
+		t.Fatalf("Should have failed to parse an entry without a weight")
+	}
+	if _, err := ParseMix("swap=1"); err == nil {
+		t.Fatalf("Should have failed to parse an unknown kind")
+	}
+}
+
+func TestRamp(t *testing.T) {
+	ramp, err := ParseRamp("100:300:10s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate := ramp.Rate(5 * time.Second); rate != 200 {
+		t.Fatalf("Rate should have been 200 but was %f", rate)
+	}
+	if target := ramp.Target(10 * time.Second); target != 2000 {
+		t.Fatalf("Target should have been 2000 but was %f", target)
+	}
+	if target := ramp.Target(12 * time.Second); target != 2600 {
+		t.Fatalf("Target should have been 2600 but was %f", target)
+	}
+
+	constant, err := ParseRamp("50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target := constant.Target(2 * time.Second); target != 100 {
+		t.Fatalf("Target should have been 100 but was %f", target)
+	}
+	if unlimited, err := ParseRamp(""); err != nil || !unlimited.Unlimited() {
+		t.Fatalf("Empty ramp should be unlimited")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := Histogram{}
+	for i := 0; i < 90; i++ {
+		h.Observe(500 * time.Microsecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(100 * time.Millisecond)
+	}
+
+	if count := h.Count(); count != 100 {
+		t.Fatalf("Count should have been 100 but was %d", count)
+	}
+	if p50 := h.Percentile(50); p50 != time.Millisecond {
+		t.Fatalf("p50 should have been 1ms but was %s", p50)
+	}
+	if p99 := h.Percentile(99); p99 != 100*time.Millisecond {
+		t.Fatalf("p99 should have been the max latency but was %s", p99)
+	}
+	if max := h.Max(); max != 100*time.Millisecond {
+		t.Fatalf("Max should have been 100ms but was %s", max)
+	}
+}
+
+func TestGeneratorRamp(t *testing.T) {
+	issuer := &testIssuer{maxTxs: [numTxKinds]int{100, 100, 100}}
+	g, err := NewGenerator(logging.NoLog{}, Config{
+		Mix:            Mix{1, 0, 1},
+		Ramp:           Ramp{Start: 10, End: 10},
+		NumTxs:         20,
+		MaxOutstanding: 1000,
+		Timeout:        time.Minute,
+	}, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1000, 0)
+	g.clock.Set(start)
+
+	if g.Tick() {
+		t.Fatalf("Load test shouldn't be done")
+	}
+	if len(issuer.kinds) != 0 {
+		t.Fatalf("Nothing should be issued at the start")
+	}
+
+	g.clock.Set(start.Add(time.Second))
+	g.Tick()
+	if len(issuer.kinds) != 10 {
+		t.Fatalf("10 issuances should have been made after 1s but %d were", len(issuer.kinds))
+	}
+	for _, kind := range issuer.kinds {
+		if kind == Stake {
+			t.Fatalf("Kinds without weight shouldn't be issued")
+		}
+	}
+
+	g.clock.Set(start.Add(time.Second + 100*time.Millisecond))
+	for i, txID := range issuer.issued {
+		status := choices.Accepted
+		if i%2 == 1 {
+			status = choices.Rejected
+		}
+		g.Decided(txID, status)
+	}
+
+	g.clock.Set(start.Add(time.Minute))
+	g.Tick()
+	if len(issuer.kinds) != 20 {
+		t.Fatalf("Every issuance should have been made but %d were", len(issuer.kinds))
+	}
+
+	// The undecided transactions time out
+	g.clock.Set(start.Add(3 * time.Minute))
+	if !g.Tick() {
+		t.Fatalf("Load test should be done")
+	}
+	<-g.Done()
+
+	report := g.Report()
+	numDecided := uint64(0)
+	numIssued := uint64(0)
+	numTimedOut := uint64(0)
+	for kind := TxKind(0); kind < numTxKinds; kind++ {
+		numDecided += report.Accepted[kind] + report.Rejected[kind]
+		numIssued += report.Issued[kind]
+		numTimedOut += report.TimedOut[kind]
+		if report.Latency[kind].Count() != report.Accepted[kind]+report.Rejected[kind] {
+			t.Fatalf("Latency of every decided %s should have been observed", kind)
+		}
+	}
+	if numIssued != uint64(len(issuer.issued)) {
+		t.Fatalf("Wrong number of issued transactions")
+	}
+	if numDecided+numTimedOut != numIssued {
+		t.Fatalf("Every transaction should have been decided or timed out")
+	}
+	if report.Duration != 3*time.Minute {
+		t.Fatalf("Wrong duration %s", report.Duration)
+	}
+}
+
+func TestGeneratorExhausted(t *testing.T) {
+	issuer := &testIssuer{
+		maxTxs:   [numTxKinds]int{5, 0, 5},
+		notReady: [numTxKinds]bool{Conflict: true},
+	}
+	g, err := NewGenerator(logging.NoLog{}, Config{
+		Mix:            Mix{1, 1, 1},
+		NumTxs:         100,
+		MaxOutstanding: 1000,
+		Timeout:        time.Minute,
+	}, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only transfers can be issued
+	g.Tick()
+	if len(issuer.kinds) != 5 {
+		t.Fatalf("5 transfers should have been issued but %d issuances were made", len(issuer.kinds))
+	}
+	for _, txID := range issuer.issued {
+		g.Decided(txID, choices.Accepted)
+	}
+	if g.Tick() {
+		t.Fatalf("Load test shouldn't be done while conflicts can be issued later")
+	}
+
+	issuer.notReady[Conflict] = false
+	g.Tick()
+	for _, txID := range issuer.issued[5:] {
+		g.Decided(txID, choices.Accepted)
+	}
+	if !g.Tick() {
+		t.Fatalf("Load test should be done once every kind is exhausted")
+	}
+	if report := g.Report(); report.Issued[Conflict] != 10 {
+		t.Fatalf("Each conflict should have issued 2 transactions")
+	}
+}
@@ -0,0 +1,118 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// minBucket is the upper bound of the first bucket of a histogram. The
+	// upper bound of each following bucket is twice the previous one.
+	minBucket = time.Millisecond
+
+	// numBuckets is the number of buckets of a histogram. The last bucket
+	// counts every latency above the bound of the previous one.
+	numBuckets = 20
+
+	// barWidth is the width of the bar of the largest bucket when a histogram
+	// is printed
+	barWidth = 40
+)
+
+// Histogram counts latencies in exponentially growing buckets
+type Histogram struct {
+	buckets [numBuckets]uint64
+	count   uint64
+	sum     time.Duration
+	max     time.Duration
+}
+
+// bucketBound returns the upper bound of bucket [i]
+func bucketBound(i int) time.Duration { return minBucket << uint(i) }
+
+// Observe a latency
+func (h *Histogram) Observe(latency time.Duration) {
+	i := 0
+	for i < numBuckets-1 && latency > bucketBound(i) {
+		i++
+	}
+	h.buckets[i]++
+	h.count++
+	h.sum += latency
+	if latency > h.max {
+		h.max = latency
+	}
+}
+
+// Count returns the number of observed latencies
+func (h *Histogram) Count() uint64 { return h.count }
+
+// Mean returns the mean of the observed latencies
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Max returns the largest observed latency
+func (h *Histogram) Max() time.Duration { return h.max }
+
+// Percentile returns an upper bound of the [p]th percentile of the observed
+// latencies, where [p] is in [0, 100]. The bound is the upper bound of the
+// bucket the percentile is in, or the largest observed latency if that's
+// lower.
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(p / 100 * float64(h.count))
+	if rank == 0 {
+		rank = 1
+	}
+	seen := uint64(0)
+	for i, count := range h.buckets {
+		seen += count
+		if seen >= rank {
+			if bound := bucketBound(i); i < numBuckets-1 && bound < h.max {
+				return bound
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+func (h *Histogram) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("count=%d mean=%s p50=%s p90=%s p99=%s max=%s",
+		h.count,
+		h.Mean(),
+		h.Percentile(50),
+		h.Percentile(90),
+		h.Percentile(99),
+		h.max,
+	))
+	largest := uint64(0)
+	for _, count := range h.buckets {
+		if count > largest {
+			largest = count
+		}
+	}
+	for i, count := range h.buckets {
+		if count == 0 {
+			continue
+		}
+		bound := "<= " + bucketBound(i).String()
+		if i == numBuckets-1 {
+			bound = "> " + bucketBound(i-1).String()
+		}
+		bar := strings.Repeat("#", int(count*barWidth/largest))
+		sb.WriteString(fmt.Sprintf("\n  %10s %8d %s", bound, count, bar))
+	}
+	return sb.String()
+}
@@ -0,0 +1,120 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// TxKind is a kind of transaction issued by a load test
+type TxKind int
+
+// Kinds of transactions
+const (
+	Transfer TxKind = iota // An X-Chain transfer
+	Stake                  // A P-Chain delegation to a default subnet validator
+	Conflict               // A pair of X-Chain transfers that spend the same UTXO
+	numTxKinds
+)
+
+func (k TxKind) String() string {
+	switch k {
+	case Transfer:
+		return "transfer"
+	case Stake:
+		return "stake"
+	case Conflict:
+		return "conflict"
+	default:
+		return "unknown"
+	}
+}
+
+// ToTxKind returns the kind of transaction named [s]
+func ToTxKind(s string) (TxKind, error) {
+	for kind := TxKind(0); kind < numTxKinds; kind++ {
+		if kind.String() == s {
+			return kind, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown transaction kind %q. Should be one of {transfer, stake, conflict}", s)
+}
+
+// Mix is the relative frequency of each kind of transaction. A kind is issued
+// with probability proportional to its weight.
+type Mix [numTxKinds]uint64
+
+// ParseMix parses a comma separated list of <kind>=<weight>
+func ParseMix(s string) (Mix, error) {
+	mix := Mix{}
+	for _, entry := range strings.Split(s, ",") {
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return Mix{}, fmt.Errorf("transaction mix entry %q should be formatted as <kind>=<weight>", entry)
+		}
+		kind, err := ToTxKind(parts[0])
+		if err != nil {
+			return Mix{}, err
+		}
+		weight, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return Mix{}, fmt.Errorf("invalid weight %q for %s: %w", parts[1], kind, err)
+		}
+		mix[kind] = weight
+	}
+	return mix, nil
+}
+
+// sample returns a kind of transaction, chosen with [r] with probability
+// proportional to its weight. Kinds in [skip] are never chosen. Returns false
+// if every kind with a weight is skipped.
+func (m Mix) sample(r *rand.Rand, skip [numTxKinds]bool) (TxKind, bool) {
+	total := uint64(0)
+	for kind, weight := range m {
+		if !skip[kind] {
+			total += weight
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	w := uint64(r.Int63n(int64(total)))
+	for kind, weight := range m {
+		if skip[kind] {
+			continue
+		}
+		if w < weight {
+			return TxKind(kind), true
+		}
+		w -= weight
+	}
+	return 0, false // Unreachable
+}
+
+// canIssue returns true if a kind with a weight isn't in [skip]
+func (m Mix) canIssue(skip [numTxKinds]bool) bool {
+	for kind, weight := range m {
+		if weight > 0 && !skip[kind] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Mix) String() string {
+	entries := []string(nil)
+	for kind, weight := range m {
+		if weight > 0 {
+			entries = append(entries, fmt.Sprintf("%s=%d", TxKind(kind), weight))
+		}
+	}
+	return strings.Join(entries, ",")
+}
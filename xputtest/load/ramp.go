@@ -0,0 +1,100 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package load
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNegativeRate = errors.New("transactions per second can't be negative")
+
+// Ramp is a target issuance rate that changes linearly from [Start] to [End]
+// transactions per second over [Duration], and then stays at [End]. A zero
+// Ramp doesn't limit the rate, so transactions are issued as fast as the limit
+// on outstanding transactions allows.
+type Ramp struct {
+	Start, End float64
+	Duration   time.Duration
+}
+
+// ParseRamp parses a ramp formatted as <start>:<end>:<duration>, such as
+// 100:1000:1m, or a constant rate formatted as <tps>. An empty string is a zero
+// Ramp.
+func ParseRamp(s string) (Ramp, error) {
+	if s == "" {
+		return Ramp{}, nil
+	}
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 1:
+		tps, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return Ramp{}, fmt.Errorf("invalid rate %q: %w", parts[0], err)
+		}
+		if tps < 0 {
+			return Ramp{}, errNegativeRate
+		}
+		return Ramp{Start: tps, End: tps}, nil
+	case 3:
+		start, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return Ramp{}, fmt.Errorf("invalid start rate %q: %w", parts[0], err)
+		}
+		end, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return Ramp{}, fmt.Errorf("invalid end rate %q: %w", parts[1], err)
+		}
+		duration, err := time.ParseDuration(parts[2])
+		if err != nil {
+			return Ramp{}, fmt.Errorf("invalid ramp duration %q: %w", parts[2], err)
+		}
+		if start < 0 || end < 0 {
+			return Ramp{}, errNegativeRate
+		}
+		if duration < 0 {
+			return Ramp{}, errors.New("ramp duration can't be negative")
+		}
+		return Ramp{Start: start, End: end, Duration: duration}, nil
+	default:
+		return Ramp{}, fmt.Errorf("ramp %q should be formatted as <start>:<end>:<duration> or <tps>", s)
+	}
+}
+
+// Unlimited returns true if this ramp doesn't limit the issuance rate
+func (r Ramp) Unlimited() bool { return r.Start == 0 && r.End == 0 }
+
+// Rate returns the target rate, in transactions per second, [elapsed] after
+// the start of the test
+func (r Ramp) Rate(elapsed time.Duration) float64 {
+	if elapsed >= r.Duration {
+		return r.End
+	}
+	return r.Start + (r.End-r.Start)*elapsed.Seconds()/r.Duration.Seconds()
+}
+
+// Target returns the number of transactions that should have been issued
+// [elapsed] after the start of the test
+func (r Ramp) Target(elapsed time.Duration) float64 {
+	if elapsed < r.Duration {
+		// Area under the line from Start to the current rate
+		return elapsed.Seconds() * (r.Start + r.Rate(elapsed)) / 2
+	}
+	ramped := r.Duration.Seconds() * (r.Start + r.End) / 2
+	return ramped + (elapsed-r.Duration).Seconds()*r.End
+}
+
+func (r Ramp) String() string {
+	switch {
+	case r.Unlimited():
+		return "unlimited"
+	case r.Start == r.End:
+		return fmt.Sprintf("%g tps", r.End)
+	default:
+		return fmt.Sprintf("%g to %g tps over %s", r.Start, r.End, r.Duration)
+	}
+}
@@ -4,11 +4,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
 	"runtime"
 	"runtime/pprof"
+	"time"
 
 	"github.com/ava-labs/salticidae-go"
 
@@ -16,11 +18,22 @@ import (
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/utils/crypto"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/utils/retry"
 	"github.com/ava-labs/gecko/vms/avm"
 	"github.com/ava-labs/gecko/vms/spchainvm"
 	"github.com/ava-labs/gecko/vms/spdagvm"
 )
 
+// dialRetry describes how the benchmark client's connection to its local node
+// is retried. The node's connections to its peers are retried by salticidae;
+// see the networking package.
+var dialRetry = retry.Config{
+	MaxAttempts:  10,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+	Jitter:       .2,
+}
+
 func main() {
 	if err != nil {
 		fmt.Printf("Failed to parse arguments: %s\n", err)
@@ -60,9 +73,18 @@ func main() {
 		return
 	}
 
-	net.conn = net.net.ConnectSync(remoteIP, true, &serr)
-	if serr.GetCode() != 0 {
-		log.Fatal("Sync error %s", salticidae.StrError(serr.GetCode()))
+	// The node may still be starting up, so retry connecting to it
+	err = retry.Do(context.Background(), dialRetry, func() error {
+		dialErr := salticidae.NewError()
+		net.conn = net.net.ConnectSync(remoteIP, true, &dialErr)
+		if code := dialErr.GetCode(); code != 0 {
+			log.Debug("Failed to connect to %s: %s", config.RemoteIP, salticidae.StrError(code))
+			return fmt.Errorf("sync error %s", salticidae.StrError(code))
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("%s", err)
 		return
 	}
 
@@ -16,7 +16,9 @@ import (
 
 	"github.com/ava-labs/gecko/ids"
 	"github.com/ava-labs/gecko/networking"
+	"github.com/ava-labs/gecko/snow/choices"
 	"github.com/ava-labs/gecko/utils/logging"
+	"github.com/ava-labs/gecko/xputtest/load"
 )
 
 // network stores the persistent data needed when running the test.
@@ -30,6 +32,9 @@ type network struct {
 	log     logging.Logger
 	decided chan ids.ID
 
+	// If set, decisions are reported to the load test rather than to [decided]
+	generator *load.Generator
+
 	networkID uint32
 }
 
@@ -72,7 +77,12 @@ func decidedTx(_msg *C.struct_msg_t, _conn *C.struct_msgnetwork_conn_t, _ unsafe
 
 	txID, err := ids.ToID(pMsg.Get(networking.TxID).([]byte))
 	net.log.AssertNoError(err) // Length is checked in message parsing
+	status := choices.Status(pMsg.Get(networking.Status).(uint32))
 
-	net.log.Debug("Decided %s", txID)
+	net.log.Debug("Decided %s with status %s", txID, status)
+	if net.generator != nil {
+		net.generator.Decided(txID, status)
+		return
+	}
 	net.decided <- txID
 }
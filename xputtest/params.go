@@ -4,9 +4,11 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	stdnet "net"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/ava-labs/gecko/utils"
 	"github.com/ava-labs/gecko/utils/logging"
 	"github.com/ava-labs/gecko/utils/wrappers"
+	"github.com/ava-labs/gecko/xputtest/load"
 )
 
 var (
@@ -59,6 +62,13 @@ func init() {
 	fs.IntVar(&config.NumTxs, "num-txs", 25000, "Total number of transaction to issue")
 	fs.IntVar(&config.MaxOutstandingTxs, "max-outstanding", 1000, "Maximum number of transactions to leave outstanding")
 
+	// Load Test:
+	txMix := fs.String("tx-mix", "transfer=1", "Relative frequency of each kind of avm test transaction, as a comma separated list of <kind>=<weight>. Kinds are {transfer, stake, conflict}")
+	tpsRamp := fs.String("tps-ramp", "", "Target issuance rate of the avm test, as <start>:<end>:<duration> or <tps>. If empty, transactions are issued as fast as --max-outstanding allows")
+	fs.DurationVar(&config.TxTimeout, "tx-timeout", 30*time.Second, "Time after which an undecided avm test transaction is counted as timed out")
+	fs.Int64Var(&config.Seed, "seed", 0, "Seed of the choice of the kind of each avm test transaction")
+	fs.StringVar(&config.ReportFile, "report-file", "", "File to write the report of the avm test to. If empty, the report is only logged")
+
 	ferr := fs.Parse(os.Args[1:])
 
 	if ferr == flag.ErrHelp {
@@ -100,6 +110,15 @@ func init() {
 	loggingConfig.DisplayLevel = level
 	config.LoggingConfig = loggingConfig
 
+	// Load Test:
+	config.Mix, err = load.ParseMix(*txMix)
+	errs.Add(err)
+	if config.Mix[load.Conflict] > 0 && config.Mix[load.Transfer] == 0 {
+		errs.Add(errors.New("conflicting transactions spend the outputs of transfers, so they can't be issued without transfers"))
+	}
+	config.Ramp, err = load.ParseRamp(*tpsRamp)
+	errs.Add(err)
+
 	// Test Variables:
 	switch {
 	case *spchain: